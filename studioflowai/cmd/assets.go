@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/config"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var assetsCmd = &cobra.Command{
+	Use:   "assets",
+	Short: "List and fetch the named fonts, logos, intros and music of workflows",
+	Long: `Assets are files referenced by name from workflow parameters as ${asset:<name>},
+so workflows do not depend on where a machine keeps them. They are declared in
+assets.yaml in ~/.studioflowai and in the project directory, whose entries win:
+
+  assets:
+    brand-font:
+      type: font
+      path: fonts/Brand-Bold.ttf        # relative to assets.yaml
+    intro:
+      type: intro
+      url: https://cdn.example.com/intro.mp4
+      sha256: 9f86d08...                # optional pinned checksum
+
+Downloaded assets are cached in ~/.studioflowai/assets.`,
+}
+
+var assetsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the assets available to the selected project",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		assets, err := config.LoadAssets(config.ActiveProject())
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		if len(assets) == 0 {
+			fmt.Fprintln(out, "No assets declared. Add them to assets.yaml (see: studioflowai assets --help)")
+			return nil
+		}
+		for _, name := range assets.Names() {
+			asset := assets[name]
+			source := asset.Path
+			if asset.URL != "" {
+				source = asset.URL
+			}
+			pinned := ""
+			if asset.SHA256 != "" {
+				pinned = " (pinned)"
+			}
+			fmt.Fprintf(out, "  %-20s %-6s %s%s\n", name, asset.Type, source, pinned)
+		}
+		return nil
+	},
+}
+
+var assetsFetchCmd = &cobra.Command{
+	Use:   "fetch [name...]",
+	Short: "Download the assets and check their pinned checksums",
+	Long: `Download the assets that are not cached yet and check every asset against its
+pinned checksum, so a run does not stop on a missing or changed file. Without
+names, all assets are fetched.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		assets, err := config.LoadAssets(config.ActiveProject())
+		if err != nil {
+			return err
+		}
+		names := args
+		if len(names) == 0 {
+			names = assets.Names()
+		}
+
+		out := cmd.OutOrStdout()
+		failed := 0
+		for _, name := range names {
+			file, err := assets.Resolve(cmd.Context(), name)
+			if err != nil {
+				fmt.Fprintf(out, "  ✗ %v\n", err)
+				failed++
+				continue
+			}
+			fmt.Fprintf(out, "  ✓ %-20s %s\n", name, file)
+			if assets[name].SHA256 == "" {
+				// Show the checksum so it can be pinned in assets.yaml
+				if sum, err := utils.FileChecksum(file); err == nil {
+					fmt.Fprintf(out, "    sha256: %s\n", sum)
+				}
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d assets could not be fetched", failed, len(names))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(assetsCmd)
+	assetsCmd.AddCommand(assetsListCmd, assetsFetchCmd)
+}
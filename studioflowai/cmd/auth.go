@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/config"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/services/tiktok"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/services/youtube"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/validator"
+
+	"github.com/spf13/cobra"
+)
+
+var authYouTubeCredentials string
+
+// authLogins are the interactive login flows of the platforms that use OAuth
+var authLogins = map[string]func(ctx context.Context, opts validator.AuthOptions) error{
+	"youtube": func(ctx context.Context, opts validator.AuthOptions) error {
+		if opts.YouTubeCredentials == "" {
+			return fmt.Errorf("no YouTube credentials file configured (use --youtube-credentials)")
+		}
+		_, err := (&youtube.Service{}).InitializeYouTubeService(ctx, opts.YouTubeCredentials)
+		return err
+	},
+	"tiktok": func(ctx context.Context, opts validator.AuthOptions) error {
+		service, err := tiktok.NewService()
+		if err != nil {
+			return err
+		}
+		return service.Initialize(tiktok.DefaultOAuthConfig())
+	},
+}
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Show and renew the credentials of each platform",
+	Long: `Show which platforms have their keys and OAuth tokens in place, and run the
+OAuth logins up front so a workflow does not stop halfway to open the browser.
+Tokens are kept per project; select one with --project.`,
+}
+
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "List each platform's credentials, token expiry and scopes",
+	Long: `List each platform's credentials, token expiry and scopes. Only stored files and
+environment variables are read; no API is called (see: studioflowai doctor).`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configDir, err := utils.ConfigDir()
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "Credentials in %s:\n", configDir)
+		for _, status := range validator.AuthStatus(authOptions()) {
+			icon := "✅"
+			switch {
+			case !status.Configured:
+				icon = "➖"
+			case !status.Authorized:
+				icon = "⚠️ "
+			}
+
+			name := status.Platform
+			if status.Account != "" {
+				name += " (" + status.Account + ")"
+			}
+			fmt.Fprintf(out, "  %s %-24s %s\n", icon, name, status.Details)
+			if len(status.Scopes) > 0 {
+				fmt.Fprintf(out, "     %-24s scopes: %s\n", "", strings.Join(status.Scopes, ", "))
+			}
+			if status.Configured && !status.Authorized && authLogins[status.Platform] != nil {
+				fmt.Fprintf(out, "     %-24s run: studioflowai auth login %s\n", "", status.Platform)
+			}
+		}
+		return nil
+	},
+}
+
+var authLoginCmd = &cobra.Command{
+	Use:   "login <platform>",
+	Short: "Run the OAuth login of a platform and store its token",
+	Long: `Run the OAuth login of a platform in the browser and store its token for the
+selected project, replacing the current one. The current token is kept when the
+login fails.`,
+	Example: `  studioflowai auth login youtube
+  studioflowai --project acme auth login tiktok`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"youtube", "tiktok"},
+	// A failed login is explained by its error; usage text would only bury it
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		platform := strings.ToLower(args[0])
+		login, ok := authLogins[platform]
+		if !ok {
+			if platform == "openai" {
+				return fmt.Errorf("OpenAI uses an API key, not a login; set OPENAI_API_KEY in .env")
+			}
+			return fmt.Errorf("unknown platform %q (supported: youtube, tiktok)", args[0])
+		}
+
+		if err := reauthorize(platform, func() error {
+			return login(cmd.Context(), authOptions())
+		}); err != nil {
+			return fmt.Errorf("%s login failed: %w", platform, err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Logged in to %s\n", platform)
+		return nil
+	},
+}
+
+// authOptions returns the accounts of the selected project, with the credentials flag taking precedence
+func authOptions() validator.AuthOptions {
+	opts := validator.AuthOptions{YouTubeCredentials: authYouTubeCredentials}
+	if project := config.ActiveProject(); project != nil {
+		if opts.YouTubeCredentials == "" {
+			opts.YouTubeCredentials = project.YouTubeCredentialsPath()
+		}
+		opts.YouTubeChannel = project.Accounts.YouTube.Channel
+		opts.TikTokUsername = project.Accounts.TikTok.Username
+	}
+	return opts
+}
+
+// reauthorize runs a login with the platform's stored token moved aside, so the login cannot reuse
+// it. The old token is put back when the login fails.
+func reauthorize(platform string, login func() error) error {
+	configDir, err := utils.ConfigDir()
+	if err != nil {
+		return err
+	}
+	tokenPath := filepath.Join(configDir, platform+"_token.json")
+	backupPath := tokenPath + ".bak"
+
+	moved := false
+	if _, err := os.Stat(tokenPath); err == nil {
+		if err := os.Rename(tokenPath, backupPath); err != nil {
+			return fmt.Errorf("failed to set the current token aside: %w", err)
+		}
+		moved = true
+	}
+
+	if err := login(); err != nil {
+		if moved {
+			if restoreErr := os.Rename(backupPath, tokenPath); restoreErr != nil {
+				utils.LogWarning("Failed to restore the previous token from %s: %v", backupPath, restoreErr)
+			}
+		}
+		return err
+	}
+	if moved {
+		if err := os.Remove(backupPath); err != nil {
+			utils.LogWarning("Failed to remove the previous token: %v", err)
+		}
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(authCmd)
+	authCmd.AddCommand(authStatusCmd, authLoginCmd)
+
+	authCmd.PersistentFlags().StringVar(&authYouTubeCredentials, "youtube-credentials", "", "Google OAuth client credentials file (default: the project's)")
+}
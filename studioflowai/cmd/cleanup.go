@@ -9,6 +9,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/config"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+
 	"github.com/spf13/cobra"
 )
 
@@ -17,13 +20,25 @@ var (
 	keepLatest    int
 	olderThanDays int
 	cleanupDryRun bool
+	cleanOrphans  bool
+	orphanAge     time.Duration
 )
 
 var cleanupCmd = &cobra.Command{
-	Use:   "cleanup",
-	Short: "Clean up old workflow output directories",
-	Long:  `Remove old workflow run folders based on age or count.`,
+	Use:     "cleanup",
+	Aliases: []string{"clean"},
+	Short:   "Clean up old workflow output directories",
+	Long: `Remove old workflow run folders based on age or count.
+
+With --orphans, remove the temporary folders that crashed or killed runs left in the system temp
+directory and below the output directory instead. Folders of runs that are still going are kept.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if project := config.ActiveProject(); project != nil && outputDir == "" {
+			outputDir = project.OutputPath()
+		}
+		if cleanOrphans {
+			return removeOrphanTempDirs(outputDir)
+		}
 		if outputDir == "" {
 			return fmt.Errorf("output directory is required")
 		}
@@ -137,6 +152,45 @@ var cleanupCmd = &cobra.Command{
 	},
 }
 
+// removeOrphanTempDirs removes the temporary folders of runs that are gone
+func removeOrphanTempDirs(root string) error {
+	if root != "" {
+		if _, err := os.Stat(root); err != nil {
+			return fmt.Errorf("output directory %s does not exist", root)
+		}
+	}
+
+	orphans, err := utils.FindOrphanTempDirs([]string{root}, orphanAge)
+	if err != nil {
+		return err
+	}
+	if len(orphans) == 0 {
+		fmt.Println("No orphaned temporary folders found.")
+		return nil
+	}
+
+	var total int64
+	fmt.Printf("Found %d orphaned temporary folders:\n", len(orphans))
+	for _, orphan := range orphans {
+		fmt.Printf("- %s (%.1f MB, %s)\n", orphan.Path, float64(orphan.Size)/(1024*1024), orphan.Reason)
+		total += orphan.Size
+	}
+
+	if cleanupDryRun {
+		fmt.Printf("Dry run - no folders were deleted (%.1f MB would be freed).\n", float64(total)/(1024*1024))
+		return nil
+	}
+
+	for _, orphan := range orphans {
+		if err := os.RemoveAll(orphan.Path); err != nil {
+			fmt.Printf("Error deleting %s: %v\n", orphan.Path, err)
+		}
+	}
+
+	fmt.Printf("Cleanup completed, %.1f MB freed.\n", float64(total)/(1024*1024))
+	return nil
+}
+
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
 		if s == item {
@@ -147,11 +201,12 @@ func contains(slice []string, item string) bool {
 }
 
 func init() {
-	cleanupCmd.Flags().StringVarP(&outputDir, "dir", "d", "", "Output directory to clean up (required)")
+	cleanupCmd.Flags().StringVarP(&outputDir, "dir", "d", "", "Output directory to clean up (required unless --project is set)")
 	cleanupCmd.Flags().IntVarP(&keepLatest, "keep-latest", "k", 0, "Keep this many latest directories")
 	cleanupCmd.Flags().IntVarP(&olderThanDays, "older-than", "o", 0, "Delete directories older than this many days")
 	cleanupCmd.Flags().BoolVarP(&cleanupDryRun, "dry-run", "n", false, "Show what would be deleted without actually deleting")
+	cleanupCmd.Flags().BoolVar(&cleanOrphans, "orphans", false, "Delete temporary folders left behind by crashed runs instead of run folders")
+	cleanupCmd.Flags().DurationVar(&orphanAge, "orphan-age", 24*time.Hour, "With --orphans, age after which folders of other hosts and earlier versions are deleted")
 
-	_ = cleanupCmd.MarkFlagRequired("dir")
 	rootCmd.AddCommand(cleanupCmd)
 }
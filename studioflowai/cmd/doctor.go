@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/config"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/validator"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	doctorOutputDirs         []string
+	doctorYouTubeCredentials string
+	doctorOffline            bool
+	doctorTimeout            time.Duration
+)
+
+// doctorIcons maps each check status to the symbol printed in the report
+var doctorIcons = map[validator.CheckStatus]string{
+	validator.CheckOK:   "✅",
+	validator.CheckWarn: "⚠️ ",
+	validator.CheckFail: "❌",
+	validator.CheckSkip: "➖",
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check that tools, credentials and output locations are ready",
+	Long: `Run a readiness check of the environment: external tools (ffmpeg, ffprobe, whisper)
+and their versions, OpenAI, YouTube and TikTok credentials using cheap test calls,
+and writable output locations. The YouTube check costs 1 API quota unit.
+No interactive OAuth flow is started; missing tokens are reported instead.`,
+	Example: `  studioflowai doctor
+  studioflowai doctor --youtube-credentials client_secret.json -o ./output
+  studioflowai doctor --offline`,
+	Args: cobra.NoArgs,
+	// The report already explains failures; usage text would only bury it
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Check the project's own locations and credentials unless overridden
+		if project := config.ActiveProject(); project != nil {
+			if !cmd.Flags().Changed("output") {
+				doctorOutputDirs = []string{project.OutputPath()}
+			}
+			if doctorYouTubeCredentials == "" {
+				doctorYouTubeCredentials = project.YouTubeCredentialsPath()
+			}
+		}
+
+		results := validator.RunDoctor(cmd.Context(), validator.DoctorOptions{
+			OutputDirs:         doctorOutputDirs,
+			YouTubeCredentials: doctorYouTubeCredentials,
+			Offline:            doctorOffline,
+			Timeout:            doctorTimeout,
+		})
+
+		out := cmd.OutOrStdout()
+		counts := make(map[validator.CheckStatus]int)
+		group := ""
+		for _, r := range results {
+			if r.Group != group {
+				if group != "" {
+					fmt.Fprintln(out)
+				}
+				group = r.Group
+				fmt.Fprintf(out, "%s:\n", group)
+			}
+			fmt.Fprintf(out, "  %s %-12s %s\n", doctorIcons[r.Status], r.Name, r.Details)
+			counts[r.Status]++
+		}
+
+		fmt.Fprintf(out, "\n%d ok, %d warnings, %d failed, %d skipped\n",
+			counts[validator.CheckOK], counts[validator.CheckWarn], counts[validator.CheckFail], counts[validator.CheckSkip])
+
+		if counts[validator.CheckFail] > 0 {
+			return fmt.Errorf("%d readiness check(s) failed", counts[validator.CheckFail])
+		}
+		fmt.Fprintln(out, "StudioFlowAI is ready")
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+
+	doctorCmd.Flags().StringSliceVarP(&doctorOutputDirs, "output", "o", []string{"./output"}, "Output directories that must be writable")
+	doctorCmd.Flags().StringVar(&doctorYouTubeCredentials, "youtube-credentials", "", "Google OAuth client credentials file used for YouTube uploads")
+	doctorCmd.Flags().BoolVar(&doctorOffline, "offline", false, "Skip checks that call remote APIs")
+	doctorCmd.Flags().DurationVar(&doctorTimeout, "timeout", 15*time.Second, "Timeout for each remote API check")
+}
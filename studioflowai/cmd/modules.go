@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/workflow"
+
+	"github.com/spf13/cobra"
+)
+
+var modulesCmd = &cobra.Command{
+	Use:     "modules",
+	Aliases: []string{"module"},
+	Short:   "Inspect and run workflow modules",
+	Long:    `List the modules that can be used in workflow files, describe their parameters and run them one at a time.`,
+}
+
+var modulesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List available modules",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		registry, err := workflow.NewRegistry()
+		if err != nil {
+			return err
+		}
+
+		docs := moduleDocs(registry)
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "MODULE\tPARAMETERS\tOUTPUTS")
+		for _, doc := range docs {
+			var outputs []string
+			for _, out := range doc.Outputs {
+				outputs = append(outputs, out.Name)
+			}
+			fmt.Fprintf(w, "%s\t%d\t%s\n", doc.Name, len(doc.Params), strings.Join(outputs, ", "))
+		}
+		return w.Flush()
+	},
+}
+
+var modulesDescribeCmd = &cobra.Command{
+	Use:   "describe <name>",
+	Short: "Describe a module's parameters, inputs and outputs",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		registry, err := workflow.NewRegistry()
+		if err != nil {
+			return err
+		}
+
+		module, err := registry.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		printModuleDoc(cmd, mod.DescribeModule(module), module.GetIO())
+		return nil
+	},
+}
+
+// moduleDocs returns the documentation of all registered modules sorted by name
+func moduleDocs(registry *mod.ModuleRegistry) []mod.ModuleDoc {
+	var docs []mod.ModuleDoc
+	for _, m := range registry.ListModules() {
+		docs = append(docs, mod.DescribeModule(m))
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].Name < docs[j].Name })
+	return docs
+}
+
+// printModuleDoc writes a human readable description of a module
+func printModuleDoc(cmd *cobra.Command, doc mod.ModuleDoc, io mod.ModuleIO) {
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Module: %s\n\n", doc.Name)
+
+	fmt.Fprintln(out, "Parameters:")
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  NAME\tTYPE\tDEFAULT\tREQUIRED\tDESCRIPTION")
+	for _, p := range doc.Params {
+		def := p.Default
+		if def == "" {
+			def = "-"
+		}
+		required := ""
+		if p.Required {
+			required = "yes"
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", p.Name, p.Kind, def, required, p.Description)
+	}
+	_ = w.Flush()
+
+	printInputs(cmd, "Required inputs", io.RequiredInputs)
+	printInputs(cmd, "Optional inputs", io.OptionalInputs)
+
+	if len(doc.Outputs) > 0 {
+		fmt.Fprintln(out, "\nProduced outputs:")
+		for _, o := range doc.Outputs {
+			fmt.Fprintf(out, "  %s (%s): %s%s\n", o.Name, o.Type, o.Description, formatPatterns(o.Patterns))
+		}
+	}
+}
+
+// printInputs writes a list of module inputs under a heading
+func printInputs(cmd *cobra.Command, heading string, inputs []mod.ModuleInput) {
+	if len(inputs) == 0 {
+		return
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "\n%s:\n", heading)
+	for _, in := range inputs {
+		fmt.Fprintf(out, "  %s (%s): %s%s\n", in.Name, in.Type, in.Description, formatPatterns(in.Patterns))
+	}
+}
+
+// formatPatterns renders file patterns as a suffix, or nothing when there are none
+func formatPatterns(patterns []string) string {
+	if len(patterns) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(patterns, ", ") + "]"
+}
+
+func init() {
+	rootCmd.AddCommand(modulesCmd)
+	modulesCmd.AddCommand(modulesListCmd)
+	modulesCmd.AddCommand(modulesDescribeCmd)
+}
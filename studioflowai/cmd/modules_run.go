@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/workflow"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	moduleParams    []string
+	moduleStdout    bool
+	moduleOutputKey string
+	moduleInputExt  string
+)
+
+var modulesRunCmd = &cobra.Command{
+	Use:   "run <name>",
+	Short: "Run a single module outside of a workflow",
+	Long: `Run one module with the parameters given as --param name=value, without a workflow file,
+so modules can be composed with other tools in shell scripts.
+
+Values are read as YAML, so --param maxTokens=4000 is a number and --param 'languages=[en, es]'
+a list. The input is read from stdin when it is "-", or when no input is given and stdin is a
+pipe; --input-ext names the kind of file it holds (default: the first type the module reads).
+
+With --stdout the module's main output file is written to stdout and all messages go to stderr.
+Outputs are then written to a temporary folder unless an output folder is given. Without it, the
+outputs are listed once the module is done.`,
+	Example: `  studioflowai modules run transcribe --param input=episode.wav --param language=en --stdout > episode.srt
+  cat notes.txt | studioflowai modules run clean_text --stdout | wc -w
+  studioflowai module run suggest_shorts --param input=- --input-ext .srt --stdout < episode.srt`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if moduleStdout {
+			utils.SetLogOutput(cmd.ErrOrStderr())
+		}
+
+		registry, err := workflow.NewRegistry()
+		if err != nil {
+			return err
+		}
+		module, err := registry.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		params, err := parseParamFlags(moduleParams)
+		if err != nil {
+			return err
+		}
+
+		var workDir string
+		workspace := func() (string, error) {
+			if workDir == "" {
+				dir, err := utils.MakeTempDir("", "module")
+				if err != nil {
+					return "", fmt.Errorf("failed to create temporary folder: %w", err)
+				}
+				workDir = dir
+			}
+			return workDir, nil
+		}
+		defer func() {
+			if workDir != "" {
+				_ = os.RemoveAll(workDir)
+			}
+		}()
+
+		input, hasInput := params["input"]
+		if input == "-" || (!hasInput && stdinIsPipe()) {
+			dir, err := workspace()
+			if err != nil {
+				return err
+			}
+			ext := moduleInputExt
+			if ext == "" {
+				ext = defaultInputExt(module.GetIO())
+			}
+			path, err := saveStdin(cmd.InOrStdin(), dir, ext)
+			if err != nil {
+				return err
+			}
+			params["input"] = path
+		}
+
+		if _, ok := params["output"]; !ok {
+			if moduleStdout {
+				dir, err := workspace()
+				if err != nil {
+					return err
+				}
+				params["output"] = dir
+			} else {
+				params["output"] = "."
+			}
+		}
+
+		if err := module.Validate(params); err != nil {
+			return fmt.Errorf("invalid parameters for module %s: %w", module.Name(), err)
+		}
+		result, err := module.Execute(cmd.Context(), params)
+		if err != nil {
+			return fmt.Errorf("module %s failed: %w", module.Name(), err)
+		}
+
+		if !moduleStdout {
+			names := make([]string, 0, len(result.Outputs))
+			for name := range result.Outputs {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\n", name, result.Outputs[name])
+			}
+			return nil
+		}
+
+		path, err := mainOutput(module.GetIO(), result, moduleOutputKey)
+		if err != nil {
+			return err
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open output: %w", err)
+		}
+		defer file.Close()
+		if _, err := io.Copy(cmd.OutOrStdout(), file); err != nil {
+			return fmt.Errorf("failed to write output to stdout: %w", err)
+		}
+		return nil
+	},
+}
+
+// parseParamFlags reads name=value pairs, decoding each value as YAML
+func parseParamFlags(pairs []string) (map[string]interface{}, error) {
+	params := make(map[string]interface{}, len(pairs))
+	for _, pair := range pairs {
+		name, raw, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --param %q: expected name=value", pair)
+		}
+		var value interface{}
+		if err := yaml.Unmarshal([]byte(raw), &value); err != nil || value == nil {
+			// Not valid YAML, or empty: keep the text as written
+			value = raw
+		}
+		params[name] = value
+	}
+	return params, nil
+}
+
+// stdinIsPipe reports whether stdin is redirected from a file or another command
+func stdinIsPipe() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice == 0
+}
+
+// defaultInputExt returns the first file type a module reads as its input, or .txt
+func defaultInputExt(io mod.ModuleIO) string {
+	for _, input := range append(io.RequiredInputs, io.OptionalInputs...) {
+		if input.Name == "input" && len(input.Patterns) > 0 {
+			return input.Patterns[0]
+		}
+	}
+	return ".txt"
+}
+
+// saveStdin copies stdin to a file in dir, so modules that read files can process it
+func saveStdin(r io.Reader, dir, ext string) (string, error) {
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	path := filepath.Join(dir, "stdin"+ext)
+	file, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to save stdin: %w", err)
+	}
+	defer file.Close()
+	if _, err := io.Copy(file, r); err != nil {
+		return "", fmt.Errorf("failed to read stdin: %w", err)
+	}
+	return path, nil
+}
+
+// mainOutput picks the output written to stdout: the one named key, or else the first output the
+// module declares that is a file
+func mainOutput(io mod.ModuleIO, result mod.ModuleResult, key string) (string, error) {
+	if key != "" {
+		path, ok := result.Outputs[key]
+		if !ok {
+			return "", fmt.Errorf("module produced no output %q", key)
+		}
+		return requireFile(key, path)
+	}
+	for _, output := range io.ProducedOutputs {
+		path, ok := result.Outputs[output.Name]
+		if !ok {
+			continue
+		}
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("module produced no output file to write to stdout; name one with --output-key")
+}
+
+// requireFile checks that an output is a regular file
+func requireFile(name, path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("output %s: %w", name, err)
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("output %s is not a file: %s", name, path)
+	}
+	return path, nil
+}
+
+func init() {
+	modulesRunCmd.Flags().StringArrayVar(&moduleParams, "param", nil, "Module parameter as name=value (repeatable)")
+	modulesRunCmd.Flags().BoolVar(&moduleStdout, "stdout", false, "Write the main output file to stdout and messages to stderr")
+	modulesRunCmd.Flags().StringVar(&moduleOutputKey, "output-key", "", "Output written with --stdout (default: the first file the module produces)")
+	modulesRunCmd.Flags().StringVar(&moduleInputExt, "input-ext", "", "File type of input read from stdin, e.g. .srt (default: the first type the module reads)")
+	modulesCmd.AddCommand(modulesRunCmd)
+}
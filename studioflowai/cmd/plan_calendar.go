@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	suggestshorts "github.com/gnzdotmx/studioflowai/studioflowai/internal/modules/suggest_shorts"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	calendarBacklog   []string
+	calendarRules     string
+	calendarPlatforms []string
+	calendarStart     string
+	calendarDays      int
+	calendarOutput    string
+	calendarName      string
+	calendarFormats   []string
+)
+
+var planCalendarCmd = &cobra.Command{
+	Use:     "plan_calendar [shorts files or run folders...]",
+	Aliases: []string{"plan-calendar"},
+	Short:   "Plan a publishing calendar for the shorts of several runs",
+	Long: `Plan when each suggested short is published on each platform. Shorts files, or run
+folders holding a shorts_suggestions.yaml, are scheduled in turns so every episode gets
+airtime. Each platform follows its own frequency rule, and slots left after all new
+clips are scheduled are filled with evergreen clips from the backlog.
+
+The rules file lists one rule per platform:
+
+  platforms:
+    - platform: youtube
+      times: ["18:00"]
+    - platform: tiktok
+      times: ["12:00", "20:00"]
+      weekdays: [mon, tue, wed, thu, fri]
+      maxPerWeek: 8
+
+The calendar is written as CSV and as an ICS file that calendar apps can import.`,
+	Example: `  studioflowai plan_calendar output/ep12-20260101-120000 output/ep13-20260108-120000
+  studioflowai plan_calendar output/*/shorts_suggestions.yaml --rules calendar.yaml --backlog evergreen.yaml --days 28
+  studioflowai plan_calendar shorts.yaml --platforms youtube,tiktok --start 2026-11-02 --format ics`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var rules []suggestshorts.CalendarRule
+		if calendarRules != "" {
+			loaded, err := suggestshorts.LoadCalendarRules(calendarRules)
+			if err != nil {
+				return err
+			}
+			rules = loaded
+		} else {
+			for _, platform := range calendarPlatforms {
+				rules = append(rules, suggestshorts.CalendarRule{Platform: strings.TrimSpace(platform)})
+			}
+		}
+
+		start := time.Now().AddDate(0, 0, 1)
+		if calendarStart != "" {
+			parsed, err := time.ParseInLocation("2006-01-02", calendarStart, time.Local)
+			if err != nil {
+				return fmt.Errorf("invalid start date %q (expected YYYY-MM-DD)", calendarStart)
+			}
+			start = parsed
+		}
+		if calendarDays < 1 {
+			return fmt.Errorf("days must be 1 or greater (--days)")
+		}
+
+		entries, err := suggestshorts.PlanCalendar(args, suggestshorts.CalendarOptions{
+			Start:   start,
+			Days:    calendarDays,
+			Rules:   rules,
+			Backlog: calendarBacklog,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := utils.EnsureDir(calendarOutput); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+		out := cmd.OutOrStdout()
+		for _, format := range calendarFormats {
+			var content string
+			switch format {
+			case "csv":
+				if content, err = suggestshorts.CalendarCSV(entries); err != nil {
+					return err
+				}
+			case "ics":
+				content = suggestshorts.CalendarICS(entries, time.Now())
+			default:
+				return fmt.Errorf("unsupported format %q (supported: csv, ics)", format)
+			}
+			path := filepath.Join(calendarOutput, calendarName+"."+format)
+			if err := utils.WriteTextFile(path, content); err != nil {
+				return fmt.Errorf("failed to write calendar: %w", err)
+			}
+			fmt.Fprintf(out, "Wrote %s\n", path)
+		}
+
+		evergreen := 0
+		for _, entry := range entries {
+			if entry.Evergreen {
+				evergreen++
+			}
+		}
+		fmt.Fprintf(out, "Planned %d posts from %s to %s (%d evergreen)\n", len(entries),
+			start.Format("2006-01-02"), start.AddDate(0, 0, calendarDays-1).Format("2006-01-02"), evergreen)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(planCalendarCmd)
+
+	planCalendarCmd.Flags().StringSliceVar(&calendarBacklog, "backlog", nil, "Shorts files or run folders with evergreen clips used to fill gaps")
+	planCalendarCmd.Flags().StringVar(&calendarRules, "rules", "", "YAML file with the publishing rule of each platform")
+	planCalendarCmd.Flags().StringSliceVar(&calendarPlatforms, "platforms", []string{"youtube"}, "Platforms posting once a day at 18:00 when no rules file is given")
+	planCalendarCmd.Flags().StringVar(&calendarStart, "start", "", "First day of the calendar, YYYY-MM-DD (default: tomorrow)")
+	planCalendarCmd.Flags().IntVar(&calendarDays, "days", 14, "Number of days to plan")
+	planCalendarCmd.Flags().StringVarP(&calendarOutput, "output", "o", ".", "Directory the calendar files are written to")
+	planCalendarCmd.Flags().StringVar(&calendarName, "name", "publishing_calendar", "Calendar file name without extension")
+	planCalendarCmd.Flags().StringSliceVar(&calendarFormats, "format", []string{"csv", "ics"}, "Calendar formats: csv, ics")
+}
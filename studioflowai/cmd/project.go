@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	projectDescription  string
+	projectOutputRoot   string
+	projectYouTubeCreds string
+	projectChannel      string
+	projectTikTokUser   string
+)
+
+var projectCmd = &cobra.Command{
+	Use:   "project",
+	Short: "Manage isolated project workspaces",
+	Long: `Projects keep the configuration, prompts, credentials, tokens and outputs of each
+client or channel apart. Select one for any command with --project <name>.
+
+Each project lives in ~/.studioflowai/projects/<name>/ with:
+  project.yaml   output root, prompts directory and platform accounts
+  .env           API keys that override the global .env
+  prompts/       prompt files overriding the ones in ./prompts
+  *_token.json   OAuth tokens and quota state of this project only`,
+}
+
+var projectCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a new project workspace",
+	Example: `  studioflowai project create acme --description "Acme Corp channel" --youtube-credentials ~/acme/client_secret.json
+  studioflowai --project acme run -w workflow.yaml -i video.mp4`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Paths given on the command line are relative to the current directory, not the project
+		for _, path := range []*string{&projectOutputRoot, &projectYouTubeCreds} {
+			if *path == "" {
+				continue
+			}
+			abs, err := filepath.Abs(*path)
+			if err != nil {
+				return fmt.Errorf("failed to resolve %s: %w", *path, err)
+			}
+			*path = abs
+		}
+
+		project := &config.Project{
+			Name:        args[0],
+			Description: projectDescription,
+			OutputRoot:  projectOutputRoot,
+			Accounts: config.ProjectAccounts{
+				YouTube: config.YouTubeAccount{Channel: projectChannel, Credentials: projectYouTubeCreds},
+				TikTok:  config.TikTokAccount{Username: projectTikTokUser},
+			},
+		}
+		if err := config.CreateProject(project); err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "Created project %s in %s\n", project.Name, project.Dir)
+		fmt.Fprintf(out, "Add its API keys to %s/.env and use it with --project %s\n", project.Dir, project.Name)
+		return nil
+	},
+}
+
+var projectListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List project workspaces",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		names, err := config.ListProjects()
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		if len(names) == 0 {
+			fmt.Fprintln(out, "No projects found. Create one with: studioflowai project create <name>")
+			return nil
+		}
+		for _, name := range names {
+			project, err := config.LoadProject(name)
+			if err != nil {
+				fmt.Fprintf(out, "  %-20s (%v)\n", name, err)
+				continue
+			}
+			fmt.Fprintf(out, "  %-20s %s\n", name, project.Description)
+		}
+		return nil
+	},
+}
+
+var projectShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Show the configuration of a project",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		project, err := config.LoadProject(args[0])
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "Project:     %s\n", project.Name)
+		if project.Description != "" {
+			fmt.Fprintf(out, "Description: %s\n", project.Description)
+		}
+		fmt.Fprintf(out, "Directory:   %s\n", project.Dir)
+		fmt.Fprintf(out, "Outputs:     %s\n", project.OutputPath())
+		fmt.Fprintf(out, "Prompts:     %s\n", project.PromptsPath())
+		if channel := project.Accounts.YouTube.Channel; channel != "" {
+			fmt.Fprintf(out, "YouTube:     %s\n", channel)
+		}
+		if creds := project.YouTubeCredentialsPath(); creds != "" {
+			fmt.Fprintf(out, "YouTube key: %s\n", creds)
+		}
+		if tt := project.Accounts.TikTok; tt.Username != "" {
+			fmt.Fprintf(out, "TikTok:      %s\n", tt.Username)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(projectCmd)
+	projectCmd.AddCommand(projectCreateCmd, projectListCmd, projectShowCmd)
+
+	projectCreateCmd.Flags().StringVar(&projectDescription, "description", "", "Short description of the project")
+	projectCreateCmd.Flags().StringVar(&projectOutputRoot, "output-root", "", "Root directory for run outputs (default: <project>/output)")
+	projectCreateCmd.Flags().StringVar(&projectYouTubeCreds, "youtube-credentials", "", "Google OAuth client credentials file for the project's channel")
+	projectCreateCmd.Flags().StringVar(&projectChannel, "youtube-channel", "", "Name of the project's YouTube channel")
+	projectCreateCmd.Flags().StringVar(&projectTikTokUser, "tiktok-user", "", "Name of the project's TikTok account")
+}
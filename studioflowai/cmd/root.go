@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"fmt"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/config"
 	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
 	"github.com/spf13/cobra"
 )
@@ -8,6 +11,12 @@ import (
 var (
 	// verbosityLevel is the command-line flag for setting the log level
 	verbosityLevel string
+	// projectName selects an isolated project workspace
+	projectName string
+	// logPrefix prefixes log lines with the step writing them: off, color or plain
+	logPrefix string
+	// stepLogs writes the log of each step to the logs folder of the run
+	stepLogs bool
 )
 
 var rootCmd = &cobra.Command{
@@ -15,10 +24,37 @@ var rootCmd = &cobra.Command{
 	Short: "An AI-powered video workflow tool for content creators",
 	Long: `StudioFlowAI is a modular application for content creators
 to process videos with AI-powered configurable workflows defined in YAML.`,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	// main prints returned errors after redacting secrets from them
+	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		// Set the global log level based on the flag
 		logLevel := utils.LogLevelFromString(verbosityLevel)
 		utils.SetLogLevel(logLevel)
+		switch logPrefix {
+		case "off":
+		case "color", "plain":
+			utils.SetLogPrefix(true, logPrefix == "color")
+		default:
+			return fmt.Errorf("unsupported --log-prefix %q (supported: off, color, plain)", logPrefix)
+		}
+		utils.SetStepLogFiles(stepLogs)
+
+		// Failure injection is test-only, so it is read from the environment and has no flag
+		if err := utils.LoadChaos(); err != nil {
+			return err
+		}
+
+		// Switch credentials, tokens and outputs to the selected project
+		if projectName != "" {
+			project, err := config.LoadProject(projectName)
+			if err != nil {
+				return err
+			}
+			if err := project.Activate(); err != nil {
+				return fmt.Errorf("failed to activate project %s: %w", projectName, err)
+			}
+		}
+		return nil
 	},
 }
 
@@ -30,4 +66,11 @@ func init() {
 	// Initialize global flags
 	rootCmd.PersistentFlags().StringVarP(&verbosityLevel, "log-level", "l", "normal",
 		"Set the logging verbosity level: quiet, normal, verbose, debug")
+	rootCmd.PersistentFlags().StringVar(&logPrefix, "log-prefix", "off",
+		"Prefix log lines with the step writing them: off, color (a color per step) or plain")
+	rootCmd.PersistentFlags().Lookup("log-prefix").NoOptDefVal = "color"
+	rootCmd.PersistentFlags().BoolVar(&stepLogs, "step-logs", false,
+		"Also write the log of each step, and of the whole run, to the logs folder of the run")
+	rootCmd.PersistentFlags().StringVarP(&projectName, "project", "p", "",
+		"Project workspace to use for credentials, tokens, prompts and outputs")
 }
@@ -2,11 +2,13 @@ package cmd
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/gnzdotmx/studioflowai/studioflowai/internal/config"
 	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
 	"github.com/gnzdotmx/studioflowai/studioflowai/internal/validator"
 	"github.com/gnzdotmx/studioflowai/studioflowai/internal/workflow"
+	"github.com/google/uuid"
 
 	"github.com/spf13/cobra"
 )
@@ -17,6 +19,12 @@ var (
 	retryFlag         bool
 	outputFolderPath  string
 	workflowName      string
+	invalidateMode    string
+	deterministicFlag bool
+	seedFlag          int64
+	outputNameFlag    string
+	previewUploads    bool
+	workerFlags       map[string]string
 )
 
 var runCmd = &cobra.Command{
@@ -24,6 +32,31 @@ var runCmd = &cobra.Command{
 	Short: "Run a video processing workflow",
 	Long:  `Execute a video processing workflow defined in a YAML file.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		// Reproducible runs derive their seed and folder name from the inputs instead of randomness and the clock
+		runID := time.Now().Format("20060102-150405")
+		shortID := uuid.New().String()[:8]
+		seed := utils.RandomSeed()
+		if deterministicFlag {
+			digest, err := utils.InputsDigest(workflowFilePath, inputFileOverride)
+			if err != nil {
+				return err
+			}
+			runID = digest[:12]
+			shortID = digest[:8]
+			seed = utils.SeedFromDigest(digest)
+		}
+		if cmd.Flags().Changed("seed") {
+			seed = seedFlag
+		}
+		utils.SetDeterministic(deterministicFlag)
+		utils.SetRunSeed(seed)
+		utils.SetPreviewUploads(previewUploads)
+		utils.LogVerbose("Run seed: %d", seed)
+
+		if outputNameFlag != "" && outputFolderPath != "" {
+			return fmt.Errorf("--output-name names a new run folder and cannot be used with --output-folder")
+		}
+
 		// Create input configuration
 		inputConfig, err := config.NewInputConfig(
 			inputFileOverride,
@@ -31,11 +64,23 @@ var runCmd = &cobra.Command{
 			workflowFilePath,
 			retryFlag,
 			workflowName,
+			invalidateMode,
 		)
 		if err != nil {
 			return fmt.Errorf("invalid input configuration: %w", err)
 		}
 
+		// Runs of a project, or named with --output-name, go to a fresh folder the engine names
+		if outputFolderPath == "" {
+			if project := config.ActiveProject(); project != nil {
+				inputConfig.OutputRoot = project.OutputPath()
+			}
+			inputConfig.OutputName = outputNameFlag
+			inputConfig.RunID = runID
+			inputConfig.ShortID = shortID
+		}
+		inputConfig.Workers = workerFlags
+
 		// Validate that external dependencies are installed
 		if err := validator.ValidateExternalTools(); err != nil {
 			return fmt.Errorf("dependency validation failed: %w", err)
@@ -46,6 +91,9 @@ var runCmd = &cobra.Command{
 		if err != nil {
 			return fmt.Errorf("failed to load workflow: %w", err)
 		}
+		if outputFolderPath == "" && inputConfig.OutputPath != "" {
+			utils.LogInfo("Writing outputs to %s", inputConfig.OutputPath)
+		}
 
 		// Execute the workflow
 		if inputConfig.RetryMode {
@@ -70,6 +118,12 @@ func init() {
 	runCmd.Flags().BoolVarP(&retryFlag, "retry", "r", false, "Retry a failed workflow execution")
 	runCmd.Flags().StringVarP(&outputFolderPath, "output-folder", "o", "", "Output folder path with timestamp (required with --retry)")
 	runCmd.Flags().StringVarP(&workflowName, "workflow-name", "n", "", "Name of the specific step to resume from (required with --retry)")
+	runCmd.Flags().StringVar(&invalidateMode, "invalidate", config.InvalidateMove, "With --retry, what to do with outputs of the retried steps from the previous attempt: move (to .stale/), delete or keep")
+	runCmd.Flags().BoolVar(&deterministicFlag, "deterministic", false, "Reproducible run: temperature 0, a seed and folder name derived from the inputs, and inputs chosen by name")
+	runCmd.Flags().StringVar(&outputNameFlag, "output-name", "", "Name of the new run folder, as a pattern such as \"{date}/{slug}-{run.shortid}\" (default: the workflow's or project's outputName, or {workflow}-{run.id})")
+	runCmd.Flags().Int64Var(&seedFlag, "seed", 0, "Seed sent to the models (default: random, or derived from the inputs with --deterministic)")
+	runCmd.Flags().StringToStringVar(&workerFlags, "worker", nil, "Address of a worker steps name with worker:, as name=host:port (repeatable; overrides the workflow's workers)")
+	runCmd.Flags().BoolVar(&previewUploads, "preview-uploads", false, "Write the requests upload steps would send to uploads_preview.yaml in the run folder instead of uploading")
 	_ = runCmd.MarkFlagRequired("workflow")
 	rootCmd.AddCommand(runCmd)
 }
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/config"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/workflow"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportOut      string
+	exportInclude  []string
+	exportPrompts  string
+	exportNoPrompt bool
+	exportWorkflow string
+	importDest     string
+	importForce    bool
+)
+
+var exportRunCmd = &cobra.Command{
+	Use:     "export_run <run folder>",
+	Aliases: []string{"export-run"},
+	Short:   "Bundle a run folder so it can be resumed on another machine",
+	Long: `Bundle the workflow state, the artifact manifest, the prompts and the artifacts of a run
+into a single archive. Import it with import_run on another machine, for example to
+transcribe on a GPU box and review or upload from a laptop.
+
+The compression follows the extension of --out: .tar.zst (needs the zstd tool),
+.tar.gz or .tar. Use --include to bundle only some artifacts; the state file and
+manifest are always bundled.`,
+	Example: `  studioflowai export_run output/ep12-20260101-120000 --out ep12.tar.zst -w workflows/episode.yaml
+  studioflowai export_run output/ep12-20260101-120000 --include '*.srt' --include shorts_suggestions.yaml`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		runDir := filepath.Clean(args[0])
+		out := exportOut
+		if out == "" {
+			// zstd bundles are much smaller, but not every machine has the tool
+			ext := ".tar.zst"
+			if err := utils.ValidateRequiredDependency("zstd"); err != nil {
+				ext = ".tar.gz"
+			}
+			out = filepath.Base(runDir) + ext
+		}
+
+		opts := workflow.BundleOptions{Include: exportInclude, Workflow: exportWorkflow}
+		if !exportNoPrompt {
+			opts.PromptsDir = exportPrompts
+			if opts.PromptsDir == "" {
+				opts.PromptsDir = defaultPromptsDir()
+			}
+		}
+
+		index, err := workflow.ExportRun(cmd.Context(), runDir, out, opts)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Bundled %d files of %s into %s\n", len(index.Files), index.Run, out)
+		return nil
+	},
+}
+
+var importRunCmd = &cobra.Command{
+	Use:     "import_run <bundle>",
+	Aliases: []string{"import-run"},
+	Short:   "Extract a run bundle created by export_run",
+	Long: `Extract a run bundle into a new run folder, check every file against the checksums
+recorded at export time and point the workflow state at the new location. The command
+to resume the run is printed once the bundle is extracted.`,
+	Example: `  studioflowai import_run ep12.tar.zst --dest output`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dest := importDest
+		if project := config.ActiveProject(); project != nil && dest == "" {
+			dest = project.OutputPath()
+		}
+		if dest == "" {
+			dest = "."
+		}
+
+		index, runDir, err := workflow.ImportRun(cmd.Context(), args[0], dest, importForce)
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "Imported %s into %s (exported %s)\n", index.Run, runDir, index.CreatedAt.Format("2006-01-02 15:04"))
+		if _, err := os.Stat(filepath.Join(runDir, ".bundle", "prompts")); err == nil {
+			fmt.Fprintf(out, "Prompts used by the run are in %s\n", filepath.Join(runDir, ".bundle", "prompts"))
+		}
+
+		pending, err := workflow.PendingSteps(runDir)
+		if err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			fmt.Fprintln(out, "All steps of the run are complete.")
+			return nil
+		}
+		workflowPath := "<workflow.yaml>"
+		if index.Workflow != "" {
+			workflowPath = filepath.Join(runDir, filepath.FromSlash(index.Workflow))
+		}
+		fmt.Fprintf(out, "Steps not completed: %s\n", strings.Join(pending, ", "))
+		fmt.Fprintf(out, "Resume with: studioflowai run -w %s --retry -o %s -n <step>\n", workflowPath, runDir)
+		return nil
+	},
+}
+
+// defaultPromptsDir returns the prompts of the active project, or ./prompts when it exists
+func defaultPromptsDir() string {
+	if ws := utils.ActiveWorkspace(); ws != nil && ws.PromptsDir != "" {
+		return ws.PromptsDir
+	}
+	if info, err := os.Stat("prompts"); err == nil && info.IsDir() {
+		return "prompts"
+	}
+	return ""
+}
+
+func init() {
+	exportRunCmd.Flags().StringVar(&exportOut, "out", "", "Bundle file to write: .tar.zst, .tar.gz or .tar (default: <run folder>.tar.zst, or .tar.gz without zstd)")
+	exportRunCmd.Flags().StringSliceVar(&exportInclude, "include", nil, "Glob patterns of the artifacts to bundle, relative to the run folder (default: all)")
+	exportRunCmd.Flags().StringVar(&exportPrompts, "prompts", "", "Directory with the prompts used by the run (default: the project prompts or ./prompts)")
+	exportRunCmd.Flags().BoolVar(&exportNoPrompt, "no-prompts", false, "Do not bundle prompt files")
+	exportRunCmd.Flags().StringVarP(&exportWorkflow, "workflow", "w", "", "Workflow file of the run, bundled so it can be retried after import")
+	rootCmd.AddCommand(exportRunCmd)
+
+	importRunCmd.Flags().StringVarP(&importDest, "dest", "d", "", "Directory to extract the run folder into (default: the project output or the current directory)")
+	importRunCmd.Flags().BoolVar(&importForce, "force", false, "Replace an existing run folder with the same name")
+	rootCmd.AddCommand(importRunCmd)
+}
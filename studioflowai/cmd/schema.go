@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/workflow"
+
+	"github.com/spf13/cobra"
+)
+
+var schemaOutputPath string
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema for workflow files",
+	Long: `Print a JSON Schema describing workflow files and the parameters of every module.
+Point your editor's YAML language server at it to get completion and inline errors.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		schema, err := workflow.JSONSchema()
+		if err != nil {
+			return err
+		}
+
+		data, err := json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode schema: %w", err)
+		}
+		data = append(data, '\n')
+
+		if schemaOutputPath == "" {
+			_, err = cmd.OutOrStdout().Write(data)
+			return err
+		}
+
+		if err := os.WriteFile(schemaOutputPath, data, 0644); err != nil {
+			return fmt.Errorf("failed to write schema: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+
+	schemaCmd.Flags().StringVarP(&schemaOutputPath, "output", "o", "", "Write the schema to a file instead of stdout")
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/api"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/workflow"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveAddr        string
+	serveAllowOrigin string
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the HTTP API for workflow editors",
+	Long: `Serve an HTTP API for UIs that build workflow editors:
+
+  GET /modules               Every module with its parameters, inputs, outputs and parameter schema
+  GET /modules/{name}        One module
+  POST /workflows/validate   Problems of the workflow YAML in the body, as "validate --diagnostics"
+
+The API listens on localhost unless --addr says otherwise.`,
+	Example: `  studioflowai serve
+  studioflowai serve --addr 0.0.0.0:8080 --allow-origin http://localhost:5173`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		registry, err := workflow.NewRegistry()
+		if err != nil {
+			return err
+		}
+
+		server := &http.Server{
+			Addr:              serveAddr,
+			Handler:           api.WithCORS(api.NewHandler(registry), serveAllowOrigin),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = server.Shutdown(shutdownCtx)
+		}()
+
+		utils.LogInfo("Serving the API on http://%s", serveAddr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("failed to serve the API: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "127.0.0.1:8080", "Address to listen on")
+	serveCmd.Flags().StringVar(&serveAllowOrigin, "allow-origin", "", "Origin of the web pages allowed to call the API, or * for any")
+}
@@ -0,0 +1,351 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	suggestshorts "github.com/gnzdotmx/studioflowai/studioflowai/internal/modules/suggest_shorts"
+	chatgpt "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/chatgpt"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/services/sheets"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	regenShortsFile  string
+	regenClips       []int
+	regenAll         bool
+	regenBatchSize   int
+	regenInstruction string
+	regenFields      string
+	regenTranscript  string
+	regenModel       string
+
+	tableShortsFile string
+	tableCSV        string
+	tableSheet      string
+	tableTab        string
+	tableCreds      string
+
+	migrateShortsFile string
+
+	editClip       int
+	editSet        []string
+	editUnset      []string
+	editShift      time.Duration
+	editShiftStart time.Duration
+	editShiftEnd   time.Duration
+)
+
+var shortsCmd = &cobra.Command{
+	Use:   "shorts",
+	Short: "Work with generated shorts suggestion files",
+	Long:  `Inspect and edit shorts suggestion YAML files produced by the suggest_shorts module.`,
+}
+
+var shortsRegenCmd = &cobra.Command{
+	Use:   "regen",
+	Short: "Regenerate the metadata of clips",
+	Long: `Re-run the metadata generation (title, short title, description, tags) for clips
+of an existing shorts YAML file and update them in place. Other clips, timestamps and
+rendered videos are left untouched.
+
+Several clips (--clip 1,3,5 or --all) are sent to the model in batches of --batch-size
+clips, one request per batch, instead of one request per clip.`,
+	Example: `  studioflowai shorts regen -f output/run/shorts_suggestions.yaml --clip 3 --instruction "make the title punchier"
+  studioflowai shorts regen -f shorts.yaml --clip 1 --fields shortTitle --transcript transcript_corrected.txt
+  studioflowai shorts regen -f shorts.yaml --all --fields title,tags --instruction "add the guest's name"`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if regenShortsFile == "" {
+			return fmt.Errorf("shorts file is required (--file)")
+		}
+		if regenAll && len(regenClips) > 0 {
+			return fmt.Errorf("--clip and --all cannot be used together")
+		}
+		if !regenAll && len(regenClips) == 0 {
+			return fmt.Errorf("clip number is required (--clip or --all)")
+		}
+		for _, clip := range regenClips {
+			if clip < 1 {
+				return fmt.Errorf("clip number must be 1 or greater (--clip)")
+			}
+		}
+
+		service, err := chatgpt.NewChatGPTService()
+		if err != nil {
+			return fmt.Errorf("failed to initialize ChatGPT service: %w", err)
+		}
+
+		var fields []string
+		if regenFields != "" {
+			fields = strings.Split(regenFields, ",")
+		}
+
+		opts := suggestshorts.RegenOptions{
+			Instruction:    regenInstruction,
+			Fields:         fields,
+			TranscriptFile: regenTranscript,
+			Model:          regenModel,
+			BatchSize:      regenBatchSize,
+		}
+
+		out := cmd.OutOrStdout()
+		if len(regenClips) == 1 {
+			opts.Clip = regenClips[0]
+			clip, err := suggestshorts.RegenerateClip(cmd.Context(), service, regenShortsFile, opts)
+			if err != nil {
+				return err
+			}
+			printRegeneratedClip(out, regenClips[0], clip)
+			return nil
+		}
+
+		// Clips are numbered as in the file, in the order they are returned
+		opts.Clips = regenClips
+		clips, err := suggestshorts.RegenerateClips(cmd.Context(), service, regenShortsFile, opts)
+		if err != nil {
+			return err
+		}
+		numbers := slices.Compact(slices.Sorted(slices.Values(regenClips)))
+		for i := range clips {
+			number := i + 1
+			if len(numbers) > 0 {
+				number = numbers[i]
+			}
+			printRegeneratedClip(out, number, &clips[i])
+		}
+		return nil
+	},
+}
+
+// printRegeneratedClip shows the new metadata of a clip
+func printRegeneratedClip(out io.Writer, number int, clip *suggestshorts.ShortClip) {
+	fmt.Fprintf(out, "Clip %d (%s - %s)\n", number, clip.StartTime, clip.EndTime)
+	fmt.Fprintf(out, "  Title:       %s\n", clip.Title)
+	fmt.Fprintf(out, "  Short title: %s\n", clip.ShortTitle)
+	fmt.Fprintf(out, "  Description: %s\n", clip.Description)
+	fmt.Fprintf(out, "  Tags:        %s\n", clip.Tags)
+}
+
+var shortsExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the clips of a shorts file to CSV or Google Sheets",
+	Long: `Write one row per clip, with a column per field, so the metadata can be reviewed and
+edited in a spreadsheet. Nested fields such as per-platform captions become dotted
+columns like "tiktok.caption". Import the edited table back with "shorts import".`,
+	Example: `  studioflowai shorts export -f output/run/shorts_suggestions.yaml --csv shorts.csv
+  studioflowai shorts export -f shorts.yaml --sheet 1AbC...xyz --tab ep12 --credentials ~/client_secret.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkTableTarget(); err != nil {
+			return err
+		}
+
+		if tableCSV != "" {
+			if err := suggestshorts.ExportShortsCSV(tableShortsFile, tableCSV); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Wrote %s\n", tableCSV)
+			return nil
+		}
+
+		rows, err := suggestshorts.ShortsTable(tableShortsFile)
+		if err != nil {
+			return err
+		}
+		service, err := newSheetsService(cmd)
+		if err != nil {
+			return err
+		}
+		if err := service.WriteRows(cmd.Context(), tableSheet, tableTab, rows); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Wrote %d clips to sheet %s (%s)\n", len(rows)-1, tableSheet, tableTab)
+		return nil
+	},
+}
+
+var shortsImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Apply an edited CSV or Google Sheets export to a shorts file",
+	Long: `Read a table written by "shorts export" and update the shorts file in place. Rows are
+matched to clips by the clip column; edited timestamps are validated and fields added
+by other steps are kept.`,
+	Example: `  studioflowai shorts import -f output/run/shorts_suggestions.yaml --csv shorts.csv
+  studioflowai shorts import -f shorts.yaml --sheet 1AbC...xyz --tab ep12 --credentials ~/client_secret.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkTableTarget(); err != nil {
+			return err
+		}
+
+		var changed int
+		if tableCSV != "" {
+			n, err := suggestshorts.ImportShortsCSV(tableShortsFile, tableCSV)
+			if err != nil {
+				return err
+			}
+			changed = n
+		} else {
+			service, err := newSheetsService(cmd)
+			if err != nil {
+				return err
+			}
+			rows, err := service.ReadRows(cmd.Context(), tableSheet, tableTab)
+			if err != nil {
+				return err
+			}
+			if changed, err = suggestshorts.ApplyShortsTable(tableShortsFile, rows); err != nil {
+				return err
+			}
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Updated %d clips in %s\n", changed, tableShortsFile)
+		return nil
+	},
+}
+
+var shortsMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Rewrite a shorts file in the current schema version",
+	Long: fmt.Sprintf(`Rewrite a shorts file in schema version %d: legacy spellings such as short_title
+become shortTitle, tag lists become one string and schemaVersion is added. Every step
+reads older files the same way, so this is only needed to edit them by hand or to
+share them with other tools. Fields added by other steps are kept.`, utils.ShortsSchemaVersion),
+	Example: `  studioflowai shorts migrate -f output/run/shorts_suggestions.yaml`,
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		doc, err := utils.ReadShortsDocument(migrateShortsFile)
+		if err != nil {
+			return err
+		}
+		if !doc.Migrated {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s is already at schema version %d\n", migrateShortsFile, utils.ShortsSchemaVersion)
+			return nil
+		}
+
+		data, err := doc.Marshal()
+		if err != nil {
+			return fmt.Errorf("failed to encode shorts file: %w", err)
+		}
+		if err := utils.AtomicWriteFile(migrateShortsFile, data, 0644); err != nil {
+			return fmt.Errorf("failed to write shorts file: %w", err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Migrated %s to schema version %d\n", migrateShortsFile, utils.ShortsSchemaVersion)
+		return nil
+	},
+}
+
+var shortsEditCmd = &cobra.Command{
+	Use:   "edit <file>",
+	Short: "Change a field or the timestamps of a clip",
+	Long: `Edit one clip of a shorts file in place without opening the YAML. Fields are set as
+field=value, with dotted names for nested fields such as tiktok.caption; timestamps are
+validated, the clip must still end after it starts, and the file is left untouched when
+an edit is invalid. --shift moves the whole clip, --shift-start and --shift-end one edge.`,
+	Example: `  studioflowai shorts edit output/run/shorts_suggestions.yaml --clip 2 --set title="Why we rewrote it in Go" --shift-start -3s
+  studioflowai shorts edit shorts.yaml --clip 4 --set tiktok.caption="Wait for it" --unset blocked
+  studioflowai shorts edit shorts.yaml --clip 1 --shift 1.5s`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		edit := suggestshorts.ClipEdit{
+			Clip:       editClip,
+			Set:        make(map[string]string),
+			ShiftStart: editShift + editShiftStart,
+			ShiftEnd:   editShift + editShiftEnd,
+		}
+		for _, assignment := range editSet {
+			field, value, ok := strings.Cut(assignment, "=")
+			if field = strings.TrimSpace(field); !ok || field == "" {
+				return fmt.Errorf("invalid --set %q (expected field=value)", assignment)
+			}
+			edit.Set[field] = value
+		}
+		for _, field := range editUnset {
+			edit.Unset = append(edit.Unset, strings.TrimSpace(field))
+		}
+		if len(edit.Set) == 0 && len(edit.Unset) == 0 && edit.ShiftStart == 0 && edit.ShiftEnd == 0 {
+			return fmt.Errorf("nothing to change (use --set, --unset or a shift)")
+		}
+
+		fields, err := suggestshorts.EditClip(args[0], edit)
+		if err != nil {
+			return err
+		}
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "Clip %d (%s - %s)\n", editClip, fields["startTime"], fields["endTime"])
+		for _, field := range slices.Sorted(maps.Keys(fields)) {
+			if field != "startTime" && field != "endTime" && field != "excerpt" {
+				fmt.Fprintf(out, "  %s: %s\n", field, fields[field])
+			}
+		}
+		return nil
+	},
+}
+
+// checkTableTarget requires exactly one of --csv and --sheet
+func checkTableTarget() error {
+	if (tableCSV == "") == (tableSheet == "") {
+		return fmt.Errorf("use either --csv or --sheet")
+	}
+	return nil
+}
+
+// newSheetsService creates a Google Sheets client from --credentials or GOOGLE_APPLICATION_CREDENTIALS
+func newSheetsService(cmd *cobra.Command) (*sheets.Service, error) {
+	credentials := tableCreds
+	if credentials == "" {
+		credentials = os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	}
+	if credentials == "" {
+		return nil, fmt.Errorf("credentials file is required for Google Sheets (--credentials)")
+	}
+	expanded, err := utils.ExpandHomeDir(credentials)
+	if err != nil {
+		return nil, err
+	}
+	return sheets.NewService(cmd.Context(), expanded)
+}
+
+func init() {
+	rootCmd.AddCommand(shortsCmd)
+	shortsCmd.AddCommand(shortsRegenCmd, shortsExportCmd, shortsImportCmd, shortsMigrateCmd, shortsEditCmd)
+
+	shortsRegenCmd.Flags().StringVarP(&regenShortsFile, "file", "f", "", "Path to the shorts suggestions YAML file")
+	shortsRegenCmd.Flags().IntSliceVar(&regenClips, "clip", nil, "1-based numbers of the clips to regenerate, e.g. 3 or 1,3,5")
+	shortsRegenCmd.Flags().BoolVar(&regenAll, "all", false, "Regenerate every clip of the file")
+	shortsRegenCmd.Flags().IntVar(&regenBatchSize, "batch-size", 8, "Clips sent to the model in one request when regenerating several")
+	shortsRegenCmd.Flags().StringVar(&regenInstruction, "instruction", "", "Extra instruction for the model, e.g. \"make the title punchier\"")
+	shortsRegenCmd.Flags().StringVar(&regenFields, "fields", "", "Comma-separated fields to regenerate: title, shortTitle, description, tags (default: all)")
+	shortsRegenCmd.Flags().StringVar(&regenTranscript, "transcript", "", "Optional transcript file used as context (default: the excerpt saved with the clip)")
+	shortsRegenCmd.Flags().StringVar(&regenModel, "model", "gpt-4o", "OpenAI model to use")
+
+	_ = shortsRegenCmd.MarkFlagRequired("file")
+
+	shortsMigrateCmd.Flags().StringVarP(&migrateShortsFile, "file", "f", "", "Path to the shorts suggestions YAML file")
+	_ = shortsMigrateCmd.MarkFlagRequired("file")
+
+	shortsEditCmd.Flags().IntVar(&editClip, "clip", 0, "1-based number of the clip to edit")
+	shortsEditCmd.Flags().StringArrayVar(&editSet, "set", nil, "Field to set as field=value, e.g. title=\"New title\" or tiktok.caption=... (repeatable)")
+	shortsEditCmd.Flags().StringArrayVar(&editUnset, "unset", nil, "Field to remove, e.g. blocked (repeatable)")
+	shortsEditCmd.Flags().DurationVar(&editShift, "shift", 0, "Move the whole clip, e.g. 2s or -1.5s")
+	shortsEditCmd.Flags().DurationVar(&editShiftStart, "shift-start", 0, "Move the start of the clip, e.g. -3s")
+	shortsEditCmd.Flags().DurationVar(&editShiftEnd, "shift-end", 0, "Move the end of the clip, e.g. 2s")
+	_ = shortsEditCmd.MarkFlagRequired("clip")
+
+	for _, c := range []*cobra.Command{shortsExportCmd, shortsImportCmd} {
+		c.Flags().StringVarP(&tableShortsFile, "file", "f", "", "Path to the shorts suggestions YAML file")
+		c.Flags().StringVar(&tableCSV, "csv", "", "CSV file to write or read")
+		c.Flags().StringVar(&tableSheet, "sheet", "", "Google Sheets spreadsheet ID to write or read")
+		c.Flags().StringVar(&tableTab, "tab", "Shorts", "Sheet tab holding the clips")
+		c.Flags().StringVar(&tableCreds, "credentials", "", "Google OAuth client credentials file (default: $GOOGLE_APPLICATION_CREDENTIALS)")
+		_ = c.MarkFlagRequired("file")
+	}
+}
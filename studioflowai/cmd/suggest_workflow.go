@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/workflow"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	suggestOutput   string
+	suggestLanguage string
+	suggestNoDetect bool
+	suggestForce    bool
+)
+
+var suggestWorkflowCmd = &cobra.Command{
+	Use:     "suggest_workflow <input>",
+	Aliases: []string{"suggest-workflow"},
+	Short:   "Propose a workflow for a recording",
+	Long: `Inspect a recording and write a workflow for it to edit. The duration, the streams and the
+language heard in a 30-second sample of the audio choose the steps and tune their defaults:
+
+  - videos get shorts, fitted into 1080x1920 unless already vertical; audio gets an article
+  - English recordings use an English-only Whisper model, long ones the faster turbo model
+  - the transcript is corrected in chunks sized for the length of the recording
+  - one short is suggested per 6 minutes of recording, between 3 and 20
+
+The reason of each tuned value is written as a comment above it. Language detection needs
+whisper; --language sets the language without it.`,
+	Example: `  studioflowai suggest_workflow input/ep12.mp4
+  studioflowai suggest-workflow interview.mp3 --language Spanish -o workflows/interview.yaml`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		input := args[0]
+		if _, err := os.Stat(input); err != nil {
+			return fmt.Errorf("input not found: %w", err)
+		}
+
+		media, err := workflow.InspectMedia(cmd.Context(), input, suggestLanguage == "" && !suggestNoDetect)
+		if err != nil {
+			return err
+		}
+		if suggestLanguage != "" {
+			media.Language = suggestLanguage
+		}
+		utils.LogInfo("%s: %s", filepath.Base(input), media)
+
+		data, err := workflow.SuggestWorkflow(media).YAML()
+		if err != nil {
+			return err
+		}
+
+		output := suggestOutput
+		if output == "" {
+			output = strings.TrimSuffix(filepath.Base(input), filepath.Ext(input)) + "_workflow.yaml"
+		}
+		if _, err := os.Stat(output); err == nil && !suggestForce {
+			return fmt.Errorf("%s already exists (use --force to replace it)", output)
+		}
+		if err := utils.EnsureDir(filepath.Dir(output)); err != nil {
+			return err
+		}
+		if err := os.WriteFile(output, data, 0644); err != nil {
+			return fmt.Errorf("failed to write workflow: %w", err)
+		}
+
+		utils.LogSuccess("Wrote %s; edit it, then run it with: studioflowai run -w %s -i %s", output, output, input)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(suggestWorkflowCmd)
+
+	suggestWorkflowCmd.Flags().StringVarP(&suggestOutput, "out", "o", "", "Workflow file to write (default: <input name>_workflow.yaml)")
+	suggestWorkflowCmd.Flags().StringVar(&suggestLanguage, "language", "", "Language of the recording, e.g. Spanish, instead of detecting it")
+	suggestWorkflowCmd.Flags().BoolVar(&suggestNoDetect, "no-detect", false, "Leave the language to detection at transcription time")
+	suggestWorkflowCmd.Flags().BoolVar(&suggestForce, "force", false, "Replace the workflow file if it exists")
+}
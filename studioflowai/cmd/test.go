@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/config"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/workflow"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	testFixturesDir string
+	testInputPath   string
+	testOutputPath  string
+)
+
+var testCmd = &cobra.Command{
+	Use:   "test <workflow.yaml>",
+	Short: "Test a workflow with fixture outputs instead of running its steps",
+	Long: `Run a workflow with every step replaced by the outputs in a fixtures folder, to check a
+custom workflow, its variable interpolation and how its steps are wired without running any
+external tool or calling any API.
+
+The fixtures folder holds a fixtures.yaml and the files it refers to:
+
+  input: episode.mp4              # Optional workflow input, relative to the folder
+  steps:
+    Transcribe:
+      outputs:
+        transcript: transcript.srt   # Copied into the run folder as the step's output
+      expect:
+        input: ${output}/audio.wav   # What the step's parameter must resolve to
+
+Every step needs an entry, even an empty one. The test fails when a step has no fixture,
+when a parameter does not resolve to its expected value, or when a fixture names a step
+the workflow does not have. Outputs are written to a temporary folder unless --output is set.`,
+	Example: `  studioflowai test workflows/episode.yaml --fixtures tests/episode`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fixtures, err := workflow.LoadFixtures(testFixturesDir)
+		if err != nil {
+			return err
+		}
+
+		input := testInputPath
+		if input == "" {
+			input = fixtures.InputPath()
+		}
+		output := testOutputPath
+		if output == "" {
+			if output, err = os.MkdirTemp("", "studioflowai-test-"); err != nil {
+				return fmt.Errorf("failed to create test folder: %w", err)
+			}
+			defer func() {
+				if err := os.RemoveAll(output); err != nil {
+					utils.LogWarning("Failed to remove test folder %s: %v", output, err)
+				}
+			}()
+		}
+
+		inputConfig, err := config.NewInputConfig(input, output, args[0], false, "", "")
+		if err != nil {
+			return fmt.Errorf("invalid input configuration: %w", err)
+		}
+		wf, err := workflow.LoadFromFile(inputConfig)
+		if err != nil {
+			return fmt.Errorf("failed to load workflow: %w", err)
+		}
+
+		report, runErr := wf.ExecuteWithFixtures(fixtures)
+		out := cmd.OutOrStdout()
+		for _, step := range report.Steps {
+			status := "ok"
+			if len(step.Problems) > 0 {
+				status = "FAIL"
+			}
+			fmt.Fprintf(out, "%-4s %s (%s)\n", status, step.Name, step.Module)
+			if step.Input != "" {
+				fmt.Fprintf(out, "     input: %s\n", step.Input)
+			}
+			names := make([]string, 0, len(step.Outputs))
+			for name := range step.Outputs {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				fmt.Fprintf(out, "     output %s: %s\n", name, step.Outputs[name])
+			}
+			for _, problem := range step.Problems {
+				fmt.Fprintf(out, "     %s\n", problem)
+			}
+		}
+		for _, problem := range report.Problems {
+			fmt.Fprintf(out, "FAIL %s\n", problem)
+		}
+
+		if runErr != nil {
+			return fmt.Errorf("workflow test failed: %w", runErr)
+		}
+		if report.Failed() {
+			return fmt.Errorf("workflow test failed")
+		}
+		utils.LogSuccess("Workflow test passed: %d steps", len(report.Steps))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(testCmd)
+
+	testCmd.Flags().StringVar(&testFixturesDir, "fixtures", "", "Folder with fixtures.yaml and the fixture outputs (required)")
+	testCmd.Flags().StringVarP(&testInputPath, "input", "i", "", "Input file path (overrides the fixtures and the workflow file)")
+	testCmd.Flags().StringVarP(&testOutputPath, "output", "o", "", "Folder to write the test run to and keep (default: a temporary folder)")
+	_ = testCmd.MarkFlagRequired("fixtures")
+}
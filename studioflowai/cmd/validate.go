@@ -1,19 +1,50 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 
 	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
 	"github.com/gnzdotmx/studioflowai/studioflowai/internal/validator"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/workflow"
 
 	"github.com/spf13/cobra"
 )
 
+var (
+	validateWorkflowPath string
+	validateInputPath    string
+	validateDiagnostics  bool
+)
+
 var validateCmd = &cobra.Command{
 	Use:   "validate",
 	Short: "Validate environment setup",
-	Long:  `Check if all required external tools and configurations are properly set up.`,
+	Long: `Check if all required external tools and configurations are properly set up.
+When a workflow file is given, it is also checked against the workflow schema and the
+parameter checks of its modules. Inputs produced by earlier steps need not exist yet.
+
+With --diagnostics, only the workflow is checked, as editors do while it is written: every
+problem is printed as JSON with its line and a code, and no input file needs to exist. Use
+"-w -" to read the workflow from stdin.`,
+	Example: `  studioflowai validate -w workflow.yaml
+  cat workflow.yaml | studioflowai validate -w - --diagnostics`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if validateDiagnostics {
+			return printDiagnostics(cmd)
+		}
+
+		// Validate the workflow file first; it needs no external tools
+		if validateWorkflowPath != "" {
+			utils.LogInfo("Validating workflow %s...", validateWorkflowPath)
+			if err := workflow.ValidateFile(validateWorkflowPath, validateInputPath); err != nil {
+				return fmt.Errorf("workflow validation failed: %w", err)
+			}
+			utils.LogSuccess("Workflow: OK")
+		}
+
 		utils.LogInfo("Validating environment...")
 
 		// Validate external tools (ffmpeg, etc.)
@@ -33,6 +64,44 @@ var validateCmd = &cobra.Command{
 	},
 }
 
+// printDiagnostics prints the diagnostics of the workflow as JSON and fails when any is an error
+func printDiagnostics(cmd *cobra.Command) error {
+	if validateWorkflowPath == "" {
+		return fmt.Errorf("--diagnostics needs a workflow: -w file, or -w - for stdin")
+	}
+	var data []byte
+	var err error
+	if validateWorkflowPath == "-" {
+		data, err = io.ReadAll(cmd.InOrStdin())
+	} else {
+		data, err = os.ReadFile(validateWorkflowPath)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read workflow: %w", err)
+	}
+
+	registry, err := workflow.NewRegistry()
+	if err != nil {
+		return err
+	}
+	report := workflow.Diagnose(data, registry)
+	out, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode diagnostics: %w", err)
+	}
+	if _, err := fmt.Fprintln(cmd.OutOrStdout(), string(out)); err != nil {
+		return err
+	}
+	if !report.Valid {
+		return fmt.Errorf("workflow has errors")
+	}
+	return nil
+}
+
 func init() {
 	rootCmd.AddCommand(validateCmd)
+
+	validateCmd.Flags().StringVarP(&validateWorkflowPath, "workflow", "w", "", "Path to a workflow YAML file to check against the schema")
+	validateCmd.Flags().BoolVar(&validateDiagnostics, "diagnostics", false, "Print the problems of the workflow as JSON, without checking inputs or the environment")
+	validateCmd.Flags().StringVarP(&validateInputPath, "input", "i", "", "Input file path the workflow will run with (overrides the one in workflow file)")
 }
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/config"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/validator"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/workflow"
+	"github.com/google/uuid"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	watchConfigPath   string
+	watchWorkflowPath string
+	watchOnce         bool
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch [folder]",
+	Short: "Run workflows on the videos dropped in a folder",
+	Long: `Watch a folder and run a workflow on every video dropped in it. Each file runs once; it
+runs again only when it is replaced.
+
+Routing rules in watch.yaml let one folder serve several shows with different workflows:
+
+  interval: 30s          # Time between scans
+  minAge: 2m             # Leave files alone until they have not changed for this long
+  minSize: 50MB          # Leave smaller files alone
+  ignore: ["drafts/**"]  # Never run these; hidden and partial downloads are always ignored
+  routes:                # Tried in order; the first match runs the file
+    - match: ["podcast/**/*.mp4", "**/podcast-*.mov"]
+      workflow: workflows/podcast.yaml
+    - match: ["**/*.mp4"]
+      ignore: ["**/*_raw.mp4"]
+      workflow: workflows/shorts.yaml
+
+Paths in watch.yaml are relative to it. Without --config, watch.yaml is read from the folder,
+or --workflow runs every .mp4, .mov and .mkv file through one workflow. Files already run are
+listed in .studioflowai-watch.json in the folder.`,
+	Example: `  studioflowai watch ./incoming
+  studioflowai watch ./incoming --workflow workflows/episode.yaml
+  studioflowai watch --config shows/watch.yaml --once`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		folder := "."
+		if len(args) > 0 {
+			folder = args[0]
+		}
+
+		var watch *workflow.WatchConfig
+		var err error
+		switch {
+		case watchConfigPath != "" && watchWorkflowPath != "":
+			return fmt.Errorf("--config and --workflow cannot be used together")
+		case watchWorkflowPath != "":
+			watch, err = workflow.NewWatchConfig(folder, watchWorkflowPath)
+		case watchConfigPath != "":
+			watch, err = workflow.LoadWatchConfig(watchConfigPath)
+			if err == nil && len(args) > 0 {
+				watch.Folder = folder
+			}
+		default:
+			watch, err = workflow.LoadWatchConfig(filepath.Join(folder, workflow.WatchFileName))
+		}
+		if err != nil {
+			return err
+		}
+		if info, err := os.Stat(watch.Folder); err != nil || !info.IsDir() {
+			return fmt.Errorf("watch folder %s does not exist", watch.Folder)
+		}
+
+		if err := validator.ValidateExternalTools(); err != nil {
+			return fmt.Errorf("dependency validation failed: %w", err)
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+
+		utils.LogInfo("Watching %s every %s: %s", watch.Folder, watch.Interval, watch)
+		for {
+			files, err := watch.Pending(time.Now())
+			if err != nil {
+				return err
+			}
+			for _, file := range files {
+				if ctx.Err() != nil {
+					return nil
+				}
+				status := "completed"
+				utils.LogInfo("Running %s for %s", file.Route.Name, file.Rel)
+				if err := runWatchedFile(file); err != nil {
+					status = "failed"
+					utils.LogError("Workflow %s failed for %s: %v", file.Route.Name, file.Rel, err)
+				}
+				if err := watch.Record(file, status); err != nil {
+					return err
+				}
+			}
+
+			if watchOnce {
+				return nil
+			}
+			select {
+			case <-ctx.Done():
+				utils.LogInfo("Stopped watching %s", watch.Folder)
+				return nil
+			case <-time.After(watch.Interval):
+			}
+		}
+	},
+}
+
+// runWatchedFile runs the workflow of a file's route on it, in a fresh run folder
+func runWatchedFile(file workflow.WatchFile) error {
+	inputConfig, err := config.NewInputConfig(file.Path, "", file.Route.Workflow, false, "", "")
+	if err != nil {
+		return fmt.Errorf("invalid input configuration: %w", err)
+	}
+	if project := config.ActiveProject(); project != nil {
+		inputConfig.OutputRoot = project.OutputPath()
+	}
+	inputConfig.RunID = time.Now().Format("20060102-150405")
+	inputConfig.ShortID = uuid.New().String()[:8]
+	utils.SetRunSeed(utils.RandomSeed())
+
+	wf, err := workflow.LoadFromFile(inputConfig)
+	if err != nil {
+		return fmt.Errorf("failed to load workflow: %w", err)
+	}
+	if inputConfig.OutputPath != "" {
+		utils.LogInfo("Writing outputs to %s", inputConfig.OutputPath)
+	}
+	return wf.Execute()
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+
+	watchCmd.Flags().StringVarP(&watchConfigPath, "config", "c", "", "Routing file (default: watch.yaml in the folder)")
+	watchCmd.Flags().StringVarP(&watchWorkflowPath, "workflow", "w", "", "Run every video through this workflow instead of routing")
+	watchCmd.Flags().BoolVar(&watchOnce, "once", false, "Run the files that are ready and exit instead of watching")
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/scheduler"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/worker"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/workflow"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	workerAddr   string
+	workerDir    string
+	workerLimits map[string]int
+)
+
+var workerCmd = &cobra.Command{
+	Use:   "worker",
+	Short: "Run the steps other machines send to this one",
+	Long: `Serve the modules over gRPC, so workflows run elsewhere can send their heavy steps
+(transcription, rendering) to this machine. A step is sent with its input files, runs in a job
+folder, and its log and the files it writes are sent back; the run folder, state and manifest
+stay on the machine running the workflow.
+
+Steps of different classes run at once: by default one gpu step (transcription), one cpu step
+(rendering) per 8 CPUs, two io steps and four api steps (LLM calls, uploads). --limit changes
+them; a step waiting for its class is told so, and higher step priorities go first.
+
+Set ` + worker.TokenEnv + ` on both machines to require a token.
+The connection is not encrypted: use it on a trusted network or through an SSH tunnel.`,
+	Example: `  ` + worker.TokenEnv + `=secret studioflowai worker --addr 0.0.0.0:7070
+  studioflowai worker --limit gpu=2 --limit api=8
+  studioflowai run -w workflow.yaml --worker gpu=gpu-box:7070`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		registry, err := workflow.NewRegistry()
+		if err != nil {
+			return err
+		}
+		token := os.Getenv(worker.TokenEnv)
+		if token == "" {
+			utils.LogWarning("%s is not set: any machine that reaches %s can run steps here", worker.TokenEnv, workerAddr)
+		}
+
+		limits, err := scheduler.ParseLimits(workerLimits)
+		if err != nil {
+			return err
+		}
+
+		listener, err := net.Listen("tcp", workerAddr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", workerAddr, err)
+		}
+		server := worker.NewServer(registry, workerDir, token, limits).GRPCServer()
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			server.GracefulStop()
+		}()
+
+		utils.LogInfo("Running steps sent to %s (at once: %s)", listener.Addr(), limits)
+		if err := server.Serve(listener); err != nil {
+			return fmt.Errorf("failed to serve the worker: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(workerCmd)
+
+	workerCmd.Flags().StringVar(&workerAddr, "addr", "127.0.0.1:7070", "Address to listen on")
+	workerCmd.Flags().StringToIntVar(&workerLimits, "limit", nil, "Steps of a class run at once, as class=n for gpu, cpu, io or api (repeatable)")
+	workerCmd.Flags().StringVar(&workerDir, "dir", "", "Folder of the job folders (default: the system's temporary folder)")
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/workflow"
+)
+
+// ModuleInfo describes a module for UIs that build workflows: its parameters as documented by
+// "modules describe", the inputs and outputs it declares, and the JSON Schema of its step parameters
+type ModuleInfo struct {
+	Name            string                 `json:"name"`
+	Parameters      []Parameter            `json:"parameters"`
+	RequiredInputs  []IO                   `json:"requiredInputs"`
+	OptionalInputs  []IO                   `json:"optionalInputs"`
+	ProducedOutputs []IO                   `json:"producedOutputs"`
+	Schema          map[string]interface{} `json:"schema"`
+}
+
+// Parameter is a parameter of a module's steps
+type Parameter struct {
+	Name        string `json:"name"`
+	Kind        string `json:"kind"`
+	Default     string `json:"default,omitempty"`
+	Required    bool   `json:"required"`
+	Description string `json:"description,omitempty"`
+}
+
+// IO is an input or output declared by a module
+type IO struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Patterns    []string `json:"patterns,omitempty"`
+	Type        string   `json:"type"`
+}
+
+// maxWorkflowSize is the largest workflow accepted for validation
+const maxWorkflowSize = 1 << 20
+
+// errorResponse is the body of failed requests
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewHandler returns the HTTP API of the modules of a registry:
+//
+//	GET /modules               every module, sorted by name
+//	GET /modules/{name}        one module
+//	POST /workflows/validate   diagnostics of the workflow YAML in the body, see workflow.Diagnose
+func NewHandler(registry *mod.ModuleRegistry) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /modules", func(w http.ResponseWriter, r *http.Request) {
+		modules := registry.ListModules()
+		infos := make([]ModuleInfo, 0, len(modules))
+		for _, m := range modules {
+			infos = append(infos, DescribeModule(m))
+		}
+		sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+		writeJSON(w, http.StatusOK, infos)
+	})
+	mux.HandleFunc("GET /modules/{name}", func(w http.ResponseWriter, r *http.Request) {
+		m, err := registry.Get(r.PathValue("name"))
+		if err != nil {
+			writeJSON(w, http.StatusNotFound, errorResponse{Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, DescribeModule(m))
+	})
+	mux.HandleFunc("POST /workflows/validate", func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWorkflowSize))
+		if err != nil {
+			writeJSON(w, http.StatusRequestEntityTooLarge, errorResponse{Error: "workflow is too large"})
+			return
+		}
+		writeJSON(w, http.StatusOK, workflow.Diagnose(data, registry))
+	})
+	return mux
+}
+
+// DescribeModule returns the description of a module served by the API
+func DescribeModule(m mod.Module) ModuleInfo {
+	doc := mod.DescribeModule(m)
+	io := m.GetIO()
+	info := ModuleInfo{
+		Name:            doc.Name,
+		Parameters:      make([]Parameter, 0, len(doc.Params)),
+		RequiredInputs:  ioList(io.RequiredInputs, nil),
+		OptionalInputs:  ioList(io.OptionalInputs, nil),
+		ProducedOutputs: ioList(nil, io.ProducedOutputs),
+		Schema:          workflow.ParamsJSONSchema(m),
+	}
+	for _, p := range doc.Params {
+		info.Parameters = append(info.Parameters, Parameter{
+			Name:        p.Name,
+			Kind:        string(p.Kind),
+			Default:     p.Default,
+			Required:    p.Required,
+			Description: p.Description,
+		})
+	}
+	return info
+}
+
+// ioList converts declared inputs or outputs, never returning nil so lists encode as []
+func ioList(inputs []mod.ModuleInput, outputs []mod.ModuleOutput) []IO {
+	list := make([]IO, 0, len(inputs)+len(outputs))
+	for _, in := range inputs {
+		list = append(list, IO{Name: in.Name, Description: in.Description, Patterns: in.Patterns, Type: in.Type})
+	}
+	for _, out := range outputs {
+		list = append(list, IO{Name: out.Name, Description: out.Description, Patterns: out.Patterns, Type: out.Type})
+	}
+	return list
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		utils.LogError("Failed to write response: %v", err)
+	}
+}
+
+// WithCORS lets pages served from origin call the API from a browser; "*" allows any origin
+func WithCORS(h http.Handler, origin string) http.Handler {
+	if origin == "" {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serve(t *testing.T, method, path string) *httptest.ResponseRecorder {
+	return serveBody(t, method, path, "")
+}
+
+func serveBody(t *testing.T, method, path, body string) *httptest.ResponseRecorder {
+	registry, err := workflow.NewRegistry()
+	require.NoError(t, err)
+	rec := httptest.NewRecorder()
+	WithCORS(NewHandler(registry), "*").ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return rec
+}
+
+func TestModules(t *testing.T) {
+	rec := serve(t, http.MethodGet, "/modules")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+
+	var modules []ModuleInfo
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &modules))
+	require.NotEmpty(t, modules)
+	for i := 1; i < len(modules); i++ {
+		assert.Less(t, modules[i-1].Name, modules[i].Name, "modules are sorted by name")
+	}
+}
+
+func TestModule(t *testing.T) {
+	rec := serve(t, http.MethodGet, "/modules/extract_shorts")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var info ModuleInfo
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &info))
+	assert.Equal(t, "extract_shorts", info.Name)
+	assert.NotEmpty(t, info.RequiredInputs)
+	assert.NotEmpty(t, info.ProducedOutputs)
+
+	var mode *Parameter
+	for i := range info.Parameters {
+		if info.Parameters[i].Name == "mode" {
+			mode = &info.Parameters[i]
+		}
+	}
+	require.NotNil(t, mode)
+	assert.Equal(t, Parameter{Name: "mode", Kind: "string", Default: "full"}, *mode)
+
+	properties, ok := info.Schema["properties"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Contains(t, properties, "mode")
+	assert.Equal(t, false, info.Schema["additionalProperties"])
+}
+
+func TestModule_Errors(t *testing.T) {
+	rec := serve(t, http.MethodGet, "/modules/missing")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Contains(t, rec.Body.String(), "missing")
+
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(t, http.MethodPost, "/modules").Code)
+	assert.Equal(t, http.StatusNoContent, serve(t, http.MethodOptions, "/modules").Code)
+}
+
+func TestValidateWorkflow(t *testing.T) {
+	rec := serveBody(t, http.MethodPost, "/workflows/validate", `name: Draft
+steps:
+  - name: Transcribe
+    module: transcribe
+    parameters:
+      input: episode.wav
+      modle: large
+`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var report workflow.DiagnosticReport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.False(t, report.Valid)
+	require.Len(t, report.Diagnostics, 1)
+	assert.Equal(t, workflow.IssueUnknownParam, report.Diagnostics[0].Code)
+	assert.Equal(t, "Transcribe", report.Diagnostics[0].Step)
+	assert.Equal(t, 7, report.Diagnostics[0].Line)
+}
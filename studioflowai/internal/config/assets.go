@@ -0,0 +1,235 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// assetsFileName is the name of the assets manifest in the global config directory and in each project
+const assetsFileName = "assets.yaml"
+
+// assetRefPattern matches ${asset:name} references in workflow parameters
+var assetRefPattern = regexp.MustCompile(`\$\{asset:([A-Za-z0-9_.-]+)\}`)
+
+// Asset is a named file used by the rendering steps, such as a font, logo, intro or music track
+type Asset struct {
+	Type   string `yaml:"type,omitempty"`   // font, logo, intro, outro, music or theme; for reference
+	Path   string `yaml:"path,omitempty"`   // Local file, relative to the manifest
+	URL    string `yaml:"url,omitempty"`    // Where to download the file from when it has no path
+	SHA256 string `yaml:"sha256,omitempty"` // Pinned checksum; a file that differs is rejected
+
+	// dir is the directory of the manifest declaring the asset
+	dir string
+}
+
+// Assets are the named assets available to a run
+type Assets map[string]*Asset
+
+// assetsManifest is the layout of assets.yaml
+type assetsManifest struct {
+	Assets map[string]*Asset `yaml:"assets"`
+}
+
+// LoadAssets reads the global assets manifest and then the project's, whose entries replace global
+// ones of the same name. Missing manifests are not an error.
+func LoadAssets(project *Project) (Assets, error) {
+	globalDir, err := utils.GlobalConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	dirs := []string{globalDir}
+	if project != nil {
+		dirs = append(dirs, project.Dir)
+	}
+
+	assets := Assets{}
+	for _, dir := range dirs {
+		if err := assets.load(filepath.Join(dir, assetsFileName)); err != nil {
+			return nil, err
+		}
+	}
+	return assets, nil
+}
+
+// load adds the assets of a manifest file, if it exists
+func (a Assets) load(manifestPath string) error {
+	data, err := os.ReadFile(manifestPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read assets manifest: %w", err)
+	}
+
+	var manifest assetsManifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", manifestPath, err)
+	}
+	for name, asset := range manifest.Assets {
+		if asset == nil || (asset.Path == "") == (asset.URL == "") {
+			return fmt.Errorf("%s: asset %s needs either a path or a url", manifestPath, name)
+		}
+		asset.SHA256 = strings.ToLower(asset.SHA256)
+		asset.dir = filepath.Dir(manifestPath)
+		a[name] = asset
+	}
+	return nil
+}
+
+// Names returns the names of the assets in alphabetical order
+func (a Assets) Names() []string {
+	names := make([]string, 0, len(a))
+	for name := range a {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// File returns where the asset is on disk: its path, or its place in the download cache
+func (asset *Asset) File() (string, error) {
+	if asset.Path != "" {
+		if filepath.IsAbs(asset.Path) {
+			return asset.Path, nil
+		}
+		return filepath.Join(asset.dir, asset.Path), nil
+	}
+
+	globalDir, err := utils.GlobalConfigDir()
+	if err != nil {
+		return "", err
+	}
+	// Downloads are kept per URL so assets of different projects never overwrite each other
+	sum := sha256.Sum256([]byte(asset.URL))
+	name := "asset"
+	if u, err := url.Parse(asset.URL); err == nil && path.Base(u.Path) != "/" && path.Base(u.Path) != "." {
+		name = path.Base(u.Path)
+	}
+	return filepath.Join(globalDir, "assets", hex.EncodeToString(sum[:8]), name), nil
+}
+
+// Resolve returns the file of a named asset, downloading it on first use and checking it against
+// its pinned checksum
+func (a Assets) Resolve(ctx context.Context, name string) (string, error) {
+	asset, ok := a[name]
+	if !ok {
+		return "", fmt.Errorf("unknown asset %q (declare it in %s)", name, assetsFileName)
+	}
+	file, err := asset.File()
+	if err != nil {
+		return "", err
+	}
+
+	if asset.URL != "" {
+		// A cached download that no longer matches the pin is fetched again
+		if _, statErr := os.Stat(file); statErr == nil && asset.verify(file) == nil {
+			return file, nil
+		}
+		utils.LogInfoContext(ctx, "Downloading asset %s from %s", name, asset.URL)
+		if err := asset.download(ctx, file); err != nil {
+			return "", fmt.Errorf("asset %s: %w", name, err)
+		}
+	}
+
+	if err := asset.verify(file); err != nil {
+		return "", fmt.Errorf("asset %s: %w", name, err)
+	}
+	return file, nil
+}
+
+// Expand replaces the ${asset:name} references in a value with the files of the assets
+func (a Assets) Expand(ctx context.Context, value string) (string, error) {
+	var firstErr error
+	expanded := assetRefPattern.ReplaceAllStringFunc(value, func(ref string) string {
+		file, err := a.Resolve(ctx, assetRefPattern.FindStringSubmatch(ref)[1])
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		return file
+	})
+	if firstErr != nil {
+		return "", firstErr
+	}
+	return expanded, nil
+}
+
+// HasAssetRef reports whether a value refers to an asset as ${asset:name}
+func HasAssetRef(value string) bool {
+	return assetRefPattern.MatchString(value)
+}
+
+// verify checks the file against the pinned checksum, if any
+func (asset *Asset) verify(file string) error {
+	if _, err := os.Stat(file); err != nil {
+		return fmt.Errorf("file not found: %w", err)
+	}
+	if asset.SHA256 == "" {
+		return nil
+	}
+	sum, err := utils.FileChecksum(file)
+	if err != nil {
+		return err
+	}
+	if sum != asset.SHA256 {
+		return fmt.Errorf("checksum of %s is %s, but %s is pinned", file, sum, asset.SHA256)
+	}
+	return nil
+}
+
+// download fetches the asset's URL into file, which is only replaced once the download is complete
+// and matches the pinned checksum
+func (asset *Asset) download(ctx context.Context, file string) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("failed to create asset cache: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, asset.URL, nil)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	resp, err := utils.NewHTTPClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("download failed: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			utils.LogWarningContext(ctx, "Failed to close response body: %v", err)
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download failed: %s", resp.Status)
+	}
+
+	out, err := utils.CreateAtomicFile(file, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := out.Close(); err != nil {
+			utils.LogWarningContext(ctx, "Failed to close asset file: %v", err)
+		}
+	}()
+
+	hash := sha256.New()
+	if _, err := io.Copy(out, io.TeeReader(resp.Body, hash)); err != nil {
+		return fmt.Errorf("download failed: %w", err)
+	}
+	if sum := hex.EncodeToString(hash.Sum(nil)); asset.SHA256 != "" && sum != asset.SHA256 {
+		return fmt.Errorf("downloaded file has checksum %s, but %s is pinned", sum, asset.SHA256)
+	}
+	return out.Commit()
+}
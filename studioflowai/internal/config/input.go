@@ -5,6 +5,15 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+// Ways to handle the artifacts a retried step produced in the previous attempt
+const (
+	InvalidateMove   = "move"   // Move them to .stale/<timestamp>/ in the run folder
+	InvalidateDelete = "delete" // Delete them
+	InvalidateKeep   = "keep"   // Leave them in place
 )
 
 // InputConfig holds the configuration for input files and directories
@@ -14,19 +23,31 @@ type InputConfig struct {
 	WorkflowPath  string
 	RetryMode     bool
 	WorkflowName  string
+	Invalidate    string // How stale artifacts of retried steps are handled (default: move)
 	InputFileName string
 	InputFileType string
 	InputFileExt  string
+
+	// A new run folder is created under OutputRoot when OutputPath is empty, named after
+	// OutputName or else the workflow's or project's pattern
+	OutputRoot string
+	OutputName string
+	RunID      string // {run.id} of the folder name
+	ShortID    string // {run.shortid} of the folder name
+
+	// Addresses of workers by name, added to or replacing the workflow's workers
+	Workers map[string]string
 }
 
 // NewInputConfig creates a new input configuration
-func NewInputConfig(inputPath, outputPath, workflowPath string, retryMode bool, workflowName string) (*InputConfig, error) {
+func NewInputConfig(inputPath, outputPath, workflowPath string, retryMode bool, workflowName, invalidate string) (*InputConfig, error) {
 	config := &InputConfig{
 		InputPath:    inputPath,
 		OutputPath:   outputPath,
 		WorkflowPath: workflowPath,
 		RetryMode:    retryMode,
 		WorkflowName: workflowName,
+		Invalidate:   invalidate,
 	}
 
 	if err := config.validate(); err != nil {
@@ -67,7 +88,7 @@ func (c *InputConfig) validate() error {
 				return fmt.Errorf("failed to access output path: %w", err)
 			}
 			// Create output directory if it doesn't exist
-			if err := os.MkdirAll(c.OutputPath, 0755); err != nil {
+			if err := utils.EnsureDir(c.OutputPath); err != nil {
 				return fmt.Errorf("failed to create output directory: %w", err)
 			}
 		} else if !fileInfo.IsDir() {
@@ -85,6 +106,15 @@ func (c *InputConfig) validate() error {
 		}
 	}
 
+	// Validate stale artifact handling
+	switch c.Invalidate {
+	case "":
+		c.Invalidate = InvalidateMove
+	case InvalidateMove, InvalidateDelete, InvalidateKeep:
+	default:
+		return fmt.Errorf("invalid invalidate mode %q (supported: %s, %s, %s)", c.Invalidate, InvalidateMove, InvalidateDelete, InvalidateKeep)
+	}
+
 	return nil
 }
 
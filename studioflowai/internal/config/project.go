@@ -0,0 +1,259 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// projectFileName is the name of the configuration file inside a project directory
+const projectFileName = "project.yaml"
+
+// activeProject is the project selected with --project, if any
+var activeProject *Project
+
+// projectNamePattern restricts project names to safe directory names
+var projectNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// Project is an isolated workspace for one client or channel.
+// Tokens, quota state, prompts, credentials and outputs all live under its directory.
+type Project struct {
+	Name            string                `yaml:"name"`
+	Description     string                `yaml:"description,omitempty"`
+	OutputRoot      string                `yaml:"outputRoot,omitempty"` // Root for run folders (default: output)
+	OutputName      string                `yaml:"outputName,omitempty"` // Pattern of the run folder names (default: {workflow}-{run.id})
+	PromptsDir      string                `yaml:"promptsDir,omitempty"` // Prompt files overriding ./prompts (default: prompts)
+	Accounts        ProjectAccounts       `yaml:"accounts,omitempty"`
+	Series          *Series               `yaml:"series,omitempty"`          // Episode numbering and title pattern of the project's show
+	WhisperProfiles map[string]string     `yaml:"whisperProfiles,omitempty"` // Whisper parameters per language, plus "default"
+	Theme           string                `yaml:"theme,omitempty"`           // Theme file (.ass or .yaml) with the fonts and colors of the rendering steps
+	TranscriptFixes []utils.TranscriptFix `yaml:"transcriptFixes,omitempty"` // Fixes of systematic transcription errors, run by the transcribe steps
+	Permissions     *utils.Permissions    `yaml:"permissions,omitempty"`     // Modes and group of the files and folders runs write, for shared output folders
+
+	// Dir is the project directory; relative paths above are resolved against it
+	Dir string `yaml:"-"`
+}
+
+// ProjectAccounts holds the platform accounts a project publishes to
+type ProjectAccounts struct {
+	YouTube YouTubeAccount `yaml:"youtube,omitempty"`
+	TikTok  TikTokAccount  `yaml:"tiktok,omitempty"`
+}
+
+// YouTubeAccount identifies the YouTube channel of a project
+type YouTubeAccount struct {
+	Channel     string `yaml:"channel,omitempty"`     // Channel name, for reference
+	Credentials string `yaml:"credentials,omitempty"` // Google OAuth client credentials file
+	Locale      string `yaml:"locale,omitempty"`      // Language of the channel's audience, e.g. "es"; shorts copy is localized to it
+}
+
+// TikTokAccount identifies the TikTok account of a project
+type TikTokAccount struct {
+	Username string `yaml:"username,omitempty"` // Account name, for reference; keys go in the project's .env
+	Locale   string `yaml:"locale,omitempty"`   // Language of the account's audience, e.g. "en"; shorts copy is localized to it
+}
+
+// ProjectsDir returns the directory containing all projects
+func ProjectsDir() (string, error) {
+	globalDir, err := utils.GlobalConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(globalDir, "projects"), nil
+}
+
+// ValidateProjectName checks that a project name can be used as a directory name
+func ValidateProjectName(name string) error {
+	if !projectNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid project name %q: use letters, digits, '-' and '_'", name)
+	}
+	return nil
+}
+
+// LoadProject reads the configuration of a named project
+func LoadProject(name string) (*Project, error) {
+	if err := ValidateProjectName(name); err != nil {
+		return nil, err
+	}
+	projectsDir, err := ProjectsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Join(projectsDir, name)
+	data, err := os.ReadFile(filepath.Join(dir, projectFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("project %s does not exist (create it with: studioflowai project create %s)", name, name)
+		}
+		return nil, fmt.Errorf("failed to read project file: %w", err)
+	}
+
+	var project Project
+	if err := yaml.Unmarshal(data, &project); err != nil {
+		return nil, fmt.Errorf("failed to parse project file: %w", err)
+	}
+	project.Name = name
+	project.Dir = dir
+
+	return &project, nil
+}
+
+// CreateProject creates the directory layout and configuration of a new project
+func CreateProject(project *Project) error {
+	if err := ValidateProjectName(project.Name); err != nil {
+		return err
+	}
+	projectsDir, err := ProjectsDir()
+	if err != nil {
+		return err
+	}
+
+	project.Dir = filepath.Join(projectsDir, project.Name)
+	if _, err := os.Stat(filepath.Join(project.Dir, projectFileName)); err == nil {
+		return fmt.Errorf("project %s already exists", project.Name)
+	}
+
+	if project.OutputRoot == "" {
+		project.OutputRoot = "output"
+	}
+	if project.PromptsDir == "" {
+		project.PromptsDir = "prompts"
+	}
+
+	// Tokens are stored in the project directory, so keep it private
+	if err := os.MkdirAll(project.Dir, 0700); err != nil {
+		return fmt.Errorf("failed to create project directory: %w", err)
+	}
+	for _, dir := range []string{project.OutputPath(), project.PromptsPath()} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+	}
+
+	data, err := yaml.Marshal(project)
+	if err != nil {
+		return fmt.Errorf("failed to marshal project: %w", err)
+	}
+	if err := utils.AtomicWriteFile(filepath.Join(project.Dir, projectFileName), data, 0600); err != nil {
+		return fmt.Errorf("failed to write project file: %w", err)
+	}
+
+	envPath := filepath.Join(project.Dir, ".env")
+	if _, err := os.Stat(envPath); os.IsNotExist(err) {
+		template := "# Credentials for this project only. Keys listed here override the global .env,\n" +
+			"# and an empty value means the key is unset for this project.\n" +
+			"OPENAI_API_KEY=\n" +
+			"TIKTOK_CLIENT_KEY=\n" +
+			"TIKTOK_CLIENT_SECRET=\n"
+		if err := os.WriteFile(envPath, []byte(template), 0600); err != nil {
+			return fmt.Errorf("failed to write project .env: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ListProjects returns the names of all projects
+func ListProjects() ([]string, error) {
+	projectsDir, err := ProjectsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(projectsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read projects directory: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(projectsDir, entry.Name(), projectFileName)); err == nil {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// ActiveProject returns the project selected for this run, or nil when none is selected
+func ActiveProject() *Project {
+	return activeProject
+}
+
+// resolve returns a project-relative path as an absolute path
+func (p *Project) resolve(path, fallback string) string {
+	if path == "" {
+		path = fallback
+	}
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(p.Dir, path)
+}
+
+// OutputPath returns the root directory for the project's run folders
+func (p *Project) OutputPath() string {
+	return p.resolve(p.OutputRoot, "output")
+}
+
+// PromptsPath returns the directory with the project's prompt overrides
+func (p *Project) PromptsPath() string {
+	return p.resolve(p.PromptsDir, "prompts")
+}
+
+// YouTubeCredentialsPath returns the project's Google credentials file, if configured
+func (p *Project) YouTubeCredentialsPath() string {
+	if p.Accounts.YouTube.Credentials == "" {
+		return ""
+	}
+	return p.resolve(p.Accounts.YouTube.Credentials, "")
+}
+
+// ThemePath returns the project's theme file, if configured. A ${asset:name} reference is
+// returned as it is, for the workflow to resolve.
+func (p *Project) ThemePath() string {
+	if p.Theme == "" || HasAssetRef(p.Theme) {
+		return p.Theme
+	}
+	return p.resolve(p.Theme, "")
+}
+
+// Activate makes the project the active workspace: its .env overrides the global
+// credentials, and tokens, quota state and prompts are read from its directory.
+func (p *Project) Activate() error {
+	envPath := filepath.Join(p.Dir, ".env")
+	if _, err := os.Stat(envPath); err == nil {
+		values, err := godotenv.Read(envPath)
+		if err != nil {
+			return fmt.Errorf("failed to load project .env: %w", err)
+		}
+		for key, value := range values {
+			// Empty entries clear the global value instead of silently inheriting another client's key
+			if err := os.Setenv(key, value); err != nil {
+				return fmt.Errorf("failed to set %s: %w", key, err)
+			}
+		}
+	}
+
+	activeProject = p
+	utils.SetWorkspace(&utils.Workspace{
+		Name:       p.Name,
+		ConfigDir:  p.Dir,
+		PromptsDir: p.PromptsPath(),
+	})
+	utils.LogVerbose("Using project %s (%s)", p.Name, p.Dir)
+	return nil
+}
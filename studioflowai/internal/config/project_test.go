@@ -0,0 +1,105 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useHome points the global config directory at a temporary home and restores the global
+// workspace after the test
+func useHome(t *testing.T) string {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Cleanup(func() {
+		activeProject = nil
+		utils.SetWorkspace(nil)
+	})
+	return home
+}
+
+func TestValidateProjectName(t *testing.T) {
+	for _, name := range []string{"acme", "Acme_Show-2", "7days"} {
+		assert.NoError(t, ValidateProjectName(name), name)
+	}
+	for _, name := range []string{"", "-acme", "_acme", "acme show", "../acme", "acme/show", ".", "acme.show"} {
+		assert.Error(t, ValidateProjectName(name), name)
+	}
+}
+
+func TestCreateProject(t *testing.T) {
+	home := useHome(t)
+
+	require.NoError(t, CreateProject(&Project{Name: "acme", Description: "Acme weekly"}))
+	dir := filepath.Join(home, ".studioflowai", "projects", "acme")
+	assert.DirExists(t, filepath.Join(dir, "output"))
+	assert.DirExists(t, filepath.Join(dir, "prompts"))
+	assert.FileExists(t, filepath.Join(dir, ".env"))
+
+	info, err := os.Stat(dir)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0700), info.Mode().Perm(), "the project directory holds tokens")
+
+	project, err := LoadProject("acme")
+	require.NoError(t, err)
+	assert.Equal(t, "Acme weekly", project.Description)
+	assert.Equal(t, dir, project.Dir)
+	assert.Equal(t, filepath.Join(dir, "output"), project.OutputPath())
+
+	names, err := ListProjects()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"acme"}, names)
+
+	assert.ErrorContains(t, CreateProject(&Project{Name: "acme"}), "already exists")
+	assert.ErrorContains(t, CreateProject(&Project{Name: "../escape"}), "invalid project name")
+	assert.NoDirExists(t, filepath.Join(home, ".studioflowai", "escape"))
+
+	_, err = LoadProject("missing")
+	assert.ErrorContains(t, err, "project missing does not exist")
+	_, err = LoadProject("../acme")
+	assert.ErrorContains(t, err, "invalid project name")
+}
+
+func TestProjectActivate(t *testing.T) {
+	home := useHome(t)
+	t.Setenv("OPENAI_API_KEY", "global-openai")
+	t.Setenv("TIKTOK_CLIENT_KEY", "global-tiktok")
+	t.Setenv("TIKTOK_CLIENT_SECRET", "global-secret")
+	t.Setenv("YOUTUBE_LOCALE", "global-locale")
+
+	project := &Project{Name: "acme"}
+	require.NoError(t, CreateProject(project))
+	env := "OPENAI_API_KEY=acme-openai\nTIKTOK_CLIENT_KEY=\nTIKTOK_CLIENT_SECRET=\n"
+	require.NoError(t, os.WriteFile(filepath.Join(project.Dir, ".env"), []byte(env), 0600))
+	prompt := filepath.Join(project.PromptsPath(), "suggest_shorts.yaml")
+	require.NoError(t, os.WriteFile(prompt, []byte("prompt"), 0644))
+
+	// Before activation the global workspace is used
+	configDir, err := utils.ConfigDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".studioflowai"), configDir)
+	assert.Equal(t, "prompts/suggest_shorts.yaml", utils.ResolvePromptPath("prompts/suggest_shorts.yaml"))
+
+	require.NoError(t, project.Activate())
+	assert.Same(t, project, ActiveProject())
+
+	assert.Equal(t, "acme-openai", os.Getenv("OPENAI_API_KEY"), "project keys override global ones")
+	value, set := os.LookupEnv("TIKTOK_CLIENT_KEY")
+	assert.True(t, set)
+	assert.Empty(t, value, "empty project keys clear global ones")
+	assert.Empty(t, os.Getenv("TIKTOK_CLIENT_SECRET"))
+	assert.Equal(t, "global-locale", os.Getenv("YOUTUBE_LOCALE"), "keys the project does not list are inherited")
+
+	configDir, err = utils.ConfigDir()
+	require.NoError(t, err)
+	assert.Equal(t, project.Dir, configDir)
+	assert.Equal(t, prompt, utils.ResolvePromptPath("prompts/suggest_shorts.yaml"))
+	assert.Equal(t, "prompts/blog_post.yaml", utils.ResolvePromptPath("prompts/blog_post.yaml"), "prompts the project does not override keep the default")
+	resolved, err := utils.ResolveProjectPath("${project}/credentials.json")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(project.Dir, "credentials.json"), resolved)
+}
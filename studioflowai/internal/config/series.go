@@ -0,0 +1,90 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+// seriesStateFileName is the file in the config directory holding the episode counters
+const seriesStateFileName = "series.json"
+
+// Series numbers the episodes of a show and names their titles after a common pattern
+type Series struct {
+	Name         string `yaml:"name,omitempty"`         // Show name, also used as {show} in the pattern
+	TitlePattern string `yaml:"titlePattern,omitempty"` // e.g. "EP{n}: {title}"
+	FirstEpisode int    `yaml:"firstEpisode,omitempty"` // Lowest number handed out; raise it to skip ahead (default: 1)
+}
+
+// seriesCounter is the persisted numbering of one series
+type seriesCounter struct {
+	Last int            `json:"last"` // Last episode number handed out
+	Runs map[string]int `json:"runs"` // Episode number of each run folder, so retries keep their number
+}
+
+// key identifies the series in the counter file
+func (s *Series) key() string {
+	if s.Name == "" {
+		return "default"
+	}
+	return s.Name
+}
+
+// AssignEpisode returns the episode number of a run and persists the series counter in the
+// active workspace. A run folder keeps its number across retries, and an explicit number
+// (greater than zero) is used as is; numbering continues after the highest number seen.
+func (s *Series) AssignEpisode(runDir string, explicit int) (int, error) {
+	configDir, err := utils.ConfigDir()
+	if err != nil {
+		return 0, err
+	}
+	statePath := filepath.Join(configDir, seriesStateFileName)
+
+	state := map[string]*seriesCounter{}
+	if data, err := os.ReadFile(statePath); err == nil {
+		if err := json.Unmarshal(data, &state); err != nil {
+			return 0, fmt.Errorf("failed to parse series counter %s: %w", statePath, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return 0, fmt.Errorf("failed to read series counter: %w", err)
+	}
+
+	counter := state[s.key()]
+	if counter == nil {
+		counter = &seriesCounter{}
+		state[s.key()] = counter
+	}
+	if counter.Runs == nil {
+		counter.Runs = make(map[string]int)
+	}
+
+	var episode int
+	switch {
+	case explicit > 0:
+		episode = explicit
+		counter.Last = max(counter.Last, explicit)
+	case counter.Runs[runDir] > 0 && runDir != "":
+		return counter.Runs[runDir], nil
+	default:
+		episode = max(counter.Last+1, s.FirstEpisode, 1)
+		counter.Last = episode
+	}
+	if runDir != "" {
+		counter.Runs[runDir] = episode
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal series counter: %w", err)
+	}
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		return 0, fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := utils.AtomicWriteFile(statePath, data, 0600); err != nil {
+		return 0, fmt.Errorf("failed to write series counter: %w", err)
+	}
+	return episode, nil
+}
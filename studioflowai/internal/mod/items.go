@@ -0,0 +1,55 @@
+package mod
+
+import (
+	"context"
+)
+
+// States of the items of a batch step
+const (
+	ItemSucceeded = "succeeded"
+	ItemFailed    = "failed"
+	ItemSkipped   = "skipped" // Not processed in this attempt, e.g. done by an earlier one
+)
+
+// ItemResult is the outcome of one item of a batch step, such as a clip render or an upload.
+// Modules that process items one by one report a failed item here instead of failing the
+// whole step, and the engine marks the step partially failed.
+type ItemResult struct {
+	ID     string `yaml:"id"` // Identifies the item across attempts, e.g. the clip's file name
+	Status string `yaml:"status"`
+	Error  string `yaml:"error,omitempty"`
+}
+
+// FailedItems returns the IDs of the items that failed
+func (r ModuleResult) FailedItems() []string {
+	var failed []string
+	for _, item := range r.Items {
+		if item.Status == ItemFailed {
+			failed = append(failed, item.ID)
+		}
+	}
+	return failed
+}
+
+// retryItemsKey is the context key of the items to retry
+type retryItemsKey struct{}
+
+// WithRetryItems returns a context telling the module to process only the given items, those
+// that failed in the previous attempt of the step
+func WithRetryItems(ctx context.Context, ids []string) context.Context {
+	if len(ids) == 0 {
+		return ctx
+	}
+	items := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		items[id] = true
+	}
+	return context.WithValue(ctx, retryItemsKey{}, items)
+}
+
+// RetryItems returns the items a module should process when a step is retried, or nil when
+// every item is to be processed
+func RetryItems(ctx context.Context) map[string]bool {
+	items, _ := ctx.Value(retryItemsKey{}).(map[string]bool)
+	return items
+}
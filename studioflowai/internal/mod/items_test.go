@@ -0,0 +1,27 @@
+package mod
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModuleResult_FailedItems(t *testing.T) {
+	result := ModuleResult{Items: []ItemResult{
+		{ID: "a.mp4", Status: ItemSucceeded},
+		{ID: "b.mp4", Status: ItemFailed, Error: "ffmpeg exited with status 1"},
+		{ID: "c.mp4", Status: ItemSkipped},
+		{ID: "d.mp4", Status: ItemFailed},
+	}}
+	assert.Equal(t, []string{"b.mp4", "d.mp4"}, result.FailedItems())
+	assert.Empty(t, ModuleResult{}.FailedItems())
+}
+
+func TestRetryItems(t *testing.T) {
+	assert.Nil(t, RetryItems(context.Background()), "every item is processed by default")
+	assert.Nil(t, RetryItems(WithRetryItems(context.Background(), nil)))
+
+	ctx := WithRetryItems(context.Background(), []string{"b.mp4", "d.mp4"})
+	assert.Equal(t, map[string]bool{"b.mp4": true, "d.mp4": true}, RetryItems(ctx))
+}
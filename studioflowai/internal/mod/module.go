@@ -4,9 +4,11 @@ package mod
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 	"sync"
+	"time"
 )
 
 // Module defines the interface that all modules must implement
@@ -55,9 +57,41 @@ type ModuleResult struct {
 	Outputs     map[string]string      // Map of output name to file/directory path
 	Metadata    map[string]interface{} // Additional metadata about the execution
 	Statistics  map[string]interface{} // Performance and other statistics
+	Stats       Stats                  // Statistics shared by all modules, aggregated into the run manifest
+	Items       []ItemResult           // Outcome of each item of a batch step; failed items make the step partially failed
 	NextModules []string               // Suggested next modules in workflow
 }
 
+// Stats is the statistics schema every module reports. Modules set Items, the number of things
+// they processed (clips, files, passages, uploads); the engine measures Duration, BytesIn and
+// BytesOut and adds the tokens, cost and tool versions recorded through the step's context.
+// Values a module sets itself are kept.
+type Stats struct {
+	Duration     time.Duration     `yaml:"duration"`               // Wall time of the step
+	BytesIn      int64             `yaml:"bytesIn"`                // Size of the input files
+	BytesOut     int64             `yaml:"bytesOut"`               // Size of the produced files
+	Items        int               `yaml:"items"`                  // Items processed
+	ToolVersions map[string]string `yaml:"toolVersions,omitempty"` // External tools run, with their versions
+	Tokens       int               `yaml:"tokens,omitempty"`       // LLM tokens used
+	CostUSD      float64           `yaml:"costUsd,omitempty"`      // Estimated LLM cost in US dollars
+}
+
+// Add accumulates other into s, for run totals
+func (s *Stats) Add(other Stats) {
+	s.Duration += other.Duration
+	s.BytesIn += other.BytesIn
+	s.BytesOut += other.BytesOut
+	s.Items += other.Items
+	s.Tokens += other.Tokens
+	s.CostUSD += other.CostUSD
+	for name, version := range other.ToolVersions {
+		if s.ToolVersions == nil {
+			s.ToolVersions = make(map[string]string)
+		}
+		s.ToolVersions[name] = version
+	}
+}
+
 // InputType defines the valid types of module inputs
 type InputType string
 
@@ -199,6 +233,17 @@ func (r *ModuleRegistry) Get(name string) (Module, error) {
 	return module, nil
 }
 
+// FromStepParam is the parameter the workflow engine sets, during validation, to the name of the
+// earlier step that produces a step's input
+const FromStepParam = "fromStep"
+
+// FromStep returns the earlier step declared as the producer of the step's input, or "" when the
+// input must already exist. Modules skip existence checks for produced inputs.
+func FromStep(params map[string]interface{}) string {
+	step, _ := params[FromStepParam].(string)
+	return step
+}
+
 // ParseParams converts generic parameter map to a specific struct for each module
 func ParseParams(params map[string]interface{}, target interface{}) error {
 	if params == nil {
@@ -218,12 +263,22 @@ func ParseParams(params map[string]interface{}, target interface{}) error {
 		return fmt.Errorf("target must be a pointer to a struct")
 	}
 
+	// Workflow files often quote numbers and booleans; convert them, or name the parameter at fault
+	params, err := coerceParams(params, reflect.TypeOf(target).Elem())
+	if err != nil {
+		return fmt.Errorf("error unmarshaling params: %w", err)
+	}
+
 	data, err := json.Marshal(params)
 	if err != nil {
 		return fmt.Errorf("error marshaling params: %w", err)
 	}
 
 	if err := json.Unmarshal(data, target); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			return fmt.Errorf("error unmarshaling params: parameter %q: expected %s, got %s", typeErr.Field, kindOf(typeErr.Type), typeErr.Value)
+		}
 		return fmt.Errorf("error unmarshaling params: %w", err)
 	}
 
@@ -0,0 +1,431 @@
+package mod
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ParamsProvider is implemented by modules that expose their parameter struct,
+// allowing workflows to be validated and documented without executing the module
+type ParamsProvider interface {
+	// ParamsTemplate returns a zero value of the module's parameter struct
+	ParamsTemplate() interface{}
+}
+
+// ParamKind describes the JSON type accepted by a module parameter
+type ParamKind string
+
+const (
+	ParamKindString  ParamKind = "string"
+	ParamKindInteger ParamKind = "integer"
+	ParamKindNumber  ParamKind = "number"
+	ParamKindBoolean ParamKind = "boolean"
+	ParamKindArray   ParamKind = "array"
+	ParamKindObject  ParamKind = "object"
+	ParamKindAny     ParamKind = "any"
+)
+
+// ParamField describes a single module parameter derived from its struct field
+type ParamField struct {
+	Name    string    // Parameter name as written in workflow files (json tag)
+	Field   string    // Go struct field name
+	Kind    ParamKind // Accepted JSON type
+	GoType  string    // Go type of the field
+	Default string    // Default value applied when the parameter is omitted (default tag)
+}
+
+// DescribeParams returns the parameters accepted by a module parameter struct
+func DescribeParams(template interface{}) []ParamField {
+	t := reflect.TypeOf(template)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields []ParamField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("json"); ok {
+			tagName := strings.Split(tag, ",")[0]
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+
+		fields = append(fields, ParamField{
+			Name:    name,
+			Field:   f.Name,
+			Kind:    kindOf(f.Type),
+			GoType:  f.Type.String(),
+			Default: f.Tag.Get("default"),
+		})
+	}
+
+	return fields
+}
+
+// kindOf maps a Go type to the JSON type it accepts
+func kindOf(t reflect.Type) ParamKind {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return kindOf(t.Elem())
+	case reflect.String:
+		return ParamKindString
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return ParamKindInteger
+	case reflect.Float32, reflect.Float64:
+		return ParamKindNumber
+	case reflect.Bool:
+		return ParamKindBoolean
+	case reflect.Slice, reflect.Array:
+		return ParamKindArray
+	case reflect.Map, reflect.Struct:
+		return ParamKindObject
+	default:
+		return ParamKindAny
+	}
+}
+
+// ParamDoc documents a module parameter for users writing workflows
+type ParamDoc struct {
+	ParamField
+	Description string // Description taken from the module's GetIO inputs
+	Required    bool   // Whether the parameter is listed as a required input
+}
+
+// ModuleDoc documents a module's parameters and produced outputs
+type ModuleDoc struct {
+	Name    string
+	Params  []ParamDoc
+	Outputs []ModuleOutput
+}
+
+// DescribeModule builds the documentation for a module from its parameter
+// struct tags and the inputs and outputs declared by GetIO
+func DescribeModule(m Module) ModuleDoc {
+	io := m.GetIO()
+	doc := ModuleDoc{Name: m.Name(), Outputs: io.ProducedOutputs}
+
+	descriptions := map[string]string{}
+	required := map[string]bool{}
+	for _, in := range io.OptionalInputs {
+		descriptions[in.Name] = in.Description
+	}
+	for _, in := range io.RequiredInputs {
+		descriptions[in.Name] = in.Description
+		required[in.Name] = true
+	}
+
+	provider, ok := m.(ParamsProvider)
+	if !ok {
+		return doc
+	}
+
+	for _, field := range DescribeParams(provider.ParamsTemplate()) {
+		doc.Params = append(doc.Params, ParamDoc{
+			ParamField:  field,
+			Description: descriptions[field.Name],
+			Required:    required[field.Name],
+		})
+	}
+
+	return doc
+}
+
+// ParamError reports a parameter value that does not fit the parameter's type
+type ParamError struct {
+	Param    string    // Parameter name, with the path into objects and lists, e.g. "effects.zooms[0]"
+	Expected ParamKind // Type the parameter accepts
+	Value    interface{}
+}
+
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("parameter %q: expected %s, got %s", e.Param, e.Expected, describeValue(e.Value))
+}
+
+// describeValue names the type of a workflow value and shows it, e.g. `string "40k"`
+func describeValue(v interface{}) string {
+	switch value := v.(type) {
+	case string:
+		return fmt.Sprintf("string %q", value)
+	case bool:
+		return fmt.Sprintf("boolean %t", value)
+	}
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Slice, reflect.Array:
+		return "a list"
+	case reflect.Map:
+		return "an object"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return fmt.Sprintf("integer %v", v)
+	case reflect.Float32, reflect.Float64:
+		return fmt.Sprintf("number %v", v)
+	}
+	return fmt.Sprintf("%T %v", v, v)
+}
+
+// paramFields maps the parameter names of a struct to their Go types, including those of
+// embedded structs, which JSON flattens into the parent
+func paramFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tagName := ""
+		if tag, ok := f.Tag.Lookup("json"); ok {
+			tagName = strings.Split(tag, ",")[0]
+		}
+		if tagName == "-" {
+			continue
+		}
+		if f.Anonymous && tagName == "" && f.Type.Kind() == reflect.Struct {
+			for name, ft := range paramFields(f.Type) {
+				if _, ok := fields[name]; !ok {
+					fields[name] = ft
+				}
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if tagName == "" {
+			tagName = f.Name
+		}
+		fields[tagName] = f.Type
+	}
+	return fields
+}
+
+// coerceParams returns a copy of params with the values workflow files commonly write with the
+// wrong type converted to the type of their parameter: "4000" for an integer, "true" or "yes" for
+// a boolean, a single value for a list. Values that cannot be converted are reported with a
+// ParamError; parameters the struct does not declare are kept as they are.
+func coerceParams(params map[string]interface{}, t reflect.Type) (map[string]interface{}, error) {
+	fields := paramFields(t)
+	coerced := make(map[string]interface{}, len(params))
+	for name, value := range params {
+		ft, ok := fields[name]
+		if !ok {
+			// encoding/json matches keys without regard to case
+			for field, candidate := range fields {
+				if strings.EqualFold(field, name) {
+					ft, ok = candidate, true
+					break
+				}
+			}
+		}
+		if !ok {
+			coerced[name] = value
+			continue
+		}
+		v, err := coerceValue(name, value, ft)
+		if err != nil {
+			return nil, err
+		}
+		coerced[name] = v
+	}
+	return coerced, nil
+}
+
+// Coercible reports whether ParseParams converts a value written as the string text to a
+// parameter of the given kind. Lists take any single value as a list of one.
+func Coercible(text string, kind ParamKind) bool {
+	var t reflect.Type
+	switch kind {
+	case ParamKindString, ParamKindArray, ParamKindAny:
+		return true
+	case ParamKindInteger:
+		t = reflect.TypeOf(int64(0))
+	case ParamKindNumber:
+		t = reflect.TypeOf(float64(0))
+	case ParamKindBoolean:
+		t = reflect.TypeOf(false)
+	default:
+		return false
+	}
+	_, err := coerceValue("", text, t)
+	return err == nil
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// coerceValue converts a value decoded from a workflow file to the kind of Go type t
+func coerceValue(name string, value interface{}, t reflect.Type) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	// Types that decode themselves accept whatever they accept
+	if reflect.PointerTo(t).Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return value, nil
+	}
+	fail := func() (interface{}, error) {
+		return nil, &ParamError{Param: name, Expected: kindOf(t), Value: value}
+	}
+	rv := reflect.ValueOf(value)
+
+	switch t.Kind() {
+	case reflect.String:
+		if _, ok := value.(string); !ok {
+			return fail()
+		}
+		return value, nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n float64
+		switch rv.Kind() {
+		case reflect.String:
+			text := strings.TrimSpace(rv.String())
+			i, err := strconv.ParseInt(text, 10, 64)
+			if err != nil {
+				f, ferr := strconv.ParseFloat(text, 64)
+				if ferr != nil {
+					return fail()
+				}
+				n = f
+			} else {
+				n = float64(i)
+			}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n = float64(rv.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			n = float64(rv.Uint())
+		case reflect.Float32, reflect.Float64:
+			n = rv.Float()
+		default:
+			return fail()
+		}
+		if n != math.Trunc(n) {
+			return fail()
+		}
+		if t.Kind() >= reflect.Uint && t.Kind() <= reflect.Uint64 {
+			if n < 0 || reflect.Zero(t).OverflowUint(uint64(n)) {
+				return fail()
+			}
+			return uint64(n), nil
+		}
+		if reflect.Zero(t).OverflowInt(int64(n)) {
+			return fail()
+		}
+		return int64(n), nil
+
+	case reflect.Float32, reflect.Float64:
+		switch rv.Kind() {
+		case reflect.String:
+			f, err := strconv.ParseFloat(strings.TrimSpace(rv.String()), 64)
+			if err != nil {
+				return fail()
+			}
+			return f, nil
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return float64(rv.Int()), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return float64(rv.Uint()), nil
+		case reflect.Float32, reflect.Float64:
+			return rv.Float(), nil
+		}
+		return fail()
+
+	case reflect.Bool:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			switch strings.ToLower(strings.TrimSpace(v)) {
+			case "true", "yes", "on", "1":
+				return true, nil
+			case "false", "no", "off", "0":
+				return false, nil
+			}
+		}
+		if rv.Kind() >= reflect.Int && rv.Kind() <= reflect.Int64 && (rv.Int() == 0 || rv.Int() == 1) {
+			return rv.Int() == 1, nil
+		}
+		return fail()
+
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// Byte slices are base64 text in JSON
+			return value, nil
+		}
+		var items []interface{}
+		switch rv.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < rv.Len(); i++ {
+				items = append(items, rv.Index(i).Interface())
+			}
+		case reflect.Map, reflect.Struct:
+			return fail()
+		default:
+			// A single value stands for a list of one
+			items = []interface{}{value}
+		}
+		result := make([]interface{}, len(items))
+		for i, item := range items {
+			v, err := coerceValue(fmt.Sprintf("%s[%d]", name, i), item, t.Elem())
+			if err != nil {
+				return nil, err
+			}
+			result[i] = v
+		}
+		return result, nil
+
+	case reflect.Map:
+		if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+			return fail()
+		}
+		if t.Elem().Kind() == reflect.Interface {
+			return value, nil
+		}
+		result := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			v, err := coerceValue(name+"."+key, iter.Value().Interface(), t.Elem())
+			if err != nil {
+				return nil, err
+			}
+			result[key] = v
+		}
+		return result, nil
+
+	case reflect.Struct:
+		if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+			return fail()
+		}
+		entries := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			entries[iter.Key().String()] = iter.Value().Interface()
+		}
+		coerced, err := coerceParams(entries, t)
+		if paramErr, ok := err.(*ParamError); ok {
+			paramErr.Param = name + "." + paramErr.Param
+		}
+		return coerced, err
+	}
+	return value, nil
+}
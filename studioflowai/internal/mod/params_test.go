@@ -0,0 +1,57 @@
+package mod
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type coercionParams struct {
+	MaxTokens   int      `json:"maxTokens"`
+	Temperature float64  `json:"temperature"`
+	Verbose     bool     `json:"verbose"`
+	Languages   []string `json:"languages"`
+	Effects     struct {
+		Zooms []int `json:"zooms"`
+	} `json:"effects"`
+}
+
+func TestParseParams_Coercion(t *testing.T) {
+	var p coercionParams
+	err := ParseParams(map[string]interface{}{
+		"maxTokens":   "4000",
+		"temperature": "0.5",
+		"verbose":     "yes",
+		"languages":   "en",
+		"effects":     map[string]interface{}{"zooms": []interface{}{"1", 2.0}},
+	}, &p)
+	require.NoError(t, err)
+	assert.Equal(t, 4000, p.MaxTokens)
+	assert.Equal(t, 0.5, p.Temperature)
+	assert.True(t, p.Verbose)
+	assert.Equal(t, []string{"en"}, p.Languages)
+	assert.Equal(t, []int{1, 2}, p.Effects.Zooms)
+}
+
+func TestParseParams_CoercionErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		want   string
+	}{
+		{"not a number", map[string]interface{}{"maxTokens": "40k"}, `parameter "maxTokens": expected integer, got string "40k"`},
+		{"fraction", map[string]interface{}{"maxTokens": 1.5}, `parameter "maxTokens": expected integer, got number 1.5`},
+		{"not a boolean", map[string]interface{}{"verbose": "maybe"}, `parameter "verbose": expected boolean, got string "maybe"`},
+		{"nested", map[string]interface{}{"effects": map[string]interface{}{"zooms": []interface{}{1, "x"}}},
+			`parameter "effects.zooms[1]": expected integer, got string "x"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p coercionParams
+			err := ParseParams(tt.params, &p)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
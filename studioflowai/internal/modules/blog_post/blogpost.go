@@ -0,0 +1,448 @@
+package blogpost
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	modules "github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	chatgpt "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/chatgpt"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+
+	"gopkg.in/yaml.v3"
+)
+
+// contextKey is a type for context keys
+type contextKey string
+
+// ChatGPTServiceKey is the context key for the ChatGPT service
+const ChatGPTServiceKey = contextKey("chatgpt_service")
+
+// Module implements blog article generation from a transcript
+type Module struct{}
+
+// Params contains the parameters for blog post generation
+type Params struct {
+	Input            string                 `json:"input"`                                             // Path to input transcript file
+	Output           string                 `json:"output"`                                            // Path to output directory
+	OutputFileName   string                 `json:"outputFileName"`                                    // Custom output file name (without extension)
+	Model            string                 `json:"model" default:"gpt-4o"`                            // OpenAI model to use (default: "gpt-4o")
+	FallbackModels   []string               `json:"fallbackModels"`                                    // Models tried in order when the primary model fails or returns an empty article
+	Temperature      float64                `json:"temperature" default:"0.7"`                         // Model temperature (default: 0.7)
+	MaxTokens        int                    `json:"maxTokens" default:"6000"`                          // Maximum tokens for the response (default: 6000)
+	RequestTimeoutMS int                    `json:"requestTimeoutMs" default:"180000"`                 // API request timeout in milliseconds (default: 180000)
+	Language         string                 `json:"language" default:"Spanish"`                        // Language for the article (default: "Spanish")
+	WordCount        int                    `json:"wordCount" default:"1500"`                          // Target article length in words (default: 1500)
+	Keywords         string                 `json:"keywords"`                                          // Comma-separated SEO keywords to target (optional)
+	PromptFilePath   string                 `json:"promptFilePath" default:"./prompts/blog_post.yaml"` // Path to custom prompt YAML file (default: "./prompts/blog_post.yaml")
+	Metadata         map[string]interface{} `json:"metadata"`                                          // Episode details (guest, episode number, recording date, links) for the prompt and front matter
+	MetadataFile     string                 `json:"metadataFile"`                                      // YAML file with episode details; inline metadata wins (optional)
+}
+
+// PromptData represents the structure of a YAML prompt template
+type PromptData struct {
+	Title       string `yaml:"title"`
+	Role        string `yaml:"role"`
+	Prompt      string `yaml:"prompt"`
+	Description string `yaml:"description"`
+}
+
+// New creates a new blog post module
+func New() modules.Module {
+	return &Module{}
+}
+
+// Name returns the module name
+func (m *Module) Name() string {
+	return "blog_post"
+}
+
+// ParamsTemplate returns the module's parameter struct, used to validate and document workflows
+func (m *Module) ParamsTemplate() interface{} {
+	return Params{}
+}
+
+// Validate checks if the parameters are valid
+func (m *Module) Validate(params map[string]interface{}) error {
+	var p Params
+	if err := modules.ParseParams(params, &p); err != nil {
+		return err
+	}
+
+	// Validate input path
+	if err := utils.ValidateInputPath(p.Input, p.Output, ""); err != nil {
+		return err
+	}
+
+	// Validate output path
+	if err := utils.ValidateOutputPath(p.Output); err != nil {
+		return err
+	}
+
+	// Check if the API key is set - just warn but don't error
+	if !chatgpt.IsAPIKeySet() {
+		utils.LogWarning("OPENAI_API_KEY environment variable is not set. A placeholder file will be generated.")
+	}
+
+	// If a custom prompt file path is provided, check if it exists
+	if p.PromptFilePath != "" {
+		if _, err := os.Stat(p.PromptFilePath); os.IsNotExist(err) {
+			return fmt.Errorf("prompt template file %s does not exist", p.PromptFilePath)
+		}
+	}
+
+	if p.WordCount < 0 {
+		return fmt.Errorf("wordCount cannot be negative: %d", p.WordCount)
+	}
+
+	if _, err := utils.ResolveEpisodeMetadata(p.Metadata, p.MetadataFile); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Execute generates a blog article from a transcript using ChatGPT
+func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (modules.ModuleResult, error) {
+	var p Params
+	if err := modules.ParseParams(params, &p); err != nil {
+		return modules.ModuleResult{}, err
+	}
+
+	// Set default values
+	if p.Model == "" {
+		p.Model = "gpt-4o"
+	}
+	if p.Temperature == 0 {
+		p.Temperature = 0.7
+	}
+	if p.MaxTokens == 0 {
+		p.MaxTokens = 6000
+	}
+	if p.RequestTimeoutMS == 0 {
+		p.RequestTimeoutMS = 180000
+	}
+	if p.Language == "" {
+		p.Language = "Spanish"
+	}
+	if p.WordCount == 0 {
+		p.WordCount = 1500
+	}
+	if p.PromptFilePath == "" {
+		p.PromptFilePath = utils.ResolvePromptPath("./prompts/blog_post.yaml")
+	}
+	metadata, err := utils.ResolveEpisodeMetadata(p.Metadata, p.MetadataFile)
+	if err != nil {
+		return modules.ModuleResult{}, err
+	}
+	p.Metadata = metadata
+
+	// Create output directory if it doesn't exist
+	if err := utils.EnsureDir(p.Output); err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// Resolve the input path if it contains ${output}
+	resolvedInput := utils.ResolveOutputPath(p.Input, p.Output)
+
+	// Verify input exists at execution time
+	fileInfo, err := os.Stat(resolvedInput)
+	if err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("input file not found: %w", err)
+	}
+
+	if fileInfo.IsDir() {
+		return modules.ModuleResult{}, fmt.Errorf("input must be a file, not a directory: %s", resolvedInput)
+	}
+
+	// Check if input is a text file
+	if !utils.IsTextFile(resolvedInput) {
+		return modules.ModuleResult{}, fmt.Errorf("file %s appears to be binary, not a text file", resolvedInput)
+	}
+
+	// Determine output file name
+	var outputPath string
+	if p.OutputFileName != "" {
+		outputPath = filepath.Join(p.Output, p.OutputFileName+".md")
+	} else {
+		baseFilename := filepath.Base(resolvedInput)
+		baseFilename = baseFilename[:len(baseFilename)-len(filepath.Ext(baseFilename))]
+		outputPath = filepath.Join(p.Output, baseFilename+"_blog.md")
+	}
+
+	article, usedModel, err := m.generateArticle(ctx, resolvedInput, p)
+	if err != nil {
+		return modules.ModuleResult{}, err
+	}
+
+	if err := utils.WriteTextFile(outputPath, article); err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to write output file: %w", err)
+	}
+
+	utils.LogSuccessContext(ctx, "Generated blog post for %s -> %s", resolvedInput, outputPath)
+
+	return modules.ModuleResult{
+		Outputs: map[string]string{
+			"blog_post": outputPath,
+		},
+		Metadata: map[string]interface{}{
+			"model": usedModel,
+		},
+		Statistics: map[string]interface{}{
+			"model":       usedModel,
+			"language":    p.Language,
+			"inputFile":   resolvedInput,
+			"outputFile":  outputPath,
+			"wordCount":   len(strings.Fields(article)),
+			"sections":    countSections(article),
+			"processTime": time.Now().Format(time.RFC3339),
+		},
+		Stats: modules.Stats{Items: 1},
+	}, nil
+}
+
+// GetIO returns the module's input/output specification
+func (m *Module) GetIO() modules.ModuleIO {
+	return modules.ModuleIO{
+		RequiredInputs: []modules.ModuleInput{
+			{
+				Name:        "input",
+				Description: "Path to input transcript file",
+				Patterns:    []string{".txt", ".srt"},
+				Type:        string(modules.InputTypeFile),
+			},
+			{
+				Name:        "output",
+				Description: "Path to output directory",
+				Type:        string(modules.InputTypeDirectory),
+			},
+		},
+		OptionalInputs: []modules.ModuleInput{
+			{
+				Name:        "outputFileName",
+				Description: "Custom output filename",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "promptFilePath",
+				Description: "Path to custom prompt YAML file",
+				Type:        string(modules.InputTypeFile),
+			},
+			{
+				Name:        "model",
+				Description: "OpenAI model to use",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "language",
+				Description: "Language for the article",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "wordCount",
+				Description: "Target article length in words",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "keywords",
+				Description: "Comma-separated SEO keywords to target",
+				Type:        string(modules.InputTypeData),
+			},
+		},
+		ProducedOutputs: []modules.ModuleOutput{
+			{
+				Name:        "blog_post",
+				Description: "Generated Markdown blog article",
+				Patterns:    []string{".md"},
+				Type:        string(modules.OutputTypeFile),
+			},
+		},
+	}
+}
+
+// generateArticle sends the transcript to ChatGPT and returns the Markdown article with the model that wrote it
+func (m *Module) generateArticle(ctx context.Context, inputPath string, p Params) (string, string, error) {
+	// Read the transcript file
+	transcript, err := utils.ReadTextFile(inputPath)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read transcript file: %w", err)
+	}
+	frontMatter, transcript := utils.SplitEpisodeFrontMatter(transcript)
+	metadata := utils.MergeEpisodeMetadata(frontMatter, p.Metadata)
+
+	// Without an API key, return a placeholder article so the rest of the workflow can run
+	if !chatgpt.IsAPIKeySet() {
+		utils.LogWarningContext(ctx, "No API key set - generating placeholder blog post")
+		article, err := utils.WithEpisodeFrontMatter(placeholderArticle(inputPath), metadata)
+		return article, p.Model, err
+	}
+
+	promptData := getPromptTemplate(ctx, p.PromptFilePath)
+
+	// Construct the full prompt
+	var prompt strings.Builder
+	prompt.WriteString(strings.TrimSpace(promptData.Prompt))
+	prompt.WriteString("\n\n")
+	prompt.WriteString(fmt.Sprintf("Language: %s\n", p.Language))
+	prompt.WriteString(fmt.Sprintf("Target length: about %d words\n", p.WordCount))
+	if p.Keywords != "" {
+		prompt.WriteString(fmt.Sprintf("Target SEO keywords: %s\n", p.Keywords))
+	}
+	if details := utils.EpisodeMetadataPrompt(metadata); details != "" {
+		prompt.WriteString("\n" + details)
+	}
+	prompt.WriteString("\nTranscript:\n")
+	prompt.WriteString(transcript)
+
+	messages := []chatgpt.ChatMessage{
+		{
+			Role:    "system",
+			Content: fmt.Sprintf("You are a %s. You turn video transcripts into well-structured, search-optimized long-form articles.", promptData.Role),
+		},
+		{
+			Role:    "user",
+			Content: prompt.String(),
+		},
+	}
+
+	// Initialize ChatGPT service
+	chatGPT, err := m.getChatGPTService(ctx)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to initialize ChatGPT service: %w", err)
+	}
+
+	utils.LogInfoContext(ctx, "Generating blog post using %s model...", p.Model)
+	// Each attempt is bounded by RequestTimeoutMS; empty articles move on to the next model
+	completion, err := chatgpt.CompleteWithFallback(ctx, chatGPT, messages, chatgpt.CompletionOptions{
+		Model:            p.Model,
+		Temperature:      p.Temperature,
+		MaxTokens:        p.MaxTokens,
+		RequestTimeoutMS: p.RequestTimeoutMS,
+	}, chatgpt.FallbackChain{Models: p.FallbackModels}, func(response string) error {
+		if stripMarkdownFence(response) == "" {
+			return fmt.Errorf("ChatGPT returned an empty article")
+		}
+		return nil
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("ChatGPT API request failed: %w", err)
+	}
+
+	// Episode details go into the article's front matter so static site generators can use them
+	article, err := utils.WithEpisodeFrontMatter(stripMarkdownFence(completion.Content)+"\n", metadata)
+	if err != nil {
+		return "", "", err
+	}
+	return article, completion.Model, nil
+}
+
+// stripMarkdownFence removes a surrounding ```markdown code fence if the model added one
+func stripMarkdownFence(content string) string {
+	content = strings.TrimSpace(content)
+	if !strings.HasPrefix(content, "```") {
+		return content
+	}
+
+	// Drop the opening fence line (e.g. ```markdown)
+	if idx := strings.Index(content, "\n"); idx != -1 {
+		content = content[idx+1:]
+	} else {
+		return ""
+	}
+
+	content = strings.TrimSuffix(strings.TrimSpace(content), "```")
+	return strings.TrimSpace(content)
+}
+
+// countSections returns the number of H2 sections in a Markdown article
+func countSections(article string) int {
+	count := 0
+	for _, line := range strings.Split(article, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "## ") {
+			count++
+		}
+	}
+	return count
+}
+
+// placeholderArticle returns example output used when no API key is configured
+func placeholderArticle(inputPath string) string {
+	return `---
+title: "MOCK OUTPUT - No OPENAI_API_KEY set"
+description: "Simulated example of a generated blog article."
+slug: "mock-blog-post"
+keywords: ["example", "placeholder"]
+---
+
+# MOCK OUTPUT - No OPENAI_API_KEY set
+
+Set the OPENAI_API_KEY environment variable to generate a real article from the transcript.
+
+## Example section
+
+> "A memorable quote from the episode would appear here."
+
+Section body generated from the transcript.
+
+**Image suggestions**
+- Image prompt: "A podcast studio with two microphones, warm lighting, photorealistic"
+- Stock search: "podcast interview studio"
+
+Transcript file: ` + inputPath + "\n"
+}
+
+// getPromptTemplate loads the prompt template from file, falling back to the default
+func getPromptTemplate(ctx context.Context, promptFilePath string) PromptData {
+	if data, err := os.ReadFile(promptFilePath); err == nil {
+		var promptData PromptData
+		if err := yaml.Unmarshal(data, &promptData); err == nil && strings.TrimSpace(promptData.Prompt) != "" {
+			if promptData.Role == "" {
+				promptData.Role = defaultRole
+			}
+			utils.LogDebugContext(ctx, "Using custom blog prompt template from YAML file: %s", promptFilePath)
+			return promptData
+		}
+		utils.LogWarningContext(ctx, "Failed to parse blog prompt %s, falling back to default", promptFilePath)
+	}
+
+	utils.LogDebugContext(ctx, "Using default blog prompt template")
+	return PromptData{
+		Title:  "Blog Article From Transcript",
+		Role:   defaultRole,
+		Prompt: defaultPrompt,
+	}
+}
+
+const defaultRole = "senior content writer and SEO editor"
+
+const defaultPrompt = `Write a long-form blog article in Markdown based on the transcript below.
+
+## REQUIREMENTS:
+1. Start with a YAML front matter block containing: title (max 60 characters), description (meta description, max 155 characters), slug, and keywords (list).
+2. Follow with a single H1 title and a short introduction that hooks the reader.
+3. Structure the body with H2 sections (##) that follow the main topics of the conversation; use H3 (###) only for sub-points.
+4. Include at least one pull quote per section as a Markdown blockquote (>) using a memorable sentence from the transcript.
+5. At the end of each H2 section add an "**Image suggestions**" list with:
+   - Image prompt: a detailed prompt for an AI image generator
+   - Stock search: 2-3 search queries for stock photo sites
+6. Finish with a conclusion and a call to action to watch the full episode.
+7. Write naturally for readers, not as a transcript summary; remove filler words and repetitions.
+8. Output ONLY the Markdown article, without explanations or code fences.`
+
+// getChatGPTService returns a ChatGPT service from context or creates a new one
+func (m *Module) getChatGPTService(ctx context.Context) (chatgpt.ChatGPTServicer, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("context cannot be nil")
+	}
+
+	// Check if service is provided in context
+	if service, ok := ctx.Value(ChatGPTServiceKey).(chatgpt.ChatGPTServicer); ok {
+		return service, nil
+	}
+
+	// Create new service if not in context
+	return chatgpt.NewChatGPTService()
+}
@@ -0,0 +1,285 @@
+package blogpost
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	modules "github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	services "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/chatgpt"
+	mocks "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/chatgpt/mocks"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/snapshot"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const mockArticle = "```markdown\n---\ntitle: \"Test\"\n---\n\n# Test Article\n\nIntro.\n\n## First Topic\n\n> \"Quote\"\n\n**Image suggestions**\n- Image prompt: \"studio\"\n\n## Second Topic\n\nBody.\n```"
+
+func TestExecute(t *testing.T) {
+	tempDir := t.TempDir()
+	inputFile := filepath.Join(tempDir, "transcript_corrected.txt")
+	require.NoError(t, os.WriteFile(inputFile, []byte("This is a test transcript."), 0644))
+	episodeFile := filepath.Join(tempDir, "episode_corrected.txt")
+	require.NoError(t, os.WriteFile(episodeFile, []byte("---\nepisode:\n  guest: Jane Doe\n---\nThis is a test transcript."), 0644))
+	binaryFile := filepath.Join(tempDir, "binary.txt")
+	require.NoError(t, os.WriteFile(binaryFile, []byte{0x00, 0x01, 0x02}, 0644))
+	outputDir := filepath.Join(tempDir, "output")
+
+	tests := []struct {
+		name           string
+		params         map[string]interface{}
+		apiKey         string
+		setupMock      func(*mocks.MockChatGPTServicer)
+		expectedOutput string
+		expectContent  string
+		errorContains  string
+	}{
+		{
+			name: "successful generation",
+			params: map[string]interface{}{
+				"input":    inputFile,
+				"output":   outputDir,
+				"language": "English",
+				"keywords": "security, podcasts",
+			},
+			apiKey: "test-api-key",
+			setupMock: func(m *mocks.MockChatGPTServicer) {
+				m.EXPECT().GetContent(
+					mock.Anything,
+					mock.MatchedBy(func(messages []services.ChatMessage) bool {
+						return len(messages) == 2 &&
+							strings.Contains(messages[1].Content, "Language: English") &&
+							strings.Contains(messages[1].Content, "Target SEO keywords: security, podcasts") &&
+							strings.Contains(messages[1].Content, "This is a test transcript.")
+					}),
+					mock.MatchedBy(func(opts services.CompletionOptions) bool {
+						return opts.Model == "gpt-4o" && opts.MaxTokens == 6000
+					}),
+				).Return(mockArticle, nil)
+			},
+			expectedOutput: filepath.Join(outputDir, "transcript_corrected_blog.md"),
+			expectContent:  "## First Topic",
+		},
+		{
+			name: "custom output filename",
+			params: map[string]interface{}{
+				"input":          inputFile,
+				"output":         outputDir,
+				"outputFileName": "article",
+			},
+			apiKey: "test-api-key",
+			setupMock: func(m *mocks.MockChatGPTServicer) {
+				m.EXPECT().GetContent(mock.Anything, mock.Anything, mock.Anything).Return(mockArticle, nil)
+			},
+			expectedOutput: filepath.Join(outputDir, "article.md"),
+			expectContent:  "# Test Article",
+		},
+		{
+			name: "episode metadata from front matter and params",
+			params: map[string]interface{}{
+				"input":    episodeFile,
+				"output":   outputDir,
+				"metadata": map[string]interface{}{"episode": 42},
+			},
+			apiKey: "test-api-key",
+			setupMock: func(m *mocks.MockChatGPTServicer) {
+				m.EXPECT().GetContent(
+					mock.Anything,
+					mock.MatchedBy(func(messages []services.ChatMessage) bool {
+						return strings.Contains(messages[1].Content, "- episode: 42\n- guest: Jane Doe") &&
+							!strings.Contains(messages[1].Content, "---\nepisode:")
+					}),
+					mock.Anything,
+				).Return(mockArticle, nil)
+			},
+			expectedOutput: filepath.Join(outputDir, "episode_corrected_blog.md"),
+			expectContent:  "title: \"Test\"\nepisode:\n    episode: 42\n    guest: Jane Doe\n---",
+		},
+		{
+			name: "no api key writes placeholder",
+			params: map[string]interface{}{
+				"input":  inputFile,
+				"output": outputDir,
+			},
+			expectedOutput: filepath.Join(outputDir, "transcript_corrected_blog.md"),
+			expectContent:  "MOCK OUTPUT",
+		},
+		{
+			name: "api error",
+			params: map[string]interface{}{
+				"input":  inputFile,
+				"output": outputDir,
+			},
+			apiKey: "test-api-key",
+			setupMock: func(m *mocks.MockChatGPTServicer) {
+				m.EXPECT().GetContent(mock.Anything, mock.Anything, mock.Anything).Return("", errors.New("API error"))
+			},
+			errorContains: "API error",
+		},
+		{
+			name: "empty response",
+			params: map[string]interface{}{
+				"input":  inputFile,
+				"output": outputDir,
+			},
+			apiKey: "test-api-key",
+			setupMock: func(m *mocks.MockChatGPTServicer) {
+				m.EXPECT().GetContent(mock.Anything, mock.Anything, mock.Anything).Return("  ", nil)
+			},
+			errorContains: "empty article",
+		},
+		{
+			name: "binary input",
+			params: map[string]interface{}{
+				"input":  binaryFile,
+				"output": outputDir,
+			},
+			apiKey:        "test-api-key",
+			errorContains: "appears to be binary",
+		},
+		{
+			name: "missing input",
+			params: map[string]interface{}{
+				"input":  filepath.Join(tempDir, "missing.txt"),
+				"output": outputDir,
+			},
+			apiKey:        "test-api-key",
+			errorContains: "input file not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OPENAI_API_KEY", tt.apiKey)
+
+			ctx := context.Background()
+			mockService := mocks.NewMockChatGPTServicer(t)
+			if tt.setupMock != nil {
+				tt.setupMock(mockService)
+			}
+			ctx = context.WithValue(ctx, ChatGPTServiceKey, mockService)
+
+			result, err := New().Execute(ctx, tt.params)
+			if tt.errorContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorContains)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedOutput, result.Outputs["blog_post"])
+
+			content, err := os.ReadFile(tt.expectedOutput)
+			require.NoError(t, err)
+			assert.Contains(t, string(content), tt.expectContent)
+			assert.NotContains(t, string(content), "```")
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tempDir := t.TempDir()
+	inputFile := filepath.Join(tempDir, "transcript.txt")
+	require.NoError(t, os.WriteFile(inputFile, []byte("transcript"), 0644))
+
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name:   "valid parameters",
+			params: map[string]interface{}{"input": inputFile, "output": tempDir},
+		},
+		{
+			name:    "missing input",
+			params:  map[string]interface{}{"output": tempDir},
+			wantErr: true,
+		},
+		{
+			name:    "missing prompt file",
+			params:  map[string]interface{}{"input": inputFile, "output": tempDir, "promptFilePath": filepath.Join(tempDir, "missing.yaml")},
+			wantErr: true,
+		},
+		{
+			name:    "negative word count",
+			params:  map[string]interface{}{"input": inputFile, "output": tempDir, "wordCount": -1},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := New().Validate(tt.params)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGetIO(t *testing.T) {
+	io := New().GetIO()
+	assert.Len(t, io.RequiredInputs, 2)
+	require.Len(t, io.ProducedOutputs, 1)
+	assert.Equal(t, "blog_post", io.ProducedOutputs[0].Name)
+	assert.Equal(t, string(modules.OutputTypeFile), io.ProducedOutputs[0].Type)
+}
+
+func TestStripMarkdownFence(t *testing.T) {
+	assert.Equal(t, "# Title", stripMarkdownFence("```markdown\n# Title\n```"))
+	assert.Equal(t, "# Title", stripMarkdownFence("  # Title  "))
+	assert.Equal(t, "", stripMarkdownFence("```"))
+}
+
+func TestGetPromptTemplate(t *testing.T) {
+	tempDir := t.TempDir()
+	promptFile := filepath.Join(tempDir, "prompt.yaml")
+	require.NoError(t, os.WriteFile(promptFile, []byte("role: \"editor\"\nprompt: |\n  Custom instructions\n"), 0644))
+
+	custom := getPromptTemplate(context.Background(), promptFile)
+	assert.Equal(t, "editor", custom.Role)
+	assert.Contains(t, custom.Prompt, "Custom instructions")
+
+	fallback := getPromptTemplate(context.Background(), filepath.Join(tempDir, "missing.yaml"))
+	assert.Equal(t, defaultRole, fallback.Role)
+	assert.Equal(t, defaultPrompt, fallback.Prompt)
+}
+
+// TestGolden records the prompt built with the default template and the article written from a
+// fixture response; rerun with UPDATE_SNAPSHOTS=1 after an intended change
+func TestGolden(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "test-api-key")
+	tempDir := t.TempDir()
+	inputFile := filepath.Join(tempDir, "episode_corrected.txt")
+	require.NoError(t, os.WriteFile(inputFile, []byte("---\nepisode:\n  guest: Jane Doe\n---\nToday we talk about analog synthesizers and why they sound warm."), 0644))
+
+	var sent []services.ChatMessage
+	chatGPT := mocks.NewMockChatGPTServicer(t)
+	chatGPT.EXPECT().GetContent(mock.Anything, mock.Anything, mock.Anything).
+		Run(func(_ context.Context, messages []services.ChatMessage, _ services.CompletionOptions) {
+			sent = messages
+		}).
+		Return(mockArticle, nil)
+
+	ctx := context.WithValue(context.Background(), ChatGPTServiceKey, chatGPT)
+	result, err := New().Execute(ctx, map[string]interface{}{
+		"input":    inputFile,
+		"output":   filepath.Join(tempDir, "output"),
+		"language": "English",
+		"keywords": "synthesizers, music production",
+		"metadata": map[string]interface{}{"episode": 42},
+	})
+	require.NoError(t, err)
+
+	snapshot.MatchMessages(t, "prompt.txt", sent)
+	article, err := os.ReadFile(result.Outputs["blog_post"])
+	require.NoError(t, err)
+	snapshot.Match(t, "blog.md", string(article))
+}
@@ -56,12 +56,12 @@ func (m *Module) GetIO() modules.ModuleIO {
 				Type:        string(modules.InputTypeData),
 			},
 			{
-				Name:        "preserveTimestamps",
+				Name:        "preserveTimestamp",
 				Description: "Whether to preserve timestamps in SRT files (default: false)",
 				Type:        string(modules.InputTypeData),
 			},
 			{
-				Name:        "preserveLineBreaks",
+				Name:        "preserveLineBreak",
 				Description: "Whether to preserve line breaks (default: true)",
 				Type:        string(modules.InputTypeData),
 			},
@@ -79,14 +79,14 @@ func (m *Module) GetIO() modules.ModuleIO {
 
 // Params contains the parameters for text cleaning
 type Params struct {
-	Input             string   `json:"input"`             // Path to input text file
-	Output            string   `json:"output"`            // Path to output directory
-	RemovePatterns    []string `json:"removePatterns"`    // Patterns to remove from each line
-	CleanFileSuffix   string   `json:"cleanFileSuffix"`   // Suffix for cleaned files (default: "_clean")
-	InputFileName     string   `json:"inputFileName"`     // Specific input file name to process
-	OutputFileName    string   `json:"outputFileName"`    // Custom output file name (without extension)
-	PreserveTimestamp bool     `json:"preserveTimestamp"` // Whether to preserve timestamps in SRT files
-	PreserveLineBreak bool     `json:"preserveLineBreak"` // Whether to preserve line breaks
+	Input             string   `json:"input"`                            // Path to input text file
+	Output            string   `json:"output"`                           // Path to output directory
+	RemovePatterns    []string `json:"removePatterns"`                   // Patterns to remove from each line
+	CleanFileSuffix   string   `json:"cleanFileSuffix" default:"_clean"` // Suffix for cleaned files (default: "_clean")
+	InputFileName     string   `json:"inputFileName"`                    // Specific input file name to process
+	OutputFileName    string   `json:"outputFileName"`                   // Custom output file name (without extension)
+	PreserveTimestamp bool     `json:"preserveTimestamp"`                // Whether to preserve timestamps in SRT files
+	PreserveLineBreak bool     `json:"preserveLineBreak"`                // Whether to preserve line breaks
 }
 
 // New creates a new clean text module
@@ -99,6 +99,11 @@ func (m *Module) Name() string {
 	return "clean_text"
 }
 
+// ParamsTemplate returns the module's parameter struct, used to validate and document workflows
+func (m *Module) ParamsTemplate() interface{} {
+	return Params{}
+}
+
 // Validate checks if the parameters are valid
 func (m *Module) Validate(params map[string]interface{}) error {
 	var p Params
@@ -106,13 +111,22 @@ func (m *Module) Validate(params map[string]interface{}) error {
 		return err
 	}
 
-	// Validate input path
-	if err := utils.ValidateInputPath(p.Input, p.Output, p.InputFileName); err != nil {
+	// Validate output path
+	if err := utils.ValidateOutputPath(p.Output); err != nil {
 		return err
 	}
 
-	// Validate output path
-	if err := utils.ValidateOutputPath(p.Output); err != nil {
+	// An input produced by an earlier step does not exist yet
+	if fromStep := modules.FromStep(params); fromStep != "" {
+		if p.Input == "" {
+			return fmt.Errorf("input: input path is required")
+		}
+		utils.LogVerbose("Note: Input file %s will be created by step %s", p.Input, fromStep)
+		return nil
+	}
+
+	// Validate input path
+	if err := utils.ValidateInputPath(p.Input, p.Output, p.InputFileName); err != nil {
 		return err
 	}
 
@@ -121,20 +135,9 @@ func (m *Module) Validate(params map[string]interface{}) error {
 		return nil
 	}
 
-	// Only validate file existence for external input paths
 	fileInfo, err := os.Stat(p.Input)
 	if err != nil {
-		// For files that don't exist, check if they might be in the output directory
-		// as they could be created by previous steps
-		if strings.Contains(p.Input, p.Output) ||
-			strings.Contains(p.Input, "${output}") ||
-			strings.Contains(p.Input, "./output") ||
-			strings.Contains(p.Input, "/output") ||
-			strings.Contains(p.Input, "/transcript") ||
-			filepath.Base(p.Input) == "transcript.srt" ||
-			filepath.Base(p.Input) == "transcript.txt" {
-			// Skip validation for expected output files
-			utils.LogVerbose("Note: Input file %s will be created by a previous step", p.Input)
+		if utils.InOutputDir(p.Input, p.Output) {
 			return nil
 		}
 		return fmt.Errorf("input file does not exist: %w", err)
@@ -164,7 +167,7 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 	}
 
 	// Create output directory if it doesn't exist
-	if err := os.MkdirAll(p.Output, 0755); err != nil {
+	if err := utils.EnsureDir(p.Output); err != nil {
 		return modules.ModuleResult{}, fmt.Errorf("failed to create output directory: %w", err)
 	}
 
@@ -197,11 +200,11 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 
 	outputPath := filepath.Join(p.Output, outputBaseName+p.CleanFileSuffix+".txt")
 
-	if err := m.cleanFile(resolvedInput, outputPath, p); err != nil {
+	if err := m.cleanFile(ctx, resolvedInput, outputPath, p); err != nil {
 		return modules.ModuleResult{}, err
 	}
 
-	utils.LogSuccess("Cleaned %s -> %s", resolvedInput, outputPath)
+	utils.LogSuccessContext(ctx, "Cleaned %s -> %s", resolvedInput, outputPath)
 
 	// Create result with output file information
 	result := modules.ModuleResult{
@@ -213,13 +216,14 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 			"outputFormat":    "txt",
 			"cleanFileSuffix": p.CleanFileSuffix,
 		},
+		Stats: modules.Stats{Items: 1},
 	}
 
 	return result, nil
 }
 
 // cleanFile cleans a single text file
-func (m *Module) cleanFile(inputPath, outputPath string, p Params) error {
+func (m *Module) cleanFile(ctx context.Context, inputPath, outputPath string, p Params) error {
 	// Compile removal patterns
 	var removeRegexes []*regexp.Regexp
 	for _, pattern := range p.RemovePatterns {
@@ -240,30 +244,25 @@ func (m *Module) cleanFile(inputPath, outputPath string, p Params) error {
 	}
 	defer func() {
 		if err := inputFile.Close(); err != nil {
-			utils.LogWarning("Failed to close input file: %v", err)
+			utils.LogWarningContext(ctx, "Failed to close input file: %v", err)
 		}
 	}()
 
-	// Create output file
-	outputFile, err := os.Create(outputPath)
+	// Create output file; it is only moved into place once cleaning succeeds
+	outputFile, err := utils.CreateAtomicFile(outputPath, 0644)
 	if err != nil {
 		return fmt.Errorf("failed to create output file: %w", err)
 	}
 	defer func() {
 		if err := outputFile.Close(); err != nil {
-			utils.LogWarning("Failed to close output file: %v", err)
+			utils.LogWarningContext(ctx, "Failed to close output file: %v", err)
 		}
 	}()
 
 	scanner := bufio.NewScanner(inputFile)
 	writer := bufio.NewWriter(outputFile)
-	defer func() {
-		if err := writer.Flush(); err != nil {
-			utils.LogWarning("Failed to flush writer: %v", err)
-		}
-	}()
 
-	utils.LogVerbose("Cleaning file: %s", inputPath)
+	utils.LogVerboseContext(ctx, "Cleaning file: %s", inputPath)
 
 	// Process based on file extension
 	fileExt := strings.ToLower(filepath.Ext(inputPath))
@@ -300,7 +299,11 @@ func (m *Module) cleanFile(inputPath, outputPath string, p Params) error {
 		}
 	}
 
-	return nil
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to flush output: %w", err)
+	}
+
+	return outputFile.Commit()
 }
 
 // SRTState represents the state of SRT file processing
@@ -464,7 +467,7 @@ func isSubtitleNumber(line string) bool {
 
 // isTimestamp checks if a line matches the SRT timestamp format
 func isTimestamp(line string) bool {
-	timestampPattern := `^\d{2}:\d{2}:\d{2},\d{3} --> \d{2}:\d{2}:\d{2},\d{3}$`
+	timestampPattern := `^\d{2,}:\d{2}:\d{2},\d{3} --> \d{2,}:\d{2}:\d{2},\d{3}$`
 	matched, _ := regexp.MatchString(timestampPattern, strings.TrimSpace(line))
 	return matched
 }
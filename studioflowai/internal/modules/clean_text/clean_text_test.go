@@ -27,8 +27,8 @@ func TestModule_GetIO(t *testing.T) {
 	assert.Equal(t, "cleanFileSuffix", io.OptionalInputs[1].Name)
 	assert.Equal(t, "inputFileName", io.OptionalInputs[2].Name)
 	assert.Equal(t, "outputFileName", io.OptionalInputs[3].Name)
-	assert.Equal(t, "preserveTimestamps", io.OptionalInputs[4].Name)
-	assert.Equal(t, "preserveLineBreaks", io.OptionalInputs[5].Name)
+	assert.Equal(t, "preserveTimestamp", io.OptionalInputs[4].Name)
+	assert.Equal(t, "preserveLineBreak", io.OptionalInputs[5].Name)
 
 	// Test produced outputs
 	assert.Len(t, io.ProducedOutputs, 1)
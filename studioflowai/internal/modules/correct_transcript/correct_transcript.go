@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -22,17 +23,23 @@ type Module struct {
 
 // Params contains the parameters for ChatGPT correction
 type Params struct {
-	Input            string  `json:"input"`            // Path to input transcript file
-	Output           string  `json:"output"`           // Path to output directory
-	OutputFileName   string  `json:"outputFileName"`   // Custom output file name (without extension)
-	PromptTemplate   string  `json:"promptTemplate"`   // Path to prompt template file
-	OutputSuffix     string  `json:"outputSuffix"`     // Suffix for corrected files (default: "_corrected")
-	Model            string  `json:"model"`            // OpenAI model to use (default: "gpt-4o")
-	Temperature      float64 `json:"temperature"`      // Model temperature (default: 0.1)
-	MaxTokens        int     `json:"maxTokens"`        // Maximum tokens for the response (default: 4000)
-	TargetLanguage   string  `json:"targetLanguage"`   // Target language for corrections (default: "English")
-	RequestTimeoutMS int     `json:"requestTimeoutMs"` // API request timeout in milliseconds (default: 300000)
-	ChunkSize        int     `json:"chunkSize"`        // Size of transcript chunks in tokens (default: 120000)
+	Input            string                 `json:"input"`                             // Path to input transcript file
+	Output           string                 `json:"output"`                            // Path to output directory
+	OutputFileName   string                 `json:"outputFileName"`                    // Custom output file name (without extension)
+	PromptTemplate   string                 `json:"promptTemplate"`                    // Path to prompt template file
+	OutputSuffix     string                 `json:"outputSuffix" default:"_corrected"` // Suffix for corrected files (default: "_corrected")
+	Model            string                 `json:"model" default:"gpt-4o"`            // OpenAI model to use (default: "gpt-4o")
+	FallbackModels   []string               `json:"fallbackModels"`                    // Models tried in order when the primary model fails or returns an empty chunk
+	Temperature      float64                `json:"temperature" default:"0.1"`         // Model temperature (default: 0.1)
+	MaxTokens        int                    `json:"maxTokens" default:"4000"`          // Maximum tokens for the response (default: 4000)
+	TargetLanguage   string                 `json:"targetLanguage" default:"English"`  // Target language for corrections and the default prompt (default: "English")
+	RequestTimeoutMS int                    `json:"requestTimeoutMs" default:"300000"` // API request timeout in milliseconds (default: 300000)
+	ChunkSize        int                    `json:"chunkSize" default:"120000"`        // Size of transcript chunks in tokens (default: 120000)
+	Metadata         map[string]interface{} `json:"metadata"`                          // Episode details (guest, episode number, recording date, links) for the prompt and front matter
+	MetadataFile     string                 `json:"metadataFile"`                      // YAML file with episode details; inline metadata wins (optional)
+	QCReport         string                 `json:"qcReport"`                          // Confidence report from transcribe; its low-confidence passages are checked first (optional)
+	DiffReport       string                 `json:"diffReport" default:"html"`         // Report of the words the model changed: html, unified or none (default: "html")
+	DiffWarnRatio    float64                `json:"diffWarnRatio" default:"0.3"`       // Warn when the model changed more than this share of the words (default: 0.3)
 }
 
 // New creates a new ChatGPT correction module
@@ -45,6 +52,11 @@ func (m *Module) Name() string {
 	return "correct_transcript"
 }
 
+// ParamsTemplate returns the module's parameter struct, used to validate and document workflows
+func (m *Module) ParamsTemplate() interface{} {
+	return Params{}
+}
+
 // Validate checks if the parameters are valid
 func (m *Module) Validate(params map[string]interface{}) error {
 	var p Params
@@ -74,6 +86,19 @@ func (m *Module) Validate(params map[string]interface{}) error {
 		}
 	}
 
+	if _, err := utils.ResolveEpisodeMetadata(p.Metadata, p.MetadataFile); err != nil {
+		return err
+	}
+
+	switch p.DiffReport {
+	case "", diffReportHTML, diffReportUnified, diffReportNone:
+	default:
+		return fmt.Errorf("invalid diffReport %q: must be html, unified or none", p.DiffReport)
+	}
+	if p.DiffWarnRatio < 0 {
+		return fmt.Errorf("diffWarnRatio must not be negative")
+	}
+
 	return nil
 }
 
@@ -106,14 +131,27 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 	if p.ChunkSize == 0 {
 		p.ChunkSize = 120000 // Default chunk size for GPT-4
 	}
+	if p.DiffReport == "" {
+		p.DiffReport = diffReportHTML
+	}
+	if p.DiffWarnRatio == 0 {
+		p.DiffWarnRatio = 0.3
+	}
 
 	// Create output directory if it doesn't exist
-	if err := os.MkdirAll(p.Output, 0755); err != nil {
+	if err := utils.EnsureDir(p.Output); err != nil {
 		return modules.ModuleResult{}, fmt.Errorf("failed to create output directory: %w", err)
 	}
 
+	// Episode details are added to every chunk's prompt and to the transcript front matter
+	metadata, err := utils.ResolveEpisodeMetadata(p.Metadata, p.MetadataFile)
+	if err != nil {
+		return modules.ModuleResult{}, err
+	}
+	p.Metadata = metadata
+
 	// Load the prompt template
-	promptTemplate, err := m.loadPromptTemplate(p.PromptTemplate)
+	promptTemplate, err := m.loadPromptTemplate(p.PromptTemplate, p.TargetLanguage)
 	if err != nil {
 		return modules.ModuleResult{}, fmt.Errorf("failed to load prompt template: %w", err)
 	}
@@ -142,25 +180,49 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 	}
 
 	// Process the file
-	if err := m.processFile(ctx, resolvedInput, outputPath, promptTemplate, p); err != nil {
+	usedModels, err := m.correctFile(ctx, resolvedInput, outputPath, promptTemplate, p)
+	if err != nil {
 		return modules.ModuleResult{}, err
 	}
+	usedModel := strings.Join(usedModels, ", ")
 
-	utils.LogSuccess("Corrected file %s -> %s", resolvedInput, outputPath)
+	utils.LogSuccessContext(ctx, "Corrected file %s -> %s", resolvedInput, outputPath)
 
-	return modules.ModuleResult{
+	result := modules.ModuleResult{
 		Outputs: map[string]string{
 			"corrected": outputPath,
 		},
+		Metadata: map[string]interface{}{
+			"model": usedModel,
+		},
 		Statistics: map[string]interface{}{
-			"model":       p.Model,
+			"model":       usedModel,
 			"chunkSize":   p.ChunkSize,
 			"language":    p.TargetLanguage,
 			"inputFile":   resolvedInput,
 			"outputFile":  outputPath,
 			"processTime": time.Now().Format(time.RFC3339),
 		},
-	}, nil
+		Stats: modules.Stats{Items: 1},
+	}
+
+	// The diff lets an editor audit what the model changed before the transcript feeds later steps
+	if p.DiffReport != diffReportNone {
+		reportPath := diffReportPath(outputPath, p.DiffReport)
+		summary, err := writeDiffReport(ctx, resolvedInput, outputPath, reportPath, p.DiffReport)
+		if err != nil {
+			return modules.ModuleResult{}, err
+		}
+		result.Outputs["diffReport"] = reportPath
+		result.Statistics["wordsRemoved"] = summary.Removed
+		result.Statistics["wordsAdded"] = summary.Added
+		result.Statistics["changeRatio"] = summary.ChangeRatio()
+		if summary.ChangeRatio() > p.DiffWarnRatio {
+			utils.LogWarningContext(ctx, "The model changed %.0f%% of the transcript's words; check %s for over-corrections", summary.ChangeRatio()*100, reportPath)
+		}
+	}
+
+	return result, nil
 }
 
 // GetIO returns the module's input/output specification
@@ -197,7 +259,18 @@ func (m *Module) GetIO() modules.ModuleIO {
 			},
 			{
 				Name:        "targetLanguage",
-				Description: "Target language for corrections",
+				Description: "Target language for corrections, which also selects the default prompt",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "qcReport",
+				Description: "Confidence report from transcribe whose low-confidence passages are corrected first",
+				Patterns:    []string{"_qc.yaml"},
+				Type:        string(modules.InputTypeFile),
+			},
+			{
+				Name:        "diffReport",
+				Description: "Format of the report of the words the model changed: html, unified or none",
 				Type:        string(modules.InputTypeData),
 			},
 		},
@@ -208,19 +281,58 @@ func (m *Module) GetIO() modules.ModuleIO {
 				Patterns:    []string{".txt"},
 				Type:        string(modules.OutputTypeFile),
 			},
+			{
+				Name:        "diffReport",
+				Description: "Word-level diff of the raw and corrected transcripts",
+				Patterns:    []string{"_diff.html", "_diff.diff"},
+				Type:        string(modules.OutputTypeFile),
+			},
 		},
 	}
 }
 
-// loadPromptTemplate loads the prompt template from a file
-func (m *Module) loadPromptTemplate(templatePath string) (string, error) {
-	// If no template path is provided, use the default prompt
+// genericPrompt is the default prompt for languages without one of their own in defaultPrompts
+const genericPrompt = "You are a helpful assistant that corrects transcript errors. " +
+	"Please fix any transcription mistakes, such as misheard words, wrong homophones and misspelled " +
+	"names or technical terms, using the surrounding context. Keep the meaning intact and improve readability. " +
+	"Here is the transcript text to correct:"
+
+// defaultPrompts holds the default prompt of each target language, keyed by the language names
+// of utils.LocaleLanguage. The examples are the mistakes speech recognition makes in that language;
+// adding a language is adding an entry.
+var defaultPrompts = map[string]string{
+	"English": "You are a helpful assistant that corrects transcript errors. " +
+		"Please fix any transcription mistakes, especially homophones (their/there, affect/effect) and " +
+		"technical terms or acronyms that were heard as everyday words. For example, 'cube cuddle' might " +
+		"actually be 'kubectl' and 'sequel server' might be 'SQL Server' when the context is about software. " +
+		"Keep the meaning intact and improve readability. " +
+		"Here is the transcript text to correct:",
+	"Spanish": "You are a helpful assistant that corrects transcript errors. " +
+		"Please fix any transcription mistakes, especially words that might have been " +
+		"misinterpreted due to multilingual context. For example, the word 'haiti' might actually be 'IT' " +
+		"when the context is about technology in Spanish. Keep the meaning intact and improve readability. " +
+		"Here is the transcript text to correct:",
+	"Japanese": "You are a helpful assistant that corrects transcript errors. " +
+		"Please fix any transcription mistakes, especially kanji chosen for the wrong homophone " +
+		"(for example 機能 'function' written as 昨日 'yesterday') and English technical terms written in katakana " +
+		"that should keep their usual spelling, such as クバネティス for 'Kubernetes'. Keep Japanese punctuation " +
+		"(。、) and do not add spaces between Japanese words. Keep the meaning intact and improve readability. " +
+		"Here is the transcript text to correct:",
+}
+
+// defaultPrompt returns the default prompt for a target language such as "Japanese" or "ja"
+func defaultPrompt(language string) string {
+	if prompt, ok := defaultPrompts[utils.LocaleLanguage(language)]; ok {
+		return prompt
+	}
+	return genericPrompt
+}
+
+// loadPromptTemplate loads the prompt template from a file, or the default prompt of the target
+// language when no file is given
+func (m *Module) loadPromptTemplate(templatePath, language string) (string, error) {
 	if templatePath == "" {
-		return "You are a helpful assistant that corrects transcript errors. " +
-			"Please fix any transcription mistakes, especially words that might have been " +
-			"misinterpreted due to multilingual context. For example, the word 'haiti' might actually be 'IT' " +
-			"when the context is about technology in Spanish. Keep the meaning intact and improve readability. " +
-			"Here is the transcript text to correct:", nil
+		return defaultPrompt(language), nil
 	}
 
 	// Read the template file
@@ -337,45 +449,59 @@ func (m *Module) getChatGPTService() (chatgpt.ChatGPTServicer, error) {
 
 // processFile sends a transcript file to ChatGPT for correction
 func (m *Module) processFile(ctx context.Context, inputPath, outputPath, promptTemplate string, p Params) error {
+	_, err := m.correctFile(ctx, inputPath, outputPath, promptTemplate, p)
+	return err
+}
+
+// correctFile corrects a transcript file chunk by chunk and returns the models that answered, in order of first use
+func (m *Module) correctFile(ctx context.Context, inputPath, outputPath, promptTemplate string, p Params) ([]string, error) {
 	// First check if the file is a text file
 	if !utils.IsTextFile(inputPath) {
-		return fmt.Errorf("file %s appears to be binary, not a text file - skipping", inputPath)
+		return nil, fmt.Errorf("file %s appears to be binary, not a text file - skipping", inputPath)
 	}
 
 	// Read the transcript file
 	transcript, err := utils.ReadTextFile(inputPath)
 	if err != nil {
-		return fmt.Errorf("failed to read transcript file: %w", err)
+		return nil, fmt.Errorf("failed to read transcript file: %w", err)
 	}
 
+	// Keep episode details from an earlier run, letting the step's metadata override them
+	frontMatter, transcript := utils.SplitEpisodeFrontMatter(transcript)
+	metadata := utils.MergeEpisodeMetadata(frontMatter, p.Metadata)
+
 	// Check if API key is set, if not, just copy the original text
 	if !chatgpt.IsAPIKeySet() {
-		utils.LogWarning("No API key set - copying original text from %s to %s", inputPath, outputPath)
-		if err := utils.WriteTextFile(outputPath, transcript); err != nil {
-			return fmt.Errorf("failed to write output file: %w", err)
+		utils.LogWarningContext(ctx, "No API key set - copying original text from %s to %s", inputPath, outputPath)
+		text, err := utils.WithEpisodeFrontMatter(transcript, metadata)
+		if err != nil {
+			return nil, err
 		}
-		return nil
+		if err := utils.WriteTextFile(outputPath, text); err != nil {
+			return nil, fmt.Errorf("failed to write output file: %w", err)
+		}
+		return []string{p.Model}, nil
 	}
 
-	utils.LogVerbose("Processing %s with ChatGPT...", filepath.Base(inputPath))
+	utils.LogVerboseContext(ctx, "Processing %s with ChatGPT...", filepath.Base(inputPath))
 
 	// Initialize ChatGPT service
 	chatGPT, err := m.getChatGPTService()
 	if err != nil {
-		return fmt.Errorf("failed to initialize ChatGPT service: %w", err)
+		return nil, fmt.Errorf("failed to initialize ChatGPT service: %w", err)
 	}
 
+	// Passages the speech recognizer was unsure about are pointed out in the chunks that contain them
+	regions := loadConfidenceRegions(ctx, utils.ResolveOutputPath(p.QCReport, p.Output))
+
 	// Split transcript into chunks if needed
 	chunks := m.splitTranscript(transcript, p.ChunkSize)
 	var correctedChunks []string
+	var usedModels []string
 
 	// Process each chunk
 	for i, chunk := range chunks {
-		utils.LogVerbose("Processing chunk %d/%d...", i+1, len(chunks))
-
-		// Create a timeout context for the API request
-		apiCtx, cancel := context.WithTimeout(ctx, time.Duration(p.RequestTimeoutMS)*time.Millisecond)
-		defer cancel()
+		utils.LogVerboseContext(ctx, "Processing chunk %d/%d...", i+1, len(chunks))
 
 		// Construct the full prompt for this chunk
 		fullPrompt := promptTemplate
@@ -383,6 +509,12 @@ func (m *Module) processFile(ctx context.Context, inputPath, outputPath, promptT
 			fullPrompt += "\n\n"
 		}
 		fullPrompt += fmt.Sprintf("Target language: %s\n\n", p.TargetLanguage)
+		if details := utils.EpisodeMetadataPrompt(metadata); details != "" {
+			fullPrompt += details + "\n"
+		}
+		if hint := lowConfidenceHint(chunk, regions); hint != "" {
+			fullPrompt += hint + "\n"
+		}
 		fullPrompt += fmt.Sprintf("Processing chunk %d of %d:\n\n", i+1, len(chunks))
 		fullPrompt += chunk
 
@@ -399,29 +531,84 @@ func (m *Module) processFile(ctx context.Context, inputPath, outputPath, promptT
 		}
 
 		// Send the request to ChatGPT
-		response, err := chatGPT.GetContent(apiCtx, messages, chatgpt.CompletionOptions{
+		// Each attempt is bounded by RequestTimeoutMS; empty chunks move on to the next model
+		completion, err := chatgpt.CompleteWithFallback(ctx, chatGPT, messages, chatgpt.CompletionOptions{
 			Model:            p.Model,
 			Temperature:      p.Temperature,
 			MaxTokens:        p.MaxTokens,
 			RequestTimeoutMS: p.RequestTimeoutMS,
+		}, chatgpt.FallbackChain{Models: p.FallbackModels}, func(response string) error {
+			if strings.TrimSpace(response) == "" {
+				return fmt.Errorf("empty response")
+			}
+			return nil
 		})
 		if err != nil {
-			return fmt.Errorf("ChatGPT API request failed for chunk %d: %w", i+1, err)
+			return nil, fmt.Errorf("ChatGPT API request failed for chunk %d: %w", i+1, err)
 		}
 
-		correctedChunks = append(correctedChunks, response)
+		correctedChunks = append(correctedChunks, completion.Content)
+		if !slices.Contains(usedModels, completion.Model) {
+			usedModels = append(usedModels, completion.Model)
+		}
 	}
 
 	// Combine all corrected chunks
-	correctedText := strings.Join(correctedChunks, "\n\n")
+	correctedText, err := utils.WithEpisodeFrontMatter(strings.Join(correctedChunks, "\n\n"), metadata)
+	if err != nil {
+		return nil, err
+	}
 
 	// Write the corrected transcript to the output file
 	if err := utils.WriteTextFile(outputPath, correctedText); err != nil {
-		return fmt.Errorf("failed to write output file: %w", err)
+		return nil, fmt.Errorf("failed to write output file: %w", err)
 	}
 
-	utils.LogSuccess("Corrected file %s -> %s", p.Input, outputPath)
-	return nil
+	utils.LogSuccessContext(ctx, "Corrected file %s -> %s", p.Input, outputPath)
+	return usedModels, nil
+}
+
+// loadConfidenceRegions reads the low-confidence regions of a QC report, or returns nil when there is none
+func loadConfidenceRegions(ctx context.Context, path string) []utils.ConfidenceRegion {
+	if path == "" {
+		return nil
+	}
+	report, err := utils.ReadConfidenceReport(path)
+	if err != nil {
+		// Reused transcripts come without a report, which is not worth failing the step for
+		utils.LogWarningContext(ctx, "Correcting without confidence hints: %v", err)
+		return nil
+	}
+	return report.Regions
+}
+
+// lowConfidenceHint lists the low-confidence regions found in a chunk so the model checks them first
+func lowConfidenceHint(chunk string, regions []utils.ConfidenceRegion) string {
+	haystack := normalizeText(chunk)
+	var b strings.Builder
+	for _, region := range regions {
+		// Match on the first words, as the region may span several subtitle cues
+		words := strings.Fields(normalizeText(region.Text))
+		if len(words) == 0 {
+			continue
+		}
+		if len(words) > 5 {
+			words = words[:5]
+		}
+		if !strings.Contains(haystack, strings.Join(words, " ")) {
+			continue
+		}
+		if b.Len() == 0 {
+			b.WriteString("The speech recognizer was unsure about these passages. Check them first and fix misheard words, names and terms:\n")
+		}
+		fmt.Fprintf(&b, "- [%s-%s] %q (%s)\n", region.Start, region.End, region.Text, strings.Join(region.Reasons, ", "))
+	}
+	return b.String()
+}
+
+// normalizeText lowercases text and collapses whitespace for matching
+func normalizeText(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
 }
 
 // splitTranscript splits a transcript into chunks of approximately the specified token size
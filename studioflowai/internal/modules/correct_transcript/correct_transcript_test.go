@@ -11,6 +11,8 @@ import (
 	modules "github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
 	services "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/chatgpt"
 	chatgptmocks "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/chatgpt/mocks"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/snapshot"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -292,10 +294,13 @@ func TestModule_GetIO(t *testing.T) {
 	assert.Contains(t, getOptionalInputNames(io), "promptTemplate")
 	assert.Contains(t, getOptionalInputNames(io), "model")
 	assert.Contains(t, getOptionalInputNames(io), "targetLanguage")
+	assert.Contains(t, getOptionalInputNames(io), "qcReport")
+	assert.Contains(t, getOptionalInputNames(io), "diffReport")
 
 	// Test produced outputs
-	assert.Len(t, io.ProducedOutputs, 1)
+	assert.Len(t, io.ProducedOutputs, 2)
 	assert.Equal(t, "corrected", io.ProducedOutputs[0].Name)
+	assert.Equal(t, "diffReport", io.ProducedOutputs[1].Name)
 }
 
 func getOptionalInputNames(io modules.ModuleIO) []string {
@@ -430,12 +435,14 @@ Please follow these instructions:
 	tests := []struct {
 		name         string
 		templatePath string
+		language     string
 		want         string
 		wantErr      bool
 	}{
 		{
-			name:         "empty path returns default prompt",
+			name:         "empty path returns default prompt of the language",
 			templatePath: "",
+			language:     "Spanish",
 			want: "You are a helpful assistant that corrects transcript errors. " +
 				"Please fix any transcription mistakes, especially words that might have been " +
 				"misinterpreted due to multilingual context. For example, the word 'haiti' might actually be 'IT' " +
@@ -443,6 +450,18 @@ Please follow these instructions:
 				"Here is the transcript text to correct:",
 			wantErr: false,
 		},
+		{
+			name:         "default prompt by language code",
+			templatePath: "",
+			language:     "ja",
+			want:         defaultPrompts["Japanese"],
+		},
+		{
+			name:         "language without a default prompt",
+			templatePath: "",
+			language:     "Finnish",
+			want:         genericPrompt,
+		},
 		{
 			name:         "yaml prompt file",
 			templatePath: yamlPath,
@@ -477,13 +496,13 @@ Please follow these instructions:
 
 	// Create invalid YAML file after defining tests
 	invalidYAML := "invalid:\n\tyaml:\n\t\t- content: [broken"
-	require.NoError(t, os.WriteFile(tests[5].templatePath, []byte(invalidYAML), 0644))
+	require.NoError(t, os.WriteFile(tests[7].templatePath, []byte(invalidYAML), 0644))
 
 	module := &Module{}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := module.loadPromptTemplate(tt.templatePath)
+			got, err := module.loadPromptTemplate(tt.templatePath, tt.language)
 			if tt.wantErr {
 				assert.Error(t, err)
 				return
@@ -834,3 +853,89 @@ func TestModule_SplitTranscript_LargeChunks(t *testing.T) {
 		assert.LessOrEqual(t, tokens, 50)
 	}
 }
+
+func TestLowConfidenceHint(t *testing.T) {
+	dir := t.TempDir()
+	reportPath := filepath.Join(dir, "audio_qc.yaml")
+	require.NoError(t, utils.WriteConfidenceReport(reportPath, utils.ConfidenceReport{
+		Regions: []utils.ConfidenceRegion{
+			{Start: "00:00:05", End: "00:00:12", Text: "Today we talk about ee bee pee eff", Reasons: []string{"low confidence"}},
+			{Start: "00:10:00", End: "00:10:04", Text: "A passage from another chunk", Reasons: []string{"repetitive text"}},
+		},
+	}))
+
+	regions := loadConfidenceRegions(context.Background(), reportPath)
+	require.Len(t, regions, 2)
+
+	chunk := "1\n00:00:05,000 --> 00:00:09,000\nToday we talk\nabout ee bee pee eff\n\n"
+	hint := lowConfidenceHint(chunk, regions)
+	assert.Contains(t, hint, `- [00:00:05-00:00:12] "Today we talk about ee bee pee eff" (low confidence)`)
+	assert.NotContains(t, hint, "another chunk")
+
+	assert.Empty(t, lowConfidenceHint("Nothing flagged here.", regions))
+	assert.Nil(t, loadConfidenceRegions(context.Background(), filepath.Join(dir, "missing_qc.yaml")))
+	assert.Nil(t, loadConfidenceRegions(context.Background(), ""))
+}
+
+func TestWriteDiffReport(t *testing.T) {
+	dir := t.TempDir()
+	rawPath := filepath.Join(dir, "raw.txt")
+	correctedPath := filepath.Join(dir, "raw_corrected.txt")
+	require.NoError(t, os.WriteFile(rawPath, []byte("we talk about haiti security\nand <cloud> stuff\n"), 0644))
+	require.NoError(t, os.WriteFile(correctedPath, []byte("---\nguest: Ana\n---\nwe talk about IT security\nand <cloud> stuff\n"), 0644))
+
+	reportPath := diffReportPath(correctedPath, diffReportHTML)
+	assert.Equal(t, filepath.Join(dir, "raw_corrected_diff.html"), reportPath)
+	summary, err := writeDiffReport(context.Background(), rawPath, correctedPath, reportPath, diffReportHTML)
+	require.NoError(t, err)
+	assert.Equal(t, diffSummary{Words: 8, Removed: 1, Added: 1}, summary)
+	assert.InDelta(t, 0.125, summary.ChangeRatio(), 1e-9)
+
+	report, err := os.ReadFile(reportPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(report), "<del>haiti</del> <ins>IT</ins>")
+	assert.Contains(t, string(report), "&lt;cloud&gt;")
+	assert.Contains(t, string(report), "1 of 8 words removed, 1 added")
+	assert.NotContains(t, string(report), "guest")
+
+	reportPath = diffReportPath(correctedPath, diffReportUnified)
+	_, err = writeDiffReport(context.Background(), rawPath, correctedPath, reportPath, diffReportUnified)
+	require.NoError(t, err)
+	report, err = os.ReadFile(reportPath)
+	require.NoError(t, err)
+	assert.Equal(t, "--- raw.txt\n+++ raw_corrected.txt\n@@ -1,2 +1,2 @@\n-we talk about haiti security\n+we talk about IT security\n and <cloud> stuff\n", string(report))
+}
+
+// TestGolden records the prompt built with the default template, the corrected transcript and the
+// diff report written from a fixture response; rerun with UPDATE_SNAPSHOTS=1 after an intended change
+func TestGolden(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "test-key")
+	tempDir := t.TempDir()
+	inputFile := filepath.Join(tempDir, "transcript.txt")
+	require.NoError(t, os.WriteFile(inputFile, []byte("so um today we we talk about moog synthesizers\nand why they sound so worm"), 0644))
+
+	var sent []services.ChatMessage
+	mockService := chatgptmocks.NewMockChatGPTServicer(t)
+	mockService.EXPECT().GetContent(mock.Anything, mock.Anything, mock.Anything).
+		Run(func(_ context.Context, messages []services.ChatMessage, _ services.CompletionOptions) {
+			sent = messages
+		}).
+		Return("So today we talk about Moog synthesizers\nand why they sound so warm.", nil)
+
+	module := &Module{chatGPTService: mockService}
+	result, err := module.Execute(context.Background(), map[string]interface{}{
+		"input":          inputFile,
+		"output":         filepath.Join(tempDir, "output"),
+		"targetLanguage": "English",
+		"diffReport":     "unified",
+		"metadata":       map[string]interface{}{"guest": "Jane Doe"},
+	})
+	require.NoError(t, err)
+
+	snapshot.MatchMessages(t, "prompt.txt", sent)
+	for _, name := range []string{"corrected", "diffReport"} {
+		data, err := os.ReadFile(result.Outputs[name])
+		require.NoError(t, err)
+		snapshot.Match(t, filepath.Base(result.Outputs[name]), string(data), tempDir, "<tmp>")
+	}
+}
@@ -0,0 +1,174 @@
+package correcttranscript
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"path/filepath"
+	"strings"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+// Formats of the diff report
+const (
+	diffReportHTML    = "html"
+	diffReportUnified = "unified"
+	diffReportNone    = "none"
+)
+
+// lineBreak is the token standing for the end of a line in a word diff
+const lineBreak = "\n"
+
+// diffSummary counts the words the correction removed and added
+type diffSummary struct {
+	Words   int // Words in the raw transcript
+	Removed int
+	Added   int
+}
+
+// ChangeRatio is the share of the raw transcript's words the correction touched
+func (s diffSummary) ChangeRatio() float64 {
+	if s.Words == 0 {
+		return 0
+	}
+	return float64(max(s.Removed, s.Added)) / float64(s.Words)
+}
+
+// diffReportPath names the report after the corrected transcript
+func diffReportPath(correctedPath, format string) string {
+	base := strings.TrimSuffix(correctedPath, filepath.Ext(correctedPath))
+	if format == diffReportUnified {
+		return base + "_diff.diff"
+	}
+	return base + "_diff.html"
+}
+
+// writeDiffReport compares the raw and corrected transcripts, both without front matter, and writes
+// the report in the requested format
+func writeDiffReport(ctx context.Context, rawPath, correctedPath, reportPath, format string) (diffSummary, error) {
+	raw, err := utils.ReadTextFile(rawPath)
+	if err != nil {
+		return diffSummary{}, fmt.Errorf("failed to read raw transcript: %w", err)
+	}
+	corrected, err := utils.ReadTextFile(correctedPath)
+	if err != nil {
+		return diffSummary{}, fmt.Errorf("failed to read corrected transcript: %w", err)
+	}
+	_, raw = utils.SplitEpisodeFrontMatter(raw)
+	_, corrected = utils.SplitEpisodeFrontMatter(corrected)
+	raw = strings.TrimSpace(raw)
+	corrected = strings.TrimSpace(corrected)
+
+	ops, complete := utils.DiffTokens(diffWords(raw), diffWords(corrected), utils.DefaultMaxDiffEdits)
+	if !complete {
+		utils.LogWarningContext(ctx, "The corrected transcript differs too much from the raw one for a word-level diff; the report shows it replaced as a whole")
+	}
+	summary := summarizeDiff(ops)
+
+	var report string
+	if format == diffReportUnified {
+		report = utils.UnifiedDiff(filepath.Base(rawPath), filepath.Base(correctedPath), raw, corrected, 3)
+	} else {
+		report = renderHTMLDiff(filepath.Base(rawPath), filepath.Base(correctedPath), ops, summary)
+	}
+	if err := utils.WriteTextFile(reportPath, report); err != nil {
+		return diffSummary{}, fmt.Errorf("failed to write diff report: %w", err)
+	}
+	return summary, nil
+}
+
+// diffWords splits a text into words, keeping line ends as tokens so the report keeps the layout
+func diffWords(text string) []string {
+	var tokens []string
+	for i, line := range strings.Split(text, "\n") {
+		if i > 0 {
+			tokens = append(tokens, lineBreak)
+		}
+		tokens = append(tokens, strings.Fields(line)...)
+	}
+	return tokens
+}
+
+// summarizeDiff counts words, leaving out line ends
+func summarizeDiff(ops []utils.DiffOp) diffSummary {
+	var s diffSummary
+	for _, op := range ops {
+		words := 0
+		for _, token := range op.Tokens {
+			if token != lineBreak {
+				words++
+			}
+		}
+		switch op.Kind {
+		case utils.DiffEqual:
+			s.Words += words
+		case utils.DiffDelete:
+			s.Words += words
+			s.Removed += words
+		case utils.DiffInsert:
+			s.Added += words
+		}
+	}
+	return s
+}
+
+// renderHTMLDiff renders the word diff as a standalone page, removed words struck through in red
+// and added words in green
+func renderHTMLDiff(rawName, correctedName string, ops []utils.DiffOp, summary diffSummary) string {
+	var b strings.Builder
+	title := html.EscapeString(fmt.Sprintf("%s → %s", rawName, correctedName))
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n", title)
+	b.WriteString("<style>\n" +
+		"body { font-family: sans-serif; max-width: 60em; margin: 2em auto; line-height: 1.6; }\n" +
+		".transcript { font-family: monospace; white-space: normal; }\n" +
+		"del { background: #fdd; color: #a00; }\n" +
+		"ins { background: #dfd; color: #060; text-decoration: none; }\n" +
+		"</style>\n</head>\n<body>\n")
+	fmt.Fprintf(&b, "<h1>%s</h1>\n", title)
+	fmt.Fprintf(&b, "<p>%d of %d words removed, %d added (%.1f%% changed)</p>\n",
+		summary.Removed, summary.Words, summary.Added, summary.ChangeRatio()*100)
+
+	b.WriteString("<div class=\"transcript\">\n")
+	for _, op := range ops {
+		tag := ""
+		switch op.Kind {
+		case utils.DiffDelete:
+			tag = "del"
+		case utils.DiffInsert:
+			tag = "ins"
+		}
+		// Each line gets its own tag, so a change never spans a <br>
+		var words []string
+		flush := func() {
+			if len(words) == 0 {
+				return
+			}
+			text := html.EscapeString(strings.Join(words, " "))
+			if tag != "" {
+				text = "<" + tag + ">" + text + "</" + tag + ">"
+			}
+			b.WriteString(text + " ")
+			words = nil
+		}
+		for _, token := range op.Tokens {
+			if token == lineBreak {
+				flush()
+				if op.Kind == utils.DiffInsert {
+					b.WriteString("<ins>¶</ins>")
+				} else if op.Kind == utils.DiffDelete {
+					b.WriteString("<del>¶</del>")
+				}
+				if op.Kind != utils.DiffDelete {
+					b.WriteString("<br>\n")
+				}
+				continue
+			}
+			words = append(words, token)
+		}
+		flush()
+	}
+	b.WriteString("\n</div>\n</body>\n</html>\n")
+	return b.String()
+}
@@ -0,0 +1,947 @@
+package exporttimeline
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	modules "github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+// Supported export formats
+const (
+	FormatEDL      = "edl"      // CMX3600 edit decision list
+	FormatFCPXML   = "fcpxml"   // Final Cut Pro X / DaVinci Resolve XML
+	FormatPremiere = "premiere" // Final Cut Pro 7 XML (xmeml), imported by Premiere Pro
+	FormatOTIO     = "otio"     // OpenTimelineIO JSON timeline
+)
+
+// supportedFormats lists the formats accepted by the formats parameter
+var supportedFormats = []string{FormatEDL, FormatFCPXML, FormatPremiere, FormatOTIO}
+
+// formatExtensions maps each format to the extension of the file it produces
+var formatExtensions = map[string]string{
+	FormatEDL:      ".edl",
+	FormatFCPXML:   ".fcpxml",
+	FormatPremiere: ".xml",
+	FormatOTIO:     ".otio",
+}
+
+// Module implements the highlight reel export for NLEs
+type Module struct{}
+
+// Params contains the parameters for the timeline export
+type Params struct {
+	Input        string  `json:"input"`                                      // Path to shorts_suggestions.yaml file
+	Output       string  `json:"output"`                                     // Path to output directory
+	VideoFile    string  `json:"videoFile"`                                  // Path to the source video (default: sourceVideo from the shorts file)
+	Formats      string  `json:"formats" default:"edl,fcpxml,premiere,otio"` // Comma-separated formats to export: edl, fcpxml, premiere, otio (default: all)
+	OutputName   string  `json:"outputName" default:"highlights"`            // Base name of the exported files, without extension (default: "highlights")
+	SequenceName string  `json:"sequenceName" default:"StudioFlowAI Shorts"` // Name of the sequence shown in the NLE (default: "StudioFlowAI Shorts")
+	FrameRate    float64 `json:"frameRate" default:"30"`                     // Frame rate of the source video, e.g. 29.97 (default: 30)
+	DropFrame    bool    `json:"dropFrame"`                                  // Write drop-frame timecode in the EDL, 29.97 and 59.94 only (default: false)
+	Width        int     `json:"width" default:"1920"`                       // Source video width in pixels (default: 1920)
+	Height       int     `json:"height" default:"1080"`                      // Source video height in pixels (default: 1080)
+	GapSeconds   float64 `json:"gapSeconds"`                                 // Gap between clips on the timeline in seconds (default: 0)
+}
+
+// TimelineClip is a single select placed on the exported sequence
+type TimelineClip struct {
+	Name        string // Clip name shown in the NLE
+	Comment     string // Description carried as a comment/marker
+	SourceIn    int    // First frame in the source video
+	SourceOut   int    // Frame after the last frame in the source video
+	RecordIn    int    // Position of the clip on the sequence
+	RecordOut   int    // End position of the clip on the sequence
+	Description string // Original suggestion description
+	Tags        string // Comma-separated tags suggested for the clip
+}
+
+// Timeline is the sequence of selects built from a shorts file
+type Timeline struct {
+	Name      string
+	VideoPath string
+	Timebase  utils.Timebase
+	DropFrame bool // Whether timecode is written as drop-frame
+	Width     int
+	Height    int
+	Clips     []TimelineClip
+}
+
+// New creates a new timeline export module
+func New() modules.Module {
+	return &Module{}
+}
+
+// Name returns the module name
+func (m *Module) Name() string {
+	return "export_timeline"
+}
+
+// ParamsTemplate returns the module's parameter struct, used to validate and document workflows
+func (m *Module) ParamsTemplate() interface{} {
+	return Params{}
+}
+
+// Validate checks if the parameters are valid
+func (m *Module) Validate(params map[string]interface{}) error {
+	var p Params
+	if err := modules.ParseParams(params, &p); err != nil {
+		return err
+	}
+
+	// Validate input path
+	if err := utils.ValidateInputPath(p.Input, p.Output, ""); err != nil {
+		return err
+	}
+
+	// Validate output path
+	if err := utils.ValidateOutputPath(p.Output); err != nil {
+		return err
+	}
+
+	// The source video may not exist yet when it is produced by an earlier step
+	if p.VideoFile != "" && !utils.ProducedByWorkflow(p.VideoFile, p.Output) {
+		if _, err := os.Stat(p.VideoFile); os.IsNotExist(err) {
+			return fmt.Errorf("video file does not exist: %s", p.VideoFile)
+		}
+	}
+
+	if _, err := parseFormats(p.Formats); err != nil {
+		return err
+	}
+
+	if p.FrameRate != 0 {
+		timebase, err := utils.NewTimebase(p.FrameRate)
+		if err != nil {
+			return err
+		}
+		if p.DropFrame && !timebase.SupportsDropFrame() {
+			return fmt.Errorf("dropFrame needs a 29.97 or 59.94 frameRate, got %v", p.FrameRate)
+		}
+	} else if p.DropFrame {
+		return fmt.Errorf("dropFrame needs a 29.97 or 59.94 frameRate")
+	}
+	if p.Width < 0 || p.Height < 0 || p.GapSeconds < 0 {
+		return fmt.Errorf("width, height and gapSeconds must not be negative")
+	}
+
+	return nil
+}
+
+// Execute converts the suggested shorts into NLE sequences referencing the source video
+func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (modules.ModuleResult, error) {
+	var p Params
+	if err := modules.ParseParams(params, &p); err != nil {
+		return modules.ModuleResult{}, err
+	}
+
+	// Set default values
+	if p.Formats == "" {
+		p.Formats = strings.Join(supportedFormats, ",")
+	}
+	if p.OutputName == "" {
+		p.OutputName = "highlights"
+	}
+	if p.SequenceName == "" {
+		p.SequenceName = "StudioFlowAI Shorts"
+	}
+	if p.FrameRate == 0 {
+		p.FrameRate = 30
+	}
+	if p.Width == 0 {
+		p.Width = 1920
+	}
+	if p.Height == 0 {
+		p.Height = 1080
+	}
+
+	formats, err := parseFormats(p.Formats)
+	if err != nil {
+		return modules.ModuleResult{}, err
+	}
+
+	timebase, err := utils.NewTimebase(p.FrameRate)
+	if err != nil {
+		return modules.ModuleResult{}, err
+	}
+
+	resolvedInput := utils.ResolveOutputPath(p.Input, p.Output)
+	shortsData, err := utils.ReadShortsFile(resolvedInput)
+	if err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to read shorts file: %w", err)
+	}
+
+	videoFile := utils.ResolveOutputPath(p.VideoFile, p.Output)
+	if videoFile == "" {
+		videoFile = shortsData.SourceVideo
+	}
+	if videoFile == "" {
+		return modules.ModuleResult{}, fmt.Errorf("videoFile is required when the shorts file has no sourceVideo")
+	}
+	if absPath, err := filepath.Abs(videoFile); err == nil {
+		videoFile = absPath
+	}
+
+	timeline, err := BuildTimeline(shortsData, videoFile, timebase, p)
+	if err != nil {
+		return modules.ModuleResult{}, err
+	}
+
+	if err := utils.EnsureDir(p.Output); err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	outputs := make(map[string]string)
+	for _, format := range formats {
+		var data []byte
+		switch format {
+		case FormatEDL:
+			data = []byte(RenderEDL(timeline))
+		case FormatFCPXML:
+			data, err = RenderFCPXML(timeline)
+		case FormatPremiere:
+			data, err = RenderPremiereXML(timeline)
+		case FormatOTIO:
+			data, err = RenderOTIO(timeline)
+		}
+		if err != nil {
+			return modules.ModuleResult{}, fmt.Errorf("failed to render %s: %w", format, err)
+		}
+
+		outputPath := filepath.Join(p.Output, p.OutputName+formatExtensions[format])
+		if err := utils.AtomicWriteFile(outputPath, data, 0644); err != nil {
+			return modules.ModuleResult{}, fmt.Errorf("failed to write %s: %w", format, err)
+		}
+		outputs[format] = outputPath
+		utils.LogSuccessContext(ctx, "Exported %s timeline to %s", format, outputPath)
+	}
+
+	return modules.ModuleResult{
+		Outputs: outputs,
+		Statistics: map[string]interface{}{
+			"input_file":   resolvedInput,
+			"source_video": videoFile,
+			"clips_count":  len(timeline.Clips),
+			"formats":      formats,
+			"frame_rate":   timebase.FPS(),
+			"process_time": time.Now().Format(time.RFC3339),
+		},
+		Stats: modules.Stats{Items: len(timeline.Clips)},
+	}, nil
+}
+
+// parseFormats splits and validates the comma-separated formats parameter
+func parseFormats(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return supportedFormats, nil
+	}
+
+	var formats []string
+	seen := make(map[string]bool)
+	for _, f := range strings.Split(value, ",") {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if f == "" || seen[f] {
+			continue
+		}
+		if _, ok := formatExtensions[f]; !ok {
+			return nil, fmt.Errorf("unsupported export format %q (supported: %s)", f, strings.Join(supportedFormats, ", "))
+		}
+		seen[f] = true
+		formats = append(formats, f)
+	}
+	if len(formats) == 0 {
+		return nil, fmt.Errorf("at least one export format is required")
+	}
+	return formats, nil
+}
+
+// BuildTimeline lays the suggested shorts out back to back on a sequence
+func BuildTimeline(shortsData *utils.ShortsData, videoPath string, timebase utils.Timebase, p Params) (*Timeline, error) {
+	timeline := &Timeline{
+		Name:      p.SequenceName,
+		VideoPath: videoPath,
+		Timebase:  timebase,
+		DropFrame: p.DropFrame && timebase.SupportsDropFrame(),
+		Width:     p.Width,
+		Height:    p.Height,
+	}
+
+	gap := timebase.Frames(time.Duration(p.GapSeconds * float64(time.Second)))
+	record := 0
+	for i, short := range shortsData.Shorts {
+		// Cut points may be timestamps or SMPTE timecode at the source frame rate
+		in, err := timebase.ParseCutPoint(short.StartTime)
+		if err != nil {
+			return nil, fmt.Errorf("clip %d: invalid startTime: %w", i+1, err)
+		}
+		out, err := timebase.ParseCutPoint(short.EndTime)
+		if err != nil {
+			return nil, fmt.Errorf("clip %d: invalid endTime: %w", i+1, err)
+		}
+		if out <= in {
+			return nil, fmt.Errorf("clip %d: endTime %s must be after startTime %s", i+1, short.EndTime, short.StartTime)
+		}
+
+		name := short.ShortTitle
+		if name == "" {
+			name = short.Title
+		}
+		if name == "" {
+			name = fmt.Sprintf("Short %d", i+1)
+		}
+
+		timeline.Clips = append(timeline.Clips, TimelineClip{
+			Name:        name,
+			Comment:     short.Title,
+			SourceIn:    in,
+			SourceOut:   out,
+			RecordIn:    record,
+			RecordOut:   record + out - in,
+			Description: short.Description,
+			Tags:        short.Tags,
+		})
+		record += out - in + gap
+	}
+
+	if len(timeline.Clips) == 0 {
+		return nil, fmt.Errorf("shorts file contains no clips to export")
+	}
+
+	return timeline, nil
+}
+
+// Duration returns the length of the sequence in frames
+func (t *Timeline) Duration() int {
+	if len(t.Clips) == 0 {
+		return 0
+	}
+	return t.Clips[len(t.Clips)-1].RecordOut
+}
+
+// SourceDuration returns the minimum source media length, in frames, covering every clip
+func (t *Timeline) SourceDuration() int {
+	duration := 0
+	for _, clip := range t.Clips {
+		if clip.SourceOut > duration {
+			duration = clip.SourceOut
+		}
+	}
+	return duration
+}
+
+// fileURL converts a local path into a file:// URL understood by NLEs
+func fileURL(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+// edlRecordStartHours places the record timeline at the conventional 01:00:00:00
+const edlRecordStartHours = 1
+
+// RenderEDL renders the timeline as a CMX3600 edit decision list
+func RenderEDL(t *Timeline) string {
+	recordOffset := edlRecordStartHours * 3600 * t.Timebase.Nominal()
+	timecode, fcm := t.Timebase.Timecode, "NON-DROP FRAME"
+	if t.DropFrame {
+		// One drop-frame hour is 108 frames shorter than its nominal frame count
+		recordOffset, _ = t.Timebase.ParseTimecode(fmt.Sprintf("%02d:00:00;00", edlRecordStartHours))
+		timecode, fcm = t.Timebase.DropFrameTimecode, "DROP FRAME"
+	}
+	clipName := filepath.Base(t.VideoPath)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "TITLE: %s\n", sanitizeEDLText(t.Name))
+	fmt.Fprintf(&b, "FCM: %s\n\n", fcm)
+
+	for i, clip := range t.Clips {
+		fmt.Fprintf(&b, "%03d  AX       AA/V  C        %s %s %s %s\n",
+			i+1,
+			timecode(clip.SourceIn),
+			timecode(clip.SourceOut),
+			timecode(recordOffset+clip.RecordIn),
+			timecode(recordOffset+clip.RecordOut),
+		)
+		fmt.Fprintf(&b, "* FROM CLIP NAME: %s\n", sanitizeEDLText(clipName))
+		fmt.Fprintf(&b, "* COMMENT: %s\n", sanitizeEDLText(clip.Name))
+		if clip.Comment != "" && clip.Comment != clip.Name {
+			fmt.Fprintf(&b, "* COMMENT: %s\n", sanitizeEDLText(clip.Comment))
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// sanitizeEDLText keeps EDL comments on a single line
+func sanitizeEDLText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// FCPXML document structure (version 1.9)
+type fcpxmlDocument struct {
+	XMLName   xml.Name        `xml:"fcpxml"`
+	Version   string          `xml:"version,attr"`
+	Resources fcpxmlResources `xml:"resources"`
+	Library   fcpxmlLibrary   `xml:"library"`
+}
+
+type fcpxmlResources struct {
+	Format fcpxmlFormat `xml:"format"`
+	Asset  fcpxmlAsset  `xml:"asset"`
+}
+
+type fcpxmlFormat struct {
+	ID            string `xml:"id,attr"`
+	FrameDuration string `xml:"frameDuration,attr"`
+	Width         int    `xml:"width,attr"`
+	Height        int    `xml:"height,attr"`
+}
+
+type fcpxmlAsset struct {
+	ID       string         `xml:"id,attr"`
+	Name     string         `xml:"name,attr"`
+	Start    string         `xml:"start,attr"`
+	Duration string         `xml:"duration,attr"`
+	HasVideo string         `xml:"hasVideo,attr"`
+	HasAudio string         `xml:"hasAudio,attr"`
+	Format   string         `xml:"format,attr"`
+	Media    fcpxmlMediaRep `xml:"media-rep"`
+}
+
+type fcpxmlMediaRep struct {
+	Kind string `xml:"kind,attr"`
+	Src  string `xml:"src,attr"`
+}
+
+type fcpxmlLibrary struct {
+	Event fcpxmlEvent `xml:"event"`
+}
+
+type fcpxmlEvent struct {
+	Name    string        `xml:"name,attr"`
+	Project fcpxmlProject `xml:"project"`
+}
+
+type fcpxmlProject struct {
+	Name     string         `xml:"name,attr"`
+	Sequence fcpxmlSequence `xml:"sequence"`
+}
+
+type fcpxmlSequence struct {
+	Format   string      `xml:"format,attr"`
+	Duration string      `xml:"duration,attr"`
+	TCStart  string      `xml:"tcStart,attr"`
+	TCFormat string      `xml:"tcFormat,attr"`
+	Spine    fcpxmlSpine `xml:"spine"`
+}
+
+type fcpxmlSpine struct {
+	Items []fcpxmlSpineItem `xml:",any"`
+}
+
+// fcpxmlSpineItem is either an asset-clip or a gap, selected by XMLName
+type fcpxmlSpineItem struct {
+	XMLName  xml.Name
+	Ref      string         `xml:"ref,attr,omitempty"`
+	Name     string         `xml:"name,attr"`
+	Offset   string         `xml:"offset,attr"`
+	Start    string         `xml:"start,attr,omitempty"`
+	Duration string         `xml:"duration,attr"`
+	Note     string         `xml:"note,omitempty"`
+	Markers  []fcpxmlMarker `xml:"marker,omitempty"`
+}
+
+type fcpxmlMarker struct {
+	Start    string `xml:"start,attr"`
+	Duration string `xml:"duration,attr"`
+	Value    string `xml:"value,attr"`
+}
+
+// RenderFCPXML renders the timeline as an FCPXML 1.9 project
+func RenderFCPXML(t *Timeline) ([]byte, error) {
+	tb := t.Timebase
+	doc := fcpxmlDocument{
+		Version: "1.9",
+		Resources: fcpxmlResources{
+			Format: fcpxmlFormat{
+				ID:            "r1",
+				FrameDuration: tb.Seconds(1),
+				Width:         t.Width,
+				Height:        t.Height,
+			},
+			Asset: fcpxmlAsset{
+				ID:       "r2",
+				Name:     filepath.Base(t.VideoPath),
+				Start:    "0s",
+				Duration: tb.Seconds(t.SourceDuration()),
+				HasVideo: "1",
+				HasAudio: "1",
+				Format:   "r1",
+				Media:    fcpxmlMediaRep{Kind: "original-media", Src: fileURL(t.VideoPath)},
+			},
+		},
+	}
+
+	var items []fcpxmlSpineItem
+	position := 0
+	for _, clip := range t.Clips {
+		if clip.RecordIn > position {
+			items = append(items, fcpxmlSpineItem{
+				XMLName:  xml.Name{Local: "gap"},
+				Name:     "Gap",
+				Offset:   tb.Seconds(position),
+				Duration: tb.Seconds(clip.RecordIn - position),
+			})
+		}
+		items = append(items, fcpxmlSpineItem{
+			XMLName:  xml.Name{Local: "asset-clip"},
+			Ref:      "r2",
+			Name:     clip.Name,
+			Offset:   tb.Seconds(clip.RecordIn),
+			Start:    tb.Seconds(clip.SourceIn),
+			Duration: tb.Seconds(clip.SourceOut - clip.SourceIn),
+			Note:     clip.Description,
+			Markers: []fcpxmlMarker{{
+				Start:    tb.Seconds(clip.SourceIn),
+				Duration: tb.Seconds(1),
+				Value:    clip.Comment,
+			}},
+		})
+		position = clip.RecordOut
+	}
+
+	doc.Library.Event = fcpxmlEvent{
+		Name: t.Name,
+		Project: fcpxmlProject{
+			Name: t.Name,
+			Sequence: fcpxmlSequence{
+				Format:   "r1",
+				Duration: tb.Seconds(t.Duration()),
+				TCStart:  "0s",
+				TCFormat: "NDF",
+				Spine:    fcpxmlSpine{Items: items},
+			},
+		},
+	}
+
+	return marshalXML(doc, "<!DOCTYPE fcpxml>\n")
+}
+
+// Premiere (xmeml version 4) document structure
+type xmemlDocument struct {
+	XMLName  xml.Name      `xml:"xmeml"`
+	Version  string        `xml:"version,attr"`
+	Sequence xmemlSequence `xml:"sequence"`
+}
+
+type xmemlRate struct {
+	Timebase int    `xml:"timebase"`
+	NTSC     string `xml:"ntsc"`
+}
+
+type xmemlSequence struct {
+	ID       string     `xml:"id,attr"`
+	Name     string     `xml:"name"`
+	Duration int        `xml:"duration"`
+	Rate     xmemlRate  `xml:"rate"`
+	Media    xmemlMedia `xml:"media"`
+}
+
+type xmemlMedia struct {
+	Video xmemlVideo `xml:"video"`
+	Audio xmemlAudio `xml:"audio"`
+}
+
+type xmemlVideo struct {
+	Format xmemlFormat  `xml:"format"`
+	Tracks []xmemlTrack `xml:"track"`
+}
+
+type xmemlAudio struct {
+	Tracks []xmemlTrack `xml:"track"`
+}
+
+type xmemlFormat struct {
+	Width  int       `xml:"samplecharacteristics>width"`
+	Height int       `xml:"samplecharacteristics>height"`
+	Rate   xmemlRate `xml:"samplecharacteristics>rate"`
+}
+
+type xmemlTrack struct {
+	ClipItems []xmemlClipItem `xml:"clipitem"`
+}
+
+type xmemlClipItem struct {
+	ID          string            `xml:"id,attr"`
+	Name        string            `xml:"name"`
+	Duration    int               `xml:"duration"`
+	Rate        xmemlRate         `xml:"rate"`
+	Start       int               `xml:"start"`
+	End         int               `xml:"end"`
+	In          int               `xml:"in"`
+	Out         int               `xml:"out"`
+	File        xmemlFile         `xml:"file"`
+	SourceTrack *xmemlSourceTrack `xml:"sourcetrack,omitempty"`
+	Comments    *xmemlComments    `xml:"comments,omitempty"`
+}
+
+type xmemlSourceTrack struct {
+	MediaType  string `xml:"mediatype"`
+	TrackIndex int    `xml:"trackindex"`
+}
+
+type xmemlComments struct {
+	MasterComment1 string `xml:"mastercomment1"`
+}
+
+// xmemlFile is written in full on first use and referenced by id afterwards
+type xmemlFile struct {
+	ID       string          `xml:"id,attr"`
+	Name     string          `xml:"name,omitempty"`
+	PathURL  string          `xml:"pathurl,omitempty"`
+	Rate     *xmemlRate      `xml:"rate,omitempty"`
+	Duration int             `xml:"duration,omitempty"`
+	Media    *xmemlFileMedia `xml:"media,omitempty"`
+}
+
+type xmemlFileMedia struct {
+	Video struct{} `xml:"video"`
+	Audio struct{} `xml:"audio"`
+}
+
+// RenderPremiereXML renders the timeline as Final Cut Pro 7 XML, the interchange format imported by Premiere Pro
+func RenderPremiereXML(t *Timeline) ([]byte, error) {
+	ntsc := "FALSE"
+	if t.Timebase.NTSC {
+		ntsc = "TRUE"
+	}
+	rate := xmemlRate{Timebase: t.Timebase.Nominal(), NTSC: ntsc}
+
+	fullFile := xmemlFile{
+		ID:       "file-1",
+		Name:     filepath.Base(t.VideoPath),
+		PathURL:  fileURL(t.VideoPath),
+		Rate:     &rate,
+		Duration: t.SourceDuration(),
+		Media:    &xmemlFileMedia{},
+	}
+
+	var videoItems, audioItems []xmemlClipItem
+	for i, clip := range t.Clips {
+		item := xmemlClipItem{
+			Name:     clip.Name,
+			Duration: t.SourceDuration(),
+			Rate:     rate,
+			Start:    clip.RecordIn,
+			End:      clip.RecordOut,
+			In:       clip.SourceIn,
+			Out:      clip.SourceOut,
+			File:     xmemlFile{ID: "file-1"},
+		}
+		if clip.Comment != "" {
+			item.Comments = &xmemlComments{MasterComment1: clip.Comment}
+		}
+
+		video := item
+		video.ID = fmt.Sprintf("clipitem-v%d", i+1)
+		if i == 0 {
+			video.File = fullFile
+		}
+		videoItems = append(videoItems, video)
+
+		audio := item
+		audio.ID = fmt.Sprintf("clipitem-a%d", i+1)
+		audio.Comments = nil
+		audio.SourceTrack = &xmemlSourceTrack{MediaType: "audio", TrackIndex: 1}
+		audioItems = append(audioItems, audio)
+	}
+
+	doc := xmemlDocument{
+		Version: "4",
+		Sequence: xmemlSequence{
+			ID:       "sequence-1",
+			Name:     t.Name,
+			Duration: t.Duration(),
+			Rate:     rate,
+			Media: xmemlMedia{
+				Video: xmemlVideo{
+					Format: xmemlFormat{Width: t.Width, Height: t.Height, Rate: rate},
+					Tracks: []xmemlTrack{{ClipItems: videoItems}},
+				},
+				Audio: xmemlAudio{
+					Tracks: []xmemlTrack{{ClipItems: audioItems}},
+				},
+			},
+		},
+	}
+
+	return marshalXML(doc, "<!DOCTYPE xmeml>\n")
+}
+
+// marshalXML encodes an XML document with its declaration and doctype
+func marshalXML(doc interface{}, doctype string) ([]byte, error) {
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(doctype)
+	b.Write(body)
+	b.WriteString("\n")
+	return []byte(b.String()), nil
+}
+
+// OpenTimelineIO schema objects, serialized as OTIO JSON
+type otioRationalTime struct {
+	Schema string  `json:"OTIO_SCHEMA"`
+	Rate   float64 `json:"rate"`
+	Value  float64 `json:"value"`
+}
+
+type otioTimeRange struct {
+	Schema    string           `json:"OTIO_SCHEMA"`
+	StartTime otioRationalTime `json:"start_time"`
+	Duration  otioRationalTime `json:"duration"`
+}
+
+type otioMarker struct {
+	Schema      string                 `json:"OTIO_SCHEMA"`
+	Name        string                 `json:"name"`
+	Color       string                 `json:"color"`
+	Comment     string                 `json:"comment"`
+	MarkedRange otioTimeRange          `json:"marked_range"`
+	Metadata    map[string]interface{} `json:"metadata"`
+}
+
+type otioExternalReference struct {
+	Schema         string                 `json:"OTIO_SCHEMA"`
+	Name           string                 `json:"name"`
+	TargetURL      string                 `json:"target_url"`
+	AvailableRange *otioTimeRange         `json:"available_range"`
+	Metadata       map[string]interface{} `json:"metadata"`
+}
+
+// otioItem is a Clip, Gap, Track or Stack; fields that do not apply to a schema are omitted
+type otioItem struct {
+	Schema               string                           `json:"OTIO_SCHEMA"`
+	Name                 string                           `json:"name"`
+	Kind                 string                           `json:"kind,omitempty"`
+	SourceRange          *otioTimeRange                   `json:"source_range"`
+	Effects              []interface{}                    `json:"effects"`
+	Markers              []otioMarker                     `json:"markers"`
+	Enabled              bool                             `json:"enabled"`
+	Metadata             map[string]interface{}           `json:"metadata"`
+	MediaReferences      map[string]otioExternalReference `json:"media_references,omitempty"`
+	ActiveMediaReference string                           `json:"active_media_reference_key,omitempty"`
+	Children             []otioItem                       `json:"children,omitempty"`
+}
+
+type otioTimeline struct {
+	Schema          string                 `json:"OTIO_SCHEMA"`
+	Name            string                 `json:"name"`
+	GlobalStartTime *otioRationalTime      `json:"global_start_time"`
+	Metadata        map[string]interface{} `json:"metadata"`
+	Tracks          otioItem               `json:"tracks"`
+}
+
+// otioRange builds a time range from frame positions at the timeline rate
+func otioRange(rate float64, start, duration int) otioTimeRange {
+	return otioTimeRange{
+		Schema:    "TimeRange.1",
+		StartTime: otioRationalTime{Schema: "RationalTime.1", Rate: rate, Value: float64(start)},
+		Duration:  otioRationalTime{Schema: "RationalTime.1", Rate: rate, Value: float64(duration)},
+	}
+}
+
+// otioMarkers creates the title marker and one marker per tag, spanning the whole clip
+func otioMarkers(clip TimelineClip, rate float64) []otioMarker {
+	marked := otioRange(rate, clip.SourceIn, clip.SourceOut-clip.SourceIn)
+	markers := []otioMarker{{
+		Schema:      "Marker.2",
+		Name:        clip.Name,
+		Color:       "RED",
+		Comment:     clip.Comment,
+		MarkedRange: marked,
+		Metadata:    map[string]interface{}{"studioflowai": map[string]interface{}{"type": "title"}},
+	}}
+	for _, tag := range strings.Split(clip.Tags, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		markers = append(markers, otioMarker{
+			Schema:      "Marker.2",
+			Name:        tag,
+			Color:       "BLUE",
+			MarkedRange: marked,
+			Metadata:    map[string]interface{}{"studioflowai": map[string]interface{}{"type": "tag"}},
+		})
+	}
+	return markers
+}
+
+// RenderOTIO renders the timeline as an OpenTimelineIO JSON document
+func RenderOTIO(t *Timeline) ([]byte, error) {
+	rate := t.Timebase.FPS()
+	available := otioRange(rate, 0, t.SourceDuration())
+
+	var children []otioItem
+	position := 0
+	for _, clip := range t.Clips {
+		if clip.RecordIn > position {
+			gap := otioRange(rate, 0, clip.RecordIn-position)
+			children = append(children, otioItem{
+				Schema:      "Gap.1",
+				SourceRange: &gap,
+				Effects:     []interface{}{},
+				Markers:     []otioMarker{},
+				Enabled:     true,
+				Metadata:    map[string]interface{}{},
+			})
+		}
+
+		source := otioRange(rate, clip.SourceIn, clip.SourceOut-clip.SourceIn)
+		children = append(children, otioItem{
+			Schema:      "Clip.2",
+			Name:        clip.Name,
+			SourceRange: &source,
+			Effects:     []interface{}{},
+			Markers:     otioMarkers(clip, rate),
+			Enabled:     true,
+			Metadata: map[string]interface{}{
+				"studioflowai": map[string]interface{}{
+					"title":       clip.Comment,
+					"description": clip.Description,
+					"tags":        clip.Tags,
+				},
+			},
+			MediaReferences: map[string]otioExternalReference{
+				"DEFAULT_MEDIA": {
+					Schema:         "ExternalReference.1",
+					Name:           filepath.Base(t.VideoPath),
+					TargetURL:      fileURL(t.VideoPath),
+					AvailableRange: &available,
+					Metadata:       map[string]interface{}{},
+				},
+			},
+			ActiveMediaReference: "DEFAULT_MEDIA",
+		})
+		position = clip.RecordOut
+	}
+
+	track := otioItem{
+		Schema:   "Track.1",
+		Name:     "V1",
+		Kind:     "Video",
+		Effects:  []interface{}{},
+		Markers:  []otioMarker{},
+		Enabled:  true,
+		Metadata: map[string]interface{}{},
+		Children: children,
+	}
+
+	doc := otioTimeline{
+		Schema: "Timeline.1",
+		Name:   t.Name,
+		Metadata: map[string]interface{}{
+			"studioflowai": map[string]interface{}{
+				"source_video": t.VideoPath,
+				"width":        t.Width,
+				"height":       t.Height,
+			},
+		},
+		Tracks: otioItem{
+			Schema:   "Stack.1",
+			Name:     "tracks",
+			Effects:  []interface{}{},
+			Markers:  []otioMarker{},
+			Enabled:  true,
+			Metadata: map[string]interface{}{},
+			Children: []otioItem{track},
+		},
+	}
+
+	data, err := json.MarshalIndent(doc, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// GetIO returns the module's input/output specification
+func (m *Module) GetIO() modules.ModuleIO {
+	return modules.ModuleIO{
+		RequiredInputs: []modules.ModuleInput{
+			{
+				Name:        "input",
+				Description: "Path to shorts suggestions YAML file",
+				Patterns:    []string{".yaml"},
+				Type:        string(modules.InputTypeFile),
+			},
+			{
+				Name:        "output",
+				Description: "Path to output directory",
+				Type:        string(modules.InputTypeDirectory),
+			},
+		},
+		OptionalInputs: []modules.ModuleInput{
+			{
+				Name:        "videoFile",
+				Description: "Path to source video file (default: sourceVideo from the shorts file)",
+				Patterns:    []string{".mp4", ".mov"},
+				Type:        string(modules.InputTypeFile),
+			},
+			{
+				Name:        "formats",
+				Description: "Comma-separated export formats: edl, fcpxml, premiere, otio",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "frameRate",
+				Description: "Frame rate of the source video (default: 30)",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "dropFrame",
+				Description: "Write drop-frame timecode in the EDL (29.97 and 59.94 only)",
+				Type:        string(modules.InputTypeData),
+			},
+		},
+		// One output per requested format, named after it
+		ProducedOutputs: []modules.ModuleOutput{
+			{
+				Name:        FormatEDL,
+				Description: "CMX3600 edit decision list",
+				Patterns:    []string{formatExtensions[FormatEDL]},
+				Type:        string(modules.OutputTypeFile),
+			},
+			{
+				Name:        FormatFCPXML,
+				Description: "Final Cut Pro X / DaVinci Resolve XML",
+				Patterns:    []string{formatExtensions[FormatFCPXML]},
+				Type:        string(modules.OutputTypeFile),
+			},
+			{
+				Name:        FormatPremiere,
+				Description: "Final Cut Pro 7 XML (xmeml), imported by Premiere Pro",
+				Patterns:    []string{formatExtensions[FormatPremiere]},
+				Type:        string(modules.OutputTypeFile),
+			},
+			{
+				Name:        FormatOTIO,
+				Description: "OpenTimelineIO JSON timeline",
+				Patterns:    []string{formatExtensions[FormatOTIO]},
+				Type:        string(modules.OutputTypeFile),
+			},
+		},
+	}
+}
@@ -0,0 +1,328 @@
+package exporttimeline
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/shortstest"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const shortsYAML = `shorts:
+  - title: "The big reveal"
+    startTime: "00:01:02"
+    endTime: "00:01:30"
+    description: "Host explains the trick"
+    tags: "magic"
+    shortTitle: "Reveal"
+  - title: "Audience reaction"
+    startTime: "00:10:00.5"
+    endTime: "00:10:20"
+    description: "Crowd goes wild"
+    tags: "crowd"
+`
+
+func TestModule_Name(t *testing.T) {
+	assert.Equal(t, "export_timeline", New().Name())
+}
+
+func TestModule_GetIO(t *testing.T) {
+	io := New().GetIO()
+
+	assert.Len(t, io.RequiredInputs, 2)
+	assert.Equal(t, "input", io.RequiredInputs[0].Name)
+	assert.Equal(t, "output", io.RequiredInputs[1].Name)
+
+	// The outputs are those Execute returns, one per format
+	names := make([]string, 0, len(io.ProducedOutputs))
+	for _, output := range io.ProducedOutputs {
+		names = append(names, output.Name)
+	}
+	assert.Equal(t, []string{"edl", "fcpxml", "premiere", "otio"}, names)
+}
+
+func TestModule_Validate(t *testing.T) {
+	tempDir := t.TempDir()
+	shortsPath := shortstest.WriteFile(t, tempDir, "/videos/source.mp4", shortsYAML)
+
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		wantErr string
+	}{
+		{
+			name:   "valid defaults",
+			params: map[string]interface{}{"input": shortsPath, "output": tempDir},
+		},
+		{
+			name:   "valid ntsc rate",
+			params: map[string]interface{}{"input": shortsPath, "output": tempDir, "frameRate": 29.97, "formats": "edl"},
+		},
+		{
+			name:    "unknown format",
+			params:  map[string]interface{}{"input": shortsPath, "output": tempDir, "formats": "edl,aaf"},
+			wantErr: "unsupported export format",
+		},
+		{
+			name:    "unsupported frame rate",
+			params:  map[string]interface{}{"input": shortsPath, "output": tempDir, "frameRate": 27.5},
+			wantErr: "unsupported frame rate",
+		},
+		{
+			name:    "missing video file",
+			params:  map[string]interface{}{"input": shortsPath, "output": tempDir, "videoFile": filepath.Join(t.TempDir(), "missing.mp4")},
+			wantErr: "video file does not exist",
+		},
+		{
+			name:   "video file produced by an earlier step",
+			params: map[string]interface{}{"input": shortsPath, "output": tempDir, "videoFile": filepath.Join(tempDir, "episode_proxy.mp4")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := New().Validate(tt.params)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestBuildTimeline(t *testing.T) {
+	timebase, err := utils.NewTimebase(30)
+	require.NoError(t, err)
+
+	data := &utils.ShortsData{Shorts: []utils.ShortClip{
+		{Title: "First", StartTime: "00:00:10", EndTime: "00:00:20"},
+		{Title: "Second", ShortTitle: "Two", StartTime: "00:01:00", EndTime: "00:01:05"},
+	}}
+
+	timeline, err := BuildTimeline(data, "/videos/source.mp4", timebase, Params{SequenceName: "Seq", GapSeconds: 1})
+	require.NoError(t, err)
+	require.Len(t, timeline.Clips, 2)
+
+	assert.Equal(t, "First", timeline.Clips[0].Name)
+	assert.Equal(t, 300, timeline.Clips[0].SourceIn)
+	assert.Equal(t, 600, timeline.Clips[0].SourceOut)
+	assert.Equal(t, 0, timeline.Clips[0].RecordIn)
+	assert.Equal(t, 300, timeline.Clips[0].RecordOut)
+
+	assert.Equal(t, "Two", timeline.Clips[1].Name)
+	assert.Equal(t, 330, timeline.Clips[1].RecordIn)
+	assert.Equal(t, 480, timeline.Clips[1].RecordOut)
+	assert.Equal(t, 480, timeline.Duration())
+	assert.Equal(t, 1950, timeline.SourceDuration())
+
+	_, err = BuildTimeline(&utils.ShortsData{Shorts: []utils.ShortClip{
+		{Title: "Backwards", StartTime: "00:00:20", EndTime: "00:00:10"},
+	}}, "/videos/source.mp4", timebase, Params{})
+	assert.ErrorContains(t, err, "must be after startTime")
+
+	_, err = BuildTimeline(&utils.ShortsData{}, "/videos/source.mp4", timebase, Params{})
+	assert.ErrorContains(t, err, "no clips")
+}
+
+func TestRenderEDL(t *testing.T) {
+	timebase, err := utils.NewTimebase(25)
+	require.NoError(t, err)
+
+	timeline := &Timeline{
+		Name:      "My Show",
+		VideoPath: "/videos/source.mp4",
+		Timebase:  timebase,
+		Clips: []TimelineClip{
+			{Name: "Reveal", Comment: "The big reveal", SourceIn: 1550, SourceOut: 2250, RecordIn: 0, RecordOut: 700},
+		},
+	}
+
+	edl := RenderEDL(timeline)
+	assert.Contains(t, edl, "TITLE: My Show\n")
+	assert.Contains(t, edl, "FCM: NON-DROP FRAME")
+	assert.Contains(t, edl, "001  AX       AA/V  C        00:01:02:00 00:01:30:00 01:00:00:00 01:00:28:00\n")
+	assert.Contains(t, edl, "* FROM CLIP NAME: source.mp4")
+	assert.Contains(t, edl, "* COMMENT: Reveal")
+	assert.Contains(t, edl, "* COMMENT: The big reveal")
+}
+
+func TestRenderEDLDropFrame(t *testing.T) {
+	timebase, err := utils.NewTimebase(29.97)
+	require.NoError(t, err)
+
+	timeline := &Timeline{
+		Name:      "My Show",
+		VideoPath: "/videos/source.mp4",
+		Timebase:  timebase,
+		DropFrame: true,
+		Clips: []TimelineClip{
+			{Name: "Reveal", SourceIn: 1800, SourceOut: 17982, RecordIn: 0, RecordOut: 16182},
+		},
+	}
+
+	edl := RenderEDL(timeline)
+	assert.Contains(t, edl, "FCM: DROP FRAME")
+	assert.Contains(t, edl, "001  AX       AA/V  C        00:01:00;02 00:10:00;00 01:00:00;00 01:08:59;28\n")
+}
+
+func TestRenderFCPXML(t *testing.T) {
+	timebase, err := utils.NewTimebase(29.97)
+	require.NoError(t, err)
+
+	timeline := &Timeline{
+		Name:      "Shorts & Selects",
+		VideoPath: "/videos/my source.mp4",
+		Timebase:  timebase,
+		Width:     1920,
+		Height:    1080,
+		Clips: []TimelineClip{
+			{Name: "One", Comment: "First", SourceIn: 30, SourceOut: 60, RecordIn: 0, RecordOut: 30},
+			{Name: "Two", Comment: "Second", SourceIn: 90, SourceOut: 120, RecordIn: 60, RecordOut: 90},
+		},
+	}
+
+	data, err := RenderFCPXML(timeline)
+	require.NoError(t, err)
+	out := string(data)
+
+	assert.True(t, strings.HasPrefix(out, "<?xml"))
+	assert.Contains(t, out, `<!DOCTYPE fcpxml>`)
+	assert.Contains(t, out, `frameDuration="1001/30000s"`)
+	assert.Contains(t, out, `src="file:///videos/my%20source.mp4"`)
+	assert.Contains(t, out, `name="Shorts &amp; Selects"`)
+	assert.Contains(t, out, `<asset-clip ref="r2" name="One" offset="0s" start="30030/30000s" duration="30030/30000s">`)
+	assert.Contains(t, out, `<gap name="Gap" offset="30030/30000s" duration="30030/30000s">`)
+
+	var doc fcpxmlDocument
+	require.NoError(t, xml.Unmarshal(data, &doc))
+	assert.Equal(t, "1.9", doc.Version)
+}
+
+func TestRenderPremiereXML(t *testing.T) {
+	timebase, err := utils.NewTimebase(23.976)
+	require.NoError(t, err)
+
+	timeline := &Timeline{
+		Name:      "Selects",
+		VideoPath: "/videos/source.mp4",
+		Timebase:  timebase,
+		Width:     3840,
+		Height:    2160,
+		Clips: []TimelineClip{
+			{Name: "One", Comment: "First", SourceIn: 24, SourceOut: 48, RecordIn: 0, RecordOut: 24},
+			{Name: "Two", SourceIn: 96, SourceOut: 120, RecordIn: 24, RecordOut: 48},
+		},
+	}
+
+	data, err := RenderPremiereXML(timeline)
+	require.NoError(t, err)
+
+	var doc xmemlDocument
+	require.NoError(t, xml.Unmarshal(data, &doc))
+	assert.Equal(t, "4", doc.Version)
+	assert.Equal(t, 24, doc.Sequence.Rate.Timebase)
+	assert.Equal(t, "TRUE", doc.Sequence.Rate.NTSC)
+	assert.Equal(t, 48, doc.Sequence.Duration)
+
+	video := doc.Sequence.Media.Video.Tracks[0].ClipItems
+	require.Len(t, video, 2)
+	assert.Equal(t, "file:///videos/source.mp4", video[0].File.PathURL)
+	assert.Equal(t, "file-1", video[1].File.ID)
+	assert.Empty(t, video[1].File.PathURL)
+	assert.Equal(t, 96, video[1].In)
+	assert.Equal(t, 120, video[1].Out)
+
+	audio := doc.Sequence.Media.Audio.Tracks[0].ClipItems
+	require.Len(t, audio, 2)
+	assert.Equal(t, "audio", audio[0].SourceTrack.MediaType)
+}
+
+func TestRenderOTIO(t *testing.T) {
+	timebase, err := utils.NewTimebase(30)
+	require.NoError(t, err)
+
+	timeline := &Timeline{
+		Name:      "Selects",
+		VideoPath: "/videos/source.mp4",
+		Timebase:  timebase,
+		Clips: []TimelineClip{
+			{Name: "One", Comment: "First", Tags: "magic, reveal", SourceIn: 300, SourceOut: 600, RecordIn: 0, RecordOut: 300},
+			{Name: "Two", SourceIn: 900, SourceOut: 960, RecordIn: 330, RecordOut: 390},
+		},
+	}
+
+	data, err := RenderOTIO(timeline)
+	require.NoError(t, err)
+
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &doc))
+	assert.Equal(t, "Timeline.1", doc["OTIO_SCHEMA"])
+	assert.Equal(t, "Selects", doc["name"])
+
+	tracks := doc["tracks"].(map[string]interface{})
+	assert.Equal(t, "Stack.1", tracks["OTIO_SCHEMA"])
+	track := tracks["children"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "Video", track["kind"])
+
+	items := track["children"].([]interface{})
+	require.Len(t, items, 3)
+	clip := items[0].(map[string]interface{})
+	assert.Equal(t, "Clip.2", clip["OTIO_SCHEMA"])
+	sourceRange := clip["source_range"].(map[string]interface{})
+	assert.Equal(t, 300.0, sourceRange["start_time"].(map[string]interface{})["value"])
+	assert.Equal(t, 300.0, sourceRange["duration"].(map[string]interface{})["value"])
+	ref := clip["media_references"].(map[string]interface{})["DEFAULT_MEDIA"].(map[string]interface{})
+	assert.Equal(t, "file:///videos/source.mp4", ref["target_url"])
+
+	markers := clip["markers"].([]interface{})
+	require.Len(t, markers, 3)
+	assert.Equal(t, "One", markers[0].(map[string]interface{})["name"])
+	assert.Equal(t, "magic", markers[1].(map[string]interface{})["name"])
+	assert.Equal(t, "reveal", markers[2].(map[string]interface{})["name"])
+
+	gap := items[1].(map[string]interface{})
+	assert.Equal(t, "Gap.1", gap["OTIO_SCHEMA"])
+	assert.Equal(t, 30.0, gap["source_range"].(map[string]interface{})["duration"].(map[string]interface{})["value"])
+}
+
+func TestModule_Execute(t *testing.T) {
+	tempDir := t.TempDir()
+	videoPath := filepath.Join(tempDir, "source.mp4")
+	require.NoError(t, os.WriteFile(videoPath, []byte("dummy"), 0644))
+	shortsPath := shortstest.WriteFile(t, tempDir, videoPath, shortsYAML)
+	outputDir := filepath.Join(tempDir, "out")
+
+	result, err := New().Execute(context.Background(), map[string]interface{}{
+		"input":  shortsPath,
+		"output": outputDir,
+	})
+	require.NoError(t, err)
+
+	assert.Len(t, result.Outputs, 4)
+	for format, ext := range formatExtensions {
+		path := result.Outputs[format]
+		assert.Equal(t, filepath.Join(outputDir, "highlights"+ext), path)
+		assert.FileExists(t, path)
+	}
+	assert.Equal(t, 2, result.Statistics["clips_count"])
+
+	edl, err := os.ReadFile(result.Outputs[FormatEDL])
+	require.NoError(t, err)
+	assert.Contains(t, string(edl), "002  AX       AA/V  C        00:10:00:15 00:10:20:00 01:00:28:00 01:00:47:15")
+
+	_, err = New().Execute(context.Background(), map[string]interface{}{
+		"input":   shortsPath,
+		"output":  outputDir,
+		"formats": "fcpxml",
+	})
+	require.NoError(t, err)
+}
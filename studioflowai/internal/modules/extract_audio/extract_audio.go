@@ -6,6 +6,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	modules "github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
 	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
@@ -19,13 +21,24 @@ type Module struct{}
 
 // Params contains the parameters for audio extraction
 type Params struct {
-	Input      string `json:"input"`      // Path to input video file or directory
-	Output     string `json:"output"`     // Path to output directory
-	OutputName string `json:"outputName"` // Custom output filename (optional)
-	SampleRate int    `json:"sampleRate"` // Sample rate in Hz (default: 16000)
-	Channels   int    `json:"channels"`   // Number of audio channels (default: 1)
+	Input      string `json:"input"`                      // Path to input video file or directory
+	Output     string `json:"output"`                     // Path to output directory
+	OutputName string `json:"outputName"`                 // Custom output filename (optional)
+	SampleRate int    `json:"sampleRate" default:"16000"` // Sample rate in Hz (default: 16000)
+	Channels   int    `json:"channels" default:"1"`       // Number of audio channels (default: 1)
+	AudioTrack int    `json:"audioTrack"`                 // Audio track to extract, counting from 1 (default: the default track)
+	Downmix    string `json:"downmix" default:"auto"`     // How channels are folded into mono: auto, average, left, right, center (default: "auto")
+	KeepSource bool   `json:"keepSourceFormat"`           // Keep the sample rate and channels of the source instead of converting them
+	StartTime  string `json:"startTime"`                  // Extract from this time of the video, e.g. "00:30:00" (default: the start)
+	EndTime    string `json:"endTime"`                    // Extract up to this time of the video (default: the end)
+
+	Include []string `json:"include"` // Patterns of the videos to use when input is a directory (default: "*.mp4", "*.mov")
+	Exclude []string `json:"exclude"` // Patterns of input directory files to leave out
 }
 
+// defaultInclude selects the videos of a directory input
+var defaultInclude = []string{"*.mp4", "*.mov"}
+
 // New creates a new extract module
 func New() modules.Module {
 	return &Module{}
@@ -36,6 +49,11 @@ func (m *Module) Name() string {
 	return "extractaudio"
 }
 
+// ParamsTemplate returns the module's parameter struct, used to validate and document workflows
+func (m *Module) ParamsTemplate() interface{} {
+	return Params{}
+}
+
 // Validate checks if the parameters are valid
 func (m *Module) Validate(params map[string]interface{}) error {
 	var p Params
@@ -53,6 +71,21 @@ func (m *Module) Validate(params map[string]interface{}) error {
 		return err
 	}
 
+	if err := utils.NewFileFilter(p.Include, p.Exclude, defaultInclude).Validate(); err != nil {
+		return err
+	}
+
+	if p.SampleRate < 0 || p.Channels < 0 || p.AudioTrack < 0 {
+		return fmt.Errorf("sampleRate, channels and audioTrack must not be negative")
+	}
+	if _, err := utils.ParseTimeRange(p.StartTime, p.EndTime); err != nil {
+		return err
+	}
+
+	if p.Downmix != "" && !containsMode(p.Downmix) {
+		return fmt.Errorf("invalid downmix %q, expected one of: %s", p.Downmix, strings.Join(downmixModes, ", "))
+	}
+
 	// Resolve the input path if it contains ${output}
 	resolvedInput := utils.ResolveOutputPath(p.Input, p.Output)
 
@@ -93,6 +126,9 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 	if p.Channels == 0 {
 		p.Channels = 1
 	}
+	if p.Downmix == "" {
+		p.Downmix = downmixAuto
+	}
 
 	// Ensure we have a valid output directory
 	if p.Output == "" {
@@ -109,42 +145,31 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 	}
 
 	// Create output directory if it doesn't exist
-	if err := os.MkdirAll(p.Output, 0755); err != nil {
+	if err := utils.EnsureDir(p.Output); err != nil {
 		return modules.ModuleResult{}, fmt.Errorf("failed to create output directory: %w", err)
 	}
 
 	if fileInfo.IsDir() {
 		// Process all video files in the directory
-		return m.processDirectory(p)
+		return m.processDirectory(ctx, p)
 	}
 
 	// Process a single file
-	return m.processFile(resolvedInput, p)
+	return m.processFile(ctx, resolvedInput, p)
 }
 
 // processDirectory processes all video files in a directory
-func (m *Module) processDirectory(p Params) (modules.ModuleResult, error) {
+func (m *Module) processDirectory(ctx context.Context, p Params) (modules.ModuleResult, error) {
 	// Resolve the input path if it contains ${output}
 	resolvedInput := utils.ResolveOutputPath(p.Input, p.Output)
 
-	entries, err := os.ReadDir(resolvedInput)
+	entries, err := utils.NewFileFilter(p.Include, p.Exclude, defaultInclude).Files(resolvedInput)
 	if err != nil {
-		return modules.ModuleResult{}, fmt.Errorf("failed to read directory: %w", err)
+		return modules.ModuleResult{}, err
 	}
 
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-
-		filename := entry.Name()
-		ext := filepath.Ext(filename)
-		if ext != ".mp4" && ext != ".mov" {
-			continue
-		}
-
-		inputPath := filepath.Join(resolvedInput, filename)
-		result, err := m.processFile(inputPath, p)
+	for _, inputPath := range entries {
+		result, err := m.processFile(ctx, inputPath, p)
 		if err != nil {
 			return modules.ModuleResult{}, err
 		}
@@ -155,7 +180,7 @@ func (m *Module) processDirectory(p Params) (modules.ModuleResult, error) {
 }
 
 // processFile extracts audio from a single video file
-func (m *Module) processFile(filePath string, p Params) (modules.ModuleResult, error) {
+func (m *Module) processFile(ctx context.Context, filePath string, p Params) (modules.ModuleResult, error) {
 	var audioPath string
 
 	if p.OutputName != "" {
@@ -168,37 +193,107 @@ func (m *Module) processFile(filePath string, p Params) (modules.ModuleResult, e
 		audioPath = filepath.Join(p.Output, baseName)
 	}
 
-	utils.LogVerbose("Extracting audio from %s to %s", filePath, audioPath)
+	utils.LogVerboseContext(ctx, "Extracting audio from %s to %s", filePath, audioPath)
+
+	args, err := extractArgs(ctx, filePath, audioPath, p)
+	if err != nil {
+		return modules.ModuleResult{}, err
+	}
 
 	// Extract audio with ffmpeg
-	cmd := execCommand(
-		"ffmpeg",
-		"-i", filePath,
-		"-vn",
-		"-ar", fmt.Sprintf("%d", p.SampleRate),
-		"-ac", fmt.Sprintf("%d", p.Channels),
-		"-c:a", "pcm_s16le",
-		audioPath,
-		"-y",
-		"-loglevel", "error",
-	)
+	cmd := execCommand("ffmpeg", args...)
 
 	// Redirect stdout and stderr to suppress output
 	cmd.Stdout = nil
 	cmd.Stderr = nil
 
-	if err := cmd.Run(); err != nil {
+	if err := utils.RunWatched(ctx, cmd); err != nil {
 		return modules.ModuleResult{}, fmt.Errorf("ffmpeg command failed: %w", err)
 	}
 
-	utils.LogSuccess("Successfully extracted audio to %s", audioPath)
+	utils.LogSuccessContext(ctx, "Successfully extracted audio to %s", audioPath)
 	return modules.ModuleResult{
 		Outputs: map[string]string{
 			"audio": audioPath,
 		},
+		Stats: modules.Stats{Items: 1, ToolVersions: utils.ToolVersions("ffmpeg")},
 	}, nil
 }
 
+// extractArgs builds the ffmpeg arguments for a file. The input is probed first, so the right
+// track is taken and its channels are folded into mono the way speech recognition works best on;
+// when probing fails the audio is converted with ffmpeg's defaults.
+func extractArgs(ctx context.Context, filePath, audioPath string, p Params) ([]string, error) {
+	// Only the range of the video the run is restricted to is extracted; seeking before the input is fast
+	timeRange, err := utils.ParseTimeRange(p.StartTime, p.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	var args []string
+	if timeRange.Start > 0 {
+		args = append(args, "-ss", utils.FFmpegSeconds(timeRange.Start))
+	}
+	args = append(args, "-i", filePath, "-vn")
+	if timeRange.End > 0 {
+		args = append(args, "-t", utils.FFmpegSeconds(timeRange.Duration()))
+	}
+	if !timeRange.IsZero() {
+		utils.LogInfoContext(ctx, "Extracting audio of %s only", timeRange)
+	}
+
+	streams, err := probeAudio(ctx, filePath)
+	if err != nil {
+		utils.LogWarningContext(ctx, "Could not probe the audio of %s, converting with ffmpeg defaults: %v", filePath, err)
+	} else if len(streams) == 0 {
+		return nil, fmt.Errorf("%s has no audio track", filePath)
+	}
+
+	var stream audioStream
+	track := 0
+	if len(streams) > 0 {
+		track, err = selectTrack(streams, p.AudioTrack)
+		if err != nil {
+			return nil, err
+		}
+		stream = streams[track]
+		if len(streams) > 1 && p.AudioTrack == 0 {
+			utils.LogWarningContext(ctx, "%s has %d audio tracks, using track %d (%s); set audioTrack to pick another",
+				filepath.Base(filePath), len(streams), track+1, stream)
+		}
+		args = append(args, "-map", fmt.Sprintf("0:a:%d", track))
+	}
+
+	if p.KeepSource {
+		utils.LogVerboseContext(ctx, "Keeping the source format: %s", stream)
+	} else {
+		if p.Channels == 1 && stream.Channels > 1 {
+			if filter := downmixFilter(ctx, filePath, track, timeRange.Start, stream, p.Downmix); filter != "" {
+				args = append(args, "-af", filter)
+			}
+		}
+		args = append(args, "-ar", strconv.Itoa(p.SampleRate), "-ac", strconv.Itoa(p.Channels))
+		if stream.Channels > 0 {
+			utils.LogVerboseContext(ctx, "Converting %s to %d Hz, %d channel(s)", stream, p.SampleRate, p.Channels)
+		}
+	}
+
+	args = append(args, "-c:a", audioCodec(audioPath))
+	if filepath.Ext(audioPath) == "" {
+		args = append(args, "-f", "wav")
+	}
+	return append(args, audioPath, "-y", "-loglevel", "error"), nil
+}
+
+// containsMode reports whether mode is a known downmix mode
+func containsMode(mode string) bool {
+	for _, m := range downmixModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
 // GetIO returns the module's input/output specification
 func (m *Module) GetIO() modules.ModuleIO {
 	return modules.ModuleIO{
@@ -231,6 +326,26 @@ func (m *Module) GetIO() modules.ModuleIO {
 				Description: "Number of audio channels (default: 1)",
 				Type:        string(modules.InputTypeData),
 			},
+			{
+				Name:        "audioTrack",
+				Description: "Audio track to extract, counting from 1 (default: the default track)",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "downmix",
+				Description: "How channels are folded into mono: auto, average, left, right, center (default: \"auto\")",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "startTime",
+				Description: "Extract from this time of the video (default: the start)",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "endTime",
+				Description: "Extract up to this time of the video (default: the end)",
+				Type:        string(modules.InputTypeData),
+			},
 		},
 		ProducedOutputs: []modules.ModuleOutput{
 			{
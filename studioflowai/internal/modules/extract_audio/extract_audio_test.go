@@ -64,10 +64,14 @@ func TestModule_GetIO(t *testing.T) {
 	assert.Equal(t, "output", io.RequiredInputs[1].Name)
 
 	// Test optional inputs
-	assert.Len(t, io.OptionalInputs, 3)
+	assert.Len(t, io.OptionalInputs, 7)
 	assert.Equal(t, "outputName", io.OptionalInputs[0].Name)
 	assert.Equal(t, "sampleRate", io.OptionalInputs[1].Name)
 	assert.Equal(t, "channels", io.OptionalInputs[2].Name)
+	assert.Equal(t, "audioTrack", io.OptionalInputs[3].Name)
+	assert.Equal(t, "downmix", io.OptionalInputs[4].Name)
+	assert.Equal(t, "startTime", io.OptionalInputs[5].Name)
+	assert.Equal(t, "endTime", io.OptionalInputs[6].Name)
 
 	// Test produced outputs
 	assert.Len(t, io.ProducedOutputs, 1)
@@ -134,6 +138,25 @@ func TestModule_Validate(t *testing.T) {
 				require.NoError(t, err)
 			},
 		},
+		{
+			name: "invalid downmix",
+			params: map[string]interface{}{
+				"input":   videoPath,
+				"output":  tempDir,
+				"downmix": "surround",
+			},
+			wantErr: true,
+		},
+		{
+			name: "end before start",
+			params: map[string]interface{}{
+				"input":     videoPath,
+				"output":    tempDir,
+				"startTime": "01:00:00",
+				"endTime":   "00:30:00",
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid output name extension",
 			params: map[string]interface{}{
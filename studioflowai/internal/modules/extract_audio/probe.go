@@ -0,0 +1,222 @@
+package extractaudio
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+const (
+	levelSampleRate = 8000  // Sample rate the audio is decoded at to compare channel levels
+	levelSeconds    = "120" // Seconds of audio from the start used to compare channel levels
+	silentGapDB     = 30.0  // A channel this much quieter than the other is treated as unused
+	silenceFloorDB  = -90.0 // Level assigned to digital silence
+)
+
+// Downmix modes
+const (
+	downmixAuto    = "auto"
+	downmixAverage = "average"
+	downmixLeft    = "left"
+	downmixRight   = "right"
+	downmixCenter  = "center"
+)
+
+// downmixModes lists the accepted values of the downmix parameter
+var downmixModes = []string{downmixAuto, downmixAverage, downmixLeft, downmixRight, downmixCenter}
+
+// audioStream describes one audio track of the input
+type audioStream struct {
+	Codec      string
+	SampleRate int
+	Channels   int
+	Layout     string
+	Default    bool
+}
+
+// String describes the stream for logs, e.g. "aac, 48000 Hz, 6 channels (5.1)"
+func (s audioStream) String() string {
+	desc := fmt.Sprintf("%s, %d Hz, %d channels", s.Codec, s.SampleRate, s.Channels)
+	if s.Layout != "" {
+		desc += " (" + s.Layout + ")"
+	}
+	return desc
+}
+
+// hasCenter reports whether the channel layout has a front center channel, where surround mixes put dialogue
+func (s audioStream) hasCenter() bool {
+	switch strings.TrimSuffix(s.Layout, "(side)") {
+	case "3.0", "3.1", "4.0", "4.1", "5.0", "5.1", "6.0", "6.1", "7.0", "7.1":
+		return true
+	}
+	return false
+}
+
+// probeAudio lists the audio streams of a file with ffprobe
+func probeAudio(ctx context.Context, path string) ([]audioStream, error) {
+	out, err := utils.OutputWatched(ctx, execCommand("ffprobe",
+		"-v", "error",
+		"-select_streams", "a",
+		"-show_entries", "stream=codec_name,sample_rate,channels,channel_layout:stream_disposition=default",
+		"-of", "json",
+		path,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe failed: %w", err)
+	}
+
+	var probe struct {
+		Streams []struct {
+			CodecName     string `json:"codec_name"`
+			SampleRate    string `json:"sample_rate"`
+			Channels      int    `json:"channels"`
+			ChannelLayout string `json:"channel_layout"`
+			Disposition   struct {
+				Default int `json:"default"`
+			} `json:"disposition"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(out, &probe); err != nil {
+		return nil, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+
+	streams := make([]audioStream, 0, len(probe.Streams))
+	for _, s := range probe.Streams {
+		rate, _ := strconv.Atoi(s.SampleRate)
+		streams = append(streams, audioStream{
+			Codec:      s.CodecName,
+			SampleRate: rate,
+			Channels:   s.Channels,
+			Layout:     s.ChannelLayout,
+			Default:    s.Disposition.Default == 1,
+		})
+	}
+	return streams, nil
+}
+
+// selectTrack returns the position among the audio streams of the track to extract. A track
+// number counts from 1; 0 picks the stream marked as default, or the first one.
+func selectTrack(streams []audioStream, track int) (int, error) {
+	if track > 0 {
+		if track > len(streams) {
+			return 0, fmt.Errorf("audioTrack %d does not exist, the input has %d audio track(s)", track, len(streams))
+		}
+		return track - 1, nil
+	}
+	for i, s := range streams {
+		if s.Default {
+			return i, nil
+		}
+	}
+	return 0, nil
+}
+
+// downmixFilter returns the filter that folds the stream into mono, or "" to let ffmpeg average
+// the channels. With auto, surround mixes keep their dialogue channel and stereo recordings with
+// one silent channel, such as a single microphone on the left input, keep the channel that has sound.
+func downmixFilter(ctx context.Context, path string, track int, start time.Duration, stream audioStream, mode string) string {
+	if mode == downmixAuto {
+		mode = downmixAverage
+		switch {
+		case stream.hasCenter():
+			mode = downmixCenter
+		case stream.Channels == 2:
+			left, right, err := channelLevels(ctx, path, track, start)
+			if err != nil {
+				utils.LogWarningContext(ctx, "Could not compare the channel levels of %s, averaging them: %v", path, err)
+				break
+			}
+			if left-right > silentGapDB {
+				mode = downmixLeft
+			} else if right-left > silentGapDB {
+				mode = downmixRight
+			}
+			if mode != downmixAverage {
+				utils.LogInfoContext(ctx, "The %s channel holds the audio (left %.1f dB, right %.1f dB), using only that channel", mode, left, right)
+			}
+		}
+	}
+
+	switch mode {
+	case downmixLeft:
+		return "pan=mono|c0=c0"
+	case downmixRight:
+		if stream.Channels < 2 {
+			return ""
+		}
+		return "pan=mono|c0=c1"
+	case downmixCenter:
+		if !stream.hasCenter() {
+			utils.LogWarningContext(ctx, "Audio of %s has no center channel (%s), averaging all channels", path, stream.Layout)
+			return ""
+		}
+		return "pan=mono|c0=FC"
+	}
+	return ""
+}
+
+// channelLevels measures the RMS level in dBFS of the left and right channels at the start of a
+// track, or from start when the extraction is restricted to a range
+func channelLevels(ctx context.Context, path string, track int, start time.Duration) (float64, float64, error) {
+	cmd := execCommand("ffmpeg",
+		"-v", "error",
+		"-ss", utils.FFmpegSeconds(start),
+		"-t", levelSeconds,
+		"-i", path,
+		"-map", fmt.Sprintf("0:a:%d", track),
+		"-ac", "2", "-ar", strconv.Itoa(levelSampleRate),
+		"-f", "s16le", "-",
+	)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := utils.RunWatched(ctx, cmd); err != nil {
+		return 0, 0, fmt.Errorf("ffmpeg level analysis failed: %w", err)
+	}
+
+	raw := stdout.Bytes()
+	samples := make([]int16, len(raw)/4*2)
+	if len(samples) == 0 {
+		return 0, 0, fmt.Errorf("no audio decoded")
+	}
+	if err := binary.Read(bytes.NewReader(raw[:len(samples)*2]), binary.LittleEndian, samples); err != nil {
+		return 0, 0, fmt.Errorf("failed to decode audio samples: %w", err)
+	}
+
+	var left, right float64
+	for i := 0; i+1 < len(samples); i += 2 {
+		l := float64(samples[i]) / math.MaxInt16
+		r := float64(samples[i+1]) / math.MaxInt16
+		left += l * l
+		right += r * r
+	}
+	frames := float64(len(samples) / 2)
+	return toDB(left / frames), toDB(right / frames), nil
+}
+
+// toDB converts a mean square amplitude into dBFS
+func toDB(meanSquare float64) float64 {
+	if meanSquare <= 0 {
+		return silenceFloorDB
+	}
+	return math.Max(silenceFloorDB, 10*math.Log10(meanSquare))
+}
+
+// audioCodec returns the encoder for the extension of the output file
+func audioCodec(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".mp3":
+		return "libmp3lame"
+	case ".m4a", ".aac":
+		return "aac"
+	}
+	return "pcm_s16le"
+}
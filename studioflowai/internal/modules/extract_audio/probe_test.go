@@ -0,0 +1,215 @@
+package extractaudio
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const stereoProbe = `{"streams":[{"codec_name":"aac","sample_rate":"48000","channels":2,"channel_layout":"stereo","disposition":{"default":1}}]}`
+
+// fakeProbeCommand mocks ffprobe with the given output and decodes stereo audio whose channels
+// have the given amplitudes. Every command is recorded in calls.
+func fakeProbeCommand(probe string, left, right int, calls *[][]string) func(string, ...string) *exec.Cmd {
+	return func(command string, args ...string) *exec.Cmd {
+		*calls = append(*calls, append([]string{command}, args...))
+		cs := []string{"-test.run=TestProbeHelperProcess", "--", command}
+		cs = append(cs, args...)
+		cmd := exec.Command(os.Args[0], cs...)
+		cmd.Env = []string{
+			"GO_WANT_PROBE_HELPER=1",
+			"PROBE_OUTPUT=" + probe,
+			"LEFT_AMPLITUDE=" + strconv.Itoa(left),
+			"RIGHT_AMPLITUDE=" + strconv.Itoa(right),
+		}
+		return cmd
+	}
+}
+
+// TestProbeHelperProcess is not a real test, it's used to mock ffprobe and ffmpeg
+func TestProbeHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_PROBE_HELPER") != "1" {
+		return
+	}
+	args := os.Args
+	for len(args) > 0 && args[0] != "--" {
+		args = args[1:]
+	}
+	if len(args) > 1 && args[1] == "ffprobe" {
+		_, _ = os.Stdout.WriteString(os.Getenv("PROBE_OUTPUT"))
+		os.Exit(0)
+	}
+	if args[len(args)-1] == "-" {
+		left, _ := strconv.Atoi(os.Getenv("LEFT_AMPLITUDE"))
+		right, _ := strconv.Atoi(os.Getenv("RIGHT_AMPLITUDE"))
+		frames := make([]int16, 0, 1600)
+		for i := 0; i < 800; i++ {
+			sign := int16(1 - 2*(i%2))
+			frames = append(frames, sign*int16(left), sign*int16(right))
+		}
+		_ = binary.Write(os.Stdout, binary.LittleEndian, frames)
+	}
+	os.Exit(0)
+}
+
+func TestSelectTrack(t *testing.T) {
+	streams := []audioStream{{Channels: 2}, {Channels: 1, Default: true}, {Channels: 6}}
+
+	track, err := selectTrack(streams, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, track, "the default track is picked")
+
+	track, err = selectTrack(streams, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 2, track)
+
+	_, err = selectTrack(streams, 4)
+	assert.Error(t, err)
+
+	track, err = selectTrack([]audioStream{{Channels: 2}}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 0, track)
+}
+
+func TestAudioCodec(t *testing.T) {
+	assert.Equal(t, "pcm_s16le", audioCodec("/out/audio.wav"))
+	assert.Equal(t, "pcm_s16le", audioCodec("/out.dir/audio"))
+	assert.Equal(t, "libmp3lame", audioCodec("audio.MP3"))
+	assert.Equal(t, "aac", audioCodec("audio.m4a"))
+}
+
+func TestExtractArgs(t *testing.T) {
+	defer func() {
+		execCommand = exec.Command
+	}()
+	p := Params{SampleRate: 16000, Channels: 1, Downmix: downmixAuto}
+
+	tests := []struct {
+		name        string
+		probe       string
+		left, right int
+		params      func(p Params) Params
+		want        []string
+		notWant     []string
+		wantErr     bool
+	}{
+		{
+			name:  "balanced stereo is averaged",
+			probe: stereoProbe,
+			left:  8000, right: 7000,
+			want:    []string{"-map 0:a:0", "-ar 16000 -ac 1", "-c:a pcm_s16le"},
+			notWant: []string{"-af"},
+		},
+		{
+			name:  "stereo with a silent right channel keeps the left one",
+			probe: stereoProbe,
+			left:  8000, right: 0,
+			want: []string{"-af pan=mono|c0=c0", "-ar 16000 -ac 1"},
+		},
+		{
+			name:  "stereo with a silent left channel keeps the right one",
+			probe: stereoProbe,
+			left:  0, right: 8000,
+			want: []string{"-af pan=mono|c0=c1"},
+		},
+		{
+			name:  "surround keeps the dialogue channel",
+			probe: `{"streams":[{"codec_name":"ac3","sample_rate":"48000","channels":6,"channel_layout":"5.1(side)"}]}`,
+			want:  []string{"-af pan=mono|c0=FC", "-ar 16000 -ac 1"},
+		},
+		{
+			name:  "second track is picked",
+			probe: `{"streams":[{"codec_name":"aac","sample_rate":"48000","channels":1},{"codec_name":"aac","sample_rate":"44100","channels":1}]}`,
+			params: func(p Params) Params {
+				p.AudioTrack = 2
+				return p
+			},
+			want: []string{"-map 0:a:1"},
+		},
+		{
+			name:  "forced average",
+			probe: stereoProbe,
+			left:  8000, right: 0,
+			params: func(p Params) Params {
+				p.Downmix = downmixAverage
+				return p
+			},
+			notWant: []string{"-af"},
+		},
+		{
+			name:  "source format kept",
+			probe: stereoProbe,
+			params: func(p Params) Params {
+				p.KeepSource = true
+				return p
+			},
+			notWant: []string{"-ar", "-ac", "-af"},
+		},
+		{
+			name:  "time range",
+			probe: stereoProbe,
+			params: func(p Params) Params {
+				p.StartTime = "00:30:00"
+				p.EndTime = "01:15:00"
+				return p
+			},
+			want: []string{"-ss 1800.000 -i in.mp4 -vn -t 2700.000"},
+		},
+		{
+			name:    "no audio track",
+			probe:   `{"streams":[]}`,
+			wantErr: true,
+		},
+		{
+			name:    "unparsable probe falls back to ffmpeg defaults",
+			probe:   "not json",
+			want:    []string{"-ar 16000 -ac 1"},
+			notWant: []string{"-map", "-af"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls [][]string
+			execCommand = fakeProbeCommand(tt.probe, tt.left, tt.right, &calls)
+			params := p
+			if tt.params != nil {
+				params = tt.params(p)
+			}
+
+			args, err := extractArgs(context.Background(), "in.mp4", filepath.Join("out", "in.wav"), params)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			joined := strings.Join(args, " ")
+			for _, want := range tt.want {
+				assert.Contains(t, joined, want)
+			}
+			for _, notWant := range tt.notWant {
+				assert.NotContains(t, joined, notWant)
+			}
+		})
+	}
+}
+
+func TestExtractArgs_OutputWithoutExtension(t *testing.T) {
+	defer func() {
+		execCommand = exec.Command
+	}()
+	var calls [][]string
+	execCommand = fakeProbeCommand(`{"streams":[{"codec_name":"aac","sample_rate":"16000","channels":1}]}`, 0, 0, &calls)
+
+	args, err := extractArgs(context.Background(), "in.mp4", "out/in", Params{SampleRate: 16000, Channels: 1, Downmix: downmixAuto})
+	require.NoError(t, err)
+	assert.Contains(t, strings.Join(args, " "), "-f wav out/in")
+}
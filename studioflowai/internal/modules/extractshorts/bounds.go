@@ -0,0 +1,169 @@
+package extractshorts
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+// What to do with clips that end after the source video
+const (
+	OverrunClamp  = "clamp"  // End the clip with the video
+	OverrunReject = "reject" // Leave the clip out
+)
+
+// What to do with clips longer than the platform accepts
+const (
+	DurationTrim  = "trim"  // Cut the clip at the longest length allowed
+	DurationSplit = "split" // Cut the clip into parts of equal length
+	DurationDrop  = "drop"  // Leave the clip out
+)
+
+// ClipAdjustment records a clip whose times did not fit the source video or the platform's length cap
+type ClipAdjustment struct {
+	Title      string `json:"title" yaml:"title"`
+	StartTime  string `json:"startTime" yaml:"startTime"`
+	EndTime    string `json:"endTime" yaml:"endTime"`
+	Action     string `json:"action" yaml:"action"`                             // clamped, rejected, trimmed, split or dropped
+	NewEndTime string `json:"newEndTime,omitempty" yaml:"newEndTime,omitempty"` // End of a clamped or trimmed clip
+	Parts      int    `json:"parts,omitempty" yaml:"parts,omitempty"`           // Number of parts of a split clip
+	Reason     string `json:"reason" yaml:"reason"`
+}
+
+// checkClipBounds fits a clip into a source video of the given duration. It returns the end to cut
+// the clip at, 0 when the clip fits, and whether the clip is kept. Clips starting after the video
+// are always rejected, as there is nothing to cut.
+func checkClipBounds(short ShortClip, p Params, sourceDuration time.Duration) (time.Duration, *ClipAdjustment, bool, error) {
+	start, end, err := clipRange(short, p)
+	if err != nil {
+		return 0, nil, false, fmt.Errorf("clip %q: %w", short.Title, err)
+	}
+	if end <= sourceDuration {
+		return 0, nil, true, nil
+	}
+
+	adjustment := &ClipAdjustment{Title: short.Title, StartTime: short.StartTime, EndTime: short.EndTime}
+	length := utils.FormatTimestamp(sourceDuration)
+	switch {
+	case start >= sourceDuration:
+		adjustment.Action = "rejected"
+		adjustment.Reason = fmt.Sprintf("starts after the end of the video (%s)", length)
+		return 0, adjustment, false, nil
+	case p.Overrun == OverrunReject:
+		adjustment.Action = "rejected"
+		adjustment.Reason = fmt.Sprintf("ends after the end of the video (%s)", length)
+		return 0, adjustment, false, nil
+	default:
+		adjustment.Action = "clamped"
+		adjustment.NewEndTime = length
+		adjustment.Reason = fmt.Sprintf("ends after the end of the video (%s)", length)
+		return sourceDuration, adjustment, true, nil
+	}
+}
+
+// maxClipDuration returns the longest clip allowed: maxDuration when it is set, otherwise the
+// platform's cap
+func maxClipDuration(p Params) time.Duration {
+	if p.MaxDuration > 0 {
+		return time.Duration(p.MaxDuration * float64(time.Second))
+	}
+	return utils.MaxClipDuration(p.Platform)
+}
+
+// checkClipDuration holds a clip to the length cap, applying the duration policy. maxEnd is the
+// end the clip is cut at, 0 when it ends as suggested. It returns the jobs to render the clip, none
+// when it is dropped. Trimmed clips keep the file name of their suggested times; the parts of a
+// split clip are named after their own times and titled "title (1/2)".
+func checkClipDuration(short ShortClip, p Params, maxEnd time.Duration) ([]clipJob, *ClipAdjustment, error) {
+	job := clipJob{short: short, maxEnd: maxEnd}
+	limit := maxClipDuration(p)
+	if limit <= 0 {
+		return []clipJob{job}, nil, nil
+	}
+	start, end, err := clipRange(short, p)
+	if err != nil {
+		return nil, nil, fmt.Errorf("clip %q: %w", short.Title, err)
+	}
+	if maxEnd > 0 && maxEnd < end {
+		end = maxEnd
+	}
+	length := end - start
+	if length <= limit {
+		return []clipJob{job}, nil, nil
+	}
+
+	adjustment := &ClipAdjustment{
+		Title:     short.Title,
+		StartTime: short.StartTime,
+		EndTime:   short.EndTime,
+		Reason:    fmt.Sprintf("is %s long, over the %s limit of %s", formatLength(length), p.Platform, formatLength(limit)),
+	}
+	if p.MaxDuration > 0 {
+		adjustment.Reason = fmt.Sprintf("is %s long, over the maxDuration of %s", formatLength(length), formatLength(limit))
+	}
+
+	switch p.DurationPolicy {
+	case DurationDrop:
+		adjustment.Action = "dropped"
+		return nil, adjustment, nil
+
+	case DurationSplit:
+		parts := int((length + limit - 1) / limit)
+		adjustment.Action = "split"
+		adjustment.Parts = parts
+		jobs := make([]clipJob, parts)
+		for i := range parts {
+			partStart := start + length*time.Duration(i)/time.Duration(parts)
+			partEnd := end
+			if i < parts-1 {
+				partEnd = start + length*time.Duration(i+1)/time.Duration(parts)
+			}
+			part := short
+			part.Title = fmt.Sprintf("%s (%d/%d)", short.Title, i+1, parts)
+			part.StartTime = formatCutTime(partStart)
+			part.EndTime = formatCutTime(partEnd)
+			// The excerpt and cues of the whole clip do not describe a part
+			part.Excerpt = ""
+			part.Cues = nil
+			jobs[i] = clipJob{short: part}
+		}
+		return jobs, adjustment, nil
+
+	default:
+		adjustment.Action = "trimmed"
+		adjustment.NewEndTime = formatCutTime(start + limit)
+		job.maxEnd = start + limit
+		return []clipJob{job}, adjustment, nil
+	}
+}
+
+// formatCutTime formats a cut point as HH:MM:SS, with milliseconds when it falls between seconds
+func formatCutTime(d time.Duration) string {
+	d = d.Truncate(time.Millisecond)
+	if d%time.Second == 0 {
+		return utils.FormatTimestamp(d)
+	}
+	return strings.Replace(utils.FormatSRTTimestamp(d), ",", ".", 1)
+}
+
+// formatLength formats a clip length in seconds, e.g. "75s" or "62.5s"
+func formatLength(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
+
+// probeVideoDuration reads the duration of the source video with ffprobe
+func probeVideoDuration(ctx context.Context, path string) (time.Duration, error) {
+	out, err := utils.OutputWatched(ctx, execCommand(ctx, "ffprobe", "-v", "error", "-show_entries", "format=duration", "-of", "csv=p=0", path))
+	if err != nil {
+		return 0, fmt.Errorf("failed to probe video duration: %w", err)
+	}
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(string(out)), 64)
+	if err != nil || seconds <= 0 {
+		return 0, fmt.Errorf("failed to read video duration from ffprobe output %q", strings.TrimSpace(string(out)))
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
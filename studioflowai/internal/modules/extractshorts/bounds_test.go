@@ -0,0 +1,141 @@
+package extractshorts
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckClipBounds(t *testing.T) {
+	duration := 95 * time.Second
+	tests := []struct {
+		name    string
+		short   ShortClip
+		overrun string
+		end     time.Duration
+		action  string
+		keep    bool
+	}{
+		{"inside the video", ShortClip{StartTime: "00:01:00", EndTime: "00:01:30"}, "", 0, "", true},
+		{"ends with the video", ShortClip{StartTime: "00:01:00", EndTime: "00:01:35"}, "", 0, "", true},
+		{"clamped", ShortClip{StartTime: "00:01:00", EndTime: "00:02:00"}, OverrunClamp, duration, "clamped", true},
+		{"rejected on overrun", ShortClip{StartTime: "00:01:00", EndTime: "00:02:00"}, OverrunReject, 0, "rejected", false},
+		{"starts after the video", ShortClip{StartTime: "00:02:00", EndTime: "00:02:30"}, OverrunClamp, 0, "rejected", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end, adjustment, keep, err := checkClipBounds(tt.short, Params{Overrun: tt.overrun}, duration)
+			require.NoError(t, err)
+			assert.Equal(t, tt.end, end)
+			assert.Equal(t, tt.keep, keep)
+			if tt.action == "" {
+				assert.Nil(t, adjustment)
+				return
+			}
+			require.NotNil(t, adjustment)
+			assert.Equal(t, tt.action, adjustment.Action)
+			if tt.action == "clamped" {
+				assert.Equal(t, "00:01:35", adjustment.NewEndTime)
+			}
+		})
+	}
+
+	_, _, _, err := checkClipBounds(ShortClip{Title: "bad", StartTime: "x", EndTime: "00:00:10"}, Params{}, duration)
+	assert.ErrorContains(t, err, `clip "bad"`)
+}
+
+func TestCheckClipDuration(t *testing.T) {
+	long := ShortClip{Title: "Long", StartTime: "00:01:00", EndTime: "00:04:30", Excerpt: "whole clip"}
+
+	jobs, adjustment, err := checkClipDuration(ShortClip{StartTime: "00:01:00", EndTime: "00:02:00"}, Params{Platform: "youtube"}, 0)
+	require.NoError(t, err)
+	assert.Len(t, jobs, 1)
+	assert.Nil(t, adjustment)
+
+	// Trimmed clips are cut at the limit but keep their suggested times
+	jobs, adjustment, err = checkClipDuration(long, Params{Platform: "youtube", DurationPolicy: DurationTrim}, 0)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, 4*time.Minute, jobs[0].maxEnd)
+	assert.Equal(t, long, jobs[0].short)
+	require.NotNil(t, adjustment)
+	assert.Equal(t, "trimmed", adjustment.Action)
+	assert.Equal(t, "00:04:00", adjustment.NewEndTime)
+	assert.Equal(t, "is 210s long, over the youtube limit of 180s", adjustment.Reason)
+
+	jobs, adjustment, err = checkClipDuration(long, Params{Platform: "youtube", MaxDuration: 60, DurationPolicy: DurationSplit}, 0)
+	require.NoError(t, err)
+	require.NotNil(t, adjustment)
+	assert.Equal(t, "split", adjustment.Action)
+	assert.Equal(t, 4, adjustment.Parts)
+	require.Len(t, jobs, 4)
+	assert.Equal(t, "Long (1/4)", jobs[0].short.Title)
+	assert.Equal(t, "00:01:00", jobs[0].short.StartTime)
+	assert.Equal(t, "00:01:52.500", jobs[0].short.EndTime)
+	assert.Empty(t, jobs[0].short.Excerpt)
+	assert.Equal(t, "Long (4/4)", jobs[3].short.Title)
+	assert.Equal(t, "00:03:37.500", jobs[3].short.StartTime)
+	assert.Equal(t, "00:04:30", jobs[3].short.EndTime)
+
+	// A clip clamped to the end of the video is split within what is left
+	jobs, _, err = checkClipDuration(long, Params{Platform: "youtube", MaxDuration: 60, DurationPolicy: DurationSplit}, 3*time.Minute)
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, "00:03:00", jobs[1].short.EndTime)
+
+	jobs, adjustment, err = checkClipDuration(long, Params{Platform: "tiktok", MaxDuration: 90, DurationPolicy: DurationDrop}, 0)
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+	assert.Equal(t, "dropped", adjustment.Action)
+	assert.Equal(t, "is 210s long, over the maxDuration of 90s", adjustment.Reason)
+}
+
+func TestModule_ExecuteClipBounds(t *testing.T) {
+	execCommand = fakeExecCommand
+	fakeVideoDuration = "75.5"
+	defer func() {
+		execCommand = exec.CommandContext
+		fakeVideoDuration = "3600.000000"
+	}()
+
+	dir := t.TempDir()
+	video := filepath.Join(dir, "video.mp4")
+	require.NoError(t, os.WriteFile(video, []byte("video"), 0644))
+	shorts := filepath.Join(dir, "shorts.yaml")
+	require.NoError(t, os.WriteFile(shorts, []byte(`shorts:
+  - title: Fits
+    startTime: "00:00:10"
+    endTime: "00:00:20"
+  - title: Overruns
+    startTime: "00:01:00"
+    endTime: "00:01:30"
+  - title: Invented
+    startTime: "00:05:00"
+    endTime: "00:05:30"
+`), 0644))
+
+	result, err := New().Execute(context.Background(), map[string]interface{}{
+		"input":     shorts,
+		"output":    dir,
+		"videoFile": video,
+		"quietFlag": true,
+	})
+	require.NoError(t, err)
+
+	// The clamped clip keeps the file name of its suggested times
+	assert.Len(t, result.Outputs, 2)
+	assert.Contains(t, result.Outputs, "000100-000130.mp4")
+	assert.Equal(t, 2, result.Statistics["clips_count"])
+	adjustments := result.Statistics["adjustments"].([]ClipAdjustment)
+	require.Len(t, adjustments, 2)
+	assert.Equal(t, "clamped", adjustments[0].Action)
+	assert.Equal(t, "00:01:15", adjustments[0].NewEndTime)
+	assert.Equal(t, "rejected", adjustments[1].Action)
+	assert.Equal(t, "Invented", adjustments[1].Title)
+}
@@ -0,0 +1,245 @@
+package extractshorts
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+// Effects are optional stylistic effects of full renders. Sentence starts and silent gaps are read
+// from the cues of subtitleFile, an SRT file of the source video.
+type Effects struct {
+	PunchIn   float64 `json:"punchIn"`              // Zoom factor toggled at every sentence start, e.g. 1.15; needs subtitleFile (default: off)
+	SpeedRamp float64 `json:"speedRamp"`            // Playback speed of silent gaps between cues, up to 4, e.g. 2; needs subtitleFile (default: off)
+	MinGap    float64 `json:"minGap" default:"0.8"` // Shortest silent gap in seconds that is sped up (default: 0.8)
+	Crossfade float64 `json:"crossfade"`            // Seconds of crossfade between segments and of fade from and to black at the clip edges (default: off)
+}
+
+// enabled reports whether any effect is on
+func (e Effects) enabled() bool {
+	return e.PunchIn > 0 || e.SpeedRamp > 0 || e.Crossfade > 0
+}
+
+// needsCues reports whether the effects are placed using the subtitle cues
+func (e Effects) needsCues() bool {
+	return e.PunchIn > 0 || e.SpeedRamp > 0
+}
+
+// validate checks the ranges of the effect settings
+func (e Effects) validate(subtitleFile string) error {
+	if e.PunchIn != 0 && (e.PunchIn <= 1 || e.PunchIn > 2) {
+		return fmt.Errorf("effects.punchIn must be between 1 and 2, got %v", e.PunchIn)
+	}
+	if e.SpeedRamp != 0 && (e.SpeedRamp <= 1 || e.SpeedRamp > 4) {
+		return fmt.Errorf("effects.speedRamp must be between 1 and 4, got %v", e.SpeedRamp)
+	}
+	if e.MinGap < 0 || e.Crossfade < 0 {
+		return fmt.Errorf("effects.minGap and effects.crossfade must not be negative")
+	}
+	if e.needsCues() {
+		if !strings.EqualFold(filepath.Ext(subtitleFile), ".srt") {
+			return fmt.Errorf("effects.punchIn and effects.speedRamp need an .srt subtitleFile")
+		}
+		if _, err := os.Stat(subtitleFile); err != nil {
+			return fmt.Errorf("subtitle file does not exist: %s", subtitleFile)
+		}
+	}
+	return nil
+}
+
+// segment is a stretch of a clip rendered at one speed and zoom. Times are relative to the clip start.
+type segment struct {
+	start, end time.Duration
+	speed      float64
+	zoom       float64
+}
+
+// duration returns the length of the segment once sped up
+func (s segment) duration() float64 {
+	return (s.end - s.start).Seconds() / s.speed
+}
+
+// planSegments splits a clip of the given duration at its sentence starts and silent gaps. cues
+// are relative to the clip start; without cues the clip is one segment.
+func planSegments(cues []utils.SubtitleCue, duration time.Duration, e Effects) []segment {
+	minGap := time.Duration(e.MinGap * float64(time.Second))
+	zoom := 1.0
+	var segments []segment
+	add := func(s segment) {
+		if s.end <= s.start {
+			return
+		}
+		if n := len(segments); n > 0 && segments[n-1].speed == s.speed && segments[n-1].zoom == s.zoom {
+			segments[n-1].end = s.end
+			return
+		}
+		segments = append(segments, s)
+	}
+
+	cursor := time.Duration(0)
+	sentenceEnded := true
+	for _, cue := range cues {
+		start, end := max(cue.Start, cursor), min(cue.End, duration)
+		if end <= start {
+			continue
+		}
+
+		// Silent gaps are sped up; shorter ones stay with the sentence that follows
+		if e.SpeedRamp > 0 && start-cursor >= minGap {
+			add(segment{start: cursor, end: start, speed: e.SpeedRamp, zoom: 1})
+			cursor = start
+		}
+
+		// Each sentence toggles the punch-in, like a cut to a closer camera
+		if e.PunchIn > 0 && sentenceEnded {
+			if zoom == 1 && len(segments) > 0 {
+				zoom = e.PunchIn
+			} else {
+				zoom = 1
+			}
+		}
+		add(segment{start: cursor, end: end, speed: 1, zoom: zoom})
+		cursor = end
+		text := strings.TrimSpace(cue.Text)
+		sentenceEnded = strings.HasSuffix(text, ".") || strings.HasSuffix(text, "!") || strings.HasSuffix(text, "?")
+	}
+
+	if e.SpeedRamp > 0 && duration-cursor >= minGap {
+		add(segment{start: cursor, end: duration, speed: e.SpeedRamp, zoom: 1})
+	} else if n := len(segments); n > 0 {
+		segments[n-1].end = duration
+	} else {
+		add(segment{start: 0, end: duration, speed: 1, zoom: 1})
+	}
+	return segments
+}
+
+// effectsFilter builds the filter_complex of a clip: each segment is trimmed, sped up and zoomed,
+// then the segments are joined, crossfading when configured. The graph ends in [v] and [a].
+// width and height are the frame size of the source, needed only for punch-ins.
+func effectsFilter(segments []segment, e Effects, width, height int) string {
+	var graph []string
+	for i, s := range segments {
+		video := []string{
+			fmt.Sprintf("trim=start=%s:end=%s", seconds(s.start), seconds(s.end)),
+			fmt.Sprintf("setpts=(PTS-STARTPTS)/%s", strconv.FormatFloat(s.speed, 'f', -1, 64)),
+		}
+		if s.zoom > 1 {
+			video = append(video,
+				fmt.Sprintf("scale=%d:%d", even(float64(width)*s.zoom), even(float64(height)*s.zoom)),
+				fmt.Sprintf("crop=%d:%d", width, height))
+		}
+		video = append(video, "setsar=1")
+		audio := append([]string{
+			fmt.Sprintf("atrim=start=%s:end=%s", seconds(s.start), seconds(s.end)),
+			"asetpts=PTS-STARTPTS",
+		}, atempo(s.speed)...)
+
+		graph = append(graph,
+			fmt.Sprintf("[0:v]%s[v%d]", strings.Join(video, ","), i),
+			fmt.Sprintf("[0:a]%s[a%d]", strings.Join(audio, ","), i))
+	}
+
+	// Join the segments; a crossfade overlaps each pair, at most by half the shorter one
+	video, audio := "[v0]", "[a0]"
+	total := segments[0].duration()
+	if e.Crossfade > 0 {
+		for i := 1; i < len(segments); i++ {
+			d := min(e.Crossfade, segments[i-1].duration()/2, segments[i].duration()/2)
+			graph = append(graph,
+				fmt.Sprintf("%s[v%d]xfade=transition=fade:duration=%.3f:offset=%.3f[xv%d]", video, i, d, total-d, i),
+				fmt.Sprintf("%s[a%d]acrossfade=d=%.3f[xa%d]", audio, i, d, i))
+			video, audio = fmt.Sprintf("[xv%d]", i), fmt.Sprintf("[xa%d]", i)
+			total += segments[i].duration() - d
+		}
+	} else if len(segments) > 1 {
+		var inputs strings.Builder
+		for i := range segments {
+			fmt.Fprintf(&inputs, "[v%d][a%d]", i, i)
+			if i > 0 {
+				total += segments[i].duration()
+			}
+		}
+		graph = append(graph, fmt.Sprintf("%sconcat=n=%d:v=1:a=1[cv][ca]", inputs.String(), len(segments)))
+		video, audio = "[cv]", "[ca]"
+	}
+
+	// Fade the clip in from and out to black
+	if e.Crossfade > 0 {
+		d := min(e.Crossfade, total/2)
+		graph = append(graph,
+			fmt.Sprintf("%sfade=t=in:st=0:d=%.3f,fade=t=out:st=%.3f:d=%.3f[v]", video, d, total-d, d),
+			fmt.Sprintf("%safade=t=in:st=0:d=%.3f,afade=t=out:st=%.3f:d=%.3f[a]", audio, d, total-d, d))
+	} else {
+		graph = append(graph, video+"null[v]", audio+"anull[a]")
+	}
+	return strings.Join(graph, ";")
+}
+
+// clipCues returns the cues of the subtitle file between start and end, relative to start
+func clipCues(subtitleFile string, start, end time.Duration) ([]utils.SubtitleCue, error) {
+	data, err := os.ReadFile(subtitleFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read subtitle file: %w", err)
+	}
+	cues, err := utils.ParseSRT(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse subtitle file: %w", err)
+	}
+
+	var clip []utils.SubtitleCue
+	for _, cue := range cues {
+		if cue.End <= start || cue.Start >= end {
+			continue
+		}
+		cue.Start = max(cue.Start-start, 0)
+		cue.End -= start
+		clip = append(clip, cue)
+	}
+	return clip, nil
+}
+
+// probeFrameSize reads the frame size of a video with ffprobe
+func probeFrameSize(ctx context.Context, path string) (int, int, error) {
+	out, err := utils.OutputWatched(ctx, execCommand(ctx, "ffprobe",
+		"-v", "error", "-select_streams", "v:0", "-show_entries", "stream=width,height", "-of", "csv=p=0:s=x", path))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to probe video size: %w", err)
+	}
+	w, h, _ := strings.Cut(strings.TrimSpace(string(out)), "x")
+	width, werr := strconv.Atoi(w)
+	height, herr := strconv.Atoi(h)
+	if werr != nil || herr != nil || width <= 0 || height <= 0 {
+		return 0, 0, fmt.Errorf("unexpected video size %q", strings.TrimSpace(string(out)))
+	}
+	return width, height, nil
+}
+
+// atempo returns the audio filters for a speed; each atempo filter handles up to 2x
+func atempo(speed float64) []string {
+	var filters []string
+	for speed > 2 {
+		filters = append(filters, "atempo=2")
+		speed /= 2
+	}
+	if speed != 1 {
+		filters = append(filters, "atempo="+strconv.FormatFloat(speed, 'f', -1, 64))
+	}
+	return filters
+}
+
+// seconds formats a duration for a filter argument
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
+
+// even rounds a pixel size to the nearest even number, as required by yuv420p
+func even(size float64) int {
+	return int(size/2+0.5) * 2
+}
@@ -0,0 +1,119 @@
+package extractshorts
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanSegments(t *testing.T) {
+	s := time.Second
+	cues := []utils.SubtitleCue{
+		{Start: 0, End: 2 * s, Text: "First sentence."},
+		{Start: 2 * s, End: 4 * s, Text: "Second one starts"},
+		{Start: 4 * s, End: 5 * s, Text: "and ends here."},
+		{Start: 7 * s, End: 9 * s, Text: "Third after a pause."},
+	}
+
+	tests := []struct {
+		name    string
+		effects Effects
+		want    []segment
+	}{
+		{
+			name:    "no effects keeps one segment",
+			effects: Effects{Crossfade: 0.2},
+			want:    []segment{{0, 10 * s, 1, 1}},
+		},
+		{
+			name:    "punch-in toggles at sentence starts",
+			effects: Effects{PunchIn: 1.2},
+			want:    []segment{{0, 2 * s, 1, 1}, {2 * s, 5 * s, 1, 1.2}, {5 * s, 10 * s, 1, 1}},
+		},
+		{
+			name:    "speed ramp of silent gaps",
+			effects: Effects{SpeedRamp: 2, MinGap: 1},
+			want:    []segment{{0, 5 * s, 1, 1}, {5 * s, 7 * s, 2, 1}, {7 * s, 9 * s, 1, 1}, {9 * s, 10 * s, 2, 1}},
+		},
+		{
+			name:    "gaps shorter than minGap stay",
+			effects: Effects{SpeedRamp: 2, MinGap: 3},
+			want:    []segment{{0, 10 * s, 1, 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var clipCues []utils.SubtitleCue
+			if tt.effects.needsCues() {
+				clipCues = cues
+			}
+			assert.Equal(t, tt.want, planSegments(clipCues, 10*s, tt.effects))
+		})
+	}
+}
+
+func TestEffectsFilter(t *testing.T) {
+	s := time.Second
+	segments := []segment{{0, 4 * s, 1, 1}, {4 * s, 8 * s, 2, 1.25}}
+
+	graph := effectsFilter(segments, Effects{}, 1920, 1080)
+	assert.Contains(t, graph, "[0:v]trim=start=0.000:end=4.000,setpts=(PTS-STARTPTS)/1,setsar=1[v0]")
+	assert.Contains(t, graph, "[0:v]trim=start=4.000:end=8.000,setpts=(PTS-STARTPTS)/2,scale=2400:1350,crop=1920:1080,setsar=1[v1]")
+	assert.Contains(t, graph, "[0:a]atrim=start=4.000:end=8.000,asetpts=PTS-STARTPTS,atempo=2[a1]")
+	assert.Contains(t, graph, "[v0][a0][v1][a1]concat=n=2:v=1:a=1[cv][ca]")
+	assert.Contains(t, graph, "[cv]null[v];[ca]anull[a]")
+
+	// The sped-up segment lasts 2s, so the crossfade is capped at 1s and the clip at 5s
+	graph = effectsFilter(segments, Effects{Crossfade: 1.5}, 1920, 1080)
+	assert.Contains(t, graph, "[v0][v1]xfade=transition=fade:duration=1.000:offset=3.000[xv1]")
+	assert.Contains(t, graph, "[a0][a1]acrossfade=d=1.000[xa1]")
+	assert.Contains(t, graph, "[xv1]fade=t=in:st=0:d=1.500,fade=t=out:st=3.500:d=1.500[v]")
+	assert.NotContains(t, graph, "concat")
+
+	assert.Equal(t, []string{"atempo=2", "atempo=1.5"}, atempo(3))
+	assert.Empty(t, atempo(1))
+}
+
+func TestEffectsValidate(t *testing.T) {
+	srt := filepath.Join(t.TempDir(), "video.srt")
+	require.NoError(t, os.WriteFile(srt, []byte("1\n00:00:00,000 --> 00:00:01,000\nHi.\n"), 0644))
+
+	assert.NoError(t, Effects{PunchIn: 1.15, SpeedRamp: 2}.validate(srt))
+	assert.NoError(t, Effects{Crossfade: 0.3}.validate(""), "crossfades need no subtitles")
+	assert.ErrorContains(t, Effects{PunchIn: 3}.validate(srt), "punchIn must be between 1 and 2")
+	assert.ErrorContains(t, Effects{SpeedRamp: 8}.validate(srt), "speedRamp must be between 1 and 4")
+	assert.ErrorContains(t, Effects{SpeedRamp: 2}.validate(""), "need an .srt subtitleFile")
+}
+
+func TestModule_ExecuteEffects(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() {
+		execCommand = exec.CommandContext
+	}()
+
+	dir := t.TempDir()
+	video := filepath.Join(dir, "video.mp4")
+	require.NoError(t, os.WriteFile(video, []byte("video"), 0644))
+	srt := filepath.Join(dir, "video.srt")
+	require.NoError(t, os.WriteFile(srt, []byte("1\n00:00:10,000 --> 00:00:12,000\nHello.\n\n2\n00:00:12,000 --> 00:00:15,000\nWorld.\n"), 0644))
+	shorts := filepath.Join(dir, "shorts.yaml")
+	require.NoError(t, os.WriteFile(shorts, []byte("shorts:\n  - title: Clip\n    startTime: \"00:00:10\"\n    endTime: \"00:00:20\"\n"), 0644))
+
+	result, err := New().Execute(context.Background(), map[string]interface{}{
+		"input":        shorts,
+		"output":       dir,
+		"videoFile":    video,
+		"subtitleFile": srt,
+		"quietFlag":    true,
+		"effects":      map[string]interface{}{"punchIn": 1.2, "speedRamp": 2, "crossfade": 0.2},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "000010-000020.mp4"), result.Outputs["000010-000020.mp4"])
+}
@@ -5,45 +5,54 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	modules "github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
 	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
-	"gopkg.in/yaml.v3"
 )
 
 // execCommand allows us to mock exec.Command in tests
-var execCommand = exec.CommandContext
+var execCommand = utils.CommandContext
 
 // Module implements short video extraction functionality
 type Module struct{}
 
 // Params contains the parameters for short video extraction
 type Params struct {
-	Input        string `json:"input"`        // Path to shorts_suggestions.yaml file
-	Output       string `json:"output"`       // Path to output directory
-	VideoFile    string `json:"videoFile"`    // Path to the source video file
-	FFmpegParams string `json:"ffmpegParams"` // Additional parameters for FFmpeg
-	QuietFlag    bool   `json:"quietFlag"`    // Suppress ffmpeg output (default: true)
+	Input          string  `json:"input"`                         // Path to shorts_suggestions.yaml file
+	Output         string  `json:"output"`                        // Path to output directory
+	VideoFile      string  `json:"videoFile"`                     // Path to the source video file
+	FFmpegParams   string  `json:"ffmpegParams"`                  // Additional parameters for FFmpeg (ignored in preview mode)
+	QuietFlag      bool    `json:"quietFlag" default:"true"`      // Suppress ffmpeg output (default: true)
+	Mode           string  `json:"mode" default:"full"`           // Render mode: full or preview (default: "full")
+	Platform       string  `json:"platform" default:"youtube"`    // Target platform, which sets the safe-area guides of previews and the longest clip: youtube, tiktok, instagram (default: "youtube")
+	MaxDuration    float64 `json:"maxDuration"`                   // Longest clip in seconds, at most the platform's limit, e.g. 60 for YouTube channels without longer Shorts (default: the platform's limit)
+	DurationPolicy string  `json:"durationPolicy" default:"trim"` // Clips over the longest length: trim, split into equal parts or drop them (default: "trim")
+	PreviewHeight  int     `json:"previewHeight" default:"640"`   // Height of the 9:16 preview in pixels (default: 640)
+	Watermark      string  `json:"watermark" default:"PREVIEW"`   // Text burned across previews (default: "PREVIEW")
+	FontFile       string  `json:"fontFile"`                      // Font for the preview text (default: fontconfig's default font)
+	Filtergraph    string  `json:"filtergraph"`                   // Custom video filtergraph template for full renders, see utils.RenderFiltergraph
+	SubtitleFile   string  `json:"subtitleFile"`                  // Subtitle file available to the filtergraph as {subtitles}
+	FrameRate      float64 `json:"frameRate"`                     // Frame rate of the source video; snaps cuts to frames and accepts SMPTE timecode cut points (default: off)
+	Effects        Effects `json:"effects"`                       // Punch-in zooms, speed ramps and crossfades of full renders (default: off)
+	Overrun        string  `json:"overrun" default:"clamp"`       // Clips ending after the source video: clamp them to its end or reject them (default: "clamp")
+	Concurrency    int     `json:"concurrency"`                   // Clips rendered at once (default: half the CPUs up to 8, at most 3 with a GPU encoder in ffmpegParams)
+	Theme          string  `json:"theme"`                         // Theme file (.ass or .yaml) whose subtitle style is {forcestyle} and whose font is the preview font
+	FailFast       bool    `json:"failFast"`                      // Fail the step at the first clip that fails instead of rendering the others (default: false)
+	SyncCheck      string  `json:"syncCheck" default:"warn"`      // Check that each clip's audio starts at its cut point: off, warn or fail the clip (default: "warn")
+	MaxDrift       float64 `json:"maxDrift" default:"0.1"`        // Largest offset in seconds between a clip's audio and the source before it is reported (default: 0.1)
+	UseProxy       bool    `json:"useProxy" default:"true"`       // Render previews from the make_proxy copy of the video when there is one (default: true)
 }
 
 // ShortsData represents the structure of the shorts_suggestions.yaml file
-type ShortsData struct {
-	SourceVideo string      `yaml:"sourceVideo"`
-	Shorts      []ShortClip `yaml:"shorts"`
-}
+type ShortsData = utils.ShortsData
 
 // ShortClip represents a single short video clip suggestion
-type ShortClip struct {
-	Title       string `yaml:"title"`
-	StartTime   string `yaml:"startTime"`
-	EndTime     string `yaml:"endTime"`
-	Description string `yaml:"description"`
-	Tags        string `yaml:"tags"`
-}
+type ShortClip = utils.ShortClip
 
 // New creates a new extract shorts module
 func New() modules.Module {
@@ -55,6 +64,11 @@ func (m *Module) Name() string {
 	return "extract_shorts"
 }
 
+// ParamsTemplate returns the module's parameter struct, used to validate and document workflows
+func (m *Module) ParamsTemplate() interface{} {
+	return Params{}
+}
+
 // Validate checks if the parameters are valid
 func (m *Module) Validate(params map[string]interface{}) error {
 	var p Params
@@ -73,21 +87,128 @@ func (m *Module) Validate(params map[string]interface{}) error {
 	}
 
 	// Validate video file
-	if err := utils.ValidateVideoFile(p.VideoFile); err != nil {
+	if err := utils.ValidateVideoFile(p.VideoFile, p.Output); err != nil {
 		return err
 	}
 
+	// Validate the platform, which sets the safe area and the longest clip
+	if p.Platform == "" {
+		p.Platform = "youtube"
+	}
+	if _, ok := utils.SafeAreas[p.Platform]; !ok {
+		return fmt.Errorf("unsupported platform %q (supported: %s)", p.Platform, strings.Join(utils.SafeAreaPlatforms(), ", "))
+	}
+	if p.MaxDuration < 0 {
+		return fmt.Errorf("maxDuration must not be negative")
+	}
+	if limit := utils.MaxClipDuration(p.Platform); limit > 0 && time.Duration(p.MaxDuration*float64(time.Second)) > limit {
+		return fmt.Errorf("maxDuration (%gs) is over the %s limit of %s", p.MaxDuration, p.Platform, formatLength(limit))
+	}
+	switch p.DurationPolicy {
+	case "", DurationTrim, DurationSplit, DurationDrop:
+	default:
+		return fmt.Errorf("unsupported durationPolicy %q (supported: %s, %s, %s)", p.DurationPolicy, DurationTrim, DurationSplit, DurationDrop)
+	}
+
+	// Validate render mode
+	switch p.Mode {
+	case "", ModeFull:
+	case ModePreview:
+		if p.PreviewHeight < 0 {
+			return fmt.Errorf("previewHeight must not be negative")
+		}
+	default:
+		return fmt.Errorf("unsupported mode %q (supported: %s, %s)", p.Mode, ModeFull, ModePreview)
+	}
+
+	// Validate the handling of clips past the end of the video
+	if p.Overrun != "" && p.Overrun != OverrunClamp && p.Overrun != OverrunReject {
+		return fmt.Errorf("unsupported overrun %q (supported: %s, %s)", p.Overrun, OverrunClamp, OverrunReject)
+	}
+
+	if p.Concurrency < 0 {
+		return fmt.Errorf("concurrency must not be negative")
+	}
+
+	// Validate the audio sync check
+	switch p.SyncCheck {
+	case "", SyncOff, SyncWarn, SyncFail:
+	default:
+		return fmt.Errorf("unsupported syncCheck %q (supported: %s, %s, %s)", p.SyncCheck, SyncOff, SyncWarn, SyncFail)
+	}
+	if p.MaxDrift < 0 {
+		return fmt.Errorf("maxDrift must not be negative")
+	}
+
+	// Validate the theme
+	if p.Theme != "" {
+		if _, err := utils.LoadTheme(p.Theme); err != nil {
+			return err
+		}
+	}
+
+	// Validate the frame rate used for frame-accurate cuts
+	if p.FrameRate != 0 {
+		if _, err := utils.NewTimebase(p.FrameRate); err != nil {
+			return err
+		}
+	}
+
+	// Validate the filtergraph template against a sample clip
+	if p.Filtergraph != "" {
+		if p.SubtitleFile != "" {
+			if _, err := os.Stat(p.SubtitleFile); err != nil {
+				return fmt.Errorf("subtitle file does not exist: %s", p.SubtitleFile)
+			}
+		}
+		vars, err := filtergraphVars(ShortClip{Title: "title", StartTime: "00:00:00", EndTime: "00:00:01"}, p)
+		if err != nil {
+			return err
+		}
+		if _, err := utils.RenderFiltergraph(p.Filtergraph, vars); err != nil {
+			return err
+		}
+	}
+
+	// Validate the effects, which replace the filtergraph
+	if p.Effects.enabled() {
+		if p.Filtergraph != "" {
+			utils.LogWarning("filtergraph is ignored when effects are set")
+		}
+		if err := p.Effects.validate(p.SubtitleFile); err != nil {
+			return err
+		}
+	}
+
 	// Validate FFmpeg dependency
 	if err := utils.ValidateRequiredDependency("ffmpeg"); err != nil {
 		return err
 	}
 
-	// Validate YAML file content
+	// Validate YAML file content, once the earlier step producing it has written it
 	resolvedInput := utils.ResolveOutputPath(p.Input, p.Output)
-	if _, err := m.readShortsFile(resolvedInput); err != nil {
+	if _, err := os.Stat(resolvedInput); os.IsNotExist(err) && utils.ProducedByWorkflow(p.Input, p.Output) {
+		return nil
+	}
+	shortsData, err := m.readShortsFile(resolvedInput)
+	if err != nil {
 		return fmt.Errorf("invalid shorts file: %w", err)
 	}
 
+	// Point out the clips the platform would not take, and what will be done with them
+	if p.DurationPolicy == "" {
+		p.DurationPolicy = DurationTrim
+	}
+	for _, short := range shortsData.Shorts {
+		_, adjustment, err := checkClipDuration(short, p, 0)
+		if err != nil {
+			return err
+		}
+		if adjustment != nil {
+			utils.LogWarning("Clip %q (%s-%s) %s and will be %s", short.Title, short.StartTime, short.EndTime, adjustment.Reason, adjustment.Action)
+		}
+	}
+
 	return nil
 }
 
@@ -98,8 +219,56 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 		return modules.ModuleResult{}, err
 	}
 
+	// Set default values
+	if p.Mode == "" {
+		p.Mode = ModeFull
+	}
+	if p.Platform == "" {
+		p.Platform = "youtube"
+	}
+	if p.PreviewHeight == 0 {
+		p.PreviewHeight = 640
+	}
+	if _, ok := params["watermark"]; !ok {
+		p.Watermark = "PREVIEW"
+	}
+	if p.Mode == ModePreview && p.Filtergraph != "" {
+		utils.LogWarningContext(ctx, "filtergraph is ignored in preview mode")
+	}
+	if p.Mode == ModePreview && p.Effects.enabled() {
+		utils.LogWarningContext(ctx, "effects are ignored in preview mode")
+	}
+	if p.Effects.MinGap == 0 {
+		p.Effects.MinGap = 0.8
+	}
+	if p.Overrun == "" {
+		p.Overrun = OverrunClamp
+	}
+	if p.DurationPolicy == "" {
+		p.DurationPolicy = DurationTrim
+	}
+	if p.Concurrency == 0 {
+		p.Concurrency = defaultConcurrency(p)
+	}
+	if p.SyncCheck == "" {
+		p.SyncCheck = SyncWarn
+	}
+	if p.MaxDrift == 0 {
+		p.MaxDrift = 0.1
+	}
+	if _, ok := params["useProxy"]; !ok {
+		p.UseProxy = true
+	}
+	if p.Theme != "" && p.FontFile == "" {
+		theme, err := utils.LoadTheme(p.Theme)
+		if err != nil {
+			return modules.ModuleResult{}, err
+		}
+		p.FontFile = theme.FontFile
+	}
+
 	// Create output directory if it doesn't exist
-	if err := os.MkdirAll(p.Output, 0755); err != nil {
+	if err := utils.EnsureDir(p.Output); err != nil {
 		return modules.ModuleResult{}, fmt.Errorf("failed to create output directory: %w", err)
 	}
 
@@ -112,25 +281,128 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 		return modules.ModuleResult{}, err
 	}
 
+	// Previews are cut from the proxy, which keeps the timing of the source; full renders never are
+	if p.Mode == ModePreview && p.UseProxy {
+		if proxy, ok := utils.FindProxy(p.VideoFile, filepath.Dir(resolvedInput), p.Output); ok {
+			utils.LogInfoContext(ctx, "Rendering the previews from the proxy %s", proxy)
+			p.VideoFile = proxy
+		}
+	}
+
+	// Models sometimes suggest times past the end of the video, so clips are checked against its length
+	sourceDuration, err := probeVideoDuration(ctx, p.VideoFile)
+	if err != nil {
+		utils.LogWarningContext(ctx, "Clip times are not checked against the video length: %v", err)
+	}
+
 	// Track extracted clips
 	extractedClips := make(map[string]string)
 	clipStats := make([]map[string]interface{}, 0)
+	adjustments := make([]ClipAdjustment, 0)
 
-	// Process each short clip
+	// Check each short clip
+	var jobs []clipJob
 	for _, short := range shortsData.Shorts {
-		clipPath, err := m.extractShortClip(ctx, short, p)
+		var maxEnd time.Duration
+		if sourceDuration > 0 {
+			end, adjustment, keep, err := checkClipBounds(short, p, sourceDuration)
+			if err != nil {
+				return modules.ModuleResult{}, err
+			}
+			if adjustment != nil {
+				utils.LogWarningContext(ctx, "Clip %q (%s-%s) %s: %s", short.Title, short.StartTime, short.EndTime, adjustment.Action, adjustment.Reason)
+				adjustments = append(adjustments, *adjustment)
+			}
+			if !keep {
+				continue
+			}
+			maxEnd = end
+		}
+
+		// Clips over the platform's limit are trimmed, split or dropped
+		clipJobs, adjustment, err := checkClipDuration(short, p, maxEnd)
+		if err != nil {
+			return modules.ModuleResult{}, err
+		}
+		if adjustment != nil {
+			utils.LogWarningContext(ctx, "Clip %q (%s-%s) %s: %s", short.Title, short.StartTime, short.EndTime, adjustment.Action, adjustment.Reason)
+			adjustments = append(adjustments, *adjustment)
+		}
+		jobs = append(jobs, clipJobs...)
+	}
+
+	// When only the clips that failed before are retried, the others are kept from that attempt
+	var items []modules.ItemResult
+	if retry := modules.RetryItems(ctx); retry != nil {
+		var retried []clipJob
+		for _, job := range jobs {
+			name := clipFileName(job.short, p)
+			if retry[name] {
+				retried = append(retried, job)
+				continue
+			}
+			if path := filepath.Join(p.Output, name); fileExists(path) {
+				extractedClips[name] = path
+			}
+			items = append(items, modules.ItemResult{ID: name, Status: modules.ItemSkipped})
+		}
+		jobs = retried
+	}
+
+	// Renders are independent, so several run at once
+	utils.LogInfoContext(ctx, "Rendering %d clips, %d at a time", len(jobs), min(p.Concurrency, len(jobs)))
+	var driftMu sync.Mutex
+	drifts := make(map[string]*SyncResult)
+	render := func(ctx context.Context, job clipJob) (string, error) {
+		clipPath, err := m.extractShortClip(ctx, job.short, p, job.maxEnd)
 		if err != nil {
+			return "", err
+		}
+		drift, err := m.verifyClipSync(ctx, clipPath, job, p)
+		driftMu.Lock()
+		drifts[clipPath] = drift
+		driftMu.Unlock()
+		return clipPath, err
+	}
+	var clipPaths []string
+	clipErrs := make([]error, len(jobs))
+	if p.FailFast {
+		if clipPaths, err = renderClips(ctx, jobs, p.Concurrency, render); err != nil {
+			return modules.ModuleResult{}, err
+		}
+	} else {
+		// A failed clip does not stop the others; it is reported so a retry renders only it
+		clipPaths, clipErrs = renderAllClips(ctx, jobs, p.Concurrency, render)
+		if err := ctx.Err(); err != nil {
 			return modules.ModuleResult{}, err
 		}
+	}
 
+	failed := 0
+	for i, clipPath := range clipPaths {
+		short := jobs[i].short
+		name := clipFileName(short, p)
+		if clipErrs[i] != nil {
+			failed++
+			items = append(items, modules.ItemResult{ID: name, Status: modules.ItemFailed, Error: clipErrs[i].Error()})
+			continue
+		}
+		items = append(items, modules.ItemResult{ID: name, Status: modules.ItemSucceeded})
 		clipName := filepath.Base(clipPath)
 		extractedClips[clipName] = clipPath
-		clipStats = append(clipStats, map[string]interface{}{
+		stats := map[string]interface{}{
 			"title":       short.Title,
 			"start_time":  short.StartTime,
 			"end_time":    short.EndTime,
 			"output_file": clipPath,
-		})
+		}
+		if drift := drifts[clipPath]; drift != nil {
+			stats["audio_drift"] = drift.Drift.Seconds()
+		}
+		clipStats = append(clipStats, stats)
+	}
+	if failed > 0 && failed == len(jobs) {
+		return modules.ModuleResult{}, fmt.Errorf("all %d clips failed to render: %w", failed, firstError(clipErrs))
 	}
 
 	return modules.ModuleResult{
@@ -138,11 +410,16 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 		Statistics: map[string]interface{}{
 			"input_file":    resolvedInput,
 			"source_video":  p.VideoFile,
-			"clips_count":   len(shortsData.Shorts),
+			"clips_count":   len(clipStats),
 			"clips_details": clipStats,
+			"adjustments":   adjustments,
 			"ffmpeg_params": p.FFmpegParams,
+			"mode":          p.Mode,
+			"concurrency":   p.Concurrency,
 			"process_time":  time.Now().Format(time.RFC3339),
 		},
+		Stats: modules.Stats{Items: len(clipStats)},
+		Items: items,
 	}, nil
 }
 
@@ -179,6 +456,43 @@ func (m *Module) GetIO() modules.ModuleIO {
 				Description: "Suppress FFmpeg output",
 				Type:        string(modules.InputTypeData),
 			},
+			{
+				Name:        "filtergraph",
+				Description: "Custom filtergraph template",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "subtitleFile",
+				Description: "Subtitle file used as {subtitles} in the filtergraph",
+				Patterns:    []string{".srt", ".ass"},
+				Type:        string(modules.InputTypeFile),
+			},
+			{
+				Name:        "frameRate",
+				Description: "Frame rate of the source video for frame-accurate cuts and timecode cut points",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "theme",
+				Description: "Theme file with the font and colors of titles and subtitles",
+				Patterns:    []string{".ass", ".yaml"},
+				Type:        string(modules.InputTypeFile),
+			},
+			{
+				Name:        "durationPolicy",
+				Description: "What to do with clips longer than the platform allows: trim, split or drop",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "syncCheck",
+				Description: "Check that each clip's audio starts at its cut point: off, warn or fail",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "useProxy",
+				Description: "Render previews from the make_proxy copy of the video when there is one (default: true)",
+				Type:        string(modules.InputTypeData),
+			},
 		},
 		ProducedOutputs: []modules.ModuleOutput{
 			{
@@ -208,42 +522,105 @@ func (m *Module) readShortsFile(inputPath string) (*ShortsData, error) {
 	}
 
 	var shortsData ShortsData
-	if err := yaml.Unmarshal(data, &shortsData); err != nil {
+	if err := utils.DecodeShorts(data, &shortsData); err != nil {
 		return nil, fmt.Errorf("failed to parse shorts file: %w", err)
 	}
 
 	return &shortsData, nil
 }
 
-// extractShortClip extracts a single short video clip
-func (m *Module) extractShortClip(ctx context.Context, short ShortClip, p Params) (string, error) {
-	// Convert startTime and endTime to HHMMSS format for filename
-	startTimeHHMMSS := convertToHHMMSS(short.StartTime)
-	endTimeHHMMSS := convertToHHMMSS(short.EndTime)
+// fileExists reports whether path is a regular file
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// clipFileName names the clip of a short after its times: HHMMSS-HHMMSS.mp4, with a _preview
+// suffix so previews never replace full renders. The name identifies the clip across attempts.
+func clipFileName(short ShortClip, p Params) string {
+	startTimeHHMMSS := utils.CompactTimestamp(short.StartTime)
+	endTimeHHMMSS := utils.CompactTimestamp(short.EndTime)
+	if p.Mode == ModePreview {
+		return fmt.Sprintf("%s-%s_preview.mp4", startTimeHHMMSS, endTimeHHMMSS)
+	}
+	return fmt.Sprintf("%s-%s.mp4", startTimeHHMMSS, endTimeHHMMSS)
+}
 
-	// Create output filename: HHMMSS-HHMMSS.mp4
-	outputFilename := fmt.Sprintf("%s-%s.mp4", startTimeHHMMSS, endTimeHHMMSS)
-	outputPath := filepath.Join(p.Output, outputFilename)
+// extractShortClip extracts a single short video clip, cut at maxEnd when it is set. The file is
+// named after the suggested times, so later steps find clamped clips too.
+func (m *Module) extractShortClip(ctx context.Context, short ShortClip, p Params, maxEnd time.Duration) (string, error) {
+	outputPath := filepath.Join(p.Output, clipFileName(short, p))
 
-	// Build FFmpeg command
+	// Build FFmpeg command; with a frame rate the cut points are passed as exact frame times
+	start, end, err := clipRange(short, p)
+	if err != nil {
+		return "", fmt.Errorf("clip %q: %w", short.Title, err)
+	}
 	args := []string{
 		"-ss", short.StartTime,
 		"-to", short.EndTime,
 	}
+	if maxEnd > 0 && end > maxEnd {
+		end = maxEnd
+	}
+	if p.FrameRate != 0 || maxEnd > 0 {
+		args = []string{
+			"-ss", strconv.FormatFloat(start.Seconds(), 'f', 6, 64),
+			"-to", strconv.FormatFloat(end.Seconds(), 'f', 6, 64),
+		}
+	}
+	// A retried clip replaces what an earlier attempt left: a partial render, or a clip that
+	// rendered but failed the sync check
+	args = append([]string{"-y"}, args...)
 
 	// Add quiet flags if enabled (default behavior)
 	if p.QuietFlag {
 		args = append(args, "-v", "error", "-stats")
 	}
 
-	args = append(args, "-i", p.VideoFile, "-c", "copy") // Copy without re-encoding for speed
+	if p.Mode == ModePreview {
+		args = append(args, "-i", p.VideoFile)
+		args = append(args, previewArgs(end-start, p)...)
+	} else if p.Effects.enabled() {
+		graph, err := m.clipEffects(ctx, start, end, p)
+		if err != nil {
+			return "", fmt.Errorf("clip %q: %w", short.Title, err)
+		}
+		args = append(args, "-i", p.VideoFile, "-filter_complex", graph, "-map", "[v]", "-map", "[a]")
+
+		// Effects re-encode the clip; ffmpegParams replaces the default codec settings
+		if p.FFmpegParams != "" {
+			args = append(args, strings.Fields(p.FFmpegParams)...)
+		} else {
+			args = append(args, "-c:v", "libx264", "-c:a", "aac", "-b:a", "128k", "-b:v", "2500k")
+		}
+	} else if p.Filtergraph != "" {
+		vars, err := filtergraphVars(short, p)
+		if err != nil {
+			return "", fmt.Errorf("clip %q: %w", short.Title, err)
+		}
+		filtergraph, err := utils.RenderFiltergraph(p.Filtergraph, vars)
+		if err != nil {
+			return "", fmt.Errorf("clip %q: %w", short.Title, err)
+		}
+		args = append(args, "-i", p.VideoFile, "-vf", filtergraph)
 
-	// Add any additional FFmpeg parameters
-	if p.FFmpegParams != "" {
-		args = append(args, strings.Fields(p.FFmpegParams)...)
+		// Filtering re-encodes the video; ffmpegParams replaces the default codec settings
+		if p.FFmpegParams != "" {
+			args = append(args, strings.Fields(p.FFmpegParams)...)
+		} else {
+			args = append(args, "-c:v", "libx264", "-c:a", "aac", "-b:a", "128k", "-b:v", "2500k")
+		}
 	} else {
-		// Default video codec settings if no custom parameters provided
-		args = append(args, "-c:v", "libx264", "-c:a", "aac", "-b:a", "128k", "-b:v", "2500k")
+		args = append(args, "-i", p.VideoFile, "-c", "copy") // Copy without re-encoding for speed
+
+		// Add any additional FFmpeg parameters
+		if p.FFmpegParams != "" {
+			args = append(args, strings.Fields(p.FFmpegParams)...)
+		} else {
+			// Default video codec settings if no custom parameters provided
+			args = append(args, "-c:v", "libx264", "-c:a", "aac", "-b:a", "128k", "-b:v", "2500k")
+		}
 	}
 
 	// Add output file
@@ -258,40 +635,107 @@ func (m *Module) extractShortClip(ctx context.Context, short ShortClip, p Params
 		cmd.Stdout = nil
 		cmd.Stderr = &stderr
 	} else {
-		cmd.Stdout = os.Stdout
+		cmd.Stdout = utils.LogWriter(ctx)
 		cmd.Stderr = os.Stderr
 	}
 
-	utils.LogInfo("Extracting clip: %s (%s to %s)", short.Title, short.StartTime, short.EndTime)
+	if p.Mode == ModePreview {
+		utils.LogInfoContext(ctx, "Rendering preview: %s (%s to %s)", short.Title, short.StartTime, short.EndTime)
+	} else {
+		utils.LogInfoContext(ctx, "Extracting clip: %s (%s to %s)", short.Title, short.StartTime, short.EndTime)
+	}
 
 	// Run the FFmpeg command
-	if err := cmd.Run(); err != nil {
+	if err := utils.RunWatched(ctx, cmd); err != nil {
 		if p.QuietFlag && stderr.Len() > 0 {
 			// Log the error output if we captured it
-			utils.LogError("FFmpeg error: %s", stderr.String())
+			utils.LogErrorContext(ctx, "FFmpeg error: %s", stderr.String())
 		}
 		return "", fmt.Errorf("ffmpeg command failed: %w", err)
 	}
 
-	utils.LogSuccess("Extracted: %s", outputFilename)
+	utils.LogSuccessContext(ctx, "Extracted: %s", filepath.Base(outputPath))
 	return outputPath, nil
 }
 
-// convertToHHMMSS converts a timestamp to HHMMSS format
-func convertToHHMMSS(timestamp string) string {
-	// Remove any non-numeric characters
-	digits := strings.Map(func(r rune) rune {
-		if r >= '0' && r <= '9' {
-			return r
+// clipEffects builds the effects filter of the clip between start and end of the source video
+func (m *Module) clipEffects(ctx context.Context, start, end time.Duration, p Params) (string, error) {
+	var cues []utils.SubtitleCue
+	if p.Effects.needsCues() {
+		var err error
+		if cues, err = clipCues(p.SubtitleFile, start, end); err != nil {
+			return "", err
 		}
-		return -1
-	}, timestamp)
+	}
 
-	// Ensure we have at least 6 digits
-	if len(digits) < 6 {
-		digits = fmt.Sprintf("%06s", digits)
+	width, height := 0, 0
+	if p.Effects.PunchIn > 0 {
+		var err error
+		if width, height, err = probeFrameSize(ctx, p.VideoFile); err != nil {
+			return "", err
+		}
 	}
 
-	// Take the first 6 digits
-	return digits[:6]
+	segments := planSegments(cues, end-start, p.Effects)
+	utils.LogVerboseContext(ctx, "Rendering %d segments with effects", len(segments))
+	return effectsFilter(segments, p.Effects, width, height), nil
+}
+
+// filtergraphVars returns the placeholders of a clip for the filtergraph template. Clips are cut
+// with -ss before -i, so their timestamps start at zero; {start} and {end} are the clip's times in
+// the source video, e.g. to shift source subtitles: setpts=PTS+{start}/TB,subtitles='{subtitles}',setpts=PTS-STARTPTS
+func filtergraphVars(short ShortClip, p Params) (map[string]string, error) {
+	start, end, err := clipRange(short, p)
+	if err != nil {
+		return nil, err
+	}
+	vars := map[string]string{
+		"input":     p.VideoFile,
+		"title":     short.Title,
+		"subtitles": p.SubtitleFile,
+		"start":     strconv.FormatFloat(start.Seconds(), 'f', 3, 64),
+		"end":       strconv.FormatFloat(end.Seconds(), 'f', 3, 64),
+		"duration":  strconv.FormatFloat((end - start).Seconds(), 'f', 3, 64),
+	}
+	if p.Theme != "" {
+		theme, err := utils.LoadTheme(p.Theme)
+		if err != nil {
+			return nil, err
+		}
+		vars["forcestyle"] = theme.ForceStyle()
+	}
+	return vars, nil
+}
+
+// clipRange returns the start and end of a suggested clip in the source video. With a frame rate
+// the cut points may be SMPTE timecode and are snapped to frame boundaries.
+func clipRange(short ShortClip, p Params) (time.Duration, time.Duration, error) {
+	if p.FrameRate == 0 {
+		start, err := utils.ParseTimestamp(short.StartTime)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid startTime: %w", err)
+		}
+		duration, err := clipDuration(short)
+		if err != nil {
+			return 0, 0, err
+		}
+		return start, start + duration, nil
+	}
+
+	timebase, err := utils.NewTimebase(p.FrameRate)
+	if err != nil {
+		return 0, 0, err
+	}
+	in, err := timebase.ParseCutPoint(short.StartTime)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid startTime: %w", err)
+	}
+	out, err := timebase.ParseCutPoint(short.EndTime)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid endTime: %w", err)
+	}
+	if out <= in {
+		return 0, 0, fmt.Errorf("endTime %s must be after startTime %s", short.EndTime, short.StartTime)
+	}
+	return timebase.Duration(in), timebase.Duration(out), nil
 }
@@ -2,11 +2,16 @@ package extractshorts
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
+	modules "github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
 	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -32,12 +37,18 @@ func TestMain(m *testing.M) {
 	os.Exit(result)
 }
 
+// fakeVideoDuration is the source video length ffprobe reports in seconds
+var fakeVideoDuration = "3600.000000"
+
+// fakeFailingClip makes ffmpeg fail when rendering a clip whose path contains it
+var fakeFailingClip = ""
+
 // fakeExecCommand creates a mock command that does nothing
 func fakeExecCommand(ctx context.Context, command string, args ...string) *exec.Cmd {
 	cs := []string{"-test.run=TestHelperProcess", "--", command}
 	cs = append(cs, args...)
 	cmd := exec.Command(os.Args[0], cs...)
-	cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1"}
+	cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1", "FAKE_VIDEO_DURATION=" + fakeVideoDuration, "FAKE_FAILING_CLIP=" + fakeFailingClip}
 	return cmd
 }
 
@@ -51,6 +62,30 @@ func TestHelperProcess(t *testing.T) {
 	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
 		return
 	}
+	if clip := os.Getenv("FAKE_FAILING_CLIP"); clip != "" && strings.Contains(strings.Join(os.Args, " "), clip) {
+		fmt.Fprintln(os.Stderr, "Conversion failed!")
+		os.Exit(1)
+	}
+	// Like ffmpeg with stdin closed, an existing output is only replaced with -y
+	for i, arg := range os.Args {
+		if arg == "--" && i+1 < len(os.Args) && os.Args[i+1] == "ffmpeg" && !slices.Contains(os.Args[i:], "-y") {
+			output := os.Args[len(os.Args)-1]
+			if _, err := os.Stat(output); err == nil {
+				fmt.Fprintf(os.Stderr, "File '%s' already exists. Exiting.\n", output)
+				os.Exit(1)
+			}
+		}
+	}
+	// ffprobe reports a 1080p frame and the configured duration
+	for i, arg := range os.Args {
+		if arg == "--" && i+1 < len(os.Args) && os.Args[i+1] == "ffprobe" {
+			if strings.Contains(strings.Join(os.Args[i:], " "), "format=duration") {
+				fmt.Println(os.Getenv("FAKE_VIDEO_DURATION"))
+			} else {
+				fmt.Println("1920x1080")
+			}
+		}
+	}
 	os.Exit(0)
 }
 
@@ -65,9 +100,15 @@ func TestModule_GetIO(t *testing.T) {
 	assert.Equal(t, "videoFile", io.RequiredInputs[2].Name)
 
 	// Test optional inputs
-	assert.Len(t, io.OptionalInputs, 2)
+	assert.Len(t, io.OptionalInputs, 9)
 	assert.Equal(t, "ffmpegParams", io.OptionalInputs[0].Name)
 	assert.Equal(t, "quietFlag", io.OptionalInputs[1].Name)
+	assert.Equal(t, "filtergraph", io.OptionalInputs[2].Name)
+	assert.Equal(t, "subtitleFile", io.OptionalInputs[3].Name)
+	assert.Equal(t, "frameRate", io.OptionalInputs[4].Name)
+	assert.Equal(t, "theme", io.OptionalInputs[5].Name)
+	assert.Equal(t, "durationPolicy", io.OptionalInputs[6].Name)
+	assert.Equal(t, "useProxy", io.OptionalInputs[8].Name)
 
 	// Test produced outputs
 	assert.Len(t, io.ProducedOutputs, 1)
@@ -144,6 +185,58 @@ shorts:
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid preview mode",
+			params: map[string]interface{}{
+				"input":     yamlPath,
+				"output":    tempDir,
+				"videoFile": videoPath,
+				"mode":      "preview",
+				"platform":  "tiktok",
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown mode",
+			params: map[string]interface{}{
+				"input":     yamlPath,
+				"output":    tempDir,
+				"videoFile": videoPath,
+				"mode":      "draft",
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown preview platform",
+			params: map[string]interface{}{
+				"input":     yamlPath,
+				"output":    tempDir,
+				"videoFile": videoPath,
+				"mode":      "preview",
+				"platform":  "myspace",
+			},
+			wantErr: true,
+		},
+		{
+			name: "maxDuration over the platform limit",
+			params: map[string]interface{}{
+				"input":       yamlPath,
+				"output":      tempDir,
+				"videoFile":   videoPath,
+				"maxDuration": 240,
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown duration policy",
+			params: map[string]interface{}{
+				"input":          yamlPath,
+				"output":         tempDir,
+				"videoFile":      videoPath,
+				"durationPolicy": "shrink",
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid yaml file",
 			params: map[string]interface{}{
@@ -245,6 +338,21 @@ shorts:
 			},
 			wantErr: false,
 		},
+		{
+			name: "render previews",
+			params: map[string]interface{}{
+				"input":     yamlPath,
+				"output":    tempDir,
+				"videoFile": videoPath,
+				"mode":      "preview",
+				"quietFlag": true,
+			},
+			expectedOutputs: []string{
+				filepath.Join(tempDir, "000010-000020_preview.mp4"),
+				filepath.Join(tempDir, "000100-000130_preview.mp4"),
+			},
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -258,6 +366,9 @@ shorts:
 			assert.NoError(t, err)
 			assert.NotEmpty(t, result.Outputs)
 			assert.Len(t, result.Outputs, len(tt.expectedOutputs))
+			for _, expected := range tt.expectedOutputs {
+				assert.Equal(t, expected, result.Outputs[filepath.Base(expected)])
+			}
 
 			// Check statistics
 			assert.NotNil(t, result.Statistics)
@@ -273,43 +384,133 @@ shorts:
 	}
 }
 
+func TestModule_ExecutePartialFailure(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() {
+		execCommand = exec.CommandContext
+		fakeFailingClip = ""
+	}()
+
+	tempDir := t.TempDir()
+	videoPath := filepath.Join(tempDir, "test.mp4")
+	require.NoError(t, os.WriteFile(videoPath, []byte("dummy video content"), 0644))
+	yamlPath := filepath.Join(tempDir, "shorts_suggestions.yaml")
+	require.NoError(t, os.WriteFile(yamlPath, []byte(`
+sourceVideo: test.mp4
+shorts:
+  - title: "First Clip"
+    startTime: "00:00:10"
+    endTime: "00:00:20"
+  - title: "Second Clip"
+    startTime: "00:01:00"
+    endTime: "00:01:30"
+`), 0644))
+	params := map[string]interface{}{
+		"input":     yamlPath,
+		"output":    tempDir,
+		"videoFile": videoPath,
+		"quietFlag": true,
+	}
+
+	// The second clip fails, the first is still rendered and returned
+	fakeFailingClip = "000100-000130"
+	result, err := New().Execute(context.Background(), params)
+	require.NoError(t, err)
+	assert.Contains(t, result.Outputs, "000010-000020.mp4")
+	assert.NotContains(t, result.Outputs, "000100-000130.mp4")
+	assert.Equal(t, []string{"000100-000130.mp4"}, result.FailedItems())
+
+	// A retry renders only the failed clip and keeps the one rendered before, replacing what
+	// the failed attempt left
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "000010-000020.mp4"), []byte("clip"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "000100-000130.mp4"), []byte("partial"), 0644))
+	fakeFailingClip = ""
+	ctx := modules.WithRetryItems(context.Background(), result.FailedItems())
+	result, err = New().Execute(ctx, params)
+	require.NoError(t, err)
+	assert.Len(t, result.Outputs, 2)
+	assert.Empty(t, result.FailedItems())
+	assert.Equal(t, modules.ItemSkipped, result.Items[0].Status)
+	assert.Equal(t, modules.ItemSucceeded, result.Items[1].Status)
+
+	// When every clip fails, the step fails
+	fakeFailingClip = "0-000"
+	_, err = New().Execute(context.Background(), params)
+	assert.Error(t, err)
+
+	// With failFast the first failure fails the step
+	fakeFailingClip = "000100-000130"
+	params["failFast"] = true
+	_, err = New().Execute(context.Background(), params)
+	assert.Error(t, err)
+}
+
 func TestModule_Name(t *testing.T) {
 	module := New()
 	assert.Equal(t, "extract_shorts", module.Name())
 }
 
-func TestConvertToHHMMSS(t *testing.T) {
-	tests := []struct {
-		name      string
-		timestamp string
-		expected  string
-	}{
-		{
-			name:      "standard format",
-			timestamp: "00:01:30",
-			expected:  "000130",
-		},
-		{
-			name:      "with milliseconds",
-			timestamp: "00:01:30.500",
-			expected:  "000130",
-		},
-		{
-			name:      "only numbers",
-			timestamp: "013000",
-			expected:  "013000",
-		},
-		{
-			name:      "short format",
-			timestamp: "1:30",
-			expected:  "000130",
-		},
-	}
+func TestPreviewFilter(t *testing.T) {
+	filter := previewFilter(42*time.Second, Params{
+		Platform:      "tiktok",
+		PreviewHeight: 640,
+		Watermark:     "DRAFT: v2",
+	})
+
+	// 9:16 frame letterboxed at 360x640
+	assert.Contains(t, filter, "scale=360:640:force_original_aspect_ratio=decrease,pad=360:640")
+	// TikTok hides the top 13% and bottom 25% of the frame
+	assert.Contains(t, filter, "drawbox=x=0:y=0:w=360:h=83:color=black@0.45:t=fill")
+	assert.Contains(t, filter, "drawbox=x=0:y=480:w=360:h=160:color=black@0.45:t=fill")
+	assert.Contains(t, filter, "drawbox=x=21:y=83:w=293:h=397:color=yellow@0.9:t=2")
+	assert.Contains(t, filter, `text='DRAFT\: v2'`)
+	assert.Contains(t, filter, `text='%{eif\:t\:d}s / 42s'`)
+	assert.NotContains(t, filter, "fontfile=")
+
+	noWatermark := previewFilter(10*time.Second, Params{Platform: "youtube", PreviewHeight: 640})
+	assert.NotContains(t, noWatermark, "white@0.35")
+}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := convertToHHMMSS(tt.timestamp)
-			assert.Equal(t, tt.expected, result)
-		})
-	}
+func TestFiltergraphVars(t *testing.T) {
+	short := ShortClip{Title: "It's 5:00", StartTime: "00:01:30", EndTime: "00:02:00"}
+	vars, err := filtergraphVars(short, Params{VideoFile: "video.mp4", SubtitleFile: "subs.srt"})
+	require.NoError(t, err)
+
+	filter, err := utils.RenderFiltergraph("setpts=PTS+{start}/TB,subtitles='{subtitles}',setpts=PTS-STARTPTS,drawtext=text='{title}':enable='lt(t,{duration})'", vars)
+	require.NoError(t, err)
+	assert.Equal(t, `setpts=PTS+90.000/TB,subtitles='subs.srt',setpts=PTS-STARTPTS,drawtext=text='It\'s 5\:00':enable='lt(t,30.000)'`, filter)
+
+	_, err = utils.RenderFiltergraph("subtitles='{subtitles}'", map[string]string{"title": "x", "subtitles": ""})
+	assert.ErrorContains(t, err, "{subtitles} is not available")
+
+	// A theme styles the burned subtitles
+	theme := filepath.Join(t.TempDir(), "brand.yaml")
+	require.NoError(t, os.WriteFile(theme, []byte("fontName: Inter\nfontSize: 80\n"), 0644))
+	vars, err = filtergraphVars(short, Params{VideoFile: "video.mp4", SubtitleFile: "subs.srt", Theme: theme})
+	require.NoError(t, err)
+	filter, err = utils.RenderFiltergraph("subtitles='{subtitles}':force_style='{forcestyle}'", vars)
+	require.NoError(t, err)
+	assert.Equal(t, "subtitles='subs.srt':force_style='FontName=Inter,FontSize=12'", filter)
+}
+
+func TestClipRange(t *testing.T) {
+	// Without a frame rate the timestamps are used as they are
+	start, end, err := clipRange(ShortClip{StartTime: "00:00:01.010", EndTime: "00:00:02"}, Params{})
+	require.NoError(t, err)
+	assert.Equal(t, 1010*time.Millisecond, start)
+	assert.Equal(t, 2*time.Second, end)
+
+	// At 25 fps timestamps snap to the nearest frame and timecode frames are 40ms apart
+	start, end, err = clipRange(ShortClip{StartTime: "00:00:01.010", EndTime: "00:00:02:12"}, Params{FrameRate: 25})
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, start)
+	assert.Equal(t, 2480*time.Millisecond, end)
+
+	// Drop-frame timecode at 29.97: 00:01:00;02 is frame 1800
+	start, _, err = clipRange(ShortClip{StartTime: "00:01:00;02", EndTime: "00:02:00;02"}, Params{FrameRate: 29.97})
+	require.NoError(t, err)
+	assert.Equal(t, 60060*time.Millisecond, start)
+
+	_, _, err = clipRange(ShortClip{StartTime: "00:00:01:30", EndTime: "00:00:02:00"}, Params{FrameRate: 25})
+	assert.ErrorContains(t, err, "frames must be below 25")
 }
@@ -0,0 +1,78 @@
+package extractshorts
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+// Render modes
+const (
+	ModeFull    = "full"    // Full-quality clips for publishing
+	ModePreview = "preview" // Low-res review copies with guides burned in
+)
+
+// previewArgs builds the ffmpeg output arguments of a watermarked low-res preview
+func previewArgs(duration time.Duration, p Params) []string {
+	return []string{
+		"-vf", previewFilter(duration, p),
+		"-c:v", "libx264", "-preset", "veryfast", "-crf", "32",
+		"-c:a", "aac", "-b:a", "64k",
+	}
+}
+
+// previewFilter letterboxes the clip into a 9:16 frame, shades the areas hidden by the
+// platform UI, outlines the safe area and burns in the watermark and an elapsed/total counter
+func previewFilter(duration time.Duration, p Params) string {
+	height := p.PreviewHeight
+	width := height * 9 / 16
+	width -= width % 2
+	area := utils.SafeAreas[p.Platform]
+
+	top := int(float64(height) * area.Top)
+	bottom := int(float64(height) * area.Bottom)
+	left := int(float64(width) * area.Left)
+	right := int(float64(width) * area.Right)
+
+	fontFile := ""
+	if p.FontFile != "" {
+		fontFile = "fontfile=" + utils.EscapeFilterText(p.FontFile) + ":"
+	}
+
+	filters := []string{
+		fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=decrease", width, height),
+		fmt.Sprintf("pad=%d:%d:(ow-iw)/2:(oh-ih)/2", width, height),
+		"setsar=1",
+		fmt.Sprintf("drawbox=x=0:y=0:w=%d:h=%d:color=black@0.45:t=fill", width, top),
+		fmt.Sprintf("drawbox=x=0:y=%d:w=%d:h=%d:color=black@0.45:t=fill", height-bottom, width, bottom),
+		fmt.Sprintf("drawbox=x=%d:y=%d:w=%d:h=%d:color=yellow@0.9:t=2", left, top, width-left-right, height-top-bottom),
+	}
+	if p.Watermark != "" {
+		filters = append(filters, fmt.Sprintf(
+			"drawtext=%stext='%s':fontsize=%d:fontcolor=white@0.35:x=(w-text_w)/2:y=(h-text_h)/2",
+			fontFile, utils.EscapeFilterText(p.Watermark), height/12))
+	}
+	filters = append(filters, fmt.Sprintf(
+		"drawtext=%stext='%%{eif\\:t\\:d}s / %ds':fontsize=%d:fontcolor=white:box=1:boxcolor=black@0.6:boxborderw=4:x=(w-text_w)/2:y=%d",
+		fontFile, int(duration.Round(time.Second).Seconds()), height/28, top+8))
+
+	return strings.Join(filters, ",")
+}
+
+// clipDuration returns the length of a suggested clip
+func clipDuration(short ShortClip) (time.Duration, error) {
+	start, err := utils.ParseTimestamp(short.StartTime)
+	if err != nil {
+		return 0, fmt.Errorf("invalid startTime: %w", err)
+	}
+	end, err := utils.ParseTimestamp(short.EndTime)
+	if err != nil {
+		return 0, fmt.Errorf("invalid endTime: %w", err)
+	}
+	if end <= start {
+		return 0, fmt.Errorf("endTime %s must be after startTime %s", short.EndTime, short.StartTime)
+	}
+	return end - start, nil
+}
@@ -0,0 +1,189 @@
+package extractshorts
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+// What to do after a clip is rendered to check that its audio starts where the cut was asked for
+const (
+	SyncOff  = "off"  // Do not check
+	SyncWarn = "warn" // Warn about clips whose audio drifts from the source
+	SyncFail = "fail" // Fail the clips whose audio drifts, so a retry renders them again
+)
+
+const (
+	syncSampleRate     = 8000            // Sample rate of the audio compared
+	syncFrameSamples   = 16              // Samples per envelope frame, 2ms at syncSampleRate
+	syncWindow         = 8 * time.Second // Audio of the clip compared with the source
+	syncSearch         = 1 * time.Second // Largest drift looked for on each side of the cut point
+	minSyncAudio       = 2 * time.Second // Shorter clips are not checked
+	minSyncCorrelation = 0.6             // Weaker matches are too uncertain to report a drift
+	syncFrame          = time.Second * syncFrameSamples / syncSampleRate
+)
+
+// SyncResult is the offset measured between the start of a clip's audio and the source at its cut point
+type SyncResult struct {
+	Drift       time.Duration // Positive when the clip's audio starts after the cut point, negative when before it
+	Correlation float64       // How closely the clip's audio matches the source at that offset, from 0 to 1
+}
+
+// verifyClipSync checks a rendered clip's audio against the source and warns about a drift over
+// maxDrift, or fails the clip when syncCheck is "fail". Clips with effects are not checked, as
+// speed ramps and crossfades change their audio on purpose.
+func (m *Module) verifyClipSync(ctx context.Context, clipPath string, job clipJob, p Params) (*SyncResult, error) {
+	if p.SyncCheck == SyncOff || (p.Mode == ModeFull && p.Effects.enabled()) {
+		return nil, nil
+	}
+	start, end, err := clipRange(job.short, p)
+	if err != nil {
+		return nil, nil
+	}
+	if job.maxEnd > 0 && end > job.maxEnd {
+		end = job.maxEnd
+	}
+
+	result, err := checkClipSync(ctx, clipPath, start, end, p)
+	if err != nil {
+		utils.LogWarningContext(ctx, "Audio sync of clip %q was not checked: %v", job.short.Title, err)
+		return nil, nil
+	}
+	if result == nil {
+		utils.LogDebugContext(ctx, "Audio sync of clip %q was not checked: too little distinct audio", job.short.Title)
+		return nil, nil
+	}
+	if math.Abs(result.Drift.Seconds()) <= p.MaxDrift {
+		return result, nil
+	}
+
+	direction := "after"
+	if result.Drift < 0 {
+		direction = "before"
+	}
+	message := fmt.Sprintf("audio of clip %q starts %.3fs %s its cut point %s; ffmpeg may have seeked to a keyframe",
+		job.short.Title, math.Abs(result.Drift.Seconds()), direction, formatCutTime(start))
+	if p.SyncCheck == SyncFail {
+		return result, fmt.Errorf("%s", message)
+	}
+	utils.LogWarningContext(ctx, "The %s", message)
+	return result, nil
+}
+
+// checkClipSync compares the audio at the start of a rendered clip with the source audio around
+// its cut point. ffmpeg seeks to keyframes when streams are copied, so a clip can start a little
+// before or after the time asked for. It returns nil when the clip has too little audio to check.
+func checkClipSync(ctx context.Context, clipPath string, start, end time.Duration, p Params) (*SyncResult, error) {
+	window := min(end-start, syncWindow)
+	if window < minSyncAudio {
+		return nil, nil
+	}
+	clip, err := decodeSyncAudio(ctx, clipPath, 0, window)
+	if err != nil {
+		return nil, err
+	}
+	lead := min(start, syncSearch)
+	source, err := decodeSyncAudio(ctx, p.VideoFile, start-lead, window+lead+syncSearch)
+	if err != nil {
+		return nil, err
+	}
+	result, ok := measureDrift(source, clip, lead)
+	if !ok {
+		return nil, nil
+	}
+	return &result, nil
+}
+
+// decodeSyncAudio decodes part of a file's audio as mono 16-bit samples
+func decodeSyncAudio(ctx context.Context, file string, start, duration time.Duration) ([]int16, error) {
+	cmd := execCommand(ctx, "ffmpeg",
+		"-v", "error",
+		"-ss", strconv.FormatFloat(start.Seconds(), 'f', 6, 64),
+		"-t", strconv.FormatFloat(duration.Seconds(), 'f', 6, 64),
+		"-i", file,
+		"-vn", "-ac", "1", "-ar", strconv.Itoa(syncSampleRate),
+		"-f", "s16le", "-",
+	)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := utils.RunWatched(ctx, cmd); err != nil {
+		if stderr.Len() > 0 {
+			utils.LogErrorContext(ctx, "FFmpeg error: %s", stderr.String())
+		}
+		return nil, fmt.Errorf("ffmpeg failed to decode the audio of %s: %w", file, err)
+	}
+
+	raw := stdout.Bytes()
+	samples := make([]int16, len(raw)/2)
+	if err := binary.Read(bytes.NewReader(raw[:len(samples)*2]), binary.LittleEndian, samples); err != nil {
+		return nil, fmt.Errorf("failed to decode audio samples: %w", err)
+	}
+	return samples, nil
+}
+
+// measureDrift finds where the clip's audio lies in the source audio, which starts lead before the
+// cut point. Loudness envelopes are compared rather than samples, which keeps the search cheap and
+// tolerates the small changes of re-encoding. It reports false when the clip is silent or matches
+// nothing in the source clearly enough.
+func measureDrift(source, clip []int16, lead time.Duration) (SyncResult, bool) {
+	src := syncEnvelope(source)
+	clp := syncEnvelope(clip)
+	if len(clp) < int(minSyncAudio/syncFrame) {
+		return SyncResult{}, false
+	}
+	leadFrames := int(lead / syncFrame)
+
+	best, bestLag := -1.0, 0
+	for offset := 0; offset+len(clp) <= len(src); offset++ {
+		if c := correlation(src[offset:offset+len(clp)], clp); c > best {
+			best, bestLag = c, offset-leadFrames
+		}
+	}
+	if best < minSyncCorrelation {
+		return SyncResult{}, false
+	}
+	return SyncResult{Drift: time.Duration(bestLag) * syncFrame, Correlation: best}, true
+}
+
+// syncEnvelope returns the loudness of each frame of the samples
+func syncEnvelope(samples []int16) []float64 {
+	envelope := make([]float64, 0, len(samples)/syncFrameSamples)
+	for offset := 0; offset+syncFrameSamples <= len(samples); offset += syncFrameSamples {
+		var sum float64
+		for _, s := range samples[offset : offset+syncFrameSamples] {
+			sum += math.Abs(float64(s))
+		}
+		envelope = append(envelope, sum/syncFrameSamples)
+	}
+	return envelope
+}
+
+// correlation returns the Pearson correlation of two series of the same length, or 0 when either is flat
+func correlation(a, b []float64) float64 {
+	var meanA, meanB float64
+	for i := range a {
+		meanA += a[i]
+		meanB += b[i]
+	}
+	meanA /= float64(len(a))
+	meanB /= float64(len(b))
+
+	var cov, varA, varB float64
+	for i := range a {
+		da, db := a[i]-meanA, b[i]-meanB
+		cov += da * db
+		varA += da * da
+		varB += db * db
+	}
+	if varA == 0 || varB == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(varA*varB)
+}
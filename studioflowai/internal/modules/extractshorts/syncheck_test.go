@@ -0,0 +1,50 @@
+package extractshorts
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// speech returns samples of bursts of noise of random loudness and length, like words and pauses
+func speech(duration time.Duration) []int16 {
+	rng := rand.New(rand.NewSource(1))
+	samples := make([]int16, int(duration.Seconds()*syncSampleRate))
+	for i := 0; i < len(samples); {
+		length := syncSampleRate/20 + rng.Intn(syncSampleRate/4)
+		level := 0.0
+		if rng.Intn(3) > 0 {
+			level = 2000 + rng.Float64()*12000
+		}
+		for j := i; j < i+length && j < len(samples); j++ {
+			samples[j] = int16((rng.Float64()*2 - 1) * level)
+		}
+		i += length
+	}
+	return samples
+}
+
+func TestMeasureDrift(t *testing.T) {
+	source := speech(14 * time.Second)
+	lead := time.Second
+	cut := int(lead.Seconds() * syncSampleRate)
+	window := 8 * syncSampleRate
+
+	for _, drift := range []time.Duration{0, 240 * time.Millisecond, -500 * time.Millisecond} {
+		// The clip starts drift after the cut point in the source
+		offset := cut + int(drift.Seconds()*syncSampleRate)
+		result, ok := measureDrift(source, source[offset:offset+window], lead)
+		require.True(t, ok, drift)
+		assert.InDelta(t, drift.Seconds(), result.Drift.Seconds(), syncFrame.Seconds(), drift)
+		assert.Greater(t, result.Correlation, 0.9)
+	}
+
+	// Silence and clips too short to check are not measured
+	_, ok := measureDrift(source, make([]int16, window), lead)
+	assert.False(t, ok)
+	_, ok = measureDrift(source, source[cut:cut+syncSampleRate], lead)
+	assert.False(t, ok)
+}
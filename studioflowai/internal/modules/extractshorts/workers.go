@@ -0,0 +1,119 @@
+package extractshorts
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// hardwareEncoders are substrings of ffmpeg encoder names that run on a GPU
+var hardwareEncoders = []string{"_nvenc", "_qsv", "_vaapi", "_videotoolbox", "_amf"}
+
+// maxHardwareSessions is the number of encodes consumer GPUs run at once
+const maxHardwareSessions = 3
+
+// clipJob is a clip to render, cut at maxEnd when it is set
+type clipJob struct {
+	short  ShortClip
+	maxEnd time.Duration
+}
+
+// defaultConcurrency returns how many clips to render at once. x264 uses several threads per
+// encode, so half the CPUs are kept busy; GPU encoders are limited by their session count.
+func defaultConcurrency(p Params) int {
+	workers := min(max(runtime.NumCPU()/2, 1), 8)
+	for _, encoder := range hardwareEncoders {
+		if strings.Contains(p.FFmpegParams, encoder) {
+			return min(workers, maxHardwareSessions)
+		}
+	}
+	return workers
+}
+
+// renderClips runs render for every job on up to workers goroutines and returns the results in the
+// order of jobs. The first error cancels the renders still running and is returned.
+func renderClips(ctx context.Context, jobs []clipJob, workers int, render func(context.Context, clipJob) (string, error)) ([]string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	paths := make([]string, len(jobs))
+	next := make(chan int)
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for w := 0; w < min(max(workers, 1), len(jobs)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				path, err := render(ctx, jobs[i])
+				if err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					continue
+				}
+				paths[i] = path
+			}
+		}()
+	}
+
+feed:
+	for i := range jobs {
+		select {
+		case next <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(next)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+// renderAllClips runs render for every job like renderClips, but a failed clip does not stop the
+// others. The path and error of each clip are returned in the order of jobs.
+func renderAllClips(ctx context.Context, jobs []clipJob, workers int, render func(context.Context, clipJob) (string, error)) ([]string, []error) {
+	paths := make([]string, len(jobs))
+	errs := make([]error, len(jobs))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(max(workers, 1), len(jobs)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				paths[i], errs[i] = render(ctx, jobs[i])
+			}
+		}()
+	}
+
+	for i := range jobs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return paths, errs
+}
+
+// firstError returns the first non-nil error of errs
+func firstError(errs []error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
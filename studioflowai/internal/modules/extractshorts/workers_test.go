@@ -0,0 +1,80 @@
+package extractshorts
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderClips(t *testing.T) {
+	jobs := make([]clipJob, 12)
+	for i := range jobs {
+		jobs[i].short.Title = string(rune('a' + i))
+	}
+
+	var running, peak atomic.Int32
+	paths, err := renderClips(context.Background(), jobs, 4, func(ctx context.Context, job clipJob) (string, error) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+		return job.short.Title + ".mp4", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "a.mp4", paths[0], "results keep the order of the clips")
+	assert.Equal(t, "l.mp4", paths[11])
+	assert.LessOrEqual(t, peak.Load(), int32(4))
+	assert.Greater(t, peak.Load(), int32(1))
+
+	// The first failure stops the clips not started yet
+	var started atomic.Int32
+	_, err = renderClips(context.Background(), jobs, 2, func(ctx context.Context, job clipJob) (string, error) {
+		started.Add(1)
+		if job.short.Title == "a" {
+			return "", errors.New("ffmpeg command failed")
+		}
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	assert.EqualError(t, err, "ffmpeg command failed")
+	assert.Less(t, started.Load(), int32(len(jobs)))
+}
+
+func TestRenderAllClips(t *testing.T) {
+	jobs := make([]clipJob, 6)
+	for i := range jobs {
+		jobs[i].short.Title = string(rune('a' + i))
+	}
+
+	// A failed clip does not stop the others
+	paths, errs := renderAllClips(context.Background(), jobs, 2, func(ctx context.Context, job clipJob) (string, error) {
+		if job.short.Title == "b" || job.short.Title == "e" {
+			return "", errors.New("ffmpeg command failed")
+		}
+		return job.short.Title + ".mp4", nil
+	})
+	require.Len(t, errs, len(jobs))
+	assert.Equal(t, "a.mp4", paths[0])
+	assert.Equal(t, "f.mp4", paths[5])
+	assert.Empty(t, paths[1])
+	assert.Error(t, errs[1])
+	assert.Error(t, errs[4])
+	assert.NoError(t, errs[2])
+	assert.EqualError(t, firstError(errs), "ffmpeg command failed")
+	assert.NoError(t, firstError(make([]error, 3)))
+}
+
+func TestDefaultConcurrency(t *testing.T) {
+	assert.GreaterOrEqual(t, defaultConcurrency(Params{}), 1)
+	assert.LessOrEqual(t, defaultConcurrency(Params{FFmpegParams: "-c:v h264_nvenc -preset p4"}), maxHardwareSessions)
+}
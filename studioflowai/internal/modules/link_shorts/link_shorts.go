@@ -0,0 +1,443 @@
+package linkshorts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	modules "github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	youtubesvc "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/youtube"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"google.golang.org/api/youtube/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// reportFileName is the file listing the shorts and whether each one was linked
+const reportFileName = "linked_shorts.yaml"
+
+// maxDescriptionBytes is the longest description YouTube accepts
+const maxDescriptionBytes = 5000
+
+// endCardMarker separates a short's own description from the link to the full video
+const endCardMarker = "\n\n―――\n"
+
+// Link states recorded in the report
+const (
+	statusLinked    = "linked"
+	statusUnchanged = "unchanged"
+	statusTooLong   = "tooLong"
+	statusDeferred  = "deferred"
+	statusPreviewed = "previewed"
+)
+
+// Module adds a link to a newly published full video to the descriptions of earlier shorts
+type Module struct {
+	youtubeService youtubesvc.YouTubeService
+}
+
+// Params contains the parameters for linking shorts
+type Params struct {
+	Input           string  `json:"input"`                            // Path to a chapter_parts.yaml; its first uploaded part is linked (optional)
+	Output          string  `json:"output"`                           // Path to output directory
+	VideoID         string  `json:"videoId"`                          // ID of the full video to link, overrides input
+	Title           string  `json:"title"`                            // Title shown in the link (default: the video's title)
+	Label           string  `json:"label" default:"▶ Full episode"`   // Text before the title in the link (default: "▶ Full episode")
+	Credentials     string  `json:"credentials"`                      // Path to Google credentials file
+	Account         string  `json:"account"`                          // Channel the shorts belong to, shown in previews
+	SinceDays       int     `json:"sinceDays" default:"30"`           // Only shorts published in the last days are linked (default: 30)
+	MaxShorts       int     `json:"maxShorts" default:"20"`           // Most shorts updated per run; every update costs 50 quota units (default: 20)
+	ShortMaxSeconds int     `json:"shortMaxSeconds" default:"180"`    // Videos up to this length count as shorts (default: 180)
+	LinkUnpublished bool    `json:"linkUnpublished"`                  // Link even while the full video is private or scheduled (default: false)
+	DailyQuota      int     `json:"dailyQuota" default:"10000"`       // YouTube Data API daily quota of the project (default: 10000)
+	QuotaWarnRatio  float64 `json:"quotaWarnThreshold" default:"0.8"` // Fraction of the daily quota that triggers a warning (default: 0.8)
+}
+
+// LinkedShort is one short and what happened to its description
+type LinkedShort struct {
+	VideoID string `yaml:"videoId"`
+	Title   string `yaml:"title"`
+	Status  string `yaml:"status"`
+}
+
+// Report is the content of linked_shorts.yaml
+type Report struct {
+	VideoID string        `yaml:"videoId"`
+	Title   string        `yaml:"title"`
+	Shorts  []LinkedShort `yaml:"shorts"`
+}
+
+// New creates a new link shorts module
+func New() modules.Module {
+	return &Module{
+		youtubeService: &youtubesvc.Service{},
+	}
+}
+
+// Name returns the module name
+func (m *Module) Name() string {
+	return "link_shorts"
+}
+
+// ParamsTemplate returns the module's parameter struct, used to validate and document workflows
+func (m *Module) ParamsTemplate() interface{} {
+	return Params{}
+}
+
+// Validate checks if the parameters are valid
+func (m *Module) Validate(params map[string]interface{}) error {
+	var p Params
+	if err := modules.ParseParams(params, &p); err != nil {
+		return err
+	}
+
+	if p.VideoID == "" {
+		if p.Input == "" {
+			return fmt.Errorf("videoId or an input parts file is required")
+		}
+		if err := utils.ValidateInputPath(p.Input, p.Output, ""); err != nil {
+			return err
+		}
+	}
+	if err := utils.ValidateOutputPath(p.Output); err != nil {
+		return err
+	}
+
+	if p.Credentials == "" {
+		return fmt.Errorf("credentials file path is required")
+	}
+	credentials, err := utils.ExpandHomeDir(p.Credentials)
+	if err != nil {
+		return fmt.Errorf("failed to expand home directory: %w", err)
+	}
+	if _, err := os.Stat(credentials); os.IsNotExist(err) {
+		return fmt.Errorf("credentials file does not exist: %s", credentials)
+	}
+
+	if p.SinceDays < 0 || p.MaxShorts < 0 || p.ShortMaxSeconds < 0 {
+		return fmt.Errorf("sinceDays, maxShorts and shortMaxSeconds must not be negative")
+	}
+	return nil
+}
+
+// Execute adds a link to the full video at the end of the descriptions of the channel's recent
+// shorts. A link added by an earlier run is replaced, so a new episode takes over the end card.
+func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (modules.ModuleResult, error) {
+	var p Params
+	if err := modules.ParseParams(params, &p); err != nil {
+		return modules.ModuleResult{}, err
+	}
+
+	// Set default values
+	if p.Label == "" {
+		p.Label = "▶ Full episode"
+	}
+	if p.SinceDays == 0 {
+		p.SinceDays = 30
+	}
+	if p.MaxShorts == 0 {
+		p.MaxShorts = 20
+	}
+	if p.ShortMaxSeconds == 0 {
+		p.ShortMaxSeconds = 180
+	}
+
+	if p.Output == "" {
+		return modules.ModuleResult{}, fmt.Errorf("output directory path is required")
+	}
+	if err := utils.EnsureDir(p.Output); err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	videoID, title := p.VideoID, p.Title
+	if videoID == "" {
+		var err error
+		videoID, title, err = readFullVideo(utils.ResolveOutputPath(p.Input, p.Output), title)
+		if err != nil {
+			return modules.ModuleResult{}, err
+		}
+	}
+
+	credentials, err := utils.ExpandHomeDir(p.Credentials)
+	if err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to expand home directory: %w", err)
+	}
+	if err := m.youtubeService.ConfigureQuota(credentials, youtubesvc.QuotaOptions{
+		DailyLimit:    p.DailyQuota,
+		WarnThreshold: p.QuotaWarnRatio,
+	}); err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to configure quota tracking: %w", err)
+	}
+	service, err := m.youtubeService.InitializeYouTubeService(ctx, credentials)
+	if err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to initialize YouTube service: %w", err)
+	}
+
+	full, err := m.youtubeService.GetVideoDetails(ctx, service, videoID)
+	if err != nil {
+		return modules.ModuleResult{}, err
+	}
+	if title == "" && full.Snippet != nil {
+		title = full.Snippet.Title
+	}
+	if !p.LinkUnpublished && (full.Status == nil || full.Status.PrivacyStatus != "public") {
+		utils.LogWarningContext(ctx, "Video %s is not public yet, so no shorts were linked; run this step again once it is published", videoID)
+		return modules.ModuleResult{
+			Metadata: map[string]interface{}{
+				"videoId":   videoID,
+				"published": false,
+			},
+		}, nil
+	}
+
+	since := time.Now().AddDate(0, 0, -p.SinceDays)
+	videos, err := m.youtubeService.ListChannelVideos(ctx, service, since)
+	if err != nil {
+		return modules.ModuleResult{}, err
+	}
+	shorts := selectShorts(videos, videoID, time.Duration(p.ShortMaxSeconds)*time.Second)
+	if len(shorts) > p.MaxShorts {
+		utils.LogWarningContext(ctx, "Linking the %d newest of %d shorts; raise maxShorts to link more", p.MaxShorts, len(shorts))
+		shorts = shorts[:p.MaxShorts]
+	}
+
+	block := endCard(p.Label, title, videoID)
+	report := Report{VideoID: videoID, Title: title}
+	preview := utils.UploadPreview{
+		Platform: utils.PlatformYouTube,
+		Source:   "https://youtu.be/" + videoID,
+		Target:   utils.UploadTarget{Account: p.Account, Credentials: p.Credentials},
+		Notes: []string{
+			"Descriptions of published shorts are replaced in place with videos.update; nothing is uploaded",
+		},
+	}
+	if ws := utils.ActiveWorkspace(); ws != nil {
+		preview.Target.Project = ws.Name
+	}
+
+	var updateErr error
+	linked, deferred := 0, 0
+	for i, short := range shorts {
+		entry := LinkedShort{VideoID: short.Id, Title: short.Snippet.Title}
+		description := withEndCard(short.Snippet.Description, block)
+
+		switch {
+		case description == short.Snippet.Description:
+			entry.Status = statusUnchanged
+		case len(description) > maxDescriptionBytes:
+			utils.LogWarningContext(ctx, "Not linking %q: its description would exceed %d bytes", short.Snippet.Title, maxDescriptionBytes)
+			entry.Status = statusTooLong
+		case utils.PreviewUploads():
+			entry.Status = statusPreviewed
+			preview.Uploads = append(preview.Uploads, utils.PreviewUpload{
+				Title:       short.Snippet.Title,
+				Description: description,
+				Tags:        short.Snippet.Tags,
+				Privacy:     short.Status.PrivacyStatus,
+				Request:     youtubesvc.DescriptionUpdate(short, description),
+			})
+		case updateErr != nil:
+			entry.Status = statusDeferred
+			deferred++
+		default:
+			if err := m.youtubeService.UpdateVideoDescription(ctx, service, short, description); err != nil {
+				var quotaErr *youtubesvc.QuotaExceededError
+				if !errors.As(err, &quotaErr) {
+					report.Shorts = append(report.Shorts, entry)
+					if _, writeErr := writeReport(p.Output, report); writeErr != nil {
+						utils.LogWarningContext(ctx, "Failed to save the linked shorts: %v", writeErr)
+					}
+					return modules.ModuleResult{}, fmt.Errorf("failed to link short %s: %w", short.Id, err)
+				}
+				utils.LogWarningContext(ctx, "YouTube API quota exhausted, %d short(s) not linked; run this step again after %s",
+					len(shorts)-i, quotaErr.ResetAt.Local().Format("2006-01-02 15:04 MST"))
+				updateErr = err
+				entry.Status = statusDeferred
+				deferred++
+				break
+			}
+			entry.Status = statusLinked
+			linked++
+			utils.LogInfoContext(ctx, "Linked short: %s", short.Snippet.Title)
+		}
+		report.Shorts = append(report.Shorts, entry)
+	}
+
+	reportPath, err := writeReport(p.Output, report)
+	if err != nil {
+		return modules.ModuleResult{}, err
+	}
+	outputs := map[string]string{
+		"linkedShorts": reportPath,
+	}
+	if utils.PreviewUploads() {
+		previewPath, err := utils.WriteUploadPreview(p.Output, preview)
+		if err != nil {
+			return modules.ModuleResult{}, err
+		}
+		outputs["uploadsPreview"] = previewPath
+		utils.LogSuccessContext(ctx, "Previewed %d description update(s) in %s; no short was changed", len(preview.Uploads), previewPath)
+	} else {
+		utils.LogSuccessContext(ctx, "Linked %d short(s) to %s", linked, title)
+	}
+
+	return modules.ModuleResult{
+		Outputs: outputs,
+		Metadata: map[string]interface{}{
+			"videoId":   videoID,
+			"published": true,
+			"shorts":    len(shorts),
+			"linked":    linked,
+			"deferred":  deferred,
+		},
+		Stats: modules.Stats{Items: linked},
+	}, nil
+}
+
+// readFullVideo returns the ID and title of the first uploaded part in a chapter_parts.yaml.
+// A title given in the step is kept.
+func readFullVideo(path, title string) (string, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read parts file: %w", err)
+	}
+	var parts struct {
+		Parts []struct {
+			Title   string `yaml:"title"`
+			VideoID string `yaml:"videoId"`
+		} `yaml:"parts"`
+	}
+	if err := yaml.Unmarshal(data, &parts); err != nil {
+		return "", "", fmt.Errorf("failed to parse parts file: %w", err)
+	}
+	for _, part := range parts.Parts {
+		if part.VideoID == "" {
+			continue
+		}
+		if title == "" {
+			title = part.Title
+		}
+		return part.VideoID, title, nil
+	}
+	return "", "", fmt.Errorf("no uploaded part in %s", path)
+}
+
+// selectShorts keeps the public videos up to maxDuration, other than the full video itself
+func selectShorts(videos []*youtube.Video, fullVideoID string, maxDuration time.Duration) []*youtube.Video {
+	var shorts []*youtube.Video
+	for _, video := range videos {
+		if video.Id == fullVideoID || video.Snippet == nil || video.Status == nil || video.ContentDetails == nil {
+			continue
+		}
+		if video.Status.PrivacyStatus != "public" {
+			continue
+		}
+		duration, err := youtubesvc.ParseDuration(video.ContentDetails.Duration)
+		if err != nil || duration == 0 || duration > maxDuration {
+			continue
+		}
+		shorts = append(shorts, video)
+	}
+	return shorts
+}
+
+// endCard builds the link to the full video appended to the descriptions
+func endCard(label, title, videoID string) string {
+	return fmt.Sprintf("%s: %s\nhttps://youtu.be/%s", label, title, videoID)
+}
+
+// withEndCard appends the end card to a description, replacing one added earlier. An earlier end
+// card is the text after the last marker when it ends in a youtu.be link, whatever its label; a
+// short without a description of its own holds only the card, without a marker.
+func withEndCard(description, card string) string {
+	body := description
+	if i := strings.LastIndex(description, endCardMarker); i >= 0 {
+		if isEndCard(description[i+len(endCardMarker):]) {
+			body = description[:i]
+		}
+	} else if isEndCard(description) {
+		body = ""
+	}
+	body = strings.TrimRight(body, "\n ")
+	if body == "" {
+		return card
+	}
+	return body + endCardMarker + card
+}
+
+// isEndCard reports whether text is a label line followed by a youtu.be link
+func isEndCard(text string) bool {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	return len(lines) == 2 && strings.Contains(lines[0], ": ") && strings.HasPrefix(lines[1], "https://youtu.be/")
+}
+
+// writeReport saves linked_shorts.yaml and returns its path
+func writeReport(outputDir string, report Report) (string, error) {
+	content, err := yaml.Marshal(&report)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate YAML: %w", err)
+	}
+	path := filepath.Join(outputDir, reportFileName)
+	if err := utils.WriteTextFile(path, string(content)); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// GetIO returns the module's input/output specification
+func (m *Module) GetIO() modules.ModuleIO {
+	return modules.ModuleIO{
+		RequiredInputs: []modules.ModuleInput{
+			{
+				Name:        "output",
+				Description: "Path to output directory",
+				Type:        string(modules.InputTypeDirectory),
+			},
+			{
+				Name:        "credentials",
+				Description: "Path to Google credentials file",
+				Patterns:    []string{".json"},
+				Type:        string(modules.InputTypeFile),
+			},
+		},
+		OptionalInputs: []modules.ModuleInput{
+			{
+				Name:        "input",
+				Description: "Parts file of split_chapters; its first uploaded part is linked",
+				Patterns:    []string{".yaml"},
+				Type:        string(modules.InputTypeFile),
+			},
+			{
+				Name:        "videoId",
+				Description: "ID of the full video to link",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "label",
+				Description: "Text before the title in the link (default: \"▶ Full episode\")",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "sinceDays",
+				Description: "Only shorts published in the last days are linked (default: 30)",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "maxShorts",
+				Description: "Most shorts updated per run (default: 20)",
+				Type:        string(modules.InputTypeData),
+			},
+		},
+		ProducedOutputs: []modules.ModuleOutput{
+			{
+				Name:        "linkedShorts",
+				Description: "Shorts of the channel and whether each one links to the full video",
+				Patterns:    []string{".yaml"},
+				Type:        string(modules.OutputTypeFile),
+			},
+		},
+	}
+}
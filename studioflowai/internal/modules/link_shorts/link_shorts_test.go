@@ -0,0 +1,210 @@
+package linkshorts
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/services/youtube"
+	youtubemocks "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/youtube/mocks"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	youtubeapi "google.golang.org/api/youtube/v3"
+	"gopkg.in/yaml.v3"
+)
+
+func testVideo(id, title, description, privacy, duration string) *youtubeapi.Video {
+	return &youtubeapi.Video{
+		Id:             id,
+		Snippet:        &youtubeapi.VideoSnippet{Title: title, Description: description, CategoryId: "22", Tags: []string{"studio"}, DefaultLanguage: "en"},
+		Status:         &youtubeapi.VideoStatus{PrivacyStatus: privacy},
+		ContentDetails: &youtubeapi.VideoContentDetails{Duration: duration},
+	}
+}
+
+func writeCredentials(t *testing.T, dir string) string {
+	path := filepath.Join(dir, "credentials.json")
+	require.NoError(t, os.WriteFile(path, []byte("{}"), 0644))
+	return path
+}
+
+func TestWithEndCard(t *testing.T) {
+	card := endCard("▶ Full episode", "Episode 2", "new123")
+
+	tests := []struct {
+		name        string
+		description string
+		expected    string
+	}{
+		{
+			name:        "appends to description",
+			description: "A quick tip.\n#studio",
+			expected:    "A quick tip.\n#studio\n\n―――\n▶ Full episode: Episode 2\nhttps://youtu.be/new123",
+		},
+		{
+			name:        "empty description",
+			description: "",
+			expected:    card,
+		},
+		{
+			name:        "replaces earlier end card with another label",
+			description: "A quick tip.\n\n―――\nWatch: Episode 1\nhttps://youtu.be/old456",
+			expected:    "A quick tip.\n\n―――\n▶ Full episode: Episode 2\nhttps://youtu.be/new123",
+		},
+		{
+			name:        "keeps other blocks after a marker",
+			description: "A quick tip.\n\n―――\nPart 1/2: Intro\nPart 2/2: Outro",
+			expected:    "A quick tip.\n\n―――\nPart 1/2: Intro\nPart 2/2: Outro\n\n―――\n▶ Full episode: Episode 2\nhttps://youtu.be/new123",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, withEndCard(tt.description, card))
+			assert.Equal(t, tt.expected, withEndCard(tt.expected, card), "linking again changes nothing")
+		})
+	}
+}
+
+func TestSelectShorts(t *testing.T) {
+	videos := []*youtubeapi.Video{
+		testVideo("full", "Episode", "", "public", "PT45M"),
+		testVideo("short1", "Tip", "", "public", "PT58S"),
+		testVideo("long", "Talk", "", "public", "PT12M3S"),
+		testVideo("private", "Draft", "", "private", "PT30S"),
+		testVideo("short2", "Trick", "", "public", "PT2M59S"),
+		{Id: "broken"},
+	}
+
+	shorts := selectShorts(videos, "full", 3*time.Minute)
+	require.Len(t, shorts, 2)
+	assert.Equal(t, "short1", shorts[0].Id)
+	assert.Equal(t, "short2", shorts[1].Id)
+}
+
+func TestModule_Validate(t *testing.T) {
+	tempDir := t.TempDir()
+	credentials := writeCredentials(t, tempDir)
+	module := New()
+
+	assert.NoError(t, module.Validate(map[string]interface{}{
+		"videoId":     "new123",
+		"output":      tempDir,
+		"credentials": credentials,
+	}))
+	assert.Error(t, module.Validate(map[string]interface{}{
+		"output":      tempDir,
+		"credentials": credentials,
+	}), "a video ID or parts file is required")
+	assert.Error(t, module.Validate(map[string]interface{}{
+		"videoId": "new123",
+		"output":  tempDir,
+	}), "credentials are required")
+	assert.Error(t, module.Validate(map[string]interface{}{
+		"videoId":     "new123",
+		"output":      tempDir,
+		"credentials": credentials,
+		"maxShorts":   -1,
+	}))
+}
+
+func TestModule_Execute(t *testing.T) {
+	tempDir := t.TempDir()
+	credentials := writeCredentials(t, tempDir)
+	partsPath := filepath.Join(tempDir, "chapter_parts.yaml")
+	require.NoError(t, os.WriteFile(partsPath, []byte("parts:\n  - part: 1\n    title: Episode 2 | Part 1/2\n    videoId: new123\n"), 0644))
+
+	tip := testVideo("short1", "Tip", "A quick tip.", "public", "PT58S")
+	linkedAlready := testVideo("short2", "Trick", withEndCard("A trick.", endCard("▶ Full episode", "Episode 2 | Part 1/2", "new123")), "public", "PT40S")
+	quota := testVideo("short3", "Hack", "A hack.", "public", "PT30S")
+
+	mockService := youtubemocks.NewMockYouTubeService(t)
+	mockService.On("ConfigureQuota", credentials, mock.Anything).Return(nil)
+	mockService.On("InitializeYouTubeService", mock.Anything, credentials).Return(&youtubeapi.Service{}, nil)
+	mockService.On("GetVideoDetails", mock.Anything, mock.Anything, "new123").Return(testVideo("new123", "Episode 2", "", "public", "PT40M"), nil)
+	mockService.On("ListChannelVideos", mock.Anything, mock.Anything, mock.Anything).Return([]*youtubeapi.Video{
+		testVideo("new123", "Episode 2", "", "public", "PT40M"), tip, linkedAlready, quota,
+	}, nil)
+	mockService.On("UpdateVideoDescription", mock.Anything, mock.Anything, tip,
+		"A quick tip.\n\n―――\n▶ Full episode: Episode 2 | Part 1/2\nhttps://youtu.be/new123").Return(nil)
+	mockService.On("UpdateVideoDescription", mock.Anything, mock.Anything, quota, mock.Anything).
+		Return(&youtube.QuotaExceededError{Operation: "videos.update", ResetAt: time.Now()})
+
+	module := &Module{youtubeService: mockService}
+	result, err := module.Execute(context.Background(), map[string]interface{}{
+		"input":       partsPath,
+		"output":      tempDir,
+		"credentials": credentials,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Stats.Items)
+	assert.Equal(t, 1, result.Metadata["deferred"])
+
+	data, err := os.ReadFile(result.Outputs["linkedShorts"])
+	require.NoError(t, err)
+	var report Report
+	require.NoError(t, yaml.Unmarshal(data, &report))
+	assert.Equal(t, "new123", report.VideoID)
+	require.Len(t, report.Shorts, 3)
+	assert.Equal(t, statusLinked, report.Shorts[0].Status)
+	assert.Equal(t, statusUnchanged, report.Shorts[1].Status)
+	assert.Equal(t, statusDeferred, report.Shorts[2].Status)
+}
+
+func TestModule_ExecuteWaitsForPublication(t *testing.T) {
+	tempDir := t.TempDir()
+	credentials := writeCredentials(t, tempDir)
+
+	mockService := youtubemocks.NewMockYouTubeService(t)
+	mockService.On("ConfigureQuota", credentials, mock.Anything).Return(nil)
+	mockService.On("InitializeYouTubeService", mock.Anything, credentials).Return(&youtubeapi.Service{}, nil)
+	mockService.On("GetVideoDetails", mock.Anything, mock.Anything, "new123").Return(testVideo("new123", "Episode 2", "", "private", "PT40M"), nil)
+
+	module := &Module{youtubeService: mockService}
+	result, err := module.Execute(context.Background(), map[string]interface{}{
+		"videoId":     "new123",
+		"output":      tempDir,
+		"credentials": credentials,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, false, result.Metadata["published"])
+	mockService.AssertNotCalled(t, "ListChannelVideos", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestModule_ExecutePreviewUploads(t *testing.T) {
+	tempDir := t.TempDir()
+	credentials := writeCredentials(t, tempDir)
+	utils.SetPreviewUploads(true)
+	defer utils.SetPreviewUploads(false)
+
+	// Videos are read to build the new descriptions, but none is updated
+	mockService := youtubemocks.NewMockYouTubeService(t)
+	mockService.On("ConfigureQuota", credentials, mock.Anything).Return(nil)
+	mockService.On("InitializeYouTubeService", mock.Anything, credentials).Return(&youtubeapi.Service{}, nil)
+	mockService.On("GetVideoDetails", mock.Anything, mock.Anything, "new123").Return(testVideo("new123", "Episode 2", "", "public", "PT40M"), nil)
+	mockService.On("ListChannelVideos", mock.Anything, mock.Anything, mock.Anything).Return([]*youtubeapi.Video{
+		testVideo("short1", "Tip", "A quick tip.", "public", "PT58S"),
+	}, nil)
+
+	module := &Module{youtubeService: mockService}
+	result, err := module.Execute(context.Background(), map[string]interface{}{
+		"videoId":     "new123",
+		"output":      tempDir,
+		"credentials": credentials,
+		"account":     "Studio Channel",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Stats.Items)
+
+	data, err := os.ReadFile(result.Outputs["uploadsPreview"])
+	require.NoError(t, err)
+	preview := string(data)
+	assert.Contains(t, preview, "account: Studio Channel")
+	assert.Contains(t, preview, "https://youtu.be/new123")
+	assert.Contains(t, preview, "defaultLanguage: en", "the rest of the snippet is sent back unchanged")
+	assert.NotContains(t, preview, "missing: true")
+}
@@ -0,0 +1,272 @@
+package makeproxy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	modules "github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+// execCommand allows us to mock exec.Command in tests
+var execCommand = utils.CommandContext
+
+// supportedExtensions lists the source video formats accepted by the module
+var supportedExtensions = []string{".mp4", ".mov", ".mkv", ".m4v", ".webm", ".avi"}
+
+// Module implements low-resolution proxy generation
+type Module struct{}
+
+// Params contains the parameters for proxy generation
+type Params struct {
+	Input        string  `json:"input"`                       // Path to the source video file
+	Output       string  `json:"output"`                      // Path to output directory
+	OutputName   string  `json:"outputName"`                  // Custom output filename (default: "<input>_proxy.mp4")
+	Height       int     `json:"height" default:"540"`        // Height of the proxy in pixels; smaller sources keep theirs (default: 540)
+	CRF          int     `json:"crf" default:"28"`            // x264 constant rate factor, lower is better quality (default: 28)
+	Preset       string  `json:"preset" default:"veryfast"`   // x264 encoding preset (default: "veryfast")
+	KeyframeSecs float64 `json:"keyframeSecs" default:"1"`    // Seconds between keyframes, so review tools seek instantly (default: 1)
+	AudioBitrate string  `json:"audioBitrate" default:"128k"` // AAC bitrate of the proxy's audio (default: "128k")
+	Force        bool    `json:"force"`                       // Encode again even if a proxy newer than the source exists
+	QuietFlag    bool    `json:"quietFlag" default:"true"`    // Suppress ffmpeg output (default: true)
+}
+
+// New creates a new make proxy module
+func New() modules.Module {
+	return &Module{}
+}
+
+// Name returns the module name
+func (m *Module) Name() string {
+	return "make_proxy"
+}
+
+// ParamsTemplate returns the module's parameter struct, used to validate and document workflows
+func (m *Module) ParamsTemplate() interface{} {
+	return Params{}
+}
+
+// Validate checks if the parameters are valid
+func (m *Module) Validate(params map[string]interface{}) error {
+	var p Params
+	if err := modules.ParseParams(params, &p); err != nil {
+		return err
+	}
+
+	// Validate input path
+	if err := utils.ValidateInputPath(p.Input, p.Output, ""); err != nil {
+		return err
+	}
+
+	// Validate output path
+	if err := utils.ValidateOutputPath(p.Output); err != nil {
+		return err
+	}
+
+	// Validate video file extension if input is a file
+	resolvedInput := utils.ResolveOutputPath(p.Input, p.Output)
+	if fileInfo, err := os.Stat(resolvedInput); err == nil && !fileInfo.IsDir() {
+		if err := utils.ValidateFileExtension(resolvedInput, supportedExtensions); err != nil {
+			return err
+		}
+	}
+
+	if p.OutputName != "" {
+		if err := utils.ValidateFileExtension(p.OutputName, []string{".mp4"}); err != nil {
+			return err
+		}
+	}
+
+	if p.Height < 0 || p.KeyframeSecs < 0 {
+		return fmt.Errorf("height and keyframeSecs must not be negative")
+	}
+	if p.Height%2 != 0 {
+		return fmt.Errorf("height must be even, got %d", p.Height)
+	}
+	if p.CRF < 0 || p.CRF > 51 {
+		return fmt.Errorf("crf must be between 0 and 51, got %d", p.CRF)
+	}
+
+	// Validate FFmpeg dependencies
+	if err := utils.ValidateRequiredDependency("ffmpeg"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Execute encodes a small, fast-seeking copy of the source video. The proxy keeps the timing of
+// the source, so clip times chosen on it apply to the full-resolution video unchanged.
+func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (modules.ModuleResult, error) {
+	var p Params
+	if err := modules.ParseParams(params, &p); err != nil {
+		return modules.ModuleResult{}, err
+	}
+
+	// Set default values
+	if p.Height == 0 {
+		p.Height = 540
+	}
+	if p.CRF == 0 {
+		p.CRF = 28
+	}
+	if p.Preset == "" {
+		p.Preset = "veryfast"
+	}
+	if p.KeyframeSecs == 0 {
+		p.KeyframeSecs = 1
+	}
+	if p.AudioBitrate == "" {
+		p.AudioBitrate = "128k"
+	}
+
+	// Default to quiet mode (no ffmpeg output) unless explicitly set to false
+	if _, exists := params["quietFlag"]; !exists {
+		p.QuietFlag = true
+	}
+
+	if p.Output == "" {
+		return modules.ModuleResult{}, fmt.Errorf("output directory path is required")
+	}
+
+	resolvedInput := utils.ResolveOutputPath(p.Input, p.Output)
+	source, err := os.Stat(resolvedInput)
+	if err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to access input: %w", err)
+	}
+
+	if err := utils.EnsureDir(p.Output); err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// The review steps find the proxy by its default name
+	outputName := p.OutputName
+	if outputName == "" {
+		outputName = utils.ProxyName(resolvedInput)
+	}
+	outputPath := filepath.Join(p.Output, outputName)
+
+	// A proxy newer than its source is reused, so reruns of the review loop skip the encode
+	if proxy, err := os.Stat(outputPath); err == nil && !p.Force && proxy.ModTime().After(source.ModTime()) {
+		utils.LogInfoContext(ctx, "Reusing proxy %s, which is newer than %s", outputPath, filepath.Base(resolvedInput))
+		return proxyResult(outputPath, resolvedInput, true, p), nil
+	}
+
+	args := proxyArgs(resolvedInput, outputPath, p)
+	if p.QuietFlag {
+		args = append(args, "-loglevel", "error")
+	}
+
+	utils.LogInfoContext(ctx, "Creating %dp proxy of %s", p.Height, filepath.Base(resolvedInput))
+	cmd := execCommand(ctx, "ffmpeg", args...)
+	var stderr bytes.Buffer
+	if p.QuietFlag {
+		cmd.Stderr = &stderr
+	} else {
+		cmd.Stdout = utils.LogWriter(ctx)
+		cmd.Stderr = os.Stderr
+	}
+	if err := utils.RunWatched(ctx, cmd); err != nil {
+		if stderr.Len() > 0 {
+			utils.LogErrorContext(ctx, "FFmpeg error: %s", stderr.String())
+		}
+		return modules.ModuleResult{}, fmt.Errorf("ffmpeg proxy encode failed: %w", err)
+	}
+
+	utils.LogSuccessContext(ctx, "Proxy written to %s", outputPath)
+	return proxyResult(outputPath, resolvedInput, false, p), nil
+}
+
+// proxyResult describes the proxy and the source it stands in for
+func proxyResult(outputPath, source string, reused bool, p Params) modules.ModuleResult {
+	return modules.ModuleResult{
+		Outputs: map[string]string{
+			"proxy": outputPath,
+		},
+		Metadata: map[string]interface{}{
+			"source": source,
+			"height": p.Height,
+			"reused": reused,
+		},
+		Stats: modules.Stats{Items: 1},
+	}
+}
+
+// proxyArgs builds ffmpeg arguments for a low-resolution H.264/AAC copy with frequent keyframes.
+// The frame rate and timestamps of the source are kept.
+func proxyArgs(input, output string, p Params) []string {
+	filters := []string{
+		fmt.Sprintf("scale=-2:'min(%d,ih)'", p.Height),
+		"format=yuv420p",
+	}
+	keyframes := strconv.FormatFloat(p.KeyframeSecs, 'f', -1, 64)
+
+	return []string{
+		"-i", input,
+		"-map", "0:v:0", "-map", "0:a:0?",
+		"-vf", strings.Join(filters, ","),
+		"-c:v", "libx264",
+		"-preset", p.Preset,
+		"-crf", strconv.Itoa(p.CRF),
+		"-force_key_frames", "expr:gte(t,n_forced*" + keyframes + ")",
+		"-c:a", "aac",
+		"-b:a", p.AudioBitrate,
+		"-movflags", "+faststart",
+		output,
+		"-y",
+	}
+}
+
+// GetIO returns the module's input/output specification
+func (m *Module) GetIO() modules.ModuleIO {
+	return modules.ModuleIO{
+		RequiredInputs: []modules.ModuleInput{
+			{
+				Name:        "input",
+				Description: "Path to the source video file",
+				Patterns:    supportedExtensions,
+				Type:        string(modules.InputTypeFile),
+			},
+			{
+				Name:        "output",
+				Description: "Path to output directory",
+				Type:        string(modules.InputTypeDirectory),
+			},
+		},
+		OptionalInputs: []modules.ModuleInput{
+			{
+				Name:        "outputName",
+				Description: "Custom output filename",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "height",
+				Description: "Height of the proxy in pixels (default: 540)",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "crf",
+				Description: "x264 constant rate factor of the proxy (default: 28)",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "force",
+				Description: "Encode again even if an up-to-date proxy exists",
+				Type:        string(modules.InputTypeData),
+			},
+		},
+		ProducedOutputs: []modules.ModuleOutput{
+			{
+				Name:        "proxy",
+				Description: "Low-resolution copy of the source with the same timing, for previews and review",
+				Patterns:    []string{".mp4"},
+				Type:        string(modules.OutputTypeFile),
+			},
+		},
+	}
+}
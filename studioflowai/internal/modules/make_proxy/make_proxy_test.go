@@ -0,0 +1,188 @@
+package makeproxy
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordedArgs holds the arguments of the last ffmpeg invocation
+var recordedArgs []string
+
+// fakeExecCommand records the ffmpeg arguments and returns a helper process that succeeds
+func fakeExecCommand(ctx context.Context, command string, args ...string) *exec.Cmd {
+	if command == "ffmpeg" {
+		recordedArgs = args
+	}
+	cs := []string{"-test.run=TestHelperProcess", "--", command}
+	cs = append(cs, args...)
+	cmd := exec.Command(os.Args[0], cs...)
+	cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1"}
+	return cmd
+}
+
+// fakeLookPath always returns success
+func fakeLookPath(file string) (string, error) {
+	return file, nil
+}
+
+// TestHelperProcess is not a real test, it's used to mock exec.Command
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	os.Exit(0)
+}
+
+func TestModule_Name(t *testing.T) {
+	assert.Equal(t, "make_proxy", New().Name())
+}
+
+func TestModule_GetIO(t *testing.T) {
+	io := New().GetIO()
+
+	assert.Len(t, io.RequiredInputs, 2)
+	assert.Equal(t, "input", io.RequiredInputs[0].Name)
+	assert.Equal(t, "output", io.RequiredInputs[1].Name)
+
+	assert.Len(t, io.ProducedOutputs, 1)
+	assert.Equal(t, "proxy", io.ProducedOutputs[0].Name)
+}
+
+func TestModule_Validate(t *testing.T) {
+	utils.ExecLookPath = fakeLookPath
+	defer func() { utils.ExecLookPath = exec.LookPath }()
+
+	tempDir := t.TempDir()
+	videoPath := filepath.Join(tempDir, "episode.mov")
+	require.NoError(t, os.WriteFile(videoPath, []byte("dummy"), 0644))
+	textPath := filepath.Join(tempDir, "notes.txt")
+	require.NoError(t, os.WriteFile(textPath, []byte("dummy"), 0644))
+
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name:   "valid parameters",
+			params: map[string]interface{}{"input": videoPath, "output": tempDir},
+		},
+		{
+			name:    "missing input",
+			params:  map[string]interface{}{"output": tempDir},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported input extension",
+			params:  map[string]interface{}{"input": textPath, "output": tempDir},
+			wantErr: true,
+		},
+		{
+			name:    "invalid output name",
+			params:  map[string]interface{}{"input": videoPath, "output": tempDir, "outputName": "proxy.mov"},
+			wantErr: true,
+		},
+		{
+			name:    "odd height",
+			params:  map[string]interface{}{"input": videoPath, "output": tempDir, "height": 541},
+			wantErr: true,
+		},
+		{
+			name:    "crf out of range",
+			params:  map[string]interface{}{"input": videoPath, "output": tempDir, "crf": 60},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := New().Validate(tt.params)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestModule_Execute(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = utils.CommandContext }()
+
+	tempDir := t.TempDir()
+	videoPath := filepath.Join(tempDir, "episode.mov")
+	require.NoError(t, os.WriteFile(videoPath, []byte("dummy"), 0644))
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(videoPath, old, old))
+
+	t.Run("encodes a fast-seeking proxy", func(t *testing.T) {
+		recordedArgs = nil
+		result, err := New().Execute(context.Background(), map[string]interface{}{
+			"input":  videoPath,
+			"output": tempDir,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, filepath.Join(tempDir, "episode_proxy.mp4"), result.Outputs["proxy"])
+		assert.Equal(t, videoPath, result.Metadata["source"])
+		assert.Equal(t, false, result.Metadata["reused"])
+
+		joined := strings.Join(recordedArgs, " ")
+		assert.Contains(t, joined, "scale=-2:'min(540,ih)'")
+		assert.Contains(t, joined, "-crf 28")
+		assert.Contains(t, joined, "-preset veryfast")
+		assert.Contains(t, joined, "-force_key_frames expr:gte(t,n_forced*1)")
+		assert.Contains(t, joined, "-movflags +faststart")
+		assert.NotContains(t, joined, "fps=", "the proxy must keep the timing of the source")
+	})
+
+	t.Run("reuses a proxy newer than the source", func(t *testing.T) {
+		recordedArgs = nil
+		proxyPath := filepath.Join(tempDir, "review.mp4")
+		require.NoError(t, os.WriteFile(proxyPath, []byte("proxy"), 0644))
+
+		result, err := New().Execute(context.Background(), map[string]interface{}{
+			"input":      videoPath,
+			"output":     tempDir,
+			"outputName": "review.mp4",
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, proxyPath, result.Outputs["proxy"])
+		assert.Equal(t, true, result.Metadata["reused"])
+		assert.Nil(t, recordedArgs, "ffmpeg must not run")
+	})
+
+	t.Run("force encodes again", func(t *testing.T) {
+		recordedArgs = nil
+		result, err := New().Execute(context.Background(), map[string]interface{}{
+			"input":      videoPath,
+			"output":     tempDir,
+			"outputName": "review.mp4",
+			"force":      true,
+			"height":     720,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, false, result.Metadata["reused"])
+		assert.Contains(t, strings.Join(recordedArgs, " "), "min(720,ih)")
+	})
+
+	t.Run("fails on missing input", func(t *testing.T) {
+		_, err := New().Execute(context.Background(), map[string]interface{}{
+			"input":  filepath.Join(tempDir, "missing.mov"),
+			"output": tempDir,
+		})
+		assert.Error(t, err)
+	})
+}
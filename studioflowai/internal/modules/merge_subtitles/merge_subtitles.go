@@ -0,0 +1,315 @@
+package mergesubtitles
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	modules "github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+// How cues of different speakers that overlap in time are written
+const (
+	OverlapKeep = "keep" // Keep both cues as they are; players show them together
+	OverlapTrim = "trim" // End the earlier cue where the later one starts, so one speaker shows at a time
+)
+
+// minTrimmedCue is the shortest cue left by trimming; shorter ones are kept whole instead
+const minTrimmedCue = 300 * time.Millisecond
+
+// Module merges the subtitles of separately recorded speakers into one labeled transcript
+type Module struct{}
+
+// Track is the subtitle file of one speaker's recording
+type Track struct {
+	File    string  `json:"file"`    // SRT file of the speaker's track
+	Speaker string  `json:"speaker"` // Name written before each of the speaker's cues (default: the file name)
+	Offset  float64 `json:"offset"`  // Seconds added to the track's times, for tracks that started recording late (default: 0)
+}
+
+// Params contains the parameters for merging speaker subtitles
+type Params struct {
+	Tracks         []Track `json:"tracks"`                              // Subtitle file of each speaker, e.g. from Riverside or Zoom separate tracks
+	Output         string  `json:"output"`                              // Path to output directory
+	OutputFileName string  `json:"outputFileName" default:"transcript"` // Name of the merged SRT file without extension (default: "transcript")
+	Overlap        string  `json:"overlap" default:"keep"`              // Overlapping cues of different speakers: keep both or trim the earlier one (default: "keep")
+	Dedupe         bool    `json:"dedupe" default:"true"`               // Drop a cue that repeats an overlapping cue of another speaker, picked up by their microphone (default: true)
+	DedupeRatio    float64 `json:"dedupeRatio" default:"0.6"`           // Share of words two overlapping cues must have in common to be the same speech (default: 0.6)
+}
+
+// cue is a subtitle cue with its speaker
+type cue struct {
+	utils.SubtitleCue
+	speaker string
+	track   int
+}
+
+// New creates a new merge subtitles module
+func New() modules.Module {
+	return &Module{}
+}
+
+// Name returns the module name
+func (m *Module) Name() string {
+	return "merge_subtitles"
+}
+
+// ParamsTemplate returns the module's parameter struct, used to validate and document workflows
+func (m *Module) ParamsTemplate() interface{} {
+	return Params{}
+}
+
+// Validate checks if the parameters are valid
+func (m *Module) Validate(params map[string]interface{}) error {
+	var p Params
+	if err := modules.ParseParams(params, &p); err != nil {
+		return err
+	}
+
+	if len(p.Tracks) < 2 {
+		return fmt.Errorf("tracks must list the subtitle files of at least two speakers")
+	}
+	speakers := map[string]bool{}
+	for i, track := range p.Tracks {
+		if track.File == "" {
+			return fmt.Errorf("track %d has no file", i+1)
+		}
+		if err := utils.ValidateInputPath(track.File, p.Output, ""); err != nil {
+			return fmt.Errorf("track %d: %w", i+1, err)
+		}
+		speaker := speakerName(track)
+		if speakers[speaker] {
+			return fmt.Errorf("speaker %q is listed twice", speaker)
+		}
+		speakers[speaker] = true
+	}
+	if err := utils.ValidateOutputPath(p.Output); err != nil {
+		return err
+	}
+	if p.Overlap != "" && p.Overlap != OverlapKeep && p.Overlap != OverlapTrim {
+		return fmt.Errorf("unsupported overlap %q (supported: %s, %s)", p.Overlap, OverlapKeep, OverlapTrim)
+	}
+	if p.DedupeRatio < 0 || p.DedupeRatio > 1 {
+		return fmt.Errorf("dedupeRatio must be between 0 and 1")
+	}
+	return nil
+}
+
+// Execute merges the speakers' subtitles into one SRT file whose cues start with the speaker's name
+func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (modules.ModuleResult, error) {
+	var p Params
+	if err := modules.ParseParams(params, &p); err != nil {
+		return modules.ModuleResult{}, err
+	}
+
+	// Set default values
+	if p.OutputFileName == "" {
+		p.OutputFileName = "transcript"
+	}
+	if p.Overlap == "" {
+		p.Overlap = OverlapKeep
+	}
+	if _, ok := params["dedupe"]; !ok {
+		p.Dedupe = true
+	}
+	if p.DedupeRatio == 0 {
+		p.DedupeRatio = 0.6
+	}
+
+	if err := utils.EnsureDir(p.Output); err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	var cues []cue
+	for i, track := range p.Tracks {
+		path := utils.ResolveOutputPath(track.File, p.Output)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return modules.ModuleResult{}, fmt.Errorf("failed to read subtitles of %s: %w", speakerName(track), err)
+		}
+		trackCues, err := utils.ParseSRT(string(data))
+		if err != nil {
+			return modules.ModuleResult{}, fmt.Errorf("%s: %w", path, err)
+		}
+		offset := time.Duration(track.Offset * float64(time.Second))
+		for _, c := range trackCues {
+			c.Start += offset
+			c.End += offset
+			if c.End <= 0 || strings.TrimSpace(c.Text) == "" {
+				continue
+			}
+			c.Start = max(c.Start, 0)
+			cues = append(cues, cue{SubtitleCue: c, speaker: speakerName(track), track: i})
+		}
+	}
+
+	merged, duplicates := mergeCues(cues, p)
+	if duplicates > 0 {
+		utils.LogVerboseContext(ctx, "Dropped %d cues heard on another speaker's microphone", duplicates)
+	}
+
+	outputPath := filepath.Join(p.Output, p.OutputFileName+".srt")
+	if err := utils.AtomicWriteFile(outputPath, []byte(formatSRT(merged)), 0644); err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to write merged transcript: %w", err)
+	}
+	utils.LogSuccessContext(ctx, "Merged the subtitles of %d speakers into %s", len(p.Tracks), outputPath)
+
+	perSpeaker := map[string]int{}
+	for _, c := range merged {
+		perSpeaker[c.speaker]++
+	}
+	return modules.ModuleResult{
+		Outputs: map[string]string{
+			"transcript": outputPath,
+		},
+		Statistics: map[string]interface{}{
+			"speakers":        len(p.Tracks),
+			"cues":            len(merged),
+			"cuesPerSpeaker":  perSpeaker,
+			"duplicates":      duplicates,
+			"overlap":         p.Overlap,
+			"processing_time": time.Now().Format(time.RFC3339),
+		},
+		Stats: modules.Stats{Items: len(merged)},
+	}, nil
+}
+
+// mergeCues orders the cues of all speakers by time, drops the ones repeating another speaker's
+// overlapping cue and, with overlap "trim", ends each cue where the next speaker's begins. It
+// returns the merged cues and the number of duplicates dropped.
+func mergeCues(cues []cue, p Params) ([]cue, int) {
+	sort.SliceStable(cues, func(i, j int) bool {
+		if cues[i].Start != cues[j].Start {
+			return cues[i].Start < cues[j].Start
+		}
+		return cues[i].track < cues[j].track
+	})
+
+	dropped := make([]bool, len(cues))
+	duplicates := 0
+	if p.Dedupe {
+		for i := range cues {
+			for j := i + 1; j < len(cues) && cues[j].Start < cues[i].End; j++ {
+				if dropped[i] || dropped[j] || cues[i].speaker == cues[j].speaker {
+					continue
+				}
+				if wordOverlap(cues[i].Text, cues[j].Text) < p.DedupeRatio {
+					continue
+				}
+				// Bleed is quieter and picked up in part, so the fuller cue is the speaker's own
+				if len(strings.Fields(cues[j].Text)) > len(strings.Fields(cues[i].Text)) {
+					dropped[i] = true
+				} else {
+					dropped[j] = true
+				}
+				duplicates++
+			}
+		}
+	}
+
+	merged := make([]cue, 0, len(cues))
+	for i, c := range cues {
+		if !dropped[i] {
+			merged = append(merged, c)
+		}
+	}
+
+	if p.Overlap == OverlapTrim {
+		for i := 0; i+1 < len(merged); i++ {
+			next := merged[i+1]
+			if next.speaker != merged[i].speaker && next.Start < merged[i].End && next.Start-merged[i].Start >= minTrimmedCue {
+				merged[i].End = next.Start
+			}
+		}
+	}
+	return merged, duplicates
+}
+
+// wordOverlap returns the share of the words of the shorter text that the other text also has
+func wordOverlap(a, b string) float64 {
+	wordsA, wordsB := words(a), words(b)
+	if len(wordsA) == 0 || len(wordsB) == 0 {
+		return 0
+	}
+	if len(wordsA) > len(wordsB) {
+		wordsA, wordsB = wordsB, wordsA
+	}
+	set := map[string]bool{}
+	for _, w := range wordsB {
+		set[w] = true
+	}
+	shared := 0
+	for _, w := range wordsA {
+		if set[w] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(wordsA))
+}
+
+// words returns the lower-case words of a text without punctuation
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !(r == '\'' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r > 127)
+	})
+}
+
+// formatSRT writes cues as an SRT file, each starting with its speaker's name
+func formatSRT(cues []cue) string {
+	var b strings.Builder
+	for i, c := range cues {
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s: %s\n\n", i+1, utils.FormatSRTTimestamp(c.Start), utils.FormatSRTTimestamp(c.End), c.speaker, c.Text)
+	}
+	return b.String()
+}
+
+// speakerName returns the name of a track's speaker
+func speakerName(track Track) string {
+	if track.Speaker != "" {
+		return track.Speaker
+	}
+	return strings.TrimSuffix(filepath.Base(track.File), filepath.Ext(track.File))
+}
+
+// GetIO returns the module's input/output specification
+func (m *Module) GetIO() modules.ModuleIO {
+	return modules.ModuleIO{
+		RequiredInputs: []modules.ModuleInput{
+			{
+				Name:        "tracks",
+				Description: "Subtitle file and name of each speaker's track",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "output",
+				Description: "Path to output directory",
+				Type:        string(modules.InputTypeDirectory),
+			},
+		},
+		OptionalInputs: []modules.ModuleInput{
+			{
+				Name:        "overlap",
+				Description: "Overlapping cues of different speakers: keep or trim",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "dedupe",
+				Description: "Drop cues picked up on another speaker's microphone",
+				Type:        string(modules.InputTypeData),
+			},
+		},
+		ProducedOutputs: []modules.ModuleOutput{
+			{
+				Name:        "transcript",
+				Description: "Transcript of all speakers, each cue starting with its speaker's name",
+				Patterns:    []string{".srt"},
+				Type:        string(modules.OutputTypeFile),
+			},
+		},
+	}
+}
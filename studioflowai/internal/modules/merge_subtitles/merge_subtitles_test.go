@@ -0,0 +1,126 @@
+package mergesubtitles
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const hostSRT = `1
+00:00:01,000 --> 00:00:04,000
+Welcome to the show.
+
+2
+00:00:06,000 --> 00:00:09,000
+So why analog synths?
+
+3
+00:00:09,500 --> 00:00:10,500
+Because they sound warm.
+`
+
+// The guest's track started recording half a second after the host's
+const guestSRT = `1
+00:00:05,000 --> 00:00:08,000
+Thanks for having me.
+
+2
+00:00:09,000 --> 00:00:12,000
+Because they sound warm and alive.
+`
+
+func writeTracks(t *testing.T) (string, string, string) {
+	dir := t.TempDir()
+	host := filepath.Join(dir, "host.srt")
+	guest := filepath.Join(dir, "guest.srt")
+	require.NoError(t, os.WriteFile(host, []byte(hostSRT), 0644))
+	require.NoError(t, os.WriteFile(guest, []byte(guestSRT), 0644))
+	return host, guest, filepath.Join(dir, "output")
+}
+
+func TestModule_Name(t *testing.T) {
+	assert.Equal(t, "merge_subtitles", New().Name())
+}
+
+func TestExecute(t *testing.T) {
+	host, guest, output := writeTracks(t)
+
+	result, err := New().Execute(context.Background(), map[string]interface{}{
+		"tracks": []interface{}{
+			map[string]interface{}{"file": host, "speaker": "Ana"},
+			map[string]interface{}{"file": guest, "speaker": "Ben", "offset": 0.5},
+		},
+		"output": output,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Statistics["duplicates"])
+
+	data, err := os.ReadFile(result.Outputs["transcript"])
+	require.NoError(t, err)
+	cues, err := utils.ParseSRT(string(data))
+	require.NoError(t, err)
+	require.Len(t, cues, 4)
+	assert.Equal(t, "Ana: Welcome to the show.", cues[0].Text)
+	assert.Equal(t, "Ben: Thanks for having me.", cues[1].Text)
+	assert.Equal(t, "5.5s", cues[1].Start.String())
+	assert.Equal(t, "Ana: So why analog synths?", cues[2].Text)
+	// The host's microphone picked up the end of the guest's answer
+	assert.Equal(t, "Ben: Because they sound warm and alive.", cues[3].Text)
+}
+
+func TestExecute_Trim(t *testing.T) {
+	host, guest, output := writeTracks(t)
+
+	result, err := New().Execute(context.Background(), map[string]interface{}{
+		"tracks":  []interface{}{map[string]interface{}{"file": host}, map[string]interface{}{"file": guest}},
+		"output":  output,
+		"overlap": "trim",
+		"dedupe":  false,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Statistics["duplicates"])
+
+	data, err := os.ReadFile(result.Outputs["transcript"])
+	require.NoError(t, err)
+	cues, err := utils.ParseSRT(string(data))
+	require.NoError(t, err)
+	require.Len(t, cues, 5)
+	assert.Equal(t, "guest: Thanks for having me.", cues[1].Text)
+	assert.Equal(t, "6s", cues[1].End.String(), "ends when the host speaks")
+	assert.Equal(t, "host: So why analog synths?", cues[2].Text)
+	assert.Equal(t, "9s", cues[2].End.String())
+}
+
+func TestValidate(t *testing.T) {
+	host, guest, output := writeTracks(t)
+	m := New()
+
+	assert.NoError(t, m.Validate(map[string]interface{}{
+		"tracks": []interface{}{map[string]interface{}{"file": host}, map[string]interface{}{"file": guest}},
+		"output": output,
+	}))
+	assert.ErrorContains(t, m.Validate(map[string]interface{}{
+		"tracks": []interface{}{map[string]interface{}{"file": host}},
+		"output": output,
+	}), "at least two speakers")
+	assert.ErrorContains(t, m.Validate(map[string]interface{}{
+		"tracks": []interface{}{map[string]interface{}{"file": host, "speaker": "Ana"}, map[string]interface{}{"file": guest, "speaker": "Ana"}},
+		"output": output,
+	}), "listed twice")
+	assert.ErrorContains(t, m.Validate(map[string]interface{}{
+		"tracks":  []interface{}{map[string]interface{}{"file": host}, map[string]interface{}{"file": guest}},
+		"output":  output,
+		"overlap": "merge",
+	}), "unsupported overlap")
+}
+
+func TestWordOverlap(t *testing.T) {
+	assert.Equal(t, 1.0, wordOverlap("Because they sound warm.", "because they sound warm and alive"))
+	assert.Equal(t, 0.0, wordOverlap("Hello", ""))
+	assert.InDelta(t, 0.5, wordOverlap("so why analog synths", "why not"), 1e-9, "shares one of the two words of the shorter text")
+}
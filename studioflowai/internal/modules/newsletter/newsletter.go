@@ -0,0 +1,547 @@
+package newsletter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	modules "github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	chatgpt "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/chatgpt"
+	newslettersvc "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/newsletter"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+
+	"gopkg.in/yaml.v3"
+)
+
+// contextKey is a type for context keys
+type contextKey string
+
+const (
+	// ChatGPTServiceKey is the context key for the ChatGPT service
+	ChatGPTServiceKey = contextKey("chatgpt_service")
+	// PublisherKey is the context key for the newsletter publisher
+	PublisherKey = contextKey("newsletter_publisher")
+)
+
+// Module implements newsletter draft generation
+type Module struct{}
+
+// Params contains the parameters for newsletter generation
+type Params struct {
+	Input            string                 `json:"input"`                                              // Path to episode summary (SNS content YAML or transcript)
+	Output           string                 `json:"output"`                                             // Path to output directory
+	ShortsFile       string                 `json:"shortsFile"`                                         // Path to shorts_suggestions.yaml (optional)
+	TopShorts        int                    `json:"topShorts" default:"3"`                              // Number of shorts to feature (default: 3)
+	OutputFileName   string                 `json:"outputFileName" default:"newsletter"`                // Custom output file name (without extension, default: "newsletter")
+	Model            string                 `json:"model" default:"gpt-4o"`                             // OpenAI model to use (default: "gpt-4o")
+	FallbackModels   []string               `json:"fallbackModels"`                                     // Models tried in order when the primary model fails or returns an invalid draft
+	Temperature      float64                `json:"temperature" default:"0.7"`                          // Model temperature (default: 0.7)
+	MaxTokens        int                    `json:"maxTokens" default:"4000"`                           // Maximum tokens for the response (default: 4000)
+	RequestTimeoutMS int                    `json:"requestTimeoutMs" default:"120000"`                  // API request timeout in milliseconds (default: 120000)
+	Language         string                 `json:"language" default:"Spanish"`                         // Language for the newsletter (default: "Spanish")
+	SubjectVariants  int                    `json:"subjectVariants" default:"3"`                        // Number of subject line variants (default: 3)
+	EpisodeURL       string                 `json:"episodeUrl"`                                         // Link to the full episode (optional)
+	Publish          string                 `json:"publish"`                                            // Push the draft to "mailchimp" or "buttondown" (optional)
+	PromptFilePath   string                 `json:"promptFilePath" default:"./prompts/newsletter.yaml"` // Path to custom prompt YAML file (default: "./prompts/newsletter.yaml")
+	Metadata         map[string]interface{} `json:"metadata"`                                           // Episode details (guest, episode number, recording date, links) for the prompt and draft
+	MetadataFile     string                 `json:"metadataFile"`                                       // YAML file with episode details; inline metadata wins (optional)
+}
+
+// Draft is the generated newsletter content
+type Draft struct {
+	SubjectLines []string               `yaml:"subject_lines"`
+	PreviewText  string                 `yaml:"preview_text"`
+	BodyMarkdown string                 `yaml:"body_markdown"`
+	BodyHTML     string                 `yaml:"body_html"`
+	Provider     string                 `yaml:"provider,omitempty"`
+	DraftID      string                 `yaml:"draft_id,omitempty"`
+	Episode      map[string]interface{} `yaml:"episode,omitempty"`
+}
+
+// PromptData represents the structure of a YAML prompt template
+type PromptData struct {
+	Title       string `yaml:"title"`
+	Role        string `yaml:"role"`
+	Prompt      string `yaml:"prompt"`
+	Description string `yaml:"description"`
+}
+
+// New creates a new newsletter module
+func New() modules.Module {
+	return &Module{}
+}
+
+// Name returns the module name
+func (m *Module) Name() string {
+	return "newsletter"
+}
+
+// ParamsTemplate returns the module's parameter struct, used to validate and document workflows
+func (m *Module) ParamsTemplate() interface{} {
+	return Params{}
+}
+
+// Validate checks if the parameters are valid
+func (m *Module) Validate(params map[string]interface{}) error {
+	var p Params
+	if err := modules.ParseParams(params, &p); err != nil {
+		return err
+	}
+
+	// Validate input path
+	if err := utils.ValidateInputPath(p.Input, p.Output, ""); err != nil {
+		return err
+	}
+
+	// Validate output path
+	if err := utils.ValidateOutputPath(p.Output); err != nil {
+		return err
+	}
+
+	// Check if the API key is set - just warn but don't error
+	if !chatgpt.IsAPIKeySet() {
+		utils.LogWarning("OPENAI_API_KEY environment variable is not set. A placeholder file will be generated.")
+	}
+
+	if p.PromptFilePath != "" {
+		if _, err := os.Stat(p.PromptFilePath); os.IsNotExist(err) {
+			return fmt.Errorf("prompt template file %s does not exist", p.PromptFilePath)
+		}
+	}
+
+	if p.TopShorts < 0 {
+		return fmt.Errorf("topShorts cannot be negative: %d", p.TopShorts)
+	}
+	if p.SubjectVariants < 0 {
+		return fmt.Errorf("subjectVariants cannot be negative: %d", p.SubjectVariants)
+	}
+
+	switch strings.ToLower(p.Publish) {
+	case "", newslettersvc.ProviderMailchimp, newslettersvc.ProviderButtondown:
+	default:
+		return fmt.Errorf("unsupported publish provider: %s (supported: %s, %s)",
+			p.Publish, newslettersvc.ProviderMailchimp, newslettersvc.ProviderButtondown)
+	}
+
+	if _, err := utils.ResolveEpisodeMetadata(p.Metadata, p.MetadataFile); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Execute generates the newsletter draft and optionally pushes it to an email platform
+func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (modules.ModuleResult, error) {
+	var p Params
+	if err := modules.ParseParams(params, &p); err != nil {
+		return modules.ModuleResult{}, err
+	}
+
+	// Set default values
+	if p.TopShorts == 0 {
+		p.TopShorts = 3
+	}
+	if p.OutputFileName == "" {
+		p.OutputFileName = "newsletter"
+	}
+	if p.Model == "" {
+		p.Model = "gpt-4o"
+	}
+	if p.Temperature == 0 {
+		p.Temperature = 0.7
+	}
+	if p.MaxTokens == 0 {
+		p.MaxTokens = 4000
+	}
+	if p.RequestTimeoutMS == 0 {
+		p.RequestTimeoutMS = 120000
+	}
+	if p.Language == "" {
+		p.Language = "Spanish"
+	}
+	if p.SubjectVariants == 0 {
+		p.SubjectVariants = 3
+	}
+	if p.PromptFilePath == "" {
+		p.PromptFilePath = utils.ResolvePromptPath("./prompts/newsletter.yaml")
+	}
+
+	// Create output directory if it doesn't exist
+	if err := utils.EnsureDir(p.Output); err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// Resolve the input paths if they contain ${output}
+	resolvedInput := utils.ResolveOutputPath(p.Input, p.Output)
+	summary, err := utils.ReadTextFile(resolvedInput)
+	if err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to read episode summary: %w", err)
+	}
+
+	// Episode details from the step win over those carried in a transcript's front matter
+	frontMatter, summary := utils.SplitEpisodeFrontMatter(summary)
+	metadata, err := utils.ResolveEpisodeMetadata(p.Metadata, p.MetadataFile)
+	if err != nil {
+		return modules.ModuleResult{}, err
+	}
+	p.Metadata = utils.MergeEpisodeMetadata(frontMatter, metadata)
+
+	var shorts []utils.ShortClip
+	if p.ShortsFile != "" {
+		shortsPath := utils.ResolveOutputPath(p.ShortsFile, p.Output)
+		shortsData, err := utils.ReadShortsFile(shortsPath)
+		if err != nil {
+			return modules.ModuleResult{}, fmt.Errorf("failed to read shorts file: %w", err)
+		}
+		shorts = shortsData.Shorts
+		if len(shorts) > p.TopShorts {
+			shorts = shorts[:p.TopShorts]
+		}
+	}
+
+	var draft *Draft
+	usedModel := p.Model
+	if !chatgpt.IsAPIKeySet() {
+		utils.LogWarningContext(ctx, "No API key set - generating placeholder newsletter")
+		draft = placeholderDraft(resolvedInput)
+	} else {
+		draft, usedModel, err = m.generateDraft(ctx, summary, shorts, p)
+		if err != nil {
+			return modules.ModuleResult{}, err
+		}
+	}
+	if len(p.Metadata) > 0 {
+		draft.Episode = p.Metadata
+	}
+
+	// Push to the email platform before writing so the draft ID is recorded
+	if p.Publish != "" {
+		publisher, err := m.getPublisher(ctx, p.Publish)
+		if err != nil {
+			return modules.ModuleResult{}, fmt.Errorf("failed to initialize %s publisher: %w", p.Publish, err)
+		}
+
+		draftID, err := publisher.CreateDraft(ctx, newslettersvc.Draft{
+			Subject:     draft.SubjectLines[0],
+			PreviewText: draft.PreviewText,
+			HTML:        draft.BodyHTML,
+			Markdown:    draft.BodyMarkdown,
+		})
+		if err != nil {
+			return modules.ModuleResult{}, fmt.Errorf("failed to push newsletter draft: %w", err)
+		}
+		draft.Provider = publisher.Name()
+		draft.DraftID = draftID
+		utils.LogSuccessContext(ctx, "Created %s draft %s", publisher.Name(), draftID)
+	}
+
+	outputs, err := writeDraft(draft, p.Output, p.OutputFileName)
+	if err != nil {
+		return modules.ModuleResult{}, err
+	}
+
+	utils.LogSuccessContext(ctx, "Generated newsletter draft -> %s", outputs["newsletter"])
+
+	stats := map[string]interface{}{
+		"model":           usedModel,
+		"language":        p.Language,
+		"inputFile":       resolvedInput,
+		"featuredShorts":  len(shorts),
+		"subjectVariants": len(draft.SubjectLines),
+		"processTime":     time.Now().Format(time.RFC3339),
+	}
+	if draft.DraftID != "" {
+		stats["provider"] = draft.Provider
+		stats["draftId"] = draft.DraftID
+	}
+
+	return modules.ModuleResult{
+		Outputs:    outputs,
+		Metadata:   map[string]interface{}{"model": usedModel},
+		Statistics: stats,
+		Stats:      modules.Stats{Items: 1},
+	}, nil
+}
+
+// GetIO returns the module's input/output specification
+func (m *Module) GetIO() modules.ModuleIO {
+	return modules.ModuleIO{
+		RequiredInputs: []modules.ModuleInput{
+			{
+				Name:        "input",
+				Description: "Path to episode summary (SNS content YAML or transcript)",
+				Patterns:    []string{".yaml", ".txt"},
+				Type:        string(modules.InputTypeFile),
+			},
+			{
+				Name:        "output",
+				Description: "Path to output directory",
+				Type:        string(modules.InputTypeDirectory),
+			},
+		},
+		OptionalInputs: []modules.ModuleInput{
+			{
+				Name:        "shortsFile",
+				Description: "Path to shorts suggestions YAML to feature in the newsletter",
+				Patterns:    []string{".yaml"},
+				Type:        string(modules.InputTypeFile),
+			},
+			{
+				Name:        "topShorts",
+				Description: "Number of shorts to feature",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "outputFileName",
+				Description: "Custom output filename",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "language",
+				Description: "Language for the newsletter",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "episodeUrl",
+				Description: "Link to the full episode",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "publish",
+				Description: "Push the draft to mailchimp or buttondown",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "promptFilePath",
+				Description: "Path to custom prompt YAML file",
+				Type:        string(modules.InputTypeFile),
+			},
+		},
+		ProducedOutputs: []modules.ModuleOutput{
+			{
+				Name:        "newsletter",
+				Description: "Newsletter draft with subject line variants and preview text",
+				Patterns:    []string{".yaml"},
+				Type:        string(modules.OutputTypeFile),
+			},
+			{
+				Name:        "newsletter_markdown",
+				Description: "Newsletter body in Markdown",
+				Patterns:    []string{".md"},
+				Type:        string(modules.OutputTypeFile),
+			},
+			{
+				Name:        "newsletter_html",
+				Description: "Newsletter body in HTML",
+				Patterns:    []string{".html"},
+				Type:        string(modules.OutputTypeFile),
+			},
+		},
+	}
+}
+
+// generateDraft asks ChatGPT for the newsletter draft and returns it with the model that wrote it
+func (m *Module) generateDraft(ctx context.Context, summary string, shorts []utils.ShortClip, p Params) (*Draft, string, error) {
+	promptData := getPromptTemplate(ctx, p.PromptFilePath)
+
+	var prompt strings.Builder
+	prompt.WriteString(strings.TrimSpace(promptData.Prompt))
+	prompt.WriteString("\n\n")
+	prompt.WriteString(fmt.Sprintf("Language: %s\n", p.Language))
+	prompt.WriteString(fmt.Sprintf("Subject line variants: %d\n", p.SubjectVariants))
+	if p.EpisodeURL != "" {
+		prompt.WriteString(fmt.Sprintf("Full episode link: %s\n", p.EpisodeURL))
+	}
+	if details := utils.EpisodeMetadataPrompt(p.Metadata); details != "" {
+		prompt.WriteString("\n" + details)
+	}
+	prompt.WriteString("\nEpisode summary:\n")
+	prompt.WriteString(summary)
+	prompt.WriteString("\n")
+
+	if len(shorts) > 0 {
+		prompt.WriteString("\nTop shorts to feature:\n")
+		for i, short := range shorts {
+			prompt.WriteString(fmt.Sprintf("%d. %s (%s - %s): %s\n", i+1, short.Title, short.StartTime, short.EndTime, short.Description))
+		}
+	}
+
+	messages := []chatgpt.ChatMessage{
+		{
+			Role:    "system",
+			Content: fmt.Sprintf("You are a %s. You write engaging email newsletters that drive readers to watch new episodes.", promptData.Role),
+		},
+		{
+			Role:    "user",
+			Content: prompt.String(),
+		},
+	}
+
+	chatGPT, err := m.getChatGPTService(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to initialize ChatGPT service: %w", err)
+	}
+
+	utils.LogInfoContext(ctx, "Generating newsletter draft using %s model...", p.Model)
+	// Each attempt is bounded by RequestTimeoutMS; drafts that fail to parse move on to the next model
+	var draft *Draft
+	completion, err := chatgpt.CompleteWithFallback(ctx, chatGPT, messages, chatgpt.CompletionOptions{
+		Model:            p.Model,
+		Temperature:      p.Temperature,
+		MaxTokens:        p.MaxTokens,
+		RequestTimeoutMS: p.RequestTimeoutMS,
+	}, chatgpt.FallbackChain{Models: p.FallbackModels}, func(response string) error {
+		var err error
+		draft, err = parseDraftResponse(response)
+		return err
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("ChatGPT API request failed: %w", err)
+	}
+
+	return draft, completion.Model, nil
+}
+
+// parseDraftResponse parses the YAML draft returned by ChatGPT
+func parseDraftResponse(response string) (*Draft, error) {
+	content := strings.TrimSpace(response)
+	if strings.HasPrefix(content, "```") {
+		if idx := strings.Index(content, "\n"); idx != -1 {
+			content = content[idx+1:]
+		}
+		content = strings.TrimSuffix(strings.TrimSpace(content), "```")
+	}
+
+	var draft Draft
+	if err := yaml.Unmarshal([]byte(content), &draft); err != nil {
+		return nil, fmt.Errorf("failed to parse newsletter draft: %w", err)
+	}
+
+	if len(draft.SubjectLines) == 0 {
+		return nil, fmt.Errorf("newsletter draft has no subject lines")
+	}
+	if strings.TrimSpace(draft.BodyMarkdown) == "" && strings.TrimSpace(draft.BodyHTML) == "" {
+		return nil, fmt.Errorf("newsletter draft has no body")
+	}
+
+	return &draft, nil
+}
+
+// writeDraft writes the draft YAML plus the Markdown and HTML bodies
+func writeDraft(draft *Draft, outputDir, baseName string) (map[string]string, error) {
+	outputs := map[string]string{}
+
+	yamlData, err := yaml.Marshal(draft)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate YAML: %w", err)
+	}
+
+	draftPath := filepath.Join(outputDir, baseName+".yaml")
+	if err := utils.AtomicWriteFile(draftPath, yamlData, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write newsletter draft: %w", err)
+	}
+	outputs["newsletter"] = draftPath
+
+	if strings.TrimSpace(draft.BodyMarkdown) != "" {
+		mdPath := filepath.Join(outputDir, baseName+".md")
+		if err := utils.WriteTextFile(mdPath, strings.TrimSpace(draft.BodyMarkdown)+"\n"); err != nil {
+			return nil, fmt.Errorf("failed to write newsletter markdown: %w", err)
+		}
+		outputs["newsletter_markdown"] = mdPath
+	}
+
+	if strings.TrimSpace(draft.BodyHTML) != "" {
+		htmlPath := filepath.Join(outputDir, baseName+".html")
+		if err := utils.WriteTextFile(htmlPath, strings.TrimSpace(draft.BodyHTML)+"\n"); err != nil {
+			return nil, fmt.Errorf("failed to write newsletter HTML: %w", err)
+		}
+		outputs["newsletter_html"] = htmlPath
+	}
+
+	return outputs, nil
+}
+
+// placeholderDraft returns example output used when no API key is configured
+func placeholderDraft(inputPath string) *Draft {
+	return &Draft{
+		SubjectLines: []string{
+			"MOCK OUTPUT - No OPENAI_API_KEY set",
+			"New episode is live",
+			"You don't want to miss this one",
+		},
+		PreviewText:  "Set the OPENAI_API_KEY environment variable to generate a real newsletter.",
+		BodyMarkdown: "# New episode\n\nSimulated newsletter body generated from " + inputPath + ".\n",
+		BodyHTML:     "<h1>New episode</h1>\n<p>Simulated newsletter body generated from " + inputPath + ".</p>\n",
+	}
+}
+
+// getPromptTemplate loads the prompt template from file, falling back to the default
+func getPromptTemplate(ctx context.Context, promptFilePath string) PromptData {
+	if data, err := os.ReadFile(promptFilePath); err == nil {
+		var promptData PromptData
+		if err := yaml.Unmarshal(data, &promptData); err == nil && strings.TrimSpace(promptData.Prompt) != "" {
+			if promptData.Role == "" {
+				promptData.Role = defaultRole
+			}
+			utils.LogDebugContext(ctx, "Using custom newsletter prompt template from YAML file: %s", promptFilePath)
+			return promptData
+		}
+		utils.LogWarningContext(ctx, "Failed to parse newsletter prompt %s, falling back to default", promptFilePath)
+	}
+
+	utils.LogDebugContext(ctx, "Using default newsletter prompt template")
+	return PromptData{
+		Title:  "Episode Newsletter Draft",
+		Role:   defaultRole,
+		Prompt: defaultPrompt,
+	}
+}
+
+const defaultRole = "email marketing copywriter"
+
+const defaultPrompt = `Write an email newsletter announcing a new episode, based on the episode summary and featured shorts below.
+
+## REQUIREMENTS:
+1. Provide several subject line variants (max 60 characters each) suitable for A/B testing.
+2. Provide preview text (max 110 characters) that complements, not repeats, the subject.
+3. The body must open with a short hook, summarize the key takeaways as a bulleted list, feature each short with a one-line teaser, and end with a clear call to action to watch the full episode.
+4. Provide the body both as Markdown and as simple, email-safe HTML (inline styles only, no scripts).
+
+## REQUIRED YAML FORMAT (USE EXACTLY THIS FORMAT):
+subject_lines:
+  - "Subject variant 1"
+  - "Subject variant 2"
+preview_text: "Preview text"
+body_markdown: |
+  Markdown body
+body_html: |
+  <p>HTML body</p>
+
+## IMPORTANT: Your response MUST be only the YAML, without prior explanations or code fences.`
+
+// getChatGPTService returns a ChatGPT service from context or creates a new one
+func (m *Module) getChatGPTService(ctx context.Context) (chatgpt.ChatGPTServicer, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("context cannot be nil")
+	}
+
+	// Check if service is provided in context
+	if service, ok := ctx.Value(ChatGPTServiceKey).(chatgpt.ChatGPTServicer); ok {
+		return service, nil
+	}
+
+	// Create new service if not in context
+	return chatgpt.NewChatGPTService()
+}
+
+// getPublisher returns a newsletter publisher from context or creates one for the provider
+func (m *Module) getPublisher(ctx context.Context, provider string) (newslettersvc.Publisher, error) {
+	if publisher, ok := ctx.Value(PublisherKey).(newslettersvc.Publisher); ok {
+		return publisher, nil
+	}
+
+	return newslettersvc.NewPublisher(provider)
+}
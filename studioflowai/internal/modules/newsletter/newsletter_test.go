@@ -0,0 +1,284 @@
+package newsletter
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	services "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/chatgpt"
+	chatgptmocks "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/chatgpt/mocks"
+	newslettersvc "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/newsletter"
+	newslettermocks "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/newsletter/mocks"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/snapshot"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const mockDraftResponse = "```yaml\nsubject_lines:\n  - \"New episode: security secrets\"\n  - \"What nobody tells you about security\"\npreview_text: \"Three takeaways from this week's guest\"\nbody_markdown: |\n  # New episode\n\n  - Takeaway one\nbody_html: |\n  <h1>New episode</h1>\n```"
+
+const shortsYAML = `sourceVideo: video.mp4
+shorts:
+  - title: "First short"
+    startTime: "00:01:00"
+    endTime: "00:01:45"
+    description: "First description"
+  - title: "Second short"
+    startTime: "00:05:00"
+    endTime: "00:05:50"
+    description: "Second description"
+  - title: "Third short"
+    startTime: "00:09:00"
+    endTime: "00:09:40"
+    description: "Third description"
+`
+
+func TestExecute(t *testing.T) {
+	tempDir := t.TempDir()
+	summaryFile := filepath.Join(tempDir, "transcript_SNS.yaml")
+	require.NoError(t, os.WriteFile(summaryFile, []byte("title: Episode summary"), 0644))
+	shortsFile := filepath.Join(tempDir, "shorts_suggestions.yaml")
+	require.NoError(t, os.WriteFile(shortsFile, []byte(shortsYAML), 0644))
+	outputDir := filepath.Join(tempDir, "output")
+
+	tests := []struct {
+		name             string
+		params           map[string]interface{}
+		apiKey           string
+		setupChatGPT     func(*chatgptmocks.MockChatGPTServicer)
+		setupPublisher   func(*newslettermocks.MockPublisher)
+		expectedOutputs  []string
+		expectedDraftID  string
+		expectInDraftYML string
+		errorContains    string
+	}{
+		{
+			name: "successful generation with top shorts",
+			params: map[string]interface{}{
+				"input":      summaryFile,
+				"output":     outputDir,
+				"shortsFile": shortsFile,
+				"topShorts":  2,
+				"episodeUrl": "https://youtu.be/example",
+			},
+			apiKey: "test-api-key",
+			setupChatGPT: func(m *chatgptmocks.MockChatGPTServicer) {
+				m.EXPECT().GetContent(
+					mock.Anything,
+					mock.MatchedBy(func(messages []services.ChatMessage) bool {
+						content := messages[1].Content
+						return strings.Contains(content, "Episode summary") &&
+							strings.Contains(content, "First short") &&
+							strings.Contains(content, "Second short") &&
+							!strings.Contains(content, "Third short") &&
+							strings.Contains(content, "https://youtu.be/example")
+					}),
+					mock.Anything,
+				).Return(mockDraftResponse, nil)
+			},
+			expectedOutputs:  []string{"newsletter", "newsletter_markdown", "newsletter_html"},
+			expectInDraftYML: "What nobody tells you about security",
+		},
+		{
+			name: "push draft to provider",
+			params: map[string]interface{}{
+				"input":   summaryFile,
+				"output":  outputDir,
+				"publish": "buttondown",
+			},
+			apiKey: "test-api-key",
+			setupChatGPT: func(m *chatgptmocks.MockChatGPTServicer) {
+				m.EXPECT().GetContent(mock.Anything, mock.Anything, mock.Anything).Return(mockDraftResponse, nil)
+			},
+			setupPublisher: func(m *newslettermocks.MockPublisher) {
+				m.EXPECT().CreateDraft(mock.Anything, mock.MatchedBy(func(d newslettersvc.Draft) bool {
+					return d.Subject == "New episode: security secrets" && strings.Contains(d.HTML, "<h1>")
+				})).Return("draft-123", nil)
+				m.EXPECT().Name().Return("buttondown")
+			},
+			expectedOutputs:  []string{"newsletter", "newsletter_markdown", "newsletter_html"},
+			expectedDraftID:  "draft-123",
+			expectInDraftYML: "draft_id: draft-123",
+		},
+		{
+			name: "publish failure",
+			params: map[string]interface{}{
+				"input":   summaryFile,
+				"output":  outputDir,
+				"publish": "mailchimp",
+			},
+			apiKey: "test-api-key",
+			setupChatGPT: func(m *chatgptmocks.MockChatGPTServicer) {
+				m.EXPECT().GetContent(mock.Anything, mock.Anything, mock.Anything).Return(mockDraftResponse, nil)
+			},
+			setupPublisher: func(m *newslettermocks.MockPublisher) {
+				m.EXPECT().CreateDraft(mock.Anything, mock.Anything).Return("", errors.New("unauthorized"))
+			},
+			errorContains: "unauthorized",
+		},
+		{
+			name: "no api key writes placeholder",
+			params: map[string]interface{}{
+				"input":  summaryFile,
+				"output": outputDir,
+			},
+			expectedOutputs:  []string{"newsletter", "newsletter_markdown", "newsletter_html"},
+			expectInDraftYML: "MOCK OUTPUT",
+		},
+		{
+			name: "invalid response",
+			params: map[string]interface{}{
+				"input":  summaryFile,
+				"output": outputDir,
+			},
+			apiKey: "test-api-key",
+			setupChatGPT: func(m *chatgptmocks.MockChatGPTServicer) {
+				m.EXPECT().GetContent(mock.Anything, mock.Anything, mock.Anything).Return("preview_text: only preview", nil)
+			},
+			errorContains: "no subject lines",
+		},
+		{
+			name: "missing shorts file",
+			params: map[string]interface{}{
+				"input":      summaryFile,
+				"output":     outputDir,
+				"shortsFile": filepath.Join(tempDir, "missing.yaml"),
+			},
+			apiKey:        "test-api-key",
+			errorContains: "failed to read shorts file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OPENAI_API_KEY", tt.apiKey)
+
+			chatGPT := chatgptmocks.NewMockChatGPTServicer(t)
+			if tt.setupChatGPT != nil {
+				tt.setupChatGPT(chatGPT)
+			}
+			publisher := newslettermocks.NewMockPublisher(t)
+			if tt.setupPublisher != nil {
+				tt.setupPublisher(publisher)
+			}
+
+			ctx := context.WithValue(context.Background(), ChatGPTServiceKey, chatGPT)
+			ctx = context.WithValue(ctx, PublisherKey, publisher)
+
+			result, err := New().Execute(ctx, tt.params)
+			if tt.errorContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorContains)
+				return
+			}
+
+			require.NoError(t, err)
+			for _, name := range tt.expectedOutputs {
+				assert.FileExists(t, result.Outputs[name])
+			}
+			if tt.expectedDraftID != "" {
+				assert.Equal(t, tt.expectedDraftID, result.Statistics["draftId"])
+			}
+
+			content, err := os.ReadFile(result.Outputs["newsletter"])
+			require.NoError(t, err)
+			assert.Contains(t, string(content), tt.expectInDraftYML)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tempDir := t.TempDir()
+	inputFile := filepath.Join(tempDir, "summary.txt")
+	require.NoError(t, os.WriteFile(inputFile, []byte("summary"), 0644))
+
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name:   "valid parameters",
+			params: map[string]interface{}{"input": inputFile, "output": tempDir, "publish": "mailchimp"},
+		},
+		{
+			name:    "missing input",
+			params:  map[string]interface{}{"output": tempDir},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported provider",
+			params:  map[string]interface{}{"input": inputFile, "output": tempDir, "publish": "substack"},
+			wantErr: true,
+		},
+		{
+			name:    "negative top shorts",
+			params:  map[string]interface{}{"input": inputFile, "output": tempDir, "topShorts": -1},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := New().Validate(tt.params)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestParseDraftResponse(t *testing.T) {
+	draft, err := parseDraftResponse(mockDraftResponse)
+	require.NoError(t, err)
+	assert.Len(t, draft.SubjectLines, 2)
+	assert.Equal(t, "Three takeaways from this week's guest", draft.PreviewText)
+	assert.Contains(t, draft.BodyMarkdown, "# New episode")
+
+	_, err = parseDraftResponse("subject_lines: [\"Only subject\"]")
+	assert.ErrorContains(t, err, "no body")
+
+	_, err = parseDraftResponse("subject_lines: [unterminated")
+	assert.Error(t, err)
+}
+
+// TestGolden records the prompt built with the default template and the draft written from a
+// fixture response; rerun with UPDATE_SNAPSHOTS=1 after an intended change
+func TestGolden(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "test-api-key")
+	tempDir := t.TempDir()
+	summaryFile := filepath.Join(tempDir, "transcript_SNS.yaml")
+	require.NoError(t, os.WriteFile(summaryFile, []byte("title: Why analog synths sound warm\ndescription: A producer explains what makes analog gear special.\n"), 0644))
+	shortsFile := filepath.Join(tempDir, "shorts_suggestions.yaml")
+	require.NoError(t, os.WriteFile(shortsFile, []byte(shortsYAML), 0644))
+
+	var sent []services.ChatMessage
+	chatGPT := chatgptmocks.NewMockChatGPTServicer(t)
+	chatGPT.EXPECT().GetContent(mock.Anything, mock.Anything, mock.Anything).
+		Run(func(_ context.Context, messages []services.ChatMessage, _ services.CompletionOptions) {
+			sent = messages
+		}).
+		Return(mockDraftResponse, nil)
+
+	ctx := context.WithValue(context.Background(), ChatGPTServiceKey, chatGPT)
+	result, err := New().Execute(ctx, map[string]interface{}{
+		"input":      summaryFile,
+		"output":     filepath.Join(tempDir, "output"),
+		"shortsFile": shortsFile,
+		"topShorts":  2,
+		"episodeUrl": "https://youtu.be/example",
+	})
+	require.NoError(t, err)
+
+	snapshot.MatchMessages(t, "prompt.txt", sent)
+	for _, name := range []string{"newsletter", "newsletter_markdown", "newsletter_html"} {
+		data, err := os.ReadFile(result.Outputs[name])
+		require.NoError(t, err)
+		snapshot.Match(t, filepath.Base(result.Outputs[name]), string(data))
+	}
+}
@@ -0,0 +1,481 @@
+package normalizevideo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	modules "github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+// execCommand allows us to mock exec.Command in tests
+var execCommand = utils.CommandContext
+
+// supportedExtensions lists the source video formats accepted by the module
+var supportedExtensions = []string{".mp4", ".mov", ".mkv", ".m4v", ".webm", ".avi"}
+
+// Module implements video ingest normalization
+type Module struct{}
+
+// Params contains the parameters for video normalization
+type Params struct {
+	Input           string  `json:"input"`                           // Path to input video file
+	Output          string  `json:"output"`                          // Path to output directory
+	OutputName      string  `json:"outputName"`                      // Custom output filename (default: "<input>_normalized.mp4")
+	FrameRate       float64 `json:"frameRate"`                       // Constant frame rate of the mezzanine (default: the source's base frame rate)
+	MaxWidth        int     `json:"maxWidth" default:"1920"`         // Maximum output width in pixels (default: 1920)
+	MaxHeight       int     `json:"maxHeight" default:"1080"`        // Maximum output height in pixels (default: 1080)
+	CRF             int     `json:"crf" default:"18"`                // x264 constant rate factor, lower is better quality (default: 18)
+	Preset          string  `json:"preset" default:"medium"`         // x264 encoding preset (default: "medium")
+	AudioSampleRate int     `json:"audioSampleRate" default:"48000"` // Audio sample rate in Hz (default: 48000)
+	ForceTranscode  bool    `json:"forceTranscode"`                  // Transcode even if the source is already edit-friendly
+	QuietFlag       bool    `json:"quietFlag" default:"true"`        // Suppress ffmpeg output (default: true)
+}
+
+// VideoInfo holds the stream properties reported by ffprobe
+type VideoInfo struct {
+	Codec         string  // Video codec name (e.g. "h264", "hevc")
+	PixelFormat   string  // Pixel format (e.g. "yuv420p", "yuv420p10le")
+	Width         int     // Frame width in pixels
+	Height        int     // Frame height in pixels
+	RealFrameRate float64 // Base frame rate (r_frame_rate)
+	BaseRate      string  // Base frame rate as ffprobe reports it (e.g. "30000/1001")
+	AvgFrameRate  float64 // Average frame rate (avg_frame_rate)
+	AudioCodec    string  // Audio codec name, empty if there is no audio stream
+	ExtraStreams  int     // Streams besides the first video and audio ones, which a remux drops
+}
+
+// New creates a new normalize video module
+func New() modules.Module {
+	return &Module{}
+}
+
+// Name returns the module name
+func (m *Module) Name() string {
+	return "normalize_video"
+}
+
+// ParamsTemplate returns the module's parameter struct, used to validate and document workflows
+func (m *Module) ParamsTemplate() interface{} {
+	return Params{}
+}
+
+// Validate checks if the parameters are valid
+func (m *Module) Validate(params map[string]interface{}) error {
+	var p Params
+	if err := modules.ParseParams(params, &p); err != nil {
+		return err
+	}
+
+	// Validate input path
+	if err := utils.ValidateInputPath(p.Input, p.Output, ""); err != nil {
+		return err
+	}
+
+	// Validate output path
+	if err := utils.ValidateOutputPath(p.Output); err != nil {
+		return err
+	}
+
+	// Validate video file extension if input is a file
+	resolvedInput := utils.ResolveOutputPath(p.Input, p.Output)
+	if fileInfo, err := os.Stat(resolvedInput); err == nil && !fileInfo.IsDir() {
+		if err := utils.ValidateFileExtension(resolvedInput, supportedExtensions); err != nil {
+			return err
+		}
+	}
+
+	if p.OutputName != "" {
+		if err := utils.ValidateFileExtension(p.OutputName, []string{".mp4", ".mov"}); err != nil {
+			return err
+		}
+	}
+
+	if p.FrameRate < 0 || p.MaxWidth < 0 || p.MaxHeight < 0 {
+		return fmt.Errorf("frameRate, maxWidth and maxHeight must not be negative")
+	}
+	if p.CRF < 0 || p.CRF > 51 {
+		return fmt.Errorf("crf must be between 0 and 51, got %d", p.CRF)
+	}
+
+	// Validate FFmpeg dependencies
+	if err := utils.ValidateRequiredDependency("ffmpeg"); err != nil {
+		return err
+	}
+	if err := utils.ValidateRequiredDependency("ffprobe"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Execute probes the source video and remuxes or transcodes it into an edit-friendly mezzanine
+func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (modules.ModuleResult, error) {
+	var p Params
+	if err := modules.ParseParams(params, &p); err != nil {
+		return modules.ModuleResult{}, err
+	}
+
+	// Set default values
+	if p.MaxWidth == 0 {
+		p.MaxWidth = 1920
+	}
+	if p.MaxHeight == 0 {
+		p.MaxHeight = 1080
+	}
+	// crf 0 is lossless, so only an omitted crf takes the default
+	if _, exists := params["crf"]; !exists {
+		p.CRF = 18
+	}
+	if p.Preset == "" {
+		p.Preset = "medium"
+	}
+	if p.AudioSampleRate == 0 {
+		p.AudioSampleRate = 48000
+	}
+
+	// Default to quiet mode (no ffmpeg output) unless explicitly set to false
+	if _, exists := params["quietFlag"]; !exists {
+		p.QuietFlag = true
+	}
+
+	if p.Output == "" {
+		return modules.ModuleResult{}, fmt.Errorf("output directory path is required")
+	}
+
+	resolvedInput := utils.ResolveOutputPath(p.Input, p.Output)
+	if _, err := os.Stat(resolvedInput); err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to access input: %w", err)
+	}
+
+	if err := utils.EnsureDir(p.Output); err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	outputName := p.OutputName
+	if outputName == "" {
+		base := filepath.Base(resolvedInput)
+		outputName = strings.TrimSuffix(base, filepath.Ext(base)) + "_normalized.mp4"
+	}
+	outputPath := filepath.Join(p.Output, outputName)
+
+	info, err := probeVideo(ctx, resolvedInput)
+	if err != nil {
+		return modules.ModuleResult{}, err
+	}
+
+	reasons := normalizationReasons(info, p)
+	if len(reasons) == 0 && !p.ForceTranscode && canLink(resolvedInput, info) {
+		// A remux would only rewrite the same streams into the same container
+		method, err := utils.LinkFile(resolvedInput, outputPath)
+		if err != nil {
+			return modules.ModuleResult{}, fmt.Errorf("failed to place %s: %w", filepath.Base(resolvedInput), err)
+		}
+		utils.LogSuccessContext(ctx, "%s is already edit-friendly, placed at %s as a %s", filepath.Base(resolvedInput), outputPath, method)
+		return normalizeResult(outputPath, method, reasons, info, p), nil
+	}
+
+	action := "remux"
+	var args []string
+	if len(reasons) > 0 || p.ForceTranscode {
+		action = "transcode"
+		if len(reasons) > 0 {
+			utils.LogInfoContext(ctx, "Normalizing %s: %s", filepath.Base(resolvedInput), strings.Join(reasons, ", "))
+		}
+		args = transcodeArgs(resolvedInput, outputPath, targetFrameRate(info, p), p)
+	} else {
+		utils.LogVerboseContext(ctx, "%s is already edit-friendly, remuxing without re-encoding", filepath.Base(resolvedInput))
+		args = remuxArgs(resolvedInput, outputPath)
+	}
+
+	if p.QuietFlag {
+		args = append(args, "-loglevel", "error")
+	}
+
+	cmd := execCommand(ctx, "ffmpeg", args...)
+	var stderr bytes.Buffer
+	if p.QuietFlag {
+		cmd.Stderr = &stderr
+	} else {
+		cmd.Stdout = utils.LogWriter(ctx)
+		cmd.Stderr = os.Stderr
+	}
+	if err := utils.RunWatched(ctx, cmd); err != nil {
+		if stderr.Len() > 0 {
+			utils.LogErrorContext(ctx, "FFmpeg error: %s", stderr.String())
+		}
+		return modules.ModuleResult{}, fmt.Errorf("ffmpeg %s failed: %w", action, err)
+	}
+
+	utils.LogSuccessContext(ctx, "Normalized video written to %s", outputPath)
+	return normalizeResult(outputPath, action, reasons, info, p), nil
+}
+
+// normalizeResult describes the normalized video and what was done to the source
+func normalizeResult(outputPath, action string, reasons []string, info VideoInfo, p Params) modules.ModuleResult {
+	return modules.ModuleResult{
+		Outputs: map[string]string{
+			"video": outputPath,
+		},
+		Metadata: map[string]interface{}{
+			"action":        action,
+			"reasons":       reasons,
+			"sourceCodec":   info.Codec,
+			"sourcePixFmt":  info.PixelFormat,
+			"sourceWidth":   info.Width,
+			"sourceHeight":  info.Height,
+			"sourceAvgFps":  info.AvgFrameRate,
+			"targetFps":     parseFrameRate(targetFrameRate(info, p)),
+			"variableFrame": isVariableFrameRate(info),
+		},
+		Stats: modules.Stats{Items: 1},
+	}
+}
+
+// canLink reports whether an edit-friendly source can be used as the mezzanine as it is: an MP4
+// holding only the video and audio streams a remux would keep
+func canLink(path string, info VideoInfo) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return (ext == ".mp4" || ext == ".m4v") && info.ExtraStreams == 0
+}
+
+// probeVideo reads the first video and audio stream properties with ffprobe
+func probeVideo(ctx context.Context, path string) (VideoInfo, error) {
+	cmd := execCommand(ctx, "ffprobe",
+		"-v", "error",
+		"-show_entries", "stream=codec_type,codec_name,pix_fmt,width,height,r_frame_rate,avg_frame_rate",
+		"-of", "json",
+		path,
+	)
+	out, err := utils.OutputWatched(ctx, cmd)
+	if err != nil {
+		return VideoInfo{}, fmt.Errorf("failed to probe video: %w", err)
+	}
+
+	return parseProbeOutput(out)
+}
+
+// parseProbeOutput converts ffprobe JSON output into VideoInfo
+func parseProbeOutput(data []byte) (VideoInfo, error) {
+	var probe struct {
+		Streams []struct {
+			CodecType    string `json:"codec_type"`
+			CodecName    string `json:"codec_name"`
+			PixFmt       string `json:"pix_fmt"`
+			Width        int    `json:"width"`
+			Height       int    `json:"height"`
+			RFrameRate   string `json:"r_frame_rate"`
+			AvgFrameRate string `json:"avg_frame_rate"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return VideoInfo{}, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+
+	var info VideoInfo
+	foundVideo := false
+	for _, s := range probe.Streams {
+		switch s.CodecType {
+		case "video":
+			if foundVideo {
+				info.ExtraStreams++
+				continue
+			}
+			foundVideo = true
+			info.Codec = s.CodecName
+			info.PixelFormat = s.PixFmt
+			info.Width = s.Width
+			info.Height = s.Height
+			info.RealFrameRate = parseFrameRate(s.RFrameRate)
+			info.BaseRate = s.RFrameRate
+			info.AvgFrameRate = parseFrameRate(s.AvgFrameRate)
+		case "audio":
+			if info.AudioCodec != "" {
+				info.ExtraStreams++
+				continue
+			}
+			info.AudioCodec = s.CodecName
+		default:
+			info.ExtraStreams++
+		}
+	}
+
+	if !foundVideo {
+		return VideoInfo{}, fmt.Errorf("no video stream found")
+	}
+	return info, nil
+}
+
+// parseFrameRate parses an ffprobe rational frame rate such as "30000/1001"
+func parseFrameRate(rate string) float64 {
+	num, den, found := strings.Cut(rate, "/")
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0
+	}
+	if !found {
+		return n
+	}
+	d, err := strconv.ParseFloat(den, 64)
+	if err != nil || d == 0 {
+		return 0
+	}
+	return n / d
+}
+
+// isVariableFrameRate reports whether the average frame rate drifts from the base rate
+func isVariableFrameRate(info VideoInfo) bool {
+	if info.RealFrameRate == 0 || info.AvgFrameRate == 0 {
+		return false
+	}
+	diff := info.RealFrameRate - info.AvgFrameRate
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff > 0.01
+}
+
+// normalizationReasons lists why the source cannot simply be remuxed
+func normalizationReasons(info VideoInfo, p Params) []string {
+	var reasons []string
+	if isVariableFrameRate(info) {
+		reasons = append(reasons, fmt.Sprintf("variable frame rate (%.2f avg vs %.2f base)", info.AvgFrameRate, info.RealFrameRate))
+	} else if p.FrameRate > 0 && info.AvgFrameRate > 0 && abs(info.AvgFrameRate-p.FrameRate) > 0.01 {
+		reasons = append(reasons, fmt.Sprintf("frame rate %.2f differs from target %.2f", info.AvgFrameRate, p.FrameRate))
+	}
+	if info.Codec != "h264" {
+		reasons = append(reasons, fmt.Sprintf("codec %s", info.Codec))
+	}
+	if info.PixelFormat != "" && info.PixelFormat != "yuv420p" && info.PixelFormat != "yuvj420p" {
+		reasons = append(reasons, fmt.Sprintf("pixel format %s", info.PixelFormat))
+	}
+	if info.Width > p.MaxWidth || info.Height > p.MaxHeight {
+		reasons = append(reasons, fmt.Sprintf("resolution %dx%d exceeds %dx%d", info.Width, info.Height, p.MaxWidth, p.MaxHeight))
+	}
+	if info.AudioCodec != "" && info.AudioCodec != "aac" {
+		reasons = append(reasons, fmt.Sprintf("audio codec %s", info.AudioCodec))
+	}
+	return reasons
+}
+
+// remuxArgs builds ffmpeg arguments that copy the streams into an MP4 container
+func remuxArgs(input, output string) []string {
+	return []string{
+		"-i", input,
+		"-map", "0:v:0", "-map", "0:a:0?",
+		"-c", "copy",
+		"-movflags", "+faststart",
+		output,
+		"-y",
+	}
+}
+
+// targetFrameRate returns the constant frame rate of the mezzanine: frameRate when set, otherwise
+// the source's base frame rate, or "" when ffprobe reported none
+func targetFrameRate(info VideoInfo, p Params) string {
+	if p.FrameRate > 0 {
+		return strconv.FormatFloat(p.FrameRate, 'f', -1, 64)
+	}
+	if info.RealFrameRate > 0 {
+		return info.BaseRate
+	}
+	return ""
+}
+
+// transcodeArgs builds ffmpeg arguments producing a constant frame rate H.264/AAC mezzanine at
+// fps, keeping the decoder's frame rate when fps is empty
+func transcodeArgs(input, output, fps string, p Params) []string {
+	filters := []string{
+		fmt.Sprintf("scale='min(%d,iw)':'min(%d,ih)':force_original_aspect_ratio=decrease", p.MaxWidth, p.MaxHeight),
+		"scale=trunc(iw/2)*2:trunc(ih/2)*2",
+	}
+	if fps != "" {
+		filters = append(filters, "fps="+fps)
+	}
+	filters = append(filters, "format=yuv420p")
+
+	args := []string{
+		"-i", input,
+		"-map", "0:v:0", "-map", "0:a:0?",
+		"-vf", strings.Join(filters, ","),
+		"-vsync", "cfr",
+	}
+	if fps != "" {
+		args = append(args, "-r", fps)
+	}
+	return append(args,
+		"-c:v", "libx264",
+		"-preset", p.Preset,
+		"-crf", strconv.Itoa(p.CRF),
+		"-c:a", "aac",
+		"-b:a", "192k",
+		"-ar", strconv.Itoa(p.AudioSampleRate),
+		"-af", "aresample=async=1",
+		"-movflags", "+faststart",
+		output,
+		"-y",
+	)
+}
+
+// abs returns the absolute value of f
+func abs(f float64) float64 {
+	if f < 0 {
+		return -f
+	}
+	return f
+}
+
+// GetIO returns the module's input/output specification
+func (m *Module) GetIO() modules.ModuleIO {
+	return modules.ModuleIO{
+		RequiredInputs: []modules.ModuleInput{
+			{
+				Name:        "input",
+				Description: "Path to input video file",
+				Patterns:    supportedExtensions,
+				Type:        string(modules.InputTypeFile),
+			},
+			{
+				Name:        "output",
+				Description: "Path to output directory",
+				Type:        string(modules.InputTypeDirectory),
+			},
+		},
+		OptionalInputs: []modules.ModuleInput{
+			{
+				Name:        "outputName",
+				Description: "Custom output filename",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "frameRate",
+				Description: "Constant frame rate of the mezzanine (default: the source's base frame rate)",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "maxWidth",
+				Description: "Maximum output width in pixels (default: 1920)",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "maxHeight",
+				Description: "Maximum output height in pixels (default: 1080)",
+				Type:        string(modules.InputTypeData),
+			},
+		},
+		ProducedOutputs: []modules.ModuleOutput{
+			{
+				Name:        "video",
+				Description: "Normalized constant frame rate H.264/AAC video",
+				Patterns:    []string{".mp4", ".mov"},
+				Type:        string(modules.OutputTypeFile),
+			},
+		},
+	}
+}
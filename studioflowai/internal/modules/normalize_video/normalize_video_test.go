@@ -0,0 +1,261 @@
+package normalizevideo
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	vfrProbe = `{"streams":[{"codec_type":"video","codec_name":"hevc","pix_fmt":"yuv420p10le","width":3840,"height":2160,"r_frame_rate":"60/1","avg_frame_rate":"5994/200"},{"codec_type":"audio","codec_name":"aac"}]}`
+	cfrProbe = `{"streams":[{"codec_type":"video","codec_name":"h264","pix_fmt":"yuv420p","width":1920,"height":1080,"r_frame_rate":"30/1","avg_frame_rate":"30/1"},{"codec_type":"audio","codec_name":"aac"}]}`
+)
+
+// recordedArgs holds the arguments of the last ffmpeg invocation
+var recordedArgs []string
+
+// fakeExecCommand returns a helper process that prints probeOutput for ffprobe
+func fakeExecCommand(probeOutput string) func(ctx context.Context, command string, args ...string) *exec.Cmd {
+	return func(ctx context.Context, command string, args ...string) *exec.Cmd {
+		if command == "ffmpeg" {
+			recordedArgs = args
+		}
+		cs := []string{"-test.run=TestHelperProcess", "--", command}
+		cs = append(cs, args...)
+		cmd := exec.Command(os.Args[0], cs...)
+		cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1", "PROBE_OUTPUT=" + probeOutput}
+		return cmd
+	}
+}
+
+// fakeLookPath always returns success
+func fakeLookPath(file string) (string, error) {
+	return file, nil
+}
+
+// TestHelperProcess is not a real test, it's used to mock exec.Command
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	for i, arg := range os.Args {
+		if arg == "--" && i+1 < len(os.Args) && os.Args[i+1] == "ffprobe" {
+			fmt.Print(os.Getenv("PROBE_OUTPUT"))
+		}
+	}
+	os.Exit(0)
+}
+
+func TestModule_Name(t *testing.T) {
+	assert.Equal(t, "normalize_video", New().Name())
+}
+
+func TestModule_GetIO(t *testing.T) {
+	io := New().GetIO()
+
+	assert.Len(t, io.RequiredInputs, 2)
+	assert.Equal(t, "input", io.RequiredInputs[0].Name)
+	assert.Equal(t, "output", io.RequiredInputs[1].Name)
+
+	assert.Len(t, io.ProducedOutputs, 1)
+	assert.Equal(t, "video", io.ProducedOutputs[0].Name)
+}
+
+func TestModule_Validate(t *testing.T) {
+	utils.ExecLookPath = fakeLookPath
+	defer func() { utils.ExecLookPath = exec.LookPath }()
+
+	tempDir := t.TempDir()
+	videoPath := filepath.Join(tempDir, "screen.mov")
+	require.NoError(t, os.WriteFile(videoPath, []byte("dummy"), 0644))
+	textPath := filepath.Join(tempDir, "notes.txt")
+	require.NoError(t, os.WriteFile(textPath, []byte("dummy"), 0644))
+
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name:   "valid parameters",
+			params: map[string]interface{}{"input": videoPath, "output": tempDir},
+		},
+		{
+			name:    "missing input",
+			params:  map[string]interface{}{"output": tempDir},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported input extension",
+			params:  map[string]interface{}{"input": textPath, "output": tempDir},
+			wantErr: true,
+		},
+		{
+			name:    "invalid output name",
+			params:  map[string]interface{}{"input": videoPath, "output": tempDir, "outputName": "out.wav"},
+			wantErr: true,
+		},
+		{
+			name:    "crf out of range",
+			params:  map[string]interface{}{"input": videoPath, "output": tempDir, "crf": 60},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := New().Validate(tt.params)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestModule_Execute(t *testing.T) {
+	defer func() { execCommand = exec.CommandContext }()
+
+	tempDir := t.TempDir()
+	videoPath := filepath.Join(tempDir, "phone.mov")
+	require.NoError(t, os.WriteFile(videoPath, []byte("dummy"), 0644))
+
+	t.Run("transcodes variable frame rate HEVC", func(t *testing.T) {
+		execCommand = fakeExecCommand(vfrProbe)
+		result, err := New().Execute(context.Background(), map[string]interface{}{
+			"input":  videoPath,
+			"output": tempDir,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, filepath.Join(tempDir, "phone_normalized.mp4"), result.Outputs["video"])
+		assert.Equal(t, "transcode", result.Metadata["action"])
+		assert.Equal(t, true, result.Metadata["variableFrame"])
+
+		joined := strings.Join(recordedArgs, " ")
+		assert.Contains(t, joined, "-c:v libx264")
+		assert.Contains(t, joined, "-vsync cfr")
+		assert.Contains(t, joined, "fps=60/1", "keeps the source's base frame rate")
+		assert.Contains(t, joined, "-crf 18")
+		assert.Contains(t, joined, "min(1920,iw)")
+		assert.Equal(t, 60.0, result.Metadata["targetFps"])
+	})
+
+	t.Run("transcodes at requested frame rate and crf", func(t *testing.T) {
+		execCommand = fakeExecCommand(vfrProbe)
+		result, err := New().Execute(context.Background(), map[string]interface{}{
+			"input":     videoPath,
+			"output":    tempDir,
+			"frameRate": 30,
+			"crf":       0,
+		})
+		require.NoError(t, err)
+
+		joined := strings.Join(recordedArgs, " ")
+		assert.Contains(t, joined, "fps=30")
+		assert.Contains(t, joined, "-r 30")
+		assert.Contains(t, joined, "-crf 0", "crf 0 is lossless, not the default")
+		assert.Equal(t, 30.0, result.Metadata["targetFps"])
+	})
+
+	t.Run("remuxes edit-friendly source", func(t *testing.T) {
+		execCommand = fakeExecCommand(cfrProbe)
+		result, err := New().Execute(context.Background(), map[string]interface{}{
+			"input":      videoPath,
+			"output":     tempDir,
+			"outputName": "mezzanine.mp4",
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, filepath.Join(tempDir, "mezzanine.mp4"), result.Outputs["video"])
+		assert.Equal(t, "remux", result.Metadata["action"])
+		assert.Contains(t, strings.Join(recordedArgs, " "), "-c copy")
+	})
+
+	t.Run("links edit-friendly MP4 source", func(t *testing.T) {
+		execCommand = fakeExecCommand(cfrProbe)
+		recordedArgs = nil
+		mp4Path := filepath.Join(tempDir, "camera.mp4")
+		require.NoError(t, os.WriteFile(mp4Path, []byte("mp4 data"), 0644))
+
+		result, err := New().Execute(context.Background(), map[string]interface{}{
+			"input":  mp4Path,
+			"output": filepath.Join(tempDir, "out"),
+		})
+		require.NoError(t, err)
+
+		assert.Contains(t, []interface{}{utils.LinkedClone, utils.LinkedHardlink, utils.LinkedCopy}, result.Metadata["action"])
+		assert.Nil(t, recordedArgs, "ffmpeg must not run")
+		data, err := os.ReadFile(result.Outputs["video"])
+		require.NoError(t, err)
+		assert.Equal(t, "mp4 data", string(data))
+	})
+
+	t.Run("forces transcode", func(t *testing.T) {
+		execCommand = fakeExecCommand(cfrProbe)
+		result, err := New().Execute(context.Background(), map[string]interface{}{
+			"input":          videoPath,
+			"output":         tempDir,
+			"forceTranscode": true,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "transcode", result.Metadata["action"])
+	})
+
+	t.Run("fails without video stream", func(t *testing.T) {
+		execCommand = fakeExecCommand(`{"streams":[{"codec_type":"audio","codec_name":"aac"}]}`)
+		_, err := New().Execute(context.Background(), map[string]interface{}{
+			"input":  videoPath,
+			"output": tempDir,
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestParseFrameRate(t *testing.T) {
+	assert.InDelta(t, 29.97, parseFrameRate("30000/1001"), 0.001)
+	assert.Equal(t, 25.0, parseFrameRate("25"))
+	assert.Equal(t, 0.0, parseFrameRate("0/0"))
+	assert.Equal(t, 0.0, parseFrameRate("bad"))
+}
+
+func TestNormalizationReasons(t *testing.T) {
+	p := Params{FrameRate: 30, MaxWidth: 1920, MaxHeight: 1080}
+
+	reasons := normalizationReasons(VideoInfo{Codec: "h264", PixelFormat: "yuv420p", Width: 1920, Height: 1080, RealFrameRate: 30, AvgFrameRate: 30, AudioCodec: "aac"}, p)
+	assert.Empty(t, reasons)
+
+	info, err := parseProbeOutput([]byte(`{"streams":[{"codec_type":"video","codec_name":"h264"},{"codec_type":"audio","codec_name":"aac"},{"codec_type":"audio","codec_name":"aac"},{"codec_type":"data"}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, 2, info.ExtraStreams)
+	assert.False(t, canLink("clip.mp4", info))
+	assert.True(t, canLink("clip.MP4", VideoInfo{}))
+	assert.False(t, canLink("clip.mov", VideoInfo{}))
+
+	reasons = normalizationReasons(VideoInfo{Codec: "hevc", PixelFormat: "yuv420p10le", Width: 3840, Height: 2160, RealFrameRate: 60, AvgFrameRate: 29.97}, p)
+	assert.Len(t, reasons, 4)
+
+	pal := VideoInfo{Codec: "h264", PixelFormat: "yuv420p", Width: 1920, Height: 1080, RealFrameRate: 25, AvgFrameRate: 25, AudioCodec: "aac"}
+	assert.Len(t, normalizationReasons(pal, p), 1, "an explicit frameRate converts other rates")
+	assert.Empty(t, normalizationReasons(pal, Params{MaxWidth: 1920, MaxHeight: 1080}), "the source rate is kept by default")
+}
+
+func TestTargetFrameRate(t *testing.T) {
+	info := VideoInfo{RealFrameRate: 29.97, BaseRate: "30000/1001", AvgFrameRate: 24.5}
+	assert.Equal(t, "30000/1001", targetFrameRate(info, Params{}))
+	assert.Equal(t, "24", targetFrameRate(info, Params{FrameRate: 24}))
+	assert.Equal(t, "", targetFrameRate(VideoInfo{}, Params{}))
+
+	args := strings.Join(transcodeArgs("in.mov", "out.mp4", "", Params{MaxWidth: 1920, MaxHeight: 1080}), " ")
+	assert.NotContains(t, args, "fps=")
+	assert.NotContains(t, args, "-r ")
+}
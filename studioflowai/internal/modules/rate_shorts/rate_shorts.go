@@ -0,0 +1,399 @@
+package rateshorts
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	modules "github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	chatgpt "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/chatgpt"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+
+	"gopkg.in/yaml.v3"
+)
+
+// contextKey is a type for context keys
+type contextKey string
+
+// ChatGPTServiceKey is the context key for the ChatGPT service
+const ChatGPTServiceKey = contextKey("chatgpt_service")
+
+// maxRating is the highest rating of each risk
+const maxRating = 10
+
+// Module rates the titles and descriptions of suggested shorts for clickbait, policy-sensitive
+// claims and misleading framing
+type Module struct{}
+
+// Params contains the parameters for title safety ratings
+type Params struct {
+	Input            string   `json:"input"`                                                // Path to shorts_suggestions.yaml
+	Output           string   `json:"output"`                                               // Path to output directory
+	OutputFileName   string   `json:"outputFileName" default:"shorts_suggestions"`          // Name of the rated shorts file, without extension (default: "shorts_suggestions")
+	MaxRisk          int      `json:"maxRisk"`                                              // Block the upload of clips rated above this risk, from 1 to 10 (default: block none)
+	Model            string   `json:"model" default:"gpt-4o"`                               // OpenAI model to use (default: "gpt-4o")
+	FallbackModels   []string `json:"fallbackModels"`                                       // Models tried in order when the primary model fails or returns an invalid rating
+	Temperature      float64  `json:"temperature" default:"0.2"`                            // Model temperature (default: 0.2)
+	MaxTokens        int      `json:"maxTokens" default:"800"`                              // Maximum tokens per clip (default: 800)
+	RequestTimeoutMS int      `json:"requestTimeoutMs" default:"60000"`                     // API request timeout in milliseconds (default: 60000)
+	PromptFilePath   string   `json:"promptFilePath" default:"./prompts/title_safety.yaml"` // Path to custom prompt YAML file (default: "./prompts/title_safety.yaml")
+}
+
+// PromptData represents the structure of a YAML prompt template
+type PromptData struct {
+	Title       string `yaml:"title"`
+	Role        string `yaml:"role"`
+	Prompt      string `yaml:"prompt"`
+	Description string `yaml:"description"`
+}
+
+// modelRating is the response expected from the model for one clip
+type modelRating struct {
+	Clickbait  *int     `yaml:"clickbait"`
+	Policy     *int     `yaml:"policy"`
+	Misleading *int     `yaml:"misleading"`
+	Flags      []string `yaml:"flags"`
+	Notes      string   `yaml:"notes"`
+}
+
+// New creates a new title safety rating module
+func New() modules.Module {
+	return &Module{}
+}
+
+// Name returns the module name
+func (m *Module) Name() string {
+	return "rate_shorts"
+}
+
+// ParamsTemplate returns the module's parameter struct, used to validate and document workflows
+func (m *Module) ParamsTemplate() interface{} {
+	return Params{}
+}
+
+// Validate checks if the parameters are valid
+func (m *Module) Validate(params map[string]interface{}) error {
+	var p Params
+	if err := modules.ParseParams(params, &p); err != nil {
+		return err
+	}
+
+	if err := utils.ValidateInputPath(p.Input, p.Output, ""); err != nil {
+		return err
+	}
+	if err := utils.ValidateOutputPath(p.Output); err != nil {
+		return err
+	}
+	if p.MaxRisk < 0 || p.MaxRisk > maxRating {
+		return fmt.Errorf("maxRisk must be between 0 and %d, got %d", maxRating, p.MaxRisk)
+	}
+
+	// Check if the API key is set - just warn but don't error
+	if !chatgpt.IsAPIKeySet() {
+		utils.LogWarning("OPENAI_API_KEY environment variable is not set. Clips will be left unrated.")
+	}
+
+	if p.PromptFilePath != "" {
+		if _, err := os.Stat(p.PromptFilePath); os.IsNotExist(err) {
+			return fmt.Errorf("prompt template file %s does not exist", p.PromptFilePath)
+		}
+	}
+	return nil
+}
+
+// Execute rates every clip of the shorts file and writes the ratings under "rating"
+func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (modules.ModuleResult, error) {
+	var p Params
+	if err := modules.ParseParams(params, &p); err != nil {
+		return modules.ModuleResult{}, err
+	}
+
+	// Set default values
+	if p.OutputFileName == "" {
+		p.OutputFileName = "shorts_suggestions"
+	}
+	if p.Model == "" {
+		p.Model = "gpt-4o"
+	}
+	if p.Temperature == 0 {
+		p.Temperature = 0.2
+	}
+	if p.MaxTokens == 0 {
+		p.MaxTokens = 800
+	}
+	if p.RequestTimeoutMS == 0 {
+		p.RequestTimeoutMS = 60000
+	}
+	if p.PromptFilePath == "" {
+		p.PromptFilePath = utils.ResolvePromptPath("./prompts/title_safety.yaml")
+	}
+
+	// The file is edited as a node tree so fields added by other steps survive the rewrite
+	resolvedInput := utils.ResolveOutputPath(p.Input, p.Output)
+	doc, err := utils.ReadShortsDocument(resolvedInput)
+	if err != nil {
+		return modules.ModuleResult{}, err
+	}
+
+	var chatGPT chatgpt.ChatGPTServicer
+	if chatgpt.IsAPIKeySet() {
+		if chatGPT, err = m.getChatGPTService(ctx); err != nil {
+			return modules.ModuleResult{}, fmt.Errorf("failed to initialize ChatGPT service: %w", err)
+		}
+	} else {
+		utils.LogWarningContext(ctx, "No API key set - leaving the clips unrated")
+	}
+	promptData := getPromptTemplate(ctx, p.PromptFilePath)
+
+	// Without an API key the clips are left unrated and the file is written as it is
+	clips := doc.Shorts.Content
+	if chatGPT == nil {
+		clips = nil
+	}
+	rated, blocked, highest := 0, 0, 0
+	for i, clip := range clips {
+		title := utils.ClipField(clip, "title")
+		utils.LogInfoContext(ctx, "Rating short %d/%d: %s", i+1, len(clips), title)
+		rating, err := rateClip(ctx, chatGPT, promptData, clip, p)
+		if err != nil {
+			return modules.ModuleResult{}, fmt.Errorf("short %d: %w", i+1, err)
+		}
+
+		rating.Blocked = p.MaxRisk > 0 && rating.Risk > p.MaxRisk
+		if rating.Blocked {
+			blocked++
+			utils.LogWarningContext(ctx, "Short %q is rated %d/10 (%s) and will not be uploaded", title, rating.Risk, strings.Join(rating.Flags, ", "))
+		}
+		highest = max(highest, rating.Risk)
+
+		var ratingNode yaml.Node
+		if err := ratingNode.Encode(rating); err != nil {
+			return modules.ModuleResult{}, fmt.Errorf("failed to encode rating: %w", err)
+		}
+		utils.SetMappingValue(clip, "rating", &ratingNode)
+		rated++
+	}
+
+	out, err := doc.Marshal()
+	if err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to generate YAML: %w", err)
+	}
+	if err := utils.EnsureDir(p.Output); err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	outputPath := filepath.Join(p.Output, p.OutputFileName+".yaml")
+	if err := utils.AtomicWriteFile(outputPath, out, 0644); err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to write output file: %w", err)
+	}
+
+	utils.LogSuccessContext(ctx, "Rated %d shorts, highest risk %d/10, %d blocked -> %s", rated, highest, blocked, outputPath)
+
+	return modules.ModuleResult{
+		Outputs: map[string]string{
+			"suggestions": outputPath,
+		},
+		Metadata: map[string]interface{}{
+			"inputFile": resolvedInput,
+			"rated":     rated,
+			"blocked":   blocked,
+		},
+		Statistics: map[string]interface{}{
+			"model":       p.Model,
+			"rated":       rated,
+			"blocked":     blocked,
+			"highestRisk": highest,
+			"processTime": time.Now().Format(time.RFC3339),
+		},
+		Stats: modules.Stats{Items: rated},
+	}, nil
+}
+
+// rateClip asks the model to rate the title and description of one clip against what is said in it
+func rateClip(ctx context.Context, chatGPT chatgpt.ChatGPTServicer, promptData PromptData, clip *yaml.Node, p Params) (utils.ClipRating, error) {
+	var prompt strings.Builder
+	prompt.WriteString(strings.TrimSpace(promptData.Prompt))
+	prompt.WriteString("\n\n")
+	for _, field := range []string{"title", "shortTitle", "description", "tags"} {
+		if value := utils.ClipField(clip, field); value != "" {
+			fmt.Fprintf(&prompt, "%s: %s\n", field, value)
+		}
+	}
+	if excerpt := utils.ClipField(clip, "excerpt"); excerpt != "" {
+		prompt.WriteString("\nWhat is said in the clip:\n")
+		prompt.WriteString(excerpt)
+	} else {
+		prompt.WriteString("\nThe transcript of the clip is not available; rate misleading framing from the title and description alone.\n")
+	}
+
+	messages := []chatgpt.ChatMessage{
+		{
+			Role:    "system",
+			Content: fmt.Sprintf("You are a %s. You rate the titles and descriptions of short videos before they are published.", promptData.Role),
+		},
+		{
+			Role:    "user",
+			Content: prompt.String(),
+		},
+	}
+
+	var rating utils.ClipRating
+	completion, err := chatgpt.CompleteWithFallback(ctx, chatGPT, messages, chatgpt.CompletionOptions{
+		Model:            p.Model,
+		Temperature:      p.Temperature,
+		MaxTokens:        p.MaxTokens,
+		RequestTimeoutMS: p.RequestTimeoutMS,
+	}, chatgpt.FallbackChain{Models: p.FallbackModels}, func(response string) error {
+		parsed, err := parseRating(response)
+		if err != nil {
+			return err
+		}
+		rating = parsed
+		return nil
+	})
+	if err != nil {
+		return utils.ClipRating{}, fmt.Errorf("ChatGPT API request failed: %w", err)
+	}
+	rating.Model = completion.Model
+	return rating, nil
+}
+
+// parseRating reads the model's rating and checks that every risk is rated within range
+func parseRating(response string) (utils.ClipRating, error) {
+	var parsed modelRating
+	if err := yaml.Unmarshal([]byte(stripYAMLFence(response)), &parsed); err != nil {
+		return utils.ClipRating{}, fmt.Errorf("invalid YAML response: %w", err)
+	}
+
+	rating := utils.ClipRating{Flags: parsed.Flags, Notes: strings.TrimSpace(parsed.Notes)}
+	for _, field := range []struct {
+		name  string
+		value *int
+		out   *int
+	}{
+		{"clickbait", parsed.Clickbait, &rating.Clickbait},
+		{"policy", parsed.Policy, &rating.Policy},
+		{"misleading", parsed.Misleading, &rating.Misleading},
+	} {
+		if field.value == nil {
+			return utils.ClipRating{}, fmt.Errorf("response has no %s rating", field.name)
+		}
+		if *field.value < 0 || *field.value > maxRating {
+			return utils.ClipRating{}, fmt.Errorf("%s rating %d is outside 0-%d", field.name, *field.value, maxRating)
+		}
+		*field.out = *field.value
+	}
+	rating.Risk = max(rating.Clickbait, rating.Policy, rating.Misleading)
+	return rating, nil
+}
+
+// stripYAMLFence removes a surrounding ```yaml code fence if the model added one
+func stripYAMLFence(content string) string {
+	content = strings.TrimSpace(content)
+	if !strings.HasPrefix(content, "```") {
+		return content
+	}
+	if idx := strings.Index(content, "\n"); idx != -1 {
+		content = content[idx+1:]
+	} else {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(content), "```"))
+}
+
+// getPromptTemplate loads the prompt template from file, falling back to the default
+func getPromptTemplate(ctx context.Context, promptFilePath string) PromptData {
+	if data, err := os.ReadFile(promptFilePath); err == nil {
+		var promptData PromptData
+		if err := yaml.Unmarshal(data, &promptData); err == nil && strings.TrimSpace(promptData.Prompt) != "" {
+			if promptData.Role == "" {
+				promptData.Role = defaultRole
+			}
+			utils.LogDebugContext(ctx, "Using custom title safety prompt template from YAML file: %s", promptFilePath)
+			return promptData
+		}
+		utils.LogWarningContext(ctx, "Failed to parse title safety prompt %s, falling back to default", promptFilePath)
+	}
+
+	utils.LogDebugContext(ctx, "Using default title safety prompt template")
+	return PromptData{
+		Title:  "Title Safety Rating",
+		Role:   defaultRole,
+		Prompt: defaultPrompt,
+	}
+}
+
+const defaultRole = "trust and safety reviewer for YouTube and TikTok"
+
+const defaultPrompt = `Rate the title and description of the short video clip below.
+
+## RATINGS (0 = none, 10 = severe):
+1. clickbait: how much the title overpromises, exaggerates or withholds information to bait clicks ("You won't believe...", "This changes everything").
+2. policy: health, medical, financial, legal, election or other claims that YouTube and TikTok restrict or label, and content that could be seen as harassment or dangerous.
+3. misleading: how far the title and description depart from what is actually said in the clip.
+
+## REQUIRED YAML FORMAT (USE EXACTLY THIS FORMAT):
+clickbait: 0
+policy: 0
+misleading: 0
+flags:
+  - "Phrase of the title or description that raised a rating"
+notes: "One sentence explaining the highest rating"
+
+## IMPORTANT: Your response MUST be only the YAML, without prior explanations or code fences.`
+
+// getChatGPTService returns a ChatGPT service from context or creates a new one
+func (m *Module) getChatGPTService(ctx context.Context) (chatgpt.ChatGPTServicer, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("context cannot be nil")
+	}
+
+	// Check if service is provided in context
+	if service, ok := ctx.Value(ChatGPTServiceKey).(chatgpt.ChatGPTServicer); ok {
+		return service, nil
+	}
+
+	// Create new service if not in context
+	return chatgpt.NewChatGPTService()
+}
+
+// GetIO returns the module's input/output specification
+func (m *Module) GetIO() modules.ModuleIO {
+	return modules.ModuleIO{
+		RequiredInputs: []modules.ModuleInput{
+			{
+				Name:        "input",
+				Description: "Path to shorts suggestions YAML file",
+				Patterns:    []string{".yaml"},
+				Type:        string(modules.InputTypeFile),
+			},
+			{
+				Name:        "output",
+				Description: "Path to output directory",
+				Type:        string(modules.InputTypeDirectory),
+			},
+		},
+		OptionalInputs: []modules.ModuleInput{
+			{
+				Name:        "maxRisk",
+				Description: "Block the upload of clips rated above this risk (0-10)",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "promptFilePath",
+				Description: "Path to custom prompt YAML file",
+				Type:        string(modules.InputTypeFile),
+			},
+		},
+		ProducedOutputs: []modules.ModuleOutput{
+			{
+				Name:        "suggestions",
+				Description: "Shorts suggestions with a safety rating per clip",
+				Patterns:    []string{".yaml"},
+				Type:        string(modules.OutputTypeFile),
+			},
+		},
+	}
+}
@@ -0,0 +1,169 @@
+package rateshorts
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	services "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/chatgpt"
+	mocks "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/chatgpt/mocks"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/snapshot"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const shortsYAML = `sourceVideo: episode.mp4
+shorts:
+  - title: "Why analog synths"
+    shortTitle: "Analog warmth"
+    startTime: "00:01:00"
+    endTime: "00:01:40"
+    description: "The host explains the warmth of analog gear"
+    excerpt: "I bought a Moog synthesizer in Berlin."
+    score:
+      total: 0.8
+  - title: "This synth cures anxiety"
+    startTime: "00:03:00"
+    endTime: "00:03:40"
+    description: "You won't believe what happened next"
+`
+
+func writeShorts(t *testing.T) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	shorts := filepath.Join(dir, "shorts_suggestions.yaml")
+	require.NoError(t, os.WriteFile(shorts, []byte(shortsYAML), 0644))
+	return shorts, filepath.Join(dir, "output")
+}
+
+func TestModule_Name(t *testing.T) {
+	assert.Equal(t, "rate_shorts", New().Name())
+}
+
+func TestExecute(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "test-key")
+	shorts, output := writeShorts(t)
+
+	chatGPT := mocks.NewMockChatGPTServicer(t)
+	chatGPT.EXPECT().GetContent(
+		mock.Anything,
+		mock.MatchedBy(func(messages []services.ChatMessage) bool {
+			return strings.Contains(messages[1].Content, "title: Why analog synths") &&
+				strings.Contains(messages[1].Content, "I bought a Moog synthesizer in Berlin.")
+		}),
+		mock.Anything,
+	).Return("clickbait: 1\npolicy: 0\nmisleading: 0\nnotes: \"Accurate\"", nil)
+	chatGPT.EXPECT().GetContent(
+		mock.Anything,
+		mock.MatchedBy(func(messages []services.ChatMessage) bool {
+			return strings.Contains(messages[1].Content, "title: This synth cures anxiety") &&
+				strings.Contains(messages[1].Content, "transcript of the clip is not available")
+		}),
+		mock.Anything,
+	).Return("```yaml\nclickbait: 7\npolicy: 9\nmisleading: 6\nflags:\n  - \"cures anxiety\"\nnotes: \"Medical claim\"\n```", nil)
+
+	ctx := context.WithValue(context.Background(), ChatGPTServiceKey, chatGPT)
+	result, err := New().Execute(ctx, map[string]interface{}{
+		"input":   shorts,
+		"output":  output,
+		"maxRisk": 6,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Statistics["blocked"])
+	assert.Equal(t, 9, result.Statistics["highestRisk"])
+
+	data, err := os.ReadFile(result.Outputs["suggestions"])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "total: 0.8", "fields of other steps are kept")
+
+	rated, err := utils.ReadShortsFile(result.Outputs["suggestions"])
+	require.NoError(t, err)
+	require.NotNil(t, rated.Shorts[0].Rating)
+	assert.Equal(t, 1, rated.Shorts[0].Rating.Risk)
+	assert.False(t, rated.Shorts[0].Rating.Blocked)
+	require.NotNil(t, rated.Shorts[1].Rating)
+	assert.Equal(t, utils.ClipRating{Clickbait: 7, Policy: 9, Misleading: 6, Risk: 9, Flags: []string{"cures anxiety"}, Notes: "Medical claim", Model: "gpt-4o", Blocked: true}, *rated.Shorts[1].Rating)
+
+	// Blocked clips are left out of uploads
+	kept := utils.UnblockedShorts(rated.Shorts)
+	require.Len(t, kept, 1)
+	assert.Equal(t, "Why analog synths", kept[0].Title)
+}
+
+func TestExecute_NoAPIKey(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "")
+	shorts, output := writeShorts(t)
+
+	result, err := New().Execute(context.Background(), map[string]interface{}{
+		"input":  shorts,
+		"output": output,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Statistics["rated"])
+
+	rated, err := utils.ReadShortsFile(result.Outputs["suggestions"])
+	require.NoError(t, err)
+	assert.Len(t, rated.Shorts, 2)
+	assert.Nil(t, rated.Shorts[0].Rating)
+}
+
+func TestParseRating(t *testing.T) {
+	rating, err := parseRating("clickbait: 2\npolicy: 5\nmisleading: 3\nflags: [\"guaranteed\"]")
+	require.NoError(t, err)
+	assert.Equal(t, 5, rating.Risk)
+	assert.Equal(t, []string{"guaranteed"}, rating.Flags)
+
+	_, err = parseRating("clickbait: 2\npolicy: 5")
+	assert.ErrorContains(t, err, "no misleading rating")
+
+	_, err = parseRating("clickbait: 12\npolicy: 0\nmisleading: 0")
+	assert.ErrorContains(t, err, "outside 0-10")
+
+	_, err = parseRating("not: [yaml")
+	assert.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	shorts, output := writeShorts(t)
+	assert.NoError(t, New().Validate(map[string]interface{}{"input": shorts, "output": output, "maxRisk": 6}))
+	assert.ErrorContains(t, New().Validate(map[string]interface{}{"input": shorts, "output": output, "maxRisk": 11}), "maxRisk")
+}
+
+// TestGolden records the prompt of each clip built with the default template and the ratings
+// written from fixture responses; rerun with UPDATE_SNAPSHOTS=1 after an intended change
+func TestGolden(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "test-key")
+	shorts, output := writeShorts(t)
+
+	var sent [][]services.ChatMessage
+	responses := []string{
+		"clickbait: 1\npolicy: 0\nmisleading: 0\nnotes: \"Accurate\"",
+		"```yaml\nclickbait: 7\npolicy: 9\nmisleading: 6\nflags:\n  - \"cures anxiety\"\nnotes: \"Medical claim\"\n```",
+	}
+	chatGPT := mocks.NewMockChatGPTServicer(t)
+	chatGPT.EXPECT().GetContent(mock.Anything, mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, messages []services.ChatMessage, _ services.CompletionOptions) (string, error) {
+			sent = append(sent, messages)
+			return responses[len(sent)-1], nil
+		}).Times(2)
+
+	ctx := context.WithValue(context.Background(), ChatGPTServiceKey, chatGPT)
+	result, err := New().Execute(ctx, map[string]interface{}{
+		"input":   shorts,
+		"output":  output,
+		"maxRisk": 6,
+	})
+	require.NoError(t, err)
+
+	for i, messages := range sent {
+		snapshot.MatchMessages(t, fmt.Sprintf("prompt_%d.txt", i+1), messages)
+	}
+	data, err := os.ReadFile(result.Outputs["suggestions"])
+	require.NoError(t, err)
+	snapshot.Match(t, "shorts_suggestions.yaml", string(data))
+}
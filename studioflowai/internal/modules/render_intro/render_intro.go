@@ -0,0 +1,423 @@
+package renderintro
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	modules "github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+// execCommand allows us to mock exec.Command in tests
+var execCommand = utils.CommandContext
+
+// DefaultRenderer renders a Lottie animation to MP4 with puppeteer-lottie-cli
+const DefaultRenderer = "puppeteer-lottie -i {template} -o {output}"
+
+// introSuffix marks the clips with the intro prepended
+const introSuffix = "-intro"
+
+// templatePlaceholder matches a {key} placeholder in the text of a template
+var templatePlaceholder = regexp.MustCompile(`\{([A-Za-z][A-Za-z0-9_]*)\}`)
+
+// Module renders an animated intro with the episode title and prepends it to clips
+type Module struct{}
+
+// Params contains the parameters for intro rendering
+type Params struct {
+	Output     string                 `json:"output"`                         // Path to output directory
+	Title      string                 `json:"title"`                          // Title shown in the intro (default: the episode metadata title)
+	Template   string                 `json:"template"`                       // Lottie JSON template; {title} and other {key} placeholders in its texts are filled in (default: built-in title card)
+	Renderer   string                 `json:"renderer"`                       // Command rendering the filled template; {template} and {output} are replaced (default: puppeteer-lottie)
+	OutputName string                 `json:"outputName" default:"intro.mp4"` // Name of the rendered intro (default: "intro.mp4")
+	Duration   float64                `json:"duration" default:"3"`           // Length of the built-in title card in seconds (default: 3)
+	Width      int                    `json:"width" default:"1080"`           // Width of the built-in title card (default: 1080)
+	Height     int                    `json:"height" default:"1920"`          // Height of the built-in title card (default: 1920)
+	Background string                 `json:"background" default:"black"`     // Background color of the built-in title card (default: "black")
+	FontFile   string                 `json:"fontFile"`                       // Font of the built-in title card (default: fontconfig's default font)
+	FontColor  string                 `json:"fontColor" default:"white"`      // Text color of the built-in title card (default: "white")
+	FontSize   int                    `json:"fontSize"`                       // Text size of the built-in title card (default: 1/16 of the height)
+	Videos     string                 `json:"videos"`                         // Glob of clips to prepend the intro to, e.g. "${output}/*-withtext.mp4" (optional)
+	QuietFlag  bool                   `json:"quietFlag" default:"true"`       // Suppress ffmpeg output (default: true)
+	Metadata   map[string]interface{} `json:"metadata"`                       // Episode details available as {key} placeholders
+}
+
+// New creates a new intro rendering module
+func New() modules.Module {
+	return &Module{}
+}
+
+// Name returns the module name
+func (m *Module) Name() string {
+	return "render_intro"
+}
+
+// ParamsTemplate returns the module's parameter struct, used to validate and document workflows
+func (m *Module) ParamsTemplate() interface{} {
+	return Params{}
+}
+
+// Validate checks if the parameters are valid
+func (m *Module) Validate(params map[string]interface{}) error {
+	var p Params
+	if err := modules.ParseParams(params, &p); err != nil {
+		return err
+	}
+
+	if err := utils.ValidateOutputPath(p.Output); err != nil {
+		return err
+	}
+	if introTitle(p) == "" {
+		return fmt.Errorf("title is required, either as a parameter or in the episode metadata")
+	}
+	if p.Duration < 0 || p.Width < 0 || p.Height < 0 || p.FontSize < 0 {
+		return fmt.Errorf("duration, width, height and fontSize must not be negative")
+	}
+
+	if p.Template != "" {
+		if err := utils.ValidateFileExtension(p.Template, []string{".json"}); err != nil {
+			return err
+		}
+		if _, err := os.Stat(p.Template); os.IsNotExist(err) {
+			return fmt.Errorf("template file does not exist: %s", p.Template)
+		}
+		renderer := strings.Fields(p.Renderer)
+		if len(renderer) == 0 {
+			renderer = strings.Fields(DefaultRenderer)
+		}
+		if err := utils.ValidateRequiredDependency(renderer[0]); err != nil {
+			return err
+		}
+	} else if p.FontFile != "" {
+		if _, err := os.Stat(p.FontFile); os.IsNotExist(err) {
+			return fmt.Errorf("font file does not exist: %s", p.FontFile)
+		}
+	}
+
+	if err := utils.ValidateRequiredDependency("ffmpeg"); err != nil {
+		return err
+	}
+	if p.Videos != "" {
+		return utils.ValidateRequiredDependency("ffprobe")
+	}
+	return nil
+}
+
+// Execute renders the intro and prepends it to the matching clips
+func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (modules.ModuleResult, error) {
+	var p Params
+	if err := modules.ParseParams(params, &p); err != nil {
+		return modules.ModuleResult{}, err
+	}
+
+	// Set default values
+	if p.OutputName == "" {
+		p.OutputName = "intro.mp4"
+	}
+	if p.Renderer == "" {
+		p.Renderer = DefaultRenderer
+	}
+	if p.Duration == 0 {
+		p.Duration = 3
+	}
+	if p.Width == 0 {
+		p.Width = 1080
+	}
+	if p.Height == 0 {
+		p.Height = 1920
+	}
+	if p.Background == "" {
+		p.Background = "black"
+	}
+	if p.FontColor == "" {
+		p.FontColor = "white"
+	}
+	if p.FontSize == 0 {
+		p.FontSize = p.Height / 16
+	}
+	if _, ok := params["quietFlag"]; !ok {
+		p.QuietFlag = true
+	}
+
+	if err := utils.EnsureDir(p.Output); err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	fields := utils.EpisodeFields(p.Metadata)
+	fields["title"] = introTitle(p)
+
+	introPath := filepath.Join(p.Output, p.OutputName)
+	renderer := "title card"
+	if p.Template != "" {
+		renderer = "lottie"
+		if err := m.renderTemplate(ctx, introPath, fields, p); err != nil {
+			return modules.ModuleResult{}, err
+		}
+	} else if err := m.renderTitleCard(ctx, introPath, fields["title"], p); err != nil {
+		return modules.ModuleResult{}, err
+	}
+	if _, err := os.Stat(introPath); err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("intro was not rendered to %s", introPath)
+	}
+	utils.LogSuccessContext(ctx, "Rendered intro: %s", introPath)
+
+	outputs := map[string]string{"intro": introPath}
+	if p.Videos != "" {
+		clips, err := m.prependIntro(ctx, introPath, p)
+		if err != nil {
+			return modules.ModuleResult{}, err
+		}
+		for name, path := range clips {
+			outputs[name] = path
+		}
+	}
+
+	return modules.ModuleResult{
+		Outputs: outputs,
+		Statistics: map[string]interface{}{
+			"title":        fields["title"],
+			"renderer":     renderer,
+			"clips":        len(outputs) - 1,
+			"process_time": time.Now().Format(time.RFC3339),
+		},
+		Stats: modules.Stats{Items: len(outputs)},
+	}, nil
+}
+
+// introTitle returns the title shown in the intro
+func introTitle(p Params) string {
+	if p.Title != "" {
+		return p.Title
+	}
+	if title, ok := p.Metadata["title"]; ok {
+		return strings.TrimSpace(fmt.Sprint(title))
+	}
+	return ""
+}
+
+// renderTemplate fills in the texts of a Lottie template and renders it with the renderer command
+func (m *Module) renderTemplate(ctx context.Context, introPath string, fields map[string]string, p Params) error {
+	data, err := os.ReadFile(p.Template)
+	if err != nil {
+		return fmt.Errorf("failed to read template: %w", err)
+	}
+	var animation interface{}
+	if err := json.Unmarshal(data, &animation); err != nil {
+		return fmt.Errorf("failed to parse template %s: %w", p.Template, err)
+	}
+
+	unknown := make(map[string]bool)
+	filled, err := json.Marshal(fillTemplate(animation, fields, unknown))
+	if err != nil {
+		return fmt.Errorf("failed to generate template: %w", err)
+	}
+	for name := range unknown {
+		utils.LogWarningContext(ctx, "Template placeholder {%s} has no value and is kept as is", name)
+	}
+
+	templatePath := filepath.Join(p.Output, strings.TrimSuffix(p.OutputName, filepath.Ext(p.OutputName))+"_template.json")
+	if err := utils.WriteTextFile(templatePath, string(filled)); err != nil {
+		return fmt.Errorf("failed to write template: %w", err)
+	}
+
+	var args []string
+	for _, arg := range strings.Fields(p.Renderer) {
+		args = append(args, strings.NewReplacer("{template}", templatePath, "{output}", introPath).Replace(arg))
+	}
+	utils.LogInfoContext(ctx, "Rendering intro template %s", p.Template)
+	return run(ctx, args[0], args[1:], p.QuietFlag)
+}
+
+// fillTemplate replaces {key} placeholders in every string of a decoded JSON document. Lottie
+// stores the text of text layers as strings, so this fills them without knowing the layer layout.
+func fillTemplate(node interface{}, fields map[string]string, unknown map[string]bool) interface{} {
+	switch value := node.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			value[key] = fillTemplate(child, fields, unknown)
+		}
+	case []interface{}:
+		for i, child := range value {
+			value[i] = fillTemplate(child, fields, unknown)
+		}
+	case string:
+		return templatePlaceholder.ReplaceAllStringFunc(value, func(match string) string {
+			name := match[1 : len(match)-1]
+			if field, ok := fields[name]; ok {
+				return field
+			}
+			unknown[name] = true
+			return match
+		})
+	}
+	return node
+}
+
+// renderTitleCard renders the built-in intro: the title fading in and out over a solid background
+func (m *Module) renderTitleCard(ctx context.Context, introPath, title string, p Params) error {
+	fontFile := ""
+	if p.FontFile != "" {
+		fontFile = "fontfile=" + utils.EscapeFilterText(p.FontFile) + ":"
+	}
+	fade := 0.5
+	if p.Duration < 2 {
+		fade = p.Duration / 4
+	}
+	duration := strconv.FormatFloat(p.Duration, 'f', -1, 64)
+	alpha := fmt.Sprintf("if(lt(t,%[1]g),t/%[1]g,if(gt(t,%[2]s-%[1]g),(%[2]s-t)/%[1]g,1))", fade, duration)
+	filter := fmt.Sprintf("drawtext=%stext='%s':fontsize=%d:fontcolor=%s:x=(w-text_w)/2:y=(h-text_h)/2:alpha='%s'",
+		fontFile, utils.EscapeFilterText(title), p.FontSize, p.FontColor, alpha)
+
+	args := []string{"-y"}
+	if p.QuietFlag {
+		args = append(args, "-v", "error", "-stats")
+	}
+	args = append(args,
+		"-f", "lavfi", "-i", fmt.Sprintf("color=c=%s:s=%dx%d:r=30:d=%s", p.Background, p.Width, p.Height, duration),
+		"-vf", filter,
+		"-c:v", "libx264", "-pix_fmt", "yuv420p", "-t", duration,
+		introPath,
+	)
+	utils.LogInfoContext(ctx, "Rendering title card: %s", title)
+	return run(ctx, "ffmpeg", args, p.QuietFlag)
+}
+
+// prependIntro writes a copy of every matching clip that starts with the intro. The intro is scaled
+// to the clip's size and gets a silent audio track, so clips of any resolution can be joined.
+func (m *Module) prependIntro(ctx context.Context, introPath string, p Params) (map[string]string, error) {
+	pattern := utils.ResolveOutputPath(p.Videos, p.Output)
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid videos pattern %q: %w", p.Videos, err)
+	}
+	sort.Strings(matches)
+
+	introDuration, err := probeDuration(ctx, introPath)
+	if err != nil {
+		return nil, err
+	}
+
+	clips := make(map[string]string)
+	for _, clip := range matches {
+		base := strings.TrimSuffix(filepath.Base(clip), filepath.Ext(clip))
+		if clip == introPath || strings.HasSuffix(base, introSuffix) {
+			continue
+		}
+		outputPath := filepath.Join(p.Output, base+introSuffix+".mp4")
+
+		args := []string{"-y"}
+		if p.QuietFlag {
+			args = append(args, "-v", "error", "-stats")
+		}
+		args = append(args,
+			"-i", introPath,
+			"-i", clip,
+			"-f", "lavfi", "-t", introDuration, "-i", "anullsrc=channel_layout=stereo:sample_rate=48000",
+			"-filter_complex", "[0:v][1:v]scale2ref[iv][cv];[iv]setsar=1[intro];[cv]setsar=1[clip];[intro][2:a][clip][1:a]concat=n=2:v=1:a=1[v][a]",
+			"-map", "[v]", "-map", "[a]",
+			"-c:v", "libx264", "-pix_fmt", "yuv420p", "-c:a", "aac", "-b:a", "128k",
+			outputPath,
+		)
+		utils.LogInfoContext(ctx, "Prepending intro to %s", filepath.Base(clip))
+		if err := run(ctx, "ffmpeg", args, p.QuietFlag); err != nil {
+			return nil, fmt.Errorf("failed to prepend intro to %s: %w", clip, err)
+		}
+		clips[filepath.Base(outputPath)] = outputPath
+	}
+
+	if len(clips) == 0 {
+		utils.LogWarningContext(ctx, "No clips match %s", pattern)
+	}
+	return clips, nil
+}
+
+// probeDuration reads the duration of a video with ffprobe, in seconds as printed by ffprobe
+func probeDuration(ctx context.Context, path string) (string, error) {
+	out, err := utils.OutputWatched(ctx, execCommand(ctx, "ffprobe", "-v", "error", "-show_entries", "format=duration", "-of", "csv=p=0", path))
+	if err != nil {
+		return "", fmt.Errorf("failed to probe intro duration: %w", err)
+	}
+	duration := strings.TrimSpace(string(out))
+	if seconds, err := strconv.ParseFloat(duration, 64); err != nil || seconds <= 0 {
+		return "", fmt.Errorf("failed to read intro duration from ffprobe output %q", duration)
+	}
+	return duration, nil
+}
+
+// run executes a command, logging its error output when quiet
+func run(ctx context.Context, name string, args []string, quiet bool) error {
+	cmd := execCommand(ctx, name, args...)
+	var stderr bytes.Buffer
+	if quiet {
+		cmd.Stderr = &stderr
+	} else {
+		cmd.Stdout = utils.LogWriter(ctx)
+		cmd.Stderr = os.Stderr
+	}
+	if err := utils.RunWatched(ctx, cmd); err != nil {
+		if stderr.Len() > 0 {
+			utils.LogErrorContext(ctx, "%s error: %s", name, stderr.String())
+		}
+		return fmt.Errorf("%s command failed: %w", name, err)
+	}
+	return nil
+}
+
+// GetIO returns the module's input/output specification
+func (m *Module) GetIO() modules.ModuleIO {
+	return modules.ModuleIO{
+		RequiredInputs: []modules.ModuleInput{
+			{
+				Name:        "output",
+				Description: "Path to output directory",
+				Type:        string(modules.InputTypeDirectory),
+			},
+		},
+		OptionalInputs: []modules.ModuleInput{
+			{
+				Name:        "title",
+				Description: "Title shown in the intro",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "template",
+				Description: "Lottie JSON template",
+				Patterns:    []string{".json"},
+				Type:        string(modules.InputTypeFile),
+			},
+			{
+				Name:        "renderer",
+				Description: "Command rendering the template",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "videos",
+				Description: "Glob of clips to prepend the intro to",
+				Type:        string(modules.InputTypeData),
+			},
+		},
+		ProducedOutputs: []modules.ModuleOutput{
+			{
+				Name:        "intro",
+				Description: "Rendered intro video",
+				Patterns:    []string{".mp4"},
+				Type:        string(modules.OutputTypeFile),
+			},
+			{
+				Name:        "clips",
+				Description: "Clips with the intro prepended",
+				Patterns:    []string{"-intro.mp4"},
+				Type:        string(modules.OutputTypeFile),
+			},
+		},
+	}
+}
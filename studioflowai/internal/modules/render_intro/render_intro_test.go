@@ -0,0 +1,164 @@
+package renderintro
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordedCommands holds every command run by a test, program first
+var recordedCommands [][]string
+
+// fakeExecCommand records the command and runs a helper process that writes its last argument
+func fakeExecCommand(ctx context.Context, command string, args ...string) *exec.Cmd {
+	recordedCommands = append(recordedCommands, append([]string{command}, args...))
+	cs := []string{"-test.run=TestHelperProcess", "--", command}
+	cs = append(cs, args...)
+	cmd := exec.CommandContext(ctx, os.Args[0], cs...)
+	cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1"}
+	return cmd
+}
+
+// TestHelperProcess is not a real test, it's used to mock exec.Command
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	args := os.Args
+	for i, arg := range args {
+		if arg == "--" {
+			args = args[i+1:]
+			break
+		}
+	}
+	if args[0] == "ffprobe" {
+		_, _ = os.Stdout.WriteString("3.000000\n")
+	} else {
+		_ = os.WriteFile(args[len(args)-1], []byte("video"), 0644)
+	}
+	os.Exit(0)
+}
+
+func TestModule_Name(t *testing.T) {
+	assert.Equal(t, "render_intro", New().Name())
+}
+
+func TestModule_Execute_TitleCard(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.CommandContext }()
+	recordedCommands = nil
+
+	output := t.TempDir()
+	result, err := New().Execute(context.Background(), map[string]interface{}{
+		"output":   output,
+		"metadata": map[string]interface{}{"title": "Ep 12: Sound design"},
+		"duration": 4,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(output, "intro.mp4"), result.Outputs["intro"])
+
+	require.Len(t, recordedCommands, 1)
+	args := strings.Join(recordedCommands[0], " ")
+	assert.Contains(t, args, "color=c=black:s=1080x1920:r=30:d=4")
+	assert.Contains(t, args, `text='Ep 12\: Sound design'`)
+	assert.Contains(t, args, "alpha='if(lt(t,0.5),t/0.5,if(gt(t,4-0.5),(4-t)/0.5,1))'")
+}
+
+func TestModule_Execute_LottieTemplate(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.CommandContext }()
+	recordedCommands = nil
+
+	dir := t.TempDir()
+	template := filepath.Join(dir, "intro.json")
+	lottie := `{"v":"5.7.4","fr":30,"layers":[{"ty":5,"t":{"d":{"k":[{"s":{"t":"{title}","f":"Inter"}},{"s":{"t":"EP {n} with {guest} {unknown}"}}]}}}]}`
+	require.NoError(t, os.WriteFile(template, []byte(lottie), 0644))
+	output := filepath.Join(dir, "output")
+
+	_, err := New().Execute(context.Background(), map[string]interface{}{
+		"output":   output,
+		"title":    "Sound design",
+		"template": template,
+		"renderer": "lottie-render --in {template} --out {output}",
+		"metadata": map[string]interface{}{"episode": 12, "guest": "Ana"},
+	})
+	require.NoError(t, err)
+
+	filledPath := filepath.Join(output, "intro_template.json")
+	require.Len(t, recordedCommands, 1)
+	assert.Equal(t, []string{"lottie-render", "--in", filledPath, "--out", filepath.Join(output, "intro.mp4")}, recordedCommands[0])
+
+	data, err := os.ReadFile(filledPath)
+	require.NoError(t, err)
+	var filled map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &filled))
+	texts := filled["layers"].([]interface{})[0].(map[string]interface{})["t"].(map[string]interface{})["d"].(map[string]interface{})["k"].([]interface{})
+	assert.Equal(t, "Sound design", texts[0].(map[string]interface{})["s"].(map[string]interface{})["t"])
+	assert.Equal(t, "EP 12 with Ana {unknown}", texts[1].(map[string]interface{})["s"].(map[string]interface{})["t"])
+}
+
+func TestModule_Execute_PrependIntro(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.CommandContext }()
+	recordedCommands = nil
+
+	output := t.TempDir()
+	for _, name := range []string{"000010-000040-withtext.mp4", "000100-000130-withtext.mp4", "000100-000130-withtext-intro.mp4"} {
+		require.NoError(t, os.WriteFile(filepath.Join(output, name), []byte("clip"), 0644))
+	}
+
+	result, err := New().Execute(context.Background(), map[string]interface{}{
+		"output": output,
+		"title":  "Sound design",
+		"videos": "${output}/*-withtext*.mp4",
+	})
+	require.NoError(t, err)
+
+	assert.Len(t, result.Outputs, 3)
+	assert.Equal(t, filepath.Join(output, "000010-000040-withtext-intro.mp4"), result.Outputs["000010-000040-withtext-intro.mp4"])
+	// Title card, ffprobe, then one concat per clip; clips that already have the intro are skipped
+	require.Len(t, recordedCommands, 4)
+	assert.Equal(t, "ffprobe", recordedCommands[1][0])
+	concat := strings.Join(recordedCommands[2], " ")
+	assert.Contains(t, concat, "-t 3.000000 -i anullsrc")
+	assert.Contains(t, concat, "scale2ref")
+}
+
+func TestModule_Validate(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		wantErr string
+	}{
+		{
+			name:    "missing title",
+			params:  map[string]interface{}{"output": dir},
+			wantErr: "title is required",
+		},
+		{
+			name:    "template not json",
+			params:  map[string]interface{}{"output": dir, "title": "x", "template": filepath.Join(dir, "intro.aep")},
+			wantErr: "file extension .aep not allowed",
+		},
+		{
+			name:    "missing template",
+			params:  map[string]interface{}{"output": dir, "title": "x", "template": filepath.Join(dir, "intro.json")},
+			wantErr: "template file does not exist",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := New().Validate(tt.params)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
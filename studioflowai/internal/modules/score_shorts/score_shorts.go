@@ -0,0 +1,424 @@
+package scoreshorts
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	modules "github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// execCommand allows us to mock exec.Command in tests
+var execCommand = utils.CommandContext
+
+// Default weights of the ranking signals
+const (
+	defaultLLMWeight    = 0.4
+	defaultEnergyWeight = 0.3
+	defaultPeakWeight   = 0.2
+	defaultFaceWeight   = 0.1
+)
+
+// Module implements signal-based ranking of suggested shorts
+type Module struct{}
+
+// Params contains the parameters for clip quality scoring
+type Params struct {
+	Input             string  `json:"input"`                                       // Path to shorts_suggestions.yaml file
+	Output            string  `json:"output"`                                      // Path to output directory
+	VideoFile         string  `json:"videoFile"`                                   // Path to the source video (default: sourceVideo from the shorts file)
+	OutputFileName    string  `json:"outputFileName" default:"shorts_suggestions"` // Name of the ranked shorts file, without extension (default: "shorts_suggestions")
+	MaxShorts         int     `json:"maxShorts"`                                   // Keep only the best N clips after ranking (default: keep all)
+	FaceDetector      string  `json:"faceDetector"`                                // Command printing the number of faces in the image passed as its last argument (optional)
+	FaceSampleSeconds float64 `json:"faceSampleSeconds" default:"2"`               // Seconds between frames sampled for face detection (default: 2)
+	LLMWeight         float64 `json:"llmWeight" default:"0.4"`                     // Weight of the model's original ordering (default: 0.4)
+	EnergyWeight      float64 `json:"energyWeight" default:"0.3"`                  // Weight of loudness and dynamics (default: 0.3)
+	PeakWeight        float64 `json:"peakWeight" default:"0.2"`                    // Weight of laughter/applause-like bursts (default: 0.2)
+	FaceWeight        float64 `json:"faceWeight" default:"0.1"`                    // Weight of face presence, used with faceDetector (default: 0.1)
+	UseProxy          bool    `json:"useProxy" default:"true"`                     // Measure the clips on the make_proxy copy of the video when there is one (default: true)
+}
+
+// ClipScore is written under "score" on every clip of the ranked shorts file
+type ClipScore struct {
+	Total          float64  `yaml:"total"`           // Weighted score the clips are ordered by
+	LLMRank        int      `yaml:"llmRank"`         // Position in the model's original suggestions (1 = first)
+	LLM            float64  `yaml:"llm"`             // Score derived from LLMRank
+	Energy         float64  `yaml:"energy"`          // Loudness and dynamics relative to the other clips
+	Peaks          float64  `yaml:"peaks"`           // Burst rate relative to the other clips
+	Faces          *float64 `yaml:"faces,omitempty"` // Fraction of sampled frames showing a face
+	LoudnessDB     float64  `yaml:"loudnessDb"`      // Mean RMS level in dBFS
+	PeaksPerMinute float64  `yaml:"peaksPerMinute"`  // Bursts per minute of audio
+}
+
+// candidate is one suggested clip with the signals measured on its window
+type candidate struct {
+	node     *yaml.Node // Clip mapping in the shorts file
+	title    string
+	llmRank  int
+	audio    AudioStats
+	faces    float64
+	hasFaces bool
+	score    ClipScore
+}
+
+// weights holds the relative importance of each ranking signal
+type weights struct {
+	llm, energy, peaks, faces float64
+}
+
+// New creates a new clip scoring module
+func New() modules.Module {
+	return &Module{}
+}
+
+// Name returns the module name
+func (m *Module) Name() string {
+	return "score_shorts"
+}
+
+// ParamsTemplate returns the module's parameter struct, used to validate and document workflows
+func (m *Module) ParamsTemplate() interface{} {
+	return Params{}
+}
+
+// Validate checks if the parameters are valid
+func (m *Module) Validate(params map[string]interface{}) error {
+	var p Params
+	if err := modules.ParseParams(params, &p); err != nil {
+		return err
+	}
+
+	// Validate input path
+	if err := utils.ValidateInputPath(p.Input, p.Output, ""); err != nil {
+		return err
+	}
+
+	// Validate output path
+	if err := utils.ValidateOutputPath(p.Output); err != nil {
+		return err
+	}
+
+	// The source video may not exist yet when it is produced by an earlier step
+	if p.VideoFile != "" && !utils.ProducedByWorkflow(p.VideoFile, p.Output) {
+		if _, err := os.Stat(p.VideoFile); os.IsNotExist(err) {
+			return fmt.Errorf("video file does not exist: %s", p.VideoFile)
+		}
+	}
+
+	if p.MaxShorts < 0 || p.FaceSampleSeconds < 0 {
+		return fmt.Errorf("maxShorts and faceSampleSeconds must not be negative")
+	}
+	if p.LLMWeight < 0 || p.EnergyWeight < 0 || p.PeakWeight < 0 || p.FaceWeight < 0 {
+		return fmt.Errorf("weights must not be negative")
+	}
+
+	// Validate FFmpeg dependency
+	if err := utils.ValidateRequiredDependency("ffmpeg"); err != nil {
+		return err
+	}
+	if detector := strings.Fields(p.FaceDetector); len(detector) > 0 {
+		if err := utils.ValidateRequiredDependency(detector[0]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Execute measures audio energy, bursts and face presence of every suggested clip and reorders the clips by score
+func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (modules.ModuleResult, error) {
+	var p Params
+	if err := modules.ParseParams(params, &p); err != nil {
+		return modules.ModuleResult{}, err
+	}
+
+	// Set default values
+	if p.OutputFileName == "" {
+		p.OutputFileName = "shorts_suggestions"
+	}
+	if p.FaceSampleSeconds == 0 {
+		p.FaceSampleSeconds = 2
+	}
+	w := weights{llm: p.LLMWeight, energy: p.EnergyWeight, peaks: p.PeakWeight, faces: p.FaceWeight}
+	if w.llm == 0 {
+		w.llm = defaultLLMWeight
+	}
+	if w.energy == 0 {
+		w.energy = defaultEnergyWeight
+	}
+	if w.peaks == 0 {
+		w.peaks = defaultPeakWeight
+	}
+	if w.faces == 0 {
+		w.faces = defaultFaceWeight
+	}
+	if _, exists := params["useProxy"]; !exists {
+		p.UseProxy = true
+	}
+
+	// The file is edited as a node tree so fields added by other steps survive the rewrite
+	resolvedInput := utils.ResolveOutputPath(p.Input, p.Output)
+	doc, err := utils.ReadShortsDocument(resolvedInput)
+	if err != nil {
+		return modules.ModuleResult{}, err
+	}
+	shortsNode := doc.Shorts
+
+	videoFile := utils.ResolveOutputPath(p.VideoFile, p.Output)
+	if videoFile == "" {
+		videoFile = doc.SourceVideo()
+	}
+	if videoFile == "" {
+		return modules.ModuleResult{}, fmt.Errorf("videoFile is required when the shorts file has no sourceVideo")
+	}
+	if p.UseProxy {
+		if proxy, ok := utils.FindProxy(videoFile, filepath.Dir(resolvedInput), p.Output); ok {
+			utils.LogInfoContext(ctx, "Measuring the clips on the proxy %s", proxy)
+			videoFile = proxy
+		}
+	}
+
+	candidates := make([]*candidate, 0, len(shortsNode.Content))
+	for i, clip := range shortsNode.Content {
+		c, err := m.measureClip(ctx, clip, i, videoFile, p)
+		if err != nil {
+			return modules.ModuleResult{}, err
+		}
+		candidates = append(candidates, c)
+	}
+
+	rankCandidates(candidates, w)
+
+	if p.MaxShorts > 0 && len(candidates) > p.MaxShorts {
+		utils.LogInfoContext(ctx, "Keeping the %d best of %d clips", p.MaxShorts, len(candidates))
+		candidates = candidates[:p.MaxShorts]
+	}
+
+	shortsNode.Content = shortsNode.Content[:0]
+	for _, c := range candidates {
+		var scoreNode yaml.Node
+		if err := scoreNode.Encode(c.score); err != nil {
+			return modules.ModuleResult{}, fmt.Errorf("failed to encode score: %w", err)
+		}
+		utils.SetMappingValue(c.node, "score", &scoreNode)
+		shortsNode.Content = append(shortsNode.Content, c.node)
+	}
+
+	out, err := doc.Marshal()
+	if err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to generate YAML: %w", err)
+	}
+
+	if err := utils.EnsureDir(p.Output); err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	outputPath := filepath.Join(p.Output, p.OutputFileName+".yaml")
+	if err := utils.AtomicWriteFile(outputPath, out, 0644); err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to write output file: %w", err)
+	}
+
+	utils.LogSuccessContext(ctx, "Ranked %d clips, best: %s (%.2f)", len(candidates), candidates[0].title, candidates[0].score.Total)
+
+	return modules.ModuleResult{
+		Outputs: map[string]string{
+			"suggestions": outputPath,
+		},
+		Metadata: map[string]interface{}{
+			"inputFile":     resolvedInput,
+			"sourceVideo":   videoFile,
+			"numShorts":     len(candidates),
+			"faceDetection": p.FaceDetector != "",
+			"topClip":       candidates[0].title,
+		},
+		Stats: modules.Stats{Items: len(candidates)},
+	}, nil
+}
+
+// measureClip reads the window of a clip and measures its signals
+func (m *Module) measureClip(ctx context.Context, clip *yaml.Node, index int, videoFile string, p Params) (*candidate, error) {
+	c := &candidate{node: clip, llmRank: index + 1, title: utils.ClipField(clip, "title")}
+
+	// A previous run stored the model's ordering; keep it so rescoring does not compound
+	if score := utils.MappingValue(clip, "score"); score != nil {
+		var previous ClipScore
+		if err := score.Decode(&previous); err == nil && previous.LLMRank > 0 {
+			c.llmRank = previous.LLMRank
+		}
+	}
+
+	startTime, endTime := utils.ClipField(clip, "startTime"), utils.ClipField(clip, "endTime")
+	start, err := utils.ParseTimestamp(startTime)
+	if err != nil {
+		return nil, fmt.Errorf("clip %d: invalid startTime: %w", index+1, err)
+	}
+	end, err := utils.ParseTimestamp(endTime)
+	if err != nil {
+		return nil, fmt.Errorf("clip %d: invalid endTime: %w", index+1, err)
+	}
+	if end <= start {
+		return nil, fmt.Errorf("clip %d: endTime %s must be after startTime %s", index+1, endTime, startTime)
+	}
+
+	utils.LogVerboseContext(ctx, "Scoring clip %d: %s (%s to %s)", index+1, c.title, startTime, endTime)
+
+	c.audio, err = analyzeAudio(ctx, videoFile, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("clip %d: %w", index+1, err)
+	}
+
+	if p.FaceDetector != "" {
+		c.faces, err = detectFaces(ctx, videoFile, p.FaceDetector, start, end, p.FaceSampleSeconds)
+		if err != nil {
+			return nil, fmt.Errorf("clip %d: %w", index+1, err)
+		}
+		c.hasFaces = true
+	}
+
+	return c, nil
+}
+
+// rankCandidates scores the candidates relative to each other and sorts them best first.
+// Audio signals are min-max normalized across the candidates, so scores rank clips of one video
+// and are not comparable between videos.
+func rankCandidates(candidates []*candidate, w weights) {
+	if len(candidates) == 0 {
+		return
+	}
+
+	loudness := make([]float64, len(candidates))
+	dynamics := make([]float64, len(candidates))
+	peaks := make([]float64, len(candidates))
+	for i, c := range candidates {
+		loudness[i] = c.audio.MeanDB
+		dynamics[i] = c.audio.Dynamics
+		peaks[i] = c.audio.PeaksPerMinute()
+	}
+	loudness = normalize(loudness)
+	dynamics = normalize(dynamics)
+	peaks = normalize(peaks)
+
+	maxRank := 0
+	for _, c := range candidates {
+		if c.llmRank > maxRank {
+			maxRank = c.llmRank
+		}
+	}
+
+	for i, c := range candidates {
+		score := ClipScore{
+			LLMRank:        c.llmRank,
+			LLM:            float64(maxRank-c.llmRank+1) / float64(maxRank),
+			Energy:         (loudness[i] + dynamics[i]) / 2,
+			Peaks:          peaks[i],
+			LoudnessDB:     c.audio.MeanDB,
+			PeaksPerMinute: c.audio.PeaksPerMinute(),
+		}
+
+		total := w.llm*score.LLM + w.energy*score.Energy + w.peaks*score.Peaks
+		weightSum := w.llm + w.energy + w.peaks
+		if c.hasFaces {
+			faces := round(c.faces)
+			score.Faces = &faces
+			total += w.faces * c.faces
+			weightSum += w.faces
+		}
+		if weightSum > 0 {
+			score.Total = total / weightSum
+		}
+
+		score.Total = round(score.Total)
+		score.LLM = round(score.LLM)
+		score.Energy = round(score.Energy)
+		score.Peaks = round(score.Peaks)
+		score.LoudnessDB = round(score.LoudnessDB)
+		score.PeaksPerMinute = round(score.PeaksPerMinute)
+		c.score = score
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].score.Total != candidates[j].score.Total {
+			return candidates[i].score.Total > candidates[j].score.Total
+		}
+		return candidates[i].llmRank < candidates[j].llmRank
+	})
+}
+
+// normalize scales values to 0-1; identical values all score 0.5
+func normalize(values []float64) []float64 {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+
+	scaled := make([]float64, len(values))
+	for i, v := range values {
+		if hi-lo < 1e-9 {
+			scaled[i] = 0.5
+		} else {
+			scaled[i] = (v - lo) / (hi - lo)
+		}
+	}
+	return scaled
+}
+
+// round keeps three decimals for readable YAML
+func round(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
+
+// GetIO returns the module's input/output specification
+func (m *Module) GetIO() modules.ModuleIO {
+	return modules.ModuleIO{
+		RequiredInputs: []modules.ModuleInput{
+			{
+				Name:        "input",
+				Description: "Path to shorts suggestions YAML file",
+				Patterns:    []string{".yaml"},
+				Type:        string(modules.InputTypeFile),
+			},
+			{
+				Name:        "output",
+				Description: "Path to output directory",
+				Type:        string(modules.InputTypeDirectory),
+			},
+		},
+		OptionalInputs: []modules.ModuleInput{
+			{
+				Name:        "videoFile",
+				Description: "Path to source video file (default: sourceVideo from the shorts file)",
+				Patterns:    []string{".mp4", ".mov"},
+				Type:        string(modules.InputTypeFile),
+			},
+			{
+				Name:        "useProxy",
+				Description: "Measure the clips on the make_proxy copy of the video when there is one (default: true)",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "faceDetector",
+				Description: "Command printing the number of faces in a frame image",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "maxShorts",
+				Description: "Keep only the best N clips after ranking",
+				Type:        string(modules.InputTypeData),
+			},
+		},
+		ProducedOutputs: []modules.ModuleOutput{
+			{
+				Name:        "suggestions",
+				Description: "Shorts suggestions ordered by score, with per-clip signal scores",
+				Patterns:    []string{".yaml"},
+				Type:        string(modules.OutputTypeFile),
+			},
+		},
+	}
+}
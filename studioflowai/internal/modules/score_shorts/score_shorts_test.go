@@ -0,0 +1,294 @@
+package scoreshorts
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/shortstest"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const shortsYAML = `shorts:
+  - title: "Quiet intro"
+    startTime: "00:00:10"
+    endTime: "00:00:20"
+    description: "Host reads the agenda"
+    tags: "intro"
+    shortTitle: "Agenda"
+  - title: "Crowd laughs"
+    startTime: "00:01:00"
+    endTime: "00:01:10"
+    description: "The joke lands"
+    tags: "comedy"
+    shortTitle: "The joke"
+`
+
+// fakeExecCommand returns a helper process standing in for ffmpeg and the face detector
+func fakeExecCommand(ctx context.Context, command string, args ...string) *exec.Cmd {
+	cs := []string{"-test.run=TestHelperProcess", "--", command}
+	cs = append(cs, args...)
+	cmd := exec.Command(os.Args[0], cs...)
+	cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1"}
+	return cmd
+}
+
+// TestHelperProcess is not a real test, it's used to mock exec.Command
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	args := os.Args
+	for i, arg := range args {
+		if arg == "--" {
+			args = args[i+1:]
+			break
+		}
+	}
+
+	switch args[0] {
+	case "ffmpeg":
+		start := ""
+		for i, arg := range args {
+			if arg == "-ss" && i+1 < len(args) {
+				start = args[i+1]
+			}
+		}
+		if args[len(args)-1] == "-" {
+			// Audio analysis: the clip at one minute is bursty, every other clip is flat
+			_ = binary.Write(os.Stdout, binary.LittleEndian, syntheticAudio(start == "60.000"))
+		} else {
+			// Frame sampling: write two frames into the requested pattern
+			pattern := args[len(args)-1]
+			for i := 1; i <= 2; i++ {
+				name := strings.Replace(pattern, "%04d", fmt.Sprintf("%04d", i), 1)
+				_ = os.WriteFile(name, []byte("jpg"), 0644)
+			}
+		}
+	case "facecount":
+		fmt.Println("1")
+	}
+	os.Exit(0)
+}
+
+// syntheticAudio returns ten seconds of low speech-level noise, with half-second bursts every two seconds when bursty
+func syntheticAudio(bursty bool) []int16 {
+	samples := make([]int16, 10*analysisSampleRate)
+	for i := range samples {
+		amplitude := 1000
+		if bursty && (i/(analysisSampleRate/2))%4 == 1 {
+			amplitude = 16000
+		}
+		if i%2 == 0 {
+			samples[i] = int16(amplitude)
+		} else {
+			samples[i] = int16(-amplitude)
+		}
+	}
+	return samples
+}
+
+func TestModule_Name(t *testing.T) {
+	assert.Equal(t, "score_shorts", New().Name())
+}
+
+func TestModule_GetIO(t *testing.T) {
+	io := New().GetIO()
+
+	assert.Len(t, io.RequiredInputs, 2)
+	assert.Equal(t, "input", io.RequiredInputs[0].Name)
+	assert.Equal(t, "output", io.RequiredInputs[1].Name)
+
+	assert.Len(t, io.ProducedOutputs, 1)
+	assert.Equal(t, "suggestions", io.ProducedOutputs[0].Name)
+}
+
+func TestModule_Validate(t *testing.T) {
+	defer func() { utils.ExecLookPath = exec.LookPath }()
+	utils.ExecLookPath = func(file string) (string, error) { return file, nil }
+
+	tempDir := t.TempDir()
+	shortsPath := shortstest.WriteFile(t, tempDir, "/videos/source.mp4", shortsYAML)
+
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		wantErr string
+	}{
+		{
+			name:   "valid defaults",
+			params: map[string]interface{}{"input": shortsPath, "output": tempDir},
+		},
+		{
+			name:    "missing video file",
+			params:  map[string]interface{}{"input": shortsPath, "output": tempDir, "videoFile": filepath.Join(t.TempDir(), "missing.mp4")},
+			wantErr: "video file does not exist",
+		},
+		{
+			name:   "video file produced by an earlier step",
+			params: map[string]interface{}{"input": shortsPath, "output": tempDir, "videoFile": filepath.Join(tempDir, "episode_proxy.mp4")},
+		},
+		{
+			name:    "negative weight",
+			params:  map[string]interface{}{"input": shortsPath, "output": tempDir, "peakWeight": -1},
+			wantErr: "weights must not be negative",
+		},
+		{
+			name:    "negative max shorts",
+			params:  map[string]interface{}{"input": shortsPath, "output": tempDir, "maxShorts": -2},
+			wantErr: "must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := New().Validate(tt.params)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAudioStatsFromPCM(t *testing.T) {
+	flat := audioStatsFromPCM(syntheticAudio(false), analysisSampleRate)
+	bursty := audioStatsFromPCM(syntheticAudio(true), analysisSampleRate)
+
+	assert.InDelta(t, 10, flat.Duration, 0.001)
+	assert.Equal(t, 0, flat.Peaks)
+	assert.InDelta(t, 0, flat.Dynamics, 0.001)
+
+	assert.Equal(t, 5, bursty.Peaks)
+	assert.Greater(t, bursty.MeanDB, flat.MeanDB)
+	assert.Greater(t, bursty.Dynamics, flat.Dynamics)
+	assert.InDelta(t, 30, bursty.PeaksPerMinute(), 0.001)
+
+	silent := audioStatsFromPCM(make([]int16, analysisSampleRate), analysisSampleRate)
+	assert.Equal(t, silenceFloorDB, silent.MeanDB)
+	assert.Equal(t, 0, silent.Peaks)
+}
+
+func TestRankCandidates(t *testing.T) {
+	w := weights{llm: defaultLLMWeight, energy: defaultEnergyWeight, peaks: defaultPeakWeight, faces: defaultFaceWeight}
+
+	t.Run("energetic clip overtakes flat narration", func(t *testing.T) {
+		candidates := []*candidate{
+			{title: "narration", llmRank: 1, audio: AudioStats{MeanDB: -30, Dynamics: 1, Duration: 30}},
+			{title: "laughter", llmRank: 2, audio: AudioStats{MeanDB: -18, Dynamics: 9, Peaks: 4, Duration: 30}},
+		}
+		rankCandidates(candidates, w)
+
+		assert.Equal(t, "laughter", candidates[0].title)
+		assert.Equal(t, 2, candidates[0].score.LLMRank)
+		assert.InDelta(t, 0.778, candidates[0].score.Total, 0.001)
+		assert.InDelta(t, 0.444, candidates[1].score.Total, 0.001)
+		assert.Nil(t, candidates[0].score.Faces)
+	})
+
+	t.Run("identical audio keeps model order", func(t *testing.T) {
+		candidates := []*candidate{
+			{title: "first", llmRank: 1, audio: AudioStats{MeanDB: -20, Duration: 30}},
+			{title: "second", llmRank: 2, audio: AudioStats{MeanDB: -20, Duration: 30}},
+		}
+		rankCandidates(candidates, w)
+		assert.Equal(t, "first", candidates[0].title)
+	})
+
+	t.Run("face presence breaks ties", func(t *testing.T) {
+		candidates := []*candidate{
+			{title: "screen", llmRank: 1, audio: AudioStats{MeanDB: -20, Duration: 30}, hasFaces: true, faces: 0},
+			{title: "talking head", llmRank: 1, audio: AudioStats{MeanDB: -20, Duration: 30}, hasFaces: true, faces: 1},
+		}
+		rankCandidates(candidates, w)
+		assert.Equal(t, "talking head", candidates[0].title)
+		require.NotNil(t, candidates[0].score.Faces)
+		assert.Equal(t, 1.0, *candidates[0].score.Faces)
+	})
+}
+
+func TestModule_Execute(t *testing.T) {
+	defer func() { execCommand = exec.CommandContext }()
+	execCommand = fakeExecCommand
+
+	tempDir := t.TempDir()
+	videoPath := filepath.Join(tempDir, "source.mp4")
+	require.NoError(t, os.WriteFile(videoPath, []byte("dummy"), 0644))
+
+	t.Run("ranks clips by signal score", func(t *testing.T) {
+		shortsPath := shortstest.WriteFile(t, tempDir, videoPath, shortsYAML)
+		result, err := New().Execute(context.Background(), map[string]interface{}{
+			"input":        shortsPath,
+			"output":       tempDir,
+			"faceDetector": "facecount --min-size 40",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, shortsPath, result.Outputs["suggestions"])
+		assert.Equal(t, "Crowd laughs", result.Metadata["topClip"])
+
+		shortsData, err := utils.ReadShortsFile(shortsPath)
+		require.NoError(t, err)
+		require.Len(t, shortsData.Shorts, 2)
+		assert.Equal(t, "Crowd laughs", shortsData.Shorts[0].Title)
+		assert.Equal(t, "The joke", shortsData.Shorts[0].ShortTitle)
+
+		var scored struct {
+			Shorts []struct {
+				Score ClipScore `yaml:"score"`
+			} `yaml:"shorts"`
+		}
+		data, err := os.ReadFile(shortsPath)
+		require.NoError(t, err)
+		require.NoError(t, yaml.Unmarshal(data, &scored))
+		assert.Equal(t, 2, scored.Shorts[0].Score.LLMRank)
+		require.NotNil(t, scored.Shorts[0].Score.Faces)
+		assert.Equal(t, 1.0, *scored.Shorts[0].Score.Faces)
+
+		// Rescoring keeps the model's original ordering as the prior
+		_, err = New().Execute(context.Background(), map[string]interface{}{
+			"input":     shortsPath,
+			"output":    tempDir,
+			"maxShorts": 1,
+		})
+		require.NoError(t, err)
+		data, err = os.ReadFile(shortsPath)
+		require.NoError(t, err)
+		require.NoError(t, yaml.Unmarshal(data, &scored))
+		require.Len(t, scored.Shorts, 1)
+		assert.Equal(t, 2, scored.Shorts[0].Score.LLMRank)
+	})
+
+	t.Run("requires a video", func(t *testing.T) {
+		shortsPath := shortstest.WriteFile(t, tempDir, "${source_video}", shortsYAML)
+		_, err := New().Execute(context.Background(), map[string]interface{}{
+			"input":  shortsPath,
+			"output": tempDir,
+		})
+		assert.ErrorContains(t, err, "videoFile is required")
+	})
+
+	t.Run("rejects invalid timestamps", func(t *testing.T) {
+		path := filepath.Join(tempDir, "broken.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("shorts:\n  - title: x\n    startTime: \"00:00:20\"\n    endTime: \"00:00:10\"\n"), 0644))
+		_, err := New().Execute(context.Background(), map[string]interface{}{
+			"input":     path,
+			"output":    tempDir,
+			"videoFile": videoPath,
+		})
+		assert.ErrorContains(t, err, "must be after startTime")
+	})
+}
+
+func TestFormatSeconds(t *testing.T) {
+	assert.Equal(t, "62.500", formatSeconds(62*time.Second+500*time.Millisecond))
+}
@@ -0,0 +1,198 @@
+package scoreshorts
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+const (
+	analysisSampleRate = 16000 // Mono sample rate the audio is decoded at
+	frameDuration      = 0.1   // Seconds of audio per loudness frame
+	silenceFloorDB     = -90.0 // Level assigned to digital silence
+	peakThresholdDB    = 6.0   // Level above the clip median that counts as a burst
+	minPeakFrames      = 2     // Shortest burst counted as a peak (0.2s)
+	maxPeakFrames      = 30    // Longest burst counted as a peak (3s); longer ones are sustained loudness
+)
+
+// AudioStats summarizes the loudness of a clip window
+type AudioStats struct {
+	MeanDB   float64 // Mean RMS level in dBFS
+	Dynamics float64 // Standard deviation of the frame levels in dB; flat narration stays low
+	Peaks    int     // Short bursts above the clip baseline, such as laughter or applause
+	Duration float64 // Seconds of audio analyzed
+}
+
+// PeaksPerMinute normalizes the peak count by the clip length
+func (s AudioStats) PeaksPerMinute() float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+	return float64(s.Peaks) / (s.Duration / 60)
+}
+
+// analyzeAudio decodes the audio of a window with ffmpeg and measures its loudness
+func analyzeAudio(ctx context.Context, videoFile string, start, end time.Duration) (AudioStats, error) {
+	cmd := execCommand(ctx, "ffmpeg",
+		"-v", "error",
+		"-ss", formatSeconds(start),
+		"-t", formatSeconds(end-start),
+		"-i", videoFile,
+		"-vn", "-ac", "1", "-ar", strconv.Itoa(analysisSampleRate),
+		"-f", "s16le", "-",
+	)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := utils.RunWatched(ctx, cmd); err != nil {
+		if stderr.Len() > 0 {
+			utils.LogErrorContext(ctx, "FFmpeg error: %s", stderr.String())
+		}
+		return AudioStats{}, fmt.Errorf("ffmpeg audio analysis failed: %w", err)
+	}
+
+	raw := stdout.Bytes()
+	samples := make([]int16, len(raw)/2)
+	if err := binary.Read(bytes.NewReader(raw[:len(samples)*2]), binary.LittleEndian, samples); err != nil {
+		return AudioStats{}, fmt.Errorf("failed to decode audio samples: %w", err)
+	}
+	return audioStatsFromPCM(samples, analysisSampleRate), nil
+}
+
+// audioStatsFromPCM computes loudness statistics from mono 16-bit samples
+func audioStatsFromPCM(samples []int16, sampleRate int) AudioStats {
+	frameSize := int(float64(sampleRate) * frameDuration)
+	if frameSize <= 0 || len(samples) < frameSize {
+		return AudioStats{MeanDB: silenceFloorDB, Duration: float64(len(samples)) / float64(sampleRate)}
+	}
+
+	var levels []float64
+	var energy float64
+	for offset := 0; offset+frameSize <= len(samples); offset += frameSize {
+		var sum float64
+		for _, s := range samples[offset : offset+frameSize] {
+			v := float64(s) / math.MaxInt16
+			sum += v * v
+		}
+		meanSquare := sum / float64(frameSize)
+		energy += meanSquare
+		levels = append(levels, toDB(meanSquare))
+	}
+
+	stats := AudioStats{
+		MeanDB:   toDB(energy / float64(len(levels))),
+		Duration: float64(len(samples)) / float64(sampleRate),
+	}
+
+	var mean float64
+	for _, l := range levels {
+		mean += l
+	}
+	mean /= float64(len(levels))
+	var variance float64
+	for _, l := range levels {
+		variance += (l - mean) * (l - mean)
+	}
+	stats.Dynamics = math.Sqrt(variance / float64(len(levels)))
+
+	// Bursts are measured against the median so a few loud frames do not raise their own baseline
+	sorted := append([]float64(nil), levels...)
+	sort.Float64s(sorted)
+	threshold := sorted[len(sorted)/2] + peakThresholdDB
+
+	run := 0
+	for _, l := range append(levels, silenceFloorDB) {
+		if l > threshold {
+			run++
+			continue
+		}
+		if run >= minPeakFrames && run <= maxPeakFrames {
+			stats.Peaks++
+		}
+		run = 0
+	}
+
+	return stats
+}
+
+// toDB converts a mean square amplitude into dBFS
+func toDB(meanSquare float64) float64 {
+	if meanSquare <= 0 {
+		return silenceFloorDB
+	}
+	return math.Max(silenceFloorDB, 10*math.Log10(meanSquare))
+}
+
+// detectFaces samples frames of a window and returns the fraction in which the detector found a face.
+// The detector is run as "<command> <frame.jpg>" and must print the number of faces found.
+func detectFaces(ctx context.Context, videoFile, detector string, start, end time.Duration, interval float64) (float64, error) {
+	detectorArgs := strings.Fields(detector)
+	if len(detectorArgs) == 0 {
+		return 0, fmt.Errorf("face detector command is empty")
+	}
+
+	frameDir, err := utils.MakeTempDir("", "faces")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create frame directory: %w", err)
+	}
+	defer os.RemoveAll(frameDir)
+
+	cmd := execCommand(ctx, "ffmpeg",
+		"-v", "error",
+		"-ss", formatSeconds(start),
+		"-t", formatSeconds(end-start),
+		"-i", videoFile,
+		"-vf", fmt.Sprintf("fps=1/%s,scale=640:-2", strconv.FormatFloat(interval, 'f', -1, 64)),
+		"-q:v", "4",
+		filepath.Join(frameDir, "frame_%04d.jpg"),
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := utils.RunWatched(ctx, cmd); err != nil {
+		if stderr.Len() > 0 {
+			utils.LogErrorContext(ctx, "FFmpeg error: %s", stderr.String())
+		}
+		return 0, fmt.Errorf("ffmpeg frame sampling failed: %w", err)
+	}
+
+	frames, err := filepath.Glob(filepath.Join(frameDir, "frame_*.jpg"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list sampled frames: %w", err)
+	}
+	if len(frames) == 0 {
+		return 0, nil
+	}
+
+	withFaces := 0
+	for _, frame := range frames {
+		args := append(append([]string(nil), detectorArgs[1:]...), frame)
+		out, err := utils.OutputWatched(ctx, execCommand(ctx, detectorArgs[0], args...))
+		if err != nil {
+			return 0, fmt.Errorf("face detector failed on %s: %w", filepath.Base(frame), err)
+		}
+		count, err := strconv.Atoi(strings.TrimSpace(string(out)))
+		if err != nil {
+			return 0, fmt.Errorf("face detector printed %q, expected the number of faces", strings.TrimSpace(string(out)))
+		}
+		if count > 0 {
+			withFaces++
+		}
+	}
+
+	return float64(withFaces) / float64(len(frames)), nil
+}
+
+// formatSeconds renders a duration as seconds for ffmpeg's -ss and -t options
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
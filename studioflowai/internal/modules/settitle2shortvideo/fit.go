@@ -0,0 +1,215 @@
+package settitle2shortvideo
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+// defaultFrameWidth is assumed when the width of a clip cannot be probed
+const defaultFrameWidth = 1080
+
+// titleToken is a piece of a title that may start a new line
+type titleToken struct {
+	text        string
+	spaceBefore bool
+}
+
+// runeWidth approximates the advance width of a character, as a fraction of the font size,
+// in a proportional sans-serif font. CJK characters and emoji are square.
+func runeWidth(r rune) float64 {
+	switch {
+	case isWideRune(r):
+		return 1.0
+	case r == ' ' || strings.ContainsRune("il.,:;'!|`", r):
+		return 0.28
+	case strings.ContainsRune("fjtrI()[]-", r):
+		return 0.36
+	case r == 'm' || r == 'w' || r == 'M' || r == 'W':
+		return 0.85
+	case unicode.IsUpper(r):
+		return 0.68
+	default:
+		return 0.56
+	}
+}
+
+// isWideRune reports whether a character is full-width, which also makes it a line break opportunity
+func isWideRune(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) ||
+		(r >= 0xFF01 && r <= 0xFF60) || (r >= 0x3000 && r <= 0x303F) || r >= 0x1F300
+}
+
+// textWidth estimates the rendered width of text in pixels
+func textWidth(text string, fontSize int) float64 {
+	width := 0.0
+	for _, r := range text {
+		width += runeWidth(r)
+	}
+	return width * float64(fontSize)
+}
+
+// splitTitle breaks a title into words, with every full-width character a token of its own
+func splitTitle(title string) []titleToken {
+	var tokens []titleToken
+	for _, word := range strings.Fields(title) {
+		space := true
+		var run strings.Builder
+		flush := func() {
+			if run.Len() > 0 {
+				tokens = append(tokens, titleToken{text: run.String(), spaceBefore: space})
+				run.Reset()
+				space = false
+			}
+		}
+		for _, r := range word {
+			if isWideRune(r) {
+				flush()
+				tokens = append(tokens, titleToken{text: string(r), spaceBefore: space})
+				space = false
+				continue
+			}
+			run.WriteRune(r)
+		}
+		flush()
+	}
+	return tokens
+}
+
+// wrapTokens fills lines up to maxWidth, breaking words that do not fit on a line of their own
+func wrapTokens(tokens []titleToken, fontSize int, maxWidth float64) []string {
+	var lines []string
+	var line strings.Builder
+	for _, token := range tokens {
+		candidate := token.text
+		if line.Len() > 0 && token.spaceBefore {
+			candidate = " " + candidate
+		}
+		if line.Len() > 0 && textWidth(line.String()+candidate, fontSize) > maxWidth {
+			lines = append(lines, line.String())
+			line.Reset()
+			candidate = token.text
+		}
+		for line.Len() == 0 && textWidth(candidate, fontSize) > maxWidth {
+			// Break an overlong word at the last character that fits
+			cut := 0
+			for i, r := range candidate {
+				if textWidth(candidate[:i+len(string(r))], fontSize) > maxWidth {
+					break
+				}
+				cut = i + len(string(r))
+			}
+			if cut == 0 {
+				_, cut = firstRune(candidate)
+			}
+			lines = append(lines, candidate[:cut])
+			candidate = candidate[cut:]
+		}
+		line.WriteString(candidate)
+	}
+	if line.Len() > 0 {
+		lines = append(lines, line.String())
+	}
+	return lines
+}
+
+// firstRune returns the first character of s and its length in bytes
+func firstRune(s string) (rune, int) {
+	for _, r := range s {
+		return r, len(string(r))
+	}
+	return 0, 0
+}
+
+// balanceTokens wraps tokens into as few lines as wrapTokens, but with line widths as even as
+// possible, so a two-line title does not end with a single orphaned word
+func balanceTokens(tokens []titleToken, fontSize int, maxWidth float64) []string {
+	lines := wrapTokens(tokens, fontSize, maxWidth)
+	if len(lines) < 2 {
+		return lines
+	}
+	lo, hi := 0.0, maxWidth
+	for i := 0; i < 20; i++ {
+		mid := (lo + hi) / 2
+		if len(wrapTokens(tokens, fontSize, mid)) <= len(lines) {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return wrapTokens(tokens, fontSize, hi)
+}
+
+// fitTitle wraps a title into at most p.MaxLines balanced lines of maxWidth pixels, shrinking the
+// font from p.FontSize down to p.MinFontSize until it fits
+func fitTitle(ctx context.Context, title string, p Params, maxWidth float64) ([]string, int) {
+	tokens := splitTitle(title)
+	for size := p.FontSize; ; size-- {
+		lines := balanceTokens(tokens, size, maxWidth)
+		if len(lines) <= p.MaxLines {
+			return lines, size
+		}
+		if size <= p.MinFontSize {
+			utils.LogWarningContext(ctx, "Title %q needs %d lines at the minimum font size %d (maxLines: %d)",
+				title, len(lines), size, p.MaxLines)
+			return lines, size
+		}
+	}
+}
+
+// titleMaxWidth returns the width in pixels available to a title line inside the platform's safe area
+func titleMaxWidth(frameWidth int, p Params) float64 {
+	area := utils.SafeAreas[p.Platform]
+	return float64(frameWidth)*(1-area.Left-area.Right) - float64(2*p.BoxBorderW)
+}
+
+// probeFrameWidth reads the width of a clip with ffprobe
+func probeFrameWidth(ctx context.Context, path string) (int, error) {
+	out, err := utils.OutputWatched(ctx, execCommand(ctx, "ffprobe",
+		"-v", "error", "-select_streams", "v:0", "-show_entries", "stream=width", "-of", "csv=p=0", path))
+	if err != nil {
+		return 0, fmt.Errorf("failed to probe clip width: %w", err)
+	}
+	width, err := strconv.Atoi(strings.TrimSpace(string(out)))
+	if err != nil || width <= 0 {
+		return 0, fmt.Errorf("unexpected clip width %q", strings.TrimSpace(string(out)))
+	}
+	return width, nil
+}
+
+// titleFilters builds one drawtext filter per title line. Each line is positioned with textX, so
+// the default expression centers every line; text_h in textY is the height of the whole block.
+func titleFilters(lines []string, fontSize int, p Params) string {
+	fontFileArg := ""
+	if p.FontFile != "" {
+		fontFileArg = "fontfile=" + utils.EscapeFilterText(p.FontFile) + ":"
+	}
+
+	lineHeight := fontSize + fontSize/5 + 2*p.BoxBorderW
+	blockHeight := lineHeight*len(lines) - (lineHeight - fontSize)
+	y := strings.ReplaceAll(p.TextY, "text_h", strconv.Itoa(blockHeight))
+
+	filters := make([]string, 0, len(lines))
+	for i, line := range lines {
+		lineY := y
+		if i > 0 {
+			lineY = fmt.Sprintf("(%s)+%d", y, i*lineHeight)
+		}
+		filters = append(filters, fmt.Sprintf(
+			"drawtext=%stext='%s':fontcolor=%s:fontsize=%d:box=1:boxcolor=%s:boxborderw=%d:x=%s:y=%s",
+			fontFileArg,
+			utils.EscapeFilterText(line),
+			p.FontColor,
+			fontSize,
+			p.BoxColor,
+			p.BoxBorderW,
+			p.TextX,
+			lineY,
+		))
+	}
+	return strings.Join(filters, ",")
+}
@@ -0,0 +1,69 @@
+package settitle2shortvideo
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFitTitle(t *testing.T) {
+	p := Params{FontSize: 64, MinFontSize: 32, MaxLines: 3, BoxBorderW: 5, Platform: "youtube"}
+	maxWidth := titleMaxWidth(1080, p)
+	assert.InDelta(t, 854, maxWidth, 0.01)
+
+	t.Run("short title stays on one line", func(t *testing.T) {
+		lines, size := fitTitle(context.Background(), "Go tips", p, maxWidth)
+		assert.Equal(t, []string{"Go tips"}, lines)
+		assert.Equal(t, 64, size)
+	})
+
+	t.Run("long title is wrapped into balanced lines", func(t *testing.T) {
+		lines, size := fitTitle(context.Background(), "Why every Go developer should learn about context cancellation today", p, maxWidth)
+		assert.Equal(t, 64, size)
+		require.Len(t, lines, 3)
+		for _, line := range lines {
+			assert.LessOrEqual(t, textWidth(line, size), maxWidth)
+		}
+		shortest, longest := textWidth(lines[0], size), textWidth(lines[0], size)
+		for _, line := range lines[1:] {
+			shortest = min(shortest, textWidth(line, size))
+			longest = max(longest, textWidth(line, size))
+		}
+		assert.Less(t, longest-shortest, maxWidth/3, "lines are balanced: %q", lines)
+	})
+
+	t.Run("font shrinks to stay within maxLines", func(t *testing.T) {
+		title := strings.Repeat("Shrinking titles keep the whole text readable ", 2)
+		lines, size := fitTitle(context.Background(), title, p, maxWidth)
+		assert.Less(t, size, 64)
+		assert.GreaterOrEqual(t, size, 32)
+		assert.LessOrEqual(t, len(lines), 3)
+	})
+
+	t.Run("titles without spaces break between characters", func(t *testing.T) {
+		lines, _ := fitTitle(context.Background(), "これは縦型動画のためのとても長いタイトルです", Params{FontSize: 64, MinFontSize: 64, MaxLines: 3, Platform: "tiktok"}, 500)
+		require.Greater(t, len(lines), 1)
+		assert.Equal(t, "これは縦型動画のためのとても長いタイトルです", strings.Join(lines, ""))
+	})
+
+	t.Run("overlong words are broken", func(t *testing.T) {
+		lines := wrapTokens(splitTitle("Supercalifragilisticexpialidocious"), 64, 400)
+		require.Greater(t, len(lines), 1)
+		assert.Equal(t, "Supercalifragilisticexpialidocious", strings.Join(lines, ""))
+	})
+}
+
+func TestTitleFilters(t *testing.T) {
+	p := Params{FontColor: "white", BoxColor: "black@0.5", BoxBorderW: 5, TextX: "(w-text_w)/2", TextY: "(h-text_h)/2"}
+
+	filters := strings.Split(titleFilters([]string{"It's 10:30", "already"}, 40, p), ",drawtext=")
+	require.Len(t, filters, 2)
+	assert.Contains(t, filters[0], `text='It\'s 10\:30'`)
+	assert.Contains(t, filters[0], "y=(h-98)/2")
+	assert.Contains(t, filters[1], "text='already'")
+	assert.Contains(t, filters[1], "y=((h-98)/2)+58")
+	assert.Contains(t, filters[1], "x=(w-text_w)/2")
+}
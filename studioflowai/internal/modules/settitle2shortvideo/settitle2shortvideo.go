@@ -4,57 +4,56 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
 	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
-	"gopkg.in/yaml.v3"
 )
 
 // execCommand allows us to mock exec.Command in tests
-var execCommand = exec.CommandContext
+var execCommand = utils.CommandContext
 
 // Module implements text overlay functionality
 type Module struct{}
 
 // Params contains the parameters for text overlay
 type Params struct {
-	Input      string `json:"input"`      // Path to input file or directory
-	Output     string `json:"output"`     // Path to output directory
-	VideoFile  string `json:"videoFile"`  // Path to the source video file
-	Text       string `json:"text"`       // Text to overlay
-	FontFile   string `json:"fontFile"`   // Path to the font file
-	FontSize   int    `json:"fontSize"`   // Font size
-	FontColor  string `json:"fontColor"`  // Font color
-	Position   string `json:"position"`   // Text position (top, bottom, center)
-	BoxColor   string `json:"boxColor"`   // Box color (default: "black@0.5")
-	BoxBorderW int    `json:"boxBorderW"` // Box border width (default: 5)
-	QuietFlag  bool   `json:"quietFlag"`  // Suppress ffmpeg output (default: true)
-	TextX      string `json:"textX"`      // X position of text (default: "(w-text_w)/2")
-	TextY      string `json:"textY"`      // Y position of text (default: "(h-text_h)/2")
+	Input      string `json:"input"`                        // Path to input file or directory
+	Output     string `json:"output"`                       // Path to output directory
+	VideoFile  string `json:"videoFile"`                    // Path to the source video file
+	Text       string `json:"text"`                         // Text to overlay
+	FontFile   string `json:"fontFile"`                     // Path to the font file
+	FontSize   int    `json:"fontSize"`                     // Font size
+	FontColor  string `json:"fontColor"`                    // Font color
+	Position   string `json:"position"`                     // Text position (top, bottom, center)
+	BoxColor   string `json:"boxColor" default:"black@0.5"` // Box color (default: "black@0.5")
+	BoxBorderW int    `json:"boxBorderW" default:"5"`       // Box border width (default: 5)
+	QuietFlag  bool   `json:"quietFlag" default:"true"`     // Suppress ffmpeg output (default: true)
+	TextX      string `json:"textX" default:"(w-text_w)/2"` // X position of text (default: "(w-text_w)/2")
+	TextY      string `json:"textY" default:"(h-text_h)/2"` // Y position of text (default: "(h-text_h)/2")
+
+	AutoFit     bool   `json:"autoFit" default:"true"`     // Wrap and shrink the title to fit the safe area (default: true)
+	MaxLines    int    `json:"maxLines" default:"3"`       // Most lines a title is wrapped into (default: 3)
+	MinFontSize int    `json:"minFontSize" default:"16"`   // Smallest font size auto-fit shrinks to (default: 16)
+	Platform    string `json:"platform" default:"youtube"` // Safe area the title must fit in: youtube, tiktok, instagram (default: "youtube")
+
+	Filtergraph  string `json:"filtergraph"`  // Custom filtergraph template replacing the drawtext overlay, see utils.RenderFiltergraph
+	SubtitleFile string `json:"subtitleFile"` // Subtitle file available to the filtergraph as {subtitles}
+
+	Theme string `json:"theme"` // Theme file (.ass or .yaml) whose font, size and colors are used where the step sets none; its subtitle style is {forcestyle}
 }
 
 // DefaultFontPath is the path to the default font file
 const DefaultFontPath = "/System/Library/Fonts/Supplemental/Arial.ttf"
 
 // ShortsData represents the structure of the shorts_suggestions.yaml file
-type ShortsData struct {
-	SourceVideo string      `yaml:"sourceVideo"`
-	Shorts      []ShortClip `yaml:"shorts"`
-}
+type ShortsData = utils.ShortsData
 
 // ShortClip represents a single short video clip suggestion
-type ShortClip struct {
-	Title       string `yaml:"title"`
-	StartTime   string `yaml:"startTime"`
-	EndTime     string `yaml:"endTime"`
-	Description string `yaml:"description"`
-	Tags        string `yaml:"tags"`
-	ShortTitle  string `yaml:"shortTitle"`
-}
+type ShortClip = utils.ShortClip
 
 // New creates a new settitle2shortvideo module
 func New() mod.Module {
@@ -66,6 +65,11 @@ func (m *Module) Name() string {
 	return "set_title_to_short_video"
 }
 
+// ParamsTemplate returns the module's parameter struct, used to validate and document workflows
+func (m *Module) ParamsTemplate() interface{} {
+	return Params{}
+}
+
 // Validate checks if the parameters are valid
 func (m *Module) Validate(params map[string]interface{}) error {
 	var p Params
@@ -100,7 +104,7 @@ func (m *Module) Validate(params map[string]interface{}) error {
 		}
 
 		var shortsData ShortsData
-		if err := yaml.Unmarshal(data, &shortsData); err != nil {
+		if err := utils.DecodeShorts(data, &shortsData); err != nil {
 			return fmt.Errorf("invalid YAML file: %w", err)
 		}
 	}
@@ -112,6 +116,43 @@ func (m *Module) Validate(params map[string]interface{}) error {
 		}
 	}
 
+	// Validate the theme
+	if p.Theme != "" {
+		if _, err := utils.LoadTheme(p.Theme); err != nil {
+			return err
+		}
+	}
+
+	// Validate the auto-fit settings
+	if p.Platform != "" {
+		if _, ok := utils.SafeAreas[p.Platform]; !ok {
+			return fmt.Errorf("unsupported platform %q (supported: %s)", p.Platform, strings.Join(utils.SafeAreaPlatforms(), ", "))
+		}
+	}
+	if p.MaxLines < 0 {
+		return fmt.Errorf("maxLines must not be negative, got %d", p.MaxLines)
+	}
+	if p.MinFontSize < 0 || (p.FontSize > 0 && p.MinFontSize > p.FontSize) {
+		return fmt.Errorf("minFontSize must be between 0 and fontSize, got %d", p.MinFontSize)
+	}
+
+	// Validate the filtergraph template against a sample clip
+	if p.Filtergraph != "" {
+		if p.SubtitleFile != "" {
+			if _, err := os.Stat(p.SubtitleFile); os.IsNotExist(err) {
+				return fmt.Errorf("subtitle file does not exist: %s", p.SubtitleFile)
+			}
+		}
+		sample := ShortClip{ShortTitle: "title", StartTime: "00:00:00", EndTime: "00:00:01"}
+		vars, err := filtergraphVars("input.mp4", sample, p)
+		if err != nil {
+			return err
+		}
+		if _, err := utils.RenderFiltergraph(p.Filtergraph, vars); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -122,6 +163,15 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 		return mod.ModuleResult{}, err
 	}
 
+	// The theme's typography fills in what the step does not set
+	if p.Theme != "" {
+		theme, err := utils.LoadTheme(p.Theme)
+		if err != nil {
+			return mod.ModuleResult{}, err
+		}
+		applyTheme(&p, theme)
+	}
+
 	// Set default values
 	if p.FontSize == 0 {
 		p.FontSize = 24
@@ -145,13 +195,31 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 		p.FontFile = DefaultFontPath
 	}
 
+	if p.MaxLines == 0 {
+		p.MaxLines = 3
+	}
+	if p.MinFontSize == 0 {
+		p.MinFontSize = 16
+	}
+	if p.MinFontSize > p.FontSize {
+		p.MinFontSize = p.FontSize
+	}
+	if p.Platform == "" {
+		p.Platform = "youtube"
+	}
+
 	// Default to quiet mode (no ffmpeg output) unless explicitly set to false
 	if _, exists := params["quietFlag"]; !exists {
 		p.QuietFlag = true
 	}
 
+	// Fit titles to the safe area unless explicitly disabled
+	if _, exists := params["autoFit"]; !exists {
+		p.AutoFit = true
+	}
+
 	// Create output directory if it doesn't exist
-	if err := os.MkdirAll(p.Output, 0755); err != nil {
+	if err := utils.EnsureDir(p.Output); err != nil {
 		return mod.ModuleResult{}, fmt.Errorf("failed to create output directory: %w", err)
 	}
 
@@ -182,11 +250,11 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 
 		// Use Title as ShortTitle if ShortTitle is empty
 		if short.ShortTitle == "" {
-			utils.LogWarning("Short clip %d is missing shortTitle, using title instead", i+1)
+			utils.LogWarningContext(ctx, "Short clip %d is missing shortTitle, using title instead", i+1)
 			short.ShortTitle = short.Title
 		}
 
-		outputPath, err := m.processShortClip(ctx, short, p)
+		outputPath, fontSize, err := m.processShortClip(ctx, short, p)
 		if err != nil {
 			return mod.ModuleResult{}, fmt.Errorf("failed to process short clip %d: %w", i+1, err)
 		}
@@ -199,14 +267,14 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 			"start_time":   short.StartTime,
 			"end_time":     short.EndTime,
 			"output_file":  outputPath,
-			"font_size":    p.FontSize,
+			"font_size":    fontSize,
 			"font_color":   p.FontColor,
 			"box_color":    p.BoxColor,
 			"box_border_w": p.BoxBorderW,
 		})
 	}
 
-	utils.LogSuccess("Successfully processed %d short clips", len(shortsData.Shorts))
+	utils.LogSuccessContext(ctx, "Successfully processed %d short clips", len(shortsData.Shorts))
 
 	return mod.ModuleResult{
 		Outputs: processedClips,
@@ -215,6 +283,7 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 			"clips_count":   len(shortsData.Shorts),
 			"clips_details": clipStats,
 			"font_file":     p.FontFile,
+			"auto_fit":      p.AutoFit,
 			"font_settings": map[string]interface{}{
 				"size":       p.FontSize,
 				"color":      p.FontColor,
@@ -225,6 +294,7 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 			},
 			"process_time": time.Now().Format(time.RFC3339),
 		},
+		Stats: mod.Stats{Items: len(shortsData.Shorts)},
 	}, nil
 }
 
@@ -290,6 +360,43 @@ func (m *Module) GetIO() mod.ModuleIO {
 				Description: "Y position of text",
 				Type:        string(mod.InputTypeData),
 			},
+			{
+				Name:        "autoFit",
+				Description: "Wrap and shrink titles to fit the safe area",
+				Type:        string(mod.InputTypeData),
+			},
+			{
+				Name:        "maxLines",
+				Description: "Maximum number of title lines",
+				Type:        string(mod.InputTypeData),
+			},
+			{
+				Name:        "minFontSize",
+				Description: "Smallest font size used to fit a title",
+				Type:        string(mod.InputTypeData),
+			},
+			{
+				Name:        "platform",
+				Description: "Platform whose safe area titles fit in",
+				Type:        string(mod.InputTypeData),
+			},
+			{
+				Name:        "filtergraph",
+				Description: "Custom filtergraph template",
+				Type:        string(mod.InputTypeData),
+			},
+			{
+				Name:        "subtitleFile",
+				Description: "Subtitle file used as {subtitles} in the filtergraph",
+				Patterns:    []string{".srt", ".ass"},
+				Type:        string(mod.InputTypeFile),
+			},
+			{
+				Name:        "theme",
+				Description: "Theme file with the font and colors of titles and subtitles",
+				Patterns:    []string{".ass", ".yaml"},
+				Type:        string(mod.InputTypeFile),
+			},
 		},
 		ProducedOutputs: []mod.ModuleOutput{
 			{
@@ -319,7 +426,7 @@ func readShortsFile(filePath string) (*ShortsData, error) {
 	}
 
 	var shortsData ShortsData
-	if err := yaml.Unmarshal(data, &shortsData); err != nil {
+	if err := utils.DecodeShorts(data, &shortsData); err != nil {
 		return nil, fmt.Errorf("failed to parse shorts file: %w", err)
 	}
 
@@ -332,10 +439,10 @@ func readShortsFile(filePath string) (*ShortsData, error) {
 }
 
 // processShortClip adds text overlay to a single short clip
-func (m *Module) processShortClip(ctx context.Context, short ShortClip, p Params) (string, error) {
+func (m *Module) processShortClip(ctx context.Context, short ShortClip, p Params) (string, int, error) {
 	// Convert startTime and endTime to HHMMSS format for filename
-	startTimeHHMMSS := convertToHHMMSS(short.StartTime)
-	endTimeHHMMSS := convertToHHMMSS(short.EndTime)
+	startTimeHHMMSS := utils.CompactTimestamp(short.StartTime)
+	endTimeHHMMSS := utils.CompactTimestamp(short.EndTime)
 
 	// Create input and output filenames with .mp4 extension
 	inputFilename := fmt.Sprintf("%s-%s.mp4", startTimeHHMMSS, endTimeHHMMSS)
@@ -349,46 +456,55 @@ func (m *Module) processShortClip(ctx context.Context, short ShortClip, p Params
 		yamlDir := filepath.Dir(utils.ResolveOutputPath(p.Input, p.Output))
 		inputPath = filepath.Join(yamlDir, inputFilename)
 		if _, err := os.Stat(inputPath); os.IsNotExist(err) {
-			return "", fmt.Errorf("input video file does not exist in either %s or %s",
+			return "", 0, fmt.Errorf("input video file does not exist in either %s or %s",
 				filepath.Join(p.Output, inputFilename),
 				filepath.Join(yamlDir, inputFilename))
 		}
 	}
 
 	// Build FFmpeg command for text overlay
+	fontSize := p.FontSize
+	// A retried video replaces the partial output of an earlier attempt
 	args := []string{
+		"-y",
 		"-i", inputPath,
 	}
 
-	// Add font file if specified and verify it exists
-	fontFileArg := ""
-	if p.FontFile != "" {
-		if _, err := os.Stat(p.FontFile); os.IsNotExist(err) {
-			return "", fmt.Errorf("font file does not exist: %s", p.FontFile)
+	// A custom filtergraph replaces the default drawtext overlay
+	if p.Filtergraph != "" {
+		vars, err := filtergraphVars(inputPath, short, p)
+		if err != nil {
+			return "", 0, err
+		}
+		filtergraph, err := utils.RenderFiltergraph(p.Filtergraph, vars)
+		if err != nil {
+			return "", 0, err
+		}
+		args = append(args, "-vf", filtergraph)
+	} else {
+		// Verify the font file exists
+		if p.FontFile != "" {
+			if _, err := os.Stat(p.FontFile); os.IsNotExist(err) {
+				return "", 0, fmt.Errorf("font file does not exist: %s", p.FontFile)
+			}
+		}
+
+		// Wrap and shrink the title to the clip's safe area
+		lines := []string{short.ShortTitle}
+		if p.AutoFit {
+			frameWidth, err := probeFrameWidth(ctx, inputPath)
+			if err != nil {
+				utils.LogWarningContext(ctx, "%v, fitting the title to %dpx", err, defaultFrameWidth)
+				frameWidth = defaultFrameWidth
+			}
+			lines, fontSize = fitTitle(ctx, short.ShortTitle, p, titleMaxWidth(frameWidth, p))
+			if fontSize < p.FontSize || len(lines) > 1 {
+				utils.LogVerboseContext(ctx, "Fitted title %q into %d line(s) at font size %d", short.ShortTitle, len(lines), fontSize)
+			}
 		}
-		fontFileArg = fmt.Sprintf("fontfile=%s:", p.FontFile)
-	}
-
-	// Escape special characters in the short_title text
-	escapedText := strings.ReplaceAll(short.ShortTitle, "'", "\\'")
-	escapedText = strings.ReplaceAll(escapedText, ":", "\\:")
-	escapedText = strings.ReplaceAll(escapedText, "\\", "\\\\")
-
-	// Build the drawtext filter
-	drawtextFilter := fmt.Sprintf(
-		"drawtext=%stext='%s':fontcolor=%s:fontsize=%d:box=1:boxcolor=%s:boxborderw=%d:x=%s:y=%s:line_spacing=10",
-		fontFileArg,
-		escapedText,
-		p.FontColor,
-		p.FontSize,
-		p.BoxColor,
-		p.BoxBorderW,
-		p.TextX,
-		p.TextY,
-	)
-
-	// Add the filter to the command
-	args = append(args, "-vf", drawtextFilter)
+
+		args = append(args, "-vf", titleFilters(lines, fontSize, p))
+	}
 
 	// Add quiet flags if enabled
 	if p.QuietFlag {
@@ -407,47 +523,70 @@ func (m *Module) processShortClip(ctx context.Context, short ShortClip, p Params
 		cmd.Stdout = nil
 		cmd.Stderr = &stderr
 	} else {
-		cmd.Stdout = os.Stdout
+		cmd.Stdout = utils.LogWriter(ctx)
 		cmd.Stderr = os.Stderr
 	}
 
 	// Run the FFmpeg command
-	if err := cmd.Run(); err != nil {
+	if err := utils.RunWatched(ctx, cmd); err != nil {
 		if p.QuietFlag && stderr.Len() > 0 {
 			// Log the error output if we captured it
-			utils.LogError("FFmpeg error: %s", stderr.String())
+			utils.LogErrorContext(ctx, "FFmpeg error: %s", stderr.String())
 		}
-		return "", fmt.Errorf("ffmpeg command failed: %w", err)
+		return "", 0, fmt.Errorf("ffmpeg command failed: %w", err)
 	}
 
 	// Verify the output file was created
 	if _, err := os.Stat(outputPath); os.IsNotExist(err) {
-		return "", fmt.Errorf("ffmpeg command completed but output file was not created: %s", outputPath)
+		return "", 0, fmt.Errorf("ffmpeg command completed but output file was not created: %s", outputPath)
 	}
 
-	utils.LogInfo("Added text overlay to: %s", outputFilename)
-	return outputPath, nil
+	utils.LogInfoContext(ctx, "Added text overlay to: %s", outputFilename)
+	return outputPath, fontSize, nil
 }
 
-// convertToHHMMSS converts a timestamp like "00:01:23" to "000123"
-func convertToHHMMSS(timestamp string) string {
-	// Remove any non-numeric characters except digits
-	digits := strings.Map(func(r rune) rune {
-		if r >= '0' && r <= '9' {
-			return r
+// filtergraphVars returns the placeholders of a clip for the filtergraph template; {start} and
+// {end} are the clip's times in the source video, in seconds
+func filtergraphVars(inputPath string, short ShortClip, p Params) (map[string]string, error) {
+	start, err := utils.ParseTimestamp(short.StartTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid startTime: %w", err)
+	}
+	end, err := utils.ParseTimestamp(short.EndTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endTime: %w", err)
+	}
+	vars := map[string]string{
+		"input":     inputPath,
+		"title":     short.ShortTitle,
+		"subtitles": p.SubtitleFile,
+		"fontfile":  p.FontFile,
+		"start":     strconv.FormatFloat(start.Seconds(), 'f', 3, 64),
+		"end":       strconv.FormatFloat(end.Seconds(), 'f', 3, 64),
+		"duration":  strconv.FormatFloat((end - start).Seconds(), 'f', 3, 64),
+	}
+	if p.Theme != "" {
+		theme, err := utils.LoadTheme(p.Theme)
+		if err != nil {
+			return nil, err
 		}
-		return -1
-	}, timestamp)
-
-	// Pad with leading zeros if needed
-	if len(digits) < 6 {
-		digits = fmt.Sprintf("%06s", digits)
+		vars["forcestyle"] = theme.ForceStyle()
 	}
+	return vars, nil
+}
 
-	// Take only the first 6 digits
-	if len(digits) > 6 {
-		digits = digits[:6]
+// applyTheme sets the font and colors of the title from a theme, where the step sets none
+func applyTheme(p *Params, theme *utils.Theme) {
+	if p.FontFile == "" {
+		p.FontFile = theme.FontFile
+	}
+	if p.FontSize == 0 {
+		p.FontSize = theme.FontSize
+	}
+	if p.FontColor == "" {
+		p.FontColor = utils.DrawtextColor(theme.PrimaryColor)
+	}
+	if p.BoxColor == "" {
+		p.BoxColor = utils.DrawtextColor(theme.BoxColor)
 	}
-
-	return digits
 }
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -63,7 +64,7 @@ func TestModule_GetIO(t *testing.T) {
 	assert.Equal(t, "output", io.RequiredInputs[1].Name)
 
 	// Test optional inputs
-	assert.Len(t, io.OptionalInputs, 9)
+	assert.Len(t, io.OptionalInputs, 16)
 	assert.Equal(t, "videoFile", io.OptionalInputs[0].Name)
 	assert.Equal(t, "fontFile", io.OptionalInputs[1].Name)
 	assert.Equal(t, "fontSize", io.OptionalInputs[2].Name)
@@ -73,6 +74,13 @@ func TestModule_GetIO(t *testing.T) {
 	assert.Equal(t, "quietFlag", io.OptionalInputs[6].Name)
 	assert.Equal(t, "textX", io.OptionalInputs[7].Name)
 	assert.Equal(t, "textY", io.OptionalInputs[8].Name)
+	assert.Equal(t, "autoFit", io.OptionalInputs[9].Name)
+	assert.Equal(t, "maxLines", io.OptionalInputs[10].Name)
+	assert.Equal(t, "minFontSize", io.OptionalInputs[11].Name)
+	assert.Equal(t, "platform", io.OptionalInputs[12].Name)
+	assert.Equal(t, "filtergraph", io.OptionalInputs[13].Name)
+	assert.Equal(t, "subtitleFile", io.OptionalInputs[14].Name)
+	assert.Equal(t, "theme", io.OptionalInputs[15].Name)
 
 	// Test produced outputs
 	assert.Len(t, io.ProducedOutputs, 1)
@@ -321,43 +329,22 @@ shorts:
 	}
 }
 
+func TestApplyTheme(t *testing.T) {
+	theme := &utils.Theme{FontFile: "/fonts/Inter.ttf", FontSize: 64, PrimaryColor: "#FFD700", BoxColor: "&H80000000"}
+
+	p := Params{}
+	applyTheme(&p, theme)
+	assert.Equal(t, Params{FontFile: "/fonts/Inter.ttf", FontSize: 64, FontColor: "0xFFD700", BoxColor: "0x000000@0.5"}, p)
+
+	// Settings of the step win over the theme
+	p = Params{FontSize: 32, FontColor: "white"}
+	applyTheme(&p, theme)
+	assert.Equal(t, 32, p.FontSize)
+	assert.Equal(t, "white", p.FontColor)
+	assert.Equal(t, "/fonts/Inter.ttf", p.FontFile)
+}
+
 func TestModule_Name(t *testing.T) {
 	module := New()
 	assert.Equal(t, "set_title_to_short_video", module.Name())
 }
-
-func TestConvertToHHMMSS(t *testing.T) {
-	tests := []struct {
-		name      string
-		timestamp string
-		expected  string
-	}{
-		{
-			name:      "standard format",
-			timestamp: "00:01:30",
-			expected:  "000130",
-		},
-		{
-			name:      "with milliseconds",
-			timestamp: "00:01:30.500",
-			expected:  "000130",
-		},
-		{
-			name:      "only numbers",
-			timestamp: "013000",
-			expected:  "013000",
-		},
-		{
-			name:      "short format",
-			timestamp: "1:30",
-			expected:  "000130",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := convertToHHMMSS(tt.timestamp)
-			assert.Equal(t, tt.expected, result)
-		})
-	}
-}
@@ -0,0 +1,432 @@
+package shortsreport
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	modules "github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// Module implements the HTML review report of the suggested shorts
+type Module struct{}
+
+// Params contains the parameters for the shorts report
+type Params struct {
+	Input            string `json:"input"`                                     // Path to shorts_suggestions.yaml file
+	Output           string `json:"output"`                                    // Path to output directory
+	StoredShortsPath string `json:"storedShortsPath"`                          // Directory of the rendered clips (default: directory of the shorts file)
+	OutputFileName   string `json:"outputFileName" default:"shorts_report"`    // Name of the report, without extension (default: "shorts_report")
+	Decisions        string `json:"decisions" default:"shorts_decisions.json"` // Decisions file the report saves; a bare file name is placed in output (default: "shorts_decisions.json")
+}
+
+// reportClip is one clip as shown in the report
+type reportClip struct {
+	Number      int
+	Title       string
+	ShortTitle  string
+	StartTime   string
+	EndTime     string
+	Duration    string
+	Description string
+	Tags        string
+	Video       string // Rendered clip, relative to the report
+	Storyboard  string // Contact sheet, relative to the report
+	Score       string // score.total from score_shorts
+	ScoreDetail string // The other score fields
+	Risk        string // rating.risk from rate_shorts
+	RiskDetail  string // Flags and notes of the rating
+	Blocked     bool   // The rating blocks the upload
+	Accepted    bool
+}
+
+// reportData is the data of the report template
+type reportData struct {
+	Title         string
+	ShortsFile    string
+	DecisionsFile string
+	DecisionsPath string
+	GeneratedAt   string
+	Clips         []reportClip
+}
+
+// New creates a new shorts report module
+func New() modules.Module {
+	return &Module{}
+}
+
+// Name returns the module name
+func (m *Module) Name() string {
+	return "shorts_report"
+}
+
+// ParamsTemplate returns the module's parameter struct, used to validate and document workflows
+func (m *Module) ParamsTemplate() interface{} {
+	return Params{}
+}
+
+// Validate checks if the parameters are valid
+func (m *Module) Validate(params map[string]interface{}) error {
+	var p Params
+	if err := modules.ParseParams(params, &p); err != nil {
+		return err
+	}
+
+	// Validate input path
+	if err := utils.ValidateInputPath(p.Input, p.Output, ""); err != nil {
+		return err
+	}
+
+	// Validate output path
+	if err := utils.ValidateOutputPath(p.Output); err != nil {
+		return err
+	}
+
+	if p.Decisions != "" && filepath.Ext(p.Decisions) != ".json" {
+		return fmt.Errorf("decisions must be a .json file, got: %s", p.Decisions)
+	}
+
+	return nil
+}
+
+// Execute writes a self-contained HTML page to review the rendered shorts and accept or reject each one
+func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (modules.ModuleResult, error) {
+	var p Params
+	if err := modules.ParseParams(params, &p); err != nil {
+		return modules.ModuleResult{}, err
+	}
+
+	// Set default values
+	if p.OutputFileName == "" {
+		p.OutputFileName = "shorts_report"
+	}
+	if p.Decisions == "" {
+		p.Decisions = utils.DefaultDecisionsFile
+	}
+
+	resolvedInput := utils.ResolveOutputPath(p.Input, p.Output)
+	doc, err := utils.ReadShortsDocument(resolvedInput)
+	if err != nil {
+		return modules.ModuleResult{}, err
+	}
+
+	clipsDir := utils.ResolveOutputPath(p.StoredShortsPath, p.Output)
+	if clipsDir == "" {
+		clipsDir = filepath.Dir(resolvedInput)
+	}
+	decisionsPath := utils.ResolveOutputPath(p.Decisions, p.Output)
+	if !filepath.IsAbs(decisionsPath) && decisionsPath == filepath.Base(decisionsPath) {
+		decisionsPath = filepath.Join(p.Output, decisionsPath)
+	}
+
+	if err := utils.EnsureDir(p.Output); err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	reportPath := filepath.Join(p.Output, p.OutputFileName+".html")
+
+	// Start from the decisions of an earlier review, if any
+	previous, err := utils.ReadShortDecisions(decisionsPath)
+	if err != nil {
+		previous = &utils.ShortDecisions{}
+	}
+
+	data := reportData{
+		Title:         "Shorts review",
+		ShortsFile:    resolvedInput,
+		DecisionsFile: filepath.Base(decisionsPath),
+		DecisionsPath: decisionsPath,
+		GeneratedAt:   time.Now().Format("2006-01-02 15:04"),
+	}
+	if source := doc.SourceVideo(); source != "" {
+		data.Title = "Shorts review: " + filepath.Base(source)
+	}
+
+	rendered := 0
+	for i, clip := range doc.Shorts.Content {
+		c := buildClip(i+1, clip, filepath.Dir(reportPath), filepath.Dir(resolvedInput), clipsDir)
+		if decision, ok := previous.Decision(c.StartTime, c.EndTime); ok {
+			c.Accepted = decision.Accepted
+		}
+		if c.Video != "" {
+			rendered++
+		} else {
+			utils.LogWarningContext(ctx, "No rendered video for short %d (%s-%s) in %s", c.Number, c.StartTime, c.EndTime, clipsDir)
+		}
+		data.Clips = append(data.Clips, c)
+	}
+
+	var out bytes.Buffer
+	if err := reportTemplate.Execute(&out, data); err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to render report: %w", err)
+	}
+	if err := utils.AtomicWriteFile(reportPath, out.Bytes(), 0644); err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to write report: %w", err)
+	}
+
+	utils.LogSuccessContext(ctx, "Shorts report written to %s; save the decisions as %s", reportPath, decisionsPath)
+
+	return modules.ModuleResult{
+		Outputs: map[string]string{
+			"report": reportPath,
+		},
+		Metadata: map[string]interface{}{
+			"inputFile":     resolvedInput,
+			"clipsDir":      clipsDir,
+			"decisionsFile": decisionsPath,
+			"numShorts":     len(data.Clips),
+			"renderedClips": rendered,
+		},
+		Stats: modules.Stats{Items: len(data.Clips)},
+	}, nil
+}
+
+// buildClip collects what the report shows of a clip. Files are linked relative to the report so
+// the output folder can be moved or shared.
+func buildClip(number int, clip *yaml.Node, reportDir, shortsDir, clipsDir string) reportClip {
+	c := reportClip{
+		Number:      number,
+		Title:       utils.ClipField(clip, "title"),
+		ShortTitle:  utils.ClipField(clip, "shortTitle"),
+		StartTime:   utils.ClipField(clip, "startTime"),
+		EndTime:     utils.ClipField(clip, "endTime"),
+		Description: utils.ClipField(clip, "description"),
+		Tags:        utils.ClipField(clip, "tags"),
+		Accepted:    true,
+	}
+	if c.ShortTitle == "" {
+		c.ShortTitle = c.Title
+	}
+
+	start, startErr := utils.ParseTimestamp(c.StartTime)
+	end, endErr := utils.ParseTimestamp(c.EndTime)
+	if startErr == nil && endErr == nil && end > start {
+		c.Duration = fmt.Sprintf("%ds", int((end - start).Seconds()))
+	}
+
+	// Prefer the titled render over the plain cut
+	base := utils.CompactTimestamp(c.StartTime) + "-" + utils.CompactTimestamp(c.EndTime)
+	for _, name := range []string{base + "-withtext.mp4", base + ".mp4"} {
+		if path := filepath.Join(clipsDir, name); fileExists(path) {
+			c.Video = relativeTo(reportDir, path)
+			break
+		}
+	}
+	if storyboard := utils.ClipField(clip, "storyboard"); storyboard != "" {
+		if !filepath.IsAbs(storyboard) {
+			storyboard = filepath.Join(shortsDir, filepath.FromSlash(storyboard))
+		}
+		c.Storyboard = relativeTo(reportDir, storyboard)
+	}
+
+	if score := utils.MappingValue(clip, "score"); score != nil && score.Kind == yaml.MappingNode {
+		var detail []string
+		for i := 0; i+1 < len(score.Content); i += 2 {
+			key, value := score.Content[i].Value, score.Content[i+1].Value
+			if key == "total" {
+				c.Score = value
+				continue
+			}
+			detail = append(detail, key+" "+value)
+		}
+		c.ScoreDetail = strings.Join(detail, ", ")
+	} else if score != nil {
+		c.Score = score.Value
+	}
+	if c.Score != "" {
+		if v, err := strconv.ParseFloat(c.Score, 64); err == nil {
+			c.Score = strconv.FormatFloat(v, 'f', 2, 64)
+		}
+	}
+
+	if node := utils.MappingValue(clip, "rating"); node != nil {
+		var rating utils.ClipRating
+		if err := node.Decode(&rating); err == nil {
+			c.Risk = strconv.Itoa(rating.Risk)
+			c.RiskDetail = strings.TrimSpace(strings.Join(rating.Flags, ", ") + " " + rating.Notes)
+			c.Blocked = rating.Blocked
+		}
+	}
+	return c
+}
+
+// relativeTo returns path relative to dir with forward slashes, or path when it cannot be made relative
+func relativeTo(dir, path string) string {
+	if rel, err := filepath.Rel(dir, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(path)
+}
+
+// fileExists reports whether path is an existing regular file
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// GetIO returns the module's input/output specification
+func (m *Module) GetIO() modules.ModuleIO {
+	return modules.ModuleIO{
+		RequiredInputs: []modules.ModuleInput{
+			{
+				Name:        "input",
+				Description: "Path to shorts suggestions YAML file",
+				Patterns:    []string{".yaml"},
+				Type:        string(modules.InputTypeFile),
+			},
+			{
+				Name:        "output",
+				Description: "Path to output directory",
+				Type:        string(modules.InputTypeDirectory),
+			},
+		},
+		OptionalInputs: []modules.ModuleInput{
+			{
+				Name:        "storedShortsPath",
+				Description: "Directory of the rendered clips (default: directory of the shorts file)",
+				Patterns:    []string{".mp4"},
+				Type:        string(modules.InputTypeDirectory),
+			},
+			{
+				Name:        "decisions",
+				Description: "Decisions file the report saves (default: shorts_decisions.json)",
+				Patterns:    []string{".json"},
+				Type:        string(modules.InputTypeData),
+			},
+		},
+		ProducedOutputs: []modules.ModuleOutput{
+			{
+				Name:        "report",
+				Description: "HTML page to review the shorts and accept or reject each one",
+				Patterns:    []string{".html"},
+				Type:        string(modules.OutputTypeFile),
+			},
+		},
+	}
+}
+
+// reportTemplate is the self-contained review page. Accept/reject choices are saved as JSON
+// with the File System Access API where the browser supports it, or downloaded otherwise.
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 0; background: #f4f4f5; color: #18181b; }
+header { position: sticky; top: 0; background: #18181b; color: #fafafa; padding: 12px 24px; display: flex; gap: 16px; align-items: center; flex-wrap: wrap; z-index: 1; }
+header h1 { font-size: 18px; margin: 0; flex: 1; }
+header button { background: #fafafa; color: #18181b; border: 0; border-radius: 6px; padding: 6px 12px; cursor: pointer; }
+header button.primary { background: #22c55e; color: #fff; font-weight: 600; }
+#status { font-size: 13px; opacity: 0.8; }
+main { display: grid; grid-template-columns: repeat(auto-fill, minmax(300px, 1fr)); gap: 16px; padding: 24px; }
+.clip { background: #fff; border-radius: 10px; padding: 12px; box-shadow: 0 1px 3px rgba(0,0,0,0.1); border: 3px solid #22c55e; }
+.clip.rejected { border-color: #ef4444; opacity: 0.6; }
+.clip video, .clip img { width: 100%; border-radius: 6px; background: #000; }
+.clip video { aspect-ratio: 9 / 16; }
+.clip h2 { font-size: 16px; margin: 8px 0 4px; }
+.meta { font-size: 12px; color: #52525b; }
+.score { float: right; font-weight: 700; color: #2563eb; }
+.risk.blocked { color: #ef4444; font-weight: 600; }
+.missing { padding: 40px 0; text-align: center; background: #e4e4e7; border-radius: 6px; color: #71717a; }
+.clip p { font-size: 13px; white-space: pre-wrap; }
+label { display: block; font-weight: 600; cursor: pointer; }
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Title}}</h1>
+<span id="summary"></span>
+<button type="button" onclick="setAll(true)">Accept all</button>
+<button type="button" onclick="setAll(false)">Reject all</button>
+<button type="button" class="primary" onclick="save()">Save decisions</button>
+<span id="status">Generated {{.GeneratedAt}}. Save as {{.DecisionsPath}}</span>
+</header>
+<main>
+{{range .Clips}}<section class="clip" data-start="{{.StartTime}}" data-end="{{.EndTime}}" data-title="{{.ShortTitle}}">
+{{if .Video}}<video src="{{.Video}}" controls preload="metadata"{{if .Storyboard}} poster="{{.Storyboard}}"{{end}}></video>
+{{else if .Storyboard}}<img src="{{.Storyboard}}" alt="Storyboard of clip {{.Number}}">
+{{else}}<div class="missing">Not rendered</div>
+{{end}}{{if .Score}}<span class="score" title="{{.ScoreDetail}}">{{.Score}}</span>{{end}}
+<h2>{{.Number}}. {{.ShortTitle}}</h2>
+<div class="meta">{{.StartTime}} – {{.EndTime}}{{if .Duration}} ({{.Duration}}){{end}}</div>
+{{if ne .Title .ShortTitle}}<div class="meta">{{.Title}}</div>{{end}}
+{{if .Description}}<p>{{.Description}}</p>{{end}}
+{{if .Tags}}<div class="meta">{{.Tags}}</div>{{end}}
+{{if .Risk}}<div class="meta risk{{if .Blocked}} blocked{{end}}" title="{{.RiskDetail}}">Title risk {{.Risk}}/10{{if .Blocked}}, blocked from upload{{end}}</div>{{end}}
+<label><input type="checkbox" class="accept" onchange="update()"{{if .Accepted}} checked{{end}}> Accept for upload</label>
+</section>
+{{end}}</main>
+<script>
+const shortsFile = {{.ShortsFile}};
+const decisionsFile = {{.DecisionsFile}};
+const decisionsPath = {{.DecisionsPath}};
+
+function clips() { return Array.from(document.querySelectorAll(".clip")); }
+
+function update() {
+  let accepted = 0;
+  for (const clip of clips()) {
+    const ok = clip.querySelector(".accept").checked;
+    clip.classList.toggle("rejected", !ok);
+    if (ok) accepted++;
+  }
+  document.getElementById("summary").textContent = accepted + " of " + clips().length + " accepted";
+}
+
+function setAll(accepted) {
+  for (const box of document.querySelectorAll(".accept")) box.checked = accepted;
+  update();
+}
+
+function decisions() {
+  return {
+    shortsFile: shortsFile,
+    updatedAt: new Date().toISOString(),
+    decisions: clips().map(clip => ({
+      startTime: clip.dataset.start,
+      endTime: clip.dataset.end,
+      title: clip.dataset.title,
+      accepted: clip.querySelector(".accept").checked,
+    })),
+  };
+}
+
+async function save() {
+  const data = JSON.stringify(decisions(), null, 2) + "\n";
+  const status = document.getElementById("status");
+  if (window.showSaveFilePicker) {
+    try {
+      const handle = await window.showSaveFilePicker({
+        suggestedName: decisionsFile,
+        types: [{ description: "Shorts decisions", accept: { "application/json": [".json"] } }],
+      });
+      const writable = await handle.createWritable();
+      await writable.write(data);
+      await writable.close();
+      status.textContent = "Saved " + handle.name + " (upload steps read " + decisionsPath + ")";
+      return;
+    } catch (err) {
+      if (err.name === "AbortError") return;
+    }
+  }
+  const link = document.createElement("a");
+  link.href = URL.createObjectURL(new Blob([data], { type: "application/json" }));
+  link.download = decisionsFile;
+  link.click();
+  status.textContent = "Downloaded " + decisionsFile + ": move it to " + decisionsPath;
+}
+
+update();
+</script>
+</body>
+</html>
+`))
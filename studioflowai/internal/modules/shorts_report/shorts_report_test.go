@@ -0,0 +1,90 @@
+package shortsreport
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testShorts = `sourceVideo: /videos/ep12.mp4
+shorts:
+  - title: "The <best> moment"
+    shortTitle: "Best moment"
+    startTime: "00:01:00"
+    endTime: "00:01:30"
+    description: "Intro & welcome"
+    tags: "go, video"
+    storyboard: storyboards/01_000100-000130.jpg
+    score:
+      total: 0.8512
+      energy: 0.9
+  - title: "Second clip"
+    startTime: "00:05:00"
+    endTime: "00:05:45"
+    rating:
+      clickbait: 8
+      policy: 2
+      misleading: 3
+      risk: 8
+      flags: ["You won't believe"]
+      blocked: true
+`
+
+func TestModule_Execute(t *testing.T) {
+	dir := t.TempDir()
+	shortsFile := filepath.Join(dir, "shorts_suggestions.yaml")
+	require.NoError(t, os.WriteFile(shortsFile, []byte(testShorts), 0644))
+	clipsDir := filepath.Join(dir, "shorts")
+	require.NoError(t, os.MkdirAll(clipsDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(clipsDir, "000100-000130-withtext.mp4"), []byte("video"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(clipsDir, "000500-000545.mp4"), []byte("video"), 0644))
+
+	// An earlier review rejected the second clip
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "shorts_decisions.json"), []byte(`{"decisions": [
+		{"startTime": "00:05:00", "endTime": "00:05:45", "accepted": false}]}`), 0644))
+
+	m := New()
+	params := map[string]interface{}{
+		"input":            shortsFile,
+		"output":           dir,
+		"storedShortsPath": clipsDir,
+	}
+	require.NoError(t, m.Validate(params))
+	result, err := m.Execute(context.Background(), params)
+	require.NoError(t, err)
+
+	reportPath := filepath.Join(dir, "shorts_report.html")
+	assert.Equal(t, reportPath, result.Outputs["report"])
+	assert.Equal(t, 2, result.Metadata["renderedClips"])
+
+	data, err := os.ReadFile(reportPath)
+	require.NoError(t, err)
+	html := string(data)
+	assert.Contains(t, html, "<title>Shorts review: ep12.mp4</title>")
+	assert.Contains(t, html, `<video src="shorts/000100-000130-withtext.mp4" controls preload="metadata" poster="storyboards/01_000100-000130.jpg">`)
+	assert.Contains(t, html, `<video src="shorts/000500-000545.mp4"`)
+	assert.Contains(t, html, `<span class="score" title="energy 0.9">0.85</span>`)
+	assert.Contains(t, html, "The &lt;best&gt; moment")
+	assert.Contains(t, html, "Intro &amp; welcome")
+
+	sections := strings.Split(html, `<section class="clip"`)
+	require.Len(t, sections, 3)
+	assert.Contains(t, sections[1], `onchange="update()" checked>`)
+	assert.Contains(t, sections[2], `onchange="update()">`, "the earlier rejection is kept")
+	assert.NotContains(t, sections[1], "Title risk")
+	assert.Contains(t, sections[2], `<div class="meta risk blocked" title="You won&#39;t believe">Title risk 8/10, blocked from upload</div>`)
+}
+
+func TestModule_Validate(t *testing.T) {
+	dir := t.TempDir()
+	shortsFile := filepath.Join(dir, "shorts_suggestions.yaml")
+	require.NoError(t, os.WriteFile(shortsFile, []byte(testShorts), 0644))
+
+	err := New().Validate(map[string]interface{}{"input": shortsFile, "output": dir, "decisions": "decisions.yaml"})
+	assert.ErrorContains(t, err, "decisions must be a .json file")
+}
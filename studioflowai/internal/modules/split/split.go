@@ -20,11 +20,14 @@ type Module struct{}
 
 // Params contains the parameters for audio splitting
 type Params struct {
-	Input       string `json:"input"`       // Path to input audio file or directory
-	Output      string `json:"output"`      // Path to output directory
-	SegmentTime int    `json:"segmentTime"` // Segment duration in seconds (default: 1800 = 30 minutes)
-	FilePattern string `json:"filePattern"` // Output file pattern (default: "splited%03d")
-	AudioFormat string `json:"audioFormat"` // Output audio format (default: "wav")
+	Input       string `json:"input"`                             // Path to input audio file or directory
+	Output      string `json:"output"`                            // Path to output directory
+	SegmentTime int    `json:"segmentTime" default:"1800"`        // Segment duration in seconds (default: 1800 = 30 minutes)
+	FilePattern string `json:"filePattern" default:"splited%03d"` // Output file pattern (default: "splited%03d")
+	AudioFormat string `json:"audioFormat" default:"wav"`         // Output audio format (default: "wav")
+
+	Include []string `json:"include"` // Patterns of the files to split when input is a directory (default: files in audioFormat)
+	Exclude []string `json:"exclude"` // Patterns of input directory files to leave out
 }
 
 // New creates a new split module
@@ -37,6 +40,11 @@ func (m *Module) Name() string {
 	return "split"
 }
 
+// ParamsTemplate returns the module's parameter struct, used to validate and document workflows
+func (m *Module) ParamsTemplate() interface{} {
+	return Params{}
+}
+
 // GetIO returns the module's input/output specification
 func (m *Module) GetIO() modules.ModuleIO {
 	return modules.ModuleIO{
@@ -98,6 +106,10 @@ func (m *Module) Validate(params map[string]interface{}) error {
 		return err
 	}
 
+	if err := utils.NewFileFilter(p.Include, p.Exclude, []string{"*"}).Validate(); err != nil {
+		return err
+	}
+
 	// Resolve the input path if it contains ${output}
 	resolvedInput := utils.ResolveOutputPath(p.Input, p.Output)
 
@@ -131,7 +143,7 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 	}
 
 	// Create output directory if it doesn't exist
-	if err := os.MkdirAll(p.Output, 0755); err != nil {
+	if err := utils.EnsureDir(p.Output); err != nil {
 		return modules.ModuleResult{}, fmt.Errorf("failed to create output directory: %w", err)
 	}
 
@@ -146,12 +158,12 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 
 	if fileInfo.IsDir() {
 		// Process all audio files in the directory
-		if err := m.processDirectory(p); err != nil {
+		if err := m.processDirectory(ctx, p); err != nil {
 			return modules.ModuleResult{}, err
 		}
 	} else {
 		// Process a single file
-		if err := m.processFile(resolvedInput, p); err != nil {
+		if err := m.processFile(ctx, resolvedInput, p); err != nil {
 			return modules.ModuleResult{}, err
 		}
 	}
@@ -168,19 +180,21 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 		Statistics: map[string]interface{}{
 			"inputFile": resolvedInput,
 		},
+		Stats: modules.Stats{ToolVersions: utils.ToolVersions("ffmpeg")},
 	}
 
 	return result, nil
 }
 
 // processDirectory processes all audio files in a directory
-func (m *Module) processDirectory(p Params) error {
+func (m *Module) processDirectory(ctx context.Context, p Params) error {
 	// Resolve the input path if it contains ${output}
 	resolvedInput := utils.ResolveOutputPath(p.Input, p.Output)
 
-	entries, err := os.ReadDir(resolvedInput)
+	filter := utils.NewFileFilter(p.Include, p.Exclude, []string{"*." + p.AudioFormat})
+	entries, err := filter.Files(resolvedInput)
 	if err != nil {
-		return fmt.Errorf("failed to read directory: %w", err)
+		return err
 	}
 
 	// Define supported input formats
@@ -191,26 +205,13 @@ func (m *Module) processDirectory(p Params) error {
 		".aac": true,
 	}
 
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-
-		filename := entry.Name()
-		ext := strings.ToLower(filepath.Ext(filename))
-
-		// Skip files that don't match the requested output format
-		if ext != "."+strings.ToLower(p.AudioFormat) {
-			continue
-		}
-
+	for _, inputPath := range entries {
 		// Skip unsupported input formats
-		if !supportedFormats[ext] {
+		if !supportedFormats[strings.ToLower(filepath.Ext(inputPath))] {
 			continue
 		}
 
-		inputPath := filepath.Join(resolvedInput, filename)
-		if err := m.processFile(inputPath, p); err != nil {
+		if err := m.processFile(ctx, inputPath, p); err != nil {
 			return err
 		}
 	}
@@ -219,14 +220,15 @@ func (m *Module) processDirectory(p Params) error {
 }
 
 // processFile splits a single audio file into segments
-func (m *Module) processFile(filePath string, p Params) error {
+func (m *Module) processFile(ctx context.Context, filePath string, p Params) error {
 	outputPattern := filepath.Join(p.Output, p.FilePattern+"."+p.AudioFormat)
 
-	utils.LogVerbose("Splitting %s into segments of %d seconds", filePath, p.SegmentTime)
+	utils.LogVerboseContext(ctx, "Splitting %s into segments of %d seconds", filePath, p.SegmentTime)
 
 	// Split audio with ffmpeg using the mockable execCommand
 	cmd := execCommand(
 		"ffmpeg",
+		"-y", // Segments of an earlier attempt are replaced
 		"-i", filePath,
 		"-f", "segment",
 		"-segment_time", fmt.Sprintf("%d", p.SegmentTime),
@@ -239,10 +241,10 @@ func (m *Module) processFile(filePath string, p Params) error {
 	cmd.Stdout = nil
 	cmd.Stderr = nil
 
-	if err := cmd.Run(); err != nil {
+	if err := utils.RunWatched(ctx, cmd); err != nil {
 		return fmt.Errorf("ffmpeg command failed: %w", err)
 	}
 
-	utils.LogSuccess("Successfully split %s into segments", filePath)
+	utils.LogSuccessContext(ctx, "Successfully split %s into segments", filePath)
 	return nil
 }
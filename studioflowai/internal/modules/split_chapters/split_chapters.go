@@ -0,0 +1,721 @@
+package splitchapters
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	modules "github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	youtubesvc "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/youtube"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// execCommand allows us to mock exec.Command in tests
+var execCommand = utils.CommandContext
+
+// partsFileName is the file listing the parts, their metadata and uploaded video IDs
+const partsFileName = "chapter_parts.yaml"
+
+// maxTitleLength is the longest title YouTube accepts
+const maxTitleLength = 100
+
+// chapterEntry matches a timeline entry such as "05:32 - Topic" or "1:05:32 Topic"
+var chapterEntry = regexp.MustCompile(`^\s*(\d{1,2}:\d{2}(?::\d{2})?)\s*(?:\(\S+\))?\s*[-–—:|]?\s*(.*)$`)
+
+// Module splits a long recording into standalone videos at chapter boundaries
+type Module struct {
+	youtubeService youtubesvc.YouTubeService
+}
+
+// Params contains the parameters for chapter splitting
+type Params struct {
+	Input           string  `json:"input"`                                                  // Path to the SNS content YAML with the timeline
+	Output          string  `json:"output"`                                                 // Path to output directory
+	VideoFile       string  `json:"videoFile"`                                              // Path to the long recording
+	MinPartDuration string  `json:"minPartDuration" default:"10:00"`                        // Chapters are grouped until a part lasts at least this long (default: "10:00")
+	TitlePattern    string  `json:"titlePattern" default:"{chapter} | Part {part}/{parts}"` // Part title; {chapter}, {title}, {part} and {parts} are replaced (default: "{chapter} | Part {part}/{parts}")
+	PartLabel       string  `json:"partLabel" default:"Part"`                               // Word used for the links between parts in descriptions (default: "Part")
+	FFmpegParams    string  `json:"ffmpegParams"`                                           // FFmpeg output parameters, e.g. to re-encode (default: stream copy)
+	QuietFlag       bool    `json:"quietFlag" default:"true"`                               // Suppress ffmpeg output (default: true)
+	Upload          bool    `json:"upload"`                                                 // Upload the parts to YouTube as a series (default: false)
+	Credentials     string  `json:"credentials"`                                            // Path to Google credentials file, required with upload
+	PrivacyStatus   string  `json:"privacyStatus" default:"private"`                        // Privacy of the uploaded parts: private, unlisted, public (default: "private")
+	CategoryID      string  `json:"categoryId"`                                             // Video category ID
+	PlaylistID      string  `json:"playlistId"`                                             // Playlist the parts are added to (optional)
+	DailyQuota      int     `json:"dailyQuota" default:"10000"`                             // YouTube Data API daily quota of the project (default: 10000)
+	QuotaWarnRatio  float64 `json:"quotaWarnThreshold" default:"0.8"`                       // Fraction of the daily quota that triggers a warning (default: 0.8)
+}
+
+// Chapter is one entry of the episode timeline
+type Chapter struct {
+	Start time.Duration
+	Title string
+}
+
+// Part is one standalone video cut from the recording
+type Part struct {
+	Number      int      `yaml:"part"`
+	Title       string   `yaml:"title"`
+	Start       string   `yaml:"start"`
+	End         string   `yaml:"end"`
+	File        string   `yaml:"file"`
+	Description string   `yaml:"description"`
+	Tags        string   `yaml:"tags,omitempty"`
+	Chapters    []string `yaml:"chapters"`          // Chapters of the part, with times relative to its start
+	VideoID     string   `yaml:"videoId,omitempty"` // Set once the part is uploaded
+
+	start, end time.Duration
+	chapters   []Chapter
+}
+
+// PartsData is the content of chapter_parts.yaml
+type PartsData struct {
+	SourceVideo string `yaml:"sourceVideo"`
+	Title       string `yaml:"title,omitempty"`
+	Parts       []Part `yaml:"parts"`
+}
+
+// episodeContent holds the SNS fields reused for every part
+type episodeContent struct {
+	Title       string
+	Description string
+	Keywords    string
+	Chapters    []Chapter
+}
+
+// New creates a new chapter split module
+func New() modules.Module {
+	return &Module{
+		youtubeService: &youtubesvc.Service{},
+	}
+}
+
+// Name returns the module name
+func (m *Module) Name() string {
+	return "split_chapters"
+}
+
+// ParamsTemplate returns the module's parameter struct, used to validate and document workflows
+func (m *Module) ParamsTemplate() interface{} {
+	return Params{}
+}
+
+// Validate checks if the parameters are valid
+func (m *Module) Validate(params map[string]interface{}) error {
+	var p Params
+	if err := modules.ParseParams(params, &p); err != nil {
+		return err
+	}
+
+	if err := utils.ValidateInputPath(p.Input, p.Output, ""); err != nil {
+		return err
+	}
+	if err := utils.ValidateOutputPath(p.Output); err != nil {
+		return err
+	}
+	if err := utils.ValidateVideoFile(p.VideoFile, p.Output); err != nil {
+		return err
+	}
+	if p.MinPartDuration != "" {
+		if _, err := utils.ParseTimestamp(p.MinPartDuration); err != nil {
+			return fmt.Errorf("invalid minPartDuration: %w", err)
+		}
+	}
+
+	if p.Upload {
+		if p.Credentials == "" {
+			return fmt.Errorf("credentials file path is required to upload the parts")
+		}
+		credentials, err := utils.ExpandHomeDir(p.Credentials)
+		if err != nil {
+			return fmt.Errorf("failed to expand home directory: %w", err)
+		}
+		if _, err := os.Stat(credentials); os.IsNotExist(err) {
+			return fmt.Errorf("credentials file does not exist: %s", credentials)
+		}
+		switch p.PrivacyStatus {
+		case "", "private", "unlisted", "public":
+		default:
+			return fmt.Errorf("invalid privacy status: %s", p.PrivacyStatus)
+		}
+	}
+
+	if err := utils.ValidateRequiredDependency("ffmpeg"); err != nil {
+		return err
+	}
+	return utils.ValidateRequiredDependency("ffprobe")
+}
+
+// Execute cuts the recording into parts and optionally uploads them
+func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (modules.ModuleResult, error) {
+	var p Params
+	if err := modules.ParseParams(params, &p); err != nil {
+		return modules.ModuleResult{}, err
+	}
+
+	// Set default values
+	if p.MinPartDuration == "" {
+		p.MinPartDuration = "10:00"
+	}
+	if p.TitlePattern == "" {
+		p.TitlePattern = "{chapter} | Part {part}/{parts}"
+	}
+	if p.PartLabel == "" {
+		p.PartLabel = "Part"
+	}
+	if p.PrivacyStatus == "" {
+		p.PrivacyStatus = "private"
+	}
+	if _, ok := params["quietFlag"]; !ok {
+		p.QuietFlag = true
+	}
+	minPart, err := utils.ParseTimestamp(p.MinPartDuration)
+	if err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("invalid minPartDuration: %w", err)
+	}
+
+	if err := utils.EnsureDir(p.Output); err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	resolvedInput := utils.ResolveOutputPath(p.Input, p.Output)
+	content, err := readEpisodeContent(resolvedInput)
+	if err != nil {
+		return modules.ModuleResult{}, err
+	}
+
+	duration, err := probeDuration(ctx, p.VideoFile)
+	if err != nil {
+		return modules.ModuleResult{}, err
+	}
+
+	parts := groupChapters(content.Chapters, duration, minPart)
+	if len(parts) < 2 {
+		utils.LogWarningContext(ctx, "The timeline of %s only yields one part of at least %s; the recording is kept whole", resolvedInput, p.MinPartDuration)
+	}
+
+	partsPath := filepath.Join(p.Output, partsFileName)
+	previous := readPreviousParts(ctx, partsPath)
+	outputs := map[string]string{"chapter_parts": partsPath}
+	ext := filepath.Ext(p.VideoFile)
+	for i := range parts {
+		part := &parts[i]
+		part.Title = partTitle(p.TitlePattern, content.Title, *part, len(parts))
+		part.File = fmt.Sprintf("part_%02d%s", part.Number, ext)
+		part.Tags = content.Keywords
+		part.Description = partDescription(content.Description, *part, parts, p.PartLabel)
+
+		// Keep the video of a part already uploaded by an earlier run, with the description it was given
+		if prev, ok := previous[part.Number]; ok && prev.Start == part.Start && prev.End == part.End {
+			part.VideoID = prev.VideoID
+			part.Description = prev.Description
+		}
+
+		path := filepath.Join(p.Output, part.File)
+		if err := m.cutPart(ctx, *part, path, p); err != nil {
+			return modules.ModuleResult{}, err
+		}
+		outputs[fmt.Sprintf("part_%02d", part.Number)] = path
+	}
+
+	data := &PartsData{SourceVideo: p.VideoFile, Title: content.Title, Parts: parts}
+	if err := writeParts(partsPath, data); err != nil {
+		return modules.ModuleResult{}, err
+	}
+
+	uploaded, deferred := 0, 0
+	if p.Upload && utils.PreviewUploads() {
+		path, err := previewParts(ctx, data, content.Description, partsPath, p)
+		if err != nil {
+			return modules.ModuleResult{}, err
+		}
+		outputs["uploadsPreview"] = path
+	} else if p.Upload {
+		if uploaded, deferred, err = m.uploadParts(ctx, data, content.Description, partsPath, p); err != nil {
+			return modules.ModuleResult{}, err
+		}
+	}
+
+	utils.LogSuccessContext(ctx, "Split %s into %d part(s) -> %s", p.VideoFile, len(parts), partsPath)
+
+	return modules.ModuleResult{
+		Outputs: outputs,
+		Metadata: map[string]interface{}{
+			"parts":         len(parts),
+			"deferredParts": deferred,
+		},
+		Statistics: map[string]interface{}{
+			"sourceVideo":   p.VideoFile,
+			"chapters":      len(content.Chapters),
+			"parts":         len(parts),
+			"uploadedParts": uploaded,
+			"duration":      duration.String(),
+			"processTime":   time.Now().Format(time.RFC3339),
+		},
+		Stats: modules.Stats{Items: len(parts)},
+	}, nil
+}
+
+// uploadParts uploads the parts without a video ID, then rewrites every description with links to
+// all parts. Progress is saved after each call, so a run stopped by the quota resumes where it left off.
+func (m *Module) uploadParts(ctx context.Context, data *PartsData, body, partsPath string, p Params) (int, int, error) {
+	credentials, err := utils.ExpandHomeDir(p.Credentials)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to expand home directory: %w", err)
+	}
+	if err := m.youtubeService.ConfigureQuota(credentials, youtubesvc.QuotaOptions{
+		DailyLimit:    p.DailyQuota,
+		WarnThreshold: p.QuotaWarnRatio,
+	}); err != nil {
+		return 0, 0, fmt.Errorf("failed to configure quota tracking: %w", err)
+	}
+	service, err := m.youtubeService.InitializeYouTubeService(ctx, credentials)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to initialize YouTube service: %w", err)
+	}
+
+	uploaded := 0
+	for i := range data.Parts {
+		part := &data.Parts[i]
+		if part.VideoID != "" {
+			continue
+		}
+
+		part.Description = partDescription(body, *part, data.Parts, p.PartLabel)
+		videoID, err := m.youtubeService.InsertVideo(ctx, service, partUpload(*part, p), filepath.Join(p.Output, part.File), p.PrivacyStatus, p.CategoryID)
+		if err != nil {
+			var quotaErr *youtubesvc.QuotaExceededError
+			if errors.As(err, &quotaErr) {
+				utils.LogWarningContext(ctx, "YouTube API quota exhausted, %d part(s) not uploaded; run this step again after %s",
+					len(data.Parts)-i, quotaErr.ResetAt.Local().Format("2006-01-02 15:04 MST"))
+				return uploaded, len(data.Parts) - i, writeParts(partsPath, data)
+			}
+			if writeErr := writeParts(partsPath, data); writeErr != nil {
+				utils.LogWarningContext(ctx, "Failed to save upload progress: %v", writeErr)
+			}
+			return uploaded, 0, fmt.Errorf("failed to upload part %d: %w", part.Number, err)
+		}
+
+		part.VideoID = videoID
+		uploaded++
+		utils.LogInfoContext(ctx, "Uploaded part %d/%d: %s", part.Number, len(data.Parts), part.Title)
+		if err := writeParts(partsPath, data); err != nil {
+			return uploaded, 0, err
+		}
+	}
+
+	// Every part is online: link each one to the others
+	for i := range data.Parts {
+		part := &data.Parts[i]
+		linked := partDescription(body, *part, data.Parts, p.PartLabel)
+		if linked == part.Description {
+			continue
+		}
+		part.Description = linked
+		if err := m.youtubeService.UpdateVideoSnippet(ctx, service, part.VideoID, partUpload(*part, p), p.CategoryID); err != nil {
+			if writeErr := writeParts(partsPath, data); writeErr != nil {
+				utils.LogWarningContext(ctx, "Failed to save upload progress: %v", writeErr)
+			}
+			return uploaded, 0, fmt.Errorf("failed to link part %d: %w", part.Number, err)
+		}
+	}
+
+	return uploaded, 0, writeParts(partsPath, data)
+}
+
+// previewParts writes the videos.insert requests of the parts not uploaded yet to
+// uploads_preview.yaml without calling the YouTube API
+func previewParts(ctx context.Context, data *PartsData, body, partsPath string, p Params) (string, error) {
+	preview := utils.UploadPreview{
+		Platform: utils.PlatformYouTube,
+		Source:   partsPath,
+		Target:   utils.UploadTarget{Credentials: p.Credentials},
+		Notes: []string{
+			"Links to the other parts are added to every description once all parts are uploaded",
+		},
+	}
+	if ws := utils.ActiveWorkspace(); ws != nil {
+		preview.Target.Project = ws.Name
+	}
+	for _, part := range data.Parts {
+		if part.VideoID != "" {
+			continue
+		}
+		part.Description = partDescription(body, part, data.Parts, p.PartLabel)
+		upload := partUpload(part, p)
+		video := youtubesvc.NewVideo(upload, p.PrivacyStatus, p.CategoryID)
+		preview.Uploads = append(preview.Uploads, utils.PreviewUpload{
+			File:        filepath.Join(p.Output, part.File),
+			Title:       video.Snippet.Title,
+			Description: video.Snippet.Description,
+			Tags:        video.Snippet.Tags,
+			Privacy:     video.Status.PrivacyStatus,
+			PlaylistID:  upload.PlaylistID,
+			Request:     video,
+		})
+	}
+
+	path, err := utils.WriteUploadPreview(p.Output, preview)
+	if err != nil {
+		return "", err
+	}
+	utils.LogSuccessContext(ctx, "Previewed %d part upload(s) in %s; nothing was uploaded", len(preview.Uploads), path)
+	return path, nil
+}
+
+// partUpload builds the upload request of a part
+func partUpload(part Part, p Params) youtubesvc.VideoUpload {
+	return youtubesvc.VideoUpload{
+		FileName:    part.File,
+		ShortTitle:  part.Title,
+		Description: part.Description,
+		Tags:        part.Tags,
+		PlaylistID:  p.PlaylistID,
+	}
+}
+
+// cutPart writes one part of the recording with ffmpeg
+func (m *Module) cutPart(ctx context.Context, part Part, outputPath string, p Params) error {
+	args := []string{"-y", "-ss", formatSeconds(part.start), "-to", formatSeconds(part.end)}
+	if p.QuietFlag {
+		args = append(args, "-v", "error", "-stats")
+	}
+	args = append(args, "-i", p.VideoFile)
+	if p.FFmpegParams != "" {
+		args = append(args, strings.Fields(p.FFmpegParams)...)
+	} else {
+		// Stream copy is fast; parts start at the keyframe before the chapter
+		args = append(args, "-c", "copy", "-avoid_negative_ts", "make_zero")
+	}
+	args = append(args, outputPath)
+
+	cmd := execCommand(ctx, "ffmpeg", args...)
+	var stderr bytes.Buffer
+	if p.QuietFlag {
+		cmd.Stderr = &stderr
+	} else {
+		cmd.Stdout = utils.LogWriter(ctx)
+		cmd.Stderr = os.Stderr
+	}
+
+	utils.LogInfoContext(ctx, "Cutting part %d: %s (%s to %s)", part.Number, part.Title, part.Start, part.End)
+	if err := utils.RunWatched(ctx, cmd); err != nil {
+		if stderr.Len() > 0 {
+			utils.LogErrorContext(ctx, "FFmpeg error: %s", stderr.String())
+		}
+		return fmt.Errorf("ffmpeg command failed for part %d: %w", part.Number, err)
+	}
+	return nil
+}
+
+// probeDuration reads the duration of the recording with ffprobe
+func probeDuration(ctx context.Context, path string) (time.Duration, error) {
+	out, err := utils.OutputWatched(ctx, execCommand(ctx, "ffprobe", "-v", "error", "-show_entries", "format=duration", "-of", "csv=p=0", path))
+	if err != nil {
+		return 0, fmt.Errorf("failed to probe video duration: %w", err)
+	}
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(string(out)), 64)
+	if err != nil || seconds <= 0 {
+		return 0, fmt.Errorf("failed to read video duration from ffprobe output %q", strings.TrimSpace(string(out)))
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// readEpisodeContent reads the timeline, title, description and keywords of an SNS content file.
+// With one section per language, the first section holding a timeline is used.
+func readEpisodeContent(path string) (*episodeContent, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SNS content file: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse SNS content file: %w", err)
+	}
+
+	section := findTimelineSection(&doc)
+	if section == nil {
+		return nil, fmt.Errorf("no timeline found in %s", path)
+	}
+
+	content := &episodeContent{}
+	for i := 0; i+1 < len(section.Content); i += 2 {
+		key, value := section.Content[i].Value, section.Content[i+1]
+		switch key {
+		case "title":
+			content.Title = strings.TrimSpace(value.Value)
+		case "description":
+			content.Description = strings.TrimSpace(value.Value)
+		case "keywords":
+			content.Keywords = strings.TrimSpace(value.Value)
+		case "timeline":
+			for _, item := range value.Content {
+				if chapter, ok := parseChapter(item); ok {
+					content.Chapters = append(content.Chapters, chapter)
+				}
+			}
+		}
+	}
+	if len(content.Chapters) == 0 {
+		return nil, fmt.Errorf("the timeline in %s has no entries with a timestamp", path)
+	}
+	return content, nil
+}
+
+// findTimelineSection returns the first mapping that has a timeline list
+func findTimelineSection(node *yaml.Node) *yaml.Node {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			if found := findTimelineSection(child); found != nil {
+				return found
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == "timeline" && node.Content[i+1].Kind == yaml.SequenceNode {
+				return node
+			}
+		}
+		for i := 1; i < len(node.Content); i += 2 {
+			if found := findTimelineSection(node.Content[i]); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+// parseChapter reads a timeline entry written as "05:32 - Topic" or as a mapping with a time and a topic
+func parseChapter(item *yaml.Node) (Chapter, bool) {
+	text := item.Value
+	if item.Kind == yaml.MappingNode {
+		var at, title string
+		for i := 0; i+1 < len(item.Content); i += 2 {
+			switch item.Content[i].Value {
+			case "time", "timestamp":
+				at = item.Content[i+1].Value
+			case "topic", "title", "description":
+				if title == "" {
+					title = item.Content[i+1].Value
+				}
+			}
+		}
+		text = at + " - " + title
+	}
+
+	match := chapterEntry.FindStringSubmatch(text)
+	if match == nil {
+		return Chapter{}, false
+	}
+	start, err := utils.ParseTimestamp(match[1])
+	if err != nil {
+		return Chapter{}, false
+	}
+	return Chapter{Start: start, Title: strings.TrimSpace(match[2])}, true
+}
+
+// groupChapters turns the timeline into parts of at least minPart each. Chapters after the end of
+// the recording are dropped, and a short last part is merged into the one before it.
+func groupChapters(chapters []Chapter, duration, minPart time.Duration) []Part {
+	sorted := make([]Chapter, 0, len(chapters))
+	for _, chapter := range chapters {
+		if chapter.Start < duration {
+			sorted = append(sorted, chapter)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+	// The first part always starts at the beginning of the recording
+	if len(sorted) == 0 || sorted[0].Start > 0 {
+		sorted = append([]Chapter{{Start: 0, Title: "Intro"}}, sorted...)
+	}
+
+	var parts []Part
+	for _, chapter := range sorted {
+		if n := len(parts); n > 0 && chapter.Start-parts[n-1].start < minPart {
+			parts[n-1].chapters = append(parts[n-1].chapters, chapter)
+			continue
+		}
+		parts = append(parts, Part{start: chapter.Start, chapters: []Chapter{chapter}})
+	}
+	if n := len(parts); n > 1 && duration-parts[n-1].start < minPart {
+		parts[n-2].chapters = append(parts[n-2].chapters, parts[n-1].chapters...)
+		parts = parts[:n-1]
+	}
+
+	for i := range parts {
+		parts[i].Number = i + 1
+		parts[i].end = duration
+		if i+1 < len(parts) {
+			parts[i].end = parts[i+1].start
+		}
+		parts[i].Start = formatTimestamp(parts[i].start)
+		parts[i].End = formatTimestamp(parts[i].end)
+		for _, chapter := range parts[i].chapters {
+			parts[i].Chapters = append(parts[i].Chapters, formatTimestamp(chapter.Start-parts[i].start)+" "+chapter.Title)
+		}
+	}
+	return parts
+}
+
+// partTitle fills in the title pattern of a part, within YouTube's title limit
+func partTitle(pattern, episodeTitle string, part Part, total int) string {
+	title := strings.NewReplacer(
+		"{chapter}", part.chapters[0].Title,
+		"{title}", episodeTitle,
+		"{part}", strconv.Itoa(part.Number),
+		"{parts}", strconv.Itoa(total),
+	).Replace(pattern)
+	if runes := []rune(title); len(runes) > maxTitleLength {
+		title = strings.TrimSpace(string(runes[:maxTitleLength-1])) + "…"
+	}
+	return title
+}
+
+// seriesMarker separates a part's own description from the list of parts appended to it
+const seriesMarker = "\n\n―――\n"
+
+// partDescription appends the part's chapters and the list of all parts to the episode description.
+// Parts that are already uploaded are linked.
+func partDescription(body string, part Part, parts []Part, label string) string {
+	var b strings.Builder
+	b.WriteString(body)
+	if len(part.Chapters) > 1 {
+		if body != "" {
+			b.WriteString("\n\n")
+		}
+		b.WriteString(strings.Join(part.Chapters, "\n"))
+	}
+	if len(parts) < 2 {
+		return b.String()
+	}
+
+	b.WriteString(seriesMarker)
+	for _, other := range parts {
+		fmt.Fprintf(&b, "%s %d/%d: %s", label, other.Number, len(parts), other.Title)
+		if other.Number == part.Number {
+			b.WriteString(" ▶")
+		} else if other.VideoID != "" {
+			b.WriteString(" https://youtu.be/" + other.VideoID)
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// readPreviousParts returns the parts of an earlier run by number, if any
+func readPreviousParts(ctx context.Context, path string) map[int]Part {
+	previous := make(map[int]Part)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return previous
+	}
+	var parts PartsData
+	if err := yaml.Unmarshal(data, &parts); err != nil {
+		utils.LogWarningContext(ctx, "Ignoring unreadable %s: %v", path, err)
+		return previous
+	}
+	for _, part := range parts.Parts {
+		if part.VideoID != "" {
+			previous[part.Number] = part
+		}
+	}
+	return previous
+}
+
+// writeParts saves the parts file
+func writeParts(path string, data *PartsData) error {
+	content, err := yaml.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to generate YAML: %w", err)
+	}
+	if err := utils.WriteTextFile(path, string(content)); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// formatTimestamp formats a time as mm:ss, or h:mm:ss from one hour on
+func formatTimestamp(d time.Duration) string {
+	seconds := int(d / time.Second)
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, (seconds/60)%60, seconds%60)
+	}
+	return fmt.Sprintf("%02d:%02d", seconds/60, seconds%60)
+}
+
+// formatSeconds renders a time as seconds for ffmpeg's -ss and -to options
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
+
+// GetIO returns the module's input/output specification
+func (m *Module) GetIO() modules.ModuleIO {
+	return modules.ModuleIO{
+		RequiredInputs: []modules.ModuleInput{
+			{
+				Name:        "input",
+				Description: "Path to SNS content YAML file with the episode timeline",
+				Patterns:    []string{".yaml"},
+				Type:        string(modules.InputTypeFile),
+			},
+			{
+				Name:        "output",
+				Description: "Path to output directory",
+				Type:        string(modules.InputTypeDirectory),
+			},
+			{
+				Name:        "videoFile",
+				Description: "Path to the long recording",
+				Patterns:    []string{".mp4", ".mov", ".mkv"},
+				Type:        string(modules.InputTypeFile),
+			},
+		},
+		OptionalInputs: []modules.ModuleInput{
+			{
+				Name:        "minPartDuration",
+				Description: "Minimum duration of a part",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "titlePattern",
+				Description: "Title of each part",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "credentials",
+				Description: "Path to Google credentials file, to upload the parts",
+				Patterns:    []string{".json"},
+				Type:        string(modules.InputTypeFile),
+			},
+			{
+				Name:        "playlistId",
+				Description: "YouTube playlist ID for the parts",
+				Type:        string(modules.InputTypeData),
+			},
+		},
+		ProducedOutputs: []modules.ModuleOutput{
+			{
+				Name:        "chapter_parts",
+				Description: "Parts with their metadata and uploaded video IDs",
+				Patterns:    []string{".yaml"},
+				Type:        string(modules.OutputTypeFile),
+			},
+			{
+				Name:        "parts",
+				Description: "One video per part",
+				Patterns:    []string{".mp4", ".mov", ".mkv"},
+				Type:        string(modules.OutputTypeFile),
+			},
+		},
+	}
+}
@@ -0,0 +1,193 @@
+package splitchapters
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/services/youtube"
+	youtubemocks "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/youtube/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	youtubeapi "google.golang.org/api/youtube/v3"
+	"gopkg.in/yaml.v3"
+)
+
+const snsContent = `youtube:
+  title: "Building a home studio"
+  description: "Everything about our new studio."
+  keywords: "studio,audio"
+  timeline:
+    - "00:00 - Welcome"
+    - "04:10 - Choosing microphones"
+    - "12:30 - Acoustic treatment"
+    - "25:00 - Lighting"
+    - "38:20 - Questions"
+`
+
+// ffmpegCalls counts the ffmpeg invocations of a test
+var ffmpegCalls int
+
+// fakeExecCommand returns a helper process that reports a 45 minute recording to ffprobe
+func fakeExecCommand(ctx context.Context, command string, args ...string) *exec.Cmd {
+	if command == "ffmpeg" {
+		ffmpegCalls++
+	}
+	cs := []string{"-test.run=TestHelperProcess", "--", command}
+	cs = append(cs, args...)
+	cmd := exec.CommandContext(ctx, os.Args[0], cs...)
+	cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1"}
+	return cmd
+}
+
+// TestHelperProcess is not a real test, it's used to mock exec.Command
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	for i, arg := range os.Args {
+		if arg == "--" && i+1 < len(os.Args) && os.Args[i+1] == "ffprobe" {
+			_, _ = os.Stdout.WriteString("2700.000000\n")
+		}
+	}
+	os.Exit(0)
+}
+
+func TestModule_Name(t *testing.T) {
+	assert.Equal(t, "split_chapters", New().Name())
+}
+
+func TestGroupChapters(t *testing.T) {
+	chapters := []Chapter{
+		{Start: 0, Title: "Welcome"},
+		{Start: 4*time.Minute + 10*time.Second, Title: "Choosing microphones"},
+		{Start: 12*time.Minute + 30*time.Second, Title: "Acoustic treatment"},
+		{Start: 25 * time.Minute, Title: "Lighting"},
+		{Start: 38*time.Minute + 20*time.Second, Title: "Questions"},
+		{Start: 50 * time.Minute, Title: "After the end"},
+	}
+
+	parts := groupChapters(chapters, 45*time.Minute, 10*time.Minute)
+
+	require.Len(t, parts, 3)
+	assert.Equal(t, "00:00", parts[0].Start)
+	assert.Equal(t, "12:30", parts[0].End)
+	assert.Equal(t, []string{"00:00 Welcome", "04:10 Choosing microphones"}, parts[0].Chapters)
+	assert.Equal(t, "12:30", parts[1].Start)
+	assert.Equal(t, "25:00", parts[1].End)
+	// The last 6:40 are too short for a part of their own
+	assert.Equal(t, "25:00", parts[2].Start)
+	assert.Equal(t, "45:00", parts[2].End)
+	assert.Equal(t, []string{"00:00 Lighting", "13:20 Questions"}, parts[2].Chapters)
+}
+
+func TestGroupChapters_StartsAtZero(t *testing.T) {
+	parts := groupChapters([]Chapter{{Start: 2 * time.Minute, Title: "Topic"}}, 5*time.Minute, time.Minute)
+
+	require.Len(t, parts, 2)
+	assert.Equal(t, "00:00", parts[0].Start)
+	assert.Equal(t, "Intro", parts[0].chapters[0].Title)
+}
+
+func TestPartDescription(t *testing.T) {
+	parts := groupChapters([]Chapter{{Start: 0, Title: "One"}, {Start: 20 * time.Minute, Title: "Two"}}, 40*time.Minute, 10*time.Minute)
+	parts[0].Title = "One | Part 1/2"
+	parts[1].Title = "Two | Part 2/2"
+	parts[1].VideoID = "vid2"
+
+	description := partDescription("About the episode.", parts[0], parts, "Part")
+
+	assert.Contains(t, description, "About the episode.")
+	assert.Contains(t, description, "Part 1/2: One | Part 1/2 ▶")
+	assert.Contains(t, description, "Part 2/2: Two | Part 2/2 https://youtu.be/vid2")
+}
+
+func TestPartTitle(t *testing.T) {
+	part := Part{Number: 2, chapters: []Chapter{{Title: "Lighting"}}}
+
+	assert.Equal(t, "Building a home studio - Lighting (2/3)", partTitle("{title} - {chapter} ({part}/{parts})", "Building a home studio", part, 3))
+}
+
+func TestModule_Execute(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.CommandContext }()
+	ffmpegCalls = 0
+
+	tempDir := t.TempDir()
+	input := filepath.Join(tempDir, "sns_content.yaml")
+	require.NoError(t, os.WriteFile(input, []byte(snsContent), 0644))
+	video := filepath.Join(tempDir, "episode.mp4")
+	require.NoError(t, os.WriteFile(video, []byte("dummy"), 0644))
+	credentials := filepath.Join(tempDir, "credentials.json")
+	require.NoError(t, os.WriteFile(credentials, []byte("{}"), 0644))
+	output := filepath.Join(tempDir, "output")
+
+	mockService := youtubemocks.NewMockYouTubeService(t)
+	api := &youtubeapi.Service{}
+	mockService.On("ConfigureQuota", credentials, youtube.QuotaOptions{}).Return(nil)
+	mockService.On("InitializeYouTubeService", mock.Anything, credentials).Return(api, nil)
+	mockService.On("InsertVideo", mock.Anything, api, mock.Anything, filepath.Join(output, "part_01.mp4"), "private", "").Return("vid1", nil).Once()
+	mockService.On("InsertVideo", mock.Anything, api, mock.Anything, filepath.Join(output, "part_02.mp4"), "private", "").Return("vid2", nil).Once()
+	mockService.On("InsertVideo", mock.Anything, api, mock.Anything, filepath.Join(output, "part_03.mp4"), "private", "").Return("", &youtube.QuotaExceededError{Operation: "videos.insert", ResetAt: time.Now()}).Once()
+
+	module := &Module{youtubeService: mockService}
+	params := map[string]interface{}{
+		"input":       input,
+		"output":      output,
+		"videoFile":   video,
+		"upload":      true,
+		"credentials": credentials,
+	}
+
+	result, err := module.Execute(context.Background(), params)
+	require.NoError(t, err)
+	assert.Equal(t, 3, ffmpegCalls)
+	assert.Equal(t, 1, result.Metadata["deferredParts"])
+	assert.Equal(t, filepath.Join(output, "part_02.mp4"), result.Outputs["part_02"])
+
+	data := readPartsFile(t, filepath.Join(output, partsFileName))
+	require.Len(t, data.Parts, 3)
+	assert.Equal(t, "Welcome | Part 1/3", data.Parts[0].Title)
+	assert.Equal(t, "vid1", data.Parts[0].VideoID)
+	assert.Equal(t, "vid2", data.Parts[1].VideoID)
+	assert.Empty(t, data.Parts[2].VideoID)
+	assert.Equal(t, "studio,audio", data.Parts[0].Tags)
+
+	// The next run resumes with the last part, then links every part to the others
+	mockService.On("InsertVideo", mock.Anything, api, mock.Anything, filepath.Join(output, "part_03.mp4"), "private", "").Return("vid3", nil).Once()
+	mockService.On("UpdateVideoSnippet", mock.Anything, api, "vid1", mock.Anything, "").Return(nil).Once()
+	mockService.On("UpdateVideoSnippet", mock.Anything, api, "vid2", mock.Anything, "").Return(nil).Once()
+
+	result, err = module.Execute(context.Background(), params)
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Metadata["deferredParts"])
+
+	data = readPartsFile(t, filepath.Join(output, partsFileName))
+	assert.Equal(t, "vid3", data.Parts[2].VideoID)
+	for i, part := range data.Parts {
+		assert.Contains(t, part.Description, "Everything about our new studio.")
+		for j, other := range data.Parts {
+			if i != j {
+				assert.Contains(t, part.Description, "https://youtu.be/"+other.VideoID)
+			}
+		}
+	}
+
+	// Once every part is linked, a new run changes nothing online
+	result, err = module.Execute(context.Background(), params)
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Statistics["uploadedParts"])
+}
+
+func readPartsFile(t *testing.T, path string) PartsData {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var data PartsData
+	require.NoError(t, yaml.Unmarshal(content, &data))
+	return data
+}
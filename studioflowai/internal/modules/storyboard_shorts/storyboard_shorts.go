@@ -0,0 +1,310 @@
+package storyboardshorts
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	modules "github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// execCommand allows us to mock exec.Command in tests
+var execCommand = utils.CommandContext
+
+// Module implements contact sheet generation for suggested shorts
+type Module struct{}
+
+// Params contains the parameters for storyboard generation
+type Params struct {
+	Input          string `json:"input"`                                       // Path to shorts_suggestions.yaml file
+	Output         string `json:"output"`                                      // Path to output directory
+	VideoFile      string `json:"videoFile"`                                   // Path to the source video (default: sourceVideo from the shorts file)
+	OutputFileName string `json:"outputFileName" default:"shorts_suggestions"` // Name of the annotated shorts file, without extension (default: "shorts_suggestions")
+	StoryboardDir  string `json:"storyboardDir" default:"storyboards"`         // Directory for the contact sheets, relative to output (default: "storyboards")
+	Columns        int    `json:"columns" default:"4"`                         // Frames per row (default: 4)
+	Rows           int    `json:"rows" default:"3"`                            // Rows per sheet (default: 3)
+	FrameWidth     int    `json:"frameWidth" default:"320"`                    // Width of each frame in pixels (default: 320)
+	UseProxy       bool   `json:"useProxy" default:"true"`                     // Take the frames from the make_proxy copy of the video when there is one (default: true)
+	QuietFlag      bool   `json:"quietFlag" default:"true"`                    // Suppress ffmpeg output (default: true)
+}
+
+// New creates a new storyboard module
+func New() modules.Module {
+	return &Module{}
+}
+
+// Name returns the module name
+func (m *Module) Name() string {
+	return "storyboard_shorts"
+}
+
+// ParamsTemplate returns the module's parameter struct, used to validate and document workflows
+func (m *Module) ParamsTemplate() interface{} {
+	return Params{}
+}
+
+// Validate checks if the parameters are valid
+func (m *Module) Validate(params map[string]interface{}) error {
+	var p Params
+	if err := modules.ParseParams(params, &p); err != nil {
+		return err
+	}
+
+	// Validate input path
+	if err := utils.ValidateInputPath(p.Input, p.Output, ""); err != nil {
+		return err
+	}
+
+	// Validate output path
+	if err := utils.ValidateOutputPath(p.Output); err != nil {
+		return err
+	}
+
+	// The source video may not exist yet when it is produced by an earlier step
+	if p.VideoFile != "" && !utils.ProducedByWorkflow(p.VideoFile, p.Output) {
+		if _, err := os.Stat(p.VideoFile); os.IsNotExist(err) {
+			return fmt.Errorf("video file does not exist: %s", p.VideoFile)
+		}
+	}
+
+	if p.Columns < 0 || p.Rows < 0 || p.FrameWidth < 0 {
+		return fmt.Errorf("columns, rows and frameWidth must not be negative")
+	}
+	if p.Columns*p.Rows > 100 {
+		return fmt.Errorf("a contact sheet holds at most 100 frames, got %dx%d", p.Columns, p.Rows)
+	}
+
+	// Validate FFmpeg dependency
+	if err := utils.ValidateRequiredDependency("ffmpeg"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Execute renders a contact sheet for every suggested short and references it in the shorts file
+func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (modules.ModuleResult, error) {
+	var p Params
+	if err := modules.ParseParams(params, &p); err != nil {
+		return modules.ModuleResult{}, err
+	}
+
+	// Set default values
+	if p.OutputFileName == "" {
+		p.OutputFileName = "shorts_suggestions"
+	}
+	if p.StoryboardDir == "" {
+		p.StoryboardDir = "storyboards"
+	}
+	if p.Columns == 0 {
+		p.Columns = 4
+	}
+	if p.Rows == 0 {
+		p.Rows = 3
+	}
+	if p.FrameWidth == 0 {
+		p.FrameWidth = 320
+	}
+	// Default to quiet mode (no ffmpeg output) unless explicitly set to false
+	if _, ok := params["quietFlag"]; !ok {
+		p.QuietFlag = true
+	}
+	if _, ok := params["useProxy"]; !ok {
+		p.UseProxy = true
+	}
+
+	resolvedInput := utils.ResolveOutputPath(p.Input, p.Output)
+	doc, err := utils.ReadShortsDocument(resolvedInput)
+	if err != nil {
+		return modules.ModuleResult{}, err
+	}
+
+	videoFile := utils.ResolveOutputPath(p.VideoFile, p.Output)
+	if videoFile == "" {
+		videoFile = doc.SourceVideo()
+	}
+	if videoFile == "" {
+		return modules.ModuleResult{}, fmt.Errorf("videoFile is required when the shorts file has no sourceVideo")
+	}
+	if p.UseProxy {
+		if proxy, ok := utils.FindProxy(videoFile, filepath.Dir(resolvedInput), p.Output); ok {
+			utils.LogInfoContext(ctx, "Taking the frames from the proxy %s", proxy)
+			videoFile = proxy
+		}
+	}
+
+	storyboardDir := p.StoryboardDir
+	if !filepath.IsAbs(storyboardDir) {
+		storyboardDir = filepath.Join(p.Output, storyboardDir)
+	}
+	if err := utils.EnsureDir(storyboardDir); err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to create storyboard directory: %w", err)
+	}
+
+	outputPath := filepath.Join(p.Output, p.OutputFileName+".yaml")
+	outputs := map[string]string{"suggestions": outputPath}
+	for i, clip := range doc.Shorts.Content {
+		startTime, endTime := utils.ClipField(clip, "startTime"), utils.ClipField(clip, "endTime")
+		start, err := utils.ParseTimestamp(startTime)
+		if err != nil {
+			return modules.ModuleResult{}, fmt.Errorf("clip %d: invalid startTime: %w", i+1, err)
+		}
+		end, err := utils.ParseTimestamp(endTime)
+		if err != nil {
+			return modules.ModuleResult{}, fmt.Errorf("clip %d: invalid endTime: %w", i+1, err)
+		}
+		if end <= start {
+			return modules.ModuleResult{}, fmt.Errorf("clip %d: endTime %s must be after startTime %s", i+1, endTime, startTime)
+		}
+
+		sheetName := fmt.Sprintf("%02d_%s-%s.jpg", i+1, compactTimestamp(start), compactTimestamp(end))
+		sheetPath := filepath.Join(storyboardDir, sheetName)
+		utils.LogInfoContext(ctx, "Rendering storyboard %d/%d: %s", i+1, len(doc.Shorts.Content), utils.ClipField(clip, "title"))
+		if err := renderContactSheet(ctx, videoFile, sheetPath, start, end, p); err != nil {
+			return modules.ModuleResult{}, fmt.Errorf("clip %d: %w", i+1, err)
+		}
+
+		// Reference the sheet relative to the shorts file so the output folder can be moved or shared
+		reference := sheetPath
+		if rel, err := filepath.Rel(filepath.Dir(outputPath), sheetPath); err == nil {
+			reference = filepath.ToSlash(rel)
+		}
+		utils.SetMappingValue(clip, "storyboard", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: reference})
+		outputs[sheetName] = sheetPath
+	}
+
+	data, err := doc.Marshal()
+	if err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to generate YAML: %w", err)
+	}
+	if err := utils.AtomicWriteFile(outputPath, data, 0644); err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to write output file: %w", err)
+	}
+
+	utils.LogSuccessContext(ctx, "Storyboards for %d shorts saved to %s", len(doc.Shorts.Content), storyboardDir)
+
+	return modules.ModuleResult{
+		Outputs: outputs,
+		Metadata: map[string]interface{}{
+			"inputFile":     resolvedInput,
+			"sourceVideo":   videoFile,
+			"storyboardDir": storyboardDir,
+			"numShorts":     len(doc.Shorts.Content),
+			"grid":          fmt.Sprintf("%dx%d", p.Columns, p.Rows),
+		},
+		Stats: modules.Stats{Items: len(doc.Shorts.Content)},
+	}, nil
+}
+
+// renderContactSheet tiles frames spread evenly across a clip into a single image
+func renderContactSheet(ctx context.Context, videoFile, outputPath string, start, end time.Duration, p Params) error {
+	frames := p.Columns * p.Rows
+	duration := (end - start).Seconds()
+
+	// Sample at the middle of each of the equal slices so the first and last frames are not black transitions
+	filter := fmt.Sprintf("fps=%s,scale=%d:-2,tile=%dx%d:padding=4:margin=4",
+		strconv.FormatFloat(float64(frames)/duration, 'f', 6, 64), p.FrameWidth, p.Columns, p.Rows)
+	offset := duration / float64(frames) / 2
+
+	args := []string{
+		"-y",
+		"-ss", strconv.FormatFloat(start.Seconds()+offset, 'f', 3, 64),
+		"-t", strconv.FormatFloat(duration, 'f', 3, 64),
+		"-i", videoFile,
+		"-vf", filter,
+		"-frames:v", "1",
+		"-q:v", "3",
+	}
+	if p.QuietFlag {
+		args = append(args, "-loglevel", "error")
+	}
+	args = append(args, outputPath)
+
+	cmd := execCommand(ctx, "ffmpeg", args...)
+	var stderr bytes.Buffer
+	if p.QuietFlag {
+		cmd.Stderr = &stderr
+	} else {
+		cmd.Stdout = utils.LogWriter(ctx)
+		cmd.Stderr = os.Stderr
+	}
+	if err := utils.RunWatched(ctx, cmd); err != nil {
+		if stderr.Len() > 0 {
+			utils.LogErrorContext(ctx, "FFmpeg error: %s", stderr.String())
+		}
+		return fmt.Errorf("ffmpeg contact sheet failed: %w", err)
+	}
+
+	if _, err := os.Stat(outputPath); err != nil {
+		return fmt.Errorf("ffmpeg completed but contact sheet was not created: %s", outputPath)
+	}
+	return nil
+}
+
+// compactTimestamp formats a clip position as HHMMSS for file names
+func compactTimestamp(d time.Duration) string {
+	total := int(d.Seconds())
+	return fmt.Sprintf("%02d%02d%02d", total/3600, total/60%60, total%60)
+}
+
+// GetIO returns the module's input/output specification
+func (m *Module) GetIO() modules.ModuleIO {
+	return modules.ModuleIO{
+		RequiredInputs: []modules.ModuleInput{
+			{
+				Name:        "input",
+				Description: "Path to shorts suggestions YAML file",
+				Patterns:    []string{".yaml"},
+				Type:        string(modules.InputTypeFile),
+			},
+			{
+				Name:        "output",
+				Description: "Path to output directory",
+				Type:        string(modules.InputTypeDirectory),
+			},
+		},
+		OptionalInputs: []modules.ModuleInput{
+			{
+				Name:        "videoFile",
+				Description: "Path to source video file (default: sourceVideo from the shorts file)",
+				Patterns:    []string{".mp4", ".mov"},
+				Type:        string(modules.InputTypeFile),
+			},
+			{
+				Name:        "useProxy",
+				Description: "Take the frames from the make_proxy copy of the video when there is one (default: true)",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "columns",
+				Description: "Frames per row of the contact sheet (default: 4)",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "rows",
+				Description: "Rows of the contact sheet (default: 3)",
+				Type:        string(modules.InputTypeData),
+			},
+		},
+		ProducedOutputs: []modules.ModuleOutput{
+			{
+				Name:        "suggestions",
+				Description: "Shorts suggestions with a storyboard reference on every clip",
+				Patterns:    []string{".yaml"},
+				Type:        string(modules.OutputTypeFile),
+			},
+			{
+				Name:        "storyboards",
+				Description: "Contact sheet image per suggested short",
+				Patterns:    []string{".jpg"},
+				Type:        string(modules.OutputTypeFile),
+			},
+		},
+	}
+}
@@ -0,0 +1,202 @@
+package storyboardshorts
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/shortstest"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const shortsYAML = `shorts:
+  - title: "The big reveal"
+    startTime: "00:01:02"
+    endTime: "00:01:30"
+    description: "Host explains the trick"
+    tags: "magic"
+    shortTitle: "Reveal"
+    score:
+      total: 0.8
+  - title: "Audience reaction"
+    startTime: "00:10:00"
+    endTime: "00:10:24"
+    description: "Crowd goes wild"
+    tags: "crowd"
+`
+
+// recordedArgs holds the arguments of every ffmpeg invocation
+var recordedArgs [][]string
+
+// fakeExecCommand returns a helper process that writes the requested image
+func fakeExecCommand(ctx context.Context, command string, args ...string) *exec.Cmd {
+	recordedArgs = append(recordedArgs, args)
+	cs := []string{"-test.run=TestHelperProcess", "--", command}
+	cs = append(cs, args...)
+	cmd := exec.Command(os.Args[0], cs...)
+	cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1"}
+	return cmd
+}
+
+// TestHelperProcess is not a real test, it's used to mock exec.Command
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	_ = os.WriteFile(os.Args[len(os.Args)-1], []byte("jpg"), 0644)
+	os.Exit(0)
+}
+
+func TestModule_Name(t *testing.T) {
+	assert.Equal(t, "storyboard_shorts", New().Name())
+}
+
+func TestModule_GetIO(t *testing.T) {
+	io := New().GetIO()
+
+	assert.Len(t, io.RequiredInputs, 2)
+	assert.Equal(t, "input", io.RequiredInputs[0].Name)
+	assert.Equal(t, "output", io.RequiredInputs[1].Name)
+
+	assert.Len(t, io.ProducedOutputs, 2)
+	assert.Equal(t, "suggestions", io.ProducedOutputs[0].Name)
+	assert.Equal(t, "storyboards", io.ProducedOutputs[1].Name)
+}
+
+func TestModule_Validate(t *testing.T) {
+	defer func() { utils.ExecLookPath = exec.LookPath }()
+	utils.ExecLookPath = func(file string) (string, error) { return file, nil }
+
+	tempDir := t.TempDir()
+	shortsPath := shortstest.WriteFile(t, tempDir, "/videos/source.mp4", shortsYAML)
+
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		wantErr string
+	}{
+		{
+			name:   "valid defaults",
+			params: map[string]interface{}{"input": shortsPath, "output": tempDir},
+		},
+		{
+			name:   "custom grid",
+			params: map[string]interface{}{"input": shortsPath, "output": tempDir, "columns": 5, "rows": 2},
+		},
+		{
+			name:    "grid too large",
+			params:  map[string]interface{}{"input": shortsPath, "output": tempDir, "columns": 20, "rows": 10},
+			wantErr: "at most 100 frames",
+		},
+		{
+			name:    "negative width",
+			params:  map[string]interface{}{"input": shortsPath, "output": tempDir, "frameWidth": -1},
+			wantErr: "must not be negative",
+		},
+		{
+			name:    "missing video file",
+			params:  map[string]interface{}{"input": shortsPath, "output": tempDir, "videoFile": filepath.Join(t.TempDir(), "missing.mp4")},
+			wantErr: "video file does not exist",
+		},
+		{
+			name:   "video file produced by an earlier step",
+			params: map[string]interface{}{"input": shortsPath, "output": tempDir, "videoFile": filepath.Join(tempDir, "episode_proxy.mp4")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := New().Validate(tt.params)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestModule_Execute(t *testing.T) {
+	defer func() { execCommand = exec.CommandContext }()
+	execCommand = fakeExecCommand
+
+	tempDir := t.TempDir()
+	videoPath := filepath.Join(tempDir, "source.mp4")
+	require.NoError(t, os.WriteFile(videoPath, []byte("dummy"), 0644))
+
+	t.Run("renders a sheet per clip", func(t *testing.T) {
+		recordedArgs = nil
+		shortsPath := shortstest.WriteFile(t, tempDir, videoPath, shortsYAML)
+		result, err := New().Execute(context.Background(), map[string]interface{}{
+			"input":   shortsPath,
+			"output":  tempDir,
+			"columns": 4,
+			"rows":    2,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, shortsPath, result.Outputs["suggestions"])
+		assert.FileExists(t, filepath.Join(tempDir, "storyboards", "01_000102-000130.jpg"))
+		assert.FileExists(t, filepath.Join(tempDir, "storyboards", "02_001000-001024.jpg"))
+
+		// 8 frames over 28 seconds, the first one in the middle of its slice
+		require.Len(t, recordedArgs, 2)
+		joined := strings.Join(recordedArgs[0], " ")
+		assert.Contains(t, joined, "-ss 63.750")
+		assert.Contains(t, joined, "fps=0.285714,scale=320:-2,tile=4x2")
+		assert.Contains(t, joined, "-frames:v 1")
+
+		var annotated struct {
+			Shorts []struct {
+				Title      string                 `yaml:"title"`
+				Storyboard string                 `yaml:"storyboard"`
+				Score      map[string]interface{} `yaml:"score"`
+			} `yaml:"shorts"`
+		}
+		data, err := os.ReadFile(shortsPath)
+		require.NoError(t, err)
+		require.NoError(t, yaml.Unmarshal(data, &annotated))
+		require.Len(t, annotated.Shorts, 2)
+		assert.Equal(t, "storyboards/01_000102-000130.jpg", annotated.Shorts[0].Storyboard)
+		assert.Equal(t, "storyboards/02_001000-001024.jpg", annotated.Shorts[1].Storyboard)
+		assert.Equal(t, 0.8, annotated.Shorts[0].Score["total"], "fields from other steps are kept")
+	})
+
+	t.Run("takes the frames from the proxy", func(t *testing.T) {
+		proxy := filepath.Join(tempDir, "source_proxy.mp4")
+		require.NoError(t, os.WriteFile(proxy, []byte("proxy"), 0644))
+		defer func() { _ = os.Remove(proxy) }()
+		shortsPath := shortstest.WriteFile(t, tempDir, videoPath, shortsYAML)
+
+		recordedArgs = nil
+		_, err := New().Execute(context.Background(), map[string]interface{}{"input": shortsPath, "output": tempDir})
+		require.NoError(t, err)
+		require.Len(t, recordedArgs, 2)
+		assert.Contains(t, strings.Join(recordedArgs[0], " "), "-i "+proxy)
+
+		recordedArgs = nil
+		_, err = New().Execute(context.Background(), map[string]interface{}{"input": shortsPath, "output": tempDir, "useProxy": false})
+		require.NoError(t, err)
+		require.Len(t, recordedArgs, 2)
+		assert.Contains(t, strings.Join(recordedArgs[0], " "), "-i "+videoPath+" ")
+	})
+
+	t.Run("requires a video", func(t *testing.T) {
+		shortsPath := shortstest.WriteFile(t, tempDir, "${source_video}", shortsYAML)
+		_, err := New().Execute(context.Background(), map[string]interface{}{
+			"input":  shortsPath,
+			"output": tempDir,
+		})
+		assert.ErrorContains(t, err, "videoFile is required")
+	})
+}
+
+func TestCompactTimestamp(t *testing.T) {
+	assert.Equal(t, "010203", compactTimestamp(time.Hour+2*time.Minute+3*time.Second+400*time.Millisecond))
+}
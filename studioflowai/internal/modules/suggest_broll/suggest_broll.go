@@ -0,0 +1,602 @@
+package suggestbroll
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	modules "github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	chatgpt "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/chatgpt"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+
+	"gopkg.in/yaml.v3"
+)
+
+// contextKey is a type for context keys
+type contextKey string
+
+// ChatGPTServiceKey is the context key for the ChatGPT service
+const ChatGPTServiceKey = contextKey("chatgpt_service")
+
+// Export formats
+const (
+	FormatYAML = "yaml"
+	FormatCSV  = "csv"
+)
+
+// Module suggests B-roll shots for every suggested short
+type Module struct{}
+
+// Params contains the parameters for B-roll suggestions
+type Params struct {
+	Input            string                 `json:"input"`                                         // Path to shorts_suggestions.yaml
+	Transcript       string                 `json:"transcript"`                                    // Path to the transcript; an SRT places shots at the moment a concept is said
+	Output           string                 `json:"output"`                                        // Path to output directory
+	OutputFileName   string                 `json:"outputFileName" default:"broll_shotlist"`       // Output file name without extension (default: "broll_shotlist")
+	Formats          []string               `json:"formats"`                                       // Export formats: yaml, csv (default: both)
+	ShotsPerClip     int                    `json:"shotsPerClip" default:"4"`                      // Target number of shots per clip (default: 4)
+	Model            string                 `json:"model" default:"gpt-4o"`                        // OpenAI model to use (default: "gpt-4o")
+	FallbackModels   []string               `json:"fallbackModels"`                                // Models tried in order when the primary model fails or returns an invalid shot list
+	Temperature      float64                `json:"temperature" default:"0.5"`                     // Model temperature (default: 0.5)
+	MaxTokens        int                    `json:"maxTokens" default:"2000"`                      // Maximum tokens per clip (default: 2000)
+	RequestTimeoutMS int                    `json:"requestTimeoutMs" default:"120000"`             // API request timeout in milliseconds (default: 120000)
+	Language         string                 `json:"language" default:"English"`                    // Language of the stock search queries (default: "English")
+	PromptFilePath   string                 `json:"promptFilePath" default:"./prompts/broll.yaml"` // Path to custom prompt YAML file (default: "./prompts/broll.yaml")
+	Metadata         map[string]interface{} `json:"metadata"`                                      // Episode details (guest, episode number, recording date, links) for the prompt
+}
+
+// PromptData represents the structure of a YAML prompt template
+type PromptData struct {
+	Title       string `yaml:"title"`
+	Role        string `yaml:"role"`
+	Prompt      string `yaml:"prompt"`
+	Description string `yaml:"description"`
+}
+
+// ShortsData represents the structure of the shorts_suggestions.yaml file
+type ShortsData struct {
+	SourceVideo string      `yaml:"sourceVideo"`
+	Shorts      []ShortClip `yaml:"shorts"`
+}
+
+// ShortClip represents a single short video clip suggestion
+type ShortClip struct {
+	Title       string `yaml:"title"`
+	StartTime   string `yaml:"startTime"`
+	EndTime     string `yaml:"endTime"`
+	Description string `yaml:"description"`
+}
+
+// Shot is one suggested B-roll insert
+type Shot struct {
+	Time     string   `yaml:"time"`     // When the shot appears in the source video (HH:MM:SS)
+	ClipTime string   `yaml:"clipTime"` // When the shot appears in the short (MM:SS)
+	Duration float64  `yaml:"duration"` // Suggested length in seconds
+	Concept  string   `yaml:"concept"`  // Visual noun or idea the speaker mentions
+	Visual   string   `yaml:"visual"`   // Description of the shot
+	Queries  []string `yaml:"queries"`  // Stock footage search queries
+}
+
+// ClipShots is the shot list of one short
+type ClipShots struct {
+	Clip      int    `yaml:"clip"`
+	Title     string `yaml:"title"`
+	StartTime string `yaml:"startTime"`
+	EndTime   string `yaml:"endTime"`
+	Shots     []Shot `yaml:"shots"`
+}
+
+// ShotList is the B-roll shot list of all shorts
+type ShotList struct {
+	SourceVideo string                 `yaml:"sourceVideo,omitempty"`
+	Episode     map[string]interface{} `yaml:"episode,omitempty"`
+	Clips       []ClipShots            `yaml:"clips"`
+}
+
+// modelShots is the response expected from the model for one clip
+type modelShots struct {
+	Shots []Shot `yaml:"shots"`
+}
+
+// New creates a new B-roll suggestion module
+func New() modules.Module {
+	return &Module{}
+}
+
+// Name returns the module name
+func (m *Module) Name() string {
+	return "suggest_broll"
+}
+
+// ParamsTemplate returns the module's parameter struct, used to validate and document workflows
+func (m *Module) ParamsTemplate() interface{} {
+	return Params{}
+}
+
+// Validate checks if the parameters are valid
+func (m *Module) Validate(params map[string]interface{}) error {
+	var p Params
+	if err := modules.ParseParams(params, &p); err != nil {
+		return err
+	}
+
+	if err := utils.ValidateInputPath(p.Input, p.Output, ""); err != nil {
+		return err
+	}
+	if err := utils.ValidateOutputPath(p.Output); err != nil {
+		return err
+	}
+	if p.Transcript == "" {
+		return fmt.Errorf("transcript is required")
+	}
+	if err := utils.ValidateInputPath(p.Transcript, p.Output, ""); err != nil {
+		return err
+	}
+
+	for _, format := range p.Formats {
+		if format != FormatYAML && format != FormatCSV {
+			return fmt.Errorf("unsupported format %q (supported: %s, %s)", format, FormatYAML, FormatCSV)
+		}
+	}
+	if p.ShotsPerClip < 0 {
+		return fmt.Errorf("shotsPerClip cannot be negative: %d", p.ShotsPerClip)
+	}
+
+	// Check if the API key is set - just warn but don't error
+	if !chatgpt.IsAPIKeySet() {
+		utils.LogWarning("OPENAI_API_KEY environment variable is not set. A placeholder shot list will be generated.")
+	}
+
+	if p.PromptFilePath != "" {
+		if _, err := os.Stat(p.PromptFilePath); os.IsNotExist(err) {
+			return fmt.Errorf("prompt template file %s does not exist", p.PromptFilePath)
+		}
+	}
+	return nil
+}
+
+// Execute generates the B-roll shot list
+func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (modules.ModuleResult, error) {
+	var p Params
+	if err := modules.ParseParams(params, &p); err != nil {
+		return modules.ModuleResult{}, err
+	}
+
+	// Set default values
+	if p.OutputFileName == "" {
+		p.OutputFileName = "broll_shotlist"
+	}
+	if len(p.Formats) == 0 {
+		p.Formats = []string{FormatYAML, FormatCSV}
+	}
+	if p.ShotsPerClip == 0 {
+		p.ShotsPerClip = 4
+	}
+	if p.Model == "" {
+		p.Model = "gpt-4o"
+	}
+	if p.Temperature == 0 {
+		p.Temperature = 0.5
+	}
+	if p.MaxTokens == 0 {
+		p.MaxTokens = 2000
+	}
+	if p.RequestTimeoutMS == 0 {
+		p.RequestTimeoutMS = 120000
+	}
+	if p.Language == "" {
+		p.Language = "English"
+	}
+	if p.PromptFilePath == "" {
+		p.PromptFilePath = utils.ResolvePromptPath("./prompts/broll.yaml")
+	}
+
+	if err := utils.EnsureDir(p.Output); err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	shortsPath := utils.ResolveOutputPath(p.Input, p.Output)
+	shorts, err := readShortsFile(shortsPath)
+	if err != nil {
+		return modules.ModuleResult{}, err
+	}
+
+	transcriptPath := utils.ResolveOutputPath(p.Transcript, p.Output)
+	transcript, err := utils.ReadTextFile(transcriptPath)
+	if err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to read transcript file: %w", err)
+	}
+	frontMatter, transcript := utils.SplitEpisodeFrontMatter(transcript)
+	metadata := utils.MergeEpisodeMetadata(frontMatter, p.Metadata)
+
+	var cues []utils.SubtitleCue
+	if strings.EqualFold(filepath.Ext(transcriptPath), ".srt") {
+		if cues, err = utils.ParseSRT(transcript); err != nil {
+			return modules.ModuleResult{}, fmt.Errorf("failed to parse SRT transcript %s: %w", transcriptPath, err)
+		}
+	} else {
+		utils.LogWarningContext(ctx, "Transcript %s has no timestamps; shots are placed from the clip text only. Use the SRT transcript for exact placement", transcriptPath)
+	}
+
+	list := &ShotList{SourceVideo: shorts.SourceVideo, Clips: make([]ClipShots, 0, len(shorts.Shorts))}
+	if len(metadata) > 0 {
+		list.Episode = metadata
+	}
+
+	var chatGPT chatgpt.ChatGPTServicer
+	if chatgpt.IsAPIKeySet() {
+		if chatGPT, err = m.getChatGPTService(ctx); err != nil {
+			return modules.ModuleResult{}, fmt.Errorf("failed to initialize ChatGPT service: %w", err)
+		}
+	} else {
+		utils.LogWarningContext(ctx, "No API key set - generating placeholder B-roll shot list")
+	}
+	promptData := getPromptTemplate(ctx, p.PromptFilePath)
+
+	usedModel := p.Model
+	totalShots := 0
+	for i, short := range shorts.Shorts {
+		start, err := utils.ParseTimestamp(short.StartTime)
+		if err != nil {
+			return modules.ModuleResult{}, fmt.Errorf("short %d: invalid startTime: %w", i+1, err)
+		}
+		end, err := utils.ParseTimestamp(short.EndTime)
+		if err != nil {
+			return modules.ModuleResult{}, fmt.Errorf("short %d: invalid endTime: %w", i+1, err)
+		}
+		if end <= start {
+			return modules.ModuleResult{}, fmt.Errorf("short %d: endTime %s must be after startTime %s", i+1, short.EndTime, short.StartTime)
+		}
+
+		var shots []Shot
+		if chatGPT == nil {
+			shots = placeholderShots(start)
+		} else {
+			utils.LogInfoContext(ctx, "Suggesting B-roll for short %d/%d: %s", i+1, len(shorts.Shorts), short.Title)
+			excerpt := clipExcerpt(transcript, cues, start, end)
+			if shots, usedModel, err = suggestShots(ctx, chatGPT, promptData, short, excerpt, start, end, metadata, p); err != nil {
+				return modules.ModuleResult{}, fmt.Errorf("short %d: %w", i+1, err)
+			}
+		}
+
+		for j := range shots {
+			at, _ := utils.ParseTimestamp(shots[j].Time)
+			shots[j].Time = utils.FormatTimestamp(at)
+			shots[j].ClipTime = formatClipTime(at - start)
+		}
+		totalShots += len(shots)
+		list.Clips = append(list.Clips, ClipShots{
+			Clip:      i + 1,
+			Title:     short.Title,
+			StartTime: short.StartTime,
+			EndTime:   short.EndTime,
+			Shots:     shots,
+		})
+	}
+
+	outputs := make(map[string]string)
+	for _, format := range p.Formats {
+		path := filepath.Join(p.Output, p.OutputFileName+"."+format)
+		var content string
+		switch format {
+		case FormatYAML:
+			data, err := yaml.Marshal(list)
+			if err != nil {
+				return modules.ModuleResult{}, fmt.Errorf("failed to generate YAML: %w", err)
+			}
+			content = string(data)
+		case FormatCSV:
+			if content, err = shotListCSV(list); err != nil {
+				return modules.ModuleResult{}, err
+			}
+		default:
+			return modules.ModuleResult{}, fmt.Errorf("unsupported format %q", format)
+		}
+		if err := utils.WriteTextFile(path, content); err != nil {
+			return modules.ModuleResult{}, fmt.Errorf("failed to write output file: %w", err)
+		}
+		outputs["broll_"+format] = path
+	}
+
+	utils.LogSuccessContext(ctx, "Suggested %d B-roll shots for %d shorts -> %s", totalShots, len(list.Clips), p.Output)
+
+	return modules.ModuleResult{
+		Outputs: outputs,
+		Metadata: map[string]interface{}{
+			"model": usedModel,
+		},
+		Statistics: map[string]interface{}{
+			"model":       usedModel,
+			"inputFile":   shortsPath,
+			"transcript":  transcriptPath,
+			"clips":       len(list.Clips),
+			"shots":       totalShots,
+			"timed":       len(cues) > 0,
+			"processTime": time.Now().Format(time.RFC3339),
+		},
+		Stats: modules.Stats{Items: len(list.Clips)},
+	}, nil
+}
+
+// suggestShots asks the model for the B-roll of one clip. Responses with shots outside the clip
+// are rejected, so the model retries or the next fallback model answers.
+func suggestShots(ctx context.Context, chatGPT chatgpt.ChatGPTServicer, promptData PromptData, short ShortClip, excerpt string, start, end time.Duration, metadata map[string]interface{}, p Params) ([]Shot, string, error) {
+	var prompt strings.Builder
+	prompt.WriteString(strings.TrimSpace(promptData.Prompt))
+	prompt.WriteString("\n\n")
+	fmt.Fprintf(&prompt, "Language of the search queries: %s\n", p.Language)
+	fmt.Fprintf(&prompt, "Number of shots: about %d\n", p.ShotsPerClip)
+	fmt.Fprintf(&prompt, "Clip: %s (%s to %s)\n", short.Title, utils.FormatTimestamp(start), utils.FormatTimestamp(end))
+	if short.Description != "" {
+		fmt.Fprintf(&prompt, "Clip description: %s\n", short.Description)
+	}
+	fmt.Fprintf(&prompt, "Every shot time must be between %s and %s.\n", utils.FormatTimestamp(start), utils.FormatTimestamp(end))
+	if details := utils.EpisodeMetadataPrompt(metadata); details != "" {
+		prompt.WriteString("\n" + details)
+	}
+	prompt.WriteString("\nTranscript:\n")
+	prompt.WriteString(excerpt)
+
+	messages := []chatgpt.ChatMessage{
+		{
+			Role:    "system",
+			Content: fmt.Sprintf("You are a %s. You plan B-roll inserts that illustrate what the speaker says.", promptData.Role),
+		},
+		{
+			Role:    "user",
+			Content: prompt.String(),
+		},
+	}
+
+	var shots []Shot
+	completion, err := chatgpt.CompleteWithFallback(ctx, chatGPT, messages, chatgpt.CompletionOptions{
+		Model:            p.Model,
+		Temperature:      p.Temperature,
+		MaxTokens:        p.MaxTokens,
+		RequestTimeoutMS: p.RequestTimeoutMS,
+	}, chatgpt.FallbackChain{Models: p.FallbackModels}, func(response string) error {
+		parsed, err := parseShots(response, start, end)
+		if err != nil {
+			return err
+		}
+		shots = parsed
+		return nil
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("ChatGPT API request failed: %w", err)
+	}
+	return shots, completion.Model, nil
+}
+
+// parseShots reads the model's shot list and checks that every shot falls within the clip
+func parseShots(response string, start, end time.Duration) ([]Shot, error) {
+	var parsed modelShots
+	if err := yaml.Unmarshal([]byte(stripYAMLFence(response)), &parsed); err != nil {
+		return nil, fmt.Errorf("invalid YAML response: %w", err)
+	}
+	if len(parsed.Shots) == 0 {
+		return nil, fmt.Errorf("response has no shots")
+	}
+	for _, shot := range parsed.Shots {
+		at, err := utils.ParseTimestamp(shot.Time)
+		if err != nil {
+			return nil, fmt.Errorf("shot %q: %w", shot.Concept, err)
+		}
+		if at < start || at > end {
+			return nil, fmt.Errorf("shot %q at %s is outside the clip (%s to %s)", shot.Concept, shot.Time, utils.FormatTimestamp(start), utils.FormatTimestamp(end))
+		}
+		if len(shot.Queries) == 0 {
+			return nil, fmt.Errorf("shot %q has no search queries", shot.Concept)
+		}
+	}
+	return parsed.Shots, nil
+}
+
+// clipExcerpt returns the lines of an SRT transcript spoken during a clip, each with its time in
+// the source video. Untimed transcripts are returned whole.
+func clipExcerpt(transcript string, cues []utils.SubtitleCue, start, end time.Duration) string {
+	if len(cues) == 0 {
+		return transcript
+	}
+	var lines strings.Builder
+	for _, cue := range cues {
+		if cue.End < start || cue.Start > end {
+			continue
+		}
+		fmt.Fprintf(&lines, "[%s] %s\n", utils.FormatTimestamp(max(cue.Start, start)), cue.Text)
+	}
+	return lines.String()
+}
+
+// shotListCSV renders one row per shot for spreadsheets and editing tools
+func shotListCSV(list *ShotList) (string, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	rows := [][]string{{"clip", "clip_title", "time", "clip_time", "duration", "concept", "visual", "queries"}}
+	for _, clip := range list.Clips {
+		for _, shot := range clip.Shots {
+			rows = append(rows, []string{
+				strconv.Itoa(clip.Clip),
+				clip.Title,
+				shot.Time,
+				shot.ClipTime,
+				strconv.FormatFloat(shot.Duration, 'f', -1, 64),
+				shot.Concept,
+				shot.Visual,
+				strings.Join(shot.Queries, "; "),
+			})
+		}
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return "", fmt.Errorf("failed to generate CSV: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// readShortsFile reads the suggested shorts
+func readShortsFile(path string) (*ShortsData, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shorts file: %w", err)
+	}
+	var shorts ShortsData
+	if err := utils.DecodeShorts(data, &shorts); err != nil {
+		return nil, fmt.Errorf("failed to parse shorts file: %w", err)
+	}
+	if len(shorts.Shorts) == 0 {
+		return nil, fmt.Errorf("no shorts found in %s", path)
+	}
+	return &shorts, nil
+}
+
+// placeholderShots returns an example shot used when no API key is configured
+func placeholderShots(start time.Duration) []Shot {
+	return []Shot{{
+		Time:     utils.FormatTimestamp(start),
+		Duration: 3,
+		Concept:  "MOCK OUTPUT - No OPENAI_API_KEY set",
+		Visual:   "Set the OPENAI_API_KEY environment variable to generate real suggestions",
+		Queries:  []string{"podcast studio microphone"},
+	}}
+}
+
+// stripYAMLFence removes a surrounding ```yaml code fence if the model added one
+func stripYAMLFence(content string) string {
+	content = strings.TrimSpace(content)
+	if !strings.HasPrefix(content, "```") {
+		return content
+	}
+	if idx := strings.Index(content, "\n"); idx != -1 {
+		content = content[idx+1:]
+	} else {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(content), "```"))
+}
+
+// formatClipTime formats a time within a short as MM:SS
+func formatClipTime(d time.Duration) string {
+	seconds := int(d / time.Second)
+	return fmt.Sprintf("%02d:%02d", seconds/60, seconds%60)
+}
+
+// getPromptTemplate loads the prompt template from file, falling back to the default
+func getPromptTemplate(ctx context.Context, promptFilePath string) PromptData {
+	if data, err := os.ReadFile(promptFilePath); err == nil {
+		var promptData PromptData
+		if err := yaml.Unmarshal(data, &promptData); err == nil && strings.TrimSpace(promptData.Prompt) != "" {
+			if promptData.Role == "" {
+				promptData.Role = defaultRole
+			}
+			utils.LogDebugContext(ctx, "Using custom B-roll prompt template from YAML file: %s", promptFilePath)
+			return promptData
+		}
+		utils.LogWarningContext(ctx, "Failed to parse B-roll prompt %s, falling back to default", promptFilePath)
+	}
+
+	utils.LogDebugContext(ctx, "Using default B-roll prompt template")
+	return PromptData{
+		Title:  "B-roll Shot List",
+		Role:   defaultRole,
+		Prompt: defaultPrompt,
+	}
+}
+
+const defaultRole = "video editor and stock footage researcher"
+
+const defaultPrompt = `Plan B-roll inserts for the short video clip below.
+
+## REQUIREMENTS:
+1. Pick the key visual nouns and concepts the speaker mentions: objects, places, actions, data and ideas that can be shown on screen.
+2. Place each shot at the transcript time where the concept is said, using the times in brackets.
+3. Suggest 2-3 stock footage search queries per shot that an editor can paste into Pexels, Storyblocks or Artgrid.
+4. Keep shots between 2 and 5 seconds and leave the speaker on screen for emotional or key moments.
+
+## REQUIRED YAML FORMAT (USE EXACTLY THIS FORMAT):
+shots:
+  - time: "HH:MM:SS"
+    duration: 3
+    concept: "Concept mentioned"
+    visual: "Description of the shot"
+    queries:
+      - "search query 1"
+      - "search query 2"
+
+## IMPORTANT: Your response MUST be only the YAML, without prior explanations or code fences.`
+
+// getChatGPTService returns a ChatGPT service from context or creates a new one
+func (m *Module) getChatGPTService(ctx context.Context) (chatgpt.ChatGPTServicer, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("context cannot be nil")
+	}
+
+	// Check if service is provided in context
+	if service, ok := ctx.Value(ChatGPTServiceKey).(chatgpt.ChatGPTServicer); ok {
+		return service, nil
+	}
+
+	// Create new service if not in context
+	return chatgpt.NewChatGPTService()
+}
+
+// GetIO returns the module's input/output specification
+func (m *Module) GetIO() modules.ModuleIO {
+	return modules.ModuleIO{
+		RequiredInputs: []modules.ModuleInput{
+			{
+				Name:        "input",
+				Description: "Path to shorts suggestions YAML file",
+				Patterns:    []string{".yaml"},
+				Type:        string(modules.InputTypeFile),
+			},
+			{
+				Name:        "transcript",
+				Description: "Path to the transcript, preferably SRT",
+				Patterns:    []string{".srt", ".txt"},
+				Type:        string(modules.InputTypeFile),
+			},
+			{
+				Name:        "output",
+				Description: "Path to output directory",
+				Type:        string(modules.InputTypeDirectory),
+			},
+		},
+		OptionalInputs: []modules.ModuleInput{
+			{
+				Name:        "formats",
+				Description: "Export formats (yaml, csv)",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "shotsPerClip",
+				Description: "Target number of shots per clip",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "promptFilePath",
+				Description: "Path to custom prompt YAML file",
+				Type:        string(modules.InputTypeFile),
+			},
+		},
+		ProducedOutputs: []modules.ModuleOutput{
+			{
+				Name:        "broll_yaml",
+				Description: "B-roll shot list per short",
+				Patterns:    []string{".yaml"},
+				Type:        string(modules.OutputTypeFile),
+			},
+			{
+				Name:        "broll_csv",
+				Description: "B-roll shot list as CSV, one row per shot",
+				Patterns:    []string{".csv"},
+				Type:        string(modules.OutputTypeFile),
+			},
+		},
+	}
+}
@@ -0,0 +1,162 @@
+package suggestbroll
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/snapshot"
+	"time"
+
+	services "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/chatgpt"
+	mocks "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/chatgpt/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const shortsYAML = `sourceVideo: episode.mp4
+shorts:
+  - title: "Why analog synths"
+    startTime: "00:01:00"
+    endTime: "00:01:40"
+    description: "The host explains the warmth of analog gear"
+`
+
+const transcriptSRT = `1
+00:00:50,000 --> 00:00:58,000
+Welcome back to the show.
+
+2
+00:01:02,000 --> 00:01:10,000
+I bought a Moog synthesizer in Berlin.
+
+3
+00:01:45,000 --> 00:01:50,000
+Let's take a break.
+`
+
+const shotsResponse = "```yaml\nshots:\n  - time: \"00:01:05\"\n    duration: 3\n    concept: \"Moog synthesizer\"\n    visual: \"Close-up of hands turning synth knobs\"\n    queries:\n      - \"analog synthesizer knobs\"\n      - \"moog synth close up\"\n```"
+
+func writeInputs(t *testing.T) (string, string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	shorts := filepath.Join(dir, "shorts_suggestions.yaml")
+	require.NoError(t, os.WriteFile(shorts, []byte(shortsYAML), 0644))
+	transcript := filepath.Join(dir, "transcript.srt")
+	require.NoError(t, os.WriteFile(transcript, []byte(transcriptSRT), 0644))
+	return shorts, transcript, filepath.Join(dir, "output")
+}
+
+func TestModule_Name(t *testing.T) {
+	assert.Equal(t, "suggest_broll", New().Name())
+}
+
+func TestExecute(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "test-key")
+	shorts, transcript, output := writeInputs(t)
+
+	chatGPT := mocks.NewMockChatGPTServicer(t)
+	chatGPT.EXPECT().GetContent(
+		mock.Anything,
+		mock.MatchedBy(func(messages []services.ChatMessage) bool {
+			prompt := messages[1].Content
+			// Only the lines spoken during the clip, with their source times
+			return strings.Contains(prompt, "[00:01:02] I bought a Moog synthesizer in Berlin.") &&
+				!strings.Contains(prompt, "Welcome back") &&
+				strings.Contains(prompt, "between 00:01:00 and 00:01:40")
+		}),
+		mock.Anything,
+	).Return(shotsResponse, nil)
+
+	ctx := context.WithValue(context.Background(), ChatGPTServiceKey, chatGPT)
+	result, err := New().Execute(ctx, map[string]interface{}{
+		"input":      shorts,
+		"transcript": transcript,
+		"output":     output,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Statistics["shots"])
+
+	data, err := os.ReadFile(result.Outputs["broll_yaml"])
+	require.NoError(t, err)
+	var list ShotList
+	require.NoError(t, yaml.Unmarshal(data, &list))
+	require.Len(t, list.Clips, 1)
+	require.Len(t, list.Clips[0].Shots, 1)
+	shot := list.Clips[0].Shots[0]
+	assert.Equal(t, "00:01:05", shot.Time)
+	assert.Equal(t, "00:05", shot.ClipTime)
+	assert.Equal(t, []string{"analog synthesizer knobs", "moog synth close up"}, shot.Queries)
+
+	csvData, err := os.ReadFile(result.Outputs["broll_csv"])
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(csvData)), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, "clip,clip_title,time,clip_time,duration,concept,visual,queries", lines[0])
+	assert.Equal(t, "1,Why analog synths,00:01:05,00:05,3,Moog synthesizer,Close-up of hands turning synth knobs,analog synthesizer knobs; moog synth close up", lines[1])
+}
+
+func TestExecute_NoAPIKey(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "")
+	shorts, transcript, output := writeInputs(t)
+
+	result, err := New().Execute(context.Background(), map[string]interface{}{
+		"input":      shorts,
+		"transcript": transcript,
+		"output":     output,
+		"formats":    []interface{}{"csv"},
+	})
+	require.NoError(t, err)
+	assert.NotContains(t, result.Outputs, "broll_yaml")
+
+	data, err := os.ReadFile(result.Outputs["broll_csv"])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "MOCK OUTPUT")
+}
+
+func TestParseShots(t *testing.T) {
+	start, end := time.Minute, time.Minute+40*time.Second
+
+	_, err := parseShots("shots:\n  - time: \"00:02:30\"\n    concept: \"Late\"\n    queries: [\"x\"]\n", start, end)
+	assert.ErrorContains(t, err, "outside the clip")
+
+	_, err = parseShots("shots:\n  - time: \"00:01:10\"\n    concept: \"Bare\"\n", start, end)
+	assert.ErrorContains(t, err, "no search queries")
+
+	_, err = parseShots("shots: []\n", start, end)
+	assert.ErrorContains(t, err, "no shots")
+}
+
+// TestGolden records the prompt built with the default template and the shot lists written from a
+// fixture response; rerun with UPDATE_SNAPSHOTS=1 after an intended change
+func TestGolden(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "test-key")
+	shorts, transcript, output := writeInputs(t)
+
+	var sent []services.ChatMessage
+	chatGPT := mocks.NewMockChatGPTServicer(t)
+	chatGPT.EXPECT().GetContent(mock.Anything, mock.Anything, mock.Anything).
+		Run(func(_ context.Context, messages []services.ChatMessage, _ services.CompletionOptions) {
+			sent = messages
+		}).
+		Return(shotsResponse, nil)
+
+	ctx := context.WithValue(context.Background(), ChatGPTServiceKey, chatGPT)
+	result, err := New().Execute(ctx, map[string]interface{}{
+		"input":      shorts,
+		"transcript": transcript,
+		"output":     output,
+	})
+	require.NoError(t, err)
+
+	snapshot.MatchMessages(t, "prompt.txt", sent)
+	for _, name := range []string{"broll_yaml", "broll_csv"} {
+		data, err := os.ReadFile(result.Outputs[name])
+		require.NoError(t, err)
+		snapshot.Match(t, filepath.Base(result.Outputs[name]), string(data))
+	}
+}
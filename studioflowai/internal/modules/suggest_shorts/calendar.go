@@ -0,0 +1,345 @@
+package suggestshorts
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// shortsFileName is the shorts file looked up in a run folder
+const shortsFileName = "shorts_suggestions.yaml"
+
+// weekdays maps the weekday names accepted in calendar rules
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// CalendarRule is the publishing frequency of one platform
+type CalendarRule struct {
+	Platform   string   `yaml:"platform"`   // Platform name, e.g. youtube, tiktok, instagram
+	Times      []string `yaml:"times"`      // Publishing times of a day, HH:MM (default: ["18:00"])
+	Weekdays   []string `yaml:"weekdays"`   // Days to publish on, e.g. [mon, wed, fri] (default: every day)
+	MaxPerWeek int      `yaml:"maxPerWeek"` // Maximum posts per calendar week, Monday to Sunday (default: no limit)
+}
+
+// CalendarOptions configures a publishing calendar
+type CalendarOptions struct {
+	Start   time.Time      // First day of the calendar; its location is used for all times
+	Days    int            // Number of days planned (default: 14)
+	Rules   []CalendarRule // Frequency rules, one per platform
+	Backlog []string       // Shorts files or run folders with evergreen clips used to fill gaps
+}
+
+// CalendarEntry is one planned post
+type CalendarEntry struct {
+	Time        time.Time
+	Platform    string
+	Title       string
+	File        string // Shorts file the clip comes from
+	Clip        int    // 1-based clip number in the shorts file
+	StartTime   string
+	EndTime     string
+	SourceVideo string
+	Evergreen   bool // Clip taken from the backlog to fill a gap
+}
+
+// calendarClip is a clip that can be scheduled
+type calendarClip struct {
+	file        string
+	clip        int
+	short       utils.ShortClip
+	sourceVideo string
+}
+
+// LoadCalendarRules reads the platform rules of a calendar from a YAML file with a platforms list
+func LoadCalendarRules(path string) ([]CalendarRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read calendar rules: %w", err)
+	}
+	var rules struct {
+		Platforms []CalendarRule `yaml:"platforms"`
+	}
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse calendar rules %s: %w", path, err)
+	}
+	if len(rules.Platforms) == 0 {
+		return nil, fmt.Errorf("calendar rules %s have no platforms", path)
+	}
+	return rules.Platforms, nil
+}
+
+// ResolveShortsFiles turns run folders into their shorts file; files are returned as is
+func ResolveShortsFiles(paths []string) ([]string, error) {
+	files := make([]string, 0, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("shorts file not found: %w", err)
+		}
+		if info.IsDir() {
+			path = filepath.Join(path, shortsFileName)
+			if _, err := os.Stat(path); err != nil {
+				return nil, fmt.Errorf("run folder has no %s: %w", shortsFileName, err)
+			}
+		}
+		files = append(files, path)
+	}
+	return files, nil
+}
+
+// PlanCalendar schedules the clips of several runs on every platform. Runs take turns so one
+// episode does not fill the calendar, each clip is posted once per platform, and slots left
+// over are filled with backlog clips, least used first.
+func PlanCalendar(files []string, opts CalendarOptions) ([]CalendarEntry, error) {
+	if opts.Days == 0 {
+		opts.Days = 14
+	}
+	if len(opts.Rules) == 0 {
+		return nil, fmt.Errorf("at least one platform rule is required")
+	}
+
+	clips, err := loadCalendarClips(files, true)
+	if err != nil {
+		return nil, err
+	}
+	backlog, err := loadCalendarClips(opts.Backlog, false)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Date(opts.Start.Year(), opts.Start.Month(), opts.Start.Day(), 0, 0, 0, 0, opts.Start.Location())
+
+	var entries []CalendarEntry
+	for _, rule := range opts.Rules {
+		slots, err := calendarSlots(rule, start, opts.Days)
+		if err != nil {
+			return nil, err
+		}
+		next, uses := 0, make([]int, len(backlog))
+		for _, slot := range slots {
+			var clip calendarClip
+			evergreen := false
+			switch {
+			case next < len(clips):
+				clip = clips[next]
+				next++
+			case len(backlog) > 0:
+				least := 0
+				for i := range backlog {
+					if uses[i] < uses[least] {
+						least = i
+					}
+				}
+				uses[least]++
+				clip, evergreen = backlog[least], true
+			default:
+				continue
+			}
+			entries = append(entries, CalendarEntry{
+				Time:        slot,
+				Platform:    rule.Platform,
+				Title:       calendarTitle(clip.short),
+				File:        clip.file,
+				Clip:        clip.clip,
+				StartTime:   clip.short.StartTime,
+				EndTime:     clip.short.EndTime,
+				SourceVideo: clip.sourceVideo,
+				Evergreen:   evergreen,
+			})
+		}
+		if next < len(clips) {
+			utils.LogWarning("%d clips do not fit in the %s calendar; plan more days or post more often", len(clips)-next, rule.Platform)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].Time.Equal(entries[j].Time) {
+			return entries[i].Time.Before(entries[j].Time)
+		}
+		return entries[i].Platform < entries[j].Platform
+	})
+	return entries, nil
+}
+
+// loadCalendarClips reads the clips of shorts files. Interleaved clips alternate between the
+// files (first clip of every file, then the second, ...); otherwise they keep file order.
+func loadCalendarClips(paths []string, interleave bool) ([]calendarClip, error) {
+	files, err := ResolveShortsFiles(paths)
+	if err != nil {
+		return nil, err
+	}
+	perFile := make([][]calendarClip, 0, len(files))
+	longest := 0
+	for _, file := range files {
+		data, err := utils.ReadShortsFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		clips := make([]calendarClip, 0, len(data.Shorts))
+		for i, short := range data.Shorts {
+			clips = append(clips, calendarClip{file: file, clip: i + 1, short: short, sourceVideo: data.SourceVideo})
+		}
+		perFile = append(perFile, clips)
+		longest = max(longest, len(clips))
+	}
+
+	var clips []calendarClip
+	if !interleave {
+		for _, fileClips := range perFile {
+			clips = append(clips, fileClips...)
+		}
+		return clips, nil
+	}
+	for i := 0; i < longest; i++ {
+		for _, fileClips := range perFile {
+			if i < len(fileClips) {
+				clips = append(clips, fileClips[i])
+			}
+		}
+	}
+	return clips, nil
+}
+
+// calendarSlots lists the publishing times of a platform within the calendar
+func calendarSlots(rule CalendarRule, start time.Time, days int) ([]time.Time, error) {
+	if rule.Platform == "" {
+		return nil, fmt.Errorf("calendar rule without platform")
+	}
+	times := rule.Times
+	if len(times) == 0 {
+		times = []string{"18:00"}
+	}
+	offsets := make([]time.Duration, 0, len(times))
+	for _, t := range times {
+		clock, err := time.Parse("15:04", t)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid time %q (expected HH:MM)", rule.Platform, t)
+		}
+		offsets = append(offsets, time.Duration(clock.Hour())*time.Hour+time.Duration(clock.Minute())*time.Minute)
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+
+	allowed := make(map[time.Weekday]bool)
+	for _, day := range rule.Weekdays {
+		weekday, ok := weekdays[strings.ToLower(day)[:min(3, len(day))]]
+		if !ok {
+			return nil, fmt.Errorf("%s: invalid weekday %q", rule.Platform, day)
+		}
+		allowed[weekday] = true
+	}
+
+	var slots []time.Time
+	perWeek := make(map[string]int)
+	for d := 0; d < days; d++ {
+		day := start.AddDate(0, 0, d)
+		if len(allowed) > 0 && !allowed[day.Weekday()] {
+			continue
+		}
+		year, week := day.ISOWeek()
+		weekKey := fmt.Sprintf("%d-%d", year, week)
+		for _, offset := range offsets {
+			if rule.MaxPerWeek > 0 && perWeek[weekKey] >= rule.MaxPerWeek {
+				break
+			}
+			slots = append(slots, time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location()).Add(offset))
+			perWeek[weekKey]++
+		}
+	}
+	return slots, nil
+}
+
+// calendarTitle is the name of a post in the calendar
+func calendarTitle(short utils.ShortClip) string {
+	if short.ShortTitle != "" {
+		return short.ShortTitle
+	}
+	return short.Title
+}
+
+// CalendarCSV renders one row per planned post
+func CalendarCSV(entries []CalendarEntry) (string, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	rows := [][]string{{"date", "time", "platform", "title", "evergreen", "shorts_file", "clip", "start_time", "end_time", "source_video"}}
+	for _, entry := range entries {
+		rows = append(rows, []string{
+			entry.Time.Format("2006-01-02"),
+			entry.Time.Format("15:04"),
+			entry.Platform,
+			entry.Title,
+			strconv.FormatBool(entry.Evergreen),
+			entry.File,
+			strconv.Itoa(entry.Clip),
+			entry.StartTime,
+			entry.EndTime,
+			entry.SourceVideo,
+		})
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return "", fmt.Errorf("failed to generate CSV: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// CalendarICS renders the planned posts as an iCalendar file that calendar apps can import
+func CalendarICS(entries []CalendarEntry, stamp time.Time) string {
+	var b strings.Builder
+	b.WriteString("BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//StudioFlowAI//Shorts calendar//EN\r\nCALSCALE:GREGORIAN\r\n")
+	for _, entry := range entries {
+		sum := sha1.Sum([]byte(fmt.Sprintf("%s|%d|%s|%s", entry.File, entry.Clip, entry.Platform, entry.Time.UTC().Format(time.RFC3339))))
+		summary := fmt.Sprintf("[%s] %s", entry.Platform, entry.Title)
+		description := fmt.Sprintf("Clip %d (%s - %s) of %s", entry.Clip, entry.StartTime, entry.EndTime, entry.File)
+		if entry.Evergreen {
+			description += "\nEvergreen clip from the backlog"
+		}
+
+		b.WriteString("BEGIN:VEVENT\r\n")
+		writeICSLine(&b, "UID:"+hex.EncodeToString(sum[:])+"@studioflowai")
+		writeICSLine(&b, "DTSTAMP:"+stamp.UTC().Format("20060102T150405Z"))
+		writeICSLine(&b, "DTSTART:"+entry.Time.UTC().Format("20060102T150405Z"))
+		writeICSLine(&b, "DURATION:PT15M")
+		writeICSLine(&b, "SUMMARY:"+escapeICS(summary))
+		writeICSLine(&b, "DESCRIPTION:"+escapeICS(description))
+		writeICSLine(&b, "CATEGORIES:"+escapeICS(entry.Platform))
+		b.WriteString("END:VEVENT\r\n")
+	}
+	b.WriteString("END:VCALENDAR\r\n")
+	return b.String()
+}
+
+// escapeICS escapes text values of an iCalendar property
+func escapeICS(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(text)
+}
+
+// writeICSLine writes a content line folded at 75 octets, without splitting UTF-8 characters
+func writeICSLine(b *strings.Builder, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		limit = 74 // Continuation lines start with a space
+	}
+	b.WriteString(line + "\r\n")
+}
+
+// isRuneStart reports whether a byte starts a UTF-8 character
+func isRuneStart(c byte) bool {
+	return c&0xC0 != 0x80
+}
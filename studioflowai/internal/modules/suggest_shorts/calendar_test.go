@@ -0,0 +1,125 @@
+package suggestshorts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeShorts writes a shorts file with the given clip titles
+func writeShorts(t *testing.T, dir, name string, titles ...string) string {
+	t.Helper()
+	var b strings.Builder
+	b.WriteString("sourceVideo: " + name + ".mp4\nshorts:\n")
+	for _, title := range titles {
+		b.WriteString("  - title: \"" + title + "\"\n    startTime: \"00:01:00\"\n    endTime: \"00:01:30\"\n")
+	}
+	path := filepath.Join(dir, name, "shorts_suggestions.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(b.String()), 0644))
+	return path
+}
+
+func TestPlanCalendar(t *testing.T) {
+	dir := t.TempDir()
+	writeShorts(t, dir, "ep1", "A1", "A2", "A3")
+	writeShorts(t, dir, "ep2", "B1")
+	backlog := writeShorts(t, dir, "evergreen", "E1", "E2")
+
+	// 2026-11-02 is a Monday
+	start := time.Date(2026, 11, 2, 9, 30, 0, 0, time.UTC)
+	entries, err := PlanCalendar([]string{filepath.Join(dir, "ep1"), filepath.Join(dir, "ep2")}, CalendarOptions{
+		Start: start,
+		Days:  7,
+		Rules: []CalendarRule{
+			{Platform: "youtube", Times: []string{"18:00"}, Weekdays: []string{"mon", "wed", "fri", "sun"}},
+			{Platform: "tiktok", Times: []string{"20:00", "12:00"}, MaxPerWeek: 3},
+		},
+		Backlog: []string{backlog},
+	})
+	require.NoError(t, err)
+
+	var youtube, tiktok []CalendarEntry
+	for _, entry := range entries {
+		if entry.Platform == "youtube" {
+			youtube = append(youtube, entry)
+		} else {
+			tiktok = append(tiktok, entry)
+		}
+	}
+
+	// Runs take turns, then the backlog fills the rest
+	require.Len(t, youtube, 4)
+	assert.Equal(t, []string{"A1", "B1", "A2", "A3"}, []string{youtube[0].Title, youtube[1].Title, youtube[2].Title, youtube[3].Title})
+	assert.Equal(t, time.Date(2026, 11, 4, 18, 0, 0, 0, time.UTC), youtube[1].Time)
+	assert.Equal(t, time.Sunday, youtube[3].Time.Weekday())
+
+	require.Len(t, tiktok, 3)
+	assert.Equal(t, time.Date(2026, 11, 2, 12, 0, 0, 0, time.UTC), tiktok[0].Time)
+	assert.Equal(t, time.Date(2026, 11, 3, 12, 0, 0, 0, time.UTC), tiktok[2].Time)
+	assert.False(t, tiktok[2].Evergreen)
+
+	// Entries are sorted by time across platforms
+	for i := 1; i < len(entries); i++ {
+		assert.False(t, entries[i].Time.Before(entries[i-1].Time))
+	}
+
+	entries, err = PlanCalendar([]string{filepath.Join(dir, "ep2")}, CalendarOptions{
+		Start:   start,
+		Days:    4,
+		Rules:   []CalendarRule{{Platform: "youtube"}},
+		Backlog: []string{backlog},
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	assert.Equal(t, []string{"B1", "E1", "E2", "E1"}, []string{entries[0].Title, entries[1].Title, entries[2].Title, entries[3].Title})
+	assert.True(t, entries[1].Evergreen)
+}
+
+func TestPlanCalendar_InvalidRules(t *testing.T) {
+	dir := t.TempDir()
+	file := writeShorts(t, dir, "ep1", "A1")
+	start := time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC)
+
+	_, err := PlanCalendar([]string{file}, CalendarOptions{Start: start, Rules: []CalendarRule{{Platform: "youtube", Times: []string{"6pm"}}}})
+	assert.ErrorContains(t, err, `invalid time "6pm"`)
+
+	_, err = PlanCalendar([]string{file}, CalendarOptions{Start: start, Rules: []CalendarRule{{Platform: "youtube", Weekdays: []string{"someday"}}}})
+	assert.ErrorContains(t, err, `invalid weekday "someday"`)
+
+	_, err = PlanCalendar([]string{dir}, CalendarOptions{Start: start, Rules: []CalendarRule{{Platform: "youtube"}}})
+	assert.ErrorContains(t, err, "run folder has no shorts_suggestions.yaml")
+}
+
+func TestCalendarExports(t *testing.T) {
+	entries := []CalendarEntry{{
+		Time:      time.Date(2026, 11, 2, 18, 0, 0, 0, time.UTC),
+		Platform:  "youtube",
+		Title:     "Synths, drums; and a very long title that needs folding across more than one line of the file",
+		File:      "ep1/shorts_suggestions.yaml",
+		Clip:      2,
+		StartTime: "00:01:00",
+		EndTime:   "00:01:30",
+		Evergreen: true,
+	}}
+
+	csvData, err := CalendarCSV(entries)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(csvData), "\n")
+	require.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[1], `2026-11-02,18:00,youtube,"Synths, drums;`))
+
+	ics := CalendarICS(entries, time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC))
+	assert.Contains(t, ics, "DTSTART:20261102T180000Z\r\n")
+	assert.Contains(t, ics, `SUMMARY:[youtube] Synths\, drums\; and a very long title`)
+	for _, line := range strings.Split(ics, "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+	}
+	// Folded lines continue with a single space
+	assert.Contains(t, strings.ReplaceAll(ics, "\r\n ", ""), `\nEvergreen clip from the backlog`)
+}
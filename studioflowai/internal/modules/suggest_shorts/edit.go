@@ -0,0 +1,129 @@
+package suggestshorts
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// ClipEdit is a change to one clip of a shorts file
+type ClipEdit struct {
+	Clip       int               // 1-based clip number
+	Set        map[string]string // Fields to set, dotted for nested ones (e.g. tiktok.caption)
+	Unset      []string          // Fields to remove, dotted for nested ones
+	ShiftStart time.Duration     // Moves startTime, negative for earlier
+	ShiftEnd   time.Duration     // Moves endTime, negative for earlier
+}
+
+// EditClip applies an edit to a clip of a shorts file and saves it in place. Timestamps are
+// validated and the clip must still end after it starts; nothing is written when the edit
+// is invalid. It returns the fields of the edited clip, keyed by their dotted path.
+func EditClip(shortsFile string, edit ClipEdit) (map[string]string, error) {
+	doc, err := utils.ReadShortsDocument(shortsFile)
+	if err != nil {
+		return nil, err
+	}
+	if edit.Clip < 1 || edit.Clip > len(doc.Shorts.Content) {
+		return nil, fmt.Errorf("clip %d out of range (file has %d clips)", edit.Clip, len(doc.Shorts.Content))
+	}
+	clip := doc.Shorts.Content[edit.Clip-1]
+
+	for _, field := range slices.Sorted(maps.Keys(edit.Set)) {
+		value := edit.Set[field]
+		if field == "" || strings.Contains(field, "..") || strings.HasPrefix(field, ".") || strings.HasSuffix(field, ".") {
+			return nil, fmt.Errorf("invalid field name %q", field)
+		}
+		if field == "startTime" || field == "endTime" {
+			if _, err := utils.ParseTimestamp(value); err != nil {
+				return nil, fmt.Errorf("invalid %s %q: %w", field, value, err)
+			}
+		}
+		if err := setClipPath(clip, strings.Split(field, "."), value); err != nil {
+			return nil, err
+		}
+	}
+	for _, field := range edit.Unset {
+		if field == "startTime" || field == "endTime" {
+			return nil, fmt.Errorf("%s cannot be removed", field)
+		}
+		if !unsetClipPath(clip, strings.Split(field, ".")) {
+			return nil, fmt.Errorf("clip %d has no field %q", edit.Clip, field)
+		}
+	}
+
+	if err := shiftClipTime(clip, "startTime", edit.ShiftStart); err != nil {
+		return nil, err
+	}
+	if err := shiftClipTime(clip, "endTime", edit.ShiftEnd); err != nil {
+		return nil, err
+	}
+
+	start, err := utils.ParseTimestamp(utils.ClipField(clip, "startTime"))
+	if err != nil {
+		return nil, fmt.Errorf("clip %d: invalid startTime: %w", edit.Clip, err)
+	}
+	end, err := utils.ParseTimestamp(utils.ClipField(clip, "endTime"))
+	if err != nil {
+		return nil, fmt.Errorf("clip %d: invalid endTime: %w", edit.Clip, err)
+	}
+	if end <= start {
+		return nil, fmt.Errorf("clip %d: endTime %s must be after startTime %s", edit.Clip, utils.ClipField(clip, "endTime"), utils.ClipField(clip, "startTime"))
+	}
+
+	data, err := doc.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate YAML: %w", err)
+	}
+	if err := utils.AtomicWriteFile(shortsFile, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write shorts file: %w", err)
+	}
+
+	fields := make(map[string]string)
+	flattenClip("", clip, fields, func(string) {})
+	return fields, nil
+}
+
+// shiftClipTime moves a timestamp field of a clip, keeping milliseconds only when it has them
+func shiftClipTime(clip *yaml.Node, field string, shift time.Duration) error {
+	if shift == 0 {
+		return nil
+	}
+	current, err := utils.ParseTimestamp(utils.ClipField(clip, field))
+	if err != nil {
+		return fmt.Errorf("cannot shift %s: %w", field, err)
+	}
+	shifted := current + shift
+	if shifted < 0 {
+		return fmt.Errorf("shifting %s %s by %s goes before the start of the video", field, utils.ClipField(clip, field), shift)
+	}
+
+	value := utils.FormatTimestamp(shifted)
+	if shifted%time.Second != 0 {
+		value = strings.Replace(utils.FormatSRTTimestamp(shifted), ",", ".", 1)
+	}
+	utils.SetMappingValue(clip, field, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Style: utils.MappingValue(clip, field).Style, Value: value})
+	return nil
+}
+
+// unsetClipPath removes a dotted field of a clip and reports whether it existed
+func unsetClipPath(node *yaml.Node, path []string) bool {
+	for _, key := range path[:len(path)-1] {
+		node = utils.MappingValue(node, key)
+		if node == nil || node.Kind != yaml.MappingNode {
+			return false
+		}
+	}
+	key := path[len(path)-1]
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return true
+		}
+	}
+	return false
+}
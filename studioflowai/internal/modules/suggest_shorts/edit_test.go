@@ -0,0 +1,65 @@
+package suggestshorts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEditClip(t *testing.T) {
+	shortsFile := filepath.Join(t.TempDir(), "shorts_suggestions.yaml")
+	require.NoError(t, os.WriteFile(shortsFile, []byte(sheetShorts), 0644))
+
+	fields, err := EditClip(shortsFile, ClipEdit{
+		Clip:       1,
+		Set:        map[string]string{"title": "First clip: the intro", "score.total": "9", "tiktok.caption": "New caption"},
+		Unset:      []string{"description"},
+		ShiftStart: -3 * time.Second,
+		ShiftEnd:   1500 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "First clip: the intro", fields["title"])
+	assert.Equal(t, "00:00:57", fields["startTime"])
+	assert.Equal(t, "00:01:31.500", fields["endTime"])
+	assert.NotContains(t, fields, "description")
+
+	data, err := os.ReadFile(shortsFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "total: 9\n", "the score stays an integer")
+	assert.Contains(t, string(data), "hashtags: [go, shorts]")
+	assert.Contains(t, string(data), `startTime: "00:00:57"`, "edited values keep their quotes")
+	assert.Contains(t, string(data), `- title: "Second clip"`, "other clips are untouched")
+}
+
+func TestEditClipErrors(t *testing.T) {
+	shortsFile := filepath.Join(t.TempDir(), "shorts_suggestions.yaml")
+	require.NoError(t, os.WriteFile(shortsFile, []byte(sheetShorts), 0644))
+
+	tests := []struct {
+		name string
+		edit ClipEdit
+		want string
+	}{
+		{"clip out of range", ClipEdit{Clip: 3, Set: map[string]string{"title": "x"}}, "clip 3 out of range (file has 2 clips)"},
+		{"invalid timestamp", ClipEdit{Clip: 1, Set: map[string]string{"startTime": "1:75"}}, "invalid startTime"},
+		{"end before start", ClipEdit{Clip: 1, ShiftStart: 40 * time.Second}, "endTime 00:01:30 must be after startTime 00:01:40"},
+		{"before the video", ClipEdit{Clip: 1, ShiftStart: -2 * time.Minute}, "goes before the start of the video"},
+		{"unknown field", ClipEdit{Clip: 2, Unset: []string{"tiktok.caption"}}, `clip 2 has no field "tiktok.caption"`},
+		{"timestamp removed", ClipEdit{Clip: 2, Unset: []string{"endTime"}}, "endTime cannot be removed"},
+		{"scalar as mapping", ClipEdit{Clip: 1, Set: map[string]string{"title.text": "x"}}, "title.text is not a nested field"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := EditClip(shortsFile, tt.edit)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+
+	data, err := os.ReadFile(shortsFile)
+	require.NoError(t, err)
+	assert.Equal(t, sheetShorts, string(data), "invalid edits leave the file untouched")
+}
@@ -0,0 +1,311 @@
+package suggestshorts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	chatgpt "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/chatgpt"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// coverageSections are the thirds of an episode a chunked run takes at least one clip from
+var coverageSections = []string{"beginning", "middle", "end"}
+
+// minPartChars keeps parts from becoming too small to hold a clip when the prompt template is long
+const minPartChars = 2000
+
+// transcriptPart is a consecutive part of a transcript that fits the model's context on its own
+type transcriptPart struct {
+	Text       string
+	Start, End time.Duration // Times the part covers; zero when the transcript has no timestamps
+}
+
+// candidate is a clip suggested from one part, with the section of the episode it comes from
+type candidate struct {
+	ID        int    `yaml:"id"`
+	Section   string `yaml:"section"`
+	ShortClip `yaml:",inline"`
+}
+
+// chunkedResult is the outcome of a chunked run
+type chunkedResult struct {
+	Shorts     []ShortClip
+	Model      string
+	Parts      int
+	Candidates int
+	Coverage   map[string]int // Chosen clips per section
+}
+
+// suggestChunked handles transcripts too long for the model's context: each part gets its own
+// request for candidate clips (map), then one request ranks all candidates (reduce). The best
+// candidate of the beginning, middle and end of the episode is always kept, so the choice covers
+// the whole episode even though no request saw all of it.
+func (m *Module) suggestChunked(ctx context.Context, service chatgpt.ChatGPTServicer, p Params, template, prefix, transcript string) (*chunkedResult, error) {
+	maxChars := max(p.ContextTokens*4-len(template)-len(prefix)-1000, minPartChars)
+	parts, duration := splitTranscriptParts(transcript, maxChars)
+	perPart := max(3, (2*p.MaxShorts+len(parts)-1)/len(parts))
+	utils.LogInfoContext(ctx, "Transcript exceeds contextTokens (%d); suggesting up to %d clips from each of %d parts, then ranking them",
+		p.ContextTokens, perPart, len(parts))
+
+	opts := chatgpt.CompletionOptions{
+		Model:            p.Model,
+		Temperature:      p.Temperature,
+		MaxTokens:        p.MaxTokens,
+		RequestTimeoutMS: p.RequestTimeoutMs,
+	}
+	chain := chatgpt.FallbackChain{Models: p.FallbackModels, ValidationAttempts: p.ValidationAttempts, Reprompt: p.Strict}
+
+	var candidates []candidate
+	model := p.Model
+	for i, part := range parts {
+		prompt := prefix + partNote(i, len(parts), part, perPart) + fmt.Sprintf(template, p.MinDuration, p.MaxDuration, part.Text)
+		var clips []ShortClip
+		completion, err := chatgpt.CompleteWithFallback(ctx, service, []chatgpt.ChatMessage{{Role: "user", Content: prompt}}, opts, chain,
+			func(response string) error {
+				var err error
+				clips, err = parseShortsResponse(ctx, response)
+				return err
+			})
+		if err != nil {
+			// One failed part loses its clips, not the run
+			utils.LogWarningContext(ctx, "Part %d of %d failed, its clips are left out: %v", i+1, len(parts), err)
+			continue
+		}
+		model = completion.Model
+		if len(clips) > perPart {
+			clips = clips[:perPart]
+		}
+		for _, clip := range clips {
+			candidates = append(candidates, candidate{
+				ID:        len(candidates) + 1,
+				Section:   sectionOf(clip, i, len(parts), duration),
+				ShortClip: clip,
+			})
+		}
+		utils.LogVerboseContext(ctx, "Part %d of %d: %d candidate clips", i+1, len(parts), len(clips))
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no part of the transcript produced clips")
+	}
+
+	ranking, err := rankCandidates(ctx, service, candidates, opts, chain)
+	if err != nil {
+		utils.LogWarningContext(ctx, "Ranking the candidate clips failed, keeping them in transcript order: %v", err)
+		ranking = make([]int, len(candidates))
+		for i, c := range candidates {
+			ranking[i] = c.ID
+		}
+	}
+
+	chosen := selectWithCoverage(candidates, ranking, p.MaxShorts)
+	result := &chunkedResult{
+		Model:      model,
+		Parts:      len(parts),
+		Candidates: len(candidates),
+		Coverage:   make(map[string]int, len(coverageSections)),
+	}
+	for _, section := range coverageSections {
+		result.Coverage[section] = 0
+	}
+	for _, c := range chosen {
+		result.Shorts = append(result.Shorts, c.ShortClip)
+		result.Coverage[c.Section]++
+	}
+	return result, nil
+}
+
+// splitTranscriptParts splits a transcript into parts of at most maxChars. SRT transcripts are
+// split between cues and their length is returned; others are split between lines.
+func splitTranscriptParts(transcript string, maxChars int) ([]transcriptPart, time.Duration) {
+	var parts []transcriptPart
+	var current strings.Builder
+	var start, end time.Duration
+	flush := func() {
+		if strings.TrimSpace(current.String()) != "" {
+			parts = append(parts, transcriptPart{Text: current.String(), Start: start, End: end})
+		}
+		current.Reset()
+	}
+
+	if cues, err := utils.ParseSRT(transcript); err == nil {
+		for i, cue := range cues {
+			block := fmt.Sprintf("%d\n%s --> %s\n%s\n\n", i+1, utils.FormatSRTTimestamp(cue.Start), utils.FormatSRTTimestamp(cue.End), cue.Text)
+			if current.Len() > 0 && current.Len()+len(block) > maxChars {
+				flush()
+			}
+			if current.Len() == 0 {
+				start = cue.Start
+			}
+			current.WriteString(block)
+			end = cue.End
+		}
+		flush()
+		return parts, utils.SubtitleDuration(cues)
+	}
+
+	for _, line := range strings.Split(transcript, "\n") {
+		// A line longer than a part is split between words
+		for len(line) > maxChars {
+			cut := strings.LastIndex(line[:maxChars], " ")
+			if cut <= 0 {
+				cut = maxChars
+			}
+			flush()
+			current.WriteString(line[:cut] + "\n")
+			flush()
+			line = strings.TrimLeft(line[cut:], " ")
+		}
+		if current.Len() > 0 && current.Len()+len(line)+1 > maxChars {
+			flush()
+		}
+		current.WriteString(line + "\n")
+	}
+	flush()
+	return parts, 0
+}
+
+// partNote tells the model which part of the transcript it reads and how many clips to suggest
+func partNote(index, total int, part transcriptPart, clips int) string {
+	span := ""
+	if part.End > 0 {
+		span = fmt.Sprintf(" (%s to %s)", utils.FormatTimestamp(part.Start), utils.FormatTimestamp(part.End))
+	}
+	return fmt.Sprintf("## TRANSCRIPT PART %d OF %d%s\n"+
+		"The transcript is too long to read at once, so it is sent in parts. Suggest at most %d clips from this part "+
+		"only, with the timestamps as they appear in it; the best clips of all parts are chosen afterwards.\n\n",
+		index+1, total, span, clips)
+}
+
+// sectionOf places a clip in the beginning, middle or end of the episode: by its start time when
+// the length of the episode is known, and otherwise by the position of its part
+func sectionOf(clip ShortClip, part, parts int, duration time.Duration) string {
+	position := (float64(part) + 0.5) / float64(parts)
+	if duration > 0 {
+		if start, err := utils.ParseTimestamp(clip.StartTime); err == nil {
+			position = float64(start) / float64(duration)
+		}
+	}
+	index := min(max(int(position*float64(len(coverageSections))), 0), len(coverageSections)-1)
+	return coverageSections[index]
+}
+
+// rankCandidates asks the model to order the candidates from best to weakest and returns their IDs.
+// Candidates the model leaves out, such as near-duplicates, are missing from the ranking.
+func rankCandidates(ctx context.Context, service chatgpt.ChatGPTServicer, candidates []candidate, opts chatgpt.CompletionOptions, chain chatgpt.FallbackChain) ([]int, error) {
+	type rankedClip struct {
+		ID          int    `yaml:"id"`
+		Section     string `yaml:"section"`
+		Title       string `yaml:"title"`
+		StartTime   string `yaml:"startTime"`
+		EndTime     string `yaml:"endTime"`
+		Description string `yaml:"description"`
+	}
+	items := make([]rankedClip, len(candidates))
+	for i, c := range candidates {
+		items[i] = rankedClip{ID: c.ID, Section: c.Section, Title: c.Title, StartTime: c.StartTime, EndTime: c.EndTime, Description: c.Description}
+	}
+	list, err := yaml.Marshal(items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode candidates: %w", err)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "You are choosing the best short video clips of an episode. The %d candidates below were suggested from separate parts of its transcript, each with the section of the episode it comes from.\n\n", len(candidates))
+	b.WriteString("## CANDIDATES:\n```yaml\n")
+	b.Write(list)
+	b.WriteString("```\n\n")
+	b.WriteString("Rank the candidates from the best short to the weakest, judging the hook, the value for the viewer and whether the clip stands on its own. Leave out candidates that repeat a better one.\n\n")
+	b.WriteString("## IMPORTANT: Respond ONLY with YAML without explanations, in this format:\nranking: [3, 1, 7]\n")
+
+	known := make(map[int]bool, len(candidates))
+	for _, c := range candidates {
+		known[c.ID] = true
+	}
+	var ranking []int
+	utils.LogInfoContext(ctx, "Ranking %d candidate clips using %s model...", len(candidates), opts.Model)
+	_, err = chatgpt.CompleteWithFallback(ctx, service, []chatgpt.ChatMessage{{Role: "user", Content: b.String()}}, opts, chain,
+		func(response string) error {
+			var err error
+			ranking, err = parseRanking(response, known)
+			return err
+		})
+	return ranking, err
+}
+
+// parseRanking reads the candidate IDs of a ranking response, dropping unknown and repeated ones
+func parseRanking(response string, known map[int]bool) ([]int, error) {
+	cleaned := stripRegenFence(response)
+	var ids []int
+	var wrapped struct {
+		Ranking []int `yaml:"ranking"`
+	}
+	if err := yaml.Unmarshal([]byte(cleaned), &wrapped); err == nil && len(wrapped.Ranking) > 0 {
+		ids = wrapped.Ranking
+	} else if err := yaml.Unmarshal([]byte(cleaned), &ids); err != nil {
+		return nil, fmt.Errorf("response is not a ranking of candidate IDs")
+	}
+
+	seen := make(map[int]bool, len(ids))
+	var ranking []int
+	for _, id := range ids {
+		if known[id] && !seen[id] {
+			seen[id] = true
+			ranking = append(ranking, id)
+		}
+	}
+	if len(ranking) == 0 {
+		return nil, fmt.Errorf("ranking names none of the candidates")
+	}
+	return ranking, nil
+}
+
+// selectWithCoverage picks up to limit candidates: first the best of each section, then the rest
+// by rank. A section whose candidates were all left out of the ranking still gets its first one.
+// The chosen candidates are returned in rank order.
+func selectWithCoverage(candidates []candidate, ranking []int, limit int) []candidate {
+	byID := make(map[int]candidate, len(candidates))
+	for _, c := range candidates {
+		byID[c.ID] = c
+	}
+	order := make([]candidate, 0, len(candidates))
+	ranked := make(map[int]bool, len(ranking))
+	for _, id := range ranking {
+		order = append(order, byID[id])
+		ranked[id] = true
+	}
+	for _, c := range candidates {
+		if !ranked[c.ID] {
+			order = append(order, c)
+		}
+	}
+
+	chosen := make(map[int]bool, limit)
+	for _, section := range coverageSections {
+		if len(chosen) >= limit {
+			break
+		}
+		for _, c := range order {
+			if c.Section == section {
+				chosen[c.ID] = true
+				break
+			}
+		}
+	}
+	for _, id := range ranking {
+		if len(chosen) >= limit {
+			break
+		}
+		chosen[id] = true
+	}
+
+	var result []candidate
+	for _, c := range order {
+		if chosen[c.ID] {
+			result = append(result, c)
+		}
+	}
+	return result
+}
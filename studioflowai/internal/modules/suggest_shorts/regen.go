@@ -0,0 +1,542 @@
+package suggestshorts
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	chatgpt "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/chatgpt"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// regenFields lists the clip metadata fields that can be regenerated, keyed by their YAML name
+var regenFields = []string{"title", "shortTitle", "description", "tags"}
+
+// defaultRegenBatchSize is the number of clips RegenerateClips sends in one request
+const defaultRegenBatchSize = 8
+
+// RegenOptions contains the options for regenerating the metadata of clips
+type RegenOptions struct {
+	Clip             int      // 1-based index of the clip in the shorts file
+	Clips            []int    // 1-based indexes of the clips RegenerateClips updates (default: all clips)
+	BatchSize        int      // Clips sent to the model in one request by RegenerateClips (default: 8)
+	Instruction      string   // Extra instruction for the model (e.g. "make the title punchier")
+	Fields           []string // Fields to update (default: title, shortTitle, description, tags)
+	TranscriptFile   string   // Optional transcript used as context for the clip (default: the clip's excerpt)
+	Model            string   // OpenAI model to use (default: "gpt-4o")
+	Temperature      float64  // Model temperature (default: 0.7)
+	MaxTokens        int      // Maximum tokens for the response, per clip (default: 1000)
+	RequestTimeoutMs int      // API request timeout in milliseconds (default: 60000)
+}
+
+// RegenerateClip re-runs metadata generation for one clip of a shorts file and updates it in place.
+// Other clips, timestamps and the rest of the document are left untouched.
+func RegenerateClip(ctx context.Context, service chatgpt.ChatGPTServicer, shortsFile string, opts RegenOptions) (*ShortClip, error) {
+	if opts.Model == "" {
+		opts.Model = "gpt-4o"
+	}
+	if opts.Temperature == 0 {
+		opts.Temperature = 0.7
+	}
+	if opts.MaxTokens == 0 {
+		opts.MaxTokens = 1000
+	}
+	if opts.RequestTimeoutMs == 0 {
+		opts.RequestTimeoutMs = 60000
+	}
+
+	fields, err := normalizeRegenFields(opts.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(shortsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shorts file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse shorts file: %w", err)
+	}
+
+	clipNode, err := findClipNode(&doc, opts.Clip)
+	if err != nil {
+		return nil, err
+	}
+
+	var current ShortClip
+	if err := clipNode.Decode(&current); err != nil {
+		return nil, fmt.Errorf("failed to decode clip %d: %w", opts.Clip, err)
+	}
+
+	var transcript string
+	if opts.TranscriptFile != "" {
+		transcript, err = utils.ReadTextFile(opts.TranscriptFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read transcript: %w", err)
+		}
+	} else {
+		// The excerpt saved with the clip is its context, so regeneration gives the same prompt every time
+		transcript = current.Excerpt
+	}
+
+	prompt, err := buildRegenPrompt(current, fields, opts.Instruction, transcript)
+	if err != nil {
+		return nil, err
+	}
+
+	apiCtx, cancel := context.WithTimeout(ctx, time.Duration(opts.RequestTimeoutMs)*time.Millisecond)
+	defer cancel()
+
+	utils.LogInfoContext(ctx, "Regenerating %s for clip %d using %s model...", strings.Join(fields, ", "), opts.Clip, opts.Model)
+	response, err := service.GetContent(apiCtx, []chatgpt.ChatMessage{
+		{Role: "user", Content: prompt},
+	}, chatgpt.CompletionOptions{
+		Model:            opts.Model,
+		Temperature:      opts.Temperature,
+		MaxTokens:        opts.MaxTokens,
+		RequestTimeoutMS: opts.RequestTimeoutMs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("API request failed: %w", err)
+	}
+
+	generated, err := parseRegenResponse(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse API response: %w\nResponse preview: %s",
+			err, response[:Min(len(response), 1000)])
+	}
+
+	updated := applyRegen(ctx, clipNode, current, *generated, fields, opts.Clip)
+	if err := writeShortsDocument(shortsFile, &doc); err != nil {
+		return nil, err
+	}
+
+	utils.LogSuccessContext(ctx, "Updated clip %d in %s", opts.Clip, shortsFile)
+	return &updated, nil
+}
+
+// RegenerateClips re-runs metadata generation for several clips of a shorts file and updates them
+// in place. Clips are sent to the model in batches, each a single request answered with a list, so
+// an episode with many shorts takes a few calls instead of one per clip. The file is only written
+// when every batch succeeded.
+func RegenerateClips(ctx context.Context, service chatgpt.ChatGPTServicer, shortsFile string, opts RegenOptions) ([]ShortClip, error) {
+	if opts.Model == "" {
+		opts.Model = "gpt-4o"
+	}
+	if opts.Temperature == 0 {
+		opts.Temperature = 0.7
+	}
+	if opts.MaxTokens == 0 {
+		opts.MaxTokens = 1000
+	}
+	if opts.RequestTimeoutMs == 0 {
+		opts.RequestTimeoutMs = 60000
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultRegenBatchSize
+	}
+
+	fields, err := normalizeRegenFields(opts.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(shortsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shorts file: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse shorts file: %w", err)
+	}
+
+	clips := opts.Clips
+	if len(clips) == 0 {
+		count, err := countClips(&doc)
+		if err != nil {
+			return nil, err
+		}
+		for i := 1; i <= count; i++ {
+			clips = append(clips, i)
+		}
+	}
+	clips = slices.Compact(slices.Sorted(slices.Values(clips)))
+
+	batch := make([]regenBatchClip, 0, len(clips))
+	nodes := make(map[int]*yaml.Node, len(clips))
+	for _, clip := range clips {
+		node, err := findClipNode(&doc, clip)
+		if err != nil {
+			return nil, err
+		}
+		var current ShortClip
+		if err := node.Decode(&current); err != nil {
+			return nil, fmt.Errorf("failed to decode clip %d: %w", clip, err)
+		}
+		nodes[clip] = node
+		batch = append(batch, regenBatchClip{Clip: clip, ShortClip: current})
+	}
+
+	var transcript string
+	if opts.TranscriptFile != "" {
+		transcript, err = utils.ReadTextFile(opts.TranscriptFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read transcript: %w", err)
+		}
+	}
+
+	updated := make([]ShortClip, 0, len(batch))
+	for start := 0; start < len(batch); start += opts.BatchSize {
+		part := batch[start:min(start+opts.BatchSize, len(batch))]
+		prompt, err := buildRegenBatchPrompt(part, fields, opts.Instruction, transcript)
+		if err != nil {
+			return nil, err
+		}
+
+		utils.LogInfoContext(ctx, "Regenerating %s for clips %d-%d of %d using %s model...", strings.Join(fields, ", "),
+			start+1, start+len(part), len(batch), opts.Model)
+		apiCtx, cancel := context.WithTimeout(ctx, time.Duration(opts.RequestTimeoutMs)*time.Millisecond)
+		response, err := service.GetContent(apiCtx, []chatgpt.ChatMessage{
+			{Role: "user", Content: prompt},
+		}, chatgpt.CompletionOptions{
+			Model:            opts.Model,
+			Temperature:      opts.Temperature,
+			MaxTokens:        opts.MaxTokens * len(part),
+			RequestTimeoutMS: opts.RequestTimeoutMs,
+		})
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("API request failed: %w", err)
+		}
+
+		generated, err := parseRegenBatchResponse(response)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse API response: %w\nResponse preview: %s",
+				err, response[:Min(len(response), 1000)])
+		}
+		for _, clip := range part {
+			metadata, ok := generated[clip.Clip]
+			if !ok {
+				utils.LogWarningContext(ctx, "Model returned no metadata for clip %d, keeping the existing values", clip.Clip)
+				updated = append(updated, clip.ShortClip)
+				continue
+			}
+			updated = append(updated, applyRegen(ctx, nodes[clip.Clip], clip.ShortClip, *metadata, fields, clip.Clip))
+		}
+	}
+
+	if err := writeShortsDocument(shortsFile, &doc); err != nil {
+		return nil, err
+	}
+	utils.LogSuccessContext(ctx, "Updated %d clips in %s", len(updated), shortsFile)
+	return updated, nil
+}
+
+// applyRegen writes the generated values of the requested fields to a clip's node and returns the
+// updated clip. Empty values keep the existing ones.
+func applyRegen(ctx context.Context, node *yaml.Node, current, generated ShortClip, fields []string, clip int) ShortClip {
+	updated := current
+	values := map[string]string{
+		"title":       generated.Title,
+		"shortTitle":  generated.ShortTitle,
+		"description": generated.Description,
+		"tags":        generated.Tags,
+	}
+	for _, field := range fields {
+		value := strings.TrimSpace(values[field])
+		if value == "" {
+			utils.LogWarningContext(ctx, "Model returned an empty %s for clip %d, keeping the existing value", field, clip)
+			continue
+		}
+		setMappingValue(node, field, value)
+		switch field {
+		case "title":
+			updated.Title = value
+		case "shortTitle":
+			updated.ShortTitle = value
+		case "description":
+			updated.Description = value
+		case "tags":
+			updated.Tags = value
+		}
+	}
+	return updated
+}
+
+// writeShortsDocument saves an edited shorts document
+func writeShortsDocument(path string, doc *yaml.Node) error {
+	out, err := yaml.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to generate YAML: %w", err)
+	}
+	if err := utils.AtomicWriteFile(path, out, 0644); err != nil {
+		return fmt.Errorf("failed to write shorts file: %w", err)
+	}
+	return nil
+}
+
+// normalizeRegenFields validates the requested fields, defaulting to all regenerable fields
+func normalizeRegenFields(fields []string) ([]string, error) {
+	if len(fields) == 0 {
+		return regenFields, nil
+	}
+
+	var result []string
+	for _, f := range fields {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		found := false
+		for _, allowed := range regenFields {
+			if strings.EqualFold(f, allowed) {
+				result = append(result, allowed)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown field %q (allowed: %s)", f, strings.Join(regenFields, ", "))
+		}
+	}
+	if len(result) == 0 {
+		return regenFields, nil
+	}
+	return result, nil
+}
+
+// countClips returns the number of clips in a shorts document
+func countClips(doc *yaml.Node) (int, error) {
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 && doc.Content[0].Kind == yaml.MappingNode {
+		root := doc.Content[0]
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			if root.Content[i].Value == "shorts" && root.Content[i+1].Kind == yaml.SequenceNode {
+				return len(root.Content[i+1].Content), nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("shorts file has no shorts list")
+}
+
+// findClipNode returns the mapping node of the 1-based clip index in a shorts document
+func findClipNode(doc *yaml.Node, clip int) (*yaml.Node, error) {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("shorts file must be a YAML mapping")
+	}
+
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != "shorts" {
+			continue
+		}
+		shorts := root.Content[i+1]
+		if shorts.Kind != yaml.SequenceNode {
+			return nil, fmt.Errorf("shorts must be a list")
+		}
+		if clip < 1 || clip > len(shorts.Content) {
+			return nil, fmt.Errorf("clip %d out of range (file has %d clips)", clip, len(shorts.Content))
+		}
+		node := shorts.Content[clip-1]
+		if node.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("clip %d is not a mapping", clip)
+		}
+		return node, nil
+	}
+
+	return nil, fmt.Errorf("shorts file has no shorts list")
+}
+
+// setMappingValue sets a scalar value on a mapping node, appending the key if it is missing
+func setMappingValue(node *yaml.Node, key, value string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1].Kind = yaml.ScalarNode
+			node.Content[i+1].Tag = "!!str"
+			node.Content[i+1].Value = value
+			node.Content[i+1].Content = nil
+			return
+		}
+	}
+	node.Content = append(node.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value},
+	)
+}
+
+// buildRegenPrompt creates the prompt asking the model to rewrite a single clip's metadata
+func buildRegenPrompt(clip ShortClip, fields []string, instruction, transcript string) (string, error) {
+	// The excerpt is context, not metadata to rewrite
+	clip.Excerpt, clip.Cues = "", nil
+	current, err := yaml.Marshal(clip)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode clip: %w", err)
+	}
+
+	var b strings.Builder
+	b.WriteString("You are improving the metadata of ONE short video clip that was already selected from a longer video.\n")
+	b.WriteString("Keep the same language as the current metadata. Do not change startTime or endTime.\n\n")
+	b.WriteString("## CURRENT CLIP:\n```yaml\n")
+	b.Write(current)
+	b.WriteString("```\n\n")
+	fmt.Fprintf(&b, "## FIELDS TO REWRITE: %s\n", strings.Join(fields, ", "))
+	if instruction != "" {
+		fmt.Fprintf(&b, "## INSTRUCTION: %s\n", instruction)
+	}
+	b.WriteString(`
+## RULES:
+- shortTitle: must be no more than 40 characters
+- title: must be maximum 100 characters including high impact hashtags using #hashtags format
+- tags: comma-separated hashtags
+
+## IMPORTANT: Respond ONLY with the rewritten clip as YAML using the keys title, shortTitle, description and tags, without explanations.
+`)
+	if transcript != "" {
+		fmt.Fprintf(&b, "\nTranscript (for context, the clip covers %s to %s):\n%s", clip.StartTime, clip.EndTime, transcript)
+	}
+
+	return b.String(), nil
+}
+
+// regenBatchClip is a clip as sent to the model in a batch, numbered so answers can be matched
+type regenBatchClip struct {
+	Clip      int `yaml:"clip"`
+	ShortClip `yaml:",inline"`
+}
+
+// buildRegenBatchPrompt creates the prompt asking the model to rewrite the metadata of several
+// clips at once. Without a transcript, each clip's excerpt is sent with it as context.
+func buildRegenBatchPrompt(clips []regenBatchClip, fields []string, instruction, transcript string) (string, error) {
+	type promptClip struct {
+		regenBatchClip `yaml:",inline"`
+		Context        string `yaml:"context,omitempty"`
+	}
+	items := make([]promptClip, len(clips))
+	for i, clip := range clips {
+		item := promptClip{regenBatchClip: clip}
+		if transcript == "" {
+			item.Context = clip.Excerpt
+		}
+		// The excerpt is context, not metadata to rewrite
+		item.Excerpt, item.Cues = "", nil
+		items[i] = item
+	}
+	current, err := yaml.Marshal(items)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode clips: %w", err)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "You are improving the metadata of %d short video clips that were already selected from a longer video.\n", len(clips))
+	b.WriteString("Rewrite each clip on its own, keeping the same language as its current metadata. Do not change startTime or endTime.\n")
+	b.WriteString("The context of a clip, when given, is the transcript it was cut from.\n\n")
+	b.WriteString("## CURRENT CLIPS:\n```yaml\n")
+	b.Write(current)
+	b.WriteString("```\n\n")
+	fmt.Fprintf(&b, "## FIELDS TO REWRITE: %s\n", strings.Join(fields, ", "))
+	if instruction != "" {
+		fmt.Fprintf(&b, "## INSTRUCTION: %s\n", instruction)
+	}
+	b.WriteString(`
+## RULES:
+- shortTitle: must be no more than 40 characters
+- title: must be maximum 100 characters including high impact hashtags using #hashtags format
+- tags: comma-separated hashtags
+
+## IMPORTANT: Respond ONLY with YAML without explanations: a list under the key clips with one item per clip, each with its clip number and the keys title, shortTitle, description and tags.
+`)
+	if transcript != "" {
+		fmt.Fprintf(&b, "\nTranscript (for context):\n%s", transcript)
+	}
+	return b.String(), nil
+}
+
+// parseRegenBatchResponse extracts the metadata of each clip from a batch response, keyed by clip number
+func parseRegenBatchResponse(content string) (map[int]*ShortClip, error) {
+	cleaned := stripRegenFence(content)
+
+	var items []map[string]interface{}
+	var wrapped struct {
+		Clips []map[string]interface{} `yaml:"clips"`
+	}
+	if err := yaml.Unmarshal([]byte(cleaned), &wrapped); err == nil && len(wrapped.Clips) > 0 {
+		items = wrapped.Clips
+	} else if err := yaml.Unmarshal([]byte(cleaned), &items); err != nil {
+		return nil, fmt.Errorf("response is not a list of clips")
+	}
+
+	clips := make(map[int]*ShortClip, len(items))
+	for _, item := range items {
+		number, ok := item["clip"].(int)
+		if !ok {
+			continue
+		}
+		if clip := regenClipFromMap(item); clip != nil {
+			clips[number] = clip
+		}
+	}
+	if len(clips) == 0 {
+		return nil, fmt.Errorf("response contains no clip metadata")
+	}
+	return clips, nil
+}
+
+// stripRegenFence returns the YAML of a model response, without a surrounding code fence
+func stripRegenFence(content string) string {
+	cleaned := strings.TrimSpace(content)
+	if start := strings.Index(cleaned, "```"); start != -1 {
+		cleaned = cleaned[start+3:]
+		if nl := strings.Index(cleaned, "\n"); nl != -1 {
+			cleaned = cleaned[nl+1:]
+		}
+		if end := strings.Index(cleaned, "```"); end != -1 {
+			cleaned = cleaned[:end]
+		}
+	}
+	return strings.TrimSpace(cleaned)
+}
+
+// parseRegenResponse extracts the clip metadata from the model response
+func parseRegenResponse(content string) (*ShortClip, error) {
+	cleaned := strings.TrimPrefix(stripRegenFence(content), "- ")
+
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal([]byte(cleaned), &raw); err != nil {
+		return nil, err
+	}
+
+	clip := regenClipFromMap(raw)
+	if clip == nil {
+		return nil, fmt.Errorf("response contains no clip metadata")
+	}
+	return clip, nil
+}
+
+// regenClipFromMap reads the metadata fields of a clip, or returns nil when there are none
+func regenClipFromMap(raw map[string]interface{}) *ShortClip {
+	// Accept short_title as well, since the generation prompt uses that spelling
+	clip := &ShortClip{}
+	for key, value := range raw {
+		s := strings.TrimSpace(fmt.Sprint(value))
+		switch strings.ToLower(strings.ReplaceAll(key, "_", "")) {
+		case "title":
+			clip.Title = s
+		case "shorttitle":
+			clip.ShortTitle = s
+		case "description":
+			clip.Description = s
+		case "tags":
+			clip.Tags = s
+		}
+	}
+
+	if clip.Title == "" && clip.ShortTitle == "" && clip.Description == "" && clip.Tags == "" {
+		return nil
+	}
+	return clip
+}
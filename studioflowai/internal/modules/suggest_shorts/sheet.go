@@ -0,0 +1,281 @@
+package suggestshorts
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// clipColumn is the column holding the 1-based clip number in exported tables
+const clipColumn = "clip"
+
+// leadingColumns are placed first so reviewers see the fields they edit most
+var leadingColumns = []string{"title", "shortTitle", "startTime", "endTime", "description", "tags"}
+
+// ShortsTable flattens a shorts file into a table with a header row and one row per clip.
+// Nested fields, such as per-platform captions, become dotted columns like "tiktok.caption",
+// and lists of scalars are joined with ", ".
+func ShortsTable(shortsFile string) ([][]string, error) {
+	doc, err := utils.ReadShortsDocument(shortsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := append([]string{}, leadingColumns...)
+	known := make(map[string]bool)
+	for _, column := range columns {
+		known[column] = true
+	}
+	values := make([]map[string]string, len(doc.Shorts.Content))
+	for i, clip := range doc.Shorts.Content {
+		values[i] = make(map[string]string)
+		flattenClip("", clip, values[i], func(column string) {
+			if !known[column] {
+				known[column] = true
+				columns = append(columns, column)
+			}
+		})
+	}
+
+	rows := [][]string{append([]string{clipColumn}, columns...)}
+	for i := range doc.Shorts.Content {
+		row := []string{strconv.Itoa(i + 1)}
+		for _, column := range columns {
+			row = append(row, values[i][column])
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// ApplyShortsTable writes the edits of a table created by ShortsTable back to the shorts file.
+// Rows are matched to clips by the clip column; clips without a row are left untouched.
+// It returns the number of clips that changed.
+func ApplyShortsTable(shortsFile string, rows [][]string) (int, error) {
+	if len(rows) == 0 {
+		return 0, fmt.Errorf("table is empty")
+	}
+	header := make([]string, len(rows[0]))
+	clipIndex := -1
+	for i, column := range rows[0] {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(column, "\ufeff"))
+		if header[i] == clipColumn {
+			clipIndex = i
+		}
+	}
+	if clipIndex == -1 {
+		return 0, fmt.Errorf("table has no %q column", clipColumn)
+	}
+
+	doc, err := utils.ReadShortsDocument(shortsFile)
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	seen := make(map[int]bool)
+	for r, row := range rows[1:] {
+		if isBlankRow(row) {
+			continue
+		}
+		number, err := strconv.Atoi(strings.TrimSpace(cell(row, clipIndex)))
+		if err != nil || number < 1 || number > len(doc.Shorts.Content) {
+			return 0, fmt.Errorf("row %d: invalid clip number %q (the file has %d clips)", r+2, cell(row, clipIndex), len(doc.Shorts.Content))
+		}
+		if seen[number] {
+			return 0, fmt.Errorf("row %d: clip %d appears more than once", r+2, number)
+		}
+		seen[number] = true
+
+		clip := doc.Shorts.Content[number-1]
+		current := make(map[string]string)
+		flattenClip("", clip, current, func(string) {})
+
+		clipChanged := false
+		for i, column := range header {
+			if i == clipIndex || column == "" {
+				continue
+			}
+			value := strings.TrimSpace(cell(row, i))
+			if previous, ok := current[column]; value == strings.TrimSpace(previous) || (!ok && value == "") {
+				continue
+			}
+			if column == "startTime" || column == "endTime" {
+				if _, err := utils.ParseTimestamp(value); err != nil {
+					return 0, fmt.Errorf("row %d: invalid %s %q: %w", r+2, column, value, err)
+				}
+			}
+			if err := setClipPath(clip, strings.Split(column, "."), value); err != nil {
+				return 0, fmt.Errorf("row %d: %w", r+2, err)
+			}
+			clipChanged = true
+		}
+		if !clipChanged {
+			continue
+		}
+
+		start, _ := utils.ParseTimestamp(utils.ClipField(clip, "startTime"))
+		end, _ := utils.ParseTimestamp(utils.ClipField(clip, "endTime"))
+		if end <= start {
+			return 0, fmt.Errorf("row %d: endTime must be after startTime", r+2)
+		}
+		changed++
+	}
+
+	if changed == 0 {
+		return 0, nil
+	}
+	data, err := doc.Marshal()
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate YAML: %w", err)
+	}
+	if err := utils.AtomicWriteFile(shortsFile, data, 0644); err != nil {
+		return 0, fmt.Errorf("failed to write shorts file: %w", err)
+	}
+	return changed, nil
+}
+
+// ExportShortsCSV writes the table of a shorts file as CSV
+func ExportShortsCSV(shortsFile, csvFile string) error {
+	rows, err := ShortsTable(shortsFile)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	if err := w.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to encode CSV: %w", err)
+	}
+	if err := utils.AtomicWriteFile(csvFile, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write CSV file: %w", err)
+	}
+	return nil
+}
+
+// ImportShortsCSV applies an edited CSV export to a shorts file and returns the number of changed clips
+func ImportShortsCSV(shortsFile, csvFile string) (int, error) {
+	f, err := os.Open(csvFile)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open CSV file: %w", err)
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	// Spreadsheet apps drop trailing empty cells, so rows may be shorter than the header
+	r.FieldsPerRecord = -1
+	rows, err := r.ReadAll()
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse CSV file: %w", err)
+	}
+	return ApplyShortsTable(shortsFile, rows)
+}
+
+// flattenClip collects the scalar fields of a clip keyed by their dotted path
+func flattenClip(prefix string, node *yaml.Node, values map[string]string, addColumn func(string)) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		column := node.Content[i].Value
+		if prefix != "" {
+			column = prefix + "." + column
+		}
+		switch value := node.Content[i+1]; value.Kind {
+		case yaml.ScalarNode:
+			values[column] = value.Value
+			addColumn(column)
+		case yaml.MappingNode:
+			flattenClip(column, value, values, addColumn)
+		case yaml.SequenceNode:
+			items := make([]string, 0, len(value.Content))
+			for _, item := range value.Content {
+				if item.Kind != yaml.ScalarNode {
+					items = nil
+					break
+				}
+				items = append(items, item.Value)
+			}
+			// Lists of mappings have no sensible cell representation and are not exported
+			if items != nil {
+				values[column] = strings.Join(items, ", ")
+				addColumn(column)
+			}
+		}
+	}
+}
+
+// setClipPath sets a dotted field of a clip, creating intermediate mappings as needed
+func setClipPath(node *yaml.Node, path []string, value string) error {
+	for _, key := range path[:len(path)-1] {
+		next := utils.MappingValue(node, key)
+		if next == nil {
+			next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			utils.SetMappingValue(node, key, next)
+		}
+		if next.Kind != yaml.MappingNode {
+			return fmt.Errorf("%s is not a nested field", strings.Join(path, "."))
+		}
+		node = next
+	}
+
+	key := path[len(path)-1]
+	existing := utils.MappingValue(node, key)
+	if existing != nil && existing.Kind == yaml.SequenceNode {
+		list := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: existing.Style}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list.Content = append(list.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: item})
+			}
+		}
+		utils.SetMappingValue(node, key, list)
+		return nil
+	}
+
+	tag, style := "!!str", yaml.Style(0)
+	if existing != nil && existing.Kind == yaml.ScalarNode {
+		// Quoted and block values keep their style, so edited files still read like the original
+		style = existing.Style
+		if keepsTag(existing.Tag, value) {
+			tag = existing.Tag
+		}
+	}
+	utils.SetMappingValue(node, key, &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Style: style, Value: value})
+	return nil
+}
+
+// keepsTag reports whether an edited value still fits the numeric or boolean type of the field
+func keepsTag(tag, value string) bool {
+	switch tag {
+	case "!!int":
+		_, err := strconv.Atoi(value)
+		return err == nil
+	case "!!float":
+		_, err := strconv.ParseFloat(value, 64)
+		return err == nil
+	case "!!bool":
+		_, err := strconv.ParseBool(value)
+		return err == nil
+	}
+	return false
+}
+
+// cell returns a cell of a row, or "" when the row is shorter
+func cell(row []string, i int) string {
+	if i < len(row) {
+		return row[i]
+	}
+	return ""
+}
+
+// isBlankRow reports whether every cell of a row is empty
+func isBlankRow(row []string) bool {
+	for _, c := range row {
+		if strings.TrimSpace(c) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,99 @@
+package suggestshorts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sheetShorts = `sourceVideo: ep12.mp4
+shorts:
+  - title: "First clip"
+    startTime: "00:01:00"
+    endTime: "00:01:30"
+    description: "Intro"
+    tags: "go, video"
+    score:
+      total: 8
+    tiktok:
+      caption: "Watch this"
+      hashtags: [go, shorts]
+  - title: "Second clip"
+    startTime: "00:05:00"
+    endTime: "00:05:45"
+    shortTitle: "Second"
+`
+
+func TestShortsTableRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	shortsFile := filepath.Join(dir, "shorts_suggestions.yaml")
+	require.NoError(t, os.WriteFile(shortsFile, []byte(sheetShorts), 0644))
+
+	rows, err := ShortsTable(shortsFile)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, []string{"clip", "title", "shortTitle", "startTime", "endTime", "description", "tags",
+		"score.total", "tiktok.caption", "tiktok.hashtags"}, rows[0])
+	assert.Equal(t, []string{"1", "First clip", "", "00:01:00", "00:01:30", "Intro", "go, video", "8", "Watch this", "go, shorts"}, rows[1])
+	assert.Equal(t, "Second", rows[2][2])
+
+	// An unchanged table leaves the file alone
+	changed, err := ApplyShortsTable(shortsFile, rows)
+	require.NoError(t, err)
+	assert.Equal(t, 0, changed)
+
+	csvFile := filepath.Join(dir, "shorts.csv")
+	require.NoError(t, ExportShortsCSV(shortsFile, csvFile))
+
+	// Edit the second clip as a reviewer would, dropping the trailing empty cells
+	edited := [][]string{
+		rows[0],
+		{"2", "Second clip, retitled", "Second", "00:05:02", "00:05:45", "", "", "", "Caption added"},
+	}
+	changed, err = ApplyShortsTable(shortsFile, edited)
+	require.NoError(t, err)
+	assert.Equal(t, 1, changed)
+
+	rows, err = ShortsTable(shortsFile)
+	require.NoError(t, err)
+	assert.Equal(t, "Second clip, retitled", rows[2][1])
+	assert.Equal(t, "00:05:02", rows[2][3])
+	assert.Equal(t, "Caption added", rows[2][8])
+	assert.Equal(t, "Watch this", rows[1][8], "clips without a row are untouched")
+
+	// Re-applying the original export restores the first state, keeping the score an integer
+	changed, err = ImportShortsCSV(shortsFile, csvFile)
+	require.NoError(t, err)
+	assert.Equal(t, 1, changed)
+	data, err := os.ReadFile(shortsFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "total: 8\n")
+	assert.Contains(t, string(data), "hashtags: [go, shorts]")
+}
+
+func TestApplyShortsTableErrors(t *testing.T) {
+	shortsFile := filepath.Join(t.TempDir(), "shorts_suggestions.yaml")
+	require.NoError(t, os.WriteFile(shortsFile, []byte(sheetShorts), 0644))
+	header := []string{"clip", "startTime", "endTime"}
+
+	tests := []struct {
+		name string
+		rows [][]string
+		want string
+	}{
+		{"no clip column", [][]string{{"title"}, {"x"}}, "no \"clip\" column"},
+		{"unknown clip", [][]string{header, {"3", "00:00:01", "00:00:02"}}, "invalid clip number"},
+		{"duplicate clip", [][]string{header, {"1", "00:01:00", "00:01:30"}, {"1", "00:01:00", "00:01:30"}}, "more than once"},
+		{"bad timestamp", [][]string{header, {"1", "soon", "00:01:30"}}, "invalid startTime"},
+		{"end before start", [][]string{header, {"1", "00:02:00", "00:01:30"}}, "endTime must be after startTime"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ApplyShortsTable(shortsFile, tt.rows)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
@@ -9,7 +9,6 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
-	"time"
 
 	modules "github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
 	chatgpt "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/chatgpt"
@@ -28,34 +27,62 @@ type Module struct{}
 
 // Params contains the parameters for shorts suggestion generation
 type Params struct {
-	Input            string  `json:"input"`            // Path to input transcript file or directory
-	Output           string  `json:"output"`           // Path to output directory
-	FilePattern      string  `json:"filePattern"`      // File pattern to match in input directory (default: "*_corrected.txt")
-	OutputFileName   string  `json:"outputFileName"`   // Custom output file name (without extension)
-	Model            string  `json:"model"`            // OpenAI model to use (default: "gpt-4o")
-	Temperature      float64 `json:"temperature"`      // Model temperature (default: 0.7)
-	MaxTokens        int     `json:"maxTokens"`        // Maximum tokens for the response (default: 4000)
-	MinDuration      int     `json:"minDuration"`      // Minimum duration of shorts in seconds (default: 15)
-	MaxDuration      int     `json:"maxDuration"`      // Maximum duration of shorts in seconds (default: 60)
-	MaxShorts        int     `json:"maxShorts"`        // Maximum number of shorts to generate (default: 10)
-	PromptFilePath   string  `json:"promptFilePath"`   // Path to custom prompt YAML file
-	RequestTimeoutMs int     `json:"requestTimeoutMs"` // API request timeout in milliseconds (default: 60000)
+	Input              string                 `json:"input"`                                 // Path to input transcript file or directory
+	Output             string                 `json:"output"`                                // Path to output directory
+	FilePattern        string                 `json:"filePattern" default:"*_corrected.txt"` // File pattern to match in input directory (default: "*_corrected.txt")
+	Include            []string               `json:"include"`                               // Patterns of input directory files to consider, such as "**/*_corrected.txt"; replaces filePattern
+	Exclude            []string               `json:"exclude"`                               // Patterns of input directory files to leave out
+	InputSelection     string                 `json:"inputSelection" default:"newest"`       // File used when several match: newest, largest, alphabetical or a file name (default: "newest")
+	OutputFileName     string                 `json:"outputFileName"`                        // Custom output file name (without extension)
+	Model              string                 `json:"model" default:"gpt-4o"`                // OpenAI model to use (default: "gpt-4o")
+	FallbackModels     []string               `json:"fallbackModels"`                        // Models tried in order when the primary model fails or returns invalid YAML
+	Strict             bool                   `json:"strict"`                                // Send the errors of invalid YAML back to the model and ask it to correct its response, instead of asking again from scratch
+	ValidationAttempts int                    `json:"validationAttempts" default:"2"`        // Responses per model checked before falling back to the next one (default: 2)
+	Temperature        float64                `json:"temperature" default:"0.7"`             // Model temperature (default: 0.7)
+	MaxTokens          int                    `json:"maxTokens" default:"4000"`              // Maximum tokens for the response (default: 4000)
+	MinDuration        int                    `json:"minDuration" default:"15"`              // Minimum duration of shorts in seconds (default: 15)
+	MaxDuration        int                    `json:"maxDuration" default:"60"`              // Maximum duration of shorts in seconds (default: 60)
+	MaxShorts          int                    `json:"maxShorts" default:"10"`                // Maximum number of shorts to generate (default: 10)
+	PromptFilePath     string                 `json:"promptFilePath"`                        // Path to custom prompt YAML file
+	RequestTimeoutMs   int                    `json:"requestTimeoutMs" default:"60000"`      // API request timeout in milliseconds (default: 60000)
+	TitleHistoryFile   string                 `json:"titleHistoryFile"`                      // Path to CSV/JSON of past titles with performance metrics (optional)
+	FewShotCount       int                    `json:"fewShotCount" default:"5"`              // Number of top past titles to include as examples (default: 5)
+	FewShotMetric      string                 `json:"fewShotMetric" default:"views"`         // Metric used to rank past titles: views, likes, comments, ctr, engagement (default: "views")
+	Metadata           map[string]interface{} `json:"metadata"`                              // Episode details (guest, episode number, recording date, links) for the prompt and output
+	MetadataFile       string                 `json:"metadataFile"`                          // YAML file with episode details; inline metadata wins (optional)
+	TranscriptMode     string                 `json:"transcriptMode" default:"auto"`         // How the transcript reaches the model: inline, file (uploaded, OpenAI only), chunked or auto (default: "auto")
+	ContextTokens      int                    `json:"contextTokens" default:"100000"`        // Estimated prompt size above which auto mode uploads or chunks the transcript (default: 100000)
+	SubtitleFile       string                 `json:"subtitleFile"`                          // SRT file whose cues give each clip its excerpt, when the transcript has no timestamps (optional)
+	StartTime          string                 `json:"startTime"`                             // Only suggest clips from this time of the source video on, e.g. "00:30:00" (optional)
+	EndTime            string                 `json:"endTime"`                               // Only suggest clips up to this time of the source video (optional)
 }
 
+// Transcript modes
+const (
+	TranscriptInline = "inline"  // The transcript is part of the prompt
+	TranscriptFile   = "file"    // The transcript is uploaded and attached to the prompt as a file
+	TranscriptChunk  = "chunked" // Each part of the transcript gets its own request; their clips are ranked in a final one
+	TranscriptAuto   = "auto"    // Inline, unless the prompt would exceed ContextTokens
+)
+
 // ShortClip represents a single short video clip suggestion
 type ShortClip struct {
-	Title       string `yaml:"title"`       // Title/description of the short
-	StartTime   string `yaml:"startTime"`   // Start timestamp in HH:MM:SS format
-	EndTime     string `yaml:"endTime"`     // End timestamp in HH:MM:SS format
-	Description string `yaml:"description"` // Additional description/context
-	Tags        string `yaml:"tags"`        // Suggested tags for the short
-	ShortTitle  string `yaml:"shortTitle"`  // Short title for the video clip
+	Title       string          `yaml:"title"`             // Title/description of the short
+	StartTime   string          `yaml:"startTime"`         // Start timestamp in HH:MM:SS format
+	EndTime     string          `yaml:"endTime"`           // End timestamp in HH:MM:SS format
+	Description string          `yaml:"description"`       // Additional description/context
+	Tags        string          `yaml:"tags"`              // Suggested tags for the short
+	ShortTitle  string          `yaml:"shortTitle"`        // Short title for the video clip
+	Excerpt     string          `yaml:"excerpt,omitempty"` // Transcript text the clip was cut from
+	Cues        *utils.CueRange `yaml:"cues,omitempty"`    // SRT cues of the excerpt
 }
 
 // ShortsOutput defines the structure of the shorts YAML output
 type ShortsOutput struct {
-	SourceVideo string      `yaml:"sourceVideo"` // Original video file (will be replaced at runtime)
-	Shorts      []ShortClip `yaml:"shorts"`      // List of short clips
+	SchemaVersion int                    `yaml:"schemaVersion,omitempty"` // Layout version of the file (utils.ShortsSchemaVersion)
+	SourceVideo   string                 `yaml:"sourceVideo"`             // Original video file (will be replaced at runtime)
+	Episode       map[string]interface{} `yaml:"episode,omitempty"`       // Episode details the suggestions were written with
+	Shorts        []ShortClip            `yaml:"shorts"`                  // List of short clips
 }
 
 // PromptData represents the structure of a YAML prompt template
@@ -79,6 +106,11 @@ func (m *Module) Name() string {
 	return "suggest_shorts"
 }
 
+// ParamsTemplate returns the module's parameter struct, used to validate and document workflows
+func (m *Module) ParamsTemplate() interface{} {
+	return Params{}
+}
+
 // Validate checks if the parameters are valid
 func (m *Module) Validate(params map[string]interface{}) error {
 	var p Params
@@ -108,11 +140,53 @@ func (m *Module) Validate(params map[string]interface{}) error {
 		}
 	}
 
+	if p.SubtitleFile != "" {
+		if err := utils.ValidateInputPath(p.SubtitleFile, p.Output, ""); err != nil {
+			return err
+		}
+	}
+
+	// Check if the title history file exists
+	if p.TitleHistoryFile != "" {
+		if _, err := os.Stat(p.TitleHistoryFile); os.IsNotExist(err) {
+			return fmt.Errorf("title history file %s does not exist", p.TitleHistoryFile)
+		}
+	}
+
+	if err := utils.ValidateInputSelection(p.InputSelection); err != nil {
+		return err
+	}
+	if err := utils.NewFileFilter(p.Include, p.Exclude, []string{"*"}).Validate(); err != nil {
+		return err
+	}
+
+	if p.ValidationAttempts < 0 {
+		return fmt.Errorf("validationAttempts must not be negative")
+	}
+
 	// Validate duration parameters
 	if p.MinDuration > 0 && p.MaxDuration > 0 && p.MinDuration > p.MaxDuration {
 		return fmt.Errorf("minDuration (%d) cannot be greater than maxDuration (%d)", p.MinDuration, p.MaxDuration)
 	}
 
+	if _, err := utils.ResolveEpisodeMetadata(p.Metadata, p.MetadataFile); err != nil {
+		return err
+	}
+
+	if _, err := utils.ParseTimeRange(p.StartTime, p.EndTime); err != nil {
+		return err
+	}
+
+	switch p.TranscriptMode {
+	case "", TranscriptInline, TranscriptChunk, TranscriptAuto:
+	case TranscriptFile:
+		if provider, _ := chatgpt.SplitModel(p.Model); p.Model != "" && provider != chatgpt.ProviderOpenAI {
+			return fmt.Errorf("transcriptMode file requires an OpenAI model, got %s", p.Model)
+		}
+	default:
+		return fmt.Errorf("invalid transcriptMode %q: must be inline, file, chunked or auto", p.TranscriptMode)
+	}
+
 	return nil
 }
 
@@ -163,24 +237,54 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 	if p.OutputFileName == "" {
 		p.OutputFileName = "shorts_suggestions"
 	}
+	if p.FewShotCount == 0 {
+		p.FewShotCount = 5
+	}
+	if p.TranscriptMode == "" {
+		p.TranscriptMode = TranscriptAuto
+	}
+	if p.ContextTokens == 0 {
+		p.ContextTokens = 100000
+	}
+	if p.MaxShorts == 0 {
+		p.MaxShorts = 10
+	}
 
 	// Resolve the input path if it contains ${output}
 	resolvedInput := utils.ResolveOutputPath(p.Input, p.Output)
 
 	// Handle input path resolution
-	inputPath, err := getInputFilePath(resolvedInput, p.FilePattern)
+	filter := utils.NewFileFilter(p.Include, p.Exclude, []string{p.FilePattern})
+	inputPath, err := utils.SelectInputFile(resolvedInput, filter, p.InputSelection)
 	if err != nil {
 		return modules.ModuleResult{}, err
 	}
 
 	// Read transcript
-	transcript, err := os.ReadFile(inputPath)
+	transcriptData, err := os.ReadFile(inputPath)
 	if err != nil {
 		return modules.ModuleResult{}, fmt.Errorf("failed to read transcript file: %w", err)
 	}
 
+	// Episode details from the step win over those carried in the transcript's front matter
+	frontMatter, transcript := utils.SplitEpisodeFrontMatter(string(transcriptData))
+	metadata, err := utils.ResolveEpisodeMetadata(p.Metadata, p.MetadataFile)
+	if err != nil {
+		return modules.ModuleResult{}, err
+	}
+	metadata = utils.MergeEpisodeMetadata(frontMatter, metadata)
+
+	// Only the part of the transcript within the time range reaches the model
+	timeRange, err := utils.ParseTimeRange(p.StartTime, p.EndTime)
+	if err != nil {
+		return modules.ModuleResult{}, err
+	}
+	if !timeRange.IsZero() {
+		transcript = utils.ShiftTranscript(transcript, 0, timeRange)
+	}
+
 	// Create output directory if it doesn't exist
-	if err := os.MkdirAll(p.Output, 0755); err != nil {
+	if err := utils.EnsureDir(p.Output); err != nil {
 		return modules.ModuleResult{}, fmt.Errorf("failed to create output directory: %w", err)
 	}
 
@@ -189,8 +293,8 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 
 	// Check if API key is set, if not, save a placeholder file
 	if !chatgpt.IsAPIKeySet() {
-		utils.LogWarning("No API key set - saving placeholder file to %s", outputFilePath)
-		if err := m.writePlaceholderFile(outputFilePath); err != nil {
+		utils.LogWarningContext(ctx, "No API key set - saving placeholder file to %s", outputFilePath)
+		if err := m.writePlaceholderFile(ctx, outputFilePath, metadata); err != nil {
 			return modules.ModuleResult{}, err
 		}
 		return modules.ModuleResult{
@@ -204,57 +308,108 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 	}
 
 	// Get prompt template
-	promptTemplate, err := m.getPromptTemplate(p.PromptFilePath)
+	promptTemplate, err := m.getPromptTemplate(ctx, p.PromptFilePath)
 	if err != nil {
 		return modules.ModuleResult{}, err
 	}
 
-	// Create prompt with transcript
-	prompt := fmt.Sprintf(promptTemplate,
-		p.MinDuration,
-		p.MaxDuration,
-		string(transcript))
-
-	// Create API client timeout context
-	apiCtx, cancel := context.WithTimeout(ctx, time.Duration(p.RequestTimeoutMs)*time.Millisecond)
-	defer cancel()
-
 	// Initialize ChatGPT service
 	chatGPT, err := m.getChatGPTService(ctx)
 	if err != nil {
 		return modules.ModuleResult{}, fmt.Errorf("failed to initialize ChatGPT service: %w", err)
 	}
 
-	// Call OpenAI API
-	utils.LogInfo("Generating shorts suggestions using %s model...", p.Model)
-	messages := []chatgpt.ChatMessage{
-		{
-			Role:    "user",
-			Content: prompt,
-		},
+	// Transcripts too long for the context window are uploaded, so the model sees all of it
+	transcriptText := transcript
+	mode := m.transcriptMode(ctx, p, chatGPT, len(promptTemplate)+len(transcript))
+	if mode == TranscriptFile {
+		files := chatGPT.(chatgpt.FileCompleter)
+		name := strings.TrimSuffix(filepath.Base(inputPath), filepath.Ext(inputPath)) + ".txt"
+		fileID, err := files.UploadFile(ctx, name, []byte(transcript))
+		if err != nil {
+			return modules.ModuleResult{}, err
+		}
+		defer func() {
+			if err := files.DeleteFile(context.WithoutCancel(ctx), fileID); err != nil {
+				utils.LogWarningContext(ctx, "Failed to delete uploaded transcript: %v", err)
+			}
+		}()
+		chatGPT = chatgpt.FileChat{Service: files, FileIDs: []string{fileID}}
+		transcriptText = fmt.Sprintf("The complete transcript is in the attached file %s. Read it to the end.", name)
+		utils.LogInfoContext(ctx, "Transcript uploaded as %s", name)
 	}
 
-	response, err := chatGPT.GetContent(apiCtx, messages, chatgpt.CompletionOptions{
-		Model:            p.Model,
-		Temperature:      p.Temperature,
-		MaxTokens:        p.MaxTokens,
-		RequestTimeoutMS: p.RequestTimeoutMs,
-	})
+	// Include the channel's best past titles as few-shot examples
+	fewShot, err := utils.BuildFewShotPrompt(p.TitleHistoryFile, p.FewShotMetric, p.FewShotCount)
 	if err != nil {
-		return modules.ModuleResult{}, fmt.Errorf("API request failed: %w", err)
+		return modules.ModuleResult{}, fmt.Errorf("failed to load title history: %w", err)
+	}
+	prefix := ""
+	if fewShot != "" {
+		prefix = fewShot + "\n"
+	}
+	if details := utils.EpisodeMetadataPrompt(metadata); details != "" {
+		prefix = details + "\n" + prefix
+	}
+
+	var shorts []ShortClip
+	var model string
+	var chunked *chunkedResult
+	if mode == TranscriptChunk {
+		chunked, err = m.suggestChunked(ctx, chatGPT, p, promptTemplate, prefix, transcript)
+		if err != nil {
+			return modules.ModuleResult{}, err
+		}
+		shorts, model = chunked.Shorts, chunked.Model
+	} else {
+		// Create prompt with transcript
+		prompt := prefix + fmt.Sprintf(promptTemplate,
+			p.MinDuration,
+			p.MaxDuration,
+			transcriptText)
+
+		// Call OpenAI API
+		utils.LogInfoContext(ctx, "Generating shorts suggestions using %s model...", p.Model)
+		messages := []chatgpt.ChatMessage{
+			{
+				Role:    "user",
+				Content: prompt,
+			},
+		}
+
+		// Each attempt is bounded by RequestTimeoutMS; responses that are not valid shorts YAML are retried, or
+		// corrected in strict mode, before moving on to the next model
+		completion, err := chatgpt.CompleteWithFallback(ctx, chatGPT, messages, chatgpt.CompletionOptions{
+			Model:            p.Model,
+			Temperature:      p.Temperature,
+			MaxTokens:        p.MaxTokens,
+			RequestTimeoutMS: p.RequestTimeoutMs,
+		}, chatgpt.FallbackChain{Models: p.FallbackModels, ValidationAttempts: p.ValidationAttempts, Reprompt: p.Strict}, func(response string) error {
+			var err error
+			shorts, err = parseShortsResponse(ctx, response)
+			return err
+		})
+		if err != nil {
+			return modules.ModuleResult{}, fmt.Errorf("API request failed: %w", err)
+		}
+		model = completion.Model
 	}
 
-	// Parse response to get shorts suggestions
-	shorts, err := parseShortsResponse(response)
+	shorts = clipsInRange(ctx, shorts, timeRange)
+
+	// Each clip carries the transcript it was cut from, for review, captions and regeneration
+	cues, err := transcriptCues(ctx, transcript, p)
 	if err != nil {
-		return modules.ModuleResult{}, fmt.Errorf("failed to parse API response: %w\nResponse preview: %s",
-			err, response[:Min(len(response), 1000)])
+		return modules.ModuleResult{}, err
 	}
+	excerpts := attachExcerpts(shorts, cues)
 
 	// Create output
 	outputData := ShortsOutput{
-		SourceVideo: "${source_video}", // This will be replaced at runtime
-		Shorts:      shorts,
+		SchemaVersion: utils.ShortsSchemaVersion,
+		SourceVideo:   "${source_video}", // This will be replaced at runtime
+		Shorts:        shorts,
+		Episode:       metadata,
 	}
 
 	// Save to YAML file
@@ -264,11 +419,11 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 	}
 
 	// Write to file
-	if err := os.WriteFile(outputFilePath, yamlData, 0644); err != nil {
+	if err := utils.AtomicWriteFile(outputFilePath, yamlData, 0644); err != nil {
 		return modules.ModuleResult{}, fmt.Errorf("failed to write output file: %w", err)
 	}
 
-	utils.LogSuccess("Shorts suggestions saved to %s", outputFilePath)
+	utils.LogSuccessContext(ctx, "Shorts suggestions saved to %s", outputFilePath)
 
 	// Create result with output file information
 	result := modules.ModuleResult{
@@ -276,15 +431,121 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 			"suggestions": outputFilePath,
 		},
 		Metadata: map[string]interface{}{
-			"inputFile":    inputPath,
-			"outputFormat": "yaml",
-			"numShorts":    len(shorts),
+			"inputFile":      inputPath,
+			"outputFormat":   "yaml",
+			"numShorts":      len(shorts),
+			"model":          model,
+			"transcriptMode": mode,
+			"excerpts":       excerpts,
 		},
+		Stats: modules.Stats{Items: len(shorts)},
+	}
+	if chunked != nil {
+		result.Metadata["transcriptParts"] = chunked.Parts
+		result.Metadata["candidates"] = chunked.Candidates
+		result.Metadata["coverage"] = chunked.Coverage
 	}
 
 	return result, nil
 }
 
+// transcriptCues returns the cues of the subtitle file, or of the transcript when it is in SRT
+// form. A plain transcript without a subtitle file has no cues.
+func transcriptCues(ctx context.Context, transcript string, p Params) ([]utils.SubtitleCue, error) {
+	if p.SubtitleFile == "" {
+		cues, err := utils.ParseSRT(transcript)
+		if err != nil {
+			utils.LogDebugContext(ctx, "Transcript has no SRT cues, clips get no excerpt: %v", err)
+			return nil, nil
+		}
+		return cues, nil
+	}
+
+	data, err := os.ReadFile(utils.ResolveOutputPath(p.SubtitleFile, p.Output))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read subtitle file: %w", err)
+	}
+	cues, err := utils.ParseSRT(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse subtitle file %s: %w", p.SubtitleFile, err)
+	}
+	return cues, nil
+}
+
+// clipsInRange drops the clips that do not lie within the time range. Clips whose times cannot
+// be read are kept for the review to catch.
+func clipsInRange(ctx context.Context, shorts []ShortClip, r utils.TimeRange) []ShortClip {
+	if r.IsZero() {
+		return shorts
+	}
+	kept := shorts[:0]
+	for _, clip := range shorts {
+		start, err1 := utils.ParseTimestamp(clip.StartTime)
+		end, err2 := utils.ParseTimestamp(clip.EndTime)
+		if err1 == nil && err2 == nil && !r.Contains(start, end) {
+			utils.LogWarningContext(ctx, "Dropping clip %q (%s-%s): outside %s", clip.Title, clip.StartTime, clip.EndTime, r)
+			continue
+		}
+		kept = append(kept, clip)
+	}
+	return kept
+}
+
+// attachExcerpts sets the excerpt and cue range of each clip from the cues its times overlap and
+// returns the number of clips that got one
+func attachExcerpts(shorts []ShortClip, cues []utils.SubtitleCue) int {
+	attached := 0
+	for i := range shorts {
+		shorts[i].Excerpt, shorts[i].Cues = "", nil
+		start, err := utils.ParseTimestamp(shorts[i].StartTime)
+		if err != nil {
+			continue
+		}
+		end, err := utils.ParseTimestamp(shorts[i].EndTime)
+		if err != nil {
+			continue
+		}
+		excerpt, cueRange, ok := utils.CueExcerpt(cues, start, end)
+		if !ok {
+			continue
+		}
+		shorts[i].Excerpt = excerpt
+		shorts[i].Cues = &cueRange
+		attached++
+	}
+	return attached
+}
+
+// transcriptMode decides whether the transcript is sent inline, uploaded or in parts. Auto mode
+// uploads transcripts whose prompt would exceed ContextTokens when the model and service can read
+// files, and splits them into parts otherwise.
+func (m *Module) transcriptMode(ctx context.Context, p Params, service chatgpt.ChatGPTServicer, promptChars int) string {
+	_, canUpload := service.(chatgpt.FileCompleter)
+	provider, _ := chatgpt.SplitModel(p.Model)
+	canUpload = canUpload && provider == chatgpt.ProviderOpenAI
+
+	switch p.TranscriptMode {
+	case TranscriptFile:
+		if !canUpload {
+			utils.LogWarningContext(ctx, "Cannot upload the transcript for model %s; sending it inline", p.Model)
+			return TranscriptInline
+		}
+		return TranscriptFile
+	case TranscriptAuto:
+		// Roughly four characters per token, as in chatgpt.EstimateTokens
+		if promptChars/4 <= p.ContextTokens {
+			return TranscriptInline
+		}
+		if !canUpload {
+			return TranscriptChunk
+		}
+		return TranscriptFile
+	case TranscriptChunk:
+		return TranscriptChunk
+	}
+	return TranscriptInline
+}
+
 // GetIO returns the module's input/output specification
 func (m *Module) GetIO() modules.ModuleIO {
 	return modules.ModuleIO{
@@ -327,6 +588,22 @@ func (m *Module) GetIO() modules.ModuleIO {
 				Description: "Maximum duration of shorts in seconds",
 				Type:        string(modules.InputTypeData),
 			},
+			{
+				Name:        "titleHistoryFile",
+				Description: "CSV/JSON of past titles with performance metrics used as few-shot examples",
+				Patterns:    []string{".csv", ".json"},
+				Type:        string(modules.InputTypeFile),
+			},
+			{
+				Name:        "startTime",
+				Description: "Only suggest clips from this time of the source video on",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "endTime",
+				Description: "Only suggest clips up to this time of the source video",
+				Type:        string(modules.InputTypeData),
+			},
 		},
 		ProducedOutputs: []modules.ModuleOutput{
 			{
@@ -340,9 +617,10 @@ func (m *Module) GetIO() modules.ModuleIO {
 }
 
 // writePlaceholderFile writes a placeholder YAML file when no API key is available
-func (m *Module) writePlaceholderFile(outputPath string) error {
+func (m *Module) writePlaceholderFile(ctx context.Context, outputPath string, metadata map[string]interface{}) error {
 	placeholderOutput := ShortsOutput{
 		SourceVideo: "${source_video}",
+		Episode:     metadata,
 		Shorts: []ShortClip{
 			{
 				Title:       "API Key Required - Please Configure",
@@ -365,29 +643,29 @@ func (m *Module) writePlaceholderFile(outputPath string) error {
 	cleanYAML := strings.ReplaceAll(string(yamlData), "\r", "")
 	cleanYAML = strings.TrimSpace(cleanYAML)
 
-	if err := os.WriteFile(outputPath, []byte(cleanYAML+"\n"), 0644); err != nil {
+	if err := utils.AtomicWriteFile(outputPath, []byte(cleanYAML+"\n"), 0644); err != nil {
 		return fmt.Errorf("failed to write placeholder file: %w", err)
 	}
 
-	utils.LogSuccess("Placeholder shorts suggestions saved to %s", outputPath)
+	utils.LogSuccessContext(ctx, "Placeholder shorts suggestions saved to %s", outputPath)
 	return nil
 }
 
 // getPromptTemplate returns the prompt template from file or default
-func (m *Module) getPromptTemplate(promptFilePath string) (string, error) {
+func (m *Module) getPromptTemplate(ctx context.Context, promptFilePath string) (string, error) {
 	if promptFilePath != "" {
 		promptData, err := loadPromptTemplate(promptFilePath)
 		if err != nil {
 			return "", fmt.Errorf("failed to load prompt template: %w", err)
 		}
-		utils.LogInfo("Using prompt template: %s", promptFilePath)
+		utils.LogInfoContext(ctx, "Using prompt template: %s", promptFilePath)
 		return promptData.Prompt, nil
 	}
 
-	utils.LogInfo("Using default prompt template")
+	utils.LogInfoContext(ctx, "Using default prompt template")
 	return `## CRITICAL REQUIREMENTS:
 1. COMPLETE COVERAGE: Analyze the ENTIRE transcript to the END. NEVER STOP early.
-2. SPANISH OUTPUT: Generate ALL content (titles, descriptions, tags, short_title) in SPANISH for Spanish-speaking audiences.
+2. SPANISH OUTPUT: Generate ALL content (titles, descriptions, tags, shortTitle) in SPANISH for Spanish-speaking audiences.
 3. TOPIC IDENTIFICATION: Identify all main topics/themes discussed in the video.
 4. MINIMUM CLIPS PER TOPIC: Create AT LEAST 3 shorts for EACH identified topic.
 5. DISTRIBUTION: Ensure clips are distributed evenly across beginning, middle, and end.
@@ -403,7 +681,7 @@ shorts:
     endTime: "hh:mm:ss"
     description: "Descripción detallada que explica por qué este momento es interesante"
     tags: "Hashtag1, Hashtag2, Hashtag3"
-    short_title: "¿Pregunta o descripción corta que se responde en el video?"
+    shortTitle: "¿Pregunta o descripción corta que se responde en el video?"
 '''
 
 ## YAML SAFETY GUIDELINES (VERY IMPORTANT):
@@ -412,7 +690,7 @@ shorts:
 - Avoid line breaks within values
 - DO NOT INCLUDE COMMENTS like "# Maximum 40 characters" in your final response
 - VERIFY that your YAML is valid before submitting
-- short_title: must be no more than 40 characters, try to be creative and interesting
+- shortTitle: must be no more than 40 characters, try to be creative and interesting
 - title: must be maximum 100 characters including high impact hashtags between the name using #hashtags format
 
 ## SELECTION CRITERIA (at least TWO):
@@ -442,33 +720,6 @@ Transcript:
 %s`, nil
 }
 
-// getInputFilePath resolves the input file path based on the input directory and pattern
-func getInputFilePath(inputPath, filePattern string) (string, error) {
-	fileInfo, err := os.Stat(inputPath)
-	if err != nil {
-		return "", fmt.Errorf("input path does not exist: %w", err)
-	}
-
-	if !fileInfo.IsDir() {
-		return inputPath, nil
-	}
-
-	files, err := filepath.Glob(filepath.Join(inputPath, filePattern))
-	if err != nil {
-		return "", fmt.Errorf("error matching files with pattern: %w", err)
-	}
-
-	if len(files) == 0 {
-		return "", fmt.Errorf("no files matching pattern %s found in %s", filePattern, inputPath)
-	}
-
-	if len(files) > 1 {
-		utils.LogWarning("Multiple files match pattern %s, using most recent one", filePattern)
-	}
-
-	return files[0], nil
-}
-
 // loadPromptTemplate loads a prompt template from a YAML file
 func loadPromptTemplate(filePath string) (*PromptData, error) {
 	data, err := os.ReadFile(filePath)
@@ -484,10 +735,11 @@ func loadPromptTemplate(filePath string) (*PromptData, error) {
 	return &promptData, nil
 }
 
-// validateTimestamp checks if a string is a valid timestamp in HH:MM:SS format
+// validateTimestamp checks if a string is a valid timestamp in HH:MM:SS format. Hours may have
+// more than two digits so clips of long livestream archives validate.
 func validateTimestamp(timestamp string) error {
 	// Check basic format using regex
-	matched, err := regexMatchString(`^\d{2}:\d{2}:\d{2}$`, timestamp)
+	matched, err := regexMatchString(`^\d{2,}:\d{2}:\d{2}$`, timestamp)
 	if err != nil {
 		return fmt.Errorf("failed to validate timestamp format: %w", err)
 	}
@@ -501,11 +753,6 @@ func validateTimestamp(timestamp string) error {
 		return fmt.Errorf("invalid timestamp format: %s (expected HH:MM:SS)", timestamp)
 	}
 
-	hours, err := strconv.Atoi(parts[0])
-	if err != nil || hours < 0 || hours > 23 {
-		return fmt.Errorf("invalid hours in timestamp: %s (must be 00-23)", timestamp)
-	}
-
 	minutes, err := strconv.Atoi(parts[1])
 	if err != nil || minutes < 0 || minutes > 59 {
 		return fmt.Errorf("invalid minutes in timestamp: %s (must be 00-59)", timestamp)
@@ -547,42 +794,16 @@ func validateShortClip(clip *ShortClip) error {
 	}
 
 	// Validate that end time is after start time
-	startParts := strings.Split(clip.StartTime, ":")
-	endParts := strings.Split(clip.EndTime, ":")
-
-	// Convert start time components to seconds
-	startHours, err := strconv.Atoi(startParts[0])
-	if err != nil {
-		return fmt.Errorf("invalid hours in start time: %w", err)
-	}
-	startMinutes, err := strconv.Atoi(startParts[1])
-	if err != nil {
-		return fmt.Errorf("invalid minutes in start time: %w", err)
-	}
-	startSeconds, err := strconv.Atoi(startParts[2])
+	start, err := utils.ParseTimestamp(clip.StartTime)
 	if err != nil {
-		return fmt.Errorf("invalid seconds in start time: %w", err)
-	}
-
-	// Convert end time components to seconds
-	endHours, err := strconv.Atoi(endParts[0])
-	if err != nil {
-		return fmt.Errorf("invalid hours in end time: %w", err)
-	}
-	endMinutes, err := strconv.Atoi(endParts[1])
-	if err != nil {
-		return fmt.Errorf("invalid minutes in end time: %w", err)
+		return fmt.Errorf("invalid start time: %w", err)
 	}
-	endSeconds, err := strconv.Atoi(endParts[2])
+	end, err := utils.ParseTimestamp(clip.EndTime)
 	if err != nil {
-		return fmt.Errorf("invalid seconds in end time: %w", err)
+		return fmt.Errorf("invalid end time: %w", err)
 	}
 
-	// Calculate total seconds
-	startTotalSeconds := (startHours * 3600) + (startMinutes * 60) + startSeconds
-	endTotalSeconds := (endHours * 3600) + (endMinutes * 60) + endSeconds
-
-	if endTotalSeconds <= startTotalSeconds {
+	if end <= start {
 		return fmt.Errorf("end time (%s) must be after start time (%s)", clip.EndTime, clip.StartTime)
 	}
 
@@ -590,7 +811,7 @@ func validateShortClip(clip *ShortClip) error {
 }
 
 // parseShortsResponse parses the ChatGPT response to extract shorts data
-func parseShortsResponse(content string) ([]ShortClip, error) {
+func parseShortsResponse(ctx context.Context, content string) ([]ShortClip, error) {
 	// Try to identify and extract YAML content - look for sourceVideo and shorts sections
 	if strings.Contains(content, "sourceVideo:") && strings.Contains(content, "shorts:") {
 		// Try to clean the content to get only the YAML portion
@@ -607,7 +828,7 @@ func parseShortsResponse(content string) ([]ShortClip, error) {
 
 			// Try to parse the yaml content directly
 			var shortsData ShortsOutput
-			err := yaml.Unmarshal([]byte(yamlContent), &shortsData)
+			err := utils.DecodeShorts([]byte(yamlContent), &shortsData)
 			if err == nil && len(shortsData.Shorts) > 0 {
 				// Validate each short clip
 				for _, clip := range shortsData.Shorts {
@@ -639,7 +860,7 @@ func parseShortsResponse(content string) ([]ShortClip, error) {
 						cleanYaml = "sourceVideo: ${source_video}\nshorts:\n" + cleanYaml
 					}
 
-					err := yaml.Unmarshal([]byte(cleanYaml), &shortsData)
+					err := utils.DecodeShorts([]byte(cleanYaml), &shortsData)
 					if err == nil && len(shortsData.Shorts) > 0 {
 						// Validate each short clip
 						for _, clip := range shortsData.Shorts {
@@ -651,7 +872,7 @@ func parseShortsResponse(content string) ([]ShortClip, error) {
 					}
 
 					// If that still fails, log the error and the cleaned YAML for debugging
-					utils.LogDebug("Failed to parse cleaned YAML: %v\nCleaned YAML:\n%s", err, cleanYaml)
+					utils.LogDebugContext(ctx, "Failed to parse cleaned YAML: %v\nCleaned YAML:\n%s", err, cleanYaml)
 				}
 			}
 
@@ -675,7 +896,8 @@ func parseShortsResponse(content string) ([]ShortClip, error) {
 					strings.HasPrefix(trimmed, "endTime:") ||
 					strings.HasPrefix(trimmed, "description:") ||
 					strings.HasPrefix(trimmed, "tags:") ||
-					strings.HasPrefix(trimmed, "shortTitle:")) {
+					strings.HasPrefix(trimmed, "shortTitle:") ||
+					strings.HasPrefix(trimmed, "short_title:")) {
 					// This is a property of a shorts item
 					cleanedLines = append(cleanedLines, "    "+trimmed)
 				}
@@ -684,7 +906,7 @@ func parseShortsResponse(content string) ([]ShortClip, error) {
 			if len(cleanedLines) > 0 {
 				fixedYaml := strings.Join(cleanedLines, "\n")
 				var shortsData ShortsOutput
-				err := yaml.Unmarshal([]byte(fixedYaml), &shortsData)
+				err := utils.DecodeShorts([]byte(fixedYaml), &shortsData)
 				if err == nil && len(shortsData.Shorts) > 0 {
 					// Validate each short clip
 					for _, clip := range shortsData.Shorts {
@@ -696,7 +918,7 @@ func parseShortsResponse(content string) ([]ShortClip, error) {
 				}
 
 				// Log the attempt for debugging
-				utils.LogDebug("Reconstructed YAML parsing failed: %v\nReconstructed YAML:\n%s", err, fixedYaml)
+				utils.LogDebugContext(ctx, "Reconstructed YAML parsing failed: %v\nReconstructed YAML:\n%s", err, fixedYaml)
 			}
 		}
 	}
@@ -799,7 +1021,7 @@ func parseShortsResponse(content string) ([]ShortClip, error) {
 			line := strings.TrimSpace(lines[i])
 
 			// Look for timestamp patterns like 00:00:00 in the line
-			timestampRegex := regexp.MustCompile(`(\d{2}:\d{2}:\d{2})`)
+			timestampRegex := regexp.MustCompile(`(\d{2,}:\d{2}:\d{2})`)
 			matches := timestampRegex.FindAllString(line, -1)
 
 			if len(matches) >= 2 {
@@ -7,12 +7,16 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	modules "github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
 	services "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/chatgpt"
 	mocks "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/chatgpt/mocks"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/snapshot"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gopkg.in/yaml.v3"
 )
 
 // Mock response for successful shorts generation
@@ -118,6 +122,13 @@ func TestSuggestShortsModule(t *testing.T) {
 		}
 	}
 
+	// Create a title history file for few-shot examples
+	titleHistoryPath := filepath.Join(tempDir, "title_history.csv")
+	titleHistory := "title,views,likes\nLow performer,100,1\nBest performer,9000,300\nSecond best,5000,200\n"
+	if err := os.WriteFile(titleHistoryPath, []byte(titleHistory), 0644); err != nil {
+		t.Fatal(err)
+	}
+
 	tests := []struct {
 		name           string
 		params         map[string]interface{}
@@ -154,6 +165,72 @@ func TestSuggestShortsModule(t *testing.T) {
 			wantErr:        false,
 			expectedOutput: filepath.Join(outputDir, "shorts_suggestions.yaml"),
 		},
+		{
+			name: "few-shot examples from title history",
+			params: map[string]interface{}{
+				"input":            filepath.Join(inputDir, "transcript_corrected.txt"),
+				"output":           outputDir,
+				"minDuration":      15,
+				"maxDuration":      60,
+				"titleHistoryFile": titleHistoryPath,
+				"fewShotCount":     2,
+			},
+			setupMock: func(m *mocks.MockChatGPTServicer) {
+				m.EXPECT().GetContent(
+					mock.Anything,
+					mock.MatchedBy(func(messages []services.ChatMessage) bool {
+						content := messages[0].Content
+						return strings.Contains(content, "CHANNEL STYLE EXAMPLES") &&
+							strings.Contains(content, "1. Best performer (views: 9000)") &&
+							strings.Contains(content, "2. Second best") &&
+							!strings.Contains(content, "Low performer") &&
+							verifyPromptContent(content, 15, 60, "This is a test transcript content.")
+					}),
+					mock.Anything,
+				).Return(mockSuccessResponse, nil)
+			},
+			apiKeySet:      true,
+			wantErr:        false,
+			expectedOutput: filepath.Join(outputDir, "shorts_suggestions.yaml"),
+		},
+		{
+			name: "strict mode asks the model to correct invalid YAML",
+			params: map[string]interface{}{
+				"input":  filepath.Join(inputDir, "transcript_corrected.txt"),
+				"output": outputDir,
+				"strict": true,
+			},
+			setupMock: func(m *mocks.MockChatGPTServicer) {
+				m.EXPECT().GetContent(
+					mock.Anything,
+					mock.MatchedBy(func(messages []services.ChatMessage) bool { return len(messages) == 1 }),
+					mock.Anything,
+				).Return("Sorry, I cannot find any good clips.", nil).Once()
+				m.EXPECT().GetContent(
+					mock.Anything,
+					mock.MatchedBy(func(messages []services.ChatMessage) bool {
+						return len(messages) == 3 &&
+							messages[1].Content == "Sorry, I cannot find any good clips." &&
+							strings.Contains(messages[2].Content, "could not be used")
+					}),
+					mock.Anything,
+				).Return(mockSuccessResponse, nil).Once()
+			},
+			apiKeySet:      true,
+			wantErr:        false,
+			expectedOutput: filepath.Join(outputDir, "shorts_suggestions.yaml"),
+		},
+		{
+			name: "invalid title history file",
+			params: map[string]interface{}{
+				"input":            filepath.Join(inputDir, "transcript_corrected.txt"),
+				"output":           outputDir,
+				"titleHistoryFile": filepath.Join(inputDir, "other.txt"),
+			},
+			setupMock: func(m *mocks.MockChatGPTServicer) {},
+			apiKeySet: true,
+			wantErr:   true,
+		},
 		{
 			name: "no api key set",
 			params: map[string]interface{}{
@@ -269,6 +346,92 @@ func TestSuggestShortsModule(t *testing.T) {
 	}
 }
 
+// fileService answers like the OpenAI Responses API, recording the transcripts uploaded to it
+type fileService struct {
+	*mocks.MockChatGPTServicer
+	uploads  map[string]string
+	deleted  []string
+	attached []string
+	prompt   string
+}
+
+func (f *fileService) UploadFile(ctx context.Context, name string, data []byte) (string, error) {
+	f.uploads[name] = string(data)
+	return "file-1", nil
+}
+
+func (f *fileService) DeleteFile(ctx context.Context, fileID string) error {
+	f.deleted = append(f.deleted, fileID)
+	return nil
+}
+
+func (f *fileService) CompleteWithFiles(ctx context.Context, messages []services.ChatMessage, fileIDs []string, opts services.CompletionOptions) (*services.ChatResponse, error) {
+	f.attached = fileIDs
+	f.prompt = messages[len(messages)-1].Content
+	resp := &services.ChatResponse{}
+	resp.Choices = make([]struct {
+		Index        int                  `json:"index"`
+		Message      services.ChatMessage `json:"message"`
+		FinishReason string               `json:"finish_reason"`
+	}, 1)
+	resp.Choices[0].Message.Content = mockSuccessResponse
+	return resp, nil
+}
+
+func TestTranscriptFileMode(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "test-api-key")
+	dir := t.TempDir()
+	input := filepath.Join(dir, "episode_corrected.txt")
+	transcript := strings.Repeat("a long transcript ", 100)
+	if err := os.WriteFile(input, []byte(transcript), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		params   map[string]interface{}
+		uploaded bool
+	}{
+		{"auto mode keeps short transcripts inline", map[string]interface{}{}, false},
+		{"auto mode uploads transcripts over contextTokens", map[string]interface{}{"contextTokens": 100}, true},
+		{"file mode", map[string]interface{}{"transcriptMode": "file"}, true},
+		{"file mode falls back to inline for other providers", map[string]interface{}{"transcriptMode": "file", "model": "groq:llama-3.3-70b-versatile"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &fileService{MockChatGPTServicer: mocks.NewMockChatGPTServicer(t), uploads: map[string]string{}}
+			if !tt.uploaded {
+				service.EXPECT().GetContent(mock.Anything, mock.MatchedBy(func(messages []services.ChatMessage) bool {
+					return strings.Contains(messages[0].Content, transcript)
+				}), mock.Anything).Return(mockSuccessResponse, nil).Once()
+			}
+
+			params := map[string]interface{}{"input": input, "output": filepath.Join(dir, "output")}
+			for k, v := range tt.params {
+				params[k] = v
+			}
+			ctx := context.WithValue(context.Background(), ChatGPTServiceKey, service)
+			result, err := New().Execute(ctx, params)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, 2, result.Stats.Items)
+
+			if !tt.uploaded {
+				assert.Empty(t, service.uploads)
+				assert.Equal(t, "inline", result.Metadata["transcriptMode"])
+				return
+			}
+			assert.Equal(t, map[string]string{"episode_corrected.txt": transcript}, service.uploads)
+			assert.Equal(t, []string{"file-1"}, service.attached)
+			assert.Equal(t, []string{"file-1"}, service.deleted, "the upload is removed afterwards")
+			assert.Contains(t, service.prompt, "attached file episode_corrected.txt")
+			assert.NotContains(t, service.prompt, transcript)
+			assert.Equal(t, "file", result.Metadata["transcriptMode"])
+		})
+	}
+}
+
 func TestValidate(t *testing.T) {
 	// Create temporary directories for testing
 	tempDir, err := os.MkdirTemp("", "shorts_validate_test")
@@ -347,6 +510,25 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "unknown transcript mode",
+			params: map[string]interface{}{
+				"input":          testTranscriptPath,
+				"output":         outputDir,
+				"transcriptMode": "stream",
+			},
+			wantErr: true,
+		},
+		{
+			name: "file mode with another provider",
+			params: map[string]interface{}{
+				"input":          testTranscriptPath,
+				"output":         outputDir,
+				"transcriptMode": "file",
+				"model":          "groq:llama-3.3-70b-versatile",
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -555,7 +737,7 @@ endTime: "00:01:00"`,
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			shorts, err := parseShortsResponse(tt.input)
+			shorts, err := parseShortsResponse(context.Background(), tt.input)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -840,7 +1022,7 @@ role: [broken yaml`
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := module.getPromptTemplate(tt.promptFilePath)
+			result, err := module.getPromptTemplate(context.Background(), tt.promptFilePath)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -1047,10 +1229,14 @@ func TestValidateTimestamp(t *testing.T) {
 			errMsg:    "invalid timestamp format: 12:34 (expected HH:MM:SS)",
 		},
 		{
-			name:      "invalid hours - too high",
+			name:      "valid timestamp past a day",
 			timestamp: "24:00:00",
-			wantErr:   true,
-			errMsg:    "invalid hours in timestamp: 24:00:00 (must be 00-23)",
+			wantErr:   false,
+		},
+		{
+			name:      "valid timestamp past 99 hours",
+			timestamp: "100:15:30",
+			wantErr:   false,
 		},
 		{
 			name:      "invalid hours - negative",
@@ -1220,14 +1406,14 @@ func TestValidateShortClip(t *testing.T) {
 			errMsg:  "invalid end time: invalid timestamp format: invalid (expected HH:MM:SS)",
 		},
 		{
-			name: "invalid start time hours",
+			name: "start time past a day after end time",
 			clip: &ShortClip{
 				Title:     "Test Title",
 				StartTime: "24:00:00",
 				EndTime:   "00:01:00",
 			},
 			wantErr: true,
-			errMsg:  "invalid start time: invalid hours in timestamp: 24:00:00 (must be 00-23)",
+			errMsg:  "end time (00:01:00) must be after start time (24:00:00)",
 		},
 		{
 			name: "invalid start time minutes",
@@ -1250,14 +1436,13 @@ func TestValidateShortClip(t *testing.T) {
 			errMsg:  "invalid start time: invalid seconds in timestamp: 00:00:60 (must be 00-59)",
 		},
 		{
-			name: "invalid end time hours",
+			name: "valid clip of a long livestream",
 			clip: &ShortClip{
 				Title:     "Test Title",
-				StartTime: "00:00:00",
-				EndTime:   "24:00:00",
+				StartTime: "99:59:30",
+				EndTime:   "100:00:30",
 			},
-			wantErr: true,
-			errMsg:  "invalid end time: invalid hours in timestamp: 24:00:00 (must be 00-23)",
+			wantErr: false,
 		},
 		{
 			name: "invalid end time minutes",
@@ -1372,3 +1557,366 @@ func TestValidateShortClip(t *testing.T) {
 		})
 	}
 }
+
+func TestRegenerateClip(t *testing.T) {
+	const shortsFile = `sourceVideo: video.mp4
+shorts:
+    - title: "First Short Title"
+      startTime: "00:00:00"
+      endTime: "00:01:00"
+      description: "First short description"
+      tags: "tag1, tag2"
+      shortTitle: "Short 1"
+    - title: "Second Short Title"
+      startTime: "00:02:00"
+      endTime: "00:03:00"
+      description: "Second short description"
+      tags: "tag3, tag4"
+      shortTitle: "Short 2"
+`
+
+	tests := []struct {
+		name       string
+		opts       RegenOptions
+		response   string
+		callsModel bool
+		wantErr    string
+		verify     func(t *testing.T, data ShortsOutput)
+	}{
+		{
+			name:       "updates all fields of one clip",
+			opts:       RegenOptions{Clip: 2, Instruction: "make the title punchier"},
+			response:   "```yaml\ntitle: \"Punchy Title #wow\"\nshort_title: \"Wow!\"\ndescription: \"New description\"\ntags: \"#wow, #new\"\n```",
+			callsModel: true,
+			verify: func(t *testing.T, data ShortsOutput) {
+				assert.Equal(t, "First Short Title", data.Shorts[0].Title)
+				assert.Equal(t, "Short 1", data.Shorts[0].ShortTitle)
+				assert.Equal(t, "Punchy Title #wow", data.Shorts[1].Title)
+				assert.Equal(t, "Wow!", data.Shorts[1].ShortTitle)
+				assert.Equal(t, "New description", data.Shorts[1].Description)
+				assert.Equal(t, "#wow, #new", data.Shorts[1].Tags)
+				assert.Equal(t, "00:02:00", data.Shorts[1].StartTime)
+				assert.Equal(t, "00:03:00", data.Shorts[1].EndTime)
+			},
+		},
+		{
+			name:       "updates only selected fields",
+			opts:       RegenOptions{Clip: 1, Fields: []string{"title"}},
+			response:   "title: \"Better Title\"\nshortTitle: \"Ignored\"\ndescription: \"Ignored\"",
+			callsModel: true,
+			verify: func(t *testing.T, data ShortsOutput) {
+				assert.Equal(t, "Better Title", data.Shorts[0].Title)
+				assert.Equal(t, "Short 1", data.Shorts[0].ShortTitle)
+				assert.Equal(t, "First short description", data.Shorts[0].Description)
+			},
+		},
+		{
+			name:    "clip out of range",
+			opts:    RegenOptions{Clip: 3},
+			wantErr: "clip 3 out of range",
+		},
+		{
+			name:    "unknown field",
+			opts:    RegenOptions{Clip: 1, Fields: []string{"startTime"}},
+			wantErr: "unknown field",
+		},
+		{
+			name:       "unparseable response",
+			opts:       RegenOptions{Clip: 1},
+			response:   "I cannot help with that.",
+			callsModel: true,
+			wantErr:    "failed to parse API response",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "shorts_suggestions.yaml")
+			if err := os.WriteFile(path, []byte(shortsFile), 0644); err != nil {
+				t.Fatalf("failed to write shorts file: %v", err)
+			}
+
+			mockService := mocks.NewMockChatGPTServicer(t)
+			if tt.callsModel {
+				mockService.EXPECT().GetContent(
+					mock.Anything,
+					mock.MatchedBy(func(messages []services.ChatMessage) bool {
+						return len(messages) == 1 &&
+							strings.Contains(messages[0].Content, "CURRENT CLIP") &&
+							strings.Contains(messages[0].Content, tt.opts.Instruction)
+					}),
+					mock.Anything,
+				).Return(tt.response, nil).Once()
+			}
+
+			_, err := RegenerateClip(context.Background(), mockService, path, tt.opts)
+			if tt.wantErr != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+
+			raw, err := os.ReadFile(path)
+			assert.NoError(t, err)
+			var data ShortsOutput
+			assert.NoError(t, yaml.Unmarshal(raw, &data))
+			assert.Len(t, data.Shorts, 2)
+			assert.Equal(t, "video.mp4", data.SourceVideo)
+			tt.verify(t, data)
+		})
+	}
+}
+
+func TestRegenerateClips(t *testing.T) {
+	const shortsFile = `sourceVideo: video.mp4
+shorts:
+    - title: "First"
+      startTime: "00:00:00"
+      endTime: "00:01:00"
+      shortTitle: "Short 1"
+      excerpt: "We start with the basics."
+    - title: "Second"
+      startTime: "00:02:00"
+      endTime: "00:03:00"
+      shortTitle: "Short 2"
+    - title: "Third"
+      startTime: "00:04:00"
+      endTime: "00:05:00"
+      shortTitle: "Short 3"
+`
+	path := filepath.Join(t.TempDir(), "shorts_suggestions.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(shortsFile), 0644))
+
+	// Three clips in batches of two take two requests
+	mockService := mocks.NewMockChatGPTServicer(t)
+	mockService.EXPECT().GetContent(
+		mock.Anything,
+		mock.MatchedBy(func(messages []services.ChatMessage) bool {
+			return strings.Contains(messages[0].Content, "clip: 1") &&
+				strings.Contains(messages[0].Content, "context: We start with the basics.") &&
+				!strings.Contains(messages[0].Content, "excerpt:")
+		}),
+		mock.MatchedBy(func(opts services.CompletionOptions) bool { return opts.MaxTokens == 2000 }),
+	).Return("```yaml\nclips:\n  - clip: 1\n    title: New first\n  - clip: 2\n    title: New second\n```", nil).Once()
+	mockService.EXPECT().GetContent(
+		mock.Anything,
+		mock.MatchedBy(func(messages []services.ChatMessage) bool {
+			return strings.Contains(messages[0].Content, "clip: 3")
+		}),
+		mock.Anything,
+	).Return("- clip: 3\n  title: New third\n", nil).Once()
+
+	clips, err := RegenerateClips(context.Background(), mockService, path, RegenOptions{Fields: []string{"title"}, BatchSize: 2})
+	assert.NoError(t, err)
+	assert.Len(t, clips, 3)
+	assert.Equal(t, "New third", clips[2].Title)
+
+	raw, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	var data ShortsOutput
+	assert.NoError(t, yaml.Unmarshal(raw, &data))
+	assert.Equal(t, "New first", data.Shorts[0].Title)
+	assert.Equal(t, "Short 1", data.Shorts[0].ShortTitle)
+	assert.Equal(t, "We start with the basics.", data.Shorts[0].Excerpt)
+	assert.Equal(t, "New second", data.Shorts[1].Title)
+	assert.Equal(t, "New third", data.Shorts[2].Title)
+
+	// A batch the model does not answer with a list leaves the file as it was
+	mockService = mocks.NewMockChatGPTServicer(t)
+	mockService.EXPECT().GetContent(mock.Anything, mock.Anything, mock.Anything).Return("title: Only one", nil).Once()
+	_, err = RegenerateClips(context.Background(), mockService, path, RegenOptions{Clips: []int{2, 1}})
+	assert.ErrorContains(t, err, "failed to parse API response")
+	after, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, raw, after)
+
+	_, err = RegenerateClips(context.Background(), mocks.NewMockChatGPTServicer(t), path, RegenOptions{Clips: []int{4}})
+	assert.ErrorContains(t, err, "clip 4 out of range")
+}
+
+func TestAttachExcerpts(t *testing.T) {
+	transcript := "1\n00:00:00,000 --> 00:00:10,000\nWelcome to the show.\n\n" +
+		"2\n00:00:10,000 --> 00:00:20,000\nToday we talk about Go.\n\n" +
+		"3\n00:00:20,000 --> 00:00:30,000\nThanks for watching.\n"
+
+	cues, err := transcriptCues(context.Background(), transcript, Params{})
+	assert.NoError(t, err)
+	shorts := []ShortClip{
+		{Title: "Go", StartTime: "00:00:10", EndTime: "00:00:20"},
+		{Title: "Late", StartTime: "00:05:00", EndTime: "00:05:30"},
+	}
+	assert.Equal(t, 1, attachExcerpts(shorts, cues))
+	assert.Equal(t, "Today we talk about Go.", shorts[0].Excerpt)
+	assert.Equal(t, &utils.CueRange{First: 2, Last: 2}, shorts[0].Cues)
+	assert.Empty(t, shorts[1].Excerpt)
+	assert.Nil(t, shorts[1].Cues)
+
+	// A plain transcript has no cues unless a subtitle file is given
+	cues, err = transcriptCues(context.Background(), "Welcome to the show.", Params{})
+	assert.NoError(t, err)
+	assert.Empty(t, cues)
+
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "transcript.srt"), []byte(transcript), 0644))
+	cues, err = transcriptCues(context.Background(), "Welcome to the show.", Params{SubtitleFile: "${output}/transcript.srt", Output: dir})
+	assert.NoError(t, err)
+	assert.Len(t, cues, 3)
+
+	// The excerpt is the context of a regeneration, not a field to rewrite
+	prompt, err := buildRegenPrompt(shorts[0], regenFields, "", shorts[0].Excerpt)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(prompt, "Today we talk about Go."))
+}
+
+func TestClipsInRange(t *testing.T) {
+	shorts := []ShortClip{
+		{Title: "Before", StartTime: "00:10:00", EndTime: "00:10:30"},
+		{Title: "Inside", StartTime: "00:31:00", EndTime: "00:31:45"},
+		{Title: "Across the end", StartTime: "01:14:50", EndTime: "01:15:20"},
+		{Title: "Unreadable", StartTime: "soon", EndTime: "later"},
+	}
+	r := utils.TimeRange{Start: 30 * time.Minute, End: 75 * time.Minute}
+
+	kept := clipsInRange(context.Background(), append([]ShortClip(nil), shorts...), r)
+	assert.Len(t, kept, 2)
+	assert.Equal(t, "Inside", kept[0].Title)
+	assert.Equal(t, "Unreadable", kept[1].Title)
+
+	assert.Len(t, clipsInRange(context.Background(), shorts, utils.TimeRange{}), 4, "no range keeps every clip")
+}
+
+func TestSplitTranscriptParts(t *testing.T) {
+	var srt strings.Builder
+	for i := 0; i < 6; i++ {
+		start := time.Duration(i) * 10 * time.Minute
+		fmt.Fprintf(&srt, "%d\n%s --> %s\n%s\n\n", i+1, utils.FormatSRTTimestamp(start), utils.FormatSRTTimestamp(start+10*time.Minute), strings.Repeat("word ", 180))
+	}
+	parts, duration := splitTranscriptParts(srt.String(), 2000)
+	assert.Len(t, parts, 3)
+	assert.Equal(t, 60*time.Minute, duration)
+	assert.Equal(t, 20*time.Minute, parts[1].Start)
+	assert.Equal(t, 40*time.Minute, parts[1].End)
+	for _, part := range parts {
+		assert.LessOrEqual(t, len(part.Text), 2000)
+	}
+
+	parts, duration = splitTranscriptParts(strings.Repeat("a plain line of text\n", 200), 2000)
+	assert.Len(t, parts, 3)
+	assert.Zero(t, duration)
+	assert.Zero(t, parts[0].End)
+}
+
+func TestSelectWithCoverage(t *testing.T) {
+	candidates := []candidate{
+		{ID: 1, Section: "beginning"}, {ID: 2, Section: "beginning"},
+		{ID: 3, Section: "middle"}, {ID: 4, Section: "middle"},
+		{ID: 5, Section: "end"}, {ID: 6, Section: "end"},
+	}
+	ids := func(chosen []candidate) []int {
+		var result []int
+		for _, c := range chosen {
+			result = append(result, c.ID)
+		}
+		return result
+	}
+
+	// The beginning is left out of the ranking but keeps a clip
+	assert.Equal(t, []int{6, 4, 1}, ids(selectWithCoverage(candidates, []int{6, 5, 4, 3}, 3)))
+	assert.Equal(t, []int{6, 5, 4, 1}, ids(selectWithCoverage(candidates, []int{6, 5, 4, 3}, 4)))
+	assert.Equal(t, []int{3, 5, 1, 2}, ids(selectWithCoverage(candidates, []int{3, 5, 1, 2}, 10)))
+	assert.Equal(t, []int{1}, ids(selectWithCoverage(candidates, []int{6}, 1)))
+}
+
+func TestChunkedTranscriptMode(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "test-api-key")
+	dir := t.TempDir()
+	var srt strings.Builder
+	for i := 0; i < 6; i++ {
+		start := time.Duration(i) * 10 * time.Minute
+		fmt.Fprintf(&srt, "%d\n%s --> %s\n%s\n\n", i+1, utils.FormatSRTTimestamp(start), utils.FormatSRTTimestamp(start+10*time.Minute), strings.Repeat("word ", 180))
+	}
+	input := filepath.Join(dir, "episode.srt")
+	assert.NoError(t, os.WriteFile(input, []byte(srt.String()), 0644))
+
+	clip := func(title, start, end string) string {
+		return fmt.Sprintf("  - title: %q\n    startTime: %q\n    endTime: %q\n    description: \"d\"\n    tags: \"t\"\n    shortTitle: \"s\"\n", title, start, end)
+	}
+	responses := map[string]string{
+		"PART 1 OF 3":   "shorts:\n" + clip("Opening", "00:05:00", "00:05:45") + clip("Early", "00:12:00", "00:12:50"),
+		"PART 2 OF 3":   "shorts:\n" + clip("Middle A", "00:25:00", "00:25:45") + clip("Middle B", "00:35:00", "00:35:45"),
+		"PART 3 OF 3":   "shorts:\n" + clip("Late A", "00:45:00", "00:45:45") + clip("Finale", "00:55:00", "00:55:45"),
+		"## CANDIDATES": "ranking: [6, 5, 4, 3]",
+	}
+	service := mocks.NewMockChatGPTServicer(t)
+	service.EXPECT().GetContent(mock.Anything, mock.Anything, mock.Anything).RunAndReturn(
+		func(ctx context.Context, messages []services.ChatMessage, opts services.CompletionOptions) (string, error) {
+			for marker, response := range responses {
+				if strings.Contains(messages[0].Content, marker) {
+					return response, nil
+				}
+			}
+			return "", fmt.Errorf("unexpected prompt")
+		}).Times(4)
+
+	ctx := context.WithValue(context.Background(), ChatGPTServiceKey, service)
+	result, err := New().Execute(ctx, map[string]interface{}{
+		"input":         input,
+		"output":        filepath.Join(dir, "output"),
+		"contextTokens": 100,
+		"maxShorts":     3,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "chunked", result.Metadata["transcriptMode"])
+	assert.Equal(t, 3, result.Metadata["transcriptParts"])
+	assert.Equal(t, 6, result.Metadata["candidates"])
+	assert.Equal(t, map[string]int{"beginning": 1, "middle": 1, "end": 1}, result.Metadata["coverage"])
+
+	data, err := os.ReadFile(result.Outputs["suggestions"])
+	assert.NoError(t, err)
+	var output ShortsOutput
+	assert.NoError(t, yaml.Unmarshal(data, &output))
+	var titles []string
+	for _, short := range output.Shorts {
+		titles = append(titles, short.Title)
+	}
+	assert.Equal(t, []string{"Finale", "Middle B", "Opening"}, titles)
+}
+
+// TestGolden records the prompt built with the default template and the suggestions written from a
+// fixture response; rerun with UPDATE_SNAPSHOTS=1 after an intended change
+func TestGolden(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "test-api-key")
+	tempDir := t.TempDir()
+	inputFile := filepath.Join(tempDir, "transcript_corrected.txt")
+	if err := os.WriteFile(inputFile, []byte("[00:00:05] Welcome back to the show.\n[00:00:12] Today we talk about why analog synthesizers sound warm."), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var sent []services.ChatMessage
+	mockService := mocks.NewMockChatGPTServicer(t)
+	mockService.EXPECT().GetContent(mock.Anything, mock.Anything, mock.Anything).
+		Run(func(_ context.Context, messages []services.ChatMessage, _ services.CompletionOptions) {
+			sent = messages
+		}).
+		Return(mockSuccessResponse, nil)
+
+	result, err := newTestModule(mockService).Execute(context.Background(), map[string]interface{}{
+		"input":       inputFile,
+		"output":      filepath.Join(tempDir, "output"),
+		"minDuration": 15,
+		"maxDuration": 60,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot.MatchMessages(t, "prompt.txt", sent, tempDir, "<tmp>")
+	data, err := os.ReadFile(result.Outputs["suggestions"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot.Match(t, "shorts_suggestions.yaml", string(data), tempDir, "<tmp>")
+}
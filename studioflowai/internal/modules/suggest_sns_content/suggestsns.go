@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -26,15 +27,28 @@ type Module struct{}
 
 // Params contains the parameters for SNS content generation
 type Params struct {
-	Input            string  `json:"input"`            // Path to input transcript file
-	Output           string  `json:"output"`           // Path to output directory
-	OutputFileName   string  `json:"outputFileName"`   // Custom output file name (without extension)
-	Model            string  `json:"model"`            // OpenAI model to use (default: "gpt-4o")
-	Temperature      float64 `json:"temperature"`      // Model temperature (default: 0.1)
-	MaxTokens        int     `json:"maxTokens"`        // Maximum tokens for the response (default: 8000)
-	RequestTimeoutMS int     `json:"requestTimeoutMs"` // API request timeout in milliseconds (default: 120000)
-	Language         string  `json:"language"`         // Language for the content (default: "Spanish")
-	PromptFilePath   string  `json:"promptFilePath"`   // Path to custom prompt YAML file (default: "./prompts/sns_content.yaml")
+	Input              string                 `json:"input"`                                               // Path to input transcript file; an SRT file gives the timeline real timestamps
+	Output             string                 `json:"output"`                                              // Path to output directory
+	OutputFileName     string                 `json:"outputFileName"`                                      // Custom output file name (without extension)
+	Model              string                 `json:"model" default:"gpt-4o"`                              // OpenAI model to use (default: "gpt-4o")
+	FallbackModels     []string               `json:"fallbackModels"`                                      // Models tried in order when the primary model fails or returns empty content
+	Strict             bool                   `json:"strict"`                                              // Require valid YAML and send its errors back to the model for a corrected response (default: false)
+	ValidationAttempts int                    `json:"validationAttempts" default:"2"`                      // Responses per model checked before falling back to the next one (default: 2)
+	Temperature        float64                `json:"temperature" default:"0.1"`                           // Model temperature (default: 0.1)
+	MaxTokens          int                    `json:"maxTokens" default:"8000"`                            // Maximum tokens for the response (default: 8000)
+	RequestTimeoutMS   int                    `json:"requestTimeoutMs" default:"120000"`                   // API request timeout in milliseconds (default: 120000)
+	Language           string                 `json:"language" default:"Spanish"`                          // Language for the content (default: "Spanish")
+	Languages          []string               `json:"languages"`                                           // Generate in several languages, e.g. [Spanish, English]; overrides language
+	SplitLanguages     bool                   `json:"splitLanguages"`                                      // Write one file per language instead of one section per language (default: false)
+	PromptFilePath     string                 `json:"promptFilePath" default:"./prompts/sns_content.yaml"` // Path to custom prompt YAML file (default: "./prompts/sns_content.yaml")
+	TitleHistoryFile   string                 `json:"titleHistoryFile"`                                    // Path to CSV/JSON of past titles with performance metrics (optional)
+	FewShotCount       int                    `json:"fewShotCount" default:"5"`                            // Number of top past titles to include as examples (default: 5)
+	FewShotMetric      string                 `json:"fewShotMetric" default:"views"`                       // Metric used to rank past titles: views, likes, comments, ctr, engagement (default: "views")
+	Metadata           map[string]interface{} `json:"metadata"`                                            // Episode details (guest, episode number, recording date, links) for the prompt and output
+	MetadataFile       string                 `json:"metadataFile"`                                        // YAML file with episode details; inline metadata wins (optional)
+	TitlePattern       string                 `json:"titlePattern"`                                        // Series title pattern such as "EP{n}: {title}"; {n} is the episode number (optional)
+	RelatedEpisodes    string                 `json:"relatedEpisodes"`                                     // Path to related_episodes.yaml from transcript_index; listed at the end of every description (optional)
+	RelatedLabel       string                 `json:"relatedLabel" default:"Related episodes"`             // Heading of the related-episode list (default: "Related episodes")
 }
 
 // New creates a new SNS module
@@ -47,6 +61,11 @@ func (m *Module) Name() string {
 	return "suggest_sns_content"
 }
 
+// ParamsTemplate returns the module's parameter struct, used to validate and document workflows
+func (m *Module) ParamsTemplate() interface{} {
+	return Params{}
+}
+
 // Validate checks if the parameters are valid
 func (m *Module) Validate(params map[string]interface{}) error {
 	var p Params
@@ -76,6 +95,21 @@ func (m *Module) Validate(params map[string]interface{}) error {
 		}
 	}
 
+	// Check if the title history file exists
+	if p.TitleHistoryFile != "" {
+		if _, err := os.Stat(p.TitleHistoryFile); os.IsNotExist(err) {
+			return fmt.Errorf("title history file %s does not exist", p.TitleHistoryFile)
+		}
+	}
+
+	if _, err := utils.ResolveEpisodeMetadata(p.Metadata, p.MetadataFile); err != nil {
+		return err
+	}
+
+	if p.ValidationAttempts < 0 {
+		return fmt.Errorf("validationAttempts must not be negative")
+	}
+
 	return nil
 }
 
@@ -103,16 +137,24 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 		p.RequestTimeoutMS = 120000
 	}
 	if p.PromptFilePath == "" {
-		p.PromptFilePath = "./prompts/sns_content.yaml"
+		p.PromptFilePath = utils.ResolvePromptPath("./prompts/sns_content.yaml")
+	}
+	if p.FewShotCount == 0 {
+		p.FewShotCount = 5
+	}
+	metadata, err := utils.ResolveEpisodeMetadata(p.Metadata, p.MetadataFile)
+	if err != nil {
+		return modules.ModuleResult{}, err
 	}
+	p.Metadata = metadata
 
 	// Create output directory if it doesn't exist
-	if err := os.MkdirAll(p.Output, 0755); err != nil {
+	if err := utils.EnsureDir(p.Output); err != nil {
 		return modules.ModuleResult{}, fmt.Errorf("failed to create output directory: %w", err)
 	}
 
 	// Get the SNS prompt
-	snsPrompt := getSNSPrompt(p.PromptFilePath)
+	snsPrompt := getSNSPrompt(ctx, p.PromptFilePath)
 
 	// Resolve the input path if it contains ${output}
 	resolvedInput := utils.ResolveOutputPath(p.Input, p.Output)
@@ -142,23 +184,102 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 		outputPath = filepath.Join(p.Output, baseFilename+"_SNS.yaml")
 	}
 
-	if err := m.processSNSFile(ctx, resolvedInput, outputPath, snsPrompt, p); err != nil {
+	if languages := uniqueLanguages(p.Languages); len(languages) > 0 {
+		return m.executeLanguages(ctx, resolvedInput, outputPath, snsPrompt, languages, p)
+	}
+
+	usedModel, err := m.processSNSFile(ctx, resolvedInput, outputPath, snsPrompt, p)
+	if err != nil {
 		return modules.ModuleResult{}, err
 	}
 
-	utils.LogSuccess("Generated SNS content for %s -> %s", resolvedInput, outputPath)
+	utils.LogSuccessContext(ctx, "Generated SNS content for %s -> %s", resolvedInput, outputPath)
 
 	return modules.ModuleResult{
 		Outputs: map[string]string{
 			"sns_content": outputPath,
 		},
+		Metadata: map[string]interface{}{
+			"model": usedModel,
+		},
 		Statistics: map[string]interface{}{
-			"model":       p.Model,
+			"model":       usedModel,
 			"language":    p.Language,
 			"inputFile":   resolvedInput,
 			"outputFile":  outputPath,
 			"processTime": time.Now().Format(time.RFC3339),
 		},
+		Stats: modules.Stats{Items: 1},
+	}, nil
+}
+
+// executeLanguages generates the content in every language and writes the sections or per-language files
+func (m *Module) executeLanguages(ctx context.Context, inputPath, outputPath, promptTemplate string, languages []string, p Params) (modules.ModuleResult, error) {
+	if !utils.IsTextFile(inputPath) {
+		return modules.ModuleResult{}, fmt.Errorf("file %s appears to be binary, not a text file - skipping", inputPath)
+	}
+	transcript, err := utils.ReadTextFile(inputPath)
+	if err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to read transcript file: %w", err)
+	}
+	frontMatter, transcript := utils.SplitEpisodeFrontMatter(transcript)
+	p.Metadata = utils.MergeEpisodeMetadata(frontMatter, p.Metadata)
+	transcript, cues, err := timedTranscript(inputPath, transcript)
+	if err != nil {
+		return modules.ModuleResult{}, err
+	}
+
+	contents, usedModels, err := m.processSNSLanguages(ctx, inputPath, transcript, promptTemplate, languages, p, cues)
+	if err != nil {
+		return modules.ModuleResult{}, err
+	}
+
+	outputs := make(map[string]string)
+	if p.SplitLanguages {
+		base := strings.TrimSuffix(outputPath, filepath.Ext(outputPath))
+		for i, language := range languages {
+			path := fmt.Sprintf("%s_%s.yaml", base, languageSlug(language))
+			content, err := withEpisode(contents[language], p.Metadata)
+			if err != nil {
+				return modules.ModuleResult{}, err
+			}
+			if err := utils.WriteTextFile(path, content); err != nil {
+				return modules.ModuleResult{}, fmt.Errorf("failed to write output file: %w", err)
+			}
+			outputs["sns_content_"+languageSlug(language)] = path
+			// Downstream steps reading sns_content get the first language
+			if i == 0 {
+				outputs["sns_content"] = path
+			}
+		}
+	} else {
+		data, err := languageSections(languages, contents, p.Metadata)
+		if err != nil {
+			return modules.ModuleResult{}, err
+		}
+		if err := utils.WriteTextFile(outputPath, string(data)); err != nil {
+			return modules.ModuleResult{}, fmt.Errorf("failed to write output file: %w", err)
+		}
+		outputs["sns_content"] = outputPath
+	}
+
+	utils.LogSuccessContext(ctx, "Generated SNS content in %s for %s", strings.Join(languages, ", "), inputPath)
+
+	usedModel := strings.Join(usedModels, ", ")
+	return modules.ModuleResult{
+		Outputs: outputs,
+		Metadata: map[string]interface{}{
+			"model":     usedModel,
+			"languages": languages,
+		},
+		Statistics: map[string]interface{}{
+			"model":       usedModel,
+			"language":    strings.Join(languages, ", "),
+			"inputFile":   inputPath,
+			"outputFile":  outputs["sns_content"],
+			"processTime": time.Now().Format(time.RFC3339),
+		},
+		Stats: modules.Stats{Items: len(languages)},
 	}, nil
 }
 
@@ -168,7 +289,7 @@ func (m *Module) GetIO() modules.ModuleIO {
 		RequiredInputs: []modules.ModuleInput{
 			{
 				Name:        "input",
-				Description: "Path to input transcript file",
+				Description: "Path to input transcript file (.srt cues are used for the timeline)",
 				Patterns:    []string{".txt", ".srt"},
 				Type:        string(modules.InputTypeFile),
 			},
@@ -199,6 +320,12 @@ func (m *Module) GetIO() modules.ModuleIO {
 				Description: "Language for the content",
 				Type:        string(modules.InputTypeData),
 			},
+			{
+				Name:        "titleHistoryFile",
+				Description: "CSV/JSON of past titles with performance metrics used as few-shot examples",
+				Patterns:    []string{".csv", ".json"},
+				Type:        string(modules.InputTypeFile),
+			},
 		},
 		ProducedOutputs: []modules.ModuleOutput{
 			{
@@ -212,120 +339,462 @@ func (m *Module) GetIO() modules.ModuleIO {
 }
 
 // processSNSFile sends a transcript file to ChatGPT for SNS content generation
-func (m *Module) processSNSFile(ctx context.Context, inputPath, outputPath, promptTemplate string, p Params) error {
+func (m *Module) processSNSFile(ctx context.Context, inputPath, outputPath, promptTemplate string, p Params) (string, error) {
 	// Check if the file is a text file
 	if !utils.IsTextFile(inputPath) {
-		return fmt.Errorf("file %s appears to be binary, not a text file - skipping", inputPath)
+		return "", fmt.Errorf("file %s appears to be binary, not a text file - skipping", inputPath)
 	}
 
 	// Read the transcript file
 	transcript, err := utils.ReadTextFile(inputPath)
 	if err != nil {
-		return fmt.Errorf("failed to read transcript file: %w", err)
+		return "", fmt.Errorf("failed to read transcript file: %w", err)
+	}
+	frontMatter, transcript := utils.SplitEpisodeFrontMatter(transcript)
+	p.Metadata = utils.MergeEpisodeMetadata(frontMatter, p.Metadata)
+
+	// SRT transcripts give the timeline real cue times
+	transcript, cues, err := timedTranscript(inputPath, transcript)
+	if err != nil {
+		return "", err
 	}
 
 	// Check if API key is set, if not, save a placeholder file
 	if !chatgpt.IsAPIKeySet() {
-		utils.LogWarning("No API key set - saving placeholder file to %s", outputPath)
-		placeholderContent := `# MOCK OUTPUT - No OPENAI_API_KEY set
-# Simulated example of generated SNS content in YAML format.
+		utils.LogWarningContext(ctx, "No API key set - saving placeholder file to %s", outputPath)
+		content, err := withEpisode(placeholderContent(inputPath), p.Metadata)
+		if err != nil {
+			return "", err
+		}
+		if err := utils.WriteTextFile(outputPath, content); err != nil {
+			return "", fmt.Errorf("failed to write output file: %w", err)
+		}
+		return p.Model, nil
+	}
 
-sns_content_generation:
-  introduction: "Analiza el siguiente script de entrevista y genera contenido optimizado para maximizar el alcance y engagement en YouTube."
+	utils.LogVerboseContext(ctx, "Generating SNS content for %s...", filepath.Base(inputPath))
 
-  title: "El secreto detrás del éxito en ciberseguridad | Entrevista exclusiva"
+	// Construct the full prompt
+	fullPrompt, err := buildSNSPrompt(promptTemplate, transcript, p.Language, p, cues)
+	if err != nil {
+		return "", err
+	}
 
-  description:
-    🚀 Descubre los secretos que llevaron a nuestro invitado a convertirse en una figura clave de la ciberseguridad. 
-    En esta entrevista exclusiva, exploramos su trayectoria, aprendizajes, y consejos para profesionales del sector.
-    🔒 Temas clave, historias impactantes y estrategias reales que puedes aplicar hoy.
-    
-    👉 ¡No olvides suscribirte, dejar tu comentario y compartir este video!
-    
-    #ciberseguridad #infosec #hackingetico #tecnología #entrevistas
+	// Initialize ChatGPT service
+	chatGPT, err := m.getChatGPTService(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to initialize ChatGPT service: %w", err)
+	}
 
-  social_media:
-    twitter: "🚨 Nuevo episodio: Entrevista exclusiva sobre ciberseguridad con insights que no te puedes perder 🔐 ¡Dale play ahora! 🎥 #infosec #hackingetico"
-    instagram_facebook: >
-      🔥 ¡Ya disponible! Entrevistamos a uno de los referentes en ciberseguridad 🎙️ Hablamos sobre sus inicios, retos y cómo ve el futuro del sector. 
-      👉 Mira el video completo y comenta qué parte te sorprendió más.
-    linkedin: >
-      Nueva entrevista publicada con un experto en ciberseguridad. Hablamos sobre tendencias, desafíos y cómo los profesionales pueden adaptarse al entorno actual. 
-      Un contenido valioso para quienes lideran equipos de seguridad o aspiran a crecer en esta industria.
+	// Send the request to ChatGPT
+	completion, err := completeSNS(ctx, chatGPT, fullPrompt, p, cues)
+	if err != nil {
+		return "", fmt.Errorf("ChatGPT API request failed: %w", err)
+	}
+	response, err := alignTimeline(completion.Content, cues)
+	if err != nil {
+		return "", err
+	}
+	if response, err = withSeriesTitles(response, p.TitlePattern, p.Metadata); err != nil {
+		return "", err
+	}
+	if response, err = withRelatedEpisodes(ctx, response, p); err != nil {
+		return "", err
+	}
+	response, err = withEpisode(response, p.Metadata)
+	if err != nil {
+		return "", err
+	}
 
-  keywords: "ciberseguridad, hacking ético, seguridad informática, entrevistas tecnología, expertos ciberseguridad, SOC, malware, pentesting"
+	// Write the generated content to the output file
+	if err := utils.WriteTextFile(outputPath, response); err != nil {
+		return "", fmt.Errorf("failed to write output file: %w", err)
+	}
 
-  timeline:
-    - "00:00 - Introducción y contexto"
-    - "03:15 - Trayectoria profesional del invitado"
-    - "10:42 - Principales desafíos en ciberseguridad"
-    - "18:20 - Herramientas y consejos prácticos"
-    - "25:50 - Futuro del sector"
-    - "30:00 - Conclusiones y despedida"
+	utils.LogSuccessContext(ctx, "Generated SNS content for %s -> %s", p.Input, outputPath)
+	return completion.Model, nil
+}
 
-  conclusion: "Este contenido ha sido generado como ejemplo en formato YAML para ilustrar el resultado esperado."  
+// processSNSLanguages generates the content in the first language from the transcript, then
+// localizes that result into the other languages so the transcript is only sent once
+func (m *Module) processSNSLanguages(ctx context.Context, inputPath, transcript, promptTemplate string, languages []string, p Params, cues []utils.SubtitleCue) (map[string]string, []string, error) {
+	contents := make(map[string]string, len(languages))
 
-  transcript_file: "` + inputPath + `"`
-		if err := utils.WriteTextFile(outputPath, placeholderContent); err != nil {
-			return fmt.Errorf("failed to write output file: %w", err)
+	// Check if API key is set, if not, use the placeholder for every language
+	if !chatgpt.IsAPIKeySet() {
+		utils.LogWarningContext(ctx, "No API key set - saving placeholder content for %s", strings.Join(languages, ", "))
+		for _, language := range languages {
+			contents[language] = placeholderContent(inputPath)
 		}
-		return nil
+		return contents, []string{p.Model}, nil
+	}
+
+	chatGPT, err := m.getChatGPTService(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize ChatGPT service: %w", err)
 	}
 
-	utils.LogVerbose("Generating SNS content for %s...", filepath.Base(inputPath))
+	primary := languages[0]
+	utils.LogVerboseContext(ctx, "Generating SNS content in %s for %s...", primary, filepath.Base(inputPath))
+	fullPrompt, err := buildSNSPrompt(promptTemplate, transcript, primary, p, cues)
+	if err != nil {
+		return nil, nil, err
+	}
+	completion, err := completeSNS(ctx, chatGPT, fullPrompt, p, cues)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ChatGPT API request failed for %s: %w", primary, err)
+	}
+	if contents[primary], err = alignTimeline(completion.Content, cues); err != nil {
+		return nil, nil, err
+	}
+	if contents[primary], err = withSeriesTitles(contents[primary], p.TitlePattern, p.Metadata); err != nil {
+		return nil, nil, err
+	}
+	if contents[primary], err = withRelatedEpisodes(ctx, contents[primary], p); err != nil {
+		return nil, nil, err
+	}
+	usedModels := []string{completion.Model}
 
-	// Create API client timeout context
-	apiCtx, cancel := context.WithTimeout(ctx, time.Duration(p.RequestTimeoutMS)*time.Millisecond)
-	defer cancel()
+	for _, language := range languages[1:] {
+		utils.LogVerboseContext(ctx, "Localizing SNS content into %s...", language)
+		completion, err := completeSNS(ctx, chatGPT, localizePrompt(contents[primary], primary, language), p, cues)
+		if err != nil {
+			return nil, nil, fmt.Errorf("ChatGPT API request failed for %s: %w", language, err)
+		}
+		if contents[language], err = alignTimeline(completion.Content, cues); err != nil {
+			return nil, nil, err
+		}
+		if contents[language], err = withSeriesTitles(contents[language], p.TitlePattern, p.Metadata); err != nil {
+			return nil, nil, err
+		}
+		if contents[language], err = withRelatedEpisodes(ctx, contents[language], p); err != nil {
+			return nil, nil, err
+		}
+		if !slices.Contains(usedModels, completion.Model) {
+			usedModels = append(usedModels, completion.Model)
+		}
+	}
 
-	// Construct the full prompt
+	return contents, usedModels, nil
+}
+
+// snsSystemPrompt is the system message of every SNS request
+const snsSystemPrompt = "Eres un asistente especializado en optimizar contenido para YouTube, marketing digital y redes sociales. Tu trabajo es analizar transcripciones y generar títulos, descripciones, hashtags y otros contenidos para maximizar visibilidad y engagement."
+
+// buildSNSPrompt combines the prompt template, few-shot titles, target language and transcript.
+// With SRT cues, the model is told to take the timeline from the cue times.
+func buildSNSPrompt(promptTemplate, transcript, language string, p Params, cues []utils.SubtitleCue) (string, error) {
 	fullPrompt := promptTemplate
 	if !strings.HasSuffix(fullPrompt, "\n") {
 		fullPrompt += "\n\n"
 	}
-	fullPrompt += "Generar en: " + p.Language + "\n\n"
+
+	// Include the channel's best past titles as few-shot examples
+	fewShot, err := utils.BuildFewShotPrompt(p.TitleHistoryFile, p.FewShotMetric, p.FewShotCount)
+	if err != nil {
+		return "", fmt.Errorf("failed to load title history: %w", err)
+	}
+	if fewShot != "" {
+		fullPrompt += fewShot + "\n"
+	}
+
+	if details := utils.EpisodeMetadataPrompt(p.Metadata); details != "" {
+		fullPrompt += details + "\n"
+	}
+	if len(cues) > 0 {
+		fullPrompt += timelinePrompt(cues)
+	}
+	if p.TitlePattern != "" {
+		fullPrompt += "El título se publica como \"" + utils.FormatSeriesTitle(p.TitlePattern, "{title}", p.Metadata) +
+			"\": escribe solo {title}, sin el número de episodio, que se agrega automáticamente.\n"
+	}
+	fullPrompt += "Generar en: " + language + "\n\n"
 	fullPrompt += transcript
+	return fullPrompt, nil
+}
+
+// localizePrompt asks for an already generated result in another language, without the transcript
+func localizePrompt(content, from, to string) string {
+	return fmt.Sprintf(`El siguiente contenido para YouTube y redes sociales fue generado en %s.
+Adáptalo al %s para una audiencia nativa: traduce títulos, descripciones y copys con naturalidad,
+usa hashtags y keywords que se busquen en ese idioma, y conserva la misma estructura YAML y las marcas de tiempo.
+Responde solo con el YAML.
+
+%s`, from, to, content)
+}
 
-	// Create the API request
+// completeSNS sends one SNS request, falling back to other models on errors, empty responses,
+// with SRT cues timeline entries outside the video, and in strict mode responses that are not YAML.
+// Strict mode also asks the model to correct a rejected response rather than sending the request again.
+func completeSNS(ctx context.Context, chatGPT chatgpt.ChatGPTServicer, prompt string, p Params, cues []utils.SubtitleCue) (*chatgpt.Completion, error) {
 	messages := []chatgpt.ChatMessage{
 		{
 			Role:    "system",
-			Content: "Eres un asistente especializado en optimizar contenido para YouTube, marketing digital y redes sociales. Tu trabajo es analizar transcripciones y generar títulos, descripciones, hashtags y otros contenidos para maximizar visibilidad y engagement.",
+			Content: snsSystemPrompt,
 		},
 		{
 			Role:    "user",
-			Content: fullPrompt,
+			Content: prompt,
 		},
 	}
 
-	// Initialize ChatGPT service
-	chatGPT, err := m.getChatGPTService(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to initialize ChatGPT service: %w", err)
-	}
-
-	// Send the request to ChatGPT
-	response, err := chatGPT.GetContent(apiCtx, messages, chatgpt.CompletionOptions{
+	return chatgpt.CompleteWithFallback(ctx, chatGPT, messages, chatgpt.CompletionOptions{
 		Model:            p.Model,
 		Temperature:      p.Temperature,
 		MaxTokens:        p.MaxTokens,
 		RequestTimeoutMS: p.RequestTimeoutMS,
+	}, chatgpt.FallbackChain{Models: p.FallbackModels, ValidationAttempts: p.ValidationAttempts, Reprompt: p.Strict}, func(response string) error {
+		if strings.TrimSpace(response) == "" {
+			return fmt.Errorf("empty response")
+		}
+		if p.Strict {
+			if err := checkSNSYAML(response); err != nil {
+				return err
+			}
+		}
+		if len(cues) > 0 {
+			return checkTimeline(response, cues)
+		}
+		return nil
 	})
+}
+
+// checkSNSYAML requires a response to be a YAML mapping, reporting where parsing failed
+func checkSNSYAML(response string) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(stripYAMLFence(response)), &doc); err != nil {
+		return fmt.Errorf("the response is not valid YAML: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("the response must be a YAML mapping of sections, not a list or plain text")
+	}
+	return nil
+}
+
+// languageSections writes one top-level section per language, after the episode details if any.
+// Responses that are valid YAML are embedded as mappings; anything else is kept verbatim as a block string.
+func languageSections(languages []string, contents map[string]string, metadata map[string]interface{}) ([]byte, error) {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	if len(metadata) > 0 {
+		episode, err := utils.EpisodeNode(metadata)
+		if err != nil {
+			return nil, err
+		}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "episode"}, episode)
+	}
+	for _, language := range languages {
+		content := stripYAMLFence(contents[language])
+		value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: content, Style: yaml.LiteralStyle}
+
+		var parsed yaml.Node
+		if err := yaml.Unmarshal([]byte(content), &parsed); err == nil && len(parsed.Content) > 0 && parsed.Content[0].Kind == yaml.MappingNode {
+			value = parsed.Content[0]
+		}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: language}, value)
+	}
+
+	data, err := yaml.Marshal(root)
 	if err != nil {
-		return fmt.Errorf("ChatGPT API request failed: %w", err)
+		return nil, fmt.Errorf("failed to generate YAML: %w", err)
 	}
+	return data, nil
+}
 
-	// Write the generated content to the output file
-	if err := utils.WriteTextFile(outputPath, response); err != nil {
-		return fmt.Errorf("failed to write output file: %w", err)
+// withSeriesTitles names every title of a YAML response after the series pattern.
+// Responses that are not YAML, and responses without a pattern, are returned as is.
+func withSeriesTitles(content, pattern string, metadata map[string]interface{}) (string, error) {
+	if pattern == "" {
+		return content, nil
 	}
 
-	utils.LogSuccess("Generated SNS content for %s -> %s", p.Input, outputPath)
-	return nil
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(stripYAMLFence(content)), &doc); err != nil || len(doc.Content) == 0 {
+		return content, nil
+	}
+
+	var rename func(node *yaml.Node)
+	rename = func(node *yaml.Node) {
+		switch node.Kind {
+		case yaml.SequenceNode:
+			for _, child := range node.Content {
+				rename(child)
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key, value := node.Content[i], node.Content[i+1]
+				if key.Value == "title" && value.Kind == yaml.ScalarNode {
+					value.Value = utils.FormatSeriesTitle(pattern, value.Value, metadata)
+					value.Style = yaml.DoubleQuotedStyle
+					continue
+				}
+				rename(value)
+			}
+		}
+	}
+	rename(doc.Content[0])
+
+	data, err := yaml.Marshal(&doc)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate YAML: %w", err)
+	}
+	return string(data), nil
+}
+
+// relatedEpisode is a past episode listed in related_episodes.yaml
+type relatedEpisode struct {
+	Episode string `yaml:"episode"`
+	URL     string `yaml:"url"`
+}
+
+// withRelatedEpisodes appends the related past episodes found by transcript_index to every
+// description of a YAML response. Responses that are not YAML are returned as is.
+func withRelatedEpisodes(ctx context.Context, content string, p Params) (string, error) {
+	if p.RelatedEpisodes == "" {
+		return content, nil
+	}
+	path := utils.ResolveOutputPath(p.RelatedEpisodes, p.Output)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		utils.LogWarningContext(ctx, "Related episodes not added: %v", err)
+		return content, nil
+	}
+	var report struct {
+		Related []relatedEpisode `yaml:"related"`
+	}
+	if err := yaml.Unmarshal(data, &report); err != nil {
+		return "", fmt.Errorf("failed to parse related episodes %s: %w", path, err)
+	}
+	if len(report.Related) == 0 {
+		return content, nil
+	}
+
+	label := p.RelatedLabel
+	if label == "" {
+		label = "Related episodes"
+	}
+	var list strings.Builder
+	list.WriteString("\n\n" + label + ":")
+	for _, related := range report.Related {
+		list.WriteString("\n- " + related.Episode)
+		if related.URL != "" {
+			list.WriteString(": " + related.URL)
+		}
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(stripYAMLFence(content)), &doc); err != nil || len(doc.Content) == 0 {
+		return content, nil
+	}
+	var appendList func(node *yaml.Node)
+	appendList = func(node *yaml.Node) {
+		switch node.Kind {
+		case yaml.SequenceNode:
+			for _, child := range node.Content {
+				appendList(child)
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key, value := node.Content[i], node.Content[i+1]
+				if key.Value == "description" && value.Kind == yaml.ScalarNode {
+					value.Value = strings.TrimRight(value.Value, "\n") + list.String()
+					value.Style = yaml.LiteralStyle
+					continue
+				}
+				appendList(value)
+			}
+		}
+	}
+	appendList(doc.Content[0])
+
+	out, err := yaml.Marshal(&doc)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate YAML: %w", err)
+	}
+	return string(out), nil
+}
+
+// withEpisode adds the episode details to a YAML response; responses are written verbatim without metadata
+func withEpisode(content string, metadata map[string]interface{}) (string, error) {
+	if len(metadata) == 0 {
+		return content, nil
+	}
+	return utils.WithEpisodeSection(stripYAMLFence(content)+"\n", metadata)
+}
+
+// stripYAMLFence removes a surrounding Markdown code fence from a model response
+func stripYAMLFence(content string) string {
+	trimmed := strings.TrimSpace(content)
+	if !strings.HasPrefix(trimmed, "```") {
+		return trimmed
+	}
+	if newline := strings.Index(trimmed, "\n"); newline >= 0 {
+		trimmed = trimmed[newline+1:]
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(trimmed), "```"))
+}
+
+// uniqueLanguages drops empty and repeated languages, keeping the first occurrence
+func uniqueLanguages(languages []string) []string {
+	var unique []string
+	for _, language := range languages {
+		language = strings.TrimSpace(language)
+		if language != "" && !slices.ContainsFunc(unique, func(l string) bool { return strings.EqualFold(l, language) }) {
+			unique = append(unique, language)
+		}
+	}
+	return unique
+}
+
+// languageSlug turns a language name into a file name suffix, e.g. "Brazilian Portuguese" -> "brazilian_portuguese"
+func languageSlug(language string) string {
+	return strings.ToLower(strings.Join(strings.Fields(language), "_"))
+}
+
+// placeholderContent is the simulated output written when no API key is set
+func placeholderContent(inputPath string) string {
+	return `# MOCK OUTPUT - No OPENAI_API_KEY set
+# Simulated example of generated SNS content in YAML format.
+
+sns_content_generation:
+  introduction: "Analiza el siguiente script de entrevista y genera contenido optimizado para maximizar el alcance y engagement en YouTube."
+
+  title: "El secreto detrás del éxito en ciberseguridad | Entrevista exclusiva"
+
+  description:
+    🚀 Descubre los secretos que llevaron a nuestro invitado a convertirse en una figura clave de la ciberseguridad. 
+    En esta entrevista exclusiva, exploramos su trayectoria, aprendizajes, y consejos para profesionales del sector.
+    🔒 Temas clave, historias impactantes y estrategias reales que puedes aplicar hoy.
+    
+    👉 ¡No olvides suscribirte, dejar tu comentario y compartir este video!
+    
+    #ciberseguridad #infosec #hackingetico #tecnología #entrevistas
+
+  social_media:
+    twitter: "🚨 Nuevo episodio: Entrevista exclusiva sobre ciberseguridad con insights que no te puedes perder 🔐 ¡Dale play ahora! 🎥 #infosec #hackingetico"
+    instagram_facebook: >
+      🔥 ¡Ya disponible! Entrevistamos a uno de los referentes en ciberseguridad 🎙️ Hablamos sobre sus inicios, retos y cómo ve el futuro del sector. 
+      👉 Mira el video completo y comenta qué parte te sorprendió más.
+    linkedin: >
+      Nueva entrevista publicada con un experto en ciberseguridad. Hablamos sobre tendencias, desafíos y cómo los profesionales pueden adaptarse al entorno actual. 
+      Un contenido valioso para quienes lideran equipos de seguridad o aspiran a crecer en esta industria.
+
+  keywords: "ciberseguridad, hacking ético, seguridad informática, entrevistas tecnología, expertos ciberseguridad, SOC, malware, pentesting"
+
+  timeline:
+    - "00:00 - Introducción y contexto"
+    - "03:15 - Trayectoria profesional del invitado"
+    - "10:42 - Principales desafíos en ciberseguridad"
+    - "18:20 - Herramientas y consejos prácticos"
+    - "25:50 - Futuro del sector"
+    - "30:00 - Conclusiones y despedida"
+
+  conclusion: "Este contenido ha sido generado como ejemplo en formato YAML para ilustrar el resultado esperado."  
+
+  transcript_file: "` + inputPath + `"`
 }
 
 // getSNSPrompt returns the prompt for SNS content generation
-func getSNSPrompt(promptFilePath string) string {
+func getSNSPrompt(ctx context.Context, promptFilePath string) string {
 	// Check if a custom prompt template exists
 	customPromptPath := promptFilePath
 	if _, err := os.Stat(customPromptPath); err == nil {
@@ -335,15 +804,15 @@ func getSNSPrompt(promptFilePath string) string {
 			// Try to parse as YAML
 			yamlPrompt, err := formatSNSYAMLPrompt(data)
 			if err == nil {
-				utils.LogDebug("Using custom SNS prompt template from YAML file: %s", customPromptPath)
+				utils.LogDebugContext(ctx, "Using custom SNS prompt template from YAML file: %s", customPromptPath)
 				return yamlPrompt
 			}
-			utils.LogWarning("Failed to parse YAML prompt: %v, falling back to default", err)
+			utils.LogWarningContext(ctx, "Failed to parse YAML prompt: %v, falling back to default", err)
 		}
 	}
 
 	// Default prompt in markdown format
-	utils.LogDebug("Using default SNS prompt template")
+	utils.LogDebugContext(ctx, "Using default SNS prompt template")
 	return `Analiza el siguiente script de entrevista y genera contenido optimizado para maximizar el alcance y engagement en YouTube. Por favor proporciona todos los siguientes elementos:
 
 ## 1. TÍTULO (50-60 caracteres)
@@ -12,8 +12,10 @@ import (
 	modules "github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
 	services "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/chatgpt"
 	mocks "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/chatgpt/mocks"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/snapshot"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gopkg.in/yaml.v3"
 )
 
 // Mock response for successful SNS content generation
@@ -141,6 +143,13 @@ conclusion: "Format as YAML"`
 		t.Fatal(err)
 	}
 
+	// Create a title history file for few-shot examples
+	titleHistoryPath := filepath.Join(tempDir, "title_history.json")
+	titleHistory := `[{"title": "Low performer", "views": 100}, {"title": "Top episode", "views": 12000, "ctr": 8.5}]`
+	if err := os.WriteFile(titleHistoryPath, []byte(titleHistory), 0644); err != nil {
+		t.Fatal(err)
+	}
+
 	tests := []struct {
 		name           string
 		params         map[string]interface{}
@@ -303,6 +312,32 @@ conclusion: "Format as YAML"`
 			wantErr:       true,
 			errorContains: "permission denied",
 		},
+		{
+			name: "few-shot examples from title history",
+			params: map[string]interface{}{
+				"input":            filepath.Join(inputDir, "transcript.txt"),
+				"output":           outputDir,
+				"language":         "Spanish",
+				"titleHistoryFile": titleHistoryPath,
+				"fewShotCount":     1,
+				"fewShotMetric":    "ctr",
+			},
+			setupMock: func(m *mocks.MockChatGPTServicer) {
+				m.EXPECT().GetContent(
+					mock.Anything,
+					mock.MatchedBy(func(messages []services.ChatMessage) bool {
+						content := messages[1].Content
+						return strings.Contains(content, "1. Top episode (ctr: 8.5)") &&
+							!strings.Contains(content, "Low performer") &&
+							verifyPromptContent(content, "Spanish", "This is a test transcript content.")
+					}),
+					mock.Anything,
+				).Return(mockSuccessResponse, nil)
+			},
+			apiKeySet:      true,
+			wantErr:        false,
+			expectedOutput: filepath.Join(outputDir, "transcript_SNS.yaml"),
+		},
 		{
 			name: "custom prompt file",
 			params: map[string]interface{}{
@@ -773,3 +808,247 @@ func TestGetChatGPTService(t *testing.T) {
 		})
 	}
 }
+
+func TestSuggestSNSModule_Languages(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "test-api-key")
+
+	tempDir := t.TempDir()
+	transcript := "This is a test transcript content."
+	inputPath := filepath.Join(tempDir, "transcript.txt")
+	if err := os.WriteFile(inputPath, []byte(transcript), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// setupMock expects one generation from the transcript and one localization without it
+	setupMock := func(m *mocks.MockChatGPTServicer) {
+		m.EXPECT().GetContent(
+			mock.Anything,
+			mock.MatchedBy(func(messages []services.ChatMessage) bool {
+				return verifyPromptContent(messages[1].Content, "Spanish", transcript)
+			}),
+			mock.Anything,
+		).Return(mockSuccessResponse, nil).Once()
+		m.EXPECT().GetContent(
+			mock.Anything,
+			mock.MatchedBy(func(messages []services.ChatMessage) bool {
+				content := messages[1].Content
+				return strings.Contains(content, "Adáptalo al English") &&
+					strings.Contains(content, "Test Title | Entrevista Exclusiva") &&
+					!strings.Contains(content, transcript)
+			}),
+			mock.Anything,
+		).Return("```yaml\nsns_content_generation:\n  title: \"Test Title | Exclusive Interview\"\n```", nil).Once()
+	}
+
+	t.Run("one section per language", func(t *testing.T) {
+		mockService := mocks.NewMockChatGPTServicer(t)
+		setupMock(mockService)
+
+		result, err := newTestModule(mockService).Execute(context.Background(), map[string]interface{}{
+			"input":     inputPath,
+			"output":    tempDir,
+			"languages": []interface{}{"Spanish", "English", "spanish"},
+		})
+		assert.NoError(t, err)
+		outputPath := filepath.Join(tempDir, "transcript_SNS.yaml")
+		assert.Equal(t, outputPath, result.Outputs["sns_content"])
+		assert.Equal(t, []string{"Spanish", "English"}, result.Metadata["languages"])
+
+		data, err := os.ReadFile(outputPath)
+		assert.NoError(t, err)
+		var sections map[string]map[string]map[string]interface{}
+		assert.NoError(t, yaml.Unmarshal(data, &sections))
+		assert.Equal(t, "Test Title | Entrevista Exclusiva", sections["Spanish"]["sns_content_generation"]["title"])
+		assert.Equal(t, "Test Title | Exclusive Interview", sections["English"]["sns_content_generation"]["title"])
+	})
+
+	t.Run("one file per language", func(t *testing.T) {
+		mockService := mocks.NewMockChatGPTServicer(t)
+		setupMock(mockService)
+
+		result, err := newTestModule(mockService).Execute(context.Background(), map[string]interface{}{
+			"input":          inputPath,
+			"output":         tempDir,
+			"outputFileName": "episode",
+			"languages":      []interface{}{"Spanish", "English"},
+			"splitLanguages": true,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, filepath.Join(tempDir, "episode_spanish.yaml"), result.Outputs["sns_content"])
+		assert.Equal(t, filepath.Join(tempDir, "episode_english.yaml"), result.Outputs["sns_content_english"])
+
+		english, err := os.ReadFile(filepath.Join(tempDir, "episode_english.yaml"))
+		assert.NoError(t, err)
+		assert.Contains(t, string(english), "Exclusive Interview")
+	})
+}
+
+func TestSuggestSNSModule_Strict(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "test-api-key")
+
+	tempDir := t.TempDir()
+	inputPath := filepath.Join(tempDir, "transcript.txt")
+	if err := os.WriteFile(inputPath, []byte("This is a test transcript content."), 0644); err != nil {
+		t.Fatal(err)
+	}
+	invalid := "Here is your content:\ntitle: \"Broken\n  - list"
+
+	mockService := mocks.NewMockChatGPTServicer(t)
+	mockService.EXPECT().GetContent(
+		mock.Anything,
+		mock.MatchedBy(func(messages []services.ChatMessage) bool { return len(messages) == 2 }),
+		mock.Anything,
+	).Return(invalid, nil).Once()
+	mockService.EXPECT().GetContent(
+		mock.Anything,
+		mock.MatchedBy(func(messages []services.ChatMessage) bool {
+			// The invalid response is sent back with the reason it was rejected
+			return len(messages) == 4 &&
+				messages[2].Role == "assistant" && messages[2].Content == invalid &&
+				messages[3].Role == "user" && strings.Contains(messages[3].Content, "not valid YAML")
+		}),
+		mock.Anything,
+	).Return(mockSuccessResponse, nil).Once()
+
+	result, err := newTestModule(mockService).Execute(context.Background(), map[string]interface{}{
+		"input":  inputPath,
+		"output": tempDir,
+		"strict": true,
+	})
+	assert.NoError(t, err)
+	assert.FileExists(t, result.Outputs["sns_content"])
+
+	assert.NoError(t, checkSNSYAML("```yaml\ntitle: Test\n```"))
+	assert.ErrorContains(t, checkSNSYAML("- just\n- a list"), "mapping")
+}
+
+func TestLanguageSlug(t *testing.T) {
+	assert.Equal(t, "brazilian_portuguese", languageSlug(" Brazilian  Portuguese "))
+	assert.Equal(t, "japanese", languageSlug("Japanese"))
+}
+
+func TestSuggestSNSModule_SRTTimeline(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "test-api-key")
+
+	tempDir := t.TempDir()
+	inputPath := filepath.Join(tempDir, "transcript.srt")
+	srt := "1\n00:00:00,000 --> 00:00:05,000\nWelcome to the show.\n\n" +
+		"2\n00:03:10,000 --> 00:03:20,000\nLet's talk about security.\n\n" +
+		"3\n00:09:58,000 --> 00:10:30,000\nThanks for watching.\n"
+	if err := os.WriteFile(inputPath, []byte(srt), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("timeline is aligned to cue starts", func(t *testing.T) {
+		mockService := mocks.NewMockChatGPTServicer(t)
+		mockService.EXPECT().GetContent(
+			mock.Anything,
+			mock.MatchedBy(func(messages []services.ChatMessage) bool {
+				content := messages[1].Content
+				return strings.Contains(content, "[03:10] Let's talk about security.") &&
+					strings.Contains(content, "El video dura 10:30") &&
+					!strings.Contains(content, "-->")
+			}),
+			mock.Anything,
+		).Return("sns_content_generation:\n  title: \"Test\"\n  timeline:\n    - \"00:00 - Intro\"\n    - \"03:15 - Security\"\n    - time: \"10:00\"\n      topic: Goodbye\n", nil).Once()
+
+		result, err := newTestModule(mockService).Execute(context.Background(), map[string]interface{}{
+			"input":  inputPath,
+			"output": tempDir,
+		})
+		assert.NoError(t, err)
+
+		data, err := os.ReadFile(result.Outputs["sns_content"])
+		assert.NoError(t, err)
+		var output struct {
+			SNS struct {
+				Timeline []interface{} `yaml:"timeline"`
+			} `yaml:"sns_content_generation"`
+		}
+		assert.NoError(t, yaml.Unmarshal(data, &output))
+		assert.Equal(t, []interface{}{
+			"00:00 - Intro",
+			"03:10 - Security",
+			map[string]interface{}{"time": "09:58", "topic": "Goodbye"},
+		}, output.SNS.Timeline)
+	})
+
+	t.Run("entries after the end of the video are rejected", func(t *testing.T) {
+		mockService := mocks.NewMockChatGPTServicer(t)
+		mockService.EXPECT().GetContent(mock.Anything, mock.Anything, mock.Anything).
+			Return("timeline:\n  - \"00:00 - Intro\"\n  - \"25:00 - Made up\"\n", nil).Once()
+
+		_, err := newTestModule(mockService).Execute(context.Background(), map[string]interface{}{
+			"input":  inputPath,
+			"output": tempDir,
+		})
+		assert.ErrorContains(t, err, "after the end of the video (10:30)")
+	})
+}
+
+func TestWithSeriesTitles(t *testing.T) {
+	metadata := map[string]interface{}{"episode": 42}
+
+	content, err := withSeriesTitles(mockSuccessResponse, "EP{n}: {title}", metadata)
+	assert.NoError(t, err)
+	var output map[string]map[string]interface{}
+	assert.NoError(t, yaml.Unmarshal([]byte(content), &output))
+	assert.Equal(t, "EP42: Test Title | Entrevista Exclusiva", output["sns_content_generation"]["title"])
+
+	content, err = withSeriesTitles("Not YAML: [", "EP{n}: {title}", metadata)
+	assert.NoError(t, err)
+	assert.Equal(t, "Not YAML: [", content)
+}
+
+func TestWithRelatedEpisodes(t *testing.T) {
+	dir := t.TempDir()
+	related := filepath.Join(dir, "related_episodes.yaml")
+	assert.NoError(t, os.WriteFile(related, []byte("episode: Ep 13\nrelated:\n  - episode: Ep 4 - Analog synths\n    url: https://youtu.be/abc\n    score: 0.81\n  - episode: Ep 9 - Modular\n    score: 0.7\n"), 0644))
+
+	content, err := withRelatedEpisodes(context.Background(), mockSuccessResponse, Params{Output: dir, RelatedEpisodes: related, RelatedLabel: "Episodios relacionados"})
+	assert.NoError(t, err)
+	var output map[string]map[string]interface{}
+	assert.NoError(t, yaml.Unmarshal([]byte(content), &output))
+	assert.True(t, strings.HasSuffix(output["sns_content_generation"]["description"].(string),
+		"\n\nEpisodios relacionados:\n- Ep 4 - Analog synths: https://youtu.be/abc\n- Ep 9 - Modular"))
+
+	// A missing report leaves the response untouched
+	content, err = withRelatedEpisodes(context.Background(), mockSuccessResponse, Params{Output: dir, RelatedEpisodes: filepath.Join(dir, "missing.yaml")})
+	assert.NoError(t, err)
+	assert.Equal(t, mockSuccessResponse, content)
+}
+
+// TestGolden records the prompt built with the default template and the content written from a
+// fixture response; rerun with UPDATE_SNAPSHOTS=1 after an intended change
+func TestGolden(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "test-api-key")
+	tempDir := t.TempDir()
+	inputFile := filepath.Join(tempDir, "transcript.txt")
+	if err := os.WriteFile(inputFile, []byte("[00:00:05] Welcome back to the show.\n[00:00:12] Today we talk about why analog synthesizers sound warm."), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var sent []services.ChatMessage
+	mockService := mocks.NewMockChatGPTServicer(t)
+	mockService.EXPECT().GetContent(mock.Anything, mock.Anything, mock.Anything).
+		Run(func(_ context.Context, messages []services.ChatMessage, _ services.CompletionOptions) {
+			sent = messages
+		}).
+		Return(mockSuccessResponse, nil)
+
+	result, err := newTestModule(mockService).Execute(context.Background(), map[string]interface{}{
+		"input":    inputFile,
+		"output":   filepath.Join(tempDir, "output"),
+		"language": "English",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot.MatchMessages(t, "prompt.txt", sent, tempDir, "<tmp>")
+	data, err := os.ReadFile(result.Outputs["sns_content"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot.Match(t, "transcript_SNS.yaml", string(data), tempDir, "<tmp>")
+}
@@ -0,0 +1,175 @@
+package suggestsnscontent
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+
+	"gopkg.in/yaml.v3"
+)
+
+// timelineTimestamp matches the timestamp at the start of a timeline entry, e.g. "05:32 - Topic"
+var timelineTimestamp = regexp.MustCompile(`^\s*(\d{1,2}:\d{2}(?::\d{2})?)`)
+
+// timedTranscript turns an SRT transcript into one "[mm:ss] text" line per cue, so the model
+// sees the real cue times. Other transcripts are returned unchanged, without cues.
+func timedTranscript(inputPath, transcript string) (string, []utils.SubtitleCue, error) {
+	if !strings.EqualFold(filepath.Ext(inputPath), ".srt") {
+		return transcript, nil, nil
+	}
+
+	cues, err := utils.ParseSRT(transcript)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to parse SRT transcript %s: %w", inputPath, err)
+	}
+
+	duration := utils.SubtitleDuration(cues)
+	var lines strings.Builder
+	for _, cue := range cues {
+		fmt.Fprintf(&lines, "[%s] %s\n", formatTimelineTimestamp(cue.Start, duration), cue.Text)
+	}
+	return lines.String(), cues, nil
+}
+
+// timelinePrompt tells the model to build the timeline from the cue times only
+func timelinePrompt(cues []utils.SubtitleCue) string {
+	duration := utils.SubtitleDuration(cues)
+	return fmt.Sprintf("La transcripción incluye entre corchetes la marca de tiempo real de cada subtítulo. "+
+		"Para el timeline usa únicamente esas marcas de tiempo, sin estimarlas ni calcular partes, "+
+		"con el formato \"%s - Tema\". El video dura %s; ninguna marca puede ser posterior.\n",
+		formatTimelineTimestamp(0, duration), formatTimelineTimestamp(duration, duration))
+}
+
+// checkTimeline verifies that every timeline entry of a response has a timestamp within the video
+func checkTimeline(content string, cues []utils.SubtitleCue) error {
+	duration := utils.SubtitleDuration(cues)
+	for _, entry := range timelineEntries(content) {
+		match := timelineTimestamp.FindStringSubmatch(entry.Value)
+		if match == nil {
+			return fmt.Errorf("timeline entry %q has no timestamp", entry.Value)
+		}
+		at, err := utils.ParseTimestamp(match[1])
+		if err != nil {
+			return fmt.Errorf("timeline entry %q: %w", entry.Value, err)
+		}
+		if at > duration {
+			return fmt.Errorf("timeline entry %q is after the end of the video (%s)", entry.Value, formatTimelineTimestamp(duration, duration))
+		}
+	}
+	return nil
+}
+
+// alignTimeline moves every timeline entry to the start of the cue playing at its timestamp,
+// so chapters begin where a subtitle actually begins. Responses without changes are returned as is.
+func alignTimeline(content string, cues []utils.SubtitleCue) (string, error) {
+	if len(cues) == 0 {
+		return content, nil
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(stripYAMLFence(content)), &doc); err != nil {
+		return content, nil
+	}
+
+	duration := utils.SubtitleDuration(cues)
+	changed := false
+	for _, entry := range collectTimelineEntries(&doc) {
+		match := timelineTimestamp.FindStringSubmatchIndex(entry.Value)
+		if match == nil {
+			continue
+		}
+		at, err := utils.ParseTimestamp(entry.Value[match[2]:match[3]])
+		if err != nil {
+			continue
+		}
+		aligned := formatTimelineTimestamp(cueStartAt(cues, at), duration)
+		if aligned != entry.Value[match[2]:match[3]] {
+			entry.Value = entry.Value[:match[2]] + aligned + entry.Value[match[3]:]
+			changed = true
+		}
+	}
+	if !changed {
+		return content, nil
+	}
+
+	data, err := yaml.Marshal(&doc)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate YAML: %w", err)
+	}
+	return string(data), nil
+}
+
+// timelineEntries returns the timeline entries of a YAML response, if it parses
+func timelineEntries(content string) []*yaml.Node {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(stripYAMLFence(content)), &doc); err != nil {
+		return nil
+	}
+	return collectTimelineEntries(&doc)
+}
+
+// collectTimelineEntries finds the entries of every "timeline" list in a YAML tree. Entries are
+// either strings or mappings with a "time" or "timestamp" key; the node holding the time is returned.
+func collectTimelineEntries(node *yaml.Node) []*yaml.Node {
+	var entries []*yaml.Node
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			entries = append(entries, collectTimelineEntries(child)...)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "timeline" && value.Kind == yaml.SequenceNode {
+				for _, item := range value.Content {
+					if at := timelineEntryTime(item); at != nil {
+						entries = append(entries, at)
+					}
+				}
+				continue
+			}
+			entries = append(entries, collectTimelineEntries(value)...)
+		}
+	}
+	return entries
+}
+
+// timelineEntryTime returns the scalar holding the time of a timeline entry
+func timelineEntryTime(item *yaml.Node) *yaml.Node {
+	switch item.Kind {
+	case yaml.ScalarNode:
+		return item
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(item.Content); i += 2 {
+			if key := item.Content[i].Value; key == "time" || key == "timestamp" {
+				return item.Content[i+1]
+			}
+		}
+	}
+	return nil
+}
+
+// cueStartAt returns the start of the cue playing at a time, or of the last cue before it
+func cueStartAt(cues []utils.SubtitleCue, at time.Duration) time.Duration {
+	var start time.Duration
+	for _, cue := range cues {
+		if cue.Start > at {
+			break
+		}
+		start = cue.Start
+	}
+	return start
+}
+
+// formatTimelineTimestamp formats a time as mm:ss, or hh:mm:ss for videos of an hour or more
+func formatTimelineTimestamp(at, duration time.Duration) string {
+	seconds := int(at / time.Second)
+	if duration >= time.Hour {
+		return fmt.Sprintf("%02d:%02d:%02d", seconds/3600, (seconds/60)%60, seconds%60)
+	}
+	return fmt.Sprintf("%02d:%02d", seconds/60, seconds%60)
+}
@@ -3,11 +3,13 @@ package tiktok
 import (
 	"context"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	modules "github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	chatgpt "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/chatgpt"
 	"github.com/gnzdotmx/studioflowai/studioflowai/internal/services/tiktok"
 	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
 )
@@ -40,6 +42,12 @@ func (m *UploadTikTokShortsModule) GetIO() modules.ModuleIO {
 				Description: "Video privacy status (private, public)",
 				Type:        string(modules.InputTypeData),
 			},
+			{
+				Name:        "decisions",
+				Description: "Review decisions saved from the shorts report",
+				Patterns:    []string{".json"},
+				Type:        string(modules.InputTypeFile),
+			},
 		},
 		ProducedOutputs: []modules.ModuleOutput{
 			{
@@ -54,10 +62,17 @@ func (m *UploadTikTokShortsModule) GetIO() modules.ModuleIO {
 
 // UploadTikTokShortsParams contains the parameters for TikTok shorts upload operations
 type UploadTikTokShortsParams struct {
-	Input            string `json:"input"`            // Path to shorts suggestions YAML file
-	Output           string `json:"output"`           // Path to output directory
-	StoredShortsPath string `json:"storedShortsPath"` // Path where the short videos are stored
-	PrivacyStatus    string `json:"privacyStatus"`    // Video privacy status (private, public)
+	Input            string `json:"input"`                  // Path to shorts suggestions YAML file
+	Output           string `json:"output"`                 // Path to output directory
+	StoredShortsPath string `json:"storedShortsPath"`       // Path where the short videos are stored
+	PrivacyStatus    string `json:"privacyStatus"`          // Video privacy status (private, public)
+	Decisions        string `json:"decisions"`              // Review decisions saved from the shorts report; rejected clips are not uploaded
+	Locale           string `json:"locale"`                 // Language of the account, e.g. "en" or "English"; shorts copy in another language is localized (default: the project account's locale)
+	ShortsLanguage   string `json:"shortsLanguage"`         // Language the shorts file is written in; an account in that language gets the copy as it is
+	SNSContent       string `json:"snsContent"`             // SNS output whose content in the account's language guides the localized wording and hashtags
+	Model            string `json:"model" default:"gpt-4o"` // OpenAI model that localizes the copy (default: "gpt-4o")
+	AllowDuplicates  bool   `json:"allowDuplicates"`        // Upload clips whose title or file was already posted to the account
+	Account          string `json:"account"`                // Account name, for reference in upload previews (default: the project account's username)
 }
 
 // VideoUploadStatus represents the status of a video upload
@@ -129,6 +144,11 @@ func (m *UploadTikTokShortsModule) Name() string {
 	return "uploadtiktokshorts"
 }
 
+// ParamsTemplate returns the module's parameter struct, used to validate and document workflows
+func (m *UploadTikTokShortsModule) ParamsTemplate() interface{} {
+	return UploadTikTokShortsParams{}
+}
+
 // Validate checks if the parameters are valid
 func (m *UploadTikTokShortsModule) Validate(params map[string]interface{}) error {
 	var p UploadTikTokShortsParams
@@ -167,6 +187,10 @@ func (m *UploadTikTokShortsModule) Execute(ctx context.Context, params map[strin
 		return modules.ModuleResult{}, err
 	}
 
+	if utils.PreviewUploads() {
+		return m.previewUploads(ctx, p)
+	}
+
 	// Initialize TikTok service
 	service, err := m.serviceFactory()
 	if err != nil {
@@ -178,53 +202,229 @@ func (m *UploadTikTokShortsModule) Execute(ctx context.Context, params map[strin
 		return modules.ModuleResult{}, fmt.Errorf("failed to initialize TikTok service: %w", err)
 	}
 
+	videoUploads, err := prepareUploads(ctx, p)
+	if err != nil {
+		return modules.ModuleResult{}, err
+	}
+
+	// Look up what the account already has, so a rerun does not post the same clip twice
+	var posted []tiktok.VideoInfo
+	var ledger *utils.UploadLedger
+	if !p.AllowDuplicates {
+		posted, err = service.GetUploadedVideos(ctx)
+		if err != nil {
+			return modules.ModuleResult{}, fmt.Errorf("failed to list uploaded TikTok videos: %w", err)
+		}
+		ledger, err = utils.LoadUploadLedger(utils.PlatformTikTok)
+		if err != nil {
+			return modules.ModuleResult{}, err
+		}
+	}
+
+	utils.LogInfoContext(ctx, "--------------------------------")
+	// Upload each video. A failed upload does not stop the others; it is reported per item so a
+	// retry only uploads the videos that failed.
+	retry := modules.RetryItems(ctx)
+	var items []modules.ItemResult
+	var firstErr error
+	uploaded, skipped, failed := 0, 0, 0
+	for _, upload := range videoUploads {
+		if retry != nil && !retry[upload.FileName] {
+			items = append(items, modules.ItemResult{ID: upload.FileName, Status: modules.ItemSkipped})
+			continue
+		}
+		videoPath := filepath.Join(p.StoredShortsPath, upload.FileName)
+
+		var checksum string
+		if ledger != nil {
+			checksum, err = utils.FileChecksum(videoPath)
+			if err != nil {
+				return modules.ModuleResult{}, fmt.Errorf("failed to read video %s: %w", upload.FileName, err)
+			}
+			if reason := duplicateReason(upload, checksum, posted, ledger); reason != "" {
+				utils.LogInfoContext(ctx, "\t Skipped video: %s (%s)", upload.ShortTitle, reason)
+				skipped++
+				items = append(items, modules.ItemResult{ID: upload.FileName, Status: modules.ItemSkipped})
+				continue
+			}
+		}
+
+		if err := service.UploadVideo(ctx, videoPath, upload.ShortTitle, upload.Description, p.PrivacyStatus, time.Now()); err != nil {
+			if ctx.Err() != nil {
+				return modules.ModuleResult{}, ctx.Err()
+			}
+			err = fmt.Errorf("failed to upload video %s: %w", upload.FileName, err)
+			utils.LogWarningContext(ctx, "\t %v", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			failed++
+			items = append(items, modules.ItemResult{ID: upload.FileName, Status: modules.ItemFailed, Error: err.Error()})
+			continue
+		}
+		utils.LogInfoContext(ctx, "\t Uploaded video: %s", upload.ShortTitle)
+		uploaded++
+		items = append(items, modules.ItemResult{ID: upload.FileName, Status: modules.ItemSucceeded})
+
+		if ledger != nil {
+			if err := ledger.Record(checksum, upload.ShortTitle); err != nil {
+				utils.LogWarningContext(ctx, "Failed to record upload of %s: %v", upload.FileName, err)
+			}
+		}
+	}
+	utils.LogInfoContext(ctx, "--------------------------------")
+
+	// Nothing was uploaded: fail the step rather than report a partial success
+	if failed > 0 && uploaded == 0 && skipped == 0 {
+		return modules.ModuleResult{}, firstErr
+	}
+
+	// Prepare result
+	result := modules.ModuleResult{
+		Outputs: map[string]string{
+			"uploadStatus": fmt.Sprintf("%s/tiktok_upload_status.json", p.Output),
+		},
+		Metadata: map[string]interface{}{
+			"totalVideos": len(videoUploads),
+		},
+		Statistics: map[string]interface{}{
+			"uploadedVideos": uploaded,
+			"skippedVideos":  skipped,
+			"failedVideos":   failed,
+		},
+		Stats: modules.Stats{Items: uploaded},
+		Items: items,
+	}
+
+	return result, nil
+}
+
+// prepareUploads reads the shorts to upload: the accepted clips, with their copy in the account's
+// language and cleaned of formatting TikTok would reject
+func prepareUploads(ctx context.Context, p UploadTikTokShortsParams) ([]VideoUpload, error) {
 	// Read shorts suggestions file
 	shortsData, err := utils.ReadShortsFile(p.Input)
 	if err != nil {
-		return modules.ModuleResult{}, fmt.Errorf("failed to read shorts suggestions file: %w", err)
+		return nil, fmt.Errorf("failed to read shorts suggestions file: %w", err)
+	}
+
+	// Leave out the clips rejected in the shorts report
+	shortsData.Shorts, err = utils.AcceptedShorts(shortsData.Shorts, utils.ResolveOutputPath(p.Decisions, p.Output))
+	if err != nil {
+		return nil, err
+	}
+
+	// Leave out the clips whose title was rated too risky
+	shortsData.Shorts = utils.UnblockedShorts(shortsData.Shorts)
+
+	// Put the copy in the account's language
+	if p.Model == "" {
+		p.Model = "gpt-4o"
+	}
+	shortsData.Shorts, err = utils.LocalizeForAccount(ctx, shortsData.Shorts, utils.ShortsLocale{
+		Locale:         p.Locale,
+		ShortsLanguage: p.ShortsLanguage,
+		SNSContent:     p.SNSContent,
+		OutputDir:      p.Output,
+	}, chatgpt.PromptFunc(p.Model))
+	if err != nil {
+		return nil, err
 	}
 
+	// Clean up model formatting the platform would reject
+	shortsData.Shorts = utils.SanitizeShorts(shortsData.Shorts, utils.PlatformTikTok)
+
 	// Create video uploads from shorts data
 	var videoUploads []VideoUpload
 	for _, short := range shortsData.Shorts {
 		videoUpload := VideoUpload{
-			FileName:    fmt.Sprintf("%s-%s-withtext.mp4", convertToHHMMSS(short.StartTime), convertToHHMMSS(short.EndTime)),
+			FileName:    fmt.Sprintf("%s-%s-withtext.mp4", utils.CompactTimestamp(short.StartTime), utils.CompactTimestamp(short.EndTime)),
 			ShortTitle:  short.ShortTitle,
 			Description: short.Description,
 			Tags:        short.Tags,
 		}
 		videoUploads = append(videoUploads, videoUpload)
 	}
+	return videoUploads, nil
+}
+
+// previewUploads writes the upload requests of the shorts to uploads_preview.yaml without
+// authorizing or calling the TikTok API
+func (m *UploadTikTokShortsModule) previewUploads(ctx context.Context, p UploadTikTokShortsParams) (modules.ModuleResult, error) {
+	videoUploads, err := prepareUploads(ctx, p)
+	if err != nil {
+		return modules.ModuleResult{}, err
+	}
 
-	utils.LogInfo("--------------------------------")
-	// Upload each video
+	preview := utils.UploadPreview{
+		Platform: utils.PlatformTikTok,
+		Source:   p.Input,
+		Target:   utils.UploadTarget{Account: p.Account},
+		Notes: []string{
+			"Videos are sent to the account's inbox as drafts: the title, description and privacy are shown for reference and set when the draft is posted",
+		},
+	}
+	if ws := utils.ActiveWorkspace(); ws != nil {
+		preview.Target.Project = ws.Name
+	}
+	if !p.AllowDuplicates {
+		preview.Notes = append(preview.Notes, "Clips already posted to the account are skipped at upload; the account was not checked")
+	}
 	for _, upload := range videoUploads {
 		videoPath := filepath.Join(p.StoredShortsPath, upload.FileName)
-		if err := service.UploadVideo(ctx, videoPath, upload.ShortTitle, upload.Description, p.PrivacyStatus, time.Now()); err != nil {
-			return modules.ModuleResult{}, fmt.Errorf("failed to upload video %s: %w", upload.FileName, err)
+		var size int64
+		if info, err := os.Stat(videoPath); err == nil {
+			size = info.Size()
 		}
-		utils.LogInfo("\t Uploaded video: %s", upload.ShortTitle)
+		preview.Uploads = append(preview.Uploads, utils.PreviewUpload{
+			File:        videoPath,
+			Title:       upload.ShortTitle,
+			Description: upload.Description,
+			Tags:        splitTags(upload.Tags),
+			Privacy:     p.PrivacyStatus,
+			Request:     tiktok.InitRequestBody(size),
+		})
 	}
-	utils.LogInfo("--------------------------------")
 
-	// Prepare result
-	result := modules.ModuleResult{
+	path, err := utils.WriteUploadPreview(p.Output, preview)
+	if err != nil {
+		return modules.ModuleResult{}, err
+	}
+	utils.LogSuccessContext(ctx, "Previewed %d TikTok upload(s) in %s; nothing was uploaded", len(preview.Uploads), path)
+
+	return modules.ModuleResult{
 		Outputs: map[string]string{
-			"uploadStatus": fmt.Sprintf("%s/tiktok_upload_status.json", p.Output),
+			"uploadsPreview": path,
 		},
 		Metadata: map[string]interface{}{
-			"totalVideos": len(videoUploads),
+			"totalVideos": len(preview.Uploads),
+			"preview":     true,
 		},
-		Statistics: map[string]interface{}{
-			"uploadedVideos": len(videoUploads),
-		},
-	}
+		Stats: modules.Stats{Items: len(preview.Uploads)},
+	}, nil
+}
 
-	return result, nil
+// splitTags turns comma-separated tags into a list
+func splitTags(tags string) []string {
+	var list []string
+	for _, tag := range strings.Split(tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			list = append(list, tag)
+		}
+	}
+	return list
 }
 
-// convertToHHMMSS converts a timestamp to HHMMSS format
-func convertToHHMMSS(timestamp string) string {
-	// Remove colons
-	return strings.ReplaceAll(timestamp, ":", "")
+// duplicateReason tells why a clip counts as already posted: its file is in the upload ledger, or a
+// video on the account has its title. It is empty for a new clip.
+func duplicateReason(upload VideoUpload, checksum string, posted []tiktok.VideoInfo, ledger *utils.UploadLedger) string {
+	if entry, ok := ledger.Find(checksum); ok {
+		return fmt.Sprintf("file already uploaded on %s", entry.UploadTime.Format("2006-01-02"))
+	}
+	for _, video := range posted {
+		if utils.SameTitle(upload.ShortTitle, video.Title) || utils.SameTitle(upload.ShortTitle, video.Description) {
+			return fmt.Sprintf("title already posted as video %s", video.ID)
+		}
+	}
+	return ""
 }
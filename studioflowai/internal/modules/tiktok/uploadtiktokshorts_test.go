@@ -8,8 +8,10 @@ import (
 	"strings"
 	"testing"
 
+	modules "github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
 	"github.com/gnzdotmx/studioflowai/studioflowai/internal/services/tiktok"
 	tiktokmocks "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/tiktok/mocks"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -104,7 +106,7 @@ shorts:
 			convertTimeFormat(short.StartTime),
 			convertTimeFormat(short.EndTime))
 		videoPath := filepath.Join(shortsPath, videoName)
-		if err := os.WriteFile(videoPath, []byte("dummy video data"), 0644); err != nil {
+		if err := os.WriteFile(videoPath, []byte("dummy video data "+short.ShortTitle), 0644); err != nil {
 			t.Fatalf("Failed to create test video file: %v", err)
 		}
 	}
@@ -185,6 +187,10 @@ func TestUploadTikTokShortsModule_Execute_ServiceError(t *testing.T) {
 		return ok && oauthConfig.RedirectURI == "http://localhost:8080/callback"
 	})).Return(nil)
 
+	// Nothing is posted yet
+	t.Setenv("HOME", t.TempDir())
+	mockService.On("GetUploadedVideos", mock.Anything).Return(nil, nil)
+
 	// Mock UploadVideo method
 	mockService.On("UploadVideo",
 		mock.Anything,
@@ -229,6 +235,10 @@ func TestUploadTikTokShortsModule_Execute_Success(t *testing.T) {
 		return ok && oauthConfig.RedirectURI == "http://localhost:8080/callback"
 	})).Return(nil)
 
+	// Nothing is posted yet
+	t.Setenv("HOME", t.TempDir())
+	mockService.On("GetUploadedVideos", mock.Anything).Return(nil, nil)
+
 	// Mock UploadVideo method
 	mockService.On("UploadVideo",
 		mock.Anything,
@@ -261,7 +271,131 @@ func TestUploadTikTokShortsModule_Execute_Success(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestUploadTikTokShortsModule_Execute_SkipsDuplicates(t *testing.T) {
+	inputPath, shortsPath, cleanup := setupTestFiles(t)
+	defer cleanup()
+	t.Setenv("HOME", t.TempDir())
+
+	mockService := tiktokmocks.NewMockService(t)
+	mockService.On("Initialize", mock.Anything).Return(nil)
+	// The first clip was posted by hand, with hashtags after its title
+	mockService.On("GetUploadedVideos", mock.Anything).Return([]tiktok.VideoInfo{
+		{ID: "7300", Description: "test short 1! #test #video"},
+	}, nil)
+	mockService.On("UploadVideo", mock.Anything, mock.Anything, "Test Short 2",
+		mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+
+	module := NewUploadTikTokShortsWithService(func() (tiktok.Service, error) {
+		return mockService, nil
+	})
+	params := map[string]interface{}{
+		"input":            inputPath,
+		"output":           t.TempDir(),
+		"storedShortsPath": shortsPath,
+	}
+
+	result, err := module.Execute(context.Background(), params)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Statistics["uploadedVideos"])
+	assert.Equal(t, 1, result.Statistics["skippedVideos"])
+
+	// A rerun finds the second clip in the upload ledger, even under a new title
+	data, err := os.ReadFile(inputPath)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(inputPath, []byte(strings.Replace(string(data), "Test Short 2", "Renamed Short", 1)), 0644))
+
+	result, err = module.Execute(context.Background(), params)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Statistics["uploadedVideos"])
+	assert.Equal(t, 2, result.Statistics["skippedVideos"])
+
+	// Duplicates can be allowed explicitly
+	mockService.On("UploadVideo", mock.Anything, mock.Anything, mock.Anything,
+		mock.Anything, mock.Anything, mock.Anything).Return(nil).Twice()
+	params["allowDuplicates"] = true
+	result, err = module.Execute(context.Background(), params)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Statistics["uploadedVideos"])
+
+	mockService.AssertExpectations(t)
+}
+
+func TestUploadTikTokShortsModule_Execute_PartialFailure(t *testing.T) {
+	inputPath, shortsPath, cleanup := setupTestFiles(t)
+	defer cleanup()
+	t.Setenv("HOME", t.TempDir())
+
+	mockService := tiktokmocks.NewMockService(t)
+	mockService.On("Initialize", mock.Anything).Return(nil)
+	mockService.On("GetUploadedVideos", mock.Anything).Return(nil, nil)
+	mockService.On("UploadVideo", mock.Anything, mock.Anything, "Test Short 1",
+		mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("rate limited")).Once()
+	mockService.On("UploadVideo", mock.Anything, mock.Anything, "Test Short 2",
+		mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+
+	module := NewUploadTikTokShortsWithService(func() (tiktok.Service, error) {
+		return mockService, nil
+	})
+	params := map[string]interface{}{
+		"input":            inputPath,
+		"output":           t.TempDir(),
+		"storedShortsPath": shortsPath,
+	}
+
+	// The second clip is uploaded even though the first one failed
+	result, err := module.Execute(context.Background(), params)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Statistics["uploadedVideos"])
+	failed := result.FailedItems()
+	assert.Len(t, failed, 1)
+	assert.Contains(t, result.Items[0].Error, "rate limited")
+
+	// A retry only uploads the clip that failed
+	mockService.On("UploadVideo", mock.Anything, mock.Anything, "Test Short 1",
+		mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+	result, err = module.Execute(modules.WithRetryItems(context.Background(), failed), params)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Statistics["uploadedVideos"])
+	assert.Empty(t, result.FailedItems())
+
+	mockService.AssertExpectations(t)
+}
+
 // Helper function to convert time format
 func convertTimeFormat(timestamp string) string {
 	return strings.ReplaceAll(timestamp, ":", "")
 }
+
+func TestUploadTikTokShortsModule_Execute_PreviewUploads(t *testing.T) {
+	inputPath, shortsPath, cleanup := setupTestFiles(t)
+	defer cleanup()
+	utils.SetPreviewUploads(true)
+	defer utils.SetPreviewUploads(false)
+
+	// The API is never authorized or called in preview
+	module := NewUploadTikTokShortsWithService(func() (tiktok.Service, error) {
+		t.Fatal("the TikTok service must not be created in preview")
+		return nil, nil
+	})
+
+	outputDir := t.TempDir()
+	result, err := module.Execute(context.Background(), map[string]interface{}{
+		"input":            inputPath,
+		"output":           outputDir,
+		"storedShortsPath": shortsPath,
+		"privacyStatus":    "public",
+		"account":          "@studio",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(outputDir, utils.UploadsPreviewFile), result.Outputs["uploadsPreview"])
+
+	data, err := os.ReadFile(result.Outputs["uploadsPreview"])
+	assert.NoError(t, err)
+	preview := string(data)
+	assert.Contains(t, preview, "platform: tiktok")
+	assert.Contains(t, preview, "account: '@studio'")
+	assert.Contains(t, preview, "title: Test Short 2")
+	assert.Contains(t, preview, "privacy: public")
+	assert.Contains(t, preview, "source: FILE_UPLOAD")
+	assert.Contains(t, preview, "video_size: 29", "the request carries the size of the video file")
+}
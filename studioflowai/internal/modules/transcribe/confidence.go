@@ -0,0 +1,103 @@
+package transcribe
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+// confidenceReportPath returns where the QC report of a transcript is written
+func confidenceReportPath(outputFile string) string {
+	return strings.TrimSuffix(outputFile, filepath.Ext(outputFile)) + "_qc.yaml"
+}
+
+// transcribeWithConfidence runs Whisper with JSON output, which carries the per-segment log probabilities,
+// then writes the transcript in the requested format and a QC report of its low-confidence regions
+func (m *Module) transcribeWithConfidence(ctx context.Context, filePath, outputFile string, p Params) error {
+	jsonFile := strings.TrimSuffix(outputFile, filepath.Ext(outputFile)) + ".json"
+
+	jsonParams := p
+	jsonParams.OutputFormat = "json"
+	args := m.buildWhisperCommand(filePath, jsonFile, jsonParams)
+	cmd := utils.CommandContext(ctx, p.Model, args...)
+	cmd.Stdout = utils.LogWriter(ctx)
+	cmd.Stderr = os.Stderr
+	if err := utils.RunWatched(ctx, cmd); err != nil {
+		return err
+	}
+
+	// Whisper names its output after the input file
+	base := filepath.Base(filePath)
+	written := filepath.Join(filepath.Dir(jsonFile), strings.TrimSuffix(base, filepath.Ext(base))+".json")
+	if written != jsonFile {
+		if err := os.Rename(written, jsonFile); err != nil {
+			return fmt.Errorf("confidence needs Whisper's JSON output (remove --output_format from whisperParams): %w", err)
+		}
+	}
+
+	return writeConfidenceOutputs(ctx, filePath, jsonFile, outputFile, p)
+}
+
+// writeConfidenceOutputs converts Whisper's JSON segments to the requested transcript format and writes the QC report
+func writeConfidenceOutputs(ctx context.Context, source, jsonFile, outputFile string, p Params) error {
+	segments, err := utils.ReadWhisperSegments(jsonFile)
+	if err != nil {
+		return err
+	}
+
+	// Audio extracted from a range of the source video starts at the start of the range
+	if timeRange, err := utils.ParseTimeRange(p.StartTime, p.EndTime); err == nil && timeRange.Start > 0 {
+		for i := range segments {
+			segments[i].Start += timeRange.Start.Seconds()
+			segments[i].End += timeRange.Start.Seconds()
+		}
+	}
+
+	if p.OutputFormat != "json" {
+		if err := utils.WriteTextFile(outputFile, renderSegments(segments, p.OutputFormat)); err != nil {
+			return fmt.Errorf("failed to write transcript: %w", err)
+		}
+	}
+
+	return writeConfidenceReport(ctx, source, segments, outputFile, p)
+}
+
+// writeConfidenceReport writes the QC report of a transcript's segments next to the transcript
+func writeConfidenceReport(ctx context.Context, source string, segments []utils.WhisperSegment, outputFile string, p Params) error {
+	report := utils.BuildConfidenceReport(source, segments, p.ConfidenceThreshold)
+	reportPath := confidenceReportPath(outputFile)
+	if err := utils.WriteConfidenceReport(reportPath, report); err != nil {
+		return err
+	}
+	utils.LogInfoContext(ctx, "Transcript confidence %.0f%%: %d of %d segments flagged in %d regions (%s)",
+		report.AverageConfidence*100, report.LowConfidenceSegments, report.Segments, len(report.Regions), reportPath)
+	return nil
+}
+
+// renderSegments writes Whisper segments as an srt, vtt or txt transcript
+func renderSegments(segments []utils.WhisperSegment, format string) string {
+	var b strings.Builder
+	if format == "vtt" {
+		b.WriteString("WEBVTT\n\n")
+	}
+	for i, segment := range segments {
+		text := strings.TrimSpace(segment.Text)
+		start := time.Duration(segment.Start * float64(time.Second))
+		end := time.Duration(segment.End * float64(time.Second))
+		switch format {
+		case "srt":
+			fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", i+1, utils.FormatSRTTimestamp(start), utils.FormatSRTTimestamp(end), text)
+		case "vtt":
+			fmt.Fprintf(&b, "%s --> %s\n%s\n\n", strings.Replace(utils.FormatSRTTimestamp(start), ",", ".", 1),
+				strings.Replace(utils.FormatSRTTimestamp(end), ",", ".", 1), text)
+		default:
+			b.WriteString(text + "\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,88 @@
+package transcribe
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"runtime"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+// embeddedSampleRate is the sample rate whisper.cpp expects its audio in
+const embeddedSampleRate = 16000
+
+// embeddedOptions configures an in-process whisper.cpp transcription
+type embeddedOptions struct {
+	ModelFile string // ggml model file
+	Language  string // Language code, or "auto" to detect it
+	Threads   int    // CPU threads used next to the GPU
+}
+
+// transcribeEmbedded transcribes a file with the whisper.cpp library linked into the binary,
+// which runs on Metal on Apple Silicon, and writes the transcript and QC report like the CLI models
+func (m *Module) transcribeEmbedded(ctx context.Context, filePath, outputFile string, p Params) error {
+	if !embeddedWhisperAvailable {
+		return errEmbeddedUnavailable
+	}
+	if p.ModelFile == "" {
+		return fmt.Errorf("modelFile is required for the whisper-cpp model")
+	}
+	modelFile, err := utils.ExpandHomeDir(p.ModelFile)
+	if err != nil {
+		return err
+	}
+
+	samples, err := decodeSamples(ctx, filePath)
+	if err != nil {
+		return err
+	}
+	utils.LogVerboseContext(ctx, "Transcribing %.0f seconds of audio in-process with %s", float64(len(samples))/embeddedSampleRate, modelFile)
+
+	segments, err := whisperEmbedded(ctx, samples, embeddedOptions{
+		ModelFile: modelFile,
+		Language:  p.Language,
+		Threads:   min(runtime.NumCPU(), 8),
+	})
+	if err != nil {
+		return err
+	}
+
+	if p.OutputFormat == "json" {
+		data, err := json.MarshalIndent(map[string]interface{}{"segments": segments}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode transcript: %w", err)
+		}
+		if err := utils.WriteTextFile(outputFile, string(data)); err != nil {
+			return fmt.Errorf("failed to write transcript: %w", err)
+		}
+	} else if err := utils.WriteTextFile(outputFile, renderSegments(segments, p.OutputFormat)); err != nil {
+		return fmt.Errorf("failed to write transcript: %w", err)
+	}
+
+	if p.Confidence {
+		return writeConfidenceReport(ctx, filePath, segments, outputFile, p)
+	}
+	return nil
+}
+
+// decodeSamples decodes an audio file to the 16 kHz mono float samples whisper.cpp reads
+func decodeSamples(ctx context.Context, filePath string) ([]float32, error) {
+	cmd := utils.CommandContext(ctx, "ffmpeg", "-v", "error", "-i", filePath,
+		"-f", "f32le", "-ac", "1", "-ar", fmt.Sprint(embeddedSampleRate), "-")
+	data, err := utils.OutputWatched(ctx, cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", filePath, err)
+	}
+	if len(data) < 4 {
+		return nil, fmt.Errorf("no audio decoded from %s", filePath)
+	}
+
+	samples := make([]float32, len(data)/4)
+	for i := range samples {
+		samples[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+	}
+	return samples, nil
+}
@@ -0,0 +1,152 @@
+package transcribe
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+// defaultWhisperParams are the Whisper arguments used when neither whisperParams nor a profile is set
+const defaultWhisperParams = "--model large-v2 --beam_size 5 --temperature 0.0 --best_of 5 --word_timestamps True --threads 16 --patience 1.0 --condition_on_previous_text True"
+
+// defaultProfile is the profile key used when no profile matches the language
+const defaultProfile = "default"
+
+// detectedLanguagePattern matches the language reported by whisper ("Detected language: Japanese")
+// and by whisper-cli ("auto-detected language: ja (p = 0.98)")
+var detectedLanguagePattern = regexp.MustCompile(`(?i)detected language:\s*([A-Za-z]+)`)
+
+// languageNames maps Whisper language codes to the names Whisper prints, so profiles can be
+// keyed by either
+var languageNames = map[string]string{
+	"ar": "arabic", "de": "german", "en": "english", "es": "spanish", "fr": "french",
+	"hi": "hindi", "it": "italian", "ja": "japanese", "ko": "korean", "nl": "dutch",
+	"pl": "polish", "pt": "portuguese", "ru": "russian", "tr": "turkish", "uk": "ukrainian",
+	"zh": "chinese",
+}
+
+// languageCode returns the Whisper code of a language given by code or name
+func languageCode(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if _, ok := languageNames[language]; ok {
+		return language
+	}
+	for code, name := range languageNames {
+		if name == language {
+			return code
+		}
+	}
+	return language
+}
+
+// whisperProfile returns the arguments of the profile for a language, falling back to the
+// default profile; profile keys are language codes or names
+func whisperProfile(profiles map[string]string, language string) (string, bool) {
+	code := languageCode(language)
+	for key, args := range profiles {
+		if language != "" && languageCode(key) == code {
+			return args, true
+		}
+	}
+	for key, args := range profiles {
+		if strings.EqualFold(key, defaultProfile) {
+			return args, false
+		}
+	}
+	return "", false
+}
+
+// withWhisperProfile fills in the Whisper arguments for one file. Explicit whisperParams win;
+// otherwise the profile of the language is used, detecting the language first when it is auto
+// and profiles exist for specific languages.
+func (m *Module) withWhisperProfile(ctx context.Context, filePath string, p Params) Params {
+	if p.WhisperParams != "" {
+		return p
+	}
+
+	language := p.Language
+	if (language == "" || language == "auto") && hasLanguageProfiles(p.WhisperProfiles) {
+		if detected := m.detectLanguage(ctx, filePath, p); detected != "" {
+			utils.LogInfoContext(ctx, "Detected language %s in %s", detected, filepath.Base(filePath))
+			language = languageCode(detected)
+			p.Language = language
+		}
+	}
+
+	args, matched := whisperProfile(p.WhisperProfiles, language)
+	switch {
+	case matched:
+		utils.LogVerboseContext(ctx, "Using whisper profile for %s", language)
+	case args == "":
+		args = defaultWhisperParams
+	}
+	p.WhisperParams = args
+	return p
+}
+
+// hasLanguageProfiles reports whether any profile targets a specific language
+func hasLanguageProfiles(profiles map[string]string) bool {
+	for key := range profiles {
+		if !strings.EqualFold(key, defaultProfile) {
+			return true
+		}
+	}
+	return false
+}
+
+// detectLanguage runs a short language detection pass and returns the language reported by the
+// model, or "" when it cannot be detected
+func (m *Module) detectLanguage(ctx context.Context, filePath string, p Params) string {
+	var args []string
+	switch p.Model {
+	case "whisper":
+		tempDir, err := utils.MakeTempDir("", "detect")
+		if err != nil {
+			utils.LogWarningContext(ctx, "Language detection skipped: %v", err)
+			return ""
+		}
+		defer func() {
+			if err := os.RemoveAll(tempDir); err != nil {
+				utils.LogWarningContext(ctx, "Failed to remove temp directory: %v", err)
+			}
+		}()
+		// Whisper detects the language from the first 30 seconds
+		args = []string{filePath, "--model", p.DetectModel, "--clip_timestamps", "0,30",
+			"--output_dir", tempDir, "--output_format", "txt", "--verbose", "False"}
+	case "whisper-cli":
+		args = []string{"--detect-language", filePath}
+		// whisper-cli needs the model file of the default profile
+		defaults, _ := whisperProfile(p.WhisperProfiles, "")
+		fields := strings.Fields(defaults)
+		for i := 0; i+1 < len(fields); i++ {
+			if fields[i] == "-m" || fields[i] == "--model" {
+				args = append([]string{fields[i], fields[i+1]}, args...)
+			}
+		}
+	default:
+		return ""
+	}
+
+	output, err := m.cmdExecutor.ExecuteCommand(ctx, p.Model, args)
+	if err != nil {
+		utils.LogWarningContext(ctx, "Language detection failed, using the default whisper profile: %v", err)
+		return ""
+	}
+	match := detectedLanguagePattern.FindStringSubmatch(string(output))
+	if match == nil {
+		utils.LogWarningContext(ctx, "Language detection reported no language, using the default whisper profile")
+		return ""
+	}
+	return match[1]
+}
+
+// DetectLanguage returns the language whisper's detectModel hears in the first 30 seconds of an
+// audio file, as whisper names it (e.g. "Spanish"), or "" when it cannot be detected
+func DetectLanguage(ctx context.Context, filePath, detectModel string) string {
+	m := &Module{cmdExecutor: &RealCommandExecutor{}}
+	return m.detectLanguage(ctx, filePath, Params{Model: "whisper", DetectModel: detectModel})
+}
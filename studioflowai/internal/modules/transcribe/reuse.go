@@ -0,0 +1,146 @@
+package transcribe
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+// Values of the reuseExisting parameter
+const (
+	ReuseNever  = "false"  // Always transcribe
+	ReuseAlways = "true"   // Use an existing transcript without checking it
+	ReuseVerify = "verify" // Use an existing transcript when it covers the media duration
+)
+
+// reuseMode normalizes the reuseExisting parameter, which may be written as a boolean or a string
+func reuseMode(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return ReuseNever, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case string:
+		mode := strings.ToLower(strings.TrimSpace(v))
+		switch mode {
+		case "":
+			return ReuseNever, nil
+		case ReuseNever, ReuseAlways, ReuseVerify:
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported reuseExisting value %v (supported: true, false, verify)", value)
+}
+
+// existingTranscript returns a transcript in the output format found next to the audio input or
+// the source video, such as video.srt or video.en.srt, or "" when there is none
+func existingTranscript(filePath string, p Params) string {
+	ext := "." + p.OutputFormat
+	sources := []string{filePath}
+	if p.VideoFile != "" {
+		sources = append(sources, utils.ResolveOutputPath(p.VideoFile, p.Output))
+	}
+
+	for _, source := range sources {
+		base := strings.TrimSuffix(source, filepath.Ext(source))
+		if info, err := os.Stat(base + ext); err == nil && info.Mode().IsRegular() {
+			return base + ext
+		}
+		// Subtitles published with a language suffix, e.g. video.en.srt
+		matches, err := filepath.Glob(escapeGlob(base) + ".*" + ext)
+		if err == nil && len(matches) > 0 {
+			sortNaturally(matches)
+			return matches[0]
+		}
+	}
+	return ""
+}
+
+// escapeGlob escapes the glob metacharacters of a path
+func escapeGlob(path string) string {
+	replacer := strings.NewReplacer(`*`, `\*`, `?`, `\?`, `[`, `\[`, `\`, `\\`)
+	return replacer.Replace(path)
+}
+
+// reuseExistingTranscript copies an existing transcript to outputFile instead of transcribing,
+// according to the reuseExisting mode. It reports whether transcription can be skipped.
+func (m *Module) reuseExistingTranscript(ctx context.Context, filePath, outputFile string, p Params) (bool, error) {
+	mode, err := reuseMode(p.ReuseExisting)
+	if err != nil {
+		return false, err
+	}
+	if mode == ReuseNever {
+		return false, nil
+	}
+
+	transcript := existingTranscript(filePath, p)
+	if transcript == "" {
+		utils.LogVerboseContext(ctx, "No existing %s transcript found for %s", p.OutputFormat, filepath.Base(filePath))
+		return false, nil
+	}
+
+	if mode == ReuseVerify {
+		if err := m.verifyCoverage(ctx, filePath, transcript, p); err != nil {
+			utils.LogWarningContext(ctx, "Not reusing %s: %v", transcript, err)
+			return false, nil
+		}
+	}
+
+	if transcript != outputFile {
+		if err := copyFile(transcript, outputFile); err != nil {
+			return false, fmt.Errorf("failed to copy existing transcript: %w", err)
+		}
+	}
+	utils.LogSuccessContext(ctx, "Reused existing transcript %s, skipping transcription of %s", transcript, filepath.Base(filePath))
+	return true, nil
+}
+
+// verifyCoverage checks that the cues of a transcript span the media: the last cue must end after
+// reuseCoverage of the duration and not well past its end, which points to another recording
+func (m *Module) verifyCoverage(ctx context.Context, filePath, transcript string, p Params) error {
+	if p.OutputFormat != "srt" && p.OutputFormat != "vtt" {
+		return fmt.Errorf("only srt and vtt transcripts can be verified")
+	}
+
+	content, err := os.ReadFile(transcript)
+	if err != nil {
+		return fmt.Errorf("failed to read transcript: %w", err)
+	}
+	cues, err := utils.ParseSRT(string(content))
+	if err != nil {
+		return err
+	}
+
+	duration, err := m.mediaDuration(ctx, filePath)
+	if err != nil {
+		return err
+	}
+
+	covered := utils.SubtitleDuration(cues)
+	if covered < time.Duration(float64(duration)*p.ReuseCoverage) {
+		return fmt.Errorf("it covers %s of %s", utils.FormatTimestamp(covered), utils.FormatTimestamp(duration))
+	}
+	if covered > duration+duration/50+time.Second {
+		return fmt.Errorf("it ends at %s, after the %s media ends", utils.FormatTimestamp(covered), utils.FormatTimestamp(duration))
+	}
+	return nil
+}
+
+// mediaDuration reads the duration of an audio or video file with ffprobe
+func (m *Module) mediaDuration(ctx context.Context, path string) (time.Duration, error) {
+	out, err := m.cmdExecutor.ExecuteCommand(ctx, "ffprobe", []string{"-v", "error", "-show_entries", "format=duration", "-of", "csv=p=0", path})
+	if err != nil {
+		return 0, fmt.Errorf("failed to read media duration: %w", err)
+	}
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(string(out)), 64)
+	if err != nil || seconds <= 0 {
+		return 0, fmt.Errorf("failed to read media duration from ffprobe output %q", strings.TrimSpace(string(out)))
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
@@ -3,7 +3,6 @@ package transcribe
 import (
 	"context"
 	"fmt"
-	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -30,8 +29,8 @@ type CommandExecutor interface {
 type RealCommandExecutor struct{}
 
 func (e *RealCommandExecutor) ExecuteCommand(ctx context.Context, name string, args []string) ([]byte, error) {
-	cmd := exec.CommandContext(ctx, name, args...)
-	return cmd.CombinedOutput()
+	cmd := utils.CommandContext(ctx, name, args...)
+	return utils.CombinedOutputWatched(ctx, cmd)
 }
 
 func (e *RealCommandExecutor) LookPath(file string) (string, error) {
@@ -45,15 +44,39 @@ type Module struct {
 
 // Params contains the parameters for audio transcription
 type Params struct {
-	Input          string `json:"input"`          // Path to input audio file
-	Output         string `json:"output"`         // Path to output directory
-	Model          string `json:"model"`          // Transcription model to use (default: "whisper")
-	Language       string `json:"language"`       // Language for transcription (default: "auto")
-	OutputFormat   string `json:"outputFormat"`   // Output format (default: "txt")
-	WhisperParams  string `json:"whisperParams"`  // Additional parameters for Whisper CLI
-	OutputFileName string `json:"outputFileName"` // Custom output file name (without extension)
+	Input          string `json:"input"`                      // Path to input audio file
+	Output         string `json:"output"`                     // Path to output directory
+	Model          string `json:"model" default:"whisper"`    // Transcription model to use (default: "whisper")
+	Language       string `json:"language" default:"auto"`    // Language for transcription (default: "auto")
+	OutputFormat   string `json:"outputFormat" default:"txt"` // Output format (default: "txt")
+	WhisperParams  string `json:"whisperParams"`              // Additional parameters for Whisper CLI
+	OutputFileName string `json:"outputFileName"`             // Custom output file name (without extension)
+	ModelFile      string `json:"modelFile"`                  // ggml model file used by the embedded whisper-cpp model
+
+	Include []string `json:"include"` // Patterns of the files to transcribe when input is a directory (default: "*.wav")
+	Exclude []string `json:"exclude"` // Patterns of input directory files to leave out
+
+	WhisperProfiles map[string]string `json:"whisperProfiles"`            // Whisper parameters per language code or name, plus "default"; used when whisperParams is not set
+	DetectModel     string            `json:"detectModel" default:"tiny"` // Whisper model used to detect the language for profiles when language is auto (default: "tiny")
+
+	ReuseExisting interface{} `json:"reuseExisting" default:"false"` // Use a transcript found next to the input or source video: true, false or verify (default: false)
+	ReuseCoverage float64     `json:"reuseCoverage" default:"0.9"`   // Share of the media duration a reused transcript must cover in verify mode (default: 0.9)
+	VideoFile     string      `json:"videoFile"`                     // Source video, whose sibling transcript (video.srt, video.en.srt) can be reused
+
+	Confidence          bool    `json:"confidence"`                         // Capture per-segment confidence from Whisper's JSON output and write a QC report (default: false)
+	ConfidenceThreshold float64 `json:"confidenceThreshold" default:"-1.0"` // avg_logprob below which a segment is flagged as low confidence (default: -1.0)
+
+	MemoryThreshold float64 `json:"memoryThreshold" default:"90"` // Percent of system memory in use above which whisper-cli waits before the next segment (default: 90)
+
+	TranscriptFixes []utils.TranscriptFix `json:"transcriptFixes"` // Regex, dictionary, casing and number fixes run on the transcript after transcription, in order
+
+	StartTime string `json:"startTime"` // Time of the source video the audio starts at, when it was extracted from a range; cues are shifted to source time
+	EndTime   string `json:"endTime"`   // End of the range of the source video; cues after it are dropped
 }
 
+// defaultInclude selects the audio files of a directory input
+var defaultInclude = []string{"*.wav"}
+
 // New creates a new transcribe module
 func New() modules.Module {
 	return &Module{
@@ -73,6 +96,11 @@ func (m *Module) Name() string {
 	return "transcribe"
 }
 
+// ParamsTemplate returns the module's parameter struct, used to validate and document workflows
+func (m *Module) ParamsTemplate() interface{} {
+	return Params{}
+}
+
 // Validate checks if the parameters are valid
 func (m *Module) Validate(params map[string]interface{}) error {
 	var p Params
@@ -80,9 +108,16 @@ func (m *Module) Validate(params map[string]interface{}) error {
 		return err
 	}
 
-	// Validate input path
-	if err := utils.ValidateInputPath(p.Input, p.Output, ""); err != nil {
-		return err
+	// Validate input path; an input produced by an earlier step does not exist yet
+	fromStep := modules.FromStep(params)
+	if fromStep == "" {
+		if err := utils.ValidateInputPath(p.Input, p.Output, ""); err != nil {
+			return err
+		}
+	} else if p.Input == "" {
+		return fmt.Errorf("input: input path is required")
+	} else {
+		utils.LogVerbose("Note: Input file %s will be created by step %s", p.Input, fromStep)
 	}
 
 	// Validate output path
@@ -90,19 +125,13 @@ func (m *Module) Validate(params map[string]interface{}) error {
 		return err
 	}
 
-	// During validation, we don't check file existence for input files inside an output directory,
-	// as they'll be created during workflow execution.
-	if strings.Contains(p.Input, "output") ||
-		strings.Contains(p.Input, "/audio.wav") ||
-		filepath.Base(p.Input) == "audio.wav" {
-		// Skip file existence check for expected output files from previous steps
-		utils.LogVerbose("Note: Input file %s will be created by a previous step", p.Input)
-		return nil
+	if err := utils.NewFileFilter(p.Include, p.Exclude, defaultInclude).Validate(); err != nil {
+		return err
 	}
 
 	// Validate audio file extension if input is a file
 	fileInfo, err := os.Stat(p.Input)
-	if err == nil && !fileInfo.IsDir() {
+	if fromStep == "" && err == nil && !fileInfo.IsDir() {
 		if err := utils.ValidateFileExtension(p.Input, []string{".wav", ".mp3", ".m4a", ".aac"}); err != nil {
 			return err
 		}
@@ -123,12 +152,47 @@ func (m *Module) Validate(params map[string]interface{}) error {
 		if _, err := m.cmdExecutor.LookPath("whisper-cli"); err != nil {
 			utils.LogWarning("whisper-cli not found in PATH; transcription module will look for existing transcription files instead")
 		}
+	case "whisper-cpp":
+		if !embeddedWhisperAvailable {
+			return errEmbeddedUnavailable
+		}
+		if p.ModelFile == "" {
+			return fmt.Errorf("modelFile is required for the whisper-cpp model")
+		}
 	case "external":
 		// External model is allowed but doesn't need validation
 	default:
 		return fmt.Errorf("unsupported transcription model: %s", p.Model)
 	}
 
+	// Validate transcript reuse
+	if _, err := reuseMode(p.ReuseExisting); err != nil {
+		return err
+	}
+	if p.ReuseCoverage < 0 || p.ReuseCoverage > 1 {
+		return fmt.Errorf("reuseCoverage must be between 0 and 1, got %v", p.ReuseCoverage)
+	}
+
+	if p.MemoryThreshold < 0 || p.MemoryThreshold > 100 {
+		return fmt.Errorf("memoryThreshold must be between 0 and 100, got %v", p.MemoryThreshold)
+	}
+
+	if _, err := utils.CompileTranscriptFixes(p.TranscriptFixes); err != nil {
+		return err
+	}
+
+	if _, err := utils.ParseTimeRange(p.StartTime, p.EndTime); err != nil {
+		return err
+	}
+
+	// Log probabilities are never positive
+	if p.ConfidenceThreshold > 0 {
+		return fmt.Errorf("confidenceThreshold is an average log probability and must not be positive, got %v", p.ConfidenceThreshold)
+	}
+	if p.Confidence && p.Model == "whisper-cli" {
+		utils.LogWarning("confidence is only captured with the whisper and whisper-cpp models; no QC report will be written")
+	}
+
 	// Validate output format
 	if p.OutputFormat != "" {
 		validFormats := map[string]bool{
@@ -160,13 +224,25 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 		p.OutputFormat = "srt" // Default to SRT instead of TXT
 	}
 
-	// Set default Whisper parameters if none provided
-	if p.WhisperParams == "" {
-		p.WhisperParams = "--model large-v2 --beam_size 5 --temperature 0.0 --best_of 5 --word_timestamps True --threads 16 --patience 1.0 --condition_on_previous_text True"
+	if p.DetectModel == "" {
+		p.DetectModel = "tiny"
+	}
+	if p.ReuseCoverage == 0 {
+		p.ReuseCoverage = 0.9
+	}
+	if p.ConfidenceThreshold == 0 {
+		p.ConfidenceThreshold = utils.DefaultConfidenceThreshold
+	}
+	if p.MemoryThreshold == 0 {
+		p.MemoryThreshold = 90
+	}
+	// Only whisper and the embedded whisper.cpp report per-segment log probabilities
+	if p.Model != "whisper" && p.Model != "whisper-cpp" {
+		p.Confidence = false
 	}
 
 	// Create output directory if it doesn't exist
-	if err := os.MkdirAll(p.Output, 0755); err != nil {
+	if err := utils.EnsureDir(p.Output); err != nil {
 		return modules.ModuleResult{}, fmt.Errorf("failed to create output directory: %w", err)
 	}
 
@@ -179,8 +255,8 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 
 	// If the model isn't installed, look for existing transcription files
 	if !modelInstalled {
-		utils.LogWarning("Transcription model not available, looking for existing transcription files")
-		if err := m.findExistingTranscripts(p); err != nil {
+		utils.LogWarningContext(ctx, "Transcription model not available, looking for existing transcription files")
+		if err := m.findExistingTranscripts(ctx, p); err != nil {
 			return modules.ModuleResult{}, err
 		}
 		return modules.ModuleResult{}, nil
@@ -190,7 +266,7 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 	resolvedInput := utils.ResolveOutputPath(p.Input, p.Output)
 
 	// Log the exact path we're looking for
-	utils.LogVerbose("Looking for input file: %s", resolvedInput)
+	utils.LogVerboseContext(ctx, "Looking for input file: %s", resolvedInput)
 
 	// Check if input is a directory or a file
 	fileInfo, err := os.Stat(resolvedInput)
@@ -206,10 +282,10 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 		}
 
 		for _, altPath := range altPaths {
-			utils.LogDebug("Trying alternative path: %s", altPath)
+			utils.LogDebugContext(ctx, "Trying alternative path: %s", altPath)
 			if fileInfo, err = os.Stat(altPath); err == nil {
 				resolvedInput = altPath
-				utils.LogVerbose("Found input file at: %s", altPath)
+				utils.LogVerboseContext(ctx, "Found input file at: %s", altPath)
 				break
 			}
 		}
@@ -249,6 +325,17 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 			"format":   p.OutputFormat,
 			"language": p.Language,
 		},
+		Stats: modules.Stats{Items: 1},
+	}
+
+	// Reused transcripts have no confidence data, so the report may not exist
+	if p.Confidence {
+		reportPath := confidenceReportPath(filepath.Join(p.Output, outputFile))
+		if report, err := utils.ReadConfidenceReport(reportPath); err == nil {
+			result.Outputs["qcReport"] = reportPath
+			result.Metadata["averageConfidence"] = report.AverageConfidence
+			result.Metadata["lowConfidenceRegions"] = len(report.Regions)
+		}
 	}
 
 	return result, nil
@@ -256,13 +343,14 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 
 // processDirectory processes all matching audio files in a directory
 func (m *Module) processDirectory(ctx context.Context, p Params) error {
-	entries, err := filepath.Glob(filepath.Join(p.Input, "*.wav"))
+	filter := utils.NewFileFilter(p.Include, p.Exclude, defaultInclude)
+	entries, err := filter.Files(p.Input)
 	if err != nil {
-		return fmt.Errorf("failed to glob input files: %w", err)
+		return err
 	}
 
 	if len(entries) == 0 {
-		return fmt.Errorf("no matching files found for pattern *.wav")
+		return fmt.Errorf("no matching files found for %s", filter)
 	}
 
 	for _, entry := range entries {
@@ -274,7 +362,7 @@ func (m *Module) processDirectory(ctx context.Context, p Params) error {
 	return nil
 }
 
-// processFile transcribes a single audio file
+// processFile transcribes a single audio file and applies the transcript fixes to the result
 func (m *Module) processFile(ctx context.Context, filePath string, p Params) error {
 	filename := filepath.Base(filePath)
 	baseName := filename[:len(filename)-len(filepath.Ext(filename))]
@@ -288,19 +376,70 @@ func (m *Module) processFile(ctx context.Context, filePath string, p Params) err
 	// Match the original script's output naming convention - keep the same base filename
 	outputFile := filepath.Join(p.Output, outputBaseName+"."+p.OutputFormat)
 
-	utils.LogVerbose("Transcribing %s to %s", filePath, outputFile)
+	if err := m.transcribeFile(ctx, filePath, baseName, outputFile, p); err != nil {
+		return err
+	}
+	return fixTranscript(ctx, outputFile, p)
+}
+
+// fixTranscript runs the transcript fixes on a transcript, which cost nothing compared with
+// leaving systematic errors to the model
+func fixTranscript(ctx context.Context, outputFile string, p Params) error {
+	if len(p.TranscriptFixes) == 0 {
+		return nil
+	}
+	if p.OutputFormat == "json" {
+		utils.LogWarningContext(ctx, "transcriptFixes are not applied to JSON transcripts")
+		return nil
+	}
+	fixer, err := utils.CompileTranscriptFixes(p.TranscriptFixes)
+	if err != nil {
+		return err
+	}
+	changed, err := utils.FixTranscriptFile(outputFile, fixer)
+	if err != nil {
+		return err
+	}
+	utils.LogVerboseContext(ctx, "Transcript fixes changed %d line(s) of %s", changed, outputFile)
+	return nil
+}
+
+// transcribeFile writes the transcript of a single audio file to outputFile
+func (m *Module) transcribeFile(ctx context.Context, filePath, baseName, outputFile string, p Params) error {
+	// Skip whisper when a transcript of this recording already exists. It covers the whole
+	// recording, so it is only cut to the range.
+	if reused, err := m.reuseExistingTranscript(ctx, filePath, outputFile, p); err != nil || reused {
+		if err != nil {
+			return err
+		}
+		return applyTimeRange(ctx, outputFile, false, p)
+	}
+
+	utils.LogVerboseContext(ctx, "Transcribing %s to %s", filePath, outputFile)
+
+	// Pick the Whisper arguments for the language of this file
+	p = m.withWhisperProfile(ctx, filePath, p)
 
 	var err error
 	switch p.Model {
 	case "whisper":
+		if p.Confidence {
+			if err := m.transcribeWithConfidence(ctx, filePath, outputFile, p); err != nil {
+				return fmt.Errorf("transcription command failed: %w", err)
+			}
+			utils.LogSuccessContext(ctx, "Successfully transcribed %s", filePath)
+			return nil
+		}
 		args := m.buildWhisperCommand(filePath, outputFile, p)
-		cmd := exec.CommandContext(ctx, p.Model, args...)
-		cmd.Stdout = os.Stdout
+		cmd := utils.CommandContext(ctx, p.Model, args...)
+		cmd.Stdout = utils.LogWriter(ctx)
 		cmd.Stderr = os.Stderr
-		err = cmd.Run()
+		err = utils.RunWatched(ctx, cmd)
 	case "whisper-cli":
 		// For whisper-cli, use the splitting workflow
 		err = m.processWhisperCliWithSplitting(ctx, filePath, outputFile, p)
+	case "whisper-cpp":
+		err = m.transcribeEmbedded(ctx, filePath, outputFile, p)
 	default:
 		return fmt.Errorf("unsupported transcription model: %s", p.Model)
 	}
@@ -318,14 +457,14 @@ func (m *Module) processFile(ctx context.Context, filePath string, p Params) err
 			// If there's a different file than what we expect, rename it
 			for _, match := range matches {
 				if match != outputFile {
-					utils.LogVerbose("Found additional output file: %s, moving to %s", match, outputFile)
+					utils.LogVerboseContext(ctx, "Found additional output file: %s, moving to %s", match, outputFile)
 					// Remove existing file if it exists
 					if err := os.Remove(outputFile); err != nil && !os.IsNotExist(err) {
-						utils.LogWarning("Failed to remove existing file: %v", err)
+						utils.LogWarningContext(ctx, "Failed to remove existing file: %v", err)
 					}
 					// Move the file
 					if err := os.Rename(match, outputFile); err != nil {
-						utils.LogWarning("Failed to rename file: %v", err)
+						utils.LogWarningContext(ctx, "Failed to rename file: %v", err)
 					}
 					break
 				}
@@ -333,7 +472,35 @@ func (m *Module) processFile(ctx context.Context, filePath string, p Params) err
 		}
 	}
 
-	utils.LogSuccess("Successfully transcribed %s", filePath)
+	utils.LogSuccessContext(ctx, "Successfully transcribed %s", filePath)
+	return applyTimeRange(ctx, outputFile, true, p)
+}
+
+// applyTimeRange moves the cues of a transcript of audio extracted from a range of the source
+// video to source time, so timestamps of later steps point into the video, and drops the cues
+// outside the range. With shift false, the transcript already is in source time.
+func applyTimeRange(ctx context.Context, outputFile string, shift bool, p Params) error {
+	timeRange, err := utils.ParseTimeRange(p.StartTime, p.EndTime)
+	if err != nil || timeRange.IsZero() {
+		return err
+	}
+	if p.OutputFormat != "srt" && p.OutputFormat != "vtt" {
+		utils.LogWarningContext(ctx, "Timestamps of %s transcripts are not moved to the range %s", p.OutputFormat, timeRange)
+		return nil
+	}
+
+	data, err := os.ReadFile(outputFile)
+	if err != nil {
+		return fmt.Errorf("failed to read transcript: %w", err)
+	}
+	var offset time.Duration
+	if shift {
+		offset = timeRange.Start
+	}
+	if err := utils.AtomicWriteFile(outputFile, []byte(utils.ShiftTranscript(string(data), offset, timeRange)), 0644); err != nil {
+		return fmt.Errorf("failed to write transcript: %w", err)
+	}
+	utils.LogVerboseContext(ctx, "Transcript %s restricted to %s", outputFile, timeRange)
 	return nil
 }
 
@@ -356,6 +523,19 @@ func (m *Module) buildWhisperCommand(inputFile, outputFile string, p Params) []s
 	if !containsParam(args, "--output_format") {
 		args = append(args, "--output_format", p.OutputFormat)
 	}
+	if p.Language != "" && p.Language != "auto" && !containsParam(args, "--language") {
+		args = append(args, "--language", p.Language)
+	}
+
+	// Reproducible runs decode greedily, without retrying at higher temperatures
+	if utils.Deterministic() {
+		if !containsParam(args, "--temperature") {
+			args = append(args, "--temperature", "0")
+		}
+		if !containsParam(args, "--temperature_increment_on_fallback") {
+			args = append(args, "--temperature_increment_on_fallback", "None")
+		}
+	}
 
 	return args
 }
@@ -382,6 +562,9 @@ func (m *Module) buildWhisperCliCommand(inputFile, outputFile string, p Params)
 	if !containsParam(args, "-tp") && !containsParam(args, "--temperature") {
 		args = append(args, "--temperature", "0.0")
 	}
+	if utils.Deterministic() && !containsParam(args, "-nf") && !containsParam(args, "--no-fallback") {
+		args = append(args, "--no-fallback")
+	}
 
 	// Set language if specified
 	if p.Language != "" && p.Language != "auto" {
@@ -422,7 +605,7 @@ func containsParam(args []string, param string) bool {
 }
 
 // findExistingTranscripts tries to find existing transcription files that match the audio files
-func (m *Module) findExistingTranscripts(p Params) error {
+func (m *Module) findExistingTranscripts(ctx context.Context, p Params) error {
 	// This is the fallback when transcription tools aren't installed
 	// Check if there are already transcription files for the audio
 	baseDir := filepath.Dir(p.Input)
@@ -466,10 +649,10 @@ func (m *Module) findExistingTranscripts(p Params) error {
 
 				outFile := filepath.Join(p.Output, outBaseName+outExt)
 				if err := copyFile(transcriptFile, outFile); err != nil {
-					utils.LogWarning("Failed to copy transcript %s: %v", transcriptFile, err)
+					utils.LogWarningContext(ctx, "Failed to copy transcript %s: %v", transcriptFile, err)
 					continue
 				}
-				utils.LogVerbose("Found existing transcript: %s", transcriptFile)
+				utils.LogVerboseContext(ctx, "Found existing transcript: %s", transcriptFile)
 				found = true
 				break
 			}
@@ -483,33 +666,11 @@ func (m *Module) findExistingTranscripts(p Params) error {
 	return nil
 }
 
-// copyFile copies a file from src to dst
+// copyFile places src at dst as a clone or hard link when the filesystem allows it, and otherwise
+// copies it atomically, verifying the copy's checksum
 func copyFile(src, dst string) error {
-	in, err := os.Open(src)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if cerr := in.Close(); cerr != nil {
-			utils.LogWarning("Failed to close input file: %v", cerr)
-		}
-	}()
-
-	out, err := os.Create(dst)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if cerr := out.Close(); cerr != nil {
-			utils.LogWarning("Failed to close output file: %v", cerr)
-		}
-	}()
-
-	_, err = io.Copy(out, in)
-	if err != nil {
-		return err
-	}
-	return out.Sync()
+	_, err := utils.LinkFile(src, dst)
+	return err
 }
 
 // sortNaturally sorts strings in natural order (e.g., split_1.wav comes before split_10.wav)
@@ -573,11 +734,14 @@ func splitIntoChunks(s string) []string {
 	return chunks
 }
 
-// splitAudioFile splits an audio file into segments of specified duration (in seconds)
+// segmentSeconds is the length of the segments whisper-cli transcribes one at a time
+const segmentSeconds = 600
+
+// splitAudioFile splits an audio file into segments of segmentSeconds up-front
 func (m *Module) splitAudioFile(ctx context.Context, inputFile string, outputDir string) ([]string, error) {
 	// Create a temporary directory for split files
 	splitDir := filepath.Join(outputDir, "splits")
-	if err := os.MkdirAll(splitDir, 0755); err != nil {
+	if err := utils.EnsureDir(splitDir); err != nil {
 		return nil, fmt.Errorf("failed to create splits directory: %w", err)
 	}
 
@@ -586,7 +750,7 @@ func (m *Module) splitAudioFile(ctx context.Context, inputFile string, outputDir
 	args := []string{
 		"-i", inputFile,
 		"-f", "segment",
-		"-segment_time", "600", // 10 minutes = 600 seconds
+		"-segment_time", strconv.Itoa(segmentSeconds),
 		"-c", "copy",
 		splitPattern,
 	}
@@ -607,65 +771,51 @@ func (m *Module) splitAudioFile(ctx context.Context, inputFile string, outputDir
 	return splitFiles, nil
 }
 
-// parseTimestamp parses an SRT timestamp into hours, minutes, seconds, and milliseconds
-func parseTimestamp(timestamp string) (int, int, int, int, error) {
-	var hours, minutes, seconds, milliseconds int
-	n, err := fmt.Sscanf(timestamp, "%d:%d:%d,%d", &hours, &minutes, &seconds, &milliseconds)
+// segmentSource returns the number of segments of inputFile and a function producing the file of
+// segment i. Segments are cut on demand with -ss/-t, so only the one being transcribed is on disk;
+// when the duration cannot be probed the file is split up-front instead.
+func (m *Module) segmentSource(ctx context.Context, inputFile string, tempDir string) (int, func(int) (string, error), error) {
+	duration, err := m.mediaDuration(ctx, inputFile)
 	if err != nil {
-		return 0, 0, 0, 0, fmt.Errorf("failed to parse timestamp: %w", err)
-	}
-	if n != 4 {
-		return 0, 0, 0, 0, fmt.Errorf("invalid timestamp format")
-	}
-
-	// Validate hours (0-99)
-	if hours < 0 || hours > 99 {
-		return 0, 0, 0, 0, fmt.Errorf("invalid hours value: %d", hours)
+		utils.LogWarningContext(ctx, "Splitting %s up-front: %v", inputFile, err)
+		splitFiles, err := m.splitAudioFile(ctx, inputFile, tempDir)
+		if err != nil {
+			return 0, nil, err
+		}
+		return len(splitFiles), func(i int) (string, error) { return splitFiles[i], nil }, nil
 	}
 
-	// Validate minutes (0-59)
-	if minutes < 0 || minutes > 59 {
-		return 0, 0, 0, 0, fmt.Errorf("invalid minutes value: %d", minutes)
-	}
+	length := segmentSeconds * time.Second
+	total := int((duration + length - 1) / length)
+	return total, func(i int) (string, error) {
+		return m.extractSegment(ctx, inputFile, tempDir, i)
+	}, nil
+}
 
-	// Validate seconds (0-59)
-	if seconds < 0 || seconds > 59 {
-		return 0, 0, 0, 0, fmt.Errorf("invalid seconds value: %d", seconds)
+// extractSegment cuts segment i of inputFile into tempDir
+func (m *Module) extractSegment(ctx context.Context, inputFile string, tempDir string, i int) (string, error) {
+	segmentFile := filepath.Join(tempDir, fmt.Sprintf("split_%03d%s", i, filepath.Ext(inputFile)))
+	args := []string{
+		"-ss", strconv.Itoa(i * segmentSeconds),
+		"-t", strconv.Itoa(segmentSeconds),
+		"-i", inputFile,
+		"-c", "copy",
+		"-y",
+		segmentFile,
 	}
-
-	// Validate milliseconds (0-999)
-	if milliseconds < 0 || milliseconds > 999 {
-		return 0, 0, 0, 0, fmt.Errorf("invalid milliseconds value: %d", milliseconds)
+	if output, err := m.cmdExecutor.ExecuteCommand(ctx, "ffmpeg", args); err != nil {
+		return "", fmt.Errorf("failed to extract segment %d: %s, error: %w", i+1, string(output), err)
 	}
-
-	return hours, minutes, seconds, milliseconds, nil
-}
-
-// formatTimestamp formats hours, minutes, seconds, and milliseconds into an SRT timestamp
-func formatTimestamp(hours, minutes, seconds, milliseconds int) string {
-	return fmt.Sprintf("%02d:%02d:%02d,%03d", hours, minutes, seconds, milliseconds)
+	return segmentFile, nil
 }
 
 // adjustTimestamp adds an offset (in seconds) to an SRT timestamp
 func adjustTimestamp(timestamp string, offsetSeconds int) (string, error) {
-	hours, minutes, seconds, milliseconds, err := parseTimestamp(timestamp)
+	at, err := utils.ParseSRTTimestamp(timestamp)
 	if err != nil {
 		return "", err
 	}
-
-	// Convert everything to milliseconds for easier calculation
-	totalMs := (hours*3600+minutes*60+seconds)*1000 + milliseconds
-	totalMs += offsetSeconds * 1000
-
-	// Convert back to h:m:s,ms
-	newHours := totalMs / (3600 * 1000)
-	totalMs %= 3600 * 1000
-	newMinutes := totalMs / (60 * 1000)
-	totalMs %= 60 * 1000
-	newSeconds := totalMs / 1000
-	newMilliseconds := totalMs % 1000
-
-	return formatTimestamp(newHours, newMinutes, newSeconds, newMilliseconds), nil
+	return utils.FormatSRTTimestamp(at + time.Duration(offsetSeconds)*time.Second), nil
 }
 
 // forceMemoryCleanup performs aggressive memory cleanup
@@ -678,34 +828,48 @@ func forceMemoryCleanup() {
 	debug.FreeOSMemory()
 }
 
-// waitForMemoryCleanup waits for memory to be cleaned up
-func waitForMemoryCleanup(ctx context.Context) error {
-	fmt.Printf("\n\033[35m[Memory Cleanup]\033[0m Waiting 5 seconds to clean up RAM memory before next segment...\n")
+var (
+	// systemMemory reads the memory of the machine; replaced in tests
+	systemMemory = utils.SystemMemory
 
-	// Create a ticker for progress indication
-	ticker := time.NewTicker(5 * time.Second)
-	defer ticker.Stop()
+	// memoryPoll is how often memory is read again while waiting for it to be freed
+	memoryPoll = time.Second
 
-	// Create a timer for the total wait time
-	timer := time.NewTimer(5 * time.Second)
-	defer timer.Stop()
+	// maxMemoryWait is the longest wait for memory before the next segment starts anyway
+	maxMemoryWait = 30 * time.Second
+)
 
-	// Start cleanup
+// waitForMemory waits before the next segment while more than threshold percent of the system
+// memory is in use, so whisper-cli does not start into swap. It returns at once when memory is
+// below the threshold or cannot be read, and gives up after maxMemoryWait.
+func waitForMemory(ctx context.Context, threshold float64) error {
 	forceMemoryCleanup()
+	stats, err := systemMemory()
+	if err != nil {
+		utils.LogVerboseContext(ctx, "Not checking memory: %v", err)
+		return nil
+	}
+	if stats.UsedPercent() <= threshold {
+		return nil
+	}
 
-	// Wait and show progress
+	utils.LogWarningContext(ctx, "Memory use is %.0f%%, above %.0f%%: waiting before the next segment", stats.UsedPercent(), threshold)
+	ticker := time.NewTicker(memoryPoll)
+	defer ticker.Stop()
+	timer := time.NewTimer(maxMemoryWait)
+	defer timer.Stop()
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
 		case <-timer.C:
-			// Final cleanup before continuing
-			forceMemoryCleanup()
+			utils.LogWarningContext(ctx, "Memory use is still above %.0f%% after %s, continuing", threshold, maxMemoryWait)
 			return nil
 		case <-ticker.C:
-			// Run cleanup every 5 seconds while waiting
 			forceMemoryCleanup()
-			// utils.LogVerbose("Still cleaning memory...")
+			if stats, err := systemMemory(); err != nil || stats.UsedPercent() <= threshold {
+				return nil
+			}
 		}
 	}
 }
@@ -713,49 +877,56 @@ func waitForMemoryCleanup(ctx context.Context) error {
 // processWhisperCliWithSplitting handles the complete workflow for whisper-cli with audio splitting
 func (m *Module) processWhisperCliWithSplitting(ctx context.Context, inputFile string, outputFile string, p Params) error {
 	// Create a temporary directory for processing
-	tempDir := filepath.Join(filepath.Dir(outputFile), "temp_transcribe")
-	if err := os.MkdirAll(tempDir, 0755); err != nil {
+	// Each run gets its own folder, so concurrent runs writing to the same folder never collide
+	tempDir, err := utils.MakeTempDir(filepath.Dir(outputFile), "transcribe")
+	if err != nil {
 		return fmt.Errorf("failed to create temp directory: %w", err)
 	}
 	defer func() {
 		// Clean up temp files
 		if err := os.RemoveAll(tempDir); err != nil {
-			utils.LogWarning("Failed to remove temp directory: %v", err)
+			utils.LogWarningContext(ctx, "Failed to remove temp directory: %v", err)
 		}
 		// Force memory cleanup
 		forceMemoryCleanup()
 	}()
 
-	// Split the audio file
-	splitFiles, err := m.splitAudioFile(ctx, inputFile, tempDir)
+	// Segments are produced, transcribed and deleted one at a time to bound temp space
+	totalSegments, segmentAt, err := m.segmentSource(ctx, inputFile, tempDir)
 	if err != nil {
 		return fmt.Errorf("failed to split audio: %w", err)
 	}
 
 	// Process each split file and immediately merge to final output
-	outFile, err := os.Create(outputFile)
+	// Segments are appended to a temporary file that only replaces the output once all succeed,
+	// so an interrupted run never leaves a truncated SRT behind
+	outFile, err := utils.CreateAtomicFile(outputFile, 0644)
 	if err != nil {
 		return fmt.Errorf("failed to create output file: %w", err)
 	}
 	defer func() {
 		if cerr := outFile.Close(); cerr != nil {
-			utils.LogWarning("Failed to close output file: %v", cerr)
+			utils.LogWarningContext(ctx, "Failed to close output file: %v", cerr)
 		}
 	}()
 
 	var subtitleIndex = 1
 	var timeOffset = 0 // offset in seconds
 
-	totalSegments := len(splitFiles)
-	for i, splitFile := range splitFiles {
-		// If this is not the first segment, wait for memory cleanup
+	for i := 0; i < totalSegments; i++ {
+		// If this is not the first segment, wait for memory to be freed when it runs low
 		if i > 0 {
-			if err := waitForMemoryCleanup(ctx); err != nil {
-				return fmt.Errorf("memory cleanup interrupted: %w", err)
+			if err := waitForMemory(ctx, p.MemoryThreshold); err != nil {
+				return fmt.Errorf("waiting for memory interrupted: %w", err)
 			}
 		}
 
-		fmt.Printf("\n\033[36m[Progress]\033[0m Processing segment %d/%d\n", i+1, totalSegments)
+		fmt.Fprintf(utils.LogWriter(ctx), "\n\033[36m[Progress]\033[0m Processing segment %d/%d\n", i+1, totalSegments)
+
+		splitFile, err := segmentAt(i)
+		if err != nil {
+			return err
+		}
 
 		// Generate output path for this segment
 		segmentOutput := filepath.Join(tempDir, fmt.Sprintf("segment_%03d.srt", i))
@@ -771,30 +942,34 @@ func (m *Module) processWhisperCliWithSplitting(ctx context.Context, inputFile s
 
 		// Process the output if needed
 		if len(output) > 0 {
-			utils.LogVerbose("whisper-cli output: %s", string(output))
+			utils.LogVerboseContext(ctx, "whisper-cli output: %s", string(output))
 		}
 
 		// Process this segment's transcription and append to final file
-		if err := m.processAndAppendTranscription(segmentOutput, outFile, &subtitleIndex, timeOffset); err != nil {
+		if err := m.processAndAppendTranscription(segmentOutput, outFile.File, &subtitleIndex, timeOffset); err != nil {
 			return fmt.Errorf("failed to process segment %d: %w", i+1, err)
 		}
 
 		// Clean up segment files immediately
 		if err := os.Remove(segmentOutput); err != nil {
-			utils.LogWarning("Failed to remove segment output: %v", err)
+			utils.LogWarningContext(ctx, "Failed to remove segment output: %v", err)
 		}
 		if err := os.Remove(splitFile); err != nil {
-			utils.LogWarning("Failed to remove split file: %v", err)
+			utils.LogWarningContext(ctx, "Failed to remove split file: %v", err)
 		}
 
-		// Update time offset for next file (10 minutes = 600 seconds)
-		timeOffset += 600
+		// Update time offset for next file
+		timeOffset += segmentSeconds
 
 		// Force cleanup after processing each segment
 		forceMemoryCleanup()
 	}
 
-	fmt.Printf("\n\033[32m[Complete]\033[0m Successfully transcribed all %d segments\n", totalSegments)
+	if err := outFile.Commit(); err != nil {
+		return fmt.Errorf("failed to finalize output file: %w", err)
+	}
+
+	fmt.Fprintf(utils.LogWriter(ctx), "\n\033[32m[Complete]\033[0m Successfully transcribed all %d segments\n", totalSegments)
 	return nil
 }
 
@@ -899,6 +1074,42 @@ func (m *Module) GetIO() modules.ModuleIO {
 				Description: "Custom output file name (without extension)",
 				Type:        string(modules.InputTypeData),
 			},
+			{
+				Name:        "whisperProfiles",
+				Description: "Whisper parameters per language, selected from language or detection",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "reuseExisting",
+				Description: "Reuse a transcript found next to the input or source video: true, false or verify",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "videoFile",
+				Description: "Source video whose sibling transcript can be reused",
+				Patterns:    []string{".mp4", ".mov"},
+				Type:        string(modules.InputTypeFile),
+			},
+			{
+				Name:        "confidence",
+				Description: "Capture per-segment confidence and write a QC report of low-confidence regions (whisper model only)",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "transcriptFixes",
+				Description: "Regex, dictionary, casing and number fixes run on the transcript before any model corrects it",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "startTime",
+				Description: "Time of the source video the audio starts at; cues are shifted to source time",
+				Type:        string(modules.InputTypeData),
+			},
+			{
+				Name:        "endTime",
+				Description: "End of the range of the source video; later cues are dropped",
+				Type:        string(modules.InputTypeData),
+			},
 		},
 		ProducedOutputs: []modules.ModuleOutput{
 			{
@@ -907,6 +1118,12 @@ func (m *Module) GetIO() modules.ModuleIO {
 				Patterns:    []string{".txt", ".srt"},
 				Type:        string(modules.OutputTypeFile),
 			},
+			{
+				Name:        "qcReport",
+				Description: "Low-confidence regions of the transcript, written when confidence is enabled",
+				Patterns:    []string{"_qc.yaml"},
+				Type:        string(modules.OutputTypeFile),
+			},
 		},
 	}
 }
@@ -4,11 +4,14 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockCommandExecutor is a mock implementation of CommandExecutor
@@ -94,6 +97,41 @@ func TestModule_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "missing input in an output folder",
+			params: map[string]interface{}{
+				"input":  filepath.Join(tempDir, "output", "audio.wav"),
+				"output": filepath.Join(tempDir, "run"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing input produced by an earlier step",
+			params: map[string]interface{}{
+				"input":    filepath.Join(tempDir, "output", "audio.wav"),
+				"output":   filepath.Join(tempDir, "run"),
+				"fromStep": "Extract Audio",
+			},
+			wantErr: false,
+		},
+		{
+			name: "whisper-cpp without model file",
+			params: map[string]interface{}{
+				"input":  testWavFile,
+				"output": outputDir,
+				"model":  "whisper-cpp",
+			},
+			wantErr: true,
+		},
+		{
+			name: "memory threshold out of range",
+			params: map[string]interface{}{
+				"input":           testWavFile,
+				"output":          outputDir,
+				"memoryThreshold": 120,
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid file extension",
 			params: map[string]interface{}{
@@ -123,21 +161,22 @@ func TestModule_GetIO(t *testing.T) {
 	io := module.GetIO()
 
 	assert.Len(t, io.RequiredInputs, 2)
-	assert.Len(t, io.OptionalInputs, 5)
-	assert.Len(t, io.ProducedOutputs, 1)
+	assert.Len(t, io.OptionalInputs, 12)
+	assert.Len(t, io.ProducedOutputs, 2)
 
 	// Verify required inputs
 	assert.Equal(t, "input", io.RequiredInputs[0].Name)
 	assert.Equal(t, "output", io.RequiredInputs[1].Name)
 
 	// Verify optional inputs
-	optionalInputNames := []string{"model", "language", "outputFormat", "whisperParams", "outputFileName"}
+	optionalInputNames := []string{"model", "language", "outputFormat", "whisperParams", "outputFileName", "whisperProfiles"}
 	for i, name := range optionalInputNames {
 		assert.Equal(t, name, io.OptionalInputs[i].Name)
 	}
 
 	// Verify produced outputs
 	assert.Equal(t, "transcript", io.ProducedOutputs[0].Name)
+	assert.Equal(t, "qcReport", io.ProducedOutputs[1].Name)
 }
 
 func TestSortNaturally(t *testing.T) {
@@ -166,6 +205,19 @@ func TestSortNaturally(t *testing.T) {
 	}
 }
 
+func TestDeterministicWhisperCommands(t *testing.T) {
+	m := &Module{}
+	p := Params{Model: "whisper", OutputFormat: "srt", WhisperParams: "--temperature 0.2"}
+	assert.False(t, containsParam(m.buildWhisperCommand("a.wav", "out/a.srt", p), "--temperature_increment_on_fallback"))
+
+	utils.SetDeterministic(true)
+	t.Cleanup(func() { utils.SetDeterministic(false) })
+	args := m.buildWhisperCommand("a.wav", "out/a.srt", p)
+	assert.Contains(t, strings.Join(args, " "), "--temperature 0.2", "explicit parameters win")
+	assert.Contains(t, strings.Join(args, " "), "--temperature_increment_on_fallback None")
+	assert.Contains(t, m.buildWhisperCliCommand("a.wav", "out/a.srt", Params{OutputFormat: "srt"}), "--no-fallback")
+}
+
 func TestContainsParam(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -289,41 +341,6 @@ func TestSplitIntoChunks(t *testing.T) {
 	}
 }
 
-func TestFormatTimestamp(t *testing.T) {
-	tests := []struct {
-		name     string
-		h        int
-		m        int
-		s        int
-		ms       int
-		expected string
-	}{
-		{
-			name:     "valid timestamp",
-			h:        1,
-			m:        30,
-			s:        45,
-			ms:       500,
-			expected: "01:30:45,500",
-		},
-		{
-			name:     "zero values",
-			h:        0,
-			m:        0,
-			s:        0,
-			ms:       0,
-			expected: "00:00:00,000",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := formatTimestamp(tt.h, tt.m, tt.s, tt.ms)
-			assert.Equal(t, tt.expected, result)
-		})
-	}
-}
-
 func TestForceMemoryCleanup(t *testing.T) {
 	// This is a simple test to ensure the function doesn't panic
 	t.Run("does not panic", func(t *testing.T) {
@@ -333,41 +350,46 @@ func TestForceMemoryCleanup(t *testing.T) {
 	})
 }
 
-func TestWaitForMemoryCleanup(t *testing.T) {
-	tests := []struct {
-		name          string
-		timeout       time.Duration
-		expectedError bool
-	}{
-		{
-			name:          "timeout occurs",
-			timeout:       100 * time.Millisecond,
-			expectedError: true,
-		},
-		{
-			name:          "zero timeout",
-			timeout:       0,
-			expectedError: true,
-		},
-		{
-			name:          "negative timeout",
-			timeout:       -1 * time.Second,
-			expectedError: true,
-		},
+func TestWaitForMemory(t *testing.T) {
+	defer func(read func() (utils.MemoryStats, error), poll, wait time.Duration) {
+		systemMemory, memoryPoll, maxMemoryWait = read, poll, wait
+	}(systemMemory, memoryPoll, maxMemoryWait)
+	memoryPoll, maxMemoryWait = time.Millisecond, 50*time.Millisecond
+
+	// Memory is freed after the third reading
+	reads := 0
+	systemMemory = func() (utils.MemoryStats, error) {
+		reads++
+		if reads < 3 {
+			return utils.MemoryStats{Total: 100, Available: 5}, nil
+		}
+		return utils.MemoryStats{Total: 100, Available: 50}, nil
 	}
+	require.NoError(t, waitForMemory(context.Background(), 90))
+	assert.Equal(t, 3, reads)
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
-			defer cancel()
-			err := waitForMemoryCleanup(ctx)
-			if tt.expectedError {
-				assert.Error(t, err)
-			} else {
-				assert.NoError(t, err)
-			}
-		})
+	// Below the threshold there is no wait
+	reads = 10
+	require.NoError(t, waitForMemory(context.Background(), 90))
+	assert.Equal(t, 11, reads)
+
+	// Memory that stays low is waited for at most maxMemoryWait
+	systemMemory = func() (utils.MemoryStats, error) {
+		return utils.MemoryStats{Total: 100, Available: 5}, nil
 	}
+	start := time.Now()
+	require.NoError(t, waitForMemory(context.Background(), 90))
+	assert.GreaterOrEqual(t, time.Since(start), maxMemoryWait)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, waitForMemory(ctx, 90), context.Canceled)
+
+	// Memory that cannot be read is not waited for
+	systemMemory = func() (utils.MemoryStats, error) {
+		return utils.MemoryStats{}, assert.AnError
+	}
+	assert.NoError(t, waitForMemory(ctx, 90))
 }
 
 func TestNaturalLess(t *testing.T) {
@@ -411,68 +433,6 @@ func TestNaturalLess(t *testing.T) {
 	}
 }
 
-func TestParseTimestamp(t *testing.T) {
-	tests := []struct {
-		name      string
-		timestamp string
-		wantH     int
-		wantM     int
-		wantS     int
-		wantMs    int
-		wantErr   bool
-	}{
-		{
-			name:      "valid timestamp",
-			timestamp: "01:30:45,500",
-			wantH:     1,
-			wantM:     30,
-			wantS:     45,
-			wantMs:    500,
-			wantErr:   false,
-		},
-		{
-			name:      "invalid format",
-			timestamp: "1:30:45.500",
-			wantErr:   true,
-		},
-		{
-			name:      "invalid hours",
-			timestamp: "100:30:45,500",
-			wantErr:   true,
-		},
-		{
-			name:      "invalid minutes",
-			timestamp: "01:60:45,500",
-			wantErr:   true,
-		},
-		{
-			name:      "invalid seconds",
-			timestamp: "01:30:60,500",
-			wantErr:   true,
-		},
-		{
-			name:      "invalid milliseconds",
-			timestamp: "01:30:45,1000",
-			wantErr:   true,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			h, m, s, ms, err := parseTimestamp(tt.timestamp)
-			if tt.wantErr {
-				assert.Error(t, err)
-			} else {
-				assert.NoError(t, err)
-				assert.Equal(t, tt.wantH, h)
-				assert.Equal(t, tt.wantM, m)
-				assert.Equal(t, tt.wantS, s)
-				assert.Equal(t, tt.wantMs, ms)
-			}
-		})
-	}
-}
-
 func TestAdjustTimestamp(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -502,6 +462,13 @@ func TestAdjustTimestamp(t *testing.T) {
 			expected:    "00:00:30,000",
 			expectError: false,
 		},
+		{
+			name:        "past 99 hours",
+			timestamp:   "99:59:30,250",
+			offsetSecs:  60,
+			expected:    "100:00:30,250",
+			expectError: false,
+		},
 		{
 			name:        "invalid timestamp",
 			timestamp:   "invalid",
@@ -522,3 +489,245 @@ func TestAdjustTimestamp(t *testing.T) {
 		})
 	}
 }
+
+func TestWithWhisperProfile(t *testing.T) {
+	profiles := map[string]string{
+		"default":  "--model large-v2",
+		"japanese": "--model large-v3 --beam_size 8",
+	}
+
+	t.Run("explicit params win", func(t *testing.T) {
+		m := &Module{cmdExecutor: &MockCommandExecutor{}}
+		p := m.withWhisperProfile(context.Background(), "a.wav", Params{Model: "whisper", Language: "ja", WhisperParams: "--model tiny", WhisperProfiles: profiles})
+		assert.Equal(t, "--model tiny", p.WhisperParams)
+	})
+
+	t.Run("language code selects a profile keyed by name", func(t *testing.T) {
+		m := &Module{cmdExecutor: &MockCommandExecutor{}}
+		p := m.withWhisperProfile(context.Background(), "a.wav", Params{Model: "whisper", Language: "ja", WhisperProfiles: profiles})
+		assert.Equal(t, "--model large-v3 --beam_size 8", p.WhisperParams)
+	})
+
+	t.Run("detected language selects a profile", func(t *testing.T) {
+		executor := &MockCommandExecutor{}
+		executor.On("ExecuteCommand", "whisper", mock.MatchedBy(func(args []string) bool {
+			return args[0] == "a.wav" && containsParam(args, "--clip_timestamps")
+		})).Return([]byte("Detecting language using up to the first 30 seconds.\nDetected language: Japanese\n"), nil)
+		m := &Module{cmdExecutor: executor}
+
+		p := m.withWhisperProfile(context.Background(), "a.wav", Params{Model: "whisper", Language: "auto", DetectModel: "tiny", WhisperProfiles: profiles})
+		assert.Equal(t, "--model large-v3 --beam_size 8", p.WhisperParams)
+		assert.Equal(t, "ja", p.Language)
+		assert.Contains(t, m.buildWhisperCommand("a.wav", "out/a.srt", p), "ja")
+		executor.AssertExpectations(t)
+	})
+
+	t.Run("other languages use the default profile", func(t *testing.T) {
+		m := &Module{cmdExecutor: &MockCommandExecutor{}}
+		p := m.withWhisperProfile(context.Background(), "a.wav", Params{Model: "whisper", Language: "es", WhisperProfiles: profiles})
+		assert.Equal(t, "--model large-v2", p.WhisperParams)
+	})
+
+	t.Run("no profiles keep the built-in default", func(t *testing.T) {
+		m := &Module{cmdExecutor: &MockCommandExecutor{}}
+		p := m.withWhisperProfile(context.Background(), "a.wav", Params{Model: "whisper", Language: "auto"})
+		assert.Equal(t, defaultWhisperParams, p.WhisperParams)
+	})
+}
+
+func TestReuseExistingTranscript(t *testing.T) {
+	const srt = "1\n00:00:00,000 --> 00:00:05,000\nHello\n\n2\n00:09:30,000 --> 00:09:58,000\nBye\n"
+
+	setup := func(t *testing.T) (string, string, Params) {
+		dir := t.TempDir()
+		video := filepath.Join(dir, "episode.mp4")
+		createTestFile(t, video)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "episode.en.srt"), []byte(srt), 0644))
+		audio := filepath.Join(dir, "out", "audio.wav")
+		createTestFile(t, audio)
+		p := Params{Output: filepath.Join(dir, "out"), OutputFormat: "srt", VideoFile: video, ReuseCoverage: 0.9}
+		return audio, filepath.Join(p.Output, "transcript.srt"), p
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		audio, outputFile, p := setup(t)
+		m := &Module{cmdExecutor: &MockCommandExecutor{}}
+		reused, err := m.reuseExistingTranscript(context.Background(), audio, outputFile, p)
+		require.NoError(t, err)
+		assert.False(t, reused)
+		assert.NoFileExists(t, outputFile)
+	})
+
+	t.Run("true copies the transcript next to the video", func(t *testing.T) {
+		audio, outputFile, p := setup(t)
+		p.ReuseExisting = true
+		m := &Module{cmdExecutor: &MockCommandExecutor{}}
+		reused, err := m.reuseExistingTranscript(context.Background(), audio, outputFile, p)
+		require.NoError(t, err)
+		assert.True(t, reused)
+		data, err := os.ReadFile(outputFile)
+		require.NoError(t, err)
+		assert.Equal(t, srt, string(data))
+	})
+
+	t.Run("verify accepts a transcript covering the media", func(t *testing.T) {
+		audio, outputFile, p := setup(t)
+		p.ReuseExisting = "verify"
+		executor := &MockCommandExecutor{}
+		executor.On("ExecuteCommand", "ffprobe", mock.Anything).Return([]byte("600.5\n"), nil)
+		m := &Module{cmdExecutor: executor}
+		reused, err := m.reuseExistingTranscript(context.Background(), audio, outputFile, p)
+		require.NoError(t, err)
+		assert.True(t, reused)
+		executor.AssertExpectations(t)
+	})
+
+	t.Run("verify rejects a transcript of a shorter recording", func(t *testing.T) {
+		audio, outputFile, p := setup(t)
+		p.ReuseExisting = "verify"
+		executor := &MockCommandExecutor{}
+		executor.On("ExecuteCommand", "ffprobe", mock.Anything).Return([]byte("3600\n"), nil)
+		m := &Module{cmdExecutor: executor}
+		reused, err := m.reuseExistingTranscript(context.Background(), audio, outputFile, p)
+		require.NoError(t, err)
+		assert.False(t, reused)
+		assert.NoFileExists(t, outputFile)
+	})
+
+	t.Run("verify rejects a transcript longer than the media", func(t *testing.T) {
+		audio, outputFile, p := setup(t)
+		p.ReuseExisting = "verify"
+		executor := &MockCommandExecutor{}
+		executor.On("ExecuteCommand", "ffprobe", mock.Anything).Return([]byte("300\n"), nil)
+		m := &Module{cmdExecutor: executor}
+		reused, err := m.reuseExistingTranscript(context.Background(), audio, outputFile, p)
+		require.NoError(t, err)
+		assert.False(t, reused)
+	})
+
+	t.Run("invalid mode", func(t *testing.T) {
+		_, err := reuseMode("sometimes")
+		assert.ErrorContains(t, err, "unsupported reuseExisting value")
+	})
+}
+
+func TestProcessFile_TranscriptFixes(t *testing.T) {
+	dir := t.TempDir()
+	video := filepath.Join(dir, "episode.mp4")
+	createTestFile(t, video)
+	srt := "1\n00:00:00,000 --> 00:00:05,000\nWelcome to open ai news\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "episode.srt"), []byte(srt), 0644))
+	audio := filepath.Join(dir, "out", "audio.wav")
+	createTestFile(t, audio)
+
+	p := Params{
+		Output:          filepath.Join(dir, "out"),
+		OutputFormat:    "srt",
+		OutputFileName:  "transcript",
+		VideoFile:       video,
+		ReuseExisting:   true,
+		TranscriptFixes: []utils.TranscriptFix{{Dictionary: map[string]string{"open ai": "OpenAI"}}},
+	}
+	m := &Module{cmdExecutor: &MockCommandExecutor{}}
+	require.NoError(t, m.processFile(context.Background(), audio, p))
+
+	data, err := os.ReadFile(filepath.Join(p.Output, "transcript.srt"))
+	require.NoError(t, err)
+	assert.Equal(t, "1\n00:00:00,000 --> 00:00:05,000\nWelcome to OpenAI news\n", string(data))
+}
+
+func TestApplyTimeRange(t *testing.T) {
+	const srt = "1\n00:00:01,000 --> 00:00:04,000\nHello\n\n2\n00:09:30,000 --> 00:09:58,000\nBye\n"
+	outputFile := filepath.Join(t.TempDir(), "transcript.srt")
+	p := Params{OutputFormat: "srt", StartTime: "00:30:00", EndTime: "00:35:00"}
+
+	// Whisper's cues of the extracted range are moved to source time, and those past the end dropped
+	require.NoError(t, os.WriteFile(outputFile, []byte(srt), 0644))
+	require.NoError(t, applyTimeRange(context.Background(), outputFile, true, p))
+	data, err := os.ReadFile(outputFile)
+	require.NoError(t, err)
+	assert.Equal(t, "1\n00:30:01,000 --> 00:30:04,000\nHello\n", string(data))
+
+	// A reused transcript of the whole recording is only cut to the range
+	p.StartTime, p.EndTime = "00:09:00", ""
+	require.NoError(t, os.WriteFile(outputFile, []byte(srt), 0644))
+	require.NoError(t, applyTimeRange(context.Background(), outputFile, false, p))
+	data, err = os.ReadFile(outputFile)
+	require.NoError(t, err)
+	assert.Equal(t, "1\n00:09:30,000 --> 00:09:58,000\nBye\n", string(data))
+}
+
+func TestWriteConfidenceOutputs(t *testing.T) {
+	dir := t.TempDir()
+	jsonFile := filepath.Join(dir, "audio.json")
+	require.NoError(t, os.WriteFile(jsonFile, []byte(`{"text": "", "segments": [
+		{"start": 0.0, "end": 2.5, "text": " Hello there.", "avg_logprob": -0.2, "compression_ratio": 1.1, "no_speech_prob": 0.01},
+		{"start": 2.5, "end": 4.0, "text": " Kubernetes at the edge.", "avg_logprob": -1.4, "compression_ratio": 1.2, "no_speech_prob": 0.05}
+	]}`), 0644))
+
+	outputFile := filepath.Join(dir, "audio.srt")
+	p := Params{OutputFormat: "srt", ConfidenceThreshold: -1.0}
+	require.NoError(t, writeConfidenceOutputs(context.Background(), "audio.wav", jsonFile, outputFile, p))
+
+	data, err := os.ReadFile(outputFile)
+	require.NoError(t, err)
+	assert.Equal(t, "1\n00:00:00,000 --> 00:00:02,500\nHello there.\n\n2\n00:00:02,500 --> 00:00:04,000\nKubernetes at the edge.\n\n", string(data))
+
+	report, err := utils.ReadConfidenceReport(filepath.Join(dir, "audio_qc.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Segments)
+	require.Len(t, report.Regions, 1)
+	assert.Equal(t, "00:00:02", report.Regions[0].Start)
+	assert.Equal(t, "Kubernetes at the edge.", report.Regions[0].Text)
+}
+
+func TestRenderSegments(t *testing.T) {
+	segments := []utils.WhisperSegment{{Start: 1, End: 2.25, Text: " Hi "}}
+	assert.Equal(t, "WEBVTT\n\n00:00:01.000 --> 00:00:02.250\nHi\n\n", renderSegments(segments, "vtt"))
+	assert.Equal(t, "Hi\n", renderSegments(segments, "txt"))
+}
+
+func TestProcessWhisperCliWithSplitting(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "audio.wav")
+	createTestFile(t, input)
+
+	// Segments on disk when each one is cut, to check they are deleted once transcribed
+	var onDisk []int
+	executor := new(MockCommandExecutor)
+	executor.On("ExecuteCommand", "ffprobe", mock.Anything).Return([]byte("1500.0\n"), nil)
+	executor.On("ExecuteCommand", "ffmpeg", mock.Anything).Run(func(args mock.Arguments) {
+		ffmpegArgs := args.Get(1).([]string)
+		existing, _ := filepath.Glob(filepath.Join(filepath.Dir(ffmpegArgs[len(ffmpegArgs)-1]), "split_*"))
+		onDisk = append(onDisk, len(existing))
+		require.NoError(t, os.WriteFile(ffmpegArgs[len(ffmpegArgs)-1], []byte("audio"), 0644))
+	}).Return([]byte{}, nil)
+	executor.On("ExecuteCommand", "whisper-cli", mock.Anything).Run(func(args mock.Arguments) {
+		cliArgs := args.Get(1).([]string)
+		for i, arg := range cliArgs {
+			if arg == "--output-file" {
+				require.NoError(t, os.WriteFile(cliArgs[i+1]+".srt", []byte("1\n00:00:01,000 --> 00:00:02,000\nHello\n\n"), 0644))
+			}
+		}
+	}).Return([]byte{}, nil)
+
+	defer func(read func() (utils.MemoryStats, error)) { systemMemory = read }(systemMemory)
+	systemMemory = func() (utils.MemoryStats, error) {
+		return utils.MemoryStats{Total: 100, Available: 50}, nil
+	}
+
+	m := &Module{cmdExecutor: executor}
+	output := filepath.Join(dir, "transcript.srt")
+	require.NoError(t, m.processWhisperCliWithSplitting(context.Background(), input, output, Params{OutputFormat: "srt", MemoryThreshold: 90}))
+
+	assert.Equal(t, []int{0, 0, 0}, onDisk, "each segment is cut after the previous one is deleted")
+	executor.AssertCalled(t, "ExecuteCommand", "ffmpeg", mock.MatchedBy(func(args []string) bool {
+		return len(args) > 3 && args[0] == "-ss" && args[1] == "1200" && args[3] == "600"
+	}))
+	content, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "3\n00:20:01,000 --> 00:20:02,000\nHello")
+
+	leftovers, _ := filepath.Glob(filepath.Join(dir, utils.TempDirPrefix+"*"))
+	assert.Empty(t, leftovers, "the temporary folder is removed")
+}
@@ -0,0 +1,123 @@
+//go:build whispercpp && cgo
+
+package transcribe
+
+/*
+#cgo LDFLAGS: -lwhisper
+#cgo darwin LDFLAGS: -framework Accelerate -framework Foundation -framework Metal -framework MetalKit
+#include <stdbool.h>
+#include <stdlib.h>
+#include <whisper.h>
+
+// sf_abort stops whisper_full once Go sets the flag behind user_data
+static bool sf_abort(void * user_data) {
+	return *(volatile int *)user_data != 0;
+}
+
+static struct whisper_full_params sf_full_params(int threads, const char * language, int * abort_flag) {
+	struct whisper_full_params params = whisper_full_default_params(WHISPER_SAMPLING_BEAM_SEARCH);
+	params.n_threads = threads;
+	params.language = language;
+	params.beam_search.beam_size = 5;
+	params.greedy.best_of = 5;
+	params.print_progress = false;
+	params.print_realtime = false;
+	params.print_timestamps = false;
+	params.print_special = false;
+	params.abort_callback = sf_abort;
+	params.abort_callback_user_data = abort_flag;
+	return params;
+}
+*/
+import "C"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"unsafe"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+// embeddedWhisperAvailable reports whether whisper.cpp is linked into this binary
+const embeddedWhisperAvailable = true
+
+var errEmbeddedUnavailable = errors.New("embedded whisper.cpp is not available")
+
+// whisperEmbedded runs whisper.cpp on 16 kHz mono samples. The GPU (Metal on macOS) is used when
+// the library was built with it. Segments carry the mean log probability of their text tokens, so
+// confidence reports work as with the whisper CLI.
+func whisperEmbedded(ctx context.Context, samples []float32, opts embeddedOptions) ([]utils.WhisperSegment, error) {
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("no audio samples to transcribe")
+	}
+
+	modelFile := C.CString(opts.ModelFile)
+	defer C.free(unsafe.Pointer(modelFile))
+	cparams := C.whisper_context_default_params()
+	cparams.use_gpu = C.bool(true)
+	wctx := C.whisper_init_from_file_with_params(modelFile, cparams)
+	if wctx == nil {
+		return nil, fmt.Errorf("failed to load whisper.cpp model %s", opts.ModelFile)
+	}
+	defer C.whisper_free(wctx)
+
+	language := opts.Language
+	if language == "" {
+		language = "auto"
+	}
+	clanguage := C.CString(language)
+	defer C.free(unsafe.Pointer(clanguage))
+
+	// whisper.cpp polls the flag between decoding steps, so cancelling the step stops it early
+	abortFlag := (*C.int)(C.calloc(1, C.size_t(unsafe.Sizeof(C.int(0)))))
+	defer C.free(unsafe.Pointer(abortFlag))
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			*abortFlag = 1
+		case <-done:
+		}
+	}()
+
+	params := C.sf_full_params(C.int(max(opts.Threads, 1)), clanguage, abortFlag)
+	if code := C.whisper_full(wctx, params, (*C.float)(unsafe.Pointer(&samples[0])), C.int(len(samples))); code != 0 {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("whisper.cpp transcription failed with code %d", int(code))
+	}
+
+	eot := C.whisper_token_eot(wctx)
+	count := int(C.whisper_full_n_segments(wctx))
+	segments := make([]utils.WhisperSegment, 0, count)
+	for i := 0; i < count; i++ {
+		ci := C.int(i)
+		segment := utils.WhisperSegment{
+			// Segment times are in hundredths of a second
+			Start: float64(C.whisper_full_get_segment_t0(wctx, ci)) / 100,
+			End:   float64(C.whisper_full_get_segment_t1(wctx, ci)) / 100,
+			Text:  C.GoString(C.whisper_full_get_segment_text(wctx, ci)),
+		}
+
+		var logprob float64
+		var tokens int
+		for j := C.int(0); j < C.whisper_full_n_tokens(wctx, ci); j++ {
+			// Special tokens (timestamps, end of text) carry no speech
+			if C.whisper_full_get_token_id(wctx, ci, j) >= eot {
+				continue
+			}
+			logprob += math.Log(math.Max(float64(C.whisper_full_get_token_p(wctx, ci, j)), 1e-10))
+			tokens++
+		}
+		if tokens > 0 {
+			segment.AvgLogprob = logprob / float64(tokens)
+		}
+		segments = append(segments, segment)
+	}
+	return segments, nil
+}
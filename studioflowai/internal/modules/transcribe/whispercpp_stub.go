@@ -0,0 +1,20 @@
+//go:build !whispercpp || !cgo
+
+package transcribe
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+// embeddedWhisperAvailable reports whether whisper.cpp is linked into this binary
+const embeddedWhisperAvailable = false
+
+var errEmbeddedUnavailable = errors.New("the whisper-cpp model needs a build with whisper.cpp linked in: install whisper.cpp and rebuild with CGO_ENABLED=1 go build -tags whispercpp")
+
+// whisperEmbedded is unavailable without the whispercpp build tag
+func whisperEmbedded(ctx context.Context, samples []float32, opts embeddedOptions) ([]utils.WhisperSegment, error) {
+	return nil, errEmbeddedUnavailable
+}
@@ -0,0 +1,143 @@
+package transcriptindex
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+// indexFileName is the file in the config directory holding the transcript index
+const indexFileName = "transcript_index.json"
+
+// Kinds of indexed entries
+const (
+	KindTranscript = "transcript" // A passage of an episode transcript
+	KindShort      = "short"      // A suggested short, title and description
+)
+
+// Entry is one embedded passage of a past run
+type Entry struct {
+	Document string    `json:"document"`        // Run the entry belongs to (its output directory)
+	Kind     string    `json:"kind"`            // KindTranscript or KindShort
+	Episode  string    `json:"episode"`         // Episode title
+	URL      string    `json:"url,omitempty"`   // Public link of the episode
+	Start    string    `json:"start,omitempty"` // Where the passage starts in the episode (HH:MM:SS)
+	Text     string    `json:"text"`            // Indexed text
+	Vector   []float32 `json:"vector"`          // Embedding of the text
+}
+
+// Match is an entry found by a search, with its cosine similarity to the query
+type Match struct {
+	Entry
+	Score float64
+}
+
+// Index is the embeddings of every indexed transcript, persisted as JSON
+type Index struct {
+	Model   string  `json:"model"`   // Embedding model of all vectors; vectors of different models are not comparable
+	Entries []Entry `json:"entries"` // Indexed passages of all runs
+
+	path string
+}
+
+// DefaultIndexPath returns the index file of the active workspace
+func DefaultIndexPath() (string, error) {
+	configDir, err := utils.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, indexFileName), nil
+}
+
+// OpenIndex loads the index at path; a missing file is an empty index for the model.
+// An index built with another embedding model is an error, since its vectors cannot be compared.
+func OpenIndex(path, model string) (*Index, error) {
+	idx := &Index{Model: model, path: path}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return idx, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transcript index: %w", err)
+	}
+	if err := json.Unmarshal(data, idx); err != nil {
+		return nil, fmt.Errorf("failed to parse transcript index %s: %w", path, err)
+	}
+	if len(idx.Entries) == 0 {
+		idx.Model = model
+	}
+	if idx.Model != model {
+		return nil, fmt.Errorf("transcript index %s was built with %s, not %s; use the same embeddingModel or another indexPath", path, idx.Model, model)
+	}
+	return idx, nil
+}
+
+// Replace drops the entries of a document and adds its new entries, so re-running an episode
+// does not index it twice
+func (idx *Index) Replace(document string, entries []Entry) {
+	kept := idx.Entries[:0]
+	for _, entry := range idx.Entries {
+		if entry.Document != document {
+			kept = append(kept, entry)
+		}
+	}
+	for _, entry := range entries {
+		entry.Document = document
+		kept = append(kept, entry)
+	}
+	idx.Entries = kept
+}
+
+// Search returns the entries most similar to vector, best first. Only entries of the given kind
+// from documents other than exclude are considered.
+func (idx *Index) Search(vector []float32, kind, exclude string, limit int) []Match {
+	var matches []Match
+	for _, entry := range idx.Entries {
+		if entry.Kind != kind || entry.Document == exclude {
+			continue
+		}
+		matches = append(matches, Match{Entry: entry, Score: cosine(vector, entry.Vector)})
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// Save writes the index back to its file
+func (idx *Index) Save() error {
+	if err := utils.EnsureDir(filepath.Dir(idx.path)); err != nil {
+		return fmt.Errorf("failed to create index directory: %w", err)
+	}
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return fmt.Errorf("failed to encode transcript index: %w", err)
+	}
+	if err := utils.AtomicWriteFile(idx.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write transcript index: %w", err)
+	}
+	return nil
+}
+
+// cosine returns the cosine similarity of two vectors, or 0 when they cannot be compared
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,537 @@
+package transcriptindex
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	modules "github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	chatgpt "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/chatgpt"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+
+	"gopkg.in/yaml.v3"
+)
+
+// contextKey is a type for context keys
+type contextKey string
+
+// EmbedderKey is the context key for the embeddings service
+const EmbedderKey = contextKey("embedder")
+
+// relatedFileName is the report written for every run
+const relatedFileName = "related_episodes.yaml"
+
+// Module indexes the transcript of every run and finds related past episodes
+type Module struct{}
+
+// Params contains the parameters for transcript indexing
+type Params struct {
+	Input               string                 `json:"input"`                                           // Path to the transcript; an SRT links related passages to their time
+	Output              string                 `json:"output"`                                          // Path to output directory
+	Shorts              string                 `json:"shorts"`                                          // Path to shorts_suggestions.yaml, checked for near-duplicates of past shorts (optional)
+	Title               string                 `json:"title"`                                           // Episode title shown in later runs (default: metadata title, then the output folder name)
+	URL                 string                 `json:"url"`                                             // Public link of the episode, used in related-episode lists (default: metadata url)
+	Metadata            map[string]interface{} `json:"metadata"`                                        // Episode details; title and url are used when not set explicitly
+	IndexPath           string                 `json:"indexPath"`                                       // Index file (default: transcript_index.json in the config directory)
+	EmbeddingModel      string                 `json:"embeddingModel" default:"text-embedding-3-small"` // OpenAI embedding model (default: "text-embedding-3-small")
+	ChunkWords          int                    `json:"chunkWords" default:"200"`                        // Words per indexed passage (default: 200)
+	TopK                int                    `json:"topK" default:"5"`                                // Maximum number of related episodes (default: 5)
+	MinScore            float64                `json:"minScore" default:"0.5"`                          // Minimum similarity of a related episode (default: 0.5)
+	DuplicateScore      float64                `json:"duplicateScore" default:"0.85"`                   // Similarity from which a short duplicates a past short (default: 0.85)
+	DropDuplicateShorts bool                   `json:"dropDuplicateShorts"`                             // Remove near-duplicate shorts from the shorts file instead of only reporting them (default: false)
+}
+
+// RelatedEpisode is a past episode that covers the topics of this run
+type RelatedEpisode struct {
+	Episode string  `yaml:"episode"`
+	URL     string  `yaml:"url,omitempty"`
+	Score   float64 `yaml:"score"`           // Best similarity of a passage of this run to the episode
+	Start   string  `yaml:"start,omitempty"` // Where the most similar passage starts in the past episode
+	Excerpt string  `yaml:"excerpt"`         // Most similar passage of the past episode
+}
+
+// DuplicateShort is a suggested short that repeats a short of a past episode
+type DuplicateShort struct {
+	Title   string  `yaml:"title"`   // Suggested short of this run
+	Matches string  `yaml:"matches"` // Past short it repeats
+	Episode string  `yaml:"episode"`
+	URL     string  `yaml:"url,omitempty"`
+	Score   float64 `yaml:"score"`
+}
+
+// Report is the content of related_episodes.yaml
+type Report struct {
+	Episode         string           `yaml:"episode"`
+	Related         []RelatedEpisode `yaml:"related"`
+	DuplicateShorts []DuplicateShort `yaml:"duplicateShorts,omitempty"`
+}
+
+// ShortClip represents a single short video clip suggestion
+type ShortClip struct {
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+}
+
+// chunk is one passage of the transcript
+type chunk struct {
+	start string
+	text  string
+}
+
+// New creates a new transcript index module
+func New() modules.Module {
+	return &Module{}
+}
+
+// Name returns the module name
+func (m *Module) Name() string {
+	return "transcript_index"
+}
+
+// ParamsTemplate returns the module's parameter struct, used to validate and document workflows
+func (m *Module) ParamsTemplate() interface{} {
+	return Params{}
+}
+
+// Validate checks if the parameters are valid
+func (m *Module) Validate(params map[string]interface{}) error {
+	var p Params
+	if err := modules.ParseParams(params, &p); err != nil {
+		return err
+	}
+
+	if err := utils.ValidateInputPath(p.Input, p.Output, ""); err != nil {
+		return err
+	}
+	if err := utils.ValidateOutputPath(p.Output); err != nil {
+		return err
+	}
+	if p.Shorts != "" {
+		if err := utils.ValidateInputPath(p.Shorts, p.Output, ""); err != nil {
+			return err
+		}
+	}
+	if p.ChunkWords < 0 || p.TopK < 0 {
+		return fmt.Errorf("chunkWords and topK cannot be negative")
+	}
+	if p.MinScore < 0 || p.MinScore > 1 || p.DuplicateScore < 0 || p.DuplicateScore > 1 {
+		return fmt.Errorf("minScore and duplicateScore must be between 0 and 1")
+	}
+
+	// Check if the API key is set - just warn but don't error
+	if !chatgpt.IsAPIKeySet() {
+		utils.LogWarning("OPENAI_API_KEY environment variable is not set. The transcript will not be indexed.")
+	}
+	return nil
+}
+
+// Execute indexes the transcript and writes the related episodes of this run
+func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (modules.ModuleResult, error) {
+	var p Params
+	if err := modules.ParseParams(params, &p); err != nil {
+		return modules.ModuleResult{}, err
+	}
+
+	// Set default values
+	if p.EmbeddingModel == "" {
+		p.EmbeddingModel = chatgpt.DefaultEmbeddingModel
+	}
+	if p.ChunkWords == 0 {
+		p.ChunkWords = 200
+	}
+	if p.TopK == 0 {
+		p.TopK = 5
+	}
+	if p.MinScore == 0 {
+		p.MinScore = 0.5
+	}
+	if p.DuplicateScore == 0 {
+		p.DuplicateScore = 0.85
+	}
+	if p.IndexPath == "" {
+		path, err := DefaultIndexPath()
+		if err != nil {
+			return modules.ModuleResult{}, err
+		}
+		p.IndexPath = path
+	}
+	indexPath, err := utils.ExpandHomeDir(p.IndexPath)
+	if err != nil {
+		return modules.ModuleResult{}, err
+	}
+	p.IndexPath = indexPath
+
+	if err := utils.EnsureDir(p.Output); err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	reportPath := filepath.Join(p.Output, relatedFileName)
+
+	transcriptPath := utils.ResolveOutputPath(p.Input, p.Output)
+	transcript, err := utils.ReadTextFile(transcriptPath)
+	if err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to read transcript file: %w", err)
+	}
+	frontMatter, transcript := utils.SplitEpisodeFrontMatter(transcript)
+	fields := utils.EpisodeFields(utils.MergeEpisodeMetadata(frontMatter, p.Metadata))
+	document, err := filepath.Abs(p.Output)
+	if err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to resolve output directory: %w", err)
+	}
+	if p.Title == "" {
+		p.Title = fields["title"]
+	}
+	if p.Title == "" {
+		p.Title = filepath.Base(document)
+	}
+	if p.URL == "" {
+		p.URL = fields["url"]
+	}
+
+	var shorts []ShortClip
+	var shortsPath string
+	if p.Shorts != "" {
+		shortsPath = utils.ResolveOutputPath(p.Shorts, p.Output)
+		if shorts, err = readShorts(shortsPath); err != nil {
+			return modules.ModuleResult{}, err
+		}
+	}
+
+	if !chatgpt.IsAPIKeySet() {
+		utils.LogWarningContext(ctx, "No API key set - the transcript was not indexed")
+		content := "# MOCK OUTPUT - No OPENAI_API_KEY set; the transcript was not indexed\n"
+		data, err := yaml.Marshal(Report{Episode: p.Title, Related: []RelatedEpisode{}})
+		if err != nil {
+			return modules.ModuleResult{}, fmt.Errorf("failed to generate YAML: %w", err)
+		}
+		if err := utils.WriteTextFile(reportPath, content+string(data)); err != nil {
+			return modules.ModuleResult{}, fmt.Errorf("failed to write output file: %w", err)
+		}
+		return modules.ModuleResult{Outputs: map[string]string{"related_episodes": reportPath}}, nil
+	}
+
+	embedder, err := m.getEmbedder(ctx)
+	if err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to initialize embeddings service: %w", err)
+	}
+	idx, err := OpenIndex(p.IndexPath, p.EmbeddingModel)
+	if err != nil {
+		return modules.ModuleResult{}, err
+	}
+
+	chunks, err := chunkTranscript(transcript, transcriptPath, p.ChunkWords)
+	if err != nil {
+		return modules.ModuleResult{}, err
+	}
+	if len(chunks) == 0 {
+		return modules.ModuleResult{}, fmt.Errorf("transcript %s is empty", transcriptPath)
+	}
+
+	texts := make([]string, 0, len(chunks)+len(shorts))
+	for _, c := range chunks {
+		texts = append(texts, c.text)
+	}
+	for _, short := range shorts {
+		texts = append(texts, shortText(short))
+	}
+	utils.LogVerboseContext(ctx, "Embedding %d transcript passages and %d shorts with %s...", len(chunks), len(shorts), p.EmbeddingModel)
+	vectors, err := embedder.Embed(ctx, texts, p.EmbeddingModel)
+	if err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("embeddings request failed: %w", err)
+	}
+	if len(vectors) != len(texts) {
+		return modules.ModuleResult{}, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(vectors))
+	}
+
+	report := Report{
+		Episode:         p.Title,
+		Related:         relatedEpisodes(idx, vectors[:len(chunks)], document, p),
+		DuplicateShorts: duplicateShorts(idx, shorts, vectors[len(chunks):], document, p.DuplicateScore),
+	}
+	for _, dup := range report.DuplicateShorts {
+		utils.LogWarningContext(ctx, "Short %q repeats %q from %s (similarity %.2f)", dup.Title, dup.Matches, dup.Episode, dup.Score)
+	}
+
+	// Index this run; shorts dropped as duplicates are not indexed
+	duplicates := make(map[string]bool, len(report.DuplicateShorts))
+	for _, dup := range report.DuplicateShorts {
+		duplicates[dup.Title] = true
+	}
+	entries := make([]Entry, 0, len(texts))
+	for i, c := range chunks {
+		entries = append(entries, Entry{Kind: KindTranscript, Episode: p.Title, URL: p.URL, Start: c.start, Text: c.text, Vector: vectors[i]})
+	}
+	for i, short := range shorts {
+		if p.DropDuplicateShorts && duplicates[short.Title] {
+			continue
+		}
+		entries = append(entries, Entry{Kind: KindShort, Episode: p.Title, URL: p.URL, Text: short.Title, Vector: vectors[len(chunks)+i]})
+	}
+	idx.Replace(document, entries)
+	if err := idx.Save(); err != nil {
+		return modules.ModuleResult{}, err
+	}
+
+	outputs := map[string]string{"related_episodes": reportPath}
+	if p.DropDuplicateShorts && len(duplicates) > 0 {
+		if err := dropShorts(shortsPath, duplicates); err != nil {
+			return modules.ModuleResult{}, err
+		}
+		utils.LogInfoContext(ctx, "Removed %d near-duplicate shorts from %s", len(duplicates), shortsPath)
+	}
+
+	data, err := yaml.Marshal(report)
+	if err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to generate YAML: %w", err)
+	}
+	if err := utils.WriteTextFile(reportPath, string(data)); err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to write output file: %w", err)
+	}
+
+	utils.LogSuccessContext(ctx, "Indexed %s: %d related episodes, %d duplicate shorts -> %s", p.Title, len(report.Related), len(report.DuplicateShorts), reportPath)
+
+	return modules.ModuleResult{
+		Outputs: outputs,
+		Statistics: map[string]interface{}{
+			"model":           p.EmbeddingModel,
+			"inputFile":       transcriptPath,
+			"indexPath":       p.IndexPath,
+			"passages":        len(chunks),
+			"related":         len(report.Related),
+			"duplicateShorts": len(report.DuplicateShorts),
+			"processTime":     time.Now().Format(time.RFC3339),
+		},
+		Stats: modules.Stats{Items: len(chunks)},
+	}, nil
+}
+
+// relatedEpisodes ranks past episodes by the best similarity of any passage of this run to any
+// of their passages
+func relatedEpisodes(idx *Index, vectors [][]float32, document string, p Params) []RelatedEpisode {
+	best := make(map[string]Match)
+	for _, vector := range vectors {
+		for _, match := range idx.Search(vector, KindTranscript, document, 0) {
+			if match.Score < p.MinScore {
+				break
+			}
+			if current, ok := best[match.Document]; !ok || match.Score > current.Score {
+				best[match.Document] = match
+			}
+		}
+	}
+
+	related := make([]RelatedEpisode, 0, len(best))
+	for _, match := range best {
+		related = append(related, RelatedEpisode{
+			Episode: match.Episode,
+			URL:     match.URL,
+			Score:   roundScore(match.Score),
+			Start:   match.Start,
+			Excerpt: excerpt(match.Text, 40),
+		})
+	}
+	sort.SliceStable(related, func(i, j int) bool {
+		if related[i].Score != related[j].Score {
+			return related[i].Score > related[j].Score
+		}
+		return related[i].Episode < related[j].Episode
+	})
+	if len(related) > p.TopK {
+		related = related[:p.TopK]
+	}
+	return related
+}
+
+// duplicateShorts finds the suggested shorts that are close to a short of a past episode
+func duplicateShorts(idx *Index, shorts []ShortClip, vectors [][]float32, document string, threshold float64) []DuplicateShort {
+	var duplicates []DuplicateShort
+	for i, short := range shorts {
+		matches := idx.Search(vectors[i], KindShort, document, 1)
+		if len(matches) == 0 || matches[0].Score < threshold {
+			continue
+		}
+		duplicates = append(duplicates, DuplicateShort{
+			Title:   short.Title,
+			Matches: matches[0].Text,
+			Episode: matches[0].Episode,
+			URL:     matches[0].URL,
+			Score:   roundScore(matches[0].Score),
+		})
+	}
+	return duplicates
+}
+
+// chunkTranscript splits a transcript into passages of about the given number of words. SRT
+// passages follow cue boundaries and keep the time of their first cue.
+func chunkTranscript(transcript, path string, words int) ([]chunk, error) {
+	if !strings.EqualFold(filepath.Ext(path), ".srt") {
+		var chunks []chunk
+		fields := strings.Fields(transcript)
+		for start := 0; start < len(fields); start += words {
+			end := min(start+words, len(fields))
+			chunks = append(chunks, chunk{text: strings.Join(fields[start:end], " ")})
+		}
+		return chunks, nil
+	}
+
+	cues, err := utils.ParseSRT(transcript)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SRT transcript %s: %w", path, err)
+	}
+	var chunks []chunk
+	var current []string
+	var start time.Duration
+	for _, cue := range cues {
+		if len(current) == 0 {
+			start = cue.Start
+		}
+		current = append(current, strings.Fields(cue.Text)...)
+		if len(current) >= words {
+			chunks = append(chunks, chunk{start: utils.FormatTimestamp(start), text: strings.Join(current, " ")})
+			current = nil
+		}
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, chunk{start: utils.FormatTimestamp(start), text: strings.Join(current, " ")})
+	}
+	return chunks, nil
+}
+
+// readShorts reads the suggested shorts
+func readShorts(path string) ([]ShortClip, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shorts file: %w", err)
+	}
+	var shorts struct {
+		Shorts []ShortClip `yaml:"shorts"`
+	}
+	if err := utils.DecodeShorts(data, &shorts); err != nil {
+		return nil, fmt.Errorf("failed to parse shorts file: %w", err)
+	}
+	return shorts.Shorts, nil
+}
+
+// dropShorts removes the shorts with the given titles from the shorts file, keeping every other
+// field of the file as is
+func dropShorts(path string, titles map[string]bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read shorts file: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return fmt.Errorf("failed to parse shorts file: %w", err)
+	}
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != "shorts" || root.Content[i+1].Kind != yaml.SequenceNode {
+			continue
+		}
+		list := root.Content[i+1]
+		kept := list.Content[:0]
+		for _, item := range list.Content {
+			var short ShortClip
+			if err := item.Decode(&short); err == nil && titles[short.Title] {
+				continue
+			}
+			kept = append(kept, item)
+		}
+		list.Content = kept
+	}
+
+	out, err := yaml.Marshal(&doc)
+	if err != nil {
+		return fmt.Errorf("failed to generate YAML: %w", err)
+	}
+	if err := utils.WriteTextFile(path, string(out)); err != nil {
+		return fmt.Errorf("failed to write shorts file: %w", err)
+	}
+	return nil
+}
+
+// shortText is the text embedded for a short
+func shortText(short ShortClip) string {
+	if short.Description == "" {
+		return short.Title
+	}
+	return short.Title + ". " + short.Description
+}
+
+// excerpt shortens a passage to its first words
+func excerpt(text string, words int) string {
+	fields := strings.Fields(text)
+	if len(fields) <= words {
+		return text
+	}
+	return strings.Join(fields[:words], " ") + "..."
+}
+
+// roundScore keeps two decimals of a similarity for the report
+func roundScore(score float64) float64 {
+	return float64(int(score*100+0.5)) / 100
+}
+
+// getEmbedder returns the embeddings service from context or creates a new one
+func (m *Module) getEmbedder(ctx context.Context) (chatgpt.Embedder, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("context cannot be nil")
+	}
+
+	// Check if service is provided in context
+	if service, ok := ctx.Value(EmbedderKey).(chatgpt.Embedder); ok {
+		return service, nil
+	}
+
+	// Create new service if not in context
+	return chatgpt.NewChatGPTService()
+}
+
+// GetIO returns the module's input/output specification
+func (m *Module) GetIO() modules.ModuleIO {
+	return modules.ModuleIO{
+		RequiredInputs: []modules.ModuleInput{
+			{
+				Name:        "input",
+				Description: "Path to the transcript, preferably SRT",
+				Patterns:    []string{".srt", ".txt"},
+				Type:        string(modules.InputTypeFile),
+			},
+			{
+				Name:        "output",
+				Description: "Path to output directory",
+				Type:        string(modules.InputTypeDirectory),
+			},
+		},
+		OptionalInputs: []modules.ModuleInput{
+			{
+				Name:        "shorts",
+				Description: "Path to shorts suggestions YAML file checked for near-duplicates",
+				Patterns:    []string{".yaml"},
+				Type:        string(modules.InputTypeFile),
+			},
+			{
+				Name:        "indexPath",
+				Description: "Transcript index file shared by all runs",
+				Patterns:    []string{".json"},
+				Type:        string(modules.InputTypeFile),
+			},
+			{
+				Name:        "embeddingModel",
+				Description: "OpenAI embedding model",
+				Type:        string(modules.InputTypeData),
+			},
+		},
+		ProducedOutputs: []modules.ModuleOutput{
+			{
+				Name:        "related_episodes",
+				Description: "Related past episodes and duplicate shorts",
+				Patterns:    []string{".yaml"},
+				Type:        string(modules.OutputTypeFile),
+			},
+		},
+	}
+}
@@ -0,0 +1,157 @@
+package transcriptindex
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// fakeEmbedder embeds text as counts of a few topic words, so texts about the same topic are similar
+type fakeEmbedder struct {
+	calls int
+}
+
+func (f *fakeEmbedder) Embed(ctx context.Context, texts []string, model string) ([][]float32, error) {
+	f.calls++
+	topics := []string{"synth", "guitar", "mixing"}
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector := make([]float32, len(topics))
+		for j, topic := range topics {
+			vector[j] = float32(strings.Count(strings.ToLower(text), topic))
+		}
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
+
+// runEpisode indexes one episode with its own output folder
+func runEpisode(t *testing.T, ctx context.Context, root, name, transcript, shorts string, extra map[string]interface{}) (*Report, string) {
+	t.Helper()
+	dir := filepath.Join(root, name)
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	input := filepath.Join(dir, "transcript.srt")
+	require.NoError(t, os.WriteFile(input, []byte(transcript), 0644))
+	shortsPath := filepath.Join(dir, "shorts_suggestions.yaml")
+	require.NoError(t, os.WriteFile(shortsPath, []byte(shorts), 0644))
+
+	params := map[string]interface{}{
+		"input":     input,
+		"output":    dir,
+		"shorts":    shortsPath,
+		"title":     name,
+		"url":       "https://youtu.be/" + name,
+		"indexPath": filepath.Join(root, "index.json"),
+	}
+	for key, value := range extra {
+		params[key] = value
+	}
+	result, err := New().Execute(ctx, params)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(result.Outputs["related_episodes"])
+	require.NoError(t, err)
+	var report Report
+	require.NoError(t, yaml.Unmarshal(data, &report))
+	return &report, shortsPath
+}
+
+func TestModule_Name(t *testing.T) {
+	assert.Equal(t, "transcript_index", New().Name())
+}
+
+func TestExecute(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "test-key")
+	root := t.TempDir()
+	ctx := context.WithValue(context.Background(), EmbedderKey, &fakeEmbedder{})
+
+	report, _ := runEpisode(t, ctx, root, "ep1",
+		"1\n00:00:05,000 --> 00:00:10,000\nMy first synth was a Moog synth.\n",
+		"shorts:\n  - title: \"Why a synth\"\n    description: \"The synth story\"\n", nil)
+	assert.Empty(t, report.Related)
+
+	runEpisode(t, ctx, root, "ep2",
+		"1\n00:00:01,000 --> 00:00:04,000\nToday we talk about guitar pedals.\n",
+		"shorts:\n  - title: \"Guitar tone\"\n", nil)
+
+	report, shortsPath := runEpisode(t, ctx, root, "ep3",
+		"1\n00:00:01,000 --> 00:00:04,000\nBack to the synth, and some mixing.\n",
+		"sourceVideo: ep3.mp4\nshorts:\n  - title: \"Synth again\"\n    description: \"Another synth\"\n  - title: \"Mixing basics\"\n",
+		map[string]interface{}{"dropDuplicateShorts": true})
+
+	require.Len(t, report.Related, 1)
+	assert.Equal(t, "ep1", report.Related[0].Episode)
+	assert.Equal(t, "https://youtu.be/ep1", report.Related[0].URL)
+	assert.Equal(t, "00:00:05", report.Related[0].Start)
+
+	require.Len(t, report.DuplicateShorts, 1)
+	assert.Equal(t, "Synth again", report.DuplicateShorts[0].Title)
+	assert.Equal(t, "Why a synth", report.DuplicateShorts[0].Matches)
+
+	data, err := os.ReadFile(shortsPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "Synth again")
+	assert.Contains(t, string(data), "sourceVideo: ep3.mp4")
+	assert.Contains(t, string(data), "Mixing basics")
+
+	// Re-running an episode replaces its entries instead of adding them again
+	runEpisode(t, ctx, root, "ep3", "1\n00:00:01,000 --> 00:00:04,000\nMixing only.\n", "shorts: []\n", nil)
+	idx, err := OpenIndex(filepath.Join(root, "index.json"), "text-embedding-3-small")
+	require.NoError(t, err)
+	count := 0
+	for _, entry := range idx.Entries {
+		if filepath.Base(entry.Document) == "ep3" {
+			count++
+		}
+	}
+	assert.Equal(t, 1, count)
+}
+
+func TestExecute_NoAPIKey(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "")
+	dir := t.TempDir()
+	input := filepath.Join(dir, "transcript.txt")
+	require.NoError(t, os.WriteFile(input, []byte("Some words"), 0644))
+
+	result, err := New().Execute(context.Background(), map[string]interface{}{
+		"input":     input,
+		"output":    dir,
+		"indexPath": filepath.Join(dir, "index.json"),
+	})
+	require.NoError(t, err)
+	data, err := os.ReadFile(result.Outputs["related_episodes"])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "MOCK OUTPUT")
+	assert.NoFileExists(t, filepath.Join(dir, "index.json"))
+}
+
+func TestOpenIndex_ModelMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.json")
+	idx, err := OpenIndex(path, "text-embedding-3-small")
+	require.NoError(t, err)
+	idx.Replace("run", []Entry{{Kind: KindTranscript, Text: "x", Vector: []float32{1}}})
+	require.NoError(t, idx.Save())
+
+	_, err = OpenIndex(path, "text-embedding-3-large")
+	assert.ErrorContains(t, err, "was built with text-embedding-3-small")
+}
+
+func TestChunkTranscript(t *testing.T) {
+	chunks, err := chunkTranscript("one two three four five", "transcript.txt", 2)
+	require.NoError(t, err)
+	require.Len(t, chunks, 3)
+	assert.Equal(t, "five", chunks[2].text)
+
+	srt := "1\n00:00:01,000 --> 00:00:02,000\none two\n\n2\n00:01:00,000 --> 00:01:02,000\nthree\n"
+	chunks, err = chunkTranscript(srt, "transcript.srt", 2)
+	require.NoError(t, err)
+	require.Len(t, chunks, 2)
+	assert.Equal(t, "00:00:01", chunks[0].start)
+	assert.Equal(t, "00:01:00", chunks[1].start)
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	modules "github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	chatgpt "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/chatgpt"
 	youtubesvc "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/youtube"
 	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
 	"google.golang.org/api/youtube/v3"
@@ -20,18 +22,29 @@ type Module struct {
 
 // Params contains the parameters for YouTube shorts upload operations
 type Params struct {
-	Input               string `json:"input"`               // Path to shorts suggestions YAML file
-	Output              string `json:"output"`              // Path to output directory
-	StoredShortsPath    string `json:"storedShortsPath"`    // Path where the short videos are stored
-	Credentials         string `json:"credentials"`         // Path to Google credentials file
-	PlaylistID          string `json:"playlistId"`          // Optional: YouTube playlist ID
-	PrivacyStatus       string `json:"privacyStatus"`       // Video privacy status (private, unlisted, public)
-	CategoryID          string `json:"categoryId"`          // Video category ID
-	SchedulePeriodicity int    `json:"schedulePeriodicity"` // Schedule videos every N days
-	ScheduleTime        string `json:"scheduleTime"`        // Time to schedule videos (24-hour format)
-	MaxAttempts         int    `json:"maxAttempts"`         // Maximum number of days to search for available slots
-	StartDate           string `json:"startDate"`           // Start date for scheduling (YYYY-MM-DD)
-	RelatedVideoID      string `json:"relatedVideoId"`      // ID of the related video to link with shorts
+	Input               string  `json:"input"`                             // Path to shorts suggestions YAML file
+	Output              string  `json:"output"`                            // Path to output directory
+	StoredShortsPath    string  `json:"storedShortsPath"`                  // Path where the short videos are stored
+	Credentials         string  `json:"credentials"`                       // Path to Google credentials file
+	PlaylistID          string  `json:"playlistId"`                        // Optional: YouTube playlist ID
+	PrivacyStatus       string  `json:"privacyStatus"`                     // Video privacy status (private, unlisted, public)
+	CategoryID          string  `json:"categoryId"`                        // Video category ID
+	SchedulePeriodicity int     `json:"schedulePeriodicity"`               // Schedule videos every N days
+	ScheduleTime        string  `json:"scheduleTime"`                      // Time to schedule videos (24-hour format)
+	MaxAttempts         int     `json:"maxAttempts"`                       // Maximum number of days to search for available slots
+	StartDate           string  `json:"startDate"`                         // Start date for scheduling (YYYY-MM-DD)
+	RelatedVideoID      string  `json:"relatedVideoId"`                    // ID of the related video to link with shorts
+	DailyQuota          int     `json:"dailyQuota" default:"10000"`        // YouTube Data API daily quota of the project (default: 10000)
+	QuotaWarnThreshold  float64 `json:"quotaWarnThreshold" default:"0.8"`  // Fraction of the daily quota that triggers a warning (default: 0.8)
+	QuotaStrategy       string  `json:"quotaStrategy" default:"defer"`     // When quota runs out: "defer" remaining uploads or "wait" for the reset (default: "defer")
+	Decisions           string  `json:"decisions"`                         // Review decisions saved from the shorts report; rejected clips are not uploaded
+	Locale              string  `json:"locale"`                            // Language of the channel, e.g. "es" or "English"; shorts copy in another language is localized (default: the project account's locale)
+	ShortsLanguage      string  `json:"shortsLanguage"`                    // Language the shorts file is written in; a channel in that language gets the copy as it is
+	SNSContent          string  `json:"snsContent"`                        // SNS output whose content in the channel's language guides the localized wording and hashtags
+	Model               string  `json:"model" default:"gpt-4o"`            // OpenAI model that localizes the copy (default: "gpt-4o")
+	RequestTimeoutMs    int     `json:"requestTimeoutMs" default:"60000"`  // Time limit of each YouTube API call in milliseconds (default: 60000)
+	UploadTimeoutMs     int     `json:"uploadTimeoutMs" default:"1800000"` // Time limit of each video upload in milliseconds; a timed-out video is skipped (default: 1800000)
+	Account             string  `json:"account"`                           // Channel name, for reference in upload previews (default: the project account's channel)
 }
 
 // New creates a new YouTube shorts upload module
@@ -46,6 +59,11 @@ func (m *Module) Name() string {
 	return "uploadyoutubeshorts"
 }
 
+// ParamsTemplate returns the module's parameter struct, used to validate and document workflows
+func (m *Module) ParamsTemplate() interface{} {
+	return Params{}
+}
+
 // Validate checks if the parameters are valid
 func (m *Module) Validate(params map[string]interface{}) error {
 	var p Params
@@ -95,6 +113,21 @@ func (m *Module) Validate(params map[string]interface{}) error {
 		return fmt.Errorf("invalid privacy status: %s", p.PrivacyStatus)
 	}
 
+	// Validate quota settings
+	if p.DailyQuota < 0 {
+		return fmt.Errorf("dailyQuota must not be negative")
+	}
+	if p.QuotaWarnThreshold < 0 || p.QuotaWarnThreshold > 1 {
+		return fmt.Errorf("quotaWarnThreshold must be between 0 and 1, got %v", p.QuotaWarnThreshold)
+	}
+	if p.QuotaStrategy != "" && p.QuotaStrategy != youtubesvc.QuotaStrategyDefer && p.QuotaStrategy != youtubesvc.QuotaStrategyWait {
+		return fmt.Errorf("invalid quota strategy: %s (expected %s or %s)", p.QuotaStrategy, youtubesvc.QuotaStrategyDefer, youtubesvc.QuotaStrategyWait)
+	}
+
+	if p.RequestTimeoutMs < 0 || p.UploadTimeoutMs < 0 {
+		return fmt.Errorf("requestTimeoutMs and uploadTimeoutMs must not be negative")
+	}
+
 	return nil
 }
 
@@ -122,6 +155,33 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 	}
 	p.Credentials = expandedCredentials
 
+	if utils.PreviewUploads() {
+		return m.previewUploads(ctx, p)
+	}
+
+	// Track quota usage per credential so long batches stop cleanly instead of failing mid-way
+	if err := m.youtubeService.ConfigureQuota(p.Credentials, youtubesvc.QuotaOptions{
+		DailyLimit:    p.DailyQuota,
+		WarnThreshold: p.QuotaWarnThreshold,
+		Strategy:      p.QuotaStrategy,
+	}); err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to configure quota tracking: %w", err)
+	}
+
+	// Every API call is bounded, so a stuck request cannot outlive the step
+	if p.RequestTimeoutMs == 0 {
+		p.RequestTimeoutMs = 60000
+	}
+	if p.UploadTimeoutMs == 0 {
+		p.UploadTimeoutMs = 1800000
+	}
+	if configurer, ok := m.youtubeService.(youtubesvc.TimeoutConfigurer); ok {
+		configurer.SetTimeouts(youtubesvc.Timeouts{
+			Request: time.Duration(p.RequestTimeoutMs) * time.Millisecond,
+			Upload:  time.Duration(p.UploadTimeoutMs) * time.Millisecond,
+		})
+	}
+
 	// Initialize YouTube service
 	service, err := m.youtubeService.InitializeYouTubeService(ctx, p.Credentials)
 	if err != nil {
@@ -134,10 +194,9 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 		return modules.ModuleResult{}, fmt.Errorf("failed to read scheduled videos: %w", err)
 	}
 
-	// Read shorts suggestions file
-	shortsData, err := utils.ReadShortsFile(p.Input)
+	shortsData, err := prepareShorts(ctx, p)
 	if err != nil {
-		return modules.ModuleResult{}, fmt.Errorf("failed to read shorts suggestions file: %w", err)
+		return modules.ModuleResult{}, err
 	}
 
 	// Find available times for each short
@@ -145,9 +204,24 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 	if err != nil {
 		return modules.ModuleResult{}, fmt.Errorf("failed to find availability: %w", err)
 	}
+	totalVideos := len(videoUploads)
+
+	// A retry only uploads the videos that failed or were deferred in the previous attempt
+	var items []modules.ItemResult
+	if retry := modules.RetryItems(ctx); retry != nil {
+		pending := make([]youtubesvc.VideoUpload, 0, len(retry))
+		for _, upload := range videoUploads {
+			if !retry[upload.FileName] {
+				items = append(items, modules.ItemResult{ID: upload.FileName, Status: modules.ItemSkipped})
+				continue
+			}
+			pending = append(pending, upload)
+		}
+		videoUploads = pending
+	}
 
 	// Collect tags and related video ID
-	videoUploads, err = m.collectTagsAndRelatedVideo(service, videoUploads, p.RelatedVideoID)
+	videoUploads, err = m.collectTagsAndRelatedVideo(ctx, service, videoUploads, p.RelatedVideoID)
 	if err != nil {
 		return modules.ModuleResult{}, fmt.Errorf("failed to collect tags and related video: %w", err)
 	}
@@ -157,10 +231,41 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 		return modules.ModuleResult{}, fmt.Errorf("failed to list available times: %w", err)
 	}
 
-	// Upload the videos
-	if err := m.youtubeService.UploadVideo(ctx, service, videoUploads, p.PrivacyStatus, p.CategoryID, p.StoredShortsPath); err != nil {
+	// Upload the videos. A failed upload does not stop the others; it is reported per item, with
+	// the videos deferred by the quota, so a retry only uploads those.
+	results, err := m.youtubeService.UploadVideo(ctx, service, videoUploads, p.PrivacyStatus, p.CategoryID, p.StoredShortsPath)
+	if err != nil {
 		return modules.ModuleResult{}, fmt.Errorf("failed to upload videos: %w", err)
 	}
+	var firstErr error
+	uploaded, failed := 0, 0
+	for _, r := range results {
+		switch {
+		case r.Err != nil:
+			err := fmt.Errorf("failed to upload video %s: %w", r.FileName, r.Err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			failed++
+			items = append(items, modules.ItemResult{ID: r.FileName, Status: modules.ItemFailed, Error: err.Error()})
+		case r.Deferred:
+			items = append(items, modules.ItemResult{ID: r.FileName, Status: modules.ItemFailed, Error: "deferred until the YouTube API quota resets"})
+		default:
+			uploaded++
+			items = append(items, modules.ItemResult{ID: r.FileName, Status: modules.ItemSucceeded})
+		}
+	}
+
+	// Every upload failed: fail the step rather than report a partial success
+	if failed > 0 && failed == len(items) {
+		return modules.ModuleResult{}, firstErr
+	}
+
+	quota := m.youtubeService.QuotaStatus()
+	if len(quota.Deferred) > 0 {
+		utils.LogWarningContext(ctx, "%d video(s) were deferred because of the YouTube API quota; run this step again after %s",
+			len(quota.Deferred), quota.ResetAt.Local().Format("2006-01-02 15:04 MST"))
+	}
 
 	// Prepare result
 	result := modules.ModuleResult{
@@ -168,29 +273,138 @@ func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (mo
 			"uploadStatus": fmt.Sprintf("%s/youtube_upload_status.json", p.Output),
 		},
 		Metadata: map[string]interface{}{
-			"totalVideos": len(videoUploads),
-			"startDate":   p.StartDate,
-			"endDate":     time.Now().UTC().Format("2006-01-02"),
+			"totalVideos":    totalVideos,
+			"startDate":      p.StartDate,
+			"endDate":        time.Now().UTC().Format("2006-01-02"),
+			"deferredVideos": quota.Deferred,
 		},
 		Statistics: map[string]interface{}{
-			"uploadedVideos": len(videoUploads),
+			"uploadedVideos": uploaded,
+			"failedVideos":   failed,
 			"scheduleSpan":   p.MaxAttempts,
+			"quotaUsed":      quota.Used,
+			"quotaRemaining": quota.Remaining,
 		},
 		NextModules: []string{}, // No next modules for this terminal operation
+		Stats:       modules.Stats{Items: uploaded},
+		Items:       items,
 	}
 
 	return result, nil
 }
 
+// prepareShorts reads the shorts to upload: the accepted clips, with their copy in the channel's
+// language and cleaned of formatting YouTube would reject
+func prepareShorts(ctx context.Context, p Params) (*utils.ShortsData, error) {
+	// Read shorts suggestions file
+	shortsData, err := utils.ReadShortsFile(p.Input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shorts suggestions file: %w", err)
+	}
+
+	// Leave out the clips rejected in the shorts report
+	shortsData.Shorts, err = utils.AcceptedShorts(shortsData.Shorts, utils.ResolveOutputPath(p.Decisions, p.Output))
+	if err != nil {
+		return nil, err
+	}
+
+	// Leave out the clips whose title was rated too risky
+	shortsData.Shorts = utils.UnblockedShorts(shortsData.Shorts)
+
+	// Put the copy in the channel's language
+	if p.Model == "" {
+		p.Model = "gpt-4o"
+	}
+	shortsData.Shorts, err = utils.LocalizeForAccount(ctx, shortsData.Shorts, utils.ShortsLocale{
+		Locale:         p.Locale,
+		ShortsLanguage: p.ShortsLanguage,
+		SNSContent:     p.SNSContent,
+		OutputDir:      p.Output,
+	}, chatgpt.PromptFunc(p.Model))
+	if err != nil {
+		return nil, err
+	}
+
+	// Clean up model formatting the platform would reject
+	shortsData.Shorts = utils.SanitizeShorts(shortsData.Shorts, utils.PlatformYouTube)
+	return shortsData, nil
+}
+
+// previewUploads writes the videos.insert requests of the shorts to uploads_preview.yaml without
+// calling the YouTube API. The channel's schedule is not read, so slots already taken by scheduled
+// videos are not skipped.
+func (m *Module) previewUploads(ctx context.Context, p Params) (modules.ModuleResult, error) {
+	shortsData, err := prepareShorts(ctx, p)
+	if err != nil {
+		return modules.ModuleResult{}, err
+	}
+
+	videoUploads, err := m.youtubeService.FindAvailability(nil, shortsData, p.SchedulePeriodicity, p.ScheduleTime, p.MaxAttempts, p.StartDate, p.PlaylistID)
+	if err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to find availability: %w", err)
+	}
+
+	preview := utils.UploadPreview{
+		Platform: utils.PlatformYouTube,
+		Source:   p.Input,
+		Target:   uploadTarget(p),
+		Notes: []string{
+			"The channel's schedule was not read: publish times may fall on slots already taken by scheduled videos",
+		},
+	}
+	if p.RelatedVideoID != "" {
+		preview.Notes = append(preview.Notes, fmt.Sprintf("The tags of related video %s are added to every video at upload", p.RelatedVideoID))
+	}
+	for _, upload := range videoUploads {
+		video := youtubesvc.NewVideo(upload, p.PrivacyStatus, p.CategoryID)
+		preview.Uploads = append(preview.Uploads, utils.PreviewUpload{
+			File:        filepath.Join(p.StoredShortsPath, upload.FileName),
+			Title:       video.Snippet.Title,
+			Description: video.Snippet.Description,
+			Tags:        video.Snippet.Tags,
+			Privacy:     video.Status.PrivacyStatus,
+			PublishAt:   video.Status.PublishAt,
+			PlaylistID:  upload.PlaylistID,
+			Request:     video,
+		})
+	}
+
+	path, err := utils.WriteUploadPreview(p.Output, preview)
+	if err != nil {
+		return modules.ModuleResult{}, err
+	}
+	utils.LogSuccessContext(ctx, "Previewed %d YouTube upload(s) in %s; nothing was uploaded", len(preview.Uploads), path)
+
+	return modules.ModuleResult{
+		Outputs: map[string]string{
+			"uploadsPreview": path,
+		},
+		Metadata: map[string]interface{}{
+			"totalVideos": len(preview.Uploads),
+			"preview":     true,
+		},
+		Stats: modules.Stats{Items: len(preview.Uploads)},
+	}, nil
+}
+
+// uploadTarget describes the channel the step uploads to
+func uploadTarget(p Params) utils.UploadTarget {
+	target := utils.UploadTarget{Account: p.Account, Credentials: p.Credentials}
+	if ws := utils.ActiveWorkspace(); ws != nil {
+		target.Project = ws.Name
+	}
+	return target
+}
+
 // collectTagsAndRelatedVideo adds tags from the related video and adds related video ID to the video uploads
-func (m *Module) collectTagsAndRelatedVideo(service *youtube.Service, videoUploads []youtubesvc.VideoUpload, relatedVideoID string) ([]youtubesvc.VideoUpload, error) {
+func (m *Module) collectTagsAndRelatedVideo(ctx context.Context, service *youtube.Service, videoUploads []youtubesvc.VideoUpload, relatedVideoID string) ([]youtubesvc.VideoUpload, error) {
 	// If no related video ID is provided, just return the uploads as is
 	if relatedVideoID == "" {
 		return videoUploads, nil
 	}
 
 	// Get the related video details to extract tags
-	video, err := m.youtubeService.GetVideoDetails(context.Background(), service, relatedVideoID)
+	video, err := m.youtubeService.GetVideoDetails(ctx, service, relatedVideoID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get related video details: %w", err)
 	}
@@ -202,7 +416,7 @@ func (m *Module) collectTagsAndRelatedVideo(service *youtube.Service, videoUploa
 	// Get tags from the related video
 	relatedVideoTags := video.Snippet.Tags
 	if len(relatedVideoTags) == 0 {
-		utils.LogWarning("No tags found in related video: %s", relatedVideoID)
+		utils.LogWarningContext(ctx, "No tags found in related video: %s", relatedVideoID)
 	}
 
 	// Add related video ID and tags to each video upload
@@ -283,6 +497,12 @@ func (m *Module) GetIO() modules.ModuleIO {
 				Description: "ID of the related video to link with shorts",
 				Type:        string(modules.InputTypeData),
 			},
+			{
+				Name:        "decisions",
+				Description: "Review decisions saved from the shorts report",
+				Patterns:    []string{".json"},
+				Type:        string(modules.InputTypeFile),
+			},
 		},
 		ProducedOutputs: []modules.ModuleOutput{
 			{
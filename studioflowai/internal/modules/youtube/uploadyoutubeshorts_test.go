@@ -2,15 +2,19 @@ package youtube
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
+	modules "github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
 	"github.com/gnzdotmx/studioflowai/studioflowai/internal/services/youtube"
 	youtubemocks "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/youtube/mocks"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	youtubeapi "google.golang.org/api/youtube/v3"
 )
 
@@ -101,6 +105,28 @@ func TestModule_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid quota strategy",
+			params: map[string]interface{}{
+				"input":            testYamlFile,
+				"output":           tempDir,
+				"storedShortsPath": testShortsPath,
+				"credentials":      testCredentialsFile,
+				"quotaStrategy":    "retry",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid quota warn threshold",
+			params: map[string]interface{}{
+				"input":              testYamlFile,
+				"output":             tempDir,
+				"storedShortsPath":   testShortsPath,
+				"credentials":        testCredentialsFile,
+				"quotaWarnThreshold": 1.5,
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -155,6 +181,7 @@ func TestModule_Execute(t *testing.T) {
 	mockYouTubeService := &youtubeapi.Service{}
 
 	// Set up mock expectations
+	mockService.On("ConfigureQuota", testCredentialsFile, youtube.QuotaOptions{}).Return(nil)
 	mockService.On("InitializeYouTubeService", mock.Anything, testCredentialsFile).Return(mockYouTubeService, nil)
 	mockService.On("ReadScheduledVideos", mock.Anything, mockYouTubeService).Return([]youtube.ScheduledVideo{}, nil)
 	mockService.On("FindAvailability", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]youtube.VideoUpload{
@@ -165,9 +192,20 @@ func TestModule_Execute(t *testing.T) {
 			PublishTime: time.Now(),
 			Tags:        "test,tags",
 		},
+		{
+			FileName:    "missing.mp4",
+			ShortTitle:  "Missing Video",
+			Description: "Test Description",
+			PublishTime: time.Now(),
+		},
 	}, nil)
 	mockService.On("ListAvailableTimes", mock.Anything).Return(nil)
-	mockService.On("UploadVideo", mock.Anything, mockYouTubeService, mock.Anything, "private", "", testShortsPath).Return(nil)
+	// The second video fails to upload
+	mockService.On("UploadVideo", mock.Anything, mockYouTubeService, mock.Anything, "private", "", testShortsPath).Return([]youtube.UploadResult{
+		{FileName: "test.mp4", VideoID: "vid1"},
+		{FileName: "missing.mp4", Err: errors.New("no such file")},
+	}, nil)
+	mockService.On("QuotaStatus").Return(youtube.QuotaStatus{Used: 1702, Limit: 10000, Remaining: 8298})
 
 	// Create module with mock service
 	module := &Module{
@@ -189,8 +227,18 @@ func TestModule_Execute(t *testing.T) {
 	// Assertions
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
-	assert.Equal(t, 1, result.Statistics["uploadedVideos"])
+	assert.Equal(t, 2, result.Metadata["totalVideos"])
+	assert.Equal(t, 1, result.Statistics["uploadedVideos"], "only uploads that succeeded count")
+	assert.Equal(t, 1, result.Statistics["failedVideos"])
+	assert.Equal(t, 1, result.Stats.Items)
+	assert.Equal(t, []modules.ItemResult{
+		{ID: "test.mp4", Status: modules.ItemSucceeded},
+		{ID: "missing.mp4", Status: modules.ItemFailed, Error: "failed to upload video missing.mp4: no such file"},
+	}, result.Items)
+	assert.Equal(t, []string{"missing.mp4"}, result.FailedItems(), "a retry uploads only the failed video")
 	assert.Equal(t, 60, result.Statistics["scheduleSpan"])
+	assert.Equal(t, 1702, result.Statistics["quotaUsed"])
+	assert.Equal(t, 8298, result.Statistics["quotaRemaining"])
 	assert.Contains(t, result.Outputs, "uploadStatus")
 
 	// Verify mock expectations
@@ -208,8 +256,8 @@ func TestModule_GetIO(t *testing.T) {
 	assert.Equal(t, "credentials", io.RequiredInputs[2].Name)
 
 	// Verify optional inputs
-	assert.Len(t, io.OptionalInputs, 5)
-	optionalInputNames := []string{"playlistId", "privacyStatus", "categoryId", "scheduleTime", "relatedVideoId"}
+	assert.Len(t, io.OptionalInputs, 6)
+	optionalInputNames := []string{"playlistId", "privacyStatus", "categoryId", "scheduleTime", "relatedVideoId", "decisions"}
 	for i, name := range optionalInputNames {
 		assert.Equal(t, name, io.OptionalInputs[i].Name)
 	}
@@ -241,7 +289,7 @@ func TestModule_CollectTagsAndRelatedVideo(t *testing.T) {
 	}
 
 	// Test with no related video ID
-	result, err := module.collectTagsAndRelatedVideo(mockYouTubeService, videoUploads, "")
+	result, err := module.collectTagsAndRelatedVideo(context.Background(), mockYouTubeService, videoUploads, "")
 	assert.NoError(t, err)
 	assert.Equal(t, videoUploads, result)
 
@@ -254,7 +302,7 @@ func TestModule_CollectTagsAndRelatedVideo(t *testing.T) {
 	}, nil)
 
 	// Test with related video ID
-	result, err = module.collectTagsAndRelatedVideo(mockYouTubeService, videoUploads, "test-video-id")
+	result, err = module.collectTagsAndRelatedVideo(context.Background(), mockYouTubeService, videoUploads, "test-video-id")
 	assert.NoError(t, err)
 	assert.Len(t, result, 1)
 	assert.Equal(t, "test-video-id", result[0].RelatedVideoID)
@@ -264,3 +312,156 @@ func TestModule_CollectTagsAndRelatedVideo(t *testing.T) {
 	// Verify mock expectations
 	mockService.AssertExpectations(t)
 }
+
+func TestModule_ExecuteQuotaDeferred(t *testing.T) {
+	tempDir := t.TempDir()
+	testYamlFile := filepath.Join(tempDir, "test.yaml")
+	testCredentialsFile := filepath.Join(tempDir, "credentials.json")
+	testShortsPath := filepath.Join(tempDir, "shorts")
+
+	if err := os.WriteFile(testYamlFile, []byte("shorts:\n  - title: \"Test Short\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(testCredentialsFile, []byte("test credentials"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	uploads := []youtube.VideoUpload{
+		{FileName: "a.mp4", ShortTitle: "First", PublishTime: time.Now()},
+		{FileName: "b.mp4", ShortTitle: "Second", PublishTime: time.Now()},
+		{FileName: "c.mp4", ShortTitle: "Third", PublishTime: time.Now()},
+	}
+
+	mockService := youtubemocks.NewMockYouTubeService(t)
+	mockYouTubeService := &youtubeapi.Service{}
+	mockService.On("ConfigureQuota", testCredentialsFile, youtube.QuotaOptions{DailyLimit: 5000, WarnThreshold: 0.5, Strategy: "defer"}).Return(nil)
+	mockService.On("InitializeYouTubeService", mock.Anything, testCredentialsFile).Return(mockYouTubeService, nil)
+	mockService.On("ReadScheduledVideos", mock.Anything, mockYouTubeService).Return([]youtube.ScheduledVideo{}, nil)
+	mockService.On("FindAvailability", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(uploads, nil)
+	mockService.On("ListAvailableTimes", mock.Anything).Return(nil)
+	mockService.On("UploadVideo", mock.Anything, mockYouTubeService, uploads, "private", "", testShortsPath).Return([]youtube.UploadResult{
+		{FileName: "a.mp4", VideoID: "vid1"},
+		{FileName: "b.mp4", Deferred: true},
+		{FileName: "c.mp4", Deferred: true},
+	}, nil)
+	mockService.On("QuotaStatus").Return(youtube.QuotaStatus{Used: 5000, Limit: 5000, Deferred: []string{"Second", "Third"}})
+
+	module := &Module{youtubeService: mockService}
+	result, err := module.Execute(context.Background(), map[string]interface{}{
+		"input":              testYamlFile,
+		"output":             tempDir,
+		"storedShortsPath":   testShortsPath,
+		"credentials":        testCredentialsFile,
+		"privacyStatus":      "private",
+		"dailyQuota":         5000,
+		"quotaWarnThreshold": 0.5,
+		"quotaStrategy":      "defer",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Statistics["uploadedVideos"])
+	assert.Equal(t, 0, result.Statistics["quotaRemaining"])
+	assert.Equal(t, []string{"Second", "Third"}, result.Metadata["deferredVideos"])
+	assert.Equal(t, []string{"b.mp4", "c.mp4"}, result.FailedItems(), "deferred videos are retried")
+}
+
+func TestModule_ExecuteRetryAndFailure(t *testing.T) {
+	tempDir := t.TempDir()
+	testYamlFile := filepath.Join(tempDir, "test.yaml")
+	testCredentialsFile := filepath.Join(tempDir, "credentials.json")
+	testShortsPath := filepath.Join(tempDir, "shorts")
+	require.NoError(t, os.WriteFile(testYamlFile, []byte("shorts:\n  - title: \"Test Short\"\n"), 0644))
+	require.NoError(t, os.WriteFile(testCredentialsFile, []byte("test credentials"), 0644))
+	params := map[string]interface{}{
+		"input":            testYamlFile,
+		"output":           tempDir,
+		"storedShortsPath": testShortsPath,
+		"credentials":      testCredentialsFile,
+		"privacyStatus":    "private",
+	}
+
+	uploads := []youtube.VideoUpload{
+		{FileName: "a.mp4", ShortTitle: "First", PublishTime: time.Now()},
+		{FileName: "b.mp4", ShortTitle: "Second", PublishTime: time.Now()},
+	}
+	newService := func(toUpload []youtube.VideoUpload, results []youtube.UploadResult) *youtubemocks.MockYouTubeService {
+		mockService := youtubemocks.NewMockYouTubeService(t)
+		mockYouTubeService := &youtubeapi.Service{}
+		mockService.On("ConfigureQuota", testCredentialsFile, youtube.QuotaOptions{}).Return(nil)
+		mockService.On("InitializeYouTubeService", mock.Anything, testCredentialsFile).Return(mockYouTubeService, nil)
+		mockService.On("ReadScheduledVideos", mock.Anything, mockYouTubeService).Return([]youtube.ScheduledVideo{}, nil)
+		mockService.On("FindAvailability", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(uploads, nil)
+		mockService.On("ListAvailableTimes", toUpload).Return(nil)
+		mockService.On("UploadVideo", mock.Anything, mockYouTubeService, toUpload, "private", "", testShortsPath).Return(results, nil)
+		mockService.On("QuotaStatus").Return(youtube.QuotaStatus{}).Maybe()
+		return mockService
+	}
+
+	// A retry uploads only the video that failed
+	module := &Module{youtubeService: newService(uploads[1:], []youtube.UploadResult{{FileName: "b.mp4", VideoID: "vid2"}})}
+	result, err := module.Execute(modules.WithRetryItems(context.Background(), []string{"b.mp4"}), params)
+	require.NoError(t, err)
+	assert.Equal(t, []modules.ItemResult{
+		{ID: "a.mp4", Status: modules.ItemSkipped},
+		{ID: "b.mp4", Status: modules.ItemSucceeded},
+	}, result.Items)
+	assert.Equal(t, 2, result.Metadata["totalVideos"])
+
+	// The step fails when no video is uploaded
+	module = &Module{youtubeService: newService(uploads, []youtube.UploadResult{
+		{FileName: "a.mp4", Err: errors.New("upload refused")},
+		{FileName: "b.mp4", Err: errors.New("upload refused")},
+	})}
+	_, err = module.Execute(context.Background(), params)
+	assert.EqualError(t, err, "failed to upload video a.mp4: upload refused")
+}
+
+func TestModule_ExecutePreviewUploads(t *testing.T) {
+	tempDir := t.TempDir()
+	testYamlFile := filepath.Join(tempDir, "test.yaml")
+	testCredentialsFile := filepath.Join(tempDir, "credentials.json")
+	testShortsPath := filepath.Join(tempDir, "shorts")
+	if err := os.WriteFile(testYamlFile, []byte("shorts:\n  - title: \"Test Short\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(testCredentialsFile, []byte("test credentials"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	utils.SetPreviewUploads(true)
+	defer utils.SetPreviewUploads(false)
+
+	// Only the schedule is computed; nothing is read from or sent to the API
+	publishAt := time.Date(2025, 3, 1, 18, 0, 0, 0, time.UTC)
+	mockService := youtubemocks.NewMockYouTubeService(t)
+	mockService.On("FindAvailability", []youtube.ScheduledVideo(nil), mock.Anything, 2, "18:00", 60, "2025-03-01", "PL123").Return([]youtube.VideoUpload{
+		{FileName: "000100-000130-withtext.mp4", ShortTitle: "Test Video", Description: "Watch this", Tags: "Go, Testing", PublishTime: publishAt, PlaylistID: "PL123"},
+	}, nil)
+
+	module := &Module{youtubeService: mockService}
+	result, err := module.Execute(context.Background(), map[string]interface{}{
+		"input":               testYamlFile,
+		"output":              tempDir,
+		"storedShortsPath":    testShortsPath,
+		"credentials":         testCredentialsFile,
+		"privacyStatus":       "private",
+		"categoryId":          "28",
+		"schedulePeriodicity": 2,
+		"scheduleTime":        "18:00",
+		"startDate":           "2025-03-01",
+		"playlistId":          "PL123",
+		"account":             "Studio Channel",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Stats.Items)
+
+	data, err := os.ReadFile(result.Outputs["uploadsPreview"])
+	assert.NoError(t, err)
+	preview := string(data)
+	assert.Contains(t, preview, "account: Studio Channel")
+	assert.Contains(t, preview, "credentials: "+testCredentialsFile)
+	assert.Contains(t, preview, "missing: true")
+	assert.Contains(t, preview, "publishAt: \"2025-03-01T18:00:00Z\"")
+	assert.Contains(t, preview, "categoryId: \"28\"")
+	assert.Contains(t, preview, "privacyStatus: private")
+	assert.Contains(t, preview, "- testing", "tags are cleaned as they are at upload")
+}
@@ -0,0 +1,230 @@
+// Package scheduler limits how many steps of each resource class run at once. Steps are
+// classified by what they wait on (the GPU, the CPU, the disk or a remote API), so a machine
+// running several steps keeps every resource busy without running two GPU-heavy steps at once.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Class is the resource a step mostly waits on
+type Class string
+
+const (
+	GPU Class = "gpu" // Transcription with Whisper, hardware encodes
+	CPU Class = "cpu" // Video encodes and analysis with FFmpeg
+	IO  Class = "io"  // Audio extraction, file conversions and reports
+	API Class = "api" // LLM calls and uploads, bound by the network and rate limits
+)
+
+// Classes lists the classes in the order they are shown
+var Classes = []Class{GPU, CPU, IO, API}
+
+// moduleClasses is the class of the steps of each module; other modules are CPU-heavy
+var moduleClasses = map[string]Class{
+	"transcribe":          GPU,
+	"extractaudio":        IO,
+	"split":               IO,
+	"merge_subtitles":     IO,
+	"clean_text":          IO,
+	"export_timeline":     IO,
+	"shorts_report":       IO,
+	"link_shorts":         IO,
+	"transcript_index":    IO,
+	"correct_transcript":  API,
+	"suggest_shorts":      API,
+	"suggest_sns_content": API,
+	"suggest_broll":       API,
+	"rate_shorts":         API,
+	"blog_post":           API,
+	"newsletter":          API,
+	"uploadyoutubeshorts": API,
+	"uploadtiktokshorts":  API,
+}
+
+// DefaultClass returns the class of the steps of a module
+func DefaultClass(module string) Class {
+	if class, ok := moduleClasses[module]; ok {
+		return class
+	}
+	return CPU
+}
+
+// ParseClass returns the class named by s
+func ParseClass(s string) (Class, error) {
+	for _, class := range Classes {
+		if strings.EqualFold(s, string(class)) {
+			return class, nil
+		}
+	}
+	return "", fmt.Errorf("unknown step class %q (supported: %s)", s, classNames())
+}
+
+// classNames returns the names of the classes, separated by commas
+func classNames() string {
+	names := make([]string, len(Classes))
+	for i, class := range Classes {
+		names[i] = string(class)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Limits is the number of steps of each class allowed to run at once
+type Limits map[Class]int
+
+// DefaultLimits returns the limits of a machine with one GPU: one GPU step, a CPU-heavy step per
+// 8 CPUs (FFmpeg encodes use several threads each), two IO steps and four API steps
+func DefaultLimits() Limits {
+	return Limits{
+		GPU: 1,
+		CPU: max(runtime.NumCPU()/8, 1),
+		IO:  2,
+		API: 4,
+	}
+}
+
+// ParseLimits returns the default limits with those of overrides, given by class name
+func ParseLimits(overrides map[string]int) (Limits, error) {
+	limits := DefaultLimits()
+	for name, limit := range overrides {
+		class, err := ParseClass(name)
+		if err != nil {
+			return nil, err
+		}
+		if limit < 1 {
+			return nil, fmt.Errorf("limit of %s steps must be at least 1", class)
+		}
+		limits[class] = limit
+	}
+	return limits, nil
+}
+
+// String formats the limits as gpu=1, cpu=2, ...
+func (l Limits) String() string {
+	parts := make([]string, 0, len(l))
+	for _, class := range Classes {
+		if limit, ok := l[class]; ok {
+			parts = append(parts, fmt.Sprintf("%s=%d", class, limit))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// waiter is a step waiting for a slot of its class
+type waiter struct {
+	priority int
+	seq      uint64
+	ready    chan struct{}
+}
+
+// Scheduler hands out the slots of each class. When a slot frees, the waiting step with the
+// highest priority takes it, and steps of the same priority take slots in the order they asked.
+type Scheduler struct {
+	mu      sync.Mutex
+	limits  Limits
+	running map[Class]int
+	waiting map[Class][]*waiter
+	seq     uint64
+}
+
+// New returns a scheduler with the given limits; classes without one run a step at a time
+func New(limits Limits) *Scheduler {
+	return &Scheduler{
+		limits:  limits,
+		running: make(map[Class]int),
+		waiting: make(map[Class][]*waiter),
+	}
+}
+
+// Limits returns the limits of the scheduler
+func (s *Scheduler) Limits() Limits {
+	return s.limits
+}
+
+// limit returns the number of steps of class allowed at once
+func (s *Scheduler) limit(class Class) int {
+	return max(s.limits[class], 1)
+}
+
+// Acquire waits for a slot of class and returns the function that frees it. Higher priorities go
+// first. It fails when ctx is done first.
+func (s *Scheduler) Acquire(ctx context.Context, class Class, priority int) (func(), error) {
+	s.mu.Lock()
+	if s.running[class] < s.limit(class) && len(s.waiting[class]) == 0 {
+		s.running[class]++
+		s.mu.Unlock()
+		return s.releaser(class), nil
+	}
+	s.seq++
+	w := &waiter{priority: priority, seq: s.seq, ready: make(chan struct{})}
+	queue := append(s.waiting[class], w)
+	sort.SliceStable(queue, func(i, j int) bool {
+		if queue[i].priority != queue[j].priority {
+			return queue[i].priority > queue[j].priority
+		}
+		return queue[i].seq < queue[j].seq
+	})
+	s.waiting[class] = queue
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return s.releaser(class), nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		select {
+		case <-w.ready:
+			// The slot was handed over as ctx ended; pass it on
+			s.release(class)
+		default:
+			s.remove(class, w)
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// Status returns the number of steps running and waiting for a slot of class
+func (s *Scheduler) Status(class Class) (running, waiting int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running[class], len(s.waiting[class])
+}
+
+// releaser returns the function freeing a slot of class, which does nothing after the first call
+func (s *Scheduler) releaser(class Class) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.release(class)
+		})
+	}
+}
+
+// release frees a slot of class, or hands it to the first waiting step; the caller holds the lock
+func (s *Scheduler) release(class Class) {
+	if queue := s.waiting[class]; len(queue) > 0 {
+		s.waiting[class] = queue[1:]
+		close(queue[0].ready)
+		return
+	}
+	s.running[class]--
+}
+
+// remove takes a waiter out of the queue of class; the caller holds the lock
+func (s *Scheduler) remove(class Class, w *waiter) {
+	queue := s.waiting[class]
+	for i, queued := range queue {
+		if queued == w {
+			s.waiting[class] = append(queue[:i:i], queue[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultClass(t *testing.T) {
+	assert.Equal(t, GPU, DefaultClass("transcribe"))
+	assert.Equal(t, API, DefaultClass("suggest_shorts"))
+	assert.Equal(t, IO, DefaultClass("extractaudio"))
+	assert.Equal(t, CPU, DefaultClass("extract_shorts"))
+	assert.Equal(t, CPU, DefaultClass("custom"))
+}
+
+func TestParseLimits(t *testing.T) {
+	limits, err := ParseLimits(map[string]int{"GPU": 2, "api": 8})
+	require.NoError(t, err)
+	assert.Equal(t, 2, limits[GPU])
+	assert.Equal(t, 8, limits[API])
+	assert.Equal(t, DefaultLimits()[IO], limits[IO])
+
+	_, err = ParseLimits(map[string]int{"tpu": 1})
+	assert.ErrorContains(t, err, `unknown step class "tpu"`)
+	_, err = ParseLimits(map[string]int{"cpu": 0})
+	assert.ErrorContains(t, err, "at least 1")
+}
+
+// acquired reports whether a slot was handed out within a short wait
+func acquired(t *testing.T, result <-chan func()) func() {
+	t.Helper()
+	select {
+	case release := <-result:
+		return release
+	case <-time.After(50 * time.Millisecond):
+		return nil
+	}
+}
+
+// acquire asks for a slot in the background
+func acquire(s *Scheduler, ctx context.Context, class Class, priority int) <-chan func() {
+	result := make(chan func(), 1)
+	go func() {
+		if release, err := s.Acquire(ctx, class, priority); err == nil {
+			result <- release
+		}
+	}()
+	return result
+}
+
+func TestScheduler_LimitsEachClass(t *testing.T) {
+	s := New(Limits{GPU: 1, API: 2})
+	ctx := context.Background()
+
+	gpu := acquired(t, acquire(s, ctx, GPU, 0))
+	require.NotNil(t, gpu)
+	require.NotNil(t, acquired(t, acquire(s, ctx, API, 0)))
+	require.NotNil(t, acquired(t, acquire(s, ctx, API, 0)), "a busy GPU does not hold back API steps")
+
+	second := acquire(s, ctx, GPU, 0)
+	assert.Nil(t, acquired(t, second), "a second GPU step waits")
+	running, waiting := s.Status(GPU)
+	assert.Equal(t, 1, running)
+	assert.Equal(t, 1, waiting)
+
+	gpu()
+	gpu() // Freeing twice frees once
+	require.NotNil(t, acquired(t, second))
+	running, waiting = s.Status(GPU)
+	assert.Equal(t, 1, running)
+	assert.Equal(t, 0, waiting)
+}
+
+func TestScheduler_Priority(t *testing.T) {
+	s := New(Limits{CPU: 1})
+	ctx := context.Background()
+	release, err := s.Acquire(ctx, CPU, 0)
+	require.NoError(t, err)
+
+	low := acquire(s, ctx, CPU, 0)
+	require.Eventually(t, func() bool { _, waiting := s.Status(CPU); return waiting == 1 }, time.Second, time.Millisecond)
+	high := acquire(s, ctx, CPU, 10)
+	require.Eventually(t, func() bool { _, waiting := s.Status(CPU); return waiting == 2 }, time.Second, time.Millisecond)
+
+	release()
+	next := acquired(t, high)
+	require.NotNil(t, next, "the higher priority goes first")
+	assert.Nil(t, acquired(t, low))
+	next()
+	assert.NotNil(t, acquired(t, low))
+}
+
+func TestScheduler_Cancel(t *testing.T) {
+	s := New(Limits{GPU: 1})
+	release, err := s.Acquire(context.Background(), GPU, 0)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = s.Acquire(ctx, GPU, 0)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	release()
+	running, waiting := s.Status(GPU)
+	assert.Equal(t, 0, running)
+	assert.Equal(t, 0, waiting)
+}
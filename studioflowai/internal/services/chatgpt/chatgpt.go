@@ -16,7 +16,11 @@ import (
 
 // ChatGPTService provides a centralized way to interact with OpenAI's ChatGPT API
 type ChatGPTService struct {
-	apiKey string
+	apiKey       string
+	organization string
+	project      string
+	httpClient   *http.Client
+	limiter      *RateLimiter
 }
 
 // ChatMessage represents a message in the ChatGPT conversation
@@ -31,6 +35,8 @@ type ChatRequest struct {
 	Messages    []ChatMessage `json:"messages"`
 	Temperature float64       `json:"temperature"`
 	MaxTokens   int           `json:"max_tokens,omitempty"`
+	Seed        int64         `json:"seed,omitempty"`
+	RandomSeed  int64         `json:"random_seed,omitempty"` // Mistral's name for the seed
 }
 
 // ChatResponse represents an OpenAI API response
@@ -75,11 +81,16 @@ func NewChatGPTService() (*ChatGPTService, error) {
 	}
 
 	return &ChatGPTService{
-		apiKey: apiKey,
+		apiKey:       apiKey,
+		organization: os.Getenv("OPENAI_ORG_ID"),
+		project:      os.Getenv("OPENAI_PROJECT_ID"),
+		httpClient:   utils.NewHTTPClient(),
+		limiter:      SharedRateLimiter(ProviderOpenAI),
 	}, nil
 }
 
-// Complete sends a completion request to the OpenAI API
+// Complete sends a completion request to the OpenAI API, or to another OpenAI-compatible
+// provider when opts.Model is a "provider:model" spec
 func (s *ChatGPTService) Complete(ctx context.Context, messages []ChatMessage, opts CompletionOptions) (*ChatResponse, error) {
 	// Create a timeout context if RequestTimeoutMS is specified
 	if opts.RequestTimeoutMS > 0 {
@@ -88,14 +99,39 @@ func (s *ChatGPTService) Complete(ctx context.Context, messages []ChatMessage, o
 		defer cancel()
 	}
 
+	// Route "provider:model" specs to the provider's OpenAI-compatible API
+	provider, model := SplitModel(opts.Model)
+	endpoint := "https://api.openai.com/v1/chat/completions"
+	apiKey := s.apiKey
+	limiter := s.limiter
+	if provider != ProviderOpenAI {
+		var err error
+		endpoint, apiKey, err = providerCredentials(provider)
+		if err != nil {
+			return nil, err
+		}
+		limiter = SharedRateLimiter(provider)
+	}
+
 	// Create the request body
 	reqBody := ChatRequest{
-		Model:       opts.Model,
+		Model:       model,
 		Messages:    messages,
 		Temperature: opts.Temperature,
 		MaxTokens:   opts.MaxTokens,
 	}
 
+	// Reproducible runs sample greedily and pass the run's seed to providers that take one
+	if utils.Deterministic() {
+		reqBody.Temperature = 0
+	}
+	switch seedParams[provider] {
+	case "seed":
+		reqBody.Seed = utils.RunSeed()
+	case "random_seed":
+		reqBody.RandomSeed = utils.RunSeed()
+	}
+
 	reqData, err := json.Marshal(reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
@@ -105,7 +141,7 @@ func (s *ChatGPTService) Complete(ctx context.Context, messages []ChatMessage, o
 	req, err := http.NewRequestWithContext(
 		ctx,
 		"POST",
-		"https://api.openai.com/v1/chat/completions",
+		endpoint,
 		bytes.NewBuffer(reqData),
 	)
 	if err != nil {
@@ -114,17 +150,38 @@ func (s *ChatGPTService) Complete(ctx context.Context, messages []ChatMessage, o
 
 	// Set headers
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	if provider == ProviderOpenAI {
+		if s.organization != "" {
+			req.Header.Set("OpenAI-Organization", s.organization)
+		}
+		if s.project != "" {
+			req.Header.Set("OpenAI-Project", s.project)
+		}
+	}
+
+	// Wait for capacity shared with all other calls to this provider in the run
+	estimate := EstimateTokens(messages, opts.MaxTokens)
+	if limiter != nil {
+		if err := limiter.Wait(ctx, estimate); err != nil {
+			return nil, fmt.Errorf("waiting for rate limit: %w", err)
+		}
+	}
 
 	// Send the request
-	client := &http.Client{}
+	client := s.httpClient
+	if client == nil {
+		client = utils.NewHTTPClient()
+	}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			utils.LogWarning("Failed to close response body: %v", err)
+			utils.LogWarningContext(ctx, "Failed to close response body: %v", err)
 		}
 	}()
 
@@ -135,6 +192,9 @@ func (s *ChatGPTService) Complete(ctx context.Context, messages []ChatMessage, o
 	}
 
 	// Check for API errors
+	if resp.StatusCode == http.StatusTooManyRequests && limiter != nil {
+		limiter.Backoff(retryAfter(resp.Header.Get("Retry-After")))
+	}
 	if resp.StatusCode != http.StatusOK {
 		var chatError ChatError
 		if err := json.Unmarshal(respBody, &chatError); err == nil {
@@ -149,6 +209,13 @@ func (s *ChatGPTService) Complete(ctx context.Context, messages []ChatMessage, o
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
+	// Replace the estimate with the usage reported by the API
+	if limiter != nil && chatResp.Usage.TotalTokens > 0 {
+		limiter.Adjust(chatResp.Usage.TotalTokens - estimate)
+	}
+	utils.RecordTokens(ctx, chatResp.Usage.TotalTokens,
+		EstimateCost(opts.Model, chatResp.Usage.PromptTokens, chatResp.Usage.CompletionTokens))
+
 	// Check if there are any choices in the response
 	if len(chatResp.Choices) == 0 {
 		return nil, errors.New("no response from ChatGPT")
@@ -167,6 +234,18 @@ func (s *ChatGPTService) GetContent(ctx context.Context, messages []ChatMessage,
 	return resp.Choices[0].Message.Content, nil
 }
 
+// PromptFunc returns a function sending a single user prompt to model, for helpers that only need
+// text back. The service is created on each call, so no API key is needed until it is used.
+func PromptFunc(model string) func(context.Context, string) (string, error) {
+	return func(ctx context.Context, prompt string) (string, error) {
+		service, err := NewChatGPTService()
+		if err != nil {
+			return "", err
+		}
+		return service.GetContent(ctx, []ChatMessage{{Role: "user", Content: prompt}}, CompletionOptions{Model: model})
+	}
+}
+
 // IsAPIKeySet checks if the OpenAI API key is set in the environment
 func IsAPIKeySet() bool {
 	return os.Getenv("OPENAI_API_KEY") != ""
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// redirectTransport sends every request to a test server, keeping its path and headers
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (t redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestComplete_OpenAIHeaders(t *testing.T) {
+	var got http.Header
+	var gotHost, gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		gotHost = r.Host
+		gotPath = r.URL.Path
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"ok"}}]}`))
+	}))
+	defer server.Close()
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	t.Setenv("OPENAI_API_KEY", "sk-test")
+	t.Setenv("OPENAI_ORG_ID", "org-studio")
+	t.Setenv("OPENAI_PROJECT_ID", "proj-shows")
+	service, err := NewChatGPTService()
+	require.NoError(t, err)
+	service.httpClient = &http.Client{Transport: redirectTransport{target: target}}
+
+	content, err := service.GetContent(context.Background(), []ChatMessage{{Role: "user", Content: "hi"}}, CompletionOptions{Model: "gpt-4o"})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", content)
+
+	assert.Equal(t, "api.openai.com", gotHost)
+	assert.Equal(t, "/v1/chat/completions", gotPath)
+	assert.Equal(t, "Bearer sk-test", got.Get("Authorization"))
+	assert.Equal(t, "org-studio", got.Get("OpenAI-Organization"))
+	assert.Equal(t, "proj-shows", got.Get("OpenAI-Project"))
+
+	// Without an organization or project the headers are left out
+	t.Setenv("OPENAI_ORG_ID", "")
+	t.Setenv("OPENAI_PROJECT_ID", "")
+	service, err = NewChatGPTService()
+	require.NoError(t, err)
+	service.httpClient = &http.Client{Transport: redirectTransport{target: target}}
+	_, err = service.GetContent(context.Background(), []ChatMessage{{Role: "user", Content: "hi"}}, CompletionOptions{Model: "gpt-4o"})
+	require.NoError(t, err)
+	assert.NotContains(t, got, "Openai-Organization")
+	assert.NotContains(t, got, "Openai-Project")
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+// DefaultEmbeddingModel is the OpenAI model used to embed text when none is configured
+const DefaultEmbeddingModel = "text-embedding-3-small"
+
+// embeddingBatchSize is the number of texts sent in a single embeddings request
+const embeddingBatchSize = 100
+
+// Embedder turns text into vectors for similarity search
+type Embedder interface {
+	// Embed returns one vector per text, in the order of texts
+	Embed(ctx context.Context, texts []string, model string) ([][]float32, error)
+}
+
+// Ensure ChatGPTService implements Embedder
+var _ Embedder = (*ChatGPTService)(nil)
+
+// embeddingRequest represents an OpenAI embeddings request
+type embeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// embeddingResponse represents an OpenAI embeddings response
+type embeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+	Usage struct {
+		TotalTokens int `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+// Embed sends texts to the OpenAI embeddings API in batches. Only OpenAI models are supported.
+func (s *ChatGPTService) Embed(ctx context.Context, texts []string, model string) ([][]float32, error) {
+	if model == "" {
+		model = DefaultEmbeddingModel
+	}
+	if provider, _ := SplitModel(model); provider != ProviderOpenAI {
+		return nil, fmt.Errorf("embeddings are only supported with OpenAI models, got %s", model)
+	}
+
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += embeddingBatchSize {
+		end := min(start+embeddingBatchSize, len(texts))
+		batch, err := s.embedBatch(ctx, texts[start:end], model)
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, batch...)
+	}
+	return vectors, nil
+}
+
+// embedBatch embeds one request worth of texts
+func (s *ChatGPTService) embedBatch(ctx context.Context, texts []string, model string) ([][]float32, error) {
+	reqData, err := json.Marshal(embeddingRequest{Model: model, Input: texts})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.openai.com/v1/embeddings", bytes.NewBuffer(reqData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	if s.organization != "" {
+		req.Header.Set("OpenAI-Organization", s.organization)
+	}
+	if s.project != "" {
+		req.Header.Set("OpenAI-Project", s.project)
+	}
+
+	// Embeddings share the token budget of the provider; roughly four characters per token
+	estimate := 0
+	for _, text := range texts {
+		estimate += len(text)/4 + 1
+	}
+	if s.limiter != nil {
+		if err := s.limiter.Wait(ctx, estimate); err != nil {
+			return nil, fmt.Errorf("waiting for rate limit: %w", err)
+		}
+	}
+
+	client := s.httpClient
+	if client == nil {
+		client = utils.NewHTTPClient()
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			utils.LogWarningContext(ctx, "Failed to close response body: %v", err)
+		}
+	}()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests && s.limiter != nil {
+		s.limiter.Backoff(retryAfter(resp.Header.Get("Retry-After")))
+	}
+	if resp.StatusCode != http.StatusOK {
+		var chatError ChatError
+		if err := json.Unmarshal(respBody, &chatError); err == nil {
+			return nil, fmt.Errorf("API error: %s", chatError.Error.Message)
+		}
+		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var embResp embeddingResponse
+	if err := json.Unmarshal(respBody, &embResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if s.limiter != nil && embResp.Usage.TotalTokens > 0 {
+		s.limiter.Adjust(embResp.Usage.TotalTokens - estimate)
+	}
+	utils.RecordTokens(ctx, embResp.Usage.TotalTokens, EstimateCost(model, embResp.Usage.TotalTokens, 0))
+	if len(embResp.Data) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(embResp.Data))
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, item := range embResp.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			return nil, fmt.Errorf("embedding index %d out of range", item.Index)
+		}
+		vectors[item.Index] = item.Embedding
+	}
+	return vectors, nil
+}
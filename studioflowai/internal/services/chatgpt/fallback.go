@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+// DefaultValidationAttempts is how many invalid responses a model may return before the next one is tried
+const DefaultValidationAttempts = 2
+
+// FallbackChain lists the models tried, in order, when the primary model fails
+type FallbackChain struct {
+	Models             []string // Fallback models, e.g. gpt-4o-mini or groq:llama-3.3-70b-versatile
+	ValidationAttempts int      // Responses per model that may fail validation (default: 2)
+	Reprompt           bool     // Answer an invalid response with its validation error and ask for a correction, instead of repeating the request
+}
+
+// Completion is the validated response of the model that answered
+type Completion struct {
+	Content  string
+	Model    string // Model that produced Content
+	Attempts int    // Requests made across all models
+}
+
+// CompleteWithFallback sends the request to opts.Model and then to each fallback model. A model is
+// abandoned when its request errors or times out, or when validate rejects ValidationAttempts
+// responses in a row. validate may be nil. Each attempt gets its own RequestTimeoutMS, so callers
+// should pass a context without that deadline. With chain.Reprompt, a rejected response is sent
+// back to the same model with the validation error, so it corrects its output rather than starting over.
+func CompleteWithFallback(ctx context.Context, service ChatGPTServicer, messages []ChatMessage, opts CompletionOptions, chain FallbackChain, validate func(string) error) (*Completion, error) {
+	attemptsPerModel := chain.ValidationAttempts
+	if attemptsPerModel <= 0 {
+		attemptsPerModel = DefaultValidationAttempts
+	}
+
+	models := []string{opts.Model}
+	for _, m := range chain.Models {
+		if m = strings.TrimSpace(m); m != "" && m != opts.Model {
+			models = append(models, m)
+		}
+	}
+
+	var failures []string
+	attempts := 0
+	for i, model := range models {
+		if i > 0 {
+			utils.LogWarningContext(ctx, "Falling back to model %s", model)
+		}
+
+		modelOpts := opts
+		modelOpts.Model = model
+		conversation := messages
+		for try := 1; try <= attemptsPerModel; try++ {
+			attempts++
+			content, err := service.GetContent(ctx, conversation, modelOpts)
+			if err != nil {
+				// The run was cancelled; no other model can help
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				utils.LogWarningContext(ctx, "Model %s failed: %v", model, err)
+				failures = append(failures, fmt.Sprintf("%s: %v", model, err))
+				break
+			}
+
+			if validate == nil {
+				return &Completion{Content: content, Model: model, Attempts: attempts}, nil
+			}
+			verr := validate(content)
+			if verr == nil {
+				return &Completion{Content: content, Model: model, Attempts: attempts}, nil
+			}
+			utils.LogWarningContext(ctx, "Model %s returned an invalid response (attempt %d of %d): %v", model, try, attemptsPerModel, verr)
+			if try == attemptsPerModel {
+				failures = append(failures, fmt.Sprintf("%s: invalid response: %v", model, verr))
+			} else if chain.Reprompt {
+				// Only the latest invalid response is kept, so the conversation does not grow with every try
+				conversation = append(append([]ChatMessage(nil), messages...),
+					ChatMessage{Role: "assistant", Content: content},
+					ChatMessage{Role: "user", Content: correctionPrompt(verr)})
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("all models failed: %w", errors.New(strings.Join(failures, "; ")))
+}
+
+// correctionPrompt asks the model to fix a response that failed validation
+func correctionPrompt(verr error) string {
+	return fmt.Sprintf("Your previous response could not be used: %v\n"+
+		"Correct it and reply again with the complete output in the same format, without any other text.", verr)
+}
+
+// ParseModelList splits a comma-separated list of models
+func ParseModelList(list string) []string {
+	var models []string
+	for _, m := range strings.Split(list, ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			models = append(models, m)
+		}
+	}
+	return models
+}
@@ -0,0 +1,189 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	services "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/chatgpt"
+	mocks "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/chatgpt/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// reply is the answer of one GetContent call to a model
+type reply struct {
+	model   string
+	content string
+	err     error
+}
+
+// forModel matches the completion options of a request to model
+func forModel(model string) interface{} {
+	return mock.MatchedBy(func(opts services.CompletionOptions) bool { return opts.Model == model })
+}
+
+// validJSON accepts responses that look like a JSON object
+func validJSON(content string) error {
+	if !strings.HasPrefix(content, "{") {
+		return errors.New("not a JSON object")
+	}
+	return nil
+}
+
+func TestCompleteWithFallback(t *testing.T) {
+	messages := []services.ChatMessage{{Role: "user", Content: "suggest"}}
+
+	tests := []struct {
+		name         string
+		chain        services.FallbackChain
+		validate     func(string) error
+		replies      []reply
+		wantContent  string
+		wantModel    string
+		wantAttempts int
+		wantErr      []string
+	}{
+		{
+			name:         "primary model answers",
+			replies:      []reply{{model: "gpt-4o", content: "done"}},
+			wantContent:  "done",
+			wantModel:    "gpt-4o",
+			wantAttempts: 1,
+		},
+		{
+			name:         "request error falls back",
+			chain:        services.FallbackChain{Models: []string{"groq:llama-3.3-70b-versatile"}},
+			replies:      []reply{{model: "gpt-4o", err: errors.New("API error: overloaded")}, {model: "groq:llama-3.3-70b-versatile", content: "done"}},
+			wantContent:  "done",
+			wantModel:    "groq:llama-3.3-70b-versatile",
+			wantAttempts: 2,
+		},
+		{
+			name:     "invalid responses use up the model's attempts",
+			chain:    services.FallbackChain{Models: []string{"gpt-4o-mini"}},
+			validate: validJSON,
+			replies: []reply{
+				{model: "gpt-4o", content: "sure!"},
+				{model: "gpt-4o", content: "here it is"},
+				{model: "gpt-4o-mini", content: "{}"},
+			},
+			wantContent:  "{}",
+			wantModel:    "gpt-4o-mini",
+			wantAttempts: 3,
+		},
+		{
+			name:     "validation attempts per model",
+			chain:    services.FallbackChain{Models: []string{"gpt-4o-mini"}, ValidationAttempts: 3},
+			validate: validJSON,
+			replies: []reply{
+				{model: "gpt-4o", content: "sure!"},
+				{model: "gpt-4o", content: "here it is"},
+				{model: "gpt-4o", content: "{}"},
+			},
+			wantContent:  "{}",
+			wantModel:    "gpt-4o",
+			wantAttempts: 3,
+		},
+		{
+			name:         "blank and repeated fallback models are skipped",
+			chain:        services.FallbackChain{Models: []string{" ", "gpt-4o", " gpt-4o-mini "}},
+			replies:      []reply{{model: "gpt-4o", err: errors.New("timeout")}, {model: "gpt-4o-mini", content: "done"}},
+			wantContent:  "done",
+			wantModel:    "gpt-4o-mini",
+			wantAttempts: 2,
+		},
+		{
+			name:     "all models fail",
+			chain:    services.FallbackChain{Models: []string{"mistral:mistral-large-latest"}, ValidationAttempts: 1},
+			validate: validJSON,
+			replies: []reply{
+				{model: "gpt-4o", err: errors.New("API error: overloaded")},
+				{model: "mistral:mistral-large-latest", content: "sure!"},
+			},
+			wantErr: []string{
+				"all models failed",
+				"gpt-4o: API error: overloaded",
+				"mistral:mistral-large-latest: invalid response: not a JSON object",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := mocks.NewMockChatGPTServicer(t)
+			for _, r := range tt.replies {
+				service.On("GetContent", mock.Anything, mock.Anything, forModel(r.model)).Return(r.content, r.err).Once()
+			}
+
+			got, err := services.CompleteWithFallback(context.Background(), service, messages,
+				services.CompletionOptions{Model: "gpt-4o"}, tt.chain, tt.validate)
+			if len(tt.wantErr) > 0 {
+				require.Error(t, err)
+				for _, want := range tt.wantErr {
+					assert.ErrorContains(t, err, want)
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantContent, got.Content)
+			assert.Equal(t, tt.wantModel, got.Model)
+			assert.Equal(t, tt.wantAttempts, got.Attempts)
+		})
+	}
+}
+
+func TestCompleteWithFallback_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	service := mocks.NewMockChatGPTServicer(t)
+	// The run is cancelled while the primary model answers; the fallback is never asked
+	service.On("GetContent", mock.Anything, mock.Anything, forModel("gpt-4o")).
+		Run(func(mock.Arguments) { cancel() }).
+		Return("", context.Canceled).Once()
+
+	_, err := services.CompleteWithFallback(ctx, service, []services.ChatMessage{{Role: "user", Content: "suggest"}},
+		services.CompletionOptions{Model: "gpt-4o"}, services.FallbackChain{Models: []string{"gpt-4o-mini"}}, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestCompleteWithFallback_Reprompt(t *testing.T) {
+	messages := []services.ChatMessage{
+		{Role: "system", Content: "Reply with JSON."},
+		{Role: "user", Content: "suggest"},
+	}
+	service := mocks.NewMockChatGPTServicer(t)
+
+	var conversations [][]services.ChatMessage
+	record := func(args mock.Arguments) {
+		conversations = append(conversations, args.Get(1).([]services.ChatMessage))
+	}
+	service.On("GetContent", mock.Anything, mock.Anything, forModel("gpt-4o")).Run(record).Return("sure!", nil).Once()
+	service.On("GetContent", mock.Anything, mock.Anything, forModel("gpt-4o")).Run(record).Return("here it is", nil).Once()
+	service.On("GetContent", mock.Anything, mock.Anything, forModel("gpt-4o")).Run(record).Return("{}", nil).Once()
+
+	got, err := services.CompleteWithFallback(context.Background(), service, messages,
+		services.CompletionOptions{Model: "gpt-4o"}, services.FallbackChain{ValidationAttempts: 3, Reprompt: true}, validJSON)
+	require.NoError(t, err)
+	assert.Equal(t, "{}", got.Content)
+	require.Len(t, conversations, 3)
+
+	assert.Equal(t, messages, conversations[0])
+
+	// The invalid response is answered with its validation error
+	require.Len(t, conversations[1], len(messages)+2)
+	assert.Equal(t, messages, conversations[1][:len(messages)])
+	assert.Equal(t, services.ChatMessage{Role: "assistant", Content: "sure!"}, conversations[1][2])
+	assert.Equal(t, "user", conversations[1][3].Role)
+	assert.Contains(t, conversations[1][3].Content, "not a JSON object")
+
+	// Only the latest invalid response is kept
+	require.Len(t, conversations[2], len(messages)+2)
+	assert.Equal(t, services.ChatMessage{Role: "assistant", Content: "here it is"}, conversations[2][2])
+}
+
+func TestParseModelList(t *testing.T) {
+	assert.Equal(t, []string{"gpt-4o-mini", "groq:llama-3.3-70b-versatile"}, services.ParseModelList(" gpt-4o-mini,, groq:llama-3.3-70b-versatile "))
+	assert.Nil(t, services.ParseModelList(""))
+}
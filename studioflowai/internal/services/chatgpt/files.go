@@ -0,0 +1,274 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+// FileCompleter answers prompts about uploaded files, for inputs too long to send in the prompt
+type FileCompleter interface {
+	// UploadFile uploads data under name and returns its file ID
+	UploadFile(ctx context.Context, name string, data []byte) (string, error)
+
+	// DeleteFile removes an uploaded file
+	DeleteFile(ctx context.Context, fileID string) error
+
+	// CompleteWithFiles sends messages with the files attached to the last user message
+	CompleteWithFiles(ctx context.Context, messages []ChatMessage, fileIDs []string, opts CompletionOptions) (*ChatResponse, error)
+}
+
+// Ensure ChatGPTService implements FileCompleter
+var _ FileCompleter = (*ChatGPTService)(nil)
+
+// FileChat is a ChatGPTServicer that attaches uploaded files to every request, so it can be used
+// with CompleteWithFallback. Models of other providers fail, as only OpenAI reads files.
+type FileChat struct {
+	Service FileCompleter
+	FileIDs []string
+}
+
+// Complete sends messages with the chat's files attached
+func (c FileChat) Complete(ctx context.Context, messages []ChatMessage, opts CompletionOptions) (*ChatResponse, error) {
+	return c.Service.CompleteWithFiles(ctx, messages, c.FileIDs, opts)
+}
+
+// GetContent returns the content of the first choice
+func (c FileChat) GetContent(ctx context.Context, messages []ChatMessage, opts CompletionOptions) (string, error) {
+	resp, err := c.Complete(ctx, messages, opts)
+	if err != nil {
+		return "", err
+	}
+	return resp.Choices[0].Message.Content, nil
+}
+
+// responsesContent is one part of a Responses API message
+type responsesContent struct {
+	Type   string `json:"type"`
+	Text   string `json:"text,omitempty"`
+	FileID string `json:"file_id,omitempty"`
+}
+
+// responsesMessage is a Responses API input message; Content is a string or a list of parts
+type responsesMessage struct {
+	Role    string      `json:"role"`
+	Content interface{} `json:"content"`
+}
+
+// responsesRequest represents an OpenAI Responses API request
+type responsesRequest struct {
+	Model           string             `json:"model"`
+	Input           []responsesMessage `json:"input"`
+	Temperature     float64            `json:"temperature"`
+	MaxOutputTokens int                `json:"max_output_tokens,omitempty"`
+}
+
+// responsesResponse represents an OpenAI Responses API response
+type responsesResponse struct {
+	ID        string `json:"id"`
+	CreatedAt int64  `json:"created_at"`
+	Status    string `json:"status"`
+	Output    []struct {
+		Type    string `json:"type"`
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+	} `json:"output"`
+	Usage struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+		TotalTokens  int `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+// UploadFile uploads data to the OpenAI files API for use as model input
+func (s *ChatGPTService) UploadFile(ctx context.Context, name string, data []byte) (string, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if err := form.WriteField("purpose", "user_data"); err != nil {
+		return "", fmt.Errorf("failed to create upload: %w", err)
+	}
+	part, err := form.CreateFormFile("file", name)
+	if err != nil {
+		return "", fmt.Errorf("failed to create upload: %w", err)
+	}
+	if _, err := part.Write(data); err != nil {
+		return "", fmt.Errorf("failed to create upload: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return "", fmt.Errorf("failed to create upload: %w", err)
+	}
+
+	respBody, err := s.openAIRequest(ctx, "POST", "https://api.openai.com/v1/files", &body, form.FormDataContentType())
+	if err != nil {
+		return "", fmt.Errorf("failed to upload %s: %w", name, err)
+	}
+	var file struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(respBody, &file); err != nil || file.ID == "" {
+		return "", fmt.Errorf("failed to parse upload response: %s", string(respBody))
+	}
+	utils.LogVerboseContext(ctx, "Uploaded %s as %s", name, file.ID)
+	return file.ID, nil
+}
+
+// DeleteFile deletes an uploaded file
+func (s *ChatGPTService) DeleteFile(ctx context.Context, fileID string) error {
+	if _, err := s.openAIRequest(ctx, "DELETE", "https://api.openai.com/v1/files/"+fileID, nil, ""); err != nil {
+		return fmt.Errorf("failed to delete file %s: %w", fileID, err)
+	}
+	return nil
+}
+
+// CompleteWithFiles sends messages to the OpenAI Responses API with the files attached to the last
+// user message. Only OpenAI models are supported.
+func (s *ChatGPTService) CompleteWithFiles(ctx context.Context, messages []ChatMessage, fileIDs []string, opts CompletionOptions) (*ChatResponse, error) {
+	provider, model := SplitModel(opts.Model)
+	if provider != ProviderOpenAI {
+		return nil, fmt.Errorf("file inputs are only supported with OpenAI models, got %s", opts.Model)
+	}
+	if opts.RequestTimeoutMS > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(opts.RequestTimeoutMS)*time.Millisecond)
+		defer cancel()
+	}
+
+	last := -1
+	for i, m := range messages {
+		if m.Role == "user" {
+			last = i
+		}
+	}
+	if last < 0 {
+		return nil, errors.New("file inputs need a user message")
+	}
+
+	input := make([]responsesMessage, len(messages))
+	for i, m := range messages {
+		input[i] = responsesMessage{Role: m.Role, Content: m.Content}
+	}
+	parts := make([]responsesContent, 0, len(fileIDs)+1)
+	for _, id := range fileIDs {
+		parts = append(parts, responsesContent{Type: "input_file", FileID: id})
+	}
+	input[last].Content = append(parts, responsesContent{Type: "input_text", Text: messages[last].Content})
+
+	reqBody := responsesRequest{
+		Model:           model,
+		Input:           input,
+		Temperature:     opts.Temperature,
+		MaxOutputTokens: opts.MaxTokens,
+	}
+	if utils.Deterministic() {
+		reqBody.Temperature = 0
+	}
+	reqData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// The size of the files is unknown here, so the prompt stands in for the estimate until usage is reported
+	estimate := EstimateTokens(messages, opts.MaxTokens)
+	if s.limiter != nil {
+		if err := s.limiter.Wait(ctx, estimate); err != nil {
+			return nil, fmt.Errorf("waiting for rate limit: %w", err)
+		}
+	}
+
+	respBody, err := s.openAIRequest(ctx, "POST", "https://api.openai.com/v1/responses", bytes.NewReader(reqData), "application/json")
+	if err != nil {
+		return nil, err
+	}
+
+	var resp responsesResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if s.limiter != nil && resp.Usage.TotalTokens > 0 {
+		s.limiter.Adjust(resp.Usage.TotalTokens - estimate)
+	}
+	utils.RecordTokens(ctx, resp.Usage.TotalTokens, EstimateCost(opts.Model, resp.Usage.InputTokens, resp.Usage.OutputTokens))
+
+	var text strings.Builder
+	for _, output := range resp.Output {
+		for _, content := range output.Content {
+			if content.Type == "output_text" {
+				text.WriteString(content.Text)
+			}
+		}
+	}
+	if text.Len() == 0 {
+		return nil, errors.New("no response from ChatGPT")
+	}
+
+	// Answer in the shape of a chat completion so callers handle both APIs alike
+	chatResp := &ChatResponse{ID: resp.ID, Object: "response", Created: resp.CreatedAt}
+	chatResp.Choices = append(chatResp.Choices, struct {
+		Index        int         `json:"index"`
+		Message      ChatMessage `json:"message"`
+		FinishReason string      `json:"finish_reason"`
+	}{Message: ChatMessage{Role: "assistant", Content: text.String()}, FinishReason: resp.Status})
+	chatResp.Usage.PromptTokens = resp.Usage.InputTokens
+	chatResp.Usage.CompletionTokens = resp.Usage.OutputTokens
+	chatResp.Usage.TotalTokens = resp.Usage.TotalTokens
+	return chatResp, nil
+}
+
+// openAIRequest sends a request to the OpenAI API and returns the body of a successful response
+func (s *ChatGPTService) openAIRequest(ctx context.Context, method, url string, body io.Reader, contentType string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	if s.organization != "" {
+		req.Header.Set("OpenAI-Organization", s.organization)
+	}
+	if s.project != "" {
+		req.Header.Set("OpenAI-Project", s.project)
+	}
+
+	client := s.httpClient
+	if client == nil {
+		client = utils.NewHTTPClient()
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			utils.LogWarningContext(ctx, "Failed to close response body: %v", err)
+		}
+	}()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests && s.limiter != nil {
+		s.limiter.Backoff(retryAfter(resp.Header.Get("Retry-After")))
+	}
+	if resp.StatusCode != http.StatusOK {
+		var chatError ChatError
+		if err := json.Unmarshal(respBody, &chatError); err == nil && chatError.Error.Message != "" {
+			return nil, fmt.Errorf("API error: %s", chatError.Error.Message)
+		}
+		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+	return respBody, nil
+}
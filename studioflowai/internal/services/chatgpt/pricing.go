@@ -0,0 +1,44 @@
+package services
+
+import "strings"
+
+// modelPrice is the list price of a model in US dollars per million tokens
+type modelPrice struct {
+	Input  float64
+	Output float64
+}
+
+// modelPrices lists the OpenAI prices used to estimate the cost of a run. Models are matched by
+// the longest prefix, so dated snapshots such as gpt-4o-2024-08-06 use their family's price.
+var modelPrices = map[string]modelPrice{
+	"gpt-4o":                 {Input: 2.50, Output: 10.00},
+	"gpt-4o-mini":            {Input: 0.15, Output: 0.60},
+	"gpt-4.1":                {Input: 2.00, Output: 8.00},
+	"gpt-4.1-mini":           {Input: 0.40, Output: 1.60},
+	"gpt-4.1-nano":           {Input: 0.10, Output: 0.40},
+	"gpt-4-turbo":            {Input: 10.00, Output: 30.00},
+	"gpt-4":                  {Input: 30.00, Output: 60.00},
+	"gpt-3.5-turbo":          {Input: 0.50, Output: 1.50},
+	"text-embedding-3-small": {Input: 0.02},
+	"text-embedding-3-large": {Input: 0.13},
+}
+
+// EstimateCost returns the estimated cost in US dollars of a request. Models of other providers
+// and unknown models cost 0, since their prices are not known.
+func EstimateCost(model string, promptTokens, completionTokens int) float64 {
+	provider, name := SplitModel(model)
+	if provider != ProviderOpenAI {
+		return 0
+	}
+	best := ""
+	for prefix := range modelPrices {
+		if strings.HasPrefix(name, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return 0
+	}
+	price := modelPrices[best]
+	return (float64(promptTokens)*price.Input + float64(completionTokens)*price.Output) / 1_000_000
+}
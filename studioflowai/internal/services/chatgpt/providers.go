@@ -0,0 +1,69 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// providerBaseURLs lists OpenAI-compatible chat completion APIs usable as "provider:model"
+var providerBaseURLs = map[string]string{
+	ProviderOpenAI: "https://api.openai.com/v1",
+	"groq":         "https://api.groq.com/openai/v1",
+	"mistral":      "https://api.mistral.ai/v1",
+	"openrouter":   "https://openrouter.ai/api/v1",
+	"ollama":       "http://localhost:11434/v1",
+}
+
+// seedParams names the request field each provider reads a sampling seed from
+var seedParams = map[string]string{
+	ProviderOpenAI: "seed",
+	"groq":         "seed",
+	"mistral":      "random_seed",
+	"openrouter":   "seed",
+	"ollama":       "seed",
+}
+
+// keylessProviders run locally and need no API key
+var keylessProviders = map[string]bool{
+	"ollama": true,
+}
+
+// providerEnvPrefix returns the environment prefix of a provider, e.g. GROQ
+func providerEnvPrefix(provider string) string {
+	return strings.ToUpper(strings.ReplaceAll(provider, "-", "_"))
+}
+
+// providerBaseURL returns the API base URL of a provider; STUDIOFLOWAI_<PROVIDER>_BASE_URL overrides the built-in one
+func providerBaseURL(provider string) string {
+	if url := os.Getenv("STUDIOFLOWAI_" + providerEnvPrefix(provider) + "_BASE_URL"); url != "" {
+		return strings.TrimSuffix(url, "/")
+	}
+	return providerBaseURLs[provider]
+}
+
+// SplitModel splits a "provider:model" spec. Plain model names, and names whose prefix
+// is not a known provider (such as "llama3:8b"), are OpenAI models.
+func SplitModel(spec string) (provider, model string) {
+	if prefix, rest, ok := strings.Cut(spec, ":"); ok && rest != "" {
+		if providerBaseURL(strings.ToLower(prefix)) != "" {
+			return strings.ToLower(prefix), rest
+		}
+	}
+	return ProviderOpenAI, spec
+}
+
+// providerCredentials returns the endpoint and API key of a provider other than OpenAI
+func providerCredentials(provider string) (endpoint, apiKey string, err error) {
+	baseURL := providerBaseURL(provider)
+	if baseURL == "" {
+		return "", "", fmt.Errorf("unknown provider %s (set STUDIOFLOWAI_%s_BASE_URL)", provider, providerEnvPrefix(provider))
+	}
+
+	keyVar := providerEnvPrefix(provider) + "_API_KEY"
+	apiKey = os.Getenv(keyVar)
+	if apiKey == "" && !keylessProviders[provider] {
+		return "", "", fmt.Errorf("%s environment variable is not set", keyVar)
+	}
+	return baseURL + "/chat/completions", apiKey, nil
+}
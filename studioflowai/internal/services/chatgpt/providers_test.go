@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitModel(t *testing.T) {
+	t.Setenv("STUDIOFLOWAI_ACME_BASE_URL", "https://llm.acme.test/v1")
+
+	tests := []struct {
+		spec         string
+		wantProvider string
+		wantModel    string
+	}{
+		{spec: "gpt-4o", wantProvider: ProviderOpenAI, wantModel: "gpt-4o"},
+		{spec: "groq:llama-3.3-70b-versatile", wantProvider: "groq", wantModel: "llama-3.3-70b-versatile"},
+		{spec: "Mistral:mistral-large-latest", wantProvider: "mistral", wantModel: "mistral-large-latest"},
+		{spec: "openrouter:meta-llama/llama-3-70b:free", wantProvider: "openrouter", wantModel: "meta-llama/llama-3-70b:free"},
+		{spec: "acme:house-model", wantProvider: "acme", wantModel: "house-model"},
+		{spec: "llama3:8b", wantProvider: ProviderOpenAI, wantModel: "llama3:8b"},
+		{spec: "groq:", wantProvider: ProviderOpenAI, wantModel: "groq:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			provider, model := SplitModel(tt.spec)
+			assert.Equal(t, tt.wantProvider, provider)
+			assert.Equal(t, tt.wantModel, model)
+		})
+	}
+}
+
+func TestProviderCredentials(t *testing.T) {
+	t.Setenv("GROQ_API_KEY", "groq-key")
+	t.Setenv("MISTRAL_API_KEY", "")
+	t.Setenv("STUDIOFLOWAI_OLLAMA_BASE_URL", "http://gpu-box:11434/v1/")
+
+	endpoint, key, err := providerCredentials("groq")
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.groq.com/openai/v1/chat/completions", endpoint)
+	assert.Equal(t, "groq-key", key)
+
+	_, _, err = providerCredentials("mistral")
+	assert.ErrorContains(t, err, "MISTRAL_API_KEY environment variable is not set")
+
+	endpoint, key, err = providerCredentials("ollama")
+	require.NoError(t, err, "local providers need no key")
+	assert.Equal(t, "http://gpu-box:11434/v1/chat/completions", endpoint)
+	assert.Empty(t, key)
+
+	_, _, err = providerCredentials("acme")
+	assert.ErrorContains(t, err, "STUDIOFLOWAI_ACME_BASE_URL")
+}
+
+func TestComplete_RoutesProviderModels(t *testing.T) {
+	var gotPath, gotAuth, gotOrg string
+	var gotRequest ChatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		gotOrg = r.Header.Get("OpenAI-Organization")
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&gotRequest))
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"hola"}}]}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("STUDIOFLOWAI_GROQ_BASE_URL", server.URL+"/openai/v1")
+	t.Setenv("GROQ_API_KEY", "groq-key")
+
+	service := &ChatGPTService{apiKey: "openai-key", organization: "org-1", httpClient: server.Client()}
+	content, err := service.GetContent(context.Background(), []ChatMessage{{Role: "user", Content: "hi"}},
+		CompletionOptions{Model: "groq:llama-3.3-70b-versatile"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "hola", content)
+	assert.Equal(t, "/openai/v1/chat/completions", gotPath)
+	assert.Equal(t, "llama-3.3-70b-versatile", gotRequest.Model, "the provider prefix is not sent")
+	assert.Equal(t, "Bearer groq-key", gotAuth, "the provider's key replaces the OpenAI key")
+	assert.Empty(t, gotOrg, "OpenAI headers stay with OpenAI")
+}
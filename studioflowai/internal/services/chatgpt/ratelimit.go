@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+// ProviderOpenAI is the rate limit key of the OpenAI API
+const ProviderOpenAI = "openai"
+
+// defaultRateLimits match the lowest paid OpenAI tier for gpt-4o; raise them for higher tiers
+var defaultRateLimits = map[string]RateLimit{
+	ProviderOpenAI: {RequestsPerMinute: 500, TokensPerMinute: 30000},
+}
+
+// RateLimit configures the throughput allowed for one provider. Zero disables a limit.
+type RateLimit struct {
+	RequestsPerMinute int
+	TokensPerMinute   int
+}
+
+// bucket is a token bucket refilled continuously up to its capacity
+type bucket struct {
+	capacity  float64
+	available float64
+	perSecond float64
+}
+
+func newBucket(perMinute int) *bucket {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &bucket{
+		capacity:  float64(perMinute),
+		available: float64(perMinute),
+		perSecond: float64(perMinute) / 60,
+	}
+}
+
+// refill adds the amount accumulated during elapsed
+func (b *bucket) refill(elapsed time.Duration) {
+	if b == nil {
+		return
+	}
+	b.available = math.Min(b.capacity, b.available+elapsed.Seconds()*b.perSecond)
+}
+
+// wait returns how long until amount is available
+func (b *bucket) wait(amount float64) time.Duration {
+	if b == nil || b.available >= amount {
+		return 0
+	}
+	return time.Duration((amount - b.available) / b.perSecond * float64(time.Second))
+}
+
+// RateLimiter throttles requests and tokens per minute for one provider.
+// One limiter is shared by every module of a run, so concurrent calls queue instead of failing.
+type RateLimiter struct {
+	mu           sync.Mutex
+	provider     string
+	requests     *bucket
+	tokens       *bucket
+	last         time.Time
+	blockedUntil time.Time
+}
+
+// NewRateLimiter creates a rate limiter for a provider
+func NewRateLimiter(provider string, limit RateLimit) *RateLimiter {
+	return &RateLimiter{
+		provider: provider,
+		requests: newBucket(limit.RequestsPerMinute),
+		tokens:   newBucket(limit.TokensPerMinute),
+		last:     time.Now(),
+	}
+}
+
+// Wait blocks until one request and the estimated tokens fit within the limits
+func (l *RateLimiter) Wait(ctx context.Context, tokens int) error {
+	logged := false
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.requests.refill(now.Sub(l.last))
+		l.tokens.refill(now.Sub(l.last))
+		l.last = now
+
+		// A single request larger than the bucket would otherwise wait forever
+		cost := float64(tokens)
+		if l.tokens != nil && cost > l.tokens.capacity {
+			cost = l.tokens.capacity
+		}
+
+		delay := l.blockedUntil.Sub(now)
+		if d := l.requests.wait(1); d > delay {
+			delay = d
+		}
+		if d := l.tokens.wait(cost); d > delay {
+			delay = d
+		}
+
+		if delay <= 0 {
+			if l.requests != nil {
+				l.requests.available--
+			}
+			if l.tokens != nil {
+				l.tokens.available -= cost
+			}
+			l.mu.Unlock()
+			return nil
+		}
+		l.mu.Unlock()
+
+		if !logged {
+			utils.LogVerboseContext(ctx, "Rate limit for %s reached, waiting %s", l.provider, delay.Round(time.Millisecond))
+			logged = true
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Adjust corrects the token estimate once the actual usage is known; delta may be negative
+func (l *RateLimiter) Adjust(delta int) {
+	if l.tokens == nil || delta == 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens.available = math.Min(l.tokens.capacity, l.tokens.available-float64(delta))
+}
+
+// Backoff pauses all callers, used when the provider answers 429 Too Many Requests
+func (l *RateLimiter) Backoff(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := time.Now().Add(d); until.After(l.blockedUntil) {
+		l.blockedUntil = until
+	}
+	utils.LogWarning("%s rate limit exceeded, pausing requests for %s", l.provider, d.Round(time.Second))
+}
+
+var (
+	limitersMu sync.Mutex
+	limiters   = make(map[string]*RateLimiter)
+)
+
+// SharedRateLimiter returns the process-wide rate limiter of a provider.
+// Limits come from STUDIOFLOWAI_<PROVIDER>_RPM and STUDIOFLOWAI_<PROVIDER>_TPM, falling back to the defaults.
+func SharedRateLimiter(provider string) *RateLimiter {
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+
+	if limiter, ok := limiters[provider]; ok {
+		return limiter
+	}
+	limiter := NewRateLimiter(provider, rateLimitFromEnv(provider))
+	limiters[provider] = limiter
+	return limiter
+}
+
+// SetRateLimit replaces the shared rate limiter of a provider
+func SetRateLimit(provider string, limit RateLimit) {
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+	limiters[provider] = NewRateLimiter(provider, limit)
+}
+
+// rateLimitFromEnv reads the limits of a provider from the environment
+func rateLimitFromEnv(provider string) RateLimit {
+	limit := defaultRateLimits[provider]
+	prefix := "STUDIOFLOWAI_" + strings.ToUpper(provider)
+	if v, ok := envInt(prefix + "_RPM"); ok {
+		limit.RequestsPerMinute = v
+	}
+	if v, ok := envInt(prefix + "_TPM"); ok {
+		limit.TokensPerMinute = v
+	}
+	return limit
+}
+
+// envInt parses an integer environment variable, warning about invalid values
+func envInt(name string) (int, bool) {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		utils.LogWarning("Ignoring %s=%q: expected a non-negative integer", name, value)
+		return 0, false
+	}
+	return n, true
+}
+
+// EstimateTokens approximates the tokens a request counts against the limit:
+// about four characters per prompt token plus the requested completion size
+func EstimateTokens(messages []ChatMessage, maxTokens int) int {
+	chars := 0
+	for _, m := range messages {
+		chars += len(m.Content) + len(m.Role)
+	}
+	return chars/4 + len(messages)*4 + maxTokens
+}
+
+// retryAfter reads the Retry-After header, defaulting to 20 seconds
+func retryAfter(header string) time.Duration {
+	if secs, err := strconv.Atoi(strings.TrimSpace(header)); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	return 20 * time.Second
+}
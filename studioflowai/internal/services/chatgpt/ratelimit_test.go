@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cancelledContext returns a context that is already done, so a Wait that has to queue
+// returns at once instead of sleeping
+func cancelledContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}
+
+func TestBucket(t *testing.T) {
+	b := newBucket(60)
+	require.NotNil(t, b)
+	assert.Equal(t, 60.0, b.capacity)
+	assert.Equal(t, 1.0, b.perSecond)
+
+	b.available = 0
+	assert.Equal(t, 5*time.Second, b.wait(5))
+	b.refill(2 * time.Second)
+	assert.Equal(t, 2.0, b.available)
+	assert.Zero(t, b.wait(2))
+	b.refill(time.Hour)
+	assert.Equal(t, 60.0, b.available, "refills stop at the capacity")
+
+	assert.Nil(t, newBucket(0), "zero disables a limit")
+	var disabled *bucket
+	disabled.refill(time.Second)
+	assert.Zero(t, disabled.wait(1000))
+}
+
+func TestRateLimiter_Wait(t *testing.T) {
+	limiter := NewRateLimiter("test", RateLimit{RequestsPerMinute: 2, TokensPerMinute: 1000})
+
+	require.NoError(t, limiter.Wait(context.Background(), 100))
+	require.NoError(t, limiter.Wait(context.Background(), 100))
+	assert.InDelta(t, 800, limiter.tokens.available, 1)
+
+	// The third request in the minute has to queue
+	assert.ErrorIs(t, limiter.Wait(cancelledContext(), 100), context.Canceled)
+	assert.InDelta(t, 800, limiter.tokens.available, 1, "a cancelled wait takes nothing")
+
+	// So does a request whose tokens do not fit
+	limiter = NewRateLimiter("test", RateLimit{TokensPerMinute: 1000})
+	require.NoError(t, limiter.Wait(context.Background(), 900))
+	assert.ErrorIs(t, limiter.Wait(cancelledContext(), 200), context.Canceled)
+
+	// Without limits nothing queues
+	limiter = NewRateLimiter("test", RateLimit{})
+	for range 10 {
+		require.NoError(t, limiter.Wait(context.Background(), 1_000_000))
+	}
+}
+
+func TestRateLimiter_WaitClampsToCapacity(t *testing.T) {
+	limiter := NewRateLimiter("test", RateLimit{TokensPerMinute: 1000})
+
+	// A request larger than the bucket takes the full bucket instead of waiting forever
+	require.NoError(t, limiter.Wait(context.Background(), 5000))
+	assert.InDelta(t, 0, limiter.tokens.available, 1)
+	assert.ErrorIs(t, limiter.Wait(cancelledContext(), 1), context.Canceled)
+}
+
+func TestRateLimiter_Adjust(t *testing.T) {
+	limiter := NewRateLimiter("test", RateLimit{TokensPerMinute: 1000})
+	require.NoError(t, limiter.Wait(context.Background(), 400))
+
+	// The response used more tokens than estimated
+	limiter.Adjust(300)
+	assert.InDelta(t, 300, limiter.tokens.available, 1)
+
+	// Unused estimates are returned, but never beyond the capacity
+	limiter.Adjust(-5000)
+	assert.Equal(t, 1000.0, limiter.tokens.available)
+
+	// Usage beyond the estimate can overdraw the bucket, delaying the next request
+	limiter.Adjust(1500)
+	assert.InDelta(t, -500, limiter.tokens.available, 1)
+	assert.ErrorIs(t, limiter.Wait(cancelledContext(), 1), context.Canceled)
+
+	// Limiters without a token limit ignore adjustments
+	NewRateLimiter("test", RateLimit{RequestsPerMinute: 10}).Adjust(100)
+}
+
+func TestRateLimiter_Backoff(t *testing.T) {
+	limiter := NewRateLimiter("test", RateLimit{})
+
+	limiter.Backoff(time.Minute)
+	until := limiter.blockedUntil
+	assert.WithinDuration(t, time.Now().Add(time.Minute), until, time.Second)
+
+	// Every caller pauses, even without limits
+	assert.ErrorIs(t, limiter.Wait(cancelledContext(), 1), context.Canceled)
+
+	// A shorter backoff does not cut the pause short
+	limiter.Backoff(time.Second)
+	assert.Equal(t, until, limiter.blockedUntil)
+
+	// Once the pause is over requests go through again
+	limiter.blockedUntil = time.Now().Add(-time.Millisecond)
+	assert.NoError(t, limiter.Wait(context.Background(), 1))
+}
+
+func TestRateLimitFromEnv(t *testing.T) {
+	t.Setenv("STUDIOFLOWAI_OPENAI_RPM", "10")
+	t.Setenv("STUDIOFLOWAI_OPENAI_TPM", "lots")
+	assert.Equal(t, RateLimit{RequestsPerMinute: 10, TokensPerMinute: 30000}, rateLimitFromEnv(ProviderOpenAI),
+		"invalid values keep the default")
+
+	t.Setenv("STUDIOFLOWAI_GROQ_TPM", "6000")
+	t.Setenv("STUDIOFLOWAI_GROQ_RPM", "-1")
+	assert.Equal(t, RateLimit{TokensPerMinute: 6000}, rateLimitFromEnv("groq"))
+
+	assert.Equal(t, RateLimit{}, rateLimitFromEnv("mistral"), "providers without defaults are unlimited")
+}
+
+func TestSharedRateLimiter(t *testing.T) {
+	const provider = "sharedtest"
+	t.Cleanup(func() {
+		limitersMu.Lock()
+		delete(limiters, provider)
+		limitersMu.Unlock()
+	})
+	t.Setenv("STUDIOFLOWAI_SHAREDTEST_RPM", "5")
+
+	limiter := SharedRateLimiter(provider)
+	assert.Same(t, limiter, SharedRateLimiter(provider), "calls of a run share one limiter")
+	assert.Equal(t, 5.0, limiter.requests.capacity)
+
+	SetRateLimit(provider, RateLimit{RequestsPerMinute: 50})
+	replaced := SharedRateLimiter(provider)
+	assert.NotSame(t, limiter, replaced)
+	assert.Equal(t, 50.0, replaced.requests.capacity)
+}
+
+func TestEstimateTokens(t *testing.T) {
+	messages := []ChatMessage{{Role: "user", Content: "0123456789012345678901234567890123456789"}}
+	assert.Equal(t, (40+4)/4+4+100, EstimateTokens(messages, 100))
+}
+
+func TestRetryAfter(t *testing.T) {
+	assert.Equal(t, 7*time.Second, retryAfter(" 7 "))
+	assert.Equal(t, 20*time.Second, retryAfter(""))
+	assert.Equal(t, 20*time.Second, retryAfter("Wed, 21 Oct 2015 07:28:00 GMT"))
+}
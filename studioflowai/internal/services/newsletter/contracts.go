@@ -0,0 +1,28 @@
+package newsletter
+
+import (
+	"context"
+)
+
+// Draft represents a newsletter draft to be pushed to an email platform
+type Draft struct {
+	Subject     string
+	PreviewText string
+	HTML        string
+	Markdown    string
+}
+
+// Publisher defines the interface for pushing newsletter drafts to an email platform
+type Publisher interface {
+	// CreateDraft creates a draft campaign/email and returns its ID
+	CreateDraft(ctx context.Context, draft Draft) (string, error)
+
+	// Name returns the name of the email platform
+	Name() string
+}
+
+// Ensure implementations satisfy Publisher
+var (
+	_ Publisher = (*MailchimpPublisher)(nil)
+	_ Publisher = (*ButtondownPublisher)(nil)
+)
@@ -0,0 +1,230 @@
+package newsletter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+// Supported newsletter providers
+const (
+	ProviderMailchimp  = "mailchimp"
+	ProviderButtondown = "buttondown"
+)
+
+// NewPublisher creates a publisher for the given provider using credentials from the environment
+func NewPublisher(provider string) (Publisher, error) {
+	switch strings.ToLower(provider) {
+	case ProviderMailchimp:
+		return NewMailchimpPublisher()
+	case ProviderButtondown:
+		return NewButtondownPublisher()
+	default:
+		return nil, fmt.Errorf("unsupported newsletter provider: %s", provider)
+	}
+}
+
+// MailchimpPublisher creates draft campaigns in Mailchimp
+type MailchimpPublisher struct {
+	apiKey   string
+	server   string
+	listID   string
+	fromName string
+	replyTo  string
+	baseURL  string
+	client   *http.Client
+}
+
+// NewMailchimpPublisher creates a Mailchimp publisher.
+// Requires MAILCHIMP_API_KEY and MAILCHIMP_LIST_ID; the server prefix is taken from
+// MAILCHIMP_SERVER_PREFIX or the data center suffix of the API key (e.g. "-us21").
+func NewMailchimpPublisher() (*MailchimpPublisher, error) {
+	apiKey := os.Getenv("MAILCHIMP_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("MAILCHIMP_API_KEY environment variable is not set")
+	}
+	listID := os.Getenv("MAILCHIMP_LIST_ID")
+	if listID == "" {
+		return nil, fmt.Errorf("MAILCHIMP_LIST_ID environment variable is not set")
+	}
+
+	server := os.Getenv("MAILCHIMP_SERVER_PREFIX")
+	if server == "" {
+		if idx := strings.LastIndex(apiKey, "-"); idx != -1 {
+			server = apiKey[idx+1:]
+		}
+	}
+	if server == "" {
+		return nil, fmt.Errorf("MAILCHIMP_SERVER_PREFIX environment variable is not set and could not be derived from the API key")
+	}
+
+	return &MailchimpPublisher{
+		apiKey:   apiKey,
+		server:   server,
+		listID:   listID,
+		fromName: os.Getenv("MAILCHIMP_FROM_NAME"),
+		replyTo:  os.Getenv("MAILCHIMP_REPLY_TO"),
+		baseURL:  fmt.Sprintf("https://%s.api.mailchimp.com/3.0", server),
+		client:   utils.NewHTTPClient(),
+	}, nil
+}
+
+// Name returns the name of the email platform
+func (p *MailchimpPublisher) Name() string {
+	return ProviderMailchimp
+}
+
+// CreateDraft creates a draft campaign and uploads its HTML content
+func (p *MailchimpPublisher) CreateDraft(ctx context.Context, draft Draft) (string, error) {
+	settings := map[string]interface{}{
+		"subject_line": draft.Subject,
+		"preview_text": draft.PreviewText,
+		"title":        draft.Subject,
+	}
+	if p.fromName != "" {
+		settings["from_name"] = p.fromName
+	}
+	if p.replyTo != "" {
+		settings["reply_to"] = p.replyTo
+	}
+
+	campaign := map[string]interface{}{
+		"type":       "regular",
+		"recipients": map[string]string{"list_id": p.listID},
+		"settings":   settings,
+	}
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := p.do(ctx, http.MethodPost, "/campaigns", campaign, &created); err != nil {
+		return "", fmt.Errorf("failed to create Mailchimp campaign: %w", err)
+	}
+
+	content := map[string]string{"html": draft.HTML}
+	if err := p.do(ctx, http.MethodPut, "/campaigns/"+created.ID+"/content", content, nil); err != nil {
+		return created.ID, fmt.Errorf("failed to set Mailchimp campaign content: %w", err)
+	}
+
+	return created.ID, nil
+}
+
+// do sends an authenticated JSON request to the Mailchimp API
+func (p *MailchimpPublisher) do(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	req, err := newJSONRequest(ctx, method, p.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth("studioflowai", p.apiKey)
+
+	return sendJSONRequest(p.client, req, out)
+}
+
+// ButtondownPublisher creates draft emails in Buttondown
+type ButtondownPublisher struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+// NewButtondownPublisher creates a Buttondown publisher. Requires BUTTONDOWN_API_KEY.
+func NewButtondownPublisher() (*ButtondownPublisher, error) {
+	apiKey := os.Getenv("BUTTONDOWN_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("BUTTONDOWN_API_KEY environment variable is not set")
+	}
+
+	return &ButtondownPublisher{
+		apiKey:  apiKey,
+		baseURL: "https://api.buttondown.email/v1",
+		client:  utils.NewHTTPClient(),
+	}, nil
+}
+
+// Name returns the name of the email platform
+func (p *ButtondownPublisher) Name() string {
+	return ProviderButtondown
+}
+
+// CreateDraft creates a draft email. Buttondown renders Markdown bodies natively.
+func (p *ButtondownPublisher) CreateDraft(ctx context.Context, draft Draft) (string, error) {
+	body := draft.Markdown
+	if body == "" {
+		body = draft.HTML
+	}
+
+	email := map[string]string{
+		"subject":     draft.Subject,
+		"description": draft.PreviewText,
+		"body":        body,
+		"status":      "draft",
+	}
+
+	req, err := newJSONRequest(ctx, http.MethodPost, p.baseURL+"/emails", email)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Token "+p.apiKey)
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := sendJSONRequest(p.client, req, &created); err != nil {
+		return "", fmt.Errorf("failed to create Buttondown draft: %w", err)
+	}
+
+	return created.ID, nil
+}
+
+// newJSONRequest creates an HTTP request with a JSON-encoded body
+func newJSONRequest(ctx context.Context, method, url string, body interface{}) (*http.Request, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return req, nil
+}
+
+// sendJSONRequest sends a request and decodes a JSON response into out when provided
+func sendJSONRequest(client *http.Client, req *http.Request, out interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			utils.LogWarning("Failed to close response body: %v", err)
+		}
+	}()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	if out != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+// Package sheets reads and writes tables in Google Sheets
+package sheets
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+)
+
+// tokenName is the key of the Sheets token in the token storage
+const tokenName = "google_sheets"
+
+// Service reads and writes the values of a spreadsheet tab
+type Service struct {
+	api *sheets.Service
+}
+
+// NewService creates a Sheets client, asking for authorization in the browser when no valid token is stored
+func NewService(ctx context.Context, credentialsPath string) (*Service, error) {
+	credentials, err := os.ReadFile(credentialsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+
+	config, err := google.ConfigFromJSON(credentials, sheets.SpreadsheetsScope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OAuth config: %w", err)
+	}
+
+	// Route OAuth and API traffic through the configured proxy
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, utils.NewHTTPClient())
+
+	tokenStorage, err := utils.NewTokenStorage()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize token storage: %w", err)
+	}
+	token, err := tokenStorage.LoadToken(tokenName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load token: %w", err)
+	}
+
+	if token == nil || !token.Valid() {
+		callbackServer := utils.NewOAuthCallbackServer()
+		if err := callbackServer.Start(8080); err != nil {
+			return nil, fmt.Errorf("failed to start callback server: %w", err)
+		}
+		defer func() {
+			if err := callbackServer.Stop(); err != nil {
+				utils.LogWarningContext(ctx, "Failed to stop callback server: %v", err)
+			}
+		}()
+
+		config.RedirectURL = "http://localhost:8080"
+		authURL := config.AuthCodeURL("state-token", oauth2.AccessTypeOffline)
+		if err := callbackServer.OpenURL(authURL); err != nil {
+			return nil, fmt.Errorf("failed to open auth URL: %w", err)
+		}
+
+		code := callbackServer.WaitForCode()
+		token, err = config.Exchange(ctx, code)
+		if err != nil {
+			return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+		}
+		if err := tokenStorage.SaveToken(tokenName, token); err != nil {
+			utils.LogWarningContext(ctx, "Failed to save token: %v", err)
+		}
+	}
+
+	api, err := sheets.NewService(ctx, option.WithHTTPClient(oauth2.NewClient(ctx, config.TokenSource(ctx, token))))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Sheets service: %w", err)
+	}
+	return &Service{api: api}, nil
+}
+
+// WriteRows replaces the contents of a tab with rows
+func (s *Service) WriteRows(ctx context.Context, spreadsheetID, tab string, rows [][]string) error {
+	if _, err := s.api.Spreadsheets.Values.Clear(spreadsheetID, tab, &sheets.ClearValuesRequest{}).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("failed to clear sheet %s: %w", tab, err)
+	}
+
+	values := make([][]interface{}, len(rows))
+	for i, row := range rows {
+		values[i] = make([]interface{}, len(row))
+		for j, cell := range row {
+			values[i][j] = cell
+		}
+	}
+	// RAW keeps timestamps such as 00:01:30 as text instead of converting them to times
+	_, err := s.api.Spreadsheets.Values.Update(spreadsheetID, tab, &sheets.ValueRange{Values: values}).
+		ValueInputOption("RAW").Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to write sheet %s: %w", tab, err)
+	}
+	return nil
+}
+
+// ReadRows returns the values of a tab as text
+func (s *Service) ReadRows(ctx context.Context, spreadsheetID, tab string) ([][]string, error) {
+	resp, err := s.api.Spreadsheets.Values.Get(spreadsheetID, tab).ValueRenderOption("FORMATTED_VALUE").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sheet %s: %w", tab, err)
+	}
+
+	rows := make([][]string, len(resp.Values))
+	for i, row := range resp.Values {
+		rows[i] = make([]string, len(row))
+		for j, cell := range row {
+			rows[i][j] = fmt.Sprint(cell)
+		}
+	}
+	return rows, nil
+}
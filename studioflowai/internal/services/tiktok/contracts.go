@@ -7,6 +7,7 @@ import (
 
 // VideoInfo represents a video on TikTok
 type VideoInfo struct {
+	ID          string
 	Title       string
 	Description string
 	CreateTime  time.Time
@@ -96,6 +96,19 @@ func (s *service) Initialize(config interface{}) error {
 	return nil
 }
 
+// InitRequestBody builds the body of the request that starts the upload of a video of videoSize
+// bytes. Videos are sent to the account's inbox in one chunk, where they are finished as drafts.
+func InitRequestBody(videoSize int64) map[string]interface{} {
+	return map[string]interface{}{
+		"source_info": map[string]interface{}{
+			"source":            "FILE_UPLOAD",
+			"video_size":        videoSize,
+			"chunk_size":        videoSize,
+			"total_chunk_count": 1,
+		},
+	}
+}
+
 // UploadVideo uploads a video to TikTok
 func (s *service) UploadVideo(ctx context.Context, videoPath string, title string, description string, privacy string, publishTime time.Time) error {
 	// Open and read the video file
@@ -105,7 +118,7 @@ func (s *service) UploadVideo(ctx context.Context, videoPath string, title strin
 	}
 	defer func() {
 		if err := file.Close(); err != nil {
-			utils.LogWarning("Failed to close video file: %v", err)
+			utils.LogWarningContext(ctx, "Failed to close video file: %v", err)
 		}
 	}()
 
@@ -117,14 +130,7 @@ func (s *service) UploadVideo(ctx context.Context, videoPath string, title strin
 
 	// Initialize upload
 	initURL := "https://open.tiktokapis.com/v2/post/publish/inbox/video/init/"
-	initBody := map[string]interface{}{
-		"source_info": map[string]interface{}{
-			"source":            "FILE_UPLOAD",
-			"video_size":        fileInfo.Size(),
-			"chunk_size":        fileInfo.Size(),
-			"total_chunk_count": 1,
-		},
-	}
+	initBody := InitRequestBody(fileInfo.Size())
 
 	initJSON, err := json.Marshal(initBody)
 	if err != nil {
@@ -140,17 +146,16 @@ func (s *service) UploadVideo(ctx context.Context, videoPath string, title strin
 	initReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.accessToken))
 	initReq.Header.Set("Content-Type", "application/json; charset=UTF-8")
 
-	utils.LogInfo("Authorization header: %s", initReq.Header.Get("Authorization"))
-	utils.LogInfo("Init request body: %s", string(initJSON))
+	utils.LogInfoContext(ctx, "Init request body: %s", string(initJSON))
 
-	client := &http.Client{}
+	client := utils.NewHTTPClient()
 	initResp, err := client.Do(initReq)
 	if err != nil {
 		return fmt.Errorf("failed to send init request: %w", err)
 	}
 	defer func() {
 		if err := initResp.Body.Close(); err != nil {
-			utils.LogWarning("Failed to close init response body: %v", err)
+			utils.LogWarningContext(ctx, "Failed to close init response body: %v", err)
 		}
 	}()
 
@@ -207,7 +212,7 @@ func (s *service) UploadVideo(ctx context.Context, videoPath string, title strin
 	}
 	defer func() {
 		if err := uploadResp.Body.Close(); err != nil {
-			utils.LogWarning("Failed to close upload response body: %v", err)
+			utils.LogWarningContext(ctx, "Failed to close upload response body: %v", err)
 		}
 	}()
 
@@ -247,7 +252,7 @@ func (s *service) UploadVideo(ctx context.Context, videoPath string, title strin
 		}
 		defer func() {
 			if err := statusResp.Body.Close(); err != nil {
-				utils.LogWarning("Failed to close status response body: %v", err)
+				utils.LogWarningContext(ctx, "Failed to close status response body: %v", err)
 			}
 		}()
 
@@ -311,7 +316,7 @@ func (s *service) UploadVideo(ctx context.Context, videoPath string, title strin
 	}
 	defer func() {
 		if err := completeResp.Body.Close(); err != nil {
-			utils.LogWarning("Failed to close complete response body: %v", err)
+			utils.LogWarningContext(ctx, "Failed to close complete response body: %v", err)
 		}
 	}()
 
@@ -346,20 +351,101 @@ func (s *service) UploadVideo(ctx context.Context, videoPath string, title strin
 	return nil
 }
 
-// GetUploadedVideos retrieves the list of videos already uploaded to TikTok
+// videoListURL lists the videos posted by the authorized user
+const videoListURL = "https://open.tiktokapis.com/v2/video/list/?fields=id,title,video_description,create_time"
+
+// maxVideoListPages bounds how far back GetUploadedVideos looks, at 20 videos per page
+const maxVideoListPages = 25
+
+// GetUploadedVideos retrieves the list of videos already uploaded to TikTok, newest first
 func (s *service) GetUploadedVideos(ctx context.Context) ([]VideoInfo, error) {
-	// Implementation of GetUploadedVideos...
-	return nil, nil
+	client := utils.NewHTTPClient()
+
+	var videos []VideoInfo
+	var cursor int64
+	for page := 0; page < maxVideoListPages; page++ {
+		listJSON, err := json.Marshal(map[string]interface{}{
+			"max_count": 20,
+			"cursor":    cursor,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal video list request: %w", err)
+		}
+
+		listReq, err := http.NewRequestWithContext(ctx, "POST", videoListURL, bytes.NewBuffer(listJSON))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create video list request: %w", err)
+		}
+		listReq.Header.Set("Authorization", "Bearer "+s.accessToken)
+		listReq.Header.Set("Content-Type", "application/json; charset=UTF-8")
+
+		listResp, err := client.Do(listReq)
+		if err != nil {
+			return nil, fmt.Errorf("failed to send video list request: %w", err)
+		}
+		listBodyBytes, err := io.ReadAll(listResp.Body)
+		if closeErr := listResp.Body.Close(); closeErr != nil {
+			utils.LogWarningContext(ctx, "Failed to close video list response body: %v", closeErr)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read video list response body: %w", err)
+		}
+
+		if listResp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("video list API request failed with status: %d, body: %s", listResp.StatusCode, string(listBodyBytes))
+		}
+
+		var listResult struct {
+			Data struct {
+				Videos []struct {
+					ID               string `json:"id"`
+					Title            string `json:"title"`
+					VideoDescription string `json:"video_description"`
+					CreateTime       int64  `json:"create_time"`
+				} `json:"videos"`
+				Cursor  int64 `json:"cursor"`
+				HasMore bool  `json:"has_more"`
+			} `json:"data"`
+			Error struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+				LogID   string `json:"log_id"`
+			} `json:"error"`
+		}
+
+		if err := json.NewDecoder(bytes.NewReader(listBodyBytes)).Decode(&listResult); err != nil {
+			return nil, fmt.Errorf("failed to decode video list response: %w", err)
+		}
+
+		if listResult.Error.Code != "" && listResult.Error.Code != "ok" {
+			return nil, fmt.Errorf("video list API error: %s - %s (log_id: %s)", listResult.Error.Code, listResult.Error.Message, listResult.Error.LogID)
+		}
+
+		for _, v := range listResult.Data.Videos {
+			videos = append(videos, VideoInfo{
+				ID:          v.ID,
+				Title:       v.Title,
+				Description: v.VideoDescription,
+				CreateTime:  time.Unix(v.CreateTime, 0),
+			})
+		}
+
+		if !listResult.Data.HasMore || listResult.Data.Cursor == cursor {
+			break
+		}
+		cursor = listResult.Data.Cursor
+	}
+
+	return videos, nil
 }
 
 // getValidToken gets a valid token, either from storage or through OAuth flow
 func (s *service) getValidToken() (*oauth2.Token, error) {
 	// Create token storage directory if it doesn't exist
-	homeDir, err := os.UserHomeDir()
+	tokenDir, err := utils.ConfigDir()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
+		return nil, err
 	}
-	tokenDir := filepath.Join(homeDir, ".studioflowai")
 	if err := os.MkdirAll(tokenDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create token directory: %w", err)
 	}
@@ -488,8 +574,6 @@ func generateCodeVerifier() string {
 func generateCodeChallenge(verifier string) string {
 	hash := sha256.Sum256([]byte(verifier))
 	challenge := hex.EncodeToString(hash[:])
-	utils.LogInfo("Code verifier: %s", verifier)
-	utils.LogInfo("Code challenge: %s", challenge)
 	return challenge
 }
 
@@ -519,7 +603,7 @@ func exchangeCodeForToken(clientKey, clientSecret, code, codeVerifier, redirectU
 	data.Set("redirect_uri", redirectURI)
 
 	// Send request
-	resp, err := http.PostForm(tokenURL, data)
+	resp, err := utils.NewHTTPClient().PostForm(tokenURL, data)
 	if err != nil {
 		return "", fmt.Errorf("failed to send token request: %w", err)
 	}
@@ -19,8 +19,8 @@ type YouTubeService interface {
 	// ListScheduledVideos displays the list of scheduled videos
 	ListScheduledVideos(videos []ScheduledVideo) error
 
-	// UploadVideo uploads videos to YouTube
-	UploadVideo(ctx context.Context, service *youtube.Service, videoUploads []VideoUpload, privacyStatus string, categoryID string, storedShortsPath string) error
+	// UploadVideo uploads videos to YouTube and returns the outcome of each one
+	UploadVideo(ctx context.Context, service *youtube.Service, videoUploads []VideoUpload, privacyStatus string, categoryID string, storedShortsPath string) ([]UploadResult, error)
 
 	// FindAvailability finds available time slots for video uploads
 	FindAvailability(scheduledVideos []ScheduledVideo, shortsData *utils.ShortsData, periodicity int, scheduleTime string, maxAttempts int, startDate string, playlistID string) ([]VideoUpload, error)
@@ -28,8 +28,26 @@ type YouTubeService interface {
 	// ListAvailableTimes displays the list of available time slots
 	ListAvailableTimes(videoUploads []VideoUpload) error
 
+	// InsertVideo uploads a single video and returns its ID
+	InsertVideo(ctx context.Context, service *youtube.Service, upload VideoUpload, videoPath string, privacyStatus string, categoryID string) (string, error)
+
+	// UpdateVideoSnippet replaces the title, description, tags and category of an uploaded video
+	UpdateVideoSnippet(ctx context.Context, service *youtube.Service, videoID string, upload VideoUpload, categoryID string) error
+
 	// GetVideoDetails retrieves details of a specific video
 	GetVideoDetails(ctx context.Context, service *youtube.Service, videoID string) (*youtube.Video, error)
+
+	// ListChannelVideos retrieves the videos uploaded to the channel since the given time, newest first
+	ListChannelVideos(ctx context.Context, service *youtube.Service, since time.Time) ([]*youtube.Video, error)
+
+	// UpdateVideoDescription replaces the description of an uploaded video, keeping the rest of its snippet
+	UpdateVideoDescription(ctx context.Context, service *youtube.Service, video *youtube.Video, description string) error
+
+	// ConfigureQuota enables quota tracking for the given credentials file
+	ConfigureQuota(credentialsPath string, opts QuotaOptions) error
+
+	// QuotaStatus returns the quota usage and the videos deferred because of the quota
+	QuotaStatus() QuotaStatus
 }
 
 // ScheduledVideo represents a scheduled video on YouTube
@@ -51,3 +69,11 @@ type VideoUpload struct {
 	Tags           string    // The tags for the video
 	RelatedVideoID string    // The ID of the related video to link with
 }
+
+// UploadResult is the outcome of the upload of one video
+type UploadResult struct {
+	FileName string // The video file name, as in VideoUpload
+	VideoID  string // The ID of the uploaded video; empty when it was not uploaded
+	Deferred bool   // The quota ran out before the video was uploaded
+	Err      error  // Why the upload failed
+}
@@ -6,6 +6,7 @@ package youtube
 
 import (
 	"context"
+	"time"
 
 	"github.com/gnzdotmx/studioflowai/studioflowai/internal/services/youtube"
 	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
@@ -40,6 +41,63 @@ func (_m *MockYouTubeService) EXPECT() *MockYouTubeService_Expecter {
 	return &MockYouTubeService_Expecter{mock: &_m.Mock}
 }
 
+// ConfigureQuota provides a mock function for the type MockYouTubeService
+func (_mock *MockYouTubeService) ConfigureQuota(credentialsPath string, opts youtube.QuotaOptions) error {
+	ret := _mock.Called(credentialsPath, opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConfigureQuota")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(string, youtube.QuotaOptions) error); ok {
+		r0 = returnFunc(credentialsPath, opts)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockYouTubeService_ConfigureQuota_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ConfigureQuota'
+type MockYouTubeService_ConfigureQuota_Call struct {
+	*mock.Call
+}
+
+// ConfigureQuota is a helper method to define mock.On call
+//   - credentialsPath string
+//   - opts youtube.QuotaOptions
+func (_e *MockYouTubeService_Expecter) ConfigureQuota(credentialsPath interface{}, opts interface{}) *MockYouTubeService_ConfigureQuota_Call {
+	return &MockYouTubeService_ConfigureQuota_Call{Call: _e.mock.On("ConfigureQuota", credentialsPath, opts)}
+}
+
+func (_c *MockYouTubeService_ConfigureQuota_Call) Run(run func(credentialsPath string, opts youtube.QuotaOptions)) *MockYouTubeService_ConfigureQuota_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		var arg1 youtube.QuotaOptions
+		if args[1] != nil {
+			arg1 = args[1].(youtube.QuotaOptions)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockYouTubeService_ConfigureQuota_Call) Return(err error) *MockYouTubeService_ConfigureQuota_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockYouTubeService_ConfigureQuota_Call) RunAndReturn(run func(credentialsPath string, opts youtube.QuotaOptions) error) *MockYouTubeService_ConfigureQuota_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // FindAvailability provides a mock function for the type MockYouTubeService
 func (_mock *MockYouTubeService) FindAvailability(scheduledVideos []youtube.ScheduledVideo, shortsData *utils.ShortsData, periodicity int, scheduleTime string, maxAttempts int, startDate string, playlistID string) ([]youtube.VideoUpload, error) {
 	ret := _mock.Called(scheduledVideos, shortsData, periodicity, scheduleTime, maxAttempts, startDate, playlistID)
@@ -280,6 +338,96 @@ func (_c *MockYouTubeService_InitializeYouTubeService_Call) RunAndReturn(run fun
 	return _c
 }
 
+// InsertVideo provides a mock function for the type MockYouTubeService
+func (_mock *MockYouTubeService) InsertVideo(ctx context.Context, service *youtube0.Service, upload youtube.VideoUpload, videoPath string, privacyStatus string, categoryID string) (string, error) {
+	ret := _mock.Called(ctx, service, upload, videoPath, privacyStatus, categoryID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertVideo")
+	}
+
+	var r0 string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *youtube0.Service, youtube.VideoUpload, string, string, string) (string, error)); ok {
+		return returnFunc(ctx, service, upload, videoPath, privacyStatus, categoryID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *youtube0.Service, youtube.VideoUpload, string, string, string) string); ok {
+		r0 = returnFunc(ctx, service, upload, videoPath, privacyStatus, categoryID)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *youtube0.Service, youtube.VideoUpload, string, string, string) error); ok {
+		r1 = returnFunc(ctx, service, upload, videoPath, privacyStatus, categoryID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockYouTubeService_InsertVideo_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'InsertVideo'
+type MockYouTubeService_InsertVideo_Call struct {
+	*mock.Call
+}
+
+// InsertVideo is a helper method to define mock.On call
+//   - ctx context.Context
+//   - service *youtube0.Service
+//   - upload youtube.VideoUpload
+//   - videoPath string
+//   - privacyStatus string
+//   - categoryID string
+func (_e *MockYouTubeService_Expecter) InsertVideo(ctx interface{}, service interface{}, upload interface{}, videoPath interface{}, privacyStatus interface{}, categoryID interface{}) *MockYouTubeService_InsertVideo_Call {
+	return &MockYouTubeService_InsertVideo_Call{Call: _e.mock.On("InsertVideo", ctx, service, upload, videoPath, privacyStatus, categoryID)}
+}
+
+func (_c *MockYouTubeService_InsertVideo_Call) Run(run func(ctx context.Context, service *youtube0.Service, upload youtube.VideoUpload, videoPath string, privacyStatus string, categoryID string)) *MockYouTubeService_InsertVideo_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *youtube0.Service
+		if args[1] != nil {
+			arg1 = args[1].(*youtube0.Service)
+		}
+		var arg2 youtube.VideoUpload
+		if args[2] != nil {
+			arg2 = args[2].(youtube.VideoUpload)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		var arg4 string
+		if args[4] != nil {
+			arg4 = args[4].(string)
+		}
+		var arg5 string
+		if args[5] != nil {
+			arg5 = args[5].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+			arg4,
+			arg5,
+		)
+	})
+	return _c
+}
+
+func (_c *MockYouTubeService_InsertVideo_Call) Return(s string, err error) *MockYouTubeService_InsertVideo_Call {
+	_c.Call.Return(s, err)
+	return _c
+}
+
+func (_c *MockYouTubeService_InsertVideo_Call) RunAndReturn(run func(ctx context.Context, service *youtube0.Service, upload youtube.VideoUpload, videoPath string, privacyStatus string, categoryID string) (string, error)) *MockYouTubeService_InsertVideo_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // ListAvailableTimes provides a mock function for the type MockYouTubeService
 func (_mock *MockYouTubeService) ListAvailableTimes(videoUploads []youtube.VideoUpload) error {
 	ret := _mock.Called(videoUploads)
@@ -331,6 +479,80 @@ func (_c *MockYouTubeService_ListAvailableTimes_Call) RunAndReturn(run func(vide
 	return _c
 }
 
+// ListChannelVideos provides a mock function for the type MockYouTubeService
+func (_mock *MockYouTubeService) ListChannelVideos(ctx context.Context, service *youtube0.Service, since time.Time) ([]*youtube0.Video, error) {
+	ret := _mock.Called(ctx, service, since)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListChannelVideos")
+	}
+
+	var r0 []*youtube0.Video
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *youtube0.Service, time.Time) ([]*youtube0.Video, error)); ok {
+		return returnFunc(ctx, service, since)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *youtube0.Service, time.Time) []*youtube0.Video); ok {
+		r0 = returnFunc(ctx, service, since)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*youtube0.Video)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *youtube0.Service, time.Time) error); ok {
+		r1 = returnFunc(ctx, service, since)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockYouTubeService_ListChannelVideos_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListChannelVideos'
+type MockYouTubeService_ListChannelVideos_Call struct {
+	*mock.Call
+}
+
+// ListChannelVideos is a helper method to define mock.On call
+//   - ctx context.Context
+//   - service *youtube0.Service
+//   - since time.Time
+func (_e *MockYouTubeService_Expecter) ListChannelVideos(ctx interface{}, service interface{}, since interface{}) *MockYouTubeService_ListChannelVideos_Call {
+	return &MockYouTubeService_ListChannelVideos_Call{Call: _e.mock.On("ListChannelVideos", ctx, service, since)}
+}
+
+func (_c *MockYouTubeService_ListChannelVideos_Call) Run(run func(ctx context.Context, service *youtube0.Service, since time.Time)) *MockYouTubeService_ListChannelVideos_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *youtube0.Service
+		if args[1] != nil {
+			arg1 = args[1].(*youtube0.Service)
+		}
+		var arg2 time.Time
+		if args[2] != nil {
+			arg2 = args[2].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockYouTubeService_ListChannelVideos_Call) Return(videos []*youtube0.Video, err error) *MockYouTubeService_ListChannelVideos_Call {
+	_c.Call.Return(videos, err)
+	return _c
+}
+
+func (_c *MockYouTubeService_ListChannelVideos_Call) RunAndReturn(run func(ctx context.Context, service *youtube0.Service, since time.Time) ([]*youtube0.Video, error)) *MockYouTubeService_ListChannelVideos_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // ListScheduledVideos provides a mock function for the type MockYouTubeService
 func (_mock *MockYouTubeService) ListScheduledVideos(videos []youtube.ScheduledVideo) error {
 	ret := _mock.Called(videos)
@@ -382,6 +604,50 @@ func (_c *MockYouTubeService_ListScheduledVideos_Call) RunAndReturn(run func(vid
 	return _c
 }
 
+// QuotaStatus provides a mock function for the type MockYouTubeService
+func (_mock *MockYouTubeService) QuotaStatus() youtube.QuotaStatus {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for QuotaStatus")
+	}
+
+	var r0 youtube.QuotaStatus
+	if returnFunc, ok := ret.Get(0).(func() youtube.QuotaStatus); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(youtube.QuotaStatus)
+	}
+	return r0
+}
+
+// MockYouTubeService_QuotaStatus_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'QuotaStatus'
+type MockYouTubeService_QuotaStatus_Call struct {
+	*mock.Call
+}
+
+// QuotaStatus is a helper method to define mock.On call
+func (_e *MockYouTubeService_Expecter) QuotaStatus() *MockYouTubeService_QuotaStatus_Call {
+	return &MockYouTubeService_QuotaStatus_Call{Call: _e.mock.On("QuotaStatus")}
+}
+
+func (_c *MockYouTubeService_QuotaStatus_Call) Run(run func()) *MockYouTubeService_QuotaStatus_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockYouTubeService_QuotaStatus_Call) Return(quotaStatus youtube.QuotaStatus) *MockYouTubeService_QuotaStatus_Call {
+	_c.Call.Return(quotaStatus)
+	return _c
+}
+
+func (_c *MockYouTubeService_QuotaStatus_Call) RunAndReturn(run func() youtube.QuotaStatus) *MockYouTubeService_QuotaStatus_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // ReadScheduledVideos provides a mock function for the type MockYouTubeService
 func (_mock *MockYouTubeService) ReadScheduledVideos(ctx context.Context, service *youtube0.Service) ([]youtube.ScheduledVideo, error) {
 	ret := _mock.Called(ctx, service)
@@ -451,20 +717,31 @@ func (_c *MockYouTubeService_ReadScheduledVideos_Call) RunAndReturn(run func(ctx
 }
 
 // UploadVideo provides a mock function for the type MockYouTubeService
-func (_mock *MockYouTubeService) UploadVideo(ctx context.Context, service *youtube0.Service, videoUploads []youtube.VideoUpload, privacyStatus string, categoryID string, storedShortsPath string) error {
+func (_mock *MockYouTubeService) UploadVideo(ctx context.Context, service *youtube0.Service, videoUploads []youtube.VideoUpload, privacyStatus string, categoryID string, storedShortsPath string) ([]youtube.UploadResult, error) {
 	ret := _mock.Called(ctx, service, videoUploads, privacyStatus, categoryID, storedShortsPath)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UploadVideo")
 	}
 
-	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *youtube0.Service, []youtube.VideoUpload, string, string, string) error); ok {
+	var r0 []youtube.UploadResult
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *youtube0.Service, []youtube.VideoUpload, string, string, string) ([]youtube.UploadResult, error)); ok {
+		return returnFunc(ctx, service, videoUploads, privacyStatus, categoryID, storedShortsPath)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *youtube0.Service, []youtube.VideoUpload, string, string, string) []youtube.UploadResult); ok {
 		r0 = returnFunc(ctx, service, videoUploads, privacyStatus, categoryID, storedShortsPath)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]youtube.UploadResult)
+		}
 	}
-	return r0
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *youtube0.Service, []youtube.VideoUpload, string, string, string) error); ok {
+		r1 = returnFunc(ctx, service, videoUploads, privacyStatus, categoryID, storedShortsPath)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
 }
 
 // MockYouTubeService_UploadVideo_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UploadVideo'
@@ -521,12 +798,156 @@ func (_c *MockYouTubeService_UploadVideo_Call) Run(run func(ctx context.Context,
 	return _c
 }
 
-func (_c *MockYouTubeService_UploadVideo_Call) Return(err error) *MockYouTubeService_UploadVideo_Call {
+func (_c *MockYouTubeService_UploadVideo_Call) Return(uploadResults []youtube.UploadResult, err error) *MockYouTubeService_UploadVideo_Call {
+	_c.Call.Return(uploadResults, err)
+	return _c
+}
+
+func (_c *MockYouTubeService_UploadVideo_Call) RunAndReturn(run func(ctx context.Context, service *youtube0.Service, videoUploads []youtube.VideoUpload, privacyStatus string, categoryID string, storedShortsPath string) ([]youtube.UploadResult, error)) *MockYouTubeService_UploadVideo_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateVideoSnippet provides a mock function for the type MockYouTubeService
+func (_mock *MockYouTubeService) UpdateVideoSnippet(ctx context.Context, service *youtube0.Service, videoID string, upload youtube.VideoUpload, categoryID string) error {
+	ret := _mock.Called(ctx, service, videoID, upload, categoryID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateVideoSnippet")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *youtube0.Service, string, youtube.VideoUpload, string) error); ok {
+		r0 = returnFunc(ctx, service, videoID, upload, categoryID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockYouTubeService_UpdateVideoSnippet_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateVideoSnippet'
+type MockYouTubeService_UpdateVideoSnippet_Call struct {
+	*mock.Call
+}
+
+// UpdateVideoSnippet is a helper method to define mock.On call
+//   - ctx context.Context
+//   - service *youtube0.Service
+//   - videoID string
+//   - upload youtube.VideoUpload
+//   - categoryID string
+func (_e *MockYouTubeService_Expecter) UpdateVideoSnippet(ctx interface{}, service interface{}, videoID interface{}, upload interface{}, categoryID interface{}) *MockYouTubeService_UpdateVideoSnippet_Call {
+	return &MockYouTubeService_UpdateVideoSnippet_Call{Call: _e.mock.On("UpdateVideoSnippet", ctx, service, videoID, upload, categoryID)}
+}
+
+func (_c *MockYouTubeService_UpdateVideoSnippet_Call) Run(run func(ctx context.Context, service *youtube0.Service, videoID string, upload youtube.VideoUpload, categoryID string)) *MockYouTubeService_UpdateVideoSnippet_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *youtube0.Service
+		if args[1] != nil {
+			arg1 = args[1].(*youtube0.Service)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 youtube.VideoUpload
+		if args[3] != nil {
+			arg3 = args[3].(youtube.VideoUpload)
+		}
+		var arg4 string
+		if args[4] != nil {
+			arg4 = args[4].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+			arg4,
+		)
+	})
+	return _c
+}
+
+func (_c *MockYouTubeService_UpdateVideoSnippet_Call) Return(err error) *MockYouTubeService_UpdateVideoSnippet_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockYouTubeService_UpdateVideoSnippet_Call) RunAndReturn(run func(ctx context.Context, service *youtube0.Service, videoID string, upload youtube.VideoUpload, categoryID string) error) *MockYouTubeService_UpdateVideoSnippet_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateVideoDescription provides a mock function for the type MockYouTubeService
+func (_mock *MockYouTubeService) UpdateVideoDescription(ctx context.Context, service *youtube0.Service, video *youtube0.Video, description string) error {
+	ret := _mock.Called(ctx, service, video, description)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateVideoDescription")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *youtube0.Service, *youtube0.Video, string) error); ok {
+		r0 = returnFunc(ctx, service, video, description)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockYouTubeService_UpdateVideoDescription_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateVideoDescription'
+type MockYouTubeService_UpdateVideoDescription_Call struct {
+	*mock.Call
+}
+
+// UpdateVideoDescription is a helper method to define mock.On call
+//   - ctx context.Context
+//   - service *youtube0.Service
+//   - video *youtube0.Video
+//   - description string
+func (_e *MockYouTubeService_Expecter) UpdateVideoDescription(ctx interface{}, service interface{}, video interface{}, description interface{}) *MockYouTubeService_UpdateVideoDescription_Call {
+	return &MockYouTubeService_UpdateVideoDescription_Call{Call: _e.mock.On("UpdateVideoDescription", ctx, service, video, description)}
+}
+
+func (_c *MockYouTubeService_UpdateVideoDescription_Call) Run(run func(ctx context.Context, service *youtube0.Service, video *youtube0.Video, description string)) *MockYouTubeService_UpdateVideoDescription_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *youtube0.Service
+		if args[1] != nil {
+			arg1 = args[1].(*youtube0.Service)
+		}
+		var arg2 *youtube0.Video
+		if args[2] != nil {
+			arg2 = args[2].(*youtube0.Video)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockYouTubeService_UpdateVideoDescription_Call) Return(err error) *MockYouTubeService_UpdateVideoDescription_Call {
 	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockYouTubeService_UploadVideo_Call) RunAndReturn(run func(ctx context.Context, service *youtube0.Service, videoUploads []youtube.VideoUpload, privacyStatus string, categoryID string, storedShortsPath string) error) *MockYouTubeService_UploadVideo_Call {
+func (_c *MockYouTubeService_UpdateVideoDescription_Call) RunAndReturn(run func(ctx context.Context, service *youtube0.Service, video *youtube0.Video, description string) error) *MockYouTubeService_UpdateVideoDescription_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -0,0 +1,310 @@
+package youtube
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"google.golang.org/api/googleapi"
+)
+
+// Quota strategies applied when the remaining daily quota cannot cover the next upload
+const (
+	QuotaStrategyDefer = "defer" // Stop uploading and report the remaining videos for a later run
+	QuotaStrategyWait  = "wait"  // Sleep until the daily quota resets, then continue
+)
+
+// DefaultDailyQuota is the default YouTube Data API quota of a Google Cloud project
+const DefaultDailyQuota = 10000
+
+// quotaCosts lists the quota units charged by the YouTube Data API for each call
+var quotaCosts = map[string]int{
+	"channels.list":        1,
+	"search.list":          100,
+	"videos.list":          1,
+	"videos.insert":        1600,
+	"videos.update":        50,
+	"playlistItems.list":   1,
+	"playlistItems.insert": 50,
+}
+
+// QuotaOptions configures quota tracking for a credential
+type QuotaOptions struct {
+	DailyLimit    int     // Daily quota units available to the project (default: 10000)
+	WarnThreshold float64 // Fraction of the daily quota that triggers a warning (default: 0.8)
+	Strategy      string  // QuotaStrategyDefer or QuotaStrategyWait (default: defer)
+	StatePath     string  // File where usage is persisted (default: youtube_quota.json in the config directory)
+}
+
+// QuotaStatus summarizes the quota usage after a run
+type QuotaStatus struct {
+	Used      int            // Units used today
+	Limit     int            // Daily quota limit
+	Remaining int            // Units left until the reset
+	ResetAt   time.Time      // Next quota reset (midnight Pacific Time)
+	Calls     map[string]int // Number of calls made today per API method
+	Deferred  []string       // Titles of videos that were not uploaded because of the quota
+}
+
+// QuotaExceededError is returned when a call would exceed the remaining daily quota
+type QuotaExceededError struct {
+	Operation string
+	Cost      int
+	Remaining int
+	Limit     int
+	ResetAt   time.Time
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("YouTube API quota exhausted: %s needs %d units but only %d of %d remain until %s",
+		e.Operation, e.Cost, e.Remaining, e.Limit, e.ResetAt.Local().Format("2006-01-02 15:04 MST"))
+}
+
+// quotaState is the persisted usage of one credential for one quota day
+type quotaState struct {
+	Day   string         `json:"day"`
+	Used  int            `json:"used"`
+	Calls map[string]int `json:"calls"`
+}
+
+// QuotaTracker tracks and persists the quota used by one credential
+type QuotaTracker struct {
+	mu     sync.Mutex
+	key    string
+	opts   QuotaOptions
+	state  quotaState
+	warned bool
+	now    func() time.Time
+}
+
+// NewQuotaTracker loads the persisted usage for the credential identified by key
+func NewQuotaTracker(key string, opts QuotaOptions) (*QuotaTracker, error) {
+	if opts.DailyLimit <= 0 {
+		opts.DailyLimit = DefaultDailyQuota
+	}
+	if opts.WarnThreshold <= 0 || opts.WarnThreshold > 1 {
+		opts.WarnThreshold = 0.8
+	}
+	if opts.Strategy == "" {
+		opts.Strategy = QuotaStrategyDefer
+	}
+	if opts.Strategy != QuotaStrategyDefer && opts.Strategy != QuotaStrategyWait {
+		return nil, fmt.Errorf("invalid quota strategy: %s (expected %s or %s)", opts.Strategy, QuotaStrategyDefer, QuotaStrategyWait)
+	}
+	if opts.StatePath == "" {
+		configDir, err := utils.ConfigDir()
+		if err != nil {
+			return nil, err
+		}
+		opts.StatePath = filepath.Join(configDir, "youtube_quota.json")
+	}
+
+	t := &QuotaTracker{key: key, opts: opts, now: time.Now}
+	states, err := t.load()
+	if err != nil {
+		return nil, err
+	}
+	t.state = states[key]
+	t.rollover()
+	t.warned = t.state.Used >= t.warnLevel()
+
+	return t, nil
+}
+
+// pacific returns the time zone in which the YouTube quota resets
+func pacific() *time.Location {
+	if loc, err := time.LoadLocation("America/Los_Angeles"); err == nil {
+		return loc
+	}
+	return time.FixedZone("PST", -8*60*60)
+}
+
+// quotaDay returns the quota day a moment belongs to
+func quotaDay(t time.Time) string {
+	return t.In(pacific()).Format("2006-01-02")
+}
+
+// rollover resets the usage when a new quota day has started
+func (t *QuotaTracker) rollover() {
+	today := quotaDay(t.now())
+	if t.state.Day != today {
+		t.state = quotaState{Day: today}
+		t.warned = false
+	}
+	if t.state.Calls == nil {
+		t.state.Calls = make(map[string]int)
+	}
+}
+
+// warnLevel returns the usage at which a warning is logged
+func (t *QuotaTracker) warnLevel() int {
+	return int(float64(t.opts.DailyLimit) * t.opts.WarnThreshold)
+}
+
+// Strategy returns the configured quota strategy
+func (t *QuotaTracker) Strategy() string {
+	return t.opts.Strategy
+}
+
+// ResetAt returns the next quota reset, midnight Pacific Time
+func (t *QuotaTracker) ResetAt() time.Time {
+	now := t.now().In(pacific())
+	return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+}
+
+// Cost returns the quota units charged for a list of API operations
+func Cost(operations ...string) int {
+	total := 0
+	for _, op := range operations {
+		total += quotaCosts[op]
+	}
+	return total
+}
+
+// CanAfford reports whether the remaining quota covers all operations
+func (t *QuotaTracker) CanAfford(operations ...string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rollover()
+	return t.state.Used+Cost(operations...) <= t.opts.DailyLimit
+}
+
+// Spend charges an API operation against the daily quota, failing if it would exceed the limit
+func (t *QuotaTracker) Spend(operation string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rollover()
+
+	cost := quotaCosts[operation]
+	remaining := t.opts.DailyLimit - t.state.Used
+	if cost > remaining {
+		return &QuotaExceededError{
+			Operation: operation,
+			Cost:      cost,
+			Remaining: remaining,
+			Limit:     t.opts.DailyLimit,
+			ResetAt:   t.ResetAt(),
+		}
+	}
+
+	t.state.Used += cost
+	t.state.Calls[operation]++
+	if !t.warned && t.state.Used >= t.warnLevel() {
+		t.warned = true
+		utils.LogWarning("YouTube API quota at %d%% (%d of %d units used today, resets %s)",
+			t.state.Used*100/t.opts.DailyLimit, t.state.Used, t.opts.DailyLimit, t.ResetAt().Local().Format("2006-01-02 15:04 MST"))
+	}
+	return t.save()
+}
+
+// MarkExhausted records that the API reported the quota as exhausted
+func (t *QuotaTracker) MarkExhausted() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rollover()
+	t.state.Used = t.opts.DailyLimit
+	t.warned = true
+	if err := t.save(); err != nil {
+		utils.LogWarning("Failed to save YouTube quota usage: %v", err)
+	}
+}
+
+// Status returns the current usage
+func (t *QuotaTracker) Status() QuotaStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rollover()
+
+	calls := make(map[string]int, len(t.state.Calls))
+	for op, n := range t.state.Calls {
+		calls[op] = n
+	}
+	return QuotaStatus{
+		Used:      t.state.Used,
+		Limit:     t.opts.DailyLimit,
+		Remaining: t.opts.DailyLimit - t.state.Used,
+		ResetAt:   t.ResetAt(),
+		Calls:     calls,
+	}
+}
+
+// load reads the usage of all credentials from the state file
+func (t *QuotaTracker) load() (map[string]quotaState, error) {
+	states := make(map[string]quotaState)
+	data, err := os.ReadFile(t.opts.StatePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return states, nil
+		}
+		return nil, fmt.Errorf("failed to read quota file: %w", err)
+	}
+	if err := json.Unmarshal(data, &states); err != nil {
+		return nil, fmt.Errorf("failed to parse quota file %s: %w", t.opts.StatePath, err)
+	}
+	return states, nil
+}
+
+// save writes this credential's usage back to the state file, keeping other credentials intact
+func (t *QuotaTracker) save() error {
+	states, err := t.load()
+	if err != nil {
+		states = make(map[string]quotaState)
+	}
+	states[t.key] = t.state
+
+	data, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal quota usage: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(t.opts.StatePath), 0700); err != nil {
+		return fmt.Errorf("failed to create quota directory: %w", err)
+	}
+	return utils.AtomicWriteFile(t.opts.StatePath, data, 0600)
+}
+
+// credentialKey identifies the Google Cloud project of a credentials file, falling back to its path
+func credentialKey(credentialsPath string) string {
+	if data, err := os.ReadFile(credentialsPath); err == nil {
+		var creds map[string]struct {
+			ClientID  string `json:"client_id"`
+			ProjectID string `json:"project_id"`
+		}
+		if json.Unmarshal(data, &creds) == nil {
+			for _, c := range creds {
+				if c.ProjectID != "" {
+					return c.ProjectID
+				}
+				if c.ClientID != "" {
+					return c.ClientID
+				}
+			}
+		}
+	}
+	if abs, err := filepath.Abs(credentialsPath); err == nil {
+		return abs
+	}
+	return credentialsPath
+}
+
+// isQuotaError reports whether an API error was caused by an exhausted quota
+func isQuotaError(err error) bool {
+	var quotaErr *QuotaExceededError
+	if errors.As(err, &quotaErr) {
+		return true
+	}
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	for _, item := range apiErr.Errors {
+		if item.Reason == "quotaExceeded" || item.Reason == "dailyLimitExceeded" {
+			return true
+		}
+	}
+	return false
+}
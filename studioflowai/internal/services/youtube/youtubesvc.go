@@ -12,6 +12,7 @@ import (
 	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 	"google.golang.org/api/youtube/v3"
 )
@@ -23,8 +24,106 @@ var requiredScopes = []string{
 	"https://www.googleapis.com/auth/youtube.force-ssl",
 }
 
+// RequiredScopes returns the OAuth scopes the uploads are authorized for
+func RequiredScopes() []string {
+	return append([]string(nil), requiredScopes...)
+}
+
+// Default time limits of a single API call
+const (
+	DefaultRequestTimeout = time.Minute      // Metadata calls: listing, searching, updating
+	DefaultUploadTimeout  = 30 * time.Minute // Sending one video file
+)
+
+// Videos larger than a chunk are sent as resumable uploads, one chunk per request. A chunk
+// whose connection drops or stalls is sent again from the last byte the server confirmed,
+// so a long episode upload survives a flaky network instead of starting over.
+const (
+	uploadChunkSize     = googleapi.DefaultUploadChunkSize
+	uploadChunkTimeout  = 5 * time.Minute  // A chunk still sending after this is cancelled and retried
+	uploadRetryDeadline = 10 * time.Minute // A chunk is retried for this long before the upload fails
+)
+
+// Timeouts bound each API call, so a stuck request fails instead of outliving the workflow. The
+// step context still wins when its deadline is earlier. Zero values use the defaults.
+type Timeouts struct {
+	Request time.Duration
+	Upload  time.Duration
+}
+
+// TimeoutConfigurer is implemented by services whose per-call timeouts can be set
+type TimeoutConfigurer interface {
+	SetTimeouts(timeouts Timeouts)
+}
+
 // Service implements the Service interface
-type Service struct{}
+type Service struct {
+	quota    *QuotaTracker // Quota usage of the configured credential, nil when not tracked
+	deferred []string      // Titles of videos postponed because of the quota
+	timeouts Timeouts
+}
+
+// SetTimeouts sets the time limits of the following API calls
+func (m *Service) SetTimeouts(timeouts Timeouts) {
+	m.timeouts = timeouts
+}
+
+// requestContext bounds a metadata call by the request timeout
+func (m *Service) requestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout := m.timeouts.Request
+	if timeout <= 0 {
+		timeout = DefaultRequestTimeout
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// uploadContext bounds the upload of one video by the upload timeout
+func (m *Service) uploadContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout := m.timeouts.Upload
+	if timeout <= 0 {
+		timeout = DefaultUploadTimeout
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// ConfigureQuota enables quota tracking for the credential used by the following calls
+func (m *Service) ConfigureQuota(credentialsPath string, opts QuotaOptions) error {
+	tracker, err := NewQuotaTracker(credentialKey(credentialsPath), opts)
+	if err != nil {
+		return err
+	}
+	m.quota = tracker
+	m.deferred = nil
+
+	status := tracker.Status()
+	utils.LogVerbose("YouTube API quota: %d of %d units used today", status.Used, status.Limit)
+	return nil
+}
+
+// QuotaStatus returns the quota usage and the videos deferred during this run
+func (m *Service) QuotaStatus() QuotaStatus {
+	if m.quota == nil {
+		return QuotaStatus{Deferred: m.deferred}
+	}
+	status := m.quota.Status()
+	status.Deferred = m.deferred
+	return status
+}
+
+// spend charges an API call against the quota when tracking is enabled
+func (m *Service) spend(operation string) error {
+	if m.quota == nil {
+		return nil
+	}
+	return m.quota.Spend(operation)
+}
+
+// checkQuota marks the quota as exhausted when the API rejected a call for that reason
+func (m *Service) checkQuota(err error) {
+	if m.quota != nil && err != nil && isQuotaError(err) {
+		m.quota.MarkExhausted()
+	}
+}
 
 // InitializeYouTubeService creates a YouTube service client
 func (m *Service) InitializeYouTubeService(ctx context.Context, credentialsPath string) (*youtube.Service, error) {
@@ -40,6 +139,9 @@ func (m *Service) InitializeYouTubeService(ctx context.Context, credentialsPath
 		return nil, fmt.Errorf("failed to create OAuth config: %w", err)
 	}
 
+	// Route OAuth and API traffic through the configured proxy
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, utils.NewHTTPClient())
+
 	// Initialize token storage
 	tokenStorage, err := utils.NewTokenStorage()
 	if err != nil {
@@ -61,7 +163,7 @@ func (m *Service) InitializeYouTubeService(ctx context.Context, credentialsPath
 		}
 		defer func() {
 			if err := callbackServer.Stop(); err != nil {
-				utils.LogWarning("Failed to stop callback server: %v", err)
+				utils.LogWarningContext(ctx, "Failed to stop callback server: %v", err)
 			}
 		}()
 
@@ -75,24 +177,29 @@ func (m *Service) InitializeYouTubeService(ctx context.Context, credentialsPath
 		}
 
 		// Wait for the authorization code
-		code := callbackServer.WaitForCode()
+		code, err := callbackServer.WaitForCodeContext(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("stopped waiting for authorization: %w", err)
+		}
 
 		// Exchange authorization code for token
-		token, err = config.Exchange(ctx, code)
+		exchangeCtx, cancel := m.requestContext(ctx)
+		token, err = config.Exchange(exchangeCtx, code)
+		cancel()
 		if err != nil {
 			return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
 		}
 
 		// Save the new token
 		if err := tokenStorage.SaveToken("youtube", token); err != nil {
-			utils.LogWarning("Failed to save token: %v", err)
+			utils.LogWarningContext(ctx, "Failed to save token: %v", err)
 		}
 	} else {
-		utils.LogInfo("Using existing authorization token")
+		utils.LogInfoContext(ctx, "Using existing authorization token")
 	}
 
 	// Create YouTube service with token
-	service, err := youtube.NewService(ctx, option.WithTokenSource(config.TokenSource(ctx, token)))
+	service, err := youtube.NewService(ctx, option.WithHTTPClient(oauth2.NewClient(ctx, config.TokenSource(ctx, token))))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create YouTube service: %w", err)
 	}
@@ -103,8 +210,14 @@ func (m *Service) InitializeYouTubeService(ctx context.Context, credentialsPath
 // ReadScheduledVideos retrieves all scheduled videos from the channel
 func (m *Service) ReadScheduledVideos(ctx context.Context, service *youtube.Service) ([]ScheduledVideo, error) {
 	// Verify channel access
-	channelsResponse, err := service.Channels.List([]string{"id"}).Mine(true).Do()
+	if err := m.spend("channels.list"); err != nil {
+		return nil, err
+	}
+	callCtx, cancel := m.requestContext(ctx)
+	channelsResponse, err := service.Channels.List([]string{"id"}).Mine(true).Context(callCtx).Do()
+	cancel()
 	if err != nil {
+		m.checkQuota(err)
 		return nil, fmt.Errorf("failed to get channel info: %w", err)
 	}
 
@@ -113,13 +226,20 @@ func (m *Service) ReadScheduledVideos(ctx context.Context, service *youtube.Serv
 	}
 
 	// Get videos using the search API
+	if err := m.spend("search.list"); err != nil {
+		return nil, err
+	}
+	callCtx, cancel = m.requestContext(ctx)
 	searchResponse, err := service.Search.List([]string{"id"}).
 		ForMine(true).
 		Type("video").
 		MaxResults(50).
+		Context(callCtx).
 		Do()
+	cancel()
 
 	if err != nil {
+		m.checkQuota(err)
 		return nil, fmt.Errorf("failed to search for videos: %w", err)
 	}
 
@@ -134,11 +254,18 @@ func (m *Service) ReadScheduledVideos(ctx context.Context, service *youtube.Serv
 	}
 
 	// Get detailed video information
+	if err := m.spend("videos.list"); err != nil {
+		return nil, err
+	}
+	callCtx, cancel = m.requestContext(ctx)
 	videosResponse, err := service.Videos.List([]string{"snippet", "status", "contentDetails"}).
 		Id(videoIds...).
+		Context(callCtx).
 		Do()
+	cancel()
 
 	if err != nil {
+		m.checkQuota(err)
 		return nil, fmt.Errorf("failed to get video details: %w", err)
 	}
 
@@ -201,12 +328,6 @@ func parseScheduleTime(timeStr string) (int, int, error) {
 	return hour, minute, nil
 }
 
-// convertToHHMMSS converts a timestamp to HH:MM:SS format
-func convertToHHMMSS(timestamp string) string {
-	// Remove colons
-	return strings.ReplaceAll(timestamp, ":", "")
-}
-
 // cleanTag removes special characters and converts to lowercase
 func cleanTag(tag string) string {
 	// Remove leading/trailing spaces
@@ -224,6 +345,27 @@ func cleanTag(tag string) string {
 	return tag
 }
 
+// NewVideo builds the body of the videos.insert request for an upload. Tags are cleaned for
+// YouTube, and the video is scheduled when the upload has a publish time.
+func NewVideo(upload VideoUpload, privacyStatus string, categoryID string) *youtube.Video {
+	video := &youtube.Video{
+		Snippet: &youtube.VideoSnippet{
+			Title:       upload.ShortTitle,
+			Description: upload.Description,
+			CategoryId:  categoryID,
+			Tags:        processTags(upload.Tags),
+		},
+		Status: &youtube.VideoStatus{
+			PrivacyStatus: privacyStatus,
+			MadeForKids:   false,
+		},
+	}
+	if !upload.PublishTime.IsZero() {
+		video.Status.PublishAt = upload.PublishTime.Format(time.RFC3339)
+	}
+	return video
+}
+
 // processTags splits and cleans tags, ensuring YouTube compatibility
 func processTags(tags string) []string {
 	// Split by comma
@@ -249,78 +391,229 @@ func processTags(tags string) []string {
 	return cleanedTags
 }
 
-// UploadVideo uploads videos to YouTube
-func (m *Service) UploadVideo(ctx context.Context, service *youtube.Service, videoUploads []VideoUpload, privacyStatus string, categoryID string, storedShortsPath string) error {
-	for _, upload := range videoUploads {
+// UploadVideo uploads videos to YouTube and returns the outcome of each one. A video that fails
+// does not stop the others, and videos the quota does not cover are deferred. Videos not reached
+// because the context ended have no result.
+func (m *Service) UploadVideo(ctx context.Context, service *youtube.Service, videoUploads []VideoUpload, privacyStatus string, categoryID string, storedShortsPath string) ([]UploadResult, error) {
+	results := make([]UploadResult, 0, len(videoUploads))
+	deferRest := func(i int) {
+		m.deferUploads(ctx, videoUploads[i:])
+		for _, upload := range videoUploads[i:] {
+			results = append(results, UploadResult{FileName: upload.FileName, Deferred: true})
+		}
+	}
+
+	for i, upload := range videoUploads {
+		// A cancelled or timed-out step stops before the next upload starts
+		if err := ctx.Err(); err != nil {
+			return results, fmt.Errorf("stopped uploading after %d of %d videos: %w", i, len(videoUploads), err)
+		}
+
+		// Make sure the remaining quota covers the upload (and playlist insert) before starting it
+		if m.quota != nil {
+			operations := []string{"videos.insert"}
+			if upload.PlaylistID != "" {
+				operations = append(operations, "playlistItems.insert")
+			}
+			if !m.quota.CanAfford(operations...) {
+				if m.quota.Strategy() != QuotaStrategyWait {
+					deferRest(i)
+					break
+				}
+				if err := m.waitForQuotaReset(ctx); err != nil {
+					deferRest(i)
+					return results, err
+				}
+			}
+		}
+
 		// Construct the full path to the video file
 		videoPath := filepath.Join(storedShortsPath, upload.FileName)
 
 		// Open the video file
 		file, err := os.Open(videoPath)
 		if err != nil {
-			utils.LogWarning("Failed to open video file: %v", err)
+			utils.LogWarningContext(ctx, "Failed to open video file: %v", err)
+			results = append(results, UploadResult{FileName: upload.FileName, Err: fmt.Errorf("failed to open video file: %w", err)})
 			continue
 		}
-		defer func() {
-			if err := file.Close(); err != nil {
-				utils.LogWarning("Failed to close video file: %v", err)
-			}
-		}()
-
-		// Process and clean tags
-		cleanedTags := processTags(upload.Tags)
 
 		// Create video insert request
-		video := &youtube.Video{
-			Snippet: &youtube.VideoSnippet{
-				Title:       upload.ShortTitle,
-				Description: upload.Description,
-				CategoryId:  categoryID,
-				Tags:        cleanedTags,
-			},
-			Status: &youtube.VideoStatus{
-				PrivacyStatus: privacyStatus,
-				PublishAt:     upload.PublishTime.Format(time.RFC3339),
-				MadeForKids:   false,
-			},
-		}
+		video := NewVideo(upload, privacyStatus, categoryID)
 
 		// Upload the video
+		if err := m.spend("videos.insert"); err != nil {
+			closeVideoFile(file)
+			deferRest(i)
+			break
+		}
+		uploadCtx, cancel := m.uploadContext(ctx)
 		call := service.Videos.Insert([]string{"snippet", "status"}, video)
 		call.NotifySubscribers(false) // Don't notify subscribers for shorts
-		response, err := call.Media(file).Do()
+		response, err := insertMedia(call, file).Context(uploadCtx).Do()
+		cancel()
+		closeVideoFile(file)
 		if err != nil {
-			utils.LogWarning("Failed to upload video: %v", err)
+			if ctx.Err() != nil {
+				return results, fmt.Errorf("stopped uploading %s: %w", upload.FileName, ctx.Err())
+			}
+			if isQuotaError(err) {
+				m.checkQuota(err)
+				deferRest(i)
+				break
+			}
+			utils.LogWarningContext(ctx, "Failed to upload video: %v", err)
+			results = append(results, UploadResult{FileName: upload.FileName, Err: fmt.Errorf("failed to upload video: %w", err)})
 			continue
 		}
 
-		utils.LogInfo("Successfully uploaded video: %s", response.Id)
-		utils.LogInfo("\t[%s] %s", upload.PublishTime.Format("2006-01-02 15:04:05"), upload.ShortTitle)
+		results = append(results, UploadResult{FileName: upload.FileName, VideoID: response.Id})
+		utils.LogInfoContext(ctx, "Successfully uploaded video: %s", response.Id)
+		utils.LogInfoContext(ctx, "\t[%s] %s", upload.PublishTime.Format("2006-01-02 15:04:05"), upload.ShortTitle)
 
 		// If playlist ID is provided, add the video to the playlist
 		if upload.PlaylistID != "" {
-			playlistItem := &youtube.PlaylistItem{
-				Snippet: &youtube.PlaylistItemSnippet{
-					PlaylistId: upload.PlaylistID,
-					ResourceId: &youtube.ResourceId{
-						Kind:    "youtube#video",
-						VideoId: response.Id,
-					},
-				},
-			}
+			m.addToPlaylist(ctx, service, upload.PlaylistID, response.Id)
+		}
+	}
 
-			_, err = service.PlaylistItems.Insert([]string{"snippet"}, playlistItem).Do()
-			if err != nil {
-				utils.LogWarning("Failed to add video to playlist: %v", err)
-			} else {
-				utils.LogInfo("Added video to playlist: %s", upload.PlaylistID)
-			}
+	return results, nil
+}
+
+// insertMedia attaches a video file to an insert call as a resumable upload that logs its
+// progress: percent, throughput and time left
+func insertMedia(call *youtube.VideosInsertCall, file *os.File) *youtube.VideosInsertCall {
+	var size int64
+	if info, err := file.Stat(); err == nil {
+		size = info.Size()
+	}
+	progress := utils.NewUploadProgress(filepath.Base(file.Name()), size)
+	return call.Media(file,
+		googleapi.ChunkSize(uploadChunkSize),
+		googleapi.ChunkTransferTimeout(uploadChunkTimeout),
+		googleapi.ChunkRetryDeadline(uploadRetryDeadline),
+	).ProgressUpdater(progress.Update)
+}
+
+// closeVideoFile closes an uploaded video file, logging failures as warnings
+func closeVideoFile(file *os.File) {
+	if err := file.Close(); err != nil {
+		utils.LogWarning("Failed to close video file: %v", err)
+	}
+}
+
+// addToPlaylist adds an uploaded video to a playlist, logging failures as warnings
+func (m *Service) addToPlaylist(ctx context.Context, service *youtube.Service, playlistID, videoID string) {
+	playlistItem := &youtube.PlaylistItem{
+		Snippet: &youtube.PlaylistItemSnippet{
+			PlaylistId: playlistID,
+			ResourceId: &youtube.ResourceId{
+				Kind:    "youtube#video",
+				VideoId: videoID,
+			},
+		},
+	}
+
+	err := m.spend("playlistItems.insert")
+	if err == nil {
+		callCtx, cancel := m.requestContext(ctx)
+		_, err = service.PlaylistItems.Insert([]string{"snippet"}, playlistItem).Context(callCtx).Do()
+		cancel()
+		m.checkQuota(err)
+	}
+	if err != nil {
+		utils.LogWarningContext(ctx, "Failed to add video to playlist: %v", err)
+	} else {
+		utils.LogInfoContext(ctx, "Added video to playlist: %s", playlistID)
+	}
+}
+
+// InsertVideo uploads a single video and returns its ID. Unlike UploadVideo it notifies
+// subscribers, publishes immediately when no publish time is set and fails instead of skipping.
+func (m *Service) InsertVideo(ctx context.Context, service *youtube.Service, upload VideoUpload, videoPath string, privacyStatus string, categoryID string) (string, error) {
+	if err := m.spend("videos.insert"); err != nil {
+		return "", err
+	}
+
+	file, err := os.Open(videoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open video file: %w", err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			utils.LogWarningContext(ctx, "Failed to close video file: %v", err)
 		}
+	}()
+
+	video := NewVideo(upload, privacyStatus, categoryID)
+
+	uploadCtx, cancel := m.uploadContext(ctx)
+	defer cancel()
+	response, err := insertMedia(service.Videos.Insert([]string{"snippet", "status"}, video), file).Context(uploadCtx).Do()
+	if err != nil {
+		m.checkQuota(err)
+		return "", fmt.Errorf("failed to upload video: %w", err)
 	}
+	utils.LogInfoContext(ctx, "Successfully uploaded video: %s", response.Id)
+
+	if upload.PlaylistID != "" {
+		m.addToPlaylist(ctx, service, upload.PlaylistID, response.Id)
+	}
+	return response.Id, nil
+}
 
+// UpdateVideoSnippet replaces the title, description, tags and category of an uploaded video
+func (m *Service) UpdateVideoSnippet(ctx context.Context, service *youtube.Service, videoID string, upload VideoUpload, categoryID string) error {
+	if err := m.spend("videos.update"); err != nil {
+		return err
+	}
+
+	video := &youtube.Video{
+		Id: videoID,
+		Snippet: &youtube.VideoSnippet{
+			Title:       upload.ShortTitle,
+			Description: upload.Description,
+			CategoryId:  categoryID,
+			Tags:        processTags(upload.Tags),
+		},
+	}
+	callCtx, cancel := m.requestContext(ctx)
+	defer cancel()
+	if _, err := service.Videos.Update([]string{"snippet"}, video).Context(callCtx).Do(); err != nil {
+		m.checkQuota(err)
+		return fmt.Errorf("failed to update video %s: %w", videoID, err)
+	}
 	return nil
 }
 
+// deferUploads records the uploads that were postponed because the daily quota ran out
+func (m *Service) deferUploads(ctx context.Context, uploads []VideoUpload) {
+	resetAt := ""
+	if m.quota != nil {
+		resetAt = m.quota.ResetAt().Local().Format("2006-01-02 15:04 MST")
+	}
+	utils.LogWarningContext(ctx, "YouTube API quota exhausted, deferring %d video(s) until the quota resets at %s:", len(uploads), resetAt)
+	for _, upload := range uploads {
+		utils.LogWarningContext(ctx, "\t[%s] %s (%s)", upload.PublishTime.Format("2006-01-02 15:04:05"), upload.ShortTitle, upload.FileName)
+		m.deferred = append(m.deferred, upload.ShortTitle)
+	}
+}
+
+// waitForQuotaReset blocks until the daily quota resets or the context is cancelled
+func (m *Service) waitForQuotaReset(ctx context.Context) error {
+	resetAt := m.quota.ResetAt()
+	utils.LogWarningContext(ctx, "YouTube API quota nearly exhausted, waiting until %s before uploading the next video", resetAt.Local().Format("2006-01-02 15:04 MST"))
+
+	timer := time.NewTimer(time.Until(resetAt) + time.Minute)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("stopped waiting for YouTube quota reset: %w", ctx.Err())
+	case <-timer.C:
+		return nil
+	}
+}
+
 // FindAvailability finds available time slots for video uploads
 func (m *Service) FindAvailability(scheduledVideos []ScheduledVideo, shortsData *utils.ShortsData, periodicity int, scheduleTime string, maxAttempts int, startDate string, playlistID string) ([]VideoUpload, error) {
 	// Parse the schedule time
@@ -399,7 +692,7 @@ func (m *Service) FindAvailability(scheduledVideos []ScheduledVideo, shortsData
 			if !scheduledTimes[publishTime] {
 				// Create video upload information
 				videoUpload := VideoUpload{
-					FileName:       fmt.Sprintf("%s-%s-withtext.mp4", convertToHHMMSS(short.StartTime), convertToHHMMSS(short.EndTime)),
+					FileName:       fmt.Sprintf("%s-%s-withtext.mp4", utils.CompactTimestamp(short.StartTime), utils.CompactTimestamp(short.EndTime)),
 					ShortTitle:     short.ShortTitle,
 					Description:    short.Description,
 					PublishTime:    publishTime,
@@ -438,7 +731,7 @@ func (m *Service) FindAvailability(scheduledVideos []ScheduledVideo, shortsData
 				if !publishTime.Before(now) && !scheduledTimes[publishTime] {
 					// Create video upload information
 					videoUpload := VideoUpload{
-						FileName:       fmt.Sprintf("%s-%s-withtext.mp4", convertToHHMMSS(short.StartTime), convertToHHMMSS(short.EndTime)),
+						FileName:       fmt.Sprintf("%s-%s-withtext.mp4", utils.CompactTimestamp(short.StartTime), utils.CompactTimestamp(short.EndTime)),
 						ShortTitle:     short.ShortTitle,
 						Description:    short.Description,
 						PublishTime:    publishTime,
@@ -482,8 +775,14 @@ func (m *Service) ListAvailableTimes(videoUploads []VideoUpload) error {
 // GetVideoDetails retrieves details of a specific video
 func (m *Service) GetVideoDetails(ctx context.Context, service *youtube.Service, videoID string) (*youtube.Video, error) {
 	// Get video details using the videos API
-	videoResponse, err := service.Videos.List([]string{"snippet"}).Id(videoID).Do()
+	if err := m.spend("videos.list"); err != nil {
+		return nil, err
+	}
+	callCtx, cancel := m.requestContext(ctx)
+	defer cancel()
+	videoResponse, err := service.Videos.List([]string{"snippet", "status"}).Id(videoID).Context(callCtx).Do()
 	if err != nil {
+		m.checkQuota(err)
 		return nil, fmt.Errorf("failed to get video details: %w", err)
 	}
 
@@ -493,3 +792,162 @@ func (m *Service) GetVideoDetails(ctx context.Context, service *youtube.Service,
 
 	return videoResponse.Items[0], nil
 }
+
+// ListChannelVideos retrieves the videos uploaded to the channel since the given time, newest first
+func (m *Service) ListChannelVideos(ctx context.Context, service *youtube.Service, since time.Time) ([]*youtube.Video, error) {
+	if err := m.spend("channels.list"); err != nil {
+		return nil, err
+	}
+	callCtx, cancel := m.requestContext(ctx)
+	channelsResponse, err := service.Channels.List([]string{"contentDetails"}).Mine(true).Context(callCtx).Do()
+	cancel()
+	if err != nil {
+		m.checkQuota(err)
+		return nil, fmt.Errorf("failed to get channel info: %w", err)
+	}
+	if len(channelsResponse.Items) == 0 || channelsResponse.Items[0].ContentDetails == nil ||
+		channelsResponse.Items[0].ContentDetails.RelatedPlaylists == nil {
+		return nil, fmt.Errorf("no channel found")
+	}
+	uploadsPlaylist := channelsResponse.Items[0].ContentDetails.RelatedPlaylists.Uploads
+
+	// The uploads playlist lists the newest videos first, so paging stops at the first older one
+	var videoIDs []string
+	pageToken := ""
+	for {
+		if err := m.spend("playlistItems.list"); err != nil {
+			return nil, err
+		}
+		callCtx, cancel = m.requestContext(ctx)
+		call := service.PlaylistItems.List([]string{"snippet", "contentDetails"}).
+			PlaylistId(uploadsPlaylist).
+			MaxResults(50).
+			Context(callCtx)
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		itemsResponse, err := call.Do()
+		cancel()
+		if err != nil {
+			m.checkQuota(err)
+			return nil, fmt.Errorf("failed to list channel uploads: %w", err)
+		}
+
+		older := false
+		for _, item := range itemsResponse.Items {
+			if item.ContentDetails == nil {
+				continue
+			}
+			uploadedAt, err := time.Parse(time.RFC3339, item.Snippet.PublishedAt)
+			if err == nil && uploadedAt.Before(since) {
+				older = true
+				break
+			}
+			videoIDs = append(videoIDs, item.ContentDetails.VideoId)
+		}
+		if older || itemsResponse.NextPageToken == "" {
+			break
+		}
+		pageToken = itemsResponse.NextPageToken
+	}
+
+	var videos []*youtube.Video
+	for start := 0; start < len(videoIDs); start += 50 {
+		end := start + 50
+		if end > len(videoIDs) {
+			end = len(videoIDs)
+		}
+		if err := m.spend("videos.list"); err != nil {
+			return nil, err
+		}
+		callCtx, cancel = m.requestContext(ctx)
+		videosResponse, err := service.Videos.List([]string{"snippet", "status", "contentDetails"}).
+			Id(videoIDs[start:end]...).
+			Context(callCtx).
+			Do()
+		cancel()
+		if err != nil {
+			m.checkQuota(err)
+			return nil, fmt.Errorf("failed to get video details: %w", err)
+		}
+		videos = append(videos, videosResponse.Items...)
+	}
+
+	return videos, nil
+}
+
+// UpdateVideoDescription replaces the description of an uploaded video. The title, tags, category
+// and language of video are sent back unchanged, because the API clears snippet fields left out.
+func (m *Service) UpdateVideoDescription(ctx context.Context, service *youtube.Service, video *youtube.Video, description string) error {
+	if video == nil || video.Snippet == nil {
+		return fmt.Errorf("video details are required to update a description")
+	}
+	if err := m.spend("videos.update"); err != nil {
+		return err
+	}
+
+	callCtx, cancel := m.requestContext(ctx)
+	defer cancel()
+	if _, err := service.Videos.Update([]string{"snippet"}, DescriptionUpdate(video, description)).Context(callCtx).Do(); err != nil {
+		m.checkQuota(err)
+		return fmt.Errorf("failed to update video %s: %w", video.Id, err)
+	}
+	return nil
+}
+
+// DescriptionUpdate builds the videos.update body that gives video a new description
+func DescriptionUpdate(video *youtube.Video, description string) *youtube.Video {
+	snippet := video.Snippet
+	return &youtube.Video{
+		Id: video.Id,
+		Snippet: &youtube.VideoSnippet{
+			Title:           snippet.Title,
+			Description:     description,
+			CategoryId:      snippet.CategoryId,
+			Tags:            snippet.Tags,
+			DefaultLanguage: snippet.DefaultLanguage,
+		},
+	}
+}
+
+// ParseDuration converts an ISO 8601 duration as reported by the API, such as "PT1M5S", to a time.Duration
+func ParseDuration(iso string) (time.Duration, error) {
+	rest, ok := strings.CutPrefix(iso, "P")
+	if !ok {
+		return 0, fmt.Errorf("invalid duration %q", iso)
+	}
+	var total time.Duration
+	inTime := false
+	number := ""
+	for _, r := range rest {
+		switch {
+		case r >= '0' && r <= '9':
+			number += string(r)
+			continue
+		case r == 'T':
+			inTime = true
+			continue
+		}
+		n, err := strconv.Atoi(number)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", iso)
+		}
+		number = ""
+		switch {
+		case r == 'D' && !inTime:
+			total += time.Duration(n) * 24 * time.Hour
+		case r == 'H' && inTime:
+			total += time.Duration(n) * time.Hour
+		case r == 'M' && inTime:
+			total += time.Duration(n) * time.Minute
+		case r == 'S' && inTime:
+			total += time.Duration(n) * time.Second
+		default:
+			return 0, fmt.Errorf("invalid duration %q", iso)
+		}
+	}
+	if number != "" {
+		return 0, fmt.Errorf("invalid duration %q", iso)
+	}
+	return total, nil
+}
@@ -0,0 +1,25 @@
+// Package shortstest writes the shorts files used by the tests of the modules that read the
+// output of suggest_shorts.
+package shortstest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// FileName is the name of the shorts files written by WriteFile
+const FileName = "shorts_suggestions.yaml"
+
+// WriteFile writes a shorts file to dir and returns its path. shorts is the YAML of the file's
+// shorts list and videoPath is recorded as its sourceVideo.
+func WriteFile(t testing.TB, dir, videoPath, shorts string) string {
+	t.Helper()
+	path := filepath.Join(dir, FileName)
+	content := fmt.Sprintf("sourceVideo: %q\n%s", videoPath, shorts)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("writing shorts file: %v", err)
+	}
+	return path
+}
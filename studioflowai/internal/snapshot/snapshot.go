@@ -0,0 +1,103 @@
+// Package snapshot compares text produced by tests with snapshots recorded in the package's
+// testdata folder, so prompt and parser changes that alter behavior show up as failing tests.
+//
+// Snapshots are recorded, and rewritten after an intended change, by running the tests with
+// UPDATE_SNAPSHOTS=1:
+//
+//	UPDATE_SNAPSHOTS=1 go test ./internal/modules/...
+//
+// Review the changed files in testdata/snapshots before committing them.
+package snapshot
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	chatgpt "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/chatgpt"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// UpdateEnv is the environment variable that records snapshots instead of comparing with them
+const UpdateEnv = "UPDATE_SNAPSHOTS"
+
+// Dir is the folder of a package's snapshots, relative to the package
+var Dir = filepath.Join("testdata", "snapshots")
+
+// unsafeChars matches the characters of test and snapshot names replaced in file names
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// Match compares got with the snapshot called name of the running test, stored in
+// testdata/snapshots/<test>/<name>. Replacements are old, new pairs applied to got first, to
+// remove what changes between runs such as temporary folders.
+func Match(t testing.TB, name, got string, replacements ...string) {
+	t.Helper()
+	if len(replacements)%2 != 0 {
+		t.Fatalf("snapshot %s: replacements must come in old, new pairs", name)
+	}
+	got = strings.NewReplacer(replacements...).Replace(got)
+
+	path := Path(t, name)
+	if os.Getenv(UpdateEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("snapshot %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatalf("snapshot %s: %v", name, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		t.Fatalf("snapshot %s does not exist; record it with %s=1 go test", path, UpdateEnv)
+	}
+	if err != nil {
+		t.Fatalf("snapshot %s: %v", name, err)
+	}
+	if diff := utils.UnifiedDiff(path, "got", string(want), got, 3); diff != "" {
+		t.Errorf("snapshot %s changed; if the change is intended, rerun with %s=1 and review the file\n%s", path, UpdateEnv, diff)
+	}
+}
+
+// MatchYAML compares v, written as YAML, with a snapshot
+func MatchYAML(t testing.TB, name string, v interface{}, replacements ...string) {
+	t.Helper()
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		t.Fatalf("snapshot %s: %v", name, err)
+	}
+	Match(t, name, string(data), replacements...)
+}
+
+// MatchMessages compares the messages of a chat completion request with a snapshot, one section
+// per message headed by its role
+func MatchMessages(t testing.TB, name string, messages []chatgpt.ChatMessage, replacements ...string) {
+	t.Helper()
+	Match(t, name, FormatMessages(messages), replacements...)
+}
+
+// FormatMessages writes chat messages as text, one section per message headed by its role
+func FormatMessages(messages []chatgpt.ChatMessage) string {
+	var b strings.Builder
+	for i, message := range messages {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "=== %s ===\n%s\n", message.Role, strings.TrimRight(message.Content, "\n"))
+	}
+	return b.String()
+}
+
+// Path returns the file of the snapshot called name of the running test
+func Path(t testing.TB, name string) string {
+	parts := strings.Split(t.Name(), "/")
+	for i, part := range parts {
+		parts[i] = unsafeChars.ReplaceAllString(part, "_")
+	}
+	return filepath.Join(append(append([]string{Dir}, parts...), unsafeChars.ReplaceAllString(name, "_"))...)
+}
@@ -0,0 +1,69 @@
+package snapshot
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	chatgpt "github.com/gnzdotmx/studioflowai/studioflowai/internal/services/chatgpt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder is a test that records its failures instead of failing
+type recorder struct {
+	testing.TB
+	name     string
+	failures []string
+}
+
+func (r *recorder) Name() string { return r.name }
+func (r *recorder) Helper()      {}
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func useDir(t *testing.T) string {
+	dir := t.TempDir()
+	orig := Dir
+	Dir = dir
+	t.Cleanup(func() { Dir = orig })
+	return dir
+}
+
+func TestMatch(t *testing.T) {
+	dir := useDir(t)
+	r := &recorder{TB: t, name: "TestPrompt/with spaces"}
+
+	// Missing snapshots fail with how to record them
+	Match(r, "prompt.txt", "hello\n")
+	require.NotEmpty(t, r.failures)
+	assert.Contains(t, r.failures[0], UpdateEnv+"=1")
+
+	t.Setenv(UpdateEnv, "1")
+	r.failures = nil
+	Match(r, "prompt.txt", "hello /tmp/run1\n", "/tmp/run1", "<tmp>")
+	assert.Empty(t, r.failures)
+	data, err := os.ReadFile(filepath.Join(dir, "TestPrompt", "with_spaces", "prompt.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello <tmp>\n", string(data))
+
+	t.Setenv(UpdateEnv, "")
+	Match(r, "prompt.txt", "hello /tmp/run2\n", "/tmp/run2", "<tmp>")
+	assert.Empty(t, r.failures)
+
+	Match(r, "prompt.txt", "goodbye\n")
+	require.Len(t, r.failures, 1)
+	assert.Contains(t, r.failures[0], "-hello <tmp>\n+goodbye")
+}
+
+func TestFormatMessages(t *testing.T) {
+	assert.Equal(t, "=== system ===\nYou are an editor.\n\n=== user ===\nFix this.\n", FormatMessages([]chatgpt.ChatMessage{
+		{Role: "system", Content: "You are an editor."},
+		{Role: "user", Content: "Fix this.\n\n"},
+	}))
+}
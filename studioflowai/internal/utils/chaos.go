@@ -0,0 +1,170 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// ChaosEnvVar turns on failure injection, a test-only mode that makes the failure, checkpoint and
+// retry paths run in CI. Its value is a comma-separated list of faults, each failing only its nth
+// occurrence when @n is given and every occurrence otherwise:
+//
+//	step:<name>[@n]  the step fails when it runs
+//	api[@n]          an outbound API request times out
+//	disk[@n]         a file write fails with "no space left on device"
+//
+// For example STUDIOFLOWAI_CHAOS="step:transcribe@1,api@2" fails the first run of the transcribe
+// step and the second API request. Occurrences are counted from the start of the process.
+const ChaosEnvVar = "STUDIOFLOWAI_CHAOS"
+
+// Kinds of injected faults
+const (
+	ChaosStep = "step"
+	ChaosAPI  = "api"
+	ChaosDisk = "disk"
+)
+
+// ChaosFault is one failure to inject
+type ChaosFault struct {
+	Kind   string // step, api or disk
+	Target string // Name of the step of a step fault
+	Nth    int    // Occurrence that fails, 0 for every one
+}
+
+var (
+	chaosMu     sync.Mutex
+	chaosFaults []ChaosFault
+	chaosCounts map[string]int
+)
+
+// ParseChaos parses the value of ChaosEnvVar
+func ParseChaos(spec string) ([]ChaosFault, error) {
+	var faults []ChaosFault
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		fault := ChaosFault{}
+		if rest, nth, ok := strings.Cut(item, "@"); ok {
+			n, err := strconv.Atoi(nth)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid fault %q: the occurrence after @ must be a number from 1", item)
+			}
+			fault.Nth = n
+			item = rest
+		}
+		fault.Kind, fault.Target, _ = strings.Cut(item, ":")
+		switch fault.Kind {
+		case ChaosStep:
+			if fault.Target == "" {
+				return nil, fmt.Errorf("invalid fault %q: name the step, as in step:transcribe", item)
+			}
+		case ChaosAPI, ChaosDisk:
+			if fault.Target != "" {
+				return nil, fmt.Errorf("invalid fault %q: %s faults take no target", item, fault.Kind)
+			}
+		default:
+			return nil, fmt.Errorf("invalid fault %q (supported: step:<name>, api, disk)", item)
+		}
+		faults = append(faults, fault)
+	}
+	return faults, nil
+}
+
+// SetChaos replaces the injected faults and resets their counts; nil turns failure injection off
+func SetChaos(faults []ChaosFault) {
+	chaosMu.Lock()
+	defer chaosMu.Unlock()
+	chaosFaults = faults
+	chaosCounts = make(map[string]int)
+}
+
+// LoadChaos turns on the faults listed in ChaosEnvVar, if any
+func LoadChaos() error {
+	spec := os.Getenv(ChaosEnvVar)
+	if spec == "" {
+		return nil
+	}
+	faults, err := ParseChaos(spec)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ChaosEnvVar, err)
+	}
+	SetChaos(faults)
+	LogWarning("Failure injection is on (%s=%s); this mode is meant for tests only", ChaosEnvVar, spec)
+	return nil
+}
+
+// chaosEnabled reports whether any fault of a kind is set
+func chaosEnabled(kind string) bool {
+	chaosMu.Lock()
+	defer chaosMu.Unlock()
+	for _, fault := range chaosFaults {
+		if fault.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// chaosStrikes counts an occurrence of kind (and target) and reports whether a fault fails it
+func chaosStrikes(kind, target string) bool {
+	chaosMu.Lock()
+	defer chaosMu.Unlock()
+	if len(chaosFaults) == 0 {
+		return false
+	}
+	key := kind + ":" + target
+	chaosCounts[key]++
+	for _, fault := range chaosFaults {
+		if fault.Kind == kind && fault.Target == target && (fault.Nth == 0 || fault.Nth == chaosCounts[key]) {
+			return true
+		}
+	}
+	return false
+}
+
+// InjectStepFailure returns the injected failure of a step's run, or nil
+func InjectStepFailure(step string) error {
+	if chaosStrikes(ChaosStep, step) {
+		return fmt.Errorf("injected failure of step %s (%s)", step, ChaosEnvVar)
+	}
+	return nil
+}
+
+// injectDiskFull returns an injected "no space left on device" error for a write to path, or nil
+func injectDiskFull(path string) error {
+	if chaosStrikes(ChaosDisk, "") {
+		return fmt.Errorf("failed to write to file: %w", &os.PathError{Op: "write", Path: path, Err: syscall.ENOSPC})
+	}
+	return nil
+}
+
+// chaosTimeout is the error of an API request that was made to time out
+type chaosTimeout struct{}
+
+func (chaosTimeout) Error() string   { return "injected API timeout (" + ChaosEnvVar + ")" }
+func (chaosTimeout) Timeout() bool   { return true }
+func (chaosTimeout) Temporary() bool { return true }
+func (chaosTimeout) Unwrap() error   { return context.DeadlineExceeded }
+
+// chaosTransport fails the API requests picked by the api faults with a timeout
+type chaosTransport struct {
+	next http.RoundTripper
+}
+
+func (t chaosTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if chaosStrikes(ChaosAPI, "") {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, chaosTimeout{}
+	}
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseChaos(t *testing.T) {
+	faults, err := ParseChaos("step:transcribe@2, api, disk@1")
+	require.NoError(t, err)
+	assert.Equal(t, []ChaosFault{
+		{Kind: ChaosStep, Target: "transcribe", Nth: 2},
+		{Kind: ChaosAPI},
+		{Kind: ChaosDisk, Nth: 1},
+	}, faults)
+
+	for _, spec := range []string{"step", "api:openai", "disk@0", "step:x@two", "network"} {
+		_, err := ParseChaos(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestInjectStepFailure(t *testing.T) {
+	SetChaos([]ChaosFault{{Kind: ChaosStep, Target: "transcribe", Nth: 2}})
+	defer SetChaos(nil)
+
+	assert.NoError(t, InjectStepFailure("transcribe"))
+	assert.ErrorContains(t, InjectStepFailure("transcribe"), "injected failure of step transcribe")
+	assert.NoError(t, InjectStepFailure("transcribe"))
+	assert.NoError(t, InjectStepFailure("split"))
+}
+
+func TestChaos_APIAndDisk(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	t.Setenv("NO_PROXY", "*")
+
+	SetChaos([]ChaosFault{{Kind: ChaosAPI, Nth: 1}, {Kind: ChaosDisk}})
+	defer SetChaos(nil)
+
+	// Only the first request times out, so a retry goes through
+	client := NewHTTPClient()
+	_, err := client.Get(server.URL)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	var netErr interface{ Timeout() bool }
+	require.True(t, errors.As(err, &netErr))
+	assert.True(t, netErr.Timeout())
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	err = WriteTextFile(filepath.Join(t.TempDir(), "out.txt"), "text")
+	assert.True(t, errors.Is(err, syscall.ENOSPC))
+}
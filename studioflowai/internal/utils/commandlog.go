@@ -0,0 +1,145 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CommandLogFileName is the script of external commands written to the output folder of every run
+const CommandLogFileName = "commands.sh"
+
+// CommandLog appends every external command of a run to a shell script, so a failing FFmpeg or
+// Whisper call can be reproduced by hand outside the tool
+type CommandLog struct {
+	mu     sync.Mutex
+	path   string
+	warned bool
+}
+
+// commandLogKey is the context key of the command log and the step writing to it
+type commandLogKey struct{}
+
+// commandLogContext is the command log of a context, with the step its commands belong to
+type commandLogContext struct {
+	log  *CommandLog
+	step string
+}
+
+// secretEnvName matches environment variables whose values are left out of the script
+var secretEnvName = regexp.MustCompile(`(?i)(KEY|SECRET|TOKEN|PASSWORD)`)
+
+// shellSafe matches arguments that need no quoting
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_./:=,+@%-]+$`)
+
+// OpenCommandLog starts the script at path, or appends to it when a retried run already wrote one
+func OpenCommandLog(path string) (*CommandLog, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		header := "#!/bin/sh\n" +
+			"# External commands run by StudioFlowAI, in order, with secrets redacted.\n" +
+			"# Copy a command to reproduce a failing step by hand.\n"
+		if err := AtomicWriteFile(path, []byte(header), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create command log: %w", err)
+		}
+	}
+	return &CommandLog{path: path}, nil
+}
+
+// WithCommandLog returns a context whose external commands are written to log under the step's name
+func WithCommandLog(ctx context.Context, log *CommandLog, step string) context.Context {
+	if log == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, commandLogKey{}, commandLogContext{log: log, step: step})
+}
+
+// logCommand writes cmd to the command log of the context, if any, and returns a function that
+// notes how it ended
+func logCommand(ctx context.Context, cmd *exec.Cmd) func(error) {
+	logCtx, ok := ctx.Value(commandLogKey{}).(commandLogContext)
+	if !ok {
+		return func(error) {}
+	}
+
+	started := time.Now()
+	logCtx.log.append(fmt.Sprintf("\n# %s, %s\n%s\n", logCtx.step, started.Format(time.RFC3339), FormatCommand(cmd)))
+	return func(err error) {
+		elapsed := time.Since(started).Round(100 * time.Millisecond)
+		var exitErr *exec.ExitError
+		switch {
+		case err == nil:
+			logCtx.log.append(fmt.Sprintf("# ok after %s\n", elapsed))
+		case errors.As(err, &exitErr):
+			logCtx.log.append(fmt.Sprintf("# failed with exit status %d after %s\n", exitErr.ExitCode(), elapsed))
+		default:
+			logCtx.log.append(fmt.Sprintf("# failed after %s: %s\n", elapsed, Redact(err.Error())))
+		}
+	}
+}
+
+// append adds text to the script; a log that cannot be written is reported once and never fails
+// the command
+func (l *CommandLog) append(text string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0755)
+	if err == nil {
+		_, err = f.WriteString(text)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil && !l.warned {
+		l.warned = true
+		LogWarning("Failed to write command log %s: %v", l.path, err)
+	}
+}
+
+// FormatCommand returns cmd as a shell command line: its working directory, the environment it sets
+// beyond the inherited one and its quoted arguments. Secrets are redacted.
+func FormatCommand(cmd *exec.Cmd) string {
+	var parts []string
+	if cmd.Env != nil {
+		inherited := make(map[string]bool)
+		for _, kv := range os.Environ() {
+			inherited[kv] = true
+		}
+		for _, kv := range cmd.Env {
+			if inherited[kv] {
+				continue
+			}
+			name, value, _ := strings.Cut(kv, "=")
+			if secretEnvName.MatchString(name) {
+				value = redactedText
+			}
+			parts = append(parts, name+"="+shellQuote(value))
+		}
+	}
+	for _, arg := range cmd.Args {
+		parts = append(parts, shellQuote(arg))
+	}
+
+	line := strings.Join(parts, " ")
+	if cmd.Dir != "" {
+		line = "(cd " + shellQuote(cmd.Dir) + " && " + line + ")"
+	}
+	if cmd.Stdin != nil {
+		line += " # reads stdin"
+	}
+	return Redact(line)
+}
+
+// shellQuote quotes an argument for sh when it contains anything but plain characters
+func shellQuote(arg string) string {
+	if shellSafe.MatchString(arg) {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
@@ -0,0 +1,181 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Whisper's own thresholds for retrying a segment at a higher temperature
+const (
+	DefaultConfidenceThreshold = -1.0 // avg_logprob below which a segment is unreliable
+	repetitionThreshold        = 2.4  // compression ratio above which a segment is likely repeated text
+	noSpeechThreshold          = 0.6  // no_speech_prob above which text is likely hallucinated
+	regionGap                  = 2.0  // seconds between flagged segments that are merged into one region
+)
+
+// WhisperSegment is a segment of Whisper's JSON output
+type WhisperSegment struct {
+	Start            float64 `json:"start"`
+	End              float64 `json:"end"`
+	Text             string  `json:"text"`
+	AvgLogprob       float64 `json:"avg_logprob"`
+	CompressionRatio float64 `json:"compression_ratio"`
+	NoSpeechProb     float64 `json:"no_speech_prob"`
+}
+
+// ConfidenceReport lists the regions of a transcript the speech recognizer was unsure about
+type ConfidenceReport struct {
+	Source                string             `yaml:"source"`                // Transcribed audio file
+	Threshold             float64            `yaml:"threshold"`             // avg_logprob below which segments are flagged
+	Segments              int                `yaml:"segments"`              // Number of segments in the transcript
+	LowConfidenceSegments int                `yaml:"lowConfidenceSegments"` // Number of flagged segments
+	AverageConfidence     float64            `yaml:"averageConfidence"`     // Mean segment confidence, from 0 to 1
+	Regions               []ConfidenceRegion `yaml:"regions"`               // Flagged segments, adjacent ones merged
+}
+
+// ConfidenceRegion is a stretch of flagged segments
+type ConfidenceRegion struct {
+	Start      string   `yaml:"start"`      // HH:MM:SS
+	End        string   `yaml:"end"`        // HH:MM:SS
+	Confidence float64  `yaml:"confidence"` // Confidence of the weakest segment, from 0 to 1
+	AvgLogprob float64  `yaml:"avgLogprob"` // avg_logprob of the weakest segment
+	Reasons    []string `yaml:"reasons"`    // Why the region was flagged
+	Text       string   `yaml:"text"`       // Transcribed text of the region
+}
+
+// ReadWhisperSegments reads the segments of a Whisper JSON transcript
+func ReadWhisperSegments(path string) ([]WhisperSegment, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Whisper JSON: %w", err)
+	}
+	var transcript struct {
+		Segments []WhisperSegment `json:"segments"`
+	}
+	if err := json.Unmarshal(data, &transcript); err != nil {
+		return nil, fmt.Errorf("failed to parse Whisper JSON: %w", err)
+	}
+	return transcript.Segments, nil
+}
+
+// SegmentConfidence converts an average log probability to a confidence between 0 and 1
+func SegmentConfidence(avgLogprob float64) float64 {
+	return math.Min(1, math.Exp(avgLogprob))
+}
+
+// BuildConfidenceReport flags unreliable segments and merges neighbouring ones into regions
+func BuildConfidenceReport(source string, segments []WhisperSegment, threshold float64) ConfidenceReport {
+	report := ConfidenceReport{Source: source, Threshold: threshold, Segments: len(segments), Regions: []ConfidenceRegion{}}
+
+	var total float64
+	var current *ConfidenceRegion
+	var currentEnd float64
+	for _, segment := range segments {
+		confidence := SegmentConfidence(segment.AvgLogprob)
+		total += confidence
+
+		reasons := segmentReasons(segment, threshold)
+		if len(reasons) == 0 {
+			continue
+		}
+		report.LowConfidenceSegments++
+
+		text := strings.TrimSpace(segment.Text)
+		if current != nil && segment.Start-currentEnd <= regionGap {
+			current.End = FormatTimestamp(secondsDuration(segment.End))
+			current.Text = strings.TrimSpace(current.Text + " " + text)
+			if segment.AvgLogprob < current.AvgLogprob {
+				current.AvgLogprob = roundTo(segment.AvgLogprob, 3)
+				current.Confidence = roundTo(confidence, 2)
+			}
+			for _, reason := range reasons {
+				if !containsString(current.Reasons, reason) {
+					current.Reasons = append(current.Reasons, reason)
+				}
+			}
+		} else {
+			report.Regions = append(report.Regions, ConfidenceRegion{
+				Start:      FormatTimestamp(secondsDuration(segment.Start)),
+				End:        FormatTimestamp(secondsDuration(segment.End)),
+				Confidence: roundTo(confidence, 2),
+				AvgLogprob: roundTo(segment.AvgLogprob, 3),
+				Reasons:    reasons,
+				Text:       text,
+			})
+			current = &report.Regions[len(report.Regions)-1]
+		}
+		currentEnd = segment.End
+	}
+
+	if len(segments) > 0 {
+		report.AverageConfidence = roundTo(total/float64(len(segments)), 2)
+	}
+	return report
+}
+
+// segmentReasons returns why a segment is unreliable, or nil
+func segmentReasons(segment WhisperSegment, threshold float64) []string {
+	var reasons []string
+	if segment.AvgLogprob < threshold {
+		reasons = append(reasons, "low confidence")
+	}
+	if segment.CompressionRatio > repetitionThreshold {
+		reasons = append(reasons, "repetitive text")
+	}
+	if segment.NoSpeechProb > noSpeechThreshold && strings.TrimSpace(segment.Text) != "" {
+		reasons = append(reasons, "possibly no speech")
+	}
+	return reasons
+}
+
+// WriteConfidenceReport writes a confidence report as YAML
+func WriteConfidenceReport(path string, report ConfidenceReport) error {
+	data, err := yaml.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal confidence report: %w", err)
+	}
+	if err := AtomicWriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write confidence report: %w", err)
+	}
+	return nil
+}
+
+// ReadConfidenceReport reads a confidence report written by WriteConfidenceReport
+func ReadConfidenceReport(path string) (*ConfidenceReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read confidence report: %w", err)
+	}
+	var report ConfidenceReport
+	if err := yaml.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse confidence report: %w", err)
+	}
+	return &report, nil
+}
+
+// secondsDuration converts fractional seconds to a duration
+func secondsDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// roundTo rounds a value to the given number of decimals
+func roundTo(value float64, decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(value*scale) / scale
+}
+
+// containsString reports whether a slice contains s
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
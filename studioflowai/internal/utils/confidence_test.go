@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildConfidenceReport(t *testing.T) {
+	segments := []WhisperSegment{
+		{Start: 0, End: 5, Text: "Welcome back to the show.", AvgLogprob: -0.15, CompressionRatio: 1.2},
+		{Start: 5, End: 9, Text: "Today we talk about", AvgLogprob: -1.3, CompressionRatio: 1.3},
+		{Start: 10, End: 12, Text: "eBPF and", AvgLogprob: -1.8, CompressionRatio: 1.1},
+		{Start: 12, End: 20, Text: "Thanks for listening.", AvgLogprob: -0.3, CompressionRatio: 1.0},
+		{Start: 60, End: 70, Text: "the the the the the", AvgLogprob: -0.5, CompressionRatio: 3.1},
+		{Start: 80, End: 82, Text: "Bye.", AvgLogprob: -0.4, NoSpeechProb: 0.9},
+	}
+
+	report := BuildConfidenceReport("audio.wav", segments, DefaultConfidenceThreshold)
+	assert.Equal(t, 6, report.Segments)
+	assert.Equal(t, 4, report.LowConfidenceSegments)
+	require.Len(t, report.Regions, 3)
+
+	// Flagged segments less than two seconds apart are one region, keeping the weakest confidence
+	merged := report.Regions[0]
+	assert.Equal(t, "00:00:05", merged.Start)
+	assert.Equal(t, "00:00:12", merged.End)
+	assert.Equal(t, "Today we talk about eBPF and", merged.Text)
+	assert.Equal(t, -1.8, merged.AvgLogprob)
+	assert.Equal(t, 0.17, merged.Confidence)
+	assert.Equal(t, []string{"low confidence"}, merged.Reasons)
+
+	assert.Equal(t, []string{"repetitive text"}, report.Regions[1].Reasons)
+	assert.Equal(t, []string{"possibly no speech"}, report.Regions[2].Reasons)
+	assert.InDelta(t, 0.55, report.AverageConfidence, 0.01)
+}
+
+func TestBuildConfidenceReportEmpty(t *testing.T) {
+	report := BuildConfidenceReport("audio.wav", nil, DefaultConfidenceThreshold)
+	assert.Zero(t, report.AverageConfidence)
+	assert.Empty(t, report.Regions)
+}
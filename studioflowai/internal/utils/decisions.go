@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// DefaultDecisionsFile is the name the shorts report saves its review decisions under
+const DefaultDecisionsFile = "shorts_decisions.json"
+
+// ShortDecisions records which suggested shorts a reviewer accepted for upload
+type ShortDecisions struct {
+	ShortsFile string          `json:"shortsFile"` // Shorts file the report was built from
+	UpdatedAt  time.Time       `json:"updatedAt"`
+	Decisions  []ShortDecision `json:"decisions"`
+}
+
+// ShortDecision is the review decision of one clip. Clips are matched on their times, so
+// reordering the shorts file keeps the decisions.
+type ShortDecision struct {
+	StartTime string `json:"startTime"`
+	EndTime   string `json:"endTime"`
+	Title     string `json:"title,omitempty"`
+	Accepted  bool   `json:"accepted"`
+}
+
+// ReadShortDecisions reads a decisions file saved from the shorts report
+func ReadShortDecisions(path string) (*ShortDecisions, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read decisions file: %w", err)
+	}
+	var decisions ShortDecisions
+	if err := json.Unmarshal(data, &decisions); err != nil {
+		return nil, fmt.Errorf("failed to parse decisions file %s: %w", path, err)
+	}
+	return &decisions, nil
+}
+
+// Decision returns the decision recorded for a clip, if any
+func (d *ShortDecisions) Decision(startTime, endTime string) (ShortDecision, bool) {
+	for _, decision := range d.Decisions {
+		if decision.StartTime == startTime && decision.EndTime == endTime {
+			return decision, true
+		}
+	}
+	return ShortDecision{}, false
+}
+
+// AcceptedShorts returns the shorts not rejected in the decisions file at path. An empty path
+// accepts every short; a missing file is an error, so unreviewed clips are never uploaded.
+func AcceptedShorts(shorts []ShortClip, path string) ([]ShortClip, error) {
+	if path == "" {
+		return shorts, nil
+	}
+	decisions, err := ReadShortDecisions(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("no review decisions at %s: open the shorts report, save the decisions there and run this step again", path)
+		}
+		return nil, err
+	}
+
+	accepted := make([]ShortClip, 0, len(shorts))
+	for _, short := range shorts {
+		decision, ok := decisions.Decision(short.StartTime, short.EndTime)
+		if !ok {
+			LogWarning("Short %q (%s-%s) has no review decision, keeping it", short.ShortTitle, short.StartTime, short.EndTime)
+		}
+		if ok && !decision.Accepted {
+			LogInfo("Skipping rejected short %q (%s-%s)", short.ShortTitle, short.StartTime, short.EndTime)
+			continue
+		}
+		accepted = append(accepted, short)
+	}
+	return accepted, nil
+}
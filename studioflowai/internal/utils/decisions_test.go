@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcceptedShorts(t *testing.T) {
+	shorts := []ShortClip{
+		{ShortTitle: "one", StartTime: "00:01:00", EndTime: "00:01:30"},
+		{ShortTitle: "two", StartTime: "00:02:00", EndTime: "00:02:30"},
+		{ShortTitle: "three", StartTime: "00:03:00", EndTime: "00:03:30"},
+	}
+
+	accepted, err := AcceptedShorts(shorts, "")
+	require.NoError(t, err)
+	assert.Len(t, accepted, 3)
+
+	path := filepath.Join(t.TempDir(), DefaultDecisionsFile)
+	_, err = AcceptedShorts(shorts, path)
+	assert.ErrorContains(t, err, "no review decisions")
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"shortsFile": "shorts_suggestions.yaml", "decisions": [
+		{"startTime": "00:01:00", "endTime": "00:01:30", "accepted": true},
+		{"startTime": "00:02:00", "endTime": "00:02:30", "accepted": false}]}`), 0644))
+	accepted, err = AcceptedShorts(shorts, path)
+	require.NoError(t, err)
+	require.Len(t, accepted, 2)
+	assert.Equal(t, "one", accepted[0].ShortTitle)
+	assert.Equal(t, "three", accepted[1].ShortTitle, "clips without a decision are kept")
+}
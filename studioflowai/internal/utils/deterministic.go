@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var (
+	runMu         sync.RWMutex
+	runSeed       int64
+	deterministic bool
+)
+
+// SetRunSeed sets the seed sent to model providers that support one
+func SetRunSeed(seed int64) {
+	runMu.Lock()
+	defer runMu.Unlock()
+	runSeed = seed
+}
+
+// RunSeed returns the seed of the current run, or 0 when none was set
+func RunSeed() int64 {
+	runMu.RLock()
+	defer runMu.RUnlock()
+	return runSeed
+}
+
+// SetDeterministic turns deterministic mode on or off. In deterministic mode models run at
+// temperature 0, directory inputs are chosen by name rather than modification time and run
+// folders are named after their inputs.
+func SetDeterministic(enabled bool) {
+	runMu.Lock()
+	defer runMu.Unlock()
+	deterministic = enabled
+}
+
+// Deterministic reports whether the run should be reproducible
+func Deterministic() bool {
+	runMu.RLock()
+	defer runMu.RUnlock()
+	return deterministic
+}
+
+// RandomSeed returns a new positive seed that fits in 31 bits, the range all providers accept
+func RandomSeed() int64 {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return 1
+	}
+	return int64(binary.BigEndian.Uint64(b[:])%(1<<31-1)) + 1
+}
+
+// InputsDigest returns the SHA-256 of the contents of paths, in order, so copies of the same inputs
+// in another checkout get the same digest. Directories contribute their files by relative name and
+// content. Empty paths are skipped.
+func InputsDigest(paths ...string) (string, error) {
+	hash := sha256.New()
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to hash %s: %w", path, err)
+		}
+		if !info.IsDir() {
+			if err := hashFile(hash, path); err != nil {
+				return "", err
+			}
+			continue
+		}
+		err = filepath.WalkDir(path, func(p string, entry fs.DirEntry, err error) error {
+			if err != nil || !entry.Type().IsRegular() {
+				return err
+			}
+			rel, err := filepath.Rel(path, p)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(hash, "%s\x00", filepath.ToSlash(rel))
+			return hashFile(hash, p)
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to hash %s: %w", path, err)
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// hashFile writes the contents of path to hash, followed by its size so files cannot run together
+func hashFile(hash io.Writer, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	size, err := io.Copy(hash, file)
+	fmt.Fprintf(hash, "\x00%d\x00", size)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	return nil
+}
+
+// SeedFromDigest derives a seed from a hex digest, so the same inputs always get the same seed
+func SeedFromDigest(digest string) int64 {
+	b, err := hex.DecodeString(digest)
+	if err != nil || len(b) < 8 {
+		return 1
+	}
+	return int64(binary.BigEndian.Uint64(b)%(1<<31-1)) + 1
+}
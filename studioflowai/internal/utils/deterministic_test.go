@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInputsDigest(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+	workflow := write("workflow.yaml", "name: test")
+	copied := write("copy.yaml", "name: test")
+	input := write("input.txt", "transcript")
+
+	digest, err := InputsDigest(workflow, input)
+	require.NoError(t, err)
+	again, err := InputsDigest(copied, "", input)
+	require.NoError(t, err)
+	assert.Equal(t, digest, again, "only contents count")
+
+	other, err := InputsDigest(input, workflow)
+	require.NoError(t, err)
+	assert.NotEqual(t, digest, other, "order counts")
+
+	sub := filepath.Join(dir, "sub")
+	require.NoError(t, os.Mkdir(sub, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(sub, "a.wav"), []byte("audio"), 0644))
+	first, err := InputsDigest(sub)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(sub, "a.wav"), []byte("other"), 0644))
+	second, err := InputsDigest(sub)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second, "directories are hashed by their files")
+
+	_, err = InputsDigest(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
+
+func TestSeeds(t *testing.T) {
+	digest, err := InputsDigest()
+	require.NoError(t, err)
+	seed := SeedFromDigest(digest)
+	assert.Equal(t, seed, SeedFromDigest(digest))
+	assert.True(t, seed > 0 && seed < 1<<31)
+
+	for i := 0; i < 100; i++ {
+		seed := RandomSeed()
+		assert.True(t, seed > 0 && seed < 1<<31)
+	}
+}
@@ -0,0 +1,206 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DiffKind tells whether a run of tokens is in both texts, only the old one or only the new one
+type DiffKind int
+
+const (
+	DiffEqual DiffKind = iota
+	DiffDelete
+	DiffInsert
+)
+
+// DiffOp is a run of tokens of the same kind
+type DiffOp struct {
+	Kind   DiffKind
+	Tokens []string
+}
+
+// DefaultMaxDiffEdits bounds the work of DiffTokens; its memory grows with the square of the edits
+const DefaultMaxDiffEdits = 4000
+
+// DiffTokens returns the shortest edit script turning a into b (Myers' algorithm). When the texts
+// differ by more than maxEdits tokens, the part between their common prefix and suffix is reported
+// as replaced as a whole, and ok is false.
+func DiffTokens(a, b []string, maxEdits int) (ops []DiffOp, ok bool) {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var script []DiffOp
+	appendOp := func(kind DiffKind, tokens ...string) {
+		if len(tokens) == 0 {
+			return
+		}
+		if n := len(script); n > 0 && script[n-1].Kind == kind {
+			script[n-1].Tokens = append(script[n-1].Tokens, tokens...)
+			return
+		}
+		script = append(script, DiffOp{Kind: kind, Tokens: append([]string(nil), tokens...)})
+	}
+
+	appendOp(DiffEqual, a[:prefix]...)
+	middle, ok := myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix], maxEdits)
+	if !ok {
+		appendOp(DiffDelete, a[prefix:len(a)-suffix]...)
+		appendOp(DiffInsert, b[prefix:len(b)-suffix]...)
+	}
+	for _, op := range middle {
+		appendOp(op.Kind, op.Tokens...)
+	}
+	appendOp(DiffEqual, a[len(a)-suffix:]...)
+	return script, ok
+}
+
+// myers computes the edit script of two token lists, one token per op and in order, or reports
+// false when more than maxEdits edits are needed
+func myers(a, b []string, maxEdits int) ([]DiffOp, bool) {
+	n, m := len(a), len(b)
+	limit := min(n+m, maxEdits)
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+	// trace[d] holds v for diagonals -d..d after d edits
+	var trace [][]int
+
+	for d := 0; d <= limit; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+				return backtrack(a, b, trace), true
+			}
+		}
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+	}
+	return nil, false
+}
+
+// backtrack walks the trace of myers back from the end of both lists
+func backtrack(a, b []string, trace [][]int) []DiffOp {
+	var reversed []DiffOp
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d > 0; d-- {
+		previous := trace[d-1]
+		at := func(k int) int { return previous[k+d-1] }
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			reversed = append(reversed, DiffOp{Kind: DiffEqual, Tokens: []string{a[x-1]}})
+			x--
+			y--
+		}
+		if x == prevX {
+			reversed = append(reversed, DiffOp{Kind: DiffInsert, Tokens: []string{b[y-1]}})
+		} else {
+			reversed = append(reversed, DiffOp{Kind: DiffDelete, Tokens: []string{a[x-1]}})
+		}
+		x, y = prevX, prevY
+	}
+	for ; x > 0; x-- {
+		reversed = append(reversed, DiffOp{Kind: DiffEqual, Tokens: []string{a[x-1]}})
+	}
+
+	ops := make([]DiffOp, len(reversed))
+	for i, op := range reversed {
+		ops[len(reversed)-1-i] = op
+	}
+	return ops
+}
+
+// UnifiedDiff formats a line diff of two texts in the unified format of diff -u, with the given
+// number of context lines around each change. It returns "" when the texts are equal.
+func UnifiedDiff(oldName, newName, oldText, newText string, context int) string {
+	oldLines := strings.Split(oldText, "\n")
+	newLines := strings.Split(newText, "\n")
+	ops, _ := DiffTokens(oldLines, newLines, DefaultMaxDiffEdits)
+
+	// Flatten to one line per entry, remembering the line numbers in both texts
+	type line struct {
+		kind     DiffKind
+		text     string
+		old, new int
+	}
+	var lines []line
+	oldNo, newNo := 1, 1
+	for _, op := range ops {
+		for _, text := range op.Tokens {
+			lines = append(lines, line{kind: op.Kind, text: text, old: oldNo, new: newNo})
+			if op.Kind != DiffInsert {
+				oldNo++
+			}
+			if op.Kind != DiffDelete {
+				newNo++
+			}
+		}
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(lines); {
+		if lines[i].kind == DiffEqual {
+			i++
+			continue
+		}
+		// A hunk runs until more than twice the context of unchanged lines follows a change
+		start := max(i-context, 0)
+		end := i
+		for j := i; j < len(lines); j++ {
+			if lines[j].kind != DiffEqual {
+				end = j + 1
+			} else if j-end >= 2*context {
+				break
+			}
+		}
+		end = min(end+context, len(lines))
+
+		oldCount, newCount := 0, 0
+		for _, l := range lines[start:end] {
+			if l.kind != DiffInsert {
+				oldCount++
+			}
+			if l.kind != DiffDelete {
+				newCount++
+			}
+		}
+		if b.Len() == 0 {
+			fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+		}
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", lines[start].old, oldCount, lines[start].new, newCount)
+		for _, l := range lines[start:end] {
+			prefix := " "
+			switch l.kind {
+			case DiffDelete:
+				prefix = "-"
+			case DiffInsert:
+				prefix = "+"
+			}
+			b.WriteString(prefix + l.text + "\n")
+		}
+		i = end
+	}
+	return b.String()
+}
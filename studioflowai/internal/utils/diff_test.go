@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// applyDiff rebuilds both texts from an edit script
+func applyDiff(ops []DiffOp) (old, new []string) {
+	for _, op := range ops {
+		if op.Kind != DiffInsert {
+			old = append(old, op.Tokens...)
+		}
+		if op.Kind != DiffDelete {
+			new = append(new, op.Tokens...)
+		}
+	}
+	return old, new
+}
+
+func TestDiffTokens(t *testing.T) {
+	a := strings.Fields("the quick brown fox jumps over the lazy dog")
+	b := strings.Fields("the quick red fox jumped over the lazy dog today")
+
+	ops, ok := DiffTokens(a, b, DefaultMaxDiffEdits)
+	assert.True(t, ok)
+	assert.Equal(t, []DiffOp{
+		{Kind: DiffEqual, Tokens: []string{"the", "quick"}},
+		{Kind: DiffDelete, Tokens: []string{"brown"}},
+		{Kind: DiffInsert, Tokens: []string{"red"}},
+		{Kind: DiffEqual, Tokens: []string{"fox"}},
+		{Kind: DiffDelete, Tokens: []string{"jumps"}},
+		{Kind: DiffInsert, Tokens: []string{"jumped"}},
+		{Kind: DiffEqual, Tokens: []string{"over", "the", "lazy", "dog"}},
+		{Kind: DiffInsert, Tokens: []string{"today"}},
+	}, ops)
+
+	old, new := applyDiff(ops)
+	assert.Equal(t, a, old)
+	assert.Equal(t, b, new)
+}
+
+func TestDiffTokens_EditLimit(t *testing.T) {
+	a := strings.Fields("keep a b c keep")
+	b := strings.Fields("keep x y z keep")
+
+	ops, ok := DiffTokens(a, b, 2)
+	assert.False(t, ok)
+	assert.Equal(t, []DiffOp{
+		{Kind: DiffEqual, Tokens: []string{"keep"}},
+		{Kind: DiffDelete, Tokens: []string{"a", "b", "c"}},
+		{Kind: DiffInsert, Tokens: []string{"x", "y", "z"}},
+		{Kind: DiffEqual, Tokens: []string{"keep"}},
+	}, ops)
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	old := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten"
+	new := "one\ntwo\nthree\nfour\nFIVE\nsix\nseven\neight\nnine\nten"
+
+	assert.Equal(t, "--- raw.txt\n+++ corrected.txt\n@@ -2,7 +2,7 @@\n two\n three\n four\n-five\n+FIVE\n six\n seven\n eight\n",
+		UnifiedDiff("raw.txt", "corrected.txt", old, new, 3))
+	assert.Empty(t, UnifiedDiff("a", "b", old, old, 3))
+}
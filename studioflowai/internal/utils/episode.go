@@ -0,0 +1,281 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// episodeKey is the key episode metadata is stored under in generated files
+const episodeKey = "episode"
+
+// episodeKeyOrder lists well-known metadata keys first when rendering, the rest follow alphabetically
+var episodeKeyOrder = []string{"show", "episode", "title", "guest", "guests", "host", "hosts", "recordingDate", "links"}
+
+// LoadEpisodeMetadata reads a YAML mapping of episode details (guest, episode number, recording date, links...)
+func LoadEpisodeMetadata(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata file: %w", err)
+	}
+
+	var meta map[string]interface{}
+	if err := yaml.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata file %s: %w", path, err)
+	}
+
+	// Accept files that nest everything under episode:, like the front matter we write
+	if nested, ok := meta[episodeKey].(map[string]interface{}); ok && len(meta) == 1 {
+		meta = nested
+	}
+	return MergeEpisodeMetadata(meta), nil
+}
+
+// ResolveEpisodeMetadata merges a metadata file with inline metadata, inline keys winning
+func ResolveEpisodeMetadata(inline map[string]interface{}, file string) (map[string]interface{}, error) {
+	var fromFile map[string]interface{}
+	if file != "" {
+		var err error
+		if fromFile, err = LoadEpisodeMetadata(file); err != nil {
+			return nil, err
+		}
+	}
+	return MergeEpisodeMetadata(fromFile, inline), nil
+}
+
+// MergeEpisodeMetadata combines metadata layers, later layers overriding earlier ones.
+// Dates are normalized to strings so they read the same in prompts and outputs.
+func MergeEpisodeMetadata(layers ...map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{})
+	for _, layer := range layers {
+		for k, v := range layer {
+			merged[k] = normalizeMetadataValue(v)
+		}
+	}
+	return merged
+}
+
+// normalizeMetadataValue turns YAML timestamps into plain dates, recursively
+func normalizeMetadataValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case time.Time:
+		if val.Equal(val.Truncate(24 * time.Hour)) {
+			return val.Format("2006-01-02")
+		}
+		return val.Format(time.RFC3339)
+	case map[string]interface{}:
+		normalized := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			normalized[k] = normalizeMetadataValue(item)
+		}
+		return normalized
+	case []interface{}:
+		normalized := make([]interface{}, len(val))
+		for i, item := range val {
+			normalized[i] = normalizeMetadataValue(item)
+		}
+		return normalized
+	default:
+		return v
+	}
+}
+
+// EpisodeMetadataPrompt renders the metadata as a prompt section, or "" when there is none
+func EpisodeMetadataPrompt(meta map[string]interface{}) string {
+	if len(meta) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("Episode details (mention them where relevant and keep names, numbers and links exactly as written):\n")
+	for _, key := range episodeKeys(meta) {
+		fmt.Fprintf(&b, "- %s: %s\n", key, formatMetadataValue(meta[key]))
+	}
+	return b.String()
+}
+
+// episodeKeys returns the metadata keys with well-known ones first
+func episodeKeys(meta map[string]interface{}) []string {
+	var keys []string
+	for _, key := range episodeKeyOrder {
+		if _, ok := meta[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+	var rest []string
+	for key := range meta {
+		if !slices.Contains(episodeKeyOrder, key) {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+	return append(keys, rest...)
+}
+
+// formatMetadataValue renders lists and nested mappings on a single line
+func formatMetadataValue(v interface{}) string {
+	switch val := v.(type) {
+	case []interface{}:
+		items := make([]string, len(val))
+		for i, item := range val {
+			items[i] = formatMetadataValue(item)
+		}
+		return strings.Join(items, ", ")
+	case map[string]interface{}:
+		items := make([]string, 0, len(val))
+		for _, key := range episodeKeys(val) {
+			items = append(items, key+": "+formatMetadataValue(val[key]))
+		}
+		return strings.Join(items, "; ")
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// FormatSeriesTitle names a title after a series pattern such as "EP{n}: {title}". {n} is the
+// episode number, {title} the generated title and other {key} placeholders take the episode
+// metadata of that key. Titles that already carry the pattern's prefix or suffix are not
+// decorated twice.
+func FormatSeriesTitle(pattern, title string, meta map[string]interface{}) string {
+	if !strings.Contains(pattern, "{title}") {
+		return title
+	}
+
+	var pairs []string
+	if episode, ok := meta[episodeKey]; ok {
+		pairs = append(pairs, "{n}", formatMetadataValue(episode))
+	}
+	for key, value := range meta {
+		if key != "title" {
+			pairs = append(pairs, "{"+key+"}", formatMetadataValue(value))
+		}
+	}
+	prefix, suffix, _ := strings.Cut(strings.NewReplacer(pairs...).Replace(pattern), "{title}")
+
+	title = strings.TrimSpace(title)
+	if p := strings.TrimSpace(prefix); p != "" {
+		title = strings.TrimSpace(strings.TrimPrefix(title, p))
+	}
+	if s := strings.TrimSpace(suffix); s != "" {
+		title = strings.TrimSpace(strings.TrimSuffix(title, s))
+	}
+	return prefix + title + suffix
+}
+
+// EpisodeFields renders every metadata value as a single line of text. The episode number is
+// also available as "n", as in series title patterns.
+func EpisodeFields(meta map[string]interface{}) map[string]string {
+	fields := make(map[string]string, len(meta)+1)
+	for key, value := range meta {
+		fields[key] = formatMetadataValue(value)
+	}
+	if episode, ok := fields[episodeKey]; ok {
+		fields["n"] = episode
+	}
+	return fields
+}
+
+// SplitEpisodeFrontMatter separates the episode front matter written by correct_transcript from a
+// transcript. Text without an episode front matter block is returned unchanged.
+func SplitEpisodeFrontMatter(text string) (map[string]interface{}, string) {
+	if !strings.HasPrefix(text, "---\n") {
+		return nil, text
+	}
+	end := strings.Index(text[4:], "\n---")
+	if end < 0 {
+		return nil, text
+	}
+	block := text[4 : 4+end]
+	body := strings.TrimPrefix(text[4+end+4:], "\n")
+
+	var frontMatter map[string]interface{}
+	if err := yaml.Unmarshal([]byte(block), &frontMatter); err != nil {
+		return nil, text
+	}
+	meta, ok := frontMatter[episodeKey].(map[string]interface{})
+	if !ok {
+		return nil, text
+	}
+	return MergeEpisodeMetadata(meta), body
+}
+
+// WithEpisodeFrontMatter adds the metadata under episode: to the front matter of a text or
+// Markdown document, creating the front matter block when the document has none
+func WithEpisodeFrontMatter(text string, meta map[string]interface{}) (string, error) {
+	if len(meta) == 0 {
+		return text, nil
+	}
+
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	body := text
+	if strings.HasPrefix(text, "---\n") {
+		if end := strings.Index(text[4:], "\n---"); end >= 0 {
+			var existing yaml.Node
+			if err := yaml.Unmarshal([]byte(text[4:4+end]), &existing); err == nil &&
+				len(existing.Content) > 0 && existing.Content[0].Kind == yaml.MappingNode {
+				root = existing.Content[0]
+				body = strings.TrimPrefix(text[4+end+4:], "\n")
+			}
+		}
+	}
+
+	value, err := EpisodeNode(meta)
+	if err != nil {
+		return "", err
+	}
+	SetMappingValue(root, episodeKey, value)
+
+	data, err := yaml.Marshal(root)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate front matter: %w", err)
+	}
+	return "---\n" + string(data) + "---\n" + body, nil
+}
+
+// WithEpisodeSection adds the metadata as the first episode: key of a YAML mapping document.
+// Content that is not a YAML mapping is returned unchanged.
+func WithEpisodeSection(content string, meta map[string]interface{}) (string, error) {
+	if len(meta) == 0 {
+		return content, nil
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return content, nil
+	}
+	root := doc.Content[0]
+
+	value, err := EpisodeNode(meta)
+	if err != nil {
+		return "", err
+	}
+	if MappingValue(root, episodeKey) != nil {
+		SetMappingValue(root, episodeKey, value)
+	} else {
+		root.Content = append([]*yaml.Node{{Kind: yaml.ScalarNode, Tag: "!!str", Value: episodeKey}, value}, root.Content...)
+	}
+
+	data, err := yaml.Marshal(&doc)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate YAML: %w", err)
+	}
+	return string(data), nil
+}
+
+// EpisodeNode encodes the metadata as a YAML mapping with well-known keys first
+func EpisodeNode(meta map[string]interface{}) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, key := range episodeKeys(meta) {
+		var value yaml.Node
+		if err := value.Encode(meta[key]); err != nil {
+			return nil, fmt.Errorf("failed to encode episode metadata %s: %w", key, err)
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, &value)
+	}
+	return node, nil
+}
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveEpisodeMetadata(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "episode.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("guest: Jane Doe\nepisode: 41\nrecordingDate: 2026-10-01\nlinks:\n  - https://example.com\n"), 0644))
+
+	meta, err := ResolveEpisodeMetadata(map[string]interface{}{"episode": 42}, path)
+	require.NoError(t, err)
+	assert.Equal(t, 42, meta["episode"], "inline metadata wins over the file")
+	assert.Equal(t, "Jane Doe", meta["guest"])
+	assert.Equal(t, "2026-10-01", meta["recordingDate"], "dates are kept as plain strings")
+
+	_, err = ResolveEpisodeMetadata(nil, filepath.Join(dir, "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read metadata file")
+}
+
+func TestEpisodeMetadataPrompt(t *testing.T) {
+	assert.Empty(t, EpisodeMetadataPrompt(nil))
+
+	prompt := EpisodeMetadataPrompt(map[string]interface{}{
+		"sponsor": "Acme",
+		"guest":   "Jane Doe",
+		"episode": 42,
+		"links":   []interface{}{"https://a.example", "https://b.example"},
+	})
+	assert.Contains(t, prompt, "- episode: 42\n- guest: Jane Doe\n- links: https://a.example, https://b.example\n- sponsor: Acme\n")
+}
+
+func TestEpisodeFrontMatter(t *testing.T) {
+	meta := map[string]interface{}{"guest": "Jane Doe", "episode": 42}
+
+	t.Run("round trip", func(t *testing.T) {
+		text, err := WithEpisodeFrontMatter("Hello world.", meta)
+		require.NoError(t, err)
+		assert.Equal(t, "---\nepisode:\n    episode: 42\n    guest: Jane Doe\n---\nHello world.", text)
+
+		parsed, body := SplitEpisodeFrontMatter(text)
+		assert.Equal(t, "Hello world.", body)
+		assert.Equal(t, 42, parsed["episode"])
+		assert.Equal(t, "Jane Doe", parsed["guest"])
+	})
+
+	t.Run("merges into existing front matter", func(t *testing.T) {
+		text, err := WithEpisodeFrontMatter("---\ntitle: Post\n---\n# Post\n", meta)
+		require.NoError(t, err)
+		assert.Equal(t, "---\ntitle: Post\nepisode:\n    episode: 42\n    guest: Jane Doe\n---\n# Post\n", text)
+	})
+
+	t.Run("other front matter is left in the text", func(t *testing.T) {
+		text := "---\ntitle: Post\n---\n# Post\n"
+		parsed, body := SplitEpisodeFrontMatter(text)
+		assert.Nil(t, parsed)
+		assert.Equal(t, text, body)
+	})
+}
+
+func TestWithEpisodeSection(t *testing.T) {
+	meta := map[string]interface{}{"guest": "Jane Doe"}
+
+	content, err := WithEpisodeSection("titles:\n    - One\n", meta)
+	require.NoError(t, err)
+	assert.Equal(t, "episode:\n    guest: Jane Doe\ntitles:\n    - One\n", content)
+
+	content, err = WithEpisodeSection("Not YAML: [", meta)
+	require.NoError(t, err)
+	assert.Equal(t, "Not YAML: [", content)
+}
+
+func TestFormatSeriesTitle(t *testing.T) {
+	meta := map[string]interface{}{"episode": 42, "show": "Hack Talks", "title": "Episode title"}
+
+	assert.Equal(t, "EP42: Zero trust", FormatSeriesTitle("EP{n}: {title}", "Zero trust", meta))
+	assert.Equal(t, "EP42: Zero trust", FormatSeriesTitle("EP{n}: {title}", "EP42: Zero trust", meta), "titles are not numbered twice")
+	assert.Equal(t, "Zero trust | Hack Talks #42", FormatSeriesTitle("{title} | {show} #{n}", " Zero trust ", meta))
+	assert.Equal(t, "Zero trust", FormatSeriesTitle("EP{n}", "Zero trust", meta), "patterns without {title} are ignored")
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// How LinkFile placed a file
+const (
+	LinkedClone    = "clone"    // Copy-on-write clone sharing the source's blocks
+	LinkedHardlink = "hardlink" // Hard link to the source's inode
+	LinkedCopy     = "copy"     // Byte copy
+)
+
+// LinkFile places src at dst without copying its bytes when the filesystem allows it, for outputs
+// that are the unchanged input, such as a reused transcript or an untouched source video. It tries
+// a copy-on-write clone (btrfs, XFS, APFS), then a hard link, and falls back to CopyFile, returning
+// which one it used. A hard link shares its data with src, so dst must only ever be replaced, never
+// written in place; the pipeline's atomic writes rename new files over their destination.
+func LinkFile(src, dst string) (string, error) {
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		return "", fmt.Errorf("failed to open source file: %w", err)
+	}
+	if dstInfo, err := os.Stat(dst); err == nil && os.SameFile(srcInfo, dstInfo) {
+		return LinkedHardlink, nil
+	}
+
+	// Link next to dst first so an existing dst is replaced atomically
+	tmp := filepath.Join(filepath.Dir(dst), fmt.Sprintf(".%s.link-%d", filepath.Base(dst), time.Now().UnixNano()))
+	for _, link := range []struct {
+		method string
+		create func(src, dst string) error
+	}{
+		{LinkedClone, cloneFile},
+		{LinkedHardlink, os.Link},
+	} {
+		if err := link.create(src, tmp); err != nil {
+			_ = os.Remove(tmp)
+			continue
+		}
+		if err := os.Rename(tmp, dst); err != nil {
+			_ = os.Remove(tmp)
+			return "", fmt.Errorf("failed to move %s into place: %w", dst, err)
+		}
+		LogVerbose("Placed %s as a %s of %s", dst, link.method, src)
+		return link.method, nil
+	}
+
+	if err := CopyFile(src, dst); err != nil {
+		return "", err
+	}
+	return LinkedCopy, nil
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+
+package utils
+
+import "errors"
+
+// cloneFile is not supported on this platform, so LinkFile falls back to a hard link or a copy
+func cloneFile(src, dst string) error {
+	return errors.ErrUnsupported
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "source.mp4")
+	dst := filepath.Join(dir, "out", "video.mp4")
+	require.NoError(t, os.WriteFile(src, []byte("video"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Dir(dst), 0755))
+	require.NoError(t, os.WriteFile(dst, []byte("stale"), 0644))
+
+	method, err := LinkFile(src, dst)
+	require.NoError(t, err)
+	assert.Contains(t, []string{LinkedClone, LinkedHardlink, LinkedCopy}, method)
+	data, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, "video", string(data))
+
+	// Placing it again is a no-op once dst is the source's inode
+	method, err = LinkFile(src, dst)
+	require.NoError(t, err)
+	assert.NotEmpty(t, method)
+
+	entries, err := os.ReadDir(filepath.Dir(dst))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary files are left behind")
+
+	_, err = LinkFile(filepath.Join(dir, "missing.mp4"), dst)
+	assert.Error(t, err)
+}
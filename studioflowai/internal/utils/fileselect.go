@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// FileFilter selects the files of a directory input. Patterns are matched against the path relative to
+// the directory, with forward slashes and without regard to case. "*" and "?" stay within one directory
+// and "**" matches any number of directories, so "*.wav" only looks at the top level and "**/*.wav"
+// at every level.
+type FileFilter struct {
+	Include []string // Files to use; a file matching any pattern is included
+	Exclude []string // Files to leave out even when included
+}
+
+// NewFileFilter returns a filter including the given patterns, or defaults when include is empty
+func NewFileFilter(include, exclude, defaults []string) FileFilter {
+	if len(include) == 0 {
+		include = defaults
+	}
+	return FileFilter{Include: include, Exclude: exclude}
+}
+
+// Validate checks the syntax of the filter's patterns
+func (f FileFilter) Validate() error {
+	for _, pattern := range append(append([]string{}, f.Include...), f.Exclude...) {
+		if pattern == "" {
+			return fmt.Errorf("file patterns must not be empty")
+		}
+		for _, part := range strings.Split(filepath.ToSlash(pattern), "/") {
+			if _, err := path.Match(part, ""); err != nil {
+				return fmt.Errorf("invalid file pattern %q: %w", pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Match reports whether a slash-separated path relative to the input directory is selected
+func (f FileFilter) Match(rel string) bool {
+	return matchAny(f.Include, rel) && !matchAny(f.Exclude, rel)
+}
+
+// Files returns the regular files of dir selected by the filter, in name order
+func (f FileFilter) Files(dir string) ([]string, error) {
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Subdirectories are only read when an include pattern can reach into them
+	recursive := false
+	for _, pattern := range f.Include {
+		recursive = recursive || strings.Contains(filepath.ToSlash(pattern), "/")
+	}
+
+	var files []string
+	err := filepath.WalkDir(dir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if p != dir && !recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		if f.Match(filepath.ToSlash(rel)) {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// String describes the filter in messages
+func (f FileFilter) String() string {
+	s := strings.Join(f.Include, ", ")
+	if len(f.Exclude) > 0 {
+		s += " excluding " + strings.Join(f.Exclude, ", ")
+	}
+	return s
+}
+
+// matchAny reports whether rel matches one of the patterns
+func matchAny(patterns []string, rel string) bool {
+	parts := strings.Split(strings.ToLower(rel), "/")
+	for _, pattern := range patterns {
+		if matchParts(strings.Split(strings.ToLower(filepath.ToSlash(pattern)), "/"), parts) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchParts matches path segments against pattern segments, where "**" matches zero or more segments
+func matchParts(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchParts(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+		return false
+	}
+	return matchParts(pattern[1:], parts[1:])
+}
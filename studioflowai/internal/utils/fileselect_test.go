@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileFilterMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  FileFilter
+		rel     string
+		matches bool
+	}{
+		{"top level glob", FileFilter{Include: []string{"*.wav"}}, "a.wav", true},
+		{"top level glob skips subdirectories", FileFilter{Include: []string{"*.wav"}}, "day1/a.wav", false},
+		{"case is ignored", FileFilter{Include: []string{"*.wav"}}, "A.WAV", true},
+		{"double star at top level", FileFilter{Include: []string{"**/*.wav"}}, "a.wav", true},
+		{"double star nested", FileFilter{Include: []string{"**/*.wav"}}, "day1/mic/a.wav", true},
+		{"double star in the middle", FileFilter{Include: []string{"day1/**/a.wav"}}, "day1/x/y/a.wav", true},
+		{"any include", FileFilter{Include: []string{"*.mp3", "*.wav"}}, "a.wav", true},
+		{"excluded", FileFilter{Include: []string{"**/*.wav"}, Exclude: []string{"**/*_draft.wav"}}, "day1/a_draft.wav", false},
+		{"exclude is relative too", FileFilter{Include: []string{"**/*.wav"}, Exclude: []string{"*_draft.wav"}}, "day1/a_draft.wav", true},
+		{"no include", FileFilter{}, "a.wav", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.matches, tt.filter.Match(tt.rel))
+		})
+	}
+}
+
+func TestFileFilterFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.wav", "a.WAV", "notes.txt", "day1/c.wav", "day1/c_draft.wav", "day1/mic/d.wav"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte("x"), 0644))
+	}
+	rel := func(files []string) []string {
+		var names []string
+		for _, file := range files {
+			name, err := filepath.Rel(dir, file)
+			require.NoError(t, err)
+			names = append(names, filepath.ToSlash(name))
+		}
+		return names
+	}
+
+	files, err := FileFilter{Include: []string{"*.wav"}}.Files(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.WAV", "b.wav"}, rel(files))
+
+	files, err = FileFilter{Include: []string{"**/*.wav"}, Exclude: []string{"**/*_draft.wav", "day1/mic/*"}}.Files(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.WAV", "b.wav", "day1/c.wav"}, rel(files))
+
+	_, err = FileFilter{Include: []string{"[*.wav"}}.Files(dir)
+	assert.ErrorContains(t, err, "invalid file pattern")
+}
+
+func TestNewFileFilter(t *testing.T) {
+	assert.Equal(t, []string{"*.wav"}, NewFileFilter(nil, nil, []string{"*.wav"}).Include)
+	assert.Equal(t, []string{"**/*.mp3"}, NewFileFilter([]string{"**/*.mp3"}, nil, []string{"*.wav"}).Include)
+	assert.Equal(t, "*.wav excluding *_draft.wav", NewFileFilter(nil, []string{"*_draft.wav"}, []string{"*.wav"}).String())
+}
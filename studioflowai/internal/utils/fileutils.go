@@ -2,6 +2,8 @@ package utils
 
 import (
 	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
@@ -67,11 +69,104 @@ func ReadTextFile(filePath string) (string, error) {
 	return strings.Join(lines, "\n"), nil
 }
 
-// WriteTextFile writes text to a file, ensuring it's written as text
+// WriteTextFile writes text to a file, ensuring it's written as text.
+// The content is written atomically so readers never observe a partial file.
 func WriteTextFile(filePath string, content string) error {
-	f, err := os.Create(filePath)
+	if err := AtomicWriteFile(filePath, []byte(content), 0644); err != nil {
+		return err
+	}
+
+	LogDebug("Successfully wrote content to %s", filePath)
+	return nil
+}
+
+// AtomicFile is a temporary file that only replaces its destination once Commit is called.
+// Closing an uncommitted AtomicFile discards the temporary file.
+type AtomicFile struct {
+	*os.File
+	path      string
+	perm      os.FileMode
+	committed bool
+	closed    bool
+}
+
+// CreateAtomicFile creates a temporary file next to filePath that will be renamed
+// into place by Commit
+func CreateAtomicFile(filePath string, perm os.FileMode) (*AtomicFile, error) {
+	dir := filepath.Dir(filePath)
+	f, err := os.CreateTemp(dir, "."+filepath.Base(filePath)+".tmp-*")
 	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
+		return nil, fmt.Errorf("failed to create file: %w", err)
+	}
+
+	return &AtomicFile{
+		File: f,
+		path: filePath,
+		perm: perm,
+	}, nil
+}
+
+// Commit flushes the temporary file to disk and atomically renames it to its destination
+func (f *AtomicFile) Commit() error {
+	if f.committed {
+		return nil
+	}
+	if f.closed {
+		return fmt.Errorf("cannot commit closed file %s", f.path)
+	}
+
+	tmpPath := f.Name()
+	if err := f.File.Sync(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to sync file: %w", err)
+	}
+	if err := f.File.Chmod(outputFileMode(f.perm)); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to set file permissions: %w", err)
+	}
+	if err := setOutputGroup(f.File); err != nil {
+		_ = f.Close()
+		return err
+	}
+	f.closed = true
+	if err := f.File.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to close file: %w", err)
+	}
+	if err := os.Rename(tmpPath, f.path); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to move file into place: %w", err)
+	}
+
+	f.committed = true
+	return nil
+}
+
+// Close releases the file. If the file was not committed, the temporary file is removed.
+func (f *AtomicFile) Close() error {
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+
+	err := f.File.Close()
+	if !f.committed {
+		if rmErr := os.Remove(f.Name()); rmErr != nil && !os.IsNotExist(rmErr) {
+			LogWarning("Failed to remove temporary file %s: %v", f.Name(), rmErr)
+		}
+	}
+	return err
+}
+
+// AtomicWriteFile writes data to a temporary file and renames it over filePath,
+// so an interrupted write never leaves a half-written file behind
+func AtomicWriteFile(filePath string, data []byte, perm os.FileMode) error {
+	if err := injectDiskFull(filePath); err != nil {
+		return err
+	}
+	f, err := CreateAtomicFile(filePath, perm)
+	if err != nil {
+		return err
 	}
 	defer func() {
 		if err := f.Close(); err != nil {
@@ -79,17 +174,31 @@ func WriteTextFile(filePath string, content string) error {
 		}
 	}()
 
-	writer := bufio.NewWriter(f)
-	if _, err := writer.WriteString(content); err != nil {
+	if _, err := f.Write(data); err != nil {
 		return fmt.Errorf("failed to write to file: %w", err)
 	}
 
-	if err := writer.Flush(); err != nil {
-		return fmt.Errorf("failed to flush writer: %w", err)
+	return f.Commit()
+}
+
+// FileChecksum returns the hex-encoded SHA-256 checksum of a file
+func FileChecksum(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			LogWarning("Failed to close file: %v", err)
+		}
+	}()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", fmt.Errorf("failed to hash file: %w", err)
 	}
 
-	LogDebug("Successfully wrote content to %s", filePath)
-	return nil
+	return hex.EncodeToString(hash.Sum(nil)), nil
 }
 
 // ExpandHomeDir expands a path if it starts with "~/"
@@ -104,7 +213,8 @@ func ExpandHomeDir(path string) (string, error) {
 	return path, nil
 }
 
-// CopyFile copies a file from src to dst
+// CopyFile copies a file from src to dst.
+// The copy is written atomically and verified against the source checksum.
 func CopyFile(src, dst string) error {
 	sourceFile, err := os.Open(src)
 	if err != nil {
@@ -116,7 +226,7 @@ func CopyFile(src, dst string) error {
 		}
 	}()
 
-	destFile, err := os.Create(dst)
+	destFile, err := CreateAtomicFile(dst, 0644)
 	if err != nil {
 		return fmt.Errorf("failed to create destination file: %w", err)
 	}
@@ -126,10 +236,27 @@ func CopyFile(src, dst string) error {
 		}
 	}()
 
-	if _, err := io.Copy(destFile, sourceFile); err != nil {
+	// Hash the source while copying so the destination can be verified afterwards
+	srcHash := sha256.New()
+	if _, err := io.Copy(destFile, io.TeeReader(sourceFile, srcHash)); err != nil {
 		return fmt.Errorf("failed to copy file contents: %w", err)
 	}
 
+	if err := destFile.Commit(); err != nil {
+		return err
+	}
+
+	dstChecksum, err := FileChecksum(dst)
+	if err != nil {
+		return fmt.Errorf("failed to verify copied file: %w", err)
+	}
+	if srcChecksum := hex.EncodeToString(srcHash.Sum(nil)); srcChecksum != dstChecksum {
+		if err := os.Remove(dst); err != nil {
+			LogWarning("Failed to remove corrupted copy %s: %v", dst, err)
+		}
+		return fmt.Errorf("checksum mismatch copying %s to %s", src, dst)
+	}
+
 	return nil
 }
 
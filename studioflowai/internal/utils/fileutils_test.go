@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tempFiles lists the temporary files AtomicFile left in dir
+func tempFiles(t *testing.T, dir string) []string {
+	matches, err := filepath.Glob(filepath.Join(dir, ".*.tmp-*"))
+	require.NoError(t, err)
+	return matches
+}
+
+func TestAtomicFile_Commit(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "transcript.srt")
+	require.NoError(t, os.WriteFile(path, []byte("old"), 0644))
+
+	f, err := CreateAtomicFile(path, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString("new")
+	require.NoError(t, err)
+
+	// Readers see the old file until the commit
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(data))
+
+	require.NoError(t, f.Commit())
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(data), "the commit replaces the destination")
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+
+	require.NoError(t, f.Commit(), "committing twice is a no-op")
+	require.NoError(t, f.Close(), "closing a committed file keeps it")
+	assert.FileExists(t, path)
+	assert.Empty(t, tempFiles(t, dir))
+}
+
+func TestAtomicFile_CloseWithoutCommit(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "transcript.srt")
+	require.NoError(t, os.WriteFile(path, []byte("old"), 0644))
+
+	f, err := CreateAtomicFile(path, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString("partial")
+	require.NoError(t, err)
+	assert.FileExists(t, f.Name())
+
+	require.NoError(t, f.Close())
+	assert.NoFileExists(t, f.Name(), "an uncommitted file is discarded")
+	assert.Empty(t, tempFiles(t, dir))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(data), "the destination is left as it was")
+
+	require.NoError(t, f.Close(), "closing twice is a no-op")
+	assert.ErrorContains(t, f.Commit(), "cannot commit closed file")
+}
+
+func TestAtomicWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "token.json")
+
+	require.NoError(t, AtomicWriteFile(path, []byte("{}"), 0600))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "{}", string(data))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	assert.Error(t, AtomicWriteFile(filepath.Join(dir, "missing", "token.json"), []byte("{}"), 0600))
+	assert.Empty(t, tempFiles(t, dir))
+}
@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// filtergraphPlaceholder matches a {name} placeholder of a filtergraph template
+var filtergraphPlaceholder = regexp.MustCompile(`\{([A-Za-z]+)\}`)
+
+// EscapeFilterText escapes text for a single-quoted option value inside an FFmpeg filtergraph,
+// such as the text of drawtext or the file name of subtitles
+func EscapeFilterText(text string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`, `:`, `\:`, `%`, `\%`).Replace(text)
+}
+
+// RenderFiltergraph fills in the {name} placeholders of a user filtergraph template. Values are
+// escaped with EscapeFilterText, so templates quote them: drawtext=text='{title}'. Placeholders
+// written as %{name} are drawtext expansions and are left alone. An unknown placeholder, or one
+// whose value is empty, is an error naming the placeholders that are available.
+func RenderFiltergraph(template string, vars map[string]string) (string, error) {
+	var missing string
+	var b strings.Builder
+	last := 0
+	for _, loc := range filtergraphPlaceholder.FindAllStringSubmatchIndex(template, -1) {
+		if loc[0] > 0 && template[loc[0]-1] == '%' {
+			continue
+		}
+		name := template[loc[2]:loc[3]]
+		value, ok := vars[name]
+		if !ok || value == "" {
+			if missing == "" {
+				missing = name
+			}
+			continue
+		}
+		b.WriteString(template[last:loc[0]])
+		b.WriteString(EscapeFilterText(value))
+		last = loc[1]
+	}
+	b.WriteString(template[last:])
+	rendered := b.String()
+
+	if missing != "" {
+		available := make([]string, 0, len(vars))
+		for name, value := range vars {
+			if value != "" {
+				available = append(available, "{"+name+"}")
+			}
+		}
+		sort.Strings(available)
+		return "", fmt.Errorf("filtergraph placeholder {%s} is not available here; use one of %s", missing, strings.Join(available, ", "))
+	}
+	if strings.TrimSpace(rendered) == "" {
+		return "", fmt.Errorf("filtergraph template is empty")
+	}
+	return rendered, nil
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderFiltergraph(t *testing.T) {
+	vars := map[string]string{"title": "50% off: today", "input": "clip.mp4", "subtitles": ""}
+
+	tests := []struct {
+		name     string
+		template string
+		expected string
+		wantErr  string
+	}{
+		{
+			name:     "escapes values",
+			template: "drawtext=text='{title}':x=10",
+			expected: `drawtext=text='50\% off\: today':x=10`,
+		},
+		{
+			name:     "keeps drawtext expansions",
+			template: "drawtext=text='%{pts} {input}'",
+			expected: "drawtext=text='%{pts} clip.mp4'",
+		},
+		{
+			name:     "unknown placeholder",
+			template: "drawtext=text='{guest}'",
+			wantErr:  "{guest} is not available here; use one of {input}, {title}",
+		},
+		{
+			name:     "empty value",
+			template: "subtitles='{subtitles}'",
+			wantErr:  "{subtitles} is not available",
+		},
+		{
+			name:     "empty template",
+			template: "  ",
+			wantErr:  "filtergraph template is empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderFiltergraph(tt.template, vars)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// Environment variables that override the standard HTTP_PROXY/HTTPS_PROXY/NO_PROXY settings
+// for outbound API calls (OpenAI, YouTube, TikTok)
+const (
+	HTTPProxyEnvVar  = "STUDIOFLOWAI_HTTP_PROXY"
+	HTTPSProxyEnvVar = "STUDIOFLOWAI_HTTPS_PROXY"
+	NoProxyEnvVar    = "STUDIOFLOWAI_NO_PROXY"
+)
+
+// ProxyConfig returns the proxy configuration used for outbound API calls.
+// Values from the StudioFlowAI-specific variables take precedence over the standard environment.
+func ProxyConfig() *httpproxy.Config {
+	cfg := httpproxy.FromEnvironment()
+
+	if v := os.Getenv(HTTPProxyEnvVar); v != "" {
+		cfg.HTTPProxy = v
+	}
+	if v := os.Getenv(HTTPSProxyEnvVar); v != "" {
+		cfg.HTTPSProxy = v
+	} else if v := os.Getenv(HTTPProxyEnvVar); v != "" {
+		// A single configured proxy is usually meant for all traffic
+		cfg.HTTPSProxy = v
+	}
+	if v := os.Getenv(NoProxyEnvVar); v != "" {
+		cfg.NoProxy = v
+	}
+
+	return cfg
+}
+
+// NewHTTPClient creates an HTTP client that honors the configured proxy settings
+func NewHTTPClient() *http.Client {
+	proxyFunc := ProxyConfig().ProxyFunc()
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}
+
+	// Injected API timeouts exercise the retry paths of the services in tests
+	if chaosEnabled(ChaosAPI) {
+		return &http.Client{Transport: chaosTransport{next: transport}}
+	}
+	return &http.Client{Transport: transport}
+}
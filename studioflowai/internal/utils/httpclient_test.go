@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clearProxyEnv unsets every proxy variable for the test
+func clearProxyEnv(t *testing.T) {
+	for _, name := range []string{
+		"HTTP_PROXY", "http_proxy", "HTTPS_PROXY", "https_proxy", "NO_PROXY", "no_proxy", "REQUEST_METHOD",
+		HTTPProxyEnvVar, HTTPSProxyEnvVar, NoProxyEnvVar,
+	} {
+		t.Setenv(name, "")
+	}
+}
+
+func TestProxyConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		env       map[string]string
+		wantHTTP  string
+		wantHTTPS string
+		wantNo    string
+	}{
+		{
+			name:      "standard environment",
+			env:       map[string]string{"HTTP_PROXY": "http://corp:3128", "HTTPS_PROXY": "http://corp:3129", "NO_PROXY": "localhost"},
+			wantHTTP:  "http://corp:3128",
+			wantHTTPS: "http://corp:3129",
+			wantNo:    "localhost",
+		},
+		{
+			name: "own variables take precedence",
+			env: map[string]string{
+				"HTTP_PROXY": "http://corp:3128", "HTTPS_PROXY": "http://corp:3129", "NO_PROXY": "localhost",
+				HTTPProxyEnvVar: "http://studio:8080", HTTPSProxyEnvVar: "http://studio:8443", NoProxyEnvVar: "nas.local",
+			},
+			wantHTTP:  "http://studio:8080",
+			wantHTTPS: "http://studio:8443",
+			wantNo:    "nas.local",
+		},
+		{
+			name:      "HTTPS falls back to the own HTTP proxy",
+			env:       map[string]string{"HTTPS_PROXY": "http://corp:3129", HTTPProxyEnvVar: "http://studio:8080"},
+			wantHTTP:  "http://studio:8080",
+			wantHTTPS: "http://studio:8080",
+		},
+		{
+			name:      "HTTPS keeps the standard proxy without own variables",
+			env:       map[string]string{"HTTPS_PROXY": "http://corp:3129"},
+			wantHTTPS: "http://corp:3129",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearProxyEnv(t)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			cfg := ProxyConfig()
+			assert.Equal(t, tt.wantHTTP, cfg.HTTPProxy)
+			assert.Equal(t, tt.wantHTTPS, cfg.HTTPSProxy)
+			assert.Equal(t, tt.wantNo, cfg.NoProxy)
+		})
+	}
+}
+
+func TestProxyConfig_ProxyFunc(t *testing.T) {
+	clearProxyEnv(t)
+	t.Setenv(HTTPProxyEnvVar, "http://studio:8080")
+	t.Setenv(NoProxyEnvVar, "nas.local")
+	proxy := ProxyConfig().ProxyFunc()
+
+	for _, target := range []string{"http://api.example.test/v1", "https://api.openai.com/v1/chat/completions"} {
+		u, err := url.Parse(target)
+		require.NoError(t, err)
+		got, err := proxy(u)
+		require.NoError(t, err)
+		require.NotNil(t, got, target)
+		assert.Equal(t, "studio:8080", got.Host, target)
+	}
+
+	u, err := url.Parse("https://nas.local/upload")
+	require.NoError(t, err)
+	got, err := proxy(u)
+	require.NoError(t, err)
+	assert.Nil(t, got, "NO_PROXY hosts are reached directly")
+}
+
+func TestNewHTTPClient_UsesProxy(t *testing.T) {
+	var gotHost, gotPath string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A forward proxy receives the absolute URL of the target
+		gotHost = r.URL.Host
+		gotPath = r.URL.Path
+		_, _ = io.WriteString(w, "via proxy")
+	}))
+	defer proxy.Close()
+
+	clearProxyEnv(t)
+	t.Setenv(HTTPProxyEnvVar, proxy.URL)
+
+	resp, err := NewHTTPClient().Get("http://api.example.test/v1/models")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, "via proxy", string(body))
+	assert.Equal(t, "api.example.test", gotHost)
+	assert.Equal(t, "/v1/models", gotPath)
+}
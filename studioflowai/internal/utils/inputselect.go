@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Strategies for choosing one input when several files of a directory match a pattern.
+// Any other selection value is the name of the file to use.
+const (
+	SelectNewest       = "newest"       // Most recently modified file
+	SelectLargest      = "largest"      // Largest file
+	SelectAlphabetical = "alphabetical" // First file in name order
+)
+
+// ValidateInputSelection checks an input selection: a strategy, or a file name inside the input directory
+func ValidateInputSelection(selection string) error {
+	switch selection {
+	case "", SelectNewest, SelectLargest, SelectAlphabetical:
+		return nil
+	}
+	if filepath.IsAbs(selection) || strings.Contains(filepath.ToSlash(selection), "..") {
+		return fmt.Errorf("inputSelection must be newest, largest, alphabetical or a file name inside the input directory, got %q", selection)
+	}
+	return nil
+}
+
+// SelectInputFile returns inputPath when it is a file. For a directory, it returns the file selected by
+// filter chosen by selection (default: newest, or alphabetical in deterministic mode), or the named
+// file when selection is a file name.
+func SelectInputFile(inputPath string, filter FileFilter, selection string) (string, error) {
+	info, err := os.Stat(inputPath)
+	if err != nil {
+		return "", fmt.Errorf("input path does not exist: %w", err)
+	}
+	if !info.IsDir() {
+		return inputPath, nil
+	}
+	if err := ValidateInputSelection(selection); err != nil {
+		return "", err
+	}
+	if selection == "" {
+		selection = SelectNewest
+		// Modification times differ between checkouts
+		if Deterministic() {
+			selection = SelectAlphabetical
+		}
+	}
+
+	switch selection {
+	case SelectNewest, SelectLargest, SelectAlphabetical:
+	default:
+		named := filepath.Join(inputPath, selection)
+		if info, err := os.Stat(named); err != nil || info.IsDir() {
+			return "", fmt.Errorf("input file %s not found in %s", selection, inputPath)
+		}
+		return named, nil
+	}
+
+	matches, err := filter.Files(inputPath)
+	if err != nil {
+		return "", err
+	}
+	type candidate struct {
+		path string
+		info os.FileInfo
+	}
+	var candidates []candidate
+	for _, match := range matches {
+		if info, err := os.Stat(match); err == nil && info.Mode().IsRegular() {
+			candidates = append(candidates, candidate{match, info})
+		}
+	}
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("no files matching %s found in %s", filter, inputPath)
+	}
+
+	// Files are returned in name order, so ties are broken alphabetically
+	sort.SliceStable(candidates, func(i, j int) bool {
+		switch selection {
+		case SelectNewest:
+			return candidates[i].info.ModTime().After(candidates[j].info.ModTime())
+		case SelectLargest:
+			return candidates[i].info.Size() > candidates[j].info.Size()
+		default:
+			return false
+		}
+	})
+
+	chosen := candidates[0].path
+	if len(candidates) > 1 {
+		LogWarning("%d files match %s, using %s (%s; set inputSelection to choose another)",
+			len(candidates), filter, filepath.Base(chosen), selection)
+	}
+	return chosen, nil
+}
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectInputFile(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	files := []struct {
+		name    string
+		size    int
+		modTime time.Time
+	}{
+		{"a_corrected.txt", 10, now.Add(-2 * time.Hour)},
+		{"b_corrected.txt", 300, now.Add(-3 * time.Hour)},
+		{"c_corrected.txt", 20, now.Add(-time.Hour)},
+		{"notes.txt", 5000, now},
+	}
+	for _, f := range files {
+		path := filepath.Join(dir, f.name)
+		require.NoError(t, os.WriteFile(path, make([]byte, f.size), 0644))
+		require.NoError(t, os.Chtimes(path, f.modTime, f.modTime))
+	}
+
+	tests := []struct {
+		selection string
+		want      string
+		wantErr   string
+	}{
+		{"", "c_corrected.txt", ""},
+		{SelectNewest, "c_corrected.txt", ""},
+		{SelectLargest, "b_corrected.txt", ""},
+		{SelectAlphabetical, "a_corrected.txt", ""},
+		{"notes.txt", "notes.txt", ""},
+		{"missing.txt", "", "not found"},
+		{"../escape.txt", "", "inside the input directory"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selection, func(t *testing.T) {
+			got, err := SelectInputFile(dir, FileFilter{Include: []string{"*_corrected.txt"}}, tt.selection)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join(dir, tt.want), got)
+		})
+	}
+
+	file := filepath.Join(dir, "notes.txt")
+	got, err := SelectInputFile(file, FileFilter{Include: []string{"*_corrected.txt"}}, SelectLargest)
+	require.NoError(t, err)
+	assert.Equal(t, file, got, "a file input is used as is")
+
+	SetDeterministic(true)
+	t.Cleanup(func() { SetDeterministic(false) })
+	got, err = SelectInputFile(dir, FileFilter{Include: []string{"*_corrected.txt"}}, "")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "a_corrected.txt"), got, "deterministic runs ignore modification times")
+
+	_, err = SelectInputFile(dir, FileFilter{Include: []string{"*.srt"}}, "")
+	assert.ErrorContains(t, err, "no files matching *.srt")
+}
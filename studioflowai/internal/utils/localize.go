@@ -0,0 +1,204 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// localeLanguages maps ISO 639-1 codes to the language names used by the SNS output
+var localeLanguages = map[string]string{
+	"de": "German",
+	"en": "English",
+	"es": "Spanish",
+	"fr": "French",
+	"it": "Italian",
+	"ja": "Japanese",
+	"ko": "Korean",
+	"nl": "Dutch",
+	"pt": "Portuguese",
+	"zh": "Chinese",
+}
+
+// LocaleLanguage returns the language of a locale such as "es", "en-US" or "English"
+func LocaleLanguage(locale string) string {
+	locale = strings.TrimSpace(locale)
+	code, region, _ := strings.Cut(strings.ReplaceAll(locale, "_", "-"), "-")
+	name, ok := localeLanguages[strings.ToLower(code)]
+	if !ok {
+		return locale
+	}
+	if name == "Portuguese" && strings.EqualFold(region, "BR") {
+		return "Brazilian Portuguese"
+	}
+	return name
+}
+
+// SameLanguage reports whether two locales or language names are the same language
+func SameLanguage(a, b string) bool {
+	return strings.EqualFold(LocaleLanguage(a), LocaleLanguage(b))
+}
+
+// languageSlug turns a language name into a file name suffix, e.g. "Brazilian Portuguese" -> "brazilian_portuguese"
+func languageSlug(language string) string {
+	return strings.ToLower(strings.Join(strings.Fields(language), "_"))
+}
+
+// SNSCopy returns the SNS content in a language: its section of a multilingual file, or the file
+// written for that language next to it with splitLanguages (sns_content_english.yaml). It returns
+// "" when the content is not available in the language.
+func SNSCopy(path, language string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read SNS content: %w", err)
+	}
+
+	var sections map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &sections); err == nil {
+		for name, section := range sections {
+			if name != "episode" && SameLanguage(name, language) {
+				out, err := yaml.Marshal(&section)
+				if err != nil {
+					return "", fmt.Errorf("failed to read SNS content in %s: %w", name, err)
+				}
+				return string(out), nil
+			}
+		}
+	}
+
+	// Files split per language share a prefix, and the step's output is the first language's file
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	slug := languageSlug(LocaleLanguage(language))
+	candidates := []string{base + "_" + slug + ".yaml"}
+	if i := strings.LastIndex(filepath.Base(base), "_"); i > 0 {
+		candidates = append(candidates, filepath.Join(filepath.Dir(base), filepath.Base(base)[:i]+"_"+slug+".yaml"))
+	}
+	for _, candidate := range candidates {
+		if data, err := os.ReadFile(candidate); err == nil {
+			return string(data), nil
+		}
+	}
+	return "", nil
+}
+
+// ShortsLocale says which language the copy of uploaded shorts must be in
+type ShortsLocale struct {
+	Locale         string // Language of the account, such as "es" or "English"; empty keeps the copy as it is
+	ShortsLanguage string // Language the shorts file is written in, if known
+	SNSContent     string // SNS output whose content in the account's language guides the localization
+	OutputDir      string // Where the localized copy is kept
+}
+
+// LocalizeForAccount returns the shorts with their copy in the account's language. Shorts already
+// in that language are returned as they are; see LocalizeShorts for the rest.
+func LocalizeForAccount(ctx context.Context, shorts []ShortClip, l ShortsLocale, complete func(context.Context, string) (string, error)) ([]ShortClip, error) {
+	if l.Locale == "" || len(shorts) == 0 || (l.ShortsLanguage != "" && SameLanguage(l.Locale, l.ShortsLanguage)) {
+		return shorts, nil
+	}
+	language := LocaleLanguage(l.Locale)
+
+	reference := ""
+	if l.SNSContent != "" {
+		var err error
+		if reference, err = SNSCopy(ResolveOutputPath(l.SNSContent, l.OutputDir), language); err != nil {
+			return nil, err
+		}
+		if reference == "" {
+			LogWarningContext(ctx, "The SNS content has no %s version; localizing the shorts without it", language)
+		}
+	}
+	return LocalizeShorts(ctx, shorts, language, reference, LocalizedShortsFile(l.OutputDir, language), complete)
+}
+
+// LocalizedShortsFile returns where the shorts localized into a language are kept
+func LocalizedShortsFile(outputDir, language string) string {
+	return filepath.Join(outputDir, "shorts_"+languageSlug(LocaleLanguage(language))+".yaml")
+}
+
+// LocalizeShorts returns the shorts with their titles, descriptions and tags in language. Copy
+// saved to cachePath by an earlier run is reused, so re-running a deferred upload sends the same
+// text; otherwise complete generates it, with the SNS content in that language as a reference for
+// wording and hashtags, and the result is saved to cachePath for review.
+func LocalizeShorts(ctx context.Context, shorts []ShortClip, language, reference, cachePath string, complete func(context.Context, string) (string, error)) ([]ShortClip, error) {
+	if cached, err := ReadShortsFile(cachePath); err == nil {
+		if localized, ok := matchLocalized(shorts, cached.Shorts); ok {
+			LogVerboseContext(ctx, "Using the %s copy of the shorts in %s", language, cachePath)
+			return localized, nil
+		}
+	}
+
+	source, err := yaml.Marshal(ShortsData{Shorts: shorts})
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare shorts for localization: %w", err)
+	}
+	LogInfoContext(ctx, "Localizing the copy of %d shorts into %s", len(shorts), language)
+	response, err := complete(ctx, localizeShortsPrompt(string(source), language, reference))
+	if err != nil {
+		return nil, fmt.Errorf("failed to localize shorts into %s: %w", language, err)
+	}
+
+	var generated ShortsData
+	if err := yaml.Unmarshal([]byte(stripCodeFence(response)), &generated); err != nil {
+		return nil, fmt.Errorf("failed to parse shorts localized into %s: %w", language, err)
+	}
+	localized, ok := matchLocalized(shorts, generated.Shorts)
+	if !ok {
+		return nil, fmt.Errorf("shorts localized into %s do not match the clips of the shorts file", language)
+	}
+
+	data, err := yaml.Marshal(ShortsData{Shorts: localized})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save localized shorts: %w", err)
+	}
+	if err := AtomicWriteFile(cachePath, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to save localized shorts: %w", err)
+	}
+	return localized, nil
+}
+
+// matchLocalized takes the text of every short from its localized copy, matched by clip times
+func matchLocalized(shorts, localized []ShortClip) ([]ShortClip, bool) {
+	byTimes := make(map[string]ShortClip, len(localized))
+	for _, short := range localized {
+		byTimes[short.StartTime+"-"+short.EndTime] = short
+	}
+	result := make([]ShortClip, len(shorts))
+	for i, short := range shorts {
+		text, ok := byTimes[short.StartTime+"-"+short.EndTime]
+		if !ok || text.ShortTitle == "" {
+			return nil, false
+		}
+		short.Title, short.ShortTitle, short.Description, short.Tags = text.Title, text.ShortTitle, text.Description, text.Tags
+		result[i] = short
+	}
+	return result, true
+}
+
+// localizeShortsPrompt asks for the copy of the shorts in another language
+func localizeShortsPrompt(shorts, language, reference string) string {
+	prompt := fmt.Sprintf(`Adapta al %s los títulos (title, shortTitle), descripciones y tags de estos shorts para una audiencia nativa.
+Traduce con naturalidad y usa hashtags y keywords que se busquen en ese idioma; si un texto ya está en %s, déjalo igual.
+Conserva startTime y endTime sin cambios y responde solo con el YAML, con la misma estructura.
+
+%s`, language, language, shorts)
+	if reference != "" {
+		prompt += fmt.Sprintf("\nUsa como referencia de tono, términos y hashtags el contenido del episodio en %s:\n\n%s", language, reference)
+	}
+	return prompt
+}
+
+// stripCodeFence removes a surrounding Markdown code fence from a model response
+func stripCodeFence(content string) string {
+	trimmed := strings.TrimSpace(content)
+	if !strings.HasPrefix(trimmed, "```") {
+		return trimmed
+	}
+	if newline := strings.Index(trimmed, "\n"); newline >= 0 {
+		trimmed = trimmed[newline+1:]
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(trimmed), "```"))
+}
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocaleLanguage(t *testing.T) {
+	assert.Equal(t, "Spanish", LocaleLanguage("es"))
+	assert.Equal(t, "English", LocaleLanguage("en-US"))
+	assert.Equal(t, "Brazilian Portuguese", LocaleLanguage("pt_BR"))
+	assert.Equal(t, "Japanese", LocaleLanguage("Japanese"))
+	assert.True(t, SameLanguage("es-MX", "Spanish"))
+	assert.False(t, SameLanguage("en", "es"))
+}
+
+func TestSNSCopy(t *testing.T) {
+	dir := t.TempDir()
+
+	multilingual := filepath.Join(dir, "sns_content.yaml")
+	require.NoError(t, os.WriteFile(multilingual, []byte("episode: 12\nSpanish:\n  title: Hola\nEnglish:\n  title: Hello\n"), 0644))
+	text, err := SNSCopy(multilingual, "en")
+	require.NoError(t, err)
+	assert.Equal(t, "title: Hello\n", text)
+
+	// Split per language, with the step's output being the first language's file
+	split := filepath.Join(dir, "split_spanish.yaml")
+	require.NoError(t, os.WriteFile(split, []byte("title: Hola\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "split_english.yaml"), []byte("title: Hello\n"), 0644))
+	text, err = SNSCopy(split, "English")
+	require.NoError(t, err)
+	assert.Equal(t, "title: Hello\n", text)
+
+	text, err = SNSCopy(multilingual, "French")
+	require.NoError(t, err)
+	assert.Empty(t, text)
+
+	_, err = SNSCopy(filepath.Join(dir, "missing.yaml"), "en")
+	assert.Error(t, err)
+}
+
+func TestLocalizeShorts(t *testing.T) {
+	shorts := []ShortClip{
+		{Title: "Hola", ShortTitle: "Hola", StartTime: "00:00:10", EndTime: "00:00:40", Storyboard: "board.jpg"},
+		{Title: "Adiós", ShortTitle: "Adiós", StartTime: "00:01:00", EndTime: "00:01:30"},
+	}
+	response := "```yaml\nshorts:\n" +
+		"  - title: Goodbye\n    shortTitle: Goodbye\n    startTime: \"00:01:00\"\n    endTime: \"00:01:30\"\n    tags: \"#bye\"\n" +
+		"  - title: Hello\n    shortTitle: Hello\n    startTime: \"00:00:10\"\n    endTime: \"00:00:40\"\n    tags: \"#hello\"\n```"
+
+	calls := 0
+	var prompt string
+	complete := func(_ context.Context, p string) (string, error) {
+		calls++
+		prompt = p
+		return response, nil
+	}
+
+	cache := filepath.Join(t.TempDir(), "shorts_english.yaml")
+	localized, err := LocalizeShorts(context.Background(), shorts, "English", "title: Hello", cache, complete)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", localized[0].Title)
+	assert.Equal(t, "#hello", localized[0].Tags)
+	assert.Equal(t, "board.jpg", localized[0].Storyboard)
+	assert.Equal(t, "Goodbye", localized[1].ShortTitle)
+	assert.Contains(t, prompt, "title: Hello")
+	assert.FileExists(t, cache)
+
+	// The saved copy is reused on the next run
+	localized, err = LocalizeShorts(context.Background(), shorts, "English", "", cache, complete)
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "Hello", localized[0].Title)
+
+	// A response missing a clip is rejected
+	response = "shorts:\n  - title: Hello\n    shortTitle: Hello\n    startTime: \"00:00:10\"\n    endTime: \"00:00:40\"\n"
+	_, err = LocalizeShorts(context.Background(), shorts, "French", "", filepath.Join(t.TempDir(), "shorts_french.yaml"), complete)
+	assert.ErrorContains(t, err, "do not match")
+}
+
+func TestLocalizeForAccount(t *testing.T) {
+	shorts := []ShortClip{{Title: "Hola", ShortTitle: "Hola", StartTime: "00:00:10", EndTime: "00:00:40"}}
+	complete := func(context.Context, string) (string, error) {
+		t.Fatal("shorts in the account's language must not be localized")
+		return "", nil
+	}
+
+	result, err := LocalizeForAccount(context.Background(), shorts, ShortsLocale{Locale: "es-MX", ShortsLanguage: "Spanish"}, complete)
+	require.NoError(t, err)
+	assert.Equal(t, shorts, result)
+
+	result, err = LocalizeForAccount(context.Background(), shorts, ShortsLocale{}, complete)
+	require.NoError(t, err)
+	assert.Equal(t, shorts, result)
+}
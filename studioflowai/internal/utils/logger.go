@@ -1,9 +1,12 @@
 package utils
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"sync"
 )
 
 // LogLevel represents the level of logging verbosity
@@ -23,6 +26,10 @@ const (
 var (
 	// CurrentLogLevel is the global log level setting
 	CurrentLogLevel LogLevel = LevelNormal
+
+	// logOutput receives all messages but errors, which always go to stderr; nil means stdout
+	logOutput   io.Writer
+	logOutputMu sync.RWMutex // Workers change the output while other steps log
 )
 
 // SetLogLevel sets the global logging level
@@ -30,6 +37,36 @@ func SetLogLevel(level LogLevel) {
 	CurrentLogLevel = level
 }
 
+// SetLogOutput sends log messages to w instead of stdout, e.g. to stderr when stdout carries data
+func SetLogOutput(w io.Writer) {
+	logOutputMu.Lock()
+	defer logOutputMu.Unlock()
+	logOutput = w
+}
+
+// LogWriter returns the writer log messages go to. With step prefixes, step log files or a
+// forwarded log, the lines written to it are handled like log messages of the step of ctx.
+func LogWriter(ctx context.Context) io.Writer {
+	w := logOutputWriter()
+	logCtx := logStepFrom(ctx)
+	stepLog.mu.Lock()
+	defer stepLog.mu.Unlock()
+	if stepLog.prefix || stepLog.combined != nil || logCtx.forward != nil {
+		return &stepLogWriter{w: w, step: logCtx.step, forward: logCtx.forward}
+	}
+	return w
+}
+
+// logOutputWriter returns the writer messages other than errors go to
+func logOutputWriter() io.Writer {
+	logOutputMu.RLock()
+	defer logOutputMu.RUnlock()
+	if logOutput == nil {
+		return os.Stdout
+	}
+	return logOutput
+}
+
 // LogLevelFromString converts a string level name to LogLevel
 func LogLevelFromString(level string) LogLevel {
 	switch strings.ToLower(level) {
@@ -46,42 +83,76 @@ func LogLevelFromString(level string) LogLevel {
 	}
 }
 
+// All log functions pass their message through Redact, so secrets never reach the output. The
+// Context variants log the message as part of the step of ctx, see WithLogStep.
+
 // LogError logs an error message (always shown)
 func LogError(format string, args ...interface{}) {
-	fmt.Fprintf(os.Stderr, "%s\n", Error(fmt.Sprintf(format, args...)))
+	LogErrorContext(context.Background(), format, args...)
 }
 
 // LogInfo logs an informational message at Normal+ level
 func LogInfo(format string, args ...interface{}) {
-	if CurrentLogLevel >= LevelNormal {
-		fmt.Printf("%s\n", Info(fmt.Sprintf(format, args...)))
-	}
+	LogInfoContext(context.Background(), format, args...)
 }
 
 // LogSuccess logs a success message at Normal+ level
 func LogSuccess(format string, args ...interface{}) {
-	if CurrentLogLevel >= LevelNormal {
-		fmt.Printf("%s\n", Success(fmt.Sprintf(format, args...)))
-	}
+	LogSuccessContext(context.Background(), format, args...)
 }
 
 // LogVerbose logs a message at Verbose+ level
 func LogVerbose(format string, args ...interface{}) {
-	if CurrentLogLevel >= LevelVerbose {
-		fmt.Printf("\t%s\n", Info(fmt.Sprintf(format, args...)))
-	}
+	LogVerboseContext(context.Background(), format, args...)
 }
 
 // LogDebug logs a debug message at Debug level
 func LogDebug(format string, args ...interface{}) {
-	if CurrentLogLevel >= LevelDebug {
-		fmt.Printf("\t%s\n", Debug(fmt.Sprintf(format, args...)))
-	}
+	LogDebugContext(context.Background(), format, args...)
 }
 
 // LogWarning logs a warning message at Normal+ level
 func LogWarning(format string, args ...interface{}) {
+	LogWarningContext(context.Background(), format, args...)
+}
+
+// LogErrorContext logs an error message of the step of ctx (always shown). Errors are not
+// forwarded, as the step returns them.
+func LogErrorContext(ctx context.Context, format string, args ...interface{}) {
+	writeLog(ctx, os.Stderr, Error(Redact(fmt.Sprintf(format, args...))), false)
+}
+
+// LogInfoContext logs an informational message of the step of ctx at Normal+ level
+func LogInfoContext(ctx context.Context, format string, args ...interface{}) {
+	if CurrentLogLevel >= LevelNormal {
+		writeLog(ctx, logOutputWriter(), Info(Redact(fmt.Sprintf(format, args...))), true)
+	}
+}
+
+// LogSuccessContext logs a success message of the step of ctx at Normal+ level
+func LogSuccessContext(ctx context.Context, format string, args ...interface{}) {
+	if CurrentLogLevel >= LevelNormal {
+		writeLog(ctx, logOutputWriter(), Success(Redact(fmt.Sprintf(format, args...))), true)
+	}
+}
+
+// LogVerboseContext logs a message of the step of ctx at Verbose+ level
+func LogVerboseContext(ctx context.Context, format string, args ...interface{}) {
+	if CurrentLogLevel >= LevelVerbose {
+		writeLog(ctx, logOutputWriter(), "\t"+Info(Redact(fmt.Sprintf(format, args...))), true)
+	}
+}
+
+// LogDebugContext logs a debug message of the step of ctx at Debug level
+func LogDebugContext(ctx context.Context, format string, args ...interface{}) {
+	if CurrentLogLevel >= LevelDebug {
+		writeLog(ctx, logOutputWriter(), "\t"+Debug(Redact(fmt.Sprintf(format, args...))), true)
+	}
+}
+
+// LogWarningContext logs a warning message of the step of ctx at Normal+ level
+func LogWarningContext(ctx context.Context, format string, args ...interface{}) {
 	if CurrentLogLevel >= LevelNormal {
-		fmt.Printf("%s\n", Warning(fmt.Sprintf(format, args...)))
+		writeLog(ctx, logOutputWriter(), Warning(Redact(fmt.Sprintf(format, args...))), true)
 	}
 }
@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// MemoryStats is the physical memory of the machine, in bytes
+type MemoryStats struct {
+	Total     uint64
+	Available uint64 // Memory that can be given to new processes without swapping
+}
+
+// UsedPercent returns the share of memory in use, from 0 to 100
+func (s MemoryStats) UsedPercent() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return float64(s.Total-min(s.Available, s.Total)) / float64(s.Total) * 100
+}
+
+// SystemMemory reads the memory of the machine from /proc/meminfo on Linux and from sysctl and
+// vm_stat on macOS
+func SystemMemory() (MemoryStats, error) {
+	switch runtime.GOOS {
+	case "linux":
+		data, err := os.ReadFile("/proc/meminfo")
+		if err != nil {
+			return MemoryStats{}, fmt.Errorf("failed to read memory: %w", err)
+		}
+		return parseMeminfo(string(data))
+	case "darwin":
+		total, err := exec.Command("sysctl", "-n", "hw.memsize").Output()
+		if err != nil {
+			return MemoryStats{}, fmt.Errorf("failed to read memory size: %w", err)
+		}
+		vmStat, err := exec.Command("vm_stat").Output()
+		if err != nil {
+			return MemoryStats{}, fmt.Errorf("failed to read memory: %w", err)
+		}
+		return parseVMStat(strings.TrimSpace(string(total)), string(vmStat))
+	default:
+		return MemoryStats{}, fmt.Errorf("reading memory is not supported on %s", runtime.GOOS)
+	}
+}
+
+// parseMeminfo reads MemTotal and MemAvailable, given in kB, from /proc/meminfo
+func parseMeminfo(meminfo string) (MemoryStats, error) {
+	values := map[string]uint64{}
+	scanner := bufio.NewScanner(strings.NewReader(meminfo))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		kb, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimSpace(value), " kB"), 10, 64)
+		if err == nil {
+			values[key] = kb * 1024
+		}
+	}
+	total, hasTotal := values["MemTotal"]
+	available, hasAvailable := values["MemAvailable"]
+	if !hasTotal || !hasAvailable {
+		return MemoryStats{}, fmt.Errorf("MemTotal or MemAvailable missing from /proc/meminfo")
+	}
+	return MemoryStats{Total: total, Available: available}, nil
+}
+
+var (
+	vmStatPageSize = regexp.MustCompile(`page size of (\d+) bytes`)
+	vmStatLine     = regexp.MustCompile(`^(.+):\s+(\d+)\.?$`)
+)
+
+// parseVMStat counts free, inactive, speculative and purgeable pages of vm_stat as available, as
+// macOS reclaims them without swapping
+func parseVMStat(memsize, vmStat string) (MemoryStats, error) {
+	total, err := strconv.ParseUint(memsize, 10, 64)
+	if err != nil {
+		return MemoryStats{}, fmt.Errorf("unexpected memory size %q", memsize)
+	}
+	match := vmStatPageSize.FindStringSubmatch(vmStat)
+	if match == nil {
+		return MemoryStats{}, fmt.Errorf("page size missing from vm_stat output")
+	}
+	pageSize, _ := strconv.ParseUint(match[1], 10, 64)
+
+	var pages uint64
+	for _, line := range strings.Split(vmStat, "\n") {
+		m := vmStatLine.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		switch m[1] {
+		case "Pages free", "Pages inactive", "Pages speculative", "Pages purgeable":
+			n, _ := strconv.ParseUint(m[2], 10, 64)
+			pages += n
+		}
+	}
+	return MemoryStats{Total: total, Available: min(pages*pageSize, total)}, nil
+}
@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMeminfo(t *testing.T) {
+	stats, err := parseMeminfo("MemTotal:       16000000 kB\nMemFree:         1000000 kB\nMemAvailable:    4000000 kB\n")
+	require.NoError(t, err)
+	assert.Equal(t, MemoryStats{Total: 16000000 * 1024, Available: 4000000 * 1024}, stats)
+	assert.InDelta(t, 75, stats.UsedPercent(), 0.001)
+
+	_, err = parseMeminfo("MemTotal:       16000000 kB\n")
+	assert.Error(t, err)
+}
+
+func TestParseVMStat(t *testing.T) {
+	vmStat := `Mach Virtual Memory Statistics: (page size of 16384 bytes)
+Pages free:                               10000.
+Pages active:                            300000.
+Pages inactive:                           40000.
+Pages speculative:                         5000.
+Pages throttled:                              0.
+Pages wired down:                        150000.
+Pages purgeable:                           5000.
+`
+	stats, err := parseVMStat("17179869184", vmStat)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(17179869184), stats.Total)
+	assert.Equal(t, uint64(60000*16384), stats.Available)
+
+	_, err = parseVMStat("17179869184", "Pages free: 10.")
+	assert.ErrorContains(t, err, "page size")
+}
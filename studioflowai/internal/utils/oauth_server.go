@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -20,12 +21,11 @@ type TokenStorage struct {
 
 // NewTokenStorage creates a new token storage instance
 func NewTokenStorage() (*TokenStorage, error) {
-	homeDir, err := os.UserHomeDir()
+	configDir, err := ConfigDir()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
+		return nil, err
 	}
 
-	configDir := filepath.Join(homeDir, ".studioflowai")
 	if err := os.MkdirAll(configDir, 0700); err != nil {
 		return nil, fmt.Errorf("failed to create config directory: %w", err)
 	}
@@ -167,6 +167,16 @@ func (s *OAuthCallbackServer) WaitForCode() string {
 	return <-s.codeChan
 }
 
+// WaitForCodeContext waits for the authorization code until the context is done
+func (s *OAuthCallbackServer) WaitForCodeContext(ctx context.Context) (string, error) {
+	select {
+	case code := <-s.codeChan:
+		return code, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
 // Stop stops the callback server
 func (s *OAuthCallbackServer) Stop() error {
 	if s.server != nil {
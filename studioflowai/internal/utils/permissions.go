@@ -0,0 +1,206 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// Permissions sets the modes and group of the files and folders a run writes, so output folders
+// shared by several users on a NAS stay readable and writable by all of them
+type Permissions struct {
+	Umask    string `yaml:"umask,omitempty"`    // Octal umask such as "002"; also applies to files written by external tools
+	FileMode string `yaml:"fileMode,omitempty"` // Octal mode of output files (default: 0666 without the umask, else 0644)
+	DirMode  string `yaml:"dirMode,omitempty"`  // Octal mode of output folders (default: 0777 without the umask, else 0755)
+	Group    string `yaml:"group,omitempty"`    // Group name or ID of output files and folders; new folders are setgid so files inherit it
+}
+
+var (
+	permsMu sync.RWMutex
+	// perms holds the applied Permissions; nil keeps the modes each writer asks for
+	perms *outputPermissions
+)
+
+// outputPermissions is the parsed form of Permissions
+type outputPermissions struct {
+	fileMode os.FileMode
+	dirMode  os.FileMode
+	gid      int // -1 keeps the group of the creating user
+}
+
+// ApplyPermissions parses p and uses it for every output file and folder written from now on.
+// A nil p restores the defaults. The umask, when given, is set for the whole process.
+func ApplyPermissions(p *Permissions) error {
+	if p == nil {
+		permsMu.Lock()
+		perms = nil
+		permsMu.Unlock()
+		return nil
+	}
+
+	parsed := &outputPermissions{fileMode: 0644, dirMode: 0755, gid: -1}
+	if p.Umask != "" {
+		umask, err := parseMode("umask", p.Umask)
+		if err != nil {
+			return err
+		}
+		if err := setUmask(umask); err != nil {
+			return err
+		}
+		parsed.fileMode = 0666 &^ umask
+		parsed.dirMode = 0777 &^ umask
+	}
+	if p.FileMode != "" {
+		mode, err := parseMode("fileMode", p.FileMode)
+		if err != nil {
+			return err
+		}
+		parsed.fileMode = mode
+	}
+	if p.DirMode != "" {
+		mode, err := parseMode("dirMode", p.DirMode)
+		if err != nil {
+			return err
+		}
+		parsed.dirMode = mode
+	}
+	if parsed.fileMode&0400 == 0 || parsed.dirMode&0700 != 0700 {
+		return fmt.Errorf("permissions would lock the owner out of the output (file mode %04o, folder mode %04o)",
+			parsed.fileMode, parsed.dirMode)
+	}
+	if p.Group != "" {
+		gid, err := lookupGroup(p.Group)
+		if err != nil {
+			return err
+		}
+		parsed.gid = gid
+	}
+
+	permsMu.Lock()
+	perms = parsed
+	permsMu.Unlock()
+	return nil
+}
+
+// parseMode parses an octal permission setting such as "0664" or "002"
+func parseMode(name, value string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(value, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf("invalid permissions %s %q: use an octal mode such as 0664", name, value)
+	}
+	return os.FileMode(mode), nil
+}
+
+// lookupGroup returns the ID of a group given by name or number
+func lookupGroup(group string) (int, error) {
+	if gid, err := strconv.Atoi(group); err == nil {
+		return gid, nil
+	}
+	g, err := user.LookupGroup(group)
+	if err != nil {
+		return 0, fmt.Errorf("invalid permissions group %q: %w", group, err)
+	}
+	gid, err := strconv.Atoi(g.Gid)
+	if err != nil {
+		return 0, fmt.Errorf("invalid permissions group %q: non-numeric ID %s", group, g.Gid)
+	}
+	return gid, nil
+}
+
+// currentPermissions returns the applied permissions, or nil
+func currentPermissions() *outputPermissions {
+	permsMu.RLock()
+	defer permsMu.RUnlock()
+	return perms
+}
+
+// outputFileMode returns the mode an output file asked to be written with perm gets. Private files,
+// such as tokens written with 0600, keep their mode. Executable files, such as scripts written with
+// 0755, stay executable by whoever the configured mode lets read them.
+func outputFileMode(perm os.FileMode) os.FileMode {
+	p := currentPermissions()
+	if p == nil || perm&0077 == 0 {
+		return perm
+	}
+	readable := p.fileMode & 0444
+	return p.fileMode | (readable>>2)&perm&0111
+}
+
+// setOutputGroup gives an output file the configured group, if any
+func setOutputGroup(f *os.File) error {
+	p := currentPermissions()
+	if p == nil || p.gid < 0 {
+		return nil
+	}
+	if err := f.Chown(-1, p.gid); err != nil {
+		return fmt.Errorf("failed to set group of %s: %w", f.Name(), err)
+	}
+	return nil
+}
+
+// EnsureDir creates an output folder and any missing parents, and succeeds when it already
+// exists. Folders it creates get the configured mode and group; existing ones are left as they are.
+func EnsureDir(path string) error {
+	p := currentPermissions()
+	if p == nil {
+		return os.MkdirAll(path, 0755)
+	}
+
+	// Note which folders are missing, so only those get the configured mode and group
+	var missing []string
+	for dir := filepath.Clean(path); ; {
+		if _, err := os.Stat(dir); !os.IsNotExist(err) {
+			break
+		}
+		missing = append(missing, dir)
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	if err := os.MkdirAll(path, p.dirMode); err != nil {
+		return err
+	}
+	for i := len(missing) - 1; i >= 0; i-- {
+		if err := setDirPermissions(missing[i], p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateDir creates a single output folder, whose parent must exist, with the configured mode
+// and group. Unlike EnsureDir it fails with an error matching fs.ErrExist when the folder is
+// already there, so concurrent callers can each claim a folder of their own.
+func CreateDir(path string) error {
+	p := currentPermissions()
+	if p == nil {
+		return os.Mkdir(path, 0755)
+	}
+	if err := os.Mkdir(path, p.dirMode); err != nil {
+		return err
+	}
+	return setDirPermissions(path, p)
+}
+
+// setDirPermissions gives a folder created for output the configured mode and group
+func setDirPermissions(dir string, p *outputPermissions) error {
+	mode := p.dirMode
+	if p.gid >= 0 {
+		if err := os.Chown(dir, -1, p.gid); err != nil {
+			return fmt.Errorf("failed to set group of %s: %w", dir, err)
+		}
+		// Files created in the folder, also by external tools, inherit its group
+		mode |= os.ModeSetgid
+	}
+	// The process umask may have taken bits off the mode the folder was created with
+	if err := os.Chmod(dir, mode); err != nil {
+		return fmt.Errorf("failed to set permissions of %s: %w", dir, err)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyPermissions(t *testing.T) {
+	t.Cleanup(func() { _ = ApplyPermissions(nil) })
+	dir := t.TempDir()
+	before, err := os.Stat(dir)
+	require.NoError(t, err)
+
+	// The user's own group can be set without privileges
+	gid := os.Getgid()
+	require.NoError(t, ApplyPermissions(&Permissions{FileMode: "0664", DirMode: "0775", Group: strconv.Itoa(gid)}))
+
+	nested := filepath.Join(dir, "a", "b")
+	require.NoError(t, EnsureDir(nested))
+	require.NoError(t, EnsureDir(nested), "creating an existing folder succeeds")
+	for _, d := range []string{filepath.Join(dir, "a"), nested} {
+		info, err := os.Stat(d)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0775), info.Mode().Perm(), d)
+		assert.NotZero(t, info.Mode()&os.ModeSetgid, "new folders pass their group on")
+	}
+	info, err := os.Stat(dir)
+	require.NoError(t, err)
+	assert.Equal(t, before.Mode(), info.Mode(), "existing folders are left as they are")
+
+	file := filepath.Join(nested, "out.txt")
+	require.NoError(t, WriteTextFile(file, "hello"))
+	info, err = os.Stat(file)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0664), info.Mode().Perm())
+
+	private := filepath.Join(nested, "token.json")
+	require.NoError(t, AtomicWriteFile(private, []byte("{}"), 0600))
+	info, err = os.Stat(private)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "private files keep their mode")
+
+	script := filepath.Join(nested, "commands.sh")
+	require.NoError(t, AtomicWriteFile(script, []byte("#!/bin/sh\n"), 0755))
+	info, err = os.Stat(script)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0775), info.Mode().Perm(), "scripts stay executable")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "file"), nil, 0644))
+	assert.Error(t, EnsureDir(filepath.Join(dir, "file")), "a file in the way is an error")
+}
+
+func TestOutputFileMode(t *testing.T) {
+	t.Cleanup(func() { _ = ApplyPermissions(nil) })
+
+	tests := []struct {
+		name     string
+		settings Permissions
+		perm     os.FileMode
+		want     os.FileMode
+	}{
+		{name: "data file", settings: Permissions{FileMode: "0664"}, perm: 0644, want: 0664},
+		{name: "private file", settings: Permissions{FileMode: "0664"}, perm: 0600, want: 0600},
+		{name: "script", settings: Permissions{FileMode: "0664"}, perm: 0755, want: 0775},
+		{name: "owner-only script", settings: Permissions{FileMode: "0664"}, perm: 0744, want: 0764},
+		{name: "group-private script", settings: Permissions{FileMode: "0640"}, perm: 0755, want: 0750},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, ApplyPermissions(&tt.settings))
+			assert.Equal(t, tt.want, outputFileMode(tt.perm))
+		})
+	}
+}
+
+func TestApplyPermissions_Invalid(t *testing.T) {
+	t.Cleanup(func() { _ = ApplyPermissions(nil) })
+
+	assert.ErrorContains(t, ApplyPermissions(&Permissions{FileMode: "rw-r--r--"}), `invalid permissions fileMode "rw-r--r--"`)
+	assert.ErrorContains(t, ApplyPermissions(&Permissions{DirMode: "0644"}), "lock the owner out")
+	assert.ErrorContains(t, ApplyPermissions(&Permissions{Group: "no-such-group-studioflowai"}), "invalid permissions group")
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package utils
+
+// processRunning cannot check processes on this platform, so temporary folders are
+// only treated as orphaned once they are old enough
+func processRunning(pid int) bool {
+	return true
+}
@@ -0,0 +1,11 @@
+//go:build unix
+
+package utils
+
+import "golang.org/x/sys/unix"
+
+// processRunning reports whether a process with the given ID exists on this host
+func processRunning(pid int) bool {
+	err := unix.Kill(pid, 0)
+	return err == nil || err == unix.EPERM
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ProxySuffix ends the default file name of the proxies written by make_proxy
+const ProxySuffix = "_proxy.mp4"
+
+// ProxyName returns the default file name of the proxy of source
+func ProxyName(source string) string {
+	base := filepath.Base(source)
+	return strings.TrimSuffix(base, filepath.Ext(base)) + ProxySuffix
+}
+
+// FindProxy returns the proxy of source written to one of dirs under its default name. A proxy
+// older than its source is stale and ignored.
+func FindProxy(source string, dirs ...string) (string, bool) {
+	if source == "" || strings.HasSuffix(source, ProxySuffix) {
+		return "", false
+	}
+	info, err := os.Stat(source)
+	if err != nil {
+		return "", false
+	}
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		proxy := filepath.Join(dir, ProxyName(source))
+		if proxyInfo, err := os.Stat(proxy); err == nil && !proxyInfo.ModTime().Before(info.ModTime()) {
+			return proxy, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindProxy(t *testing.T) {
+	sourceDir := t.TempDir()
+	runDir := t.TempDir()
+	source := filepath.Join(sourceDir, "episode.mov")
+	require.NoError(t, os.WriteFile(source, []byte("4k"), 0644))
+	assert.Equal(t, "episode_proxy.mp4", ProxyName(source))
+
+	_, ok := FindProxy(source, runDir)
+	assert.False(t, ok, "no proxy was made")
+
+	proxy := filepath.Join(runDir, "episode_proxy.mp4")
+	require.NoError(t, os.WriteFile(proxy, []byte("540p"), 0644))
+	got, ok := FindProxy(source, "", filepath.Join(runDir, "previews"), runDir)
+	assert.True(t, ok)
+	assert.Equal(t, proxy, got)
+
+	_, ok = FindProxy(proxy, runDir)
+	assert.False(t, ok, "a proxy has no proxy of its own")
+
+	// A source changed after the proxy was made
+	later := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(source, later, later))
+	_, ok = FindProxy(source, runDir)
+	assert.False(t, ok, "stale proxies are ignored")
+}
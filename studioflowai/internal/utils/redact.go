@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// redactedText replaces every secret removed from log output
+const redactedText = "[REDACTED]"
+
+// secretEnvVars lists environment variables whose values are never printed
+var secretEnvVars = []string{
+	"OPENAI_API_KEY",
+	"TIKTOK_CLIENT_SECRET",
+	"BUTTONDOWN_API_KEY",
+	"MAILCHIMP_API_KEY",
+}
+
+// secretPatterns match secrets by shape; the first group is kept, the rest is redacted
+var secretPatterns = []*regexp.Regexp{
+	// Authorization header values: "Authorization: Bearer <token>"
+	regexp.MustCompile(`(?i)(\bauthorization\s*:\s*(?:(?:bearer|basic)\s+)?)[^\s"',]+`),
+	// JSON fields: "access_token": "..."
+	regexp.MustCompile(`(?i)("(?:access_token|refresh_token|id_token|client_secret|code|code_verifier|api_key|apikey)"\s*:\s*")[^"]*`),
+	// Query strings and form bodies: ?code=...&access_token=...
+	regexp.MustCompile(`(?i)((?:^|[?&\s])(?:access_token|refresh_token|id_token|client_secret|code|code_verifier|api_key|apikey|key)=)[^&\s"']+`),
+	// Environment style assignments: OPENAI_API_KEY=...
+	regexp.MustCompile(`(\b[A-Z0-9_]*(?:API_KEY|SECRET|TOKEN)\s*=\s*)[^\s"']+`),
+	// OpenAI API keys
+	regexp.MustCompile(`()\bsk-[A-Za-z0-9_\-]{16,}`),
+	// Google API keys
+	regexp.MustCompile(`()\bAIza[0-9A-Za-z_\-]{35}`),
+}
+
+// authSchemePattern matches credentials after an authorization scheme, e.g. "Bearer <token>".
+// The scheme is case-sensitive and only token-shaped values are redacted, so prose such as
+// "a basic outline" or "Basic auth required" is left alone.
+var authSchemePattern = regexp.MustCompile(`\b((?:Bearer|Basic)\s+)([A-Za-z0-9\-._~+/]+=*)`)
+
+// minTokenLength is the length from which a value after a scheme is taken for a token
+const minTokenLength = 16
+
+var (
+	secretsMu sync.RWMutex
+	secrets   []string
+)
+
+// RegisterSecret adds a value that must be redacted from all log output
+func RegisterSecret(value string) {
+	// Very short values would redact ordinary words
+	if len(value) < 8 {
+		return
+	}
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	for _, s := range secrets {
+		if s == value {
+			return
+		}
+	}
+	secrets = append(secrets, value)
+}
+
+// Redact removes bearer tokens, API keys and OAuth codes from a message
+func Redact(message string) string {
+	for _, envVar := range secretEnvVars {
+		if value := os.Getenv(envVar); len(value) >= 8 {
+			message = strings.ReplaceAll(message, value, redactedText)
+		}
+	}
+
+	secretsMu.RLock()
+	for _, value := range secrets {
+		message = strings.ReplaceAll(message, value, redactedText)
+	}
+	secretsMu.RUnlock()
+
+	for _, re := range secretPatterns {
+		message = re.ReplaceAllString(message, "${1}"+redactedText)
+	}
+	return authSchemePattern.ReplaceAllStringFunc(message, func(match string) string {
+		groups := authSchemePattern.FindStringSubmatch(match)
+		if !tokenShaped(groups[2]) {
+			return match
+		}
+		return groups[1] + redactedText
+	})
+}
+
+// tokenShaped reports whether a value looks like a credential rather than a word: base64 with
+// padding, or a long value that is not only letters
+func tokenShaped(value string) bool {
+	if strings.HasSuffix(value, "=") {
+		return true
+	}
+	return len(value) >= minTokenLength && strings.ContainsFunc(value, func(r rune) bool {
+		return !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z')
+	})
+}
@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		secret  string
+		want    string
+	}{
+		{
+			name:    "bearer token",
+			message: "Authorization header: Bearer act.example-Token_123/abc==",
+			secret:  "act.example-Token_123/abc==",
+			want:    "Authorization header: Bearer [REDACTED]",
+		},
+		{
+			name:    "basic credentials",
+			message: "request failed: Basic dXNlcjpwYXNzd29yZA==",
+			secret:  "dXNlcjpwYXNzd29yZA==",
+			want:    "request failed: Basic [REDACTED]",
+		},
+		{
+			name:    "authorization header",
+			message: "headers: Authorization: Bearer shorttok, Accept: */*",
+			secret:  "shorttok",
+			want:    "headers: Authorization: Bearer [REDACTED], Accept: */*",
+		},
+		{
+			name:    "OpenAI API key",
+			message: "using key sk-proj-abcdefghijklmnopqrstuvwx",
+			secret:  "sk-proj-abcdefghijklmnopqrstuvwx",
+			want:    "using key [REDACTED]",
+		},
+		{
+			name:    "Google API key",
+			message: "GET https://example.com/v3/videos?key=AIzaSyA1234567890abcdefghijklmnopqrstu",
+			secret:  "AIzaSyA1234567890abcdefghijklmnopqrstu",
+			want:    "GET https://example.com/v3/videos?key=[REDACTED]",
+		},
+		{
+			name:    "OAuth code in callback URL",
+			message: "callback: /callback?code=4/0AX4XfWh-secretcode&state=state-token",
+			secret:  "4/0AX4XfWh-secretcode",
+			want:    "callback: /callback?code=[REDACTED]&state=state-token",
+		},
+		{
+			name:    "form body",
+			message: "client_key=abc&client_secret=verysecretvalue&code=authcode123&code_verifier=verifier456",
+			secret:  "verysecretvalue",
+			want:    "client_key=abc&client_secret=[REDACTED]&code=[REDACTED]&code_verifier=[REDACTED]",
+		},
+		{
+			name:    "JSON token response",
+			message: `body: {"access_token":"act.1234","refresh_token": "rft.5678","open_id":"user"}`,
+			secret:  "act.1234",
+			want:    `body: {"access_token":"[REDACTED]","refresh_token": "[REDACTED]","open_id":"user"}`,
+		},
+		{
+			name:    "environment assignment",
+			message: "loaded MAILCHIMP_API_KEY=0123456789abcdef-us21",
+			secret:  "0123456789abcdef-us21",
+			want:    "loaded MAILCHIMP_API_KEY=[REDACTED]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Redact(tt.message)
+			assert.Equal(t, tt.want, got)
+			assert.NotContains(t, got, tt.secret)
+		})
+	}
+}
+
+func TestRedact_KeepsOrdinaryMessages(t *testing.T) {
+	messages := []string{
+		"ffmpeg exited with code 1",
+		"Failed to load token: file not found",
+		"Opening https://www.tiktok.com/v2/auth/authorize/?client_key=abc&code_challenge=xyz&state=s",
+		"Processing clip 3 of 10",
+		"Generating a basic outline for clip 3",
+		"basic auth required",
+		"Basic authentication failed for the proxy",
+		"Bearer token expired, logging in again",
+	}
+	for _, message := range messages {
+		assert.Equal(t, message, Redact(message))
+	}
+}
+
+func TestRedact_EnvironmentSecrets(t *testing.T) {
+	t.Setenv("TIKTOK_CLIENT_SECRET", "plain-secret-value")
+
+	got := Redact("token request failed: invalid client plain-secret-value")
+	assert.Equal(t, "token request failed: invalid client [REDACTED]", got)
+}
+
+func TestRegisterSecret(t *testing.T) {
+	RegisterSecret("custom-secret-1234")
+	RegisterSecret("short")
+
+	assert.Equal(t, "value [REDACTED]", Redact("value custom-secret-1234"))
+	assert.Equal(t, "value short", Redact("value short"))
+}
+
+func TestLogFunctionsRedact(t *testing.T) {
+	previous := CurrentLogLevel
+	SetLogLevel(LevelDebug)
+	defer SetLogLevel(previous)
+
+	output := captureStdout(t, func() {
+		LogInfo("Authorization header: %s", "Bearer act.secret-token")
+		LogWarning("token response: %s", `{"access_token":"act.secret-token"}`)
+		LogDebug("callback: /callback?code=%s", "secret-auth-code")
+	})
+
+	assert.NotContains(t, output, "act.secret-token")
+	assert.NotContains(t, output, "secret-auth-code")
+	assert.Contains(t, output, "Bearer [REDACTED]")
+}
+
+// captureStdout returns everything written to stdout while fn runs
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	fn()
+	require.NoError(t, w.Close())
+
+	var buf bytes.Buffer
+	_, err = io.Copy(&buf, r)
+	require.NoError(t, err)
+	return buf.String()
+}
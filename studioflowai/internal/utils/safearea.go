@@ -0,0 +1,25 @@
+package utils
+
+import "sort"
+
+// SafeArea is the fraction of each edge of a vertical video covered by platform UI
+type SafeArea struct {
+	Top, Bottom, Left, Right float64
+}
+
+// SafeAreas approximates where each platform draws captions, buttons and the progress bar on a 9:16 frame
+var SafeAreas = map[string]SafeArea{
+	"youtube":   {Top: 0.15, Bottom: 0.20, Left: 0.05, Right: 0.15},
+	"tiktok":    {Top: 0.13, Bottom: 0.25, Left: 0.06, Right: 0.13},
+	"instagram": {Top: 0.14, Bottom: 0.20, Left: 0.06, Right: 0.06},
+}
+
+// SafeAreaPlatforms returns the platforms with a known safe area, sorted
+func SafeAreaPlatforms() []string {
+	platforms := make([]string, 0, len(SafeAreas))
+	for name := range SafeAreas {
+		platforms = append(platforms, name)
+	}
+	sort.Strings(platforms)
+	return platforms
+}
@@ -0,0 +1,179 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Platforms shorts are published to
+const (
+	PlatformYouTube   = "youtube"
+	PlatformTikTok    = "tiktok"
+	PlatformInstagram = "instagram"
+)
+
+// PlatformLimits are the limits a platform puts on a short video and its text. Zero means no limit.
+type PlatformLimits struct {
+	TitleChars       int           // Characters in the title
+	DescriptionChars int           // Characters in the description
+	DescriptionBytes int           // UTF-8 bytes in the description
+	Hashtags         int           // Hashtags in the description
+	MaxDuration      time.Duration // Length of the video
+}
+
+// platformLimits lists the limits of each platform. YouTube rejects titles over 100 characters,
+// descriptions over 5000 bytes and either with angle brackets, and ignores every hashtag of a
+// description with more than 60. TikTok captions hold 2200 characters. Shorts and Reels run up to
+// three minutes (YouTube channels without the longer Shorts are still held to 60 seconds), TikTok
+// videos up to ten.
+var platformLimits = map[string]PlatformLimits{
+	PlatformYouTube:   {TitleChars: 100, DescriptionChars: 5000, DescriptionBytes: 5000, Hashtags: 60, MaxDuration: 3 * time.Minute},
+	PlatformTikTok:    {TitleChars: 2200, DescriptionChars: 2200, MaxDuration: 10 * time.Minute},
+	PlatformInstagram: {MaxDuration: 3 * time.Minute},
+}
+
+// MaxClipDuration returns the longest short a platform accepts, or 0 when it is not known
+func MaxClipDuration(platform string) time.Duration {
+	return platformLimits[platform].MaxDuration
+}
+
+var (
+	htmlTagRegex       = regexp.MustCompile(`</?[a-zA-Z][^<>]*>`)
+	codeFenceRegex     = regexp.MustCompile("(?m)^[ \t]*```[^\n]*\n?")
+	headingRegex       = regexp.MustCompile(`(?m)^[ \t]{0,3}#{1,6}[ \t]+`)
+	markdownLinkRegex  = regexp.MustCompile(`\[([^\]]+)\]\((https?://[^)\s]+)\)`)
+	emphasisRegex      = regexp.MustCompile(`(\*\*|__)(\S(?:.*?\S)?)(\*\*|__)`)
+	starBulletRegex    = regexp.MustCompile(`(?m)^([ \t]*)[*+][ \t]+`)
+	inlineCodeRegex    = regexp.MustCompile("`([^`\\n]+)`")
+	hashtagRegex       = regexp.MustCompile(`#[\p{L}\p{N}_]+`)
+	extraNewlinesRegex = regexp.MustCompile(`\n{3,}`)
+)
+
+// SanitizeShorts prepares the titles and descriptions of shorts for upload to platform: markdown
+// and HTML that models like to produce are removed and the platform's limits enforced, so the
+// API does not reject the video. Every change is logged.
+func SanitizeShorts(shorts []ShortClip, platform string) []ShortClip {
+	sanitized := make([]ShortClip, len(shorts))
+	for i, short := range shorts {
+		title, changes := SanitizeTitle(short.ShortTitle, platform)
+		logSanitized(platform, "title", short.ShortTitle, changes)
+		description, changes := SanitizeDescription(short.Description, platform)
+		logSanitized(platform, "description", short.ShortTitle, changes)
+
+		short.ShortTitle = title
+		short.Description = description
+		sanitized[i] = short
+	}
+	return sanitized
+}
+
+// SanitizeTitle returns the title as a single plain-text line within the platform's limits,
+// and a description of each change made
+func SanitizeTitle(title, platform string) (string, []string) {
+	limits := platformLimits[platform]
+	text, changes := stripFormatting(title)
+	if flat := strings.Join(strings.Fields(text), " "); flat != text {
+		if strings.Contains(text, "\n") {
+			changes = append(changes, "joined lines")
+		}
+		text = flat
+	}
+	if t, ok := truncateText(text, limits.TitleChars, 0); ok {
+		text = t
+		changes = append(changes, fmt.Sprintf("truncated to %d characters", limits.TitleChars))
+	}
+	return text, changes
+}
+
+// SanitizeDescription returns the description as plain text within the platform's limits,
+// and a description of each change made
+func SanitizeDescription(description, platform string) (string, []string) {
+	limits := platformLimits[platform]
+	text, changes := stripFormatting(description)
+	text = strings.TrimSpace(extraNewlinesRegex.ReplaceAllString(text, "\n\n"))
+
+	if limits.Hashtags > 0 {
+		count := 0
+		removed := 0
+		text = hashtagRegex.ReplaceAllStringFunc(text, func(tag string) string {
+			if count++; count > limits.Hashtags {
+				removed++
+				return ""
+			}
+			return tag
+		})
+		if removed > 0 {
+			text = strings.TrimSpace(strings.Join(strings.FieldsFunc(text, func(r rune) bool { return r == ' ' }), " "))
+			changes = append(changes, fmt.Sprintf("removed %d hashtags over the limit of %d", removed, limits.Hashtags))
+		}
+	}
+
+	if t, ok := truncateText(text, limits.DescriptionChars, limits.DescriptionBytes); ok {
+		text = t
+		changes = append(changes, fmt.Sprintf("truncated to %d characters", utf8.RuneCountInString(text)))
+	}
+	return text, changes
+}
+
+// stripFormatting removes HTML, angle brackets and markdown markup, keeping the text they format
+func stripFormatting(text string) (string, []string) {
+	var changes []string
+	replace := func(re *regexp.Regexp, repl, change string) {
+		if re.MatchString(text) {
+			text = re.ReplaceAllString(text, repl)
+			changes = append(changes, change)
+		}
+	}
+
+	replace(htmlTagRegex, "", "removed HTML tags")
+	if strings.ContainsAny(text, "<>") {
+		text = strings.NewReplacer("<", "", ">", "").Replace(text)
+		changes = append(changes, "removed angle brackets")
+	}
+
+	replace(codeFenceRegex, "", "removed code fences")
+	replace(headingRegex, "", "removed markdown headings")
+	replace(markdownLinkRegex, "$1 ($2)", "expanded markdown links")
+	replace(emphasisRegex, "$2", "removed bold markers")
+	replace(inlineCodeRegex, "$1", "removed code markers")
+	replace(starBulletRegex, "$1- ", "replaced list markers")
+	return strings.TrimSpace(text), changes
+}
+
+// truncateText cuts text to at most maxChars characters and maxBytes bytes, at a word boundary
+// when one is near. It reports whether the text was cut.
+func truncateText(text string, maxChars, maxBytes int) (string, bool) {
+	cut := len(text)
+	if maxChars > 0 && utf8.RuneCountInString(text) > maxChars {
+		cut = 0
+		for i := 0; i < maxChars; i++ {
+			_, size := utf8.DecodeRuneInString(text[cut:])
+			cut += size
+		}
+	}
+	if maxBytes > 0 && cut > maxBytes {
+		cut = maxBytes
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+	}
+	if cut == len(text) {
+		return text, false
+	}
+
+	// Prefer ending at a space in the last fifth of the kept text
+	if space := strings.LastIndexAny(text[:cut], " \n"); space > cut*4/5 {
+		cut = space
+	}
+	return strings.TrimSpace(text[:cut]), true
+}
+
+// logSanitized logs the changes made to a field of a short
+func logSanitized(platform, field, title string, changes []string) {
+	if len(changes) > 0 {
+		LogWarning("Sanitized %s of %q for %s: %s", field, title, platform, strings.Join(changes, ", "))
+	}
+}
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeTitle(t *testing.T) {
+	tests := []struct {
+		name    string
+		title   string
+		want    string
+		changes []string
+	}{
+		{"plain title", "¿Qué es Go?", "¿Qué es Go?", nil},
+		{"bold and heading", "## **¿Qué es Go?**", "¿Qué es Go?", []string{"removed markdown headings", "removed bold markers"}},
+		{"html and brackets", "<b>Go</b> <3 Rust", "Go 3 Rust", []string{"removed HTML tags", "removed angle brackets"}},
+		{"several lines", "Go\nis fast", "Go is fast", []string{"joined lines"}},
+		{"hashtags are kept", "Go #golang", "Go #golang", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changes := SanitizeTitle(tt.title, PlatformYouTube)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.changes, changes)
+		})
+	}
+
+	long := strings.Repeat("palabra ", 20)
+	got, changes := SanitizeTitle(long, PlatformYouTube)
+	assert.LessOrEqual(t, utf8.RuneCountInString(got), 100)
+	assert.True(t, strings.HasSuffix(got, "palabra"), "cut at a word boundary")
+	assert.Equal(t, []string{"truncated to 100 characters"}, changes)
+
+	got, changes = SanitizeTitle(long, PlatformTikTok)
+	assert.Equal(t, strings.TrimSpace(long), got)
+	assert.Empty(t, changes)
+}
+
+func TestSanitizeDescription(t *testing.T) {
+	description := "# Resumen\n\n**Go** explicado en `1 minuto`.\n\n\n\n* Ver [el video](https://youtu.be/abc)\n<script>x</script>"
+	got, changes := SanitizeDescription(description, PlatformYouTube)
+	assert.Equal(t, "Resumen\n\nGo explicado en 1 minuto.\n\n- Ver el video (https://youtu.be/abc)\nx", got)
+	assert.Len(t, changes, 6)
+
+	// More than 60 hashtags make YouTube ignore all of them
+	var tags []string
+	for i := 0; i < 65; i++ {
+		tags = append(tags, "#tag"+strings.Repeat("x", i%3))
+	}
+	got, changes = SanitizeDescription("Video "+strings.Join(tags, " "), PlatformYouTube)
+	assert.Len(t, hashtagRegex.FindAllString(got, -1), 60)
+	assert.Equal(t, []string{"removed 5 hashtags over the limit of 60"}, changes)
+	assert.NotContains(t, got, "  ")
+
+	_, changes = SanitizeDescription("Video "+strings.Join(tags, " "), PlatformTikTok)
+	assert.Empty(t, changes)
+
+	// The YouTube limit is in bytes, which accented text reaches first
+	got, changes = SanitizeDescription(strings.Repeat("canción ", 700), PlatformYouTube)
+	assert.LessOrEqual(t, len(got), 5000)
+	assert.True(t, utf8.ValidString(got))
+	assert.Len(t, changes, 1)
+}
+
+func TestSanitizeShorts(t *testing.T) {
+	shorts := []ShortClip{{ShortTitle: "**Go**", Description: "<p>Hola</p>", StartTime: "00:00:01"}}
+	got := SanitizeShorts(shorts, PlatformYouTube)
+	assert.Equal(t, []ShortClip{{ShortTitle: "Go", Description: "Hola", StartTime: "00:00:01"}}, got)
+	assert.Equal(t, "**Go**", shorts[0].ShortTitle, "the input is not modified")
+}
@@ -3,27 +3,83 @@ package utils
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
 // ShortClip represents a single short video clip
 type ShortClip struct {
-	Title       string `yaml:"title"`
-	StartTime   string `yaml:"startTime"`
-	EndTime     string `yaml:"endTime"`
-	Description string `yaml:"description"`
-	Tags        string `yaml:"tags"`
-	ShortTitle  string `yaml:"shortTitle"`
+	Title       string      `yaml:"title"`
+	StartTime   string      `yaml:"startTime"`
+	EndTime     string      `yaml:"endTime"`
+	Description string      `yaml:"description"`
+	Tags        string      `yaml:"tags"`
+	ShortTitle  string      `yaml:"shortTitle"`
+	Storyboard  string      `yaml:"storyboard,omitempty"` // Contact sheet image, relative to the shorts file
+	Excerpt     string      `yaml:"excerpt,omitempty"`    // Transcript text the clip was cut from
+	Cues        *CueRange   `yaml:"cues,omitempty"`       // SRT cues of the excerpt
+	Rating      *ClipRating `yaml:"rating,omitempty"`     // Safety rating of the title and description
 }
 
+// ClipRating is the safety rating rate_shorts gives the title and description of a clip. Ratings
+// go from 0 (none) to 10 (severe).
+type ClipRating struct {
+	Clickbait  int      `yaml:"clickbait"`         // How much the title overpromises or withholds to bait clicks
+	Policy     int      `yaml:"policy"`            // Health, financial, legal or other claims platforms restrict
+	Misleading int      `yaml:"misleading"`        // How far the framing departs from what is said in the clip
+	Risk       int      `yaml:"risk"`              // Highest of the three
+	Flags      []string `yaml:"flags,omitempty"`   // Phrases that raised the ratings
+	Notes      string   `yaml:"notes,omitempty"`   // Why, in a sentence
+	Model      string   `yaml:"model,omitempty"`   // Model that rated the clip
+	Blocked    bool     `yaml:"blocked,omitempty"` // Risk above the rating step's maxRisk; the clip is not uploaded
+}
+
+// UnblockedShorts returns the shorts whose rating does not block their upload
+func UnblockedShorts(shorts []ShortClip) []ShortClip {
+	kept := make([]ShortClip, 0, len(shorts))
+	for _, short := range shorts {
+		if short.Rating != nil && short.Rating.Blocked {
+			LogWarning("Skipping short %q (%s-%s): risk %d/10 blocks its upload", short.ShortTitle, short.StartTime, short.EndTime, short.Rating.Risk)
+			continue
+		}
+		kept = append(kept, short)
+	}
+	return kept
+}
+
+// ShortsSchemaVersion is the version of the shorts file layout written by this release. Version 2
+// spells every field in camelCase and keeps tags as one string; files without a schemaVersion are
+// version 1 and are migrated when they are read.
+const ShortsSchemaVersion = 2
+
 // ShortsData represents the structure of the shorts_suggestions.yaml file
 type ShortsData struct {
-	SourceVideo string      `yaml:"sourceVideo"`
-	Shorts      []ShortClip `yaml:"shorts"`
+	SchemaVersion int         `yaml:"schemaVersion,omitempty"`
+	SourceVideo   string      `yaml:"sourceVideo"`
+	Shorts        []ShortClip `yaml:"shorts"`
+}
+
+// shortsFileKeys are the fields of the top level of a shorts file, by their normalized spelling
+var shortsFileKeys = map[string]string{
+	"schemaversion": "schemaVersion",
+	"sourcevideo":   "sourceVideo",
+	"shorts":        "shorts",
 }
 
-// readShortsFile reads and parses the shorts_suggestions.yaml file
+// shortClipKeys are the fields of a clip, by their normalized spelling. Older files and model
+// responses use short_title, start_time and end_time.
+var shortClipKeys = map[string]string{
+	"title":       "title",
+	"shorttitle":  "shortTitle",
+	"starttime":   "startTime",
+	"endtime":     "endTime",
+	"description": "description",
+	"tags":        "tags",
+	"storyboard":  "storyboard",
+}
+
+// ReadShortsFile reads a shorts file, migrating an older layout to the current one
 func ReadShortsFile(filePath string) (*ShortsData, error) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
@@ -31,13 +87,108 @@ func ReadShortsFile(filePath string) (*ShortsData, error) {
 	}
 
 	var shortsData ShortsData
-	if err := yaml.Unmarshal(data, &shortsData); err != nil {
+	if err := DecodeShorts(data, &shortsData); err != nil {
 		return nil, fmt.Errorf("failed to parse YAML: %w", err)
 	}
 
 	return &shortsData, nil
 }
 
+// DecodeShorts decodes a shorts file into out after migrating it to the current layout, so every
+// step reads the same field names whichever release or model wrote the file
+func DecodeShorts(data []byte, out interface{}) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	if _, err := MigrateShorts(doc.Content[0]); err != nil {
+		return err
+	}
+	return doc.Content[0].Decode(out)
+}
+
+// MigrateShorts brings the top-level mapping of a shorts file to the current schema version in
+// place: legacy spellings such as short_title become shortTitle and tag lists become one string.
+// It reports whether anything changed, and rejects files written by a newer release.
+func MigrateShorts(root *yaml.Node) (bool, error) {
+	if root.Kind != yaml.MappingNode {
+		return false, nil
+	}
+
+	version := 1
+	if v := MappingValue(root, "schemaVersion"); v != nil {
+		if err := v.Decode(&version); err != nil {
+			return false, fmt.Errorf("invalid schemaVersion %q", v.Value)
+		}
+	}
+	if version > ShortsSchemaVersion {
+		return false, fmt.Errorf("shorts file has schemaVersion %d, but this release reads up to %d; update StudioFlowAI", version, ShortsSchemaVersion)
+	}
+
+	changed := renameKeys(root, shortsFileKeys)
+	if shorts := MappingValue(root, "shorts"); shorts != nil && shorts.Kind == yaml.SequenceNode {
+		for _, clip := range shorts.Content {
+			if clip.Kind != yaml.MappingNode {
+				continue
+			}
+			if renameKeys(clip, shortClipKeys) {
+				changed = true
+			}
+			if tags := MappingValue(clip, "tags"); tags != nil && tags.Kind == yaml.SequenceNode {
+				values := make([]string, 0, len(tags.Content))
+				for _, tag := range tags.Content {
+					values = append(values, tag.Value)
+				}
+				SetMappingValue(clip, "tags", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: strings.Join(values, " ")})
+				changed = true
+			}
+		}
+	}
+
+	if version < ShortsSchemaVersion {
+		changed = true
+	}
+	versionNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: fmt.Sprint(ShortsSchemaVersion)}
+	if v := MappingValue(root, "schemaVersion"); v != nil {
+		*v = *versionNode
+	} else {
+		// The version goes first, where a reader looks for it
+		key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "schemaVersion"}
+		root.Content = append([]*yaml.Node{key, versionNode}, root.Content...)
+	}
+	return changed, nil
+}
+
+// renameKeys gives the keys of a mapping their canonical spelling. A key already present in its
+// canonical spelling wins over a legacy one, which is dropped.
+func renameKeys(node *yaml.Node, canonical map[string]string) bool {
+	present := make(map[string]bool)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		present[node.Content[i].Value] = true
+	}
+
+	changed := false
+	content := node.Content[:0]
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		normalized := strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key.Value))
+		if name, ok := canonical[normalized]; ok && name != key.Value {
+			changed = true
+			if present[name] {
+				continue
+			}
+			key.Value = name
+			present[name] = true
+		}
+		content = append(content, key, value)
+	}
+	node.Content = content
+	return changed
+}
+
 // listShorts lists available shorts that can be uploaded
 func ListShorts(shortsData *ShortsData) error {
 	LogInfo("Available shorts for upload:")
@@ -46,7 +197,91 @@ func ListShorts(shortsData *ShortsData) error {
 		LogInfo("   Duration: %s - %s", short.StartTime, short.EndTime)
 		LogInfo("   Description: %s", short.Description)
 		LogInfo("   Tags: %s", short.Tags)
+		if short.Storyboard != "" {
+			LogInfo("   Storyboard: %s", short.Storyboard)
+		}
 		LogInfo("---")
 	}
 	return nil
 }
+
+// ShortsDocument is a shorts file loaded as a YAML node tree, so steps can annotate or
+// reorder clips without dropping fields added by other steps
+type ShortsDocument struct {
+	doc      yaml.Node
+	Root     *yaml.Node // Top-level mapping
+	Shorts   *yaml.Node // Sequence of clip mappings
+	Migrated bool       // The file was in an older layout and was brought to the current one
+}
+
+// ReadShortsDocument reads a shorts file that must contain at least one clip
+func ReadShortsDocument(filePath string) (*ShortsDocument, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shorts file: %w", err)
+	}
+
+	d := &ShortsDocument{}
+	if err := yaml.Unmarshal(data, &d.doc); err != nil {
+		return nil, fmt.Errorf("failed to parse shorts file: %w", err)
+	}
+	if len(d.doc.Content) == 0 || d.doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("shorts file %s is not a YAML mapping", filePath)
+	}
+	d.Root = d.doc.Content[0]
+	if d.Migrated, err = MigrateShorts(d.Root); err != nil {
+		return nil, fmt.Errorf("shorts file %s: %w", filePath, err)
+	}
+	d.Shorts = MappingValue(d.Root, "shorts")
+	if d.Shorts == nil || d.Shorts.Kind != yaml.SequenceNode || len(d.Shorts.Content) == 0 {
+		return nil, fmt.Errorf("shorts file %s contains no clips", filePath)
+	}
+	for i, clip := range d.Shorts.Content {
+		if clip.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("clip %d is not a YAML mapping", i+1)
+		}
+	}
+	return d, nil
+}
+
+// SourceVideo returns the sourceVideo field, or "" when it is missing or still a placeholder
+func (d *ShortsDocument) SourceVideo() string {
+	if v := MappingValue(d.Root, "sourceVideo"); v != nil && !strings.Contains(v.Value, "${") {
+		return v.Value
+	}
+	return ""
+}
+
+// Marshal encodes the document back to YAML
+func (d *ShortsDocument) Marshal() ([]byte, error) {
+	return yaml.Marshal(&d.doc)
+}
+
+// ClipField returns a scalar field of a clip mapping, or ""
+func ClipField(clip *yaml.Node, key string) string {
+	if v := MappingValue(clip, key); v != nil {
+		return v.Value
+	}
+	return ""
+}
+
+// MappingValue returns the value of key in a YAML mapping node, or nil
+func MappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// SetMappingValue replaces or appends key in a YAML mapping node
+func SetMappingValue(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeShorts_MigratesLegacyFields(t *testing.T) {
+	legacy := `source_video: talk.mp4
+shorts:
+  - title: "Why Go?"
+    start_time: "00:01:00"
+    end_time: "00:01:45"
+    short_title: "Go in 45s"
+    tags: ["#go", "#dev"]
+`
+	var data ShortsData
+	require.NoError(t, DecodeShorts([]byte(legacy), &data))
+
+	assert.Equal(t, ShortsSchemaVersion, data.SchemaVersion)
+	assert.Equal(t, "talk.mp4", data.SourceVideo)
+	require.Len(t, data.Shorts, 1)
+	clip := data.Shorts[0]
+	assert.Equal(t, "00:01:00", clip.StartTime)
+	assert.Equal(t, "00:01:45", clip.EndTime)
+	assert.Equal(t, "Go in 45s", clip.ShortTitle)
+	assert.Equal(t, "#go #dev", clip.Tags)
+}
+
+func TestDecodeShorts_CanonicalFieldWins(t *testing.T) {
+	var data ShortsData
+	require.NoError(t, DecodeShorts([]byte("shorts:\n  - shortTitle: kept\n    short_title: dropped\n"), &data))
+	require.Len(t, data.Shorts, 1)
+	assert.Equal(t, "kept", data.Shorts[0].ShortTitle)
+}
+
+func TestDecodeShorts_RejectsNewerVersion(t *testing.T) {
+	var data ShortsData
+	err := DecodeShorts([]byte("schemaVersion: 99\nshorts: []\n"), &data)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "schemaVersion 99")
+}
+
+func TestReadShortsDocument_Migrated(t *testing.T) {
+	dir := t.TempDir()
+	legacy := filepath.Join(dir, "legacy.yaml")
+	require.NoError(t, os.WriteFile(legacy, []byte("sourceVideo: talk.mp4\nshorts:\n  - title: A\n    short_title: Short A\n    review: keep me\n"), 0644))
+
+	doc, err := ReadShortsDocument(legacy)
+	require.NoError(t, err)
+	assert.True(t, doc.Migrated)
+	assert.Equal(t, "Short A", ClipField(doc.Shorts.Content[0], "shortTitle"))
+	assert.Equal(t, "keep me", ClipField(doc.Shorts.Content[0], "review"))
+
+	out, err := doc.Marshal()
+	require.NoError(t, err)
+	current := filepath.Join(dir, "current.yaml")
+	require.NoError(t, os.WriteFile(current, out, 0644))
+
+	doc, err = ReadShortsDocument(current)
+	require.NoError(t, err)
+	assert.False(t, doc.Migrated)
+}
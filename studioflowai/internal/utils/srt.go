@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SubtitleCue is a single timed block of an SRT file
+type SubtitleCue struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+// ParseSRT parses the cues of an SRT file, skipping blocks without a valid timing line.
+// It returns an error when the content has no cues at all.
+func ParseSRT(content string) ([]SubtitleCue, error) {
+	content = strings.TrimPrefix(content, "\ufeff")
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	var cues []SubtitleCue
+	for _, block := range strings.Split(content, "\n\n") {
+		lines := strings.Split(strings.TrimSpace(block), "\n")
+		for i, line := range lines {
+			start, end, ok := strings.Cut(line, "-->")
+			if !ok {
+				continue
+			}
+			// Drop position settings some tools add after the end time
+			if fields := strings.Fields(end); len(fields) > 0 {
+				end = fields[0]
+			}
+			startTime, err := ParseTimestamp(start)
+			if err != nil {
+				break
+			}
+			endTime, err := ParseTimestamp(end)
+			if err != nil || endTime < startTime {
+				break
+			}
+			cues = append(cues, SubtitleCue{
+				Start: startTime,
+				End:   endTime,
+				Text:  strings.TrimSpace(strings.Join(lines[i+1:], " ")),
+			})
+			break
+		}
+	}
+
+	if len(cues) == 0 {
+		return nil, fmt.Errorf("no subtitle cues found")
+	}
+	return cues, nil
+}
+
+// SubtitleDuration returns the end time of the last cue, the length of the subtitled video
+func SubtitleDuration(cues []SubtitleCue) time.Duration {
+	var duration time.Duration
+	for _, cue := range cues {
+		duration = max(duration, cue.End)
+	}
+	return duration
+}
+
+// CueRange is the span of SRT cues a clip covers, numbered from 1 in file order
+type CueRange struct {
+	First int `yaml:"first"`
+	Last  int `yaml:"last"`
+}
+
+// CueExcerpt returns the text of the cues overlapping start to end and their range. ok is false
+// when no cue overlaps.
+func CueExcerpt(cues []SubtitleCue, start, end time.Duration) (excerpt string, cueRange CueRange, ok bool) {
+	var text []string
+	for i, cue := range cues {
+		if cue.End <= start || cue.Start >= end {
+			continue
+		}
+		if !ok {
+			cueRange.First = i + 1
+			ok = true
+		}
+		cueRange.Last = i + 1
+		if cue.Text != "" {
+			text = append(text, cue.Text)
+		}
+	}
+	return strings.Join(text, " "), cueRange, ok
+}
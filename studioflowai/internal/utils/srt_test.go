@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSRT(t *testing.T) {
+	content := "\ufeff1\r\n00:00:01,000 --> 00:00:04,500\r\nHello and welcome\r\nto the show.\r\n\r\n" +
+		"2\n00:01:05,250 --> 00:01:09,000 X1:40 X2:600\nFirst topic.\n\n" +
+		"3\nnot a timing line\nIgnored.\n"
+
+	cues, err := ParseSRT(content)
+	require.NoError(t, err)
+	require.Len(t, cues, 2)
+	assert.Equal(t, SubtitleCue{Start: time.Second, End: 4500 * time.Millisecond, Text: "Hello and welcome to the show."}, cues[0])
+	assert.Equal(t, 65250*time.Millisecond, cues[1].Start)
+	assert.Equal(t, 69*time.Second, SubtitleDuration(cues))
+
+	_, err = ParseSRT("Just a plain transcript.")
+	assert.ErrorContains(t, err, "no subtitle cues found")
+}
+
+func TestCueExcerpt(t *testing.T) {
+	cues := []SubtitleCue{
+		{Start: 0, End: 5 * time.Second, Text: "Intro."},
+		{Start: 5 * time.Second, End: 10 * time.Second, Text: "First point."},
+		{Start: 10 * time.Second, End: 15 * time.Second, Text: "Second point."},
+		{Start: 15 * time.Second, End: 20 * time.Second, Text: "Outro."},
+	}
+
+	excerpt, cueRange, ok := CueExcerpt(cues, 5*time.Second, 15*time.Second)
+	require.True(t, ok)
+	assert.Equal(t, "First point. Second point.", excerpt)
+	assert.Equal(t, CueRange{First: 2, Last: 3}, cueRange)
+
+	// A clip starting or ending inside a cue includes it
+	excerpt, cueRange, ok = CueExcerpt(cues, 12*time.Second, 16*time.Second)
+	require.True(t, ok)
+	assert.Equal(t, "Second point. Outro.", excerpt)
+	assert.Equal(t, CueRange{First: 3, Last: 4}, cueRange)
+
+	_, _, ok = CueExcerpt(cues, 30*time.Second, 40*time.Second)
+	assert.False(t, ok)
+}
@@ -0,0 +1,238 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// StepLogDirName is the folder of the run's output that receives the log of each step
+const StepLogDirName = "logs"
+
+// combinedLogName is the file of the step log folder that receives the messages of every step
+const combinedLogName = "workflow.log"
+
+// stepColors are the colors of step prefixes, picked by step name so a step keeps its color
+var stepColors = []string{CyanColor, MagentaColor, GreenColor, BlueColor, YellowColor}
+
+// ansiCode matches terminal color codes, which are left out of log files
+var ansiCode = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// unsafeFileChars matches the characters of a step name that are replaced in its log file name
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// stepLog holds where the messages of steps go besides the terminal. Every message is written whole
+// under its lock, so lines of clips and steps running at once never interleave.
+var stepLog = struct {
+	mu       sync.Mutex
+	prefix   bool
+	color    bool
+	perStep  bool
+	dir      string
+	combined *os.File
+	files    map[string]*os.File
+}{color: true}
+
+// SetLogPrefix prefixes every log line with the name of the step writing it, colored per step
+// when color is true
+func SetLogPrefix(prefix, color bool) {
+	stepLog.mu.Lock()
+	defer stepLog.mu.Unlock()
+	stepLog.prefix = prefix
+	stepLog.color = color
+}
+
+// SetStepLogFiles asks workflows to write the log of each step to the logs folder of their run
+func SetStepLogFiles(on bool) {
+	stepLog.mu.Lock()
+	defer stepLog.mu.Unlock()
+	stepLog.perStep = on
+}
+
+// StepLogFiles reports whether workflows write the log of each step to the logs folder of their run
+func StepLogFiles() bool {
+	stepLog.mu.Lock()
+	defer stepLog.mu.Unlock()
+	return stepLog.perStep
+}
+
+// logStepKey is the context key of the step whose messages are logged
+type logStepKey struct{}
+
+// logStepContext is the step of a context and the writer its messages are forwarded to, if any
+type logStepContext struct {
+	step    string
+	forward io.Writer
+}
+
+// WithLogStep returns a context whose messages, logged with the Context log functions, belong to
+// step: they are prefixed with its name and written to its log file
+func WithLogStep(ctx context.Context, step string) context.Context {
+	logCtx := logStepFrom(ctx)
+	logCtx.step = step
+	return context.WithValue(ctx, logStepKey{}, logCtx)
+}
+
+// WithLogForward returns a context whose messages other than errors are also written to w, without
+// the step prefix, e.g. to the client a worker runs the step for
+func WithLogForward(ctx context.Context, w io.Writer) context.Context {
+	logCtx := logStepFrom(ctx)
+	logCtx.forward = w
+	return context.WithValue(ctx, logStepKey{}, logCtx)
+}
+
+// logStepFrom returns the step of a context; messages logged without one belong to no step
+func logStepFrom(ctx context.Context) logStepContext {
+	if ctx == nil {
+		return logStepContext{}
+	}
+	logCtx, _ := ctx.Value(logStepKey{}).(logStepContext)
+	return logCtx
+}
+
+// OpenStepLogs also writes the messages of each step to <dir>/<step>.log, and those of every step
+// to <dir>/workflow.log, without colors. Files of a retried run are appended to.
+func OpenStepLogs(dir string) error {
+	if err := EnsureDir(dir); err != nil {
+		return fmt.Errorf("failed to create log folder: %w", err)
+	}
+	combined, err := os.OpenFile(filepath.Join(dir, combinedLogName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open workflow log: %w", err)
+	}
+
+	stepLog.mu.Lock()
+	defer stepLog.mu.Unlock()
+	closeStepLogs()
+	stepLog.dir = dir
+	stepLog.combined = combined
+	stepLog.files = make(map[string]*os.File)
+	return nil
+}
+
+// CloseStepLogs stops writing messages to the step log files
+func CloseStepLogs() {
+	stepLog.mu.Lock()
+	defer stepLog.mu.Unlock()
+	closeStepLogs()
+}
+
+// closeStepLogs closes the step log files; the caller holds the lock
+func closeStepLogs() {
+	if stepLog.combined != nil {
+		_ = stepLog.combined.Close()
+	}
+	for _, f := range stepLog.files {
+		_ = f.Close()
+	}
+	stepLog.dir = ""
+	stepLog.combined = nil
+	stepLog.files = nil
+}
+
+// StepLogFileName returns the name of the log file of a step
+func StepLogFileName(step string) string {
+	name := strings.Trim(unsafeFileChars.ReplaceAllString(step, "_"), "_")
+	if name == "" {
+		name = "step"
+	}
+	return name + ".log"
+}
+
+// writeLog writes a message of one or more lines to w, prefixed with the step of ctx, and to the
+// step log files. When forward is true it also goes to the writer the step forwards its log to.
+func writeLog(ctx context.Context, w io.Writer, message string, forward bool) {
+	logCtx := logStepFrom(ctx)
+	if !forward {
+		logCtx.forward = nil
+	}
+	stepLog.mu.Lock()
+	defer stepLog.mu.Unlock()
+	writeLogLocked(w, logCtx.step, logCtx.forward, message)
+}
+
+// writeLogLocked writes a message for step; the caller holds the lock
+func writeLogLocked(w io.Writer, step string, forward io.Writer, message string) {
+	lines := strings.Split(strings.TrimSuffix(message, "\n"), "\n")
+	if forward != nil {
+		_, _ = io.WriteString(forward, strings.Join(lines, "\n")+"\n")
+	}
+
+	if stepLog.prefix && step != "" {
+		prefix := "[" + step + "] "
+		if stepLog.color {
+			prefix = ColoredText(prefix, stepColor(step))
+		}
+		_, _ = io.WriteString(w, prefix+strings.Join(lines, "\n"+prefix)+"\n")
+	} else {
+		_, _ = io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	}
+
+	if stepLog.combined == nil {
+		return
+	}
+	plain := make([]string, len(lines))
+	for i, line := range lines {
+		plain[i] = ansiCode.ReplaceAllString(line, "")
+	}
+	if step == "" {
+		_, _ = io.WriteString(stepLog.combined, strings.Join(plain, "\n")+"\n")
+		return
+	}
+	_, _ = io.WriteString(stepLog.combined, "["+step+"] "+strings.Join(plain, "\n["+step+"] ")+"\n")
+	if f := stepLogFile(step); f != nil {
+		_, _ = io.WriteString(f, strings.Join(plain, "\n")+"\n")
+	}
+}
+
+// stepLogFile returns the open log file of a step, opening it on its first message; the caller
+// holds the lock
+func stepLogFile(step string) *os.File {
+	if f, ok := stepLog.files[step]; ok {
+		return f
+	}
+	f, err := os.OpenFile(filepath.Join(stepLog.dir, StepLogFileName(step)), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", Warning(fmt.Sprintf("Messages of step %s are not written to its log file: %v", step, err)))
+	}
+	// A file that failed to open is remembered as nil so the warning is shown once
+	stepLog.files[step] = f
+	return f
+}
+
+// stepColor returns the color of a step's prefix
+func stepColor(step string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(step))
+	return stepColors[h.Sum32()%uint32(len(stepColors))]
+}
+
+// stepLogWriter passes the output of external tools through the step log, a line at a time
+type stepLogWriter struct {
+	w       io.Writer
+	step    string
+	forward io.Writer
+	partial []byte
+}
+
+// Write writes the complete lines of p and keeps the rest for the next write
+func (s *stepLogWriter) Write(p []byte) (int, error) {
+	s.partial = append(s.partial, p...)
+	i := strings.LastIndexByte(string(s.partial), '\n')
+	if i < 0 {
+		return len(p), nil
+	}
+	lines := string(s.partial[:i+1])
+	s.partial = append(s.partial[:0], s.partial[i+1:]...)
+
+	stepLog.mu.Lock()
+	defer stepLog.mu.Unlock()
+	writeLogLocked(s.w, s.step, s.forward, lines)
+	return len(p), nil
+}
@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureLog sends log messages to a buffer for the duration of the test
+func captureLog(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	SetLogOutput(&buf)
+	t.Cleanup(func() {
+		SetLogOutput(nil)
+		SetLogPrefix(false, true)
+		CloseStepLogs()
+	})
+	return &buf
+}
+
+func TestStepLogPrefix(t *testing.T) {
+	buf := captureLog(t)
+
+	LogInfo("Before any step")
+	SetLogPrefix(true, false)
+	ctx := WithLogStep(context.Background(), "Transcribe")
+	LogWarningContext(ctx, "Two\nlines")
+	w := LogWriter(ctx)
+	fmt.Fprint(w, "tool output\nsecond ")
+	fmt.Fprint(w, "line\n")
+
+	out := ansiCode.ReplaceAllString(buf.String(), "")
+	assert.Equal(t, "Before any step\n[Transcribe] Two\n[Transcribe] lines\n[Transcribe] tool output\n[Transcribe] second line\n", out)
+
+	// Each step keeps its color
+	buf.Reset()
+	SetLogPrefix(true, true)
+	LogInfoContext(ctx, "colored")
+	assert.True(t, strings.HasPrefix(buf.String(), ColoredText("[Transcribe] ", stepColor("Transcribe"))))
+}
+
+func TestStepLogFiles(t *testing.T) {
+	buf := captureLog(t)
+	dir := filepath.Join(t.TempDir(), StepLogDirName)
+	require.NoError(t, OpenStepLogs(dir))
+
+	LogInfoContext(WithLogStep(context.Background(), "Extract Audio"), "extracting")
+
+	// Messages of steps written at once are never mixed and each goes to the file of its step
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			step := "Transcribe"
+			if i%2 == 1 {
+				step = "Suggest Shorts"
+			}
+			LogInfoContext(WithLogStep(context.Background(), step), "segment %d", i)
+		}(i)
+	}
+	wg.Wait()
+	LogInfo("done")
+	CloseStepLogs()
+
+	audio, err := os.ReadFile(filepath.Join(dir, "Extract_Audio.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "extracting\n", string(audio))
+
+	transcribe, err := os.ReadFile(filepath.Join(dir, "Transcribe.log"))
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(string(transcribe)), "\n"), 10)
+	assert.NotContains(t, string(transcribe), "\x1b[", "log files have no colors")
+	shorts, err := os.ReadFile(filepath.Join(dir, "Suggest_Shorts.log"))
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(string(shorts)), "\n"), 10)
+
+	combined, err := os.ReadFile(filepath.Join(dir, combinedLogName))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(combined)), "\n")
+	require.Len(t, lines, 22)
+	assert.Equal(t, "[Extract Audio] extracting", lines[0])
+	assert.Regexp(t, `^\[(Transcribe|Suggest Shorts)\] segment \d+$`, lines[1])
+	assert.Equal(t, "done", lines[21])
+
+	// The terminal keeps its output without prefixes
+	assert.Len(t, strings.Split(strings.TrimSpace(buf.String()), "\n"), 22)
+}
+
+func TestLogForward(t *testing.T) {
+	buf := captureLog(t)
+	SetLogPrefix(true, false)
+
+	var transcribe, shorts bytes.Buffer
+	transcribeCtx := WithLogForward(WithLogStep(context.Background(), "Transcribe"), &transcribe)
+	shortsCtx := WithLogForward(WithLogStep(context.Background(), "Suggest Shorts"), &shorts)
+	LogInfoContext(transcribeCtx, "segment 1")
+	LogInfoContext(shortsCtx, "clip 1")
+	fmt.Fprint(LogWriter(transcribeCtx), "tool output\n")
+	LogErrorContext(shortsCtx, "returned with the step")
+	LogInfo("outside any step")
+
+	// Each step gets its own lines, without the prefix the terminal shows
+	assert.Equal(t, "segment 1\ntool output\n", ansiCode.ReplaceAllString(transcribe.String(), ""))
+	assert.Equal(t, "clip 1\n", ansiCode.ReplaceAllString(shorts.String(), ""))
+	assert.Equal(t, "[Transcribe] segment 1\n[Suggest Shorts] clip 1\n[Transcribe] tool output\noutside any step\n",
+		ansiCode.ReplaceAllString(buf.String(), ""))
+}
+
+func TestStepLogFileName(t *testing.T) {
+	assert.Equal(t, "Extract_Audio.log", StepLogFileName("Extract Audio"))
+	assert.Equal(t, "clips_1.log", StepLogFileName("clips/1"))
+	assert.Equal(t, "step.log", StepLogFileName("/"))
+}
@@ -0,0 +1,173 @@
+package utils
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TempDirPrefix starts the name of every temporary folder, so concurrent runs never share one
+// and crashed runs leave folders that can be recognized as theirs
+const TempDirPrefix = "studioflowai-tmp-"
+
+// tempOwnerFile records the host and process that created a temporary folder
+const tempOwnerFile = ".owner"
+
+// legacyTempDirs are fixed-name temporary folders of earlier versions, left in run folders by crashes
+var legacyTempDirs = []string{"temp_transcribe"}
+
+// legacyTempPrefixes are names of temporary folders earlier versions created in the system temp directory
+var legacyTempPrefixes = []string{"studioflowai-detect-", "studioflowai-validate-", "studioflowai-faces-", "studioflowai-module-"}
+
+// MakeTempDir creates a temporary folder for purpose in parent, or in the system temp directory
+// when parent is empty. The name is unique per run and the folder records its owner, so
+// FindOrphanTempDirs can tell when the run that made it is gone.
+func MakeTempDir(parent, purpose string) (string, error) {
+	if parent == "" {
+		parent = os.TempDir()
+	} else if err := EnsureDir(parent); err != nil {
+		return "", err
+	}
+	dir, err := os.MkdirTemp(parent, fmt.Sprintf("%s%s-%d-", TempDirPrefix, purpose, os.Getpid()))
+	if err != nil {
+		return "", err
+	}
+	host, _ := os.Hostname()
+	owner := fmt.Sprintf("%s %d\n", host, os.Getpid())
+	if err := os.WriteFile(filepath.Join(dir, tempOwnerFile), []byte(owner), 0600); err != nil {
+		_ = os.RemoveAll(dir)
+		return "", err
+	}
+	return dir, nil
+}
+
+// OrphanTempDir is a temporary folder whose run is gone
+type OrphanTempDir struct {
+	Path   string
+	Reason string
+	Size   int64
+}
+
+// FindOrphanTempDirs lists the temporary folders left behind by runs that are gone: the top level
+// of the system temp directory and every folder below roots are searched. A folder made on this
+// host is orphaned when its process no longer runs. Folders of other hosts and of earlier versions
+// do not say whether their run is alive, so they are orphaned only once untouched for maxAge.
+func FindOrphanTempDirs(roots []string, maxAge time.Duration) ([]OrphanTempDir, error) {
+	var orphans []OrphanTempDir
+	seen := make(map[string]bool)
+	add := func(path string, legacy bool) {
+		if seen[path] {
+			return
+		}
+		seen[path] = true
+		if reason := orphanReason(path, legacy, maxAge); reason != "" {
+			orphans = append(orphans, OrphanTempDir{Path: path, Reason: reason, Size: dirSize(path)})
+		}
+	}
+
+	systemTemp := os.TempDir()
+	entries, err := os.ReadDir(systemTemp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", systemTemp, err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		if strings.HasPrefix(name, TempDirPrefix) {
+			add(filepath.Join(systemTemp, name), false)
+		} else if hasAnyPrefix(name, legacyTempPrefixes) {
+			add(filepath.Join(systemTemp, name), true)
+		}
+	}
+
+	for _, root := range roots {
+		if root == "" {
+			continue
+		}
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				// Unreadable folders cannot hold anything we could remove
+				if path == root {
+					return err
+				}
+				return nil
+			}
+			if !d.IsDir() || path == root {
+				return nil
+			}
+			name := d.Name()
+			switch {
+			case strings.HasPrefix(name, TempDirPrefix):
+				add(path, false)
+			case containsString(legacyTempDirs, name):
+				add(path, true)
+			default:
+				return nil
+			}
+			return filepath.SkipDir
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to search %s: %w", root, err)
+		}
+	}
+	return orphans, nil
+}
+
+// orphanReason explains why a temporary folder is orphaned, or returns "" when its run may be alive
+func orphanReason(path string, legacy bool, maxAge time.Duration) string {
+	info, err := os.Lstat(path)
+	if err != nil || !info.IsDir() {
+		return ""
+	}
+	age := time.Since(info.ModTime())
+	stale := fmt.Sprintf("untouched for %s", age.Round(time.Minute))
+
+	if !legacy {
+		data, err := os.ReadFile(filepath.Join(path, tempOwnerFile))
+		if err == nil {
+			fields := strings.Fields(string(data))
+			host, _ := os.Hostname()
+			if len(fields) == 2 && fields[0] == host {
+				pid, err := strconv.Atoi(fields[1])
+				if err == nil && !processRunning(pid) {
+					return fmt.Sprintf("process %d is no longer running", pid)
+				}
+				return ""
+			}
+		}
+	}
+	if age >= maxAge {
+		return stale
+	}
+	return ""
+}
+
+// dirSize returns the total size of the files below path
+func dirSize(path string) int64 {
+	var size int64
+	_ = filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
+
+// hasAnyPrefix reports whether s starts with one of prefixes
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMakeTempDir(t *testing.T) {
+	parent := t.TempDir()
+
+	first, err := MakeTempDir(parent, "transcribe")
+	require.NoError(t, err)
+	second, err := MakeTempDir(parent, "transcribe")
+	require.NoError(t, err)
+
+	assert.NotEqual(t, first, second, "concurrent runs get their own folder")
+	assert.True(t, strings.HasPrefix(filepath.Base(first), fmt.Sprintf("%stranscribe-%d-", TempDirPrefix, os.Getpid())))
+	owner, err := os.ReadFile(filepath.Join(first, tempOwnerFile))
+	require.NoError(t, err)
+	assert.Contains(t, string(owner), fmt.Sprint(os.Getpid()))
+}
+
+func TestFindOrphanTempDirs(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	root := t.TempDir()
+	host, _ := os.Hostname()
+	old := time.Now().Add(-48 * time.Hour)
+
+	// makeDir creates a temporary folder with the given owner, or none when owner is empty
+	makeDir := func(path, owner string, modTime time.Time) string {
+		require.NoError(t, os.MkdirAll(path, 0755))
+		if owner != "" {
+			require.NoError(t, os.WriteFile(filepath.Join(path, tempOwnerFile), []byte(owner), 0600))
+		}
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+		return path
+	}
+
+	live, err := MakeTempDir(filepath.Join(root, "run-1"), "transcribe")
+	require.NoError(t, err)
+	crashed := makeDir(filepath.Join(root, "run-2", TempDirPrefix+"transcribe-99999999-1"), host+" 99999999\n", time.Now())
+	otherHostRecent := makeDir(filepath.Join(root, "run-3", TempDirPrefix+"transcribe-1-1"), "elsewhere 1\n", time.Now())
+	otherHostOld := makeDir(filepath.Join(root, "run-4", TempDirPrefix+"transcribe-1-2"), "elsewhere 1\n", old)
+	legacyOld := makeDir(filepath.Join(root, "run-5", "temp_transcribe"), "", old)
+	legacyRecent := makeDir(filepath.Join(root, "run-6", "temp_transcribe"), "", time.Now())
+	systemLegacy := makeDir(filepath.Join(os.TempDir(), "studioflowai-faces-123"), "", old)
+	systemCrashed := makeDir(filepath.Join(os.TempDir(), TempDirPrefix+"module-99999999-1"), host+" 99999999\n", time.Now())
+	unrelated := makeDir(filepath.Join(os.TempDir(), "studioflowai-checkout"), "", old)
+
+	orphans, err := FindOrphanTempDirs([]string{root}, 24*time.Hour)
+	require.NoError(t, err)
+
+	var paths []string
+	for _, orphan := range orphans {
+		paths = append(paths, orphan.Path)
+	}
+	assert.ElementsMatch(t, []string{crashed, otherHostOld, legacyOld, systemLegacy, systemCrashed}, paths)
+	for _, kept := range []string{live, otherHostRecent, legacyRecent, unrelated} {
+		assert.NotContains(t, paths, kept)
+	}
+	for _, orphan := range orphans {
+		if orphan.Path == crashed {
+			assert.Contains(t, orphan.Reason, "no longer running")
+			assert.Positive(t, orphan.Size)
+		}
+	}
+}
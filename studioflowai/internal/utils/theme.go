@@ -0,0 +1,255 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ThemeHeight is the frame height theme sizes are given for: a vertical 1080x1920 short
+const ThemeHeight = 1920
+
+// assDefaultPlayResY is the script height libass assumes for subtitles without one, such as SRT files
+const assDefaultPlayResY = 288
+
+// Theme is the brand typography shared by the subtitle and title rendering steps. It is read from
+// the Default style of an .ass file or from a theme YAML file; sizes are pixels at ThemeHeight.
+type Theme struct {
+	FontName     string  `yaml:"fontName"`     // Font family of burned subtitles
+	FontFile     string  `yaml:"fontFile"`     // Font file of drawn titles, relative to the theme file
+	FontSize     int     `yaml:"fontSize"`     // Text size in pixels
+	PrimaryColor string  `yaml:"primaryColor"` // Text color: a name, #RRGGBB or &HAABBGGRR, with an optional @opacity
+	OutlineColor string  `yaml:"outlineColor"` // Color of the text outline
+	BoxColor     string  `yaml:"boxColor"`     // Color of the shadow or box behind the text
+	Bold         bool    `yaml:"bold"`
+	Outline      float64 `yaml:"outline"` // Outline width in pixels
+	Shadow       float64 `yaml:"shadow"`  // Shadow distance in pixels
+	MarginV      int     `yaml:"marginV"` // Distance of subtitles from the bottom edge in pixels
+}
+
+// LoadTheme reads a theme from an .ass or .ssa subtitle file, using its Default style or else its
+// first one, or from a .yaml theme file
+func LoadTheme(path string) (*Theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read theme: %w", err)
+	}
+
+	var theme *Theme
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ass", ".ssa":
+		theme, err = parseASSTheme(string(data))
+	case ".yaml", ".yml":
+		theme = &Theme{}
+		err = yaml.Unmarshal(data, theme)
+	default:
+		return nil, fmt.Errorf("theme %s must be an .ass or .yaml file", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid theme %s: %w", path, err)
+	}
+
+	for name, color := range map[string]string{"primaryColor": theme.PrimaryColor, "outlineColor": theme.OutlineColor, "boxColor": theme.BoxColor} {
+		if _, err := parseThemeColor(color); color != "" && err != nil {
+			return nil, fmt.Errorf("invalid theme %s: %s: %w", path, name, err)
+		}
+	}
+	if theme.FontFile != "" && !filepath.IsAbs(theme.FontFile) {
+		theme.FontFile = filepath.Join(filepath.Dir(path), theme.FontFile)
+	}
+	return theme, nil
+}
+
+// parseASSTheme reads the Default style, or the first one, of an ASS script, scaling its sizes
+// from the script's PlayResY to ThemeHeight
+func parseASSTheme(script string) (*Theme, error) {
+	var (
+		section string
+		format  []string
+		styles  [][]string
+	)
+	playResY := assDefaultPlayResY
+	scanner := bufio.NewScanner(strings.NewReader(script))
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if strings.HasPrefix(line, "[") {
+			section = strings.ToLower(line)
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch {
+		case section == "[script info]" && key == "PlayResY":
+			if n, err := strconv.Atoi(value); err == nil && n > 0 {
+				playResY = n
+			}
+		case (section == "[v4+ styles]" || section == "[v4 styles]") && key == "Format":
+			format = splitASSFields(value, -1)
+		case (section == "[v4+ styles]" || section == "[v4 styles]") && key == "Style":
+			styles = append(styles, splitASSFields(value, len(format)))
+		}
+	}
+	if len(format) == 0 || len(styles) == 0 {
+		return nil, fmt.Errorf("no styles found")
+	}
+
+	style := styles[0]
+	for _, s := range styles {
+		if len(s) > 0 && s[0] == "Default" {
+			style = s
+			break
+		}
+	}
+	field := func(name string) string {
+		for i, f := range format {
+			if strings.EqualFold(f, name) && i < len(style) {
+				return style[i]
+			}
+		}
+		return ""
+	}
+	number := func(name string) float64 {
+		n, _ := strconv.ParseFloat(field(name), 64)
+		return n * ThemeHeight / float64(playResY)
+	}
+
+	return &Theme{
+		FontName:     field("Fontname"),
+		FontSize:     int(math.Round(number("Fontsize"))),
+		PrimaryColor: field("PrimaryColour"),
+		OutlineColor: field("OutlineColour"),
+		BoxColor:     field("BackColour"),
+		Bold:         field("Bold") != "" && field("Bold") != "0",
+		Outline:      math.Round(number("Outline")*10) / 10,
+		Shadow:       math.Round(number("Shadow")*10) / 10,
+		MarginV:      int(math.Round(number("MarginV"))),
+	}, nil
+}
+
+// splitASSFields splits a comma-separated ASS line into at most n trimmed fields; n < 0 means all
+func splitASSFields(value string, n int) []string {
+	fields := strings.SplitN(value, ",", n)
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	return fields
+}
+
+// ForceStyle returns the theme as the force_style option of FFmpeg's subtitles filter. libass
+// renders SRT files at a script height of 288, so sizes are scaled down from ThemeHeight.
+func (t *Theme) ForceStyle() string {
+	scale := func(v float64) string {
+		return strconv.FormatFloat(math.Round(v*assDefaultPlayResY/ThemeHeight*10)/10, 'f', -1, 64)
+	}
+	var style []string
+	if t.FontName != "" {
+		style = append(style, "FontName="+t.FontName)
+	}
+	if t.FontSize > 0 {
+		style = append(style, "FontSize="+scale(float64(t.FontSize)))
+	}
+	for _, c := range []struct{ key, value string }{
+		{"PrimaryColour", t.PrimaryColor}, {"OutlineColour", t.OutlineColor}, {"BackColour", t.BoxColor},
+	} {
+		if color, err := parseThemeColor(c.value); c.value != "" && err == nil {
+			style = append(style, c.key+"="+color.ass())
+		}
+	}
+	if t.Bold {
+		style = append(style, "Bold=1")
+	}
+	if t.Outline > 0 {
+		style = append(style, "Outline="+scale(t.Outline))
+	}
+	if t.Shadow > 0 {
+		style = append(style, "Shadow="+scale(t.Shadow))
+	}
+	if t.MarginV > 0 {
+		style = append(style, "MarginV="+scale(float64(t.MarginV)))
+	}
+	return strings.Join(style, ",")
+}
+
+// DrawtextColor converts a theme color to FFmpeg's color syntax, as used by drawtext; an empty or
+// invalid color gives ""
+func DrawtextColor(color string) string {
+	c, err := parseThemeColor(color)
+	if color == "" || err != nil {
+		return ""
+	}
+	return c.ffmpeg()
+}
+
+// themeColor is an RGB color with an opacity from 0 (transparent) to 1
+type themeColor struct {
+	r, g, b uint8
+	opacity float64
+}
+
+// namedColors are the color names accepted by themes
+var namedColors = map[string]themeColor{
+	"white":  {255, 255, 255, 1},
+	"black":  {0, 0, 0, 1},
+	"red":    {255, 0, 0, 1},
+	"green":  {0, 128, 0, 1},
+	"blue":   {0, 0, 255, 1},
+	"yellow": {255, 255, 0, 1},
+}
+
+// parseThemeColor reads a color name, #RRGGBB or 0xRRGGBB, optionally followed by @opacity, or
+// an ASS color &HAABBGGRR, whose alpha is a transparency
+func parseThemeColor(s string) (themeColor, error) {
+	s = strings.TrimSpace(s)
+	if rest, ok := strings.CutPrefix(strings.ToUpper(s), "&H"); ok {
+		hex := strings.TrimSuffix(rest, "&")
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil || len(hex) > 8 {
+			return themeColor{}, fmt.Errorf("invalid ASS color %q", s)
+		}
+		alpha := uint8(v >> 24)
+		return themeColor{r: uint8(v), g: uint8(v >> 8), b: uint8(v >> 16), opacity: 1 - float64(alpha)/255}, nil
+	}
+
+	name, opacityText, hasOpacity := strings.Cut(s, "@")
+	opacity := 1.0
+	if hasOpacity {
+		var err error
+		if opacity, err = strconv.ParseFloat(opacityText, 64); err != nil || opacity < 0 || opacity > 1 {
+			return themeColor{}, fmt.Errorf("invalid opacity in color %q", s)
+		}
+	}
+	if c, ok := namedColors[strings.ToLower(name)]; ok {
+		c.opacity = opacity
+		return c, nil
+	}
+	hex := strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(name), "#"), "0x")
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || len(hex) != 6 {
+		return themeColor{}, fmt.Errorf("invalid color %q (use a name, #RRGGBB or &HAABBGGRR)", s)
+	}
+	return themeColor{r: uint8(v >> 16), g: uint8(v >> 8), b: uint8(v), opacity: opacity}, nil
+}
+
+// ass formats the color as &HAABBGGRR
+func (c themeColor) ass() string {
+	alpha := uint8(math.Round((1 - c.opacity) * 255))
+	return fmt.Sprintf("&H%02X%02X%02X%02X", alpha, c.b, c.g, c.r)
+}
+
+// ffmpeg formats the color as 0xRRGGBB@opacity
+func (c themeColor) ffmpeg() string {
+	color := fmt.Sprintf("0x%02X%02X%02X", c.r, c.g, c.b)
+	if c.opacity < 1 {
+		color += "@" + strconv.FormatFloat(math.Round(c.opacity*100)/100, 'f', -1, 64)
+	}
+	return color
+}
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testASSTheme = `[Script Info]
+ScriptType: v4.00+
+PlayResX: 1080
+PlayResY: 960
+
+[V4+ Styles]
+Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding
+Style: Title,Arial,40,&H00FFFFFF,&H000000FF,&H00000000,&H00000000,0,0,0,0,100,100,0,0,1,2,0,8,10,10,10,1
+Style: Default,Montserrat Black,36,&H0000D7FF,&H000000FF,&H00101010,&H80000000,-1,0,0,0,100,100,0,0,1,3,1.5,2,40,40,150,1
+`
+
+func TestLoadTheme(t *testing.T) {
+	dir := t.TempDir()
+
+	// The Default style of an .ass file, with sizes scaled from PlayResY 960 to 1920
+	ass := filepath.Join(dir, "brand.ass")
+	require.NoError(t, os.WriteFile(ass, []byte(testASSTheme), 0644))
+	theme, err := LoadTheme(ass)
+	require.NoError(t, err)
+	assert.Equal(t, &Theme{
+		FontName:     "Montserrat Black",
+		FontSize:     72,
+		PrimaryColor: "&H0000D7FF",
+		OutlineColor: "&H00101010",
+		BoxColor:     "&H80000000",
+		Bold:         true,
+		Outline:      6,
+		Shadow:       3,
+		MarginV:      300,
+	}, theme)
+	assert.Equal(t, "FontName=Montserrat Black,FontSize=10.8,PrimaryColour=&H0000D7FF,OutlineColour=&H00101010,BackColour=&H80000000,Bold=1,Outline=0.9,Shadow=0.5,MarginV=45", theme.ForceStyle())
+	assert.Equal(t, "0xFFD700", DrawtextColor(theme.PrimaryColor))
+	assert.Equal(t, "0x000000@0.5", DrawtextColor(theme.BoxColor))
+
+	// A YAML theme, whose font file is relative to it
+	yamlTheme := filepath.Join(dir, "brand.yaml")
+	require.NoError(t, os.WriteFile(yamlTheme, []byte("fontFile: fonts/Inter.ttf\nfontSize: 64\nprimaryColor: \"#FFD700\"\nboxColor: black@0.6\n"), 0644))
+	theme, err = LoadTheme(yamlTheme)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "fonts", "Inter.ttf"), theme.FontFile)
+	assert.Equal(t, "FontSize=9.6,PrimaryColour=&H0000D7FF,BackColour=&H66000000", theme.ForceStyle())
+
+	// Invalid themes
+	bad := filepath.Join(dir, "bad.yaml")
+	require.NoError(t, os.WriteFile(bad, []byte("primaryColor: gold\n"), 0644))
+	_, err = LoadTheme(bad)
+	assert.ErrorContains(t, err, "primaryColor")
+
+	_, err = LoadTheme(filepath.Join(dir, "brand.json"))
+	assert.Error(t, err)
+
+	empty := filepath.Join(dir, "empty.ass")
+	require.NoError(t, os.WriteFile(empty, []byte("[Script Info]\nPlayResY: 1920\n"), 0644))
+	_, err = LoadTheme(empty)
+	assert.ErrorContains(t, err, "no styles found")
+}
@@ -0,0 +1,255 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// srtTimestampPattern matches an SRT timestamp; hours may have more than two digits so
+// transcripts of long livestream archives keep working
+var srtTimestampPattern = regexp.MustCompile(`^\d{2,}:\d{2}:\d{2},\d{3}$`)
+
+// ParseTimestamp parses a clip timestamp in "hh:mm:ss", "mm:ss" or "ss" form, with an
+// optional fractional part separated by "." or "," (e.g. "00:01:02,500"). Hours are not
+// limited, so media longer than a day can be addressed.
+func ParseTimestamp(timestamp string) (time.Duration, error) {
+	ts := strings.TrimSpace(timestamp)
+	if ts == "" {
+		return 0, fmt.Errorf("empty timestamp")
+	}
+
+	ts = strings.Replace(ts, ",", ".", 1)
+	parts := strings.Split(ts, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("invalid timestamp format: %s (expected HH:MM:SS)", timestamp)
+	}
+
+	var total float64
+	for i, part := range parts {
+		value, err := strconv.ParseFloat(part, 64)
+		if err != nil || value < 0 {
+			return 0, fmt.Errorf("invalid timestamp format: %s (expected HH:MM:SS)", timestamp)
+		}
+		// Only the last component may have a fraction, and minutes/seconds must stay below 60
+		if i < len(parts)-1 && value != math.Trunc(value) {
+			return 0, fmt.Errorf("invalid timestamp format: %s (expected HH:MM:SS)", timestamp)
+		}
+		if i > 0 && value >= 60 {
+			return 0, fmt.Errorf("invalid timestamp: %s (minutes and seconds must be 00-59)", timestamp)
+		}
+		total = total*60 + value
+	}
+
+	return time.Duration(math.Round(total * float64(time.Second))), nil
+}
+
+// ParseSRTTimestamp parses a strict SRT timestamp ("hh:mm:ss,mmm")
+func ParseSRTTimestamp(timestamp string) (time.Duration, error) {
+	ts := strings.TrimSpace(timestamp)
+	if !srtTimestampPattern.MatchString(ts) {
+		return 0, fmt.Errorf("invalid SRT timestamp format: %s (expected HH:MM:SS,mmm)", timestamp)
+	}
+	return ParseTimestamp(ts)
+}
+
+// FormatSRTTimestamp formats a duration as an SRT timestamp ("hh:mm:ss,mmm"). Hours use as many
+// digits as needed and negative durations are clamped to zero.
+func FormatSRTTimestamp(d time.Duration) string {
+	ms := max(d.Round(time.Millisecond).Milliseconds(), 0)
+	return fmt.Sprintf("%02d:%02d:%02d,%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+// FormatTimestamp formats a duration as "hh:mm:ss", dropping the fractional seconds. Hours use as
+// many digits as needed and negative durations are clamped to zero.
+func FormatTimestamp(d time.Duration) string {
+	seconds := max(int64(d/time.Second), 0)
+	return fmt.Sprintf("%02d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
+
+// CompactTimestamp formats a clip timestamp as HHMMSS for file names ("01:02:03.500" becomes
+// "010203", "100:00:00" becomes "1000000"). Values without colons are taken as digits and padded.
+func CompactTimestamp(timestamp string) string {
+	if strings.Contains(timestamp, ":") {
+		if d, err := ParseTimestamp(timestamp); err == nil {
+			return strings.ReplaceAll(FormatTimestamp(d), ":", "")
+		}
+	}
+
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, timestamp)
+	if len(digits) < 6 {
+		digits = fmt.Sprintf("%06s", digits)
+	}
+	return digits[:6]
+}
+
+// Timebase describes an editorial frame rate as a rational frame duration (Num/Den seconds)
+type Timebase struct {
+	Num  int  // Frame duration numerator
+	Den  int  // Frame duration denominator
+	NTSC bool // Whether the rate is an NTSC (x/1.001) rate
+}
+
+// NewTimebase returns the timebase for a frame rate; 23.976, 29.97 and 59.94 map to NTSC rates
+func NewTimebase(fps float64) (Timebase, error) {
+	if fps <= 0 {
+		return Timebase{}, fmt.Errorf("frame rate must be positive, got %v", fps)
+	}
+
+	rounded := math.Round(fps)
+	if math.Abs(fps-rounded) < 0.001 {
+		return Timebase{Num: 1, Den: int(rounded)}, nil
+	}
+
+	ntsc := rounded * 1000 / 1001
+	if math.Abs(fps-ntsc) < 0.01 {
+		return Timebase{Num: 1001, Den: int(rounded) * 1000, NTSC: true}, nil
+	}
+
+	return Timebase{}, fmt.Errorf("unsupported frame rate %v (use an integer or NTSC rate such as 29.97)", fps)
+}
+
+// FPS returns the exact frame rate
+func (t Timebase) FPS() float64 {
+	return float64(t.Den) / float64(t.Num)
+}
+
+// Nominal returns the rounded frame rate used to count timecode frames
+func (t Timebase) Nominal() int {
+	return int(math.Round(t.FPS()))
+}
+
+// Frames converts a duration to the nearest whole frame count
+func (t Timebase) Frames(d time.Duration) int {
+	return int(math.Round(d.Seconds() * t.FPS()))
+}
+
+// Seconds formats a frame count as a rational number of seconds (e.g. "1001/30000s")
+func (t Timebase) Seconds(frames int) string {
+	if frames == 0 {
+		return "0s"
+	}
+	if t.Num == 1 {
+		return fmt.Sprintf("%d/%ds", frames, t.Den)
+	}
+	return fmt.Sprintf("%d/%ds", frames*t.Num, t.Den)
+}
+
+// Timecode formats a frame count as non-drop-frame SMPTE timecode (HH:MM:SS:FF)
+func (t Timebase) Timecode(frames int) string {
+	nominal := t.Nominal()
+	ff := frames % nominal
+	totalSeconds := frames / nominal
+	return fmt.Sprintf("%02d:%02d:%02d:%02d", totalSeconds/3600, (totalSeconds/60)%60, totalSeconds%60, ff)
+}
+
+// SupportsDropFrame reports whether drop-frame timecode is defined for the rate (29.97 and 59.94)
+func (t Timebase) SupportsDropFrame() bool {
+	return t.NTSC && t.Nominal()%30 == 0
+}
+
+// Duration converts a frame count to the exact time of that frame, truncated to nanoseconds
+func (t Timebase) Duration(frames int) time.Duration {
+	return time.Duration(int64(frames) * int64(t.Num) * int64(time.Second) / int64(t.Den))
+}
+
+// Snap rounds a duration to the nearest frame boundary
+func (t Timebase) Snap(d time.Duration) time.Duration {
+	return t.Duration(t.Frames(d))
+}
+
+// dropFrameCounts returns the frame numbers skipped at the start of each minute, the frames in a
+// ten-minute block and the frames in a minute that drops frames
+func (t Timebase) dropFrameCounts() (dropped, perTenMinutes, perMinute int) {
+	nominal := t.Nominal()
+	dropped = nominal / 15
+	perMinute = nominal*60 - dropped
+	perTenMinutes = nominal*600 - dropped*9
+	return dropped, perTenMinutes, perMinute
+}
+
+// DropFrameTimecode formats a frame count as drop-frame SMPTE timecode (HH:MM:SS;FF). Rates
+// without drop-frame timecode are formatted as non-drop-frame.
+func (t Timebase) DropFrameTimecode(frames int) string {
+	if !t.SupportsDropFrame() {
+		return t.Timecode(frames)
+	}
+
+	// Frame numbers 0 and 1 (0-3 at 59.94) are skipped every minute except each tenth minute
+	dropped, perTenMinutes, perMinute := t.dropFrameCounts()
+	tens, rest := frames/perTenMinutes, frames%perTenMinutes
+	frames += dropped * 9 * tens
+	if rest > dropped {
+		frames += dropped * ((rest - dropped) / perMinute)
+	}
+
+	nominal := t.Nominal()
+	ff := frames % nominal
+	totalSeconds := frames / nominal
+	return fmt.Sprintf("%02d:%02d:%02d;%02d", totalSeconds/3600, (totalSeconds/60)%60, totalSeconds%60, ff)
+}
+
+// ParseTimecode parses SMPTE timecode into a frame count. "HH:MM:SS:FF" is non-drop-frame;
+// "HH:MM:SS;FF" (or "HH;MM;SS;FF") is drop-frame and only accepted at 29.97 and 59.94.
+func (t Timebase) ParseTimecode(timecode string) (int, error) {
+	tc := strings.TrimSpace(timecode)
+	dropFrame := strings.Contains(tc, ";")
+	parts := strings.FieldsFunc(tc, func(r rune) bool { return r == ':' || r == ';' })
+	if len(parts) != 4 {
+		return 0, fmt.Errorf("invalid timecode format: %s (expected HH:MM:SS:FF)", timecode)
+	}
+
+	values := make([]int, 4)
+	for i, part := range parts {
+		value, err := strconv.Atoi(part)
+		if err != nil || value < 0 {
+			return 0, fmt.Errorf("invalid timecode format: %s (expected HH:MM:SS:FF)", timecode)
+		}
+		values[i] = value
+	}
+	hours, minutes, seconds, ff := values[0], values[1], values[2], values[3]
+
+	nominal := t.Nominal()
+	if minutes >= 60 || seconds >= 60 {
+		return 0, fmt.Errorf("invalid timecode: %s (minutes and seconds must be 00-59)", timecode)
+	}
+	if ff >= nominal {
+		return 0, fmt.Errorf("invalid timecode: %s (frames must be below %d)", timecode, nominal)
+	}
+
+	totalMinutes := hours*60 + minutes
+	frames := ((totalMinutes*60)+seconds)*nominal + ff
+	if !dropFrame {
+		return frames, nil
+	}
+
+	if !t.SupportsDropFrame() {
+		return 0, fmt.Errorf("drop-frame timecode %s needs a 29.97 or 59.94 frame rate, got %v", timecode, t.FPS())
+	}
+	dropped, _, _ := t.dropFrameCounts()
+	if seconds == 0 && ff < dropped && minutes%10 != 0 {
+		return 0, fmt.Errorf("invalid drop-frame timecode: %s (frames 00-%02d do not exist at the start of this minute)", timecode, dropped-1)
+	}
+	return frames - dropped*(totalMinutes-totalMinutes/10), nil
+}
+
+// ParseCutPoint parses a clip cut point given as SMPTE timecode or as a timestamp and returns its
+// frame. Timestamps are rounded to the nearest frame.
+func (t Timebase) ParseCutPoint(value string) (int, error) {
+	if strings.Contains(value, ";") || strings.Count(value, ":") == 3 {
+		return t.ParseTimecode(value)
+	}
+	d, err := ParseTimestamp(value)
+	if err != nil {
+		return 0, err
+	}
+	return t.Frames(d), nil
+}
@@ -0,0 +1,140 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	tests := []struct {
+		name      string
+		timestamp string
+		want      time.Duration
+		wantErr   bool
+	}{
+		{name: "hh:mm:ss", timestamp: "01:02:03", want: time.Hour + 2*time.Minute + 3*time.Second},
+		{name: "srt milliseconds", timestamp: "01:30:45,500", want: time.Hour + 30*time.Minute + 45500*time.Millisecond},
+		{name: "dot milliseconds", timestamp: "00:00:01.250", want: 1250 * time.Millisecond},
+		{name: "mm:ss", timestamp: "1:30", want: 90 * time.Second},
+		{name: "past a day", timestamp: "26:00:00", want: 26 * time.Hour},
+		{name: "past 99 hours", timestamp: "120:05:00", want: 120*time.Hour + 5*time.Minute},
+		{name: "invalid minutes", timestamp: "01:60:45", wantErr: true},
+		{name: "invalid seconds", timestamp: "01:30:60", wantErr: true},
+		{name: "too many parts", timestamp: "01:02:03:04", wantErr: true},
+		{name: "empty", timestamp: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTimestamp(tt.timestamp)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseSRTTimestamp(t *testing.T) {
+	got, err := ParseSRTTimestamp("100:30:45,500")
+	require.NoError(t, err)
+	assert.Equal(t, 100*time.Hour+30*time.Minute+45500*time.Millisecond, got)
+
+	for _, invalid := range []string{"1:30:45.500", "01:30:45.500", "01:60:45,500", "01:30:45,1000"} {
+		_, err := ParseSRTTimestamp(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestFormatTimestamps(t *testing.T) {
+	assert.Equal(t, "00:00:00,000", FormatSRTTimestamp(0))
+	assert.Equal(t, "01:30:45,500", FormatSRTTimestamp(time.Hour+30*time.Minute+45500*time.Millisecond))
+	assert.Equal(t, "123:00:01,001", FormatSRTTimestamp(123*time.Hour+1001*time.Millisecond))
+	assert.Equal(t, "00:00:00,000", FormatSRTTimestamp(-time.Second))
+
+	assert.Equal(t, "01:02:03", FormatTimestamp(time.Hour+2*time.Minute+3500*time.Millisecond))
+	assert.Equal(t, "250:00:00", FormatTimestamp(250*time.Hour))
+}
+
+func TestCompactTimestamp(t *testing.T) {
+	tests := map[string]string{
+		"00:01:30":     "000130",
+		"00:01:30.500": "000130",
+		"013000":       "013000",
+		"1:30":         "000130",
+		"30:00:00":     "300000",
+		"100:00:00":    "1000000",
+	}
+	for timestamp, want := range tests {
+		assert.Equal(t, want, CompactTimestamp(timestamp), timestamp)
+	}
+}
+
+func TestTimebaseTimecode(t *testing.T) {
+	df, err := NewTimebase(29.97)
+	require.NoError(t, err)
+	assert.True(t, df.SupportsDropFrame())
+
+	tests := []struct {
+		frames   int
+		timecode string
+	}{
+		{0, "00:00:00;00"},
+		{1799, "00:00:59;29"},
+		{1800, "00:01:00;02"},
+		{17981, "00:09:59;29"},
+		{17982, "00:10:00;00"},
+		{107892, "01:00:00;00"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.timecode, df.DropFrameTimecode(tt.frames), tt.frames)
+		frames, err := df.ParseTimecode(tt.timecode)
+		require.NoError(t, err, tt.timecode)
+		assert.Equal(t, tt.frames, frames, tt.timecode)
+	}
+
+	// Frame numbers dropped at the start of a minute do not exist
+	_, err = df.ParseTimecode("00:01:00;00")
+	assert.ErrorContains(t, err, "do not exist")
+	frames, err := df.ParseTimecode("00:01:00:00")
+	require.NoError(t, err)
+	assert.Equal(t, 1800, frames)
+
+	df60, err := NewTimebase(59.94)
+	require.NoError(t, err)
+	assert.Equal(t, "00:01:00;04", df60.DropFrameTimecode(3600))
+
+	film, err := NewTimebase(23.976)
+	require.NoError(t, err)
+	assert.False(t, film.SupportsDropFrame())
+	assert.Equal(t, "00:00:01:00", film.DropFrameTimecode(24))
+	_, err = film.ParseTimecode("00:00:01;00")
+	assert.ErrorContains(t, err, "needs a 29.97 or 59.94 frame rate")
+}
+
+func TestTimebaseConversions(t *testing.T) {
+	film, err := NewTimebase(23.976)
+	require.NoError(t, err)
+	assert.Equal(t, 1001*time.Millisecond, film.Duration(24))
+	assert.Equal(t, film.Duration(24), film.Snap(time.Second))
+
+	pal, err := NewTimebase(25)
+	require.NoError(t, err)
+	frames, err := pal.ParseCutPoint("00:00:02:12")
+	require.NoError(t, err)
+	assert.Equal(t, 62, frames)
+	frames, err = pal.ParseCutPoint("00:00:02.48")
+	require.NoError(t, err)
+	assert.Equal(t, 62, frames)
+	frames, err = pal.ParseCutPoint("100:00:00:00")
+	require.NoError(t, err)
+	assert.Equal(t, 9000000, frames)
+
+	_, err = pal.ParseCutPoint("00:00:02:25")
+	assert.Error(t, err)
+}
@@ -0,0 +1,141 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeRange is the part of the source video a run is restricted to. A zero End means the end of
+// the video, so the zero TimeRange covers the whole video.
+type TimeRange struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// ParseTimeRange parses the startTime and endTime parameters, either of which may be empty
+func ParseTimeRange(start, end string) (TimeRange, error) {
+	var r TimeRange
+	var err error
+	if start != "" {
+		if r.Start, err = ParseTimestamp(start); err != nil {
+			return TimeRange{}, fmt.Errorf("startTime: %w", err)
+		}
+	}
+	if end != "" {
+		if r.End, err = ParseTimestamp(end); err != nil {
+			return TimeRange{}, fmt.Errorf("endTime: %w", err)
+		}
+		if r.End <= r.Start {
+			return TimeRange{}, fmt.Errorf("endTime %s must be after startTime %s", end, start)
+		}
+	}
+	return r, nil
+}
+
+// IsZero reports whether the range covers the whole video
+func (r TimeRange) IsZero() bool {
+	return r.Start == 0 && r.End == 0
+}
+
+// Duration returns the length of the range, or 0 when it runs to the end of the video
+func (r TimeRange) Duration() time.Duration {
+	if r.End == 0 {
+		return 0
+	}
+	return r.End - r.Start
+}
+
+// Contains reports whether the span from start to end lies within the range
+func (r TimeRange) Contains(start, end time.Duration) bool {
+	return start >= r.Start && (r.End == 0 || end <= r.End)
+}
+
+// Overlaps reports whether the span from start to end overlaps the range
+func (r TimeRange) Overlaps(start, end time.Duration) bool {
+	return end > r.Start && (r.End == 0 || start < r.End)
+}
+
+// String formats the range for logs, e.g. "00:30:00-01:15:00"
+func (r TimeRange) String() string {
+	end := "end"
+	if r.End > 0 {
+		end = FormatTimestamp(r.End)
+	}
+	return FormatTimestamp(r.Start) + "-" + end
+}
+
+// FFmpegSeconds formats a duration for ffmpeg's -ss and -t options
+func FFmpegSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
+
+// ShiftTranscript moves every cue of an SRT or VTT transcript by offset and keeps only the cues
+// that overlap r. SRT cues are numbered again. Text without timing lines is returned unchanged.
+func ShiftTranscript(content string, offset time.Duration, r TimeRange) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	blocks := strings.Split(content, "\n\n")
+
+	var kept []string
+	number := 0
+	for _, block := range blocks {
+		lines := strings.Split(block, "\n")
+		timing := -1
+		for i, line := range lines {
+			if strings.Contains(line, "-->") {
+				timing = i
+				break
+			}
+		}
+		if timing < 0 {
+			// Headers such as WEBVTT and plain text are kept as written
+			if strings.TrimSpace(block) != "" {
+				kept = append(kept, block)
+			}
+			continue
+		}
+
+		startText, rest, _ := strings.Cut(lines[timing], "-->")
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			kept = append(kept, block)
+			continue
+		}
+		start, err1 := ParseTimestamp(startText)
+		end, err2 := ParseTimestamp(fields[0])
+		if err1 != nil || err2 != nil {
+			kept = append(kept, block)
+			continue
+		}
+		start, end = start+offset, end+offset
+		if !r.Overlaps(start, end) {
+			continue
+		}
+
+		vtt := strings.Contains(startText, ".")
+		fields[0] = formatCueTimestamp(end, vtt)
+		lines[timing] = formatCueTimestamp(start, vtt) + " --> " + strings.Join(fields, " ")
+		if timing > 0 {
+			if _, err := strconv.Atoi(strings.TrimSpace(lines[timing-1])); err == nil && !vtt {
+				number++
+				lines[timing-1] = strconv.Itoa(number)
+			}
+		}
+		kept = append(kept, strings.Join(lines, "\n"))
+	}
+	shifted := strings.Join(kept, "\n\n")
+	if strings.HasSuffix(content, "\n") && !strings.HasSuffix(shifted, "\n") {
+		shifted += "\n"
+	}
+	return shifted
+}
+
+// formatCueTimestamp formats a cue time as SRT ("hh:mm:ss,mmm") or VTT ("hh:mm:ss.mmm")
+func formatCueTimestamp(d time.Duration, vtt bool) string {
+	formatted := FormatSRTTimestamp(d)
+	if vtt {
+		return strings.Replace(formatted, ",", ".", 1)
+	}
+	return formatted
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimeRange(t *testing.T) {
+	r, err := ParseTimeRange("00:30:00", "1:15:00")
+	require.NoError(t, err)
+	assert.Equal(t, TimeRange{Start: 30 * time.Minute, End: 75 * time.Minute}, r)
+	assert.Equal(t, 45*time.Minute, r.Duration())
+	assert.Equal(t, "00:30:00-01:15:00", r.String())
+
+	r, err = ParseTimeRange("", "")
+	require.NoError(t, err)
+	assert.True(t, r.IsZero())
+
+	r, err = ParseTimeRange("90", "")
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), r.Duration(), "runs to the end of the video")
+	assert.Equal(t, "00:01:30-end", r.String())
+
+	_, err = ParseTimeRange("10:00", "05:00")
+	assert.Error(t, err)
+	_, err = ParseTimeRange("ten", "")
+	assert.Error(t, err)
+}
+
+func TestTimeRange_Contains(t *testing.T) {
+	r := TimeRange{Start: time.Minute, End: 2 * time.Minute}
+	assert.True(t, r.Contains(70*time.Second, 90*time.Second))
+	assert.False(t, r.Contains(50*time.Second, 90*time.Second))
+	assert.False(t, r.Contains(110*time.Second, 130*time.Second))
+	assert.True(t, r.Overlaps(110*time.Second, 130*time.Second))
+	assert.False(t, r.Overlaps(130*time.Second, 140*time.Second))
+	assert.True(t, TimeRange{Start: time.Minute}.Contains(time.Hour, 2*time.Hour))
+}
+
+func TestShiftTranscript(t *testing.T) {
+	srt := "1\n00:00:01,000 --> 00:00:02,500\nHello\n\n2\n00:00:05,000 --> 00:00:06,000\nWorld\n"
+
+	shifted := ShiftTranscript(srt, 30*time.Minute, TimeRange{Start: 30 * time.Minute})
+	assert.Equal(t, "1\n00:30:01,000 --> 00:30:02,500\nHello\n\n2\n00:30:05,000 --> 00:30:06,000\nWorld\n", shifted)
+
+	// Cues outside the range are dropped and the others numbered again
+	kept := ShiftTranscript(srt, 0, TimeRange{Start: 4 * time.Second})
+	assert.Equal(t, "1\n00:00:05,000 --> 00:00:06,000\nWorld\n", kept)
+
+	vtt := "WEBVTT\n\n00:00:01.000 --> 00:00:02.000 align:start\nHi\n"
+	assert.Equal(t, "WEBVTT\n\n00:01:01.000 --> 00:01:02.000 align:start\nHi\n", ShiftTranscript(vtt, time.Minute, TimeRange{}))
+
+	assert.Equal(t, "plain text", ShiftTranscript("plain text", time.Minute, TimeRange{}))
+}
@@ -0,0 +1,177 @@
+package utils
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TitlePerformance represents a past video title and its performance metrics
+type TitlePerformance struct {
+	Title    string  `json:"title"`
+	Views    float64 `json:"views"`
+	Likes    float64 `json:"likes"`
+	Comments float64 `json:"comments"`
+	CTR      float64 `json:"ctr"`
+}
+
+// Metric returns the value of the named metric (views, likes, comments, ctr, engagement)
+func (t TitlePerformance) Metric(name string) (float64, error) {
+	switch strings.ToLower(name) {
+	case "", "views":
+		return t.Views, nil
+	case "likes":
+		return t.Likes, nil
+	case "comments":
+		return t.Comments, nil
+	case "ctr":
+		return t.CTR, nil
+	case "engagement":
+		if t.Views == 0 {
+			return 0, nil
+		}
+		return (t.Likes + t.Comments) / t.Views, nil
+	default:
+		return 0, fmt.Errorf("unsupported metric: %s (supported: views, likes, comments, ctr, engagement)", name)
+	}
+}
+
+// LoadTitleHistory reads past titles and their metrics from a CSV or JSON file.
+// CSV files need a header row with a "title" column; metric columns are optional.
+func LoadTitleHistory(filePath string) ([]TitlePerformance, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open title history: %w", err)
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			LogWarning("Failed to close title history: %v", err)
+		}
+	}()
+
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".csv":
+		return parseTitleHistoryCSV(f)
+	case ".json":
+		var history []TitlePerformance
+		if err := json.NewDecoder(f).Decode(&history); err != nil {
+			return nil, fmt.Errorf("failed to parse title history JSON: %w", err)
+		}
+		return history, nil
+	default:
+		return nil, fmt.Errorf("unsupported title history format: %s (expected .csv or .json)", filepath.Ext(filePath))
+	}
+}
+
+// parseTitleHistoryCSV parses a CSV file with a header row
+func parseTitleHistoryCSV(r io.Reader) ([]TitlePerformance, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse title history CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	titleCol, ok := columns["title"]
+	if !ok {
+		return nil, fmt.Errorf("title history CSV must have a \"title\" column")
+	}
+
+	value := func(record []string, column string) float64 {
+		idx, ok := columns[column]
+		if !ok || idx >= len(record) {
+			return 0
+		}
+		raw := strings.TrimSuffix(strings.ReplaceAll(strings.TrimSpace(record[idx]), ",", ""), "%")
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return 0
+		}
+		return v
+	}
+
+	var history []TitlePerformance
+	for _, record := range records[1:] {
+		if titleCol >= len(record) || strings.TrimSpace(record[titleCol]) == "" {
+			continue
+		}
+		history = append(history, TitlePerformance{
+			Title:    strings.TrimSpace(record[titleCol]),
+			Views:    value(record, "views"),
+			Likes:    value(record, "likes"),
+			Comments: value(record, "comments"),
+			CTR:      value(record, "ctr"),
+		})
+	}
+
+	return history, nil
+}
+
+// TopPerformers returns the n best titles ranked by the given metric
+func TopPerformers(history []TitlePerformance, metric string, n int) ([]TitlePerformance, error) {
+	if _, err := (TitlePerformance{}).Metric(metric); err != nil {
+		return nil, err
+	}
+
+	ranked := make([]TitlePerformance, len(history))
+	copy(ranked, history)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, _ := ranked[i].Metric(metric)
+		b, _ := ranked[j].Metric(metric)
+		return a > b
+	})
+
+	if n > 0 && len(ranked) > n {
+		ranked = ranked[:n]
+	}
+	return ranked, nil
+}
+
+// BuildFewShotPrompt loads the title history and formats the top performers as
+// prompt examples. It returns an empty string when no history file is configured.
+func BuildFewShotPrompt(historyFile, metric string, count int) (string, error) {
+	if historyFile == "" {
+		return "", nil
+	}
+
+	history, err := LoadTitleHistory(historyFile)
+	if err != nil {
+		return "", err
+	}
+
+	top, err := TopPerformers(history, metric, count)
+	if err != nil {
+		return "", err
+	}
+	if len(top) == 0 {
+		return "", nil
+	}
+
+	if metric == "" {
+		metric = "views"
+	}
+
+	var b strings.Builder
+	b.WriteString("## CHANNEL STYLE EXAMPLES:\n")
+	b.WriteString(fmt.Sprintf("These past titles performed best on this channel (ranked by %s). Match their tone, length and structure without copying them:\n", metric))
+	for i, t := range top {
+		value, _ := t.Metric(metric)
+		b.WriteString(fmt.Sprintf("%d. %s (%s: %s)\n", i+1, t.Title, metric, strconv.FormatFloat(value, 'f', -1, 64)))
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,285 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TranscriptFix is one rule of the cleanup run on a transcript right after transcription, to fix
+// systematic Whisper errors before any model sees the text. Each fix sets one kind of rule, and
+// fixes run in the order they are listed.
+type TranscriptFix struct {
+	Pattern        string            `json:"pattern,omitempty" yaml:"pattern,omitempty"`               // Regular expression, replaced by replace ($1 refers to a group)
+	Replace        string            `json:"replace,omitempty" yaml:"replace,omitempty"`               // Replacement of pattern
+	Dictionary     map[string]string `json:"dictionary,omitempty" yaml:"dictionary,omitempty"`         // Misheard word or phrase -> correct spelling, matched as whole words ignoring case
+	DictionaryFile string            `json:"dictionaryFile,omitempty" yaml:"dictionaryFile,omitempty"` // YAML mapping of more dictionary entries; ${project} is the project directory
+	Casing         []string          `json:"casing,omitempty" yaml:"casing,omitempty"`                 // Names always written with this casing, e.g. "YouTube"
+	Numbers        string            `json:"numbers,omitempty" yaml:"numbers,omitempty"`               // "digits": spelled-out numbers from 10 to 99 become digits (English and Spanish)
+}
+
+// TranscriptFixer applies a compiled list of transcript fixes
+type TranscriptFixer struct {
+	rules []func(string) string
+}
+
+// CompileTranscriptFixes checks the fixes and prepares them to run
+func CompileTranscriptFixes(fixes []TranscriptFix) (*TranscriptFixer, error) {
+	fixer := &TranscriptFixer{}
+	for i, fix := range fixes {
+		rule, err := compileTranscriptFix(fix)
+		if err != nil {
+			return nil, fmt.Errorf("transcriptFixes[%d]: %w", i, err)
+		}
+		fixer.rules = append(fixer.rules, rule)
+	}
+	return fixer, nil
+}
+
+// compileTranscriptFix turns one fix into a function on a line of text
+func compileTranscriptFix(fix TranscriptFix) (func(string) string, error) {
+	var kinds []string
+	if fix.Pattern != "" {
+		kinds = append(kinds, "pattern")
+	}
+	if len(fix.Dictionary) > 0 || fix.DictionaryFile != "" {
+		kinds = append(kinds, "dictionary")
+	}
+	if len(fix.Casing) > 0 {
+		kinds = append(kinds, "casing")
+	}
+	if fix.Numbers != "" {
+		kinds = append(kinds, "numbers")
+	}
+	if len(kinds) != 1 {
+		return nil, fmt.Errorf("set exactly one of pattern, dictionary, dictionaryFile, casing or numbers (found %d)", len(kinds))
+	}
+
+	switch kinds[0] {
+	case "pattern":
+		re, err := regexp.Compile(fix.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", fix.Pattern, err)
+		}
+		return func(line string) string { return re.ReplaceAllString(line, fix.Replace) }, nil
+
+	case "dictionary":
+		entries := make(map[string]string, len(fix.Dictionary))
+		for from, to := range fix.Dictionary {
+			entries[from] = to
+		}
+		if fix.DictionaryFile != "" {
+			fileEntries, err := loadTranscriptDictionary(fix.DictionaryFile)
+			if err != nil {
+				return nil, err
+			}
+			// Entries written in the workflow win over those of the file
+			for from, to := range fileEntries {
+				if _, ok := entries[from]; !ok {
+					entries[from] = to
+				}
+			}
+		}
+		return wordReplacer(entries), nil
+
+	case "casing":
+		entries := make(map[string]string, len(fix.Casing))
+		for _, name := range fix.Casing {
+			entries[name] = name
+		}
+		return wordReplacer(entries), nil
+
+	default:
+		if fix.Numbers != "digits" {
+			return nil, fmt.Errorf("invalid numbers %q: must be digits", fix.Numbers)
+		}
+		return wordReplacer(spelledNumbers()), nil
+	}
+}
+
+// loadTranscriptDictionary reads a YAML mapping of misheard words to their spelling
+func loadTranscriptDictionary(path string) (map[string]string, error) {
+	resolved, err := ResolveProjectPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if resolved, err = ExpandHomeDir(resolved); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dictionary file: %w", err)
+	}
+	var entries map[string]string
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("dictionary file %s must be a mapping of words to their spelling: %w", path, err)
+	}
+	return entries, nil
+}
+
+// wordReplacer replaces whole words or phrases, ignoring case, by their entry. Longer entries are
+// tried first, so "open ai studio" wins over "open ai".
+func wordReplacer(entries map[string]string) func(string) string {
+	if len(entries) == 0 {
+		return func(line string) string { return line }
+	}
+	lookup := make(map[string]string, len(entries))
+	keys := make([]string, 0, len(entries))
+	for from, to := range entries {
+		key := strings.ToLower(strings.TrimSpace(from))
+		if key == "" {
+			continue
+		}
+		if _, ok := lookup[key]; !ok {
+			keys = append(keys, key)
+		}
+		lookup[key] = to
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+
+	quoted := make([]string, len(keys))
+	for i, key := range keys {
+		// Spaces in a phrase match any run of spaces, as Whisper spaces words unevenly
+		quoted[i] = strings.ReplaceAll(regexp.QuoteMeta(key), " ", `\s+`)
+	}
+	re := regexp.MustCompile(`(?i)` + strings.Join(quoted, "|"))
+
+	return func(line string) string {
+		var b strings.Builder
+		last := 0
+		for _, loc := range re.FindAllStringIndex(line, -1) {
+			if !wordBoundary(line, loc[0], loc[1]) {
+				continue
+			}
+			match := strings.ToLower(strings.Join(strings.Fields(line[loc[0]:loc[1]]), " "))
+			b.WriteString(line[last:loc[0]])
+			b.WriteString(lookup[match])
+			last = loc[1]
+		}
+		if last == 0 {
+			return line
+		}
+		b.WriteString(line[last:])
+		return b.String()
+	}
+}
+
+// wordBoundary reports whether text[start:end] is not part of a longer word. Unlike \b it treats
+// accented letters as letters.
+func wordBoundary(text string, start, end int) bool {
+	isWord := func(r rune) bool { return unicode.IsLetter(r) || unicode.IsNumber(r) || r == '_' }
+	if start > 0 {
+		if r, _ := utf8.DecodeLastRuneInString(text[:start]); isWord(r) {
+			return false
+		}
+	}
+	if end < len(text) {
+		if r, _ := utf8.DecodeRuneInString(text[end:]); isWord(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// spelledNumbers maps the English and Spanish words for 10 to 99 to digits. Numbers below ten
+// stay words, as "one" and "uno" are as often pronouns as numbers.
+func spelledNumbers() map[string]string {
+	numbers := make(map[string]string)
+	add := func(word string, n int) { numbers[word] = strconv.Itoa(n) }
+
+	english := []string{"ten", "eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen", "seventeen", "eighteen", "nineteen"}
+	englishTens := []string{"twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety"}
+	englishUnits := []string{"one", "two", "three", "four", "five", "six", "seven", "eight", "nine"}
+	for i, word := range english {
+		add(word, 10+i)
+	}
+	for i, tens := range englishTens {
+		add(tens, 20+10*i)
+		for j, unit := range englishUnits {
+			add(tens+"-"+unit, 21+10*i+j)
+			add(tens+" "+unit, 21+10*i+j)
+		}
+	}
+
+	spanish := []string{"diez", "once", "doce", "trece", "catorce", "quince", "dieciséis", "diecisiete", "dieciocho", "diecinueve",
+		"veinte", "veintiuno", "veintidós", "veintitrés", "veinticuatro", "veinticinco", "veintiséis", "veintisiete", "veintiocho", "veintinueve"}
+	spanishTens := []string{"treinta", "cuarenta", "cincuenta", "sesenta", "setenta", "ochenta", "noventa"}
+	spanishUnits := []string{"uno", "dos", "tres", "cuatro", "cinco", "seis", "siete", "ocho", "nueve"}
+	for i, word := range spanish {
+		add(word, 10+i)
+	}
+	// Whisper often drops the accents
+	for word, n := range map[string]int{"dieciseis": 16, "veintidos": 22, "veintitres": 23, "veintiseis": 26} {
+		add(word, n)
+	}
+	for i, tens := range spanishTens {
+		add(tens, 30+10*i)
+		for j, unit := range spanishUnits {
+			add(tens+" y "+unit, 31+10*i+j)
+		}
+	}
+	return numbers
+}
+
+// Apply runs the fixes on a transcript and returns it with the number of lines changed. Subtitle
+// indexes, timing lines and the WEBVTT header are left alone, so the timings of an SRT or VTT
+// transcript are kept; patterns therefore match within a line.
+func (f *TranscriptFixer) Apply(transcript string) (string, int) {
+	if f == nil || len(f.rules) == 0 {
+		return transcript, 0
+	}
+	lines := strings.Split(transcript, "\n")
+	changed := 0
+	for i, line := range lines {
+		if subtitleStructureLine(line) {
+			continue
+		}
+		fixed := line
+		for _, rule := range f.rules {
+			fixed = rule(fixed)
+		}
+		if fixed != line {
+			lines[i] = fixed
+			changed++
+		}
+	}
+	return strings.Join(lines, "\n"), changed
+}
+
+// subtitleStructureLine reports whether a line is a cue number, a timing line or a VTT header
+func subtitleStructureLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.Contains(trimmed, "-->") || strings.HasPrefix(trimmed, "WEBVTT") {
+		return true
+	}
+	_, err := strconv.Atoi(trimmed)
+	return err == nil
+}
+
+// FixTranscriptFile applies the fixes to a transcript file in place and returns the number of lines changed
+func FixTranscriptFile(path string, fixer *TranscriptFixer) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read transcript: %w", err)
+	}
+	fixed, changed := fixer.Apply(string(data))
+	if changed == 0 {
+		return 0, nil
+	}
+	if err := AtomicWriteFile(path, []byte(fixed), 0644); err != nil {
+		return 0, fmt.Errorf("failed to write transcript: %w", err)
+	}
+	return changed, nil
+}
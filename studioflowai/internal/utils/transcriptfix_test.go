@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranscriptFixer_Apply(t *testing.T) {
+	dir := t.TempDir()
+	dictionary := filepath.Join(dir, "dictionary.yaml")
+	require.NoError(t, os.WriteFile(dictionary, []byte("studio flow: StudioFlowAI\n"), 0644))
+
+	fixer, err := CompileTranscriptFixes([]TranscriptFix{
+		{Pattern: `\s+([,.?!])`, Replace: "$1"},
+		{Dictionary: map[string]string{"open ai": "OpenAI"}, DictionaryFile: dictionary},
+		{Casing: []string{"YouTube", "Kubernetes"}},
+		{Numbers: "digits"},
+	})
+	require.NoError(t, err)
+
+	srt := "1\n00:00:01,000 --> 00:00:04,000\nWelcome to studio flow , built on open  ai .\n\n" +
+		"2\n00:00:04,000 --> 00:00:08,000\nWe posted twenty-one youtube videos about kubernetes in one week.\n\n" +
+		"3\n00:00:08,000 --> 00:00:10,000\nSubimos treinta y dos videos, no openaible.\n"
+
+	fixed, changed := fixer.Apply(srt)
+	assert.Equal(t, 3, changed)
+	assert.Equal(t, "1\n00:00:01,000 --> 00:00:04,000\nWelcome to StudioFlowAI, built on OpenAI.\n\n"+
+		"2\n00:00:04,000 --> 00:00:08,000\nWe posted 21 YouTube videos about Kubernetes in one week.\n\n"+
+		"3\n00:00:08,000 --> 00:00:10,000\nSubimos 32 videos, no openaible.\n", fixed)
+}
+
+func TestCompileTranscriptFixes_Errors(t *testing.T) {
+	_, err := CompileTranscriptFixes([]TranscriptFix{{Pattern: "(", Replace: "x"}})
+	assert.ErrorContains(t, err, "transcriptFixes[0]: invalid pattern")
+
+	_, err = CompileTranscriptFixes([]TranscriptFix{{Pattern: "a", Casing: []string{"A"}}})
+	assert.ErrorContains(t, err, "exactly one")
+
+	_, err = CompileTranscriptFixes([]TranscriptFix{{}, {Numbers: "words"}})
+	assert.ErrorContains(t, err, "transcriptFixes[0]")
+
+	_, err = CompileTranscriptFixes([]TranscriptFix{{Numbers: "words"}})
+	assert.ErrorContains(t, err, "must be digits")
+}
+
+func TestFixTranscriptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transcript.txt")
+	require.NoError(t, os.WriteFile(path, []byte("hello youtube\n"), 0644))
+
+	fixer, err := CompileTranscriptFixes([]TranscriptFix{{Casing: []string{"YouTube"}}})
+	require.NoError(t, err)
+	changed, err := FixTranscriptFile(path, fixer)
+	require.NoError(t, err)
+	assert.Equal(t, 1, changed)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "hello YouTube\n", string(data))
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package utils
+
+import (
+	"errors"
+	"os"
+)
+
+// setUmask is not available on this platform
+func setUmask(umask os.FileMode) error {
+	return errors.New("permissions umask is not supported on this platform; set fileMode and dirMode instead")
+}
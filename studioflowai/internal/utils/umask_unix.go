@@ -0,0 +1,15 @@
+//go:build unix
+
+package utils
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// setUmask sets the umask of the process, and so of the external tools it starts
+func setUmask(umask os.FileMode) error {
+	unix.Umask(int(umask))
+	return nil
+}
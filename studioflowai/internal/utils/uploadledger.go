@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// LedgerEntry is a file posted to a platform
+type LedgerEntry struct {
+	SHA256     string    `json:"sha256"`
+	Title      string    `json:"title"`
+	UploadTime time.Time `json:"uploadTime"`
+}
+
+// UploadLedger remembers the files posted to a platform from this machine, so a rerun of a
+// workflow never posts the same clip twice
+type UploadLedger struct {
+	path    string
+	Uploads []LedgerEntry `json:"uploads"`
+}
+
+// LoadUploadLedger reads the ledger of a platform from the config directory of the active workspace.
+// A missing ledger is empty.
+func LoadUploadLedger(platform string) (*UploadLedger, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	ledger := &UploadLedger{path: filepath.Join(dir, platform+"_uploads.json")}
+
+	data, err := os.ReadFile(ledger.path)
+	if os.IsNotExist(err) {
+		return ledger, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload ledger: %w", err)
+	}
+	if err := json.Unmarshal(data, ledger); err != nil {
+		return nil, fmt.Errorf("failed to parse upload ledger %s: %w", ledger.path, err)
+	}
+	return ledger, nil
+}
+
+// Find returns the entry of a file with the given checksum
+func (l *UploadLedger) Find(sha256 string) (LedgerEntry, bool) {
+	for _, entry := range l.Uploads {
+		if entry.SHA256 == sha256 {
+			return entry, true
+		}
+	}
+	return LedgerEntry{}, false
+}
+
+// Record adds a posted file to the ledger and saves it
+func (l *UploadLedger) Record(sha256, title string) error {
+	l.Uploads = append(l.Uploads, LedgerEntry{SHA256: sha256, Title: title, UploadTime: time.Now()})
+
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal upload ledger: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	return AtomicWriteFile(l.path, data, 0644)
+}
+
+// SameTitle reports whether a posted title or caption is the given title, ignoring case, spacing
+// and punctuation. A caption matches when it starts with the title, as captions carry hashtags after it.
+func SameTitle(title, posted string) bool {
+	title, posted = normalizeTitle(title), normalizeTitle(posted)
+	if title == "" || posted == "" {
+		return false
+	}
+	return posted == title || strings.HasPrefix(posted, title+" ")
+}
+
+// normalizeTitle lower-cases a title and reduces it to its words
+func normalizeTitle(title string) string {
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '#'
+	})
+	return strings.Join(words, " ")
+}
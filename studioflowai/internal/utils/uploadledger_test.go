@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadLedger(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	ledger, err := LoadUploadLedger(PlatformTikTok)
+	require.NoError(t, err)
+	_, ok := ledger.Find("abc")
+	assert.False(t, ok)
+
+	require.NoError(t, ledger.Record("abc", "First clip"))
+
+	reloaded, err := LoadUploadLedger(PlatformTikTok)
+	require.NoError(t, err)
+	entry, ok := reloaded.Find("abc")
+	assert.True(t, ok)
+	assert.Equal(t, "First clip", entry.Title)
+}
+
+func TestSameTitle(t *testing.T) {
+	assert.True(t, SameTitle("Why Go?", "why go"))
+	assert.True(t, SameTitle("Why Go?", "Why  Go? #golang #dev"))
+	assert.False(t, SameTitle("Why Go?", "Why Gophers love Go"))
+	assert.False(t, SameTitle("", ""))
+}
@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UploadsPreviewFile is the file in the run folder where upload previews are written
+const UploadsPreviewFile = "uploads_preview.yaml"
+
+var (
+	previewMu      sync.RWMutex
+	previewUploads bool
+)
+
+// SetPreviewUploads turns upload preview on or off. In preview, upload steps write the requests
+// they would send to uploads_preview.yaml instead of calling the platform APIs.
+func SetPreviewUploads(enabled bool) {
+	previewMu.Lock()
+	defer previewMu.Unlock()
+	previewUploads = enabled
+}
+
+// PreviewUploads reports whether upload steps should only preview their requests
+func PreviewUploads() bool {
+	previewMu.RLock()
+	defer previewMu.RUnlock()
+	return previewUploads
+}
+
+// UploadTarget is the account an upload step publishes to
+type UploadTarget struct {
+	Project     string `yaml:"project,omitempty"`     // Active project, if any
+	Account     string `yaml:"account,omitempty"`     // Channel or user name, when known
+	Credentials string `yaml:"credentials,omitempty"` // Credentials file the step authorizes with
+}
+
+// PreviewUpload is one video an upload step would publish
+type PreviewUpload struct {
+	File        string      `yaml:"file,omitempty"`    // Empty when an already published video is changed
+	Missing     bool        `yaml:"missing,omitempty"` // The video file does not exist, so the upload would be skipped or fail
+	Title       string      `yaml:"title"`
+	Description string      `yaml:"description"`
+	Tags        []string    `yaml:"tags,omitempty"`
+	Privacy     string      `yaml:"privacy"`
+	PublishAt   string      `yaml:"publishAt,omitempty"`
+	PlaylistID  string      `yaml:"playlistId,omitempty"`
+	Request     interface{} `yaml:"request"` // Body of the API request, as sent
+}
+
+// UploadPreview lists the uploads of one step
+type UploadPreview struct {
+	Platform string          `yaml:"platform"`
+	Source   string          `yaml:"source"` // Shorts or parts file the uploads come from
+	Target   UploadTarget    `yaml:"target"`
+	Notes    []string        `yaml:"notes,omitempty"`
+	Uploads  []PreviewUpload `yaml:"uploads"`
+}
+
+// uploadsPreview is the content of uploads_preview.yaml
+type uploadsPreview struct {
+	Steps []UploadPreview `yaml:"steps"`
+}
+
+// WriteUploadPreview adds the preview of a step to uploads_preview.yaml in outputDir, replacing an
+// earlier preview of the same platform and source, and returns the file's path
+func WriteUploadPreview(outputDir string, preview UploadPreview) (string, error) {
+	for i, upload := range preview.Uploads {
+		request, err := apiPayload(upload.Request)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode request of %s: %w", upload.Title, err)
+		}
+		preview.Uploads[i].Request = request
+		if upload.File == "" {
+			continue
+		}
+		if _, err := os.Stat(upload.File); err != nil {
+			preview.Uploads[i].Missing = true
+		}
+	}
+
+	path := filepath.Join(outputDir, UploadsPreviewFile)
+	var doc uploadsPreview
+	if data, err := os.ReadFile(path); err == nil {
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", path, err)
+		}
+	}
+
+	replaced := false
+	for i, step := range doc.Steps {
+		if step.Platform == preview.Platform && step.Source == preview.Source {
+			doc.Steps[i] = preview
+			replaced = true
+		}
+	}
+	if !replaced {
+		doc.Steps = append(doc.Steps, preview)
+	}
+
+	data, err := yaml.Marshal(&doc)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode upload preview: %w", err)
+	}
+	if err := WriteTextFile(path, string(data)); err != nil {
+		return "", fmt.Errorf("failed to write upload preview: %w", err)
+	}
+	return path, nil
+}
+
+// apiPayload converts a request body to the fields and names it has in the JSON sent to the API
+func apiPayload(request interface{}) (interface{}, error) {
+	if request == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	var payload interface{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
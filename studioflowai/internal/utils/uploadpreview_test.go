@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestWriteUploadPreview(t *testing.T) {
+	dir := t.TempDir()
+	video := filepath.Join(dir, "clip.mp4")
+	require.NoError(t, os.WriteFile(video, []byte("video"), 0644))
+
+	type snippet struct {
+		Title      string `json:"title"`
+		CategoryID string `json:"categoryId,omitempty"`
+	}
+	youtube := UploadPreview{
+		Platform: PlatformYouTube,
+		Source:   "shorts.yaml",
+		Uploads: []PreviewUpload{
+			{File: video, Title: "First", Request: map[string]interface{}{"snippet": snippet{Title: "First"}}},
+			{File: filepath.Join(dir, "missing.mp4"), Title: "Second"},
+		},
+	}
+	path, err := WriteUploadPreview(dir, youtube)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, UploadsPreviewFile), path)
+
+	// A second platform is added; previewing the same source again replaces its entry
+	_, err = WriteUploadPreview(dir, UploadPreview{Platform: PlatformTikTok, Source: "shorts.yaml"})
+	require.NoError(t, err)
+	youtube.Uploads = youtube.Uploads[:1]
+	youtube.Uploads[0].Title = "First, renamed"
+	_, err = WriteUploadPreview(dir, youtube)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var doc uploadsPreview
+	require.NoError(t, yaml.Unmarshal(data, &doc))
+	require.Len(t, doc.Steps, 2)
+	assert.Equal(t, PlatformYouTube, doc.Steps[0].Platform)
+	require.Len(t, doc.Steps[0].Uploads, 1)
+	assert.Equal(t, "First, renamed", doc.Steps[0].Uploads[0].Title)
+	assert.False(t, doc.Steps[0].Uploads[0].Missing)
+	assert.Contains(t, string(data), "snippet:\n", "requests use the field names of the API")
+	assert.NotContains(t, string(data), "categoryId", "fields left out of the request are left out of the preview")
+	assert.Equal(t, PlatformTikTok, doc.Steps[1].Platform)
+}
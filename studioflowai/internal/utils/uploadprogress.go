@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// uploadProgressInterval is the least time between two progress lines of an upload
+const uploadProgressInterval = 10 * time.Second
+
+// UploadProgress reports how far a long upload got, with its throughput and the time left,
+// as lines of the step log. Update has the signature of googleapi.ProgressUpdater.
+type UploadProgress struct {
+	mu       sync.Mutex
+	name     string
+	size     int64 // Size of the file, used when the uploader does not know it
+	now      func() time.Time
+	start    time.Time
+	lastLine time.Time
+	reported int64 // Bytes sent at the last progress line
+}
+
+// NewUploadProgress starts timing the upload of name, a file of size bytes (0 when unknown)
+func NewUploadProgress(name string, size int64) *UploadProgress {
+	return newUploadProgress(name, size, time.Now)
+}
+
+func newUploadProgress(name string, size int64, now func() time.Time) *UploadProgress {
+	start := now()
+	return &UploadProgress{name: name, size: size, now: now, start: start, lastLine: start}
+}
+
+// Update records that current of total bytes were sent, logging a progress line at most every
+// 10 seconds and when the upload completes. A total of 0 stands for the size given to
+// NewUploadProgress.
+func (p *UploadProgress) Update(current, total int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if total <= 0 {
+		total = p.size
+	}
+	now := p.now()
+	if current <= p.reported || ((total <= 0 || current < total) && now.Sub(p.lastLine) < uploadProgressInterval) {
+		return
+	}
+	p.lastLine = now
+	p.reported = current
+	LogInfo("%s", p.line(current, total, now))
+}
+
+// line formats the progress of an upload, e.g.
+// "Uploading ep12.mp4: 42% (210.0 MB of 500.0 MB) at 6.3 MB/s, 46s left"
+func (p *UploadProgress) line(current, total int64, now time.Time) string {
+	elapsed := now.Sub(p.start)
+	rate := 0.0
+	if elapsed > 0 {
+		rate = float64(current) / elapsed.Seconds()
+	}
+	if total <= 0 {
+		return fmt.Sprintf("Uploading %s: %s at %s/s", p.name, FormatBytes(current), FormatBytes(int64(rate)))
+	}
+
+	line := fmt.Sprintf("Uploading %s: %d%% (%s of %s) at %s/s", p.name, current*100/total, FormatBytes(current), FormatBytes(total), FormatBytes(int64(rate)))
+	switch {
+	case current >= total:
+		line += fmt.Sprintf(", done in %s", elapsed.Round(time.Second))
+	case rate > 0:
+		left := time.Duration(float64(total-current) / rate * float64(time.Second))
+		line += fmt.Sprintf(", %s left", left.Round(time.Second))
+	}
+	return line
+}
+
+// FormatBytes formats a size in binary units, e.g. "512 B", "3.5 MB" or "1.2 GB"
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value, suffix := float64(n)/unit, "KB"
+	for _, next := range []string{"MB", "GB", "TB"} {
+		if value < unit {
+			break
+		}
+		value, suffix = value/unit, next
+	}
+	return fmt.Sprintf("%.1f %s", value, suffix)
+}
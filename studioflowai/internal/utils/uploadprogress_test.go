@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUploadProgress(t *testing.T) {
+	buf := captureLog(t)
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	p := newUploadProgress("ep12.mp4", 400<<20, func() time.Time { return now })
+
+	// The first chunks after 20 seconds: 5 MB/s, a minute left
+	now = start.Add(20 * time.Second)
+	p.Update(100<<20, 0)
+	assert.Contains(t, buf.String(), "Uploading ep12.mp4: 25% (100.0 MB of 400.0 MB) at 5.0 MB/s, 1m0s left")
+
+	// Chunks within 10 seconds of the last line are not reported, except the last one
+	buf.Reset()
+	now = start.Add(25 * time.Second)
+	p.Update(200<<20, 400<<20)
+	assert.Empty(t, buf.String())
+	now = start.Add(80 * time.Second)
+	p.Update(400<<20, 400<<20)
+	assert.Contains(t, buf.String(), "Uploading ep12.mp4: 100% (400.0 MB of 400.0 MB) at 5.0 MB/s, done in 1m20s")
+}
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "512 B", FormatBytes(512))
+	assert.Equal(t, "1.5 KB", FormatBytes(1536))
+	assert.Equal(t, "3.5 MB", FormatBytes(3670016))
+	assert.Equal(t, "1.2 GB", FormatBytes(1288490189))
+}
@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// Usage collects the LLM tokens, their cost and the external tools used while a step runs
+type Usage struct {
+	mu      sync.Mutex
+	tokens  int
+	costUSD float64
+	tools   map[string]string
+}
+
+// usageKey is the context key of the step's Usage
+type usageKey struct{}
+
+// toolVersions caches the version of every external tool for the lifetime of the process
+var (
+	toolVersionsMu sync.Mutex
+	toolVersions   = map[string]string{}
+)
+
+// WithUsage returns a context that collects the usage of everything run with it
+func WithUsage(ctx context.Context) (context.Context, *Usage) {
+	usage := &Usage{tools: make(map[string]string)}
+	return context.WithValue(ctx, usageKey{}, usage), usage
+}
+
+// RecordTokens adds LLM tokens and their estimated cost to the usage of the context, if any
+func RecordTokens(ctx context.Context, tokens int, costUSD float64) {
+	usage, ok := ctx.Value(usageKey{}).(*Usage)
+	if !ok {
+		return
+	}
+	usage.mu.Lock()
+	defer usage.mu.Unlock()
+	usage.tokens += tokens
+	usage.costUSD += costUSD
+}
+
+// RecordTool adds an external tool and its version to the usage of the context, if any
+func RecordTool(ctx context.Context, name string) {
+	usage, ok := ctx.Value(usageKey{}).(*Usage)
+	if !ok {
+		return
+	}
+	usage.mu.Lock()
+	_, seen := usage.tools[name]
+	usage.mu.Unlock()
+	if seen {
+		return
+	}
+	version := ToolVersion(name)
+	usage.mu.Lock()
+	defer usage.mu.Unlock()
+	usage.tools[name] = version
+}
+
+// Totals returns the tokens, cost and tool versions collected so far
+func (u *Usage) Totals() (int, float64, map[string]string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	tools := make(map[string]string, len(u.tools))
+	for name, version := range u.tools {
+		tools[name] = version
+	}
+	return u.tokens, u.costUSD, tools
+}
+
+// CommandContext is exec.CommandContext that also records the tool in the usage of the context
+func CommandContext(ctx context.Context, name string, args ...string) *exec.Cmd {
+	RecordTool(ctx, name)
+	return exec.CommandContext(ctx, name, args...)
+}
+
+// ToolVersion returns the version reported by an external tool, "available" when it runs but
+// reports no version, or "" when it cannot be run. Versions are looked up once per process.
+func ToolVersion(name string) string {
+	toolVersionsMu.Lock()
+	defer toolVersionsMu.Unlock()
+	if version, ok := toolVersions[name]; ok {
+		return version
+	}
+
+	version := ""
+	if path, err := exec.LookPath(name); err == nil {
+		version = "available"
+		// FFmpeg tools print "ffmpeg version 6.1.1 Copyright ..."; others answer --version
+		arg := "--version"
+		if strings.HasPrefix(name, "ff") {
+			arg = "-version"
+		}
+		if output, err := exec.Command(path, arg).Output(); err == nil {
+			version = parseToolVersion(name, string(output))
+		}
+	}
+	toolVersions[name] = version
+	return version
+}
+
+// ToolVersions returns the versions of several tools, leaving out tools that cannot be run
+func ToolVersions(names ...string) map[string]string {
+	versions := make(map[string]string, len(names))
+	for _, name := range names {
+		if version := ToolVersion(name); version != "" {
+			versions[name] = version
+		}
+	}
+	return versions
+}
+
+// parseToolVersion extracts the version number from the first line of a tool's version output
+func parseToolVersion(name, output string) string {
+	firstLine := strings.TrimSpace(strings.SplitN(output, "\n", 2)[0])
+	if rest, ok := strings.CutPrefix(firstLine, name+" version "); ok {
+		if fields := strings.Fields(rest); len(fields) > 0 {
+			return fields[0]
+		}
+	}
+	for _, field := range strings.Fields(firstLine) {
+		if field[0] >= '0' && field[0] <= '9' || (field[0] == 'v' && len(field) > 1 && field[1] >= '0' && field[1] <= '9') {
+			return field
+		}
+	}
+	return "available"
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsage(t *testing.T) {
+	// Recording without a usage context is a no-op
+	RecordTokens(context.Background(), 10, 0.1)
+
+	ctx, usage := WithUsage(context.Background())
+	RecordTokens(ctx, 100, 0.25)
+	RecordTokens(ctx, 50, 0.05)
+	toolVersionsMu.Lock()
+	toolVersions["fake-tool"] = "1.2.3"
+	toolVersionsMu.Unlock()
+	RecordTool(ctx, "fake-tool")
+
+	tokens, cost, tools := usage.Totals()
+	assert.Equal(t, 150, tokens)
+	assert.InDelta(t, 0.30, cost, 1e-9)
+	assert.Equal(t, map[string]string{"fake-tool": "1.2.3"}, tools)
+}
+
+func TestParseToolVersion(t *testing.T) {
+	assert.Equal(t, "6.1.1", parseToolVersion("ffmpeg", "ffmpeg version 6.1.1 Copyright (c) 2000-2023\nbuilt with gcc"))
+	assert.Equal(t, "v1.7.4", parseToolVersion("whisper-cli", "whisper.cpp v1.7.4\n"))
+	assert.Equal(t, "available", parseToolVersion("whisper", "usage: whisper [-h]"))
+}
@@ -34,8 +34,7 @@ func ValidateInputPath(input, output string, inputFileName string) error {
 		}
 	}
 
-	// Skip validation for files that will be created in the output directory
-	if strings.Contains(input, output) || strings.Contains(input, "output") {
+	if ProducedByWorkflow(input, output) {
 		return nil
 	}
 
@@ -73,6 +72,21 @@ func ValidateInputPath(input, output string, inputFileName string) error {
 	return nil
 }
 
+// ProducedByWorkflow reports whether path need not exist before the workflow runs: variables are
+// resolved by the workflow engine, and files inside the output directory are written by earlier steps
+func ProducedByWorkflow(path, output string) bool {
+	return strings.Contains(path, "${") || InOutputDir(path, output)
+}
+
+// InOutputDir reports whether path lies inside the output directory, where earlier steps write their files
+func InOutputDir(path, output string) bool {
+	if output == "" {
+		return false
+	}
+	rel, err := filepath.Rel(filepath.Clean(output), filepath.Clean(path))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
 // ValidateOutputPath validates an output path
 func ValidateOutputPath(output string) error {
 	if output == "" {
@@ -83,7 +97,7 @@ func ValidateOutputPath(output string) error {
 	}
 
 	// Create output directory if it doesn't exist
-	if err := os.MkdirAll(output, 0755); err != nil {
+	if err := EnsureDir(output); err != nil {
 		return &ValidationError{
 			Field:   "output",
 			Message: "failed to create output directory",
@@ -94,8 +108,9 @@ func ValidateOutputPath(output string) error {
 	return nil
 }
 
-// ValidateVideoFile validates a video file path and checks for FFmpeg
-func ValidateVideoFile(videoFile string) error {
+// ValidateVideoFile validates a video file path and checks for FFmpeg. Videos produced by the
+// workflow, inside output, need not exist yet.
+func ValidateVideoFile(videoFile, output string) error {
 	if videoFile == "" {
 		return &ValidationError{
 			Field:   "video",
@@ -104,7 +119,7 @@ func ValidateVideoFile(videoFile string) error {
 	}
 
 	// Verify the video file exists
-	if _, err := os.Stat(videoFile); os.IsNotExist(err) {
+	if _, err := os.Stat(videoFile); os.IsNotExist(err) && !ProducedByWorkflow(videoFile, output) {
 		return &ValidationError{
 			Field:   "video",
 			Message: fmt.Sprintf("video file does not exist: %s", videoFile),
@@ -158,7 +173,8 @@ func ValidateFileExtension(filePath string, allowedExts []string) error {
 	}
 }
 
-// ValidateTimestampFormat checks if a string matches the HH:MM:SS format
+// ValidateTimestampFormat checks if a string matches the HH:MM:SS format; hours may have more
+// than two digits
 func ValidateTimestampFormat(timestamp string) error {
 	parts := strings.Split(timestamp, ":")
 	if len(parts) != 3 {
@@ -170,16 +186,18 @@ func ValidateTimestampFormat(timestamp string) error {
 
 	// Validate each part
 	for i, part := range parts {
-		if len(part) != 2 {
+		if len(part) != 2 && (i > 0 || len(part) < 2) {
 			return &ValidationError{
 				Field:   "timestamp",
 				Message: fmt.Sprintf("invalid timestamp part %d: %s (expected 2 digits)", i+1, part),
 			}
 		}
-		if part[0] < '0' || part[0] > '9' || part[1] < '0' || part[1] > '9' {
-			return &ValidationError{
-				Field:   "timestamp",
-				Message: fmt.Sprintf("invalid timestamp part %d: %s (expected digits)", i+1, part),
+		for _, c := range part {
+			if c < '0' || c > '9' {
+				return &ValidationError{
+					Field:   "timestamp",
+					Message: fmt.Sprintf("invalid timestamp part %d: %s (expected digits)", i+1, part),
+				}
 			}
 		}
 	}
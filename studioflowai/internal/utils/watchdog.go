@@ -0,0 +1,286 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// CommandWatchdog is the inactivity limit of an external tool
+type CommandWatchdog struct {
+	Idle    time.Duration // Time without any output after which the process is killed; 0 disables the watchdog
+	Retries int           // Times a killed process is started again before giving up
+}
+
+// HungError is returned when a command was killed because it stopped producing output
+type HungError struct {
+	Command  string
+	Idle     time.Duration
+	Attempts int
+}
+
+func (e *HungError) Error() string {
+	return fmt.Sprintf("%s produced no output for %s and was killed after %d attempt(s)", e.Command, e.Idle, e.Attempts)
+}
+
+var (
+	watchdogsMu sync.Mutex
+	watchdogs   = DefaultCommandWatchdogs()
+
+	// watchdogPoll is how often a running command is checked for inactivity
+	watchdogPoll = 5 * time.Second
+
+	// ffmpegProgressLine matches the key=value lines printed by ffmpeg's -progress option
+	ffmpegProgressLine = regexp.MustCompile(`^[a-z0-9_]+=\S*$`)
+)
+
+// DefaultCommandWatchdogs returns the inactivity limits of the tools known to hang. FFmpeg reports
+// progress every half second while it runs; Whisper can stay quiet while it decodes a long window.
+func DefaultCommandWatchdogs() map[string]CommandWatchdog {
+	return map[string]CommandWatchdog{
+		"ffmpeg":      {Idle: 10 * time.Minute, Retries: 1},
+		"ffprobe":     {Idle: 5 * time.Minute, Retries: 1},
+		"whisper":     {Idle: 30 * time.Minute, Retries: 1},
+		"whisper-cli": {Idle: 30 * time.Minute, Retries: 1},
+	}
+}
+
+// SetCommandWatchdog sets the inactivity limit of a tool; an Idle of 0 disables its watchdog
+func SetCommandWatchdog(name string, watchdog CommandWatchdog) {
+	watchdogsMu.Lock()
+	defer watchdogsMu.Unlock()
+	watchdogs[name] = watchdog
+}
+
+// CommandWatchdogFor returns the inactivity limit of a tool, if any
+func CommandWatchdogFor(name string) CommandWatchdog {
+	watchdogsMu.Lock()
+	defer watchdogsMu.Unlock()
+	return watchdogs[name]
+}
+
+// RunWatched runs cmd like cmd.Run. When the tool has a watchdog and the process prints nothing on
+// stdout or stderr for its idle limit, the process is killed and started again up to Retries times.
+// FFmpeg is asked for -progress reports so long quiet encodes still count as activity; the reports
+// are kept out of the command's stderr. A restarted ffmpeg overwrites the output of the killed one.
+// The command is written to the run's command log, if any.
+func RunWatched(ctx context.Context, cmd *exec.Cmd) error {
+	finish := logCommand(ctx, cmd)
+	err := runWatched(ctx, cmd)
+	finish(err)
+	return err
+}
+
+// runWatched runs cmd under the watchdog of its tool
+func runWatched(ctx context.Context, cmd *exec.Cmd) error {
+	name := commandName(cmd)
+	watchdog := CommandWatchdogFor(name)
+	if watchdog.Idle <= 0 {
+		return cmd.Run()
+	}
+
+	progress := name == "ffmpeg" && !containsString(cmd.Args, "-progress")
+	if progress {
+		cmd.Args = append([]string{cmd.Args[0], "-progress", "pipe:2"}, cmd.Args[1:]...)
+	}
+
+	for attempt := 1; ; attempt++ {
+		hung, err := runWithWatchdog(cmd, watchdog.Idle, progress)
+		if !hung {
+			return err
+		}
+		// A process reading stdin cannot be replayed
+		if attempt > watchdog.Retries || cmd.Stdin != nil || ctx.Err() != nil {
+			return &HungError{Command: name, Idle: watchdog.Idle, Attempts: attempt}
+		}
+		LogWarningContext(ctx, "%s produced no output for %s, restarting it (attempt %d of %d)",
+			name, watchdog.Idle, attempt+1, watchdog.Retries+1)
+		cmd = restartCommand(ctx, cmd)
+	}
+}
+
+// OutputWatched runs cmd like cmd.Output under the watchdog of RunWatched
+func OutputWatched(ctx context.Context, cmd *exec.Cmd) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	captureStderr := cmd.Stderr == nil
+	if captureStderr {
+		cmd.Stderr = &stderr
+	}
+	err := RunWatched(ctx, cmd)
+	var exitErr *exec.ExitError
+	if captureStderr && errors.As(err, &exitErr) {
+		exitErr.Stderr = stderr.Bytes()
+	}
+	return stdout.Bytes(), err
+}
+
+// CombinedOutputWatched runs cmd like cmd.CombinedOutput under the watchdog of RunWatched
+func CombinedOutputWatched(ctx context.Context, cmd *exec.Cmd) ([]byte, error) {
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err := RunWatched(ctx, cmd)
+	return output.Bytes(), err
+}
+
+// commandName returns the tool name a command was started with
+func commandName(cmd *exec.Cmd) string {
+	if len(cmd.Args) > 0 {
+		return filepath.Base(cmd.Args[0])
+	}
+	return filepath.Base(cmd.Path)
+}
+
+// runWithWatchdog runs cmd once, killing it when its output stays silent for idle
+func runWithWatchdog(cmd *exec.Cmd, idle time.Duration, progress bool) (bool, error) {
+	monitor := &activityMonitor{last: time.Now()}
+	stdout, stderr := cmd.Stdout, cmd.Stderr
+	defer func() { cmd.Stdout, cmd.Stderr = stdout, stderr }()
+
+	var writers []*activityWriter
+	if stdout != nil && sameWriter(stdout, stderr) {
+		// Both streams share one writer, which must not be written concurrently
+		w := &activityWriter{monitor: monitor, dest: stdout, filter: progress}
+		cmd.Stdout, cmd.Stderr = w, w
+		writers = append(writers, w)
+	} else {
+		out := &activityWriter{monitor: monitor, dest: stdout}
+		errOut := &activityWriter{monitor: monitor, dest: stderr, filter: progress}
+		cmd.Stdout, cmd.Stderr = out, errOut
+		writers = append(writers, out, errOut)
+	}
+	// Do not wait forever for pipes held open by children of a killed process
+	if cmd.WaitDelay == 0 {
+		cmd.WaitDelay = 5 * time.Second
+	}
+
+	if err := cmd.Start(); err != nil {
+		return false, err
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	ticker := time.NewTicker(watchdogPoll)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-done:
+			for _, w := range writers {
+				w.flush()
+			}
+			return false, err
+		case <-ticker.C:
+			if monitor.idleFor() >= idle {
+				_ = cmd.Process.Kill()
+				<-done
+				return true, nil
+			}
+		}
+	}
+}
+
+// restartCommand returns a fresh copy of a finished command, emptying the buffers it wrote to
+func restartCommand(ctx context.Context, cmd *exec.Cmd) *exec.Cmd {
+	next := exec.CommandContext(ctx, cmd.Path)
+	next.Args = cmd.Args
+	// The killed ffmpeg left a partial output, which the new attempt must replace
+	if commandName(cmd) == "ffmpeg" && !containsString(cmd.Args, "-y") {
+		next.Args = append([]string{cmd.Args[0], "-y"}, cmd.Args[1:]...)
+	}
+	next.Dir = cmd.Dir
+	next.Env = cmd.Env
+	next.Stdout = cmd.Stdout
+	next.Stderr = cmd.Stderr
+	next.WaitDelay = cmd.WaitDelay
+	for _, w := range []io.Writer{cmd.Stdout, cmd.Stderr} {
+		if buf, ok := w.(*bytes.Buffer); ok {
+			buf.Reset()
+		}
+	}
+	return next
+}
+
+// sameWriter reports whether two writers are the same value, as os/exec decides it
+func sameWriter(a, b io.Writer) (same bool) {
+	defer func() {
+		if recover() != nil {
+			same = false
+		}
+	}()
+	return a == b
+}
+
+// activityMonitor records when a process last printed anything
+type activityMonitor struct {
+	mu   sync.Mutex
+	last time.Time
+}
+
+func (m *activityMonitor) touch() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.last = time.Now()
+}
+
+func (m *activityMonitor) idleFor() time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return time.Since(m.last)
+}
+
+// activityWriter forwards a process's output to its original destination, recording activity
+// and, when filter is set, dropping ffmpeg -progress lines
+type activityWriter struct {
+	monitor *activityMonitor
+	dest    io.Writer
+	filter  bool
+
+	mu   sync.Mutex
+	line []byte
+}
+
+func (w *activityWriter) Write(p []byte) (int, error) {
+	w.monitor.touch()
+	if w.dest == nil {
+		return len(p), nil
+	}
+	if !w.filter {
+		return w.dest.Write(p)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.line = append(w.line, p...)
+	for {
+		// ffmpeg's own stats end in \r, so split on both to keep them flowing
+		i := bytes.IndexAny(w.line, "\r\n")
+		if i < 0 {
+			break
+		}
+		if !ffmpegProgressLine.Match(w.line[:i]) {
+			if _, err := w.dest.Write(w.line[:i+1]); err != nil {
+				return 0, err
+			}
+		}
+		w.line = w.line[i+1:]
+	}
+	return len(p), nil
+}
+
+// flush writes any unterminated output left when the process exits
+func (w *activityWriter) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.line) > 0 && w.dest != nil && !ffmpegProgressLine.Match(w.line) {
+		_, _ = w.dest.Write(w.line)
+	}
+	w.line = nil
+}
@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withWatchdog sets a short watchdog for sh for the duration of a test
+func withWatchdog(t *testing.T, watchdog CommandWatchdog) {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	previousPoll := watchdogPoll
+	watchdogPoll = 20 * time.Millisecond
+	SetCommandWatchdog("sh", watchdog)
+	t.Cleanup(func() {
+		watchdogPoll = previousPoll
+		watchdogsMu.Lock()
+		delete(watchdogs, "sh")
+		watchdogsMu.Unlock()
+	})
+}
+
+func TestRunWatchedKillsAndRetriesSilentCommand(t *testing.T) {
+	withWatchdog(t, CommandWatchdog{Idle: 200 * time.Millisecond, Retries: 1})
+	starts := filepath.Join(t.TempDir(), "starts")
+
+	ctx := context.Background()
+	cmd := exec.CommandContext(ctx, "sh", "-c", "echo started >> "+starts+"; echo working; exec sleep 10")
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+
+	begin := time.Now()
+	err := RunWatched(ctx, cmd)
+	var hung *HungError
+	require.ErrorAs(t, err, &hung)
+	assert.Equal(t, 2, hung.Attempts)
+	assert.Less(t, time.Since(begin), 5*time.Second)
+
+	data, err := os.ReadFile(starts)
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(data), "started"))
+	assert.Equal(t, "working\n", stdout.String(), "output of the killed attempt is discarded")
+}
+
+func TestRunWatchedRestartedFFmpegOverwrites(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	// A fake ffmpeg that hangs like one refusing to replace its output, unless given -y
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+	script := "#!/bin/sh\necho \"$@\" >> " + calls + "\ncase \" $* \" in *\" -y \"*) exit 0;; esac\nexec sleep 10\n"
+	ffmpeg := filepath.Join(dir, "ffmpeg")
+	require.NoError(t, os.WriteFile(ffmpeg, []byte(script), 0755))
+
+	previousPoll := watchdogPoll
+	watchdogPoll = 20 * time.Millisecond
+	SetCommandWatchdog("ffmpeg", CommandWatchdog{Idle: 200 * time.Millisecond, Retries: 1})
+	t.Cleanup(func() {
+		watchdogPoll = previousPoll
+		watchdogsMu.Lock()
+		delete(watchdogs, "ffmpeg")
+		watchdogsMu.Unlock()
+	})
+
+	ctx := context.Background()
+	require.NoError(t, RunWatched(ctx, exec.CommandContext(ctx, ffmpeg, "-i", "in.mp4", "out.mp4")))
+
+	data, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	assert.NotContains(t, lines[0], "-y")
+	assert.Equal(t, "-y -progress pipe:2 -i in.mp4 out.mp4", lines[1])
+}
+
+func TestRunWatchedKeepsActiveCommandAlive(t *testing.T) {
+	withWatchdog(t, CommandWatchdog{Idle: 300 * time.Millisecond})
+
+	ctx := context.Background()
+	cmd := exec.CommandContext(ctx, "sh", "-c", "for i in 1 2 3 4 5 6; do echo tick >&2; sleep 0.1; done; echo done")
+	output, err := CombinedOutputWatched(ctx, cmd)
+	require.NoError(t, err)
+	assert.Equal(t, 6, strings.Count(string(output), "tick"))
+	assert.Contains(t, string(output), "done")
+}
+
+func TestRunWatchedWithoutWatchdog(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	ctx := context.Background()
+	output, err := OutputWatched(ctx, exec.CommandContext(ctx, "sh", "-c", "echo plain"))
+	require.NoError(t, err)
+	assert.Equal(t, "plain\n", string(output))
+}
+
+func TestActivityWriterDropsProgressLines(t *testing.T) {
+	var dest bytes.Buffer
+	w := &activityWriter{monitor: &activityMonitor{}, dest: &dest, filter: true}
+
+	for _, chunk := range []string{
+		"frame=12\nfps=24.0\nout_time=00:00:01.5",
+		"00000\nprogress=continue\n[mp4 @ 0x1] error while writing\n",
+		"frame=   42 fps= 24 q=28.0 size=  256kB\r",
+		"speed=1.2x\nunterminated",
+	} {
+		n, err := w.Write([]byte(chunk))
+		require.NoError(t, err)
+		assert.Equal(t, len(chunk), n)
+	}
+	w.flush()
+
+	assert.Equal(t, "[mp4 @ 0x1] error while writing\nframe=   42 fps= 24 q=28.0 size=  256kB\runterminated", dest.String())
+}
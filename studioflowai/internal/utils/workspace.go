@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Workspace describes where the active project keeps its tokens, prompts and state
+type Workspace struct {
+	Name       string // Project name, empty for the global workspace
+	ConfigDir  string // Directory holding tokens and quota state
+	PromptsDir string // Directory with prompt files overriding the defaults, if any
+}
+
+var (
+	workspaceMu sync.RWMutex
+	workspace   *Workspace
+)
+
+// SetWorkspace activates a project workspace; nil restores the global one
+func SetWorkspace(ws *Workspace) {
+	workspaceMu.Lock()
+	defer workspaceMu.Unlock()
+	workspace = ws
+}
+
+// ActiveWorkspace returns the active project workspace, or nil when none is selected
+func ActiveWorkspace() *Workspace {
+	workspaceMu.RLock()
+	defer workspaceMu.RUnlock()
+	return workspace
+}
+
+// GlobalConfigDir returns ~/.studioflowai
+func GlobalConfigDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".studioflowai"), nil
+}
+
+// ConfigDir returns the directory for tokens and state of the active workspace.
+// Each project gets its own directory so credentials never leak between clients.
+func ConfigDir() (string, error) {
+	if ws := ActiveWorkspace(); ws != nil && ws.ConfigDir != "" {
+		return ws.ConfigDir, nil
+	}
+	return GlobalConfigDir()
+}
+
+// ResolvePromptPath returns the active project's copy of a default prompt file when it has one
+func ResolvePromptPath(defaultPath string) string {
+	ws := ActiveWorkspace()
+	if ws == nil || ws.PromptsDir == "" {
+		return defaultPath
+	}
+	candidate := filepath.Join(ws.PromptsDir, filepath.Base(defaultPath))
+	if _, err := os.Stat(candidate); err == nil {
+		return candidate
+	}
+	return defaultPath
+}
+
+// ResolveProjectPath replaces ${project} with the active project's directory
+func ResolveProjectPath(path string) (string, error) {
+	if !strings.Contains(path, "${project}") {
+		return path, nil
+	}
+	ws := ActiveWorkspace()
+	if ws == nil {
+		return "", fmt.Errorf("%s uses ${project} but no project is selected (use --project)", path)
+	}
+	return strings.ReplaceAll(path, "${project}", ws.ConfigDir), nil
+}
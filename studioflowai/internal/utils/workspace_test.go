@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkspace(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Cleanup(func() { SetWorkspace(nil) })
+
+	projectDir := filepath.Join(home, ".studioflowai", "projects", "acme")
+	promptsDir := filepath.Join(projectDir, "prompts")
+	require.NoError(t, os.MkdirAll(promptsDir, 0755))
+	override := filepath.Join(promptsDir, "suggest_shorts.yaml")
+	require.NoError(t, os.WriteFile(override, []byte("prompt"), 0644))
+
+	// The global workspace
+	assert.Nil(t, ActiveWorkspace())
+	dir, err := ConfigDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".studioflowai"), dir)
+	assert.Equal(t, "prompts/suggest_shorts.yaml", ResolvePromptPath("prompts/suggest_shorts.yaml"))
+	_, err = ResolveProjectPath("${project}/credentials.json")
+	assert.ErrorContains(t, err, "no project is selected")
+	path, err := ResolveProjectPath("./credentials.json")
+	require.NoError(t, err, "paths without ${project} need no project")
+	assert.Equal(t, "./credentials.json", path)
+
+	// A project workspace
+	SetWorkspace(&Workspace{Name: "acme", ConfigDir: projectDir, PromptsDir: promptsDir})
+	dir, err = ConfigDir()
+	require.NoError(t, err)
+	assert.Equal(t, projectDir, dir)
+	assert.Equal(t, override, ResolvePromptPath("prompts/suggest_shorts.yaml"))
+	assert.Equal(t, "prompts/blog_post.yaml", ResolvePromptPath("prompts/blog_post.yaml"))
+	path, err = ResolveProjectPath("${project}/credentials.json")
+	require.NoError(t, err)
+	assert.Equal(t, projectDir+"/credentials.json", path)
+
+	// Back to the global workspace
+	SetWorkspace(nil)
+	dir, err = ConfigDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".studioflowai"), dir)
+	assert.Equal(t, "prompts/suggest_shorts.yaml", ResolvePromptPath("prompts/suggest_shorts.yaml"))
+}
@@ -0,0 +1,147 @@
+package validator
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/services/tiktok"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/services/youtube"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"golang.org/x/oauth2"
+)
+
+// AuthOptions identifies the accounts whose credentials are reported
+type AuthOptions struct {
+	YouTubeCredentials string // Google OAuth client credentials file
+	YouTubeChannel     string // Channel name, for reference
+	TikTokUsername     string // Account name, for reference
+}
+
+// PlatformAuth describes the stored credentials of one platform, read without calling its API
+type PlatformAuth struct {
+	Platform   string
+	Account    string    // Channel or user name, when known
+	Configured bool      // The keys or client credentials the platform needs are present
+	Authorized bool      // Calls can be made without an interactive login
+	Details    string    // What is set, missing or expired
+	Expiry     time.Time // Expiry of the stored access token; zero when there is none or it never expires
+	Scopes     []string  // OAuth scopes requested by the login flow
+}
+
+// authPlatforms lists the platforms reported by AuthStatus, in report order. A new platform only
+// needs an entry here and a login flow in the auth command.
+var authPlatforms = []struct {
+	name   string
+	status func(AuthOptions) PlatformAuth
+}{
+	{name: "openai", status: openAIAuth},
+	{name: "youtube", status: youTubeAuth},
+	{name: "tiktok", status: tikTokAuth},
+}
+
+// AuthStatus reports the credentials and stored tokens of every platform for the active workspace
+func AuthStatus(opts AuthOptions) []PlatformAuth {
+	statuses := make([]PlatformAuth, 0, len(authPlatforms))
+	for _, p := range authPlatforms {
+		status := p.status(opts)
+		status.Platform = p.name
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// openAIAuth reports the API key; OpenAI has no login flow
+func openAIAuth(AuthOptions) PlatformAuth {
+	if os.Getenv("OPENAI_API_KEY") == "" {
+		return PlatformAuth{Details: "OPENAI_API_KEY is not set"}
+	}
+	return PlatformAuth{Configured: true, Authorized: true, Details: "OPENAI_API_KEY is set"}
+}
+
+// youTubeAuth reports the client credentials file and the stored token, which refreshes itself
+func youTubeAuth(opts AuthOptions) PlatformAuth {
+	status := PlatformAuth{Account: opts.YouTubeChannel, Scopes: youtube.RequiredScopes()}
+
+	if opts.YouTubeCredentials == "" {
+		status.Details = "no credentials file configured (use --youtube-credentials or the project's accounts.youtube.credentials)"
+		return status
+	}
+	if _, err := os.Stat(opts.YouTubeCredentials); err != nil {
+		status.Details = fmt.Sprintf("credentials file not found: %s", opts.YouTubeCredentials)
+		return status
+	}
+	status.Configured = true
+
+	token, err := loadStoredToken("youtube")
+	if err != nil {
+		status.Details = err.Error()
+		return status
+	}
+	describeToken(&status, token, token != nil && token.RefreshToken != "")
+	return status
+}
+
+// tikTokAuth reports the client keys and the stored token, which is not refreshed before it expires
+func tikTokAuth(opts AuthOptions) PlatformAuth {
+	status := PlatformAuth{Account: opts.TikTokUsername, Scopes: tiktok.DefaultOAuthConfig().Scopes}
+
+	var missing []string
+	for _, envVar := range []string{"TIKTOK_CLIENT_KEY", "TIKTOK_CLIENT_SECRET"} {
+		if os.Getenv(envVar) == "" {
+			missing = append(missing, envVar)
+		}
+	}
+	if len(missing) > 0 {
+		status.Details = fmt.Sprintf("%s not set", strings.Join(missing, " and "))
+		return status
+	}
+	status.Configured = true
+
+	token, err := loadStoredToken("tiktok")
+	if err != nil {
+		status.Details = err.Error()
+		return status
+	}
+	describeToken(&status, token, false)
+	return status
+}
+
+// loadStoredToken reads the OAuth token of a platform from the config directory of the active workspace
+func loadStoredToken(platform string) (*oauth2.Token, error) {
+	tokenStorage, err := utils.NewTokenStorage()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open token storage: %w", err)
+	}
+	token, err := tokenStorage.LoadToken(platform)
+	if err != nil {
+		return nil, fmt.Errorf("stored token is unreadable: %w", err)
+	}
+	return token, nil
+}
+
+// describeToken fills in whether a stored token can be used without logging in again
+func describeToken(status *PlatformAuth, token *oauth2.Token, refreshable bool) {
+	switch {
+	case token == nil:
+		status.Details = "no token stored"
+	case token.Valid():
+		status.Authorized = true
+		status.Expiry = token.Expiry
+		status.Details = "token valid"
+		if !token.Expiry.IsZero() {
+			status.Details = fmt.Sprintf("token valid until %s", token.Expiry.Local().Format("2006-01-02 15:04"))
+		}
+		if refreshable {
+			status.Details += ", refreshes automatically"
+		}
+	case refreshable:
+		status.Authorized = true
+		status.Expiry = token.Expiry
+		status.Details = "access token expired, refreshes automatically on the next call"
+	default:
+		status.Expiry = token.Expiry
+		status.Details = fmt.Sprintf("token expired on %s", token.Expiry.Local().Format("2006-01-02 15:04"))
+	}
+}
@@ -0,0 +1,386 @@
+package validator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/option"
+	"google.golang.org/api/youtube/v3"
+)
+
+// CheckStatus is the outcome of a single doctor check
+type CheckStatus string
+
+const (
+	CheckOK   CheckStatus = "ok"
+	CheckWarn CheckStatus = "warn"
+	CheckFail CheckStatus = "fail"
+	CheckSkip CheckStatus = "skip"
+)
+
+// CheckResult describes the outcome of one readiness check
+type CheckResult struct {
+	Group   string
+	Name    string
+	Status  CheckStatus
+	Details string
+}
+
+// DoctorOptions configures the readiness checks
+type DoctorOptions struct {
+	OutputDirs         []string      // Directories that must be writable (default: ./output)
+	YouTubeCredentials string        // Google OAuth client credentials file; YouTube is skipped when empty
+	Offline            bool          // Skip checks that call remote APIs
+	Timeout            time.Duration // Timeout for each remote check (default: 15s)
+}
+
+// doctorTool describes an external tool and how to read its version
+type doctorTool struct {
+	Name        string
+	VersionArgs []string
+	Required    bool
+	Purpose     string
+}
+
+// doctorTools lists the external tools reported by the doctor
+var doctorTools = []doctorTool{
+	{Name: "ffmpeg", VersionArgs: []string{"-version"}, Required: true, Purpose: "audio extraction and video rendering"},
+	{Name: "ffprobe", VersionArgs: []string{"-version"}, Required: true, Purpose: "media inspection"},
+	{Name: "whisper", VersionArgs: []string{"--help"}, Required: false, Purpose: "local transcription"},
+}
+
+// RunDoctor runs all readiness checks and returns their results in report order
+func RunDoctor(ctx context.Context, opts DoctorOptions) []CheckResult {
+	if len(opts.OutputDirs) == 0 {
+		opts.OutputDirs = []string{"./output"}
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 15 * time.Second
+	}
+
+	var results []CheckResult
+	for _, tool := range doctorTools {
+		results = append(results, checkTool(tool))
+	}
+
+	results = append(results, checkOpenAI(ctx, opts))
+	results = append(results, checkYouTube(ctx, opts))
+	results = append(results, checkTikTok(ctx, opts))
+
+	for _, dir := range opts.OutputDirs {
+		results = append(results, checkWritable(dir))
+	}
+	if configDir, err := utils.ConfigDir(); err == nil {
+		results = append(results, checkWritable(configDir))
+	}
+
+	return results
+}
+
+// checkTool verifies that a tool is in PATH and reports its version
+func checkTool(tool doctorTool) CheckResult {
+	result := CheckResult{Group: "Tools", Name: tool.Name}
+
+	missing := CheckWarn
+	if tool.Required {
+		missing = CheckFail
+	}
+
+	path, err := exec.LookPath(tool.Name)
+	if err != nil {
+		result.Status = missing
+		result.Details = fmt.Sprintf("not found in PATH (needed for %s)", tool.Purpose)
+		return result
+	}
+
+	output, err := exec.Command(path, tool.VersionArgs...).CombinedOutput()
+	if err != nil && len(output) == 0 {
+		result.Status = missing
+		result.Details = fmt.Sprintf("found at %s but failed to run: %v", path, err)
+		return result
+	}
+
+	result.Status = CheckOK
+	result.Details = fmt.Sprintf("%s (%s)", toolVersion(tool.Name, string(output)), path)
+	return result
+}
+
+// toolVersion extracts a short version string from a tool's version output
+func toolVersion(name, output string) string {
+	firstLine := strings.TrimSpace(strings.SplitN(output, "\n", 2)[0])
+	prefix := name + " version "
+	if strings.HasPrefix(firstLine, prefix) {
+		fields := strings.Fields(strings.TrimPrefix(firstLine, prefix))
+		if len(fields) > 0 {
+			return "version " + fields[0]
+		}
+	}
+	// whisper has no version flag; its help output only confirms it runs
+	return "available"
+}
+
+// checkOpenAI lists the models of the account, which costs no tokens
+func checkOpenAI(ctx context.Context, opts DoctorOptions) CheckResult {
+	result := CheckResult{Group: "Credentials", Name: "OpenAI"}
+
+	apiKey := os.Getenv("OPENAI_API_KEY")
+	if apiKey == "" {
+		result.Status = CheckFail
+		result.Details = "OPENAI_API_KEY environment variable is not set"
+		return result
+	}
+	if opts.Offline {
+		result.Status = CheckSkip
+		result.Details = "OPENAI_API_KEY is set (not verified, offline mode)"
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.openai.com/v1/models", nil)
+	if err != nil {
+		result.Status = CheckFail
+		result.Details = fmt.Sprintf("failed to create request: %v", err)
+		return result
+	}
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	if org := os.Getenv("OPENAI_ORG_ID"); org != "" {
+		req.Header.Set("OpenAI-Organization", org)
+	}
+	if project := os.Getenv("OPENAI_PROJECT_ID"); project != "" {
+		req.Header.Set("OpenAI-Project", project)
+	}
+
+	status, err := doCheckRequest(req)
+	switch {
+	case err != nil:
+		result.Status = CheckFail
+		result.Details = fmt.Sprintf("request failed: %v", err)
+	case status == http.StatusOK:
+		result.Status = CheckOK
+		result.Details = "API key accepted"
+	case status == http.StatusUnauthorized:
+		result.Status = CheckFail
+		result.Details = "API key rejected (401 Unauthorized)"
+	default:
+		result.Status = CheckFail
+		result.Details = fmt.Sprintf("API returned status %d", status)
+	}
+	return result
+}
+
+// checkYouTube reads the own channel with the stored token, which costs 1 quota unit
+func checkYouTube(ctx context.Context, opts DoctorOptions) CheckResult {
+	result := CheckResult{Group: "Credentials", Name: "YouTube"}
+
+	if opts.YouTubeCredentials == "" {
+		result.Status = CheckSkip
+		result.Details = "no credentials file given (use --youtube-credentials)"
+		return result
+	}
+
+	credentials, err := os.ReadFile(opts.YouTubeCredentials)
+	if err != nil {
+		result.Status = CheckFail
+		result.Details = fmt.Sprintf("failed to read credentials file: %v", err)
+		return result
+	}
+	config, err := google.ConfigFromJSON(credentials, youtube.YoutubeReadonlyScope)
+	if err != nil {
+		result.Status = CheckFail
+		result.Details = fmt.Sprintf("invalid credentials file: %v", err)
+		return result
+	}
+
+	tokenStorage, err := utils.NewTokenStorage()
+	if err != nil {
+		result.Status = CheckFail
+		result.Details = fmt.Sprintf("failed to open token storage: %v", err)
+		return result
+	}
+	token, err := tokenStorage.LoadToken("youtube")
+	if err != nil {
+		result.Status = CheckFail
+		result.Details = fmt.Sprintf("failed to load token: %v", err)
+		return result
+	}
+	if token == nil {
+		result.Status = CheckWarn
+		result.Details = "credentials file is valid but no authorization token is stored; the first upload will open the browser"
+		return result
+	}
+	if !token.Valid() && token.RefreshToken == "" {
+		result.Status = CheckWarn
+		result.Details = "stored authorization token has expired; the next upload will open the browser"
+		return result
+	}
+	if opts.Offline {
+		result.Status = CheckSkip
+		result.Details = "credentials and token found (not verified, offline mode)"
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, utils.NewHTTPClient())
+
+	// Never start the interactive OAuth flow here; only the stored token is used
+	service, err := youtube.NewService(ctx, option.WithHTTPClient(oauth2.NewClient(ctx, config.TokenSource(ctx, token))))
+	if err != nil {
+		result.Status = CheckFail
+		result.Details = fmt.Sprintf("failed to create YouTube service: %v", err)
+		return result
+	}
+
+	resp, err := service.Channels.List([]string{"snippet"}).Mine(true).Context(ctx).Do()
+	if err != nil {
+		result.Status = CheckFail
+		result.Details = fmt.Sprintf("channels.list failed: %v", err)
+		return result
+	}
+	if len(resp.Items) == 0 {
+		result.Status = CheckWarn
+		result.Details = "token is valid but the account has no YouTube channel"
+		return result
+	}
+
+	result.Status = CheckOK
+	result.Details = fmt.Sprintf("authorized for channel %q", resp.Items[0].Snippet.Title)
+	return result
+}
+
+// checkTikTok verifies the client credentials and, when a token is stored, queries the user info
+func checkTikTok(ctx context.Context, opts DoctorOptions) CheckResult {
+	result := CheckResult{Group: "Credentials", Name: "TikTok"}
+
+	var missing []string
+	for _, envVar := range []string{"TIKTOK_CLIENT_KEY", "TIKTOK_CLIENT_SECRET"} {
+		if os.Getenv(envVar) == "" {
+			missing = append(missing, envVar)
+		}
+	}
+	if len(missing) == 2 {
+		result.Status = CheckSkip
+		result.Details = "TIKTOK_CLIENT_KEY and TIKTOK_CLIENT_SECRET are not set"
+		return result
+	}
+	if len(missing) > 0 {
+		result.Status = CheckFail
+		result.Details = fmt.Sprintf("%s is not set", strings.Join(missing, ", "))
+		return result
+	}
+
+	configDir, err := utils.ConfigDir()
+	if err != nil {
+		result.Status = CheckFail
+		result.Details = err.Error()
+		return result
+	}
+	data, err := os.ReadFile(filepath.Join(configDir, "tiktok_token.json"))
+	if err != nil {
+		result.Status = CheckWarn
+		result.Details = "client credentials are set but no authorization token is stored; the first upload will open the browser"
+		return result
+	}
+	var token oauth2.Token
+	if err := json.Unmarshal(data, &token); err != nil {
+		result.Status = CheckWarn
+		result.Details = fmt.Sprintf("stored authorization token is unreadable: %v", err)
+		return result
+	}
+	if !token.Valid() {
+		result.Status = CheckWarn
+		result.Details = "stored authorization token has expired; the next upload will open the browser"
+		return result
+	}
+	if opts.Offline {
+		result.Status = CheckSkip
+		result.Details = "client credentials and token found (not verified, offline mode)"
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://open.tiktokapis.com/v2/user/info/?fields=open_id,display_name", nil)
+	if err != nil {
+		result.Status = CheckFail
+		result.Details = fmt.Sprintf("failed to create request: %v", err)
+		return result
+	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+
+	status, err := doCheckRequest(req)
+	switch {
+	case err != nil:
+		result.Status = CheckFail
+		result.Details = fmt.Sprintf("request failed: %v", err)
+	case status == http.StatusOK:
+		result.Status = CheckOK
+		result.Details = "access token accepted"
+	case status == http.StatusUnauthorized:
+		result.Status = CheckWarn
+		result.Details = "access token rejected; the next upload will open the browser"
+	default:
+		result.Status = CheckFail
+		result.Details = fmt.Sprintf("API returned status %d", status)
+	}
+	return result
+}
+
+// doCheckRequest sends a check request and returns the HTTP status code
+func doCheckRequest(req *http.Request) (int, error) {
+	resp, err := utils.NewHTTPClient().Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			utils.LogWarning("Failed to close response body: %v", err)
+		}
+	}()
+	// Drain the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
+
+// checkWritable verifies that files can be created in a directory, creating it if needed
+func checkWritable(dir string) CheckResult {
+	result := CheckResult{Group: "Storage", Name: dir}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		result.Status = CheckFail
+		result.Details = fmt.Sprintf("cannot create directory: %v", err)
+		return result
+	}
+
+	f, err := os.CreateTemp(dir, ".studioflowai-doctor-*")
+	if err != nil {
+		result.Status = CheckFail
+		result.Details = fmt.Sprintf("not writable: %v", err)
+		return result
+	}
+	name := f.Name()
+	if err := f.Close(); err != nil {
+		utils.LogWarning("Failed to close %s: %v", name, err)
+	}
+	if err := os.Remove(name); err != nil {
+		utils.LogWarning("Failed to remove %s: %v", name, err)
+	}
+
+	result.Status = CheckOK
+	result.Details = "writable"
+	return result
+}
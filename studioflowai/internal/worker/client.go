@@ -0,0 +1,276 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	modules "github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// Client sends steps to a worker
+type Client struct {
+	addr  string
+	token string
+	conn  *grpc.ClientConn
+}
+
+// Dial returns a client of the worker at addr, sending token when it is set. The connection is made
+// on the first call.
+func Dial(addr, token string, opts ...grpc.DialOption) (*Client, error) {
+	opts = append([]grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(codec{}), grpc.MaxCallRecvMsgSize(maxMessageSize)),
+	}, opts...)
+	conn, err := grpc.NewClient(addr, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid worker address %q: %w", addr, err)
+	}
+	return &Client{addr: addr, token: token, conn: conn}, nil
+}
+
+// Close closes the connection to the worker
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Addr returns the address of the worker
+func (c *Client) Addr() string {
+	return c.addr
+}
+
+// authorized adds the client's token to the metadata of the calls made with ctx
+func (c *Client) authorized(ctx context.Context) context.Context {
+	if c.token == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+c.token)
+}
+
+// Info asks the worker which modules it runs
+func (c *Client) Info(ctx context.Context) (*InfoResponse, error) {
+	info := new(InfoResponse)
+	if err := c.conn.Invoke(c.authorized(ctx), "/"+ServiceName+"/Info", &InfoRequest{}, info); err != nil {
+		return nil, fmt.Errorf("worker %s: %w", c.addr, err)
+	}
+	return info, nil
+}
+
+// Run runs the step of job on the worker with params. The local files and folders named by the
+// parameters are sent with it, as are the files of the output folder they name; the files the step
+// writes are received into the output folder, and the paths of its result point to them.
+func (c *Client) Run(ctx context.Context, job Job, params map[string]interface{}) (modules.ModuleResult, error) {
+	step := job.Step
+	output, _ := params["output"].(string)
+	transfer, err := newTransfer(output)
+	if err != nil {
+		return modules.ModuleResult{}, err
+	}
+	job.Params = make(map[string]interface{}, len(params))
+	job.RetryItems = nil
+	for key, value := range params {
+		if key != "output" {
+			job.Params[key] = mapStrings(value, transfer.remote)
+		}
+	}
+	for id := range modules.RetryItems(ctx) {
+		job.RetryItems = append(job.RetryItems, id)
+	}
+	sort.Strings(job.RetryItems)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.conn.NewStream(c.authorized(ctx), &serviceDesc.Streams[0], "/"+ServiceName+"/Run")
+	if err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("worker %s: %w", c.addr, err)
+	}
+
+	// Inputs go up while nothing comes back, so they are sent before reading
+	if err := transfer.send(stream, &job); err != nil {
+		return modules.ModuleResult{}, fmt.Errorf("failed to send step %s to worker %s: %w", step, c.addr, err)
+	}
+
+	receiver := newFileReceiver(output)
+	defer receiver.abort()
+	logs := utils.LogWriter(ctx)
+	for {
+		frame := new(Frame)
+		if err := stream.RecvMsg(frame); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = fmt.Errorf("the worker ended the step without a result")
+			}
+			return modules.ModuleResult{}, fmt.Errorf("worker %s: %w", c.addr, err)
+		}
+		switch {
+		case frame.Log != "":
+			_, _ = fmt.Fprintln(logs, frame.Log)
+		case frame.File != "":
+			name, ok := strings.CutPrefix(frame.File, "output/")
+			if !ok {
+				return modules.ModuleResult{}, fmt.Errorf("worker %s sent %s, which is not in the output folder", c.addr, frame.File)
+			}
+			if err := receiver.write(name, frame); err != nil {
+				return modules.ModuleResult{}, err
+			}
+		case frame.Result != nil:
+			return transfer.result(frame.Result, c.addr)
+		}
+	}
+}
+
+// upload is a local file or folder sent with a job
+type upload struct {
+	local  string
+	remote string // Path in the job folder
+}
+
+// transfer maps the local files of a job to paths in its folder on the worker
+type transfer struct {
+	output  string // Local output folder, sent as the output folder of the job
+	uploads []upload
+	byLocal map[string]string
+}
+
+// newTransfer returns the transfer of a job writing to output
+func newTransfer(output string) (*transfer, error) {
+	if output == "" {
+		return nil, fmt.Errorf("steps run on workers need an output folder")
+	}
+	abs, err := filepath.Abs(output)
+	if err != nil {
+		return nil, err
+	}
+	return &transfer{output: abs, byLocal: make(map[string]string)}, nil
+}
+
+// remote returns the path on the worker of a parameter naming a local file or folder, which is sent
+// with the job. Other values are kept, including paths that only exist on the worker.
+func (t *transfer) remote(value string) string {
+	if value == "" || strings.Contains(value, "\n") || !strings.ContainsAny(value, `/\`) && filepath.Ext(value) == "" {
+		return value
+	}
+	if _, err := os.Stat(value); err != nil {
+		return value
+	}
+	local, err := filepath.Abs(value)
+	if err != nil {
+		return value
+	}
+	if remote, ok := t.byLocal[local]; ok {
+		return path.Join(JobDir, remote)
+	}
+
+	// Files of the output folder keep their place in it, as steps find files there by name
+	remote := ""
+	if rel, err := filepath.Rel(t.output, local); err == nil && (filepath.IsLocal(rel) || rel == ".") {
+		remote = path.Join("output", filepath.ToSlash(rel))
+	} else {
+		remote = path.Join("inputs", strconv.Itoa(len(t.uploads)+1), filepath.Base(local))
+	}
+	t.byLocal[local] = remote
+	t.uploads = append(t.uploads, upload{local: local, remote: remote})
+	return path.Join(JobDir, remote)
+}
+
+// send sends the job, its files and the start of the job
+func (t *transfer) send(stream grpc.ClientStream, job *Job) error {
+	send := func(frame *Frame) error { return stream.SendMsg(frame) }
+	if err := send(&Frame{Job: job}); err != nil {
+		return err
+	}
+	for _, u := range t.uploads {
+		err := filepath.WalkDir(u.local, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil || !entry.Type().IsRegular() {
+				return err
+			}
+			rel, err := filepath.Rel(u.local, file)
+			if err != nil {
+				return err
+			}
+			utils.LogDebug("Sending %s", file)
+			return sendFile(send, file, path.Join(u.remote, filepath.ToSlash(rel)))
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if err := send(&Frame{Start: true}); err != nil {
+		return err
+	}
+	return stream.CloseSend()
+}
+
+// result returns the module result of a job, its paths in the job folder replaced by the local
+// files they were received into or sent from
+func (t *transfer) result(result *Result, addr string) (modules.ModuleResult, error) {
+	if result.Error != "" {
+		return modules.ModuleResult{}, fmt.Errorf("on worker %s: %s", addr, result.Error)
+	}
+	outputs := make(map[string]string, len(result.Outputs))
+	for name, value := range result.Outputs {
+		outputs[name] = t.local(value)
+	}
+	metadata, _ := mapStrings(result.Metadata, t.local).(map[string]interface{})
+	statistics, _ := mapStrings(result.Statistics, t.local).(map[string]interface{})
+	return modules.ModuleResult{
+		Outputs:    outputs,
+		Metadata:   metadata,
+		Statistics: statistics,
+		Stats:      result.Stats,
+		Items:      result.Items,
+	}, nil
+}
+
+// local returns the local path of a path in the job folder
+func (t *transfer) local(value string) string {
+	rel, ok := strings.CutPrefix(value, JobDir+"/")
+	if !ok {
+		return value
+	}
+	if rel == "output" || strings.HasPrefix(rel, "output/") {
+		return filepath.Join(t.output, filepath.FromSlash(strings.TrimPrefix(rel, "output")))
+	}
+	for _, u := range t.uploads {
+		if rel == u.remote || strings.HasPrefix(rel, u.remote+"/") {
+			return filepath.Join(u.local, filepath.FromSlash(strings.TrimPrefix(rel, u.remote)))
+		}
+	}
+	return value
+}
+
+// Module runs the steps of a module on a worker. It describes itself as the module does, so the
+// workflow graph is built as for a local step.
+type Module struct {
+	modules.Module
+	client *Client
+	job    Job
+}
+
+// NewModule returns a module running the step of job on the worker of client
+func NewModule(module modules.Module, client *Client, job Job) *Module {
+	job.Module = module.Name()
+	return &Module{Module: module, client: client, job: job}
+}
+
+// Validate accepts any parameters: the worker checks them, as files they name may only exist there
+func (m *Module) Validate(params map[string]interface{}) error {
+	return nil
+}
+
+// Execute runs the step on the worker
+func (m *Module) Execute(ctx context.Context, params map[string]interface{}) (modules.ModuleResult, error) {
+	utils.LogInfoContext(ctx, "Running step %s on worker %s", m.job.Step, m.client.Addr())
+	return m.client.Run(ctx, m.job, params)
+}
@@ -0,0 +1,133 @@
+package worker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+// sendFile sends a local file in chunks, as the file at remote
+func sendFile(send func(*Frame) error, local, remote string) error {
+	f, err := os.Open(local)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := io.ReadFull(f, buf)
+		eof := errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+		if err != nil && !eof {
+			return fmt.Errorf("failed to read %s: %w", local, err)
+		}
+		if err := send(&Frame{File: remote, Mode: uint32(info.Mode().Perm()), Data: buf[:n], EOF: eof}); err != nil {
+			return err
+		}
+		if eof {
+			return nil
+		}
+	}
+}
+
+// fileReceiver writes the files of a stream below a folder. Each file is written to a temporary
+// file renamed into place once complete, so an interrupted transfer leaves no partial file.
+type fileReceiver struct {
+	dir      string
+	received map[string]string // SHA-256 of each file received, by its path in the stream
+
+	path string
+	file *os.File
+	hash hash.Hash
+}
+
+// newFileReceiver returns a receiver writing below dir
+func newFileReceiver(dir string) *fileReceiver {
+	return &fileReceiver{dir: dir, received: make(map[string]string)}
+}
+
+// write writes a chunk to the file at name, relative to the receiver's folder; files are sent one
+// after the other
+func (r *fileReceiver) write(name string, frame *Frame) error {
+	if r.file != nil && r.path != name {
+		return fmt.Errorf("file %s was not sent whole", r.path)
+	}
+	target := filepath.Join(r.dir, filepath.FromSlash(name))
+	if r.file == nil {
+		if !filepath.IsLocal(filepath.FromSlash(name)) {
+			return fmt.Errorf("file %q is outside the job folder", name)
+		}
+		if err := utils.EnsureDir(filepath.Dir(target)); err != nil {
+			return err
+		}
+		f, err := os.CreateTemp(filepath.Dir(target), "."+filepath.Base(target)+".part-*")
+		if err != nil {
+			return err
+		}
+		r.path, r.file, r.hash = name, f, sha256.New()
+	}
+
+	if _, err := r.file.Write(frame.Data); err != nil {
+		r.abort()
+		return fmt.Errorf("failed to write %s: %w", target, err)
+	}
+	r.hash.Write(frame.Data)
+	if !frame.EOF {
+		return nil
+	}
+
+	f, temp := r.file, r.file.Name()
+	r.file = nil
+	mode := os.FileMode(frame.Mode).Perm()
+	if mode == 0 {
+		mode = 0644
+	}
+	err := f.Close()
+	if err == nil {
+		err = os.Chmod(temp, mode)
+	}
+	if err == nil {
+		err = os.Rename(temp, target)
+	}
+	if err != nil {
+		_ = os.Remove(temp)
+		return fmt.Errorf("failed to write %s: %w", target, err)
+	}
+	r.received[name] = hex.EncodeToString(r.hash.Sum(nil))
+	return nil
+}
+
+// abort removes the file being received
+func (r *fileReceiver) abort() {
+	if r.file == nil {
+		return
+	}
+	_ = r.file.Close()
+	_ = os.Remove(r.file.Name())
+	r.file = nil
+}
+
+// fileHash returns the SHA-256 of a file
+func fileHash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,157 @@
+// Package worker runs the steps of a workflow on other machines. A worker serves the modules over
+// gRPC: the machine running the workflow sends it a step's parameters and input files, and gets
+// back the step's log, the files it wrote and its result. The run's state, graph and manifest stay
+// on the machine running the workflow, so a laptop can run a workflow whose transcription and
+// rendering happen on a GPU server.
+package worker
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	modules "github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/scheduler"
+	"google.golang.org/grpc"
+)
+
+// ServiceName is the gRPC service workers serve
+const ServiceName = "studioflowai.worker.v1.Worker"
+
+// TokenEnv is the environment variable holding the token workers require from the machines
+// sending them steps; both sides read it
+const TokenEnv = "STUDIOFLOWAI_WORKER_TOKEN"
+
+// JobDir starts the parameters that are paths in the folder of a job on the worker, e.g.
+// ${job}/inputs/1/episode.mp4. Paths in messages always use forward slashes.
+const JobDir = "${job}"
+
+const (
+	chunkSize      = 1 << 20  // Bytes of a file sent per message
+	maxMessageSize = 16 << 20 // Largest message accepted, well above a chunk and its header
+)
+
+// InfoRequest asks a worker what it can run
+type InfoRequest struct{}
+
+// InfoResponse tells what a worker can run
+type InfoResponse struct {
+	Hostname string           `json:"hostname"`
+	Modules  []string         `json:"modules"`
+	Limits   scheduler.Limits `json:"limits"` // Steps of each class the worker runs at once
+}
+
+// Frame is a message of the Run stream. The machine running the workflow sends the job, then the
+// chunks of its input files, then start; the worker answers with log lines, then the chunks of the
+// files the step wrote, then the result.
+type Frame struct {
+	Job    *Job    `json:"job,omitempty"`
+	File   string  `json:"file,omitempty"` // File a chunk belongs to, relative to the job folder
+	Mode   uint32  `json:"mode,omitempty"` // Permissions of the file
+	EOF    bool    `json:"eof,omitempty"`  // Last chunk of the file
+	Data   []byte  `json:"-"`              // Chunk of the file, sent as is after the header
+	Start  bool    `json:"start,omitempty"`
+	Log    string  `json:"log,omitempty"` // A line of the step's log
+	Result *Result `json:"result,omitempty"`
+}
+
+// Job is a step to run on a worker
+type Job struct {
+	Step       string                 `json:"step"`
+	Module     string                 `json:"module"`
+	Params     map[string]interface{} `json:"params"`               // Local files are replaced by ${job} paths
+	RetryItems []string               `json:"retryItems,omitempty"` // Items that failed in the previous attempt of the step
+	Class      string                 `json:"class,omitempty"`      // Resource class of the step; empty uses the module's
+	Priority   int                    `json:"priority,omitempty"`   // Steps with higher priorities take free slots of their class first
+}
+
+// Result is the outcome of a job
+type Result struct {
+	Outputs    map[string]string      `json:"outputs,omitempty"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+	Statistics map[string]interface{} `json:"statistics,omitempty"`
+	Stats      modules.Stats          `json:"stats"`
+	Items      []modules.ItemResult   `json:"items,omitempty"`
+	Error      string                 `json:"error,omitempty"` // The step failed on the worker
+}
+
+// codec writes messages as a JSON header followed by the raw chunk of a file, so files travel
+// without the overhead of encoding them
+type codec struct{}
+
+// Name returns the name of the codec
+func (codec) Name() string {
+	return "studioflowai"
+}
+
+// Marshal writes the length of the JSON header, the header and the chunk of a Frame
+func (codec) Marshal(v interface{}) ([]byte, error) {
+	header, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	if frame, ok := v.(*Frame); ok {
+		data = frame.Data
+	}
+	message := make([]byte, 4, 4+len(header)+len(data))
+	binary.BigEndian.PutUint32(message, uint32(len(header)))
+	return append(append(message, header...), data...), nil
+}
+
+// Unmarshal reads a message written by Marshal
+func (codec) Unmarshal(message []byte, v interface{}) error {
+	if len(message) < 4 {
+		return fmt.Errorf("message of %d bytes is too short", len(message))
+	}
+	n := int(binary.BigEndian.Uint32(message))
+	if n > len(message)-4 {
+		return fmt.Errorf("message header of %d bytes is longer than the message", n)
+	}
+	if err := json.Unmarshal(message[4:4+n], v); err != nil {
+		return err
+	}
+	if frame, ok := v.(*Frame); ok && len(message) > 4+n {
+		frame.Data = append([]byte(nil), message[4+n:]...)
+	}
+	return nil
+}
+
+// service is the server side of the Worker service
+type service interface {
+	info(ctx context.Context, req *InfoRequest) (*InfoResponse, error)
+	run(stream grpc.ServerStream) error
+}
+
+// serviceDesc describes the Worker service to gRPC, as generated code would
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*service)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Info", Handler: infoHandler},
+	},
+	Streams: []grpc.StreamDesc{
+		{StreamName: "Run", Handler: runHandler, ServerStreams: true, ClientStreams: true},
+	},
+}
+
+// infoHandler decodes an Info call and passes it to the server
+func infoHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	req := new(InfoRequest)
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(service).info(ctx, req)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + ServiceName + "/Info"}
+	return interceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(service).info(ctx, req.(*InfoRequest))
+	})
+}
+
+// runHandler passes a Run stream to the server
+func runHandler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(service).run(stream)
+}
@@ -0,0 +1,310 @@
+package worker
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	modules "github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/scheduler"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Server runs the steps sent by machines running workflows. Steps of different classes run at once,
+// within the limits of each class, so a transcription on the GPU does not hold back the LLM steps of
+// another workflow. Each step runs in a job folder of its own, removed once its files are sent back.
+type Server struct {
+	registry  *modules.ModuleRegistry
+	dir       string // Folder of the job folders; empty uses the system's temporary folder
+	token     string // Token required from clients; empty accepts any client
+	scheduler *scheduler.Scheduler
+}
+
+// NewServer creates a worker running the modules of registry, with its job folders in dir and at
+// most limits steps of each class at once; nil limits use the defaults
+func NewServer(registry *modules.ModuleRegistry, dir, token string, limits scheduler.Limits) *Server {
+	if limits == nil {
+		limits = scheduler.DefaultLimits()
+	}
+	return &Server{registry: registry, dir: dir, token: token, scheduler: scheduler.New(limits)}
+}
+
+// GRPCServer returns a gRPC server serving the worker
+func (s *Server) GRPCServer() *grpc.Server {
+	g := grpc.NewServer(
+		grpc.ForceServerCodec(codec{}),
+		grpc.MaxRecvMsgSize(maxMessageSize),
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := s.authorize(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := s.authorize(stream.Context()); err != nil {
+				return err
+			}
+			return handler(srv, stream)
+		}),
+	)
+	g.RegisterService(&serviceDesc, s)
+	return g
+}
+
+// authorize checks the token sent by the client
+func (s *Server) authorize(ctx context.Context) error {
+	if s.token == "" {
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		if subtle.ConstantTimeCompare([]byte(value), []byte("Bearer "+s.token)) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "missing or wrong worker token")
+}
+
+// info tells the client which modules the worker runs
+func (s *Server) info(ctx context.Context, req *InfoRequest) (*InfoResponse, error) {
+	hostname, _ := os.Hostname()
+	names := make([]string, 0)
+	for _, module := range s.registry.ListModules() {
+		names = append(names, module.Name())
+	}
+	sort.Strings(names)
+	return &InfoResponse{Hostname: hostname, Modules: names, Limits: s.scheduler.Limits()}, nil
+}
+
+// run receives a job and its input files, runs the step and sends back its log, the files it
+// wrote and its result
+func (s *Server) run(stream grpc.ServerStream) error {
+	first := new(Frame)
+	if err := stream.RecvMsg(first); err != nil {
+		return err
+	}
+	job := first.Job
+	if job == nil {
+		return status.Error(codes.InvalidArgument, "the first message must describe the job")
+	}
+	module, err := s.registry.Get(job.Module)
+	if err != nil {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	class := scheduler.DefaultClass(job.Module)
+	if job.Class != "" {
+		if class, err = scheduler.ParseClass(job.Class); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	dir, err := utils.MakeTempDir(s.dir, "job")
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to create job folder: %v", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			utils.LogWarning("Failed to remove job folder %s: %v", dir, err)
+		}
+	}()
+
+	receiver := newFileReceiver(dir)
+	defer receiver.abort()
+	for {
+		frame := new(Frame)
+		if err := stream.RecvMsg(frame); err != nil {
+			return err
+		}
+		if frame.Start {
+			break
+		}
+		if frame.File == "" {
+			return status.Error(codes.InvalidArgument, "expected an input file or the start of the job")
+		}
+		if err := receiver.write(frame.File, frame); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	var sendMu sync.Mutex
+	send := func(frame *Frame) error {
+		sendMu.Lock()
+		defer sendMu.Unlock()
+		return stream.SendMsg(frame)
+	}
+
+	// Wait for a slot of the step's class, telling the client when others hold them all
+	if running, waiting := s.scheduler.Status(class); running >= s.scheduler.Limits()[class] || waiting > 0 {
+		_ = send(&Frame{Log: fmt.Sprintf("Step %s waits for a free %s slot (%d running, %d waiting)", job.Step, class, running, waiting)})
+	}
+	release, err := s.scheduler.Acquire(stream.Context(), class, job.Priority)
+	if err != nil {
+		return status.FromContextError(err).Err()
+	}
+	defer release()
+
+	result := s.execute(stream.Context(), dir, job, module, send)
+	if result.Error == "" {
+		if err := sendOutputs(send, dir, receiver.received); err != nil {
+			return status.Errorf(codes.Internal, "failed to send the files of step %s: %v", job.Step, err)
+		}
+	}
+	return send(&Frame{Result: result})
+}
+
+// execute runs a job's step in its folder, sending the lines of its log as they are written
+func (s *Server) execute(ctx context.Context, dir string, job *Job, module modules.Module, send func(*Frame) error) *Result {
+	params, _ := toLocal(job.Params, dir).(map[string]interface{})
+	if params == nil {
+		params = make(map[string]interface{})
+	}
+	params["output"] = filepath.Join(dir, "output")
+	if err := utils.EnsureDir(params["output"].(string)); err != nil {
+		return &Result{Error: fmt.Sprintf("failed to create output folder: %v", err)}
+	}
+
+	// The messages of the step go to its client, while those of steps running beside it go to theirs
+	logs := &logForwarder{send: send}
+	defer logs.flush()
+	ctx = utils.WithLogForward(utils.WithLogStep(ctx, job.Step), logs)
+
+	utils.LogInfoContext(ctx, "Running step %s (%s)", job.Step, job.Module)
+	if err := module.Validate(params); err != nil {
+		utils.LogErrorContext(ctx, "Step %s: %v", job.Step, err)
+		return &Result{Error: fmt.Sprintf("invalid parameters: %v", err)}
+	}
+	result, err := module.Execute(modules.WithRetryItems(ctx, job.RetryItems), params)
+	if err != nil {
+		utils.LogErrorContext(ctx, "Step %s failed: %v", job.Step, err)
+		return &Result{Error: err.Error()}
+	}
+	utils.LogSuccessContext(ctx, "Completed step %s", job.Step)
+
+	outputs := make(map[string]string, len(result.Outputs))
+	for name, value := range result.Outputs {
+		outputs[name] = toJob(value, dir).(string)
+	}
+	metadata, _ := toJob(result.Metadata, dir).(map[string]interface{})
+	statistics, _ := toJob(result.Statistics, dir).(map[string]interface{})
+	return &Result{
+		Outputs:    outputs,
+		Metadata:   metadata,
+		Statistics: statistics,
+		Stats:      result.Stats,
+		Items:      result.Items,
+	}
+}
+
+// sendOutputs sends the files of the job's output folder that the step wrote or changed
+func sendOutputs(send func(*Frame) error, dir string, received map[string]string) error {
+	return filepath.WalkDir(filepath.Join(dir, "output"), func(file string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if sum, ok := received[name]; ok {
+			if current, err := fileHash(file); err == nil && current == sum {
+				return nil
+			}
+		}
+		return sendFile(send, file, name)
+	})
+}
+
+// toLocal replaces ${job} in the strings of a value with the job folder
+func toLocal(value interface{}, dir string) interface{} {
+	return mapStrings(value, func(s string) string {
+		if s != JobDir && !strings.HasPrefix(s, JobDir+"/") {
+			return s
+		}
+		return filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(s, JobDir)))
+	})
+}
+
+// toJob replaces the job folder in the strings of a value with ${job}
+func toJob(value interface{}, dir string) interface{} {
+	return mapStrings(value, func(s string) string {
+		rel, err := filepath.Rel(dir, s)
+		if !filepath.IsAbs(s) || err != nil || !filepath.IsLocal(rel) && rel != "." {
+			return s
+		}
+		return path.Join(JobDir, filepath.ToSlash(rel))
+	})
+}
+
+// mapStrings applies f to every string of a value decoded from YAML or JSON
+func mapStrings(value interface{}, f func(string) string) interface{} {
+	switch v := value.(type) {
+	case string:
+		return f(v)
+	case map[string]interface{}:
+		mapped := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			mapped[key] = mapStrings(item, f)
+		}
+		return mapped
+	case []interface{}:
+		mapped := make([]interface{}, len(v))
+		for i, item := range v {
+			mapped[i] = mapStrings(item, f)
+		}
+		return mapped
+	case []string:
+		mapped := make([]string, len(v))
+		for i, item := range v {
+			mapped[i] = f(item)
+		}
+		return mapped
+	default:
+		return value
+	}
+}
+
+// logForwarder sends the lines written to it to the client
+type logForwarder struct {
+	send func(*Frame) error
+
+	mu      sync.Mutex
+	partial string
+}
+
+// Write sends the complete lines of p and keeps the rest for the next write
+func (l *logForwarder) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.partial += string(p)
+	for {
+		i := strings.IndexByte(l.partial, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		// A client gone away shows up as the error of the step, not of the log
+		_ = l.send(&Frame{Log: l.partial[:i]})
+		l.partial = l.partial[i+1:]
+	}
+}
+
+// flush sends the last line if it did not end with a newline
+func (l *logForwarder) flush() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.partial != "" {
+		_ = l.send(&Frame{Log: l.partial})
+		l.partial = ""
+	}
+}
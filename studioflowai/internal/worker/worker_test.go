@@ -0,0 +1,251 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	modules "github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/scheduler"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// upperModule writes its input file in upper case to a folder of the output folder
+type upperModule struct{}
+
+func (upperModule) Name() string                          { return "upper" }
+func (upperModule) GetIO() modules.ModuleIO               { return modules.ModuleIO{} }
+func (upperModule) Validate(map[string]interface{}) error { return nil }
+func (upperModule) Execute(ctx context.Context, params map[string]interface{}) (modules.ModuleResult, error) {
+	input, _ := params["input"].(string)
+	output, _ := params["output"].(string)
+	data, err := os.ReadFile(input)
+	if err != nil {
+		return modules.ModuleResult{}, err
+	}
+	if strings.Contains(string(data), "fail") {
+		return modules.ModuleResult{}, fmt.Errorf("cannot shout %q", strings.TrimSpace(string(data)))
+	}
+	utils.LogInfoContext(ctx, "Shouting %s", filepath.Base(input))
+	result := filepath.Join(output, "shouted", strings.TrimSuffix(filepath.Base(input), ".txt")+"_upper.txt")
+	if err := utils.EnsureDir(filepath.Dir(result)); err != nil {
+		return modules.ModuleResult{}, err
+	}
+	if err := os.WriteFile(result, []byte(strings.ToUpper(string(data))), 0644); err != nil {
+		return modules.ModuleResult{}, err
+	}
+	retried := make([]string, 0)
+	for id := range modules.RetryItems(ctx) {
+		retried = append(retried, id)
+	}
+	return modules.ModuleResult{
+		Outputs:    map[string]string{"upper": result},
+		Statistics: map[string]interface{}{"input": input, "model": params["model"], "retried": len(retried)},
+		Stats:      modules.Stats{Items: 1},
+	}, nil
+}
+
+// holdModule runs until its step is released
+type holdModule struct {
+	started chan string
+	release chan struct{}
+}
+
+func (holdModule) Name() string                          { return "hold" }
+func (holdModule) GetIO() modules.ModuleIO               { return modules.ModuleIO{} }
+func (holdModule) Validate(map[string]interface{}) error { return nil }
+func (m holdModule) Execute(ctx context.Context, params map[string]interface{}) (modules.ModuleResult, error) {
+	name := params["name"].(string)
+	utils.LogInfoContext(ctx, "Holding %s", name)
+	m.started <- name
+	<-m.release
+	utils.LogInfoContext(ctx, "Released %s", name)
+	return modules.ModuleResult{}, nil
+}
+
+// startWorker serves a worker over an in-memory connection and returns a client of it
+func startWorker(t *testing.T, serverToken, clientToken string, extra ...modules.Module) *Client {
+	t.Helper()
+	return startWorkerWithLimits(t, serverToken, clientToken, nil, extra...)
+}
+
+// startWorkerWithLimits is startWorker with the limits of each class of steps
+func startWorkerWithLimits(t *testing.T, serverToken, clientToken string, limits scheduler.Limits, extra ...modules.Module) *Client {
+	t.Helper()
+	registry := modules.NewModuleRegistry()
+	require.NoError(t, registry.Register(upperModule{}))
+	for _, module := range extra {
+		require.NoError(t, registry.Register(module))
+	}
+
+	listener := bufconn.Listen(1 << 20)
+	server := NewServer(registry, t.TempDir(), serverToken, limits).GRPCServer()
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	client, err := Dial("passthrough:///worker", clientToken, grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	}))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+func TestRun(t *testing.T) {
+	client := startWorker(t, "secret", "secret")
+	dir := t.TempDir()
+	output := filepath.Join(dir, "run")
+	require.NoError(t, os.MkdirAll(output, 0755))
+	input := filepath.Join(output, "episode.txt")
+	require.NoError(t, os.WriteFile(input, []byte("hello from the laptop"), 0644))
+
+	info, err := client.Info(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"upper"}, info.Modules)
+
+	ctx := modules.WithRetryItems(context.Background(), []string{"clip-2"})
+	result, err := NewModule(upperModule{}, client, Job{Step: "shout"}).Execute(ctx, map[string]interface{}{
+		"input":  input,
+		"output": output,
+		"model":  "/models/only-on-the-worker.bin",
+	})
+	require.NoError(t, err)
+
+	// The written file is received into the local output folder, and the paths point to local files
+	want := filepath.Join(output, "shouted", "episode_upper.txt")
+	assert.Equal(t, want, result.Outputs["upper"])
+	data, err := os.ReadFile(want)
+	require.NoError(t, err)
+	assert.Equal(t, "HELLO FROM THE LAPTOP", string(data))
+	assert.Equal(t, input, result.Statistics["input"])
+	assert.Equal(t, "/models/only-on-the-worker.bin", result.Statistics["model"], "paths that are not local files are left to the worker")
+	assert.EqualValues(t, 1, result.Statistics["retried"])
+	assert.Equal(t, 1, result.Stats.Items)
+
+	// The input sent with the job is not sent back
+	entries, err := os.ReadDir(output)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestRun_InputOutsideOutput(t *testing.T) {
+	client := startWorker(t, "", "")
+	input := filepath.Join(t.TempDir(), "notes.txt")
+	require.NoError(t, os.WriteFile(input, []byte("quiet"), 0644))
+	output := t.TempDir()
+
+	result, err := client.Run(context.Background(), Job{Step: "shout", Module: "upper"}, map[string]interface{}{"input": input, "output": output})
+	require.NoError(t, err)
+	assert.Equal(t, input, result.Statistics["input"])
+	assert.FileExists(t, filepath.Join(output, "shouted", "notes_upper.txt"))
+}
+
+func TestRun_StepFails(t *testing.T) {
+	client := startWorker(t, "", "")
+	output := t.TempDir()
+	input := filepath.Join(output, "bad.txt")
+	require.NoError(t, os.WriteFile(input, []byte("fail"), 0644))
+
+	_, err := client.Run(context.Background(), Job{Step: "shout", Module: "upper"}, map[string]interface{}{"input": input, "output": output})
+	assert.ErrorContains(t, err, `cannot shout "fail"`)
+	assert.NoDirExists(t, filepath.Join(output, "shouted"))
+}
+
+func TestRun_ClassLimits(t *testing.T) {
+	hold := holdModule{started: make(chan string, 3), release: make(chan struct{})}
+	client := startWorkerWithLimits(t, "", "", scheduler.Limits{scheduler.CPU: 1, scheduler.API: 1}, hold)
+
+	info, err := client.Info(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, info.Limits[scheduler.API])
+
+	errs := make(chan error, 3)
+	run := func(name, class string) {
+		_, err := client.Run(context.Background(), Job{Step: name, Module: "hold", Class: class}, map[string]interface{}{"name": name, "output": t.TempDir()})
+		errs <- err
+	}
+	next := func() string {
+		select {
+		case name := <-hold.started:
+			return name
+		case <-time.After(100 * time.Millisecond):
+			return ""
+		}
+	}
+
+	go run("render", "")
+	require.Equal(t, "render", next())
+	go run("render-2", "cpu")
+	assert.Equal(t, "", next(), "a second cpu step waits for the first")
+	go run("suggest", "api")
+	assert.Equal(t, "suggest", next(), "an api step runs beside the cpu step")
+
+	close(hold.release)
+	assert.Equal(t, "render-2", next())
+	for i := 0; i < 3; i++ {
+		require.NoError(t, <-errs)
+	}
+}
+
+func TestRun_WrongToken(t *testing.T) {
+	client := startWorker(t, "secret", "guess")
+	_, err := client.Info(context.Background())
+	assert.ErrorContains(t, err, "worker token")
+}
+
+func TestCodec(t *testing.T) {
+	message, err := codec{}.Marshal(&Frame{File: "output/a.wav", EOF: true, Data: []byte{0, 1, 2}})
+	require.NoError(t, err)
+	var frame Frame
+	require.NoError(t, codec{}.Unmarshal(message, &frame))
+	assert.Equal(t, Frame{File: "output/a.wav", EOF: true, Data: []byte{0, 1, 2}}, frame)
+
+	assert.Error(t, codec{}.Unmarshal([]byte{0, 0, 1, 0, '{'}, &frame))
+}
+
+func TestFileReceiver_RejectsPathsOutside(t *testing.T) {
+	receiver := newFileReceiver(t.TempDir())
+	assert.ErrorContains(t, receiver.write("../escape.txt", &Frame{EOF: true}), "outside the job folder")
+}
+
+func TestRun_LogOfEachStep(t *testing.T) {
+	hold := holdModule{started: make(chan string, 2), release: make(chan struct{})}
+	client := startWorkerWithLimits(t, "", "", scheduler.Limits{scheduler.CPU: 1, scheduler.API: 1}, hold)
+
+	// The client writes the lines it receives to the log of its step, forwarded here
+	steps := []Job{{Step: "render", Module: "hold", Class: "cpu"}, {Step: "suggest", Module: "hold", Class: "api"}}
+	logs := make([]bytes.Buffer, len(steps))
+	errs := make(chan error, len(steps))
+	for i, job := range steps {
+		output := t.TempDir()
+		go func() {
+			ctx := utils.WithLogForward(context.Background(), &logs[i])
+			_, err := client.Run(ctx, job, map[string]interface{}{"name": job.Step, "output": output})
+			errs <- err
+		}()
+	}
+	<-hold.started
+	<-hold.started
+	close(hold.release)
+	for range steps {
+		require.NoError(t, <-errs)
+	}
+
+	// Steps running at once each get their own lines, and only those
+	assert.Contains(t, logs[0].String(), "Holding render")
+	assert.Contains(t, logs[0].String(), "Released render")
+	assert.NotContains(t, logs[0].String(), "suggest")
+	assert.Contains(t, logs[1].String(), "Holding suggest")
+	assert.Contains(t, logs[1].String(), "Released suggest")
+	assert.NotContains(t, logs[1].String(), "render")
+}
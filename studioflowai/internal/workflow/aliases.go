@@ -0,0 +1,71 @@
+package workflow
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+// legacyModule is a module of older workflow files that was split into several modules; the
+// step's task parameter picks the module that runs it
+type legacyModule struct {
+	tasks       map[string]string // Task to the module that replaced it
+	defaultTask string            // Task of steps that do not set one
+}
+
+// legacyModules lists the module names older workflow files may still use
+var legacyModules = map[string]legacyModule{
+	"chatgpt": {
+		tasks: map[string]string{
+			"correct": "correct_transcript",
+			"sns":     "suggest_sns_content",
+			"shorts":  "suggest_shorts",
+		},
+		defaultTask: "correct",
+	},
+}
+
+// legacyTaskParam is the parameter of a legacy step naming its task; the replacing modules do
+// not take it
+const legacyTaskParam = "task"
+
+// resolveLegacyModule returns the module that runs a step of a legacy module with the given
+// task, "" for the default. ok is false when module is not a legacy module.
+func resolveLegacyModule(module, task string) (replacement string, ok bool, err error) {
+	legacy, ok := legacyModules[module]
+	if !ok {
+		return "", false, nil
+	}
+	if task == "" {
+		task = legacy.defaultTask
+	}
+	replacement, found := legacy.tasks[task]
+	if !found {
+		tasks := slices.Sorted(maps.Keys(legacy.tasks))
+		return "", true, fmt.Errorf("module %s has no task %q (use %s)", module, task, strings.Join(tasks, ", "))
+	}
+	return replacement, true, nil
+}
+
+// applyLegacyModules rewrites the steps of legacy modules to the modules that replaced them,
+// dropping their task parameter, and warns that the workflow should be updated
+func applyLegacyModules(workflow *Workflow) error {
+	for i := range workflow.Steps {
+		step := &workflow.Steps[i]
+		task, _ := step.Parameters[legacyTaskParam].(string)
+		replacement, ok, err := resolveLegacyModule(step.Module, task)
+		if !ok {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("step %q: %w", step.Name, err)
+		}
+		utils.LogWarning("Step %q: module %s is deprecated, running it as module %s (replace it in the workflow file)", step.Name, step.Module, replacement)
+		step.Module = replacement
+		delete(step.Parameters, legacyTaskParam)
+	}
+	return nil
+}
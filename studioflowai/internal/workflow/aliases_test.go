@@ -0,0 +1,73 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const legacyWorkflow = `name: Content Enhancement
+steps:
+  - name: Improve Transcript
+    module: chatgpt
+    parameters:
+      input: ./input/transcript.srt
+      targetLanguage: auto
+  - name: Social Media
+    module: chatgpt
+    parameters:
+      input: ./output/transcript_corrected.txt
+      task: sns
+      language: English
+  - name: Shorts
+    module: chatgpt
+    parameters:
+      input: ./output/transcript_corrected.txt
+      task: shorts
+      maxDuration: 45
+`
+
+func TestLegacyChatGPTModule(t *testing.T) {
+	registry, err := NewRegistry()
+	require.NoError(t, err)
+
+	// The parameters of each step are checked against the module its task runs
+	require.NoError(t, ValidateWorkflowSchema("legacy.yaml", []byte(legacyWorkflow), registry))
+
+	w := &Workflow{Steps: []Step{
+		{Name: "Improve Transcript", Module: "chatgpt", Parameters: map[string]interface{}{"input": "t.srt"}},
+		{Name: "Social Media", Module: "chatgpt", Parameters: map[string]interface{}{"task": "sns"}},
+		{Name: "Shorts", Module: "chatgpt", Parameters: map[string]interface{}{"task": "shorts"}},
+		{Name: "Clean", Module: "clean_text", Parameters: map[string]interface{}{"task": "kept"}},
+	}}
+	require.NoError(t, applyLegacyModules(w))
+	assert.Equal(t, "correct_transcript", w.Steps[0].Module, "steps without a task correct the transcript")
+	assert.Equal(t, "suggest_sns_content", w.Steps[1].Module)
+	assert.Equal(t, "suggest_shorts", w.Steps[2].Module)
+	assert.NotContains(t, w.Steps[1].Parameters, "task")
+	assert.Equal(t, "kept", w.Steps[3].Parameters["task"], "other modules are left alone")
+}
+
+func TestLegacyChatGPTModule_Errors(t *testing.T) {
+	registry, err := NewRegistry()
+	require.NoError(t, err)
+
+	err = ValidateWorkflowSchema("legacy.yaml", []byte(`name: x
+steps:
+  - name: Blog
+    module: chatgpt
+    parameters:
+      task: blog
+  - name: Shorts
+    module: chatgpt
+    parameters:
+      task: shorts
+      tone: engaging
+`), registry)
+	assert.ErrorContains(t, err, `legacy.yaml:4:13: steps[0].module: module chatgpt has no task "blog" (use correct, shorts, sns)`)
+	assert.ErrorContains(t, err, `unknown parameter "tone" for module suggest_shorts`)
+
+	w := &Workflow{Steps: []Step{{Name: "Blog", Module: "chatgpt", Parameters: map[string]interface{}{"task": "blog"}}}}
+	assert.ErrorContains(t, applyLegacyModules(w), `step "Blog": module chatgpt has no task "blog"`)
+}
@@ -0,0 +1,83 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/config"
+)
+
+// applyAssets replaces ${asset:name} references in step parameters with the files of the global
+// and project assets, downloading the ones that are not cached yet. The manifests are only read
+// when a step refers to an asset.
+func applyAssets(ctx context.Context, w *Workflow, project *config.Project) error {
+	var assets config.Assets
+	for i, step := range w.Steps {
+		for k, v := range step.Parameters {
+			if !hasAssetRef(v) {
+				continue
+			}
+			if assets == nil {
+				var err error
+				if assets, err = config.LoadAssets(project); err != nil {
+					return err
+				}
+			}
+			expanded, err := expandAssets(ctx, assets, v)
+			if err != nil {
+				return fmt.Errorf("step %s: parameter %s: %w", step.Name, k, err)
+			}
+			w.Steps[i].Parameters[k] = expanded
+		}
+	}
+	return nil
+}
+
+// hasAssetRef reports whether a parameter value, or any value nested in it, refers to an asset
+func hasAssetRef(value interface{}) bool {
+	switch val := value.(type) {
+	case string:
+		return config.HasAssetRef(val)
+	case []interface{}:
+		for _, item := range val {
+			if hasAssetRef(item) {
+				return true
+			}
+		}
+	case map[string]interface{}:
+		for _, item := range val {
+			if hasAssetRef(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// expandAssets resolves the asset references of a value, recursing into lists and mappings
+func expandAssets(ctx context.Context, assets config.Assets, value interface{}) (interface{}, error) {
+	switch val := value.(type) {
+	case string:
+		return assets.Expand(ctx, val)
+	case []interface{}:
+		expanded := make([]interface{}, len(val))
+		for i, item := range val {
+			var err error
+			if expanded[i], err = expandAssets(ctx, assets, item); err != nil {
+				return nil, err
+			}
+		}
+		return expanded, nil
+	case map[string]interface{}:
+		expanded := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			var err error
+			if expanded[k], err = expandAssets(ctx, assets, item); err != nil {
+				return nil, err
+			}
+		}
+		return expanded, nil
+	default:
+		return value, nil
+	}
+}
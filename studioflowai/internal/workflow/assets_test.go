@@ -0,0 +1,76 @@
+package workflow
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyAssets(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	downloads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downloads++
+		_, _ = w.Write([]byte("intro video"))
+	}))
+	defer server.Close()
+	sum := sha256.Sum256([]byte("intro video"))
+
+	globalDir := filepath.Join(home, ".studioflowai")
+	require.NoError(t, os.MkdirAll(globalDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(globalDir, "assets.yaml"), []byte(`assets:
+  brand-font:
+    path: /global/font.ttf
+  intro:
+    url: `+server.URL+`/intro.mp4
+    sha256: `+hex.EncodeToString(sum[:])+`
+  broken:
+    url: `+server.URL+`/broken.mp4
+    sha256: 0000
+`), 0644))
+
+	projectDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "Brand.ttf"), []byte("font"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "assets.yaml"), []byte("assets:\n  brand-font:\n    type: font\n    path: Brand.ttf\n"), 0644))
+	project := &config.Project{Name: "acme", Dir: projectDir}
+
+	w := &Workflow{Steps: []Step{{
+		Name:   "titles",
+		Module: "set_title_to_short_video",
+		Parameters: map[string]interface{}{
+			"fontFile": "${asset:brand-font}",
+			"intros":   []interface{}{"${asset:intro}"},
+			"fontSize": 48,
+		},
+	}}}
+	require.NoError(t, applyAssets(context.Background(), w, project))
+
+	params := w.Steps[0].Parameters
+	assert.Equal(t, filepath.Join(projectDir, "Brand.ttf"), params["fontFile"], "project assets replace global ones")
+	intro := params["intros"].([]interface{})[0].(string)
+	data, err := os.ReadFile(intro)
+	require.NoError(t, err)
+	assert.Equal(t, "intro video", string(data))
+	assert.Equal(t, 48, params["fontSize"])
+
+	// Cached downloads matching their pin are not fetched again
+	w.Steps[0].Parameters["intros"] = "${asset:intro}"
+	require.NoError(t, applyAssets(context.Background(), w, project))
+	assert.Equal(t, 1, downloads)
+
+	w.Steps[0].Parameters["intros"] = "${asset:broken}"
+	assert.ErrorContains(t, applyAssets(context.Background(), w, project), "pinned")
+	w.Steps[0].Parameters["intros"] = "${asset:missing}"
+	assert.ErrorContains(t, applyAssets(context.Background(), w, project), `unknown asset "missing"`)
+}
@@ -0,0 +1,566 @@
+package workflow
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// bundleIndexName is the index written at the root of every run bundle
+const bundleIndexName = "bundle.yaml"
+
+// bundleExtrasDir holds the files of a bundle that do not belong to the run folder itself
+const bundleExtrasDir = ".bundle"
+
+// BundleOptions selects what goes into a run bundle besides the state file and manifest
+type BundleOptions struct {
+	Include    []string // Glob patterns, relative to the run folder, of the artifacts to bundle (default: all)
+	PromptsDir string   // Directory with the prompt files used by the run (default: none)
+	Workflow   string   // Workflow file of the run, bundled so the run can be retried (default: none)
+}
+
+// BundleIndex describes the contents of a run bundle
+type BundleIndex struct {
+	Run       string            `yaml:"run"`                // Name of the run folder
+	SourceDir string            `yaml:"sourceDir"`          // Absolute path of the run folder on the exporting machine
+	CreatedAt time.Time         `yaml:"createdAt"`          // When the bundle was written
+	Workflow  string            `yaml:"workflow,omitempty"` // Bundled workflow file, relative to the run folder
+	Files     map[string]string `yaml:"files"`              // SHA-256 of every bundled file, keyed by its path in the run folder
+}
+
+// ExportRun bundles a run folder into a tar archive that can be imported on another machine.
+// The compression follows the extension of out: .tar.zst (needs the zstd tool), .tar.gz/.tgz or .tar.
+func ExportRun(ctx context.Context, runDir, out string, opts BundleOptions) (*BundleIndex, error) {
+	absRunDir, err := filepath.Abs(runDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve run folder: %w", err)
+	}
+	info, err := os.Stat(absRunDir)
+	if err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("run folder does not exist: %s", runDir)
+	}
+
+	files, err := bundleFiles(absRunDir, opts.Include)
+	if err != nil {
+		return nil, err
+	}
+	if !hasStateFile(files) {
+		return nil, fmt.Errorf("%s has no workflow state file (*.state.yaml); is it a run folder?", runDir)
+	}
+
+	// Extra files are stored under .bundle/ so they never collide with run artifacts
+	sources := make(map[string]string, len(files))
+	for _, rel := range files {
+		sources[rel] = filepath.Join(absRunDir, filepath.FromSlash(rel))
+	}
+	index := &BundleIndex{
+		Run:       filepath.Base(absRunDir),
+		SourceDir: absRunDir,
+		CreatedAt: time.Now(),
+		Files:     make(map[string]string),
+	}
+	if opts.PromptsDir != "" {
+		prompts, err := filepath.Glob(filepath.Join(opts.PromptsDir, "*.yaml"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list prompts: %w", err)
+		}
+		for _, prompt := range prompts {
+			sources[path.Join(bundleExtrasDir, "prompts", filepath.Base(prompt))] = prompt
+		}
+	}
+	if opts.Workflow != "" {
+		if _, err := os.Stat(opts.Workflow); err != nil {
+			return nil, fmt.Errorf("workflow file does not exist: %s", opts.Workflow)
+		}
+		index.Workflow = path.Join(bundleExtrasDir, "workflow.yaml")
+		sources[index.Workflow] = opts.Workflow
+	}
+
+	names := make([]string, 0, len(sources))
+	for name, source := range sources {
+		checksum, err := utils.FileChecksum(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to checksum %s: %w", source, err)
+		}
+		index.Files[name] = checksum
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	indexData, err := yaml.Marshal(index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal bundle index: %w", err)
+	}
+
+	if err := utils.EnsureDir(filepath.Dir(out)); err != nil {
+		return nil, fmt.Errorf("failed to create bundle directory: %w", err)
+	}
+	w, err := createBundleWriter(ctx, out)
+	if err != nil {
+		return nil, err
+	}
+
+	tw := tar.NewWriter(w)
+	writeErr := writeTarFile(tw, bundleIndexName, indexData, index.CreatedAt)
+	for _, name := range names {
+		if writeErr != nil {
+			break
+		}
+		writeErr = copyIntoTar(tw, name, sources[name])
+	}
+	if writeErr == nil {
+		writeErr = tw.Close()
+	}
+	if closeErr := w.Close(); writeErr == nil {
+		writeErr = closeErr
+	}
+	if writeErr != nil {
+		_ = os.Remove(out)
+		return nil, fmt.Errorf("failed to write bundle: %w", writeErr)
+	}
+
+	return index, nil
+}
+
+// ImportRun extracts a run bundle into destDir/<run> and points the state file at the new location.
+// It returns the index of the bundle and the folder the run was extracted to.
+func ImportRun(ctx context.Context, bundle, destDir string, overwrite bool) (*BundleIndex, string, error) {
+	r, err := openBundleReader(ctx, bundle)
+	if err != nil {
+		return nil, "", err
+	}
+	defer r.Close()
+
+	tr := tar.NewReader(r)
+	header, err := tr.Next()
+	if err != nil || header.Name != bundleIndexName {
+		return nil, "", fmt.Errorf("%s is not a run bundle (missing %s)", bundle, bundleIndexName)
+	}
+	indexData, err := io.ReadAll(tr)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read bundle index: %w", err)
+	}
+	var index BundleIndex
+	if err := yaml.Unmarshal(indexData, &index); err != nil {
+		return nil, "", fmt.Errorf("failed to parse bundle index: %w", err)
+	}
+	if index.Run == "" || index.Run != filepath.Base(index.Run) || index.Run == ".." {
+		return nil, "", fmt.Errorf("bundle index has an invalid run name: %q", index.Run)
+	}
+
+	runDir, err := filepath.Abs(filepath.Join(destDir, index.Run))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to resolve destination: %w", err)
+	}
+	if _, err := os.Stat(runDir); err == nil {
+		if !overwrite {
+			return nil, "", fmt.Errorf("%s already exists (use --force to overwrite)", runDir)
+		}
+		if err := os.RemoveAll(runDir); err != nil {
+			return nil, "", fmt.Errorf("failed to remove existing run folder: %w", err)
+		}
+	}
+
+	// A half-extracted run would look like one that can be resumed, so drop it on any error
+	if err := extractRun(tr, &index, runDir); err != nil {
+		_ = os.RemoveAll(runDir)
+		return nil, "", err
+	}
+
+	return &index, runDir, nil
+}
+
+// extractRun writes the files of the bundle into runDir, checking each against the index,
+// and points the state files at runDir
+func extractRun(tr *tar.Reader, index *BundleIndex, runDir string) error {
+	seen := make(map[string]bool, len(index.Files))
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read bundle: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name, err := cleanBundlePath(header.Name)
+		if err != nil {
+			return err
+		}
+		expected, ok := index.Files[name]
+		if !ok {
+			return fmt.Errorf("bundle contains %s, which is not listed in its index", name)
+		}
+
+		target := filepath.Join(runDir, filepath.FromSlash(name))
+		if err := extractTarFile(tr, target, os.FileMode(header.Mode).Perm()); err != nil {
+			return err
+		}
+		checksum, err := utils.FileChecksum(target)
+		if err != nil {
+			return fmt.Errorf("failed to checksum %s: %w", name, err)
+		}
+		if checksum != expected {
+			return fmt.Errorf("%s is corrupted (checksum mismatch)", name)
+		}
+		seen[name] = true
+	}
+	for name := range index.Files {
+		if !seen[name] {
+			return fmt.Errorf("bundle is incomplete: %s is missing", name)
+		}
+	}
+
+	// Outputs recorded in the state file point at the exporting machine; move them to the new folder
+	if index.SourceDir != "" && index.SourceDir != runDir {
+		states, _ := filepath.Glob(filepath.Join(runDir, "*.state.yaml"))
+		for _, statePath := range states {
+			if err := rebaseFile(statePath, index.SourceDir, runDir); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// PendingSteps returns the steps of a run that did not complete, read from its state file
+func PendingSteps(runDir string) ([]string, error) {
+	states, err := filepath.Glob(filepath.Join(runDir, "*.state.yaml"))
+	if err != nil || len(states) == 0 {
+		return nil, fmt.Errorf("no workflow state file found in %s", runDir)
+	}
+
+	data, err := os.ReadFile(states[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workflow state: %w", err)
+	}
+	var summary struct {
+		Nodes map[string]struct {
+			Name   string `yaml:"name"`
+			Status string `yaml:"status"`
+		} `yaml:"nodes"`
+	}
+	if err := yaml.Unmarshal(data, &summary); err != nil {
+		return nil, fmt.Errorf("failed to parse workflow state: %w", err)
+	}
+
+	var pending []string
+	for _, node := range summary.Nodes {
+		if status := NodeStatus(node.Status); status != NodeStatusComplete && status != NodeStatusSkipped {
+			pending = append(pending, node.Name)
+		}
+	}
+	sort.Strings(pending)
+	return pending, nil
+}
+
+// bundleFiles lists the run files to bundle as slash-separated paths relative to runDir.
+// The state file and manifest are always included; other files are filtered by the include patterns.
+func bundleFiles(runDir string, include []string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(runDir, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(runDir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			// Invalidated outputs and bundles of earlier imports are not part of the run
+			if rel == staleDirName || rel == bundleExtrasDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if isRunRecord(rel) || matchesAny(rel, include) {
+			files = append(files, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list run folder: %w", err)
+	}
+	return files, nil
+}
+
+// isRunRecord reports whether a file is the state file or manifest of a run
+func isRunRecord(rel string) bool {
+	return !strings.Contains(rel, "/") &&
+		(strings.HasSuffix(rel, ".state.yaml") || strings.HasSuffix(rel, ".manifest.yaml"))
+}
+
+// hasStateFile reports whether the bundled files include a workflow state file
+func hasStateFile(files []string) bool {
+	for _, rel := range files {
+		if isRunRecord(rel) && strings.HasSuffix(rel, ".state.yaml") {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAny reports whether rel matches one of the patterns, or whether there are no patterns.
+// A pattern matches a file by its full relative path or by its base name.
+func matchesAny(rel string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, rel); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, path.Base(rel)); ok {
+			return true
+		}
+		// A directory pattern bundles everything below it
+		if strings.HasPrefix(rel, strings.TrimSuffix(pattern, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanBundlePath rejects archive entries that would be extracted outside the run folder
+func cleanBundlePath(name string) (string, error) {
+	cleaned := path.Clean(name)
+	if path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") || strings.Contains(cleaned, "\\") {
+		return "", fmt.Errorf("bundle entry %q escapes the run folder", name)
+	}
+	return cleaned, nil
+}
+
+// writeTarFile adds an in-memory file to the archive
+func writeTarFile(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	}); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// copyIntoTar adds a file from disk to the archive under name
+func copyIntoTar(tw *tar.Writer, name, source string) error {
+	f, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// extractTarFile writes the current archive entry to target. The entry is written to a
+// temporary file first so a truncated archive never leaves a partial file at target.
+func extractTarFile(r io.Reader, target string, perm os.FileMode) error {
+	if err := utils.EnsureDir(filepath.Dir(target)); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if perm == 0 {
+		perm = 0644
+	}
+	f, err := utils.CreateAtomicFile(target, perm)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", target, err)
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			utils.LogWarning("Failed to close %s: %v", target, err)
+		}
+	}()
+	if _, err := io.Copy(f, r); err != nil {
+		return fmt.Errorf("failed to extract %s: %w", target, err)
+	}
+	return f.Commit()
+}
+
+// rebaseFile replaces every occurrence of the old run folder path in a text file
+func rebaseFile(filePath, oldDir, newDir string) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+	rebased := strings.ReplaceAll(string(data), oldDir, newDir)
+	if rebased == string(data) {
+		return nil
+	}
+	if err := utils.AtomicWriteFile(filePath, []byte(rebased), 0644); err != nil {
+		return fmt.Errorf("failed to update %s: %w", filePath, err)
+	}
+	return nil
+}
+
+// bundleCompression returns the compression of a bundle from its file name
+func bundleCompression(name string) (string, error) {
+	switch lower := strings.ToLower(name); {
+	case strings.HasSuffix(lower, ".tar.zst"), strings.HasSuffix(lower, ".tzst"):
+		return "zstd", nil
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return "gzip", nil
+	case strings.HasSuffix(lower, ".tar"):
+		return "", nil
+	default:
+		return "", fmt.Errorf("unsupported bundle extension for %s (use .tar.zst, .tar.gz or .tar)", name)
+	}
+}
+
+// commandWriter streams into an external compressor and waits for it on Close
+type commandWriter struct {
+	io.WriteCloser
+	cmd *exec.Cmd
+}
+
+func (c *commandWriter) Close() error {
+	if err := c.WriteCloser.Close(); err != nil {
+		return err
+	}
+	if err := c.cmd.Wait(); err != nil {
+		return fmt.Errorf("zstd failed: %w", err)
+	}
+	return nil
+}
+
+// commandReader streams from an external decompressor and waits for it on Close
+type commandReader struct {
+	io.ReadCloser
+	cmd *exec.Cmd
+}
+
+func (c *commandReader) Close() error {
+	_ = c.ReadCloser.Close()
+	return c.cmd.Wait()
+}
+
+// gzipWriter closes both the gzip stream and the underlying file
+type gzipWriter struct {
+	*gzip.Writer
+	file *os.File
+}
+
+func (g *gzipWriter) Close() error {
+	if err := g.Writer.Close(); err != nil {
+		g.file.Close()
+		return err
+	}
+	return g.file.Close()
+}
+
+// gzipReader closes both the gzip stream and the underlying file
+type gzipReader struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (g *gzipReader) Close() error {
+	g.Reader.Close()
+	return g.file.Close()
+}
+
+// createBundleWriter opens out for writing with the compression its extension asks for
+func createBundleWriter(ctx context.Context, out string) (io.WriteCloser, error) {
+	compression, err := bundleCompression(out)
+	if err != nil {
+		return nil, err
+	}
+
+	if compression == "zstd" {
+		if err := utils.ValidateRequiredDependency("zstd"); err != nil {
+			return nil, fmt.Errorf("%w (or use a .tar.gz bundle)", err)
+		}
+		cmd := utils.CommandContext(ctx, "zstd", "-q", "-f", "-T0", "-o", out)
+		stdin, err := cmd.StdinPipe()
+		if err != nil {
+			return nil, fmt.Errorf("failed to start zstd: %w", err)
+		}
+		if err := cmd.Start(); err != nil {
+			return nil, fmt.Errorf("failed to start zstd: %w", err)
+		}
+		return &commandWriter{WriteCloser: stdin, cmd: cmd}, nil
+	}
+
+	f, err := os.Create(out)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bundle: %w", err)
+	}
+	if compression == "gzip" {
+		return &gzipWriter{Writer: gzip.NewWriter(f), file: f}, nil
+	}
+	return f, nil
+}
+
+// openBundleReader opens a bundle for reading and decompresses it according to its extension
+func openBundleReader(ctx context.Context, bundle string) (io.ReadCloser, error) {
+	compression, err := bundleCompression(bundle)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(bundle); err != nil {
+		return nil, fmt.Errorf("bundle does not exist: %s", bundle)
+	}
+
+	if compression == "zstd" {
+		if err := utils.ValidateRequiredDependency("zstd"); err != nil {
+			return nil, err
+		}
+		cmd := utils.CommandContext(ctx, "zstd", "-q", "-d", "-c", bundle)
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, fmt.Errorf("failed to start zstd: %w", err)
+		}
+		if err := cmd.Start(); err != nil {
+			return nil, fmt.Errorf("failed to start zstd: %w", err)
+		}
+		return &commandReader{ReadCloser: stdout, cmd: cmd}, nil
+	}
+
+	f, err := os.Open(bundle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle: %w", err)
+	}
+	if compression == "gzip" {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to read bundle: %w", err)
+		}
+		return &gzipReader{Reader: gz, file: f}, nil
+	}
+	return f, nil
+}
@@ -0,0 +1,117 @@
+package workflow
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportImportRun(t *testing.T) {
+	runDir := filepath.Join(t.TempDir(), "ep12-20260101-120000")
+	files := map[string]string{
+		"Episode.state.yaml": "nodes:\n" +
+			"  a:\n    name: transcribe\n    status: complete\n    outputs:\n      transcript: " + runDir + "/audio.srt\n" +
+			"  b:\n    name: upload\n    status: failed\n",
+		"Episode.manifest.yaml":        "workflow: Episode\n",
+		"audio.srt":                    "1\n00:00:00,000 --> 00:00:01,000\nhello\n",
+		"audio.wav":                    "RIFF",
+		"clips/short1.mp4":             "mp4",
+		".stale/20260101-130000/x.txt": "stale",
+	}
+	for name, content := range files {
+		path := filepath.Join(runDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	promptsDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(promptsDir, "shorts_prompts.yaml"), []byte("prompt"), 0644))
+
+	bundle := filepath.Join(t.TempDir(), "run.tar.gz")
+	index, err := ExportRun(context.Background(), runDir, bundle, BundleOptions{
+		Include:    []string{"*.srt", "clips"},
+		PromptsDir: promptsDir,
+	})
+	require.NoError(t, err)
+	assert.Contains(t, index.Files, "audio.srt")
+	assert.Contains(t, index.Files, "clips/short1.mp4")
+	assert.Contains(t, index.Files, "Episode.manifest.yaml")
+	assert.Contains(t, index.Files, ".bundle/prompts/shorts_prompts.yaml")
+	assert.NotContains(t, index.Files, "audio.wav")
+	assert.NotContains(t, index.Files, ".stale/20260101-130000/x.txt")
+
+	dest := t.TempDir()
+	imported, importedDir, err := ImportRun(context.Background(), bundle, dest, false)
+	require.NoError(t, err)
+	assert.Equal(t, "ep12-20260101-120000", imported.Run)
+	assert.FileExists(t, filepath.Join(importedDir, "clips", "short1.mp4"))
+	assert.FileExists(t, filepath.Join(importedDir, ".bundle", "prompts", "shorts_prompts.yaml"))
+
+	state, err := os.ReadFile(filepath.Join(importedDir, "Episode.state.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(state), importedDir+"/audio.srt")
+	assert.NotContains(t, string(state), runDir)
+
+	pending, err := PendingSteps(importedDir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"upload"}, pending)
+
+	_, _, err = ImportRun(context.Background(), bundle, dest, false)
+	assert.ErrorContains(t, err, "already exists")
+	_, _, err = ImportRun(context.Background(), bundle, dest, true)
+	assert.NoError(t, err)
+}
+
+func TestImportRunRejectsUnsafeBundles(t *testing.T) {
+	bundle := filepath.Join(t.TempDir(), "evil.tar.gz")
+	f, err := os.Create(bundle)
+	require.NoError(t, err)
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	index := []byte("run: evil\nfiles:\n  ../escape.txt: abc\n")
+	require.NoError(t, writeTarFile(tw, bundleIndexName, index, time.Now()))
+	require.NoError(t, writeTarFile(tw, "../escape.txt", []byte("x"), time.Now()))
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	require.NoError(t, f.Close())
+
+	_, _, err = ImportRun(context.Background(), bundle, t.TempDir(), false)
+	assert.ErrorContains(t, err, "escapes the run folder")
+}
+
+func TestBundleCompression(t *testing.T) {
+	for name, want := range map[string]string{"run.tar.zst": "zstd", "run.TGZ": "gzip", "run.tar.gz": "gzip", "run.tar": ""} {
+		got, err := bundleCompression(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, got, name)
+	}
+	_, err := bundleCompression("run.zip")
+	assert.Error(t, err)
+}
+
+func TestImportRunRemovesTruncatedRun(t *testing.T) {
+	runDir := filepath.Join(t.TempDir(), "ep13-20260101-120000")
+	require.NoError(t, os.MkdirAll(runDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(runDir, "Episode.state.yaml"), []byte("nodes: {}\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(runDir, "clip.mp4"), make([]byte, 64*1024), 0644))
+
+	bundle := filepath.Join(t.TempDir(), "run.tar")
+	_, err := ExportRun(context.Background(), runDir, bundle, BundleOptions{Include: []string{"*.mp4"}})
+	require.NoError(t, err)
+
+	// Cut the archive in the middle of the clip
+	info, err := os.Stat(bundle)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(bundle, info.Size()/2))
+
+	dest := t.TempDir()
+	_, _, err = ImportRun(context.Background(), bundle, dest, false)
+	require.Error(t, err)
+	assert.NoDirExists(t, filepath.Join(dest, "ep13-20260101-120000"), "a truncated import leaves no run folder behind")
+}
@@ -0,0 +1,212 @@
+package workflow
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	"gopkg.in/yaml.v3"
+)
+
+// Codes of the problems Diagnose finds in the steps of a workflow, on top of the schema issues
+const (
+	IssueSyntax       = "syntax"        // The file is not valid YAML
+	IssueMissingInput = "missing-input" // A step has no way to get one of its inputs
+	IssueUnreachable  = "unreachable"   // A step depends on a step that cannot run
+)
+
+// Severities of diagnostics
+const (
+	SeverityError   = "error"   // The workflow cannot run
+	SeverityWarning = "warning" // The workflow may run, but probably not as intended
+)
+
+// Diagnostic is a problem of a workflow being edited, located for editors
+type Diagnostic struct {
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Path     string `json:"path,omitempty"` // Path of the value, e.g. steps[2].parameters.input
+	Step     string `json:"step,omitempty"` // Name of the step the problem is in
+	Message  string `json:"message"`
+}
+
+// DiagnosticReport is the result of diagnosing a workflow
+type DiagnosticReport struct {
+	Valid       bool         `json:"valid"` // No diagnostic is an error
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// yamlErrorLine matches the line number of a YAML syntax error
+var yamlErrorLine = regexp.MustCompile(`line (\d+):`)
+
+// stepPath matches the step index at the start of a schema issue path
+var stepPath = regexp.MustCompile(`^steps\[(\d+)\]`)
+
+// Diagnose checks a workflow that may still be incomplete, as written in an editor, and returns
+// every problem found: schema issues, steps without a way to get their inputs and steps that depend
+// on broken steps. Unlike ValidateFile, it neither reads the input files nor runs the parameter
+// checks of the modules, so it works before the inputs exist and on any machine.
+func Diagnose(data []byte, registry *mod.ModuleRegistry) DiagnosticReport {
+	report := DiagnosticReport{Diagnostics: []Diagnostic{}}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		d := Diagnostic{Severity: SeverityError, Code: IssueSyntax, Message: err.Error()}
+		if m := yamlErrorLine.FindStringSubmatch(err.Error()); m != nil {
+			d.Line, _ = strconv.Atoi(m[1])
+		}
+		report.Diagnostics = append(report.Diagnostics, d)
+		return report
+	}
+
+	// Steps are decoded one at a time, so a malformed step does not hide the problems of the others
+	var stepNodes []*yaml.Node
+	if len(doc.Content) > 0 {
+		if steps := mappingValue(doc.Content[0], "steps"); steps != nil && steps.Kind == yaml.SequenceNode {
+			stepNodes = steps.Content
+		}
+	}
+	w := &Workflow{registry: registry}
+	if len(doc.Content) > 0 {
+		if input := mappingValue(doc.Content[0], "input"); input != nil && input.Kind == yaml.ScalarNode {
+			w.Input = input.Value
+		}
+	}
+	for _, node := range stepNodes {
+		var step Step
+		_ = node.Decode(&step)
+		w.Steps = append(w.Steps, step)
+	}
+
+	for _, issue := range schemaIssues(&doc, registry) {
+		d := Diagnostic{
+			Severity: SeverityError,
+			Code:     issue.Code,
+			Line:     issue.Line,
+			Column:   issue.Column,
+			Path:     issue.Path,
+			Message:  issue.Message,
+		}
+		if m := stepPath.FindStringSubmatch(issue.Path); m != nil {
+			if i, _ := strconv.Atoi(m[1]); i < len(w.Steps) {
+				d.Step = w.Steps[i].Name
+			}
+		}
+		report.Diagnostics = append(report.Diagnostics, d)
+	}
+	report.Diagnostics = append(report.Diagnostics, w.diagnoseSteps(stepNodes)...)
+	sort.SliceStable(report.Diagnostics, func(i, j int) bool {
+		return report.Diagnostics[i].Line < report.Diagnostics[j].Line
+	})
+
+	report.Valid = true
+	for _, d := range report.Diagnostics {
+		if d.Severity == SeverityError {
+			report.Valid = false
+		}
+	}
+	return report
+}
+
+// diagnoseSteps finds the steps that have no way to get their inputs and the steps that cannot run
+// because a step they depend on cannot
+func (w *Workflow) diagnoseSteps(nodes []*yaml.Node) []Diagnostic {
+	var diagnostics []Diagnostic
+	broken := map[string]bool{}
+	for i, step := range w.Steps {
+		node := nodes[i]
+		path := fmt.Sprintf("steps[%d]", i)
+		module, err := w.registry.Get(step.Module)
+		if err != nil {
+			// Reported by the schema
+			broken[step.Name] = true
+			continue
+		}
+
+		producer := w.inputProducer(i)
+		if producer != "" && broken[producer] {
+			broken[step.Name] = true
+			diagnostics = append(diagnostics, Diagnostic{
+				Severity: SeverityError,
+				Code:     IssueUnreachable,
+				Line:     node.Line,
+				Column:   node.Column,
+				Path:     path,
+				Step:     step.Name,
+				Message:  fmt.Sprintf("step %q cannot run: its input comes from step %q, which cannot run", step.Name, producer),
+			})
+			continue
+		}
+
+		for _, input := range module.GetIO().RequiredInputs {
+			if input.Name == "output" || hasParam(step, input.Name) {
+				continue
+			}
+			if input.Name == "input" {
+				// A broken earlier step may be the one meant to produce it
+				if producer != "" || (i == 0 && w.Input != "") || len(broken) > 0 {
+					continue
+				}
+				message := fmt.Sprintf("step %q has no input: set parameters.input", step.Name)
+				switch {
+				case i == 0:
+					message += " or the workflow input"
+				case len(input.Patterns) > 0:
+					message += fmt.Sprintf(" or add an earlier step producing %s", strings.Join(input.Patterns, ", "))
+				}
+				diagnostics = append(diagnostics, Diagnostic{
+					Severity: SeverityError,
+					Code:     IssueMissingInput,
+					Line:     node.Line,
+					Column:   node.Column,
+					Path:     path + ".parameters.input",
+					Step:     step.Name,
+					Message:  message,
+				})
+				continue
+			}
+
+			// Modules fill in some of their other inputs themselves, e.g. the project's credentials
+			diagnostics = append(diagnostics, Diagnostic{
+				Severity: SeverityWarning,
+				Code:     IssueMissingInput,
+				Line:     node.Line,
+				Column:   node.Column,
+				Path:     path + ".parameters." + input.Name,
+				Step:     step.Name,
+				Message:  fmt.Sprintf("step %q does not set %s (%s); it runs only if the module has a default for it", step.Name, input.Name, input.Description),
+			})
+		}
+	}
+	return diagnostics
+}
+
+// hasParam reports whether a step sets a parameter to a value
+func hasParam(step Step, name string) bool {
+	value, ok := step.Parameters[name]
+	if !ok || value == nil {
+		return false
+	}
+	if s, isString := value.(string); isString {
+		return strings.TrimSpace(s) != ""
+	}
+	return true
+}
+
+// mappingValue returns the value of a key of a YAML mapping, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
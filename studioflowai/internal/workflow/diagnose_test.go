@@ -0,0 +1,84 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func diagnose(t *testing.T, yaml string) DiagnosticReport {
+	registry, err := NewRegistry()
+	require.NoError(t, err)
+	return Diagnose([]byte(yaml), registry)
+}
+
+func TestDiagnose(t *testing.T) {
+	// Inputs need not exist, and later steps take the outputs of earlier ones
+	report := diagnose(t, `name: Shorts
+input: /nowhere/episode.mp4
+steps:
+  - name: Extract Audio
+    module: extractaudio
+  - name: Transcribe
+    module: transcribe
+`)
+	assert.True(t, report.Valid, report.Diagnostics)
+	assert.Empty(t, report.Diagnostics)
+}
+
+func TestDiagnose_PartialWorkflow(t *testing.T) {
+	report := diagnose(t, `name: Draft
+steps:
+  - name: Audio
+    module: extractaudio
+  - name: Transcribe
+    module: transcrib
+  - name: Clean
+    module: clean_text
+    fromStep: Transcribe
+    parameters:
+      removePaterns: []
+  - name: Clips
+    module: extract_shorts
+    parameters:
+      input: shorts.yaml
+`)
+	assert.False(t, report.Valid)
+
+	byCode := map[string][]Diagnostic{}
+	for _, d := range report.Diagnostics {
+		byCode[d.Code] = append(byCode[d.Code], d)
+	}
+
+	require.Len(t, byCode[IssueMissingInput], 2)
+	assert.Equal(t, Diagnostic{
+		Severity: SeverityError, Code: IssueMissingInput, Line: 3, Column: 5,
+		Path: "steps[0].parameters.input", Step: "Audio",
+		Message: `step "Audio" has no input: set parameters.input or the workflow input`,
+	}, byCode[IssueMissingInput][0])
+	assert.Equal(t, SeverityWarning, byCode[IssueMissingInput][1].Severity)
+	assert.Equal(t, "steps[3].parameters.videoFile", byCode[IssueMissingInput][1].Path)
+
+	require.Len(t, byCode[IssueUnknownModule], 1)
+	assert.Equal(t, "Transcribe", byCode[IssueUnknownModule][0].Step)
+	assert.Contains(t, byCode[IssueUnknownModule][0].Message, `did you mean "transcribe"?`)
+
+	require.Len(t, byCode[IssueUnreachable], 1)
+	assert.Equal(t, "Clean", byCode[IssueUnreachable][0].Step)
+
+	require.Len(t, byCode[IssueUnknownParam], 1)
+	assert.Equal(t, 11, byCode[IssueUnknownParam][0].Line)
+
+	for i := 1; i < len(report.Diagnostics); i++ {
+		assert.LessOrEqual(t, report.Diagnostics[i-1].Line, report.Diagnostics[i].Line, "diagnostics are in file order")
+	}
+}
+
+func TestDiagnose_Syntax(t *testing.T) {
+	report := diagnose(t, "name: Draft\nsteps:\n  - name: [oops\n")
+	assert.False(t, report.Valid)
+	require.Len(t, report.Diagnostics, 1)
+	assert.Equal(t, IssueSyntax, report.Diagnostics[0].Code)
+	assert.NotZero(t, report.Diagnostics[0].Line)
+}
@@ -0,0 +1,210 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// FixturesFileName is the file of a fixtures folder that describes what each step produces
+const FixturesFileName = "fixtures.yaml"
+
+// fixtureStepParam is the parameter that tells a fixture module which step it runs for. It is
+// removed before the parameters are checked.
+const fixtureStepParam = "__fixtureStep"
+
+// Fixtures mock the results of the steps of a workflow, so the workflow can be tested without
+// external tools or API calls
+type Fixtures struct {
+	Input string                 `yaml:"input"` // Workflow input, relative to the fixtures folder
+	Steps map[string]StepFixture `yaml:"steps"` // Fixtures by step name
+
+	dir string
+}
+
+// StepFixture is what a step produces in a test, and what its parameters must resolve to
+type StepFixture struct {
+	Outputs  map[string]string      `yaml:"outputs"`  // Output name to a file of the fixtures folder, copied into the run folder
+	Metadata map[string]interface{} `yaml:"metadata"` // Metadata the step reports
+	Expect   map[string]string      `yaml:"expect"`   // Parameter to the value it must resolve to; ${output}, ${input} and ${fixtures} are expanded
+}
+
+// FixtureStepReport is the outcome of one step in a test run
+type FixtureStepReport struct {
+	Name     string
+	Module   string
+	Input    string            // Resolved input parameter, showing how the step was wired
+	Outputs  map[string]string // Outputs copied from the fixtures
+	Problems []string          // Expectations that were not met
+}
+
+// FixtureReport is the outcome of a test run
+type FixtureReport struct {
+	Steps    []FixtureStepReport
+	Problems []string // Problems not tied to a step that ran, such as fixtures of unknown steps
+	Output   string   // Run folder the test wrote to
+}
+
+// Failed reports whether any expectation of the test run was not met
+func (r *FixtureReport) Failed() bool {
+	if len(r.Problems) > 0 {
+		return true
+	}
+	for _, step := range r.Steps {
+		if len(step.Problems) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadFixtures reads the fixtures.yaml of a fixtures folder
+func LoadFixtures(dir string) (*Fixtures, error) {
+	path := filepath.Join(dir, FixturesFileName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixtures: %w", err)
+	}
+
+	var fixtures Fixtures
+	if err := yaml.Unmarshal(data, &fixtures); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	fixtures.dir = dir
+	return &fixtures, nil
+}
+
+// InputPath returns the path of the workflow input of the fixtures, or "" when they set none
+func (f *Fixtures) InputPath() string {
+	if f.Input == "" || filepath.IsAbs(f.Input) {
+		return f.Input
+	}
+	return filepath.Join(f.dir, f.Input)
+}
+
+// path returns the path of a file of the fixtures folder
+func (f *Fixtures) path(file string) string {
+	if filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(f.dir, file)
+}
+
+// fixtureModule stands in for a module in a test run. It keeps the module's inputs and outputs,
+// so steps are wired as in a real run, and returns the step's fixture instead of executing.
+type fixtureModule struct {
+	mod.Module
+	fixtures *Fixtures
+	vars     Variables
+	report   *FixtureReport
+}
+
+func (m *fixtureModule) Validate(params map[string]interface{}) error { return nil }
+
+func (m *fixtureModule) Execute(ctx context.Context, params map[string]interface{}) (mod.ModuleResult, error) {
+	name, _ := params[fixtureStepParam].(string)
+	delete(params, fixtureStepParam)
+	step := Step{Name: name, Module: m.Name()}
+
+	fixture, ok := m.fixtures.Steps[name]
+	if !ok {
+		return mod.ModuleResult{}, fmt.Errorf("no fixture for step %q in %s", name, FixturesFileName)
+	}
+
+	stepReport := FixtureStepReport{Name: name, Module: m.Name(), Outputs: make(map[string]string)}
+	stepReport.Input, _ = params["input"].(string)
+
+	// Check the parameters the step was given, after interpolation and wiring
+	keys := make([]string, 0, len(fixture.Expect))
+	for key := range fixture.Expect {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		want := m.vars.Resolve(key, strings.ReplaceAll(fixture.Expect[key], "${fixtures}", m.fixtures.dir), step)
+		value, set := params[key]
+		switch {
+		case !set:
+			stepReport.Problems = append(stepReport.Problems, fmt.Sprintf("%s is not set, expected %q", key, want))
+		case fmt.Sprint(value) != want:
+			stepReport.Problems = append(stepReport.Problems, fmt.Sprintf("%s is %q, expected %q", key, fmt.Sprint(value), want))
+		}
+	}
+
+	// Copy the fixture outputs into the run folder, where the next steps look for them
+	outputDir, _ := params["output"].(string)
+	for output, file := range fixture.Outputs {
+		target := filepath.Join(outputDir, filepath.Base(file))
+		if err := utils.CopyFile(m.fixtures.path(file), target); err != nil {
+			return mod.ModuleResult{}, fmt.Errorf("fixture output %s of step %q: %w", output, name, err)
+		}
+		stepReport.Outputs[output] = target
+	}
+	m.report.Steps = append(m.report.Steps, stepReport)
+
+	return mod.ModuleResult{
+		Outputs:  stepReport.Outputs,
+		Metadata: fixture.Metadata,
+		Stats:    mod.Stats{Items: len(stepReport.Outputs)},
+	}, nil
+}
+
+// ExecuteWithFixtures runs the workflow with every module replaced by the fixtures, checking
+// variable interpolation and how the steps are wired without running any tool or calling any API.
+// An error is returned when the workflow cannot run; unmet expectations are in the report.
+func (w *Workflow) ExecuteWithFixtures(fixtures *Fixtures) (*FixtureReport, error) {
+	report := &FixtureReport{Output: w.Output}
+
+	known := make(map[string]bool, len(w.Steps))
+	for _, step := range w.Steps {
+		known[step.Name] = true
+	}
+	for name := range fixtures.Steps {
+		if !known[name] {
+			report.Problems = append(report.Problems, fmt.Sprintf("fixture for unknown step %q", name))
+		}
+	}
+	sort.Strings(report.Problems)
+
+	// Expected values are resolved like the parameters they are compared with
+	vars := Variables{Output: w.Output}
+	if w.Input != "" && len(w.Steps) > 0 {
+		vars.Input = vars.Resolve("input", w.Input, w.Steps[0])
+	}
+
+	registry := mod.NewModuleRegistry()
+	for _, module := range w.registry.ListModules() {
+		if err := registry.Register(&fixtureModule{Module: module, fixtures: fixtures, vars: vars, report: report}); err != nil {
+			return report, err
+		}
+	}
+
+	// Each step tells its fixture module which step it is
+	steps := make([]Step, len(w.Steps))
+	for i, step := range w.Steps {
+		params := make(map[string]interface{}, len(step.Parameters)+1)
+		for k, v := range step.Parameters {
+			params[k] = v
+		}
+		params[fixtureStepParam] = step.Name
+		step.Parameters = params
+		steps[i] = step
+	}
+
+	original, originalRegistry := w.Steps, w.registry
+	defer func() {
+		w.Steps, w.registry = original, originalRegistry
+	}()
+	w.Steps, w.registry = steps, registry
+	if _, err := w.ExecuteWithState(); err != nil {
+		return report, err
+	}
+	return report, nil
+}
@@ -0,0 +1,123 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fixtureWorkflow = `name: Fixture Test
+steps:
+  - name: Extract Audio
+    module: extractaudio
+    parameters:
+      input: ./missing.mov
+      outputName: audio.wav
+  - name: Transcribe
+    module: transcribe
+    parameters:
+      input: "${output}/audio.wav"
+      outputFileName: transcript
+      model: whisper
+      outputFormat: srt
+  - name: Clean
+    module: clean_text
+    parameters:
+      input: "${output}/transcript.srt"
+      outputFileName: transcript
+`
+
+// writeFixtures writes a workflow and a fixtures folder with the given fixtures.yaml
+func writeFixtures(t *testing.T, fixtures string) (string, string) {
+	dir := t.TempDir()
+	workflowPath := filepath.Join(dir, "workflow.yaml")
+	require.NoError(t, os.WriteFile(workflowPath, []byte(fixtureWorkflow), 0644))
+
+	fixturesDir := filepath.Join(dir, "fixtures")
+	require.NoError(t, os.MkdirAll(fixturesDir, 0755))
+	for name, content := range map[string]string{
+		"episode.mov":    "video",
+		"audio.wav":      "wav",
+		"transcript.srt": "1\n00:00:00,000 --> 00:00:01,000\nHello\n",
+		"clean.txt":      "Hello",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(fixturesDir, name), []byte(content), 0644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(fixturesDir, FixturesFileName), []byte(fixtures), 0644))
+	return workflowPath, fixturesDir
+}
+
+func runFixtures(t *testing.T, fixtures string) (*FixtureReport, error) {
+	workflowPath, fixturesDir := writeFixtures(t, fixtures)
+	f, err := LoadFixtures(fixturesDir)
+	require.NoError(t, err)
+
+	inputConfig, err := config.NewInputConfig(f.InputPath(), t.TempDir(), workflowPath, false, "", "")
+	require.NoError(t, err)
+	w, err := LoadFromFile(inputConfig)
+	require.NoError(t, err)
+	return w.ExecuteWithFixtures(f)
+}
+
+func TestExecuteWithFixtures(t *testing.T) {
+	report, err := runFixtures(t, `input: episode.mov
+steps:
+  Extract Audio:
+    outputs:
+      audio: audio.wav
+    expect:
+      input: ${fixtures}/episode.mov
+  Transcribe:
+    outputs:
+      transcript: transcript.srt
+    expect:
+      input: ${output}/audio.wav
+      model: whisper
+  Clean:
+    outputs:
+      text: clean.txt
+    expect:
+      input: ${output}/transcript.srt
+`)
+	require.NoError(t, err)
+	assert.False(t, report.Failed(), "problems: %v", report)
+	require.Len(t, report.Steps, 3)
+	assert.Equal(t, "Extract Audio", report.Steps[0].Name)
+	assert.Equal(t, filepath.Join(report.Output, "audio.wav"), report.Steps[1].Input)
+	assert.FileExists(t, report.Steps[2].Outputs["text"])
+}
+
+func TestExecuteWithFixtures_Problems(t *testing.T) {
+	report, err := runFixtures(t, `input: episode.mov
+steps:
+  Extract Audio:
+    outputs:
+      audio: audio.wav
+  Transcribe:
+    expect:
+      input: ${output}/speech.wav
+      language: en
+  Clean: {}
+  Publish: {}
+`)
+	require.NoError(t, err)
+	assert.True(t, report.Failed())
+	assert.Equal(t, []string{`fixture for unknown step "Publish"`}, report.Problems)
+	require.Len(t, report.Steps, 3)
+	assert.Len(t, report.Steps[1].Problems, 2)
+	assert.Contains(t, report.Steps[1].Problems[0], "input is")
+	assert.Contains(t, report.Steps[1].Problems[1], "language is not set")
+
+	// A step without a fixture stops the test
+	_, err = runFixtures(t, `input: episode.mov
+steps:
+  Extract Audio:
+    outputs:
+      audio: audio.wav
+`)
+	assert.ErrorContains(t, err, `no fixture for step "Transcribe"`)
+}
@@ -116,7 +116,7 @@ func (g *WorkflowGraph) CanExecuteNode(nodeID string) bool {
 
 	deps := g.GetNodeDependencies(nodeID)
 	for _, depID := range deps {
-		if g.Nodes[depID].Status != NodeStatusComplete {
+		if status := g.Nodes[depID].Status; status != NodeStatusComplete && status != NodeStatusPartiallyFailed {
 			return false
 		}
 	}
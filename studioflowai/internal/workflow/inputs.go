@@ -0,0 +1,133 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+// inputPatterns returns the file patterns a module accepts as its input
+func inputPatterns(module mod.Module) []string {
+	for _, input := range module.GetIO().RequiredInputs {
+		if input.Name == "input" {
+			return input.Patterns
+		}
+	}
+	return nil
+}
+
+// inputProducer returns the step whose output becomes the input of step i: the step named by its
+// fromStep, or else the latest earlier step producing a file the module accepts as input. It returns
+// "" when the input must already exist.
+func (w *Workflow) inputProducer(i int) string {
+	step := w.Steps[i]
+	if step.FromStep != "" {
+		return step.FromStep
+	}
+	if i == 0 {
+		return ""
+	}
+
+	module, err := w.registry.Get(step.Module)
+	if err != nil {
+		return ""
+	}
+	var input *mod.ModuleInput
+	for _, required := range module.GetIO().RequiredInputs {
+		if required.Name == "input" {
+			input = &required
+			break
+		}
+	}
+	if input == nil {
+		return ""
+	}
+
+	for j := i - 1; j >= 0; j-- {
+		prevModule, err := w.registry.Get(w.Steps[j].Module)
+		if err != nil {
+			continue
+		}
+		for _, output := range prevModule.GetIO().ProducedOutputs {
+			if matchesIOPattern(*input, output) {
+				return w.Steps[j].Name
+			}
+		}
+	}
+	return ""
+}
+
+// producedInput returns the output of the producer step that matches the input patterns of step's
+// module; a producer with a single output provides it whatever its name
+func producedInput(module mod.Module, outputs map[string]string) (string, bool) {
+	names := make([]string, 0, len(outputs))
+	for name := range outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, pattern := range inputPatterns(module) {
+			if strings.HasSuffix(outputs[name], strings.TrimPrefix(pattern, "*")) {
+				return outputs[name], true
+			}
+		}
+	}
+	if len(outputs) == 1 {
+		return outputs[names[0]], true
+	}
+	return "", false
+}
+
+// validateSteps runs the parameter checks of every step's module. Modules are told which step
+// produces their input, so only inputs that come from outside the workflow must exist. Outputs go
+// to a scratch folder, so validation leaves no directories behind.
+func (w *Workflow) validateSteps() error {
+	scratch, err := utils.MakeTempDir("", "validate")
+	if err != nil {
+		return fmt.Errorf("failed to create validation folder: %w", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(scratch); err != nil {
+			utils.LogWarning("Failed to remove validation folder: %v", err)
+		}
+	}()
+	w.Output = scratch
+
+	input := ""
+	if w.Input != "" && len(w.Steps) > 0 {
+		input = Variables{Output: w.Output}.Resolve("input", w.Input, w.Steps[0])
+	}
+
+	var errs []error
+	for i, step := range w.Steps {
+		module, err := w.registry.Get(step.Module)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("step %q: %w", step.Name, err))
+			continue
+		}
+
+		params := Variables{Output: w.Output, Input: input}.ResolveParams(step)
+		if i == 0 && input != "" {
+			params["input"] = input
+		}
+		params["output"] = w.Output
+		if producer := w.inputProducer(i); producer != "" {
+			params[mod.FromStepParam] = producer
+		}
+
+		// Files named by the parameters of a worker's step may only exist on the worker
+		if step.Worker != "" {
+			continue
+		}
+		if err := module.Validate(params); err != nil {
+			errs = append(errs, fmt.Errorf("step %q: %w", step.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
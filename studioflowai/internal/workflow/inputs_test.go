@@ -0,0 +1,169 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// checkingModule reads .wav files and, like the real modules, requires inputs not produced by an earlier step to exist
+type checkingModule struct {
+	producers []string
+}
+
+func (m *checkingModule) Name() string { return "check" }
+
+func (m *checkingModule) Validate(params map[string]interface{}) error {
+	producer := mod.FromStep(params)
+	m.producers = append(m.producers, producer)
+	if producer != "" {
+		return nil
+	}
+	input, _ := params["input"].(string)
+	if _, err := os.Stat(input); err != nil {
+		return fmt.Errorf("input path does not exist: %s", input)
+	}
+	return nil
+}
+
+func (m *checkingModule) GetIO() mod.ModuleIO {
+	return mod.ModuleIO{
+		RequiredInputs: []mod.ModuleInput{
+			{Name: "input", Patterns: []string{".wav"}, Type: string(mod.InputTypeFile)},
+		},
+	}
+}
+
+func (m *checkingModule) Execute(ctx context.Context, params map[string]interface{}) (mod.ModuleResult, error) {
+	return mod.ModuleResult{}, nil
+}
+
+func TestValidateSteps_FromStep(t *testing.T) {
+	checker := &checkingModule{}
+	registry := mod.NewModuleRegistry()
+	require.NoError(t, registry.Register(&recordingModule{}))
+	require.NoError(t, registry.Register(checker))
+
+	w := &Workflow{
+		Steps: []Step{
+			{Name: "first", Module: "record", Parameters: map[string]interface{}{"input": "notes.txt"}},
+			{Name: "missing", Module: "check", Parameters: map[string]interface{}{"input": "./output/audio.wav"}},
+			{Name: "declared", Module: "check", Parameters: map[string]interface{}{"input": "./output/audio.wav"}, FromStep: "first"},
+		},
+		registry: registry,
+	}
+
+	err := w.validateSteps()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `step "missing": input path does not exist: ./output/audio.wav`)
+	assert.NotContains(t, err.Error(), `step "declared"`)
+	assert.Equal(t, []string{"", "first"}, checker.producers)
+}
+
+func TestInputProducer(t *testing.T) {
+	registry := mod.NewModuleRegistry()
+	require.NoError(t, registry.Register(&recordingModule{}))
+	require.NoError(t, registry.Register(&checkingModule{}))
+
+	w := &Workflow{
+		Steps: []Step{
+			{Name: "first", Module: "record"},
+			{Name: "second", Module: "record"},
+			{Name: "wav", Module: "check"},
+			{Name: "declared", Module: "check", FromStep: "first"},
+		},
+		registry: registry,
+	}
+
+	assert.Equal(t, "", w.inputProducer(0), "the first step reads the workflow input")
+	assert.Equal(t, "first", w.inputProducer(1), "an earlier step producing the input type")
+	assert.Equal(t, "", w.inputProducer(2), "no earlier step produces .wav files")
+	assert.Equal(t, "first", w.inputProducer(3), "fromStep wins over inference")
+}
+
+func TestExecute_FromStep(t *testing.T) {
+	outputDir := t.TempDir()
+	w, recorder := newRecordingWorkflow(t, outputDir)
+	w.Steps = append(w.Steps, Step{Name: "third", Module: "record", FromStep: "first", Parameters: map[string]interface{}{
+		"input": "placeholder.txt",
+		"label": "${step.name}",
+	}})
+
+	_, err := w.ExecuteWithState()
+	require.NoError(t, err)
+	require.Len(t, recorder.calls, 3)
+	assert.Equal(t, filepath.Join(outputDir, "first.txt"), recorder.calls[2]["input"],
+		"the declared producer is used instead of the latest matching step")
+}
+
+func TestValidateWorkflowSchema_FromStep(t *testing.T) {
+	registry := mod.NewModuleRegistry()
+	require.NoError(t, registry.Register(&recordingModule{}))
+
+	valid := []byte("name: x\nsteps:\n  - name: first\n    module: record\n  - name: second\n    module: record\n    fromStep: first\n")
+	assert.NoError(t, ValidateWorkflowSchema("", valid, registry))
+
+	later := []byte("name: x\nsteps:\n  - name: first\n    module: record\n    fromStep: second\n  - name: second\n    module: record\n")
+	err := ValidateWorkflowSchema("", later, registry)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `fromStep "second" does not name an earlier step`)
+}
+
+func TestValidateFile_VideoFromEarlierStep(t *testing.T) {
+	defer func() { utils.ExecLookPath = exec.LookPath }()
+	utils.ExecLookPath = func(file string) (string, error) { return file, nil }
+
+	dir := t.TempDir()
+	source := filepath.Join(dir, "episode.mp4")
+	require.NoError(t, os.WriteFile(source, []byte("video"), 0644))
+
+	// The review steps read the proxy written by make_proxy, which only exists once the run reaches them
+	path := filepath.Join(dir, "workflow.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`name: review
+steps:
+  - name: Make Proxy
+    module: make_proxy
+    parameters:
+      input: "`+source+`"
+  - name: Score Shorts
+    module: score_shorts
+    parameters:
+      input: "${output}/shorts_suggestions.yaml"
+      videoFile: "${output}/episode_proxy.mp4"
+  - name: Preview Shorts
+    module: extract_shorts
+    parameters:
+      input: "${output}/shorts_suggestions.yaml"
+      videoFile: "${output}/episode_proxy.mp4"
+      mode: "preview"
+  - name: Storyboards
+    module: storyboard_shorts
+    parameters:
+      input: "${output}/shorts_suggestions.yaml"
+      videoFile: "${output}/episode_proxy.mp4"
+  - name: Timeline
+    module: export_timeline
+    parameters:
+      input: "${output}/shorts_suggestions.yaml"
+      videoFile: "${output}/episode_proxy.mp4"
+`), 0644))
+	assert.NoError(t, ValidateFile(path, ""))
+
+	// A video outside the run folder must still exist
+	missing := filepath.Join(dir, "missing.mp4")
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte(strings.Replace(string(data), "${output}/episode_proxy.mp4", missing, 1)), 0644))
+	err = ValidateFile(path, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `step "Score Shorts": video file does not exist: `+missing)
+}
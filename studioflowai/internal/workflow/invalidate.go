@@ -0,0 +1,82 @@
+package workflow
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/config"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+// staleDirName is the folder inside a run where invalidated artifacts are moved
+const staleDirName = ".stale"
+
+// invalidationMode returns how stale artifacts are handled on retry, moving them by default
+func (w *Workflow) invalidationMode() string {
+	if w.inputConfig != nil && w.inputConfig.Invalidate != "" {
+		return w.inputConfig.Invalidate
+	}
+	return config.InvalidateMove
+}
+
+// invalidateArtifacts moves or deletes the artifacts the given steps recorded in a previous attempt,
+// so later steps cannot match outputs from two different attempts. Invalidated artifacts are
+// removed from the manifest. It returns the manifest keys of the invalidated artifacts.
+func invalidateArtifacts(manifest *RunManifest, baseDir string, steps []Step, mode string) ([]string, error) {
+	if mode == config.InvalidateKeep {
+		return nil, nil
+	}
+
+	retried := make(map[string]bool, len(steps))
+	for _, step := range steps {
+		retried[step.Name] = true
+	}
+
+	var keys, invalidated []string
+	for key, record := range manifest.Artifacts {
+		if retried[record.Step] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	staleDir := filepath.Join(baseDir, staleDirName, time.Now().Format("20060102-150405"))
+	for _, key := range keys {
+		path := key
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(baseDir, key)
+		}
+
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			delete(manifest.Artifacts, key)
+			continue
+		}
+
+		if mode == config.InvalidateDelete {
+			if err := os.Remove(path); err != nil {
+				return nil, fmt.Errorf("failed to delete stale artifact %s: %w", key, err)
+			}
+			utils.LogVerbose("Deleted stale artifact %s", key)
+		} else {
+			// Artifacts outside the run folder keep only their file name
+			target := filepath.Join(staleDir, key)
+			if filepath.IsAbs(key) {
+				target = filepath.Join(staleDir, filepath.Base(key))
+			}
+			if err := utils.EnsureDir(filepath.Dir(target)); err != nil {
+				return nil, fmt.Errorf("failed to create stale artifact directory: %w", err)
+			}
+			if err := os.Rename(path, target); err != nil {
+				return nil, fmt.Errorf("failed to move stale artifact %s: %w", key, err)
+			}
+			utils.LogVerbose("Moved stale artifact %s to %s", key, target)
+		}
+		delete(manifest.Artifacts, key)
+		invalidated = append(invalidated, key)
+	}
+
+	return invalidated, nil
+}
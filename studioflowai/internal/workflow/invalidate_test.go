@@ -0,0 +1,106 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeArtifact writes a file in dir and records it in the manifest as an output of step
+func writeArtifact(t *testing.T, manifest *RunManifest, dir, name, step string) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(step), 0644))
+	manifest.RecordOutputs(Step{Name: step, Module: "record"}, map[string]string{name: path}, dir)
+	return path
+}
+
+func TestInvalidateArtifacts(t *testing.T) {
+	retried := []Step{{Name: "second"}}
+
+	t.Run("move", func(t *testing.T) {
+		dir := t.TempDir()
+		manifest := NewRunManifest("test")
+		kept := writeArtifact(t, manifest, dir, "first.txt", "first")
+		stale := writeArtifact(t, manifest, dir, "clips/second.mp4", "second")
+
+		invalidated, err := invalidateArtifacts(manifest, dir, retried, config.InvalidateMove)
+		require.NoError(t, err)
+		assert.Equal(t, []string{filepath.Join("clips", "second.mp4")}, invalidated)
+		assert.FileExists(t, kept)
+		assert.NoFileExists(t, stale)
+
+		moved, err := filepath.Glob(filepath.Join(dir, staleDirName, "*", "clips", "second.mp4"))
+		require.NoError(t, err)
+		assert.Len(t, moved, 1)
+		assert.Len(t, manifest.Artifacts, 1)
+		assert.Empty(t, manifest.Verify(dir))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		dir := t.TempDir()
+		manifest := NewRunManifest("test")
+		stale := writeArtifact(t, manifest, dir, "second.txt", "second")
+
+		invalidated, err := invalidateArtifacts(manifest, dir, retried, config.InvalidateDelete)
+		require.NoError(t, err)
+		assert.Len(t, invalidated, 1)
+		assert.NoFileExists(t, stale)
+		assert.NoDirExists(t, filepath.Join(dir, staleDirName))
+		assert.Empty(t, manifest.Artifacts)
+	})
+
+	t.Run("keep", func(t *testing.T) {
+		dir := t.TempDir()
+		manifest := NewRunManifest("test")
+		stale := writeArtifact(t, manifest, dir, "second.txt", "second")
+
+		invalidated, err := invalidateArtifacts(manifest, dir, retried, config.InvalidateKeep)
+		require.NoError(t, err)
+		assert.Empty(t, invalidated)
+		assert.FileExists(t, stale)
+		assert.Len(t, manifest.Artifacts, 1)
+	})
+
+	t.Run("missing artifacts are dropped from the manifest", func(t *testing.T) {
+		dir := t.TempDir()
+		manifest := NewRunManifest("test")
+		stale := writeArtifact(t, manifest, dir, "second.txt", "second")
+		require.NoError(t, os.Remove(stale))
+
+		invalidated, err := invalidateArtifacts(manifest, dir, retried, config.InvalidateMove)
+		require.NoError(t, err)
+		assert.Empty(t, invalidated)
+		assert.Empty(t, manifest.Artifacts)
+	})
+}
+
+func TestExecuteRetry_InvalidatesStaleArtifacts(t *testing.T) {
+	outputDir := t.TempDir()
+	w, recorder := newRecordingWorkflow(t, outputDir)
+
+	// First attempt: both steps run and record their outputs
+	require.NoError(t, w.Execute())
+	require.FileExists(t, filepath.Join(outputDir, "second.txt"))
+
+	// An extra artifact the second step wrote before failing last time
+	manifestPath := filepath.Join(outputDir, manifestFileName(w.Name))
+	manifest, err := LoadRunManifest(manifestPath, w.Name)
+	require.NoError(t, err)
+	partial := writeArtifact(t, manifest, outputDir, "second_partial.txt", "second")
+	require.NoError(t, manifest.Save(manifestPath))
+
+	retry, _ := newRecordingWorkflow(t, outputDir)
+	retry.registry = w.registry
+	retry.inputConfig = &config.InputConfig{Invalidate: config.InvalidateDelete}
+	require.NoError(t, retry.ExecuteRetry(outputDir, "second"))
+
+	assert.Len(t, recorder.calls, 3)
+	assert.NoFileExists(t, partial, "stale outputs of the retried step are removed")
+	assert.FileExists(t, filepath.Join(outputDir, "first.txt"), "outputs of earlier steps are kept")
+	assert.FileExists(t, filepath.Join(outputDir, "second.txt"), "the retried step writes its outputs again")
+}
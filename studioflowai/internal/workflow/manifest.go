@@ -0,0 +1,162 @@
+// Package workflow provides functionality for managing video processing workflows
+package workflow
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	modules "github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// manifestFileName returns the manifest file name for a workflow
+func manifestFileName(workflowName string) string {
+	return strings.ReplaceAll(workflowName, " ", "_") + ".manifest.yaml"
+}
+
+// NewRunManifest creates an empty manifest for a workflow run
+func NewRunManifest(workflowName string) *RunManifest {
+	return &RunManifest{
+		Workflow:  workflowName,
+		Artifacts: make(map[string]ArtifactRecord),
+	}
+}
+
+// LoadRunManifest loads a manifest from disk, returning an empty manifest if none exists
+func LoadRunManifest(path, workflowName string) (*RunManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NewRunManifest(workflowName), nil
+		}
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	manifest := NewRunManifest(workflowName)
+	if err := yaml.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if manifest.Artifacts == nil {
+		manifest.Artifacts = make(map[string]ArtifactRecord)
+	}
+
+	return manifest, nil
+}
+
+// Save writes the manifest atomically
+func (m *RunManifest) Save(path string) error {
+	m.UpdatedAt = time.Now()
+
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	if err := utils.EnsureDir(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	if err := utils.AtomicWriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	return nil
+}
+
+// RecordOutputs checksums the regular files produced by a step and stores them in the manifest.
+// Artifacts inside baseDir are keyed by their relative path so the run folder can be moved.
+func (m *RunManifest) RecordOutputs(step Step, outputs map[string]string, baseDir string) {
+	for name, outputPath := range outputs {
+		info, err := os.Stat(outputPath)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+
+		checksum, err := utils.FileChecksum(outputPath)
+		if err != nil {
+			utils.LogWarning("Failed to checksum %s: %v", outputPath, err)
+			continue
+		}
+
+		m.Artifacts[manifestKey(outputPath, baseDir)] = ArtifactRecord{
+			Step:       step.Name,
+			Module:     step.Module,
+			Output:     name,
+			SHA256:     checksum,
+			Size:       info.Size(),
+			RecordedAt: time.Now(),
+		}
+	}
+}
+
+// RecordStats stores the statistics of a step and recomputes the run totals. A retried step
+// replaces its earlier record.
+func (m *RunManifest) RecordStats(step Step, stats modules.Stats) {
+	if m.Steps == nil {
+		m.Steps = make(map[string]StepRecord)
+	}
+	m.Steps[step.Name] = StepRecord{Module: step.Module, Stats: stats, RecordedAt: time.Now()}
+
+	m.Totals = modules.Stats{}
+	for _, record := range m.Steps {
+		m.Totals.Add(record.Stats)
+	}
+}
+
+// Verify compares the recorded checksums with the files on disk and returns
+// a description of each artifact that is missing or has changed
+func (m *RunManifest) Verify(baseDir string) []string {
+	keys := make([]string, 0, len(m.Artifacts))
+	for key := range m.Artifacts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var problems []string
+	for _, key := range keys {
+		record := m.Artifacts[key]
+		path := key
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(baseDir, key)
+		}
+
+		checksum, err := utils.FileChecksum(path)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s (from step %s) is missing or unreadable", key, record.Step))
+			continue
+		}
+		if checksum != record.SHA256 {
+			problems = append(problems, fmt.Sprintf("%s (from step %s) does not match its recorded checksum", key, record.Step))
+		}
+	}
+
+	return problems
+}
+
+// manifestKey returns the path of an artifact relative to baseDir when possible
+func manifestKey(path, baseDir string) string {
+	if baseDir == "" {
+		return path
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	absBase, err := filepath.Abs(baseDir)
+	if err != nil {
+		return path
+	}
+
+	rel, err := filepath.Rel(absBase, absPath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return absPath
+	}
+
+	return rel
+}
@@ -0,0 +1,66 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunManifest_Verify(t *testing.T) {
+	dir := t.TempDir()
+	manifest := NewRunManifest("test")
+	writeArtifact(t, manifest, dir, "audio.wav", "extract")
+	transcript := writeArtifact(t, manifest, dir, "transcripts/episode.srt", "transcribe")
+	summary := writeArtifact(t, manifest, dir, "summary.txt", "summarize")
+
+	assert.Contains(t, manifest.Artifacts, filepath.Join("transcripts", "episode.srt"), "artifacts are keyed relative to the run folder")
+	assert.Empty(t, manifest.Verify(dir), "untouched artifacts verify")
+
+	require.NoError(t, os.WriteFile(transcript, []byte("edited by hand"), 0644))
+	require.NoError(t, os.Remove(summary))
+
+	assert.Equal(t, []string{
+		"summary.txt (from step summarize) is missing or unreadable",
+		filepath.Join("transcripts", "episode.srt") + " (from step transcribe) does not match its recorded checksum",
+	}, manifest.Verify(dir))
+}
+
+func TestRunManifest_VerifyMovedRunFolder(t *testing.T) {
+	dir := t.TempDir()
+	manifest := NewRunManifest("test")
+	writeArtifact(t, manifest, dir, "audio.wav", "extract")
+
+	// Relative keys follow the run folder to its new place
+	moved := filepath.Join(t.TempDir(), "moved")
+	require.NoError(t, os.Rename(dir, moved))
+	assert.Empty(t, manifest.Verify(moved))
+
+	// Artifacts outside the run folder keep their absolute path
+	outside := filepath.Join(t.TempDir(), "cover.png")
+	require.NoError(t, os.WriteFile(outside, []byte("png"), 0644))
+	manifest.RecordOutputs(Step{Name: "cover", Module: "record"}, map[string]string{"image": outside}, moved)
+	assert.Contains(t, manifest.Artifacts, outside)
+	assert.Empty(t, manifest.Verify(moved))
+}
+
+func TestRunManifest_SaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	manifest := NewRunManifest("My Show")
+	writeArtifact(t, manifest, dir, "audio.wav", "extract")
+
+	path := filepath.Join(dir, manifestFileName("My Show"))
+	assert.Equal(t, "My_Show.manifest.yaml", filepath.Base(path))
+	require.NoError(t, manifest.Save(path))
+
+	loaded, err := LoadRunManifest(path, "My Show")
+	require.NoError(t, err)
+	assert.Equal(t, manifest.Artifacts["audio.wav"].SHA256, loaded.Artifacts["audio.wav"].SHA256)
+	assert.Empty(t, loaded.Verify(dir))
+
+	missing, err := LoadRunManifest(filepath.Join(dir, "none.yaml"), "My Show")
+	require.NoError(t, err, "a run without a manifest starts an empty one")
+	assert.Empty(t, missing.Artifacts)
+}
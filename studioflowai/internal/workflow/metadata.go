@@ -0,0 +1,80 @@
+package workflow
+
+import (
+	"fmt"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+// metadataParam is the parameter through which modules receive episode metadata
+const metadataParam = "metadata"
+
+// applyMetadata merges the workflow's episode metadata into every step whose module accepts a
+// metadata parameter. Keys set on a step win over the workflow's.
+func applyMetadata(w *Workflow) error {
+	metadata, err := workflowMetadata(w)
+	if err != nil {
+		return err
+	}
+
+	for i, step := range w.Steps {
+		module, err := w.registry.Get(step.Module)
+		if err != nil || !acceptsParam(module, metadataParam) {
+			continue
+		}
+
+		stepMetadata, _ := step.Parameters[metadataParam].(map[string]interface{})
+		merged := utils.MergeEpisodeMetadata(metadata, stepMetadata)
+		if len(merged) == 0 {
+			continue
+		}
+		if w.Steps[i].Parameters == nil {
+			w.Steps[i].Parameters = make(map[string]interface{})
+		}
+		w.Steps[i].Parameters[metadataParam] = merged
+		utils.LogVerbose("Passing %d episode metadata fields to step %s", len(merged), step.Name)
+	}
+	return nil
+}
+
+// workflowMetadata returns the workflow's inline metadata merged over its metadata file
+func workflowMetadata(w *Workflow) (map[string]interface{}, error) {
+	metadataFile, err := utils.ResolveProjectPath(w.MetadataFile)
+	if err != nil {
+		return nil, fmt.Errorf("metadataFile: %w", err)
+	}
+	return utils.ResolveEpisodeMetadata(w.Metadata, metadataFile)
+}
+
+// defaultStepParam sets a parameter on every step whose module accepts it and that does not
+// set it itself
+func defaultStepParam(w *Workflow, name string, value interface{}) {
+	for i, step := range w.Steps {
+		if _, ok := step.Parameters[name]; ok {
+			continue
+		}
+		module, err := w.registry.Get(step.Module)
+		if err != nil || !acceptsParam(module, name) {
+			continue
+		}
+		if w.Steps[i].Parameters == nil {
+			w.Steps[i].Parameters = make(map[string]interface{})
+		}
+		w.Steps[i].Parameters[name] = value
+	}
+}
+
+// acceptsParam reports whether a module declares the named parameter
+func acceptsParam(m mod.Module, name string) bool {
+	provider, ok := m.(mod.ParamsProvider)
+	if !ok {
+		return false
+	}
+	for _, field := range mod.DescribeParams(provider.ParamsTemplate()) {
+		if field.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package workflow
+
+import (
+	"fmt"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/config"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+// applyProject resolves ${project} placeholders and fills in the active project's
+// platform accounts for steps that do not configure them explicitly
+func applyProject(w *Workflow, project *config.Project) error {
+	for i, step := range w.Steps {
+		for k, v := range step.Parameters {
+			strVal, ok := v.(string)
+			if !ok {
+				continue
+			}
+			resolved, err := utils.ResolveProjectPath(strVal)
+			if err != nil {
+				return fmt.Errorf("step %s: parameter %s: %w", step.Name, k, err)
+			}
+			w.Steps[i].Parameters[k] = resolved
+		}
+
+		if project == nil {
+			continue
+		}
+
+		switch step.Module {
+		case "uploadyoutubeshorts":
+			if _, ok := step.Parameters["credentials"]; !ok && project.YouTubeCredentialsPath() != "" {
+				setStepParam(&w.Steps[i], "credentials", project.YouTubeCredentialsPath())
+				utils.LogVerbose("Using YouTube credentials of project %s for step %s", project.Name, step.Name)
+			}
+			if _, ok := step.Parameters["locale"]; !ok && project.Accounts.YouTube.Locale != "" {
+				setStepParam(&w.Steps[i], "locale", project.Accounts.YouTube.Locale)
+			}
+			if _, ok := step.Parameters["account"]; !ok && project.Accounts.YouTube.Channel != "" {
+				setStepParam(&w.Steps[i], "account", project.Accounts.YouTube.Channel)
+			}
+		case "link_shorts":
+			if _, ok := step.Parameters["credentials"]; !ok && project.YouTubeCredentialsPath() != "" {
+				setStepParam(&w.Steps[i], "credentials", project.YouTubeCredentialsPath())
+				utils.LogVerbose("Using YouTube credentials of project %s for step %s", project.Name, step.Name)
+			}
+			if _, ok := step.Parameters["account"]; !ok && project.Accounts.YouTube.Channel != "" {
+				setStepParam(&w.Steps[i], "account", project.Accounts.YouTube.Channel)
+			}
+		case "uploadtiktokshorts":
+			if _, ok := step.Parameters["locale"]; !ok && project.Accounts.TikTok.Locale != "" {
+				setStepParam(&w.Steps[i], "locale", project.Accounts.TikTok.Locale)
+			}
+			if _, ok := step.Parameters["account"]; !ok && project.Accounts.TikTok.Username != "" {
+				setStepParam(&w.Steps[i], "account", project.Accounts.TikTok.Username)
+			}
+		}
+	}
+	return nil
+}
+
+// setStepParam sets a parameter of a step, creating its parameters if it has none
+func setStepParam(step *Step, name string, value interface{}) {
+	if step.Parameters == nil {
+		step.Parameters = make(map[string]interface{})
+	}
+	step.Parameters[name] = value
+}
@@ -0,0 +1,136 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+// DefaultRunFolderPattern names a run folder after the workflow file and the run ID
+const DefaultRunFolderPattern = "{workflow}-{run.id}"
+
+// runFolderPlaceholder matches the {name} placeholders of a run folder pattern
+var runFolderPlaceholder = regexp.MustCompile(`\{([a-z.]+)\}`)
+
+// maxRunFolderSuffix bounds the search for a free folder name
+const maxRunFolderSuffix = 1000
+
+// RunFolderVars are the values the placeholders of a run folder pattern stand for
+type RunFolderVars struct {
+	WorkflowFile string    // Path of the workflow file; {workflow} is its base name
+	Name         string    // Name of the workflow; {slug} is its lower-case, dash-separated form
+	Input        string    // Path of the run's input; {input} is its base name
+	Project      string    // Name of the active project, if any
+	RunID        string    // {run.id}: the time, or the inputs digest in deterministic mode
+	ShortID      string    // {run.shortid}: a short random or deterministic ID
+	Time         time.Time // Start of the run; {date}, {time} and {timestamp}
+}
+
+// RunFolderName expands the placeholders of a run folder pattern. The pattern may contain / to
+// group runs in subfolders, such as {date}/{slug}-{run.shortid}.
+func RunFolderName(pattern string, vars RunFolderVars) (string, error) {
+	if pattern == "" {
+		pattern = DefaultRunFolderPattern
+	}
+	workflowName := strings.TrimSuffix(filepath.Base(vars.WorkflowFile), filepath.Ext(vars.WorkflowFile))
+	inputName := strings.TrimSuffix(filepath.Base(vars.Input), filepath.Ext(vars.Input))
+	if vars.Input == "" {
+		inputName = ""
+	}
+	slug := folderSlug(vars.Name)
+	if slug == "" {
+		slug = folderSlug(workflowName)
+	}
+	values := map[string]string{
+		"workflow":    workflowName,
+		"slug":        slug,
+		"input":       inputName,
+		"project":     vars.Project,
+		"run.id":      vars.RunID,
+		"run.shortid": vars.ShortID,
+		"date":        vars.Time.Format("2006-01-02"),
+		"time":        vars.Time.Format("150405"),
+		"timestamp":   vars.Time.Format("20060102-150405"),
+	}
+
+	var expandErr error
+	name := runFolderPlaceholder.ReplaceAllStringFunc(pattern, func(placeholder string) string {
+		key := strings.Trim(placeholder, "{}")
+		value, ok := values[key]
+		if !ok && expandErr == nil {
+			expandErr = fmt.Errorf("unknown placeholder %s in output name %q (supported: {workflow}, {slug}, {input}, {project}, {run.id}, {run.shortid}, {date}, {time}, {timestamp})", placeholder, pattern)
+		}
+		// A value never adds folder levels of its own
+		return strings.NewReplacer("/", "-", `\`, "-").Replace(value)
+	})
+	if expandErr != nil {
+		return "", expandErr
+	}
+
+	name = filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(name) || name == "." || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("output name %q must be a relative folder inside the output root", pattern)
+	}
+	return name, nil
+}
+
+// ResolveRunFolder creates the run folder under root named by the pattern and returns it. When
+// the folder is already taken, -2, -3 and so on are appended, except in deterministic mode, where
+// the same inputs are meant to reuse the same folder. Each folder is claimed by creating it, so
+// runs started at the same time never share one.
+func ResolveRunFolder(root, pattern string, vars RunFolderVars) (string, error) {
+	name, err := RunFolderName(pattern, vars)
+	if err != nil {
+		return "", err
+	}
+	folder := filepath.Join(root, name)
+	if utils.Deterministic() {
+		if err := utils.EnsureDir(folder); err != nil {
+			return "", fmt.Errorf("failed to create output directory: %w", err)
+		}
+		return folder, nil
+	}
+
+	if err := utils.EnsureDir(filepath.Dir(folder)); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+	for i := 1; i <= maxRunFolderSuffix; i++ {
+		candidate := folder
+		if i > 1 {
+			candidate = fmt.Sprintf("%s-%d", folder, i)
+		}
+		err := utils.CreateDir(candidate)
+		if err == nil {
+			return candidate, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return "", fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+	return "", fmt.Errorf("no free output folder found for %s", folder)
+}
+
+// runFolderTime returns the time the {date}, {time} and {timestamp} placeholders stand for. In
+// deterministic mode it is derived from the run seed, which comes from the inputs digest, so the
+// same inputs name the same folder whenever they run.
+func runFolderTime() time.Time {
+	if utils.Deterministic() {
+		return time.Unix(utils.RunSeed(), 0).UTC()
+	}
+	return time.Now()
+}
+
+// folderSlug turns a name into lower-case words joined by dashes, e.g. "Weekly Show!" -> "weekly-show"
+func folderSlug(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	return strings.Join(words, "-")
+}
@@ -0,0 +1,123 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/config"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunFolderName(t *testing.T) {
+	vars := RunFolderVars{
+		WorkflowFile: "examples/full_workflow.yaml",
+		Name:         "Weekly Show: Episode",
+		Input:        "/videos/ep12.mp4",
+		Project:      "acme",
+		RunID:        "20261016-100000",
+		ShortID:      "a1b2c3d4",
+		Time:         time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		pattern string
+		want    string
+		wantErr bool
+	}{
+		{pattern: "", want: "full_workflow-20261016-100000"},
+		{pattern: "{date}/{slug}-{run.shortid}", want: filepath.Join("2026-10-16", "weekly-show-episode-a1b2c3d4")},
+		{pattern: "{project}_{input}_{time}", want: "acme_ep12_100000"},
+		{pattern: "{episode}", wantErr: true},
+		{pattern: "../{workflow}", wantErr: true},
+		{pattern: "/tmp/{workflow}", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			got, err := RunFolderName(tt.pattern, vars)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestResolveRunFolder(t *testing.T) {
+	root := t.TempDir()
+	vars := RunFolderVars{WorkflowFile: "show.yaml", RunID: "run"}
+
+	folder, err := ResolveRunFolder(root, "", vars)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "show-run"), folder)
+	assert.DirExists(t, folder, "the folder is claimed by creating it")
+
+	// A taken name gets a numbered suffix
+	require.NoError(t, os.MkdirAll(folder+"-2", 0755))
+	folder, err = ResolveRunFolder(root, "", vars)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "show-run-3"), folder)
+
+	// Deterministic runs reuse the folder of the same inputs
+	utils.SetDeterministic(true)
+	defer utils.SetDeterministic(false)
+	folder, err = ResolveRunFolder(root, "", vars)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "show-run"), folder)
+}
+
+func TestResolveRunFolderConcurrent(t *testing.T) {
+	root := t.TempDir()
+	vars := RunFolderVars{WorkflowFile: "show.yaml", RunID: "run"}
+
+	const runs = 8
+	folders := make([]string, runs)
+	var wg sync.WaitGroup
+	for i := range runs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			folder, err := ResolveRunFolder(root, "{date}/{workflow}-{run.id}", vars)
+			assert.NoError(t, err)
+			folders[i] = folder
+		}()
+	}
+	wg.Wait()
+
+	seen := map[string]bool{}
+	for _, folder := range folders {
+		assert.False(t, seen[folder], "runs started together share %s", folder)
+		seen[folder] = true
+	}
+}
+
+func TestCreateRunFolderDeterministic(t *testing.T) {
+	utils.SetDeterministic(true)
+	utils.SetRunSeed(123456789)
+	defer func() {
+		utils.SetDeterministic(false)
+		utils.SetRunSeed(0)
+	}()
+
+	w := &Workflow{Name: "Show"}
+	inputConfig := &config.InputConfig{
+		WorkflowPath: "show.yaml",
+		OutputRoot:   t.TempDir(),
+		OutputName:   "{date}/{workflow}-{timestamp}-{run.id}",
+		RunID:        "0123456789ab",
+	}
+
+	first, err := w.createRunFolder(inputConfig)
+	require.NoError(t, err)
+	second, err := w.createRunFolder(inputConfig)
+	require.NoError(t, err)
+	assert.Equal(t, first, second, "the same inputs get the same folder")
+	assert.Equal(t, filepath.Join(inputConfig.OutputRoot, "1973-11-29", "show-19731129-213309-0123456789ab"), first,
+		"the time is derived from the seed, not the clock")
+}
@@ -0,0 +1,566 @@
+// Package workflow provides functionality for managing video processing workflows
+package workflow
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/scheduler"
+	"gopkg.in/yaml.v3"
+)
+
+// workflowFields lists the top-level keys allowed in a workflow file
+var workflowFields = map[string]mod.ParamKind{
+	"name":            mod.ParamKindString,
+	"description":     mod.ParamKindString,
+	"input":           mod.ParamKindString,
+	"output":          mod.ParamKindString,
+	"outputName":      mod.ParamKindString,
+	"steps":           mod.ParamKindArray,
+	"metadata":        mod.ParamKindObject,
+	"metadataFile":    mod.ParamKindString,
+	"series":          mod.ParamKindObject,
+	"filtergraph":     mod.ParamKindString,
+	"theme":           mod.ParamKindString,
+	"whisperProfiles": mod.ParamKindObject,
+	"transcriptFixes": mod.ParamKindArray,
+	"watchdog":        mod.ParamKindObject,
+	"permissions":     mod.ParamKindObject,
+	"startTime":       mod.ParamKindString,
+	"endTime":         mod.ParamKindString,
+	"workers":         mod.ParamKindObject,
+}
+
+// stepFields lists the keys allowed in a workflow step
+var stepFields = map[string]mod.ParamKind{
+	"name":       mod.ParamKindString,
+	"module":     mod.ParamKindString,
+	"parameters": mod.ParamKindObject,
+	"fromStep":   mod.ParamKindString,
+	"worker":     mod.ParamKindString,
+	"class":      mod.ParamKindString,
+	"priority":   mod.ParamKindInteger,
+}
+
+// Codes of the problems found in workflow files, for editors that act on them
+const (
+	IssueInvalid       = "invalid"        // The file or a step is not a mapping
+	IssueUnknownField  = "unknown-field"  // A workflow or step field that does not exist
+	IssueMissingField  = "missing-field"  // A required field is not set
+	IssueWrongType     = "wrong-type"     // A value of the wrong type
+	IssueUnknownModule = "unknown-module" // A step uses a module that does not exist
+	IssueUnknownParam  = "unknown-param"  // A step sets a parameter its module does not have
+	IssueUnknownStep   = "unknown-step"   // fromStep does not name an earlier step
+	IssueDuplicateStep = "duplicate-step" // Two steps have the same name
+)
+
+// SchemaIssue describes a single problem found while validating a workflow file
+type SchemaIssue struct {
+	Line    int
+	Column  int
+	Code    string
+	Path    string
+	Message string
+}
+
+// SchemaError is returned when a workflow file does not match the workflow schema
+type SchemaError struct {
+	File   string
+	Issues []SchemaIssue
+}
+
+// Error formats all issues, one per line, prefixed with their file position
+func (e *SchemaError) Error() string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("workflow has %d schema error(s):", len(e.Issues)))
+	for _, issue := range e.Issues {
+		b.WriteString("\n  ")
+		if e.File != "" {
+			b.WriteString(e.File + ":")
+		}
+		b.WriteString(fmt.Sprintf("%d:%d: ", issue.Line, issue.Column))
+		if issue.Path != "" {
+			b.WriteString(issue.Path + ": ")
+		}
+		b.WriteString(issue.Message)
+	}
+	return b.String()
+}
+
+// schemaValidator collects issues while walking a workflow YAML document
+type schemaValidator struct {
+	registry *mod.ModuleRegistry
+	issues   []SchemaIssue
+}
+
+// ValidateWorkflowSchema checks a workflow YAML document against the workflow schema,
+// reporting unknown fields, wrong types and unknown modules or parameters with line numbers
+func ValidateWorkflowSchema(file string, data []byte, registry *mod.ModuleRegistry) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse workflow file: %w", err)
+	}
+
+	issues := schemaIssues(&doc, registry)
+	if len(issues) == 0 {
+		return nil
+	}
+	return &SchemaError{File: file, Issues: issues}
+}
+
+// schemaIssues returns the schema issues of a parsed workflow document in file order
+func schemaIssues(doc *yaml.Node, registry *mod.ModuleRegistry) []SchemaIssue {
+	v := &schemaValidator{registry: registry}
+	if len(doc.Content) == 0 {
+		v.add(doc, IssueInvalid, "", "workflow file is empty")
+	} else {
+		v.validateWorkflow(doc.Content[0])
+	}
+
+	sort.SliceStable(v.issues, func(i, j int) bool {
+		if v.issues[i].Line != v.issues[j].Line {
+			return v.issues[i].Line < v.issues[j].Line
+		}
+		return v.issues[i].Column < v.issues[j].Column
+	})
+	return v.issues
+}
+
+// add records an issue at the position of node
+func (v *schemaValidator) add(node *yaml.Node, code, path, format string, args ...interface{}) {
+	v.issues = append(v.issues, SchemaIssue{
+		Line:    node.Line,
+		Column:  node.Column,
+		Code:    code,
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// validateWorkflow validates the top-level workflow mapping
+func (v *schemaValidator) validateWorkflow(node *yaml.Node) {
+	if node.Kind != yaml.MappingNode {
+		v.add(node, IssueInvalid, "", "workflow must be a mapping, got %s", describeNode(node))
+		return
+	}
+
+	seen := map[string]bool{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		seen[key.Value] = true
+
+		kind, ok := workflowFields[key.Value]
+		if !ok {
+			v.add(key, IssueUnknownField, key.Value, "unknown field %q%s", key.Value, suggestion(key.Value, keysOf(workflowFields)))
+			continue
+		}
+		if !v.checkKind(value, key.Value, kind) {
+			continue
+		}
+
+		if key.Value == "steps" {
+			v.validateSteps(value)
+		}
+	}
+
+	if !seen["name"] {
+		v.add(node, IssueMissingField, "", "missing required field \"name\"")
+	}
+	if !seen["steps"] {
+		v.add(node, IssueMissingField, "", "missing required field \"steps\"")
+	}
+}
+
+// validateSteps validates each workflow step
+func (v *schemaValidator) validateSteps(node *yaml.Node) {
+	names := map[string]int{}
+	for i, step := range node.Content {
+		path := fmt.Sprintf("steps[%d]", i)
+		if step.Kind != yaml.MappingNode {
+			v.add(step, IssueInvalid, path, "step must be a mapping, got %s", describeNode(step))
+			continue
+		}
+
+		var moduleNode, paramsNode, nameNode, fromStepNode *yaml.Node
+		for j := 0; j+1 < len(step.Content); j += 2 {
+			key, value := step.Content[j], step.Content[j+1]
+			kind, ok := stepFields[key.Value]
+			if !ok {
+				v.add(key, IssueUnknownField, path+"."+key.Value, "unknown step field %q%s", key.Value, suggestion(key.Value, keysOf(stepFields)))
+				continue
+			}
+			if !v.checkKind(value, path+"."+key.Value, kind) {
+				continue
+			}
+
+			switch key.Value {
+			case "name":
+				nameNode = value
+			case "module":
+				moduleNode = value
+			case "parameters":
+				paramsNode = value
+			case "fromStep":
+				fromStepNode = value
+			}
+		}
+
+		// Inputs can only come from steps that run before
+		if fromStepNode != nil {
+			if _, ok := names[fromStepNode.Value]; !ok {
+				earlier := make([]string, 0, len(names))
+				for name := range names {
+					earlier = append(earlier, name)
+				}
+				sort.Strings(earlier)
+				v.add(fromStepNode, IssueUnknownStep, path+".fromStep", "fromStep %q does not name an earlier step%s",
+					fromStepNode.Value, suggestion(fromStepNode.Value, earlier))
+			}
+		}
+
+		if nameNode == nil {
+			v.add(step, IssueMissingField, path, "missing required field \"name\"")
+		} else if line, dup := names[nameNode.Value]; dup {
+			v.add(nameNode, IssueDuplicateStep, path+".name", "duplicate step name %q (first defined on line %d)", nameNode.Value, line)
+		} else {
+			names[nameNode.Value] = nameNode.Line
+		}
+
+		if moduleNode == nil {
+			v.add(step, IssueMissingField, path, "missing required field \"module\"")
+			continue
+		}
+
+		// Steps of legacy modules are checked against the module their task runs
+		var task string
+		if paramsNode != nil {
+			if taskNode := mappingValue(paramsNode, legacyTaskParam); taskNode != nil {
+				task = taskNode.Value
+			}
+		}
+		moduleName, legacy, err := resolveLegacyModule(moduleNode.Value, task)
+		if err != nil {
+			v.add(moduleNode, IssueInvalid, path+".module", "%v", err)
+			continue
+		}
+		if !legacy {
+			moduleName = moduleNode.Value
+		}
+
+		module, err := v.registry.Get(moduleName)
+		if err != nil {
+			v.add(moduleNode, IssueUnknownModule, path+".module", "unknown module %q%s", moduleNode.Value, suggestion(moduleNode.Value, moduleNames(v.registry)))
+			continue
+		}
+
+		if paramsNode != nil {
+			v.validateParameters(paramsNode, path+".parameters", module, legacy)
+		}
+	}
+}
+
+// validateParameters checks step parameters against the module's parameter struct; steps of a
+// legacy module also take its task parameter
+func (v *schemaValidator) validateParameters(node *yaml.Node, path string, module mod.Module, legacy bool) {
+	provider, ok := module.(mod.ParamsProvider)
+	if !ok {
+		return
+	}
+
+	fields := map[string]mod.ParamKind{}
+	for _, f := range mod.DescribeParams(provider.ParamsTemplate()) {
+		fields[f.Name] = f.Kind
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if legacy && key.Value == legacyTaskParam {
+			continue
+		}
+		kind, ok := fields[key.Value]
+		if !ok {
+			v.add(key, IssueUnknownParam, path+"."+key.Value, "unknown parameter %q for module %s%s",
+				key.Value, module.Name(), suggestion(key.Value, keysOf(fields)))
+			continue
+		}
+		v.checkParamKind(value, path+"."+key.Value, kind)
+	}
+}
+
+// checkParamKind checks a module parameter, accepting the scalars ParseParams converts to its
+// kind, such as "4000" for an integer or yes for a boolean
+func (v *schemaValidator) checkParamKind(node *yaml.Node, path string, kind mod.ParamKind) bool {
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag != "!!null" && kind != mod.ParamKindString &&
+		kind != mod.ParamKindObject && mod.Coercible(node.Value, kind) {
+		return true
+	}
+	return v.checkKind(node, path, kind)
+}
+
+// checkKind reports an issue if node does not hold a value of the expected kind
+func (v *schemaValidator) checkKind(node *yaml.Node, path string, kind mod.ParamKind) bool {
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	// Empty values fall back to module defaults
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return true
+	}
+
+	ok := true
+	switch kind {
+	case mod.ParamKindString:
+		ok = node.Kind == yaml.ScalarNode && node.Tag == "!!str"
+	case mod.ParamKindInteger:
+		ok = node.Kind == yaml.ScalarNode && node.Tag == "!!int"
+	case mod.ParamKindNumber:
+		ok = node.Kind == yaml.ScalarNode && (node.Tag == "!!int" || node.Tag == "!!float")
+	case mod.ParamKindBoolean:
+		ok = node.Kind == yaml.ScalarNode && node.Tag == "!!bool"
+	case mod.ParamKindArray:
+		ok = node.Kind == yaml.SequenceNode
+	case mod.ParamKindObject:
+		ok = node.Kind == yaml.MappingNode
+	}
+
+	if !ok {
+		hint := ""
+		if kind == mod.ParamKindString && node.Kind == yaml.ScalarNode {
+			hint = fmt.Sprintf(" (quote the value: \"%s\")", node.Value)
+		}
+		v.add(node, IssueWrongType, path, "expected %s, got %s%s", kind, describeNode(node), hint)
+	}
+	return ok
+}
+
+// describeNode returns a human readable type for a YAML node
+func describeNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "mapping"
+	case yaml.SequenceNode:
+		return "list"
+	case yaml.ScalarNode:
+		switch node.Tag {
+		case "!!int":
+			return fmt.Sprintf("integer %s", node.Value)
+		case "!!float":
+			return fmt.Sprintf("number %s", node.Value)
+		case "!!bool":
+			return fmt.Sprintf("boolean %s", node.Value)
+		case "!!null":
+			return "null"
+		default:
+			return fmt.Sprintf("string %q", node.Value)
+		}
+	default:
+		return "unknown value"
+	}
+}
+
+// keysOf returns the sorted keys of a field map
+func keysOf(fields map[string]mod.ParamKind) []string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// moduleNames returns the sorted names of all registered modules
+func moduleNames(registry *mod.ModuleRegistry) []string {
+	var names []string
+	for _, m := range registry.ListModules() {
+		names = append(names, m.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// suggestion returns a "did you mean" hint for the closest candidate, if any is close enough
+func suggestion(value string, candidates []string) string {
+	best := ""
+	bestDistance := -1
+	lower := strings.ToLower(value)
+	for _, c := range candidates {
+		d := levenshtein(lower, strings.ToLower(c))
+		if bestDistance == -1 || d < bestDistance {
+			best, bestDistance = c, d
+		}
+	}
+
+	// Allow roughly one typo per four characters
+	maxDistance := len(value)/4 + 1
+	if best == "" || bestDistance > maxDistance {
+		if len(candidates) > 0 && len(candidates) <= 20 {
+			return fmt.Sprintf(" (valid: %s)", strings.Join(candidates, ", "))
+		}
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %q?)", best)
+}
+
+// levenshtein returns the edit distance between two strings
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// WorkflowJSONSchema returns a JSON Schema (draft-07) describing workflow files for the
+// registered modules, suitable for editor integration such as yaml-language-server
+func WorkflowJSONSchema(registry *mod.ModuleRegistry) map[string]interface{} {
+	var stepVariants []interface{}
+	for _, name := range moduleNames(registry) {
+		module, err := registry.Get(name)
+		if err != nil {
+			continue
+		}
+
+		parameters := ParamsJSONSchema(module)
+		stepVariants = append(stepVariants, map[string]interface{}{
+			"if": map[string]interface{}{
+				"properties": map[string]interface{}{"module": map[string]interface{}{"const": name}},
+			},
+			"then": map[string]interface{}{
+				"properties": map[string]interface{}{"parameters": parameters},
+			},
+		})
+	}
+
+	step := map[string]interface{}{
+		"type":     "object",
+		"required": []string{"name", "module"},
+		"properties": map[string]interface{}{
+			"name":       map[string]interface{}{"type": "string"},
+			"module":     map[string]interface{}{"type": "string", "enum": moduleNames(registry)},
+			"parameters": map[string]interface{}{"type": "object"},
+			"fromStep":   map[string]interface{}{"type": "string"},
+			"worker":     map[string]interface{}{"type": "string"},
+			"class":      map[string]interface{}{"type": "string", "enum": scheduler.Classes},
+			"priority":   map[string]interface{}{"type": "integer"},
+		},
+		"additionalProperties": false,
+		"allOf":                stepVariants,
+	}
+
+	return map[string]interface{}{
+		"$schema":              "http://json-schema.org/draft-07/schema#",
+		"title":                "StudioFlowAI workflow",
+		"type":                 "object",
+		"required":             []string{"name", "steps"},
+		"additionalProperties": false,
+		"properties": map[string]interface{}{
+			"name":         map[string]interface{}{"type": "string"},
+			"description":  map[string]interface{}{"type": "string"},
+			"input":        map[string]interface{}{"type": "string"},
+			"output":       map[string]interface{}{"type": "string"},
+			"outputName":   map[string]interface{}{"type": "string"},
+			"steps":        map[string]interface{}{"type": "array", "items": step},
+			"metadata":     map[string]interface{}{"type": "object"},
+			"metadataFile": map[string]interface{}{"type": "string"},
+			"series": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"name":         map[string]interface{}{"type": "string"},
+					"titlePattern": map[string]interface{}{"type": "string"},
+					"firstEpisode": map[string]interface{}{"type": "integer"},
+				},
+				"additionalProperties": false,
+			},
+			"filtergraph": map[string]interface{}{"type": "string"},
+			"theme":       map[string]interface{}{"type": "string"},
+			"startTime":   map[string]interface{}{"type": "string"},
+			"endTime":     map[string]interface{}{"type": "string"},
+			"workers": map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": map[string]interface{}{"type": "string"},
+			},
+			"whisperProfiles": map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": map[string]interface{}{"type": "string"},
+			},
+			"transcriptFixes": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"pattern":        map[string]interface{}{"type": "string"},
+						"replace":        map[string]interface{}{"type": "string"},
+						"dictionary":     map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"type": "string"}},
+						"dictionaryFile": map[string]interface{}{"type": "string"},
+						"casing":         map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+						"numbers":        map[string]interface{}{"type": "string", "enum": []string{"digits"}},
+					},
+					"additionalProperties": false,
+				},
+			},
+			"watchdog": map[string]interface{}{
+				"type": "object",
+				"additionalProperties": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"idle":    map[string]interface{}{"type": "string"},
+						"retries": map[string]interface{}{"type": "integer", "minimum": 0},
+					},
+					"additionalProperties": false,
+				},
+			},
+			"permissions": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"umask":    map[string]interface{}{"type": "string", "pattern": "^[0-7]{1,4}$"},
+					"fileMode": map[string]interface{}{"type": "string", "pattern": "^[0-7]{1,4}$"},
+					"dirMode":  map[string]interface{}{"type": "string", "pattern": "^[0-7]{1,4}$"},
+					"group":    map[string]interface{}{"type": "string"},
+				},
+				"additionalProperties": false,
+			},
+		},
+	}
+}
+
+// ParamsJSONSchema returns a JSON Schema (draft-07) of the parameters of a module's steps
+func ParamsJSONSchema(module mod.Module) map[string]interface{} {
+	parameters := map[string]interface{}{"type": "object"}
+	if provider, ok := module.(mod.ParamsProvider); ok {
+		properties := map[string]interface{}{}
+		for _, f := range mod.DescribeParams(provider.ParamsTemplate()) {
+			properties[f.Name] = jsonSchemaType(f.Kind)
+		}
+		parameters["properties"] = properties
+		parameters["additionalProperties"] = false
+	}
+	return parameters
+}
+
+// jsonSchemaType returns the JSON Schema fragment for a parameter kind
+func jsonSchemaType(kind mod.ParamKind) map[string]interface{} {
+	if kind == mod.ParamKindAny {
+		return map[string]interface{}{}
+	}
+	return map[string]interface{}{"type": []string{string(kind), "null"}}
+}
@@ -0,0 +1,203 @@
+package workflow
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// schemaModule is a module with typed parameters for the schema checks
+type schemaModule struct{}
+
+type schemaParams struct {
+	Input     string   `json:"input"`
+	Threshold float64  `json:"threshold"`
+	Retries   int      `json:"retries"`
+	Enabled   bool     `json:"enabled"`
+	Tags      []string `json:"tags"`
+}
+
+func (m *schemaModule) Name() string { return "probe" }
+
+func (m *schemaModule) Validate(params map[string]interface{}) error { return nil }
+
+func (m *schemaModule) GetIO() mod.ModuleIO { return mod.ModuleIO{} }
+
+func (m *schemaModule) Execute(ctx context.Context, params map[string]interface{}) (mod.ModuleResult, error) {
+	return mod.ModuleResult{}, nil
+}
+
+func (m *schemaModule) ParamsTemplate() interface{} { return schemaParams{} }
+
+func TestValidateWorkflowSchema(t *testing.T) {
+	registry := mod.NewModuleRegistry()
+	require.NoError(t, registry.Register(&schemaModule{}))
+
+	tests := []struct {
+		name    string
+		yaml    string
+		want    []SchemaIssue // Line, Column, Code and Path of each issue, in file order
+		message []string      // Fragments of the issue messages
+	}{
+		{
+			name: "valid workflow",
+			yaml: `name: show
+steps:
+  - name: probe
+    module: probe
+    parameters:
+      input: ./in.txt
+      threshold: 3
+      retries: "2"
+      enabled: yes
+      tags: [a, b]
+`,
+		},
+		{
+			name: "unknown workflow field",
+			yaml: `name: show
+outpt: ./out
+steps: []
+`,
+			want:    []SchemaIssue{{Line: 2, Column: 1, Code: IssueUnknownField, Path: "outpt"}},
+			message: []string{`unknown field "outpt" (did you mean "output"?)`},
+		},
+		{
+			name: "unknown step field",
+			yaml: `name: show
+steps:
+  - name: probe
+    modul: probe
+`,
+			want: []SchemaIssue{
+				{Line: 3, Column: 5, Code: IssueMissingField, Path: "steps[0]"},
+				{Line: 4, Column: 5, Code: IssueUnknownField, Path: "steps[0].modul"},
+			},
+			message: []string{`missing required field "module"`, `unknown step field "modul" (did you mean "module"?)`},
+		},
+		{
+			name: "unknown parameter",
+			yaml: `name: show
+steps:
+  - name: probe
+    module: probe
+    parameters:
+      treshold: 3
+`,
+			want:    []SchemaIssue{{Line: 6, Column: 7, Code: IssueUnknownParam, Path: "steps[0].parameters.treshold"}},
+			message: []string{`unknown parameter "treshold" for module probe (did you mean "threshold"?)`},
+		},
+		{
+			name: "wrong types",
+			yaml: `name: [show]
+steps:
+  - name: probe
+    module: probe
+    priority: high
+    parameters:
+      retries: many
+      enabled: maybe
+      tags: {a: 1}
+      input: 12
+`,
+			want: []SchemaIssue{
+				{Line: 1, Column: 7, Code: IssueWrongType, Path: "name"},
+				{Line: 5, Column: 15, Code: IssueWrongType, Path: "steps[0].priority"},
+				{Line: 7, Column: 16, Code: IssueWrongType, Path: "steps[0].parameters.retries"},
+				{Line: 8, Column: 16, Code: IssueWrongType, Path: "steps[0].parameters.enabled"},
+				{Line: 9, Column: 13, Code: IssueWrongType, Path: "steps[0].parameters.tags"},
+				{Line: 10, Column: 14, Code: IssueWrongType, Path: "steps[0].parameters.input"},
+			},
+			message: []string{`expected string, got list`, `expected integer, got string "many"`, `expected string, got integer 12 (quote the value: "12")`},
+		},
+		{
+			name: "unknown module",
+			yaml: `name: show
+steps:
+  - name: probe
+    module: prob
+    parameters:
+      anything: 1
+`,
+			want:    []SchemaIssue{{Line: 4, Column: 13, Code: IssueUnknownModule, Path: "steps[0].module"}},
+			message: []string{`unknown module "prob" (did you mean "probe"?)`},
+		},
+		{
+			name: "duplicate step name",
+			yaml: `name: show
+steps:
+  - name: probe
+    module: probe
+
+  - name: probe
+    module: probe
+`,
+			want:    []SchemaIssue{{Line: 6, Column: 11, Code: IssueDuplicateStep, Path: "steps[1].name"}},
+			message: []string{`duplicate step name "probe" (first defined on line 3)`},
+		},
+		{
+			name: "missing fields",
+			yaml: `description: no name
+`,
+			want: []SchemaIssue{
+				{Line: 1, Column: 1, Code: IssueMissingField},
+				{Line: 1, Column: 1, Code: IssueMissingField},
+			},
+			message: []string{`missing required field "name"`, `missing required field "steps"`},
+		},
+		{
+			name: "not a mapping",
+			yaml: `- name: show
+`,
+			want: []SchemaIssue{{Line: 1, Column: 1, Code: IssueInvalid}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateWorkflowSchema("show.yaml", []byte(tt.yaml), registry)
+			if len(tt.want) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			var schemaErr *SchemaError
+			require.ErrorAs(t, err, &schemaErr)
+			got := make([]SchemaIssue, len(schemaErr.Issues))
+			for i, issue := range schemaErr.Issues {
+				got[i] = SchemaIssue{Line: issue.Line, Column: issue.Column, Code: issue.Code, Path: issue.Path}
+			}
+			assert.Equal(t, tt.want, got)
+
+			for _, fragment := range tt.message {
+				assert.Contains(t, err.Error(), fragment)
+			}
+		})
+	}
+}
+
+func TestSchemaError_Error(t *testing.T) {
+	err := &SchemaError{File: "show.yaml", Issues: []SchemaIssue{
+		{Line: 2, Column: 1, Path: "outpt", Message: `unknown field "outpt"`},
+		{Line: 4, Column: 13, Message: "step must be a mapping"},
+	}}
+
+	lines := strings.Split(err.Error(), "\n")
+	assert.Equal(t, []string{
+		"workflow has 2 schema error(s):",
+		`  show.yaml:2:1: outpt: unknown field "outpt"`,
+		"  show.yaml:4:13: step must be a mapping",
+	}, lines)
+}
+
+func TestValidateWorkflowSchema_Unparsable(t *testing.T) {
+	err := ValidateWorkflowSchema("show.yaml", []byte("name: [show\n"), mod.NewModuleRegistry())
+	require.Error(t, err)
+	var schemaErr *SchemaError
+	assert.NotErrorAs(t, err, &schemaErr, "YAML syntax errors are not schema issues")
+	assert.ErrorContains(t, err, "failed to parse workflow file")
+}
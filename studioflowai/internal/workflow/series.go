@@ -0,0 +1,68 @@
+package workflow
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/config"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+// titlePatternParam is the parameter through which modules receive the series title pattern
+const titlePatternParam = "titlePattern"
+
+// applySeries numbers the episode of the run when the workflow or the active project defines a
+// series. The number is added to the workflow metadata, and steps that generate titles receive
+// the series title pattern unless they set their own.
+func applySeries(w *Workflow, project *config.Project) error {
+	series := w.Series
+	if series == nil && project != nil {
+		series = project.Series
+	}
+	if series == nil {
+		return nil
+	}
+	if series.TitlePattern != "" && !strings.Contains(series.TitlePattern, "{title}") {
+		return fmt.Errorf("series titlePattern %q must contain {title}", series.TitlePattern)
+	}
+
+	metadata, err := workflowMetadata(w)
+	if err != nil {
+		return err
+	}
+	if w.Metadata == nil {
+		w.Metadata = make(map[string]interface{})
+	}
+
+	// Episode labels such as "S02E05" are used as written and leave the counter alone
+	explicit, numbered := 0, true
+	if value, ok := metadata["episode"]; ok {
+		n, err := strconv.Atoi(strings.TrimSpace(fmt.Sprint(value)))
+		explicit, numbered = n, err == nil
+	}
+	if numbered {
+		var runDir string
+		if w.inputConfig != nil && w.inputConfig.OutputPath != "" {
+			if runDir, err = filepath.Abs(w.inputConfig.OutputPath); err != nil {
+				return fmt.Errorf("failed to resolve output folder: %w", err)
+			}
+		}
+		episode, err := series.AssignEpisode(runDir, explicit)
+		if err != nil {
+			return err
+		}
+		w.Metadata["episode"] = episode
+		utils.LogInfo("Episode %d of series %s", episode, series.Name)
+	}
+	if _, ok := metadata["show"]; !ok && series.Name != "" {
+		w.Metadata["show"] = series.Name
+	}
+
+	if series.TitlePattern == "" {
+		return nil
+	}
+	defaultStepParam(w, titlePatternParam, series.TitlePattern)
+	return nil
+}
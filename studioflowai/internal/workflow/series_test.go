@@ -0,0 +1,51 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/config"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	suggestsnscontent "github.com/gnzdotmx/studioflowai/studioflowai/internal/modules/suggest_sns_content"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplySeries(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	registry := mod.NewModuleRegistry()
+	require.NoError(t, registry.Register(suggestsnscontent.New()))
+	series := &config.Series{Name: "Hack Talks", TitlePattern: "EP{n}: {title}", FirstEpisode: 41}
+
+	// load builds the workflow of a run in the given output folder
+	load := func(runDir string, metadata map[string]interface{}) *Workflow {
+		w := &Workflow{
+			Name:        "Series Test",
+			Series:      series,
+			Metadata:    metadata,
+			Steps:       []Step{{Name: "sns", Module: "suggest_sns_content", Parameters: map[string]interface{}{}}},
+			registry:    registry,
+			inputConfig: &config.InputConfig{OutputPath: runDir},
+		}
+		require.NoError(t, applySeries(w, nil))
+		return w
+	}
+
+	first := load("output/run-1", nil)
+	assert.Equal(t, 41, first.Metadata["episode"], "numbering starts at firstEpisode")
+	assert.Equal(t, "Hack Talks", first.Metadata["show"])
+	assert.Equal(t, "EP{n}: {title}", first.Steps[0].Parameters["titlePattern"])
+
+	assert.Equal(t, 41, load("output/run-1", nil).Metadata["episode"], "a retried run keeps its number")
+	assert.Equal(t, 42, load("output/run-2", nil).Metadata["episode"])
+
+	special := load("output/special", map[string]interface{}{"episode": "S02E05"})
+	assert.Equal(t, "S02E05", special.Metadata["episode"], "episode labels are kept as written")
+	assert.Equal(t, 43, load("output/run-3", nil).Metadata["episode"])
+
+	assert.Equal(t, 50, load("output/run-4", map[string]interface{}{"episode": 50}).Metadata["episode"])
+	assert.Equal(t, 51, load("output/run-5", nil).Metadata["episode"], "numbering continues after an explicit number")
+
+	w := &Workflow{Series: &config.Series{TitlePattern: "EP{n}"}, registry: registry}
+	assert.ErrorContains(t, applySeries(w, nil), "must contain {title}")
+}
@@ -0,0 +1,105 @@
+package workflow
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteWithState_InjectedFailure(t *testing.T) {
+	utils.SetChaos([]utils.ChaosFault{{Kind: utils.ChaosStep, Target: "second", Nth: 1}})
+	defer utils.SetChaos(nil)
+
+	w, recorder := newRecordingWorkflow(t, t.TempDir())
+	state, err := w.ExecuteWithState()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "injected failure of step second")
+	assert.Equal(t, WorkflowStatusFailed, state.Status)
+	assert.Len(t, recorder.calls, 1, "the failing step does not run its module")
+
+	// The failed step is checkpointed for a retry, and its failure recorded
+	require.NotNil(t, w.GetCheckpoint(state.CurrentNode))
+	assert.Equal(t, NodeStatusFailed, state.GetNodeStatus(state.CurrentNode))
+	last := state.History[len(state.History)-1]
+	assert.Equal(t, "failed", last.Type)
+	assert.Contains(t, last.Data["error"], "injected failure")
+
+	// The fault only strikes the first run, so running again succeeds
+	state, err = w.ExecuteWithState()
+	require.NoError(t, err)
+	assert.Equal(t, WorkflowStatusComplete, state.Status)
+	assert.Len(t, recorder.calls, 3)
+}
+
+// batchModule processes three clips, failing those listed in failing, and records the clips each
+// execution was asked to process
+type batchModule struct {
+	recordingModule
+	failing  map[string]bool
+	attempts [][]string
+}
+
+func (m *batchModule) Name() string { return "batch" }
+
+func (m *batchModule) Execute(ctx context.Context, params map[string]interface{}) (mod.ModuleResult, error) {
+	retry := mod.RetryItems(ctx)
+	var processed []string
+	var items []mod.ItemResult
+	for _, id := range []string{"a.mp4", "b.mp4", "c.mp4"} {
+		switch {
+		case retry != nil && !retry[id]:
+			items = append(items, mod.ItemResult{ID: id, Status: mod.ItemSkipped})
+			continue
+		case m.failing[id]:
+			items = append(items, mod.ItemResult{ID: id, Status: mod.ItemFailed, Error: "render failed"})
+		default:
+			items = append(items, mod.ItemResult{ID: id, Status: mod.ItemSucceeded})
+		}
+		processed = append(processed, id)
+	}
+	m.attempts = append(m.attempts, processed)
+	return mod.ModuleResult{Items: items}, nil
+}
+
+func TestExecuteWithState_PartialFailure(t *testing.T) {
+	outputDir := t.TempDir()
+	w, _ := newRecordingWorkflow(t, outputDir)
+	batch := &batchModule{failing: map[string]bool{"b.mp4": true}}
+	require.NoError(t, w.registry.Register(batch))
+	w.Steps = append(w.Steps, Step{Name: "render", Module: "batch", Parameters: map[string]interface{}{}})
+
+	state, err := w.ExecuteWithState()
+	require.NoError(t, err, "a partially failed step does not stop the workflow")
+	assert.Equal(t, WorkflowStatusPartiallyFailed, state.Status)
+	var event *WorkflowEvent
+	for i := range state.History {
+		if state.History[i].Type == "partially_failed" {
+			event = &state.History[i]
+		}
+	}
+	require.NotNil(t, event)
+	assert.Equal(t, NodeStatusPartiallyFailed, state.GetNodeStatus(event.NodeID))
+	assert.Equal(t, "render failed", event.Data["b.mp4"])
+
+	// The failed items survive a round trip through the state file
+	statePath := filepath.Join(outputDir, "test.state.yaml")
+	require.NoError(t, w.SaveWorkflowState(state, statePath))
+	loaded, err := w.LoadWorkflowState(statePath)
+	require.NoError(t, err)
+	retry := failedItems(loaded, "render")
+	assert.Equal(t, map[string][]string{"render": {"b.mp4"}}, retry)
+	assert.Nil(t, failedItems(loaded, "first"))
+
+	// A retry only processes the failed items
+	batch.failing = nil
+	w.retryItems = retry
+	state, err = w.ExecuteWithState()
+	require.NoError(t, err)
+	assert.Equal(t, WorkflowStatusComplete, state.Status)
+	assert.Equal(t, []string{"b.mp4"}, batch.attempts[len(batch.attempts)-1])
+}
@@ -0,0 +1,60 @@
+package workflow
+
+import (
+	"os"
+	"time"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+// stepStats completes the statistics reported by a module with what the engine measured: the
+// step duration, the size of the files it read and wrote, and the tokens, cost and tools
+// recorded through its context. Values the module set itself are kept.
+func stepStats(result mod.ModuleResult, params map[string]interface{}, usage *utils.Usage, elapsed time.Duration) mod.Stats {
+	stats := result.Stats
+	if stats.Duration == 0 {
+		stats.Duration = elapsed
+	}
+	if stats.BytesIn == 0 {
+		for name, value := range params {
+			if path, ok := value.(string); ok && name != "output" {
+				stats.BytesIn += regularFileSize(path)
+			}
+		}
+	}
+	if stats.BytesOut == 0 {
+		for _, path := range result.Outputs {
+			stats.BytesOut += regularFileSize(path)
+		}
+	}
+
+	tokens, cost, tools := usage.Totals()
+	if stats.Tokens == 0 {
+		stats.Tokens = tokens
+	}
+	if stats.CostUSD == 0 {
+		stats.CostUSD = cost
+	}
+	for name, version := range tools {
+		if stats.ToolVersions == nil {
+			stats.ToolVersions = make(map[string]string)
+		}
+		if _, ok := stats.ToolVersions[name]; !ok {
+			stats.ToolVersions[name] = version
+		}
+	}
+	return stats
+}
+
+// regularFileSize returns the size of a regular file, or 0 for anything else
+func regularFileSize(path string) int64 {
+	if path == "" {
+		return 0
+	}
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return 0
+	}
+	return info.Size()
+}
@@ -0,0 +1,56 @@
+package workflow
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	modules "github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStepStats(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.txt")
+	output := filepath.Join(dir, "output.txt")
+	require.NoError(t, os.WriteFile(input, []byte("hello"), 0644))
+	require.NoError(t, os.WriteFile(output, []byte("hello world"), 0644))
+
+	ctx, usage := utils.WithUsage(context.Background())
+	utils.RecordTokens(ctx, 120, 0.25)
+
+	result := modules.ModuleResult{
+		Outputs: map[string]string{"cleaned": output},
+		Stats:   modules.Stats{Items: 3, ToolVersions: map[string]string{"ffmpeg": "6.1"}},
+	}
+	params := map[string]interface{}{"input": input, "output": dir, "language": "en"}
+
+	stats := stepStats(result, params, usage, 2*time.Second)
+	assert.Equal(t, 2*time.Second, stats.Duration)
+	assert.Equal(t, int64(5), stats.BytesIn)
+	assert.Equal(t, int64(11), stats.BytesOut)
+	assert.Equal(t, 3, stats.Items)
+	assert.Equal(t, 120, stats.Tokens)
+	assert.InDelta(t, 0.25, stats.CostUSD, 1e-9)
+	assert.Equal(t, "6.1", stats.ToolVersions["ffmpeg"])
+}
+
+func TestRecordStats(t *testing.T) {
+	manifest := NewRunManifest("test")
+	manifest.RecordStats(Step{Name: "clean", Module: "clean_text"}, modules.Stats{Items: 1, Tokens: 100, CostUSD: 0.5})
+	manifest.RecordStats(Step{Name: "shorts", Module: "suggest_shorts"}, modules.Stats{Items: 4, Tokens: 50, ToolVersions: map[string]string{"ffmpeg": "6.1"}})
+
+	// A retried step replaces its earlier record
+	manifest.RecordStats(Step{Name: "clean", Module: "clean_text"}, modules.Stats{Items: 1, Tokens: 10})
+
+	require.Len(t, manifest.Steps, 2)
+	assert.Equal(t, "clean_text", manifest.Steps["clean"].Module)
+	assert.Equal(t, 5, manifest.Totals.Items)
+	assert.Equal(t, 60, manifest.Totals.Tokens)
+	assert.Zero(t, manifest.Totals.CostUSD)
+	assert.Equal(t, map[string]string{"ffmpeg": "6.1"}, manifest.Totals.ToolVersions)
+}
@@ -0,0 +1,351 @@
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/modules/transcribe"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// Tuning of suggested workflows
+const (
+	// Spoken words run about 150 a minute, at about 1.3 tokens a word
+	tokensPerMinute = 200
+
+	// Transcripts above this are corrected in chunks, each small enough for its corrected text to
+	// fit in correctMaxTokens
+	correctChunkTokens = 12000
+	correctMaxTokens   = 16384
+
+	// One short is suggested per this many minutes of recording, within minShorts and maxShorts
+	minutesPerShort = 6
+	minShorts       = 3
+	maxShorts       = 20
+
+	// Recordings longer than this are transcribed with the faster turbo model
+	longRecording = 90 * time.Minute
+
+	// Length of the audio sample language detection listens to
+	languageSample = 30 * time.Second
+)
+
+// MediaInfo describes a recording, as read by InspectMedia
+type MediaInfo struct {
+	Path     string
+	Duration time.Duration
+	HasVideo bool // Cover art of audio files does not count
+	HasAudio bool
+	Width    int
+	Height   int
+	Language string // Language heard in a sample of the audio, e.g. "Spanish"; empty when not detected
+}
+
+// Vertical reports whether the video is taller than wide, as phone recordings are
+func (m MediaInfo) Vertical() bool {
+	return m.HasVideo && m.Height > m.Width
+}
+
+// String describes the recording, e.g. "1:12:00 video, 1920x1080, Spanish"
+func (m MediaInfo) String() string {
+	parts := []string{formatClock(m.Duration) + " audio"}
+	if m.HasVideo {
+		parts[0] = formatClock(m.Duration) + " video"
+		parts = append(parts, fmt.Sprintf("%dx%d", m.Width, m.Height))
+	}
+	if m.Language != "" {
+		parts = append(parts, m.Language)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// InspectMedia reads the duration and streams of a recording with ffprobe and, when detect is
+// set and whisper is installed, the language of a sample of its audio
+func InspectMedia(ctx context.Context, path string, detect bool) (MediaInfo, error) {
+	cmd := exec.CommandContext(ctx, "ffprobe",
+		"-v", "error",
+		"-show_entries", "format=duration:stream=codec_type,width,height:stream_disposition=attached_pic",
+		"-of", "json",
+		path,
+	)
+	out, err := utils.OutputWatched(ctx, cmd)
+	if err != nil {
+		return MediaInfo{}, fmt.Errorf("failed to probe %s: %w", path, err)
+	}
+	info, err := parseMediaProbe(out)
+	if err != nil {
+		return MediaInfo{}, err
+	}
+	info.Path = path
+	if !info.HasAudio {
+		return MediaInfo{}, fmt.Errorf("%s has no audio to transcribe", path)
+	}
+
+	if detect {
+		if _, err := exec.LookPath("whisper"); err != nil {
+			utils.LogWarningContext(ctx, "whisper is not installed: the language is left to detection at transcription time")
+		} else {
+			info.Language = detectMediaLanguage(ctx, path, info.Duration)
+		}
+	}
+	return info, nil
+}
+
+// parseMediaProbe reads the ffprobe JSON output of InspectMedia
+func parseMediaProbe(data []byte) (MediaInfo, error) {
+	var probe struct {
+		Streams []struct {
+			CodecType   string `json:"codec_type"`
+			Width       int    `json:"width"`
+			Height      int    `json:"height"`
+			Disposition struct {
+				AttachedPic int `json:"attached_pic"`
+			} `json:"disposition"`
+		} `json:"streams"`
+		Format struct {
+			Duration string `json:"duration"`
+		} `json:"format"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return MediaInfo{}, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+
+	var info MediaInfo
+	if seconds, err := strconv.ParseFloat(probe.Format.Duration, 64); err == nil {
+		info.Duration = time.Duration(seconds * float64(time.Second))
+	}
+	for _, s := range probe.Streams {
+		switch {
+		case s.CodecType == "audio":
+			info.HasAudio = true
+		case s.CodecType == "video" && s.Disposition.AttachedPic == 0 && !info.HasVideo:
+			info.HasVideo = true
+			info.Width, info.Height = s.Width, s.Height
+		}
+	}
+	return info, nil
+}
+
+// detectMediaLanguage detects the language of a sample a tenth of the way into the recording, past
+// the intro music most recordings open with, and returns "" when it cannot be detected
+func detectMediaLanguage(ctx context.Context, path string, duration time.Duration) string {
+	tempDir, err := utils.MakeTempDir("", "suggest")
+	if err != nil {
+		utils.LogWarningContext(ctx, "Language detection skipped: %v", err)
+		return ""
+	}
+	defer func() {
+		if err := os.RemoveAll(tempDir); err != nil {
+			utils.LogWarningContext(ctx, "Failed to remove temp directory: %v", err)
+		}
+	}()
+
+	start := min(duration/10, 10*time.Minute)
+	sample := filepath.Join(tempDir, "sample.wav")
+	cmd := exec.CommandContext(ctx, "ffmpeg",
+		"-v", "error",
+		"-ss", strconv.FormatFloat(start.Seconds(), 'f', 3, 64),
+		"-t", strconv.FormatFloat(languageSample.Seconds(), 'f', 0, 64),
+		"-i", path,
+		"-vn", "-ac", "1", "-ar", "16000",
+		"-y", sample,
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		utils.LogWarningContext(ctx, "Language detection skipped: failed to extract a sample: %s", strings.TrimSpace(string(output)))
+		return ""
+	}
+	utils.LogInfoContext(ctx, "Detecting the language of %s from %s", filepath.Base(path), formatClock(start))
+	return transcribe.DetectLanguage(ctx, sample, "tiny")
+}
+
+// formatClock formats a duration as H:MM:SS
+func formatClock(d time.Duration) string {
+	seconds := int(d.Round(time.Second).Seconds())
+	return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
+
+// WorkflowSuggestion is a workflow proposed for a recording, with the reasons of its tuned values
+type WorkflowSuggestion struct {
+	Media    MediaInfo
+	Workflow Workflow
+	Notes    map[string]map[string]string // Reason of a parameter's value, by step name and parameter
+}
+
+// note records why a parameter of a step has its value
+func (s *WorkflowSuggestion) note(step, param, format string, args ...interface{}) {
+	if s.Notes[step] == nil {
+		s.Notes[step] = make(map[string]string)
+	}
+	s.Notes[step][param] = fmt.Sprintf(format, args...)
+}
+
+// SuggestWorkflow proposes a workflow for a recording: transcription and correction for every
+// recording, shorts for videos and an article for audio. Models, chunk sizes and the number of
+// shorts follow its length and language.
+func SuggestWorkflow(media MediaInfo) *WorkflowSuggestion {
+	s := &WorkflowSuggestion{Media: media, Notes: make(map[string]map[string]string)}
+	base := strings.TrimSuffix(filepath.Base(media.Path), filepath.Ext(media.Path))
+	minutes := int(media.Duration.Minutes())
+	language := media.Language
+
+	s.Workflow = Workflow{
+		Name:        "Process " + base,
+		Description: "Suggested for " + filepath.Base(media.Path) + " (" + media.String() + ")",
+		Output:      "./output",
+	}
+	add := func(name, module string, params map[string]interface{}) {
+		s.Workflow.Steps = append(s.Workflow.Steps, Step{Name: name, Module: module, Parameters: params})
+	}
+
+	add("Extract Audio", "extractaudio", map[string]interface{}{
+		"input":      media.Path,
+		"outputName": "audio.wav",
+		"sampleRate": 16000,
+		"channels":   1,
+	})
+
+	// English-only models are faster and as accurate for English; turbo keeps long recordings fast
+	whisperModel := "large-v3"
+	switch {
+	case strings.EqualFold(language, "English"):
+		whisperModel = "medium.en"
+		s.note("Transcribe", "whisperParams", "English-only model, faster than large-v3 for English")
+	case media.Duration > longRecording:
+		whisperModel = "large-v3-turbo"
+		s.note("Transcribe", "whisperParams", "Over %d minutes: the turbo model transcribes several times faster", int(longRecording.Minutes()))
+	default:
+		s.note("Transcribe", "whisperParams", "Most accurate multilingual model")
+	}
+	transcribeParams := map[string]interface{}{
+		"input":          "${output}/audio.wav",
+		"outputFileName": "transcript",
+		"outputFormat":   "srt",
+		"model":          "whisper",
+		"whisperParams":  "--model " + whisperModel,
+	}
+	if language != "" {
+		transcribeParams["language"] = language
+		s.note("Transcribe", "language", "Detected in a sample; set to auto for recordings mixing languages")
+	}
+	add("Transcribe", "transcribe", transcribeParams)
+
+	add("Clean Transcript", "clean_text", map[string]interface{}{
+		"input":             "${output}/transcript.srt",
+		"outputFileName":    "transcript",
+		"cleanFileSuffix":   "_clean",
+		"preserveTimestamp": true,
+		"preserveLineBreak": true,
+	})
+
+	tokens := minutes * tokensPerMinute
+	correctParams := map[string]interface{}{
+		"input":          "${output}/transcript_clean.txt",
+		"outputFileName": "transcript_corrected",
+		"targetLanguage": "auto",
+		"maxTokens":      correctMaxTokens,
+		"chunkSize":      correctChunkTokens,
+	}
+	if language != "" {
+		correctParams["targetLanguage"] = language
+	}
+	if tokens > correctChunkTokens {
+		s.note("Correct Transcript", "chunkSize", "About %d tokens of transcript: corrected in %d chunks that each fit in maxTokens", tokens, (tokens+correctChunkTokens-1)/correctChunkTokens)
+	} else {
+		s.note("Correct Transcript", "chunkSize", "About %d tokens of transcript: corrected in one request", tokens)
+	}
+	add("Correct Transcript", "correct_transcript", correctParams)
+
+	contentLanguage := language
+	if contentLanguage == "" {
+		contentLanguage = "English"
+	}
+	if media.HasVideo {
+		shorts := min(max(minutes/minutesPerShort, minShorts), maxShorts)
+		add("Suggest Shorts", "suggest_shorts", map[string]interface{}{
+			"input":          "${output}/transcript.srt",
+			"outputFileName": "shorts_suggestions",
+			"maxShorts":      shorts,
+			"minDuration":    15,
+			"maxDuration":    60,
+		})
+		s.note("Suggest Shorts", "maxShorts", "One short per %d minutes of recording, between %d and %d", minutesPerShort, minShorts, maxShorts)
+
+		extractParams := map[string]interface{}{
+			"input":     "${output}/shorts_suggestions.yaml",
+			"videoFile": media.Path,
+		}
+		if media.Vertical() {
+			extractParams["ffmpegParams"] = "-c:v libx264 -c:a aac -b:a 128k -b:v 2500k"
+			s.note("Extract Shorts", "ffmpegParams", "The recording is already vertical: clips are cut without reframing")
+		} else {
+			extractParams["ffmpegParams"] = "-vf scale=1080:1920:force_original_aspect_ratio=decrease,pad=1080:1920:(ow-iw)/2:(oh-ih)/2,setsar=1 -c:v libx264 -c:a aac -b:a 128k -b:v 2500k"
+			s.note("Extract Shorts", "ffmpegParams", "%dx%d recording: clips are fitted into 1080x1920 for vertical platforms", media.Width, media.Height)
+		}
+		add("Extract Shorts", "extract_shorts", extractParams)
+	} else {
+		add("Write Blog Post", "blog_post", map[string]interface{}{
+			"input":          "${output}/transcript_corrected.txt",
+			"outputFileName": "blog_post",
+			"language":       contentLanguage,
+		})
+		s.note("Write Blog Post", "input", "No video to cut shorts from: the episode becomes an article")
+	}
+
+	add("Generate Social Media Content", "suggest_sns_content", map[string]interface{}{
+		"input":          "${output}/transcript.srt",
+		"outputFileName": "social_media_content",
+		"language":       contentLanguage,
+	})
+	if language == "" {
+		s.note("Generate Social Media Content", "language", "The language was not detected: set the language of your audience")
+	}
+	return s
+}
+
+// YAML returns the suggested workflow file, with the reasons of its tuned values as comments
+func (s *WorkflowSuggestion) YAML() ([]byte, error) {
+	var doc yaml.Node
+	if err := doc.Encode(&s.Workflow); err != nil {
+		return nil, fmt.Errorf("failed to encode workflow: %w", err)
+	}
+	doc.HeadComment = fmt.Sprintf("Suggested by studioflowai suggest-workflow for %s (%s).\nEdit it, then run it with: studioflowai run -w <this file> -i %s",
+		filepath.Base(s.Media.Path), s.Media, s.Media.Path)
+
+	steps := mappingValue(&doc, "steps")
+	if steps == nil {
+		return nil, fmt.Errorf("encoded workflow has no steps")
+	}
+	for _, step := range steps.Content {
+		name := mappingValue(step, "name")
+		params := mappingValue(step, "parameters")
+		if name == nil || params == nil {
+			continue
+		}
+		for param, reason := range s.Notes[name.Value] {
+			for i := 0; i+1 < len(params.Content); i += 2 {
+				if params.Content[i].Value == param {
+					params.Content[i].HeadComment = reason
+				}
+			}
+		}
+	}
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to encode workflow: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
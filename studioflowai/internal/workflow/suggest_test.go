@@ -0,0 +1,94 @@
+package workflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseMediaProbe(t *testing.T) {
+	info, err := parseMediaProbe([]byte(`{
+		"streams": [
+			{"codec_type": "video", "width": 1080, "height": 1920, "disposition": {"attached_pic": 0}},
+			{"codec_type": "audio", "disposition": {"attached_pic": 0}}
+		],
+		"format": {"duration": "4325.500000"}
+	}`))
+	require.NoError(t, err)
+	assert.True(t, info.HasVideo)
+	assert.True(t, info.HasAudio)
+	assert.True(t, info.Vertical())
+	assert.Equal(t, 4325500*time.Millisecond, info.Duration)
+
+	// The cover art of an audio file is not a video
+	info, err = parseMediaProbe([]byte(`{
+		"streams": [
+			{"codec_type": "audio"},
+			{"codec_type": "video", "width": 600, "height": 600, "disposition": {"attached_pic": 1}}
+		],
+		"format": {"duration": "1800"}
+	}`))
+	require.NoError(t, err)
+	assert.False(t, info.HasVideo)
+	assert.Equal(t, "0:30:00 audio", info.String())
+}
+
+// suggestedParams returns the parameters of the step of a suggestion using module
+func suggestedParams(t *testing.T, s *WorkflowSuggestion, module string) map[string]interface{} {
+	t.Helper()
+	for _, step := range s.Workflow.Steps {
+		if step.Module == module {
+			return step.Parameters
+		}
+	}
+	t.Fatalf("no %s step in the suggestion", module)
+	return nil
+}
+
+func TestSuggestWorkflow_Video(t *testing.T) {
+	s := SuggestWorkflow(MediaInfo{Path: "./input/ep12.mp4", Duration: 150 * time.Minute, HasVideo: true, HasAudio: true, Width: 1920, Height: 1080, Language: "Spanish"})
+
+	assert.Equal(t, "--model large-v3-turbo", suggestedParams(t, s, "transcribe")["whisperParams"])
+	assert.Equal(t, "Spanish", suggestedParams(t, s, "transcribe")["language"])
+	assert.Equal(t, "Spanish", suggestedParams(t, s, "correct_transcript")["targetLanguage"])
+	assert.Equal(t, 20, suggestedParams(t, s, "suggest_shorts")["maxShorts"], "at most 20 shorts")
+	assert.Contains(t, suggestedParams(t, s, "extract_shorts")["ffmpegParams"], "pad=1080:1920")
+	assert.Equal(t, "./input/ep12.mp4", suggestedParams(t, s, "extract_shorts")["videoFile"])
+	assert.Contains(t, s.Notes["Correct Transcript"]["chunkSize"], "corrected in 3 chunks")
+
+	// The file is a valid workflow, with the reasons as comments
+	data, err := s.YAML()
+	require.NoError(t, err)
+	registry, err := NewRegistry()
+	require.NoError(t, err)
+	require.NoError(t, ValidateWorkflowSchema("suggested.yaml", data, registry))
+	assert.Contains(t, string(data), "# Over 90 minutes: the turbo model transcribes several times faster\n      whisperParams: --model large-v3-turbo\n")
+
+	var parsed Workflow
+	require.NoError(t, yaml.Unmarshal(data, &parsed))
+	assert.Equal(t, s.Workflow.Steps, parsed.Steps)
+}
+
+func TestSuggestWorkflow_ShortEnglishVerticalVideo(t *testing.T) {
+	s := SuggestWorkflow(MediaInfo{Path: "clip.mov", Duration: 8 * time.Minute, HasVideo: true, HasAudio: true, Width: 1080, Height: 1920, Language: "English"})
+
+	assert.Equal(t, "--model medium.en", suggestedParams(t, s, "transcribe")["whisperParams"])
+	assert.Equal(t, 3, suggestedParams(t, s, "suggest_shorts")["maxShorts"], "at least 3 shorts")
+	assert.NotContains(t, suggestedParams(t, s, "extract_shorts")["ffmpegParams"], "scale=")
+	assert.Contains(t, s.Notes["Correct Transcript"]["chunkSize"], "one request")
+}
+
+func TestSuggestWorkflow_Audio(t *testing.T) {
+	s := SuggestWorkflow(MediaInfo{Path: "episode.mp3", Duration: 45 * time.Minute, HasAudio: true})
+
+	modules := make([]string, 0, len(s.Workflow.Steps))
+	for _, step := range s.Workflow.Steps {
+		modules = append(modules, step.Module)
+	}
+	assert.Equal(t, []string{"extractaudio", "transcribe", "clean_text", "correct_transcript", "blog_post", "suggest_sns_content"}, modules)
+	assert.NotContains(t, suggestedParams(t, s, "transcribe"), "language", "undetected languages are left to whisper")
+	assert.Equal(t, "auto", suggestedParams(t, s, "correct_transcript")["targetLanguage"])
+}
@@ -0,0 +1,296 @@
+package workflow
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+)
+
+const (
+	// TimelineFileName is the Markdown timeline of a run's events, written to its output folder
+	TimelineFileName = "timeline.md"
+	// TimelineHTMLFileName is the same timeline as a standalone page, to attach to a report or issue
+	TimelineHTMLFileName = "timeline.html"
+)
+
+// timeline is the history of a run in the order it happened, with what a post-mortem needs
+type timeline struct {
+	Name    string
+	RunID   string
+	Status  string
+	Start   time.Time
+	End     time.Time
+	Entries []timelineEntry
+	Failure *timelineEntry
+	Links   []timelineLink
+}
+
+// timelineEntry is one event of the run
+type timelineEntry struct {
+	Time    time.Time
+	Since   time.Duration // Time since the previous event
+	Took    time.Duration // Time since the step started, for completed and failed events
+	Step    string
+	Type    string
+	Details string // Statistics of a completed step or the error of a failed one
+}
+
+// timelineLink points to a log or output file in the output folder
+type timelineLink struct {
+	Label string
+	Path  string // Relative to the output folder
+}
+
+// WriteTimeline writes the events of a run to the output folder as Markdown and HTML and returns
+// the path of the Markdown file
+func WriteTimeline(state *WorkflowState, outputDir string) (string, error) {
+	t := buildTimeline(state, outputDir)
+
+	mdPath := filepath.Join(outputDir, TimelineFileName)
+	if err := utils.AtomicWriteFile(mdPath, []byte(t.markdown()), 0644); err != nil {
+		return "", fmt.Errorf("failed to write timeline: %w", err)
+	}
+
+	var page strings.Builder
+	if err := timelineHTML.Execute(&page, t); err != nil {
+		return "", fmt.Errorf("failed to render timeline: %w", err)
+	}
+	if err := utils.AtomicWriteFile(filepath.Join(outputDir, TimelineHTMLFileName), []byte(page.String()), 0644); err != nil {
+		return "", fmt.Errorf("failed to write timeline: %w", err)
+	}
+	return mdPath, nil
+}
+
+// buildTimeline orders the events of a run and links the logs and outputs found in the output folder
+func buildTimeline(state *WorkflowState, outputDir string) timeline {
+	state.RLock()
+	defer state.RUnlock()
+
+	t := timeline{
+		Name:   state.Name,
+		RunID:  state.ID,
+		Status: string(state.Status),
+		Start:  state.StartTime,
+		End:    state.EndTime,
+	}
+
+	events := make([]WorkflowEvent, len(state.History))
+	copy(events, state.History)
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Timestamp.Before(events[j].Timestamp) })
+
+	previous := state.StartTime
+	stepStarted := make(map[string]time.Time)
+	for _, event := range events {
+		entry := timelineEntry{
+			Time:  event.Timestamp,
+			Since: event.Timestamp.Sub(previous),
+			Step:  event.NodeID,
+			Type:  event.Type,
+		}
+		if state.Graph != nil {
+			if node, ok := state.Graph.Nodes[event.NodeID]; ok {
+				entry.Step = node.Step.Name
+			}
+		}
+
+		switch event.Type {
+		case "started":
+			stepStarted[event.NodeID] = event.Timestamp
+		case "completed", "partially_failed":
+			entry.Details = formatEventData(event.Data)
+		case "failed":
+			entry.Details = utils.Redact(event.Message)
+			if msg, ok := event.Data["error"].(string); ok {
+				entry.Details = utils.Redact(msg)
+			}
+		}
+		if started, ok := stepStarted[event.NodeID]; ok && event.Type != "started" {
+			entry.Took = event.Timestamp.Sub(started)
+		}
+
+		t.Entries = append(t.Entries, entry)
+		if event.Type == "failed" {
+			failure := entry
+			t.Failure = &failure
+		}
+		previous = event.Timestamp
+	}
+	if t.End.IsZero() && len(events) > 0 {
+		t.End = events[len(events)-1].Timestamp
+	}
+
+	t.Links = timelineLinks(state, outputDir)
+	return t
+}
+
+// timelineLinks lists the logs of the run and the outputs of its steps that exist in the output folder
+func timelineLinks(state *WorkflowState, outputDir string) []timelineLink {
+	var links []timelineLink
+	add := func(label, path string) {
+		rel, err := filepath.Rel(outputDir, path)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return
+		}
+		if _, err := os.Stat(path); err != nil {
+			return
+		}
+		links = append(links, timelineLink{Label: label, Path: filepath.ToSlash(rel)})
+	}
+
+	add("External commands", filepath.Join(outputDir, utils.CommandLogFileName))
+	add("Artifact manifest", filepath.Join(outputDir, manifestFileName(state.Name)))
+	add("Workflow state", filepath.Join(outputDir, strings.ReplaceAll(state.Name, " ", "_")+".state.yaml"))
+
+	if state.Graph == nil {
+		return links
+	}
+	// Outputs are listed in execution order, as the events are
+	seen := make(map[string]bool)
+	for _, event := range state.History {
+		node, ok := state.Graph.Nodes[event.NodeID]
+		if !ok || seen[node.ID] {
+			continue
+		}
+		seen[node.ID] = true
+		names := make([]string, 0, len(node.Outputs))
+		for name := range node.Outputs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			add(fmt.Sprintf("%s: %s", node.Step.Name, name), node.Outputs[name])
+		}
+	}
+	return links
+}
+
+// formatEventData lists the statistics of an event as "key=value" in a stable order
+func formatEventData(data map[string]interface{}) string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%v", key, data[key]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatOffset shows a duration between events, leaving out zero
+func formatOffset(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	return "+" + d.Round(100*time.Millisecond).String()
+}
+
+// formatTook shows the duration of a step, leaving out zero
+func formatTook(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
+
+// markdown renders the timeline as a Markdown document
+func (t timeline) markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Timeline of %s\n\n", t.Name)
+	fmt.Fprintf(&b, "- Run: %s\n", t.RunID)
+	fmt.Fprintf(&b, "- Status: %s\n", t.Status)
+	fmt.Fprintf(&b, "- Started: %s\n", t.Start.Format(time.RFC3339))
+	if !t.End.IsZero() {
+		fmt.Fprintf(&b, "- Ended: %s (%s)\n", t.End.Format(time.RFC3339), formatTook(t.End.Sub(t.Start)))
+	}
+
+	b.WriteString("\n| Time | Since previous | Step | Event | Took | Details |\n")
+	b.WriteString("|------|----------------|------|-------|------|---------|\n")
+	for _, e := range t.Entries {
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n",
+			e.Time.Format("15:04:05"), formatOffset(e.Since), markdownCell(e.Step), e.Type, formatTook(e.Took), markdownCell(e.Details))
+	}
+
+	if t.Failure != nil {
+		fmt.Fprintf(&b, "\n## Failure\n\n%s failed at %s", t.Failure.Step, t.Failure.Time.Format("15:04:05"))
+		if t.Failure.Took > 0 {
+			fmt.Fprintf(&b, " after %s", formatTook(t.Failure.Took))
+		}
+		fmt.Fprintf(&b, ":\n\n```\n%s\n```\n", t.Failure.Details)
+	}
+
+	if len(t.Links) > 0 {
+		b.WriteString("\n## Logs and outputs\n\n")
+		for _, link := range t.Links {
+			fmt.Fprintf(&b, "- [%s](%s)\n", link.Label, strings.ReplaceAll(link.Path, " ", "%20"))
+		}
+	}
+	return b.String()
+}
+
+// markdownCell keeps a value on one table row
+func markdownCell(value string) string {
+	value = strings.ReplaceAll(value, "|", `\|`)
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// timelineHTML renders the timeline as a standalone page
+var timelineHTML = template.Must(template.New("timeline").Funcs(template.FuncMap{
+	"clock":  func(t time.Time) string { return t.Format("15:04:05") },
+	"date":   func(t time.Time) string { return t.Format(time.RFC3339) },
+	"offset": formatOffset,
+	"took":   formatTook,
+	"span":   func(start, end time.Time) string { return formatTook(end.Sub(start)) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Timeline of {{.Name}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+tr.failed { background: #fdd; }
+pre { background: #f4f4f4; padding: 1em; white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<h1>Timeline of {{.Name}}</h1>
+<ul>
+<li>Run: {{.RunID}}</li>
+<li>Status: {{.Status}}</li>
+<li>Started: {{date .Start}}</li>
+{{- if not .End.IsZero}}
+<li>Ended: {{date .End}} ({{span .Start .End}})</li>
+{{- end}}
+</ul>
+<table>
+<tr><th>Time</th><th>Since previous</th><th>Step</th><th>Event</th><th>Took</th><th>Details</th></tr>
+{{- range .Entries}}
+<tr class="{{.Type}}"><td>{{clock .Time}}</td><td>{{offset .Since}}</td><td>{{.Step}}</td><td>{{.Type}}</td><td>{{took .Took}}</td><td>{{.Details}}</td></tr>
+{{- end}}
+</table>
+{{- with .Failure}}
+<h2>Failure</h2>
+<p>{{.Step}} failed at {{clock .Time}}{{if gt .Took 0}} after {{took .Took}}{{end}}:</p>
+<pre>{{.Details}}</pre>
+{{- end}}
+{{- if .Links}}
+<h2>Logs and outputs</h2>
+<ul>
+{{- range .Links}}
+<li><a href="{{.Path}}">{{.Label}}</a></li>
+{{- end}}
+</ul>
+{{- end}}
+</body>
+</html>
+`))
@@ -0,0 +1,58 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteTimeline(t *testing.T) {
+	dir := t.TempDir()
+	audio := filepath.Join(dir, "audio.wav")
+	require.NoError(t, os.WriteFile(audio, []byte("wav"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "commands.sh"), []byte("#!/bin/sh\n"), 0755))
+
+	graph := NewWorkflowGraph()
+	extract := graph.AddNode(Step{Name: "Extract Audio", Module: "extract_audio"})
+	extract.Outputs = map[string]string{"audio": audio}
+	transcribe := graph.AddNode(Step{Name: "Transcribe", Module: "transcribe"})
+
+	start := time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)
+	state := &WorkflowState{
+		ID:        "run-1",
+		Name:      "Podcast",
+		Graph:     graph,
+		StartTime: start,
+		Status:    WorkflowStatusFailed,
+		History: []WorkflowEvent{
+			{Timestamp: start, NodeID: extract.ID, Type: "started"},
+			{Timestamp: start.Add(12 * time.Second), NodeID: extract.ID, Type: "completed", Data: map[string]interface{}{"files": 1}},
+			{Timestamp: start.Add(12 * time.Second), NodeID: transcribe.ID, Type: "started"},
+			{Timestamp: start.Add(3 * time.Minute), NodeID: transcribe.ID, Type: "failed",
+				Data: map[string]interface{}{"error": "whisper exited | status 1"}},
+		},
+	}
+
+	path, err := WriteTimeline(state, dir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, TimelineFileName), path)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	md := string(data)
+	assert.Contains(t, md, "- Status: failed")
+	assert.Contains(t, md, "| 10:00:12 | +12s | Extract Audio | completed | 12s | files=1 |")
+	assert.Contains(t, md, "| 10:03:00 | +2m48s | Transcribe | failed | 2m48s | whisper exited \\| status 1 |")
+	assert.Contains(t, md, "Transcribe failed at 10:03:00 after 2m48s")
+	assert.Contains(t, md, "- [External commands](commands.sh)")
+	assert.Contains(t, md, "- [Extract Audio: audio](audio.wav)")
+
+	page, err := os.ReadFile(filepath.Join(dir, TimelineHTMLFileName))
+	require.NoError(t, err)
+	assert.Contains(t, string(page), `<tr class="failed">`)
+	assert.Contains(t, string(page), `<a href="commands.sh">External commands</a>`)
+}
@@ -7,6 +7,8 @@ import (
 
 	"github.com/gnzdotmx/studioflowai/studioflowai/internal/config"
 	modules "github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/worker"
 )
 
 // Core workflow types
@@ -19,6 +21,41 @@ type Workflow struct {
 	Output      string `yaml:"output"`
 	Steps       []Step `yaml:"steps"`
 
+	// Pattern of the run folder name, e.g. "{date}/{slug}-{run.shortid}"; overrides the active project's
+	OutputName string `yaml:"outputName,omitempty"`
+
+	// Episode details passed to every step that accepts a metadata parameter
+	Metadata     map[string]interface{} `yaml:"metadata,omitempty"`
+	MetadataFile string                 `yaml:"metadataFile,omitempty"`
+
+	// Episode numbering and title pattern; overrides the active project's series
+	Series *config.Series `yaml:"series,omitempty"`
+
+	// Filtergraph template used by every rendering step that does not set its own
+	Filtergraph string `yaml:"filtergraph,omitempty"`
+
+	// Theme file (.ass or .yaml) of the rendering steps that do not set their own; overrides the active project's theme
+	Theme string `yaml:"theme,omitempty"`
+
+	// Whisper parameters per language for the transcribe steps; overrides the active project's profiles
+	WhisperProfiles map[string]string `yaml:"whisperProfiles,omitempty"`
+
+	// Fixes run on transcripts right after transcription; overrides the active project's fixes
+	TranscriptFixes []utils.TranscriptFix `yaml:"transcriptFixes,omitempty"`
+
+	// Inactivity limits of external tools, by tool name; overrides the built-in ones
+	Watchdog map[string]WatchdogConfig `yaml:"watchdog,omitempty"`
+
+	// Modes and group of the files and folders the run writes; overrides the active project's
+	Permissions *utils.Permissions `yaml:"permissions,omitempty"`
+
+	// Part of the source video the audio, transcription and suggestion steps are restricted to
+	StartTime string `yaml:"startTime,omitempty"`
+	EndTime   string `yaml:"endTime,omitempty"`
+
+	// Addresses (host:port) of the machines that run steps, by the name steps refer to them with
+	Workers map[string]string `yaml:"workers,omitempty"`
+
 	// Registry holds all available modules
 	registry    *modules.ModuleRegistry
 	inputConfig *config.InputConfig
@@ -26,6 +63,18 @@ type Workflow struct {
 	// Checkpoint management
 	checkpoints     map[string]*WorkflowCheckpoint
 	checkpointMutex sync.RWMutex
+
+	// Items that failed in the previous attempt of a step, by step name; a retry processes only these
+	retryItems map[string][]string
+
+	// Connections to the workers of the run, by address
+	workerClients map[string]*worker.Client
+}
+
+// WatchdogConfig sets how long an external tool may run without printing anything before it is killed
+type WatchdogConfig struct {
+	Idle    string `yaml:"idle"`              // Duration such as "20m"; "0" disables the watchdog
+	Retries *int   `yaml:"retries,omitempty"` // Times a killed process is started again
 }
 
 // Step represents a single processing step in a workflow
@@ -33,6 +82,17 @@ type Step struct {
 	Name       string                 `yaml:"name"`
 	Module     string                 `yaml:"module"`
 	Parameters map[string]interface{} `yaml:"parameters"`
+
+	// Earlier step whose output is this step's input; declares a dependency the engine cannot infer
+	FromStep string `yaml:"fromStep,omitempty"`
+
+	// Worker, named in the workflow's workers, that runs the step instead of this machine
+	Worker string `yaml:"worker,omitempty"`
+
+	// Resource the step mostly waits on (gpu, cpu, io or api) and its priority among the steps
+	// waiting for it; workers run steps of different classes at once, within the limits of each
+	Class    string `yaml:"class,omitempty"`
+	Priority int    `yaml:"priority,omitempty"`
 }
 
 // Graph-related types
@@ -101,6 +161,9 @@ const (
 	NodeStatusComplete NodeStatus = "complete"
 	NodeStatusFailed   NodeStatus = "failed"
 	NodeStatusSkipped  NodeStatus = "skipped"
+
+	// Some items of the step failed; the others succeeded and later steps use them
+	NodeStatusPartiallyFailed NodeStatus = "partially_failed"
 )
 
 // WorkflowStatus represents the current status of the workflow
@@ -111,6 +174,9 @@ const (
 	WorkflowStatusRunning  WorkflowStatus = "running"
 	WorkflowStatusComplete WorkflowStatus = "complete"
 	WorkflowStatusFailed   WorkflowStatus = "failed"
+
+	// Every step ran, but some items of a batch step failed
+	WorkflowStatusPartiallyFailed WorkflowStatus = "partially_failed"
 )
 
 // Execution types
@@ -128,3 +194,34 @@ type RetryStrategy struct {
 	BackoffDuration time.Duration
 	OnRetry         func(error) bool
 }
+
+// Manifest types
+
+// RunManifest records checksums of the artifacts produced by a workflow run
+type RunManifest struct {
+	Workflow  string                    `yaml:"workflow"`
+	UpdatedAt time.Time                 `yaml:"updatedAt"`
+	Artifacts map[string]ArtifactRecord `yaml:"artifacts"`
+	Steps     map[string]StepRecord     `yaml:"steps,omitempty"` // Statistics of each completed step, keyed by step name
+	Totals    modules.Stats             `yaml:"totals"`          // Sum of the statistics of all steps
+
+	Seed          int64 `yaml:"seed,omitempty"`          // Seed sent to the models; rerun with --seed to reproduce
+	Deterministic bool  `yaml:"deterministic,omitempty"` // Whether the run used --deterministic
+}
+
+// StepRecord holds the statistics of the last successful execution of a step
+type StepRecord struct {
+	Module     string        `yaml:"module"`
+	Stats      modules.Stats `yaml:"stats"`
+	RecordedAt time.Time     `yaml:"recordedAt"`
+}
+
+// ArtifactRecord describes a single artifact written by a workflow step
+type ArtifactRecord struct {
+	Step       string    `yaml:"step"`
+	Module     string    `yaml:"module"`
+	Output     string    `yaml:"output"`
+	SHA256     string    `yaml:"sha256"`
+	Size       int64     `yaml:"size"`
+	RecordedAt time.Time `yaml:"recordedAt"`
+}
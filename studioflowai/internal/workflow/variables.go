@@ -0,0 +1,88 @@
+package workflow
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Variables holds the values substituted for ${...} placeholders in step parameters.
+// Placeholders without a value, and those owned by modules such as ${source_video}, are left as written.
+type Variables struct {
+	Output string // ${output}: the run's output directory
+	Input  string // ${input}: the workflow input, from -i or the first step
+	RunID  string // ${run.id}: the ID of the current execution
+}
+
+// ResolveParams returns a copy of a step's parameters with all variables interpolated,
+// including inside lists and nested mappings
+func (v Variables) ResolveParams(step Step) map[string]interface{} {
+	params := make(map[string]interface{}, len(step.Parameters))
+	for k, value := range step.Parameters {
+		params[k] = v.resolveValue(k, value, step)
+	}
+	return params
+}
+
+// Resolve interpolates the variables in a single parameter value of a step.
+// Path parameters also get escaped spaces removed and relative paths anchored at ./
+func (v Variables) Resolve(key, value string, step Step) string {
+	pairs := []string{"${step.name}", step.Name}
+	for _, variable := range [][2]string{{"${output}", v.Output}, {"${input}", v.Input}, {"${run.id}", v.RunID}} {
+		if variable[1] != "" {
+			pairs = append(pairs, variable[0], variable[1])
+		}
+	}
+	resolved := strings.NewReplacer(pairs...).Replace(value)
+
+	if !isPathParam(key) || resolved == "" {
+		return resolved
+	}
+
+	// Paths copied from a shell may keep their escaped spaces
+	resolved = strings.ReplaceAll(resolved, "\\ ", " ")
+
+	// Only add ./ to plain relative paths, not to unresolved placeholders
+	if !filepath.IsAbs(resolved) && !strings.HasPrefix(resolved, "./") && !strings.HasPrefix(resolved, "${") {
+		resolved = "./" + resolved
+	}
+	return resolved
+}
+
+// HasVariable reports whether a value refers to one of the workflow variables
+func HasVariable(value string) bool {
+	for _, variable := range []string{"${output}", "${input}", "${run.id}", "${step.name}"} {
+		if strings.Contains(value, variable) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveValue interpolates strings and recurses into lists and mappings
+func (v Variables) resolveValue(key string, value interface{}, step Step) interface{} {
+	switch val := value.(type) {
+	case string:
+		return v.Resolve(key, val, step)
+	case []interface{}:
+		resolved := make([]interface{}, len(val))
+		for i, item := range val {
+			resolved[i] = v.resolveValue(key, item, step)
+		}
+		return resolved
+	case map[string]interface{}:
+		resolved := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			// Nested values are interpolated but never treated as paths
+			resolved[k] = v.resolveValue("", item, step)
+		}
+		return resolved
+	default:
+		return value
+	}
+}
+
+// isPathParam reports whether a parameter holds a file or directory path
+func isPathParam(key string) bool {
+	return key == "input" || key == "output" ||
+		strings.HasSuffix(key, "Path") || strings.HasSuffix(key, "File") || strings.HasSuffix(key, "Dir")
+}
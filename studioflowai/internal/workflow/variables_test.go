@@ -0,0 +1,147 @@
+package workflow
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingModule records the parameters of every execution and writes <step>.txt
+type recordingModule struct {
+	calls []map[string]interface{}
+}
+
+func (m *recordingModule) Name() string { return "record" }
+
+func (m *recordingModule) Validate(params map[string]interface{}) error { return nil }
+
+func (m *recordingModule) GetIO() mod.ModuleIO {
+	return mod.ModuleIO{
+		RequiredInputs: []mod.ModuleInput{
+			{Name: "input", Patterns: []string{".txt"}, Type: string(mod.InputTypeFile)},
+			{Name: "output", Type: string(mod.InputTypeDirectory)},
+		},
+		ProducedOutputs: []mod.ModuleOutput{
+			{Name: "text", Patterns: []string{".txt"}, Type: string(mod.OutputTypeFile)},
+		},
+	}
+}
+
+func (m *recordingModule) Execute(ctx context.Context, params map[string]interface{}) (mod.ModuleResult, error) {
+	m.calls = append(m.calls, params)
+	path := filepath.Join(params["output"].(string), params["label"].(string)+".txt")
+	if err := os.WriteFile(path, []byte("text"), 0644); err != nil {
+		return mod.ModuleResult{}, err
+	}
+	return mod.ModuleResult{Outputs: map[string]string{"text": path}}, nil
+}
+
+// newRecordingWorkflow returns a two-step workflow whose second step reads the seed file explicitly
+func newRecordingWorkflow(t *testing.T, outputDir string) (*Workflow, *recordingModule) {
+	recorder := &recordingModule{}
+	registry := mod.NewModuleRegistry()
+	require.NoError(t, registry.Register(recorder))
+
+	w := &Workflow{
+		Name:   "Variables Test",
+		Input:  "${output}/seed.txt",
+		Output: outputDir,
+		Steps: []Step{
+			{Name: "first", Module: "record", Parameters: map[string]interface{}{
+				"input": "${output}/seed.txt",
+				"label": "${step.name}",
+				"note":  "${run.id}",
+			}},
+			{Name: "second", Module: "record", Parameters: map[string]interface{}{
+				"input":     "${output}/seed.txt",
+				"label":     "${step.name}",
+				"sourceDir": "${input}",
+				"notesPath": "notes/my\\ notes.txt",
+			}},
+		},
+		registry:    registry,
+		checkpoints: make(map[string]*WorkflowCheckpoint),
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(outputDir, "seed.txt"), []byte("seed"), 0644))
+	return w, recorder
+}
+
+func TestVariables_Resolve(t *testing.T) {
+	vars := Variables{Output: "/runs/r1", Input: "/videos/in.mp4", RunID: "abc"}
+	step := Step{Name: "Suggest Shorts"}
+
+	tests := []struct {
+		name  string
+		key   string
+		value string
+		want  string
+	}{
+		{name: "output", key: "input", value: "${output}/transcript.txt", want: "/runs/r1/transcript.txt"},
+		{name: "input", key: "videoFile", value: "${input}", want: "/videos/in.mp4"},
+		{name: "run id and step name", key: "title", value: "${run.id}-${step.name}", want: "abc-Suggest Shorts"},
+		{name: "relative path gets ./", key: "promptFilePath", value: "prompts/sns.yaml", want: "./prompts/sns.yaml"},
+		{name: "non-path values are untouched", key: "model", value: "gpt-4o", want: "gpt-4o"},
+		{name: "escaped spaces in paths", key: "input", value: "${output}/my\\ clip.mp4", want: "/runs/r1/my clip.mp4"},
+		{name: "module placeholders are kept", key: "videoFile", value: "${source_video}", want: "${source_video}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, vars.Resolve(tt.key, tt.value, step))
+		})
+	}
+
+	t.Run("variables without a value are kept", func(t *testing.T) {
+		assert.Equal(t, "${input}", Variables{Output: "/runs/r1"}.Resolve("videoFile", "${input}", step))
+	})
+
+	t.Run("lists and nested mappings", func(t *testing.T) {
+		params := vars.ResolveParams(Step{Name: "s", Parameters: map[string]interface{}{
+			"fallbackModels": []interface{}{"${step.name}-a", 3},
+			"metadata":       map[string]interface{}{"guestFile": "guest.yaml", "run": "${run.id}"},
+		}})
+		assert.Equal(t, []interface{}{"s-a", 3}, params["fallbackModels"])
+		assert.Equal(t, map[string]interface{}{"guestFile": "guest.yaml", "run": "abc"}, params["metadata"])
+	})
+}
+
+func TestExecute_ResolvesVariables(t *testing.T) {
+	outputDir := t.TempDir()
+	w, recorder := newRecordingWorkflow(t, outputDir)
+
+	require.NoError(t, w.Execute())
+	require.Len(t, recorder.calls, 2)
+
+	first, second := recorder.calls[0], recorder.calls[1]
+	seed := filepath.Join(outputDir, "seed.txt")
+	assert.Equal(t, seed, first["input"], "the workflow input is resolved before the first step")
+	assert.Equal(t, "first", first["label"])
+	assert.NotEmpty(t, first["note"])
+	assert.False(t, strings.Contains(first["note"].(string), "${"))
+
+	assert.Equal(t, seed, second["input"], "inputs configured with a variable are not replaced by earlier outputs")
+	assert.Equal(t, "second", second["label"])
+	assert.Equal(t, seed, second["sourceDir"])
+	assert.Equal(t, "./notes/my notes.txt", second["notesPath"])
+}
+
+func TestExecuteRetry_ResolvesVariables(t *testing.T) {
+	outputDir := t.TempDir()
+	w, recorder := newRecordingWorkflow(t, outputDir)
+	w.Output = ""
+
+	require.NoError(t, w.ExecuteRetry(outputDir, "second"))
+	require.Len(t, recorder.calls, 1)
+
+	call := recorder.calls[0]
+	assert.Equal(t, filepath.Join(outputDir, "seed.txt"), call["input"])
+	assert.Equal(t, "second", call["label"])
+	assert.Equal(t, "./notes/my notes.txt", call["notesPath"])
+	assert.FileExists(t, filepath.Join(outputDir, "Variables_Test.state.yaml"))
+}
@@ -0,0 +1,297 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// WatchFileName is the routing file read from a watched folder when no other one is given
+const WatchFileName = "watch.yaml"
+
+// watchLedgerName is the file of the watched folder that remembers the files already run
+const watchLedgerName = ".studioflowai-watch.json"
+
+// Defaults of the watch settings
+const (
+	DefaultWatchInterval = 30 * time.Second
+	DefaultWatchMinAge   = time.Minute
+)
+
+// defaultWatchIgnore are files that are never run: hidden files, downloads and copies in progress,
+// and the watch files themselves
+var defaultWatchIgnore = []string{"**/.*", "**/*.part", "**/*.partial", "**/*.tmp", "**/*.crdownload", "**/*.download", WatchFileName}
+
+// defaultWatchMatch are the files a route without patterns matches
+var defaultWatchMatch = []string{"**/*.mp4", "**/*.mov", "**/*.mkv"}
+
+// WatchConfig routes the files dropped in a watched folder to the workflows that process them,
+// so one folder can serve several shows
+type WatchConfig struct {
+	Folder   string        `yaml:"folder"`   // Folder to watch, relative to the config file (default: the config file's folder)
+	Interval time.Duration `yaml:"interval"` // Time between scans (default: 30s)
+	MinAge   time.Duration `yaml:"minAge"`   // Time since the last change before a file is run, so files still being written are left alone (default: 1m)
+	MinSize  string        `yaml:"minSize"`  // Smallest file run, e.g. "50MB" (default: any size)
+	Ignore   []string      `yaml:"ignore"`   // Patterns of files never run, on top of hidden and partial files
+	Routes   []WatchRoute  `yaml:"routes"`   // Rules tried in order; the first one matching a file runs it
+
+	dir     string
+	minSize int64
+}
+
+// WatchRoute sends the files matching its patterns to a workflow
+type WatchRoute struct {
+	Name     string        `yaml:"name"`     // Name shown in logs (default: the workflow file name)
+	Match    []string      `yaml:"match"`    // Patterns of the files, relative to the watched folder (default: "**/*.mp4", "**/*.mov", "**/*.mkv")
+	Ignore   []string      `yaml:"ignore"`   // Patterns of matching files this route leaves to the next ones
+	Workflow string        `yaml:"workflow"` // Workflow file, relative to the config file
+	MinAge   time.Duration `yaml:"minAge"`   // Overrides the config's minAge
+	MinSize  string        `yaml:"minSize"`  // Overrides the config's minSize
+
+	minSize int64
+}
+
+// WatchFile is a file of the watched folder ready to run, with the route that runs it
+type WatchFile struct {
+	Path  string
+	Rel   string // Slash-separated path relative to the watched folder
+	Route *WatchRoute
+	info  os.FileInfo
+}
+
+// watchLedgerEntry remembers a file that was run, so it is not run again unless it changes
+type watchLedgerEntry struct {
+	Size     int64     `json:"size"`
+	ModTime  time.Time `json:"modTime"`
+	Workflow string    `json:"workflow"`
+	Status   string    `json:"status"`
+	RunTime  time.Time `json:"runTime"`
+}
+
+// LoadWatchConfig reads a routing file
+func LoadWatchConfig(path string) (*WatchConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read watch config: %w", err)
+	}
+
+	var config WatchConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	config.dir = filepath.Dir(path)
+	if config.Folder == "" {
+		config.Folder = config.dir
+	} else if !filepath.IsAbs(config.Folder) {
+		config.Folder = filepath.Join(config.dir, config.Folder)
+	}
+	for i := range config.Routes {
+		if workflow := config.Routes[i].Workflow; workflow != "" && !filepath.IsAbs(workflow) {
+			config.Routes[i].Workflow = filepath.Join(config.dir, workflow)
+		}
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &config, nil
+}
+
+// NewWatchConfig returns a config running every video dropped in folder through one workflow
+func NewWatchConfig(folder, workflow string) (*WatchConfig, error) {
+	config := &WatchConfig{Folder: folder, Routes: []WatchRoute{{Workflow: workflow}}}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Validate checks the routes and fills in the defaults
+func (c *WatchConfig) Validate() error {
+	if c.Interval == 0 {
+		c.Interval = DefaultWatchInterval
+	}
+	if c.Interval < 0 || c.MinAge < 0 {
+		return fmt.Errorf("interval and minAge must not be negative")
+	}
+	if c.MinAge == 0 {
+		c.MinAge = DefaultWatchMinAge
+	}
+	var err error
+	if c.minSize, err = parseByteSize(c.MinSize); err != nil {
+		return fmt.Errorf("minSize: %w", err)
+	}
+	if err := utils.NewFileFilter(nil, c.Ignore, nil).Validate(); err != nil {
+		return err
+	}
+
+	if len(c.Routes) == 0 {
+		return fmt.Errorf("no routes: add at least one route with a workflow")
+	}
+	for i := range c.Routes {
+		route := &c.Routes[i]
+		if route.Workflow == "" {
+			return fmt.Errorf("route %d has no workflow", i+1)
+		}
+		if _, err := os.Stat(route.Workflow); err != nil {
+			return fmt.Errorf("route %d: workflow %s does not exist", i+1, route.Workflow)
+		}
+		if route.Name == "" {
+			route.Name = strings.TrimSuffix(filepath.Base(route.Workflow), filepath.Ext(route.Workflow))
+		}
+		if len(route.Match) == 0 {
+			route.Match = defaultWatchMatch
+		}
+		if err := route.filter().Validate(); err != nil {
+			return fmt.Errorf("route %s: %w", route.Name, err)
+		}
+		if route.MinAge < 0 {
+			return fmt.Errorf("route %s: minAge must not be negative", route.Name)
+		}
+		if route.MinAge == 0 {
+			route.MinAge = c.MinAge
+		}
+		route.minSize = c.minSize
+		if route.MinSize != "" {
+			if route.minSize, err = parseByteSize(route.MinSize); err != nil {
+				return fmt.Errorf("route %s: minSize: %w", route.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// filter returns the files of the route
+func (r *WatchRoute) filter() utils.FileFilter {
+	return utils.NewFileFilter(r.Match, r.Ignore, defaultWatchMatch)
+}
+
+// Route returns the first route matching a slash-separated path relative to the watched folder,
+// or nil when the file is ignored or no route matches it
+func (c *WatchConfig) Route(rel string) *WatchRoute {
+	if utils.NewFileFilter(append(append([]string{}, defaultWatchIgnore...), c.Ignore...), nil, nil).Match(rel) {
+		return nil
+	}
+	for i := range c.Routes {
+		if c.Routes[i].filter().Match(rel) {
+			return &c.Routes[i]
+		}
+	}
+	return nil
+}
+
+// Pending returns the files of the watched folder that a route matches, that are old and large
+// enough to be complete, and that have not been run since they last changed, in name order
+func (c *WatchConfig) Pending(now time.Time) ([]WatchFile, error) {
+	ledger, err := c.loadLedger()
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := utils.NewFileFilter([]string{"**/*"}, nil, nil).Files(c.Folder)
+	if err != nil {
+		return nil, err
+	}
+	var pending []WatchFile
+	for _, path := range files {
+		rel, err := filepath.Rel(c.Folder, path)
+		if err != nil {
+			return nil, err
+		}
+		rel = filepath.ToSlash(rel)
+		route := c.Route(rel)
+		if route == nil {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			// Moved or deleted since the folder was read
+			continue
+		}
+		if now.Sub(info.ModTime()) < route.MinAge || info.Size() < route.minSize {
+			utils.LogDebug("Waiting for %s to be complete", rel)
+			continue
+		}
+		if entry, ok := ledger[rel]; ok && entry.Size == info.Size() && entry.ModTime.Equal(info.ModTime()) {
+			continue
+		}
+		pending = append(pending, WatchFile{Path: path, Rel: rel, Route: route, info: info})
+	}
+	return pending, nil
+}
+
+// Record remembers that a file was run with the given status, so it is not run again unless it changes
+func (c *WatchConfig) Record(file WatchFile, status string) error {
+	ledger, err := c.loadLedger()
+	if err != nil {
+		return err
+	}
+	ledger[file.Rel] = watchLedgerEntry{
+		Size:     file.info.Size(),
+		ModTime:  file.info.ModTime(),
+		Workflow: file.Route.Workflow,
+		Status:   status,
+		RunTime:  time.Now(),
+	}
+
+	data, err := json.MarshalIndent(ledger, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal watch ledger: %w", err)
+	}
+	return utils.AtomicWriteFile(filepath.Join(c.Folder, watchLedgerName), data, 0644)
+}
+
+// loadLedger reads the files already run. A missing ledger is empty.
+func (c *WatchConfig) loadLedger() (map[string]watchLedgerEntry, error) {
+	ledger := make(map[string]watchLedgerEntry)
+	path := filepath.Join(c.Folder, watchLedgerName)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return ledger, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read watch ledger: %w", err)
+	}
+	if err := json.Unmarshal(data, &ledger); err != nil {
+		return nil, fmt.Errorf("failed to parse watch ledger %s: %w", path, err)
+	}
+	return ledger, nil
+}
+
+// String describes the routes in the order they are tried
+func (c *WatchConfig) String() string {
+	routes := make([]string, 0, len(c.Routes))
+	for _, route := range c.Routes {
+		routes = append(routes, fmt.Sprintf("%s (%s)", route.Name, route.filter()))
+	}
+	return strings.Join(routes, "; ")
+}
+
+// parseByteSize parses a size such as "500", "64KB", "50MB" or "2GB". Units are powers of 1024.
+func parseByteSize(size string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(size))
+	if s == "" {
+		return 0, nil
+	}
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		bytes  int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(s, unit.suffix) {
+			s, multiplier = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix)), unit.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q: use a number of bytes or KB, MB, GB", size)
+	}
+	return int64(n * float64(multiplier)), nil
+}
@@ -0,0 +1,127 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const watchConfig = `minAge: 1m
+minSize: 1KB
+ignore: ["drafts/**"]
+routes:
+  - name: podcast
+    match: ["podcast/**/*.mp4", "**/podcast-*.mov"]
+    workflow: podcast.yaml
+  - match: ["**/*.mp4"]
+    ignore: ["**/*_raw.mp4"]
+    workflow: shorts.yaml
+    minSize: 10
+`
+
+// writeWatchFolder writes a watched folder with a watch.yaml, its workflows and the given files
+func writeWatchFolder(t *testing.T, files map[string]int) string {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, WatchFileName), []byte(watchConfig), 0644))
+	for _, name := range []string{"podcast.yaml", "shorts.yaml"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("name: "+name), 0644))
+	}
+
+	old := time.Now().Add(-time.Hour)
+	for name, size := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, make([]byte, size), 0644))
+		require.NoError(t, os.Chtimes(path, old, old))
+	}
+	return dir
+}
+
+func TestWatchConfig_Route(t *testing.T) {
+	dir := writeWatchFolder(t, nil)
+	config, err := LoadWatchConfig(filepath.Join(dir, WatchFileName))
+	require.NoError(t, err)
+	assert.Equal(t, dir, config.Folder)
+	assert.Equal(t, DefaultWatchInterval, config.Interval)
+
+	for rel, want := range map[string]string{
+		"podcast/ep1/episode.mp4": "podcast",
+		"live/podcast-42.mov":     "podcast",
+		"clips/short.mp4":         "shorts",
+		"clips/short_raw.mp4":     "",
+		"drafts/cut.mp4":          "",
+		"notes.txt":               "",
+		"clips/.short.mp4":        "",
+		"clips/short.mp4.part":    "",
+	} {
+		route := config.Route(rel)
+		if want == "" {
+			assert.Nil(t, route, rel)
+			continue
+		}
+		require.NotNil(t, route, rel)
+		assert.Equal(t, want, route.Name, rel)
+		assert.Equal(t, filepath.Join(dir, want+".yaml"), route.Workflow)
+	}
+}
+
+func TestWatchConfig_Pending(t *testing.T) {
+	dir := writeWatchFolder(t, map[string]int{
+		"podcast/episode.mp4": 2048,
+		"podcast/teaser.mp4":  100, // Below the config's minSize
+		"clips/short.mp4":     100, // Above the route's minSize
+		"notes.txt":           2048,
+	})
+	config, err := LoadWatchConfig(filepath.Join(dir, WatchFileName))
+	require.NoError(t, err)
+
+	// A file still being written is left alone
+	fresh := filepath.Join(dir, "clips", "live.mp4")
+	require.NoError(t, os.WriteFile(fresh, make([]byte, 2048), 0644))
+
+	pending, err := config.Pending(time.Now())
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, "clips/short.mp4", pending[0].Rel)
+	assert.Equal(t, "podcast/episode.mp4", pending[1].Rel)
+	assert.Equal(t, "podcast", pending[1].Route.Name)
+
+	// Files run are not run again until they change
+	require.NoError(t, config.Record(pending[0], "completed"))
+	require.NoError(t, config.Record(pending[1], "failed"))
+	pending, err = config.Pending(time.Now().Add(2 * time.Minute))
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "clips/live.mp4", pending[0].Rel)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "clips", "short.mp4"), make([]byte, 200), 0644))
+	pending, err = config.Pending(time.Now().Add(2 * time.Minute))
+	require.NoError(t, err)
+	assert.Len(t, pending, 2)
+}
+
+func TestWatchConfig_Validate(t *testing.T) {
+	dir := t.TempDir()
+	workflow := filepath.Join(dir, "episode.yaml")
+	require.NoError(t, os.WriteFile(workflow, []byte("name: episode"), 0644))
+
+	config, err := NewWatchConfig(dir, workflow)
+	require.NoError(t, err)
+	assert.Equal(t, "episode", config.Routes[0].Name)
+	assert.Equal(t, defaultWatchMatch, config.Routes[0].Match)
+	assert.Equal(t, DefaultWatchMinAge, config.Routes[0].MinAge)
+
+	_, err = NewWatchConfig(dir, filepath.Join(dir, "missing.yaml"))
+	assert.ErrorContains(t, err, "does not exist")
+
+	bad := &WatchConfig{Folder: dir, MinSize: "lots", Routes: []WatchRoute{{Workflow: workflow}}}
+	assert.ErrorContains(t, bad.Validate(), "minSize")
+
+	size, err := parseByteSize("1.5 MB")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1572864), size)
+}
@@ -0,0 +1,88 @@
+package workflow
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/scheduler"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/worker"
+)
+
+// applyWorkers adds the workers given on the command line to the workflow's and checks that every
+// step sent to a worker names one of them
+func applyWorkers(workflow *Workflow, workers map[string]string) error {
+	if len(workers) > 0 && workflow.Workers == nil {
+		workflow.Workers = make(map[string]string, len(workers))
+	}
+	for name, addr := range workers {
+		workflow.Workers[name] = addr
+	}
+	return workflow.checkWorkers()
+}
+
+// checkWorkers checks that the worker of every step is known and has an address, and that the
+// steps' classes exist
+func (w *Workflow) checkWorkers() error {
+	names := make([]string, 0, len(w.Workers))
+	for name, addr := range w.Workers {
+		if addr == "" {
+			return fmt.Errorf("worker %q has no address", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, step := range w.Steps {
+		if step.Class != "" {
+			if _, err := scheduler.ParseClass(step.Class); err != nil {
+				return fmt.Errorf("step %q: %w", step.Name, err)
+			}
+		}
+		if step.Worker == "" {
+			continue
+		}
+		if _, ok := w.Workers[step.Worker]; !ok {
+			return fmt.Errorf("step %q runs on worker %q, which is not in the workflow's workers %v (add it or pass --worker %s=host:port)", step.Name, step.Worker, names, step.Worker)
+		}
+		utils.LogVerbose("Step %s runs on worker %s (%s)", step.Name, step.Worker, w.Workers[step.Worker])
+	}
+	return nil
+}
+
+// stepModule returns the module running a step: the registered module, sent to the step's worker
+// when it has one
+func (w *Workflow) stepModule(step Step) (mod.Module, error) {
+	module, err := w.registry.Get(step.Module)
+	if err != nil || step.Worker == "" {
+		return module, err
+	}
+	addr, ok := w.Workers[step.Worker]
+	if !ok {
+		return nil, fmt.Errorf("step %q runs on unknown worker %q", step.Name, step.Worker)
+	}
+
+	client, ok := w.workerClients[addr]
+	if !ok {
+		if client, err = worker.Dial(addr, os.Getenv(worker.TokenEnv)); err != nil {
+			return nil, err
+		}
+		if w.workerClients == nil {
+			w.workerClients = make(map[string]*worker.Client)
+		}
+		w.workerClients[addr] = client
+	}
+	return worker.NewModule(module, client, worker.Job{Step: step.Name, Class: step.Class, Priority: step.Priority}), nil
+}
+
+// closeWorkers closes the connections to the workers of the run
+func (w *Workflow) closeWorkers() {
+	for addr, client := range w.workerClients {
+		if err := client.Close(); err != nil {
+			utils.LogWarning("Failed to close the connection to worker %s: %v", addr, err)
+		}
+		delete(w.workerClients, addr)
+	}
+}
@@ -0,0 +1,53 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/worker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyWorkers(t *testing.T) {
+	w := &Workflow{
+		Workers: map[string]string{"gpu": "gpu-box:7070"},
+		Steps: []Step{
+			{Name: "transcribe", Module: "record", Worker: "gpu"},
+			{Name: "render", Module: "record", Worker: "render"},
+		},
+	}
+	err := applyWorkers(w, nil)
+	assert.ErrorContains(t, err, `step "render" runs on worker "render"`)
+	assert.ErrorContains(t, err, "--worker render=host:port")
+
+	// Workers from the command line are added to the workflow's and replace those of the same name
+	require.NoError(t, applyWorkers(w, map[string]string{"gpu": "10.0.0.2:7070", "render": "10.0.0.3:7070"}))
+	assert.Equal(t, map[string]string{"gpu": "10.0.0.2:7070", "render": "10.0.0.3:7070"}, w.Workers)
+
+	assert.ErrorContains(t, applyWorkers(&Workflow{}, map[string]string{"gpu": ""}), `worker "gpu" has no address`)
+	assert.ErrorContains(t, applyWorkers(&Workflow{Steps: []Step{{Name: "render", Class: "tpu"}}}, nil), `step "render": unknown step class "tpu"`)
+}
+
+func TestStepModule(t *testing.T) {
+	registry := mod.NewModuleRegistry()
+	require.NoError(t, registry.Register(&recordingModule{}))
+	w := &Workflow{Workers: map[string]string{"gpu": "gpu-box:7070"}, registry: registry}
+	defer w.closeWorkers()
+
+	local, err := w.stepModule(Step{Name: "notes", Module: "record"})
+	require.NoError(t, err)
+	assert.IsType(t, &recordingModule{}, local)
+
+	// Steps of the same worker share its connection
+	remote, err := w.stepModule(Step{Name: "transcribe", Module: "record", Worker: "gpu"})
+	require.NoError(t, err)
+	assert.IsType(t, &worker.Module{}, remote)
+	assert.Equal(t, "record", remote.Name())
+	_, err = w.stepModule(Step{Name: "render", Module: "record", Worker: "gpu"})
+	require.NoError(t, err)
+	assert.Len(t, w.workerClients, 1)
+
+	w.closeWorkers()
+	assert.Empty(t, w.workerClients)
+}
@@ -6,20 +6,36 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/gnzdotmx/studioflowai/studioflowai/internal/config"
 	"github.com/gnzdotmx/studioflowai/studioflowai/internal/mod"
+	blogpost "github.com/gnzdotmx/studioflowai/studioflowai/internal/modules/blog_post"
 	cleantext "github.com/gnzdotmx/studioflowai/studioflowai/internal/modules/clean_text"
 	correcttranscript "github.com/gnzdotmx/studioflowai/studioflowai/internal/modules/correct_transcript"
+	exporttimeline "github.com/gnzdotmx/studioflowai/studioflowai/internal/modules/export_timeline"
 	extractaudio "github.com/gnzdotmx/studioflowai/studioflowai/internal/modules/extract_audio"
 	extractshorts "github.com/gnzdotmx/studioflowai/studioflowai/internal/modules/extractshorts"
+	linkshorts "github.com/gnzdotmx/studioflowai/studioflowai/internal/modules/link_shorts"
+	makeproxy "github.com/gnzdotmx/studioflowai/studioflowai/internal/modules/make_proxy"
+	mergesubtitles "github.com/gnzdotmx/studioflowai/studioflowai/internal/modules/merge_subtitles"
+	"github.com/gnzdotmx/studioflowai/studioflowai/internal/modules/newsletter"
+	normalizevideo "github.com/gnzdotmx/studioflowai/studioflowai/internal/modules/normalize_video"
+	rateshorts "github.com/gnzdotmx/studioflowai/studioflowai/internal/modules/rate_shorts"
+	renderintro "github.com/gnzdotmx/studioflowai/studioflowai/internal/modules/render_intro"
+	scoreshorts "github.com/gnzdotmx/studioflowai/studioflowai/internal/modules/score_shorts"
 	settitle2shortvideo "github.com/gnzdotmx/studioflowai/studioflowai/internal/modules/settitle2shortvideo"
+	shortsreport "github.com/gnzdotmx/studioflowai/studioflowai/internal/modules/shorts_report"
+	splitchapters "github.com/gnzdotmx/studioflowai/studioflowai/internal/modules/split_chapters"
+	storyboardshorts "github.com/gnzdotmx/studioflowai/studioflowai/internal/modules/storyboard_shorts"
+	suggestbroll "github.com/gnzdotmx/studioflowai/studioflowai/internal/modules/suggest_broll"
 	suggestshorts "github.com/gnzdotmx/studioflowai/studioflowai/internal/modules/suggest_shorts"
 	suggestsnscontent "github.com/gnzdotmx/studioflowai/studioflowai/internal/modules/suggest_sns_content"
 	"github.com/gnzdotmx/studioflowai/studioflowai/internal/modules/tiktok"
 	"github.com/gnzdotmx/studioflowai/studioflowai/internal/modules/transcribe"
+	transcriptindex "github.com/gnzdotmx/studioflowai/studioflowai/internal/modules/transcript_index"
 	"github.com/gnzdotmx/studioflowai/studioflowai/internal/modules/youtube"
 	"github.com/gnzdotmx/studioflowai/studioflowai/internal/utils"
 	"github.com/google/uuid"
@@ -79,6 +95,44 @@ func (w *Workflow) ExecuteWithState() (*WorkflowState, error) {
 	// Keep track of module outputs
 	moduleOutputs := make(map[string]map[string]string)
 
+	// Load the artifact manifest so checksums accumulate across retries
+	var manifest *RunManifest
+	var manifestPath string
+	if w.Output != "" {
+		manifestPath = filepath.Join(w.Output, manifestFileName(w.Name))
+		manifest, err = LoadRunManifest(manifestPath, w.Name)
+		if err != nil {
+			utils.LogWarning("Failed to load artifact manifest, starting a new one: %v", err)
+			manifest = NewRunManifest(w.Name)
+		}
+		manifest.Seed = utils.RunSeed()
+		manifest.Deterministic = utils.Deterministic()
+	}
+
+	// Write every external command to a script that reproduces the run by hand
+	var commandLog *utils.CommandLog
+	if w.Output != "" {
+		if commandLog, err = utils.OpenCommandLog(filepath.Join(w.Output, utils.CommandLogFileName)); err != nil {
+			utils.LogWarning("Commands of this run will not be logged: %v", err)
+		}
+	}
+
+	// Split the log by step when asked, keeping the combined log of the run beside it
+	if w.Output != "" && utils.StepLogFiles() {
+		if err := utils.OpenStepLogs(filepath.Join(w.Output, utils.StepLogDirName)); err != nil {
+			utils.LogWarning("Steps of this run will not be logged to files: %v", err)
+		} else {
+			defer utils.CloseStepLogs()
+		}
+	}
+	defer w.closeWorkers()
+
+	// The workflow input may itself refer to the output folder, e.g. ${output}/shorts_suggestions.yaml
+	input := ""
+	if w.Input != "" && len(w.Steps) > 0 {
+		input = Variables{Output: w.Output, RunID: state.ID}.Resolve("input", w.Input, w.Steps[0])
+	}
+
 	// Execute nodes in order
 	for i, nodeID := range order {
 		node := graph.Nodes[nodeID]
@@ -94,6 +148,7 @@ func (w *Workflow) ExecuteWithState() (*WorkflowState, error) {
 		// Update state
 		state.CurrentNode = nodeID
 		node.Status = NodeStatusRunning
+		stepCtx := utils.WithLogStep(context.Background(), node.Step.Name)
 
 		// Record event
 		state.AddEvent(WorkflowEvent{
@@ -104,8 +159,8 @@ func (w *Workflow) ExecuteWithState() (*WorkflowState, error) {
 			Message:   fmt.Sprintf("Started executing %s", node.Step.Name),
 		})
 
-		// Execute the module
-		module, err := w.registry.Get(node.Step.Module)
+		// Execute the module, on its worker if it has one
+		module, err := w.stepModule(node.Step)
 		if err != nil {
 			node.Status = NodeStatusFailed
 			state.Status = WorkflowStatusFailed
@@ -113,41 +168,39 @@ func (w *Workflow) ExecuteWithState() (*WorkflowState, error) {
 			// Save checkpoint for retry
 			w.SaveCheckpoint(nodeID, state)
 
+			// Record failure event
+			state.AddEvent(WorkflowEvent{
+				ID:        uuid.New().String(),
+				Timestamp: time.Now(),
+				NodeID:    nodeID,
+				Type:      "failed",
+				Message:   fmt.Sprintf("Failed to get module %s: %v", node.Step.Module, err),
+				Data: map[string]interface{}{
+					"error": err.Error(),
+				},
+			})
+
 			return state, fmt.Errorf("failed to get module %s: %w", node.Step.Module, err)
 		}
 
-		// Prepare parameters with input/output paths
-		params := make(map[string]interface{})
-		for k, v := range node.Step.Parameters {
-			// Handle string parameters that might contain ${output}
-			if strVal, ok := v.(string); ok {
-				if strings.Contains(strVal, "${output}") {
-					// Replace ${output} with actual output path
-					resolvedPath := strings.ReplaceAll(strVal, "${output}", w.Output)
-					params[k] = resolvedPath
-				} else {
-					// Only add ./ prefix for input/output paths, not for command names
-					if k == "input" || k == "output" || strings.HasSuffix(k, "Path") || strings.HasSuffix(k, "File") || strings.HasSuffix(k, "Dir") {
-						if !filepath.IsAbs(strVal) && !strings.HasPrefix(strVal, "./") {
-							params[k] = "./" + strVal
-						} else {
-							params[k] = strVal
-						}
-					} else {
-						params[k] = strVal
-					}
-				}
-			} else {
-				params[k] = v
-			}
-		}
+		// Interpolate ${output}, ${input}, ${run.id} and ${step.name} in the step's parameters
+		vars := Variables{Output: w.Output, Input: input, RunID: state.ID}
+		params := vars.ResolveParams(node.Step)
 
 		// Handle input parameter based on step position
-		if i == 0 {
+		if producer, ok := nodeMap[node.Step.FromStep]; ok && node.Step.FromStep != "" {
+			// The input was declared to come from an earlier step
+			if outputPath, found := producedInput(module, moduleOutputs[producer.ID]); found {
+				utils.LogInfoContext(stepCtx, "Step %s: Processing: %s", node.Step.Name, outputPath)
+				params["input"] = outputPath
+			} else {
+				utils.LogWarningContext(stepCtx, "Step %s: step %s produced no matching output, using the configured input", node.Step.Name, node.Step.FromStep)
+			}
+		} else if i == 0 {
 			// First step: use global input if provided, otherwise keep input from parameters
-			if w.Input != "" {
-				params["input"] = w.Input
-				state.GlobalInputs["input"] = w.Input
+			if input != "" {
+				params["input"] = input
+				state.GlobalInputs["input"] = input
 			}
 		} else if _, hasInput := params["input"]; hasInput {
 			// Get the module's input requirements
@@ -166,11 +219,9 @@ func (w *Workflow) ExecuteWithState() (*WorkflowState, error) {
 				}
 			}
 
-			// Check if input is explicitly configured with ${output}
-			if strInput, ok := params["input"].(string); ok {
-				if strings.Contains(strInput, "${output}") {
-					goto inputFound
-				}
+			// Inputs explicitly configured with a variable are kept as written
+			if strInput, ok := node.Step.Parameters["input"].(string); ok && HasVariable(strInput) {
+				goto inputFound
 			}
 
 			// Only try to find matching outputs if we have patterns to match against
@@ -179,12 +230,18 @@ func (w *Workflow) ExecuteWithState() (*WorkflowState, error) {
 					prevNode := graph.Nodes[order[j]]
 
 					if outputs, ok := moduleOutputs[prevNode.ID]; ok {
-						// Try to find a matching output based on file patterns
-						for _, outputPath := range outputs {
+						// Try to find a matching output based on file patterns, in a stable order
+						names := make([]string, 0, len(outputs))
+						for name := range outputs {
+							names = append(names, name)
+						}
+						sort.Strings(names)
+						for _, name := range names {
+							outputPath := outputs[name]
 							// Only use the output if it matches one of our expected patterns
 							for _, expectedPattern := range expectedPatterns {
 								if strings.HasSuffix(outputPath, expectedPattern) {
-									utils.LogInfo("Step %s: Processing: %s", node.Step.Name, outputPath)
+									utils.LogInfoContext(stepCtx, "Step %s: Processing: %s", node.Step.Name, outputPath)
 									params["input"] = outputPath
 									goto inputFound
 								}
@@ -199,8 +256,17 @@ func (w *Workflow) ExecuteWithState() (*WorkflowState, error) {
 		// Set output directory
 		params["output"] = w.Output
 
-		// Execute the module
-		result, err := module.Execute(context.Background(), params)
+		// Execute the module, collecting the tokens and tools it uses
+		ctx, usage := utils.WithUsage(utils.WithCommandLog(stepCtx, commandLog, node.Step.Name))
+		if retry := w.retryItems[node.Step.Name]; len(retry) > 0 {
+			utils.LogInfoContext(stepCtx, "Step %s: retrying the %d item(s) that failed in the previous attempt", node.Step.Name, len(retry))
+			ctx = mod.WithRetryItems(ctx, retry)
+		}
+		started := time.Now()
+		var result mod.ModuleResult
+		if err = utils.InjectStepFailure(node.Step.Name); err == nil {
+			result, err = module.Execute(ctx, params)
+		}
 		if err != nil {
 			node.Status = NodeStatusFailed
 			state.Status = WorkflowStatusFailed
@@ -231,6 +297,45 @@ func (w *Workflow) ExecuteWithState() (*WorkflowState, error) {
 		node.Outputs = result.Outputs
 		node.Metadata = result.Metadata
 
+		// The status of every item and the items that failed are kept in the state file, so a
+		// retry processes only those
+		if len(result.Items) > 0 {
+			if node.Metadata == nil {
+				node.Metadata = make(map[string]interface{})
+			}
+			node.Metadata["items"] = result.Items
+		}
+		failed := result.FailedItems()
+		if len(failed) > 0 {
+			node.Status = NodeStatusPartiallyFailed
+			node.Metadata["failedItems"] = failed
+			errs := make(map[string]interface{}, len(failed))
+			for _, item := range result.Items {
+				if item.Status == mod.ItemFailed {
+					errs[item.ID] = item.Error
+					utils.LogWarningContext(stepCtx, "Step %s: %s failed: %s", node.Step.Name, item.ID, item.Error)
+				}
+			}
+			utils.LogWarningContext(stepCtx, "Step %s: %d of %d items failed; the others are used by the next steps", node.Step.Name, len(failed), len(result.Items))
+			state.AddEvent(WorkflowEvent{
+				ID:        uuid.New().String(),
+				Timestamp: time.Now(),
+				NodeID:    nodeID,
+				Type:      "partially_failed",
+				Message:   fmt.Sprintf("%d of %d items of %s failed", len(failed), len(result.Items), node.Step.Name),
+				Data:      errs,
+			})
+		}
+
+		// Record checksums of the produced artifacts and the step statistics
+		if manifest != nil {
+			manifest.RecordOutputs(node.Step, result.Outputs, w.Output)
+			manifest.RecordStats(node.Step, stepStats(result, params, usage, time.Since(started)))
+			if err := manifest.Save(manifestPath); err != nil {
+				utils.LogWarningContext(stepCtx, "Failed to save artifact manifest: %v", err)
+			}
+		}
+
 		// Clear checkpoint on success
 		w.ClearCheckpoint(nodeID)
 
@@ -249,6 +354,13 @@ func (w *Workflow) ExecuteWithState() (*WorkflowState, error) {
 	state.Status = WorkflowStatusComplete
 	state.EndTime = time.Now()
 
+	for _, nodeID := range order {
+		if node := state.Graph.Nodes[nodeID]; node != nil && node.Status == NodeStatusPartiallyFailed {
+			state.Status = WorkflowStatusPartiallyFailed
+			utils.LogWarning("Step %s partially failed; retry its failed items with: --retry -o %s -n %q", node.Step.Name, w.Output, node.Step.Name)
+		}
+	}
+
 	return state, nil
 }
 
@@ -263,6 +375,21 @@ func (w *Workflow) buildDependencyEdges(graph *WorkflowGraph, nodeMap map[string
 		}
 	}
 
+	// Declared producers run before the steps reading their output
+	for _, step := range w.Steps {
+		if step.FromStep == "" {
+			continue
+		}
+		producer, ok := nodeMap[step.FromStep]
+		if !ok {
+			// The producer is not part of a retried subset; its output already exists
+			continue
+		}
+		if err := graph.AddEdge(producer.ID, nodeMap[step.Name].ID); err != nil {
+			return fmt.Errorf("failed to add fromStep edge: %w", err)
+		}
+	}
+
 	// Then add edges based on module dependencies
 	for i, step := range w.Steps {
 		module, err := w.registry.Get(step.Module)
@@ -363,12 +490,12 @@ func (w *Workflow) SaveWorkflowState(state *WorkflowState, outputPath string) er
 	}
 
 	// Ensure directory exists
-	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+	if err := utils.EnsureDir(filepath.Dir(outputPath)); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
-	// Write to file
-	if err := os.WriteFile(outputPath, data, 0644); err != nil {
+	// Write to file atomically so a crash never leaves a truncated state file
+	if err := utils.AtomicWriteFile(outputPath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write workflow state: %w", err)
 	}
 
@@ -450,6 +577,58 @@ func (w *Workflow) LoadWorkflowState(inputPath string) (*WorkflowState, error) {
 	return state, nil
 }
 
+// NewRegistry returns a module registry with all available modules registered
+func NewRegistry() (*mod.ModuleRegistry, error) {
+	registry := mod.NewModuleRegistry()
+	if err := registerModules(registry); err != nil {
+		return nil, fmt.Errorf("failed to register modules: %w", err)
+	}
+	return registry, nil
+}
+
+// ValidateFile checks a workflow file against the workflow schema and the parameter checks of its
+// modules without executing it. A non-empty input replaces the workflow input, as with run -i.
+func ValidateFile(path, input string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read workflow file: %w", err)
+	}
+
+	registry, err := NewRegistry()
+	if err != nil {
+		return err
+	}
+
+	if err := ValidateWorkflowSchema(path, data, registry); err != nil {
+		return err
+	}
+
+	var workflow Workflow
+	if err := yaml.Unmarshal(data, &workflow); err != nil {
+		return fmt.Errorf("failed to parse workflow file: %w", err)
+	}
+	if err := applyLegacyModules(&workflow); err != nil {
+		return err
+	}
+	workflow.registry = registry
+	if input != "" {
+		workflow.Input = input
+	} else if workflow.Input == "" && len(workflow.Steps) > 0 {
+		workflow.Input, _ = workflow.Steps[0].Parameters["input"].(string)
+	}
+	return workflow.validateSteps()
+}
+
+// JSONSchema returns the JSON Schema for workflow files using all available modules
+func JSONSchema() (map[string]interface{}, error) {
+	registry, err := NewRegistry()
+	if err != nil {
+		return nil, err
+	}
+
+	return WorkflowJSONSchema(registry), nil
+}
+
 // LoadFromFile loads a workflow from a YAML file
 func LoadFromFile(inputConfig *config.InputConfig) (*Workflow, error) {
 	// Read workflow file
@@ -474,11 +653,122 @@ func LoadFromFile(inputConfig *config.InputConfig) (*Workflow, error) {
 		return nil, fmt.Errorf("failed to register modules: %w", err)
 	}
 
+	// Reject unknown fields, wrong types and misnamed modules before running anything
+	if err := ValidateWorkflowSchema(inputConfig.WorkflowPath, data, workflow.registry); err != nil {
+		return nil, err
+	}
+	if err := applyLegacyModules(&workflow); err != nil {
+		return nil, err
+	}
+
+	// Use the output permissions of the workflow, or else of the project, before any output is written
+	permissions := workflow.Permissions
+	if permissions == nil && config.ActiveProject() != nil {
+		permissions = config.ActiveProject().Permissions
+	}
+	if err := utils.ApplyPermissions(permissions); err != nil {
+		return nil, err
+	}
+
+	// Resolve ${project} and the active project's accounts
+	if err := applyProject(&workflow, config.ActiveProject()); err != nil {
+		return nil, err
+	}
+
+	// Number the episode of a series before the metadata is handed out
+	if err := applySeries(&workflow, config.ActiveProject()); err != nil {
+		return nil, err
+	}
+
+	// Hand the workflow's filtergraph template to the rendering steps
+	if workflow.Filtergraph != "" {
+		defaultStepParam(&workflow, "filtergraph", workflow.Filtergraph)
+	}
+
+	// Hand the theme of the workflow, or else of the project, to the rendering steps
+	theme, err := utils.ResolveProjectPath(workflow.Theme)
+	if err != nil {
+		return nil, fmt.Errorf("theme: %w", err)
+	}
+	if theme == "" && config.ActiveProject() != nil {
+		theme = config.ActiveProject().ThemePath()
+	}
+	if theme != "" {
+		defaultStepParam(&workflow, "theme", theme)
+	}
+
+	// Hand the whisper profiles of the workflow, or else of the project, to the transcribe steps
+	whisperProfiles := workflow.WhisperProfiles
+	if whisperProfiles == nil && config.ActiveProject() != nil {
+		whisperProfiles = config.ActiveProject().WhisperProfiles
+	}
+	if len(whisperProfiles) > 0 {
+		defaultStepParam(&workflow, "whisperProfiles", whisperProfiles)
+	}
+
+	// Hand the transcript fixes of the workflow, or else of the project, to the transcribe steps
+	transcriptFixes := workflow.TranscriptFixes
+	if transcriptFixes == nil && config.ActiveProject() != nil {
+		transcriptFixes = config.ActiveProject().TranscriptFixes
+	}
+	if len(transcriptFixes) > 0 {
+		defaultStepParam(&workflow, "transcriptFixes", transcriptFixes)
+	}
+
+	// Hand the time range to the audio, transcription and suggestion steps
+	timeRange, err := utils.ParseTimeRange(workflow.StartTime, workflow.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	if !timeRange.IsZero() {
+		utils.LogInfo("Processing only %s of the source video", timeRange)
+		if workflow.StartTime != "" {
+			defaultStepParam(&workflow, "startTime", workflow.StartTime)
+		}
+		if workflow.EndTime != "" {
+			defaultStepParam(&workflow, "endTime", workflow.EndTime)
+		}
+	}
+
+	// Replace ${asset:name} with the files of the named fonts, logos, intros and music
+	if err := applyAssets(context.Background(), &workflow, config.ActiveProject()); err != nil {
+		return nil, err
+	}
+
+	// Send steps to the workers given on the command line, or else in the workflow
+	if err := applyWorkers(&workflow, inputConfig.Workers); err != nil {
+		return nil, err
+	}
+
+	// Set the inactivity limits of external tools
+	if err := applyWatchdog(workflow.Watchdog); err != nil {
+		return nil, err
+	}
+
+	// Pass the episode metadata to the LLM steps
+	if err := applyMetadata(&workflow); err != nil {
+		return nil, err
+	}
+
 	// Map of module parameters that require video input
 	videoInputParams := map[string][]string{
+		"normalize_video":          {"input"},
+		"make_proxy":               {"input"},
 		"extractaudio":             {"input"},
 		"extract_shorts":           {"videoFile"},
 		"set_title_to_short_video": {"videoFile"},
+		"export_timeline":          {"videoFile"},
+		"score_shorts":             {"videoFile"},
+		"storyboard_shorts":        {"videoFile"},
+		"split_chapters":           {"videoFile"},
+	}
+
+	// When the source is normalized first, later steps read the mezzanine instead of the raw input
+	for _, step := range workflow.Steps {
+		if step.Module == "normalize_video" {
+			videoInputParams = map[string][]string{"normalize_video": {"input"}}
+			break
+		}
 	}
 
 	// Set input path - prefer command line flag over workflow file
@@ -513,36 +803,152 @@ func LoadFromFile(inputConfig *config.InputConfig) (*Workflow, error) {
 		workflow.Input = inputPath
 	} else if len(workflow.Steps) > 0 {
 		// If no command line input, try to get it from the first step's parameters
+		// Variables such as ${output} and the ./ prefix are resolved when the step runs
 		if inputParam, ok := workflow.Steps[0].Parameters["input"].(string); ok {
-			// If the input path is absolute, use it as is
-			if filepath.IsAbs(inputParam) {
-				workflow.Input = inputParam
-			} else {
-				// For relative paths, add ./ prefix if not present
-				if !strings.HasPrefix(inputParam, "./") {
-					workflow.Input = "./" + inputParam
-				} else {
-					workflow.Input = inputParam
-				}
+			workflow.Input = inputParam
+		}
+	}
+
+	// Let transcribe steps find a transcript published next to the source video
+	if isVideoFile(workflow.Input) {
+		for i, step := range workflow.Steps {
+			if step.Module != "transcribe" {
+				continue
 			}
+			if _, ok := step.Parameters["videoFile"]; ok {
+				continue
+			}
+			if workflow.Steps[i].Parameters == nil {
+				workflow.Steps[i].Parameters = make(map[string]interface{})
+			}
+			workflow.Steps[i].Parameters["videoFile"] = workflow.Input
 		}
 	}
 
+	// Point the correction steps at the confidence report of the first transcribe step that writes one
+	for _, step := range workflow.Steps {
+		if report := confidenceReportParam(step); report != "" {
+			defaultStepParam(&workflow, "qcReport", report)
+			break
+		}
+	}
+
+	// Create the run folder when only its root is known
+	if inputConfig.OutputPath == "" && (inputConfig.OutputRoot != "" || inputConfig.OutputName != "") {
+		folder, err := workflow.createRunFolder(inputConfig)
+		if err != nil {
+			return nil, err
+		}
+		inputConfig.OutputPath = folder
+	}
+
 	// Set output path
 	workflow.Output = inputConfig.OutputPath
 
 	return &workflow, nil
 }
 
+// createRunFolder creates the folder of a new run, named after the --output-name pattern, the
+// workflow's or the project's. Without a root, as for runs outside a project, it goes under the
+// workflow's output directory.
+func (w *Workflow) createRunFolder(inputConfig *config.InputConfig) (string, error) {
+	root := inputConfig.OutputRoot
+	if root == "" {
+		root = w.Output
+	}
+	if root == "" {
+		root = "output"
+	}
+
+	pattern := inputConfig.OutputName
+	if pattern == "" {
+		pattern = w.OutputName
+	}
+	vars := RunFolderVars{
+		WorkflowFile: inputConfig.WorkflowPath,
+		Name:         w.Name,
+		Input:        w.Input,
+		RunID:        inputConfig.RunID,
+		ShortID:      inputConfig.ShortID,
+		Time:         runFolderTime(),
+	}
+	if project := config.ActiveProject(); project != nil {
+		vars.Project = project.Name
+		if pattern == "" {
+			pattern = project.OutputName
+		}
+	}
+
+	return ResolveRunFolder(root, pattern, vars)
+}
+
+// confidenceReportParam returns where a transcribe step with confidence enabled writes its QC report
+func confidenceReportParam(step Step) string {
+	if step.Module != "transcribe" {
+		return ""
+	}
+	if enabled, _ := step.Parameters["confidence"].(bool); !enabled {
+		return ""
+	}
+	output, _ := step.Parameters["output"].(string)
+	base, _ := step.Parameters["outputFileName"].(string)
+	if base == "" {
+		input, _ := step.Parameters["input"].(string)
+		base = strings.TrimSuffix(filepath.Base(input), filepath.Ext(input))
+	}
+	if output == "" || base == "" || base == "." {
+		return ""
+	}
+	return filepath.Join(output, base+"_qc.yaml")
+}
+
+// applyWatchdog overrides the built-in inactivity limits of external tools with the workflow's
+func applyWatchdog(configs map[string]WatchdogConfig) error {
+	defaults := utils.DefaultCommandWatchdogs()
+	for name, cfg := range configs {
+		watchdog := defaults[name]
+		if cfg.Idle != "" {
+			idle, err := time.ParseDuration(cfg.Idle)
+			if cfg.Idle == "0" {
+				idle, err = 0, nil
+			}
+			if err != nil || idle < 0 {
+				return fmt.Errorf("invalid watchdog idle %q for %s: use a duration such as 20m", cfg.Idle, name)
+			}
+			watchdog.Idle = idle
+		}
+		if cfg.Retries != nil {
+			if *cfg.Retries < 0 {
+				return fmt.Errorf("invalid watchdog retries for %s: must not be negative", name)
+			}
+			watchdog.Retries = *cfg.Retries
+		}
+		if watchdog.Idle == 0 && cfg.Idle == "" {
+			return fmt.Errorf("watchdog for %s needs an idle duration", name)
+		}
+		utils.SetCommandWatchdog(name, watchdog)
+	}
+	return nil
+}
+
 // registerModules registers all available modules with the registry
 func registerModules(registry *mod.ModuleRegistry) error {
 	// Upload modules (these implement the correct interface)
+	if err := registry.Register(normalizevideo.New()); err != nil {
+		utils.LogError("Failed to register normalizevideo module: %v", err)
+	}
+	if err := registry.Register(makeproxy.New()); err != nil {
+		utils.LogError("Failed to register makeproxy module: %v", err)
+	}
 	if err := registry.Register(extractaudio.New()); err != nil {
 		utils.LogError("Failed to register extractaudio module: %v", err)
 	}
 	if err := registry.Register(transcribe.New()); err != nil {
 		utils.LogError("Failed to register transcribe module: %v", err)
 	}
+	if err := registry.Register(mergesubtitles.New()); err != nil {
+		utils.LogError("Failed to register mergesubtitles module: %v", err)
+	}
 	if err := registry.Register(cleantext.New()); err != nil {
 		utils.LogError("Failed to register cleantext module: %v", err)
 	}
@@ -558,15 +964,51 @@ func registerModules(registry *mod.ModuleRegistry) error {
 	if err := registry.Register(suggestshorts.New()); err != nil {
 		utils.LogError("Failed to register suggestshorts module: %v", err)
 	}
+	if err := registry.Register(scoreshorts.New()); err != nil {
+		utils.LogError("Failed to register scoreshorts module: %v", err)
+	}
+	if err := registry.Register(rateshorts.New()); err != nil {
+		utils.LogError("Failed to register rateshorts module: %v", err)
+	}
+	if err := registry.Register(storyboardshorts.New()); err != nil {
+		utils.LogError("Failed to register storyboardshorts module: %v", err)
+	}
+	if err := registry.Register(shortsreport.New()); err != nil {
+		utils.LogError("Failed to register shortsreport module: %v", err)
+	}
 	if err := registry.Register(settitle2shortvideo.New()); err != nil {
 		utils.LogError("Failed to register settitle2shortvideo module: %v", err)
 	}
+	if err := registry.Register(exporttimeline.New()); err != nil {
+		utils.LogError("Failed to register exporttimeline module: %v", err)
+	}
+	if err := registry.Register(renderintro.New()); err != nil {
+		utils.LogError("Failed to register renderintro module: %v", err)
+	}
 	if err := registry.Register(youtube.New()); err != nil {
 		utils.LogError("Failed to register youtube module: %v", err)
 	}
+	if err := registry.Register(splitchapters.New()); err != nil {
+		utils.LogError("Failed to register splitchapters module: %v", err)
+	}
+	if err := registry.Register(linkshorts.New()); err != nil {
+		utils.LogError("Failed to register linkshorts module: %v", err)
+	}
 	if err := registry.Register(tiktok.NewUploadTikTokShorts()); err != nil {
 		utils.LogError("Failed to register tiktok module: %v", err)
 	}
+	if err := registry.Register(suggestbroll.New()); err != nil {
+		utils.LogError("Failed to register suggestbroll module: %v", err)
+	}
+	if err := registry.Register(transcriptindex.New()); err != nil {
+		utils.LogError("Failed to register transcriptindex module: %v", err)
+	}
+	if err := registry.Register(blogpost.New()); err != nil {
+		utils.LogError("Failed to register blogpost module: %v", err)
+	}
+	if err := registry.Register(newsletter.New()); err != nil {
+		utils.LogError("Failed to register newsletter module: %v", err)
+	}
 
 	return nil
 }
@@ -586,21 +1028,8 @@ func (w *Workflow) ExecuteRetry(outputPath, workflowName string) error {
 		return fmt.Errorf("workflow step '%s' not found in workflow", workflowName)
 	}
 
-	// Process all paths in workflow steps
-	for i := range w.Steps {
-		for k, v := range w.Steps[i].Parameters {
-			if strVal, ok := v.(string); ok {
-				// Handle ${output} placeholder and escaped spaces
-				if strings.Contains(strVal, "${output}") {
-					strVal = strings.ReplaceAll(strVal, "${output}", outputPath)
-				}
-				if strings.Contains(strVal, "\\ ") {
-					strVal = strings.ReplaceAll(strVal, "\\ ", " ")
-				}
-				w.Steps[i].Parameters[k] = strVal
-			}
-		}
-	}
+	// Variables are resolved against the retried run's folder when each step executes
+	w.Output = outputPath
 
 	// Create a subset of steps starting from the specified step
 	w.Steps = w.Steps[startStepIndex:]
@@ -652,6 +1081,7 @@ func (w *Workflow) ExecuteRetry(outputPath, workflowName string) error {
 				Name:       step.Name,
 				Module:     step.Module,
 				Parameters: make(map[string]interface{}),
+				FromStep:   step.FromStep,
 			}
 
 			// Copy and process parameters
@@ -671,7 +1101,7 @@ func (w *Workflow) ExecuteRetry(outputPath, workflowName string) error {
 		// For the starting step, try to use its configured input if no override is provided
 		if w.Input == "" {
 			if inputParam, ok := w.Steps[0].Parameters["input"].(string); ok {
-				w.Input = inputParam
+				w.Input = Variables{Output: outputPath}.Resolve("input", inputParam, w.Steps[0])
 				utils.LogInfo("Using configured input from step: %s", w.Input)
 			}
 		}
@@ -700,11 +1130,45 @@ func (w *Workflow) ExecuteRetry(outputPath, workflowName string) error {
 				break
 			}
 		}
+
+		// A partially failed step is retried for its failed items only
+		w.retryItems = failedItems(prevState, workflowName)
+	}
+
+	manifestPath := filepath.Join(outputPath, manifestFileName(w.Name))
+	if manifest, err := LoadRunManifest(manifestPath, w.Name); err != nil {
+		utils.LogWarning("Failed to load artifact manifest: %v", err)
+	} else {
+		// Outputs of the retried steps from the previous attempt must not be matched by later steps.
+		// A step retried for its failed items keeps the items that succeeded.
+		mode := w.invalidationMode()
+		stale := make([]Step, 0, len(w.Steps))
+		for _, step := range w.Steps {
+			if len(w.retryItems[step.Name]) == 0 {
+				stale = append(stale, step)
+			}
+		}
+		invalidated, err := invalidateArtifacts(manifest, outputPath, stale, mode)
+		if err != nil {
+			return err
+		}
+		if len(invalidated) > 0 {
+			utils.LogInfo("Invalidated %d artifacts from the previous attempt (%s)", len(invalidated), mode)
+			if err := manifest.Save(manifestPath); err != nil {
+				utils.LogWarning("Failed to save artifact manifest: %v", err)
+			}
+		}
+
+		// Warn about artifacts from earlier steps that were truncated or changed since they were written
+		for _, problem := range manifest.Verify(outputPath) {
+			utils.LogWarning("Artifact check: %s", problem)
+		}
 	}
 
 	// Execute from specified step or last failed node
 	newState, err := w.ExecuteWithState()
 	if err != nil {
+		w.writeTimeline(newState, outputPath, err)
 		return err
 	}
 
@@ -712,14 +1176,37 @@ func (w *Workflow) ExecuteRetry(outputPath, workflowName string) error {
 	if err := w.SaveWorkflowState(newState, filepath.Join(outputPath, sanitizedName+".state.yaml")); err != nil {
 		return fmt.Errorf("failed to save workflow state: %w", err)
 	}
+	w.writeTimeline(newState, outputPath, nil)
 
 	return nil
 }
 
+// failedItems returns the items that failed in a partially failed step of a previous run, by step name
+func failedItems(state *WorkflowState, stepName string) map[string][]string {
+	for _, node := range state.Graph.Nodes {
+		if node.Step.Name != stepName || node.Status != NodeStatusPartiallyFailed {
+			continue
+		}
+		list, ok := node.Metadata["failedItems"].([]interface{})
+		if !ok {
+			return nil
+		}
+		var ids []string
+		for _, id := range list {
+			if s, ok := id.(string); ok {
+				ids = append(ids, s)
+			}
+		}
+		return map[string][]string{stepName: ids}
+	}
+	return nil
+}
+
 // Execute runs the workflow and returns any error
 func (w *Workflow) Execute() error {
 	state, err := w.ExecuteWithState()
 	if err != nil {
+		w.writeTimeline(state, w.Output, err)
 		return err
 	}
 
@@ -731,6 +1218,23 @@ func (w *Workflow) Execute() error {
 	if err := w.SaveWorkflowState(state, statePath); err != nil {
 		return fmt.Errorf("failed to save workflow state: %w", err)
 	}
+	w.writeTimeline(state, w.Output, nil)
 
 	return nil
 }
+
+// writeTimeline writes the events of a finished or failed run to its output folder. A run that
+// failed points to it, so a post-mortem need not start from the state file.
+func (w *Workflow) writeTimeline(state *WorkflowState, outputPath string, runErr error) {
+	if state == nil || outputPath == "" {
+		return
+	}
+	path, err := WriteTimeline(state, outputPath)
+	if err != nil {
+		utils.LogWarning("%v", err)
+		return
+	}
+	if runErr != nil {
+		utils.LogInfo("Timeline of the failed run: %s", path)
+	}
+}